@@ -11,11 +11,13 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+	"github.com/grafana/grafana/pkg/tsdb/syntheticsds"
 )
 
 // API related actions
 const (
 	ActionProvisioningReload = "provisioning:reload"
+	ActionProvisioningRead   = "provisioning:read"
 )
 
 // API related scopes
@@ -26,6 +28,7 @@ var (
 	ScopeProvisionersDatasources   = ac.Scope("provisioners", "datasources")
 	ScopeProvisionersNotifications = ac.Scope("provisioners", "notifications")
 	ScopeProvisionersAlertRules    = ac.Scope("provisioners", "alerting")
+	ScopeProvisionersAccessControl = ac.Scope("provisioners", "accesscontrol")
 )
 
 // declareFixedRoles declares to the AccessControl service fixed roles and their
@@ -53,6 +56,22 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{ac.RoleGrafanaAdmin},
 	}
 
+	provisioningReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:provisioning:reader",
+			DisplayName: "Provisioning reader",
+			Description: "Read the status of provisioning.",
+			Group:       "Provisioning",
+			Permissions: []ac.Permission{
+				{
+					Action: ActionProvisioningRead,
+					Scope:  ScopeProvisionersAll,
+				},
+			},
+		},
+		Grants: []string{ac.RoleGrafanaAdmin},
+	}
+
 	datasourcesExplorerRole := ac.RoleRegistration{
 		Role: ac.RoleDTO{
 			Name:        "fixed:datasources:explorer",
@@ -96,7 +115,7 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Role: ac.RoleDTO{
 			Name:        "fixed:datasources.builtin:reader",
 			DisplayName: "Built in data source reader",
-			Description: "Read and query Grafana's built in test data sources.",
+			Description: "Read and query Grafana's built in test data sources and the results of synthetic checks.",
 			Group:       "Data sources",
 			Permissions: []ac.Permission{
 				{
@@ -107,6 +126,14 @@ func (hs *HTTPServer) declareFixedRoles() error {
 					Action: datasources.ActionQuery,
 					Scope:  fmt.Sprintf("%s%s", datasources.ScopePrefix, grafanads.DatasourceUID),
 				},
+				{
+					Action: datasources.ActionRead,
+					Scope:  fmt.Sprintf("%s%s", datasources.ScopePrefix, syntheticsds.DatasourceUID),
+				},
+				{
+					Action: datasources.ActionQuery,
+					Scope:  fmt.Sprintf("%s%s", datasources.ScopePrefix, syntheticsds.DatasourceUID),
+				},
 			},
 			Hidden: true,
 		},
@@ -422,14 +449,95 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{"Admin"},
 	}
 
+	livePublisherRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:live:publisher",
+			DisplayName: "Live publisher",
+			Description: "Push data to all Grafana Live channels.",
+			Group:       "Live",
+			Permissions: []ac.Permission{
+				{Action: ac.ActionLivePublish, Scope: ac.ScopeLiveChannelsAll},
+			},
+		},
+		Grants: []string{"Admin"},
+	}
+
+	shortURLsReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:shorturls:reader",
+			DisplayName: "Short URL reader",
+			Description: "List the short URLs of the organization and their usage.",
+			Group:       "Short URLs",
+			Permissions: []ac.Permission{
+				{Action: ac.ActionShortURLsRead},
+			},
+		},
+		Grants: []string{"Admin"},
+	}
+
+	shortURLsWriterRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:shorturls:writer",
+			DisplayName: "Short URL writer",
+			Description: "List, update and delete the short URLs of the organization.",
+			Group:       "Short URLs",
+			Permissions: []ac.Permission{
+				{Action: ac.ActionShortURLsRead},
+				{Action: ac.ActionShortURLsWrite},
+			},
+		},
+		Grants: []string{"Admin"},
+	}
+
+	webhooksReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:webhooks:reader",
+			DisplayName: "Webhook reader",
+			Description: "List the webhooks of the organization and their deliveries.",
+			Group:       "Webhooks",
+			Permissions: []ac.Permission{
+				{Action: ac.ActionWebhooksRead},
+			},
+		},
+		Grants: []string{"Admin"},
+	}
+
+	webhooksWriterRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:webhooks:writer",
+			DisplayName: "Webhook writer",
+			Description: "Create, update and delete the webhooks of the organization and redeliver events.",
+			Group:       "Webhooks",
+			Permissions: []ac.Permission{
+				{Action: ac.ActionWebhooksRead},
+				{Action: ac.ActionWebhooksWrite},
+			},
+		},
+		Grants: []string{"Admin"},
+	}
+
+	orgLogsReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:organization.logs:reader",
+			DisplayName: "Organization logs reader",
+			Description: "Read and stream the log lines of the requests made in the organization.",
+			Group:       "Organizations",
+			Permissions: []ac.Permission{
+				{Action: ac.ActionOrgsLogsRead},
+			},
+		},
+		Grants: []string{"Admin"},
+	}
+
 	return hs.accesscontrolService.DeclareFixedRoles(
-		provisioningWriterRole, datasourcesReaderRole, builtInDatasourceReader, datasourcesWriterRole,
+		provisioningWriterRole, provisioningReaderRole, datasourcesReaderRole, builtInDatasourceReader, datasourcesWriterRole,
 		datasourcesIdReaderRole, orgReaderRole, orgWriterRole,
 		orgMaintainerRole, teamsCreatorRole, teamsWriterRole, datasourcesExplorerRole,
 		annotationsReaderRole, dashboardAnnotationsWriterRole, annotationsWriterRole,
 		dashboardsCreatorRole, dashboardsReaderRole, dashboardsWriterRole,
 		foldersCreatorRole, foldersReaderRole, foldersWriterRole, apikeyReaderRole, apikeyWriterRole,
-		publicDashboardsWriterRole,
+		publicDashboardsWriterRole, livePublisherRole, shortURLsReaderRole, shortURLsWriterRole,
+		webhooksReaderRole, webhooksWriterRole, orgLogsReaderRole,
 	)
 }
 
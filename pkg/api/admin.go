@@ -2,7 +2,10 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
@@ -32,12 +35,45 @@ func (hs *HTTPServer) AdminGetSettings(c *models.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, settings)
 }
 
+// swagger:route POST /admin/settings/reload admin adminReloadSettings
+//
+// Reload settings.
+//
+// Reads the configuration files again and applies the changed settings which can be applied without a restart. The response lists the changed settings which were applied and the ones which require a restart.
+// If you have Fine-grained access control enabled, you need to have a permission with action `settings:write` and scope `settings:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminReloadSettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminReloadSettings(c *models.ReqContext) response.Response {
+	result, err := hs.SettingsProvider.Reload()
+	if err != nil {
+		var validationErr setting.ValidationError
+		if errors.As(err, &validationErr) {
+			return response.Error(http.StatusBadRequest, "Invalid settings: "+err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to reload settings", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// adminStatsCacheTTL is how long the admin stats are cached. The stats are
+// computed at most once per period, however often the admin page is loaded.
+const adminStatsCacheTTL = time.Minute
+
 // swagger:route GET /admin/stats admin adminGetStats
 //
 // Fetch Grafana Stats.
 //
 // Only works with Basic Authentication (username and password). See introduction for an explanation.
 // If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `server:stats:read`.
+// The stats are cached for a minute.
 //
 // Responses:
 // 200: adminGetStatsResponse
@@ -45,13 +81,95 @@ func (hs *HTTPServer) AdminGetSettings(c *models.ReqContext) response.Response {
 // 403: forbiddenError
 // 500: internalServerError
 func (hs *HTTPServer) AdminGetStats(c *models.ReqContext) response.Response {
-	statsQuery := models.GetAdminStatsQuery{}
-
-	if err := hs.SQLStore.GetAdminStats(c.Req.Context(), &statsQuery); err != nil {
+	stats, err := hs.cachedAdminStats("admin-stats", func() (interface{}, error) {
+		statsQuery := models.GetAdminStatsQuery{}
+		err := hs.SQLStore.GetAdminStats(c.Req.Context(), &statsQuery)
+		return statsQuery.Result, err
+	})
+	if err != nil {
 		return response.Error(500, "Failed to get admin stats from database", err)
 	}
 
-	return response.JSON(http.StatusOK, statsQuery.Result)
+	return response.JSON(http.StatusOK, stats)
+}
+
+// swagger:route GET /admin/stats/orgs admin adminGetOrgStats
+//
+// Fetch Grafana Stats per organization.
+//
+// Returns a page of the resource counts per organization, ordered by organization ID.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `server:stats:read`.
+// The stats are cached for a minute.
+//
+// Responses:
+// 200: adminGetOrgStatsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetOrgStats(c *models.ReqContext) response.Response {
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+	perPage := c.QueryInt("perpage")
+	if perPage < 1 || perPage > 1000 {
+		perPage = 100
+	}
+
+	stats, err := hs.cachedAdminStats(fmt.Sprintf("admin-stats-orgs-%d-%d", page, perPage), func() (interface{}, error) {
+		statsQuery := models.GetAdminOrgStatsQuery{Page: page, PerPage: perPage}
+		err := hs.SQLStore.GetAdminOrgStats(c.Req.Context(), &statsQuery)
+		return statsQuery.Result, err
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get org stats from database", err)
+	}
+
+	return response.JSON(http.StatusOK, stats)
+}
+
+// swagger:route GET /admin/stats/age admin adminGetResourceAgeStats
+//
+// Fetch Grafana Stats per resource age.
+//
+// Counts the dashboards and data sources by the time since their last update, and the users by the time since they were last seen.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `server:stats:read`.
+// The stats are cached for a minute.
+//
+// Responses:
+// 200: adminGetResourceAgeStatsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetResourceAgeStats(c *models.ReqContext) response.Response {
+	stats, err := hs.cachedAdminStats("admin-stats-age", func() (interface{}, error) {
+		statsQuery := models.GetAdminResourceAgeStatsQuery{}
+		err := hs.SQLStore.GetAdminResourceAgeStats(c.Req.Context(), &statsQuery)
+		return statsQuery.Result, err
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get resource age stats from database", err)
+	}
+
+	return response.JSON(http.StatusOK, stats)
+}
+
+// cachedAdminStats returns the cached admin stats of a key, or computes them. Concurrent
+// requests for the same key share a single computation.
+func (hs *HTTPServer) cachedAdminStats(key string, compute func() (interface{}, error)) (interface{}, error) {
+	if cached, found := hs.CacheService.Get(key); found {
+		return cached, nil
+	}
+
+	stats, err, _ := hs.adminStatsGroup.Do(key, func() (interface{}, error) {
+		stats, err := compute()
+		if err != nil {
+			return nil, err
+		}
+		hs.CacheService.Set(key, stats, adminStatsCacheTTL)
+		return stats, nil
+	})
+	return stats, err
 }
 
 func (hs *HTTPServer) getAuthorizedSettings(ctx context.Context, user *user.SignedInUser, bag setting.SettingsBag) (setting.SettingsBag, error) {
@@ -105,8 +223,38 @@ type GetSettingsResponse struct {
 	Body setting.SettingsBag `json:"body"`
 }
 
+// swagger:response adminReloadSettingsResponse
+type ReloadSettingsResponse struct {
+	// in:body
+	Body setting.ReloadResult `json:"body"`
+}
+
 // swagger:response adminGetStatsResponse
 type GetStatsResponse struct {
 	// in:body
 	Body models.AdminStats `json:"body"`
 }
+
+// swagger:parameters adminGetOrgStats
+type AdminGetOrgStatsParams struct {
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:response adminGetOrgStatsResponse
+type GetOrgStatsResponse struct {
+	// in:body
+	Body models.AdminOrgStatsResult `json:"body"`
+}
+
+// swagger:response adminGetResourceAgeStatsResponse
+type GetResourceAgeStatsResponse struct {
+	// in:body
+	Body models.AdminResourceAgeStats `json:"body"`
+}
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	skv "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 )
 
@@ -18,7 +20,10 @@ func (hs *HTTPServer) AdminRotateDataEncryptionKeys(c *models.ReqContext) respon
 }
 
 func (hs *HTTPServer) AdminReEncryptEncryptionKeys(c *models.ReqContext) response.Response {
-	if err := hs.SecretsService.ReEncryptDataKeys(c.Req.Context()); err != nil {
+	if err := hs.secretsMigrator.ReEncryptDataKeys(c.Req.Context()); err != nil {
+		if errors.Is(err, secrets.ErrReEncryptionLimitReached) {
+			return response.Error(http.StatusConflict, "Secrets are being re-encrypted by other instances", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to re-encrypt data keys", err)
 	}
 
@@ -28,6 +33,9 @@ func (hs *HTTPServer) AdminReEncryptEncryptionKeys(c *models.ReqContext) respons
 func (hs *HTTPServer) AdminReEncryptSecrets(c *models.ReqContext) response.Response {
 	success, err := hs.secretsMigrator.ReEncryptSecrets(c.Req.Context())
 	if err != nil {
+		if errors.Is(err, secrets.ErrReEncryptionLimitReached) {
+			return response.Error(http.StatusConflict, "Secrets are being re-encrypted by other instances", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to re-encrypt secrets", err)
 	}
 
@@ -38,6 +46,46 @@ func (hs *HTTPServer) AdminReEncryptSecrets(c *models.ReqContext) response.Respo
 	return response.Respond(http.StatusOK, "Secrets re-encrypted successfully")
 }
 
+// AdminRotateSecrets starts rotating the data keys and re-encrypting all stored secrets with
+// new ones in the background. The progress is reported by AdminGetSecretsRotationStatus.
+func (hs *HTTPServer) AdminRotateSecrets(c *models.ReqContext) response.Response {
+	status, err := hs.secretsMigrator.RotateSecrets(c.Req.Context(), secrets.RotationTriggerManual)
+	if err != nil {
+		if errors.Is(err, secrets.ErrRotationInProgress) {
+			return response.Error(http.StatusConflict, "Secrets rotation is already in progress", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to start secrets rotation", err)
+	}
+
+	return response.JSON(http.StatusAccepted, status)
+}
+
+func (hs *HTTPServer) AdminGetSecretsRotationStatus(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.secretsMigrator.GetRotationStatus())
+}
+
+// AdminGetSecretsInventory lists the stored secrets with the provider and data key encrypting them.
+// The secret values are never returned. With legacyOnly=true, only the secrets still encrypted
+// with the legacy secret key are listed.
+func (hs *HTTPServer) AdminGetSecretsInventory(c *models.ReqContext) response.Response {
+	inventory, err := hs.secretsMigrator.GetInventory(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list secrets", err)
+	}
+
+	if c.QueryBool("legacyOnly") {
+		items := make([]secrets.InventoryItem, 0, inventory.Summary.Legacy)
+		for _, item := range inventory.Items {
+			if item.Provider == secrets.LegacyProvider {
+				items = append(items, item)
+			}
+		}
+		inventory.Items = items
+	}
+
+	return response.JSON(http.StatusOK, inventory)
+}
+
 func (hs *HTTPServer) AdminRollbackSecrets(c *models.ReqContext) response.Response {
 	success, err := hs.secretsMigrator.RollBackSecrets(c.Req.Context())
 	if err != nil {
@@ -98,3 +146,14 @@ func (hs *HTTPServer) AdminDeleteAllSecretsManagerPluginSecrets(c *models.ReqCon
 	}
 	return response.Respond(http.StatusOK, fmt.Sprintf("All %d Secrets Manager plugin secrets deleted", len(items)))
 }
+
+// AdminGetLegacyPluginSecrets lists the plugin settings which still hold secrets in the legacy
+// secure_json_data column, so that admins can check the progress of the migration to the secrets store.
+func (hs *HTTPServer) AdminGetLegacyPluginSecrets(c *models.ReqContext) response.Response {
+	infos, err := hs.PluginSettings.LegacySecrets(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list legacy plugin secrets", err)
+	}
+
+	return response.JSON(http.StatusOK, infos)
+}
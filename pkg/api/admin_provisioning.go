@@ -3,9 +3,11 @@ package api
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning"
 )
 
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
@@ -107,3 +109,51 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *models.ReqContext) resp
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:route POST /admin/provisioning/access-control/reload admin_provisioning adminProvisioningReloadAccessControl
+//
+// Reload team and role provisioning configurations.
+//
+// Reloads the provisioning config files for teams, team memberships, custom roles and role assignments again. It won’t return until the new provisioned entities are already stored in the database.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:accesscontrol`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningReloadAccessControl(c *models.ReqContext) response.Response {
+	err := hs.ProvisioningService.ProvisionAccessControl(c.Req.Context())
+	if err != nil {
+		return response.Error(500, "Failed to reload access control config", err)
+	}
+	return response.Success("Access control config reloaded")
+}
+
+// swagger:route GET /admin/provisioning/status admin_provisioning adminProvisioningGetStatus
+//
+// Get the provisioning status.
+//
+// Returns the last run time, the number of applied resources and the errors of every provisioner, and of every dashboard provider.
+// Errors caused by a provisioning file include the file and, when known, the line.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:read` and scope `provisioners:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningGetStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminProvisioningGetStatus(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.ProvisioningService.GetStatus())
+}
+
+// swagger:response adminProvisioningGetStatusResponse
+type AdminProvisioningGetStatusResponse struct {
+	// in:body
+	Body provisioning.Status `json:"body"`
+}
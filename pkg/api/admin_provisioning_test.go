@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 )
@@ -162,6 +163,27 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 			url:          "/api/admin/provisioning/alerting/reload",
 			exit:         true,
 		},
+		{
+			desc:         "should work for access control with specific scope",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"Access control config reloaded"}`,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersAccessControl,
+				},
+			},
+			url: "/api/admin/provisioning/access-control/reload",
+			checkCall: func(mock provisioning.ProvisioningServiceMock) {
+				assert.Len(t, mock.Calls.ProvisionAccessControl, 1)
+			},
+		},
+		{
+			desc:         "should fail for access control with no permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/admin/provisioning/access-control/reload",
+			exit:         true,
+		},
 	}
 
 	cfg := setting.NewCfg()
@@ -195,3 +217,74 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminProvisioningStatus_AccessControl(t *testing.T) {
+	tests := []reloadProvisioningTestCase{
+		{
+			desc:         "should work with read permission",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"provisioners":[{"type":"datasources","lastRun":"0001-01-01T00:00:00Z","applied":2,"errors":[{"file":"datasources.yaml","line":3,"reason":"invalid"}]}]}`,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: ActionProvisioningRead,
+					Scope:  ScopeProvisionersAll,
+				},
+			},
+			url: "/api/admin/provisioning/status",
+			checkCall: func(mock provisioning.ProvisioningServiceMock) {
+				assert.Len(t, mock.Calls.GetStatus, 1)
+			},
+		},
+		{
+			desc:         "should fail with reload permission only",
+			expectedCode: http.StatusForbidden,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersAll,
+				},
+			},
+			url:  "/api/admin/provisioning/status",
+			exit: true,
+		},
+		{
+			desc:         "should fail with no permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/admin/provisioning/status",
+			exit:         true,
+		},
+	}
+
+	cfg := setting.NewCfg()
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sc, hs := setupAccessControlScenarioContext(t, cfg, test.url, test.permissions)
+
+			provisioningMock := provisioning.NewProvisioningServiceMock(context.Background())
+			provisioningMock.GetStatusFunc = func() provisioning.Status {
+				return provisioning.Status{Provisioners: []utils.ProvisioningStatus{{
+					Type:    "datasources",
+					Applied: 2,
+					Errors:  []utils.ProvisioningError{{File: "datasources.yaml", Line: 3, Reason: "invalid"}},
+				}}}
+			}
+			hs.ProvisioningService = provisioningMock
+
+			sc.resp = httptest.NewRecorder()
+			var err error
+			sc.req, err = http.NewRequest(http.MethodGet, test.url, nil)
+			assert.NoError(t, err)
+
+			sc.exec()
+
+			assert.Equal(t, test.expectedCode, sc.resp.Code)
+			if test.exit {
+				return
+			}
+
+			assert.Equal(t, test.expectedBody, sc.resp.Body.String())
+			test.checkCall(*provisioningMock)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -118,6 +119,39 @@ func TestAdmin_AccessControl(t *testing.T) {
 				},
 			},
 		},
+		{
+			expectedCode: http.StatusOK,
+			desc:         "AdminGetOrgStats should return 200 for user with correct permissions",
+			url:          "/api/admin/stats/orgs",
+			method:       http.MethodGet,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionServerStatsRead,
+				},
+			},
+		},
+		{
+			expectedCode: http.StatusForbidden,
+			desc:         "AdminGetOrgStats should return 403 for user without required permissions",
+			url:          "/api/admin/stats/orgs",
+			method:       http.MethodGet,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: "wrong",
+				},
+			},
+		},
+		{
+			expectedCode: http.StatusOK,
+			desc:         "AdminGetResourceAgeStats should return 200 for user with correct permissions",
+			url:          "/api/admin/stats/age",
+			method:       http.MethodGet,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionServerStatsRead,
+				},
+			},
+		},
 		{
 			expectedCode: http.StatusOK,
 			desc:         "AdminGetSettings should return 200 for user with correct permissions",
@@ -140,6 +174,18 @@ func TestAdmin_AccessControl(t *testing.T) {
 				},
 			},
 		},
+		{
+			expectedCode: http.StatusForbidden,
+			desc:         "AdminReloadSettings should return 403 for user without required permissions",
+			url:          "/api/admin/settings/reload",
+			method:       http.MethodPost,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionSettingsRead,
+					Scope:  accesscontrol.ScopeSettingsAll,
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -149,6 +195,7 @@ func TestAdmin_AccessControl(t *testing.T) {
 			sc.resp = httptest.NewRecorder()
 			hs.SettingsProvider = &setting.OSSImpl{Cfg: cfg}
 			hs.SQLStore = mockstore.NewSQLStoreMock()
+			hs.CacheService = localcache.ProvideService()
 
 			var err error
 			sc.req, err = http.NewRequest(test.method, test.url, nil)
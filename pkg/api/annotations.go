@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -21,6 +22,12 @@ import (
 	"github.com/grafana/grafana/pkg/web"
 )
 
+var annotationsPagination = pagination.Config{
+	SortFields:   []string{"time"},
+	DefaultSort:  pagination.Sort{Field: "time", Descending: true},
+	DefaultLimit: 100,
+}
+
 // swagger:route GET /annotations annotations getAnnotations
 //
 // Find Annotations.
@@ -32,6 +39,11 @@ import (
 // 401: unauthorisedError
 // 500: internalServerError
 func (hs *HTTPServer) GetAnnotations(c *models.ReqContext) response.Response {
+	paging, err := annotationsPagination.Parse(c.Req.URL.Query())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+
 	query := &annotations.ItemQuery{
 		From:         c.QueryInt64("from"),
 		To:           c.QueryInt64("to"),
@@ -41,7 +53,9 @@ func (hs *HTTPServer) GetAnnotations(c *models.ReqContext) response.Response {
 		DashboardId:  c.QueryInt64("dashboardId"),
 		DashboardUid: c.Query("dashboardUID"),
 		PanelId:      c.QueryInt64("panelId"),
-		Limit:        c.QueryInt64("limit"),
+		Limit:        int64(paging.Limit),
+		Sort:         paging.Sort,
+		After:        paging.After,
 		Tags:         c.QueryStrings("tags"),
 		Type:         c.Query("type"),
 		MatchAny:     c.QueryBool("matchAny"),
@@ -68,28 +82,19 @@ func (hs *HTTPServer) GetAnnotations(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to get annotations", err)
 	}
 
-	// since there are several annotations per dashboard, we can cache dashboard uid
-	dashboardCache := make(map[int64]*string)
+	// the dashboard UIDs are selected together with the annotations
 	for _, item := range items {
 		if item.Email != "" {
 			item.AvatarUrl = dtos.GetGravatarUrl(item.Email)
 		}
+	}
 
-		if item.DashboardId != 0 {
-			if val, ok := dashboardCache[item.DashboardId]; ok {
-				item.DashboardUID = val
-			} else {
-				query := models.GetDashboardQuery{Id: item.DashboardId, OrgId: c.OrgID}
-				err := hs.DashboardService.GetDashboard(c.Req.Context(), &query)
-				if err == nil && query.Result != nil {
-					item.DashboardUID = &query.Result.Uid
-					dashboardCache[item.DashboardId] = &query.Result.Uid
-				}
-			}
+	if n := len(items); n > 0 {
+		if next := paging.NextCursor(n, items[n-1].Time, items[n-1].Id); next != "" {
+			c.Resp.Header().Set(pagination.NextCursorHeader, next)
 		}
 	}
-
-	return response.JSON(http.StatusOK, items)
+	return jsonWithFields(c, items, "")
 }
 
 type AnnotationError struct {
@@ -545,6 +550,7 @@ func (hs *HTTPServer) GetAnnotationTags(c *models.ReqContext) response.Response
 	query := &annotations.TagsQuery{
 		OrgID: c.OrgID,
 		Tag:   c.Query("tag"),
+		Key:   c.Query("key"),
 		Limit: c.QueryInt64("limit"),
 	}
 
@@ -556,6 +562,31 @@ func (hs *HTTPServer) GetAnnotationTags(c *models.ReqContext) response.Response
 	return response.JSON(http.StatusOK, annotations.GetAnnotationTagsResponse{Result: result})
 }
 
+// swagger:route GET /annotations/tags/keys annotations getAnnotationTagKeys
+//
+// Find Annotations Tag Keys.
+//
+// Find the keys of the namespaced tags of the annotations, like `env` for `env:prod`, together with the number of tagged annotations.
+//
+// Responses:
+// 200: getAnnotationTagKeysResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) GetAnnotationTagKeys(c *models.ReqContext) response.Response {
+	query := &annotations.TagKeysQuery{
+		OrgID:  c.OrgID,
+		Prefix: c.Query("prefix"),
+		Limit:  c.QueryInt64("limit"),
+	}
+
+	result, err := hs.annotationsRepo.FindTagKeys(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(500, "Failed to find annotation tag keys", err)
+	}
+
+	return response.JSON(http.StatusOK, annotations.GetAnnotationTagKeysResponse{Result: result})
+}
+
 // AnnotationTypeScopeResolver provides an ScopeAttributeResolver able to
 // resolve annotation types. Scope "annotations:id:<id>" will be translated to "annotations:type:<type>,
 // where <type> is the type of annotation with id <id>.
@@ -703,6 +734,12 @@ type GetAnnotationsParams struct {
 	// in:query
 	// required:false
 	MatchAny bool `json:"matchAny"`
+	// Fields of the items to return, separated by commas, e.g. `id,time,text`. All fields are returned by default.
+	// in:query
+	// required:false
+	// type: array
+	// collectionFormat: csv
+	Fields []string `json:"fields"`
 }
 
 // swagger:parameters getAnnotationTags
@@ -711,6 +748,23 @@ type GetAnnotationTagsParams struct {
 	// in:query
 	// required:false
 	Tag string `json:"tag"`
+	// Key limits the result to the tags with this key.
+	// in:query
+	// required:false
+	Key string `json:"key"`
+	// Max limit for results returned.
+	// in:query
+	// required:false
+	// default: 100
+	Limit string `json:"limit"`
+}
+
+// swagger:parameters getAnnotationTagKeys
+type GetAnnotationTagKeysParams struct {
+	// Prefix limits the result to the keys starting with it.
+	// in:query
+	// required:false
+	Prefix string `json:"prefix"`
 	// Max limit for results returned.
 	// in:query
 	// required:false
@@ -789,6 +843,13 @@ type PostAnnotationResponse struct {
 	} `json:"body"`
 }
 
+// swagger:response getAnnotationTagKeysResponse
+type GetAnnotationTagKeysResponse struct {
+	// The response message
+	// in: body
+	Body annotations.GetAnnotationTagKeysResponse `json:"body"`
+}
+
 // swagger:response getAnnotationTagsResponse
 type GetAnnotationTagsResponse struct {
 	// The response message
@@ -0,0 +1,380 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+const (
+	maxAnnotationsImportSize = 10 << 20
+	maxAnnotationsImportRows = 10000
+)
+
+// annotationTimeLayouts are the layouts accepted for times in imported CSV files, besides epoch
+// milliseconds. Times without a time zone are read in the time zone of the import.
+var annotationTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// annotationsCSVMapping maps the fields of an annotation to the columns of an imported CSV file.
+type annotationsCSVMapping struct {
+	time, timeEnd, text, tags, dashboardUID string
+}
+
+type annotationsCSVColumns struct {
+	time, timeEnd, text, tags, dashboardUID int
+}
+
+func (m annotationsCSVMapping) columns(header []string) (annotationsCSVColumns, error) {
+	find := func(name string) int {
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	columns := annotationsCSVColumns{
+		time:         find(m.time),
+		timeEnd:      find(m.timeEnd),
+		text:         find(m.text),
+		tags:         find(m.tags),
+		dashboardUID: find(m.dashboardUID),
+	}
+	if columns.time < 0 {
+		return columns, fmt.Errorf("column %q for the time is missing", m.time)
+	}
+	if columns.text < 0 {
+		return columns, fmt.Errorf("column %q for the text is missing", m.text)
+	}
+	return columns, nil
+}
+
+// swagger:route POST /annotations/import/csv annotations importAnnotationsCSV
+//
+// Import annotations from a CSV file.
+//
+// Creates an annotation for every row of a CSV file, sent either as the request body or as the `file` field of a multipart form. The first row is the header. The columns holding the fields of the annotations are configured by the `timeColumn`, `timeEndColumn`, `textColumn`, `tagsColumn` and `dashboardUIDColumn` parameters, which default to the names of the fields. Only the time and text columns are required.
+// Times are epoch milliseconds or dates like `2006-01-02 15:04:05`, read in the time zone given by `timezone`. Rows with errors are reported and skipped, all other rows are imported. Set `dryRun` to only validate the file.
+//
+// Responses:
+// 200: importAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) ImportAnnotationsCSV(c *models.ReqContext) response.Response {
+	body, err := annotationsCSVBody(c.Req)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read CSV file", err)
+	}
+	defer func() { _ = body.Close() }()
+
+	// Parameters are read from the query string, or from the multipart form. The request is not
+	// parsed as a form otherwise as that would consume a CSV body sent as form data.
+	query := c.Req.URL.Query()
+	param := func(name, fallback string) string {
+		value := query.Get(name)
+		if value == "" && c.Req.MultipartForm != nil && len(c.Req.MultipartForm.Value[name]) > 0 {
+			value = c.Req.MultipartForm.Value[name][0]
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+		return fallback
+	}
+
+	location, err := time.LoadLocation(param("timezone", "UTC"))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid time zone", err)
+	}
+	delimiter, size := utf8.DecodeRuneInString(param("delimiter", ","))
+	if size == 0 || delimiter == '"' || delimiter == '\n' || delimiter == '\r' {
+		return response.Error(http.StatusBadRequest, "Invalid delimiter", nil)
+	}
+	tagsSeparator := param("tagsSeparator", ",")
+	dryRun := param("dryRun", "false") == "true"
+	mapping := annotationsCSVMapping{
+		time:         param("timeColumn", "time"),
+		timeEnd:      param("timeEndColumn", "timeEnd"),
+		text:         param("textColumn", "text"),
+		tags:         param("tagsColumn", "tags"),
+		dashboardUID: param("dashboardUIDColumn", "dashboardUID"),
+	}
+
+	reader := csv.NewReader(body)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read CSV header", err)
+	}
+	columns, err := mapping.columns(header)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	importer := annotationsImporter{
+		hs:            hs,
+		c:             c,
+		columns:       columns,
+		location:      location,
+		tagsSeparator: tagsSeparator,
+		dashboards:    map[string]int64{},
+		canCreate:     map[int64]bool{},
+	}
+	result := dtos.ImportAnnotationsResult{DryRun: dryRun, Errors: []dtos.ImportAnnotationsError{}}
+	items := make([]annotations.Item, 0)
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return response.Error(http.StatusBadRequest, "Failed to read CSV file", err)
+		}
+		if row-1 > maxAnnotationsImportRows {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("CSV files can have at most %d rows", maxAnnotationsImportRows), nil)
+		}
+
+		var item *annotations.Item
+		if err == nil {
+			item, err = importer.item(record)
+		}
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, dtos.ImportAnnotationsError{Row: row, Error: err.Error()})
+			continue
+		}
+		items = append(items, *item)
+	}
+
+	if !dryRun && len(items) > 0 {
+		if err := hs.annotationsRepo.SaveMany(c.Req.Context(), items); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save annotations", err)
+		}
+	}
+	result.Imported = len(items)
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// annotationsCSVBody returns the uploaded file of a multipart form or else the request body.
+func annotationsCSVBody(req *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return http.MaxBytesReader(nil, req.Body, maxAnnotationsImportSize), nil
+	}
+
+	req.Body = http.MaxBytesReader(nil, req.Body, maxAnnotationsImportSize)
+	if err := req.ParseMultipartForm(maxAnnotationsImportSize); err != nil {
+		return nil, err
+	}
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("missing multipart form field named 'file': %w", err)
+	}
+	return file, nil
+}
+
+type annotationsImporter struct {
+	hs            *HTTPServer
+	c             *models.ReqContext
+	columns       annotationsCSVColumns
+	location      *time.Location
+	tagsSeparator string
+
+	// dashboards and canCreate cache the dashboards of the file and the permissions of the
+	// user on them, rows usually reference a handful of dashboards only.
+	dashboards map[string]int64
+	canCreate  map[int64]bool
+}
+
+// item validates a row of the CSV file and turns it into an annotation.
+func (i *annotationsImporter) item(record []string) (*annotations.Item, error) {
+	field := func(column int) string {
+		if column < 0 || column >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[column])
+	}
+
+	item := &annotations.Item{OrgId: i.c.OrgID, UserId: i.c.UserID, Text: field(i.columns.text)}
+	if item.Text == "" {
+		return nil, errors.New("text is empty")
+	}
+
+	epoch, err := i.parseTime(field(i.columns.time))
+	if err != nil {
+		return nil, fmt.Errorf("invalid time: %w", err)
+	}
+	if epoch == 0 {
+		return nil, errors.New("time is empty")
+	}
+	item.Epoch = epoch
+	item.EpochEnd = epoch
+	if value := field(i.columns.timeEnd); value != "" {
+		if item.EpochEnd, err = i.parseTime(value); err != nil {
+			return nil, fmt.Errorf("invalid time end: %w", err)
+		}
+		if item.EpochEnd < item.Epoch {
+			return nil, errors.New("time end is before time")
+		}
+	}
+
+	if value := field(i.columns.tags); value != "" {
+		for _, t := range strings.Split(value, i.tagsSeparator) {
+			if t = strings.TrimSpace(t); t != "" {
+				item.Tags = append(item.Tags, t)
+			}
+		}
+		if err := i.validateTagsLength(item.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if uid := field(i.columns.dashboardUID); uid != "" {
+		if item.DashboardId, err = i.dashboardID(uid); err != nil {
+			return nil, err
+		}
+	}
+
+	canCreate, ok := i.canCreate[item.DashboardId]
+	if !ok {
+		if canCreate, err = i.hs.canCreateAnnotation(i.c, item.DashboardId); err != nil {
+			return nil, err
+		}
+		i.canCreate[item.DashboardId] = canCreate
+	}
+	if !canCreate {
+		return nil, errors.New("permission denied")
+	}
+
+	return item, nil
+}
+
+func (i *annotationsImporter) parseTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return epoch, nil
+	}
+	for _, layout := range annotationTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, i.location); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, fmt.Errorf("%q is neither epoch milliseconds nor a date", value)
+}
+
+// validateTagsLength rejects rows whose tags would be refused by the annotation store.
+func (i *annotationsImporter) validateTagsLength(tags []string) error {
+	return validateAnnotationTagsLength(tags, i.hs.Cfg.AnnotationMaximumTagsLength)
+}
+
+// validateAnnotationTagsLength checks that tags fit the annotation store, which stores them as a
+// JSON array of at most max characters.
+func validateAnnotationTagsLength(tags []string, max int64) error {
+	length := 2 + len(tags) - 1
+	for _, t := range tags {
+		length += len(t) + 2
+	}
+	if max > 0 && int64(length) > max {
+		return fmt.Errorf("tags length (%d) exceeds the maximum allowed (%d)", length, max)
+	}
+	return nil
+}
+
+func (i *annotationsImporter) dashboardID(uid string) (int64, error) {
+	if id, ok := i.dashboards[uid]; ok {
+		if id == 0 {
+			return 0, fmt.Errorf("dashboard %q not found", uid)
+		}
+		return id, nil
+	}
+
+	query := models.GetDashboardQuery{OrgId: i.c.OrgID, Uid: uid}
+	if err := i.hs.DashboardService.GetDashboard(i.c.Req.Context(), &query); err != nil {
+		if !errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return 0, err
+		}
+		i.dashboards[uid] = 0
+		return 0, fmt.Errorf("dashboard %q not found", uid)
+	}
+	i.dashboards[uid] = query.Result.Id
+	return query.Result.Id, nil
+}
+
+// swagger:parameters importAnnotationsCSV
+type ImportAnnotationsCSVParams struct {
+	// in:body
+	// required:true
+	Body string `json:"body"`
+	// in:query
+	// required:false
+	// default:time
+	TimeColumn string `json:"timeColumn"`
+	// in:query
+	// required:false
+	// default:timeEnd
+	TimeEndColumn string `json:"timeEndColumn"`
+	// in:query
+	// required:false
+	// default:text
+	TextColumn string `json:"textColumn"`
+	// in:query
+	// required:false
+	// default:tags
+	TagsColumn string `json:"tagsColumn"`
+	// in:query
+	// required:false
+	// default:dashboardUID
+	DashboardUIDColumn string `json:"dashboardUIDColumn"`
+	// Separator of the tags in the tags column.
+	// in:query
+	// required:false
+	// default:,
+	TagsSeparator string `json:"tagsSeparator"`
+	// in:query
+	// required:false
+	// default:,
+	Delimiter string `json:"delimiter"`
+	// Time zone of the times without one.
+	// in:query
+	// required:false
+	// default:UTC
+	Timezone string `json:"timezone"`
+	// in:query
+	// required:false
+	DryRun bool `json:"dryRun"`
+}
+
+// swagger:response importAnnotationsResponse
+type ImportAnnotationsResponse struct {
+	// in:body
+	Body dtos.ImportAnnotationsResult `json:"body"`
+}
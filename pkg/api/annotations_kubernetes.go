@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// kubernetesAnnotationTag is added to all annotations created from Kubernetes events.
+	kubernetesAnnotationTag = "kubernetes"
+	maxKubernetesEvents     = 1000
+)
+
+// swagger:route POST /annotations/kubernetes annotations postKubernetesAnnotations
+//
+// Create annotations from Kubernetes events.
+//
+// Creates an organization annotation for a Kubernetes Event object, or for each event of an EventList, as sent by an event exporter running in the cluster. Events of both the core/v1 and the events.k8s.io/v1 API are accepted.
+// Annotations are tagged with `kubernetes`, the type and reason of the event, the kind and namespace of the object the event is about, and `<kind>:<name>` of the object, e.g. `pod:shop-1`. The namespaces and kinds annotated, and the tags added by reason, are set in the `[annotations.kubernetes]` section of the configuration.
+// Repeats of an event, which Kubernetes records by increasing its count, are skipped so that an event is annotated once. Only available if enabled in the configuration.
+//
+// Responses:
+// 200: postKubernetesAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) PostKubernetesAnnotations(c *models.ReqContext) response.Response {
+	cmd := dtos.PostKubernetesEventsCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	events := cmd.Items
+	if cmd.Items == nil {
+		events = []dtos.KubernetesEvent{cmd.KubernetesEvent}
+	}
+	if len(events) > maxKubernetesEvents {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("At most %d events can be posted at once", maxKubernetesEvents), nil)
+	}
+
+	result := dtos.KubernetesAnnotationsResult{Errors: []dtos.KubernetesAnnotationError{}}
+	items := make([]annotations.Item, 0, len(events))
+	now := time.Now()
+	for i, event := range events {
+		item, err := kubernetesAnnotation(hs.Cfg.KubernetesAnnotations, event, now)
+		if err == nil && item != nil {
+			err = validateAnnotationTagsLength(item.Tags, hs.Cfg.AnnotationMaximumTagsLength)
+		}
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, dtos.KubernetesAnnotationError{Index: i, Error: err.Error()})
+			continue
+		}
+		if item == nil {
+			result.Skipped++
+			continue
+		}
+		item.OrgId = c.OrgID
+		item.UserId = c.UserID
+		items = append(items, *item)
+	}
+
+	if len(items) > 0 {
+		if err := hs.annotationsRepo.SaveMany(c.Req.Context(), items); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save annotations", err)
+		}
+	}
+	result.Created = len(items)
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// kubernetesAnnotation returns the annotation of a Kubernetes event, or nil if the event is
+// filtered out or a repeat.
+func kubernetesAnnotation(settings setting.KubernetesAnnotationSettings, event dtos.KubernetesEvent, now time.Time) (*annotations.Item, error) {
+	object := event.Regarding
+	if object.Kind == "" {
+		object = event.InvolvedObject
+	}
+	if object.Kind == "" || object.Name == "" {
+		return nil, errors.New("the object the event is about is missing")
+	}
+	message := event.Note
+	if message == "" {
+		message = event.Message
+	}
+	if event.Reason == "" && message == "" {
+		return nil, errors.New("the event has neither a reason nor a message")
+	}
+
+	if event.Count > 1 || event.Series != nil {
+		return nil, nil
+	}
+	if len(settings.Kinds) > 0 && !stringsContainFold(settings.Kinds, object.Kind) {
+		return nil, nil
+	}
+	// cluster scoped objects have no namespace
+	if object.Namespace != "" && len(settings.Namespaces) > 0 && !stringsContain(settings.Namespaces, object.Namespace) {
+		return nil, nil
+	}
+
+	at := now
+	for _, t := range []*time.Time{event.EventTime, event.FirstTimestamp, event.DeprecatedFirstTimestamp, event.Metadata.CreationTimestamp} {
+		if t != nil && !t.IsZero() {
+			at = *t
+			break
+		}
+	}
+
+	name := object.Name
+	if object.Namespace != "" {
+		name = object.Namespace + "/" + object.Name
+	}
+	text := fmt.Sprintf("%s %s", object.Kind, name)
+	if event.Reason != "" {
+		text += " " + event.Reason
+	}
+	if message != "" {
+		text += ": " + message
+	}
+
+	tags := []string{kubernetesAnnotationTag}
+	tags = append(tags, settings.ReasonTags[event.Reason]...)
+	if event.Type != "" {
+		tags = append(tags, "type:"+event.Type)
+	}
+	if event.Reason != "" {
+		tags = append(tags, "reason:"+event.Reason)
+	}
+	tags = append(tags, "kind:"+object.Kind)
+	if object.Namespace != "" {
+		tags = append(tags, "namespace:"+object.Namespace)
+	}
+	tags = append(tags, strings.ToLower(object.Kind)+":"+object.Name)
+
+	return &annotations.Item{
+		Epoch:    at.UnixMilli(),
+		EpochEnd: at.UnixMilli(),
+		Text:     text,
+		Tags:     tags,
+		Data: simplejson.NewFromAny(map[string]interface{}{
+			"eventUid": event.Metadata.UID,
+			"object": map[string]interface{}{
+				"kind":      object.Kind,
+				"namespace": object.Namespace,
+				"name":      object.Name,
+				"uid":       object.UID,
+			},
+		}),
+	}, nil
+}
+
+func stringsContain(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func stringsContainFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// swagger:parameters postKubernetesAnnotations
+type PostKubernetesAnnotationsParams struct {
+	// in:body
+	// required:true
+	Body dtos.PostKubernetesEventsCmd `json:"body"`
+}
+
+// swagger:response postKubernetesAnnotationsResponse
+type PostKubernetesAnnotationsResponse struct {
+	// in:body
+	Body dtos.KubernetesAnnotationsResult `json:"body"`
+}
@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -25,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestAnnotationsAPIEndpoint(t *testing.T) {
@@ -945,3 +950,175 @@ func setUpRBACGuardian(t *testing.T) {
 
 	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanEditValue: true})
 }
+
+type savedAnnotationsRepo struct {
+	annotations.Repository
+	saved []annotations.Item
+}
+
+func (r *savedAnnotationsRepo) SaveMany(_ context.Context, items []annotations.Item) error {
+	r.saved = append(r.saved, items...)
+	return nil
+}
+
+func TestAPI_ImportAnnotationsCSV(t *testing.T) {
+	sc := setupHTTPServer(t, true)
+	setInitCtxSignedInEditor(sc.initCtx)
+	setUpRBACGuardian(t)
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{
+		{Action: accesscontrol.ActionAnnotationsCreate, Scope: accesscontrol.ScopeAnnotationsTypeOrganization},
+		{Action: accesscontrol.ActionAnnotationsCreate, Scope: accesscontrol.ScopeAnnotationsTypeDashboard},
+	}, sc.initCtx.OrgID)
+
+	dashboard, err := sc.dashboardsStore.SaveDashboard(context.Background(), models.SaveDashboardCommand{
+		OrgId:     testOrgID,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "incidents", "title": "Incidents"}),
+	})
+	require.NoError(t, err)
+
+	importCSV := func(t *testing.T, url, body string) (*savedAnnotationsRepo, dtos.ImportAnnotationsResult) {
+		t.Helper()
+		repo := &savedAnnotationsRepo{Repository: annotationstest.NewFakeAnnotationsRepo()}
+		sc.hs.annotationsRepo = repo
+
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "text/csv")
+		recorder := httptest.NewRecorder()
+		sc.server.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		var result dtos.ImportAnnotationsResult
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+		return repo, result
+	}
+
+	t.Run("imports the valid rows and reports the others", func(t *testing.T) {
+		repo, result := importCSV(t, "/api/annotations/import/csv", strings.Join([]string{
+			"time,timeEnd,text,tags,dashboardUID",
+			"2022-11-07 08:00:00,2022-11-07 09:30:00,Database failover,\"db, incident\",incidents",
+			"1667811600000,,Deploy,,",
+			"yesterday,,Invalid time,,",
+			"2022-11-07 10:00:00,,,,",
+			"2022-11-07 10:00:00,2022-11-07 09:00:00,Ends before it starts,,",
+			"2022-11-07 10:00:00,,Unknown dashboard,,missing",
+		}, "\n"))
+
+		assert.Equal(t, 2, result.Imported)
+		assert.Equal(t, 4, result.Failed)
+		assert.Equal(t, []dtos.ImportAnnotationsError{
+			{Row: 4, Error: `invalid time: "yesterday" is neither epoch milliseconds nor a date`},
+			{Row: 5, Error: "text is empty"},
+			{Row: 6, Error: "time end is before time"},
+			{Row: 7, Error: `dashboard "missing" not found`},
+		}, result.Errors)
+
+		require.Len(t, repo.saved, 2)
+		assert.Equal(t, int64(1667808000000), repo.saved[0].Epoch)
+		assert.Equal(t, int64(1667813400000), repo.saved[0].EpochEnd)
+		assert.Equal(t, []string{"db", "incident"}, repo.saved[0].Tags)
+		assert.Equal(t, dashboard.Id, repo.saved[0].DashboardId)
+		assert.Equal(t, int64(1667811600000), repo.saved[1].Epoch)
+		assert.Equal(t, int64(0), repo.saved[1].DashboardId)
+	})
+
+	t.Run("maps custom columns and reads times in the given time zone", func(t *testing.T) {
+		repo, result := importCSV(t, "/api/annotations/import/csv?timeColumn=Date&textColumn=What&tagsColumn=Labels&tagsSeparator=%7C&delimiter=%3B&timezone=Europe/Berlin",
+			"Date;What;Labels\n2022-11-07 09:00;Maintenance;infra|planned\n")
+
+		assert.Equal(t, 1, result.Imported)
+		require.Len(t, repo.saved, 1)
+		assert.Equal(t, int64(1667808000000), repo.saved[0].Epoch)
+		assert.Equal(t, []string{"infra", "planned"}, repo.saved[0].Tags)
+	})
+
+	t.Run("only validates on dry runs", func(t *testing.T) {
+		repo, result := importCSV(t, "/api/annotations/import/csv?dryRun=true", "time,text\n1667811600000,Deploy\n")
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 1, result.Imported)
+		assert.Empty(t, repo.saved)
+	})
+
+	t.Run("rejects files without the required columns", func(t *testing.T) {
+		r := callAPI(sc.server, http.MethodPost, "/api/annotations/import/csv", strings.NewReader("when,what\n1667811600000,Deploy\n"), t)
+		assert.Equal(t, http.StatusBadRequest, r.Code)
+	})
+}
+
+func TestAPI_PostKubernetesAnnotations(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.KubernetesAnnotations = setting.KubernetesAnnotationSettings{
+		Enabled:    true,
+		Namespaces: []string{"shop"},
+		Kinds:      []string{"pod", "node"},
+		ReasonTags: map[string][]string{"OOMKilling": {"oom"}},
+	}
+	sc := setupHTTPServerWithCfg(t, true, cfg)
+	setInitCtxSignedInEditor(sc.initCtx)
+	setAccessControlPermissions(sc.acmock, []accesscontrol.Permission{
+		{Action: accesscontrol.ActionAnnotationsCreate, Scope: accesscontrol.ScopeAnnotationsTypeOrganization},
+	}, sc.initCtx.OrgID)
+
+	postEvents := func(t *testing.T, body string) (*savedAnnotationsRepo, dtos.KubernetesAnnotationsResult) {
+		t.Helper()
+		repo := &savedAnnotationsRepo{Repository: annotationstest.NewFakeAnnotationsRepo()}
+		sc.hs.annotationsRepo = repo
+
+		recorder := callAPI(sc.server, http.MethodPost, "/api/annotations/kubernetes", strings.NewReader(body), t)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var result dtos.KubernetesAnnotationsResult
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+		return repo, result
+	}
+
+	t.Run("annotates a core event", func(t *testing.T) {
+		repo, result := postEvents(t, `{
+			"kind": "Event",
+			"metadata": {"uid": "e1", "namespace": "shop"},
+			"involvedObject": {"kind": "Pod", "namespace": "shop", "name": "shop-1", "uid": "p1"},
+			"type": "Warning",
+			"reason": "BackOff",
+			"message": "Back-off restarting failed container",
+			"count": 1,
+			"firstTimestamp": "2022-11-07T08:00:00Z"
+		}`)
+
+		assert.Equal(t, dtos.KubernetesAnnotationsResult{Created: 1, Errors: []dtos.KubernetesAnnotationError{}}, result)
+		require.Len(t, repo.saved, 1)
+		item := repo.saved[0]
+		assert.Equal(t, "Pod shop/shop-1 BackOff: Back-off restarting failed container", item.Text)
+		assert.Equal(t, []string{"kubernetes", "type:Warning", "reason:BackOff", "kind:Pod", "namespace:shop", "pod:shop-1"}, item.Tags)
+		assert.Equal(t, int64(1667808000000), item.Epoch)
+		assert.Equal(t, int64(0), item.DashboardId)
+		assert.Equal(t, "e1", item.Data.Get("eventUid").MustString())
+	})
+
+	t.Run("annotates the events of a list and reports the others", func(t *testing.T) {
+		repo, result := postEvents(t, `{
+			"kind": "EventList",
+			"items": [
+				{"regarding": {"kind": "Node", "name": "node-1"}, "reason": "OOMKilling", "note": "Memory cgroup out of memory", "eventTime": "2022-11-07T08:00:00.123456Z"},
+				{"involvedObject": {"kind": "Pod", "namespace": "shop", "name": "shop-1"}, "reason": "BackOff", "count": 4},
+				{"regarding": {"kind": "Pod", "namespace": "shop", "name": "shop-1"}, "reason": "BackOff", "series": {"count": 2}},
+				{"involvedObject": {"kind": "Pod", "namespace": "kube-system", "name": "dns"}, "reason": "Killing"},
+				{"involvedObject": {"kind": "Deployment", "namespace": "shop", "name": "shop"}, "reason": "ScalingReplicaSet"},
+				{"involvedObject": {"kind": "Pod", "namespace": "shop"}, "reason": "Killing"}
+			]
+		}`)
+
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 4, result.Skipped)
+		assert.Equal(t, []dtos.KubernetesAnnotationError{{Index: 5, Error: "the object the event is about is missing"}}, result.Errors)
+		require.Len(t, repo.saved, 1)
+		assert.Equal(t, "Node node-1 OOMKilling: Memory cgroup out of memory", repo.saved[0].Text)
+		assert.Equal(t, []string{"kubernetes", "oom", "reason:OOMKilling", "kind:Node", "node:node-1"}, repo.saved[0].Tags)
+		assert.Equal(t, int64(1667808000123), repo.saved[0].Epoch)
+	})
+
+	t.Run("is not available unless enabled", func(t *testing.T) {
+		sc := setupHTTPServer(t, true)
+		setInitCtxSignedInEditor(sc.initCtx)
+		r := callAPI(sc.server, http.MethodPost, "/api/annotations/kubernetes", strings.NewReader(`{}`), t)
+		assert.NotEqual(t, http.StatusOK, r.Code)
+	})
+}
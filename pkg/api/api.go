@@ -41,10 +41,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/correlations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/embedtoken"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/org"
 	publicdashboardsapi "github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -156,6 +158,9 @@ func (hs *HTTPServer) registerRoutes() {
 		)
 	}
 
+	// view-only dashboard or panel embedded with an embed token
+	r.Get("/embed/:embedToken", embedtoken.RequireEmbedToken(hs.embedTokenService), hs.Index)
+
 	r.Get("/explore", authorize(func(c *models.ReqContext) {
 		if f, ok := reqSignedIn.(func(c *models.ReqContext)); ok {
 			f(c)
@@ -224,6 +229,12 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Post("/stars/dashboard/uid/:uid", routing.Wrap(hs.StarDashboardByUID))
 			userRoute.Delete("/stars/dashboard/uid/:uid", routing.Wrap(hs.UnstarDashboardByUID))
+			userRoute.Post("/stars/folder/uid/:uid", routing.Wrap(hs.starResource(star.KindFolder)))
+			userRoute.Delete("/stars/folder/uid/:uid", routing.Wrap(hs.unstarResource(star.KindFolder)))
+			userRoute.Post("/stars/datasource/uid/:uid", routing.Wrap(hs.starResource(star.KindDataSource)))
+			userRoute.Delete("/stars/datasource/uid/:uid", routing.Wrap(hs.unstarResource(star.KindDataSource)))
+			userRoute.Post("/stars/alert-rule/uid/:uid", routing.Wrap(hs.starResource(star.KindAlertRule)))
+			userRoute.Delete("/stars/alert-rule/uid/:uid", routing.Wrap(hs.unstarResource(star.KindAlertRule)))
 
 			userRoute.Put("/password", routing.Wrap(hs.ChangeUserPassword))
 			userRoute.Get("/quotas", routing.Wrap(hs.GetUserQuotas))
@@ -263,6 +274,7 @@ func (hs *HTTPServer) registerRoutes() {
 			teamsRoute.Delete("/:teamId/members/:userId", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsPermissionsWrite, ac.ScopeTeamsID)), routing.Wrap(hs.RemoveTeamMember))
 			teamsRoute.Get("/:teamId/preferences", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsRead, ac.ScopeTeamsID)), routing.Wrap(hs.GetTeamPreferences))
 			teamsRoute.Put("/:teamId/preferences", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsWrite, ac.ScopeTeamsID)), routing.Wrap(hs.UpdateTeamPreferences))
+			teamsRoute.Get("/:teamId/usage", authorize(reqCanAccessTeams, ac.EvalPermission(ac.ActionTeamsRead, ac.ScopeTeamsID)), routing.Wrap(hs.GetTeamUsage))
 		})
 
 		// team without requirement of user to be org admin
@@ -315,6 +327,26 @@ func (hs *HTTPServer) registerRoutes() {
 			orgRoute.Get("/preferences", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgPreferences))
 			orgRoute.Put("/preferences", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(hs.UpdateOrgPreferences))
 			orgRoute.Patch("/preferences", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(hs.PatchOrgPreferences))
+
+			// email sender identity
+			orgRoute.Get("/email-sender", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(hs.GetOrgEmailSender))
+			orgRoute.Put("/email-sender", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgEmailSender))
+			orgRoute.Delete("/email-sender", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.DeleteOrgEmailSender))
+
+			// email templates
+			orgRoute.Get("/email-templates", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(hs.GetOrgEmailTemplates))
+			orgRoute.Get("/email-templates/:name", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(hs.GetOrgEmailTemplate))
+			orgRoute.Put("/email-templates/:name", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgEmailTemplate))
+			orgRoute.Delete("/email-templates/:name", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.DeleteOrgEmailTemplate))
+
+			// network policy
+			orgRoute.Get("/network-policy", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(hs.GetOrgNetworkPolicy))
+			orgRoute.Put("/network-policy", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgNetworkPolicy))
+			orgRoute.Get("/network-policy/rejections", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(hs.GetOrgNetworkPolicyRejections))
+
+			if hs.Features.IsEnabled(featuremgmt.FlagOrgLogStreaming) {
+				orgRoute.Get("/logs", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionOrgsLogsRead)), routing.Wrap(hs.GetOrgLogs))
+			}
 		})
 
 		// current org without requirement of user to be org admin
@@ -387,6 +419,9 @@ func (hs *HTTPServer) registerRoutes() {
 			datasourceRoute.Get("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(hs.GetDataSourceByUID))
 			datasourceRoute.Get("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(datasources.ActionRead, nameScope)), routing.Wrap(hs.GetDataSourceByName))
 			datasourceRoute.Get("/id/:name", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionIDRead, nameScope)), routing.Wrap(hs.GetDataSourceIdByName))
+			datasourceRoute.Get("/uid/:uid/credentials", authorize(reqSignedInNoAnonymous, ac.EvalPermission(datasources.ActionQuery, uidScope)), routing.Wrap(hs.GetDataSourceUserCredentials))
+			datasourceRoute.Put("/uid/:uid/credentials", authorize(reqSignedInNoAnonymous, ac.EvalPermission(datasources.ActionQuery, uidScope)), routing.Wrap(hs.SetDataSourceUserCredentials))
+			datasourceRoute.Delete("/uid/:uid/credentials", authorize(reqSignedInNoAnonymous, ac.EvalPermission(datasources.ActionQuery, uidScope)), routing.Wrap(hs.DeleteDataSourceUserCredentials))
 		})
 
 		pluginIDScope := plugins.ScopeProvider.GetResourceScope(ac.Parameter(":pluginId"))
@@ -404,6 +439,10 @@ func (hs *HTTPServer) registerRoutes() {
 			apiRoute.Group("/plugins", func(pluginRoute routing.RouteRegister) {
 				pluginRoute.Post("/:pluginId/install", authorize(reqGrafanaAdmin, ac.EvalPermission(plugins.ActionInstall)), routing.Wrap(hs.InstallPlugin))
 				pluginRoute.Post("/:pluginId/uninstall", authorize(reqGrafanaAdmin, ac.EvalPermission(plugins.ActionInstall)), routing.Wrap(hs.UninstallPlugin))
+				pluginRoute.Post("/:pluginId/rollback", authorize(reqGrafanaAdmin, ac.EvalPermission(plugins.ActionInstall)), routing.Wrap(hs.RollbackPlugin))
+				pluginRoute.Post("/:pluginId/pin", authorize(reqGrafanaAdmin, ac.EvalPermission(plugins.ActionInstall)), routing.Wrap(hs.PinPlugin))
+				pluginRoute.Post("/:pluginId/unpin", authorize(reqGrafanaAdmin, ac.EvalPermission(plugins.ActionInstall)), routing.Wrap(hs.UnpinPlugin))
+				pluginRoute.Get("/:pluginId/install-state", authorize(reqGrafanaAdmin, ac.EvalPermission(plugins.ActionInstall)), routing.Wrap(hs.GetPluginInstallState))
 			})
 		}
 
@@ -466,6 +505,7 @@ func (hs *HTTPServer) registerRoutes() {
 			dashboardRoute.Group("/uid/:uid", func(dashUidRoute routing.RouteRegister) {
 				if hs.ThumbService != nil {
 					dashUidRoute.Get("/img/:kind/:theme", hs.ThumbService.GetImage)
+					dashUidRoute.Get("/thumbnail", hs.ThumbService.GetThumbnail)
 					if hs.Features.IsEnabled(featuremgmt.FlagDashboardPreviewsAdmin) {
 						dashUidRoute.Post("/img/:kind/:theme", reqGrafanaAdmin, hs.ThumbService.SetImage)
 						dashUidRoute.Put("/img/:kind/:theme", reqGrafanaAdmin, hs.ThumbService.UpdateThumbnailState)
@@ -476,10 +516,18 @@ func (hs *HTTPServer) registerRoutes() {
 			dashboardRoute.Post("/calculate-diff", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.CalculateDashboardDiff))
 			dashboardRoute.Post("/validate", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.ValidateDashboard))
 			dashboardRoute.Post("/trim", routing.Wrap(hs.TrimDashboard))
+			dashboardRoute.Post("/move", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsWrite)), routing.Wrap(hs.MoveDashboardsBySelector))
+
+			dashboardRoute.Group("/uid-reservations", func(reservationRoute routing.RouteRegister) {
+				reservationRoute.Get("/", reqOrgAdmin, routing.Wrap(hs.GetUIDReservations))
+				reservationRoute.Post("/", reqOrgAdmin, routing.Wrap(hs.ReserveUID))
+				reservationRoute.Delete("/:uid", reqOrgAdmin, routing.Wrap(hs.DeleteUIDReservation))
+			})
 
 			dashboardRoute.Post("/db", authorize(reqSignedIn, ac.EvalAny(ac.EvalPermission(dashboards.ActionDashboardsCreate), ac.EvalPermission(dashboards.ActionDashboardsWrite))), routing.Wrap(hs.PostDashboard))
 			dashboardRoute.Get("/home", routing.Wrap(hs.GetHomeDashboard))
 			dashboardRoute.Get("/tags", hs.GetDashboardTags)
+			dashboardRoute.Get("/tags/keys", hs.GetDashboardTagKeys)
 
 			// Deprecated: used to convert internal IDs to UIDs
 			dashboardRoute.Get("/ids/:ids", authorize(reqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead)), hs.GetDashboardUIDs)
@@ -517,6 +565,14 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Get("/search/sorting", routing.Wrap(hs.ListSortOptions))
 		apiRoute.Get("/search/", routing.Wrap(hs.Search))
 
+		// resource labels
+		apiRoute.Group("/labels", func(labelRoute routing.RouteRegister) {
+			labelRoute.Get("/:kind", routing.Wrap(hs.FindLabeledResources))
+			labelRoute.Patch("/:kind", reqSignedInNoAnonymous, routing.Wrap(hs.UpdateLabelsBySelector))
+			labelRoute.Get("/:kind/:uid", routing.Wrap(hs.GetResourceLabels))
+			labelRoute.Put("/:kind/:uid", reqSignedInNoAnonymous, routing.Wrap(hs.SetResourceLabels))
+		})
+
 		// metrics
 		// DataSource w/ expressions
 		apiRoute.Post("/ds/query", authorize(reqSignedIn, ac.EvalPermission(datasources.ActionQuery)), routing.Wrap(hs.QueryMetricsV2))
@@ -566,8 +622,13 @@ func (hs *HTTPServer) registerRoutes() {
 			annotationsRoute.Delete("/:annotationId", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsDelete, ac.ScopeAnnotationsID)), routing.Wrap(hs.DeleteAnnotationByID))
 			annotationsRoute.Put("/:annotationId", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsWrite, ac.ScopeAnnotationsID)), routing.Wrap(hs.UpdateAnnotation))
 			annotationsRoute.Patch("/:annotationId", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsWrite, ac.ScopeAnnotationsID)), routing.Wrap(hs.PatchAnnotation))
+			annotationsRoute.Post("/import/csv", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsCreate)), routing.Wrap(hs.ImportAnnotationsCSV))
 			annotationsRoute.Post("/graphite", authorize(reqEditorRole, ac.EvalPermission(ac.ActionAnnotationsCreate, ac.ScopeAnnotationsTypeOrganization)), routing.Wrap(hs.PostGraphiteAnnotation))
+			if hs.Cfg.KubernetesAnnotations.Enabled {
+				annotationsRoute.Post("/kubernetes", authorize(reqEditorRole, ac.EvalPermission(ac.ActionAnnotationsCreate, ac.ScopeAnnotationsTypeOrganization)), routing.Wrap(hs.PostKubernetesAnnotations))
+			}
 			annotationsRoute.Get("/tags", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsRead)), routing.Wrap(hs.GetAnnotationTags))
+			annotationsRoute.Get("/tags/keys", authorize(reqSignedIn, ac.EvalPermission(ac.ActionAnnotationsRead)), routing.Wrap(hs.GetAnnotationTagKeys))
 		})
 
 		apiRoute.Post("/frontend-metrics", routing.Wrap(hs.PostFrontendMetrics))
@@ -576,8 +637,8 @@ func (hs *HTTPServer) registerRoutes() {
 			// the channel path is in the name
 			liveRoute.Post("/publish", routing.Wrap(hs.Live.HandleHTTPPublish))
 
-			// POST influx line protocol.
-			liveRoute.Post("/push/:streamId", hs.LivePushGateway.Handle)
+			// POST influx line protocol. Permissions for the pushed channels are checked by the gateway.
+			liveRoute.Post("/push/:streamId", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionLivePublish)), hs.LivePushGateway.Handle)
 
 			// List available streams and fields
 			liveRoute.Get("/list", routing.Wrap(hs.Live.HandleListHTTP))
@@ -585,9 +646,12 @@ func (hs *HTTPServer) registerRoutes() {
 			// Some channels may have info
 			liveRoute.Get("/info/*", routing.Wrap(hs.Live.HandleInfoHTTP))
 
+			// Catch up on messages published to channels with history
+			liveRoute.Get("/history/*", routing.Wrap(hs.Live.HandleHistoryHTTP))
+
 			if hs.Features.IsEnabled(featuremgmt.FlagLivePipeline) {
 				// POST Live data to be processed according to channel rules.
-				liveRoute.Post("/pipeline/push/*", hs.LivePushGateway.HandlePipelinePush)
+				liveRoute.Post("/pipeline/push/*", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionLivePublish)), hs.LivePushGateway.HandlePipelinePush)
 				liveRoute.Post("/pipeline-convert-test", routing.Wrap(hs.Live.HandlePipelineConvertTestHTTP), reqOrgAdmin)
 				liveRoute.Get("/pipeline-entities", routing.Wrap(hs.Live.HandlePipelineEntitiesListHTTP), reqOrgAdmin)
 				liveRoute.Get("/channel-rules", routing.Wrap(hs.Live.HandleChannelRulesListHTTP), reqOrgAdmin)
@@ -602,7 +666,12 @@ func (hs *HTTPServer) registerRoutes() {
 		})
 
 		// short urls
-		apiRoute.Post("/short-urls", routing.Wrap(hs.createShortURL))
+		apiRoute.Group("/short-urls", func(shortURLRoute routing.RouteRegister) {
+			shortURLRoute.Post("/", routing.Wrap(hs.createShortURL))
+			shortURLRoute.Get("/", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionShortURLsRead)), routing.Wrap(hs.searchShortURLs))
+			shortURLRoute.Patch("/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionShortURLsWrite)), routing.Wrap(hs.updateShortURL))
+			shortURLRoute.Delete("/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionShortURLsWrite)), routing.Wrap(hs.deleteShortURL))
+		})
 
 		apiRoute.Group("/comments", func(commentRoute routing.RouteRegister) {
 			commentRoute.Post("/get", routing.Wrap(hs.commentsGet))
@@ -613,10 +682,18 @@ func (hs *HTTPServer) registerRoutes() {
 	// admin api
 	r.Group("/api/admin", func(adminRoute routing.RouteRegister) {
 		adminRoute.Get("/settings", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetSettings))
+		adminRoute.Post("/settings/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsWrite, ac.ScopeSettingsAll)), routing.Wrap(hs.AdminReloadSettings))
+		adminRoute.Get("/security-headers", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetSecurityHeaders))
+		adminRoute.Put("/security-headers", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsWrite, ac.ScopeSettingsAll)), routing.Wrap(hs.AdminUpdateSecurityHeaders))
+		adminRoute.Delete("/security-headers", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsWrite, ac.ScopeSettingsAll)), routing.Wrap(hs.AdminResetSecurityHeaders))
+		adminRoute.Get("/security-headers/orgs/:orgId", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetOrgSecurityHeaders))
+		adminRoute.Put("/security-headers/orgs/:orgId", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsWrite, ac.ScopeSettingsAll)), routing.Wrap(hs.AdminUpdateOrgSecurityHeaders))
 		if hs.Features.IsEnabled(featuremgmt.FlagShowFeatureFlagsInUI) {
 			adminRoute.Get("/settings/features", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), hs.Features.HandleGetSettings)
 		}
 		adminRoute.Get("/stats", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetStats))
+		adminRoute.Get("/stats/orgs", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetOrgStats))
+		adminRoute.Get("/stats/age", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetResourceAgeStats))
 		adminRoute.Post("/pause-all-alerts", reqGrafanaAdmin, routing.Wrap(hs.PauseAllAlerts(setting.AlertingEnabled)))
 
 		if hs.ThumbService != nil && hs.Features.IsEnabled(featuremgmt.FlagDashboardPreviewsAdmin) {
@@ -639,12 +716,21 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/encryption/migrate-secrets/to-plugin", reqGrafanaAdmin, routing.Wrap(hs.AdminMigrateSecretsToPlugin))
 		adminRoute.Post("/encryption/migrate-secrets/from-plugin", reqGrafanaAdmin, routing.Wrap(hs.AdminMigrateSecretsFromPlugin))
 		adminRoute.Post("/encryption/delete-secretsmanagerplugin-secrets", reqGrafanaAdmin, routing.Wrap(hs.AdminDeleteAllSecretsManagerPluginSecrets))
+		adminRoute.Get("/encryption/legacy-plugin-secrets", reqGrafanaAdmin, routing.Wrap(hs.AdminGetLegacyPluginSecrets))
+		adminRoute.Post("/secrets/rotate", reqGrafanaAdmin, routing.Wrap(hs.AdminRotateSecrets))
+		adminRoute.Get("/secrets/rotate", reqGrafanaAdmin, routing.Wrap(hs.AdminGetSecretsRotationStatus))
+		adminRoute.Get("/secrets/inventory", reqGrafanaAdmin, routing.Wrap(hs.AdminGetSecretsInventory))
+
+		adminRoute.Post("/emails/test", reqGrafanaAdmin, routing.Wrap(hs.AdminSendTestEmail))
+		adminRoute.Get("/emails/deliveries", reqGrafanaAdmin, routing.Wrap(hs.AdminGetEmailDeliveries))
 
 		adminRoute.Post("/provisioning/dashboards/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards)), routing.Wrap(hs.AdminProvisioningReloadDashboards))
 		adminRoute.Post("/provisioning/plugins/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/datasources/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources)), routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersNotifications)), routing.Wrap(hs.AdminProvisioningReloadNotifications))
 		adminRoute.Post("/provisioning/alerting/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAlertRules)), routing.Wrap(hs.AdminProvisioningReloadAlerting))
+		adminRoute.Post("/provisioning/access-control/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAccessControl)), routing.Wrap(hs.AdminProvisioningReloadAccessControl))
+		adminRoute.Get("/provisioning/status", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningRead, ScopeProvisionersAll)), routing.Wrap(hs.AdminProvisioningGetStatus))
 
 		adminRoute.Post("/ldap/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPConfigReload)), routing.Wrap(hs.ReloadLDAPCfg))
 		adminRoute.Post("/ldap/sync/:id", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(hs.PostSyncUserWithLDAP))
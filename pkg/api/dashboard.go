@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/resourceversion"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -21,14 +22,18 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/services/dashboardreview"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/editlock"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -176,6 +181,7 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		UpdatedBy:              updater,
 		CreatedBy:              creator,
 		Version:                dash.Version,
+		ResourceVersion:        resourceversion.Format(int64(dash.Version)),
 		HasACL:                 dash.HasACL,
 		IsFolder:               dash.IsFolder,
 		FolderId:               dash.FolderId,
@@ -200,6 +206,21 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		meta.FolderUrl = query.Result.GetUrl()
 	}
 
+	if hs.editLocks != nil && !dash.IsFolder {
+		lock, err := hs.editLocks.GetLock(c.Req.Context(), c.OrgID, editlock.KindDashboard, dash.Uid)
+		if err == nil {
+			meta.EditLock = &dtos.DashboardEditLock{
+				UserID:   lock.UserID,
+				Login:    lock.Login,
+				Name:     lock.Name,
+				Acquired: lock.Acquired,
+				Expires:  lock.Expires,
+			}
+		} else if !errors.Is(err, editlock.ErrLockNotFound) {
+			hs.log.Warn("Failed to get edit lock of dashboard", "dashboardUid", dash.Uid, "error", err)
+		}
+	}
+
 	provisioningData, err := hs.dashboardProvisioningService.GetProvisionedDashboardDataByDashboardID(c.Req.Context(), dash.Id)
 	if err != nil {
 		return response.Error(500, "Error while checking if dashboard is provisioned", err)
@@ -237,7 +258,7 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 	}
 
 	c.TimeRequest(metrics.MApiDashboardGet)
-	return response.JSON(http.StatusOK, dto)
+	return response.JSON(http.StatusOK, dto).SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(dash.Version)))
 }
 
 func (hs *HTTPServer) getAnnotationPermissionsByScope(c *models.ReqContext, actions *dtos.AnnotationActions, scope string) {
@@ -312,6 +333,9 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 	if canDelete, err := guardian.CanDelete(); err != nil || !canDelete {
 		return dashboardGuardianResponse(err)
 	}
+	if err := resourceversion.Check(c.Req, int64(dash.Version)); err != nil {
+		return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+	}
 
 	// disconnect all library elements for this dashboard
 	err := hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.Id)
@@ -410,14 +434,51 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	}
 
 	dash := cmd.GetDashboardModel()
+	if resourceversion.IsConditional(c.Req) {
+		if rsp := hs.checkDashboardResourceVersion(c, dash); rsp != nil {
+			return rsp
+		}
+		// Saving fails with a version mismatch if the dashboard is changed after the check
+		cmd.Overwrite = false
+	}
 	newDashboard := dash.Id == 0
+	// The quota is checked here rather than by the quota middleware, since the route both creates
+	// and updates dashboards and only new dashboards count against the quota.
+	var quotaStatus *quota.Status
 	if newDashboard {
-		limitReached, err := hs.QuotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
+		status, err := hs.QuotaService.Check(c, dashboards.QuotaTargetSrv)
 		if err != nil {
 			return response.Error(500, "failed to get quota", err)
 		}
-		if limitReached {
-			return response.Error(403, "Quota reached", nil)
+		if status.Reached {
+			return response.Err(status.Err())
+		}
+		quotaStatus = status
+	}
+
+	if hs.dashboardReviews != nil && !dash.IsFolder {
+		if err := hs.dashboardReviews.CheckDirectSave(ctx, dash); err != nil {
+			if errors.Is(err, dashboardreview.ErrReviewRequired) {
+				return response.JSON(http.StatusForbidden, util.DynMap{
+					"status":  "review-required",
+					"message": err.Error(),
+				})
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to check review policy", err)
+		}
+	}
+
+	if hs.dashboardLintService != nil && !dash.IsFolder {
+		if err := hs.dashboardLintService.CheckOnSave(ctx, c.OrgID, dash.Data); err != nil {
+			var rejected *dashboardlint.RejectedError
+			if errors.As(err, &rejected) {
+				return response.JSON(http.StatusUnprocessableEntity, util.DynMap{
+					"status":  "lint-failed",
+					"message": rejected.Error(),
+					"report":  rejected.Report,
+				})
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to lint dashboard", err)
 		}
 	}
 
@@ -478,6 +539,9 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	if err != nil {
 		return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, err)
 	}
+	if quotaStatus != nil && quotaStatus.AddUsage != nil {
+		quotaStatus.AddUsage()
+	}
 
 	// Clear permission cache for the user who's created the dashboard, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
@@ -493,13 +557,34 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
-		"status":  "success",
-		"slug":    dashboard.Slug,
-		"version": dashboard.Version,
-		"id":      dashboard.Id,
-		"uid":     dashboard.Uid,
-		"url":     dashboard.GetUrl(),
-	})
+		"status":          "success",
+		"slug":            dashboard.Slug,
+		"version":         dashboard.Version,
+		"resourceVersion": resourceversion.Format(int64(dashboard.Version)),
+		"id":              dashboard.Id,
+		"uid":             dashboard.Uid,
+		"url":             dashboard.GetUrl(),
+	}).SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(dashboard.Version)))
+}
+
+// checkDashboardResourceVersion checks the If-Match header of a save request against the stored
+// version of the dashboard, and sets the version of the dashboard to save to it.
+func (hs *HTTPServer) checkDashboardResourceVersion(c *models.ReqContext, dash *models.Dashboard) response.Response {
+	query := models.GetDashboardQuery{Id: dash.Id, Uid: dash.Uid, OrgId: c.OrgID}
+	err := hs.DashboardService.GetDashboard(c.Req.Context(), &query)
+	switch {
+	case errors.Is(err, dashboards.ErrDashboardNotFound), errors.Is(err, dashboards.ErrDashboardIdentifierNotSet):
+		err = resourceversion.ErrMismatch
+	case err != nil:
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	default:
+		err = resourceversion.Check(c.Req, int64(query.Result.Version))
+	}
+	if err != nil {
+		return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+	}
+	dash.SetVersion(query.Result.Version)
+	return nil
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -519,6 +604,10 @@ func (hs *HTTPServer) GetHomeDashboard(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to get preferences", err)
 	}
 
+	if redirect, ok := hs.homeDashboardRuleRedirect(c); ok {
+		return response.JSON(http.StatusOK, &redirect)
+	}
+
 	if preference.HomeDashboardID == 0 && len(homePage) > 0 {
 		homePageRedirect := dtos.DashboardRedirect{RedirectUri: homePage}
 		return response.JSON(http.StatusOK, &homePageRedirect)
@@ -568,6 +657,44 @@ func (hs *HTTPServer) GetHomeDashboard(c *models.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, &dash)
 }
 
+// homeDashboardRuleRedirect redirects to the home dashboard of the first home dashboard rule of the
+// organization matching the user, unless the user chose a home dashboard in their preferences.
+func (hs *HTTPServer) homeDashboardRuleRedirect(c *models.ReqContext) (dtos.DashboardRedirect, bool) {
+	if hs.homeRouting == nil {
+		return dtos.DashboardRedirect{}, false
+	}
+	ctx := c.Req.Context()
+
+	// the preferences of the organization are returned for the user 0 of anonymous users
+	if c.SignedInUser.UserID != 0 {
+		userPrefs, err := hs.preferenceService.Get(ctx, &pref.GetPreferenceQuery{OrgID: c.OrgID, UserID: c.SignedInUser.UserID})
+		if err != nil {
+			hs.log.Warn("Failed to get user preferences", "err", err)
+			return dtos.DashboardRedirect{}, false
+		}
+		if userPrefs.HomeDashboardID != 0 {
+			return dtos.DashboardRedirect{}, false
+		}
+	}
+
+	rule, err := hs.homeRouting.Resolve(ctx, c.SignedInUser)
+	if err != nil {
+		hs.log.Warn("Failed to resolve home dashboard rules", "err", err)
+		return dtos.DashboardRedirect{}, false
+	}
+	if rule == nil {
+		return dtos.DashboardRedirect{}, false
+	}
+
+	query := models.GetDashboardQuery{Uid: rule.DashboardUID, OrgId: c.OrgID}
+	if err := hs.DashboardService.GetDashboard(ctx, &query); err != nil {
+		// e.g. the dashboard was deleted since the rule was saved
+		hs.log.Warn("Failed to get the dashboard of the home dashboard rule", "dashboardUid", rule.DashboardUID, "err", err)
+		return dtos.DashboardRedirect{}, false
+	}
+	return dtos.DashboardRedirect{RedirectUri: models.GetDashboardUrl(query.Result.Uid, query.Result.Slug)}, true
+}
+
 func (hs *HTTPServer) addGettingStartedPanelToHomeDashboard(c *models.ReqContext, dash *simplejson.Json) {
 	// We only add this getting started panel for Admins who have not dismissed it,
 	// and if a custom default home dashboard hasn't been configured
@@ -1001,7 +1128,7 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext) response.Res
 // 401: unauthorisedError
 // 500: internalServerError
 func (hs *HTTPServer) GetDashboardTags(c *models.ReqContext) {
-	query := models.GetDashboardTagsQuery{OrgId: c.OrgID}
+	query := models.GetDashboardTagsQuery{OrgId: c.OrgID, Key: c.Query("key")}
 	err := hs.DashboardService.GetDashboardTags(c.Req.Context(), &query)
 	if err != nil {
 		c.JsonApiErr(500, "Failed to get tags from database", err)
@@ -1011,6 +1138,27 @@ func (hs *HTTPServer) GetDashboardTags(c *models.ReqContext) {
 	c.JSON(http.StatusOK, query.Result)
 }
 
+// swagger:route GET /dashboards/tags/keys dashboards getDashboardTagKeys
+//
+// Get the keys of the namespaced dashboard tags of an organisation.
+//
+// Lists the keys of the `key:value` tags with the number of dashboards using them.
+//
+// Responses:
+// 200: getDashboardTagKeysResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardTagKeys(c *models.ReqContext) {
+	query := models.GetDashboardTagKeysQuery{OrgId: c.OrgID, Prefix: c.Query("prefix")}
+	err := hs.DashboardService.GetDashboardTagKeys(c.Req.Context(), &query)
+	if err != nil {
+		c.JsonApiErr(500, "Failed to get tag keys from database", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, query.Result)
+}
+
 // GetDashboardUIDs converts internal ids to UIDs
 func (hs *HTTPServer) GetDashboardUIDs(c *models.ReqContext) {
 	ids := strings.Split(web.Params(c.Req)[":ids"], ",")
@@ -1235,6 +1383,28 @@ type DashboardsTagsResponse struct {
 	Body []*models.DashboardTagCloudItem `json:"body"`
 }
 
+// swagger:parameters getDashboardTags
+type GetDashboardTagsParams struct {
+	// Only return the tags with this key, e.g. `env` for `env:prod` and `env:dev`.
+	// in:query
+	// required:false
+	Key string `json:"key"`
+}
+
+// swagger:parameters getDashboardTagKeys
+type GetDashboardTagKeysParams struct {
+	// Only return the keys starting with this prefix.
+	// in:query
+	// required:false
+	Prefix string `json:"prefix"`
+}
+
+// swagger:response getDashboardTagKeysResponse
+type DashboardTagKeysResponse struct {
+	// in: body
+	Body []*models.DashboardTagKeyItem `json:"body"`
+}
+
 // Get home dashboard response.
 // swagger:model GetHomeDashboardResponse
 type GetHomeDashboardResponseBody struct {
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /dashboards/uid-reservations dashboards getUIDReservations
+//
+// Get the UID reservations of the current organization.
+//
+// Every reservation reports the dashboard or folder conflicting with it, which uses the UID without
+// being owned by the provisioner the UID is reserved for.
+//
+// Responses:
+// 200: getUIDReservationsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetUIDReservations(c *models.ReqContext) response.Response {
+	reservations, err := hs.dashboardProvisioningService.GetUIDReservations(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get uid reservations", err)
+	}
+	return response.JSON(http.StatusOK, reservations)
+}
+
+// swagger:route POST /dashboards/uid-reservations dashboards reserveUID
+//
+// Reserve the UID of a dashboard or folder for a provisioner.
+//
+// Only the provisioner can save a dashboard or folder with a reserved UID, users and other
+// provisioners can't. A UID used by a dashboard which isn't provisioned by the provisioner can't be
+// reserved for it.
+//
+// Responses:
+// 200: uidReservationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 412: preconditionFailedError
+// 500: internalServerError
+func (hs *HTTPServer) ReserveUID(c *models.ReqContext) response.Response {
+	cmd := dashboards.ReserveUIDCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.CreatedBy = c.UserID
+
+	reservation, err := hs.dashboardProvisioningService.ReserveUID(c.Req.Context(), &cmd)
+	if err != nil {
+		return uidReservationErrorResponse(err, "Failed to reserve uid")
+	}
+	return response.JSON(http.StatusOK, reservation)
+}
+
+// swagger:route DELETE /dashboards/uid-reservations/{uid} dashboards deleteUIDReservation
+//
+// Release the reservation of a UID.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteUIDReservation(c *models.ReqContext) response.Response {
+	err := hs.dashboardProvisioningService.DeleteUIDReservation(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return uidReservationErrorResponse(err, "Failed to delete uid reservation")
+	}
+	return response.Success("UID reservation deleted")
+}
+
+// uidReservationErrorResponse returns the dashboard errors with the details of the conflicts.
+func uidReservationErrorResponse(err error, message string) response.Response {
+	var dashboardErr dashboards.DashboardErr
+	if errors.As(err, &dashboardErr) {
+		return response.Error(dashboardErr.StatusCode, err.Error(), nil)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters reserveUID
+type ReserveUIDParams struct {
+	// in:body
+	// required:true
+	Body dashboards.ReserveUIDCommand
+}
+
+// swagger:parameters deleteUIDReservation
+type DeleteUIDReservationParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response getUIDReservationsResponse
+type GetUIDReservationsResponse struct {
+	// in: body
+	Body []*dashboards.UIDReservation `json:"body"`
+}
+
+// swagger:response uidReservationResponse
+type UIDReservationResponse struct {
+	// in: body
+	Body *dashboards.UIDReservation `json:"body"`
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /datasources/uid/{uid}/credentials datasources getDataSourceUserCredentials
+//
+// Get the credentials the signed in user supplied for a data source.
+//
+// Only available for data sources using per-user credentials. The password is not returned.
+//
+// Responses:
+// 200: getDataSourceUserCredentialsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDataSourceUserCredentials(c *models.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgID)
+	if err != nil {
+		return userCredentialsErrorResponse(err)
+	}
+	credentials, err := hs.DataSourcesService.GetUserCredentials(c.Req.Context(), ds, c.UserID)
+	if err != nil {
+		return userCredentialsErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, dtos.DataSourceUserCredentials{User: credentials.User, Updated: credentials.Updated})
+}
+
+// swagger:route PUT /datasources/uid/{uid}/credentials datasources setDataSourceUserCredentials
+//
+// Set the credentials of the signed in user for a data source.
+//
+// Data sources using per-user credentials, which is enabled with `jsonData.perUserCredentials`, query with the credentials each user supplied. They replace the user and password of the data source, and the basic authentication user and password if basic authentication is enabled. The credentials are kept in the secrets store.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) SetDataSourceUserCredentials(c *models.ReqContext) response.Response {
+	cmd := datasources.SetUserCredentialsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.User == "" && cmd.Password == "" {
+		return response.Error(http.StatusBadRequest, "User or password is required", nil)
+	}
+
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgID)
+	if err != nil {
+		return userCredentialsErrorResponse(err)
+	}
+	cmd.DataSource = ds
+	cmd.UserID = c.UserID
+	if err := hs.DataSourcesService.SetUserCredentials(c.Req.Context(), &cmd); err != nil {
+		return userCredentialsErrorResponse(err)
+	}
+	return response.Success("Data source credentials saved")
+}
+
+// swagger:route DELETE /datasources/uid/{uid}/credentials datasources deleteDataSourceUserCredentials
+//
+// Delete the credentials of the signed in user for a data source.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteDataSourceUserCredentials(c *models.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgID)
+	if err != nil {
+		return userCredentialsErrorResponse(err)
+	}
+	if err := hs.DataSourcesService.DeleteUserCredentials(c.Req.Context(), ds, c.UserID); err != nil {
+		return userCredentialsErrorResponse(err)
+	}
+	return response.Success("Data source credentials deleted")
+}
+
+func userCredentialsErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, datasources.ErrDataSourceNotFound):
+		return response.Error(http.StatusNotFound, "Data source not found", err)
+	case errors.Is(err, datasources.ErrUserCredentialsNotFound):
+		return response.Error(http.StatusNotFound, "Data source credentials not found", err)
+	case errors.Is(err, datasources.ErrUserCredentialsNotSupported):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process data source credentials", err)
+}
+
+// swagger:parameters getDataSourceUserCredentials deleteDataSourceUserCredentials
+type DataSourceUserCredentialsParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"uid"`
+}
+
+// swagger:parameters setDataSourceUserCredentials
+type SetDataSourceUserCredentialsParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"uid"`
+	// in:body
+	// required:true
+	Body datasources.SetUserCredentialsCommand `json:"body"`
+}
+
+// swagger:response getDataSourceUserCredentialsResponse
+type GetDataSourceUserCredentialsResponse struct {
+	// in:body
+	Body dtos.DataSourceUserCredentials `json:"body"`
+}
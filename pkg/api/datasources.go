@@ -14,6 +14,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/datasource"
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/resourceversion"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -132,7 +133,7 @@ func (hs *HTTPServer) GetDataSourceById(c *models.ReqContext) response.Response
 	// Add accesscontrol metadata
 	dto.AccessControl = hs.getAccessControlMetadata(c, c.OrgID, datasources.ScopePrefix, dto.UID)
 
-	return response.JSON(http.StatusOK, &dto)
+	return response.JSON(http.StatusOK, &dto).SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(dto.Version)))
 }
 
 // swagger:route DELETE /datasources/{id} datasources deleteDataSourceByID
@@ -151,6 +152,7 @@ func (hs *HTTPServer) GetDataSourceById(c *models.ReqContext) response.Response
 // 401: unauthorisedError
 // 404: notFoundError
 // 403: forbiddenError
+// 409: conflictError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Response {
 	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
@@ -173,6 +175,12 @@ func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Respon
 	if ds.ReadOnly {
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
+	if err := resourceversion.Check(c.Req, int64(ds.Version)); err != nil {
+		return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+	}
+	if resp := hs.checkDataSourceReferences(c, ds); resp != nil {
+		return resp
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{ID: id, OrgID: c.OrgID, Name: ds.Name}
 
@@ -218,7 +226,7 @@ func (hs *HTTPServer) GetDataSourceByUID(c *models.ReqContext) response.Response
 	// Add accesscontrol metadata
 	dto.AccessControl = hs.getAccessControlMetadata(c, c.OrgID, datasources.ScopePrefix, dto.UID)
 
-	return response.JSON(http.StatusOK, &dto)
+	return response.JSON(http.StatusOK, &dto).SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(dto.Version)))
 }
 
 // swagger:route DELETE /datasources/uid/{uid} datasources deleteDataSourceByUID
@@ -233,6 +241,7 @@ func (hs *HTTPServer) GetDataSourceByUID(c *models.ReqContext) response.Response
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 409: conflictError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Response {
 	uid := web.Params(c.Req)[":uid"]
@@ -252,6 +261,12 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 	if ds.ReadOnly {
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
+	if err := resourceversion.Check(c.Req, int64(ds.Version)); err != nil {
+		return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+	}
+	if resp := hs.checkDataSourceReferences(c, ds); resp != nil {
+		return resp
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{UID: uid, OrgID: c.OrgID, Name: ds.Name}
 
@@ -283,6 +298,7 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 409: conflictError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Response {
 	name := web.Params(c.Req)[":name"]
@@ -302,6 +318,12 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 	if getCmd.Result.ReadOnly {
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
+	if err := resourceversion.Check(c.Req, int64(getCmd.Result.Version)); err != nil {
+		return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+	}
+	if resp := hs.checkDataSourceReferences(c, getCmd.Result); resp != nil {
+		return resp
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{Name: name, OrgID: c.OrgID}
 	err := hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
@@ -320,6 +342,29 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 	})
 }
 
+// checkDataSourceReferences refuses to delete a data source which is still queried by dashboards or
+// alert rules, unless the deletion is forced with force=true. It returns nil if the data source can
+// be deleted.
+func (hs *HTTPServer) checkDataSourceReferences(c *models.ReqContext, ds *datasources.DataSource) response.Response {
+	if hs.dataSourceUsage == nil || c.QueryBool("force") {
+		return nil
+	}
+	usage, err := hs.dataSourceUsage.GetDataSourceUsage(c.Req.Context(), c.OrgID, ds.Uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the references of the data source", err)
+	}
+	if len(usage.Dashboards) == 0 && len(usage.AlertRules) == 0 {
+		return nil
+	}
+	return response.JSON(http.StatusConflict, util.DynMap{
+		"status": "referenced",
+		"message": fmt.Sprintf("Data source is used by %d dashboard(s) and %d alert rule(s), use force=true to delete it anyway",
+			len(usage.Dashboards), len(usage.AlertRules)),
+		"dashboards": usage.Dashboards,
+		"alertRules": usage.AlertRules,
+	})
+}
+
 func validateURL(cmdType string, url string) response.Response {
 	if _, err := datasource.ValidateURL(cmdType, url); err != nil {
 		datasourcesLogger.Error("Failed to validate URL", "url", url)
@@ -505,10 +550,21 @@ func (hs *HTTPServer) updateDataSourceByID(c *models.ReqContext, ds *datasources
 	if ds.ReadOnly {
 		return response.Error(403, "Cannot update read-only data source", nil)
 	}
+	conditional := resourceversion.IsConditional(c.Req)
+	if conditional {
+		if err := resourceversion.Check(c.Req, int64(ds.Version)); err != nil {
+			return response.Error(http.StatusPreconditionFailed, err.Error(), err)
+		}
+		// Updating fails with ErrDataSourceUpdatingOldVersion if the data source is changed after the check
+		cmd.Version = ds.Version
+	}
 
 	err := hs.DataSourcesService.UpdateDataSource(c.Req.Context(), &cmd)
 	if err != nil {
 		if errors.Is(err, datasources.ErrDataSourceUpdatingOldVersion) {
+			if conditional {
+				return response.Error(http.StatusPreconditionFailed, resourceversion.ErrMismatch.Error(), resourceversion.ErrMismatch)
+			}
 			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err)
 		}
 
@@ -539,7 +595,7 @@ func (hs *HTTPServer) updateDataSourceByID(c *models.ReqContext, ds *datasources
 		"id":         cmd.Id,
 		"name":       cmd.Name,
 		"datasource": datasourceDTO,
-	})
+	}).SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(datasourceDTO.Version)))
 }
 
 func (hs *HTTPServer) getRawDataSourceById(ctx context.Context, id int64, orgID int64) (*datasources.DataSource, error) {
@@ -591,7 +647,7 @@ func (hs *HTTPServer) GetDataSourceByName(c *models.ReqContext) response.Respons
 	}
 
 	dto := hs.convertModelToDtos(c.Req.Context(), query.Result)
-	return response.JSON(http.StatusOK, &dto)
+	return response.JSON(http.StatusOK, &dto).SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(dto.Version)))
 }
 
 // swagger:route GET /datasources/id/{name} datasources getDataSourceIdByName
@@ -652,6 +708,10 @@ func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
 			c.JsonApiErr(403, "Access denied to datasource", err)
 			return
 		}
+		if errors.Is(err, datasources.ErrUserCredentialsNotFound) {
+			c.JsonApiErr(http.StatusForbidden, "Credentials of the user are required to query the data source", err)
+			return
+		}
 		c.JsonApiErr(500, "Unable to load datasource meta data", err)
 		return
 	}
@@ -689,6 +749,10 @@ func (hs *HTTPServer) CallDatasourceResourceWithUID(c *models.ReqContext) {
 			c.JsonApiErr(http.StatusForbidden, "Access denied to datasource", err)
 			return
 		}
+		if errors.Is(err, datasources.ErrUserCredentialsNotFound) {
+			c.JsonApiErr(http.StatusForbidden, "Credentials of the user are required to query the data source", err)
+			return
+		}
 		c.JsonApiErr(http.StatusInternalServerError, "Unable to load datasource meta data", err)
 		return
 	}
@@ -720,6 +784,7 @@ func (hs *HTTPServer) convertModelToDtos(ctx context.Context, ds *datasources.Da
 		JsonData:         ds.JsonData,
 		SecureJsonFields: map[string]bool{},
 		Version:          ds.Version,
+		ResourceVersion:  resourceversion.Format(int64(ds.Version)),
 		ReadOnly:         ds.ReadOnly,
 	}
 
@@ -758,6 +823,9 @@ func (hs *HTTPServer) CheckDatasourceHealthWithUID(c *models.ReqContext) respons
 		if errors.Is(err, datasources.ErrDataSourceAccessDenied) {
 			return response.Error(http.StatusForbidden, "Access denied to datasource", err)
 		}
+		if errors.Is(err, datasources.ErrUserCredentialsNotFound) {
+			return response.Error(http.StatusForbidden, "Credentials of the user are required to query the data source", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Unable to load datasource metadata", err)
 	}
 	return hs.checkDatasourceHealth(c, ds)
@@ -788,6 +856,9 @@ func (hs *HTTPServer) CheckDatasourceHealth(c *models.ReqContext) response.Respo
 		if errors.Is(err, datasources.ErrDataSourceAccessDenied) {
 			return response.Error(http.StatusForbidden, "Access denied to datasource", err)
 		}
+		if errors.Is(err, datasources.ErrUserCredentialsNotFound) {
+			return response.Error(http.StatusForbidden, "Credentials of the user are required to query the data source", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Unable to load datasource metadata", err)
 	}
 	return hs.checkDatasourceHealth(c, ds)
@@ -908,6 +979,10 @@ type DeleteDataSourceByIDParams struct {
 	// in:path
 	// required:true
 	DatasourceID string `json:"id"`
+	// Delete the data source even if dashboards or alert rules still use it.
+	// in:query
+	// required:false
+	Force bool `json:"force"`
 }
 
 // swagger:parameters getDataSourceByID
@@ -936,6 +1011,10 @@ type DeleteDataSourceByUIDParams struct {
 	// in:path
 	// required:true
 	DatasourceUID string `json:"uid"`
+	// Delete the data source even if dashboards or alert rules still use it.
+	// in:query
+	// required:false
+	Force bool `json:"force"`
 }
 
 // swagger:parameters getDataSourceByUID
@@ -957,6 +1036,10 @@ type DeleteDataSourceByNameParams struct {
 	// in:path
 	// required:true
 	DatasourceName string `json:"name"`
+	// Delete the data source even if dashboards or alert rules still use it.
+	// in:query
+	// required:false
+	Force bool `json:"force"`
 }
 
 // swagger:parameters getDataSourceIdByName
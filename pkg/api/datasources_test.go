@@ -23,6 +23,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	"github.com/grafana/grafana/pkg/services/datasourceusage"
+	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -250,6 +252,90 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 	assert.Equal(t, 200, sc.resp.Code)
 }
 
+func TestUpdateDataSourceByUID_IfMatch(t *testing.T) {
+	hs := &HTTPServer{
+		DataSourcesService: &dataSourcesServiceMock{
+			expectedDatasource: &datasources.DataSource{Id: 1, Uid: "test", Name: "Test", Version: 2},
+		},
+		Cfg:                  setting.NewCfg(),
+		AccessControl:        acimpl.ProvideAccessControl(setting.NewCfg()),
+		accesscontrolService: actest.FakeService{},
+		Live:                 &live.GrafanaLive{},
+	}
+	sc := setupScenarioContext(t, "/api/datasources/uid/test")
+	sc.m.Put("/api/datasources/uid/:uid", routing.Wrap(func(c *models.ReqContext) response.Response {
+		c.Req.Body = mockRequestBody(datasources.UpdateDataSourceCommand{
+			Name:   "Test",
+			Url:    "http://localhost:5432",
+			Access: "proxy",
+			Type:   "test",
+		})
+		return hs.UpdateDataSourceByUID(c)
+	}))
+
+	for _, tc := range []struct {
+		ifMatch string
+		status  int
+	}{
+		{ifMatch: `"1"`, status: http.StatusPreconditionFailed},
+		{ifMatch: `"2"`, status: http.StatusOK},
+		{ifMatch: "*", status: http.StatusOK},
+	} {
+		sc.fakeReqWithParams("PUT", sc.url, map[string]string{})
+		sc.req.Header.Set("If-Match", tc.ifMatch)
+		sc.exec()
+
+		assert.Equal(t, tc.status, sc.resp.Code, tc.ifMatch)
+		if tc.status == http.StatusOK {
+			assert.Equal(t, `"2"`, sc.resp.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestDeleteDataSourceByUID_References(t *testing.T) {
+	usage := &dataSourceUsageMock{usage: &datasourceusage.Usage{
+		UID:        "test",
+		Dashboards: []datasourceusage.Resource{{UID: "overview", Title: "Overview"}},
+		AlertRules: []datasourceusage.Resource{},
+	}}
+	hs := &HTTPServer{
+		DataSourcesService: &dataSourcesServiceMock{
+			expectedDatasource: &datasources.DataSource{Id: 1, Uid: "test", Name: "Test"},
+		},
+		Cfg:             setting.NewCfg(),
+		Live:            &live.GrafanaLive{},
+		dataSourceUsage: usage,
+	}
+	sc := setupScenarioContext(t, "/api/datasources/uid/test")
+	sc.m.Delete("/api/datasources/uid/:uid", routing.Wrap(hs.DeleteDataSourceByUID))
+
+	t.Run("referenced data source is not deleted", func(t *testing.T) {
+		sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+		require.Equal(t, http.StatusConflict, sc.resp.Code)
+
+		var body struct {
+			Status     string                     `json:"status"`
+			Dashboards []datasourceusage.Resource `json:"dashboards"`
+			AlertRules []datasourceusage.Resource `json:"alertRules"`
+		}
+		require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&body))
+		assert.Equal(t, "referenced", body.Status)
+		assert.Equal(t, usage.usage.Dashboards, body.Dashboards)
+		assert.Empty(t, body.AlertRules)
+	})
+
+	t.Run("referenced data source is deleted with force", func(t *testing.T) {
+		sc.fakeReqWithParams("DELETE", sc.url, map[string]string{"force": "true"}).exec()
+		assert.Equal(t, http.StatusOK, sc.resp.Code)
+	})
+
+	t.Run("unused data source is deleted", func(t *testing.T) {
+		usage.usage = &datasourceusage.Usage{UID: "test", LibraryPanels: []datasourceusage.Resource{{UID: "errors", Title: "Errors"}}}
+		sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+		assert.Equal(t, http.StatusOK, sc.resp.Code)
+	})
+}
+
 func TestAPI_Datasources_AccessControl(t *testing.T) {
 	testDatasource := datasources.DataSource{
 		Id:     3,
@@ -617,6 +703,16 @@ func TestAPI_Datasources_AccessControl(t *testing.T) {
 	}
 }
 
+type dataSourceUsageMock struct {
+	datasourceusage.Service
+
+	usage *datasourceusage.Usage
+}
+
+func (m *dataSourceUsageMock) GetDataSourceUsage(ctx context.Context, orgID int64, uid string) (*datasourceusage.Usage, error) {
+	return m.usage, nil
+}
+
 type dataSourcesServiceMock struct {
 	datasources.DataSourceService
 
@@ -1,6 +1,10 @@
 package dtos
 
-import "github.com/grafana/grafana/pkg/components/simplejson"
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
 
 type PostAnnotationsCmd struct {
 	DashboardId  int64  `json:"dashboardId"`
@@ -43,3 +47,70 @@ type PostGraphiteAnnotationsCmd struct {
 	Data string      `json:"data"`
 	Tags interface{} `json:"tags"`
 }
+
+// ImportAnnotationsResult is the report of a CSV import. Rows are numbered like in a
+// spreadsheet, the header being row 1.
+type ImportAnnotationsResult struct {
+	Imported int                      `json:"imported"`
+	Failed   int                      `json:"failed"`
+	DryRun   bool                     `json:"dryRun"`
+	Errors   []ImportAnnotationsError `json:"errors"`
+}
+
+type ImportAnnotationsError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// PostKubernetesEventsCmd is a Kubernetes Event object, or an EventList of several events. Events of
+// both the core/v1 and the events.k8s.io/v1 API are accepted.
+type PostKubernetesEventsCmd struct {
+	KubernetesEvent
+	Items []KubernetesEvent `json:"items"`
+}
+
+type KubernetesEvent struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		UID               string     `json:"uid"`
+		Namespace         string     `json:"namespace"`
+		CreationTimestamp *time.Time `json:"creationTimestamp"`
+	} `json:"metadata"`
+	// InvolvedObject of core/v1 events, Regarding of events.k8s.io/v1 events.
+	InvolvedObject KubernetesObjectReference `json:"involvedObject"`
+	Regarding      KubernetesObjectReference `json:"regarding"`
+	Type           string                    `json:"type"`
+	Reason         string                    `json:"reason"`
+	// Message of core/v1 events, Note of events.k8s.io/v1 events.
+	Message string `json:"message"`
+	Note    string `json:"note"`
+	// Count of core/v1 events, Series of events.k8s.io/v1 events are set when an event repeats.
+	Count  int `json:"count"`
+	Series *struct {
+		Count int `json:"count"`
+	} `json:"series"`
+	EventTime                *time.Time `json:"eventTime"`
+	FirstTimestamp           *time.Time `json:"firstTimestamp"`
+	DeprecatedFirstTimestamp *time.Time `json:"deprecatedFirstTimestamp"`
+}
+
+type KubernetesObjectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+}
+
+// KubernetesAnnotationsResult is the report of the events posted at once. Events are numbered by
+// their index in the list, starting at 0.
+type KubernetesAnnotationsResult struct {
+	Created int                         `json:"created"`
+	Skipped int                         `json:"skipped"`
+	Failed  int                         `json:"failed"`
+	Errors  []KubernetesAnnotationError `json:"errors"`
+}
+
+type KubernetesAnnotationError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
@@ -23,6 +23,7 @@ type DashboardMeta struct {
 	UpdatedBy                  string                `json:"updatedBy"`
 	CreatedBy                  string                `json:"createdBy"`
 	Version                    int                   `json:"version"`
+	ResourceVersion            string                `json:"resourceVersion"`
 	HasACL                     bool                  `json:"hasAcl" xorm:"has_acl"`
 	IsFolder                   bool                  `json:"isFolder"`
 	FolderId                   int64                 `json:"folderId"`
@@ -36,6 +37,16 @@ type DashboardMeta struct {
 	PublicDashboardAccessToken string                `json:"publicDashboardAccessToken"`
 	PublicDashboardUID         string                `json:"publicDashboardUid"`
 	PublicDashboardEnabled     bool                  `json:"publicDashboardEnabled"`
+	// EditLock is set while a user is editing the dashboard.
+	EditLock *DashboardEditLock `json:"editLock,omitempty"`
+}
+
+type DashboardEditLock struct {
+	UserID   int64     `json:"userId"`
+	Login    string    `json:"login"`
+	Name     string    `json:"name"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
 }
 type AnnotationPermission struct {
 	Dashboard    AnnotationActions `json:"dashboard"`
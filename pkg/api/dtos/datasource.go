@@ -2,6 +2,7 @@ package dtos
 
 import (
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -26,6 +27,7 @@ type DataSource struct {
 	JsonData         *simplejson.Json       `json:"jsonData,omitempty"`
 	SecureJsonFields map[string]bool        `json:"secureJsonFields"`
 	Version          int                    `json:"version"`
+	ResourceVersion  string                 `json:"resourceVersion"`
 	ReadOnly         bool                   `json:"readOnly"`
 	AccessControl    accesscontrol.Metadata `json:"accessControl,omitempty"`
 }
@@ -48,6 +50,13 @@ type DataSourceListItemDTO struct {
 	ReadOnly    bool                 `json:"readOnly"`
 }
 
+// DataSourceUserCredentials are the credentials the signed in user supplied for a data source
+// using per-user credentials, without the password.
+type DataSourceUserCredentials struct {
+	User    string    `json:"user"`
+	Updated time.Time `json:"updated"`
+}
+
 type DataSourceList []DataSourceListItemDTO
 
 func (slice DataSourceList) Len() int {
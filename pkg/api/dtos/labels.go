@@ -0,0 +1,38 @@
+package dtos
+
+// ResourceLabels are the labels of a dashboard, folder, data source or alert rule.
+type ResourceLabels struct {
+	// example: {"team":"checkout","env":"prod"}
+	Labels map[string]string `json:"labels"`
+}
+
+// UpdateLabelsBySelectorCommand updates the labels of all resources matching a label selector.
+type UpdateLabelsBySelectorCommand struct {
+	// example: team=checkout
+	Selector string `json:"selector"`
+	// Labels to add or overwrite
+	Set map[string]string `json:"set"`
+	// Keys of the labels to remove
+	Remove []string `json:"remove"`
+}
+
+// MoveDashboardsBySelectorCommand moves all dashboards matching a label selector to a folder.
+type MoveDashboardsBySelectorCommand struct {
+	// example: team=checkout
+	Selector string `json:"selector"`
+	// UID of the target folder, the General folder if empty
+	FolderUID string `json:"folderUid"`
+}
+
+// BulkResult is the result of an operation on all resources matching a label selector.
+type BulkResult struct {
+	// Number of resources the operation succeeded for
+	Count int `json:"count"`
+	// Resources the operation failed for
+	Errors []BulkError `json:"errors"`
+}
+
+type BulkError struct {
+	UID   string `json:"uid"`
+	Error string `json:"error"`
+}
@@ -63,4 +63,6 @@ func (slice PluginList) Swap(i, j int) {
 
 type InstallPluginCommand struct {
 	Version string `json:"version"`
+	// Pin prevents the installed version from being changed until the plugin is unpinned.
+	Pin bool `json:"pin"`
 }
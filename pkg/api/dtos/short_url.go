@@ -1,10 +1,42 @@
 package dtos
 
+import "time"
+
 type ShortURL struct {
-	UID string `json:"uid"`
-	URL string `json:"url"`
+	UID       string     `json:"uid"`
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
+	// Slug is an optional human-readable UID, e.g. team-overview.
+	Slug string `json:"slug"`
+	// ExpiresIn is an optional lifetime, e.g. 30d. The short URL never expires if empty.
+	ExpiresIn string `json:"expiresIn"`
+}
+
+type UpdateShortURLCmd struct {
+	Path *string `json:"path"`
+	// ExpiresIn resets the lifetime of the short URL from now, an empty string removes the expiry.
+	ExpiresIn *string `json:"expiresIn"`
+}
+
+type ShortURLDetails struct {
+	UID        string     `json:"uid"`
+	URL        string     `json:"url"`
+	Path       string     `json:"path"`
+	CustomSlug bool       `json:"customSlug"`
+	CreatedBy  int64      `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	Hits       int64      `json:"hits"`
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64              `json:"totalCount"`
+	ShortURLs  []*ShortURLDetails `json:"shortUrls"`
+	Page       int                `json:"page"`
+	PerPage    int                `json:"perPage"`
 }
@@ -0,0 +1,14 @@
+package dtos
+
+// StarredResource is a resource the signed in user starred.
+type StarredResource struct {
+	// Kind of the resource, one of dashboard, folder, datasource or alert-rule
+	// example: dashboard
+	Kind string `json:"kind"`
+	// example: nErXDvCkzz
+	UID string `json:"uid"`
+	// example: Production overview
+	Title string `json:"title"`
+	// example: /d/nErXDvCkzz/production-overview
+	URL string `json:"url"`
+}
@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+type TeamUsage struct {
+	TeamID      int64              `json:"teamId"`
+	Since       time.Time          `json:"since"`
+	APIRequests int64              `json:"apiRequests"`
+	Queries     int64              `json:"queries"`
+	Members     []*TeamMemberUsage `json:"members"`
+}
+
+type TeamMemberUsage struct {
+	UserID      int64  `json:"userId"`
+	Login       string `json:"login"`
+	APIRequests int64  `json:"apiRequests"`
+	Queries     int64  `json:"queries"`
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// SendTestEmailForm is the body of POST /api/admin/emails/test.
+type SendTestEmailForm struct {
+	To string `json:"to"`
+	// OrgID selects the organization whose sender identity is used, the default sender is used when zero.
+	OrgID int64 `json:"orgId"`
+}
+
+// AdminSendTestEmail sends an email synchronously through the configured provider so that
+// delivery problems are reported to the caller instead of only to the server log.
+func (hs *HTTPServer) AdminSendTestEmail(c *models.ReqContext) response.Response {
+	form := SendTestEmailForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if !util.IsEmail(form.To) {
+		return response.Error(http.StatusBadRequest, "Invalid recipient address", nil)
+	}
+
+	msg, err := hs.NotificationService.SendTestEmail(c.Req.Context(), form.OrgID, form.To)
+	if err != nil {
+		if errors.Is(err, models.ErrSmtpNotEnabled) {
+			return response.Error(http.StatusPreconditionFailed, "Email is not enabled", err)
+		}
+		return response.Error(http.StatusBadGateway, "Failed to send test email", err)
+	}
+
+	return response.JSON(http.StatusOK, map[string]interface{}{
+		"message":           "Test email sent",
+		"from":              msg.From,
+		"provider":          msg.Provider,
+		"providerMessageId": msg.ProviderMessageID,
+	})
+}
+
+// AdminGetEmailDeliveries lists the most recent email deliveries, optionally filtered by
+// orgId and status (sent or failed).
+func (hs *HTTPServer) AdminGetEmailDeliveries(c *models.ReqContext) response.Response {
+	status := notifications.DeliveryStatus(c.Query("status"))
+	if status != "" && status != notifications.DeliveryStatusSent && status != notifications.DeliveryStatusFailed {
+		return response.Error(http.StatusBadRequest, "Invalid status, expected sent or failed", nil)
+	}
+
+	deliveries, err := hs.NotificationService.GetDeliveries(c.Req.Context(), notifications.DeliveriesQuery{
+		OrgID:  c.QueryInt64("orgId"),
+		Status: status,
+		Limit:  c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get email deliveries", err)
+	}
+
+	return response.JSON(http.StatusOK, deliveries)
+}
+
+func (hs *HTTPServer) GetOrgEmailSender(c *models.ReqContext) response.Response {
+	identity, err := hs.NotificationService.GetSenderIdentity(c.Req.Context(), c.OrgID)
+	if err != nil {
+		if errors.Is(err, notifications.ErrSenderIdentityNotFound) {
+			return response.Error(http.StatusNotFound, "Organization has no email sender identity", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get email sender identity", err)
+	}
+
+	return response.JSON(http.StatusOK, identity)
+}
+
+func (hs *HTTPServer) UpdateOrgEmailSender(c *models.ReqContext) response.Response {
+	cmd := notifications.SetSenderIdentityCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+
+	identity, err := hs.NotificationService.SetSenderIdentity(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, notifications.ErrInvalidSenderAddress) || errors.Is(err, notifications.ErrSenderDomainNotAllowed) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update email sender identity", err)
+	}
+
+	return response.JSON(http.StatusOK, identity)
+}
+
+func (hs *HTTPServer) DeleteOrgEmailSender(c *models.ReqContext) response.Response {
+	if err := hs.NotificationService.DeleteSenderIdentity(c.Req.Context(), c.OrgID); err != nil {
+		if errors.Is(err, notifications.ErrSenderIdentityNotFound) {
+			return response.Error(http.StatusNotFound, "Organization has no email sender identity", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete email sender identity", err)
+	}
+
+	return response.Success("Email sender identity deleted")
+}
+
+func (hs *HTTPServer) GetOrgEmailTemplates(c *models.ReqContext) response.Response {
+	templates, err := hs.NotificationService.GetEmailTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get email templates", err)
+	}
+
+	return response.JSON(http.StatusOK, templates)
+}
+
+func (hs *HTTPServer) GetOrgEmailTemplate(c *models.ReqContext) response.Response {
+	template, err := hs.NotificationService.GetEmailTemplate(c.Req.Context(), c.OrgID, web.Params(c.Req)[":name"])
+	if err != nil {
+		if errors.Is(err, notifications.ErrEmailTemplateNotFound) {
+			return response.Error(http.StatusNotFound, "Email template not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get email template", err)
+	}
+
+	return response.JSON(http.StatusOK, template)
+}
+
+func (hs *HTTPServer) UpdateOrgEmailTemplate(c *models.ReqContext) response.Response {
+	cmd := notifications.SetEmailTemplateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.Name = web.Params(c.Req)[":name"]
+
+	template, err := hs.NotificationService.SetEmailTemplate(c.Req.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, notifications.ErrEmailTemplateNotFound):
+			return response.Error(http.StatusNotFound, "Email template not found", err)
+		case errors.Is(err, notifications.ErrInvalidEmailTemplate):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update email template", err)
+	}
+
+	return response.JSON(http.StatusOK, template)
+}
+
+func (hs *HTTPServer) DeleteOrgEmailTemplate(c *models.ReqContext) response.Response {
+	if err := hs.NotificationService.DeleteEmailTemplate(c.Req.Context(), c.OrgID, web.Params(c.Req)[":name"]); err != nil {
+		if errors.Is(err, notifications.ErrEmailTemplateNotFound) {
+			return response.Error(http.StatusNotFound, "Email template is not customized", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete email template", err)
+	}
+
+	return response.Success("Email template reset to default")
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/resourceversion"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
+)
+
+// errorCodes are the stable error codes of the errors returned by the API, which are returned
+// to clients requesting errors as problem details. Codes must not be changed once released.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{dashboards.ErrDashboardNotFound, "dashboards.notFound"},
+	{dashboards.ErrDashboardVersionMismatch, "dashboards.versionMismatch"},
+	{dashboards.ErrDashboardWithSameUIDExists, "dashboards.uidExists"},
+	{dashboards.ErrDashboardWithSameNameInFolderExists, "dashboards.nameExists"},
+	{dashboards.ErrDashboardFolderNotFound, "dashboards.folderNotFound"},
+	{dashboards.ErrDashboardTitleEmpty, "dashboards.titleEmpty"},
+	{dashboards.ErrDashboardInvalidUid, "dashboards.invalidUid"},
+	{dashboards.ErrDashboardUidTooLong, "dashboards.uidTooLong"},
+	{dashboards.ErrDashboardCannotSaveProvisionedDashboard, "dashboards.provisioned"},
+	{dashboards.ErrDashboardCannotDeleteProvisionedDashboard, "dashboards.provisioned"},
+	{dashboards.ErrDashboardUpdateAccessDenied, "dashboards.accessDenied"},
+	{dashboards.ErrFolderNotFound, "folders.notFound"},
+	{dashboards.ErrFolderVersionMismatch, "folders.versionMismatch"},
+	{dashboards.ErrFolderWithSameUIDExists, "folders.uidExists"},
+	{dashboards.ErrFolderSameNameExists, "folders.nameExists"},
+	{dashboards.ErrFolderTitleEmpty, "folders.titleEmpty"},
+	{dashboards.ErrFolderAccessDenied, "folders.accessDenied"},
+	{datasources.ErrDataSourceNotFound, "datasources.notFound"},
+	{datasources.ErrDataSourceNameExists, "datasources.nameExists"},
+	{datasources.ErrDataSourceUidExists, "datasources.uidExists"},
+	{datasources.ErrDataSourceUpdatingOldVersion, "datasources.versionMismatch"},
+	{datasources.ErrDataSourceAccessDenied, "datasources.accessDenied"},
+	{datasources.ErrUserCredentialsNotFound, "datasources.userCredentialsNotFound"},
+	{models.ErrOrgNotFound, "orgs.notFound"},
+	{org.ErrOrgNotFound, "orgs.notFound"},
+	{models.ErrOrgNameTaken, "orgs.nameTaken"},
+	{org.ErrOrgNameTaken, "orgs.nameTaken"},
+	{models.ErrOrgUserNotFound, "orgs.userNotFound"},
+	{models.ErrOrgUserAlreadyAdded, "orgs.userAlreadyAdded"},
+	{models.ErrLastOrgAdmin, "orgs.lastAdmin"},
+	{models.ErrTeamNotFound, "teams.notFound"},
+	{models.ErrTeamNameTaken, "teams.nameTaken"},
+	{models.ErrTeamMemberNotFound, "teams.memberNotFound"},
+	{models.ErrLastTeamAdmin, "teams.lastAdmin"},
+	{user.ErrUserNotFound, "users.notFound"},
+	{user.ErrUserAlreadyExists, "users.alreadyExists"},
+	{user.ErrLastGrafanaAdmin, "users.lastGrafanaAdmin"},
+	{serviceaccounts.ErrServiceAccountNotFound, "serviceaccounts.notFound"},
+	{apikey.ErrDuplicate, "apikeys.duplicate"},
+	{playlist.ErrPlaylistNotFound, "playlists.notFound"},
+	{resourceversion.ErrMismatch, "resources.versionMismatch"},
+}
+
+func init() {
+	for _, c := range errorCodes {
+		errhttp.RegisterCode(c.err, c.code)
+	}
+}
@@ -13,8 +13,10 @@ type fakePluginInstaller struct {
 }
 
 type fakePlugin struct {
-	pluginID string
-	version  string
+	pluginID        string
+	version         string
+	previousVersion string
+	pinned          bool
 }
 
 func NewFakePluginInstaller() *fakePluginInstaller {
@@ -22,9 +24,14 @@ func NewFakePluginInstaller() *fakePluginInstaller {
 }
 
 func (pm *fakePluginInstaller) Add(_ context.Context, pluginID, version string, _ plugins.CompatOpts) error {
+	p, exists := pm.plugins[pluginID]
+	if exists && p.pinned {
+		return plugins.ErrPluginPinned
+	}
 	pm.plugins[pluginID] = fakePlugin{
-		pluginID: pluginID,
-		version:  version,
+		pluginID:        pluginID,
+		version:         version,
+		previousVersion: p.version,
 	}
 	return nil
 }
@@ -34,6 +41,45 @@ func (pm *fakePluginInstaller) Remove(_ context.Context, pluginID string) error
 	return nil
 }
 
+func (pm *fakePluginInstaller) Rollback(_ context.Context, pluginID string, _ plugins.CompatOpts) error {
+	p, exists := pm.plugins[pluginID]
+	if !exists {
+		return plugins.ErrPluginNotInstalled
+	}
+	if p.pinned {
+		return plugins.ErrPluginPinned
+	}
+	if p.previousVersion == "" {
+		return plugins.ErrNoPreviousVersion
+	}
+	p.version, p.previousVersion = p.previousVersion, p.version
+	pm.plugins[pluginID] = p
+	return nil
+}
+
+func (pm *fakePluginInstaller) Pin(_ context.Context, pluginID string, pinned bool) error {
+	p, exists := pm.plugins[pluginID]
+	if !exists {
+		return plugins.ErrPluginNotInstalled
+	}
+	p.pinned = pinned
+	pm.plugins[pluginID] = p
+	return nil
+}
+
+func (pm *fakePluginInstaller) InstallState(_ context.Context, pluginID string) (*plugins.InstallState, error) {
+	p, exists := pm.plugins[pluginID]
+	if !exists {
+		return nil, plugins.ErrPluginNotInstalled
+	}
+	return &plugins.InstallState{
+		PluginID:        p.pluginID,
+		Version:         p.version,
+		PreviousVersion: p.previousVersion,
+		Pinned:          p.pinned,
+	}, nil
+}
+
 type fakeRendererManager struct {
 	plugins.RendererManager
 }
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/fieldset"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// jsonWithFields creates a JSON response of a list of items which only keeps the fields of the
+// items selected with the `fields` query parameter. key is the key of the items when body is an
+// object wrapping them, or empty when body is the list.
+func jsonWithFields(c *models.ReqContext, body interface{}, key string) response.Response {
+	fields, err := fieldset.Parse(c.Req.URL.Query())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	var b []byte
+	if key == "" {
+		b, err = fields.Select(body)
+	} else {
+		b, err = fields.SelectIn(body, key)
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to select the fields of the response", err)
+	}
+	return response.JSON(http.StatusOK, b)
+}
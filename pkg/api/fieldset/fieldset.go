@@ -0,0 +1,148 @@
+// Package fieldset implements the sparse fieldsets of the list APIs.
+//
+// The `fields` parameter of a list API selects the fields of the items it returns, e.g.
+// `fields=uid,title`. Fields are separated by commas, and the parameter can be repeated. Items
+// only keep the selected fields, in the order they are requested, which cuts the size of the
+// responses for clients using a few fields only. Unknown fields are ignored.
+package fieldset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Param is the query parameter selecting the fields.
+const Param = "fields"
+
+// maxFields caps the number of fields of a request.
+const maxFields = 100
+
+var ErrInvalidFields = errors.New("invalid fields")
+
+// Fields are the fields of the items selected by a request, all fields if empty.
+type Fields []string
+
+// Parse returns the fields selected with the `fields` query parameter.
+func Parse(values url.Values) (Fields, error) {
+	var fields Fields
+	seen := map[string]bool{}
+	for _, value := range values[Param] {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				return nil, fmt.Errorf("%w: empty field in %q", ErrInvalidFields, value)
+			}
+			if seen[field] {
+				continue
+			}
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) > maxFields {
+		return nil, fmt.Errorf("%w: more than %d fields", ErrInvalidFields, maxFields)
+	}
+	return fields, nil
+}
+
+// Select returns the JSON representation of a list of items, or of a single item, with only the
+// selected fields.
+func (f Fields) Select(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(f) == 0 {
+		return b, nil
+	}
+	return f.selectRaw(b)
+}
+
+// SelectIn returns the JSON representation of an object wrapping a list of items under key, e.g. a
+// page of search results, with only the selected fields in the items. The other keys of the object
+// are kept.
+func (f Fields) SelectIn(v interface{}, key string) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(f) == 0 {
+		return b, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	if items, ok := obj[key]; ok {
+		if obj[key], err = f.selectRaw(items); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(obj)
+}
+
+func (f Fields) selectRaw(b []byte) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || b[0] != '[' {
+		return f.selectObject(b)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		selected, err := f.selectObject(item)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(selected)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// selectObject returns the selected fields of a JSON object in the requested order. Values which
+// aren't objects, like null, are returned unchanged.
+func (f Fields) selectObject(b []byte) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || b[0] != '{' {
+		return b, nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	n := 0
+	for _, field := range f {
+		value, ok := obj[field]
+		if !ok {
+			continue
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+		n++
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
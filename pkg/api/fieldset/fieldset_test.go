@@ -0,0 +1,93 @@
+package fieldset
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tooMany := make([]string, maxFields+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("f%d", i)
+	}
+
+	testCases := []struct {
+		desc     string
+		query    string
+		expected Fields
+		err      error
+	}{
+		{desc: "no fields", query: ""},
+		{desc: "fields", query: "fields=uid,title", expected: Fields{"uid", "title"}},
+		{desc: "repeated parameter", query: "fields=uid&fields=title,%20url", expected: Fields{"uid", "title", "url"}},
+		{desc: "duplicate field", query: "fields=uid,uid", expected: Fields{"uid"}},
+		{desc: "empty field", query: "fields=uid,,title", err: ErrInvalidFields},
+		{desc: "empty parameter", query: "fields=", err: ErrInvalidFields},
+		{desc: "too many fields", query: "fields=" + strings.Join(tooMany, ","), err: ErrInvalidFields},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+			fields, err := Parse(values)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fields)
+		})
+	}
+}
+
+type item struct {
+	ID    int64             `json:"id"`
+	Title string            `json:"title"`
+	Tags  []string          `json:"tags"`
+	Meta  map[string]string `json:"meta,omitempty"`
+}
+
+func TestFields_Select(t *testing.T) {
+	items := []*item{
+		{ID: 1, Title: "CPU", Tags: []string{"linux"}, Meta: map[string]string{"folder": "ops"}},
+		{ID: 2, Title: "Memory"},
+		nil,
+	}
+
+	b, err := Fields{"title", "meta", "unknown", "id"}.Select(items)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"title":"CPU","meta":{"folder":"ops"},"id":1},{"title":"Memory","id":2},null]`, string(b))
+
+	b, err = Fields{"title"}.Select(items[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"title":"CPU"}`, string(b))
+
+	b, err = Fields(nil).Select(items[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":2,"title":"Memory","tags":null}`, string(b))
+
+	b, err = Fields{"id"}.Select([]*item{})
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(b))
+}
+
+func TestFields_SelectIn(t *testing.T) {
+	page := struct {
+		TotalCount int64   `json:"totalCount"`
+		Items      []*item `json:"items"`
+	}{TotalCount: 2, Items: []*item{{ID: 1, Title: "CPU"}, {ID: 2, Title: "Memory"}}}
+
+	b, err := Fields{"id"}.SelectIn(page, "items")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"totalCount":2,"items":[{"id":1},{"id":2}]}`, string(b))
+
+	b, err = Fields{"id"}.SelectIn(page, "unknown")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"totalCount":2,"items":[{"id":1,"title":"CPU","tags":null},{"id":2,"title":"Memory","tags":null}]}`, string(b))
+}
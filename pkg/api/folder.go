@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/resourceversion"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -74,7 +75,8 @@ func (hs *HTTPServer) GetFolderByUID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), folder.ID, c.OrgID, c.SignedInUser)
-	return response.JSON(http.StatusOK, hs.newToFolderDto(c, g, folder))
+	return response.JSON(http.StatusOK, hs.newToFolderDto(c, g, folder)).
+		SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(folder.Version)))
 }
 
 // swagger:route GET /folders/id/{folder_id} folders getFolderByID
@@ -100,7 +102,8 @@ func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
 	}
 
 	g := guardian.New(c.Req.Context(), folder.ID, c.OrgID, c.SignedInUser)
-	return response.JSON(http.StatusOK, hs.newToFolderDto(c, g, folder))
+	return response.JSON(http.StatusOK, hs.newToFolderDto(c, g, folder)).
+		SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(folder.Version)))
 }
 
 // swagger:route POST /folders folders createFolder
@@ -186,12 +189,23 @@ func (hs *HTTPServer) UpdateFolder(c *models.ReqContext) response.Response {
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
-	result, err := hs.folderService.Update(c.Req.Context(), c.SignedInUser, c.OrgID, web.Params(c.Req)[":uid"], &cmd)
+	uid := web.Params(c.Req)[":uid"]
+	if resourceversion.IsConditional(c.Req) {
+		version, rsp := hs.checkFolderResourceVersion(c, uid)
+		if rsp != nil {
+			return rsp
+		}
+		// Updating fails with a version mismatch if the folder is changed after the check
+		cmd.Version = version
+		cmd.Overwrite = false
+	}
+	result, err := hs.folderService.Update(c.Req.Context(), c.SignedInUser, c.OrgID, uid, &cmd)
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
 	}
 	g := guardian.New(c.Req.Context(), result.ID, c.OrgID, c.SignedInUser)
-	return response.JSON(http.StatusOK, hs.newToFolderDto(c, g, result))
+	return response.JSON(http.StatusOK, hs.newToFolderDto(c, g, result)).
+		SetHeader(resourceversion.ETagHeader, resourceversion.ETag(int64(result.Version)))
 }
 
 // swagger:route DELETE /folders/{folder_uid} folders deleteFolder
@@ -209,6 +223,12 @@ func (hs *HTTPServer) UpdateFolder(c *models.ReqContext) response.Response {
 // 404: notFoundError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
+	if resourceversion.IsConditional(c.Req) {
+		if _, rsp := hs.checkFolderResourceVersion(c, web.Params(c.Req)[":uid"]); rsp != nil {
+			return rsp
+		}
+	}
+
 	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
 	if err != nil {
 		if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
@@ -226,6 +246,19 @@ func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { //
 	return response.JSON(http.StatusOK, "")
 }
 
+// checkFolderResourceVersion checks the If-Match header of the request against the stored version
+// of the folder and returns the version.
+func (hs *HTTPServer) checkFolderResourceVersion(c *models.ReqContext, uid string) (int, response.Response) {
+	f, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.OrgID, UID: &uid, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return 0, apierrors.ToFolderErrorResponse(err)
+	}
+	if err := resourceversion.Check(c.Req, int64(f.Version)); err != nil {
+		return 0, response.Error(http.StatusPreconditionFailed, err.Error(), err)
+	}
+	return f.Version, nil
+}
+
 func (hs *HTTPServer) newToFolderDto(c *models.ReqContext, g guardian.DashboardGuardian, folder *folder.Folder) dtos.Folder {
 	canEdit, _ := g.CanEdit()
 	canSave, _ := g.CanSave()
@@ -242,22 +275,23 @@ func (hs *HTTPServer) newToFolderDto(c *models.ReqContext, g guardian.DashboardG
 	}
 
 	return dtos.Folder{
-		Id:            folder.ID,
-		Uid:           folder.UID,
-		Title:         folder.Title,
-		Url:           folder.Url,
-		HasACL:        folder.HasACL,
-		CanSave:       canSave,
-		CanEdit:       canEdit,
-		CanAdmin:      canAdmin,
-		CanDelete:     canDelete,
-		CreatedBy:     creator,
-		Created:       folder.Created,
-		UpdatedBy:     updater,
-		Updated:       folder.Updated,
-		Version:       folder.Version,
-		AccessControl: hs.getAccessControlMetadata(c, c.OrgID, dashboards.ScopeFoldersPrefix, folder.UID),
-		ParentUID:     folder.ParentUID,
+		Id:              folder.ID,
+		Uid:             folder.UID,
+		Title:           folder.Title,
+		Url:             folder.Url,
+		HasACL:          folder.HasACL,
+		CanSave:         canSave,
+		CanEdit:         canEdit,
+		CanAdmin:        canAdmin,
+		CanDelete:       canDelete,
+		CreatedBy:       creator,
+		Created:         folder.Created,
+		UpdatedBy:       updater,
+		Updated:         folder.Updated,
+		Version:         folder.Version,
+		ResourceVersion: resourceversion.Format(int64(folder.Version)),
+		AccessControl:   hs.getAccessControlMetadata(c, c.OrgID, dashboards.ScopeFoldersPrefix, folder.UID),
+		ParentUID:       folder.ParentUID,
 	}
 }
 
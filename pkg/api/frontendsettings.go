@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+	"github.com/grafana/grafana/pkg/tsdb/syntheticsds"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -349,6 +350,9 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins Enab
 				dto.ID = grafanads.DatasourceID
 				dto.UID = grafanads.DatasourceUID
 			}
+			if ds.ID == syntheticsds.PluginID {
+				dto.UID = syntheticsds.DatasourceUID
+			}
 			dataSources[ds.Name] = dto
 		}
 	}
@@ -421,7 +425,7 @@ func (hs *HTTPServer) enabledPlugins(ctx context.Context, orgID int64) (EnabledP
 	for _, app := range hs.pluginStore.Plugins(ctx, plugins.App) {
 		if b, exists := pluginSettingMap[app.ID]; exists {
 			app.Pinned = b.Pinned
-			apps[app.ID] = app
+			apps[app.ID] = hs.withCDNAssets(app)
 		}
 	}
 	ep[plugins.App] = apps
@@ -429,7 +433,7 @@ func (hs *HTTPServer) enabledPlugins(ctx context.Context, orgID int64) (EnabledP
 	dataSources := make(map[string]plugins.PluginDTO)
 	for _, ds := range hs.pluginStore.Plugins(ctx, plugins.DataSource) {
 		if _, exists := pluginSettingMap[ds.ID]; exists {
-			dataSources[ds.ID] = ds
+			dataSources[ds.ID] = hs.withCDNAssets(ds)
 		}
 	}
 	ep[plugins.DataSource] = dataSources
@@ -437,7 +441,7 @@ func (hs *HTTPServer) enabledPlugins(ctx context.Context, orgID int64) (EnabledP
 	panels := make(map[string]plugins.PluginDTO)
 	for _, p := range hs.pluginStore.Plugins(ctx, plugins.Panel) {
 		if _, exists := pluginSettingMap[p.ID]; exists {
-			panels[p.ID] = p
+			panels[p.ID] = hs.withCDNAssets(p)
 		}
 	}
 	ep[plugins.Panel] = panels
@@ -445,6 +449,14 @@ func (hs *HTTPServer) enabledPlugins(ctx context.Context, orgID int64) (EnabledP
 	return ep, nil
 }
 
+// withCDNAssets returns the plugin with the URLs of its assets on the CDN, when they are served from it.
+func (hs *HTTPServer) withCDNAssets(p plugins.PluginDTO) plugins.PluginDTO {
+	if hs.cdnAssets == nil {
+		return p
+	}
+	return hs.cdnAssets.WithCDNURLs(p)
+}
+
 func (hs *HTTPServer) pluginSettings(ctx context.Context, orgID int64) (map[string]*pluginsettings.InfoDTO, error) {
 	pluginSettings := make(map[string]*pluginsettings.InfoDTO)
 
@@ -19,6 +19,7 @@ import (
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsettings/service"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
 	"github.com/grafana/grafana/pkg/setting"
@@ -57,7 +58,7 @@ func setupTestEnvironment(t *testing.T, cfg *setting.Cfg, features *featuremgmt.
 		pluginStore:          &plugins.FakePluginStore{},
 		grafanaUpdateChecker: &updatechecker.GrafanaService{},
 		AccessControl:        accesscontrolmock.New().WithDisabled(),
-		PluginSettings:       pluginSettings.ProvideService(sqlStore, secretsService),
+		PluginSettings:       pluginSettings.ProvideService(sqlStore, secretsService, secretskvs.NewFakeSecretsKVStore(), features, cfg),
 		SocialService:        social.ProvideService(cfg, features),
 	}
 
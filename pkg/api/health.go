@@ -2,11 +2,40 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
 )
 
+const (
+	healthStatusOK      = "ok"
+	healthStatusFailing = "failing"
+
+	readinessCheckTimeout = 5 * time.Second
+	remoteCacheHealthKey  = "readiness-check"
+)
+
+// dependencyHealth is the status of a dependency checked by the readiness endpoint.
+type dependencyHealth struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]dependencyHealth `json:"checks"`
+}
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
 func (hs *HTTPServer) databaseHealthy(ctx context.Context) bool {
 	const cacheKey = "db-healthy"
 
@@ -19,3 +48,135 @@ func (hs *HTTPServer) databaseHealthy(ctx context.Context) bool {
 	hs.CacheService.Set(cacheKey, healthy, time.Second*5)
 	return healthy
 }
+
+// pendingMigrations returns the number of database migrations which were not applied.
+func (hs *HTTPServer) pendingMigrations(ctx context.Context) (int, error) {
+	const cacheKey = "db-pending-migrations"
+
+	if cached, found := hs.CacheService.Get(cacheKey); found {
+		return cached.(int), nil
+	}
+
+	query := models.GetPendingMigrationsQuery{}
+	if err := hs.SQLStore.GetPendingMigrations(ctx, &query); err != nil {
+		return 0, err
+	}
+
+	hs.CacheService.Set(cacheKey, len(query.Result), time.Minute)
+	return len(query.Result), nil
+}
+
+func (hs *HTTPServer) readinessChecks() []readinessCheck {
+	checks := []readinessCheck{
+		{
+			name: "database",
+			check: func(ctx context.Context) error {
+				if !hs.databaseHealthy(ctx) {
+					return errors.New("database is not reachable")
+				}
+				return nil
+			},
+		},
+		{
+			name: "migrations",
+			check: func(ctx context.Context) error {
+				pending, err := hs.pendingMigrations(ctx)
+				if err != nil {
+					return errors.New("failed to read migration status")
+				}
+				if pending > 0 {
+					return fmt.Errorf("%d pending migrations", pending)
+				}
+				return nil
+			},
+		},
+	}
+
+	if hs.RemoteCacheService != nil {
+		checks = append(checks, readinessCheck{
+			name: "remoteCache",
+			check: func(ctx context.Context) error {
+				if err := hs.RemoteCacheService.Set(ctx, remoteCacheHealthKey, healthStatusOK, time.Minute); err != nil {
+					return errors.New("remote cache is not writable")
+				}
+				if _, err := hs.RemoteCacheService.Get(ctx, remoteCacheHealthKey); err != nil {
+					return errors.New("remote cache is not readable")
+				}
+				return nil
+			},
+		})
+	}
+
+	if hs.pluginStore != nil {
+		checks = append(checks, readinessCheck{
+			name: "plugins",
+			check: func(ctx context.Context) error {
+				if len(hs.pluginStore.Plugins(ctx)) == 0 {
+					return errors.New("no plugins loaded")
+				}
+				return nil
+			},
+		})
+	}
+
+	return checks
+}
+
+// apiHealthLivenessHandler returns 200 - Ok if Grafana's web server is running.
+// The dependencies are not checked, so a broken database does not get the
+// instance restarted.
+func (hs *HTTPServer) apiHealthLivenessHandler(ctx *web.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	if notHeadOrGet || ctx.Req.URL.Path != "/api/health/live" {
+		return
+	}
+
+	hs.writeHealthResponse(ctx, http.StatusOK, map[string]string{"status": healthStatusOK})
+}
+
+// apiHealthReadinessHandler returns 200 - Ok if Grafana can serve requests, that is
+// the database is reachable and migrated, and the remote cache and plugins are
+// available. Otherwise it returns 503 with the status of each dependency.
+func (hs *HTTPServer) apiHealthReadinessHandler(ctx *web.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	if notHeadOrGet || ctx.Req.URL.Path != "/api/health/ready" {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx.Req.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	resp := readinessResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]dependencyHealth),
+	}
+	for _, c := range hs.readinessChecks() {
+		if err := c.check(checkCtx); err != nil {
+			hs.log.Warn("Readiness check failed", "dependency", c.name, "error", err)
+			resp.Status = healthStatusFailing
+			resp.Checks[c.name] = dependencyHealth{Status: healthStatusFailing, Message: err.Error()}
+			continue
+		}
+		resp.Checks[c.name] = dependencyHealth{Status: healthStatusOK}
+	}
+
+	status := http.StatusOK
+	if resp.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	hs.writeHealthResponse(ctx, status, resp)
+}
+
+func (hs *HTTPServer) writeHealthResponse(ctx *web.Context, status int, body interface{}) {
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		hs.log.Error("Failed to encode data", "err", err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	ctx.Resp.WriteHeader(status)
+	if _, err := ctx.Resp.Write(data); err != nil {
+		hs.log.Error("Failed to write to response", "err", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -146,6 +147,82 @@ func TestHealthAPI_DatabaseHealthCached(t *testing.T) {
 	require.True(t, healthy.(bool))
 }
 
+func TestHealthAPI_Liveness(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t)
+	hs.SQLStore.(*mockstore.SQLStoreMock).ExpectedError = errors.New("bad")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health/live", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	require.JSONEq(t, `{"status": "ok"}`, rec.Body.String())
+}
+
+func TestHealthAPI_Readiness(t *testing.T) {
+	t.Run("ready when all dependencies are healthy", func(t *testing.T) {
+		m, _ := setupHealthAPITestEnvironment(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/health/ready", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		require.Equal(t, 200, rec.Code)
+		expectedBody := `
+			{
+				"status": "ok",
+				"checks": {
+					"database": {"status": "ok"},
+					"migrations": {"status": "ok"}
+				}
+			}
+		`
+		require.JSONEq(t, expectedBody, rec.Body.String())
+	})
+
+	t.Run("not ready when migrations are pending", func(t *testing.T) {
+		m, hs := setupHealthAPITestEnvironment(t)
+		hs.SQLStore.(*mockstore.SQLStoreMock).ExpectedPendingMigrations = []string{"create alert_rule table", "add index"}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/health/ready", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		require.Equal(t, 503, rec.Code)
+		expectedBody := `
+			{
+				"status": "failing",
+				"checks": {
+					"database": {"status": "ok"},
+					"migrations": {"status": "failing", "message": "2 pending migrations"}
+				}
+			}
+		`
+		require.JSONEq(t, expectedBody, rec.Body.String())
+	})
+
+	t.Run("not ready when the database is unreachable", func(t *testing.T) {
+		m, hs := setupHealthAPITestEnvironment(t)
+		hs.SQLStore.(*mockstore.SQLStoreMock).ExpectedError = errors.New("bad")
+
+		req := httptest.NewRequest(http.MethodGet, "/api/health/ready", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		require.Equal(t, 503, rec.Code)
+		expectedBody := `
+			{
+				"status": "failing",
+				"checks": {
+					"database": {"status": "failing", "message": "database is not reachable"},
+					"migrations": {"status": "failing", "message": "failed to read migration status"}
+				}
+			}
+		`
+		require.JSONEq(t, expectedBody, rec.Body.String())
+	})
+}
+
 func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*web.Mux, *HTTPServer) {
 	t.Helper()
 
@@ -158,8 +235,11 @@ func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*we
 		CacheService: localcache.New(5*time.Minute, 10*time.Minute),
 		Cfg:          cfg,
 		SQLStore:     mockstore.NewSQLStoreMock(),
+		log:          log.New("test"),
 	}
 
 	m.Get("/api/health", hs.apiHealthHandler)
+	m.Get("/api/health/live", hs.apiHealthLivenessHandler)
+	m.Get("/api/health/ready", hs.apiHealthReadinessHandler)
 	return m, hs
 }
@@ -14,11 +14,14 @@ import (
 	"sync"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/profiler"
 	"github.com/grafana/grafana/pkg/middleware/csrf"
+	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/querylibrary"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/store/object/httpobjectstore"
 
@@ -34,6 +37,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
 	loginpkg "github.com/grafana/grafana/pkg/login"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/middleware"
@@ -45,19 +49,28 @@ import (
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/cdnassets"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/comments"
+	"github.com/grafana/grafana/pkg/services/contentsync"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/services/dashboardreview"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardsubscription"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	"github.com/grafana/grafana/pkg/services/datasourceusage"
+	"github.com/grafana/grafana/pkg/services/editlock"
+	"github.com/grafana/grafana/pkg/services/embedtoken"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/export"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/homerouting"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/ldap"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
@@ -67,9 +80,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/login"
 	loginAttempt "github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/orglogs"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsettings"
@@ -77,6 +93,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	publicdashboardsApi "github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/querycapture"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -85,6 +102,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets"
 	secretsKV "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	spm "github.com/grafana/grafana/pkg/services/secrets/kvstore/migrations"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -97,8 +115,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/thumbs"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/variablecache"
+	"github.com/grafana/grafana/pkg/services/variableconstraints"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
+	"golang.org/x/sync/singleflight"
 )
 
 type HTTPServer struct {
@@ -207,6 +228,24 @@ type HTTPServer struct {
 	annotationsRepo        annotations.Repository
 	tagService             tag.Service
 	oauthTokenService      oauthtoken.OAuthTokenService
+	userUsageTracker       usagestats.UserUsageTracker
+	slowRequestProfiler    *profiler.SlowRequestProfiler
+	resourceLabelService   resourcelabel.Service
+	dashboardLintService   dashboardlint.Service
+	variableCache          variablecache.Service
+	queryCaptureService    querycapture.Service
+	networkPolicyService   networkpolicy.Service
+	securityHeaders        securityheaders.Service
+	embedTokenService      *embedtoken.EmbedTokenService
+	dashboardSubscriptions dashboardsubscription.Service
+	dashboardReviews       dashboardreview.Service
+	editLocks              editlock.Service
+	dataSourceUsage        datasourceusage.Service
+	variableConstraints    variableconstraints.Service
+	orgLogs                orglogs.Service
+	cdnAssets              *cdnassets.AssetService
+	homeRouting            homerouting.Service
+	adminStatsGroup        singleflight.Group
 }
 
 type ServerOptions struct {
@@ -249,6 +288,13 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	accesscontrolService accesscontrol.Service, dashboardThumbsService thumbs.DashboardThumbService, navTreeService navtree.Service,
 	annotationRepo annotations.Repository, tagService tag.Service, searchv2HTTPService searchV2.SearchHTTPService,
 	queryLibraryHTTPService querylibrary.HTTPService, queryLibraryService querylibrary.Service, oauthTokenService oauthtoken.OAuthTokenService,
+	userUsageTracker usagestats.UserUsageTracker, slowRequestProfiler *profiler.SlowRequestProfiler,
+	resourceLabelService resourcelabel.Service, dashboardLintService dashboardlint.Service, variableCache variablecache.Service,
+	queryCaptureService querycapture.Service, networkPolicyService networkpolicy.Service,
+	securityHeaders securityheaders.Service, embedTokenService *embedtoken.EmbedTokenService,
+	dashboardSubscriptions dashboardsubscription.Service, dashboardReviews dashboardreview.Service,
+	editLocks editlock.Service, dataSourceUsage datasourceusage.Service, variableConstraints variableconstraints.Service,
+	orgLogs orglogs.Service, cdnAssets *cdnassets.AssetService, homeRouting homerouting.Service,
 ) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
@@ -353,10 +399,28 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		QueryLibraryHTTPService:      queryLibraryHTTPService,
 		QueryLibraryService:          queryLibraryService,
 		oauthTokenService:            oauthTokenService,
+		userUsageTracker:             userUsageTracker,
+		slowRequestProfiler:          slowRequestProfiler,
+		resourceLabelService:         resourceLabelService,
+		dashboardLintService:         dashboardLintService,
+		variableCache:                variableCache,
+		queryCaptureService:          queryCaptureService,
+		networkPolicyService:         networkPolicyService,
+		securityHeaders:              securityHeaders,
+		embedTokenService:            embedTokenService,
+		dashboardSubscriptions:       dashboardSubscriptions,
+		dashboardReviews:             dashboardReviews,
+		editLocks:                    editLocks,
+		dataSourceUsage:              dataSourceUsage,
+		variableConstraints:          variableConstraints,
+		orgLogs:                      orgLogs,
+		cdnAssets:                    cdnAssets,
+		homeRouting:                  homeRouting,
 	}
 	if hs.Listener != nil {
 		hs.log.Debug("Using provided listener")
 	}
+	hs.AddNamedMiddleware(middleware.Idempotency(remoteCache, idempotentRoutes))
 	hs.registerRoutes()
 
 	// Register access control scope resolver for annotations
@@ -376,6 +440,15 @@ func (hs *HTTPServer) AddNamedMiddleware(middleware routing.RegisterNamedMiddlew
 	hs.namedMiddlewares = append(hs.namedMiddlewares, middleware)
 }
 
+// StartupDependencies makes the server wait for the Alertmanagers to be loaded before serving
+// requests, so that the alerting API doesn't answer that they don't exist.
+func (hs *HTTPServer) StartupDependencies() []registry.BackgroundService {
+	if hs.AlertNG == nil {
+		return nil
+	}
+	return []registry.BackgroundService{hs.AlertNG}
+}
+
 func (hs *HTTPServer) Run(ctx context.Context) error {
 	hs.context = ctx
 
@@ -576,6 +649,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 
 	m.Use(middleware.RequestTracing(hs.tracer))
 	m.Use(middleware.RequestMetrics(hs.Features))
+	m.Use(hs.slowRequestProfiler.Middleware())
 
 	m.UseMiddleware(middleware.Logger(hs.Cfg))
 
@@ -594,7 +668,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 		hs.mapStatic(m, hs.Cfg.ImagesDir, "", "/public/img/attachments")
 	}
 
-	m.Use(middleware.AddDefaultResponseHeaders(hs.Cfg))
+	m.Use(middleware.AddDefaultResponseHeaders(hs.securityHeaders))
 
 	if hs.Cfg.ServeFromSubPath && hs.Cfg.AppSubURL != "" {
 		m.SetURLPrefix(hs.Cfg.AppSubURL)
@@ -606,6 +680,8 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	// and should not be redirected or rejected.
 	m.Use(hs.healthzHandler)
 	m.Use(hs.apiHealthHandler)
+	m.Use(hs.apiHealthLivenessHandler)
+	m.Use(hs.apiHealthReadinessHandler)
 	m.Use(hs.metricsEndpoint)
 	m.Use(hs.pluginMetricsEndpoint)
 	m.Use(hs.frontendLogEndpoints())
@@ -613,6 +689,8 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m.UseMiddleware(hs.ContextHandler.Middleware)
 	m.Use(middleware.OrgRedirect(hs.Cfg, hs.userService))
 	m.Use(accesscontrol.LoadPermissionsMiddleware(hs.accesscontrolService))
+	m.Use(orgtoken.RestrictPermissions)
+	m.Use(contentsync.ReadOnlyMiddleware(hs.Cfg))
 
 	// needs to be after context handler
 	if hs.Cfg.EnforceDomain {
@@ -621,10 +699,12 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 
 	m.Use(middleware.HandleNoCacheHeader)
 
-	if hs.Cfg.CSPEnabled || hs.Cfg.CSPReportOnlyEnabled {
-		m.UseMiddleware(middleware.ContentSecurityPolicy(hs.Cfg, hs.log))
+	if hs.userUsageTracker != nil {
+		m.Use(middleware.UserUsage(hs.userUsageTracker))
 	}
 
+	m.UseMiddleware(middleware.ContentSecurityPolicy(hs.Cfg, hs.securityHeaders, hs.log))
+
 	for _, mw := range hs.middlewares {
 		m.Use(mw)
 	}
@@ -0,0 +1,20 @@
+package api
+
+// idempotentRoutes are the routes supporting the Idempotency-Key header, see
+// middleware.Idempotency. Routes opt in with their pattern, including the routes other services
+// register. Only routes creating resources, which would create duplicates when retried, need to
+// opt in.
+var idempotentRoutes = map[string]bool{
+	"/api/annotations/":                          true,
+	"/api/annotations/graphite":                  true,
+	"/api/dashboards/db":                         true,
+	"/api/dashboards/import":                     true,
+	"/api/folders/":                              true,
+	"/api/orgs":                                  true,
+	"/api/orgs/:orgId/users":                     true,
+	"/api/org/users":                             true,
+	"/api/ruler/grafana/api/v1/rules/:Namespace": true,
+	"/api/v1/provisioning/alert-rules":           true,
+	"/api/v1/provisioning/contact-points":        true,
+	"/api/v1/provisioning/mute-timings":          true,
+}
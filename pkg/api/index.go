@@ -38,6 +38,8 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 	}
 
 	settings["dateFormats"] = hs.Cfg.DateFormats
+	// plugins add the nonce to the scripts and styles they load, so that they are allowed by the Content-Security-Policy
+	settings["cspNonce"] = c.RequestNonce
 
 	prefsQuery := pref.GetPreferenceWithDefaultsQuery{UserID: c.UserID, OrgID: c.OrgID, Teams: c.Teams}
 	prefs, err := hs.preferenceService.GetWithDefaults(c.Req.Context(), &prefsQuery)
@@ -79,6 +81,10 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 		settings["isPublicDashboardView"] = true
 	}
 
+	if c.IsEmbedView {
+		settings["isEmbedView"] = true
+	}
+
 	weekStart := ""
 	if prefs.WeekStart != nil {
 		weekStart = *prefs.WeekStart
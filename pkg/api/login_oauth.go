@@ -242,6 +242,10 @@ func (hs *HTTPServer) OAuthLogin(ctx *models.ReqContext) {
 	}
 
 	loginInfo.ExternalUser = *hs.buildExternalUserInfo(token, userInfo, name)
+	if err := hs.mapOAuthOrgs(ctx.Req.Context(), provider, userInfo, &loginInfo.ExternalUser); err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(ctx, loginInfo, err)
+		return
+	}
 	loginInfo.User, err = hs.SyncUser(ctx, &loginInfo.ExternalUser, connect)
 	if err != nil {
 		hs.handleOAuthLoginErrorWithRedirect(ctx, loginInfo, err)
@@ -307,6 +311,56 @@ func (hs *HTTPServer) buildExternalUserInfo(token *oauth2.Token, userInfo *socia
 	return extUser
 }
 
+// mapOAuthOrgs replaces the organization roles of the external user with the organizations its
+// claims are mapped to, creating the missing organizations if the provider is configured to.
+func (hs *HTTPServer) mapOAuthOrgs(ctx context.Context, provider *social.OAuthInfo, userInfo *social.BasicUserInfo, extUser *models.ExternalUserInfo) error {
+	if len(provider.OrgMapping) == 0 || hs.Cfg.OAuthSkipOrgRoleUpdateSync {
+		return nil
+	}
+
+	claims := userInfo.OrgClaims
+	if len(claims) == 0 {
+		claims = userInfo.Groups
+	}
+	defaultRole := userInfo.Role
+	if !defaultRole.IsValid() {
+		defaultRole = org.RoleType(hs.Cfg.AutoAssignOrgRole)
+	}
+
+	orgRoles := map[int64]org.RoleType{}
+	for _, membership := range social.MapOrgs(provider.OrgMapping, claims, defaultRole) {
+		orgID := membership.OrgID
+		if orgID == 0 {
+			orga, err := hs.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: membership.OrgName})
+			switch {
+			case errors.Is(err, models.ErrOrgNotFound) && provider.OrgAutoCreate:
+				orga, err = hs.orgService.CreateWithMember(ctx, &org.CreateOrgCommand{Name: membership.OrgName})
+				if errors.Is(err, models.ErrOrgNameTaken) {
+					// another login created the organization in the meantime
+					orga, err = hs.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: membership.OrgName})
+				}
+				if err != nil {
+					return err
+				}
+				oauthLogger.Info("Created organization mapped from OAuth claims", "org", orga.Name, "orgId", orga.ID)
+			case errors.Is(err, models.ErrOrgNotFound):
+				oauthLogger.Warn("Organization mapped from OAuth claims does not exist", "org", membership.OrgName)
+				continue
+			case err != nil:
+				return err
+			}
+			orgID = orga.ID
+		}
+		orgRoles[orgID] = membership.Role
+	}
+
+	if len(orgRoles) > 0 {
+		oauthLogger.Debug("Mapped OAuth claims to organizations", "orgRoles", orgRoles)
+		extUser.OrgRoles = orgRoles
+	}
+	return nil
+}
+
 // SyncUser syncs a Grafana user profile with the corresponding OAuth profile.
 func (hs *HTTPServer) SyncUser(
 	ctx *models.ReqContext,
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"net/http"
@@ -14,9 +15,12 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -159,3 +163,72 @@ func TestOAuthLogin_UsePKCE(t *testing.T) {
 		base64.RawURLEncoding.EncodeToString(shasum[:]),
 	)
 }
+
+type fakeMappedOrgService struct {
+	orgtest.FakeOrgService
+	orgs    map[string]int64
+	created []string
+}
+
+func (f *fakeMappedOrgService) GetByName(ctx context.Context, query *org.GetOrgByNameQuery) (*org.Org, error) {
+	if id, ok := f.orgs[query.Name]; ok {
+		return &org.Org{ID: id, Name: query.Name}, nil
+	}
+	return nil, models.ErrOrgNotFound
+}
+
+func (f *fakeMappedOrgService) CreateWithMember(ctx context.Context, cmd *org.CreateOrgCommand) (*org.Org, error) {
+	f.created = append(f.created, cmd.Name)
+	f.orgs[cmd.Name] = int64(len(f.orgs) + 1)
+	return &org.Org{ID: f.orgs[cmd.Name], Name: cmd.Name}, nil
+}
+
+func TestMapOAuthOrgs(t *testing.T) {
+	mapping, err := social.ParseOrgMapping("*:1:Viewer,tenant-*:*,admins:Ops:Admin")
+	require.NoError(t, err)
+
+	setup := func(autoCreate bool) (*HTTPServer, *fakeMappedOrgService, *social.OAuthInfo) {
+		orgService := &fakeMappedOrgService{orgs: map[string]int64{"Main Org.": 1, "Ops": 2}}
+		cfg := setting.NewCfg()
+		cfg.AutoAssignOrgRole = string(org.RoleViewer)
+		return &HTTPServer{Cfg: cfg, orgService: orgService}, orgService, &social.OAuthInfo{OrgMapping: mapping, OrgAutoCreate: autoCreate}
+	}
+
+	t.Run("Should map the claims to the organizations", func(t *testing.T) {
+		hs, orgService, provider := setup(true)
+		extUser := &models.ExternalUserInfo{OrgRoles: map[int64]org.RoleType{1: org.RoleAdmin}}
+		userInfo := &social.BasicUserInfo{Role: org.RoleEditor, OrgClaims: []string{"tenant-globex", "admins"}, Groups: []string{"ignored"}}
+
+		require.NoError(t, hs.mapOAuthOrgs(context.Background(), provider, userInfo, extUser))
+		assert.Equal(t, []string{"globex"}, orgService.created)
+		assert.Equal(t, map[int64]org.RoleType{1: org.RoleViewer, 3: org.RoleEditor, 2: org.RoleAdmin}, extUser.OrgRoles)
+	})
+
+	t.Run("Should map the groups without organization claims", func(t *testing.T) {
+		hs, _, provider := setup(true)
+		extUser := &models.ExternalUserInfo{}
+		userInfo := &social.BasicUserInfo{Groups: []string{"admins"}}
+
+		require.NoError(t, hs.mapOAuthOrgs(context.Background(), provider, userInfo, extUser))
+		assert.Equal(t, map[int64]org.RoleType{1: org.RoleViewer, 2: org.RoleAdmin}, extUser.OrgRoles)
+	})
+
+	t.Run("Should skip missing organizations without auto creation", func(t *testing.T) {
+		hs, orgService, provider := setup(false)
+		extUser := &models.ExternalUserInfo{}
+		userInfo := &social.BasicUserInfo{OrgClaims: []string{"tenant-globex"}}
+
+		require.NoError(t, hs.mapOAuthOrgs(context.Background(), provider, userInfo, extUser))
+		assert.Empty(t, orgService.created)
+		assert.Equal(t, map[int64]org.RoleType{1: org.RoleViewer}, extUser.OrgRoles)
+	})
+
+	t.Run("Should not map organizations if the role sync is skipped", func(t *testing.T) {
+		hs, _, provider := setup(true)
+		hs.Cfg.OAuthSkipOrgRoleUpdateSync = true
+		extUser := &models.ExternalUserInfo{OrgRoles: map[int64]org.RoleType{}}
+
+		require.NoError(t, hs.mapOAuthOrgs(context.Background(), provider, &social.BasicUserInfo{OrgClaims: []string{"admins"}}, extUser))
+		assert.Empty(t, extUser.OrgRoles)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -12,6 +13,8 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/variableconstraints"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -22,6 +25,12 @@ func (hs *HTTPServer) handleQueryMetricsError(err error) *response.NormalRespons
 	if errors.Is(err, datasources.ErrDataSourceNotFound) {
 		return response.Error(http.StatusNotFound, "Data source not found", err)
 	}
+	if errors.Is(err, datasources.ErrUserCredentialsNotFound) {
+		return response.Error(http.StatusForbidden, "Credentials of the user are required to query the data source", err)
+	}
+	if errors.Is(err, variableconstraints.ErrValueNotAllowed) {
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
 
 	var secretsPlugin datasources.ErrDatasourceSecretsPluginUserFriendly
 	if errors.As(err, &secretsPlugin) {
@@ -54,7 +63,18 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext) response.Response {
 
 	reqDTO.HTTPRequest = c.Req
 
+	if hs.variableConstraints != nil {
+		dashboardUID := c.Req.Header.Get(query.HeaderDashboardUID)
+		if err := hs.variableConstraints.ValidateQueries(c.Req.Context(), c.SignedInUser, dashboardUID, reqDTO.Queries); err != nil {
+			return hs.handleQueryMetricsError(err)
+		}
+	}
+
+	start := time.Now()
 	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipCache, reqDTO)
+	if hs.queryCaptureService != nil {
+		hs.queryCaptureService.Capture(c, reqDTO, resp, err, time.Since(start))
+	}
 	if err != nil {
 		return hs.handleQueryMetricsError(err)
 	}
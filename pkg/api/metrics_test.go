@@ -15,6 +15,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
@@ -28,6 +30,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/variableconstraints"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/web/webtest"
@@ -90,6 +93,7 @@ func TestAPIEndpoint_Metrics_QueryMetricsV2(t *testing.T) {
 			},
 		},
 		&fakeOAuthTokenService{},
+		nil,
 	)
 	serverFeatureEnabled := SetupAPITestServer(t, func(hs *HTTPServer) {
 		hs.queryDataService = qds
@@ -139,6 +143,7 @@ func TestAPIEndpoint_Metrics_PluginDecryptionFailure(t *testing.T) {
 			},
 		},
 		&fakeOAuthTokenService{},
+		nil,
 	)
 	httpServer := SetupAPITestServer(t, func(hs *HTTPServer) {
 		hs.queryDataService = qds
@@ -163,6 +168,61 @@ func TestAPIEndpoint_Metrics_PluginDecryptionFailure(t *testing.T) {
 	})
 }
 
+type fakeVariableConstraints struct {
+	variableconstraints.Service
+	dashboardUIDs []string
+}
+
+func (f *fakeVariableConstraints) ValidateQueries(_ context.Context, _ *user.SignedInUser, dashboardUID string, _ []*simplejson.Json) error {
+	f.dashboardUIDs = append(f.dashboardUIDs, dashboardUID)
+	if dashboardUID == "restricted" {
+		return fmt.Errorf("%w: team-b of variable namespace", variableconstraints.ErrValueNotAllowed)
+	}
+	return nil
+}
+
+func TestAPIEndpoint_Metrics_VariableConstraints(t *testing.T) {
+	qds := query.ProvideService(
+		setting.NewCfg(),
+		nil,
+		nil,
+		&fakePluginRequestValidator{},
+		&fakeDatasources.FakeDataSourceService{},
+		&fakePluginClient{
+			QueryDataHandlerFunc: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				return &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{}}}, nil
+			},
+		},
+		&fakeOAuthTokenService{},
+		nil,
+	)
+	constraints := &fakeVariableConstraints{}
+	httpServer := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.queryDataService = qds
+		hs.QuotaService = quotatest.New(false, nil)
+		hs.variableConstraints = constraints
+	})
+
+	send := func(dashboardUID string) int {
+		req := httpServer.NewPostRequest("/api/ds/query", strings.NewReader(reqValid))
+		req.Header.Set(query.HeaderDashboardUID, dashboardUID)
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer})
+		resp, err := httpServer.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	t.Run("Status code is 403 when a query uses a variable value the user is not allowed to select", func(t *testing.T) {
+		require.Equal(t, http.StatusForbidden, send("restricted"))
+	})
+
+	t.Run("Queries of dashboards are validated with the uid of the dashboard", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, send("open"))
+		require.Equal(t, "open", constraints.dashboardUIDs[len(constraints.dashboardUIDs)-1])
+	})
+}
+
 var reqValid = `{
 	"from": "",
 	"to": "",
@@ -291,8 +351,9 @@ func TestDataSourceQueryError(t *testing.T) {
 					nil,
 					&fakePluginRequestValidator{},
 					&fakeDatasources.FakeDataSourceService{},
-					pluginClient.ProvideService(r, &config.Cfg{}),
+					pluginClient.ProvideService(r, &config.Cfg{}, tracing.InitializeTracerForTest()),
 					&fakeOAuthTokenService{},
+					nil,
 				)
 				hs.QuotaService = quotatest.New(false, nil)
 			})
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (hs *HTTPServer) GetOrgNetworkPolicy(c *models.ReqContext) response.Response {
+	policy, err := hs.networkPolicyService.GetPolicy(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get network policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// UpdateOrgNetworkPolicy replaces the network policy of the organization. A policy which would
+// deny the address of the request is rejected, so that organization admins cannot lock
+// themselves out.
+func (hs *HTTPServer) UpdateOrgNetworkPolicy(c *models.ReqContext) response.Response {
+	cmd := networkpolicy.SetPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	// the address the policies are checked against, so that a forged header cannot pass the check
+	cmd.RequesterAddr = network.ClientIP(c.Req, hs.Cfg.TrustedProxies)
+
+	policy, err := hs.networkPolicyService.SetPolicy(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, networkpolicy.ErrInvalidCIDR) || errors.Is(err, networkpolicy.ErrRequesterDenied) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update network policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// GetOrgNetworkPolicyRejections lists the requests rejected by the network policies of the
+// organization and of its API keys, newest first.
+func (hs *HTTPServer) GetOrgNetworkPolicyRejections(c *models.ReqContext) response.Response {
+	result, err := hs.networkPolicyService.SearchRejections(c.Req.Context(), networkpolicy.SearchRejectionsQuery{
+		OrgID:   c.OrgID,
+		Page:    c.QueryInt("page"),
+		PerPage: c.QueryInt("perpage"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get rejected requests", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
@@ -301,6 +302,13 @@ func (hs *HTTPServer) DeleteOrgByID(c *models.ReqContext) response.Response {
 	return response.Success("Organization deleted")
 }
 
+var orgsPagination = pagination.Config{
+	SortFields:   []string{"name"},
+	DefaultSort:  pagination.Sort{Field: "name"},
+	DefaultLimit: 1000,
+	MaxLimit:     5000,
+}
+
 // swagger:route GET /orgs orgs searchOrgs
 //
 // Search all Organizations.
@@ -315,18 +323,18 @@ func (hs *HTTPServer) DeleteOrgByID(c *models.ReqContext) response.Response {
 // 409: conflictError
 // 500: internalServerError
 func (hs *HTTPServer) SearchOrgs(c *models.ReqContext) response.Response {
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+	paging, err := orgsPagination.Parse(c.Req.URL.Query())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
 	}
 
-	page := c.QueryInt("page")
-
 	query := org.SearchOrgsQuery{
 		Query: c.Query("query"),
 		Name:  c.Query("name"),
-		Page:  page,
-		Limit: perPage,
+		Page:  paging.Page,
+		Limit: paging.Limit,
+		Sort:  paging.Sort,
+		After: paging.After,
 	}
 
 	result, err := hs.orgService.Search(c.Req.Context(), &query)
@@ -334,6 +342,11 @@ func (hs *HTTPServer) SearchOrgs(c *models.ReqContext) response.Response {
 		return response.Error(http.StatusInternalServerError, "Failed to search orgs", err)
 	}
 
+	if n := len(result); n > 0 {
+		if next := paging.NextCursor(n, result[n-1].Name, result[n-1].ID); next != "" {
+			c.Resp.Header().Set(pagination.NextCursorHeader, next)
+		}
+	}
 	return response.JSON(http.StatusOK, result)
 }
 
@@ -425,6 +438,15 @@ type SearchOrgParams struct {
 	// If set it will return results where the query value is contained in the name field. Query values with spaces need to be URL encoded.
 	// required:false
 	Query string `json:"query"`
+	// Sort the organizations by name, e.g. `name-desc`.
+	// in:query
+	// required:false
+	// default: name-asc
+	Sort string `json:"sort"`
+	// Return the page after this cursor, the X-Grafana-Next-Cursor header of the previous page.
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
 }
 
 // swagger:response createOrgResponse
@@ -113,6 +113,7 @@ func (hs *HTTPServer) AddOrgInvite(c *models.ReqContext) response.Response {
 		emailCmd := models.SendEmailCommand{
 			To:       []string{inviteDto.LoginOrEmail},
 			Template: "new_user_invite",
+			OrgID:    c.OrgID,
 			Data: map[string]interface{}{
 				"Name":      util.StringsFallback2(cmd.Name, cmd.Email),
 				"OrgName":   c.OrgName,
@@ -155,6 +156,7 @@ func (hs *HTTPServer) inviteExistingUserToOrg(c *models.ReqContext, user *user.U
 		emailCmd := models.SendEmailCommand{
 			To:       []string{user.Email},
 			Template: "invited_to_org",
+			OrgID:    c.OrgID,
 			Data: map[string]interface{}{
 				"Name":      user.NameOrFallback(),
 				"OrgName":   c.OrgName,
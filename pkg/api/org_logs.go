@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/orglogs"
+)
+
+// swagger:route GET /org/logs org getOrgLogs
+//
+// Get the log lines of the current organization.
+//
+// Returns the most recent log lines of the requests made in the current organization, oldest first.
+// With `follow=true`, the log lines are streamed as they are logged, as one JSON object per line,
+// until the client disconnects. Requires the `orgLogStreaming` feature toggle.
+//
+// Responses:
+// 200: getOrgLogsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) GetOrgLogs(c *models.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if !c.QueryBool("follow") {
+		return response.JSON(http.StatusOK, hs.orgLogs.Recent(c.OrgID, limit))
+	}
+
+	recent, lines, unsubscribe := hs.orgLogs.Subscribe(c.OrgID, limit)
+	defer unsubscribe()
+
+	c.Resp.Header().Set("Content-Type", "application/x-ndjson")
+	c.Resp.Header().Set("Cache-Control", "no-cache")
+	c.Resp.Header().Set("X-Accel-Buffering", "no")
+	c.Resp.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(c.Resp)
+	write := func(line orglogs.Line) bool {
+		if err := enc.Encode(line); err != nil {
+			return false
+		}
+		c.Resp.Flush()
+		return true
+	}
+	for _, line := range recent {
+		if !write(line) {
+			return nil
+		}
+	}
+	c.Resp.Flush()
+
+	timeout := time.NewTimer(hs.Cfg.OrgLogs.MaxStreamDuration)
+	defer timeout.Stop()
+	for {
+		select {
+		case line := <-lines:
+			if !write(line) {
+				return nil
+			}
+		case <-timeout.C:
+			return nil
+		case <-c.Req.Context().Done():
+			return nil
+		}
+	}
+}
+
+// swagger:parameters getOrgLogs
+type GetOrgLogsParams struct {
+	// Maximum number of recent log lines to return, all the kept lines by default.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+	// Stream the log lines as they are logged after the recent ones.
+	// in:query
+	// required:false
+	Follow bool `json:"follow"`
+}
+
+// swagger:response getOrgLogsResponse
+type GetOrgLogsResponse struct {
+	// in:body
+	Body []orglogs.Line `json:"body"`
+}
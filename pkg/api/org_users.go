@@ -6,8 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -114,18 +119,24 @@ func (hs *HTTPServer) addOrgUserHelper(c *models.ReqContext, cmd org.AddOrgUserC
 // 403: forbiddenError
 // 500: internalServerError
 func (hs *HTTPServer) GetOrgUsersForCurrentOrg(c *models.ReqContext) response.Response {
+	filter, err := parseOrgUsersFilter(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+
 	result, err := hs.getOrgUsersHelper(c, &org.GetOrgUsersQuery{
-		OrgID: c.OrgID,
-		Query: c.Query("query"),
-		Limit: c.QueryInt("limit"),
-		User:  c.SignedInUser,
+		OrgID:          c.OrgID,
+		Query:          c.Query("query"),
+		Limit:          c.QueryInt("limit"),
+		OrgUsersFilter: filter,
+		User:           c.SignedInUser,
 	}, c.SignedInUser)
 
 	if err != nil {
 		return response.Error(500, "Failed to get users for current organization", err)
 	}
 
-	return response.JSON(http.StatusOK, result)
+	return jsonWithFields(c, result, "")
 }
 
 // swagger:route GET /org/users/lookup org getOrgUsersForCurrentOrgLookup
@@ -189,18 +200,24 @@ func (hs *HTTPServer) GetOrgUsers(c *models.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
 	}
 
+	filter, err := parseOrgUsersFilter(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+
 	result, err := hs.getOrgUsersHelper(c, &org.GetOrgUsersQuery{
-		OrgID: orgId,
-		Query: "",
-		Limit: 0,
-		User:  c.SignedInUser,
+		OrgID:          orgId,
+		Query:          "",
+		Limit:          0,
+		OrgUsersFilter: filter,
+		User:           c.SignedInUser,
 	}, c.SignedInUser)
 
 	if err != nil {
 		return response.Error(500, "Failed to get users for organization", err)
 	}
 
-	return response.JSON(http.StatusOK, result)
+	return jsonWithFields(c, result, "")
 }
 
 func (hs *HTTPServer) getOrgUsersHelper(c *models.ReqContext, query *org.GetOrgUsersQuery, signedInUser *user.SignedInUser) ([]*org.OrgUserDTO, error) {
@@ -246,12 +263,18 @@ func (hs *HTTPServer) SearchOrgUsersWithPaging(c *models.ReqContext) response.Re
 		page = 1
 	}
 
+	filter, err := parseOrgUsersFilter(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+
 	query := &org.SearchOrgUsersQuery{
-		OrgID: c.OrgID,
-		Query: c.Query("query"),
-		Page:  page,
-		Limit: perPage,
-		User:  c.SignedInUser,
+		OrgID:          c.OrgID,
+		Query:          c.Query("query"),
+		Page:           page,
+		Limit:          perPage,
+		OrgUsersFilter: filter,
+		User:           c.SignedInUser,
 	}
 
 	result, err := hs.orgService.SearchOrgUsers(ctx, query)
@@ -273,7 +296,66 @@ func (hs *HTTPServer) SearchOrgUsersWithPaging(c *models.ReqContext) response.Re
 	result.Page = page
 	result.PerPage = perPage
 
-	return response.JSON(http.StatusOK, result)
+	return jsonWithFields(c, result, "OrgUsers")
+}
+
+// parseOrgUsersFilter reads the filters of the org users from the `role` (repeatable), `disabled`,
+// `authModule`, `lastSeenWithin` and `notSeenWithin` (durations such as 30d) and `sort` query parameters.
+func parseOrgUsersFilter(c *models.ReqContext) (org.OrgUsersFilter, error) {
+	filter := org.OrgUsersFilter{AuthModule: c.Query("authModule")}
+
+	for _, role := range c.QueryStrings("role") {
+		if !org.RoleType(role).IsValid() {
+			return filter, fmt.Errorf("invalid role %q", role)
+		}
+		filter.Roles = append(filter.Roles, org.RoleType(role))
+	}
+
+	if disabled := c.Query("disabled"); disabled != "" {
+		isDisabled, err := strconv.ParseBool(disabled)
+		if err != nil {
+			return filter, fmt.Errorf("invalid disabled %q", disabled)
+		}
+		filter.IsDisabled = &isDisabled
+	}
+
+	now := time.Now()
+	if within := c.Query("lastSeenWithin"); within != "" {
+		d, err := gtime.ParseDuration(within)
+		if err != nil {
+			return filter, fmt.Errorf("invalid lastSeenWithin %q", within)
+		}
+		filter.LastSeenAfter = now.Add(-d)
+	}
+	if within := c.Query("notSeenWithin"); within != "" {
+		d, err := gtime.ParseDuration(within)
+		if err != nil {
+			return filter, fmt.Errorf("invalid notSeenWithin %q", within)
+		}
+		filter.LastSeenBefore = now.Add(-d)
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		var err error
+		filter.Sort, err = pagination.ParseSort(sort)
+		if err != nil {
+			return filter, err
+		}
+		if !isOrgUserSortField(filter.Sort.Field) {
+			return filter, fmt.Errorf("%w: can only sort by %s", pagination.ErrInvalidSort, strings.Join(org.OrgUserSortFields, ", "))
+		}
+	}
+
+	return filter, nil
+}
+
+func isOrgUserSortField(field string) bool {
+	for _, f := range org.OrgUserSortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
 }
 
 // swagger:route PATCH /org/users/{user_id} org updateOrgUserForCurrentOrg
@@ -357,8 +439,11 @@ func (hs *HTTPServer) updateOrgUserHelper(c *models.ReqContext, cmd org.UpdateOr
 // If you are running Grafana Enterprise and have Fine-grained access control enabled
 // you need to have a permission with action: `org.users:remove` with scope `users:*`.
 //
+// The response lists the service accounts and dashboards the user had permissions on, and the organization tokens they created.
+// With `preview`, the user is not removed. With `revoke`, the tokens of the service accounts no other user or team has permissions on are revoked and the organization tokens created by the user are deleted.
+//
 // Responses:
-// 200: okResponse
+// 200: removeOrgUserResponse
 // 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
@@ -373,6 +458,8 @@ func (hs *HTTPServer) RemoveOrgUserForCurrentOrg(c *models.ReqContext) response.
 		UserID:                   userId,
 		OrgID:                    c.OrgID,
 		ShouldDeleteOrphanedUser: true,
+		Preview:                  c.QueryBool("preview"),
+		RevokeOrphanedAccess:     c.QueryBool("revoke"),
 	})
 }
 
@@ -383,8 +470,11 @@ func (hs *HTTPServer) RemoveOrgUserForCurrentOrg(c *models.ReqContext) response.
 // If you are running Grafana Enterprise and have Fine-grained access control enabled
 // you need to have a permission with action: `org.users:remove` with scope `users:*`.
 //
+// The response lists the service accounts and dashboards the user had permissions on, and the organization tokens they created.
+// With `preview`, the user is not removed. With `revoke`, the tokens of the service accounts no other user or team has permissions on are revoked and the organization tokens created by the user are deleted.
+//
 // Responses:
-// 200: okResponse
+// 200: removeOrgUserResponse
 // 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
@@ -399,8 +489,10 @@ func (hs *HTTPServer) RemoveOrgUser(c *models.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
 	}
 	return hs.removeOrgUserHelper(c.Req.Context(), &org.RemoveOrgUserCommand{
-		UserID: userId,
-		OrgID:  orgId,
+		UserID:               userId,
+		OrgID:                orgId,
+		Preview:              c.QueryBool("preview"),
+		RevokeOrphanedAccess: c.QueryBool("revoke"),
 	})
 }
 
@@ -412,12 +504,16 @@ func (hs *HTTPServer) removeOrgUserHelper(ctx context.Context, cmd *org.RemoveOr
 		return response.Error(500, "Failed to remove user from organization", err)
 	}
 
+	if cmd.Preview {
+		return response.JSON(http.StatusOK, util.DynMap{"message": "User can be removed from organization", "inventory": cmd.Inventory})
+	}
+
 	if cmd.UserWasDeleted {
 		// This should be called from appropriate service when moved
 		if err := hs.accesscontrolService.DeleteUserPermissions(ctx, accesscontrol.GlobalOrgID, cmd.UserID); err != nil {
 			hs.log.Warn("failed to delete permissions for user", "userID", cmd.UserID, "orgID", accesscontrol.GlobalOrgID, "err", err)
 		}
-		return response.Success("User deleted")
+		return response.JSON(http.StatusOK, util.DynMap{"message": "User deleted", "inventory": cmd.Inventory})
 	}
 
 	// This should be called from appropriate service when moved
@@ -425,7 +521,7 @@ func (hs *HTTPServer) removeOrgUserHelper(ctx context.Context, cmd *org.RemoveOr
 		hs.log.Warn("failed to delete permissions for user", "userID", cmd.UserID, "orgID", cmd.OrgID, "err", err)
 	}
 
-	return response.Success("User removed from organization")
+	return response.JSON(http.StatusOK, util.DynMap{"message": "User removed from organization", "inventory": cmd.Inventory})
 }
 
 // swagger:parameters addOrgUserToCurrentOrg
@@ -460,6 +556,54 @@ type GetOrgUsersParams struct {
 	// in:path
 	// required:true
 	OrgID int64 `json:"org_id"`
+	// Fields of the items to return, separated by commas, e.g. `userId,login,role`. All fields are returned by default.
+	// in:query
+	// required:false
+	// type: array
+	// collectionFormat: csv
+	Fields []string `json:"fields"`
+	OrgUsersFilterParams
+}
+
+// swagger:parameters getOrgUsersForCurrentOrg
+type GetOrgUsersForCurrentOrgParams struct {
+	// in:query
+	// required:false
+	Query string `json:"query"`
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+	OrgUsersFilterParams
+}
+
+// OrgUsersFilterParams are the filters of the org users.
+type OrgUsersFilterParams struct {
+	// Only return the users with any of the roles in the organization.
+	// in:query
+	// required:false
+	// type: array
+	// collectionFormat: multi
+	Role []string `json:"role"`
+	// Only return the disabled (true) or enabled (false) users.
+	// in:query
+	// required:false
+	Disabled *bool `json:"disabled"`
+	// Only return the users who last logged in with the auth module, e.g. `ldap` or `oauth_github`, `oauth` for any OAuth provider or `local` for users without an external auth module.
+	// in:query
+	// required:false
+	AuthModule string `json:"authModule"`
+	// Only return the users seen within the duration, e.g. `30d`.
+	// in:query
+	// required:false
+	LastSeenWithin string `json:"lastSeenWithin"`
+	// Only return the users not seen within the duration, e.g. `90d`.
+	// in:query
+	// required:false
+	NotSeenWithin string `json:"notSeenWithin"`
+	// Sort the users by `login`, `email`, `name`, `role` or `lastSeenAt`, ascending or descending, e.g. `lastSeenAt-desc`.
+	// in:query
+	// required:false
+	Sort string `json:"sort"`
 }
 
 // swagger:parameters updateOrgUserForCurrentOrg
@@ -490,6 +634,7 @@ type RemoveOrgUserForCurrentOrgParams struct {
 	// in:path
 	// required:true
 	UserID int64 `json:"user_id"`
+	RemoveOrgUserQueryParams
 }
 
 // swagger:parameters removeOrgUser
@@ -500,6 +645,28 @@ type RemoveOrgUserParams struct {
 	// in:path
 	// required:true
 	UserID int64 `json:"user_id"`
+	RemoveOrgUserQueryParams
+}
+
+type RemoveOrgUserQueryParams struct {
+	// Only return the inventory of the access of the user, without removing the user.
+	// in:query
+	// required:false
+	Preview bool `json:"preview"`
+	// Revoke the tokens of the service accounts no other user or team has permissions on, and delete the organization tokens created by the user.
+	// in:query
+	// required:false
+	Revoke bool `json:"revoke"`
+}
+
+// swagger:response removeOrgUserResponse
+type RemoveOrgUserResponse struct {
+	// in: body
+	Body struct {
+		// example: User removed from organization
+		Message   string                      `json:"message"`
+		Inventory *org.OrgUserAccessInventory `json:"inventory"`
+	} `json:"body"`
 }
 
 // swagger:response getOrgUsersForCurrentOrgLookupResponse
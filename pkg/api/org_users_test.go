@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
@@ -137,6 +138,40 @@ func TestOrgUsersAPIEndpoint_userLoggedIn(t *testing.T) {
 		assert.Equal(t, 2, resp.Page)
 	}, mock)
 
+	loggedInUserScenario(t, "When calling GET with fields query parameter on", "api/org/users/search", "api/org/users/search", func(sc *scenarioContext) {
+		orgService.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{
+			OrgUsers:   []*org.OrgUserDTO{{UserID: 2, Login: "user1", Email: "user1@grafana.com"}},
+			TotalCount: 1,
+		}
+
+		sc.handlerFunc = hs.SearchOrgUsersWithPaging
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"fields": "login,userId"}).exec()
+
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.JSONEq(t, `{"totalCount":1,"OrgUsers":[{"login":"user1","userId":2}],"page":1,"perPage":1000}`, sc.resp.Body.String())
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"fields": "login,"}).exec()
+		require.Equal(t, http.StatusBadRequest, sc.resp.Code)
+	}, mock)
+
+	loggedInUserScenario(t, "When calling GET with invalid filters on", "api/org/users/search", "api/org/users/search", func(sc *scenarioContext) {
+		orgService.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{}
+
+		sc.handlerFunc = hs.SearchOrgUsersWithPaging
+		for _, params := range []map[string]string{
+			{"role": "Owner"},
+			{"disabled": "maybe"},
+			{"lastSeenWithin": "soon"},
+			{"sort": "password-asc"},
+		} {
+			sc.fakeReqWithParams("GET", sc.url, params).exec()
+			assert.Equal(t, http.StatusBadRequest, sc.resp.Code, params)
+		}
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"role": "Editor", "disabled": "false", "authModule": "oauth", "lastSeenWithin": "30d", "sort": "lastSeenAt-desc"}).exec()
+		assert.Equal(t, http.StatusOK, sc.resp.Code)
+	}, mock)
+
 	t.Run("Given there are two hidden users", func(t *testing.T) {
 		settings.HiddenUsers = map[string]struct{}{
 			"user1":       {},
@@ -377,10 +412,12 @@ func TestGetOrgUsersAPIEndpoint_AccessControlMetadata(t *testing.T) {
 			enableAccessControl: true,
 			expectedCode:        http.StatusOK,
 			expectedMetadata: map[string]bool{
-				"org.users:write":  true,
-				"org.users:add":    true,
-				"org.users:read":   true,
-				"org.users:remove": true},
+				"org.users:write":    true,
+				"org.users:add":      true,
+				"org.users:read":     true,
+				"org.users:remove":   true,
+				"users.roles:add":    true,
+				"users.roles:remove": true},
 			user:      testServerAdminViewer,
 			targetOrg: testServerAdminViewer.OrgID,
 		},
@@ -395,7 +432,7 @@ func TestGetOrgUsersAPIEndpoint_AccessControlMetadata(t *testing.T) {
 				hs.userService, err = userimpl.ProvideService(
 					hs.SQLStore, nil, cfg, teamimpl.ProvideService(hs.SQLStore.(*sqlstore.SQLStore), cfg), localcache.ProvideService(), quotatest.New(false, nil))
 				require.NoError(t, err)
-				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotatest.New(false, nil))
+				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotatest.New(false, nil), actest.FakeService{})
 				require.NoError(t, err)
 			})
 			setupOrgUsersDBForAccessControlTests(t, sc.db, sc.hs.orgService)
@@ -502,7 +539,7 @@ func TestGetOrgUsersAPIEndpoint_AccessControl(t *testing.T) {
 				hs.userService, err = userimpl.ProvideService(
 					hs.SQLStore, nil, cfg, teamimpl.ProvideService(hs.SQLStore.(*sqlstore.SQLStore), cfg), localcache.ProvideService(), quotaService)
 				require.NoError(t, err)
-				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotaService)
+				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotaService, actest.FakeService{})
 				require.NoError(t, err)
 			})
 			setInitCtxSignedInUser(sc.initCtx, tc.user)
@@ -606,7 +643,7 @@ func TestPostOrgUsersAPIEndpoint_AccessControl(t *testing.T) {
 			cfg.RBACEnabled = tc.enableAccessControl
 			var err error
 			sc := setupHTTPServerWithCfg(t, false, cfg, func(hs *HTTPServer) {
-				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotatest.New(false, nil))
+				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotatest.New(false, nil), actest.FakeService{})
 				require.NoError(t, err)
 				hs.userService, err = userimpl.ProvideService(
 					hs.SQLStore, hs.orgService, cfg, teamimpl.ProvideService(hs.SQLStore.(*sqlstore.SQLStore), cfg), localcache.ProvideService(), quotatest.New(false, nil))
@@ -851,7 +888,7 @@ func TestPatchOrgUsersAPIEndpoint_AccessControl(t *testing.T) {
 				hs.userService, err = userimpl.ProvideService(
 					hs.SQLStore, nil, cfg, teamimpl.ProvideService(hs.SQLStore.(*sqlstore.SQLStore), cfg), localcache.ProvideService(), quotaService)
 				require.NoError(t, err)
-				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotaService)
+				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotaService, actest.FakeService{})
 				require.NoError(t, err)
 			})
 			setupOrgUsersDBForAccessControlTests(t, sc.db, sc.hs.orgService)
@@ -981,7 +1018,7 @@ func TestDeleteOrgUsersAPIEndpoint_AccessControl(t *testing.T) {
 				hs.userService, err = userimpl.ProvideService(
 					hs.SQLStore, nil, cfg, teamimpl.ProvideService(hs.SQLStore.(*sqlstore.SQLStore), cfg), localcache.ProvideService(), quotaService)
 				require.NoError(t, err)
-				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotaService)
+				hs.orgService, err = orgimpl.ProvideService(hs.SQLStore, cfg, quotaService, actest.FakeService{})
 				require.NoError(t, err)
 			})
 			setupOrgUsersDBForAccessControlTests(t, sc.db, sc.hs.orgService)
@@ -992,10 +1029,15 @@ func TestDeleteOrgUsersAPIEndpoint_AccessControl(t *testing.T) {
 
 			if tc.expectedCode != http.StatusForbidden {
 				// Check result
-				var message util.DynMap
+				var message struct {
+					Message   string          `json:"message"`
+					Inventory json.RawMessage `json:"inventory"`
+				}
 				err := json.NewDecoder(response.Body).Decode(&message)
 				require.NoError(t, err)
-				assert.Equal(t, tc.expectedMessage, message)
+				assert.Equal(t, tc.expectedMessage["message"], message.Message)
+				// the removed users have no access beyond their membership, and nothing is revoked unless requested
+				assert.JSONEq(t, `{"serviceAccounts": [], "orgTokens": [], "dashboards": [], "revoked": false}`, string(message.Inventory))
 			}
 		})
 	}
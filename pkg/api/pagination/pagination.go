@@ -0,0 +1,208 @@
+// Package pagination implements the paging and sorting parameters shared by the list APIs.
+//
+// List APIs support offset pagination with the `page` parameter and keyset pagination with the
+// `cursor` parameter. Keyset pagination is stable while items are created or deleted: the cursor
+// returned as `nextCursor` with a page holds the sort value and ID of the last item, and the next
+// page starts right after it.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NextCursorHeader is the header returning the cursor of the next page for APIs responding with a
+// plain list of items.
+const NextCursorHeader = "X-Grafana-Next-Cursor"
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidLimit  = errors.New("invalid limit")
+)
+
+// IsInvalid returns whether the error is caused by invalid paging parameters.
+func IsInvalid(err error) bool {
+	return errors.Is(err, ErrInvalidCursor) || errors.Is(err, ErrInvalidSort) || errors.Is(err, ErrInvalidLimit)
+}
+
+// Sort is the order of a list by a field, e.g. `login-asc` or `time-desc`.
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSort parses a sort in the `<field>-asc` or `<field>-desc` format. A field without
+// direction is sorted ascending.
+func ParseSort(s string) (Sort, error) {
+	field, direction := s, "asc"
+	if i := strings.LastIndex(s, "-"); i >= 0 {
+		field, direction = s[:i], s[i+1:]
+	}
+	if field == "" || (direction != "asc" && direction != "desc") {
+		return Sort{}, fmt.Errorf("%w: %q", ErrInvalidSort, s)
+	}
+	return Sort{Field: field, Descending: direction == "desc"}, nil
+}
+
+func (s Sort) String() string {
+	if s.Descending {
+		return s.Field + "-desc"
+	}
+	return s.Field + "-asc"
+}
+
+// Cursor is the position after the last item of a page. ID breaks ties between items with the
+// same sort value.
+type Cursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    int64       `json:"id"`
+}
+
+// Encode returns the opaque representation of the cursor used in the API.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decodes a cursor returned by Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+	var c Cursor
+	if err := dec.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	switch v := c.Value.(type) {
+	case string:
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		c.Value = n
+	default:
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Config is the paging configuration of a list API.
+type Config struct {
+	// SortFields are the fields the list can be sorted by.
+	SortFields  []string
+	DefaultSort Sort
+	// DefaultLimit is the number of items returned without `limit` parameter.
+	DefaultLimit int
+	// MaxLimit caps the number of items returned per page, unlimited if zero.
+	MaxLimit int
+}
+
+// Params are the paging parameters of a request.
+type Params struct {
+	Limit int
+	// Page is the page requested with offset pagination, ignored if After is set.
+	Page  int
+	Sort  Sort
+	After *Cursor
+}
+
+// Parse returns the paging parameters of a request from the `limit` (or `perpage`), `page`,
+// `sort` and `cursor` query parameters.
+func (cfg Config) Parse(values url.Values) (Params, error) {
+	p := Params{Limit: cfg.DefaultLimit, Sort: cfg.DefaultSort}
+
+	limit := values.Get("limit")
+	if limit == "" {
+		limit = values.Get("perpage")
+	}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("%w: %q", ErrInvalidLimit, limit)
+		}
+		if n > 0 {
+			p.Limit = n
+		}
+	}
+	if cfg.MaxLimit > 0 && p.Limit > cfg.MaxLimit {
+		p.Limit = cfg.MaxLimit
+	}
+
+	if page := values.Get("page"); page != "" {
+		p.Page, _ = strconv.Atoi(page)
+	}
+
+	if s := values.Get("sort"); s != "" {
+		sort, err := ParseSort(s)
+		if err != nil {
+			return p, err
+		}
+		if !cfg.isSortField(sort.Field) {
+			return p, fmt.Errorf("%w: can only sort by %s", ErrInvalidSort, strings.Join(cfg.SortFields, ", "))
+		}
+		p.Sort = sort
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return p, err
+		}
+		if after.Sort != p.Sort.String() {
+			return p, fmt.Errorf("%w: the cursor was returned for sort %q", ErrInvalidCursor, after.Sort)
+		}
+		p.After = after
+	}
+
+	return p, nil
+}
+
+func (cfg Config) isSortField(field string) bool {
+	for _, f := range cfg.SortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// NextCursor returns the cursor of the page after a page of count items whose last item has the
+// given sort value and ID, or an empty string if there is no next page.
+func (p Params) NextCursor(count int, value interface{}, id int64) string {
+	if p.Limit <= 0 || count < p.Limit {
+		return ""
+	}
+	return Cursor{Sort: p.Sort.String(), Value: value, ID: id}.Encode()
+}
+
+// Where returns the SQL condition selecting the items after the cursor when sorting by column and
+// breaking ties by idColumn.
+func Where(sort Sort, after *Cursor, column, idColumn string) (string, []interface{}) {
+	op := ">"
+	if sort.Descending {
+		op = "<"
+	}
+	sql := fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", column, idColumn, op)
+	return sql, []interface{}{after.Value, after.Value, after.ID}
+}
+
+// OrderBy returns the SQL ORDER BY expression, without keyword, sorting by column and breaking ties
+// by idColumn.
+func OrderBy(sort Sort, column, idColumn string) string {
+	direction := "ASC"
+	if sort.Descending {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s, %s %s", column, direction, idColumn, direction)
+}
@@ -0,0 +1,88 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	cfg := Config{
+		SortFields:   []string{"login", "email"},
+		DefaultSort:  Sort{Field: "login"},
+		DefaultLimit: 100,
+		MaxLimit:     500,
+	}
+	cursor := Cursor{Sort: "email-desc", Value: "admin@example.com", ID: 7}.Encode()
+
+	testCases := []struct {
+		desc     string
+		query    string
+		expected Params
+		err      error
+	}{
+		{desc: "defaults", query: "", expected: Params{Limit: 100, Sort: Sort{Field: "login"}}},
+		{desc: "limit and page", query: "limit=10&page=3", expected: Params{Limit: 10, Page: 3, Sort: Sort{Field: "login"}}},
+		{desc: "perpage", query: "perpage=20", expected: Params{Limit: 20, Sort: Sort{Field: "login"}}},
+		{desc: "sort", query: "sort=email-desc", expected: Params{Limit: 100, Sort: Sort{Field: "email", Descending: true}}},
+		{desc: "sort without direction", query: "sort=email", expected: Params{Limit: 100, Sort: Sort{Field: "email"}}},
+		{
+			desc:     "cursor",
+			query:    "sort=email-desc&cursor=" + cursor,
+			expected: Params{Limit: 100, Sort: Sort{Field: "email", Descending: true}, After: &Cursor{Sort: "email-desc", Value: "admin@example.com", ID: 7}},
+		},
+		{desc: "limit above max", query: "limit=501", expected: Params{Limit: 500, Sort: Sort{Field: "login"}}},
+		{desc: "invalid limit", query: "limit=ten", err: ErrInvalidLimit},
+		{desc: "unknown sort field", query: "sort=password-asc", err: ErrInvalidSort},
+		{desc: "invalid sort direction", query: "sort=login-up", err: ErrInvalidSort},
+		{desc: "cursor for other sort", query: "cursor=" + cursor, err: ErrInvalidCursor},
+		{desc: "invalid cursor", query: "cursor=abc", err: ErrInvalidCursor},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+			p, err := cfg.Parse(values)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	c, err := DecodeCursor(Cursor{Sort: "time-desc", Value: int64(1665000000000), ID: 3}.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, &Cursor{Sort: "time-desc", Value: int64(1665000000000), ID: 3}, c)
+
+	_, err = DecodeCursor(Cursor{Sort: "time-desc", Value: 1.5, ID: 3}.Encode())
+	require.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = DecodeCursor(Cursor{Sort: "time-desc", ID: 3}.Encode())
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestParams_NextCursor(t *testing.T) {
+	p := Params{Limit: 2, Sort: Sort{Field: "name"}}
+	assert.Empty(t, p.NextCursor(1, "a", 1))
+
+	next, err := DecodeCursor(p.NextCursor(2, "b", 2))
+	require.NoError(t, err)
+	assert.Equal(t, &Cursor{Sort: "name-asc", Value: "b", ID: 2}, next)
+}
+
+func TestWhere(t *testing.T) {
+	after := &Cursor{Value: "b", ID: 2}
+	sql, params := Where(Sort{Field: "name"}, after, "t.name", "t.id")
+	assert.Equal(t, "(t.name > ? OR (t.name = ? AND t.id > ?))", sql)
+	assert.Equal(t, []interface{}{"b", "b", int64(2)}, params)
+
+	sql, _ = Where(Sort{Field: "name", Descending: true}, after, "t.name", "t.id")
+	assert.Equal(t, "(t.name < ? OR (t.name = ? AND t.id < ?))", sql)
+	assert.Equal(t, "t.name DESC, t.id DESC", OrderBy(Sort{Field: "name", Descending: true}, "t.name", "t.id"))
+}
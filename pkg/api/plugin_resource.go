@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/variablecache"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -87,6 +88,13 @@ func (hs *HTTPServer) callPluginResourceWithDataSource(c *models.ReqContext, plu
 				req.Header.Add("X-ID-Token", idToken)
 			}
 		}
+	} else if hs.variableCache != nil {
+		// Responses of data sources forwarding the OAuth token of the user depend on the user and
+		// are never cached.
+		if key, ok := hs.variableCache.Key(c.OrgID, ds, req); ok {
+			hs.callCachedPluginResource(c, req, pCtx, key)
+			return
+		}
 	}
 
 	if err = hs.makePluginResourceRequest(c.Resp, req, pCtx); err != nil {
@@ -94,6 +102,27 @@ func (hs *HTTPServer) callPluginResourceWithDataSource(c *models.ReqContext, plu
 	}
 }
 
+// callCachedPluginResource serves a resource call from the variable cache, or calls the plugin and
+// caches its response. Requests with a Cache-Control: no-cache header always call the plugin.
+func (hs *HTTPServer) callCachedPluginResource(c *models.ReqContext, req *http.Request, pCtx backend.PluginContext, key string) {
+	if c.Req.Header.Get("Cache-Control") != "no-cache" {
+		if entry, ok := hs.variableCache.Get(key); ok {
+			if err := entry.Write(c.Resp); err != nil {
+				hs.log.Error("Failed to write cached resource response", "err", err)
+			}
+			return
+		}
+	}
+
+	c.Resp.Header().Set(variablecache.HeaderCache, "MISS")
+	recorder := variablecache.NewRecorder(c.Resp)
+	if err := hs.makePluginResourceRequest(recorder, req, pCtx); err != nil {
+		handleCallResourceError(err, c)
+		return
+	}
+	hs.variableCache.Set(key, recorder.Entry())
+}
+
 func (hs *HTTPServer) pluginResourceRequest(c *models.ReqContext) (*http.Request, error) {
 	clonedReq := c.Req.Clone(c.Req.Context())
 	rawURL := web.Params(c.Req)["*"]
@@ -140,11 +140,15 @@ func (proxy *DataSourceProxy) HandleRequest() {
 
 	span.SetAttributes("datasource_name", proxy.ds.Name, attribute.Key("datasource_name").String(proxy.ds.Name))
 	span.SetAttributes("datasource_type", proxy.ds.Type, attribute.Key("datasource_type").String(proxy.ds.Type))
+	span.SetAttributes("datasource_uid", proxy.ds.Uid, attribute.Key("datasource_uid").String(proxy.ds.Uid))
 	span.SetAttributes("user", proxy.ctx.SignedInUser.Login, attribute.Key("user").String(proxy.ctx.SignedInUser.Login))
 	span.SetAttributes("org_id", proxy.ctx.SignedInUser.OrgID, attribute.Key("org_id").Int64(proxy.ctx.SignedInUser.OrgID))
 
 	proxy.addTraceFromHeaderValue(span, "X-Panel-Id", "panel_id")
 	proxy.addTraceFromHeaderValue(span, "X-Dashboard-Id", "dashboard_id")
+	if dashboardUID := proxy.ctx.Req.Header.Get("X-Dashboard-Uid"); dashboardUID != "" {
+		span.SetAttributes("dashboard_uid", dashboardUID, attribute.Key("dashboard_uid").String(dashboardUID))
+	}
 
 	proxy.tracer.Inject(ctx, proxy.ctx.Req.Header, span)
 
@@ -163,6 +163,7 @@ func (hs *HTTPServer) GetPluginSettingByID(c *models.ReqContext) response.Respon
 	if !exists {
 		return response.Error(http.StatusNotFound, "Plugin not found, no installed plugin with that id", nil)
 	}
+	plugin = hs.withCDNAssets(plugin)
 
 	// In a first iteration, we only have one permission for app plugins.
 	// We will need a different permission to allow users to configure the plugin without needing access to it.
@@ -418,38 +419,84 @@ func (hs *HTTPServer) InstallPlugin(c *models.ReqContext) response.Response {
 	}
 	pluginID := web.Params(c.Req)[":pluginId"]
 
-	err := hs.pluginInstaller.Add(c.Req.Context(), pluginID, dto.Version, plugins.CompatOpts{
-		GrafanaVersion: hs.Cfg.BuildVersion,
-		OS:             runtime.GOOS,
-		Arch:           runtime.GOARCH,
-	})
+	err := hs.pluginInstaller.Add(c.Req.Context(), pluginID, dto.Version, hs.pluginCompatOpts())
 	if err != nil {
-		var dupeErr plugins.DuplicateError
-		if errors.As(err, &dupeErr) {
-			return response.Error(http.StatusConflict, "Plugin already installed", err)
+		return translatePluginInstallErrorToAPIError(err)
+	}
+
+	if dto.Pin {
+		if err := hs.pluginInstaller.Pin(c.Req.Context(), pluginID, true); err != nil {
+			return response.Error(http.StatusInternalServerError, "Plugin installed but failed to pin its version", err)
 		}
-		var versionUnsupportedErr repo.ErrVersionUnsupported
-		if errors.As(err, &versionUnsupportedErr) {
-			return response.Error(http.StatusConflict, "Plugin version not supported", err)
+	}
+
+	return response.JSON(http.StatusOK, []byte{})
+}
+
+func (hs *HTTPServer) RollbackPlugin(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	err := hs.pluginInstaller.Rollback(c.Req.Context(), pluginID, hs.pluginCompatOpts())
+	if err != nil {
+		if errors.Is(err, plugins.ErrPluginNotInstalled) {
+			return response.Error(http.StatusNotFound, "Plugin not installed", err)
 		}
-		var versionNotFoundErr repo.ErrVersionNotFound
-		if errors.As(err, &versionNotFoundErr) {
-			return response.Error(http.StatusNotFound, "Plugin version not found", err)
+		if errors.Is(err, plugins.ErrNoPreviousVersion) {
+			return response.Error(http.StatusConflict, "No previous plugin version to roll back to", err)
 		}
-		var clientError repo.Response4xxError
-		if errors.As(err, &clientError) {
-			return response.Error(clientError.StatusCode, clientError.Message, err)
+		return translatePluginInstallErrorToAPIError(err)
+	}
+
+	return response.JSON(http.StatusOK, []byte{})
+}
+
+func (hs *HTTPServer) PinPlugin(c *models.ReqContext) response.Response {
+	return hs.setPluginPinned(c, true)
+}
+
+func (hs *HTTPServer) UnpinPlugin(c *models.ReqContext) response.Response {
+	return hs.setPluginPinned(c, false)
+}
+
+func (hs *HTTPServer) setPluginPinned(c *models.ReqContext, pinned bool) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	err := hs.pluginInstaller.Pin(c.Req.Context(), pluginID, pinned)
+	if err != nil {
+		if errors.Is(err, plugins.ErrPluginNotInstalled) {
+			return response.Error(http.StatusNotFound, "Plugin not installed", err)
 		}
 		if errors.Is(err, plugins.ErrInstallCorePlugin) {
-			return response.Error(http.StatusForbidden, "Cannot install or change a Core plugin", err)
+			return response.Error(http.StatusForbidden, "Cannot pin a Core plugin", err)
 		}
-
-		return response.Error(http.StatusInternalServerError, "Failed to install plugin", err)
+		return response.Error(http.StatusInternalServerError, "Failed to update plugin pin", err)
 	}
 
 	return response.JSON(http.StatusOK, []byte{})
 }
 
+func (hs *HTTPServer) GetPluginInstallState(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	state, err := hs.pluginInstaller.InstallState(c.Req.Context(), pluginID)
+	if err != nil {
+		if errors.Is(err, plugins.ErrPluginNotInstalled) {
+			return response.Error(http.StatusNotFound, "Plugin not installed", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get plugin install state", err)
+	}
+
+	return response.JSON(http.StatusOK, state)
+}
+
+func (hs *HTTPServer) pluginCompatOpts() plugins.CompatOpts {
+	return plugins.CompatOpts{
+		GrafanaVersion: hs.Cfg.BuildVersion,
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+	}
+}
+
 func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
 
@@ -470,6 +517,33 @@ func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, []byte{})
 }
 
+func translatePluginInstallErrorToAPIError(err error) response.Response {
+	var dupeErr plugins.DuplicateError
+	if errors.As(err, &dupeErr) {
+		return response.Error(http.StatusConflict, "Plugin already installed", err)
+	}
+	var versionUnsupportedErr repo.ErrVersionUnsupported
+	if errors.As(err, &versionUnsupportedErr) {
+		return response.Error(http.StatusConflict, "Plugin version not supported", err)
+	}
+	var versionNotFoundErr repo.ErrVersionNotFound
+	if errors.As(err, &versionNotFoundErr) {
+		return response.Error(http.StatusNotFound, "Plugin version not found", err)
+	}
+	var clientError repo.Response4xxError
+	if errors.As(err, &clientError) {
+		return response.Error(clientError.StatusCode, clientError.Message, err)
+	}
+	if errors.Is(err, plugins.ErrInstallCorePlugin) {
+		return response.Error(http.StatusForbidden, "Cannot install or change a Core plugin", err)
+	}
+	if errors.Is(err, plugins.ErrPluginPinned) {
+		return response.Error(http.StatusConflict, "Plugin version is pinned", err)
+	}
+
+	return response.Error(http.StatusInternalServerError, "Failed to install plugin", err)
+}
+
 func translatePluginRequestErrorToAPIError(err error) response.Response {
 	if errors.Is(err, backendplugin.ErrPluginNotRegistered) {
 		return response.Error(404, "Plugin not found", err)
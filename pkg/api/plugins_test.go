@@ -101,6 +101,39 @@ func Test_PluginsInstallAndUninstall(t *testing.T) {
 	}
 }
 
+func Test_PluginsPinAndRollback(t *testing.T) {
+	inst := NewFakePluginInstaller()
+	srv := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = &setting.Cfg{PluginAdminEnabled: true}
+		hs.pluginInstaller = inst
+		hs.QuotaService = quotatest.New(false, nil)
+	})
+
+	send := func(t *testing.T, path, body string) int {
+		t.Helper()
+		req := srv.NewPostRequest(path, strings.NewReader(body))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer, IsGrafanaAdmin: true})
+		resp, err := srv.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, send(t, "/api/plugins/test/install", `{"version": "1.0.0"}`))
+	require.Equal(t, http.StatusConflict, send(t, "/api/plugins/test/rollback", `{}`))
+
+	require.Equal(t, http.StatusOK, send(t, "/api/plugins/test/install", `{"version": "2.0.0", "pin": true}`))
+	require.True(t, inst.plugins["test"].pinned)
+	require.Equal(t, http.StatusConflict, send(t, "/api/plugins/test/install", `{"version": "3.0.0"}`))
+	require.Equal(t, http.StatusConflict, send(t, "/api/plugins/test/rollback", `{}`))
+
+	require.Equal(t, http.StatusOK, send(t, "/api/plugins/test/unpin", `{}`))
+	require.Equal(t, http.StatusOK, send(t, "/api/plugins/test/rollback", `{}`))
+	require.Equal(t, fakePlugin{pluginID: "test", version: "1.0.0", previousVersion: "2.0.0"}, inst.plugins["test"])
+
+	require.Equal(t, http.StatusNotFound, send(t, "/api/plugins/unknown/pin", `{}`))
+}
+
 func Test_PluginsInstallAndUninstall_AccessControl(t *testing.T) {
 	canInstall := []ac.Permission{{Action: plugins.ActionInstall}}
 	cannotInstall := []ac.Permission{{Action: "plugins:cannotinstall"}}
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /labels/{kind} labels findLabeledResources
+//
+// Find labeled resources.
+//
+// Lists the resources of a kind whose labels match the label selector. Resources without labels
+// and resources the user cannot read are left out.
+//
+// Responses:
+// 200: findLabeledResourcesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) FindLabeledResources(c *models.ReqContext) response.Response {
+	kind := resourcelabel.Kind(web.Params(c.Req)[":kind"])
+	selector, err := resourcelabel.ParseSelector(c.Query("selector"))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	resources, err := hs.findLabeledResources(c, kind, selector, false)
+	if err != nil {
+		return labelErrorResponse(err, "Failed to find labeled resources")
+	}
+	return response.JSON(http.StatusOK, resources)
+}
+
+// swagger:route GET /labels/{kind}/{uid} labels getResourceLabels
+//
+// Get the labels of a resource.
+//
+// Responses:
+// 200: getResourceLabelsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetResourceLabels(c *models.ReqContext) response.Response {
+	kind, uid := resourcelabel.Kind(web.Params(c.Req)[":kind"]), web.Params(c.Req)[":uid"]
+	if resp := hs.checkLabeledResourceAccess(c, kind, uid, false); resp != nil {
+		return resp
+	}
+
+	labels, err := hs.resourceLabelService.Get(c.Req.Context(), &resourcelabel.GetLabelsQuery{OrgID: c.OrgID, Kind: kind, UID: uid})
+	if err != nil {
+		return labelErrorResponse(err, "Failed to get labels")
+	}
+	return response.JSON(http.StatusOK, dtos.ResourceLabels{Labels: labels})
+}
+
+// swagger:route PUT /labels/{kind}/{uid} labels setResourceLabels
+//
+// Set the labels of a resource.
+//
+// Replaces all labels of the resource. Label keys and values follow the syntax of Kubernetes labels.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) SetResourceLabels(c *models.ReqContext) response.Response {
+	kind, uid := resourcelabel.Kind(web.Params(c.Req)[":kind"]), web.Params(c.Req)[":uid"]
+	body := dtos.ResourceLabels{}
+	if err := web.Bind(c.Req, &body); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if resp := hs.checkLabeledResourceAccess(c, kind, uid, true); resp != nil {
+		return resp
+	}
+
+	cmd := resourcelabel.SetLabelsCommand{OrgID: c.OrgID, Kind: kind, UID: uid, Labels: body.Labels}
+	if err := hs.resourceLabelService.Set(c.Req.Context(), &cmd); err != nil {
+		return labelErrorResponse(err, "Failed to set labels")
+	}
+	return response.Success("Labels updated")
+}
+
+// swagger:route PATCH /labels/{kind} labels updateLabelsBySelector
+//
+// Update the labels of all resources matching a label selector.
+//
+// Sets and removes labels of every resource of the kind matching the selector, e.g. to relabel
+// everything with `team=checkout`. Resources the user cannot write are left out.
+//
+// Responses:
+// 200: bulkResultResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateLabelsBySelector(c *models.ReqContext) response.Response {
+	kind := resourcelabel.Kind(web.Params(c.Req)[":kind"])
+	cmd := dtos.UpdateLabelsBySelectorCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	selector, resp := parseRequiredSelector(cmd.Selector)
+	if resp != nil {
+		return resp
+	}
+	if err := resourcelabel.Labels(cmd.Set).Validate(); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	resources, err := hs.findLabeledResources(c, kind, selector, true)
+	if err != nil {
+		return labelErrorResponse(err, "Failed to find labeled resources")
+	}
+
+	result := dtos.BulkResult{Errors: make([]dtos.BulkError, 0)}
+	for _, r := range resources {
+		for key, value := range cmd.Set {
+			r.Labels[key] = value
+		}
+		for _, key := range cmd.Remove {
+			delete(r.Labels, key)
+		}
+
+		setCmd := resourcelabel.SetLabelsCommand{OrgID: c.OrgID, Kind: kind, UID: r.UID, Labels: r.Labels}
+		if err := hs.resourceLabelService.Set(c.Req.Context(), &setCmd); err != nil {
+			result.Errors = append(result.Errors, dtos.BulkError{UID: r.UID, Error: err.Error()})
+			continue
+		}
+		result.Count++
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /dashboards/move dashboards moveDashboardsBySelector
+//
+// Move all dashboards matching a label selector to a folder.
+//
+// Dashboards the user cannot write are left out. Dashboards which cannot be moved, like
+// provisioned dashboards, are reported as errors.
+//
+// Responses:
+// 200: bulkResultResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) MoveDashboardsBySelector(c *models.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	cmd := dtos.MoveDashboardsBySelectorCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	selector, resp := parseRequiredSelector(cmd.Selector)
+	if resp != nil {
+		return resp
+	}
+
+	var folderID int64
+	folderScope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.GeneralFolderUID)
+	if cmd.FolderUID != "" {
+		f, err := hs.folderService.Get(ctx, &folder.GetFolderQuery{UID: &cmd.FolderUID, OrgID: c.OrgID, SignedInUser: c.SignedInUser})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || folder.ErrFolderNotFound.Is(err) {
+				return response.Error(http.StatusBadRequest, "Folder not found", err)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to get folder", err)
+		}
+		folderID = f.ID
+		folderScope = dashboards.ScopeFoldersProvider.GetResourceScopeUID(f.UID)
+	}
+	canCreate, err := hs.AccessControl.Evaluate(ctx, c.SignedInUser, ac.EvalPermission(dashboards.ActionDashboardsCreate, folderScope))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !canCreate {
+		return response.Error(http.StatusForbidden, "Access denied to the target folder", nil)
+	}
+
+	resources, err := hs.findLabeledResources(c, resourcelabel.KindDashboard, selector, true)
+	if err != nil {
+		return labelErrorResponse(err, "Failed to find labeled dashboards")
+	}
+
+	result := dtos.BulkResult{Errors: make([]dtos.BulkError, 0)}
+	for _, r := range resources {
+		if err := hs.moveDashboard(c, r.UID, folderID); err != nil {
+			result.Errors = append(result.Errors, dtos.BulkError{UID: r.UID, Error: err.Error()})
+			continue
+		}
+		result.Count++
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (hs *HTTPServer) moveDashboard(c *models.ReqContext, uid string, folderID int64) error {
+	ctx := c.Req.Context()
+	query := &models.GetDashboardQuery{Uid: uid, OrgId: c.OrgID}
+	if err := hs.DashboardService.GetDashboard(ctx, query); err != nil {
+		return err
+	}
+	dash := query.Result
+	if dash.FolderId == folderID {
+		return nil
+	}
+
+	dash.FolderId = folderID
+	_, err := hs.DashboardService.SaveDashboard(alerting.WithUAEnabled(ctx, hs.Cfg.UnifiedAlerting.IsEnabled()), &dashboards.SaveDashboardDTO{
+		OrgId:     c.OrgID,
+		User:      c.SignedInUser,
+		Message:   "Moved by label selector",
+		Dashboard: dash,
+	}, false)
+	return err
+}
+
+// findLabeledResources returns the labeled resources of a kind matching the selector, which the
+// user can read, or write if write is set.
+func (hs *HTTPServer) findLabeledResources(c *models.ReqContext, kind resourcelabel.Kind, selector resourcelabel.Selector, write bool) ([]*resourcelabel.Resource, error) {
+	resources, err := hs.resourceLabelService.Find(c.Req.Context(), &resourcelabel.FindResourcesQuery{OrgID: c.OrgID, Kind: kind, Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resourcelabel.Resource, 0, len(resources))
+	for _, r := range resources {
+		evaluator, err := hs.labeledResourceEvaluator(c, kind, r.UID, write)
+		if err != nil {
+			return nil, err
+		}
+		if evaluator == nil {
+			continue
+		}
+		allowed, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// checkLabeledResourceAccess returns an error response if the resource does not exist or the
+// user cannot read it, or write it if write is set.
+func (hs *HTTPServer) checkLabeledResourceAccess(c *models.ReqContext, kind resourcelabel.Kind, uid string, write bool) response.Response {
+	if !kind.IsValid() {
+		return response.Error(http.StatusBadRequest, resourcelabel.ErrInvalidKind.Error(), nil)
+	}
+	evaluator, err := hs.labeledResourceEvaluator(c, kind, uid, write)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get resource", err)
+	}
+	if evaluator == nil {
+		return response.Error(http.StatusNotFound, fmt.Sprintf("The %s was not found", kind), nil)
+	}
+
+	allowed, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !allowed {
+		return response.Error(http.StatusForbidden, "Access denied", nil)
+	}
+	return nil
+}
+
+// labeledResourceEvaluator returns the permission required to read, or write if write is set, a
+// resource, or nil if it does not exist.
+func (hs *HTTPServer) labeledResourceEvaluator(c *models.ReqContext, kind resourcelabel.Kind, uid string, write bool) (ac.Evaluator, error) {
+	ctx := c.Req.Context()
+
+	var readAction, writeAction, scope string
+	switch kind {
+	case resourcelabel.KindDashboard:
+		query := &models.GetDashboardQuery{Uid: uid, OrgId: c.OrgID}
+		if err := hs.DashboardService.GetDashboard(ctx, query); err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if query.Result.IsFolder {
+			return nil, nil
+		}
+		readAction, writeAction = dashboards.ActionDashboardsRead, dashboards.ActionDashboardsWrite
+		scope = dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid)
+	case resourcelabel.KindFolder:
+		if _, err := hs.folderService.Get(ctx, &folder.GetFolderQuery{UID: &uid, OrgID: c.OrgID, SignedInUser: c.SignedInUser}); err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || folder.ErrFolderNotFound.Is(err) || errors.Is(err, dashboards.ErrFolderAccessDenied) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		readAction, writeAction = dashboards.ActionFoldersRead, dashboards.ActionFoldersWrite
+		scope = dashboards.ScopeFoldersProvider.GetResourceScopeUID(uid)
+	case resourcelabel.KindDataSource:
+		if err := hs.DataSourcesService.GetDataSource(ctx, &datasources.GetDataSourceQuery{Uid: uid, OrgId: c.OrgID}); err != nil {
+			if errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		readAction, writeAction = datasources.ActionRead, datasources.ActionWrite
+		scope = datasources.ScopeProvider.GetResourceScopeUID(uid)
+	case resourcelabel.KindAlertRule:
+		if hs.AlertNG == nil {
+			return nil, nil
+		}
+		rule, err := hs.AlertNG.GetAlertRuleByUID(ctx, c.OrgID, uid)
+		if err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		readAction, writeAction = ac.ActionAlertingRuleRead, ac.ActionAlertingRuleUpdate
+		scope = dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID)
+	default:
+		return nil, resourcelabel.ErrInvalidKind
+	}
+
+	if write {
+		return ac.EvalPermission(writeAction, scope), nil
+	}
+	return ac.EvalPermission(readAction, scope), nil
+}
+
+// parseRequiredSelector parses the selector of a bulk operation, which must not be empty to not
+// accidentally apply the operation to all labeled resources.
+func parseRequiredSelector(s string) (resourcelabel.Selector, response.Response) {
+	selector, err := resourcelabel.ParseSelector(s)
+	if err != nil {
+		return nil, response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	if len(selector) == 0 {
+		return nil, response.Error(http.StatusBadRequest, "selector is required", nil)
+	}
+	return selector, nil
+}
+
+func labelErrorResponse(err error, message string) response.Response {
+	if errors.Is(err, resourcelabel.ErrInvalidKind) || errors.Is(err, resourcelabel.ErrInvalidLabel) || errors.Is(err, resourcelabel.ErrInvalidSelector) {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters findLabeledResources
+type FindLabeledResourcesParams struct {
+	// in:path
+	// required:true
+	// Enum: dashboard,folder,datasource,alert-rule
+	Kind string `json:"kind"`
+	// Label selector, e.g. `team=checkout,env in (dev,test)`
+	// in:query
+	// required:false
+	Selector string `json:"selector"`
+}
+
+// swagger:parameters getResourceLabels
+type GetResourceLabelsParams struct {
+	// in:path
+	// required:true
+	// Enum: dashboard,folder,datasource,alert-rule
+	Kind string `json:"kind"`
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters setResourceLabels
+type SetResourceLabelsParams struct {
+	// in:path
+	// required:true
+	// Enum: dashboard,folder,datasource,alert-rule
+	Kind string `json:"kind"`
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body dtos.ResourceLabels `json:"body"`
+}
+
+// swagger:parameters updateLabelsBySelector
+type UpdateLabelsBySelectorParams struct {
+	// in:path
+	// required:true
+	// Enum: dashboard,folder,datasource,alert-rule
+	Kind string `json:"kind"`
+	// in:body
+	// required:true
+	Body dtos.UpdateLabelsBySelectorCommand `json:"body"`
+}
+
+// swagger:parameters moveDashboardsBySelector
+type MoveDashboardsBySelectorParams struct {
+	// in:body
+	// required:true
+	Body dtos.MoveDashboardsBySelectorCommand `json:"body"`
+}
+
+// swagger:response findLabeledResourcesResponse
+type FindLabeledResourcesResponse struct {
+	// in:body
+	Body []*resourcelabel.Resource `json:"body"`
+}
+
+// swagger:response getResourceLabelsResponse
+type GetResourceLabelsResponse struct {
+	// in:body
+	Body dtos.ResourceLabels `json:"body"`
+}
+
+// swagger:response bulkResultResponse
+type BulkResultResponse struct {
+	// in:body
+	Body dtos.BulkResult `json:"body"`
+}
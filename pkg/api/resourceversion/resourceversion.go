@@ -0,0 +1,58 @@
+// Package resourceversion implements optimistic concurrency control for the HTTP API.
+//
+// Resources return their version as the `resourceVersion` field and as the ETag header. Clients
+// changing a resource send the version they last read in the If-Match header, and the request
+// fails with 412 Precondition Failed if the resource was changed since, instead of overwriting
+// the changes.
+package resourceversion
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+// ErrMismatch is returned when the If-Match header of a request does not match the version of
+// the resource, or the resource does not exist.
+var ErrMismatch = errors.New("the resource was changed since the version in the If-Match header")
+
+// Format returns the resource version of a version number.
+func Format(version int64) string {
+	return strconv.FormatInt(version, 10)
+}
+
+// ETag returns the entity tag of a version number.
+func ETag(version int64) string {
+	return `"` + Format(version) + `"`
+}
+
+// IsConditional returns whether the request has an If-Match header.
+func IsConditional(r *http.Request) bool {
+	return len(r.Header.Values(IfMatchHeader)) > 0
+}
+
+// Check returns ErrMismatch if the request has an If-Match header which does not match the
+// version. The header is a list of entity tags, or `*` to match any version. Plain resource
+// versions are accepted as well, weak entity tags never match.
+func Check(r *http.Request, version int64) error {
+	if !IsConditional(r) {
+		return nil
+	}
+
+	etag, resourceVersion := ETag(version), Format(version)
+	for _, header := range r.Header.Values(IfMatchHeader) {
+		for _, tag := range strings.Split(header, ",") {
+			switch strings.TrimSpace(tag) {
+			case "*", etag, resourceVersion:
+				return nil
+			}
+		}
+	}
+	return ErrMismatch
+}
@@ -0,0 +1,40 @@
+package resourceversion
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		ifMatch []string
+		err     error
+	}{
+		{desc: "no header", ifMatch: nil},
+		{desc: "matching entity tag", ifMatch: []string{`"3"`}},
+		{desc: "matching resource version", ifMatch: []string{"3"}},
+		{desc: "wildcard", ifMatch: []string{"*"}},
+		{desc: "matching tag in list", ifMatch: []string{`"1", "3"`}},
+		{desc: "matching tag in second header", ifMatch: []string{`"1"`, `"3"`}},
+		{desc: "other version", ifMatch: []string{`"2"`}, err: ErrMismatch},
+		{desc: "weak entity tag", ifMatch: []string{`W/"3"`}, err: ErrMismatch},
+		{desc: "empty header", ifMatch: []string{""}, err: ErrMismatch},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPut, "/api/folders/abc", nil)
+			assert.NoError(t, err)
+			for _, v := range tc.ifMatch {
+				r.Header.Add(IfMatchHeader, v)
+			}
+			assert.Equal(t, tc.err, Check(r, 3))
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	assert.Equal(t, "12", Format(12))
+	assert.Equal(t, `"12"`, ETag(12))
+}
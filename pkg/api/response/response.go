@@ -10,10 +10,12 @@ import (
 
 	jsoniter "github.com/json-iterator/go"
 
+	"github.com/grafana/grafana/pkg/infra/log/redact"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
 )
 
 // Response is an HTTP response interface.
@@ -40,6 +42,8 @@ type NormalResponse struct {
 	header     http.Header
 	errMessage string
 	err        error
+	// problem is the RFC 7807 representation of an error response.
+	problem *errhttp.Problem
 }
 
 // Write implements http.ResponseWriter
@@ -78,9 +82,9 @@ func (r *NormalResponse) ErrMessage() string {
 }
 
 func (r *NormalResponse) WriteTo(ctx *models.ReqContext) {
+	traceID := tracing.TraceIDFromContext(ctx.Req.Context(), false)
 	if r.err != nil {
 		v := map[string]interface{}{}
-		traceID := tracing.TraceIDFromContext(ctx.Req.Context(), false)
 		if err := json.Unmarshal(r.body.Bytes(), &v); err == nil {
 			v["traceID"] = traceID
 			if b, err := json.Marshal(v); err == nil {
@@ -96,6 +100,15 @@ func (r *NormalResponse) WriteTo(ctx *models.ReqContext) {
 		logger(r.errMessage, "error", r.err, "remote_addr", ctx.RemoteAddr(), "traceID", traceID)
 	}
 
+	if r.problem != nil && errhttp.AcceptsProblem(ctx.Req) {
+		problem := *r.problem
+		problem.TraceID = traceID
+		if b, err := json.Marshal(problem); err == nil {
+			r.body = bytes.NewBuffer(b)
+			r.header.Set("Content-Type", errhttp.ProblemContentType)
+		}
+	}
+
 	header := ctx.Resp.Header()
 	for k, v := range r.header {
 		header[k] = v
@@ -197,6 +210,7 @@ func Success(message string) *NormalResponse {
 // Error creates an error response.
 func Error(status int, message string, err error) *NormalResponse {
 	data := make(map[string]interface{})
+	message = redact.String(message)
 
 	switch status {
 	case 404:
@@ -211,11 +225,13 @@ func Error(status int, message string, err error) *NormalResponse {
 
 	if err != nil {
 		if setting.Env != setting.Prod {
-			data["error"] = err.Error()
+			data["error"] = redact.String(err.Error())
 		}
 	}
 
 	resp := JSON(status, data)
+	problem := errhttp.NewProblem(status, message, err)
+	resp.problem = &problem
 
 	if err != nil {
 		resp.errMessage = message
@@ -232,7 +248,11 @@ func Err(err error) *NormalResponse {
 		return Error(http.StatusInternalServerError, "", fmt.Errorf("unexpected error type [%s]: %w", reflect.TypeOf(err), err))
 	}
 
-	resp := JSON(grafanaErr.Reason.Status().HTTPStatus(), grafanaErr.Public())
+	public := grafanaErr.Public()
+	public.Message = redact.String(public.Message)
+	resp := JSON(public.StatusCode, public)
+	problem := errhttp.NewProblem(public.StatusCode, public.Message, *grafanaErr)
+	resp.problem = &problem
 	resp.errMessage = string(grafanaErr.Reason.Status())
 	resp.err = grafanaErr
 
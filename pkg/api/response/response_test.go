@@ -1,11 +1,18 @@
 package response
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/log/redact"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
+	"github.com/grafana/grafana/pkg/web"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -125,3 +132,64 @@ func TestErrors(t *testing.T) {
 		)
 	}
 }
+
+func TestProblemDetails(t *testing.T) {
+	writeTo := func(resp *NormalResponse, accept string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+		req.Header.Set("Accept", accept)
+		resp.WriteTo(&models.ReqContext{
+			Context: &web.Context{Req: req, Resp: web.NewResponseWriter(http.MethodGet, rec)},
+			Logger:  log.New("test"),
+		})
+		return rec
+	}
+
+	t.Run("JSON by default", func(t *testing.T) {
+		rec := writeTo(Error(http.StatusNotFound, "Thing not found", nil), "application/json")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"message": "Thing not found"}`, rec.Body.String())
+	})
+
+	t.Run("problem details when accepted", func(t *testing.T) {
+		rec := writeTo(Error(http.StatusNotFound, "Thing not found", errors.New("not found")), "application/problem+json")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+		var problem errhttp.Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, errhttp.Problem{
+			Type:   "urn:grafana:error:core.notFound",
+			Title:  "Not Found",
+			Status: http.StatusNotFound,
+			Detail: "Thing not found",
+			Code:   "core.notFound",
+		}, problem)
+	})
+
+	t.Run("problem details use grafana error message ID", func(t *testing.T) {
+		err := errutil.NewBase(errutil.StatusTimeout, "thing.timeout").Errorf("whoops")
+		rec := writeTo(Err(err), "application/problem+json")
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+
+		var problem errhttp.Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, "thing.timeout", problem.Code)
+		assert.Equal(t, "urn:grafana:error:thing.timeout", problem.Type)
+	})
+}
+
+func TestErrorRedaction(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, redact.Configure(redact.Config{})) })
+	require.NoError(t, redact.Configure(redact.Config{Enabled: true, Patterns: []string{redact.PatternEmail}}))
+
+	resp := Error(http.StatusConflict, "User bob@example.com already exists", errors.New("duplicate login bob@example.com"))
+	assert.JSONEq(t, `{"message": "User [REDACTED] already exists", "error": "duplicate login [REDACTED]"}`, string(resp.Body()))
+	require.NotNil(t, resp.problem)
+	assert.Equal(t, "User [REDACTED] already exists", resp.problem.Detail)
+
+	err := errutil.NewBase(errutil.StatusBadRequest, "user.invalid", errutil.WithPublicMessage("Invalid user alice@example.com")).Errorf("invalid user")
+	resp = Err(err)
+	assert.Contains(t, string(resp.Body()), `"message":"Invalid user [REDACTED]"`)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -64,6 +65,11 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 		return response.Error(400, "search supports UIDs or IDs, not both", nil)
 	}
 
+	labelSelector, err := resourcelabel.ParseSelector(c.Query("labelSelector"))
+	if err != nil {
+		return response.Error(400, err.Error(), err)
+	}
+
 	searchQuery := search.Query{
 		Title:         query,
 		Tags:          tags,
@@ -78,9 +84,10 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 		FolderIds:     folderIDs,
 		Permission:    permission,
 		Sort:          sort,
+		LabelSelector: labelSelector,
 	}
 
-	err := hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
+	err = hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
 	if err != nil {
 		return response.Error(500, "Search failed", err)
 	}
@@ -88,7 +95,7 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 	defer c.TimeRequest(metrics.MApiDashboardSearch)
 
 	if !c.QueryBool("accesscontrol") {
-		return response.JSON(http.StatusOK, searchQuery.Result)
+		return jsonWithFields(c, searchQuery.Result, "")
 	}
 
 	return hs.searchHitsWithMetadata(c, searchQuery.Result)
@@ -126,7 +133,7 @@ func (hs *HTTPServer) searchHitsWithMetadata(c *models.ReqContext, hits models.H
 		hitsWithMeta = append(hitsWithMeta, hitWithMeta{hit, meta})
 	}
 
-	return response.JSON(http.StatusOK, hitsWithMeta)
+	return jsonWithFields(c, hitsWithMeta, "")
 }
 
 // swagger:route GET /search/sorting search listSortOptions
@@ -210,6 +217,16 @@ type SearchParams struct {
 	// default: alpha-asc
 	// Enum: alpha-asc,alpha-desc
 	Sort string `json:"sort"`
+	// Label selector the labels of the dashboards and folders must match, e.g. `team=checkout,env in (dev,test)`
+	// in:query
+	// required: false
+	LabelSelector string `json:"labelSelector"`
+	// Fields of the items to return, separated by commas, e.g. `uid,title,url`. All fields are returned by default.
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: csv
+	Fields []string `json:"fields"`
 }
 
 // swagger:response searchResponse
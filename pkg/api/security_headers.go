@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminGetSecurityHeaders returns the security headers of the instance, and whether they are read
+// from the configuration or were set through the API.
+func (hs *HTTPServer) AdminGetSecurityHeaders(c *models.ReqContext) response.Response {
+	result, err := hs.securityHeaders.GetSettings(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get security headers", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// AdminUpdateSecurityHeaders replaces the security headers of the configuration until they are reset.
+func (hs *HTTPServer) AdminUpdateSecurityHeaders(c *models.ReqContext) response.Response {
+	settings := securityheaders.Settings{}
+	if err := web.Bind(c.Req, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.securityHeaders.SetSettings(c.Req.Context(), settings); err != nil {
+		if errors.Is(err, securityheaders.ErrInvalidSettings) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update security headers", err)
+	}
+
+	return response.Success("Security headers updated")
+}
+
+func (hs *HTTPServer) AdminResetSecurityHeaders(c *models.ReqContext) response.Response {
+	if err := hs.securityHeaders.ResetSettings(c.Req.Context()); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset security headers", err)
+	}
+
+	return response.Success("Security headers reset to the configuration")
+}
+
+func (hs *HTTPServer) AdminGetOrgSecurityHeaders(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	settings, err := hs.securityHeaders.GetOrgSettings(c.Req.Context(), orgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get security headers of organization", err)
+	}
+
+	return response.JSON(http.StatusOK, settings)
+}
+
+// AdminUpdateOrgSecurityHeaders sets the sources allowed to embed an organization.
+func (hs *HTTPServer) AdminUpdateOrgSecurityHeaders(c *models.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	settings := securityheaders.OrgSettings{}
+	if err := web.Bind(c.Req, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	settings.OrgID = orgID
+
+	if _, err := hs.orgService.GetByID(c.Req.Context(), &org.GetOrgByIdQuery{ID: orgID}); err != nil {
+		if errors.Is(err, models.ErrOrgNotFound) {
+			return response.Error(http.StatusNotFound, "Organization not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get organization", err)
+	}
+
+	if err := hs.securityHeaders.SetOrgSettings(c.Req.Context(), settings); err != nil {
+		if errors.Is(err, securityheaders.ErrInvalidSettings) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update security headers of organization", err)
+	}
+
+	return response.Success("Security headers of organization updated")
+}
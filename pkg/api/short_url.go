@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -19,23 +22,97 @@ func (hs *HTTPServer) createShortURL(c *models.ReqContext) response.Response {
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Err(models.ErrShortURLBadRequest.Errorf("bad request data: %w", err))
 	}
-	hs.log.Debug("Received request to create short URL", "path", cmd.Path)
-	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, cmd.Path)
+	expiresIn, err := parseShortURLExpiry(cmd.ExpiresIn)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	hs.log.Debug("Received request to create short URL", "path", cmd.Path, "slug", cmd.Slug)
+	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, &models.CreateShortURLCommand{
+		Path:      cmd.Path,
+		Slug:      cmd.Slug,
+		ExpiresIn: expiresIn,
+	})
 	if err != nil {
 		return response.Err(err)
 	}
 
-	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, c.OrgID)
+	url := shortURLLink(shortURL, c.OrgID)
 	c.Logger.Debug("Created short URL", "url", url)
 
 	dto := dtos.ShortURL{
-		UID: shortURL.Uid,
-		URL: url,
+		UID:       shortURL.Uid,
+		URL:       url,
+		ExpiresAt: unixTimeOrNil(shortURL.ExpiresAt),
 	}
 
 	return response.JSON(http.StatusOK, dto)
 }
 
+// searchShortURLs lists the short URLs of the organization with their usage.
+func (hs *HTTPServer) searchShortURLs(c *models.ReqContext) response.Response {
+	result, err := hs.ShortURLService.SearchShortURLs(c.Req.Context(), &models.SearchShortURLsQuery{
+		OrgID:     c.OrgID,
+		Query:     c.Query("query"),
+		CreatedBy: c.QueryInt64("createdBy"),
+		Sort:      c.Query("sort"),
+		Page:      c.QueryInt("page"),
+		Limit:     c.QueryInt("perpage"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search short URLs", err)
+	}
+
+	dto := dtos.SearchShortURLsResult{
+		TotalCount: result.TotalCount,
+		ShortURLs:  make([]*dtos.ShortURLDetails, 0, len(result.ShortURLs)),
+		Page:       result.Page,
+		PerPage:    result.PerPage,
+	}
+	for _, shortURL := range result.ShortURLs {
+		dto.ShortURLs = append(dto.ShortURLs, shortURLDetails(shortURL, c.OrgID))
+	}
+
+	return response.JSON(http.StatusOK, dto)
+}
+
+// updateShortURL changes the path or the expiry of a short URL.
+func (hs *HTTPServer) updateShortURL(c *models.ReqContext) response.Response {
+	cmd := dtos.UpdateShortURLCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(models.ErrShortURLBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	update := &models.UpdateShortURLCommand{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.OrgID,
+		Path:  cmd.Path,
+	}
+	if cmd.ExpiresIn != nil {
+		expiresIn, err := parseShortURLExpiry(*cmd.ExpiresIn)
+		if err != nil {
+			return response.Err(err)
+		}
+		update.ExpiresIn = &expiresIn
+	}
+
+	shortURL, err := hs.ShortURLService.UpdateShortURL(c.Req.Context(), update)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, shortURLDetails(shortURL, c.OrgID))
+}
+
+// deleteShortURL deletes a short URL, links to it stop redirecting.
+func (hs *HTTPServer) deleteShortURL(c *models.ReqContext) response.Response {
+	if err := hs.ShortURLService.DeleteShortURL(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Short URL deleted")
+}
+
 func (hs *HTTPServer) redirectFromShortURL(c *models.ReqContext) {
 	shortURLUID := web.Params(c.Req)[":uid"]
 
@@ -45,8 +122,8 @@ func (hs *HTTPServer) redirectFromShortURL(c *models.ReqContext) {
 
 	shortURL, err := hs.ShortURLService.GetShortURLByUID(c.Req.Context(), c.SignedInUser, shortURLUID)
 	if err != nil {
-		if models.ErrShortURLNotFound.Is(err) {
-			hs.log.Debug("Not redirecting short URL since not found")
+		if models.ErrShortURLNotFound.Is(err) || models.ErrShortURLExpired.Is(err) {
+			hs.log.Debug("Not redirecting short URL since not found or expired", "uid", shortURLUID)
 			return
 		}
 
@@ -62,3 +139,41 @@ func (hs *HTTPServer) redirectFromShortURL(c *models.ReqContext) {
 	hs.log.Debug("Redirecting short URL", "path", shortURL.Path)
 	c.Redirect(setting.ToAbsUrl(shortURL.Path), 302)
 }
+
+// parseShortURLExpiry parses lifetimes such as 12h or 30d. An empty lifetime never expires.
+func parseShortURLExpiry(expiresIn string) (time.Duration, error) {
+	if expiresIn == "" {
+		return 0, nil
+	}
+	d, err := gtime.ParseDuration(expiresIn)
+	if err != nil || d <= 0 {
+		return 0, models.ErrShortURLInvalidExpiry.Errorf("invalid expiry: %s", expiresIn)
+	}
+	return d, nil
+}
+
+func shortURLLink(shortURL *models.ShortUrl, orgID int64) string {
+	return fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, orgID)
+}
+
+func shortURLDetails(shortURL *models.ShortUrl, orgID int64) *dtos.ShortURLDetails {
+	return &dtos.ShortURLDetails{
+		UID:        shortURL.Uid,
+		URL:        shortURLLink(shortURL, orgID),
+		Path:       shortURL.Path,
+		CustomSlug: shortURL.CustomSlug,
+		CreatedBy:  shortURL.CreatedBy,
+		CreatedAt:  time.Unix(shortURL.CreatedAt, 0),
+		LastSeenAt: unixTimeOrNil(shortURL.LastSeenAt),
+		ExpiresAt:  unixTimeOrNil(shortURL.ExpiresAt),
+		Hits:       shortURL.Hits,
+	}
+}
+
+func unixTimeOrNil(sec int64) *time.Time {
+	if sec == 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0)
+	return &t
+}
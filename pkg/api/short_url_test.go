@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -30,7 +31,7 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 			Path:  cmd.Path,
 		}
 		service := &fakeShortURLService{
-			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, path string) (*models.ShortUrl, error) {
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *models.CreateShortURLCommand) (*models.ShortUrl, error) {
 				return createResp, nil
 			},
 		}
@@ -44,6 +45,50 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, 200, sc.resp.Code)
 				require.Equal(t, fmt.Sprintf("/goto/%s?orgId=%d", createResp.Uid, createResp.OrgId), shortUrl.URL)
+				require.Nil(t, shortUrl.ExpiresAt)
+			})
+	})
+
+	t.Run("Given a request for a short URL with a slug and an expiry", func(t *testing.T) {
+		cmd := dtos.CreateShortURLCmd{
+			Path:      "d/TxKARsmGz/new-dashboard",
+			Slug:      "team-overview",
+			ExpiresIn: "30d",
+		}
+
+		var received *models.CreateShortURLCommand
+		service := &fakeShortURLService{
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *models.CreateShortURLCommand) (*models.ShortUrl, error) {
+				received = cmd
+				return &models.ShortUrl{Id: 1, OrgId: testOrgID, Uid: cmd.Slug, Path: cmd.Path, ExpiresAt: 1700000000, CustomSlug: true}, nil
+			},
+		}
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, service,
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+
+				shortUrl := dtos.ShortURL{}
+				err := json.NewDecoder(sc.resp.Body).Decode(&shortUrl)
+				require.NoError(t, err)
+				require.Equal(t, 200, sc.resp.Code)
+				require.Equal(t, "team-overview", received.Slug)
+				require.Equal(t, 30*24*time.Hour, received.ExpiresIn)
+				require.Equal(t, fmt.Sprintf("/goto/team-overview?orgId=%d", testOrgID), shortUrl.URL)
+				require.NotNil(t, shortUrl.ExpiresAt)
+			})
+	})
+
+	t.Run("Given a request for a short URL with an invalid expiry", func(t *testing.T) {
+		cmd := dtos.CreateShortURLCmd{
+			Path:      "d/TxKARsmGz/new-dashboard",
+			ExpiresIn: "soon",
+		}
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, &fakeShortURLService{},
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+				require.Equal(t, 400, sc.resp.Code)
 			})
 	})
 }
@@ -77,21 +122,33 @@ func createShortURLScenario(t *testing.T, desc string, url string, routePattern
 }
 
 type fakeShortURLService struct {
-	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, path string) (*models.ShortUrl, error)
+	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, cmd *models.CreateShortURLCommand) (*models.ShortUrl, error)
 }
 
 func (s *fakeShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*models.ShortUrl, error) {
 	return nil, nil
 }
 
-func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*models.ShortUrl, error) {
+func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *models.CreateShortURLCommand) (*models.ShortUrl, error) {
 	if s.createShortURLFunc != nil {
-		return s.createShortURLFunc(ctx, user, path)
+		return s.createShortURLFunc(ctx, user, cmd)
 	}
 
 	return nil, nil
 }
 
+func (s *fakeShortURLService) UpdateShortURL(ctx context.Context, cmd *models.UpdateShortURLCommand) (*models.ShortUrl, error) {
+	return nil, nil
+}
+
+func (s *fakeShortURLService) DeleteShortURL(ctx context.Context, orgID int64, uid string) error {
+	return nil
+}
+
+func (s *fakeShortURLService) SearchShortURLs(ctx context.Context, query *models.SearchShortURLsQuery) (*models.SearchShortURLsResult, error) {
+	return &models.SearchShortURLsResult{}, nil
+}
+
 func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *models.ShortUrl) error {
 	return nil
 }
@@ -1,26 +1,95 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// swagger:route GET /user/stars signed_in_user getStars
+//
+// List starred resources.
+//
+// Lists the dashboards, folders, data sources and alert rules the actual user starred in the
+// current organization. Resources the user can no longer access are left out.
+//
+// Responses:
+// 200: getStarsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
 func (hs *HTTPServer) GetStars(c *models.ReqContext) response.Response {
+	kinds := make(map[star.Kind]bool)
+	for _, k := range c.QueryStrings("kind") {
+		kind := star.Kind(k)
+		if kind != star.KindDashboard && !kind.IsValid() {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("Invalid kind %q", k), nil)
+		}
+		kinds[kind] = true
+	}
+	includes := func(kind star.Kind) bool {
+		return len(kinds) == 0 || kinds[kind]
+	}
+
+	result := make([]dtos.StarredResource, 0)
+	if includes(star.KindDashboard) {
+		dashboardStars, err := hs.getStarredDashboards(c)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to get user stars", err)
+		}
+		result = append(result, dashboardStars...)
+	}
+
+	query := star.GetUserResourceStarsQuery{UserID: c.UserID, OrgID: c.OrgID}
+	for kind := range kinds {
+		if kind != star.KindDashboard {
+			query.Kinds = append(query.Kinds, kind)
+		}
+	}
+	if len(kinds) == 0 || len(query.Kinds) > 0 {
+		stars, err := hs.starService.GetResourcesByUser(c.Req.Context(), &query)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to get user stars", err)
+		}
+		for _, s := range stars {
+			resource, err := hs.getStarredResource(c, s.Kind, s.ResourceUID)
+			if err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to get user stars", err)
+			}
+			if resource != nil {
+				result = append(result, *resource)
+			}
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+func (hs *HTTPServer) getStarredDashboards(c *models.ReqContext) ([]dtos.StarredResource, error) {
 	query := star.GetUserStarsQuery{
 		UserID: c.SignedInUser.UserID,
 	}
 
 	iuserstars, err := hs.starService.GetByUser(c.Req.Context(), &query)
 	if err != nil {
-		return response.Error(500, "Failed to get user stars", err)
+		return nil, err
 	}
 
-	uids := []string{}
+	result := make([]dtos.StarredResource, 0, len(iuserstars.UserStars))
 	for dashboardId := range iuserstars.UserStars {
 		query := &models.GetDashboardQuery{
 			Id:    dashboardId,
@@ -29,11 +98,118 @@ func (hs *HTTPServer) GetStars(c *models.ReqContext) response.Response {
 		err := hs.DashboardService.GetDashboard(c.Req.Context(), query)
 
 		// Grafana admin users may have starred dashboards in multiple orgs.  This will avoid returning errors when the dashboard is in another org
-		if err == nil {
-			uids = append(uids, query.Result.Uid)
+		if err != nil {
+			continue
+		}
+
+		canRead, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser,
+			ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(query.Result.Uid)))
+		if err != nil {
+			return nil, err
+		}
+		if canRead {
+			result = append(result, dtos.StarredResource{
+				Kind:  string(star.KindDashboard),
+				UID:   query.Result.Uid,
+				Title: query.Result.Title,
+				URL:   query.Result.GetUrl(),
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Title < result[j].Title
+	})
+	return result, nil
+}
+
+// getStarredResource returns a starred resource, or nil if it does not exist anymore or the user
+// cannot access it.
+func (hs *HTTPServer) getStarredResource(c *models.ReqContext, kind star.Kind, uid string) (*dtos.StarredResource, error) {
+	ctx := c.Req.Context()
+	resource := &dtos.StarredResource{Kind: string(kind), UID: uid}
+
+	var evaluator ac.Evaluator
+	switch kind {
+	case star.KindFolder:
+		f, err := hs.folderService.Get(ctx, &folder.GetFolderQuery{UID: &uid, OrgID: c.OrgID, SignedInUser: c.SignedInUser})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) || folder.ErrFolderNotFound.Is(err) || errors.Is(err, dashboards.ErrFolderAccessDenied) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		resource.Title = f.Title
+		resource.URL = models.GetFolderUrl(f.UID, models.SlugifyTitle(f.Title))
+		evaluator = ac.EvalPermission(dashboards.ActionFoldersRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(uid))
+	case star.KindDataSource:
+		query := &datasources.GetDataSourceQuery{Uid: uid, OrgId: c.OrgID}
+		if err := hs.DataSourcesService.GetDataSource(ctx, query); err != nil {
+			if errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		resource.Title = query.Result.Name
+		resource.URL = hs.Cfg.AppSubURL + "/explore?left=" + url.QueryEscape(fmt.Sprintf(`{"datasource":%q}`, uid))
+		evaluator = ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid))
+	case star.KindAlertRule:
+		if hs.AlertNG == nil {
+			return nil, nil
+		}
+		rule, err := hs.AlertNG.GetAlertRuleByUID(ctx, c.OrgID, uid)
+		if err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		resource.Title = rule.Title
+		resource.URL = fmt.Sprintf("%s/alerting/grafana/%s/view", hs.Cfg.AppSubURL, uid)
+		evaluator = ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID))
+	default:
+		return nil, nil
+	}
+
+	allowed, err := hs.AccessControl.Evaluate(ctx, c.SignedInUser, evaluator)
+	if err != nil || !allowed {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// starResource returns the handler starring resources of a kind for the actual user. The
+// resource must exist and be accessible to the user.
+func (hs *HTTPServer) starResource(kind star.Kind) func(c *models.ReqContext) response.Response {
+	return func(c *models.ReqContext) response.Response {
+		uid := web.Params(c.Req)[":uid"]
+		resource, err := hs.getStarredResource(c, kind, uid)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to star resource", err)
 		}
+		if resource == nil {
+			return response.Error(http.StatusNotFound, fmt.Sprintf("The %s was not found", kind), nil)
+		}
+
+		cmd := star.StarResourceCommand{UserID: c.UserID, OrgID: c.OrgID, Kind: kind, ResourceUID: uid}
+		if err := hs.starService.AddResource(c.Req.Context(), &cmd); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to star resource", err)
+		}
+
+		return response.Success("Resource starred")
+	}
+}
+
+// unstarResource returns the handler unstarring resources of a kind for the actual user. Stars
+// of resources which were deleted can be removed as well.
+func (hs *HTTPServer) unstarResource(kind star.Kind) func(c *models.ReqContext) response.Response {
+	return func(c *models.ReqContext) response.Response {
+		cmd := star.UnstarResourceCommand{UserID: c.UserID, OrgID: c.OrgID, Kind: kind, ResourceUID: web.Params(c.Req)[":uid"]}
+		if err := hs.starService.DeleteResource(c.Req.Context(), &cmd); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to unstar resource", err)
+		}
+
+		return response.Success("Resource unstarred")
 	}
-	return response.JSON(200, uids)
 }
 
 // swagger:route POST /user/stars/dashboard/{dashboard_id} signed_in_user starDashboard
@@ -165,6 +341,20 @@ func (hs *HTTPServer) UnstarDashboardByUID(c *models.ReqContext) response.Respon
 	return response.Success("Dashboard unstarred")
 }
 
+// swagger:parameters getStars
+type GetStarsParams struct {
+	// Kinds of the resources to list, one of dashboard, folder, datasource or alert-rule. All kinds are listed if empty.
+	// in:query
+	// required:false
+	Kind []string `json:"kind"`
+}
+
+// swagger:response getStarsResponse
+type GetStarsResponse struct {
+	// in:body
+	Body []dtos.StarredResource `json:"body"`
+}
+
 // swagger:parameters starDashboard
 type StarDashboardParams struct {
 	// in:path
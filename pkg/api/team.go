@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -137,6 +138,13 @@ func (hs *HTTPServer) DeleteTeamByID(c *models.ReqContext) response.Response {
 	return response.Success("Team deleted")
 }
 
+var teamsPagination = pagination.Config{
+	SortFields:   []string{"name", "email"},
+	DefaultSort:  pagination.Sort{Field: "name"},
+	DefaultLimit: 1000,
+	MaxLimit:     5000,
+}
+
 // swagger:route GET /teams/search teams searchTeams
 //
 // Team Search With Paging.
@@ -147,11 +155,11 @@ func (hs *HTTPServer) DeleteTeamByID(c *models.ReqContext) response.Response {
 // 403: forbiddenError
 // 500: internalServerError
 func (hs *HTTPServer) SearchTeams(c *models.ReqContext) response.Response {
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+	paging, err := teamsPagination.Parse(c.Req.URL.Query())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
 	}
-	page := c.QueryInt("page")
+	page := paging.Page
 	if page < 1 {
 		page = 1
 	}
@@ -168,7 +176,9 @@ func (hs *HTTPServer) SearchTeams(c *models.ReqContext) response.Response {
 		Name:         c.Query("name"),
 		UserIdFilter: userIdFilter,
 		Page:         page,
-		Limit:        perPage,
+		Limit:        paging.Limit,
+		Sort:         paging.Sort,
+		After:        paging.After,
 		SignedInUser: c.SignedInUser,
 		HiddenUsers:  hs.Cfg.HiddenUsers,
 	}
@@ -191,7 +201,15 @@ func (hs *HTTPServer) SearchTeams(c *models.ReqContext) response.Response {
 	}
 
 	query.Result.Page = page
-	query.Result.PerPage = perPage
+	query.Result.PerPage = paging.Limit
+	if n := len(query.Result.Teams); n > 0 {
+		last := query.Result.Teams[n-1]
+		value := last.Name
+		if paging.Sort.Field == "email" {
+			value = last.Email
+		}
+		query.Result.NextCursor = paging.NextCursor(n, value, last.Id)
+	}
 
 	return response.JSON(http.StatusOK, query.Result)
 }
@@ -308,6 +326,76 @@ func (hs *HTTPServer) UpdateTeamPreferences(c *models.ReqContext) response.Respo
 	return hs.updatePreferencesFor(c.Req.Context(), orgId, 0, teamId, &dtoCmd)
 }
 
+// swagger:route GET /teams/{team_id}/usage teams getTeamUsage
+//
+// Get Team Usage.
+//
+// Returns the API requests and data source queries made by the team members
+// since the start of the current usage tracking window.
+//
+// Responses:
+// 200: getTeamUsageResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetTeamUsage(c *models.ReqContext) response.Response {
+	teamId, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	if hs.AccessControl.IsDisabled() {
+		if err := hs.teamGuardian.CanAdmin(c.Req.Context(), c.OrgID, teamId, c.SignedInUser); err != nil {
+			return response.Error(403, "Not allowed to view team usage", err)
+		}
+	}
+
+	query := models.GetTeamMembersQuery{OrgId: c.OrgID, TeamId: teamId, SignedInUser: c.SignedInUser}
+	if err := hs.teamService.GetTeamMembers(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get Team Members", err)
+	}
+
+	userIDs := make([]int64, 0, len(query.Result))
+	for _, member := range query.Result {
+		userIDs = append(userIDs, member.UserId)
+	}
+	window := hs.userUsageTracker.GetUsersUsage(c.OrgID, userIDs)
+
+	result := dtos.TeamUsage{
+		TeamID:  teamId,
+		Since:   window.Since,
+		Members: make([]*dtos.TeamMemberUsage, 0, len(query.Result)),
+	}
+	for _, member := range query.Result {
+		usage := window.Users[member.UserId]
+		result.APIRequests += usage.APIRequests
+		result.Queries += usage.Queries
+		result.Members = append(result.Members, &dtos.TeamMemberUsage{
+			UserID:      member.UserId,
+			Login:       member.Login,
+			APIRequests: usage.APIRequests,
+			Queries:     usage.Queries,
+		})
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters getTeamUsage
+type GetTeamUsageParams struct {
+	// in:path
+	// required:true
+	TeamID string `json:"team_id"`
+}
+
+// swagger:response getTeamUsageResponse
+type GetTeamUsageResponse struct {
+	// The response message
+	// in: body
+	Body dtos.TeamUsage `json:"body"`
+}
+
 // swagger:parameters updateTeamPreferences
 type UpdateTeamPreferencesParams struct {
 	// in:path
@@ -355,6 +443,15 @@ type SearchTeamsParams struct {
 	// If set it will return results where the query value is contained in the name field. Query values with spaces need to be URL encoded.
 	// required:false
 	Query string `json:"query"`
+	// Sort the teams by name or email, e.g. `name-desc`.
+	// in:query
+	// required:false
+	// default: name-asc
+	Sort string `json:"sort"`
+	// Return the page after this cursor, the nextCursor field of the previous page.
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
 }
 
 // swagger:parameters createTeam
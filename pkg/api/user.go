@@ -534,6 +534,15 @@ type SearchUsersParams struct {
 	// required:false
 	// default:1
 	Page int64 `json:"page"`
+	// Sort the users by login, email or name, e.g. `email-desc`.
+	// in:query
+	// required:false
+	// default:login-asc
+	Sort string `json:"sort"`
+	// Return the page after this cursor, the X-Grafana-Next-Cursor header of the previous page.
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
 }
 
 // swagger:parameters searchUsersWithPaging
@@ -553,6 +562,15 @@ type SearchUsersWithPagingParams struct {
 	// in:query
 	// required:false
 	Query string `json:"query"`
+	// Sort the users by login, email or name, e.g. `email-desc`.
+	// in:query
+	// required:false
+	// default:login-asc
+	Sort string `json:"sort"`
+	// Return the page after this cursor, the nextCursor field of the previous page.
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
 }
 
 // swagger:parameters updateSignedInUser
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/backup"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func backupCmd(c utils.CommandLine, cfg *setting.Cfg, sqlStore db.DB) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("missing backup file argument")
+	}
+
+	// #nosec G304 -- the path is chosen by the administrator running the command
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	manifest, err := backup.ProvideService(cfg, sqlStore).Backup(context.Background(), f, backup.BackupOptions{
+		OrgID:       int64(c.Int("org-id")),
+		SkipSecrets: c.Bool("skip-secrets"),
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to back up: %w", err)
+	}
+
+	logger.Infof("Backed up %d tables and %d files to %s\n", len(manifest.Tables), len(manifest.Assets), path)
+	return nil
+}
+
+func restoreCmd(c utils.CommandLine, cfg *setting.Cfg, sqlStore db.DB) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("missing backup file argument")
+	}
+
+	// #nosec G304 -- the path is chosen by the administrator running the command
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	verifyOnly := c.Bool("verify-only")
+	manifest, err := backup.ProvideService(cfg, sqlStore).Restore(context.Background(), f, backup.RestoreOptions{VerifyOnly: verifyOnly})
+	if err != nil {
+		return fmt.Errorf("failed to restore: %w", err)
+	}
+
+	if verifyOnly {
+		logger.Info(color.GreenString("Backup created %s with Grafana %s is valid", manifest.Created.Format("2006-01-02 15:04:05"), manifest.GrafanaVersion) + "\n")
+		return nil
+	}
+	logger.Info(color.GreenString("Restored %d tables and %d files from %s", len(manifest.Tables), len(manifest.Assets), path) + "\n")
+	return nil
+}
@@ -44,6 +44,12 @@ func runRunnerCommand(command func(commandLine utils.CommandLine, runner runner.
 }
 
 func runDbCommand(command func(commandLine utils.CommandLine, sqlStore db.DB) error) func(context *cli.Context) error {
+	return runCfgDbCommand(func(commandLine utils.CommandLine, cfg *setting.Cfg, sqlStore db.DB) error {
+		return command(commandLine, sqlStore)
+	})
+}
+
+func runCfgDbCommand(command func(commandLine utils.CommandLine, cfg *setting.Cfg, sqlStore db.DB) error) func(context *cli.Context) error {
 	return func(context *cli.Context) error {
 		cmd := &utils.ContextCommandLine{Context: context}
 
@@ -64,7 +70,7 @@ func runDbCommand(command func(commandLine utils.CommandLine, sqlStore db.DB) er
 			return fmt.Errorf("%v: %w", "failed to initialize SQL store", err)
 		}
 
-		if err := command(cmd, sqlStore); err != nil {
+		if err := command(cmd, cfg, sqlStore); err != nil {
 			return err
 		}
 
@@ -253,6 +259,53 @@ grafana-cli user-manager conflicts ingest-file <filepath>
 	},
 }
 
+var configCommands = []*cli.Command{
+	{
+		Name:   "validate",
+		Usage:  "validates grafana.ini and the provisioning files, and checks that related settings are consistent",
+		Action: runConfigValidateCommand,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Fail on warnings too",
+				Value: false,
+			},
+		},
+	},
+}
+
+var backupCommand = &cli.Command{
+	Name:      "backup",
+	Usage:     "Writes a consistent backup of the database and the files of the data path to a file",
+	ArgsUsage: "<file>",
+	Action:    runCfgDbCommand(backupCmd),
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "org-id",
+			Usage: "Only back up the organization with this id",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-secrets",
+			Usage: "Leave encrypted secrets out of the backup, so that it can be restored with another secret key",
+			Value: false,
+		},
+	},
+}
+
+var restoreCommand = &cli.Command{
+	Name:      "restore",
+	Usage:     "Verifies a backup and restores it, replacing the content of the database or of the backed up organization",
+	ArgsUsage: "<file>",
+	Action:    runCfgDbCommand(restoreCmd),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "verify-only",
+			Usage: "Only verify the integrity of the backup",
+			Value: false,
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -264,4 +317,11 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "config",
+		Usage:       "Grafana configuration commands",
+		Subcommands: configCommands,
+	},
+	backupCommand,
+	restoreCommand,
 }
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/configvalidation"
+)
+
+func runConfigValidateCommand(context *cli.Context) error {
+	cmd := &utils.ContextCommandLine{Context: context}
+
+	cfg, err := initCfg(cmd)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to load configuration", err)
+	}
+
+	report := configvalidation.Validate(cfg)
+	for _, issue := range report.Issues {
+		if issue.Severity == configvalidation.SeverityError {
+			logger.Info(color.RedString(issue.String()) + "\n")
+		} else {
+			logger.Info(color.YellowString(issue.String()) + "\n")
+		}
+	}
+
+	if report.HasErrors(cmd.Bool("strict")) {
+		return fmt.Errorf("configuration is invalid, %d issues found", len(report.Issues))
+	}
+
+	logger.Info(color.GreenString("Configuration is valid") + "\n")
+	return nil
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func ReEncryptDEKS(_ utils.CommandLine, runner runner.Runner) error {
-	return runner.SecretsService.ReEncryptDataKeys(context.Background())
+	return runner.SecretsMigrator.ReEncryptDataKeys(context.Background())
 }
 
 func ReEncryptSecrets(_ utils.CommandLine, runner runner.Runner) error {
@@ -7,6 +7,33 @@ import (
 	"context"
 
 	"github.com/google/wire"
+	"github.com/grafana/grafana/pkg/infra/profiler"
+	"github.com/grafana/grafana/pkg/services/announcements"
+	"github.com/grafana/grafana/pkg/services/cdnassets"
+	"github.com/grafana/grafana/pkg/services/contentsync"
+	"github.com/grafana/grafana/pkg/services/dashboardbackup"
+	"github.com/grafana/grafana/pkg/services/dashboardcatalog"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/services/dashboardreview"
+	"github.com/grafana/grafana/pkg/services/dashboardsubscription"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
+	"github.com/grafana/grafana/pkg/services/editlock"
+	"github.com/grafana/grafana/pkg/services/embedtoken"
+	"github.com/grafana/grafana/pkg/services/loginhistory"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/oncall"
+	"github.com/grafana/grafana/pkg/services/panelexport"
+	"github.com/grafana/grafana/pkg/services/pluginjobs"
+	"github.com/grafana/grafana/pkg/services/querycapture"
+	"github.com/grafana/grafana/pkg/services/resourcelabel/resourcelabelimpl"
+	"github.com/grafana/grafana/pkg/services/scheduledreports"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
+	"github.com/grafana/grafana/pkg/services/synthetics"
+	"github.com/grafana/grafana/pkg/services/userdeactivation"
+	"github.com/grafana/grafana/pkg/services/userexport"
+	"github.com/grafana/grafana/pkg/services/variablecache"
+	"github.com/grafana/grafana/pkg/services/variableconstraints"
 	"github.com/grafana/grafana/pkg/tsdb/parca"
 	"github.com/grafana/grafana/pkg/tsdb/phlare"
 
@@ -22,9 +49,11 @@ import (
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/infra/semaphore"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
@@ -68,16 +97,20 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources/service"
+	"github.com/grafana/grafana/pkg/services/datasourceusage"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	encryptionservice "github.com/grafana/grafana/pkg/services/encryption/service"
 	"github.com/grafana/grafana/pkg/services/export"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/homerouting"
 	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/ldapsync"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/logannotations"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfoservice"
 	authinfodatabase "github.com/grafana/grafana/pkg/services/login/authinfoservice/database"
@@ -87,8 +120,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	ngmetrics "github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/notifications/emailstore"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/orglogs"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+	"github.com/grafana/grafana/pkg/services/orgtoken/orgtokenimpl"
 	"github.com/grafana/grafana/pkg/services/playlist/playlistimpl"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
@@ -126,6 +163,7 @@ import (
 	teamguardianDatabase "github.com/grafana/grafana/pkg/services/teamguardian/database"
 	teamguardianManager "github.com/grafana/grafana/pkg/services/teamguardian/manager"
 	"github.com/grafana/grafana/pkg/services/thumbs"
+	"github.com/grafana/grafana/pkg/services/traceannotations"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
 	"github.com/grafana/grafana/pkg/setting"
@@ -144,6 +182,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/opentsdb"
 	"github.com/grafana/grafana/pkg/tsdb/postgres"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+	"github.com/grafana/grafana/pkg/tsdb/syntheticsds"
 	"github.com/grafana/grafana/pkg/tsdb/tempo"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
 	"github.com/grafana/grafana/pkg/web"
@@ -181,6 +220,8 @@ var wireSet = wire.NewSet(
 	updatechecker.ProvideGrafanaService,
 	updatechecker.ProvidePluginsService,
 	uss.ProvideService,
+	wire.Bind(new(usagestats.UserUsageTracker), new(*uss.UsageStats)),
+	wire.Bind(new(usagestats.DataSourceUsageTracker), new(*uss.UsageStats)),
 	pluginsCfg.ProvideConfig,
 	registry.ProvideService,
 	wire.Bind(new(registry.Service), new(*registry.InMemory)),
@@ -213,11 +254,74 @@ var wireSet = wire.NewSet(
 	httpclientprovider.New,
 	wire.Bind(new(httpclient.Provider), new(*sdkhttpclient.Provider)),
 	serverlock.ProvideService,
+	leaderelection.ProvideService,
+	wire.Bind(new(leaderelection.Service), new(*leaderelection.LeaderElectionService)),
+	semaphore.ProvideService,
+	wire.Bind(new(semaphore.Service), new(*semaphore.SemaphoreService)),
 	cleanup.ProvideService,
 	shorturls.ProvideService,
 	wire.Bind(new(shorturls.Service), new(*shorturls.ShortURLService)),
 	queryhistory.ProvideService,
 	wire.Bind(new(queryhistory.Service), new(*queryhistory.QueryHistoryService)),
+	userexport.ProvideService,
+	wire.Bind(new(userexport.Service), new(*userexport.UserExportService)),
+	userdeactivation.ProvideService,
+	loginhistory.ProvideService,
+	wire.Bind(new(loginhistory.Service), new(*loginhistory.LoginHistoryService)),
+	pluginjobs.ProvideService,
+	wire.Bind(new(pluginjobs.Service), new(*pluginjobs.PluginJobsService)),
+	panelexport.ProvideService,
+	scheduledreports.ProvideService,
+	notificationcenter.ProvideService,
+	wire.Bind(new(notificationcenter.Service), new(*notificationcenter.NotificationCenterService)),
+	announcements.ProvideService,
+	wire.Bind(new(announcements.Service), new(*announcements.AnnouncementService)),
+	dashboardcatalog.ProvideService,
+	wire.Bind(new(dashboardcatalog.Service), new(*dashboardcatalog.CatalogService)),
+	dashboardlint.ProvideService,
+	wire.Bind(new(dashboardlint.Service), new(*dashboardlint.LintService)),
+	homerouting.ProvideService,
+	wire.Bind(new(homerouting.Service), new(*homerouting.HomeRoutingService)),
+	variablecache.ProvideService,
+	wire.Bind(new(variablecache.Service), new(*variablecache.VariableCacheService)),
+	querycapture.ProvideService,
+	wire.Bind(new(querycapture.Service), new(*querycapture.QueryCaptureService)),
+	networkpolicy.ProvideService,
+	wire.Bind(new(networkpolicy.Service), new(*networkpolicy.NetworkPolicyService)),
+	securityheaders.ProvideService,
+	wire.Bind(new(securityheaders.Service), new(*securityheaders.SecurityHeadersService)),
+	embedtoken.ProvideService,
+	wire.Bind(new(embedtoken.Service), new(*embedtoken.EmbedTokenService)),
+	dashboardsubscription.ProvideService,
+	wire.Bind(new(dashboardsubscription.Service), new(*dashboardsubscription.SubscriptionService)),
+	dashboardreview.ProvideService,
+	wire.Bind(new(dashboardreview.Service), new(*dashboardreview.ReviewService)),
+	dashboardbackup.ProvideService,
+	wire.Bind(new(dashboardbackup.Service), new(*dashboardbackup.BackupService)),
+	editlock.ProvideService,
+	wire.Bind(new(editlock.Service), new(*editlock.EditLockService)),
+	oncall.ProvideService,
+	wire.Bind(new(oncall.Service), new(*oncall.OnCallService)),
+	synthetics.ProvideService,
+	wire.Bind(new(synthetics.Service), new(*synthetics.SyntheticsService)),
+	datasourceusage.ProvideService,
+	wire.Bind(new(datasourceusage.Service), new(*datasourceusage.UsageService)),
+	traceannotations.ProvideService,
+	wire.Bind(new(traceannotations.Service), new(*traceannotations.TraceAnnotationService)),
+	logannotations.ProvideService,
+	wire.Bind(new(logannotations.Service), new(*logannotations.LogAnnotationService)),
+	ldapsync.ProvideService,
+	wire.Bind(new(ldapsync.Service), new(*ldapsync.LDAPSyncService)),
+	variableconstraints.ProvideService,
+	wire.Bind(new(variableconstraints.Service), new(*variableconstraints.ConstraintService)),
+	orglogs.ProvideService,
+	wire.Bind(new(orglogs.Service), new(*orglogs.OrgLogsService)),
+	orgtokenimpl.ProvideService,
+	wire.Bind(new(orgtoken.Service), new(*orgtokenimpl.OrgTokenService)),
+	contentsync.ProvideService,
+	dashboardtemplates.ProvideService,
+	cdnassets.ProvideService,
+	resourcelabelimpl.ProvideService,
 	quotaimpl.ProvideService,
 	remotecache.ProvideService,
 	loginservice.ProvideService,
@@ -247,7 +351,10 @@ var wireSet = wire.NewSet(
 	wire.Bind(new(libraryelements.Service), new(*libraryelements.LibraryElementService)),
 	notifications.ProvideService,
 	notifications.ProvideSmtpService,
+	emailstore.ProvideStore,
+	wire.Bind(new(notifications.EmailStore), new(*emailstore.Store)),
 	metrics.ProvideService,
+	profiler.ProvideService,
 	testdatasource.ProvideService,
 	social.ProvideService,
 	influxdb.ProvideService,
@@ -264,6 +371,7 @@ var wireSet = wire.NewSet(
 	secretsMigrator.ProvideSecretsMigrator,
 	wire.Bind(new(secrets.Migrator), new(*secretsMigrator.SecretsMigrator)),
 	grafanads.ProvideService,
+	syntheticsds.ProvideService,
 	wire.Bind(new(dashboardsnapshots.Store), new(*dashsnapstore.DashboardSnapshotStore)),
 	dashsnapstore.ProvideStore,
 	wire.Bind(new(dashboardsnapshots.Service), new(*dashsnapsvc.ServiceImpl)),
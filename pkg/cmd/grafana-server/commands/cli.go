@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/server"
 	_ "github.com/grafana/grafana/pkg/services/alerting/conditions"
 	_ "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/services/configvalidation"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -65,6 +66,8 @@ func RunServer(opt ServerOptions) int {
 		profilePort = serverFs.Uint64("profile-port", 6060, "Define custom port for profiling")
 		tracing     = serverFs.Bool("tracing", false, "Turn on tracing")
 		tracingFile = serverFs.String("tracing-file", "trace.out", "Define tracing output file")
+
+		strictConfig = serverFs.Bool("strict-config", false, "Validate the configuration and provisioning files and refuse to start if they have errors")
 	)
 
 	if err := serverFs.Parse(opt.Args); err != nil {
@@ -112,7 +115,7 @@ func RunServer(opt ServerOptions) int {
 		}()
 	}
 
-	if err := executeServer(*configFile, *homePath, *pidFile, *packaging, *configOverrides, traceDiagnostics, opt); err != nil {
+	if err := executeServer(*configFile, *homePath, *pidFile, *packaging, *configOverrides, *strictConfig, traceDiagnostics, opt); err != nil {
 		code := 1
 		var ewc exitWithCode
 		if errors.As(err, &ewc) {
@@ -128,7 +131,7 @@ func RunServer(opt ServerOptions) int {
 	return 0
 }
 
-func executeServer(configFile, homePath, pidFile, packaging, configOverrides string, traceDiagnostics *tracingDiagnostics, opt ServerOptions) error {
+func executeServer(configFile, homePath, pidFile, packaging, configOverrides string, strictConfig bool, traceDiagnostics *tracingDiagnostics, opt ServerOptions) error {
 	defer func() {
 		if err := log.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to close log: %s\n", err)
@@ -190,14 +193,22 @@ func executeServer(configFile, homePath, pidFile, packaging, configOverrides str
 	}
 
 	configOptions := strings.Split(configOverrides, " ")
+	cfgArgs := setting.CommandLineArgs{
+		Config:   configFile,
+		HomePath: homePath,
+		// tailing arguments have precedence over the options string
+		Args: append(configOptions, serverFs.Args()...),
+	}
+
+	if strictConfig {
+		if err := validateConfig(cfgArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start grafana. error: %s\n", err.Error())
+			return err
+		}
+	}
 
 	s, err := server.Initialize(
-		setting.CommandLineArgs{
-			Config:   configFile,
-			HomePath: homePath,
-			// tailing arguments have precedence over the options string
-			Args: append(configOptions, serverFs.Args()...),
-		},
+		cfgArgs,
 		server.Options{
 			PidFile:     pidFile,
 			Version:     opt.Version,
@@ -226,6 +237,24 @@ func executeServer(configFile, homePath, pidFile, packaging, configOverrides str
 	return nil
 }
 
+// validateConfig validates the configuration and the provisioning files before the server boots,
+// printing all issues found.
+func validateConfig(args setting.CommandLineArgs) error {
+	cfg, err := setting.NewCfgFromArgs(args)
+	if err != nil {
+		return err
+	}
+
+	report := configvalidation.Validate(cfg)
+	for _, issue := range report.Issues {
+		fmt.Fprintln(os.Stderr, issue.String())
+	}
+	if report.HasErrors(false) {
+		return fmt.Errorf("configuration is invalid")
+	}
+	return nil
+}
+
 func validPackaging(packaging string) string {
 	validTypes := []string{"dev", "deb", "rpm", "docker", "brew", "hosted", "unknown"}
 	for _, vt := range validTypes {
@@ -249,6 +278,9 @@ func listenToSystemSignals(ctx context.Context, s *server.Server) {
 			if err := log.Reload(); err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to reload loggers: %s\n", err)
 			}
+			if _, err := s.HTTPServer.SettingsProvider.Reload(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to reload settings: %s\n", err)
+			}
 		case sig := <-signalChan:
 			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 			defer cancel()
@@ -78,3 +78,142 @@ type FolderTitleUpdated struct {
 	UID       string    `json:"uid"`
 	OrgID     int64     `json:"org_id"`
 }
+
+type FolderSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	Version   int       `json:"version"`
+}
+
+type FolderDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type DataSourceUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	Version   int       `json:"version"`
+}
+
+type DashboardSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	FolderID  int64     `json:"folder_id"`
+	Version   int       `json:"version"`
+	UserID    int64     `json:"user_id"`
+	// PreviousFolderID is the folder the dashboard was in before it was saved, it differs from
+	// FolderID when the dashboard was moved.
+	PreviousFolderID int64 `json:"previous_folder_id"`
+}
+
+type DashboardDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+// ResourcePermissionsUpdated is published when the permissions of a dashboard or a folder change.
+type ResourcePermissionsUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Resource is dashboards or folders.
+	Resource string `json:"resource"`
+	UID      string `json:"uid"`
+	OrgID    int64  `json:"org_id"`
+}
+
+// Sources of the changes to the members of an organization.
+const (
+	// OrgUserSourceManual is a change made by a user or through the API.
+	OrgUserSourceManual = "manual"
+	// OrgUserSourceSync is a change made when syncing the roles of an external user, e.g. from
+	// LDAP or OAuth, on login.
+	OrgUserSourceSync = "sync"
+	// OrgUserSourceSCIM is a change made by a SCIM provisioning client.
+	OrgUserSourceSCIM = "scim"
+)
+
+type OrgUserAdded struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgID     int64     `json:"org_id"`
+	UserID    int64     `json:"user_id"`
+	Role      string    `json:"role"`
+	Source    string    `json:"source"`
+}
+
+type OrgUserRoleChanged struct {
+	Timestamp    time.Time `json:"timestamp"`
+	OrgID        int64     `json:"org_id"`
+	UserID       int64     `json:"user_id"`
+	Role         string    `json:"role"`
+	PreviousRole string    `json:"previous_role"`
+	Source       string    `json:"source"`
+}
+
+type OrgUserRemoved struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgID     int64     `json:"org_id"`
+	UserID    int64     `json:"user_id"`
+	Role      string    `json:"role"`
+	Source    string    `json:"source"`
+	// UserDeleted is whether the user was deleted because it was not a member of any other
+	// organization.
+	UserDeleted bool `json:"user_deleted"`
+}
+
+type AlertRuleCreated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	FolderUID string    `json:"folder_uid"`
+	RuleGroup string    `json:"rule_group"`
+}
+
+type AlertRuleUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	FolderUID string    `json:"folder_uid"`
+	RuleGroup string    `json:"rule_group"`
+	Version   int64     `json:"version"`
+}
+
+type AlertRuleDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type LibraryElementSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	Kind      int64     `json:"kind"`
+	Version   int64     `json:"version"`
+	// PreviousUID is the uid of the library element before it was saved, it differs from UID when
+	// the uid was changed.
+	PreviousUID string `json:"previous_uid"`
+}
+
+type LibraryElementDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
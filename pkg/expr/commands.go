@@ -295,6 +295,14 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeWindow is the CMDType for a function over a moving window of a timeseries.
+	TypeWindow
+	// TypeDecompose is the CMDType for a seasonal decomposition of a timeseries.
+	TypeDecompose
+	// TypeForecast is the CMDType for a Holt-Winters forecast of a timeseries.
+	TypeForecast
+	// TypeJoin is the CMDType for math between timeseries joined on time and labels.
+	TypeJoin
 )
 
 func (gt CommandType) String() string {
@@ -307,6 +315,14 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeWindow:
+		return "window"
+	case TypeDecompose:
+		return "decompose"
+	case TypeForecast:
+		return "forecast"
+	case TypeJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -325,6 +341,14 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "window":
+		return TypeWindow, nil
+	case "decompose":
+		return TypeDecompose, nil
+	case "forecast":
+		return TypeForecast, nil
+	case "join":
+		return TypeJoin, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
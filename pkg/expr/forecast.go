@@ -0,0 +1,169 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// Default smoothing factors of the forecast command.
+const (
+	defaultForecastAlpha = 0.5
+	defaultForecastBeta  = 0.1
+	defaultForecastGamma = 0.1
+)
+
+// DecomposeCommand is an expression command which returns the trend, seasonal or residual
+// component of a timeseries.
+type DecomposeCommand struct {
+	VarToDecompose string
+	Season         time.Duration
+	Component      string
+	refID          string
+}
+
+// NewDecomposeCommand creates a new DecomposeCommand.
+func NewDecomposeCommand(refID, varToDecompose, rawSeason, component string) (*DecomposeCommand, error) {
+	season, err := gtime.ParseDuration(rawSeason)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse decompose "season" duration field %q: %w`, rawSeason, err)
+	}
+	if season <= 0 {
+		return nil, fmt.Errorf("season duration must be positive, got %q", rawSeason)
+	}
+	if !isSupported(mathexp.DecomposeComponents, component) {
+		return nil, fmt.Errorf("decompose component %q is not supported, supported are %s", component, strings.Join(mathexp.DecomposeComponents, ", "))
+	}
+
+	return &DecomposeCommand{
+		VarToDecompose: varToDecompose,
+		Season:         season,
+		Component:      component,
+		refID:          refID,
+	}, nil
+}
+
+// UnmarshalDecomposeCommand creates a DecomposeCommand from Grafana's frontend query.
+func UnmarshalDecomposeCommand(rn *rawNode) (*DecomposeCommand, error) {
+	varToDecompose, err := getVarParam(rn)
+	if err != nil {
+		return nil, err
+	}
+	season, err := getStringParam(rn, "season", "")
+	if err != nil {
+		return nil, err
+	}
+	component, err := getStringParam(rn, "component", "trend")
+	if err != nil {
+		return nil, err
+	}
+	return NewDecomposeCommand(rn.RefID, varToDecompose, season, component)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (dc *DecomposeCommand) NeedsVars() []string {
+	return []string{dc.VarToDecompose}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (dc *DecomposeCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	return mapSeries(vars[dc.VarToDecompose], "decompose", func(s mathexp.Series) (mathexp.Series, error) {
+		return s.Decompose(dc.refID, dc.Season, dc.Component)
+	})
+}
+
+// ForecastCommand is an expression command which predicts each point of a timeseries and forecasts
+// it beyond its last point with Holt-Winters exponential smoothing.
+type ForecastCommand struct {
+	VarToForecast string
+	Horizon       time.Duration
+	Params        mathexp.HoltWinters
+	refID         string
+}
+
+// NewForecastCommand creates a new ForecastCommand. The season is optional.
+func NewForecastCommand(refID, varToForecast, rawHorizon, rawSeason string, alpha, beta, gamma float64) (*ForecastCommand, error) {
+	horizon, err := gtime.ParseDuration(rawHorizon)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, rawHorizon, err)
+	}
+	if horizon < 0 {
+		return nil, fmt.Errorf("horizon duration must not be negative, got %q", rawHorizon)
+	}
+
+	var season time.Duration
+	if rawSeason != "" {
+		season, err = gtime.ParseDuration(rawSeason)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "season" duration field %q: %w`, rawSeason, err)
+		}
+		if season <= 0 {
+			return nil, fmt.Errorf("season duration must be positive, got %q", rawSeason)
+		}
+	}
+
+	for name, factor := range map[string]float64{"alpha": alpha, "beta": beta, "gamma": gamma} {
+		if factor < 0 || factor > 1 {
+			return nil, fmt.Errorf("forecast %s must be between 0 and 1, got %v", name, factor)
+		}
+	}
+
+	return &ForecastCommand{
+		VarToForecast: varToForecast,
+		Horizon:       horizon,
+		Params:        mathexp.HoltWinters{Alpha: alpha, Beta: beta, Gamma: gamma, Season: season},
+		refID:         refID,
+	}, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	varToForecast, err := getVarParam(rn)
+	if err != nil {
+		return nil, err
+	}
+	horizon, err := getStringParam(rn, "horizon", "0s")
+	if err != nil {
+		return nil, err
+	}
+	season := ""
+	if _, ok := rn.Query["season"]; ok {
+		if season, err = getStringParam(rn, "season", ""); err != nil {
+			return nil, err
+		}
+	}
+	alpha, err := getNumberParam(rn, "alpha", defaultForecastAlpha)
+	if err != nil {
+		return nil, err
+	}
+	beta, err := getNumberParam(rn, "beta", defaultForecastBeta)
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := getNumberParam(rn, "gamma", defaultForecastGamma)
+	if err != nil {
+		return nil, err
+	}
+	return NewForecastCommand(rn.RefID, varToForecast, horizon, season, alpha, beta, gamma)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.VarToForecast}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	return mapSeries(vars[fc.VarToForecast], "forecast", func(s mathexp.Series) (mathexp.Series, error) {
+		return s.Forecast(fc.refID, fc.Params, fc.Horizon)
+	})
+}
@@ -0,0 +1,172 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// JoinCommand is an expression command which joins the series of several queries on their labels
+// and time stamps and applies a math expression to each joined group, such as "$A / $B" between
+// series of different datasources.
+type JoinCommand struct {
+	Inputs        []string
+	RawExpression string
+	Expression    *mathexp.Expr
+	Options       mathexp.JoinOptions
+	refID         string
+}
+
+// NewJoinCommand creates a new JoinCommand. The tolerance and fill value are optional.
+func NewJoinCommand(refID string, inputs []string, expr, mode string, on []string, rawTolerance string, fill *float64) (*JoinCommand, error) {
+	if len(inputs) < 2 {
+		return nil, fmt.Errorf("join requires at least two inputs, got %d", len(inputs))
+	}
+	if !isSupported(mathexp.JoinModes, mode) {
+		return nil, fmt.Errorf("join mode %q is not supported, supported are %s", mode, strings.Join(mathexp.JoinModes, ", "))
+	}
+
+	var tolerance time.Duration
+	if rawTolerance != "" {
+		var err error
+		tolerance, err = gtime.ParseDuration(rawTolerance)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse join "tolerance" duration field %q: %w`, rawTolerance, err)
+		}
+		if tolerance < 0 {
+			return nil, fmt.Errorf("tolerance duration must not be negative, got %q", rawTolerance)
+		}
+	}
+
+	parsedExpr, err := mathexp.New(expr)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range parsedExpr.VarNames {
+		if !isSupported(inputs, name) {
+			return nil, fmt.Errorf("join expression uses $%s which is not an input of the join", name)
+		}
+	}
+
+	return &JoinCommand{
+		Inputs:        inputs,
+		RawExpression: expr,
+		Expression:    parsedExpr,
+		Options: mathexp.JoinOptions{
+			Mode:      mathexp.JoinMode(mode),
+			On:        on,
+			Tolerance: tolerance,
+			Fill:      fill,
+		},
+		refID: refID,
+	}, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	inputs, err := getStringListParam(rn, "inputs")
+	if err != nil {
+		return nil, err
+	}
+	for i, input := range inputs {
+		inputs[i] = strings.TrimPrefix(input, "$")
+	}
+	rawExpr, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("command is missing an expression")
+	}
+	expr, ok := rawExpr.(string)
+	if !ok {
+		return nil, fmt.Errorf("join expression is expected to be a string, got %T", rawExpr)
+	}
+	mode, err := getStringParam(rn, "mode", string(mathexp.JoinInner))
+	if err != nil {
+		return nil, err
+	}
+	var on []string
+	if _, ok := rn.Query["on"]; ok {
+		if on, err = getStringListParam(rn, "on"); err != nil {
+			return nil, err
+		}
+	}
+	tolerance := ""
+	if _, ok := rn.Query["tolerance"]; ok {
+		if tolerance, err = getStringParam(rn, "tolerance", ""); err != nil {
+			return nil, err
+		}
+	}
+	var fill *float64
+	if raw, ok := rn.Query["fill"]; ok && raw != nil {
+		value, err := getNumberParam(rn, "fill", 0)
+		if err != nil {
+			return nil, err
+		}
+		fill = &value
+	}
+	return NewJoinCommand(rn.RefID, inputs, expr, mode, on, tolerance, fill)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	return jc.Inputs
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (jc *JoinCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	inputs := make([]mathexp.Results, len(jc.Inputs))
+	for i, name := range jc.Inputs {
+		inputs[i] = vars[name]
+	}
+	groups, err := mathexp.Join(jc.Inputs, inputs, jc.Options)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+
+	newRes := mathexp.Results{}
+	for _, group := range groups {
+		res, err := jc.Expression.Execute(jc.refID, group.Vars)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		for _, val := range res.Values {
+			if _, ok := val.(mathexp.NoData); ok {
+				continue
+			}
+			val.SetLabels(group.Labels.Copy())
+			newRes.Values = append(newRes.Values, val)
+		}
+	}
+	if len(newRes.Values) == 0 {
+		newRes.Values = mathexp.Values{mathexp.NoData{}.New()}
+	}
+	return newRes, nil
+}
+
+// getStringListParam returns a required field of a command which is a list of strings.
+func getStringListParam(rn *rawNode, key string) ([]string, error) {
+	raw, ok := rn.Query[key]
+	if !ok {
+		return nil, fmt.Errorf("no %s specified for refId %v", key, rn.RefID)
+	}
+	rawList, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected %s to be a list of strings, got %T for refId %v", key, raw, rn.RefID)
+	}
+	values := make([]string, 0, len(rawList))
+	for _, item := range rawList {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %s to be a list of strings, got an item of type %T for refId %v", key, item, rn.RefID)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,123 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalJoinCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expectedError string
+	}{
+		{
+			description: "join with defaults",
+			query:       `{"type": "join", "inputs": ["$A", "B"], "expression": "$A / $B"}`,
+		},
+		{
+			description: "join with all options",
+			query:       `{"type": "join", "inputs": ["A", "B"], "expression": "$A - $B", "mode": "outer", "on": ["job"], "tolerance": "30s", "fill": 0}`,
+		},
+		{
+			description:   "join with a single input",
+			query:         `{"type": "join", "inputs": ["A"], "expression": "$A"}`,
+			expectedError: "at least two inputs",
+		},
+		{
+			description:   "join unsupported mode",
+			query:         `{"type": "join", "inputs": ["A", "B"], "expression": "$A / $B", "mode": "cross"}`,
+			expectedError: "not supported",
+		},
+		{
+			description:   "join expression with variable which is no input",
+			query:         `{"type": "join", "inputs": ["A", "B"], "expression": "$A / $C"}`,
+			expectedError: "not an input of the join",
+		},
+		{
+			description:   "join invalid tolerance",
+			query:         `{"type": "join", "inputs": ["A", "B"], "expression": "$A / $B", "tolerance": "soon"}`,
+			expectedError: "failed to parse",
+		},
+		{
+			description:   "join inputs are no list",
+			query:         `{"type": "join", "inputs": "A", "expression": "$A"}`,
+			expectedError: "list of strings",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var query map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &query))
+			rn := &rawNode{RefID: "C", Query: query}
+			commandType, err := rn.GetCommandType()
+			require.NoError(t, err)
+			require.Equal(t, TypeJoin, commandType)
+
+			cmd, err := UnmarshalJoinCommand(rn)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestJoinCommandExecute(t *testing.T) {
+	newSeries := func(labels data.Labels, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries("", labels, len(values))
+		for i := range values {
+			s.SetPoint(i, time.Unix(int64(i*60), 0), &values[i])
+		}
+		return s
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			newSeries(data.Labels{"host": "a", "__name__": "errors"}, 1, 2, 3),
+			newSeries(data.Labels{"host": "b", "__name__": "errors"}, 1, 1, 1),
+		}},
+		"B": mathexp.Results{Values: mathexp.Values{
+			newSeries(data.Labels{"instance": "a", "__name__": "requests"}, 10, 10),
+		}},
+	}
+
+	t.Run("ratio between series joined on a label", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "B"}, "$A / $B", "left", []string{"host"}, "", nil)
+		require.NoError(t, err)
+		results, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": vars["A"],
+			"B": mathexp.Results{Values: mathexp.Values{newSeries(data.Labels{"host": "a"}, 10, 10)}},
+		})
+		require.NoError(t, err)
+		require.Len(t, results.Values, 2)
+
+		ratio := results.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a", "__name__": "errors"}, ratio.GetLabels())
+		require.Equal(t, 3, ratio.Len())
+		require.InDelta(t, 0.2, *ratio.GetValue(1), 1e-9)
+		require.Nil(t, ratio.GetValue(2))
+
+		missing := results.Values[1].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "b", "__name__": "errors"}, missing.GetLabels())
+		require.Nil(t, missing.GetValue(0))
+	})
+
+	t.Run("inner join without matches has no data", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", []string{"A", "B"}, "$A / $B", "inner", []string{"host"}, "", nil)
+		require.NoError(t, err)
+		results, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, results.Values, 1)
+		require.IsType(t, mathexp.NoData{}, results.Values[0])
+	})
+}
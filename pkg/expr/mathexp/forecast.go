@@ -0,0 +1,231 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DecomposeComponents are the components of a series Decompose can return.
+var DecomposeComponents = []string{"trend", "seasonal", "residual"}
+
+// Decompose splits a series into a trend, a seasonal and a residual component, which add up to the
+// series, and returns one of them. The trend is the centered moving average over a season, and the
+// seasonal component the average difference to the trend of the points at the same position in the
+// season. The series must have regular intervals, sorted by time in ascending order, and cover at
+// least two seasons.
+func (s Series) Decompose(refID string, season time.Duration, component string) (Series, error) {
+	switch component {
+	case "trend", "seasonal", "residual":
+	default:
+		return s, fmt.Errorf("decompose component %q is not supported, supported are %v", component, DecomposeComponents)
+	}
+	period, _, err := s.seasonLength(season)
+	if err != nil {
+		return s, err
+	}
+	if s.Len() < 2*period {
+		return s, fmt.Errorf("the series must cover at least two seasons of %v points, has %v points", period, s.Len())
+	}
+
+	trend := s.centeredMovingAverage(period)
+	seasonal := s.seasonalIndices(trend, period)
+
+	result := NewSeries(refID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		var value *float64
+		switch component {
+		case "trend":
+			value = trend[i]
+		case "seasonal":
+			value = floatPtr(seasonal[i%period])
+		case "residual":
+			if v := s.GetValue(i); v != nil && trend[i] != nil {
+				value = floatPtr(*v - *trend[i] - seasonal[i%period])
+			}
+		}
+		result.SetPoint(i, s.GetTime(i), value)
+	}
+	return result, nil
+}
+
+// centeredMovingAverage returns the average of the season centered on each point, which is null
+// where the season is not complete. Seasons with an even number of points are centered by weighting
+// the points at both ends with a half.
+func (s Series) centeredMovingAverage(period int) []*float64 {
+	half := period / 2
+	result := make([]*float64, s.Len())
+	for i := half; i < s.Len()-half; i++ {
+		sum := 0.0
+		complete := true
+		for j := i - half; j <= i+half; j++ {
+			v := s.GetValue(j)
+			if v == nil || math.IsNaN(*v) {
+				complete = false
+				break
+			}
+			weight := 1.0
+			if period%2 == 0 && (j == i-half || j == i+half) {
+				weight = 0.5
+			}
+			sum += weight * *v
+		}
+		if complete {
+			result[i] = floatPtr(sum / float64(period))
+		}
+	}
+	return result
+}
+
+// seasonalIndices returns the average difference to the trend of each position in the season,
+// adjusted to add up to zero over the season.
+func (s Series) seasonalIndices(trend []*float64, period int) []float64 {
+	sums := make([]float64, period)
+	counts := make([]int, period)
+	for i := 0; i < s.Len(); i++ {
+		v := s.GetValue(i)
+		if v == nil || math.IsNaN(*v) || trend[i] == nil {
+			continue
+		}
+		sums[i%period] += *v - *trend[i]
+		counts[i%period]++
+	}
+
+	indices := make([]float64, period)
+	total := 0.0
+	for k := range indices {
+		if counts[k] > 0 {
+			indices[k] = sums[k] / float64(counts[k])
+		}
+		total += indices[k]
+	}
+	for k := range indices {
+		indices[k] -= total / float64(period)
+	}
+	return indices
+}
+
+// HoltWinters are the smoothing factors of a Holt-Winters forecast, each between 0 and 1. Alpha
+// smooths the level, Beta the trend and Gamma the seasonal component.
+type HoltWinters struct {
+	Alpha float64
+	Beta  float64
+	Gamma float64
+	// Season is the length of the season, 0 for series without seasonality.
+	Season time.Duration
+}
+
+func (hw HoltWinters) validate() error {
+	for name, factor := range map[string]float64{"alpha": hw.Alpha, "beta": hw.Beta, "gamma": hw.Gamma} {
+		if factor < 0 || factor > 1 || math.IsNaN(factor) {
+			return fmt.Errorf("the smoothing factor %s must be between 0 and 1, got %v", name, factor)
+		}
+	}
+	return nil
+}
+
+// Forecast applies additive Holt-Winters exponential smoothing to a series. It returns the one step
+// ahead prediction for each point of the series, which can be compared with the series to find
+// anomalies, followed by the points forecast for the horizon. Predictions start after the first
+// season, or the first point without seasonality. Null values are replaced with their prediction.
+// The series must have regular intervals, sorted by time in ascending order.
+func (s Series) Forecast(refID string, hw HoltWinters, horizon time.Duration) (Series, error) {
+	if err := hw.validate(); err != nil {
+		return s, err
+	}
+	if horizon < 0 {
+		return s, fmt.Errorf("the horizon must not be negative, got %v", horizon)
+	}
+
+	var step time.Duration
+	var err error
+	period := 1
+	if hw.Season > 0 {
+		period, step, err = s.seasonLength(hw.Season)
+		if err == nil && s.Len() < 2*period {
+			err = fmt.Errorf("the series must cover at least two seasons of %v points, has %v points", period, s.Len())
+		}
+	} else {
+		step, err = s.step()
+	}
+	if err != nil {
+		return s, err
+	}
+
+	values := make([]float64, s.Len())
+	for i := range values {
+		values[i] = math.NaN()
+		if v := s.GetValue(i); v != nil {
+			values[i] = *v
+		}
+	}
+	level, trend, seasonal, err := initHoltWinters(values, period)
+	if err != nil {
+		return s, err
+	}
+
+	points := int(horizon / step)
+	result := NewSeries(refID, s.GetLabels(), s.Len()+points)
+	start := period
+	if hw.Season == 0 {
+		start = 1
+	}
+	for i := 0; i < s.Len(); i++ {
+		var prediction *float64
+		if i >= start {
+			predicted := level + trend + seasonal[i%period]
+			prediction = &predicted
+
+			x := values[i]
+			if math.IsNaN(x) {
+				x = predicted
+			}
+			previousLevel := level
+			level = hw.Alpha*(x-seasonal[i%period]) + (1-hw.Alpha)*(level+trend)
+			trend = hw.Beta*(level-previousLevel) + (1-hw.Beta)*trend
+			if hw.Season > 0 {
+				seasonal[i%period] = hw.Gamma*(x-level) + (1-hw.Gamma)*seasonal[i%period]
+			}
+		}
+		result.SetPoint(i, s.GetTime(i), prediction)
+	}
+
+	last := s.GetTime(s.Len() - 1)
+	for h := 1; h <= points; h++ {
+		value := level + float64(h)*trend + seasonal[(s.Len()-1+h)%period]
+		result.SetPoint(s.Len()-1+h, last.Add(time.Duration(h)*step), &value)
+	}
+	return result, nil
+}
+
+// initHoltWinters estimates the initial level and trend from the first two seasons, and the seasonal
+// component from the first season. Without seasonality, they are estimated from the first two points.
+func initHoltWinters(values []float64, period int) (level, trend float64, seasonal []float64, err error) {
+	seasonal = make([]float64, period)
+	if period == 1 {
+		if math.IsNaN(values[0]) || math.IsNaN(values[1]) {
+			return 0, 0, nil, fmt.Errorf("the first two points of the series must not be null")
+		}
+		return values[0], values[1] - values[0], seasonal, nil
+	}
+
+	first, second := 0.0, 0.0
+	for i := 0; i < period; i++ {
+		if math.IsNaN(values[i]) || math.IsNaN(values[period+i]) {
+			return 0, 0, nil, fmt.Errorf("the first two seasons of the series must not have null values")
+		}
+		first += values[i]
+		second += values[period+i]
+	}
+	first /= float64(period)
+	second /= float64(period)
+
+	for i := 0; i < period; i++ {
+		seasonal[i] = values[i] - first
+	}
+	return first, (second - first) / float64(period), seasonal, nil
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
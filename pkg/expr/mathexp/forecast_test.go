@@ -0,0 +1,101 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var seasonPattern = []float64{1, -1, 2, -2}
+
+// seasonalSeries returns a series with a point every minute, a linear trend and a season of four
+// minutes.
+func seasonalSeries(points int) Series {
+	values := make([]*float64, points)
+	for i := range values {
+		values[i] = float64Pointer(10 + 0.5*float64(i) + seasonPattern[i%4])
+	}
+	return seriesOf(values...)
+}
+
+func TestSeriesDecompose(t *testing.T) {
+	input := seasonalSeries(12)
+
+	t.Run("trend", func(t *testing.T) {
+		trend, err := input.Decompose("B", 4*time.Minute, "trend")
+		require.NoError(t, err)
+		for i := 0; i < trend.Len(); i++ {
+			if i < 2 || i > 9 {
+				assert.Nil(t, trend.GetValue(i), "point %d", i)
+				continue
+			}
+			require.NotNil(t, trend.GetValue(i), "point %d", i)
+			assert.InDelta(t, 10+0.5*float64(i), *trend.GetValue(i), 1e-9, "point %d", i)
+		}
+	})
+
+	t.Run("seasonal", func(t *testing.T) {
+		seasonal, err := input.Decompose("B", 4*time.Minute, "seasonal")
+		require.NoError(t, err)
+		for i := 0; i < seasonal.Len(); i++ {
+			assert.InDelta(t, seasonPattern[i%4], *seasonal.GetValue(i), 1e-9, "point %d", i)
+		}
+	})
+
+	t.Run("residual", func(t *testing.T) {
+		residual, err := input.Decompose("B", 4*time.Minute, "residual")
+		require.NoError(t, err)
+		for i := 2; i <= 9; i++ {
+			assert.InDelta(t, 0, *residual.GetValue(i), 1e-9, "point %d", i)
+		}
+	})
+
+	t.Run("series shorter than two seasons", func(t *testing.T) {
+		_, err := seasonalSeries(7).Decompose("B", 4*time.Minute, "trend")
+		assert.Error(t, err)
+	})
+
+	t.Run("season shorter than two points", func(t *testing.T) {
+		_, err := input.Decompose("B", time.Minute, "trend")
+		assert.Error(t, err)
+	})
+}
+
+func TestSeriesForecast(t *testing.T) {
+	t.Run("linear series without season", func(t *testing.T) {
+		input := seriesOf(float64Pointer(0), float64Pointer(2), float64Pointer(4), nil, float64Pointer(8))
+		forecast, err := input.Forecast("B", HoltWinters{Alpha: 0.5, Beta: 0.1}, 3*time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 8, forecast.Len())
+
+		assert.Nil(t, forecast.GetValue(0))
+		for i := 1; i < forecast.Len(); i++ {
+			require.NotNil(t, forecast.GetValue(i), "point %d", i)
+			assert.InDelta(t, 2*float64(i), *forecast.GetValue(i), 1e-9, "point %d", i)
+			assert.Equal(t, time.Unix(int64(i*60), 0), forecast.GetTime(i))
+		}
+	})
+
+	t.Run("seasonal series", func(t *testing.T) {
+		input := seasonalSeries(40)
+		forecast, err := input.Forecast("B", HoltWinters{Alpha: 0.5, Beta: 0.1, Gamma: 0.3, Season: 4 * time.Minute}, 8*time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 48, forecast.Len())
+
+		for i := 0; i < 4; i++ {
+			assert.Nil(t, forecast.GetValue(i), "point %d", i)
+		}
+		// the forecast follows the trend and the season
+		for i := 40; i < 48; i++ {
+			expected := 10 + 0.5*float64(i) + seasonPattern[i%4]
+			assert.InDelta(t, expected, *forecast.GetValue(i), 0.5, "point %d", i)
+		}
+	})
+
+	t.Run("invalid smoothing factor", func(t *testing.T) {
+		_, err := seasonalSeries(8).Forecast("B", HoltWinters{Alpha: 1.5}, 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,294 @@
+package mathexp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// JoinMode decides which items of the inputs of a join are kept when they have no match.
+type JoinMode string
+
+const (
+	// JoinInner keeps the items and time stamps which all inputs have.
+	JoinInner JoinMode = "inner"
+	// JoinLeft keeps the items and time stamps of the first input.
+	JoinLeft JoinMode = "left"
+	// JoinOuter keeps the items and time stamps of all inputs.
+	JoinOuter JoinMode = "outer"
+)
+
+// JoinModes are the supported join modes.
+var JoinModes = []string{string(JoinInner), string(JoinLeft), string(JoinOuter)}
+
+// JoinOptions configure how the items of the inputs of a join are matched and aligned.
+type JoinOptions struct {
+	Mode JoinMode
+	// On are the label keys items are matched on. Without keys, items are matched when the labels
+	// they have in common are equal.
+	On []string
+	// Tolerance matches points whose time stamps differ by up to the tolerance. Points must have the
+	// same time stamp without tolerance.
+	Tolerance time.Duration
+	// Fill is the value of points and items missing from an input. They are null if Fill is nil.
+	Fill *float64
+}
+
+// JoinGroup is a combination of items of the inputs of a join, matched on their labels and aligned
+// to the same time stamps. Each input has exactly one value in Vars, labeled with Labels.
+type JoinGroup struct {
+	Labels data.Labels
+	Vars   Vars
+}
+
+// Join matches the items of the inputs by their labels and aligns the time series of each match to
+// the same time stamps, so that math between the inputs can be applied to each JoinGroup. Items of
+// an input may match several items of the other inputs, which results in a group for each match.
+func Join(refIDs []string, inputs []Results, opts JoinOptions) ([]JoinGroup, error) {
+	if len(refIDs) != len(inputs) || len(inputs) < 2 {
+		return nil, fmt.Errorf("join requires at least two inputs")
+	}
+
+	items := make([][]Value, len(inputs))
+	for i, input := range inputs {
+		for _, val := range input.Values {
+			switch val.(type) {
+			case Series, Number:
+				items[i] = append(items[i], val)
+			case NoData:
+			default:
+				return nil, fmt.Errorf("can only join series and numbers, got type %v for input %s", val.Type(), refIDs[i])
+			}
+		}
+	}
+
+	// every match has one item per input, which is nil if the input has no matching item
+	var matches [][]Value
+	for _, item := range items[0] {
+		matches = append(matches, []Value{item})
+	}
+	for i := 1; i < len(items); i++ {
+		matched := make([]bool, len(items[i]))
+		next := make([][]Value, 0, len(matches))
+		for _, match := range matches {
+			found := false
+			for j, item := range items[i] {
+				if !labelsMatch(mergeLabels(match), item.GetLabels(), opts.On) {
+					continue
+				}
+				found = true
+				matched[j] = true
+				next = append(next, append(append([]Value{}, match...), item))
+			}
+			if !found && opts.Mode != JoinInner {
+				next = append(next, append(append([]Value{}, match...), nil))
+			}
+		}
+		if opts.Mode == JoinOuter {
+			for j, item := range items[i] {
+				if !matched[j] {
+					match := make([]Value, i+1)
+					match[i] = item
+					next = append(next, match)
+				}
+			}
+		}
+		matches = next
+	}
+
+	groups := make([]JoinGroup, 0, len(matches))
+	for _, match := range matches {
+		group, ok := align(refIDs, match, opts)
+		if ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+func labelsMatch(a, b data.Labels, on []string) bool {
+	if len(on) > 0 {
+		for _, key := range on {
+			if a[key] != b[key] {
+				return false
+			}
+		}
+		return true
+	}
+	for key, value := range a {
+		if other, ok := b[key]; ok && other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLabels returns the labels of all items, where the labels of earlier items take precedence.
+func mergeLabels(items []Value) data.Labels {
+	labels := data.Labels{}
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		for key, value := range item.GetLabels() {
+			if _, ok := labels[key]; !ok {
+				labels[key] = value
+			}
+		}
+	}
+	return labels
+}
+
+// align returns the items of a match as a group. Series are aligned to the time stamps the join
+// mode keeps, and missing items are filled. It returns false if an inner join leaves no time stamps.
+func align(refIDs []string, match []Value, opts JoinOptions) (JoinGroup, bool) {
+	labels := mergeLabels(match)
+	group := JoinGroup{Labels: labels, Vars: Vars{}}
+
+	var series []Series
+	for _, item := range match {
+		if s, ok := item.(Series); ok {
+			series = append(series, s)
+		}
+	}
+	if len(series) == 0 {
+		for i, item := range match {
+			n := NewNumber(refIDs[i], labels.Copy())
+			if item != nil {
+				n.SetValue(item.(Number).GetFloat64Value())
+			} else {
+				n.SetValue(opts.Fill)
+			}
+			group.Vars[refIDs[i]] = Results{Values: Values{n}}
+		}
+		return group, true
+	}
+
+	lookups := make([]func(t time.Time) (*float64, bool), len(match))
+	for i, item := range match {
+		if s, ok := item.(Series); ok {
+			lookups[i] = pointLookup(s, opts.Tolerance)
+		}
+	}
+	times := joinTimes(match, series, lookups, opts.Mode)
+	if len(times) == 0 && opts.Mode == JoinInner {
+		return group, false
+	}
+
+	for i, item := range match {
+		var value Value
+		switch v := item.(type) {
+		case Number:
+			n := NewNumber(refIDs[i], labels.Copy())
+			n.SetValue(v.GetFloat64Value())
+			value = n
+		default:
+			s := NewSeries(refIDs[i], labels.Copy(), len(times))
+			for idx, t := range times {
+				point := opts.Fill
+				if lookups[i] != nil {
+					if f, ok := lookups[i](t); ok {
+						point = f
+					}
+				}
+				s.SetPoint(idx, t, point)
+			}
+			value = s
+		}
+		group.Vars[refIDs[i]] = Results{Values: Values{value}}
+	}
+	return group, true
+}
+
+// joinTimes returns the time stamps of the series of a match the join mode keeps. A left join keeps
+// the time stamps of the first input, or of all inputs if the first input has no series.
+func joinTimes(match []Value, series []Series, lookups []func(t time.Time) (*float64, bool), mode JoinMode) []time.Time {
+	if first, ok := match[0].(Series); ok && mode != JoinOuter {
+		times := make([]time.Time, 0, first.Len())
+		for idx := 0; idx < first.Len(); idx++ {
+			t := first.GetTime(idx)
+			if mode == JoinInner && !allHavePoint(lookups, t) {
+				continue
+			}
+			times = append(times, t)
+		}
+		return times
+	}
+
+	seen := map[int64]bool{}
+	var times []time.Time
+	for _, s := range series {
+		for idx := 0; idx < s.Len(); idx++ {
+			t := s.GetTime(idx)
+			if seen[t.UnixNano()] || (mode == JoinInner && !allHavePoint(lookups, t)) {
+				continue
+			}
+			seen[t.UnixNano()] = true
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+func allHavePoint(lookups []func(t time.Time) (*float64, bool), t time.Time) bool {
+	for _, lookup := range lookups {
+		if lookup == nil {
+			continue
+		}
+		if _, ok := lookup(t); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pointLookup returns a function which finds the value of the point of a series closest to a time
+// stamp within the tolerance.
+func pointLookup(s Series, tolerance time.Duration) func(t time.Time) (*float64, bool) {
+	if tolerance <= 0 {
+		index := make(map[int64]int, s.Len())
+		for idx := 0; idx < s.Len(); idx++ {
+			index[s.GetTime(idx).UnixNano()] = idx
+		}
+		return func(t time.Time) (*float64, bool) {
+			idx, ok := index[t.UnixNano()]
+			if !ok {
+				return nil, false
+			}
+			return s.GetValue(idx), true
+		}
+	}
+
+	times := make([]time.Time, s.Len())
+	order := make([]int, s.Len())
+	for idx := range times {
+		order[idx] = idx
+	}
+	sort.Slice(order, func(i, j int) bool { return s.GetTime(order[i]).Before(s.GetTime(order[j])) })
+	for i, idx := range order {
+		times[i] = s.GetTime(idx)
+	}
+	return func(t time.Time) (*float64, bool) {
+		i := sort.Search(len(times), func(i int) bool { return !times[i].Before(t) })
+		best, bestDiff := -1, tolerance+1
+		for _, candidate := range []int{i - 1, i} {
+			if candidate < 0 || candidate >= len(times) {
+				continue
+			}
+			diff := times[candidate].Sub(t)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff <= tolerance && diff < bestDiff {
+				best, bestDiff = candidate, diff
+			}
+		}
+		if best == -1 {
+			return nil, false
+		}
+		return s.GetValue(order[best]), true
+	}
+}
@@ -0,0 +1,110 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labeledSeries returns a series with the points at the given offsets in minutes.
+func labeledSeries(labels data.Labels, points map[int]float64) Series {
+	s := NewSeries("", labels, 0)
+	for minute := 0; minute < 60; minute++ {
+		if v, ok := points[minute]; ok {
+			s.AppendPoint(time.Unix(int64(minute*60), 0), float64Pointer(v))
+		}
+	}
+	return s
+}
+
+func TestJoin(t *testing.T) {
+	requests := Results{Values: Values{
+		labeledSeries(data.Labels{"host": "a", "job": "api"}, map[int]float64{0: 10, 1: 20, 2: 30}),
+		labeledSeries(data.Labels{"host": "b", "job": "api"}, map[int]float64{0: 5, 1: 5}),
+	}}
+	errors := Results{Values: Values{
+		labeledSeries(data.Labels{"host": "a"}, map[int]float64{1: 2, 2: 3, 3: 4}),
+		labeledSeries(data.Labels{"host": "c"}, map[int]float64{0: 1}),
+	}}
+
+	t.Run("inner join keeps matches and common time stamps", func(t *testing.T) {
+		groups, err := Join([]string{"A", "B"}, []Results{requests, errors}, JoinOptions{Mode: JoinInner})
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, data.Labels{"host": "a", "job": "api"}, groups[0].Labels)
+
+		a := groups[0].Vars["A"].Values[0].(Series)
+		b := groups[0].Vars["B"].Values[0].(Series)
+		assert.Equal(t, []*float64{float64Pointer(20), float64Pointer(30)}, seriesValues(a))
+		assert.Equal(t, []*float64{float64Pointer(2), float64Pointer(3)}, seriesValues(b))
+		assert.Equal(t, a.GetTime(0), b.GetTime(0))
+		assert.Equal(t, groups[0].Labels, b.GetLabels())
+	})
+
+	t.Run("left join fills missing points and items", func(t *testing.T) {
+		groups, err := Join([]string{"A", "B"}, []Results{requests, errors}, JoinOptions{Mode: JoinLeft, Fill: float64Pointer(0)})
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+
+		assert.Equal(t, []*float64{float64Pointer(10), float64Pointer(20), float64Pointer(30)}, seriesValues(groups[0].Vars["A"].Values[0].(Series)))
+		assert.Equal(t, []*float64{float64Pointer(0), float64Pointer(2), float64Pointer(3)}, seriesValues(groups[0].Vars["B"].Values[0].(Series)))
+		assert.Equal(t, data.Labels{"host": "b", "job": "api"}, groups[1].Labels)
+		assert.Equal(t, []*float64{float64Pointer(0), float64Pointer(0)}, seriesValues(groups[1].Vars["B"].Values[0].(Series)))
+	})
+
+	t.Run("outer join keeps all items and time stamps", func(t *testing.T) {
+		groups, err := Join([]string{"A", "B"}, []Results{requests, errors}, JoinOptions{Mode: JoinOuter})
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+
+		assert.Equal(t, []*float64{float64Pointer(10), float64Pointer(20), float64Pointer(30), nil}, seriesValues(groups[0].Vars["A"].Values[0].(Series)))
+		assert.Equal(t, []*float64{nil, float64Pointer(2), float64Pointer(3), float64Pointer(4)}, seriesValues(groups[0].Vars["B"].Values[0].(Series)))
+		assert.Equal(t, data.Labels{"host": "c"}, groups[2].Labels)
+		assert.Equal(t, []*float64{nil}, seriesValues(groups[2].Vars["A"].Values[0].(Series)))
+	})
+
+	t.Run("join on label keys", func(t *testing.T) {
+		totals := Results{Values: Values{labeledSeries(data.Labels{"job": "api", "cluster": "eu"}, map[int]float64{0: 100, 1: 100})}}
+		groups, err := Join([]string{"A", "B"}, []Results{requests, totals}, JoinOptions{Mode: JoinInner, On: []string{"job"}})
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, data.Labels{"host": "a", "job": "api", "cluster": "eu"}, groups[0].Labels)
+		assert.Equal(t, data.Labels{"host": "b", "job": "api", "cluster": "eu"}, groups[1].Labels)
+	})
+
+	t.Run("tolerance matches the closest point", func(t *testing.T) {
+		shifted := NewSeries("", data.Labels{"host": "a"}, 2)
+		shifted.SetPoint(0, time.Unix(50, 0), float64Pointer(1))
+		shifted.SetPoint(1, time.Unix(125, 0), float64Pointer(2))
+		inputs := []Results{{Values: Values{requests.Values[0]}}, {Values: Values{shifted}}}
+
+		groups, err := Join([]string{"A", "B"}, inputs, JoinOptions{Mode: JoinInner, Tolerance: 10 * time.Second})
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, []*float64{float64Pointer(20), float64Pointer(30)}, seriesValues(groups[0].Vars["A"].Values[0].(Series)))
+		assert.Equal(t, []*float64{float64Pointer(1), float64Pointer(2)}, seriesValues(groups[0].Vars["B"].Values[0].(Series)))
+
+		groups, err = Join([]string{"A", "B"}, inputs, JoinOptions{Mode: JoinInner})
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+
+	t.Run("numbers", func(t *testing.T) {
+		number := NewNumber("", data.Labels{"host": "a"})
+		number.SetValue(float64Pointer(2))
+		groups, err := Join([]string{"A", "B"}, []Results{requests, {Values: Values{number}}}, JoinOptions{Mode: JoinLeft})
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, float64Pointer(2), groups[0].Vars["B"].Values[0].(Number).GetFloat64Value())
+		assert.Equal(t, []*float64{nil, nil}, seriesValues(groups[1].Vars["B"].Values[0].(Series)))
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		groups, err := Join([]string{"A", "B"}, []Results{requests, {Values: Values{NoData{}.New()}}}, JoinOptions{Mode: JoinInner})
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+}
@@ -0,0 +1,147 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// WindowFunctions are the functions Window can apply to the values of each window.
+var WindowFunctions = []string{"mean", "median", "min", "max", "sum", "stddev", "percentile"}
+
+// Window returns a series with the same time stamps, where the value of each point is the function
+// applied to the values of the trailing window ending at the point. Null and NaN values are ignored,
+// and windows without values are null. The series must be sorted by time in ascending order.
+func (s Series) Window(refID string, window time.Duration, function string, percentile float64) (Series, error) {
+	if window <= 0 {
+		return s, fmt.Errorf("the window must be positive, got %v", window)
+	}
+	apply, err := getWindowFunc(function, percentile)
+	if err != nil {
+		return s, err
+	}
+
+	result := NewSeries(refID, s.GetLabels(), s.Len())
+	values := make([]float64, 0)
+	start := 0
+	for i := 0; i < s.Len(); i++ {
+		t := s.GetTime(i)
+		for start < i && !s.GetTime(start).After(t.Add(-window)) {
+			start++
+		}
+
+		values = values[:0]
+		for j := start; j <= i; j++ {
+			if v := s.GetValue(j); v != nil && !math.IsNaN(*v) {
+				values = append(values, *v)
+			}
+		}
+		var value *float64
+		if len(values) > 0 {
+			f := apply(values)
+			value = &f
+		}
+		result.SetPoint(i, t, value)
+	}
+	return result, nil
+}
+
+func getWindowFunc(function string, percentile float64) (func(values []float64) float64, error) {
+	switch function {
+	case "mean":
+		return mean, nil
+	case "sum":
+		return func(values []float64) float64 {
+			sum := 0.0
+			for _, v := range values {
+				sum += v
+			}
+			return sum
+		}, nil
+	case "min":
+		return func(values []float64) float64 {
+			min := values[0]
+			for _, v := range values[1:] {
+				min = math.Min(min, v)
+			}
+			return min
+		}, nil
+	case "max":
+		return func(values []float64) float64 {
+			max := values[0]
+			for _, v := range values[1:] {
+				max = math.Max(max, v)
+			}
+			return max
+		}, nil
+	case "stddev":
+		return func(values []float64) float64 {
+			m := mean(values)
+			variance := 0.0
+			for _, v := range values {
+				variance += (v - m) * (v - m)
+			}
+			return math.Sqrt(variance / float64(len(values)))
+		}, nil
+	case "median":
+		return func(values []float64) float64 {
+			return quantile(values, 0.5)
+		}, nil
+	case "percentile":
+		if percentile < 0 || percentile > 100 {
+			return nil, fmt.Errorf("the percentile must be between 0 and 100, got %v", percentile)
+		}
+		return func(values []float64) float64 {
+			return quantile(values, percentile/100)
+		}, nil
+	}
+	return nil, fmt.Errorf("window function %q is not supported, supported are %v", function, WindowFunctions)
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// quantile interpolates linearly between the closest ranks. It sorts values.
+func quantile(values []float64, q float64) float64 {
+	sort.Float64s(values)
+	rank := q * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+// step returns the interval of a series, which is the median of the intervals between its points.
+func (s Series) step() (time.Duration, error) {
+	if s.Len() < 2 {
+		return 0, fmt.Errorf("the series must have at least two points, has %v", s.Len())
+	}
+	intervals := make([]float64, 0, s.Len()-1)
+	for i := 1; i < s.Len(); i++ {
+		intervals = append(intervals, float64(s.GetTime(i).Sub(s.GetTime(i-1))))
+	}
+	step := time.Duration(quantile(intervals, 0.5))
+	if step <= 0 {
+		return 0, fmt.Errorf("the series must be sorted by time in ascending order")
+	}
+	return step, nil
+}
+
+// seasonLength returns the number of points of a season, which requires the series to have regular
+// intervals.
+func (s Series) seasonLength(season time.Duration) (int, time.Duration, error) {
+	step, err := s.step()
+	if err != nil {
+		return 0, 0, err
+	}
+	period := int(math.Round(float64(season) / float64(step)))
+	if period < 2 {
+		return 0, 0, fmt.Errorf("the season %v must be at least two intervals of the series, which are %v", season, step)
+	}
+	return period, step, nil
+}
@@ -0,0 +1,91 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesOf returns a series with a point every minute.
+func seriesOf(values ...*float64) Series {
+	s := NewSeries("A", nil, len(values))
+	for i, v := range values {
+		s.SetPoint(i, time.Unix(int64(i*60), 0), v)
+	}
+	return s
+}
+
+func seriesValues(s Series) []*float64 {
+	values := make([]*float64, s.Len())
+	for i := range values {
+		values[i] = s.GetValue(i)
+	}
+	return values
+}
+
+func TestSeriesWindow(t *testing.T) {
+	input := seriesOf(float64Pointer(1), float64Pointer(5), nil, float64Pointer(3), float64Pointer(math.NaN()), float64Pointer(10))
+
+	tests := []struct {
+		name       string
+		window     time.Duration
+		function   string
+		percentile float64
+		expected   []*float64
+	}{
+		{
+			name:     "mean over three points",
+			window:   3 * time.Minute,
+			function: "mean",
+			expected: []*float64{float64Pointer(1), float64Pointer(3), float64Pointer(3), float64Pointer(4), float64Pointer(3), float64Pointer(6.5)},
+		},
+		{
+			name:     "window without values is null",
+			window:   time.Minute,
+			function: "sum",
+			expected: []*float64{float64Pointer(1), float64Pointer(5), nil, float64Pointer(3), nil, float64Pointer(10)},
+		},
+		{
+			name:     "max",
+			window:   2 * time.Minute,
+			function: "max",
+			expected: []*float64{float64Pointer(1), float64Pointer(5), float64Pointer(5), float64Pointer(3), float64Pointer(3), float64Pointer(10)},
+		},
+		{
+			name:     "median",
+			window:   4 * time.Minute,
+			function: "median",
+			expected: []*float64{float64Pointer(1), float64Pointer(3), float64Pointer(3), float64Pointer(3), float64Pointer(4), float64Pointer(6.5)},
+		},
+		{
+			name:       "percentile interpolates between ranks",
+			window:     10 * time.Minute,
+			function:   "percentile",
+			percentile: 75,
+			expected:   []*float64{float64Pointer(1), float64Pointer(4), float64Pointer(4), float64Pointer(4), float64Pointer(4), float64Pointer(6.25)},
+		},
+		{
+			name:     "stddev",
+			window:   2 * time.Minute,
+			function: "stddev",
+			expected: []*float64{float64Pointer(0), float64Pointer(2), float64Pointer(0), float64Pointer(0), float64Pointer(0), float64Pointer(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := input.Window("B", tt.window, tt.function, tt.percentile)
+			require.NoError(t, err)
+			require.Equal(t, input.Len(), result.Len())
+			assert.Equal(t, tt.expected, seriesValues(result))
+			assert.Equal(t, input.GetTime(5), result.GetTime(5))
+		})
+	}
+
+	t.Run("unsupported function", func(t *testing.T) {
+		_, err := input.Window("B", time.Minute, "mode", 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,161 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// WindowCommand is an expression command which applies a function such as a mean or a percentile
+// to a moving window over each point of a timeseries.
+type WindowCommand struct {
+	VarToWindow string
+	Window      time.Duration
+	Function    string
+	// Percentile is between 0 and 100, and only used by the percentile function.
+	Percentile float64
+	refID      string
+}
+
+// NewWindowCommand creates a new WindowCommand.
+func NewWindowCommand(refID, varToWindow, rawWindow, function string, percentile float64) (*WindowCommand, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse window "window" duration field %q: %w`, rawWindow, err)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("window duration must be positive, got %q", rawWindow)
+	}
+	if !isSupported(mathexp.WindowFunctions, function) {
+		return nil, fmt.Errorf("window function %q is not supported, supported are %s", function, strings.Join(mathexp.WindowFunctions, ", "))
+	}
+	if function == "percentile" && (percentile < 0 || percentile > 100) {
+		return nil, fmt.Errorf("window percentile must be between 0 and 100, got %v", percentile)
+	}
+
+	return &WindowCommand{
+		VarToWindow: varToWindow,
+		Window:      window,
+		Function:    function,
+		Percentile:  percentile,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalWindowCommand creates a WindowCommand from Grafana's frontend query.
+func UnmarshalWindowCommand(rn *rawNode) (*WindowCommand, error) {
+	varToWindow, err := getVarParam(rn)
+	if err != nil {
+		return nil, err
+	}
+	window, err := getStringParam(rn, "window", "")
+	if err != nil {
+		return nil, err
+	}
+	function, err := getStringParam(rn, "function", "")
+	if err != nil {
+		return nil, err
+	}
+	percentile := 0.0
+	if function == "percentile" {
+		if percentile, err = getNumberParam(rn, "percentile", -1); err != nil {
+			return nil, err
+		}
+	}
+	return NewWindowCommand(rn.RefID, varToWindow, window, function, percentile)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (wc *WindowCommand) NeedsVars() []string {
+	return []string{wc.VarToWindow}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (wc *WindowCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	return mapSeries(vars[wc.VarToWindow], "window", func(s mathexp.Series) (mathexp.Series, error) {
+		return s.Window(wc.refID, wc.Window, wc.Function, wc.Percentile)
+	})
+}
+
+// mapSeries applies fn to each series of the results of a variable. NoData is passed through.
+func mapSeries(input mathexp.Results, command string, fn func(s mathexp.Series) (mathexp.Series, error)) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range input.Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			series, err := fn(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, series)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only %s type series, got type %v", command, val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// getVarParam returns the variable referenced by the expression field of a command.
+func getVarParam(rn *rawNode) (string, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return "", errors.New("no expression ID is specified. Must be a reference to an existing query or expression")
+	}
+	varName, ok := rawVar.(string)
+	if !ok {
+		return "", fmt.Errorf("expression ID is expected to be a string, got %T", rawVar)
+	}
+	return strings.TrimPrefix(varName, "$"), nil
+}
+
+// getStringParam returns a field of a command, which is required unless it has a default value.
+func getStringParam(rn *rawNode, key, defaultValue string) (string, error) {
+	raw, ok := rn.Query[key]
+	if !ok {
+		if defaultValue == "" {
+			return "", fmt.Errorf("no %s specified for refId %v", key, rn.RefID)
+		}
+		return defaultValue, nil
+	}
+	value, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("expected %s to be a string, got %T for refId %v", key, raw, rn.RefID)
+	}
+	return value, nil
+}
+
+// getNumberParam returns a numeric field of a command, which is required if the default value is
+// negative.
+func getNumberParam(rn *rawNode, key string, defaultValue float64) (float64, error) {
+	raw, ok := rn.Query[key]
+	if !ok {
+		if defaultValue < 0 {
+			return 0, fmt.Errorf("no %s specified for refId %v", key, rn.RefID)
+		}
+		return defaultValue, nil
+	}
+	value, ok := raw.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expected %s to be a number, got %T for refId %v", key, raw, rn.RefID)
+	}
+	return value, nil
+}
+
+func isSupported(supported []string, name string) bool {
+	for _, s := range supported {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalWindowCommands(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expectedError string
+	}{
+		{
+			description: "window",
+			query:       `{"type": "window", "expression": "$A", "window": "5m", "function": "mean"}`,
+		},
+		{
+			description: "window percentile",
+			query:       `{"type": "window", "expression": "A", "window": "1h", "function": "percentile", "percentile": 95}`,
+		},
+		{
+			description:   "window percentile out of range",
+			query:         `{"type": "window", "expression": "A", "window": "1h", "function": "percentile", "percentile": 150}`,
+			expectedError: "between 0 and 100",
+		},
+		{
+			description:   "window percentile missing",
+			query:         `{"type": "window", "expression": "A", "window": "1h", "function": "percentile"}`,
+			expectedError: "no percentile specified",
+		},
+		{
+			description:   "window unsupported function",
+			query:         `{"type": "window", "expression": "A", "window": "5m", "function": "mode"}`,
+			expectedError: "not supported",
+		},
+		{
+			description:   "window missing duration",
+			query:         `{"type": "window", "expression": "A", "function": "mean"}`,
+			expectedError: "no window specified",
+		},
+		{
+			description: "decompose",
+			query:       `{"type": "decompose", "expression": "A", "season": "1d", "component": "residual"}`,
+		},
+		{
+			description:   "decompose unsupported component",
+			query:         `{"type": "decompose", "expression": "A", "season": "1d", "component": "noise"}`,
+			expectedError: "not supported",
+		},
+		{
+			description: "forecast without season",
+			query:       `{"type": "forecast", "expression": "A", "horizon": "1h"}`,
+		},
+		{
+			description: "forecast",
+			query:       `{"type": "forecast", "expression": "A", "horizon": "1h", "season": "1d", "alpha": 0.3, "beta": 0.05, "gamma": 0.2}`,
+		},
+		{
+			description:   "forecast invalid smoothing factor",
+			query:         `{"type": "forecast", "expression": "A", "alpha": 2}`,
+			expectedError: "alpha must be between 0 and 1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var query map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &query))
+			rn := &rawNode{RefID: "B", Query: query}
+			commandType, err := rn.GetCommandType()
+			require.NoError(t, err)
+
+			var cmd Command
+			switch commandType {
+			case TypeWindow:
+				cmd, err = UnmarshalWindowCommand(rn)
+			case TypeDecompose:
+				cmd, err = UnmarshalDecomposeCommand(rn)
+			case TypeForecast:
+				cmd, err = UnmarshalForecastCommand(rn)
+			default:
+				t.Fatalf("unexpected command type %v", commandType)
+			}
+
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestWindowCommandsExecute(t *testing.T) {
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 10)
+	for i := 0; i < 10; i++ {
+		v := float64(i)
+		series.SetPoint(i, time.Unix(int64(i*60), 0), &v)
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series, mathexp.NoData{}.New()}}}
+
+	window, err := NewWindowCommand("B", "A", "3m", "mean", 0)
+	require.NoError(t, err)
+	forecast, err := NewForecastCommand("C", "A", "5m", "", 0.5, 0.1, 0.1)
+	require.NoError(t, err)
+	decompose, err := NewDecomposeCommand("D", "A", "2m", "trend")
+	require.NoError(t, err)
+
+	for _, cmd := range []Command{window, forecast, decompose} {
+		results, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, results.Values, 2)
+		require.Equal(t, data.Labels{"host": "a"}, results.Values[0].GetLabels())
+		require.IsType(t, mathexp.NoData{}, results.Values[1])
+	}
+
+	t.Run("numbers cannot be windowed", func(t *testing.T) {
+		number := mathexp.NewNumber("A", nil)
+		_, err := window.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{number}}})
+		require.ErrorContains(t, err, "can only window type series")
+	})
+}
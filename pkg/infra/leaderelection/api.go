@@ -0,0 +1,42 @@
+package leaderelection
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func (s *LeaderElectionService) registerAPIEndpoints() {
+	s.routeRegister.Get("/api/admin/leader-election", middleware.ReqGrafanaAdmin, routing.Wrap(s.getStatusHandler))
+}
+
+// swagger:route GET /admin/leader-election admin getLeaderElectionStatus
+//
+// Get the status of the leader election.
+//
+// Lists the leases of the singleton background services and which node of the cluster holds them.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getLeaderElectionStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *LeaderElectionService) getStatusHandler(c *models.ReqContext) response.Response {
+	status, err := s.GetStatus(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get leader election status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:response getLeaderElectionStatusResponse
+type GetLeaderElectionStatusResponse struct {
+	// in:body
+	Body *Status `json:"body"`
+}
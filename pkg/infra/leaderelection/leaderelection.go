@@ -0,0 +1,208 @@
+// Package leaderelection elects one node of a Grafana cluster to run each singleton background
+// service, such as the cleanup jobs or the usage stats reporter.
+//
+// A node is leader for a name as long as it holds the database lease with that name. Leases are
+// renewed in the background and taken over by another node once their holder stops renewing them,
+// for example because it was shut down or lost its connection to the database.
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const releaseTimeout = 5 * time.Second
+
+type settings struct {
+	enabled       bool
+	nodeID        string
+	leaseDuration time.Duration
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("leader_election")
+	s := settings{
+		enabled:       section.Key("enabled").MustBool(false),
+		nodeID:        section.Key("node_id").MustString(""),
+		leaseDuration: section.Key("lease_duration").MustDuration(30 * time.Second),
+	}
+	if s.nodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "grafana"
+		}
+		s.nodeID = fmt.Sprintf("%s:%s", hostname, cfg.HTTPPort)
+	}
+	if s.leaseDuration < 3*time.Second {
+		s.leaseDuration = 3 * time.Second
+	}
+	return s
+}
+
+type Service interface {
+	// IsLeader returns true if this node holds the lease with the given name, trying to acquire it
+	// if it does not. From then on the lease is renewed in the background, so that the node stays
+	// leader until it is shut down. If leader election is disabled every node is leader.
+	IsLeader(ctx context.Context, name string) bool
+}
+
+type LeaderElectionService struct {
+	settings      settings
+	nodeID        string
+	store         db.DB
+	routeRegister routing.RouteRegister
+	log           log.Logger
+
+	mu sync.Mutex
+	// leases holds the names this node campaigns for, mapped to the time until which this node
+	// considers itself leader. The zero time means another node holds the lease.
+	leases map[string]time.Time
+	now    func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister) *LeaderElectionService {
+	s := &LeaderElectionService{
+		settings:      readSettings(cfg),
+		store:         sqlStore,
+		routeRegister: routeRegister,
+		log:           log.New("leader-election"),
+		leases:        map[string]time.Time{},
+		now:           time.Now,
+	}
+	s.nodeID = s.settings.nodeID
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *LeaderElectionService) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+// renewInterval is how often held leases are renewed. A node stops considering itself leader one
+// interval before its lease expires in the database, so that two nodes never both consider
+// themselves leader as long as their clocks agree.
+func (s *LeaderElectionService) renewInterval() time.Duration {
+	return s.settings.leaseDuration / 3
+}
+
+func (s *LeaderElectionService) IsLeader(ctx context.Context, name string) bool {
+	if !s.settings.enabled {
+		return true
+	}
+
+	s.mu.Lock()
+	until, ok := s.leases[name]
+	s.mu.Unlock()
+	if ok && s.now().Before(until) {
+		return true
+	}
+
+	return s.campaign(ctx, name)
+}
+
+// campaign tries to acquire or renew the lease with the given name and records the outcome.
+func (s *LeaderElectionService) campaign(ctx context.Context, name string) bool {
+	start := s.now()
+	acquired, err := s.acquire(ctx, name)
+	if err != nil {
+		s.log.Error("Failed to acquire lease", "name", name, "error", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wasLeader := s.now().Before(s.leases[name])
+	if acquired {
+		s.leases[name] = start.Add(s.settings.leaseDuration - s.renewInterval())
+	} else {
+		s.leases[name] = time.Time{}
+	}
+
+	if acquired && !wasLeader {
+		s.log.Info("Became leader", "name", name, "node", s.nodeID)
+	} else if !acquired && wasLeader {
+		s.log.Warn("Lost leadership", "name", name, "node", s.nodeID)
+	}
+	return acquired
+}
+
+func (s *LeaderElectionService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.renewInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.releaseAll()
+			return ctx.Err()
+		case <-ticker.C:
+			for _, name := range s.names() {
+				s.campaign(ctx, name)
+			}
+		}
+	}
+}
+
+func (s *LeaderElectionService) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.leases))
+	for name := range s.leases {
+		names = append(names, name)
+	}
+	return names
+}
+
+// releaseAll gives up the leases held by this node on shutdown, so that other nodes take over
+// without waiting for the leases to expire.
+func (s *LeaderElectionService) releaseAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, until := range s.leases {
+		if until.IsZero() {
+			continue
+		}
+		if err := s.release(ctx, name); err != nil {
+			s.log.Warn("Failed to release lease", "name", name, "error", err)
+		}
+		s.leases[name] = time.Time{}
+	}
+}
+
+// GetStatus lists the leases of all nodes and which node holds them.
+func (s *LeaderElectionService) GetStatus(ctx context.Context) (*Status, error) {
+	status := &Status{Enabled: s.settings.enabled, NodeID: s.nodeID, Leases: make([]Lease, 0)}
+	if !s.settings.enabled {
+		return status, nil
+	}
+
+	leases, err := s.listLeases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().Unix()
+	for _, l := range leases {
+		status.Leases = append(status.Leases, Lease{
+			Name:     l.Name,
+			Holder:   l.Holder,
+			Self:     l.Holder == s.nodeID && l.Expires > now,
+			Expired:  l.Expires <= now,
+			Acquired: time.Unix(l.Acquired, 0).UTC(),
+			Renewed:  time.Unix(l.Renewed, 0).UTC(),
+			Expires:  time.Unix(l.Expires, 0).UTC(),
+		})
+	}
+	return status, nil
+}
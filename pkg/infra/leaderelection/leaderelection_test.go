@@ -0,0 +1,88 @@
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupNode(t *testing.T, sqlStore db.DB, nodeID string, now *time.Time) *LeaderElectionService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	_, err := cfg.Raw.Section("leader_election").NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = cfg.Raw.Section("leader_election").NewKey("node_id", nodeID)
+	require.NoError(t, err)
+	_, err = cfg.Raw.Section("leader_election").NewKey("lease_duration", "30s")
+	require.NoError(t, err)
+	s := ProvideService(cfg, sqlStore, routing.NewRouteRegister())
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestIsLeaderWhenDisabled(t *testing.T) {
+	s := ProvideService(setting.NewCfg(), nil, routing.NewRouteRegister())
+	assert.True(t, s.IsDisabled())
+	assert.True(t, s.IsLeader(context.Background(), LeaseCleanup))
+}
+
+func TestIntegrationLeaderElection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	a := setupNode(t, sqlStore, "a", &now)
+	b := setupNode(t, sqlStore, "b", &now)
+
+	t.Run("Should elect the first node campaigning", func(t *testing.T) {
+		assert.True(t, a.IsLeader(ctx, LeaseCleanup))
+		assert.False(t, b.IsLeader(ctx, LeaseCleanup))
+		assert.True(t, a.IsLeader(ctx, LeaseCleanup))
+	})
+
+	t.Run("Should elect a leader per lease", func(t *testing.T) {
+		assert.True(t, b.IsLeader(ctx, LeaseUsageStatsReport))
+		assert.False(t, a.IsLeader(ctx, LeaseUsageStatsReport))
+	})
+
+	t.Run("Should keep the lease while it is renewed", func(t *testing.T) {
+		now = now.Add(20 * time.Second)
+		assert.True(t, a.campaign(ctx, LeaseCleanup))
+		now = now.Add(20 * time.Second)
+		assert.False(t, b.IsLeader(ctx, LeaseCleanup))
+		assert.True(t, a.IsLeader(ctx, LeaseCleanup))
+	})
+
+	t.Run("Should fail over once the lease expired", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		assert.True(t, b.IsLeader(ctx, LeaseCleanup))
+		assert.False(t, a.IsLeader(ctx, LeaseCleanup))
+	})
+
+	t.Run("Should let other nodes take over released leases", func(t *testing.T) {
+		b.releaseAll()
+		assert.True(t, a.IsLeader(ctx, LeaseCleanup))
+		assert.True(t, a.IsLeader(ctx, LeaseUsageStatsReport))
+	})
+
+	t.Run("Should list the leases and their holders", func(t *testing.T) {
+		status, err := b.GetStatus(ctx)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, "b", status.NodeID)
+		require.Len(t, status.Leases, 2)
+		assert.Equal(t, LeaseCleanup, status.Leases[0].Name)
+		assert.Equal(t, "a", status.Leases[0].Holder)
+		assert.False(t, status.Leases[0].Self)
+		assert.False(t, status.Leases[0].Expired)
+	})
+}
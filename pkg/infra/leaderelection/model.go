@@ -0,0 +1,53 @@
+package leaderelection
+
+import "time"
+
+// Names of the leases of the singleton background services.
+const (
+	LeaseCleanup             = "cleanup"
+	LeaseUsageStatsReport    = "usage-stats-report"
+	LeaseProvisioningPrune   = "provisioning-prune"
+	LeaseDashboardBackup     = "dashboard-backup"
+	LeaseSyntheticMonitoring = "synthetic-monitoring"
+	LeaseTraceAnnotations    = "trace-annotations"
+	LeaseLogAnnotations      = "log-annotations"
+	LeaseLDAPSync            = "ldap-sync"
+)
+
+type lease struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	Name    string `xorm:"name"`
+	Holder  string `xorm:"holder"`
+	Version int64  `xorm:"version"`
+	// Acquired, Renewed and Expires are unix timestamps in seconds.
+	Acquired int64 `xorm:"acquired"`
+	Renewed  int64 `xorm:"renewed"`
+	Expires  int64 `xorm:"expires"`
+}
+
+func (l lease) TableName() string {
+	return "leader_lease"
+}
+
+// Lease describes which node holds a lease.
+// swagger:model LeaderLease
+type Lease struct {
+	Name   string `json:"name"`
+	Holder string `json:"holder"`
+	// Self is true if the lease is held by the node answering the request.
+	Self bool `json:"self"`
+	// Expired is true if the holder did not renew the lease in time, the next node trying to
+	// acquire it takes it over.
+	Expired  bool      `json:"expired"`
+	Acquired time.Time `json:"acquired"`
+	Renewed  time.Time `json:"renewed"`
+	Expires  time.Time `json:"expires"`
+}
+
+// Status lists the leases of all nodes of the cluster.
+// swagger:model LeaderElectionStatus
+type Status struct {
+	Enabled bool    `json:"enabled"`
+	NodeID  string  `json:"nodeId"`
+	Leases  []Lease `json:"leases"`
+}
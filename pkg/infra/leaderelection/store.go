@@ -0,0 +1,69 @@
+package leaderelection
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// acquire takes the lease with the given name for this node, or renews it if this node already
+// holds it. A lease held by another node is only taken over once it expired. The update is
+// conditional on the version of the lease, so that only one of the nodes racing for an expired
+// lease gets it.
+func (s *LeaderElectionService) acquire(ctx context.Context, name string) (bool, error) {
+	now := s.now().Unix()
+	expires := now + int64(s.settings.leaseDuration.Seconds())
+
+	var acquired bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		current := &lease{}
+		exists, err := sess.Where("name = ?", name).Get(current)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			// The name is unique, if another node inserted the lease first the insert fails and
+			// the lease stays with that node.
+			_, err := sess.Insert(&lease{Name: name, Holder: s.nodeID, Version: 1, Acquired: now, Renewed: now, Expires: expires})
+			acquired = err == nil
+			return nil
+		}
+
+		if current.Holder != s.nodeID && current.Expires > now {
+			return nil
+		}
+
+		since := current.Acquired
+		if current.Holder != s.nodeID {
+			since = now
+		}
+		res, err := sess.Exec("UPDATE leader_lease SET holder = ?, version = ?, acquired = ?, renewed = ?, expires = ? WHERE id = ? AND version = ?",
+			s.nodeID, current.Version+1, since, now, expires, current.ID, current.Version)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		acquired = affected == 1
+		return err
+	})
+	return acquired, err
+}
+
+// release lets the lease expire immediately if this node holds it, so that another node can take
+// it over without waiting for the lease duration.
+func (s *LeaderElectionService) release(ctx context.Context, name string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE leader_lease SET expires = ?, version = version + 1 WHERE name = ? AND holder = ?",
+			s.now().Unix(), name, s.nodeID)
+		return err
+	})
+}
+
+func (s *LeaderElectionService) listLeases(ctx context.Context) ([]*lease, error) {
+	leases := make([]*lease, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("name").Find(&leases)
+	})
+	return leases, err
+}
@@ -1,6 +1,10 @@
 package log
 
-import gokitlog "github.com/go-kit/log"
+import (
+	gokitlog "github.com/go-kit/log"
+
+	"github.com/grafana/grafana/pkg/infra/log/redact"
+)
 
 type compositeLogger struct {
 	loggers []gokitlog.Logger
@@ -15,6 +19,10 @@ func newCompositeLogger(loggers ...gokitlog.Logger) *compositeLogger {
 }
 
 func (l *compositeLogger) Log(keyvals ...interface{}) error {
+	if r := redact.Active(); r != nil {
+		keyvals = r.KeyVals(keyvals)
+	}
+
 	for _, logger := range l.loggers {
 		if err := logger.Log(keyvals...); err != nil {
 			return err
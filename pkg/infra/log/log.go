@@ -21,6 +21,7 @@ import (
 	"github.com/mattn/go-isatty"
 	"gopkg.in/ini.v1"
 
+	"github.com/grafana/grafana/pkg/infra/log/redact"
 	"github.com/grafana/grafana/pkg/infra/log/term"
 	"github.com/grafana/grafana/pkg/infra/log/text"
 	"github.com/grafana/grafana/pkg/util"
@@ -33,6 +34,7 @@ var (
 	now             = time.Now
 	logTimeFormat   = time.RFC3339Nano
 	ctxLogProviders = []ContextualLogProviderFunc{}
+	sinks           = []logWithFilters{}
 )
 
 const (
@@ -376,6 +378,7 @@ func Close() error {
 		}
 	}
 	loggersToClose = make([]DisposableHandler, 0)
+	loggersToReload = make([]ReloadableHandler, 0)
 
 	return err
 }
@@ -402,6 +405,14 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 		return err
 	}
 
+	if err := redact.Configure(redact.Config{
+		Enabled:  cfg.Section("log").Key("redact").MustBool(false),
+		Keys:     util.SplitString(cfg.Section("log").Key("redact_keys").MustString("email password token secret authorization cookie api_key")),
+		Patterns: util.SplitString(cfg.Section("log").Key("redact_patterns").MustString("email token secret")),
+	}); err != nil {
+		return fmt.Errorf("invalid log redaction settings: %w", err)
+	}
+
 	logEnabled := cfg.Section("log").Key("enabled").MustBool(true)
 	if !logEnabled {
 		return nil
@@ -474,8 +485,24 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 		configLoggers = append(configLoggers, handler)
 	}
 	if len(configLoggers) > 0 {
+		root.mutex.RLock()
+		configLoggers = append(configLoggers, sinks...)
+		root.mutex.RUnlock()
 		root.initialize(configLoggers)
 	}
 
 	return nil
 }
+
+// AddSink adds a logger receiving the log lines of all loggers at or above the level, in addition
+// to the loggers of the configured log modes. The log lines include the context of the loggers.
+func AddSink(logger gokitlog.Logger, levelName string) {
+	sink := logWithFilters{val: logger, filters: map[string]level.Option{}, maxLevel: getLogLevelFromString(levelName)}
+
+	root.mutex.Lock()
+	sinks = append(sinks, sink)
+	loggers := append(append([]logWithFilters{}, root.logFilters...), sink)
+	root.mutex.Unlock()
+
+	root.initialize(loggers)
+}
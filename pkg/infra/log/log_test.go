@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-kit/log/level"
+	"github.com/grafana/grafana/pkg/infra/log/redact"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -185,6 +186,56 @@ func TestLogger(t *testing.T) {
 	})
 }
 
+func TestAddSink(t *testing.T) {
+	newLoggerScenario(t, "AddSink should add a logger receiving the log lines at or above its level", func(t *testing.T, ctx *scenarioContext) {
+		origSinks := sinks
+		t.Cleanup(func() { sinks = origSinks })
+
+		logger := New("test").New("ctxKey", "ctxValue")
+		var sunk [][]interface{}
+		AddSink(gokitlog.LoggerFunc(func(i ...interface{}) error {
+			sunk = append(sunk, i)
+			return nil
+		}), "info")
+
+		logger.Debug("hello debug")
+		logger.Info("hello info", "key", "value")
+
+		require.Len(t, sunk, 1)
+		require.Len(t, sunk[0], 12)
+		require.Equal(t, "test", sunk[0][1].(string))
+		require.Equal(t, "ctxValue", sunk[0][3].(string))
+		require.Equal(t, level.InfoValue(), sunk[0][7].(level.Value))
+		require.Equal(t, "hello info", sunk[0][9].(string))
+		require.Equal(t, "value", sunk[0][11].(string))
+	})
+}
+
+func TestRedaction(t *testing.T) {
+	newLoggerScenario(t, "Log lines should be redacted when redaction is enabled", func(t *testing.T, ctx *scenarioContext) {
+		origSinks := sinks
+		t.Cleanup(func() {
+			sinks = origSinks
+			require.NoError(t, redact.Configure(redact.Config{}))
+		})
+		require.NoError(t, redact.Configure(redact.Config{Enabled: true, Keys: []string{"password"}, Patterns: []string{redact.PatternEmail}}))
+
+		var sunk [][]interface{}
+		AddSink(gokitlog.LoggerFunc(func(i ...interface{}) error {
+			sunk = append(sunk, i)
+			return nil
+		}), "info")
+		New("test").Info("Invite sent to bob@example.com", "password", "hunter2", "userId", 2)
+
+		require.Len(t, sunk, 1)
+		require.Len(t, sunk[0], 12)
+		require.Equal(t, level.InfoValue(), sunk[0][5].(level.Value))
+		require.Equal(t, "Invite sent to [REDACTED]", sunk[0][7].(string))
+		require.Equal(t, redact.Mask, sunk[0][9].(string))
+		require.Equal(t, 2, sunk[0][11].(int))
+	})
+}
+
 func TestWithPrefix(t *testing.T) {
 	newLoggerScenario(t, "WithPrefix should prepend context to beginning of log message", func(t *testing.T, ctx *scenarioContext) {
 		ls := WithPrefix(New("test"), "k1", "v1")
@@ -100,6 +100,12 @@ var (
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
 
+	// MRenderingQueueWaiting is a metric gauge for image rendering requests waiting for a free rendering slot
+	MRenderingQueueWaiting prometheus.Gauge
+
+	// MRenderingRenderersHealthy is a metric gauge for remote image renderers passing their health check
+	MRenderingRenderersHealthy prometheus.Gauge
+
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
 
@@ -394,6 +400,18 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MRenderingQueueWaiting = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "rendering_queue_waiting",
+		Help:      "number of rendering requests waiting for a free rendering slot",
+		Namespace: ExporterName,
+	})
+
+	MRenderingRenderersHealthy = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "rendering_renderers_healthy",
+		Help:      "number of remote image renderers passing their health check",
+		Namespace: ExporterName,
+	})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -654,6 +672,8 @@ func initMetricVars() {
 		MRenderingRequestTotal,
 		MRenderingSummary,
 		MRenderingQueue,
+		MRenderingQueueWaiting,
+		MRenderingRenderersHealthy,
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAlertingActiveAlerts,
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OrgLabelOther is the org_id label of the orgs exceeding the cardinality limit of the org labelled metrics.
+const OrgLabelOther = "other"
+
+var (
+	// MOrgHTTPRequestsTotal is a metric counter for HTTP requests per org
+	MOrgHTTPRequestsTotal *prometheus.CounterVec
+
+	// MOrgDataSourceQueryDuration is a metric histogram for data source query durations per org
+	MOrgDataSourceQueryDuration *prometheus.HistogramVec
+
+	// MOrgActiveUsers is a metric number of active users per org
+	MOrgActiveUsers *prometheus.GaugeVec
+
+	orgLabels = &orgLabelLimiter{}
+)
+
+func initOrgMetrics() {
+	MOrgHTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "org_http_requests_total",
+		Help:      "counter for HTTP requests per org",
+		Namespace: ExporterName,
+	}, []string{"org_id", "status_code"})
+
+	MOrgDataSourceQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "org_datasource_query_duration_seconds",
+		Help:      "histogram of data source query durations per org",
+		Namespace: ExporterName,
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 25},
+	}, []string{"org_id"})
+
+	MOrgActiveUsers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "org_active_users",
+		Help:      "number of active users per org",
+		Namespace: ExporterName,
+	}, []string{"org_id"})
+
+	prometheus.MustRegister(
+		MOrgHTTPRequestsTotal,
+		MOrgDataSourceQueryDuration,
+		MOrgActiveUsers,
+	)
+}
+
+// orgLabelLimiter hands out the org_id labels of the org labelled metrics. Only the first maxOrgs
+// orgs get a label of their own, all other orgs share the OrgLabelOther label, so the number of
+// series stays bounded on instances with many orgs.
+type orgLabelLimiter struct {
+	mu      sync.RWMutex
+	enabled bool
+	maxOrgs int
+	labels  map[int64]string
+}
+
+func (l *orgLabelLimiter) configure(enabled bool, maxOrgs int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enabled = enabled
+	l.maxOrgs = maxOrgs
+	l.labels = make(map[int64]string)
+}
+
+func (l *orgLabelLimiter) isEnabled() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.enabled
+}
+
+// label returns the org_id label of an org, or false if the org labelled metrics are disabled.
+func (l *orgLabelLimiter) label(orgID int64) (string, bool) {
+	l.mu.RLock()
+	enabled := l.enabled
+	label, ok := l.labels[orgID]
+	l.mu.RUnlock()
+	if !enabled || orgID <= 0 {
+		return "", false
+	}
+	if ok {
+		return label, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if label, ok := l.labels[orgID]; ok {
+		return label, true
+	}
+	if len(l.labels) >= l.maxOrgs {
+		return OrgLabelOther, true
+	}
+	label = strconv.FormatInt(orgID, 10)
+	l.labels[orgID] = label
+	return label, true
+}
+
+// ConfigureOrgLabels enables or disables the org labelled metrics. At most maxOrgs orgs are
+// exposed with their own org_id label.
+func ConfigureOrgLabels(enabled bool, maxOrgs int) {
+	orgLabels.configure(enabled, maxOrgs)
+	MOrgHTTPRequestsTotal.Reset()
+	MOrgDataSourceQueryDuration.Reset()
+	MOrgActiveUsers.Reset()
+}
+
+// OrgLabelsEnabled returns true if the org labelled metrics are enabled.
+func OrgLabelsEnabled() bool {
+	return orgLabels.isEnabled()
+}
+
+// ObserveOrgHTTPRequest counts an HTTP request served for an org.
+func ObserveOrgHTTPRequest(orgID int64, statusCode string) {
+	if label, ok := orgLabels.label(orgID); ok {
+		MOrgHTTPRequestsTotal.WithLabelValues(label, statusCode).Inc()
+	}
+}
+
+// ObserveOrgDataSourceQuery observes the duration of a data source query of an org.
+func ObserveOrgDataSourceQuery(orgID int64, duration time.Duration) {
+	if label, ok := orgLabels.label(orgID); ok {
+		MOrgDataSourceQueryDuration.WithLabelValues(label).Observe(duration.Seconds())
+	}
+}
+
+// SetOrgActiveUsers sets the number of active users per org. The orgs with the most
+// active users are labelled first when the cardinality limit is not reached yet.
+func SetOrgActiveUsers(activeUsers map[int64]int64) {
+	if !orgLabels.isEnabled() {
+		return
+	}
+
+	orgIDs := make([]int64, 0, len(activeUsers))
+	for orgID := range activeUsers {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool {
+		if activeUsers[orgIDs[i]] != activeUsers[orgIDs[j]] {
+			return activeUsers[orgIDs[i]] > activeUsers[orgIDs[j]]
+		}
+		return orgIDs[i] < orgIDs[j]
+	})
+
+	MOrgActiveUsers.Reset()
+	for _, orgID := range orgIDs {
+		if label, ok := orgLabels.label(orgID); ok {
+			MOrgActiveUsers.WithLabelValues(label).Add(float64(activeUsers[orgID]))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgLabelLimiter(t *testing.T) {
+	l := &orgLabelLimiter{}
+	l.configure(false, 2)
+	_, ok := l.label(1)
+	assert.False(t, ok)
+
+	l.configure(true, 2)
+	// the labels are handed out in the order the orgs are first seen
+	for _, tc := range []struct {
+		orgID    int64
+		expected string
+	}{
+		{orgID: 1, expected: "1"},
+		{orgID: 2, expected: "2"},
+		{orgID: 3, expected: OrgLabelOther},
+	} {
+		label, ok := l.label(tc.orgID)
+		assert.True(t, ok)
+		assert.Equal(t, tc.expected, label)
+	}
+	label, _ := l.label(1)
+	assert.Equal(t, "1", label)
+
+	_, ok = l.label(0)
+	assert.False(t, ok, "requests without an org are not labelled")
+}
+
+func TestSetOrgActiveUsers(t *testing.T) {
+	ConfigureOrgLabels(true, 2)
+	t.Cleanup(func() {
+		ConfigureOrgLabels(false, 0)
+	})
+
+	SetOrgActiveUsers(map[int64]int64{1: 5, 2: 20, 3: 10, 4: 1})
+
+	assert.Equal(t, 3, testutil.CollectAndCount(MOrgActiveUsers))
+	assert.Equal(t, float64(20), testutil.ToFloat64(MOrgActiveUsers.WithLabelValues("2")))
+	assert.Equal(t, float64(10), testutil.ToFloat64(MOrgActiveUsers.WithLabelValues("3")))
+	assert.Equal(t, float64(6), testutil.ToFloat64(MOrgActiveUsers.WithLabelValues(OrgLabelOther)))
+}
@@ -21,12 +21,14 @@ func (lw *logWrapper) Println(v ...interface{}) {
 func init() {
 	initMetricVars()
 	initFrontendMetrics()
+	initOrgMetrics()
 }
 
 func ProvideService(cfg *setting.Cfg) (*InternalMetricsService, error) {
 	s := &InternalMetricsService{
 		Cfg: cfg,
 	}
+	ConfigureOrgLabels(cfg.MetricsOrgLabelsEnabled, cfg.MetricsOrgLabelsMaxOrgs)
 	return s, s.readSettings()
 }
 
@@ -0,0 +1,72 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+var ErrInvalidCIDR = errors.New("invalid IP address or CIDR network")
+
+// NormalizeCIDRs validates a list of IP addresses and CIDR networks and returns it as networks.
+// Single IP addresses are converted to networks containing only that address, and empty entries
+// are dropped.
+func NormalizeCIDRs(cidrs []string) ([]string, error) {
+	normalized := make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, ErrInvalidCIDR
+			}
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, ErrInvalidCIDR
+		}
+		normalized = append(normalized, network.String())
+	}
+	return normalized, nil
+}
+
+// IsAllowed applies an allow list and a deny list to an address. Denied networks take precedence,
+// and an empty allow list allows every address which is not denied. Addresses which cannot be
+// parsed are only allowed if both lists are empty.
+func IsAllowed(allowed []string, denied []string, addr string) bool {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return true
+	}
+
+	ip, err := GetIPFromAddress(addr)
+	if err != nil {
+		return false
+	}
+	if containsIP(denied, ip) {
+		return false
+	}
+	return len(allowed) == 0 || containsIP(allowed, ip)
+}
+
+func containsIP(cidrs []string, ip net.IP) bool {
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
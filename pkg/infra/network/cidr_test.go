@@ -0,0 +1,34 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCIDRs(t *testing.T) {
+	normalized, err := NormalizeCIDRs([]string{" 10.1.2.3/8", "192.168.1.10", "", "::1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10/32", "::1/128"}, normalized)
+
+	_, err = NormalizeCIDRs([]string{"10.0.0.0/33"})
+	assert.ErrorIs(t, err, ErrInvalidCIDR)
+	_, err = NormalizeCIDRs([]string{"not-an-ip"})
+	assert.ErrorIs(t, err, ErrInvalidCIDR)
+}
+
+func TestIsAllowed(t *testing.T) {
+	allowed := []string{"10.0.0.0/8"}
+	denied := []string{"10.0.1.0/24"}
+
+	assert.True(t, IsAllowed(nil, nil, "192.168.1.1"))
+	assert.True(t, IsAllowed(nil, nil, "invalid"))
+	assert.True(t, IsAllowed(allowed, nil, "10.0.1.1:3000"))
+	assert.False(t, IsAllowed(allowed, nil, "192.168.1.1"))
+	assert.False(t, IsAllowed(allowed, denied, "10.0.1.1"))
+	assert.True(t, IsAllowed(allowed, denied, "10.0.2.1"))
+	assert.True(t, IsAllowed(nil, denied, "192.168.1.1"))
+	assert.False(t, IsAllowed(nil, denied, "[10.0.1.5]:3000"))
+	assert.False(t, IsAllowed(nil, denied, "invalid"))
+}
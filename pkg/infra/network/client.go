@@ -0,0 +1,47 @@
+package network
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP returns the IP address of the client which made a request, for checking it against
+// allow and deny lists. The X-Forwarded-For and X-Real-IP headers can be set by any client, so
+// they are only used when the request comes from one of the trusted proxies. The address is the
+// last one in X-Forwarded-For not added by a trusted proxy, or X-Real-IP if X-Forwarded-For is
+// not set. Otherwise it is the address of the peer of the connection.
+func ClientIP(req *http.Request, trustedProxies []string) string {
+	return ForwardedClientIP(req.RemoteAddr, req.Header.Values("X-Forwarded-For"), req.Header.Get("X-Real-IP"), trustedProxies)
+}
+
+// ForwardedClientIP is ClientIP for the address of the peer and the values of the X-Forwarded-For
+// and X-Real-IP headers of requests which are not an http.Request, such as gRPC requests.
+func ForwardedClientIP(remoteAddr string, forwarded []string, realIP string, trustedProxies []string) string {
+	peer, err := GetIPFromAddress(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	if len(trustedProxies) == 0 || !containsIP(trustedProxies, peer) {
+		return peer.String()
+	}
+
+	if len(forwarded) > 0 {
+		addrs := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+			if ip == nil {
+				// a malformed entry cannot be attributed to anyone
+				return peer.String()
+			}
+			if i == 0 || !containsIP(trustedProxies, ip) {
+				return ip.String()
+			}
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(realIP)); ip != nil {
+		return ip.String()
+	}
+	return peer.String()
+}
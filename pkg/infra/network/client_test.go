@@ -0,0 +1,73 @@
+package network
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []string{"10.0.0.0/8"}
+
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		headers    map[string]string
+		trusted    []string
+		expected   string
+	}{
+		{
+			desc:       "should use the peer address without trusted proxies",
+			remoteAddr: "192.168.1.1:50000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "1.2.3.4"},
+			expected:   "192.168.1.1",
+		},
+		{
+			desc:       "should ignore the headers of an untrusted peer",
+			remoteAddr: "192.168.1.1:50000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			trusted:    trusted,
+			expected:   "192.168.1.1",
+		},
+		{
+			desc:       "should use the last address not added by a trusted proxy",
+			remoteAddr: "10.0.0.1:50000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 5.6.7.8, 10.0.0.2"},
+			trusted:    trusted,
+			expected:   "5.6.7.8",
+		},
+		{
+			desc:       "should use the first address if all proxies are trusted",
+			remoteAddr: "10.0.0.1:50000",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"},
+			trusted:    trusted,
+			expected:   "10.0.0.3",
+		},
+		{
+			desc:       "should use X-Real-IP of a trusted proxy without X-Forwarded-For",
+			remoteAddr: "[::ffff:10.0.0.1]:50000",
+			headers:    map[string]string{"X-Real-IP": "1.2.3.4"},
+			trusted:    trusted,
+			expected:   "1.2.3.4",
+		},
+		{
+			desc:       "should use the peer address if X-Forwarded-For is malformed",
+			remoteAddr: "10.0.0.1:50000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, evil"},
+			trusted:    trusted,
+			expected:   "10.0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+			assert.Equal(t, tc.expected, ClientIP(req, tc.trusted))
+		})
+	}
+}
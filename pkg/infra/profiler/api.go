@@ -0,0 +1,90 @@
+package profiler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *SlowRequestProfiler) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/admin/profiles", func(profiles routing.RouteRegister) {
+		profiles.Get("/", middleware.ReqGrafanaAdmin, routing.Wrap(s.listProfilesHandler))
+		profiles.Get("/:name", middleware.ReqGrafanaAdmin, routing.Wrap(s.getProfileHandler))
+	})
+}
+
+// swagger:route GET /admin/profiles admin listSlowRequestProfiles
+//
+// List the profiles captured by the slow request profiler.
+//
+// Lists the CPU and goroutine profiles which were captured automatically when the latency
+// of a request or the number of requests in flight crossed a threshold, newest first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: listSlowRequestProfilesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *SlowRequestProfiler) listProfilesHandler(c *models.ReqContext) response.Response {
+	profiles, err := s.ListProfiles(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list profiles", err)
+	}
+	return response.JSON(http.StatusOK, profiles)
+}
+
+// swagger:route GET /admin/profiles/{profile_name} admin getSlowRequestProfile
+//
+// Download a profile captured by the slow request profiler.
+//
+// The profile can be analysed with `go tool pprof`.
+//
+// Produces:
+// - application/octet-stream
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: contentResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *SlowRequestProfiler) getProfileHandler(c *models.ReqContext) response.Response {
+	name := web.Params(c.Req)[":name"]
+	contents, err := s.GetProfile(c.Req.Context(), name)
+	if err != nil {
+		if errors.Is(err, ErrProfileNotFound) {
+			return response.Error(http.StatusNotFound, "Profile not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get profile", err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", profileMimeType)
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	return response.CreateNormalResponse(header, contents, http.StatusOK)
+}
+
+// swagger:parameters getSlowRequestProfile
+type GetSlowRequestProfileParams struct {
+	// in:path
+	// required:true
+	Name string `json:"profile_name"`
+}
+
+// swagger:response listSlowRequestProfilesResponse
+type ListSlowRequestProfilesResponse struct {
+	// in:body
+	Body []Profile `json:"body"`
+}
@@ -0,0 +1,280 @@
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// storageRoot is the folder of the file storage the profiles are stored in.
+	storageRoot = "/slow-request-profiles/"
+
+	profileMimeType = "application/octet-stream"
+)
+
+// Reasons for capturing a profile.
+const (
+	ReasonLatency  = "latency"
+	ReasonInFlight = "in_flight"
+)
+
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile is a CPU or goroutine profile captured when a threshold was crossed.
+type Profile struct {
+	Name string `json:"name"`
+	// Type is either cpu or goroutine.
+	Type string `json:"type"`
+	// Reason is the threshold which was crossed, either latency or in_flight.
+	Reason string `json:"reason"`
+	// Path is the path of the request which triggered the capture.
+	Path    string    `json:"path,omitempty"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
+
+type settings struct {
+	enabled            bool
+	latencyThreshold   time.Duration
+	inFlightThreshold  int64
+	cpuProfileDuration time.Duration
+	cooldown           time.Duration
+	maxProfiles        int
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("slow_request_profiler")
+	return settings{
+		enabled:            section.Key("enabled").MustBool(false),
+		latencyThreshold:   section.Key("latency_threshold").MustDuration(10 * time.Second),
+		inFlightThreshold:  section.Key("in_flight_threshold").MustInt64(0),
+		cpuProfileDuration: section.Key("cpu_profile_duration").MustDuration(10 * time.Second),
+		cooldown:           section.Key("cooldown").MustDuration(10 * time.Minute),
+		maxProfiles:        section.Key("max_profiles").MustInt(20),
+	}
+}
+
+type trigger struct {
+	reason string
+	path   string
+}
+
+// SlowRequestProfiler captures CPU and goroutine profiles when the latency of a request or the
+// number of requests in flight crosses a threshold, so sporadic latency spikes can be analysed
+// after they happened. Captures are rate limited by a cooldown and only the newest profiles are kept.
+type SlowRequestProfiler struct {
+	settings      settings
+	log           log.Logger
+	store         filestorage.FileStorage
+	routeRegister routing.RouteRegister
+
+	inFlight    int64
+	triggers    chan trigger
+	lastCapture time.Time
+	now         func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister) *SlowRequestProfiler {
+	logger := log.New("slow-request-profiler")
+	s := &SlowRequestProfiler{
+		settings:      readSettings(cfg),
+		log:           logger,
+		store:         filestorage.NewDbStorage(logger, sqlStore, nil, storageRoot),
+		routeRegister: routeRegister,
+		triggers:      make(chan trigger, 1),
+		now:           time.Now,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *SlowRequestProfiler) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+func (s *SlowRequestProfiler) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t := <-s.triggers:
+			now := s.now()
+			if !s.lastCapture.IsZero() && now.Sub(s.lastCapture) < s.settings.cooldown {
+				continue
+			}
+			s.lastCapture = now
+
+			s.log.Info("Capturing profiles", "reason", t.reason, "path", t.path)
+			if err := s.capture(ctx, now, t); err != nil {
+				s.log.Error("Failed to capture profiles", "reason", t.reason, "error", err)
+			}
+			if err := s.deleteOldProfiles(ctx); err != nil {
+				s.log.Error("Failed to delete old profiles", "error", err)
+			}
+		}
+	}
+}
+
+// Middleware tracks the latency of the requests and the number of requests in flight,
+// and triggers a capture when one of them crosses its threshold.
+func (s *SlowRequestProfiler) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		if s == nil || !s.settings.enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inFlight := atomic.AddInt64(&s.inFlight, 1)
+			defer atomic.AddInt64(&s.inFlight, -1)
+			if s.settings.inFlightThreshold > 0 && inFlight >= s.settings.inFlightThreshold {
+				s.trigger(trigger{reason: ReasonInFlight, path: r.URL.Path})
+			}
+
+			if s.settings.latencyThreshold > 0 {
+				// the profiles are captured while the slow request is still being served
+				timer := time.AfterFunc(s.settings.latencyThreshold, func() {
+					s.trigger(trigger{reason: ReasonLatency, path: r.URL.Path})
+				})
+				defer timer.Stop()
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *SlowRequestProfiler) trigger(t trigger) {
+	select {
+	case s.triggers <- t:
+	default:
+		// a capture is already pending
+	}
+}
+
+func (s *SlowRequestProfiler) capture(ctx context.Context, now time.Time, t trigger) error {
+	prefix := now.UTC().Format("20060102T150405Z") + "-" + t.reason
+	properties := map[string]string{
+		"reason": t.reason,
+		"path":   t.path,
+	}
+
+	// the goroutines are captured first, since they show what was blocked when the threshold was crossed
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 0); err != nil {
+		return fmt.Errorf("failed to capture goroutine profile: %w", err)
+	}
+	if err := s.save(ctx, prefix+"-goroutine.pprof", goroutines.Bytes(), properties); err != nil {
+		return err
+	}
+
+	var cpu bytes.Buffer
+	if err := pprof.StartCPUProfile(&cpu); err != nil {
+		// another CPU profile is running, e.g. one requested from the pprof endpoints
+		return fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(s.settings.cpuProfileDuration):
+	}
+	pprof.StopCPUProfile()
+	return s.save(ctx, prefix+"-cpu.pprof", cpu.Bytes(), properties)
+}
+
+func (s *SlowRequestProfiler) save(ctx context.Context, name string, contents []byte, properties map[string]string) error {
+	err := s.store.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:       filestorage.Delimiter + name,
+		MimeType:   profileMimeType,
+		Contents:   contents,
+		Properties: properties,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListProfiles returns the stored profiles, newest first.
+func (s *SlowRequestProfiler) ListProfiles(ctx context.Context) ([]Profile, error) {
+	resp, err := s.store.List(ctx, filestorage.Delimiter, &filestorage.Paging{First: 1000}, &filestorage.ListOptions{WithFiles: true})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]Profile, 0)
+	if resp == nil {
+		return profiles, nil
+	}
+	for _, f := range resp.Files {
+		if f.IsFolder() {
+			continue
+		}
+		profiles = append(profiles, Profile{
+			Name:    f.Name,
+			Type:    profileType(f.Name),
+			Reason:  f.Properties["reason"],
+			Path:    f.Properties["path"],
+			Size:    f.Size,
+			Created: f.Created,
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name > profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// GetProfile returns the contents of a stored profile.
+func (s *SlowRequestProfiler) GetProfile(ctx context.Context, name string) ([]byte, error) {
+	path := filestorage.Delimiter + name
+	if name == "" || strings.Contains(name, filestorage.Delimiter) || filestorage.ValidatePath(path) != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	f, ok, err := s.store.Get(ctx, path, &filestorage.GetFileOptions{WithContents: true})
+	if err != nil {
+		return nil, err
+	}
+	if !ok || f.IsFolder() {
+		return nil, ErrProfileNotFound
+	}
+	return f.Contents, nil
+}
+
+func (s *SlowRequestProfiler) deleteOldProfiles(ctx context.Context) error {
+	profiles, err := s.ListProfiles(ctx)
+	if err != nil {
+		return err
+	}
+	if len(profiles) <= s.settings.maxProfiles {
+		return nil
+	}
+
+	for _, p := range profiles[s.settings.maxProfiles:] {
+		if err := s.store.Delete(ctx, filestorage.Delimiter+p.Name); err != nil {
+			return fmt.Errorf("failed to delete profile %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func profileType(name string) string {
+	name = strings.TrimSuffix(name, ".pprof")
+	return name[strings.LastIndex(name, "-")+1:]
+}
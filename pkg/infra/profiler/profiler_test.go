@@ -0,0 +1,97 @@
+package profiler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func newTestProfiler(store filestorage.FileStorage, settings settings) *SlowRequestProfiler {
+	settings.enabled = true
+	return &SlowRequestProfiler{
+		settings: settings,
+		log:      log.NewNopLogger(),
+		store:    store,
+		triggers: make(chan trigger, 1),
+		now:      time.Now,
+	}
+}
+
+func TestSlowRequestProfiler_Middleware(t *testing.T) {
+	serve := func(s *SlowRequestProfiler, delay time.Duration) {
+		handler := s.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/ds/query", nil))
+	}
+
+	t.Run("triggers a capture when a request is slow", func(t *testing.T) {
+		s := newTestProfiler(nil, settings{latencyThreshold: 10 * time.Millisecond})
+		serve(s, 50*time.Millisecond)
+
+		select {
+		case tr := <-s.triggers:
+			require.Equal(t, trigger{reason: ReasonLatency, path: "/api/ds/query"}, tr)
+		default:
+			t.Fatal("expected a capture to be triggered")
+		}
+	})
+
+	t.Run("triggers a capture when too many requests are in flight", func(t *testing.T) {
+		s := newTestProfiler(nil, settings{inFlightThreshold: 1})
+		serve(s, 0)
+
+		require.Len(t, s.triggers, 1)
+		require.Equal(t, ReasonInFlight, (<-s.triggers).reason)
+	})
+
+	t.Run("does not trigger a capture below the thresholds", func(t *testing.T) {
+		s := newTestProfiler(nil, settings{latencyThreshold: time.Second, inFlightThreshold: 2})
+		serve(s, 0)
+
+		require.Empty(t, s.triggers)
+	})
+
+	t.Run("passes requests through when disabled", func(t *testing.T) {
+		var s *SlowRequestProfiler
+		serve(s, 0)
+	})
+}
+
+func TestSlowRequestProfiler_GetProfile(t *testing.T) {
+	s := newTestProfiler(&filestorage.MockFileStorage{}, settings{})
+
+	for _, name := range []string{"", "..", "../secret", "a/b.pprof"} {
+		_, err := s.GetProfile(context.Background(), name)
+		require.ErrorIs(t, err, ErrProfileNotFound, name)
+	}
+}
+
+func TestSlowRequestProfiler_DeleteOldProfiles(t *testing.T) {
+	store := &filestorage.MockFileStorage{}
+	store.On("List", mock.Anything, filestorage.Delimiter, mock.Anything, mock.Anything).Return(&filestorage.ListResponse{
+		Files: []*filestorage.File{
+			{FileMetadata: filestorage.FileMetadata{Name: "20221001T100000Z-latency-cpu.pprof"}},
+			{FileMetadata: filestorage.FileMetadata{Name: "20221003T100000Z-in_flight-cpu.pprof"}},
+			{FileMetadata: filestorage.FileMetadata{Name: "20221002T100000Z-latency-goroutine.pprof"}},
+		},
+	}, nil)
+	store.On("Delete", mock.Anything, "/20221001T100000Z-latency-cpu.pprof").Return(nil)
+
+	s := newTestProfiler(store, settings{maxProfiles: 2})
+	require.NoError(t, s.deleteOldProfiles(context.Background()))
+	store.AssertNumberOfCalls(t, "Delete", 1)
+
+	profiles, err := s.ListProfiles(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "cpu", profiles[0].Type)
+	require.Equal(t, "goroutine", profiles[1].Type)
+}
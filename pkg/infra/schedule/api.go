@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	defaultUpcomingLimit = 100
+	maxUpcomingLimit     = 1000
+	maxUpcomingRange     = 31 * 24 * time.Hour
+)
+
+func (r *Registry) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Get("/api/org/schedules", middleware.ReqOrgAdmin, routing.Wrap(r.getUpcomingHandler))
+}
+
+// swagger:route GET /org/schedules org getUpcomingSchedules
+//
+// Get the upcoming scheduled runs of the current organization.
+//
+// Lists when the scheduled features of the organization, like scheduled reports, run next, in
+// the time zones they are scheduled in.
+//
+// Responses:
+// 200: getUpcomingSchedulesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (r *Registry) getUpcomingHandler(c *models.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultUpcomingLimit
+	}
+	if limit > maxUpcomingLimit {
+		limit = maxUpcomingLimit
+	}
+	days := c.QueryInt("days")
+	if days <= 0 {
+		days = 7
+	}
+	period := time.Duration(days) * 24 * time.Hour
+	if period > maxUpcomingRange {
+		period = maxUpcomingRange
+	}
+
+	from := time.Now()
+	runs, err := r.Upcoming(c.Req.Context(), c.OrgID, from, from.Add(period), limit)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get upcoming schedules", err)
+	}
+	return response.JSON(http.StatusOK, runs)
+}
+
+// swagger:parameters getUpcomingSchedules
+type GetUpcomingSchedulesParams struct {
+	// in:query
+	// required:false
+	// default:7
+	Days int `json:"days"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:response getUpcomingSchedulesResponse
+type GetUpcomingSchedulesResponse struct {
+	// in:body
+	Body []Run `json:"body"`
+}
@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+)
+
+// Item is something a feature does on a schedule, for example sending a report.
+type Item struct {
+	Feature  string `json:"feature"`
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Timezone string `json:"timezone"`
+}
+
+// Run is a time an item is scheduled for.
+type Run struct {
+	Item
+	At time.Time `json:"at"`
+	// Local is the time in the time zone of the item.
+	Local string `json:"local"`
+}
+
+// Source lists the scheduled items of a feature.
+type Source interface {
+	// ScheduledItems returns the enabled scheduled items of the organization.
+	ScheduledItems(ctx context.Context, orgID int64) ([]Item, error)
+}
+
+// Registry collects the scheduled items of all features, so that the upcoming runs of an
+// organization can be listed in one place.
+type Registry struct {
+	mu      sync.RWMutex
+	sources map[string]Source
+}
+
+func ProvideRegistry(routeRegister routing.RouteRegister) *Registry {
+	r := &Registry{sources: map[string]Source{}}
+	r.registerAPIEndpoints(routeRegister)
+	return r
+}
+
+// Register registers the source of the scheduled items of a feature.
+func (r *Registry) Register(feature string, source Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[feature] = source
+}
+
+// Upcoming returns the runs of the scheduled items of the organization from the given time until
+// the other, in order and at most limit of them.
+func (r *Registry) Upcoming(ctx context.Context, orgID int64, from, to time.Time, limit int) ([]Run, error) {
+	r.mu.RLock()
+	sources := make(map[string]Source, len(r.sources))
+	for feature, source := range r.sources {
+		sources[feature] = source
+	}
+	r.mu.RUnlock()
+
+	runs := make([]Run, 0)
+	for feature, source := range sources {
+		items, err := source.ScheduledItems(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			item.Feature = feature
+			// the features validate the schedules they store
+			sched, err := Parse(item.Schedule, item.Timezone)
+			if err != nil {
+				continue
+			}
+			// no item contributes more than limit runs to the first limit runs
+			next := sched.Next(from)
+			for i := 0; i < limit && !next.IsZero() && !next.After(to); i++ {
+				runs = append(runs, Run{Item: item, At: next.UTC(), Local: next.Format(time.RFC3339)})
+				next = sched.Next(next)
+			}
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].At.Before(runs[j].At)
+	})
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
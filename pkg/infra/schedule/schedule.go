@@ -0,0 +1,173 @@
+// Package schedule evaluates cron schedules in the time zones of organizations and users, so that
+// every scheduled feature agrees on when a time like "Monday 9:00" is, also on the days daylight
+// saving time begins or ends.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	ErrUnknownTimezone = errors.New("unknown timezone")
+
+	parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+)
+
+const (
+	// the time zones of preferences which don't name a location
+	timezoneUTC     = "utc"
+	timezoneBrowser = "browser"
+
+	allHours = 1<<24 - 1
+	// maxSkips bounds the times Next skips because of daylight saving time changes.
+	maxSkips = 100
+)
+
+// LoadLocation returns the location of an IANA time zone name. The time zones of preferences which
+// don't name a location, utc, browser and the empty string, are UTC since schedules are evaluated by
+// the server.
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" || strings.EqualFold(timezone, timezoneUTC) || strings.EqualFold(timezone, timezoneBrowser) {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTimezone, timezone)
+	}
+	return loc, nil
+}
+
+// ResolveTimezone returns the first of the time zones naming a location, for example of the
+// preferences of a user, their teams and their organization, or UTC if none does.
+func ResolveTimezone(timezones ...string) string {
+	for _, timezone := range timezones {
+		if timezone == "" || strings.EqualFold(timezone, timezoneBrowser) {
+			continue
+		}
+		if strings.EqualFold(timezone, timezoneUTC) {
+			return time.UTC.String()
+		}
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			return loc.String()
+		}
+	}
+	return time.UTC.String()
+}
+
+// Schedule is a cron expression with five fields, or a descriptor like @daily, evaluated in a time
+// zone.
+type Schedule struct {
+	expr     string
+	location *time.Location
+	spec     cron.Schedule
+	// wallClock is set for schedules firing at fixed hours, which follow the wall clock of the
+	// location rather than the time elapsed.
+	wallClock bool
+}
+
+// Parse parses the cron expression of a schedule evaluated in the time zone.
+func Parse(expr, timezone string) (*Schedule, error) {
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := parser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+
+	s := &Schedule{expr: expr, location: loc, spec: spec}
+	if specSchedule, ok := spec.(*cron.SpecSchedule); ok {
+		s.wallClock = specSchedule.Hour&allHours != allHours
+	}
+	return s, nil
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+// Next returns the first time after the given time the schedule fires, in the location of the
+// schedule, or the zero time if it never fires.
+//
+// Schedules with fixed hours follow the wall clock: a time skipped when the clocks are set forward
+// fires right after the clocks were set forward, and a time repeated when the clocks are set back
+// fires once, the first time it occurs. Schedules firing every hour and @every intervals fire by
+// the time elapsed.
+func (s *Schedule) Next(after time.Time) time.Time {
+	after = after.In(s.location)
+	if !s.wallClock {
+		return s.spec.Next(after)
+	}
+
+	wall := toWall(after)
+	for i := 0; i < maxSkips; i++ {
+		wall = s.spec.Next(wall)
+		if wall.IsZero() {
+			return wall
+		}
+
+		next, ok := s.firstOccurrence(wall)
+		if !ok {
+			next = s.afterGap(wall)
+		}
+		// a time repeated by setting the clocks back fired already if its first occurrence passed
+		if next.After(after) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// candidates returns the times in the location which could show the wall clock time, one for each
+// offset of the location around it.
+func (s *Schedule) candidates(wall time.Time) []time.Time {
+	result := make([]time.Time, 0, 3)
+	for _, probe := range []time.Duration{-24 * time.Hour, 0, 24 * time.Hour} {
+		_, offset := wall.Add(probe).In(s.location).Zone()
+		result = append(result, wall.Add(-time.Duration(offset)*time.Second).In(s.location))
+	}
+	return result
+}
+
+// firstOccurrence returns the first time showing the wall clock time in the location, or false if
+// the location skips it.
+func (s *Schedule) firstOccurrence(wall time.Time) (time.Time, bool) {
+	var first time.Time
+	for _, t := range s.candidates(wall) {
+		if toWall(t).Equal(wall) && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// afterGap returns the time the clocks were set forward skipping the wall clock time.
+func (s *Schedule) afterGap(wall time.Time) time.Time {
+	var next time.Time
+	for _, t := range s.candidates(wall) {
+		if toWall(t).After(wall) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	// the clocks are set forward at a full minute by at most a day
+	for i := 0; i < 24*60 && toWall(next.Add(-time.Minute)).After(wall); i++ {
+		next = next.Add(-time.Minute)
+	}
+	return next
+}
+
+// toWall returns the wall clock time of a time as a UTC time, which has no daylight saving time.
+func toWall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
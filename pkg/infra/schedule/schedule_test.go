@@ -0,0 +1,125 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		expr     string
+		timezone string
+		after    time.Time
+		expected []time.Time
+	}{
+		{
+			desc:     "should fire at the wall clock time in the time zone",
+			expr:     "0 9 * * 1",
+			timezone: "America/New_York",
+			after:    time.Date(2022, time.March, 1, 0, 0, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2022, time.March, 7, 9, 0, 0, 0, newYork),
+				time.Date(2022, time.March, 14, 9, 0, 0, 0, newYork),
+			},
+		},
+		{
+			desc:     "should fire right after the clocks are set forward if the time is skipped",
+			expr:     "30 2 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2022, time.March, 12, 12, 0, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2022, time.March, 13, 3, 0, 0, 0, newYork),
+				time.Date(2022, time.March, 14, 2, 30, 0, 0, newYork),
+			},
+		},
+		{
+			desc:     "should fire once if the time is repeated when the clocks are set back",
+			expr:     "30 1 * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2022, time.November, 6, 0, 0, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2022, time.November, 6, 5, 30, 0, 0, time.UTC),
+				time.Date(2022, time.November, 7, 1, 30, 0, 0, newYork),
+			},
+		},
+		{
+			desc:     "should fire by the time elapsed if the schedule fires every hour",
+			expr:     "0 * * * *",
+			timezone: "America/New_York",
+			after:    time.Date(2022, time.November, 6, 5, 30, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2022, time.November, 6, 6, 0, 0, 0, time.UTC),
+				time.Date(2022, time.November, 6, 7, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			desc:     "should evaluate the time zones of preferences not naming a location in UTC",
+			expr:     "@daily",
+			timezone: "browser",
+			after:    time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sched, err := Parse(tc.expr, tc.timezone)
+			require.NoError(t, err)
+
+			next := tc.after
+			for _, expected := range tc.expected {
+				next = sched.Next(next)
+				assert.True(t, expected.Equal(next), "expected %s, got %s", expected.UTC(), next.UTC())
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse("0 9 * *", "UTC")
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+
+	_, err = Parse("0 9 * * 1", "Mars/Olympus_Mons")
+	require.ErrorIs(t, err, ErrUnknownTimezone)
+}
+
+func TestResolveTimezone(t *testing.T) {
+	assert.Equal(t, "Europe/Berlin", ResolveTimezone("", "browser", "Europe/Berlin", "America/New_York"))
+	assert.Equal(t, "UTC", ResolveTimezone("utc", "Europe/Berlin"))
+	assert.Equal(t, "UTC", ResolveTimezone("browser", "Mars/Olympus_Mons"))
+	assert.Equal(t, "UTC", ResolveTimezone())
+}
+
+type fakeSource []Item
+
+func (f fakeSource) ScheduledItems(context.Context, int64) ([]Item, error) {
+	return f, nil
+}
+
+func TestRegistry_Upcoming(t *testing.T) {
+	registry := ProvideRegistry(routing.NewRouteRegister())
+	registry.Register("reports", fakeSource{{Name: "weekly", Schedule: "0 9 * * 1", Timezone: "Europe/Berlin"}})
+	registry.Register("sync", fakeSource{{Name: "daily", Schedule: "0 1 * * *", Timezone: "UTC"}})
+
+	from := time.Date(2022, time.March, 6, 0, 0, 0, 0, time.UTC)
+	runs, err := registry.Upcoming(context.Background(), 1, from, from.Add(7*24*time.Hour), 3)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, "sync", runs[0].Feature)
+	assert.Equal(t, "sync", runs[1].Feature)
+	assert.Equal(t, "reports", runs[2].Feature)
+	assert.Equal(t, time.Date(2022, time.March, 7, 8, 0, 0, 0, time.UTC), runs[2].At)
+	assert.Equal(t, "2022-03-07T09:00:00+01:00", runs[2].Local)
+}
@@ -0,0 +1,24 @@
+package semaphore
+
+// Names of the expensive operations whose concurrency is limited.
+const (
+	OperationSearchReindex       = "search_reindex"
+	OperationSecretsReEncryption = "secrets_reencryption"
+	OperationExport              = "export"
+)
+
+type lease struct {
+	ID     int64  `xorm:"pk autoincr 'id'"`
+	Name   string `xorm:"name"`
+	Slot   int    `xorm:"slot"`
+	Holder string `xorm:"holder"`
+	// Version is quoted, xorm would otherwise use it for optimistic locking.
+	Version int64 `xorm:"'version'"`
+	// Acquired and Expires are unix timestamps in seconds.
+	Acquired int64 `xorm:"acquired"`
+	Expires  int64 `xorm:"expires"`
+}
+
+func (l lease) TableName() string {
+	return "semaphore_lease"
+}
@@ -0,0 +1,232 @@
+// Package semaphore limits the number of nodes of a Grafana cluster running an expensive operation
+// at the same time, such as re-indexing the dashboards for search, re-encrypting the secrets or
+// exporting an organization.
+//
+// Each operation has a limited number of slots, stored as database leases. A node runs the
+// operation while it holds one of them. Leases are renewed in the background and taken over by
+// another node once their holder stops renewing them, for example because it crashed.
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const releaseTimeout = 5 * time.Second
+
+var ErrLimitReached = errors.New("too many nodes are running the operation")
+
+type settings struct {
+	nodeID        string
+	leaseDuration time.Duration
+	waitInterval  time.Duration
+	limits        map[string]int
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("operation_concurrency")
+	s := settings{
+		nodeID:        cfg.Raw.Section("leader_election").Key("node_id").MustString(""),
+		leaseDuration: section.Key("lease_ttl").MustDuration(time.Minute),
+		waitInterval:  section.Key("wait_interval").MustDuration(5 * time.Second),
+		limits:        map[string]int{},
+	}
+	if s.nodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "grafana"
+		}
+		s.nodeID = fmt.Sprintf("%s:%s", hostname, cfg.HTTPPort)
+	}
+	if s.leaseDuration < 3*time.Second {
+		s.leaseDuration = 3 * time.Second
+	}
+	for _, operation := range []string{OperationSearchReindex, OperationSecretsReEncryption, OperationExport} {
+		s.limits[operation] = section.Key(operation).MustInt(1)
+	}
+	return s
+}
+
+// limit returns the number of slots of an operation, 0 if it is not limited.
+func (s settings) limit(operation string) int {
+	limit, ok := s.limits[operation]
+	if !ok {
+		return 1
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+type Service interface {
+	// TryAcquire takes a slot of the operation for this node, or returns ErrLimitReached if all its
+	// slots are held. The operation must stop when the context of the lease is done, which happens
+	// if the lease can't be renewed, and release the lease once it is done.
+	TryAcquire(ctx context.Context, operation string) (*Lease, error)
+	// Acquire takes a slot of the operation for this node like TryAcquire, but waits for a slot to
+	// be released if all of them are held, until the context is done.
+	Acquire(ctx context.Context, operation string) (*Lease, error)
+}
+
+type SemaphoreService struct {
+	settings settings
+	store    db.DB
+	tracer   tracing.Tracer
+	log      log.Logger
+	now      func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, tracer tracing.Tracer) *SemaphoreService {
+	return &SemaphoreService{
+		settings: readSettings(cfg),
+		store:    sqlStore,
+		tracer:   tracer,
+		log:      log.New("semaphore"),
+		now:      time.Now,
+	}
+}
+
+// renewInterval is how often held leases are renewed. A node stops an operation one interval
+// before its lease expires in the database when the lease can't be renewed, so that no other node
+// starts it in the meantime as long as their clocks agree.
+func (s *SemaphoreService) renewInterval() time.Duration {
+	return s.settings.leaseDuration / 3
+}
+
+func (s *SemaphoreService) TryAcquire(ctx context.Context, operation string) (*Lease, error) {
+	spanCtx, span := s.tracer.Start(ctx, "SemaphoreService.TryAcquire")
+	span.SetAttributes("semaphore.operation", operation, attribute.Key("semaphore.operation").String(operation))
+	defer span.End()
+
+	limit := s.settings.limit(operation)
+	if limit == 0 {
+		leaseCtx, cancel := context.WithCancel(ctx)
+		return &Lease{ctx: leaseCtx, cancel: cancel}, nil
+	}
+
+	for slot := 0; slot < limit; slot++ {
+		l, err := s.acquire(spanCtx, operation, slot)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if l != nil {
+			s.log.Debug("Acquired operation slot", "operation", operation, "slot", slot)
+			return s.hold(ctx, l), nil
+		}
+	}
+	return nil, ErrLimitReached
+}
+
+func (s *SemaphoreService) Acquire(ctx context.Context, operation string) (*Lease, error) {
+	waiting := false
+	for {
+		l, err := s.TryAcquire(ctx, operation)
+		if !errors.Is(err, ErrLimitReached) {
+			return l, err
+		}
+		if !waiting {
+			s.log.Info("Waiting for other nodes to finish the operation", "operation", operation)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.settings.waitInterval):
+		}
+	}
+}
+
+// hold renews an acquired lease in the background until it is released.
+func (s *SemaphoreService) hold(ctx context.Context, l *lease) *Lease {
+	leaseCtx, cancel := context.WithCancel(ctx)
+	held := &Lease{
+		ctx:    leaseCtx,
+		cancel: cancel,
+		s:      s,
+		lease:  l,
+		done:   make(chan struct{}),
+	}
+	go held.keepAlive()
+	return held
+}
+
+// Lease is a slot of an operation held by this node.
+type Lease struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+
+	// s, lease and done are nil for operations without limit.
+	s     *SemaphoreService
+	lease *lease
+	done  chan struct{}
+}
+
+// Context returns a context which is done when the lease is lost or released, or when the context
+// the lease was acquired with is done.
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Release frees the slot, so that other nodes can run the operation. It can be called several
+// times.
+func (l *Lease) Release() {
+	l.once.Do(func() {
+		l.cancel()
+		if l.done == nil {
+			return
+		}
+		<-l.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+		defer cancel()
+		if err := l.s.release(ctx, l.lease); err != nil {
+			l.s.log.Warn("Failed to release operation slot", "operation", l.lease.Name, "slot", l.lease.Slot, "error", err)
+		}
+	})
+}
+
+func (l *Lease) keepAlive() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.s.renewInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := l.s.renew(l.ctx, l.lease)
+			if renewed {
+				continue
+			}
+			if err != nil {
+				if l.ctx.Err() != nil {
+					return
+				}
+				l.s.log.Warn("Failed to renew operation slot", "operation", l.lease.Name, "slot", l.lease.Slot, "error", err)
+				if l.s.now().Add(l.s.renewInterval()).Unix() < l.lease.Expires {
+					continue
+				}
+			}
+			l.s.log.Error("Lost operation slot, stopping the operation", "operation", l.lease.Name, "slot", l.lease.Slot)
+			l.cancel()
+			return
+		}
+	}
+}
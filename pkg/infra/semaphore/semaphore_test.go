@@ -0,0 +1,136 @@
+package semaphore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupNode(t *testing.T, sqlStore db.DB, nodeID string, now *time.Time) *SemaphoreService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	_, err := cfg.Raw.Section("leader_election").NewKey("node_id", nodeID)
+	require.NoError(t, err)
+	section := cfg.Raw.Section("operation_concurrency")
+	for key, value := range map[string]string{
+		"lease_ttl":                  "30s",
+		"wait_interval":              "10ms",
+		OperationSearchReindex:       "2",
+		OperationSecretsReEncryption: "1",
+		OperationExport:              "0",
+	} {
+		_, err = section.NewKey(key, value)
+		require.NoError(t, err)
+	}
+	s := ProvideService(cfg, sqlStore, tracing.InitializeTracerForTest())
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestSettings(t *testing.T) {
+	s := readSettings(setting.NewCfg())
+	assert.Equal(t, time.Minute, s.leaseDuration)
+	assert.Equal(t, 1, s.limit(OperationSearchReindex))
+	assert.Equal(t, 1, s.limit("unknown"))
+	assert.NotEmpty(t, s.nodeID)
+}
+
+func TestIntegrationSemaphore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	a := setupNode(t, sqlStore, "a", &now)
+	b := setupNode(t, sqlStore, "b", &now)
+
+	t.Run("Should limit the nodes running an operation", func(t *testing.T) {
+		l, err := a.TryAcquire(ctx, OperationSecretsReEncryption)
+		require.NoError(t, err)
+		_, err = b.TryAcquire(ctx, OperationSecretsReEncryption)
+		require.ErrorIs(t, err, ErrLimitReached)
+
+		l.Release()
+		l.Release()
+		assert.Error(t, l.Context().Err())
+		l, err = b.TryAcquire(ctx, OperationSecretsReEncryption)
+		require.NoError(t, err)
+		l.Release()
+	})
+
+	t.Run("Should give each operation its own slots", func(t *testing.T) {
+		first, err := a.TryAcquire(ctx, OperationSearchReindex)
+		require.NoError(t, err)
+		defer first.Release()
+		second, err := b.TryAcquire(ctx, OperationSearchReindex)
+		require.NoError(t, err)
+		defer second.Release()
+		_, err = b.TryAcquire(ctx, OperationSearchReindex)
+		require.ErrorIs(t, err, ErrLimitReached)
+
+		other, err := b.TryAcquire(ctx, OperationSecretsReEncryption)
+		require.NoError(t, err)
+		other.Release()
+	})
+
+	t.Run("Should not limit operations with a limit of 0", func(t *testing.T) {
+		first, err := a.TryAcquire(ctx, OperationExport)
+		require.NoError(t, err)
+		defer first.Release()
+		second, err := b.TryAcquire(ctx, OperationExport)
+		require.NoError(t, err)
+		second.Release()
+	})
+
+	t.Run("Should wait for a slot to be released", func(t *testing.T) {
+		held, err := a.TryAcquire(ctx, OperationSecretsReEncryption)
+		require.NoError(t, err)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			held.Release()
+		}()
+
+		l, err := b.Acquire(ctx, OperationSecretsReEncryption)
+		require.NoError(t, err)
+		l.Release()
+
+		held, err = a.TryAcquire(ctx, OperationSecretsReEncryption)
+		require.NoError(t, err)
+		defer held.Release()
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = b.Acquire(timeoutCtx, OperationSecretsReEncryption)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Should take over slots which are not renewed", func(t *testing.T) {
+		held, err := a.acquire(ctx, "crashed", 0)
+		require.NoError(t, err)
+		require.NotNil(t, held)
+
+		now = now.Add(20 * time.Second)
+		renewed, err := a.renew(ctx, held)
+		require.NoError(t, err)
+		require.True(t, renewed)
+		now = now.Add(20 * time.Second)
+		taken, err := b.acquire(ctx, "crashed", 0)
+		require.NoError(t, err)
+		assert.Nil(t, taken)
+
+		now = now.Add(time.Minute)
+		taken, err = b.acquire(ctx, "crashed", 0)
+		require.NoError(t, err)
+		require.NotNil(t, taken)
+		renewed, err = a.renew(ctx, held)
+		require.NoError(t, err)
+		assert.False(t, renewed)
+	})
+}
@@ -0,0 +1,91 @@
+package semaphore
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// acquire takes a slot of an operation for this node if it is free, or if the node holding it
+// stopped renewing it. The update is conditional on the version of the lease, so that only one of
+// the nodes racing for an expired slot gets it. It returns nil if the slot is held.
+func (s *SemaphoreService) acquire(ctx context.Context, operation string, slot int) (*lease, error) {
+	now := s.now().Unix()
+	l := &lease{
+		Name:     operation,
+		Slot:     slot,
+		Holder:   s.settings.nodeID,
+		Version:  1,
+		Acquired: now,
+		Expires:  now + int64(s.settings.leaseDuration.Seconds()),
+	}
+
+	var acquired bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		current := &lease{}
+		exists, err := sess.Where("name = ? AND slot = ?", operation, slot).Get(current)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			_, err := sess.Insert(l)
+			if err != nil && s.store.GetDialect().IsUniqueConstraintViolation(err) {
+				// another node took the slot first
+				return nil
+			}
+			acquired = err == nil
+			return err
+		}
+
+		if current.Expires > now {
+			return nil
+		}
+
+		l.ID = current.ID
+		l.Version = current.Version + 1
+		res, err := sess.Exec("UPDATE semaphore_lease SET holder = ?, version = ?, acquired = ?, expires = ? WHERE id = ? AND version = ?",
+			l.Holder, l.Version, l.Acquired, l.Expires, current.ID, current.Version)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		acquired = affected == 1
+		return err
+	})
+	if err != nil || !acquired {
+		return nil, err
+	}
+	return l, nil
+}
+
+// renew extends a lease held by this node. It returns false if the lease was taken over by another
+// node after it expired.
+func (s *SemaphoreService) renew(ctx context.Context, l *lease) (bool, error) {
+	expires := s.now().Unix() + int64(s.settings.leaseDuration.Seconds())
+
+	var renewed bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE semaphore_lease SET version = ?, expires = ? WHERE id = ? AND version = ?",
+			l.Version+1, expires, l.ID, l.Version)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		renewed = affected == 1
+		return err
+	})
+	if renewed {
+		l.Version++
+		l.Expires = expires
+	}
+	return renewed, err
+}
+
+// release frees a slot if this node still holds it.
+func (s *SemaphoreService) release(ctx context.Context, l *lease) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM semaphore_lease WHERE id = ? AND version = ?", l.ID, l.Version)
+		return err
+	})
+}
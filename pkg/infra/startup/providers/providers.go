@@ -0,0 +1,41 @@
+// Package providers records how long the wire providers doing work when they are called took to
+// construct their services. It has no dependencies, so that any provider can use it.
+package providers
+
+import (
+	"sync"
+	"time"
+)
+
+// Timing is how long a provider took to construct its service.
+type Timing struct {
+	Name    string    `json:"name"`
+	Started time.Time `json:"started"`
+	// Duration in milliseconds.
+	Duration int64 `json:"duration"`
+}
+
+var (
+	mu      sync.Mutex
+	timings []Timing
+)
+
+// Track starts timing a provider and returns the function stopping it, to be deferred at the
+// start of the provider:
+//
+//	defer providers.Track("sqlstore")()
+func Track(name string) func() {
+	started := time.Now()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		timings = append(timings, Timing{Name: name, Started: started, Duration: time.Since(started).Milliseconds()})
+	}
+}
+
+// Timings returns the providers tracked so far in the order they finished.
+func Timings() []Timing {
+	mu.Lock()
+	defer mu.Unlock()
+	return append(make([]Timing, 0, len(timings)), timings...)
+}
@@ -0,0 +1,23 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrack(t *testing.T) {
+	before := len(Timings())
+
+	func() {
+		defer Track("slow")()
+		time.Sleep(10 * time.Millisecond)
+	}()
+
+	timings := Timings()
+	require.Len(t, timings, before+1)
+	timing := timings[len(timings)-1]
+	require.Equal(t, "slow", timing.Name)
+	require.GreaterOrEqual(t, timing.Duration, int64(10))
+}
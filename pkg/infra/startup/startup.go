@@ -0,0 +1,159 @@
+// Package startup records how long the phases of the server startup and the initialization of
+// every background service took, to find the services slowing down cold starts.
+package startup
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/startup/providers"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// processStarted approximates the start of the process, package variables are initialized before
+// main runs.
+var processStarted = time.Now()
+
+// Phase is a step of the server startup run before the background services are started.
+type Phase struct {
+	Name    string    `json:"name"`
+	Started time.Time `json:"started"`
+	// Duration in milliseconds.
+	Duration int64 `json:"duration"`
+}
+
+// Service describes the startup of a background service.
+type Service struct {
+	Name string `json:"name"`
+	// Dependencies are the names of the services which had to be started before this service.
+	Dependencies []string `json:"dependencies"`
+	Disabled     bool     `json:"disabled"`
+	// Waited is how long the service waited for its dependencies to start, in milliseconds.
+	Waited int64 `json:"waited"`
+	// Init is how long the lazy initialization of the service took, in milliseconds. It is 0 for
+	// services which are initialized when they are constructed.
+	Init int64 `json:"init"`
+	// Started is when the service finished initializing and started running.
+	Started time.Time `json:"started,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Report lists the timing of the server startup.
+// swagger:model StartupReport
+type Report struct {
+	ProcessStarted time.Time `json:"processStarted"`
+	// Duration from the start of the process until the last background service started, in
+	// milliseconds.
+	Duration int64   `json:"duration"`
+	Phases   []Phase `json:"phases"`
+	// Providers are the wire providers which do work when they construct their service, like
+	// running the database migrations or loading the plugins, in the order they finished.
+	Providers []providers.Timing `json:"providers"`
+	Services  []Service          `json:"services"`
+}
+
+type Tracker struct {
+	mu       sync.Mutex
+	phases   []Phase
+	services []Service
+}
+
+func ProvideService(routeRegister routing.RouteRegister) *Tracker {
+	t := &Tracker{}
+	routeRegister.Get("/api/admin/diagnostics/startup", middleware.ReqGrafanaAdmin, routing.Wrap(t.getReportHandler))
+	return t
+}
+
+// ProcessStarted returns when the process started.
+func (t *Tracker) ProcessStarted() time.Time {
+	return processStarted
+}
+
+// RecordPhase records a startup phase which started at the given time and ends now.
+func (t *Tracker) RecordPhase(name string, started time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases = append(t.phases, Phase{Name: name, Started: started, Duration: time.Since(started).Milliseconds()})
+}
+
+// TrackPhase runs fn as a startup phase.
+func (t *Tracker) TrackPhase(name string, fn func() error) error {
+	started := time.Now()
+	defer t.RecordPhase(name, started)
+	return fn()
+}
+
+// RecordService records the startup of a background service.
+func (t *Tracker) RecordService(service Service) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.services = append(t.services, service)
+}
+
+// GetReport returns the timing of the startup so far. Services are sorted by the time they
+// started, services not started yet come last.
+func (t *Tracker) GetReport() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := Report{
+		ProcessStarted: processStarted,
+		Phases:         append(make([]Phase, 0, len(t.phases)), t.phases...),
+		Providers:      providers.Timings(),
+		Services:       make([]Service, 0, len(t.services)),
+	}
+	last := processStarted
+	for _, phase := range t.phases {
+		if end := phase.Started.Add(time.Duration(phase.Duration) * time.Millisecond); end.After(last) {
+			last = end
+		}
+	}
+	for _, service := range t.services {
+		report.Services = append(report.Services, service)
+		if service.Started.After(last) {
+			last = service.Started
+		}
+	}
+	report.Duration = last.Sub(processStarted).Milliseconds()
+
+	sort.SliceStable(report.Services, func(i, j int) bool {
+		a, b := report.Services[i], report.Services[j]
+		if a.Started.IsZero() != b.Started.IsZero() {
+			return b.Started.IsZero()
+		}
+		if !a.Started.Equal(b.Started) {
+			return a.Started.Before(b.Started)
+		}
+		return a.Name < b.Name
+	})
+	return report
+}
+
+// swagger:route GET /admin/diagnostics/startup admin getStartupReport
+//
+// Get the timing of the server startup.
+//
+// Lists how long the phases of the startup of the server took, and for every background service
+// how long it waited for the services it depends on and how long its initialization took.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getStartupReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (t *Tracker) getReportHandler(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, t.GetReport())
+}
+
+// swagger:response getStartupReportResponse
+type GetStartupReportResponse struct {
+	// in:body
+	Body Report `json:"body"`
+}
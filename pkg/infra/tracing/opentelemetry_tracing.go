@@ -268,6 +268,7 @@ func (ots *Opentelemetry) Start(ctx context.Context, spanName string, opts ...tr
 	if traceID := span.SpanContext().TraceID(); traceID.IsValid() {
 		ctx = context.WithValue(ctx, traceKey{}, traceValue{traceID.String(), span.SpanContext().IsSampled()})
 	}
+	SetOrgIDAttribute(ctx, opentelemetrySpan)
 
 	return ctx, opentelemetrySpan
 }
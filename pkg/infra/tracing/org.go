@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// OrgIDKey is the key of the organization in spans and log lines.
+const OrgIDKey = "orgId"
+
+type orgKey struct{}
+
+// ContextWithOrgID returns a context whose spans and contextual log lines are tagged with the
+// organization of a request. orgID is the value identifying the organization in logs and traces,
+// as returned by setting.Cfg.OrgIDLogValue.
+func ContextWithOrgID(ctx context.Context, orgID interface{}) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// OrgIDFromContext returns the organization set with ContextWithOrgID.
+func OrgIDFromContext(ctx context.Context) (interface{}, bool) {
+	orgID := ctx.Value(orgKey{})
+	return orgID, orgID != nil
+}
+
+// SetOrgIDAttribute tags the span with the organization of the context, if any.
+func SetOrgIDAttribute(ctx context.Context, span Span) {
+	orgID, ok := OrgIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	kv := attribute.String(OrgIDKey, fmt.Sprint(orgID))
+	if id, ok := orgID.(int64); ok {
+		kv = attribute.Int64(OrgIDKey, id)
+	}
+	span.SetAttributes(OrgIDKey, orgID, kv)
+}
@@ -91,6 +91,13 @@ func ProvideService(cfg *setting.Cfg) (Tracer, error) {
 
 		return nil, false
 	})
+	log.RegisterContextualLogProvider(func(ctx context.Context) ([]interface{}, bool) {
+		if orgID, ok := OrgIDFromContext(ctx); ok {
+			return []interface{}{OrgIDKey, orgID}, true
+		}
+
+		return nil, false
+	})
 
 	if ts.enabled {
 		return ts, ts.initJaegerGlobalTracer()
@@ -259,6 +266,7 @@ func (ts *Opentracing) Start(ctx context.Context, spanName string, opts ...trace
 	if sctx, ok := span.Context().(jaeger.SpanContext); ok {
 		ctx = context.WithValue(ctx, traceKey{}, traceValue{sctx.TraceID().String(), sctx.IsSampled()})
 	}
+	SetOrgIDAttribute(ctx, opentracingSpan)
 	return ctx, opentracingSpan
 }
 
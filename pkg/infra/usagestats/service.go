@@ -2,6 +2,7 @@ package usagestats
 
 import (
 	"context"
+	"time"
 )
 
 type Report struct {
@@ -25,3 +26,73 @@ type Service interface {
 	RegisterSendReportCallback(SendReportCallbackFunc)
 	ShouldBeReported(context.Context, string) bool
 }
+
+// UsageKind identifies a category of usage tracked per user.
+type UsageKind string
+
+const (
+	UsageKindAPIRequest UsageKind = "apiRequests"
+	UsageKindQuery      UsageKind = "queries"
+)
+
+// UserUsage holds the usage counters of a single user within an organization.
+type UserUsage struct {
+	APIRequests int64 `json:"apiRequests"`
+	Queries     int64 `json:"queries"`
+}
+
+// UsageWindow is the usage of a set of users since the start of the
+// current tracking window.
+type UsageWindow struct {
+	Since time.Time           `json:"since"`
+	Users map[int64]UserUsage `json:"users"`
+}
+
+// UserUsageTracker records API and query usage per user so that it can be
+// aggregated through team membership and charged back to teams.
+type UserUsageTracker interface {
+	RecordUserUsage(orgID, userID int64, kind UsageKind)
+	GetUsersUsage(orgID int64, userIDs []int64) UsageWindow
+}
+
+// DataSourceQuery is a query request sent to a data source.
+type DataSourceQuery struct {
+	OrgID          int64
+	DataSourceUID  string
+	DataSourceType string
+	// DashboardUID is empty if the query was not made from a dashboard.
+	DashboardUID string
+	Duration     time.Duration
+	Failed       bool
+}
+
+// QueryUsage holds the query counters of a data source.
+type QueryUsage struct {
+	Queries          int64   `json:"queries"`
+	Errors           int64   `json:"errors"`
+	ErrorRate        float64 `json:"errorRate"`
+	AvgLatencyMillis float64 `json:"avgLatencyMs"`
+	MaxLatencyMillis float64 `json:"maxLatencyMs"`
+}
+
+// DataSourceUsage is the query usage of a data source, in total and per dashboard.
+type DataSourceUsage struct {
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+	QueryUsage
+	Dashboards map[string]QueryUsage `json:"dashboards"`
+}
+
+// DataSourceUsageWindow is the query usage of the data sources of an
+// organization since the start of the current tracking window.
+type DataSourceUsageWindow struct {
+	Since       time.Time         `json:"since"`
+	DataSources []DataSourceUsage `json:"datasources"`
+}
+
+// DataSourceUsageTracker records the volume, errors and latency of data source
+// queries per data source and dashboard for capacity planning.
+type DataSourceUsageTracker interface {
+	RecordDataSourceQuery(query DataSourceQuery)
+	GetDataSourcesUsage(orgID int64) DataSourceUsageWindow
+}
@@ -15,6 +15,7 @@ func (uss *UsageStats) registerAPIEndpoints() {
 	uss.RouteRegister.Group(rootUrl, func(subrouter routing.RouteRegister) {
 		subrouter.Get("/usage-report-preview", middleware.ReqGrafanaAdmin, routing.Wrap(uss.getUsageReportPreview))
 	})
+	uss.RouteRegister.Get("/api/usage/datasources", middleware.ReqOrgAdmin, routing.Wrap(uss.getDataSourcesUsage))
 }
 
 func (uss *UsageStats) getUsageReportPreview(ctx *models.ReqContext) response.Response {
@@ -25,3 +26,7 @@ func (uss *UsageStats) getUsageReportPreview(ctx *models.ReqContext) response.Re
 
 	return response.JSON(http.StatusOK, usageReport)
 }
+
+func (uss *UsageStats) getDataSourcesUsage(ctx *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, uss.GetDataSourcesUsage(ctx.OrgID))
+}
@@ -47,7 +47,6 @@ func TestApi_getUsageStats(t *testing.T) {
 	}
 	sqlStore := mockstore.NewSQLStoreMock()
 	uss := createService(t, setting.Cfg{}, sqlStore, false)
-	uss.registerAPIEndpoints()
 
 	sqlStore.ExpectedSystemStats = &models.SystemStats{}
 	sqlStore.ExpectedDataSourceStats = []*models.DataSourceStats{}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+)
+
+var (
+	dataSourceQueriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      "datasource_queries_total",
+			Help:      "A counter for query requests sent to a data source",
+		},
+		[]string{"datasource_uid", "datasource_type", "status"},
+	)
+
+	dataSourceQueryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Name:      "datasource_query_duration_seconds",
+			Help:      "histogram of durations of query requests sent to a data source",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 25, 50, 100},
+		},
+		[]string{"datasource_uid", "datasource_type"},
+	)
+)
+
+type queryCounters struct {
+	queries       int64
+	errors        int64
+	totalDuration time.Duration
+	maxDuration   time.Duration
+}
+
+func (c *queryCounters) add(query usagestats.DataSourceQuery) {
+	c.queries++
+	if query.Failed {
+		c.errors++
+	}
+	c.totalDuration += query.Duration
+	if query.Duration > c.maxDuration {
+		c.maxDuration = query.Duration
+	}
+}
+
+func (c *queryCounters) usage() usagestats.QueryUsage {
+	usage := usagestats.QueryUsage{
+		Queries:          c.queries,
+		Errors:           c.errors,
+		MaxLatencyMillis: float64(c.maxDuration) / float64(time.Millisecond),
+	}
+	if c.queries > 0 {
+		usage.ErrorRate = float64(c.errors) / float64(c.queries)
+		usage.AvgLatencyMillis = float64(c.totalDuration) / float64(c.queries) / float64(time.Millisecond)
+	}
+	return usage
+}
+
+type dataSourceUsageKey struct {
+	orgID int64
+	uid   string
+}
+
+type dataSourceCounters struct {
+	dsType     string
+	total      queryCounters
+	dashboards map[string]*queryCounters
+}
+
+// dataSourceUsageCounters keeps the in-memory query counters of the current
+// tracking window. The window is restarted every time a usage report is sent.
+type dataSourceUsageCounters struct {
+	mu       sync.RWMutex
+	since    time.Time
+	counters map[dataSourceUsageKey]*dataSourceCounters
+}
+
+func newDataSourceUsageCounters() *dataSourceUsageCounters {
+	return &dataSourceUsageCounters{
+		since:    time.Now(),
+		counters: make(map[dataSourceUsageKey]*dataSourceCounters),
+	}
+}
+
+func (c *dataSourceUsageCounters) record(query usagestats.DataSourceQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := dataSourceUsageKey{orgID: query.OrgID, uid: query.DataSourceUID}
+	counters, ok := c.counters[key]
+	if !ok {
+		counters = &dataSourceCounters{dashboards: make(map[string]*queryCounters)}
+		c.counters[key] = counters
+	}
+	counters.dsType = query.DataSourceType
+	counters.total.add(query)
+
+	if query.DashboardUID == "" {
+		return
+	}
+	dashboard, ok := counters.dashboards[query.DashboardUID]
+	if !ok {
+		dashboard = &queryCounters{}
+		counters.dashboards[query.DashboardUID] = dashboard
+	}
+	dashboard.add(query)
+}
+
+func (c *dataSourceUsageCounters) get(orgID int64) usagestats.DataSourceUsageWindow {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	window := usagestats.DataSourceUsageWindow{
+		Since:       c.since,
+		DataSources: make([]usagestats.DataSourceUsage, 0),
+	}
+	for key, counters := range c.counters {
+		if key.orgID != orgID {
+			continue
+		}
+		usage := usagestats.DataSourceUsage{
+			UID:        key.uid,
+			Type:       counters.dsType,
+			QueryUsage: counters.total.usage(),
+			Dashboards: make(map[string]usagestats.QueryUsage, len(counters.dashboards)),
+		}
+		for dashboardUID, dashboard := range counters.dashboards {
+			usage.Dashboards[dashboardUID] = dashboard.usage()
+		}
+		window.DataSources = append(window.DataSources, usage)
+	}
+
+	// the busiest data sources first
+	sort.Slice(window.DataSources, func(i, j int) bool {
+		a, b := window.DataSources[i], window.DataSources[j]
+		if a.Queries != b.Queries {
+			return a.Queries > b.Queries
+		}
+		return a.UID < b.UID
+	})
+	return window
+}
+
+func (c *dataSourceUsageCounters) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.since = time.Now()
+	c.counters = make(map[dataSourceUsageKey]*dataSourceCounters)
+}
+
+// RecordDataSourceQuery records a query request sent to a data source.
+func (uss *UsageStats) RecordDataSourceQuery(query usagestats.DataSourceQuery) {
+	status := "success"
+	if query.Failed {
+		status = "error"
+	}
+	dataSourceQueriesTotal.WithLabelValues(query.DataSourceUID, query.DataSourceType, status).Inc()
+	dataSourceQueryDuration.WithLabelValues(query.DataSourceUID, query.DataSourceType).Observe(query.Duration.Seconds())
+	metrics.ObserveOrgDataSourceQuery(query.OrgID, query.Duration)
+
+	if uss.dataSourceUsage == nil {
+		return
+	}
+	uss.dataSourceUsage.record(query)
+}
+
+// GetDataSourcesUsage returns the query usage of the data sources of an organization in the current tracking window.
+func (uss *UsageStats) GetDataSourcesUsage(orgID int64) usagestats.DataSourceUsageWindow {
+	if uss.dataSourceUsage == nil {
+		return usagestats.DataSourceUsageWindow{DataSources: []usagestats.DataSourceUsage{}}
+	}
+	return uss.dataSourceUsage.get(orgID)
+}
+
+var _ usagestats.DataSourceUsageTracker = (*UsageStats)(nil)
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+)
+
+func TestDataSourceUsage(t *testing.T) {
+	uss := &UsageStats{dataSourceUsage: newDataSourceUsageCounters()}
+
+	uss.RecordDataSourceQuery(usagestats.DataSourceQuery{OrgID: 1, DataSourceUID: "prom", DataSourceType: "prometheus", DashboardUID: "dash", Duration: 100 * time.Millisecond})
+	uss.RecordDataSourceQuery(usagestats.DataSourceQuery{OrgID: 1, DataSourceUID: "prom", DataSourceType: "prometheus", DashboardUID: "dash", Duration: 300 * time.Millisecond, Failed: true})
+	uss.RecordDataSourceQuery(usagestats.DataSourceQuery{OrgID: 1, DataSourceUID: "prom", DataSourceType: "prometheus", Duration: 200 * time.Millisecond})
+	uss.RecordDataSourceQuery(usagestats.DataSourceQuery{OrgID: 1, DataSourceUID: "loki", DataSourceType: "loki", Duration: 50 * time.Millisecond})
+	uss.RecordDataSourceQuery(usagestats.DataSourceQuery{OrgID: 2, DataSourceUID: "prom", DataSourceType: "prometheus", Duration: 50 * time.Millisecond})
+
+	t.Run("usage is aggregated per data source and dashboard", func(t *testing.T) {
+		window := uss.GetDataSourcesUsage(1)
+		assert.False(t, window.Since.IsZero())
+		require.Len(t, window.DataSources, 2)
+
+		prom := window.DataSources[0]
+		assert.Equal(t, "prom", prom.UID)
+		assert.Equal(t, "prometheus", prom.Type)
+		assert.Equal(t, usagestats.QueryUsage{
+			Queries:          3,
+			Errors:           1,
+			ErrorRate:        1.0 / 3,
+			AvgLatencyMillis: 200,
+			MaxLatencyMillis: 300,
+		}, prom.QueryUsage)
+		assert.Equal(t, map[string]usagestats.QueryUsage{
+			"dash": {Queries: 2, Errors: 1, ErrorRate: 0.5, AvgLatencyMillis: 200, MaxLatencyMillis: 300},
+		}, prom.Dashboards)
+
+		assert.Equal(t, "loki", window.DataSources[1].UID)
+		assert.Empty(t, window.DataSources[1].Dashboards)
+	})
+
+	t.Run("reset starts a new window", func(t *testing.T) {
+		uss.dataSourceUsage.reset()
+		assert.Empty(t, uss.GetDataSourcesUsage(1).DataSources)
+	})
+
+	t.Run("service without counters reports no usage", func(t *testing.T) {
+		empty := &UsageStats{}
+		empty.RecordDataSourceQuery(usagestats.DataSourceQuery{OrgID: 1, DataSourceUID: "prom"})
+		assert.Empty(t, empty.GetDataSourcesUsage(1).DataSources)
+	})
+}
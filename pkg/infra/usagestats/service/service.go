@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
@@ -14,26 +15,34 @@ import (
 )
 
 type UsageStats struct {
-	Cfg           *setting.Cfg
-	kvStore       *kvstore.NamespacedKVStore
-	RouteRegister routing.RouteRegister
-	pluginStore   plugins.Store
+	Cfg            *setting.Cfg
+	kvStore        *kvstore.NamespacedKVStore
+	RouteRegister  routing.RouteRegister
+	pluginStore    plugins.Store
+	leaderElection leaderelection.Service
 
 	log    log.Logger
 	tracer tracing.Tracer
 
 	externalMetrics     []usagestats.MetricsFunc
 	sendReportCallbacks []usagestats.SendReportCallbackFunc
+
+	userUsage       *userUsageCounters
+	dataSourceUsage *dataSourceUsageCounters
 }
 
-func ProvideService(cfg *setting.Cfg, pluginStore plugins.Store, kvStore kvstore.KVStore, routeRegister routing.RouteRegister, tracer tracing.Tracer) *UsageStats {
+func ProvideService(cfg *setting.Cfg, pluginStore plugins.Store, kvStore kvstore.KVStore, routeRegister routing.RouteRegister, tracer tracing.Tracer,
+	leaderElection leaderelection.Service) *UsageStats {
 	s := &UsageStats{
-		Cfg:           cfg,
-		RouteRegister: routeRegister,
-		pluginStore:   pluginStore,
-		kvStore:       kvstore.WithNamespace(kvStore, 0, "infra.usagestats"),
-		log:           log.New("infra.usagestats"),
-		tracer:        tracer,
+		Cfg:             cfg,
+		RouteRegister:   routeRegister,
+		pluginStore:     pluginStore,
+		kvStore:         kvstore.WithNamespace(kvStore, 0, "infra.usagestats"),
+		log:             log.New("infra.usagestats"),
+		tracer:          tracer,
+		leaderElection:  leaderElection,
+		userUsage:       newUserUsageCounters(),
+		dataSourceUsage: newDataSourceUsageCounters(),
 	}
 
 	s.registerAPIEndpoints()
@@ -68,6 +77,17 @@ func (uss *UsageStats) Run(ctx context.Context) error {
 	for {
 		select {
 		case <-sendReportTicker.C:
+			if nextSendInterval != sendInterval {
+				nextSendInterval = sendInterval
+				sendReportTicker.Reset(nextSendInterval)
+			}
+
+			// Only one instance of a cluster sends the report, the other instances keep their counters.
+			if !uss.leaderElection.IsLeader(ctx, leaderelection.LeaseUsageStatsReport) {
+				uss.log.Debug("Not leader, skipping sending usage stats")
+				continue
+			}
+
 			if traceID, err := uss.sendUsageStats(ctx); err != nil {
 				uss.log.Warn("Failed to send usage stats", "error", err, "traceID", traceID)
 			}
@@ -77,14 +97,16 @@ func (uss *UsageStats) Run(ctx context.Context) error {
 				uss.log.Warn("Failed to update last sent time", "error", err)
 			}
 
-			if nextSendInterval != sendInterval {
-				nextSendInterval = sendInterval
-				sendReportTicker.Reset(nextSendInterval)
-			}
-
 			for _, callback := range uss.sendReportCallbacks {
 				callback()
 			}
+
+			if uss.userUsage != nil {
+				uss.userUsage.reset()
+			}
+			if uss.dataSourceUsage != nil {
+				uss.dataSourceUsage.reset()
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
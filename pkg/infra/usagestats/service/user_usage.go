@@ -0,0 +1,90 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+)
+
+type userUsageKey struct {
+	orgID  int64
+	userID int64
+}
+
+// userUsageCounters keeps the in-memory usage counters of the current
+// tracking window. The window is restarted every time a usage report is sent.
+type userUsageCounters struct {
+	mu       sync.RWMutex
+	since    time.Time
+	counters map[userUsageKey]*usagestats.UserUsage
+}
+
+func newUserUsageCounters() *userUsageCounters {
+	return &userUsageCounters{
+		since:    time.Now(),
+		counters: make(map[userUsageKey]*usagestats.UserUsage),
+	}
+}
+
+func (c *userUsageCounters) record(orgID, userID int64, kind usagestats.UsageKind) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := userUsageKey{orgID: orgID, userID: userID}
+	usage, ok := c.counters[key]
+	if !ok {
+		usage = &usagestats.UserUsage{}
+		c.counters[key] = usage
+	}
+
+	switch kind {
+	case usagestats.UsageKindAPIRequest:
+		usage.APIRequests++
+	case usagestats.UsageKindQuery:
+		usage.Queries++
+	}
+}
+
+func (c *userUsageCounters) get(orgID int64, userIDs []int64) usagestats.UsageWindow {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	window := usagestats.UsageWindow{
+		Since: c.since,
+		Users: make(map[int64]usagestats.UserUsage, len(userIDs)),
+	}
+	for _, userID := range userIDs {
+		if usage, ok := c.counters[userUsageKey{orgID: orgID, userID: userID}]; ok {
+			window.Users[userID] = *usage
+		}
+	}
+
+	return window
+}
+
+func (c *userUsageCounters) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.since = time.Now()
+	c.counters = make(map[userUsageKey]*usagestats.UserUsage)
+}
+
+// RecordUserUsage increments the usage counter of the given kind for a user.
+func (uss *UsageStats) RecordUserUsage(orgID, userID int64, kind usagestats.UsageKind) {
+	if uss.userUsage == nil || userID <= 0 {
+		return
+	}
+	uss.userUsage.record(orgID, userID, kind)
+}
+
+// GetUsersUsage returns the usage of the given users in the current tracking window.
+func (uss *UsageStats) GetUsersUsage(orgID int64, userIDs []int64) usagestats.UsageWindow {
+	if uss.userUsage == nil {
+		return usagestats.UsageWindow{Users: map[int64]usagestats.UserUsage{}}
+	}
+	return uss.userUsage.get(orgID, userIDs)
+}
+
+var _ usagestats.UserUsageTracker = (*UsageStats)(nil)
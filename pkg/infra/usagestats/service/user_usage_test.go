@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+)
+
+func TestUserUsage(t *testing.T) {
+	uss := &UsageStats{userUsage: newUserUsageCounters()}
+
+	uss.RecordUserUsage(1, 10, usagestats.UsageKindAPIRequest)
+	uss.RecordUserUsage(1, 10, usagestats.UsageKindAPIRequest)
+	uss.RecordUserUsage(1, 10, usagestats.UsageKindQuery)
+	uss.RecordUserUsage(1, 11, usagestats.UsageKindAPIRequest)
+	uss.RecordUserUsage(2, 10, usagestats.UsageKindAPIRequest)
+	uss.RecordUserUsage(1, 0, usagestats.UsageKindAPIRequest)
+
+	t.Run("usage is scoped to the org and requested users", func(t *testing.T) {
+		window := uss.GetUsersUsage(1, []int64{10, 12})
+		require.Len(t, window.Users, 1)
+		assert.Equal(t, usagestats.UserUsage{APIRequests: 2, Queries: 1}, window.Users[10])
+		assert.False(t, window.Since.IsZero())
+	})
+
+	t.Run("reset starts a new window", func(t *testing.T) {
+		before := uss.GetUsersUsage(1, []int64{10}).Since
+		uss.userUsage.reset()
+
+		window := uss.GetUsersUsage(1, []int64{10, 11})
+		assert.Empty(t, window.Users)
+		assert.False(t, window.Since.Before(before))
+	})
+
+	t.Run("service without counters reports no usage", func(t *testing.T) {
+		empty := &UsageStats{}
+		empty.RecordUserUsage(1, 10, usagestats.UsageKindAPIRequest)
+		assert.Empty(t, empty.GetUsersUsage(1, []int64{10}).Users)
+	})
+}
@@ -345,6 +345,10 @@ func (s *Service) updateTotalStats(ctx context.Context) bool {
 
 	metrics.MStatTotalPublicDashboards.Set(float64(statsQuery.Result.PublicDashboards))
 
+	if metrics.OrgLabelsEnabled() {
+		s.updateOrgActiveUsers(ctx)
+	}
+
 	dsStats := models.GetDataSourceStatsQuery{}
 	if err := s.sqlstore.GetDataSourceStats(ctx, &dsStats); err != nil {
 		s.log.Error("Failed to get datasource stats", "error", err)
@@ -357,6 +361,20 @@ func (s *Service) updateTotalStats(ctx context.Context) bool {
 	return true
 }
 
+func (s *Service) updateOrgActiveUsers(ctx context.Context) {
+	query := models.GetOrgActiveUsersStatsQuery{}
+	if err := s.sqlstore.GetOrgActiveUsersStats(ctx, &query); err != nil {
+		s.log.Error("Failed to get active users per org", "error", err)
+		return
+	}
+
+	activeUsers := make(map[int64]int64, len(query.Result))
+	for _, stats := range query.Result {
+		activeUsers[stats.OrgId] = stats.Count
+	}
+	metrics.SetOrgActiveUsers(activeUsers)
+}
+
 func (s *Service) appCount(ctx context.Context) int {
 	return len(s.plugins.Plugins(ctx, plugins.App))
 }
@@ -31,6 +31,7 @@ type SocialGenericOAuth struct {
 	idTokenAttributeName string
 	teamIdsAttributePath string
 	teamIds              []string
+	orgAttributePath     string
 }
 
 func (s *SocialGenericOAuth) IsTeamMember(client *http.Client) bool {
@@ -154,6 +155,16 @@ func (s *SocialGenericOAuth) UserInfo(client *http.Client, token *oauth2.Token)
 			}
 		}
 
+		if len(userInfo.OrgClaims) == 0 && s.orgAttributePath != "" {
+			claims, err := s.extractOrgClaims(data)
+			if err != nil {
+				s.log.Warn("Failed to extract organization claims", "err", err)
+			} else if len(claims) > 0 {
+				s.log.Debug("Setting user info organization claims from extracted claims")
+				userInfo.OrgClaims = claims
+			}
+		}
+
 		if len(userInfo.Groups) == 0 {
 			groups, err := s.extractGroups(data)
 			if err != nil {
@@ -366,6 +377,19 @@ func (s *SocialGenericOAuth) extractGroups(data *UserInfoJson) ([]string, error)
 	return s.searchJSONForStringArrayAttr(s.groupsAttributePath, data.rawJSON)
 }
 
+// extractOrgClaims returns the claims found at the org attribute path, which is either a string,
+// e.g. a tenant, or an array of strings.
+func (s *SocialGenericOAuth) extractOrgClaims(data *UserInfoJson) ([]string, error) {
+	claim, err := s.searchJSONForStringAttr(s.orgAttributePath, data.rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	if claim != "" {
+		return []string{claim}, nil
+	}
+	return s.searchJSONForStringArrayAttr(s.orgAttributePath, data.rawJSON)
+}
+
 func (s *SocialGenericOAuth) FetchPrivateEmail(client *http.Client) (string, error) {
 	type Record struct {
 		Email       string `json:"email"`
@@ -0,0 +1,126 @@
+package social
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// orgMappingWildcard matches any claim, or the part of a claim after a prefix. As the organization
+// of a mapping it stands for the matched part of the claim.
+const orgMappingWildcard = "*"
+
+// OrgMapping maps a claim of the users, e.g. a group or a tenant, to an organization.
+type OrgMapping struct {
+	// Claim is matched against the claims of the users. It matches any claim if it is "*", and the
+	// claims starting with the prefix if it ends with "*".
+	Claim string
+	// Org is the ID or the name of the organization, or "*" for the organization named like the part
+	// of the claim matched by the wildcard.
+	Org string
+	// Role is the role of the users in the organization. The role of the users from the role
+	// attribute path, or the default role, is used if it is empty.
+	Role org.RoleType
+}
+
+// OrgMembership is an organization the claims of a user map to. Either the ID or the name of the
+// organization is set.
+type OrgMembership struct {
+	OrgID   int64
+	OrgName string
+	Role    org.RoleType
+}
+
+// ParseOrgMapping parses comma separated mappings of the form claim:org[:role]. Claims may contain
+// colons, organization names may contain spaces.
+func ParseOrgMapping(value string) ([]OrgMapping, error) {
+	var mappings []OrgMapping
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		mapping := OrgMapping{}
+		if i := strings.LastIndex(entry, ":"); i > 0 {
+			if role := org.RoleType(cases.Title(language.Und).String(strings.TrimSpace(entry[i+1:]))); role.IsValid() {
+				mapping.Role = role
+				entry = entry[:i]
+			}
+		}
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 || i == len(entry)-1 {
+			return nil, fmt.Errorf("invalid organization mapping %q, expected claim:org[:role]", entry)
+		}
+		mapping.Claim = strings.TrimSpace(entry[:i])
+		mapping.Org = strings.TrimSpace(entry[i+1:])
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// MapOrgs returns the organizations the claims map to. If several mappings grant a role in the same
+// organization, the highest role is kept. Mappings without a role grant the default role.
+func MapOrgs(mappings []OrgMapping, claims []string, defaultRole org.RoleType) []OrgMembership {
+	var memberships []OrgMembership
+	byOrg := map[string]int{}
+	for _, mapping := range mappings {
+		role := mapping.Role
+		if role == "" {
+			role = defaultRole
+		}
+		if !role.IsValid() {
+			continue
+		}
+
+		for _, claim := range mapping.match(claims) {
+			membership := OrgMembership{Role: role, OrgName: mapping.Org}
+			if mapping.Org == orgMappingWildcard {
+				membership.OrgName = claim
+			} else if id, err := strconv.ParseInt(mapping.Org, 10, 64); err == nil {
+				membership.OrgID, membership.OrgName = id, ""
+			}
+
+			key := strings.ToLower(membership.OrgName)
+			if membership.OrgID != 0 {
+				key = strconv.FormatInt(membership.OrgID, 10)
+			}
+			if i, ok := byOrg[key]; ok {
+				if !memberships[i].Role.Includes(role) {
+					memberships[i].Role = role
+				}
+				continue
+			}
+			byOrg[key] = len(memberships)
+			memberships = append(memberships, membership)
+		}
+	}
+	return memberships
+}
+
+// match returns the claims the mapping matches, or the part matched by the wildcard of the claim
+// of the mapping.
+func (m OrgMapping) match(claims []string) []string {
+	var matched []string
+	if m.Claim == orgMappingWildcard && m.Org != orgMappingWildcard {
+		// every user is mapped to the organization
+		return []string{m.Claim}
+	}
+
+	wildcard := strings.HasSuffix(m.Claim, orgMappingWildcard)
+	prefix := strings.TrimSuffix(m.Claim, orgMappingWildcard)
+	for _, claim := range claims {
+		switch {
+		case wildcard && strings.HasPrefix(claim, prefix) && len(claim) > len(prefix):
+			matched = append(matched, claim[len(prefix):])
+		case !wildcard && claim == m.Claim:
+			matched = append(matched, claim)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,72 @@
+package social
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestParseOrgMapping(t *testing.T) {
+	t.Run("Should parse the mappings with and without roles", func(t *testing.T) {
+		mappings, err := ParseOrgMapping("admins:1:admin, tenant-*:*, urn:acme:group:Acme Corp:Editor,*:Main Org.")
+		require.NoError(t, err)
+		assert.Equal(t, []OrgMapping{
+			{Claim: "admins", Org: "1", Role: org.RoleAdmin},
+			{Claim: "tenant-*", Org: "*"},
+			{Claim: "urn:acme:group", Org: "Acme Corp", Role: org.RoleEditor},
+			{Claim: "*", Org: "Main Org."},
+		}, mappings)
+	})
+
+	t.Run("Should not parse an empty mapping", func(t *testing.T) {
+		mappings, err := ParseOrgMapping(" ")
+		require.NoError(t, err)
+		assert.Empty(t, mappings)
+	})
+
+	t.Run("Should fail without organization", func(t *testing.T) {
+		for _, value := range []string{"admins", "admins:Admin", ":Acme", "admins:"} {
+			_, err := ParseOrgMapping(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+func TestMapOrgs(t *testing.T) {
+	mappings := []OrgMapping{
+		{Claim: "*", Org: "1", Role: org.RoleViewer},
+		{Claim: "admins", Org: "1", Role: org.RoleAdmin},
+		{Claim: "tenant-*", Org: "*"},
+		{Claim: "acme", Org: "*", Role: org.RoleEditor},
+		{Claim: "acme-editors", Org: "Acme", Role: org.RoleEditor},
+	}
+
+	t.Run("Should map every user to the organizations of the wildcard", func(t *testing.T) {
+		assert.Equal(t, []OrgMembership{{OrgID: 1, Role: org.RoleViewer}}, MapOrgs(mappings, nil, org.RoleViewer))
+	})
+
+	t.Run("Should keep the highest role in an organization", func(t *testing.T) {
+		memberships := MapOrgs(mappings, []string{"admins", "acme", "acme-editors"}, org.RoleViewer)
+		assert.Equal(t, []OrgMembership{
+			{OrgID: 1, Role: org.RoleAdmin},
+			{OrgName: "acme", Role: org.RoleEditor},
+		}, memberships)
+	})
+
+	t.Run("Should map to the organizations named after the claims with the default role", func(t *testing.T) {
+		memberships := MapOrgs(mappings, []string{"tenant-globex", "tenant-initech", "tenant-"}, org.RoleEditor)
+		assert.Equal(t, []OrgMembership{
+			{OrgID: 1, Role: org.RoleViewer},
+			{OrgName: "globex", Role: org.RoleEditor},
+			{OrgName: "initech", Role: org.RoleEditor},
+		}, memberships)
+	})
+
+	t.Run("Should skip mappings without a valid role", func(t *testing.T) {
+		memberships := MapOrgs(mappings, []string{"tenant-globex"}, "")
+		assert.Equal(t, []OrgMembership{{OrgID: 1, Role: org.RoleViewer}}, memberships)
+	})
+}
@@ -57,6 +57,13 @@ type OAuthInfo struct {
 	TlsClientCa             string
 	TlsSkipVerify           bool
 	UsePKCE                 bool
+	// OrgAttributePath extracts the claims mapped to organizations, the groups of the users are
+	// mapped if it is empty.
+	OrgAttributePath string
+	OrgMapping       []OrgMapping
+	// OrgAutoCreate creates the organizations of the mapping which don't exist when the first user
+	// mapped to them logs in.
+	OrgAutoCreate bool
 }
 
 func ProvideService(cfg *setting.Cfg, features *featuremgmt.FeatureManager) *SocialService {
@@ -95,8 +102,16 @@ func ProvideService(cfg *setting.Cfg, features *featuremgmt.FeatureManager) *Soc
 			TlsSkipVerify:           sec.Key("tls_skip_verify_insecure").MustBool(),
 			UsePKCE:                 sec.Key("use_pkce").MustBool(),
 			AllowAssignGrafanaAdmin: sec.Key("allow_assign_grafana_admin").MustBool(false),
+			OrgAttributePath:        sec.Key("org_attribute_path").String(),
+			OrgAutoCreate:           sec.Key("org_auto_create").MustBool(false),
 		}
 
+		orgMapping, err := ParseOrgMapping(sec.Key("org_mapping").String())
+		if err != nil {
+			logger.Error("Invalid organization mapping, users are not mapped to organizations", "provider", name, "error", err)
+		}
+		info.OrgMapping = orgMapping
+
 		// when empty_scopes parameter exists and is true, overwrite scope with empty value
 		if sec.Key("empty_scopes").MustBool() {
 			info.Scopes = []string{}
@@ -198,6 +213,7 @@ func ProvideService(cfg *setting.Cfg, features *featuremgmt.FeatureManager) *Soc
 				teamIdsAttributePath: sec.Key("team_ids_attribute_path").String(),
 				teamIds:              sec.Key("team_ids").Strings(","),
 				allowedOrganizations: util.SplitString(sec.Key("allowed_organizations").String()),
+				orgAttributePath:     info.OrgAttributePath,
 			}
 		}
 
@@ -232,6 +248,8 @@ type BasicUserInfo struct {
 	Role           org.RoleType
 	IsGrafanaAdmin *bool // nil will avoid overriding user's set server admin setting
 	Groups         []string
+	// OrgClaims are mapped to organizations instead of the groups if set.
+	OrgClaims []string
 }
 
 func (b *BasicUserInfo) String() string {
@@ -11,18 +11,16 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-// ContentSecurityPolicy sets the configured Content-Security-Policy and/or Content-Security-Policy-Report-Only header(s) in the response.
-func ContentSecurityPolicy(cfg *setting.Cfg, logger log.Logger) func(http.Handler) http.Handler {
+// ContentSecurityPolicy sets the Content-Security-Policy and/or Content-Security-Policy-Report-Only header(s) of the
+// security headers of the request in the response. The nonce of the request is generated even if both are disabled,
+// so that the headers can be enabled at runtime.
+func ContentSecurityPolicy(cfg *setting.Cfg, securityHeaders securityheaders.Service, logger log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		if cfg.CSPEnabled {
-			next = cspMiddleware(cfg, next, logger)
-		}
-		if cfg.CSPReportOnlyEnabled {
-			next = cspReportOnlyMiddleware(cfg, next, logger)
-		}
+		next = cspMiddleware(cfg, securityHeaders, next)
 		next = nonceMiddleware(next, logger)
 		return next
 	}
@@ -42,22 +40,58 @@ func nonceMiddleware(next http.Handler, logger log.Logger) http.Handler {
 	})
 }
 
-func cspMiddleware(cfg *setting.Cfg, next http.Handler, logger log.Logger) http.Handler {
+func cspMiddleware(cfg *setting.Cfg, securityHeaders securityheaders.Service, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		ctx := contexthandler.FromContext(req.Context())
-		policy := replacePolicyVariables(cfg.CSPTemplate, cfg.AppURL, ctx.RequestNonce)
-		rw.Header().Set("Content-Security-Policy", policy)
+		var orgID int64
+		if ctx.SignedInUser != nil {
+			orgID = ctx.OrgID
+		}
+		headers := securityHeaders.GetHeaders(req.Context(), orgID)
+
+		switch {
+		case headers.CSPEnabled:
+			policy := replacePolicyVariables(headers.CSPTemplate, cfg.AppURL, ctx.RequestNonce)
+			rw.Header().Set("Content-Security-Policy", withFrameAncestors(policy, headers.FrameAncestors))
+		case len(headers.FrameAncestors) > 0:
+			rw.Header().Set("Content-Security-Policy", withFrameAncestors("", headers.FrameAncestors))
+		}
+
+		if headers.CSPReportOnlyEnabled {
+			policy := replacePolicyVariables(headers.CSPReportOnlyTemplate, cfg.AppURL, ctx.RequestNonce)
+			rw.Header().Set("Content-Security-Policy-Report-Only", policy)
+		}
 		next.ServeHTTP(rw, req)
 	})
 }
 
-func cspReportOnlyMiddleware(cfg *setting.Cfg, next http.Handler, logger log.Logger) http.Handler {
-	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		ctx := contexthandler.FromContext(req.Context())
-		policy := replacePolicyVariables(cfg.CSPReportOnlyTemplate, cfg.AppURL, ctx.RequestNonce)
-		rw.Header().Set("Content-Security-Policy-Report-Only", policy)
-		next.ServeHTTP(rw, req)
-	})
+// withFrameAncestors replaces the frame-ancestors directive of a policy, or adds it if the policy has none.
+func withFrameAncestors(policy string, sources []string) string {
+	if len(sources) == 0 {
+		return policy
+	}
+
+	directive := "frame-ancestors " + strings.Join(sources, " ")
+	directives := make([]string, 0)
+	replaced := false
+	for _, d := range strings.Split(policy, ";") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		if name := strings.Fields(d)[0]; strings.EqualFold(name, "frame-ancestors") {
+			if replaced {
+				continue
+			}
+			d = directive
+			replaced = true
+		}
+		directives = append(directives, d)
+	}
+	if !replaced {
+		directives = append(directives, directive)
+	}
+	return strings.Join(directives, ";") + ";"
 }
 
 func replacePolicyVariables(policyTemplate, appURL, nonce string) string {
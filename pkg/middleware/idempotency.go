@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyResponseTTL    = 24 * time.Hour
+	idempotencyInProgressTTL  = time.Minute
+	idempotencyMaxCachedBytes = 1 << 20
+)
+
+func init() {
+	remotecache.Register(&idempotentResponse{})
+}
+
+// idempotentResponse is the response to a request with an idempotency key, or a marker for the
+// request being in progress.
+type idempotentResponse struct {
+	RequestHash string
+	InProgress  bool
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Idempotency returns a named middleware making the given routes idempotent for requests with an
+// Idempotency-Key header, so clients can safely retry requests creating resources. The first
+// successful response to a key is cached for a day in the remote cache and replayed for retries
+// with the same key. Keys are scoped to the signed in user, and reusing a key for a different
+// request is rejected.
+func Idempotency(cache *remotecache.RemoteCache, routes map[string]bool) func(string) web.Handler {
+	logger := log.New("middleware.idempotency")
+	// inProgress guards against concurrent requests with the same key on this instance, the
+	// in progress marker in the remote cache against concurrent requests on other instances.
+	var inProgress sync.Map
+
+	return func(pattern string) web.Handler {
+		if !routes[pattern] {
+			return web.Middleware(func(next http.Handler) http.Handler { return next })
+		}
+
+		return web.Middleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				key := r.Header.Get(IdempotencyKeyHeader)
+				c := contexthandler.FromContext(r.Context())
+				if key == "" || c == nil || !c.IsSignedIn || !isMutatingMethod(r.Method) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				if len(key) > idempotencyKeyMaxLength {
+					c.JsonApiErr(http.StatusBadRequest, fmt.Sprintf("%s header must not be longer than %d characters", IdempotencyKeyHeader, idempotencyKeyMaxLength), nil)
+					return
+				}
+
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					c.JsonApiErr(http.StatusBadRequest, "Failed to read request body", err)
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+
+				ctx := r.Context()
+				cacheKey := fmt.Sprintf("idempotency-%x", sha256.Sum256([]byte(fmt.Sprintf("%d/%d/%d/%s", c.OrgID, c.UserID, c.ApiKeyID, key))))
+				requestHash := fmt.Sprintf("%x", sha256.Sum256(append([]byte(r.Method+" "+r.URL.RequestURI()+"\n"), body...)))
+
+				if _, loaded := inProgress.LoadOrStore(cacheKey, true); loaded {
+					c.JsonApiErr(http.StatusConflict, "A request with the same idempotency key is in progress", nil)
+					return
+				}
+				defer inProgress.Delete(cacheKey)
+
+				cached, err := cache.Get(ctx, cacheKey)
+				switch {
+				case err == nil:
+					resp, ok := cached.(*idempotentResponse)
+					if !ok {
+						break
+					}
+					if resp.RequestHash != requestHash {
+						c.JsonApiErr(http.StatusUnprocessableEntity, "The idempotency key was already used for a different request", nil)
+						return
+					}
+					if resp.InProgress {
+						c.JsonApiErr(http.StatusConflict, "A request with the same idempotency key is in progress", nil)
+						return
+					}
+					if resp.ContentType != "" {
+						w.Header().Set("Content-Type", resp.ContentType)
+					}
+					w.Header().Set(IdempotentReplayedHeader, "true")
+					w.WriteHeader(resp.Status)
+					_, _ = w.Write(resp.Body)
+					return
+				case !errors.Is(err, remotecache.ErrCacheItemNotFound):
+					logger.Warn("Failed to get idempotent response, handling request without idempotency", "error", err)
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if err := cache.Set(ctx, cacheKey, &idempotentResponse{RequestHash: requestHash, InProgress: true}, idempotencyInProgressTTL); err != nil {
+					logger.Warn("Failed to store idempotency key, handling request without idempotency", "error", err)
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				rec := &idempotencyRecorder{ResponseWriter: c.Resp}
+				c.Resp = rec
+				next.ServeHTTP(rec, r)
+				c.Resp = rec.ResponseWriter
+
+				status := rec.Status()
+				if status < 200 || status > 299 || rec.truncated {
+					// let clients retry failed requests
+					if err := cache.Delete(ctx, cacheKey); err != nil {
+						logger.Warn("Failed to delete idempotency key", "error", err)
+					}
+					return
+				}
+
+				resp := &idempotentResponse{
+					RequestHash: requestHash,
+					Status:      status,
+					ContentType: rec.Header().Get("Content-Type"),
+					Body:        rec.body.Bytes(),
+				}
+				if err := cache.Set(ctx, cacheKey, resp, idempotencyResponseTTL); err != nil {
+					logger.Warn("Failed to store idempotent response", "error", err)
+				}
+			})
+		})
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyRecorder records the response body to replay it, unless it is too large to cache.
+type idempotencyRecorder struct {
+	web.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if r.body.Len()+len(b) > idempotencyMaxCachedBytes {
+		r.truncated = true
+	} else if !r.truncated {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
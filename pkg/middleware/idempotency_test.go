@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIdempotency(t *testing.T) {
+	idempotency := Idempotency(remotecache.NewFakeStore(t), map[string]bool{"/api/things": true})
+
+	created := 0
+	m := web.New()
+	m.Use(func(c *web.Context) {
+		userID := int64(1)
+		if id, err := strconv.ParseInt(c.Req.Header.Get("X-User-Id"), 10, 64); err == nil {
+			userID = id
+		}
+		reqCtx := &models.ReqContext{Context: c, IsSignedIn: true, SignedInUser: &user.SignedInUser{OrgID: 1, UserID: userID}}
+		c.Req = c.Req.WithContext(ctxkey.Set(c.Req.Context(), reqCtx))
+	})
+	m.Post("/api/things", idempotency("/api/things"), func(c *models.ReqContext) {
+		body, err := io.ReadAll(c.Req.Body)
+		require.NoError(t, err)
+		if string(body) == "fail" {
+			c.JsonApiErr(http.StatusBadRequest, "invalid thing", nil)
+			return
+		}
+		created++
+		c.JSON(http.StatusOK, map[string]interface{}{"id": created, "name": string(body)})
+	})
+	m.Post("/api/others", idempotency("/api/others"), func(c *models.ReqContext) {
+		created++
+		c.JSON(http.StatusOK, map[string]interface{}{"id": created})
+	})
+
+	send := func(path, key, body string, headers ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("replays the response to retries", func(t *testing.T) {
+		first := send("/api/things", "key-1", "a")
+		require.Equal(t, http.StatusOK, first.Code)
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+		retry := send("/api/things", "key-1", "a")
+		require.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("rejects reusing a key for a different request", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, send("/api/things", "key-1", "b").Code)
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("scopes keys to the user", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send("/api/things", "key-1", "b", "X-User-Id", "2").Code)
+		assert.Equal(t, 2, created)
+	})
+
+	t.Run("does not replay failed requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("/api/things", "key-2", "fail").Code)
+		assert.Equal(t, http.StatusBadRequest, send("/api/things", "key-2", "fail").Code)
+		assert.Equal(t, http.StatusOK, send("/api/things", "key-2", "c").Code)
+		assert.Equal(t, 3, created)
+	})
+
+	t.Run("ignores requests without key and routes which did not opt in", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, http.StatusOK, send("/api/things", "", "d").Code)
+			assert.Equal(t, http.StatusOK, send("/api/others", "key-3", "").Code)
+		}
+		assert.Equal(t, 7, created)
+	})
+
+	t.Run("rejects too long keys", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("/api/things", fmt.Sprintf("%0256d", 0), "e").Code)
+	})
+}
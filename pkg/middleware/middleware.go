@@ -5,8 +5,9 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/org"
-	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -25,7 +26,7 @@ func HandleNoCacheHeader(ctx *models.ReqContext) {
 	ctx.SkipCache = ctx.Req.Header.Get("X-Grafana-NoCache") == "true"
 }
 
-func AddDefaultResponseHeaders(cfg *setting.Cfg) web.Handler {
+func AddDefaultResponseHeaders(securityHeaders securityheaders.Service) web.Handler {
 	return func(c *web.Context) {
 		c.Resp.Before(func(w web.ResponseWriter) {
 			// if response has already been written, skip.
@@ -37,33 +38,44 @@ func AddDefaultResponseHeaders(cfg *setting.Cfg) web.Handler {
 				addNoCacheHeaders(c.Resp)
 			}
 
-			if !cfg.AllowEmbedding {
+			// the context handler has set the organization of the request when the response is written
+			var orgID int64
+			embedView := false
+			if reqContext := contexthandler.FromContext(c.Req.Context()); reqContext != nil && reqContext.SignedInUser != nil {
+				orgID = reqContext.OrgID
+				embedView = reqContext.IsEmbedView
+			}
+			headers := securityHeaders.GetHeaders(c.Req.Context(), orgID)
+
+			// the frame-ancestors directive added by the CSP middleware replaces X-Frame-Options, and
+			// embed views are meant to be framed
+			if !headers.AllowEmbedding && len(headers.FrameAncestors) == 0 && !embedView {
 				addXFrameOptionsDenyHeader(w)
 			}
 
-			addSecurityHeaders(w, cfg)
+			addSecurityHeaders(w, headers)
 		})
 	}
 }
 
 // addSecurityHeaders adds HTTP(S) response headers that enable various security protections in the client's browser.
-func addSecurityHeaders(w web.ResponseWriter, cfg *setting.Cfg) {
-	if cfg.StrictTransportSecurity {
-		strictHeaderValues := []string{fmt.Sprintf("max-age=%v", cfg.StrictTransportSecurityMaxAge)}
-		if cfg.StrictTransportSecurityPreload {
+func addSecurityHeaders(w web.ResponseWriter, headers *securityheaders.Headers) {
+	if headers.StrictTransportSecurity {
+		strictHeaderValues := []string{fmt.Sprintf("max-age=%v", headers.StrictTransportSecurityMaxAge)}
+		if headers.StrictTransportSecurityPreload {
 			strictHeaderValues = append(strictHeaderValues, "preload")
 		}
-		if cfg.StrictTransportSecuritySubDomains {
+		if headers.StrictTransportSecuritySubDomains {
 			strictHeaderValues = append(strictHeaderValues, "includeSubDomains")
 		}
 		w.Header().Set("Strict-Transport-Security", strings.Join(strictHeaderValues, "; "))
 	}
 
-	if cfg.ContentTypeProtectionHeader {
+	if headers.ContentTypeProtection {
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 	}
 
-	if cfg.XSSProtectionHeader {
+	if headers.XSSProtection {
 		w.Header().Set("X-XSS-Protection", "1; mode=block")
 	}
 }
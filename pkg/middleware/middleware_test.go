@@ -35,9 +35,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/login/loginservice"
 	"github.com/grafana/grafana/pkg/services/login/logintest"
 	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
+	"github.com/grafana/grafana/pkg/services/networkpolicy/networkpolicytest"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+	"github.com/grafana/grafana/pkg/services/orgtoken/orgtokentest"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/securityheaders/securityheaderstest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
@@ -123,6 +128,35 @@ func TestMiddleWareContentSecurityPolicyHeaders(t *testing.T) {
 		cfg.CSPReportOnlyTemplate = "script-src 'self' 'strict-dynamic' $NONCE;connect-src 'self' ws://$ROOT_PATH wss://$ROOT_PATH;"
 		cfg.AppURL = "http://localhost:3000/"
 	})
+
+	middlewareScenario(t, "middleware should add frame-ancestors of the organization", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+		sc.apiKeyService.ExpectedAPIKey = &apikey.APIKey{OrgId: 12, Role: org.RoleEditor, Key: keyhash}
+		sc.securityHeadersService.FrameAncestors[12] = []string{"'self'", "https://*.example.com"}
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+		assert.Regexp(t, `^script-src 'self' 'strict-dynamic' 'nonce-[^']+';connect-src 'self' ws://localhost:3000/ wss://localhost:3000/;frame-ancestors 'self' https://\*.example.com;$`,
+			sc.resp.Header().Get("Content-Security-Policy"))
+		assert.Empty(t, sc.resp.Header().Get("X-Frame-Options"))
+
+		sc.apiKey = ""
+		sc.fakeReq("GET", "/").exec()
+		assert.Regexp(t, `^script-src 'self' 'strict-dynamic' 'nonce-[^']+';connect-src 'self' ws://localhost:3000/ wss://localhost:3000/;$`,
+			sc.resp.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, "deny", sc.resp.Header().Get("X-Frame-Options"))
+	}, func(cfg *setting.Cfg) {
+		cfg.CSPEnabled = true
+		cfg.CSPTemplate = "script-src 'self' 'strict-dynamic' $NONCE;connect-src 'self' ws://$ROOT_PATH wss://$ROOT_PATH;"
+		cfg.AppURL = "http://localhost:3000/"
+	})
+}
+
+func TestWithFrameAncestors(t *testing.T) {
+	assert.Equal(t, "frame-ancestors 'self';", withFrameAncestors("", []string{"'self'"}))
+	assert.Equal(t, "default-src 'self';frame-ancestors https://example.com;",
+		withFrameAncestors("default-src 'self'; frame-ancestors 'none';", []string{"https://example.com"}))
+	assert.Equal(t, "default-src 'self';", withFrameAncestors("default-src 'self';", nil))
 }
 
 func TestMiddlewareContext(t *testing.T) {
@@ -192,6 +226,15 @@ func TestMiddlewareContext(t *testing.T) {
 		cfg.AllowEmbedding = true
 	})
 
+	middlewareScenario(t, "middleware should not add X-Frame-Options header for embed views", func(
+		t *testing.T, sc *scenarioContext) {
+		sc.handlerFunc = func(c *models.ReqContext) {
+			c.IsEmbedView = true
+		}
+		sc.fakeReq("GET", "/").exec()
+		assert.Empty(t, sc.resp.Header().Get("X-Frame-Options"))
+	})
+
 	middlewareScenario(t, "Invalid api key", func(t *testing.T, sc *scenarioContext) {
 		sc.apiKey = "invalid_key_test"
 		sc.fakeReq("GET", "/").exec()
@@ -217,6 +260,32 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, org.RoleEditor, sc.context.OrgRole)
 	})
 
+	middlewareScenario(t, "Valid organization token", func(t *testing.T, sc *scenarioContext) {
+		const orgID int64 = 12
+		sc.orgTokenService.ExpectedToken = &orgtoken.OrgToken{UID: "ci", OrgID: orgID, Name: "ci", Role: org.RoleEditor}
+
+		sc.fakeReq("GET", "/").withAuthorizationHeader("Bearer " + orgtoken.KeyPrefix + "secret").exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+
+		assert.True(t, sc.context.IsSignedIn)
+		assert.Equal(t, orgID, sc.context.OrgID)
+		assert.Equal(t, int64(0), sc.context.UserID)
+		assert.Equal(t, org.RoleEditor, sc.context.OrgRole)
+		token, ok := orgtoken.FromContext(sc.context.Req.Context())
+		require.True(t, ok)
+		assert.Equal(t, "ci", token.UID)
+	})
+
+	middlewareScenario(t, "Expired organization token", func(t *testing.T, sc *scenarioContext) {
+		sc.orgTokenService.ExpectedError = orgtoken.ErrTokenExpired
+
+		sc.fakeReq("GET", "/").withAuthorizationHeader("Bearer " + orgtoken.KeyPrefix + "secret").exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, orgtoken.ErrTokenExpired.Error(), sc.respJson["message"])
+	})
+
 	middlewareScenario(t, "Valid API key with JWT enabled", func(t *testing.T, sc *scenarioContext) {
 		const orgID int64 = 12
 		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
@@ -258,6 +327,59 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, "Expired API key", sc.respJson["message"])
 	})
 
+	middlewareScenario(t, "Valid API key, but used outside of its allow list", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		allowedCIDRs := "10.0.0.0/8"
+		sc.apiKeyService.ExpectedAPIKey = &apikey.APIKey{OrgId: 12, Role: org.RoleEditor, Key: keyhash, AllowedCIDRs: &allowedCIDRs}
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, "API key is not allowed from this address", sc.respJson["message"])
+		require.Len(t, sc.networkPolicyService.Rejections, 1)
+		assert.Equal(t, networkpolicy.PolicyTypeAPIKey, sc.networkPolicyService.Rejections[0].Policy)
+	})
+
+	middlewareScenario(t, "Valid API key, but used outside of its allow list with a forged forwarding header", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		allowedCIDRs := "10.0.0.0/8"
+		sc.apiKeyService.ExpectedAPIKey = &apikey.APIKey{OrgId: 12, Role: org.RoleEditor, Key: keyhash, AllowedCIDRs: &allowedCIDRs}
+
+		sc.fakeReq("GET", "/").withValidApiKey()
+		sc.req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		sc.req.Header.Set("X-Real-IP", "10.0.0.1")
+		sc.exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, "API key is not allowed from this address", sc.respJson["message"])
+	})
+
+	middlewareScenario(t, "Valid API key, but used outside of the network policy of its organization", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		sc.apiKeyService.ExpectedAPIKey = &apikey.APIKey{Id: 3, OrgId: 12, Role: org.RoleEditor, Key: keyhash}
+		sc.networkPolicyService.ExpectedDenied = true
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 403, sc.resp.Code)
+		assert.Equal(t, "Access from this network is not allowed", sc.respJson["message"])
+		require.Len(t, sc.networkPolicyService.Rejections, 1)
+		assert.Equal(t, networkpolicy.Rejection{
+			OrgID:     12,
+			APIKeyID:  3,
+			Policy:    networkpolicy.PolicyTypeOrg,
+			IPAddress: sc.networkPolicyService.Rejections[0].IPAddress,
+			Method:    "GET",
+			Path:      "/",
+		}, sc.networkPolicyService.Rejections[0])
+	})
+
 	middlewareScenario(t, "Non-expired auth token in cookie which is not being rotated", func(
 		t *testing.T, sc *scenarioContext) {
 		const userID int64 = 12
@@ -811,8 +933,8 @@ func middlewareScenario(t *testing.T, desc string, fn scenarioFunc, cbs ...func(
 		require.Truef(t, exists, "Views directory should exist at %q", viewsPath)
 
 		sc.m = web.New()
-		sc.m.Use(AddDefaultResponseHeaders(cfg))
-		sc.m.UseMiddleware(ContentSecurityPolicy(cfg, logger))
+		sc.securityHeadersService = &securityheaderstest.FakeService{Cfg: cfg, FrameAncestors: map[int64][]string{}}
+		sc.m.Use(AddDefaultResponseHeaders(sc.securityHeadersService))
 		sc.m.UseMiddleware(web.Renderer(viewsPath, "[[", "]]"))
 
 		sc.mockSQLStore = dbtest.NewFakeDB()
@@ -821,10 +943,13 @@ func middlewareScenario(t *testing.T, desc string, fn scenarioFunc, cbs ...func(
 		sc.orgService = orgtest.NewOrgServiceFake()
 		sc.apiKeyService = &apikeytest.Service{}
 		sc.oauthTokenService = &authtest.FakeOAuthTokenService{}
-		ctxHdlr := getContextHandler(t, cfg, sc.mockSQLStore, sc.loginService, sc.apiKeyService, sc.userService, sc.orgService, sc.oauthTokenService)
+		sc.networkPolicyService = &networkpolicytest.FakeService{}
+		sc.orgTokenService = &orgtokentest.FakeService{}
+		ctxHdlr := getContextHandler(t, cfg, sc.mockSQLStore, sc.loginService, sc.apiKeyService, sc.userService, sc.orgService, sc.oauthTokenService, sc.networkPolicyService, sc.orgTokenService)
 		sc.sqlStore = ctxHdlr.SQLStore
 		sc.contextHandler = ctxHdlr
 		sc.m.Use(ctxHdlr.Middleware)
+		sc.m.UseMiddleware(ContentSecurityPolicy(cfg, sc.securityHeadersService, logger))
 		sc.m.Use(OrgRedirect(sc.cfg, sc.userService))
 
 		sc.userAuthTokenService = ctxHdlr.AuthTokenService.(*authtest.FakeUserAuthTokenService)
@@ -857,10 +982,18 @@ func middlewareScenario(t *testing.T, desc string, fn scenarioFunc, cbs ...func(
 func getContextHandler(t *testing.T, cfg *setting.Cfg, mockSQLStore *dbtest.FakeDB,
 	loginService *loginservice.LoginServiceMock, apiKeyService *apikeytest.Service,
 	userService *usertest.FakeUserService, orgService *orgtest.FakeOrgService,
-	oauthTokenService *authtest.FakeOAuthTokenService,
+	oauthTokenService *authtest.FakeOAuthTokenService, networkPolicyService *networkpolicytest.FakeService,
+	orgTokenService *orgtokentest.FakeService,
 ) *contexthandler.ContextHandler {
 	t.Helper()
 
+	if networkPolicyService == nil {
+		networkPolicyService = &networkpolicytest.FakeService{}
+	}
+	if orgTokenService == nil {
+		orgTokenService = &orgtokentest.FakeService{}
+	}
+
 	if cfg == nil {
 		cfg = setting.NewCfg()
 	}
@@ -875,7 +1008,7 @@ func getContextHandler(t *testing.T, cfg *setting.Cfg, mockSQLStore *dbtest.Fake
 	tracer := tracing.InitializeTracerForTest()
 	authProxy := authproxy.ProvideAuthProxy(cfg, remoteCacheSvc, loginService, userService, mockSQLStore)
 	authenticator := &logintest.AuthenticatorFake{ExpectedUser: &user.User{}}
-	return contexthandler.ProvideService(cfg, userAuthTokenSvc, authJWTSvc, remoteCacheSvc, renderSvc, mockSQLStore, tracer, authProxy, loginService, apiKeyService, authenticator, userService, orgService, oauthTokenService, featuremgmt.WithFeatures(featuremgmt.FlagAccessTokenExpirationCheck))
+	return contexthandler.ProvideService(cfg, userAuthTokenSvc, authJWTSvc, remoteCacheSvc, renderSvc, mockSQLStore, tracer, authProxy, loginService, apiKeyService, authenticator, userService, orgService, oauthTokenService, featuremgmt.WithFeatures(featuremgmt.FlagAccessTokenExpirationCheck), networkPolicyService, orgTokenService)
 }
 
 type fakeRenderService struct {
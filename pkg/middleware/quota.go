@@ -1,30 +1,48 @@
 package middleware
 
 import (
-	"fmt"
+	"net/http"
+	"strconv"
 
-	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/web"
 )
 
-// Quota returns a function that returns a function used to call quotaservice based on target name
+// Quota returns a function that returns a function used to call quotaservice based on target name.
+// Requests rejected by a quota respond with 403 Forbidden, or with 429 Too Many Requests and a
+// Retry-After header if the quota frees up on its own. The resources created by the requests which
+// succeed are added to the cached usage.
 func Quota(quotaService quota.Service) func(string) web.Handler {
 	if quotaService == nil {
 		panic("quotaService is nil")
 	}
 	//https://open.spotify.com/track/7bZSoBEAEEUsGEuLOf94Jm?si=T1Tdju5qRSmmR0zph_6RBw fuuuuunky
 	return func(targetSrv string) web.Handler {
-		return func(c *models.ReqContext) {
-			limitReached, err := quotaService.QuotaReached(c, quota.TargetSrv(targetSrv))
-			if err != nil {
-				c.JsonApiErr(500, "Failed to get quota", err)
-				return
-			}
-			if limitReached {
-				c.JsonApiErr(403, fmt.Sprintf("%s Quota reached", targetSrv), nil)
-				return
-			}
-		}
+		return web.Middleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c := contexthandler.FromContext(r.Context())
+				status, err := quotaService.Check(c, quota.TargetSrv(targetSrv))
+				if err != nil {
+					c.JsonApiErr(500, "Failed to get quota", err)
+					return
+				}
+				if status.Reached {
+					resp := response.Err(status.Err())
+					if status.RetryAfter > 0 {
+						resp.SetHeader("Retry-After", strconv.FormatInt(int64(status.RetryAfter.Seconds()), 10))
+					}
+					resp.WriteTo(c)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+
+				if rw, ok := w.(web.ResponseWriter); ok && status.AddUsage != nil && rw.Status() >= 200 && rw.Status() < 300 {
+					status.AddUsage()
+				}
+			})
+		})
 	}
 }
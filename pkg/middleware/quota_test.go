@@ -2,11 +2,14 @@ package middleware
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -28,6 +31,7 @@ func TestMiddlewareQuota(t *testing.T) {
 			sc.m.Get("/user", quotaHandler, sc.defaultHandler)
 			sc.fakeReq("GET", "/user").exec()
 			assert.Equal(t, 403, sc.resp.Code)
+			assert.Contains(t, sc.resp.Body.String(), `"messageId":"quota.reached"`)
 		}, func(cfg *setting.Cfg) {
 			configure(cfg)
 		})
@@ -183,6 +187,34 @@ func TestMiddlewareQuota(t *testing.T) {
 	})
 }
 
+func TestMiddlewareQuota_AddUsage(t *testing.T) {
+	middlewareScenario(t, "adds the usage of successful requests", func(t *testing.T, sc *scenarioContext) {
+		qs := &usageQuotaService{FakeQuotaService: quotatest.New(false, nil)}
+		sc.m.Post("/dashboard", Quota(qs)("dashboard"), sc.defaultHandler)
+		sc.m.Post("/failed", Quota(qs)("dashboard"), func(c *models.ReqContext) {
+			c.JsonApiErr(http.StatusBadRequest, "Invalid dashboard", nil)
+		})
+
+		sc.fakeReq("POST", "/failed").exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		assert.Equal(t, 0, qs.added)
+
+		sc.fakeReq("POST", "/dashboard").exec()
+		assert.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.Equal(t, 1, qs.added)
+	}, configure)
+}
+
+// usageQuotaService counts the usage added by the quota middleware.
+type usageQuotaService struct {
+	*quotatest.FakeQuotaService
+	added int
+}
+
+func (s *usageQuotaService) Check(c *models.ReqContext, target quota.TargetSrv) (*quota.Status, error) {
+	return &quota.Status{AddUsage: func() { s.added++ }}, nil
+}
+
 func getQuotaHandler(reached bool, target string) web.Handler {
 	qs := quotatest.New(reached, nil)
 	return Quota(qs)(target)
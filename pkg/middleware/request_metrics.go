@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/prometheus/client_golang/prometheus"
@@ -76,6 +77,10 @@ func RequestMetrics(features featuremgmt.FeatureToggles) web.Middleware {
 				}
 			}
 
+			if ctx := contexthandler.FromContext(r.Context()); ctx != nil && ctx.SignedInUser != nil {
+				metrics.ObserveOrgHTTPRequest(ctx.OrgID, code)
+			}
+
 			// avoiding the sanitize functions for in the new instrumentation
 			// since they dont make much sense. We should remove them later.
 			histogram := httpRequestDurationHistogram.
@@ -97,6 +97,7 @@ func RequestTracing(tracer tracing.Tracer) web.Middleware {
 
 			status := rw.Status()
 
+			tracing.SetOrgIDAttribute(req.Context(), span)
 			span.SetAttributes("http.status_code", status, attribute.Int("http.status_code", status))
 			span.SetAttributes("http.url", req.RequestURI, attribute.String("http.url", req.RequestURI))
 			span.SetAttributes("http.method", req.Method, attribute.String("http.method", req.Method))
@@ -17,37 +17,43 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
 	"github.com/grafana/grafana/pkg/services/login/loginservice"
+	"github.com/grafana/grafana/pkg/services/networkpolicy/networkpolicytest"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/orgtoken/orgtokentest"
+	"github.com/grafana/grafana/pkg/services/securityheaders/securityheaderstest"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
 type scenarioContext struct {
-	t                    *testing.T
-	m                    *web.Mux
-	context              *models.ReqContext
-	resp                 *httptest.ResponseRecorder
-	apiKey               string
-	authHeader           string
-	jwtAuthHeader        string
-	tokenSessionCookie   string
-	respJson             map[string]interface{}
-	handlerFunc          handlerFunc
-	defaultHandler       web.Handler
-	url                  string
-	userAuthTokenService *authtest.FakeUserAuthTokenService
-	jwtAuthService       *models.FakeJWTService
-	remoteCacheService   *remotecache.RemoteCache
-	cfg                  *setting.Cfg
-	sqlStore             db.DB
-	mockSQLStore         *dbtest.FakeDB
-	contextHandler       *contexthandler.ContextHandler
-	loginService         *loginservice.LoginServiceMock
-	apiKeyService        *apikeytest.Service
-	userService          *usertest.FakeUserService
-	oauthTokenService    *authtest.FakeOAuthTokenService
-	orgService           *orgtest.FakeOrgService
+	t                      *testing.T
+	m                      *web.Mux
+	context                *models.ReqContext
+	resp                   *httptest.ResponseRecorder
+	apiKey                 string
+	authHeader             string
+	jwtAuthHeader          string
+	tokenSessionCookie     string
+	respJson               map[string]interface{}
+	handlerFunc            handlerFunc
+	defaultHandler         web.Handler
+	url                    string
+	userAuthTokenService   *authtest.FakeUserAuthTokenService
+	jwtAuthService         *models.FakeJWTService
+	remoteCacheService     *remotecache.RemoteCache
+	cfg                    *setting.Cfg
+	sqlStore               db.DB
+	mockSQLStore           *dbtest.FakeDB
+	contextHandler         *contexthandler.ContextHandler
+	loginService           *loginservice.LoginServiceMock
+	apiKeyService          *apikeytest.Service
+	networkPolicyService   *networkpolicytest.FakeService
+	orgTokenService        *orgtokentest.FakeService
+	securityHeadersService *securityheaderstest.FakeService
+	userService            *usertest.FakeUserService
+	oauthTokenService      *authtest.FakeOAuthTokenService
+	orgService             *orgtest.FakeOrgService
 
 	req *http.Request
 }
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// UserUsage records API requests and data source queries made by signed in
+// users so that usage can be reported per user and team.
+func UserUsage(tracker usagestats.UserUsageTracker) web.Handler {
+	return func(c *models.ReqContext) {
+		if c.SignedInUser == nil || c.UserID <= 0 {
+			return
+		}
+
+		path := c.Req.URL.Path
+		if !strings.HasPrefix(path, "/api/") {
+			return
+		}
+
+		tracker.RecordUserUsage(c.OrgID, c.UserID, usagestats.UsageKindAPIRequest)
+		if strings.HasPrefix(path, "/api/ds/query") || strings.HasPrefix(path, "/api/datasources/proxy") {
+			tracker.RecordUserUsage(c.OrgID, c.UserID, usagestats.UsageKindQuery)
+		}
+	}
+}
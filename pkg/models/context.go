@@ -1,14 +1,18 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/log/redact"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models/usertoken"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -26,6 +30,8 @@ type ReqContext struct {
 	// RequestNonce is a cryptographic request identifier for use with Content Security Policy.
 	RequestNonce          string
 	IsPublicDashboardView bool
+	// IsEmbedView is set for requests made with an embed token, which can be framed by other sites.
+	IsEmbedView bool
 
 	PerfmonTimer   prometheus.Summary
 	LookupTokenErr error
@@ -44,6 +50,9 @@ func (ctx *ReqContext) Handle(cfg *setting.Cfg, status int, title string, err er
 		ctx.Logger.Error(title, "error", err)
 		if setting.Env != setting.Prod {
 			data.ErrorMsg = err
+			if msg := redact.String(err.Error()); msg != err.Error() {
+				data.ErrorMsg = errors.New(msg)
+			}
 		}
 	}
 
@@ -57,12 +66,13 @@ func (ctx *ReqContext) IsApiRequest() bool {
 func (ctx *ReqContext) JsonApiErr(status int, message string, err error) {
 	resp := make(map[string]interface{})
 	traceID := tracing.TraceIDFromContext(ctx.Req.Context(), false)
+	message = redact.String(message)
 
 	if err != nil {
 		resp["traceID"] = traceID
 		ctx.Logger.Error(message, "error", err, "traceID", traceID)
 		if setting.Env != setting.Prod {
-			resp["error"] = err.Error()
+			resp["error"] = redact.String(err.Error())
 		}
 	}
 
@@ -77,6 +87,17 @@ func (ctx *ReqContext) JsonApiErr(status int, message string, err error) {
 		resp["message"] = message
 	}
 
+	if errhttp.AcceptsProblem(ctx.Req) {
+		problem := errhttp.NewProblem(status, message, err)
+		problem.TraceID = traceID
+		ctx.Resp.Header().Set("Content-Type", errhttp.ProblemContentType)
+		ctx.Resp.WriteHeader(status)
+		if err := json.NewEncoder(ctx.Resp).Encode(problem); err != nil {
+			ctx.Logger.Error("Error writing to response", "err", err)
+		}
+		return
+	}
+
 	ctx.JSON(status, resp)
 }
 
@@ -272,10 +272,25 @@ type DashboardTagCloudItem struct {
 }
 
 type GetDashboardTagsQuery struct {
-	OrgId  int64
+	OrgId int64
+	// Key limits the result to the namespaced tags with this key.
+	Key    string
 	Result []*DashboardTagCloudItem
 }
 
+type DashboardTagKeyItem struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// GetDashboardTagKeysQuery lists the keys of the namespaced key:value tags of an organization
+// with the number of dashboards using them.
+type GetDashboardTagKeysQuery struct {
+	OrgId  int64
+	Prefix string
+	Result []*DashboardTagKeyItem
+}
+
 type GetDashboardsQuery struct {
 	DashboardIds  []int64
 	DashboardUIds []string
@@ -1,3 +1,7 @@
 package models
 
 type GetDBHealthQuery struct{}
+
+type GetPendingMigrationsQuery struct {
+	Result []string
+}
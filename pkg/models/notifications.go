@@ -26,6 +26,8 @@ type SendEmailCommand struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*SendEmailAttachFile
+	// OrgID selects the sender identity of the organization, if it has one.
+	OrgID int64
 }
 
 // SendEmailCommandSync is the command for sending emails synchronously
@@ -12,6 +12,11 @@ var (
 	ErrShortURLAbsolutePath = errutil.NewBase(errutil.StatusValidationFailed, "shorturl.absolute-path", errutil.WithPublicMessage("Path should be relative"))
 	ErrShortURLInvalidPath  = errutil.NewBase(errutil.StatusValidationFailed, "shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
 	ErrShortURLInternal     = errutil.NewBase(errutil.StatusInternal, "shorturl.internal")
+	ErrShortURLInvalidSlug  = errutil.NewBase(errutil.StatusValidationFailed, "shorturl.invalid-slug",
+		errutil.WithPublicMessage("Slugs must be 3 to 40 lowercase letters, digits, dashes or underscores"))
+	ErrShortURLSlugTaken     = errutil.NewBase(errutil.StatusValidationFailed, "shorturl.slug-taken", errutil.WithPublicMessage("Slug is already in use"))
+	ErrShortURLInvalidExpiry = errutil.NewBase(errutil.StatusValidationFailed, "shorturl.invalid-expiry", errutil.WithPublicMessage("Invalid expiry"))
+	ErrShortURLExpired       = errutil.NewBase(errutil.StatusNotFound, "shorturl.expired", errutil.WithPublicMessage("Short URL has expired"))
 )
 
 type ShortUrl struct {
@@ -22,6 +27,52 @@ type ShortUrl struct {
 	CreatedBy  int64
 	CreatedAt  int64
 	LastSeenAt int64
+	// ExpiresAt is when the short URL stops redirecting, zero if it never expires.
+	ExpiresAt int64
+	// Hits counts the redirects of the short URL.
+	Hits int64
+	// CustomSlug is set for short URLs whose UID was chosen by the user. They are never
+	// deleted for not being visited.
+	CustomSlug bool
+}
+
+// IsExpired returns whether the short URL stopped redirecting.
+func (s *ShortUrl) IsExpired(now time.Time) bool {
+	return s.ExpiresAt > 0 && s.ExpiresAt <= now.Unix()
+}
+
+type CreateShortURLCommand struct {
+	Path string
+	// Slug is the UID of the short URL, generated if empty.
+	Slug string
+	// ExpiresIn is the lifetime of the short URL, zero if it never expires.
+	ExpiresIn time.Duration
+}
+
+type UpdateShortURLCommand struct {
+	UID   string
+	OrgID int64
+	Path  *string
+	// ExpiresIn resets the lifetime of the short URL from now, zero removes the expiry.
+	ExpiresIn *time.Duration
+}
+
+type SearchShortURLsQuery struct {
+	OrgID int64
+	// Query matches the UID or the path of the short URLs.
+	Query     string
+	CreatedBy int64
+	// Sort is one of created, hits or lastSeen, in descending order.
+	Sort  string
+	Page  int
+	Limit int
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64
+	ShortURLs  []*ShortUrl
+	Page       int
+	PerPage    int
 }
 
 type DeleteShortUrlCommand struct {
@@ -57,6 +57,15 @@ type GetDataSourceStatsQuery struct {
 	Result []*DataSourceStats
 }
 
+type OrgActiveUsersStats struct {
+	OrgId int64
+	Count int64
+}
+
+type GetOrgActiveUsersStatsQuery struct {
+	Result []*OrgActiveUsersStats
+}
+
 type DataSourceAccessStats struct {
 	Type   string
 	Access string
@@ -106,6 +115,52 @@ type GetAdminStatsQuery struct {
 	Result *AdminStats
 }
 
+// AdminOrgStats are the resource counts of an org.
+type AdminOrgStats struct {
+	OrgId       int64  `json:"orgId"`
+	Name        string `json:"name"`
+	Users       int64  `json:"users"`
+	Dashboards  int64  `json:"dashboards"`
+	Folders     int64  `json:"folders"`
+	Datasources int64  `json:"datasources"`
+}
+
+type AdminOrgStatsResult struct {
+	TotalCount int64            `json:"totalCount"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"perPage"`
+	Orgs       []*AdminOrgStats `json:"orgs"`
+}
+
+type GetAdminOrgStatsQuery struct {
+	Page    int
+	PerPage int
+
+	Result *AdminOrgStatsResult
+}
+
+// ResourceAgeStats counts resources by their age, in disjoint buckets.
+type ResourceAgeStats struct {
+	Total       int64 `json:"total"`
+	UpTo7Days   int64 `json:"upTo7Days" xorm:"up_to_7_days"`
+	UpTo30Days  int64 `json:"upTo30Days" xorm:"up_to_30_days"`
+	UpTo90Days  int64 `json:"upTo90Days" xorm:"up_to_90_days"`
+	UpTo365Days int64 `json:"upTo365Days" xorm:"up_to_365_days"`
+	Older       int64 `json:"older"`
+}
+
+// AdminResourceAgeStats are the ages of the dashboards and data sources
+// since their last update, and of the users since they were last seen.
+type AdminResourceAgeStats struct {
+	Dashboards  ResourceAgeStats `json:"dashboards"`
+	Datasources ResourceAgeStats `json:"datasources"`
+	Users       ResourceAgeStats `json:"users"`
+}
+
+type GetAdminResourceAgeStatsQuery struct {
+	Result *AdminResourceAgeStats
+}
+
 type SystemUserCountStats struct {
 	Count int64
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -79,6 +80,10 @@ type SearchTeamsQuery struct {
 	UserIdFilter int64
 	SignedInUser *user.SignedInUser
 	HiddenUsers  map[string]struct{}
+	// Sort is the order of the teams by name or email, by name if not set.
+	Sort pagination.Sort
+	// After returns the teams after the cursor instead of a page.
+	After *pagination.Cursor
 
 	Result SearchTeamQueryResult
 }
@@ -99,6 +104,7 @@ type SearchTeamQueryResult struct {
 	Teams      []*TeamDTO `json:"teams"`
 	Page       int        `json:"page"`
 	PerPage    int        `json:"perPage"`
+	NextCursor string     `json:"nextCursor,omitempty"`
 }
 
 type IsAdminOfTeamsQuery struct {
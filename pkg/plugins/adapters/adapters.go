@@ -4,6 +4,9 @@ package adapters
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -11,6 +14,26 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+const (
+	// HeaderUserID is the header with the id of the signed-in user in requests to backend plugins,
+	// whose plugin context only has the login, name, email and role of the user.
+	HeaderUserID = "X-Grafana-User-Id"
+	// HeaderUserTeams is the header with the comma separated ids of the teams of the signed-in user.
+	HeaderUserTeams = "X-Grafana-User-Teams"
+)
+
+// instanceID returns the id of the instance settings of a data source. Plugins keep one instance
+// for each id, so data sources using per-user credentials get a distinct negative id for each
+// user, which keeps the instances, and the connections they hold, of users apart.
+func instanceID(ds *datasources.DataSource) int64 {
+	if ds.CredentialsUserID == 0 {
+		return ds.Id
+	}
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d:%d", ds.Id, ds.CredentialsUserID)
+	return -int64(h.Sum64()>>1) - 1
+}
+
 // ModelToInstanceSettings converts a datasources.DataSource to a backend.DataSourceInstanceSettings.
 func ModelToInstanceSettings(ds *datasources.DataSource, decryptFn func(ds *datasources.DataSource) (map[string]string, error),
 ) (*backend.DataSourceInstanceSettings, error) {
@@ -29,7 +52,7 @@ func ModelToInstanceSettings(ds *datasources.DataSource, decryptFn func(ds *data
 
 	return &backend.DataSourceInstanceSettings{
 		Type:                    ds.Type,
-		ID:                      ds.Id,
+		ID:                      instanceID(ds),
 		Name:                    ds.Name,
 		URL:                     ds.Url,
 		UID:                     ds.Uid,
@@ -56,3 +79,21 @@ func BackendUserFromSignedInUser(su *user.SignedInUser) *backend.User {
 		Role:  string(su.OrgRole),
 	}
 }
+
+// BackendUserHeaders returns the headers with the identity of the signed-in user, which is not part
+// of the backend plugin's user model. Anonymous users and API keys have no headers.
+func BackendUserHeaders(su *user.SignedInUser) map[string]string {
+	headers := map[string]string{}
+	if su == nil || su.UserID <= 0 {
+		return headers
+	}
+	headers[HeaderUserID] = strconv.FormatInt(su.UserID, 10)
+	if len(su.Teams) > 0 {
+		teams := make([]string, 0, len(su.Teams))
+		for _, id := range su.Teams {
+			teams = append(teams, strconv.FormatInt(id, 10))
+		}
+		headers[HeaderUserTeams] = strings.Join(teams, ",")
+	}
+	return headers
+}
@@ -21,6 +21,12 @@ type Installer interface {
 	Add(ctx context.Context, pluginID, version string, opts CompatOpts) error
 	// Remove removes an existing plugin.
 	Remove(ctx context.Context, pluginID string) error
+	// Rollback reinstalls the version of a plugin that was installed before the current one.
+	Rollback(ctx context.Context, pluginID string, opts CompatOpts) error
+	// Pin prevents (or allows again) changes to the installed version of a plugin.
+	Pin(ctx context.Context, pluginID string, pinned bool) error
+	// InstallState returns the installation state tracked for a plugin.
+	InstallState(ctx context.Context, pluginID string) (*InstallState, error)
 }
 
 type PluginSource struct {
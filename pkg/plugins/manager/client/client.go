@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/instrumentation"
@@ -19,12 +24,14 @@ var _ plugins.Client = (*Service)(nil)
 type Service struct {
 	pluginRegistry registry.Service
 	cfg            *config.Cfg
+	tracer         tracing.Tracer
 }
 
-func ProvideService(pluginRegistry registry.Service, cfg *config.Cfg) *Service {
+func ProvideService(pluginRegistry registry.Service, cfg *config.Cfg, tracer tracing.Tracer) *Service {
 	return &Service{
 		pluginRegistry: pluginRegistry,
 		cfg:            cfg,
+		tracer:         tracer,
 	}
 }
 
@@ -34,11 +41,16 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, plugins.ErrPluginNotRegistered.Errorf("%w", backendplugin.ErrPluginNotRegistered)
 	}
 
+	ctx, span := s.tracer.Start(ctx, "plugin query data", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	req = s.propagateTraceContext(ctx, span, req)
+
 	var resp *backend.QueryDataResponse
 	err := instrumentation.InstrumentQueryDataRequest(ctx, &req.PluginContext, s.cfg, func() (innerErr error) {
 		resp, innerErr = plugin.QueryData(ctx, req)
 		return
 	})
+	recordQueryDataSpan(span, resp, err)
 
 	if err != nil {
 		if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
@@ -64,6 +76,58 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return resp, err
 }
 
+// propagateTraceContext returns a copy of the request with the trace context in its headers,
+// so plugins can continue the trace in their requests to the data source.
+func (s *Service) propagateTraceContext(ctx context.Context, span tracing.Span, req *backend.QueryDataRequest) *backend.QueryDataRequest {
+	span.SetAttributes("plugin_id", req.PluginContext.PluginID, attribute.String("plugin_id", req.PluginContext.PluginID))
+	span.SetAttributes("org_id", req.PluginContext.OrgID, attribute.Int64("org_id", req.PluginContext.OrgID))
+	span.SetAttributes("queries", len(req.Queries), attribute.Int("queries", len(req.Queries)))
+	if ds := req.PluginContext.DataSourceInstanceSettings; ds != nil {
+		span.SetAttributes("datasource_uid", ds.UID, attribute.String("datasource_uid", ds.UID))
+		span.SetAttributes("datasource_name", ds.Name, attribute.String("datasource_name", ds.Name))
+	}
+
+	header := http.Header{}
+	s.tracer.Inject(ctx, header, span)
+	if len(header) == 0 {
+		return req
+	}
+
+	// the headers can be shared between requests, e.g. by the queries of an expression
+	headers := make(map[string]string, len(req.Headers)+len(header))
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	for k := range header {
+		headers[k] = header.Get(k)
+	}
+	propagated := *req
+	propagated.Headers = headers
+	return &propagated
+}
+
+func recordQueryDataSpan(span tracing.Span, resp *backend.QueryDataResponse, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query data")
+		return
+	}
+	if resp == nil {
+		return
+	}
+
+	failed := 0
+	for _, res := range resp.Responses {
+		if res.Error != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		span.SetAttributes("failed_queries", failed, attribute.Int("failed_queries", failed))
+		span.SetStatus(codes.Error, fmt.Sprintf("%d queries failed", failed))
+	}
+}
+
 func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
 	p, exists := s.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
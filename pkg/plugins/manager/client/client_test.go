@@ -7,17 +7,20 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/manager/fakes"
-	"github.com/stretchr/testify/require"
 )
 
 func TestQueryData(t *testing.T) {
 	t.Run("Empty registry should return not registered error", func(t *testing.T) {
 		registry := fakes.NewFakePluginRegistry()
-		client := ProvideService(registry, &config.Cfg{})
+		client := ProvideService(registry, &config.Cfg{}, tracing.InitializeTracerForTest())
 		_, err := client.QueryData(context.Background(), &backend.QueryDataRequest{})
 		require.Error(t, err)
 		require.ErrorIs(t, err, plugins.ErrPluginNotRegistered)
@@ -58,7 +61,7 @@ func TestQueryData(t *testing.T) {
 				err := registry.Add(context.Background(), p)
 				require.NoError(t, err)
 
-				client := ProvideService(registry, &config.Cfg{})
+				client := ProvideService(registry, &config.Cfg{}, tracing.InitializeTracerForTest())
 				_, err = client.QueryData(context.Background(), &backend.QueryDataRequest{
 					PluginContext: backend.PluginContext{
 						PluginID: "grafana",
@@ -71,6 +74,48 @@ func TestQueryData(t *testing.T) {
 	})
 }
 
+func TestQueryData_TracePropagation(t *testing.T) {
+	registry := fakes.NewFakePluginRegistry()
+	p := &plugins.Plugin{
+		JSONData: plugins.JSONData{
+			ID: "grafana",
+		},
+	}
+	var headers map[string]string
+	p.RegisterClient(&fakePluginBackend{
+		qdr: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			headers = req.Headers
+			return &backend.QueryDataResponse{}, nil
+		},
+	})
+	err := registry.Add(context.Background(), p)
+	require.NoError(t, err)
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	client := ProvideService(registry, &config.Cfg{}, tracing.InitializeTracerForTest())
+	req := &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			PluginID: "grafana",
+		},
+		Headers: map[string]string{"X-Custom": "value"},
+	}
+	_, err = client.QueryData(ctx, req)
+	require.NoError(t, err)
+
+	require.Equal(t, "value", headers["X-Custom"])
+	require.Contains(t, headers["Traceparent"], traceID.String())
+	require.NotContains(t, req.Headers, "Traceparent")
+}
+
 type fakePluginBackend struct {
 	qdr backend.QueryDataHandlerFunc
 
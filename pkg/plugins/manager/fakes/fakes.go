@@ -17,7 +17,10 @@ import (
 type FakePluginInstaller struct {
 	AddFunc func(ctx context.Context, pluginID, version string, opts plugins.CompatOpts) error
 	// Remove removes a plugin from the store.
-	RemoveFunc func(ctx context.Context, pluginID string) error
+	RemoveFunc       func(ctx context.Context, pluginID string) error
+	RollbackFunc     func(ctx context.Context, pluginID string, opts plugins.CompatOpts) error
+	PinFunc          func(ctx context.Context, pluginID string, pinned bool) error
+	InstallStateFunc func(ctx context.Context, pluginID string) (*plugins.InstallState, error)
 }
 
 func (i *FakePluginInstaller) Add(ctx context.Context, pluginID, version string, opts plugins.CompatOpts) error {
@@ -34,6 +37,27 @@ func (i *FakePluginInstaller) Remove(ctx context.Context, pluginID string) error
 	return nil
 }
 
+func (i *FakePluginInstaller) Rollback(ctx context.Context, pluginID string, opts plugins.CompatOpts) error {
+	if i.RollbackFunc != nil {
+		return i.RollbackFunc(ctx, pluginID, opts)
+	}
+	return nil
+}
+
+func (i *FakePluginInstaller) Pin(ctx context.Context, pluginID string, pinned bool) error {
+	if i.PinFunc != nil {
+		return i.PinFunc(ctx, pluginID, pinned)
+	}
+	return nil
+}
+
+func (i *FakePluginInstaller) InstallState(ctx context.Context, pluginID string) (*plugins.InstallState, error) {
+	if i.InstallStateFunc != nil {
+		return i.InstallStateFunc(ctx, pluginID)
+	}
+	return &plugins.InstallState{PluginID: pluginID}, nil
+}
+
 type FakeLoader struct {
 	LoadFunc   func(_ context.Context, _ plugins.Class, paths []string) ([]*plugins.Plugin, error)
 	UnloadFunc func(_ context.Context, _ string) error
@@ -266,6 +290,31 @@ func (s *FakePluginStorage) Remove(ctx context.Context, pluginID string) error {
 	return nil
 }
 
+type FakeInstallStateStore struct {
+	Store map[string]string
+}
+
+func NewFakeInstallStateStore() *FakeInstallStateStore {
+	return &FakeInstallStateStore{
+		Store: map[string]string{},
+	}
+}
+
+func (s *FakeInstallStateStore) Get(_ context.Context, key string) (string, bool, error) {
+	v, exists := s.Store[key]
+	return v, exists, nil
+}
+
+func (s *FakeInstallStateStore) Set(_ context.Context, key string, value string) error {
+	s.Store[key] = value
+	return nil
+}
+
+func (s *FakeInstallStateStore) Del(_ context.Context, key string) error {
+	delete(s.Store, key)
+	return nil
+}
+
 type FakeProcessManager struct {
 	StartFunc func(_ context.Context, pluginID string) error
 	StopFunc  func(_ context.Context, pluginID string) error
@@ -2,8 +2,11 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/config"
@@ -16,38 +19,129 @@ import (
 
 var _ plugins.Installer = (*PluginInstaller)(nil)
 
+const installStateNamespace = "plugin.installer"
+
+// InstallStateStore persists the installation state of plugins by plugin ID.
+type InstallStateStore interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key string, value string) error
+	Del(ctx context.Context, key string) error
+}
+
 type PluginInstaller struct {
 	pluginRepo     repo.Service
 	pluginStorage  storage.Manager
 	pluginRegistry registry.Service
 	pluginLoader   loader.Service
+	installStates  InstallStateStore
 	log            log.Logger
 }
 
 func ProvideInstaller(cfg *config.Cfg, pluginRegistry registry.Service, pluginLoader loader.Service,
-	pluginRepo repo.Service) *PluginInstaller {
-	return New(pluginRegistry, pluginLoader, pluginRepo, storage.FileSystem(logger.NewLogger("installer.fs"), cfg.PluginsPath))
+	pluginRepo repo.Service, kv kvstore.KVStore) *PluginInstaller {
+	return New(pluginRegistry, pluginLoader, pluginRepo, storage.FileSystem(logger.NewLogger("installer.fs"), cfg.PluginsPath),
+		kvstore.WithNamespace(kv, 0, installStateNamespace))
 }
 
 func New(pluginRegistry registry.Service, pluginLoader loader.Service, pluginRepo repo.Service,
-	pluginStorage storage.Manager) *PluginInstaller {
+	pluginStorage storage.Manager, installStates InstallStateStore) *PluginInstaller {
 	return &PluginInstaller{
 		pluginLoader:   pluginLoader,
 		pluginRegistry: pluginRegistry,
 		pluginRepo:     pluginRepo,
 		pluginStorage:  pluginStorage,
+		installStates:  installStates,
 		log:            log.New("plugin.installer"),
 	}
 }
 
 func (m *PluginInstaller) Add(ctx context.Context, pluginID, version string, opts plugins.CompatOpts) error {
+	if plugin, exists := m.plugin(ctx, pluginID); exists && plugin.Info.Version != version {
+		state, err := m.getInstallState(ctx, pluginID)
+		if err != nil {
+			return err
+		}
+		if state != nil && state.Pinned {
+			return plugins.ErrPluginPinned
+		}
+	}
+
+	return m.install(ctx, pluginID, version, opts)
+}
+
+// Rollback reinstalls the previously installed version of a plugin. Rolling back twice
+// returns to the version that was installed before the first rollback.
+func (m *PluginInstaller) Rollback(ctx context.Context, pluginID string, opts plugins.CompatOpts) error {
+	if _, exists := m.plugin(ctx, pluginID); !exists {
+		return plugins.ErrPluginNotInstalled
+	}
+
+	state, err := m.getInstallState(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+	if state == nil || state.PreviousVersion == "" {
+		return plugins.ErrNoPreviousVersion
+	}
+	if state.Pinned {
+		return plugins.ErrPluginPinned
+	}
+
+	m.log.Info("Rolling back plugin", "pluginID", pluginID, "from", state.Version, "to", state.PreviousVersion)
+	return m.install(ctx, pluginID, state.PreviousVersion, opts)
+}
+
+func (m *PluginInstaller) Pin(ctx context.Context, pluginID string, pinned bool) error {
+	plugin, exists := m.plugin(ctx, pluginID)
+	if !exists {
+		return plugins.ErrPluginNotInstalled
+	}
+	if !plugin.IsExternalPlugin() {
+		return plugins.ErrInstallCorePlugin
+	}
+
+	state, err := m.getInstallState(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		// the plugin was installed before its state was tracked, e.g. by the CLI
+		state = &plugins.InstallState{PluginID: pluginID, Version: plugin.Info.Version}
+	}
+	state.Pinned = pinned
+
+	return m.setInstallState(ctx, state)
+}
+
+func (m *PluginInstaller) InstallState(ctx context.Context, pluginID string) (*plugins.InstallState, error) {
+	plugin, exists := m.plugin(ctx, pluginID)
+	if !exists {
+		return nil, plugins.ErrPluginNotInstalled
+	}
+
+	state, err := m.getInstallState(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return &plugins.InstallState{PluginID: pluginID, Version: plugin.Info.Version}, nil
+	}
+	// the plugin can be changed on disk without the installer knowing about it
+	state.Version = plugin.Info.Version
+
+	return state, nil
+}
+
+func (m *PluginInstaller) install(ctx context.Context, pluginID, version string, opts plugins.CompatOpts) error {
 	compatOpts := repo.NewCompatOpts(opts.GrafanaVersion, opts.OS, opts.Arch)
 
 	var pluginArchive *repo.PluginArchive
+	previousVersion := ""
 	if plugin, exists := m.plugin(ctx, pluginID); exists {
 		if !plugin.IsExternalPlugin() {
 			return plugins.ErrInstallCorePlugin
 		}
+		previousVersion = plugin.Info.Version
 
 		if plugin.Info.Version == version {
 			return plugins.DuplicateError{
@@ -75,7 +169,7 @@ func (m *PluginInstaller) Add(ctx context.Context, pluginID, version string, opt
 		}
 
 		// remove existing installation of plugin
-		err = m.Remove(ctx, plugin.ID)
+		err = m.pluginLoader.Unload(ctx, plugin.ID)
 		if err != nil {
 			return err
 		}
@@ -121,13 +215,20 @@ func (m *PluginInstaller) Add(ctx context.Context, pluginID, version string, opt
 		pathsToScan = append(pathsToScan, depArchive.Path)
 	}
 
-	_, err = m.pluginLoader.Load(ctx, plugins.External, pathsToScan)
+	loaded, err := m.pluginLoader.Load(ctx, plugins.External, pathsToScan)
 	if err != nil {
 		m.log.Error("Could not load plugins", "paths", pathsToScan, "err", err)
 		return err
 	}
 
-	return nil
+	installedVersion := version
+	for _, p := range loaded {
+		if p.ID == pluginID {
+			installedVersion = p.Info.Version
+		}
+	}
+
+	return m.recordInstall(ctx, pluginID, installedVersion, previousVersion)
 }
 
 func (m *PluginInstaller) Remove(ctx context.Context, pluginID string) error {
@@ -143,7 +244,45 @@ func (m *PluginInstaller) Remove(ctx context.Context, pluginID string) error {
 	if err := m.pluginLoader.Unload(ctx, plugin.ID); err != nil {
 		return err
 	}
-	return nil
+	return m.installStates.Del(ctx, plugin.ID)
+}
+
+// recordInstall tracks the version of a plugin that was just installed together with the
+// version it replaced. Pins are kept since only unpinned plugins can be changed.
+func (m *PluginInstaller) recordInstall(ctx context.Context, pluginID, version, previousVersion string) error {
+	state, err := m.getInstallState(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &plugins.InstallState{PluginID: pluginID}
+	}
+	state.Version = version
+	state.PreviousVersion = previousVersion
+
+	return m.setInstallState(ctx, state)
+}
+
+func (m *PluginInstaller) getInstallState(ctx context.Context, pluginID string) (*plugins.InstallState, error) {
+	value, exists, err := m.installStates.Get(ctx, pluginID)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	state := &plugins.InstallState{}
+	if err := json.Unmarshal([]byte(value), state); err != nil {
+		return nil, fmt.Errorf("failed to read install state of plugin %s: %w", pluginID, err)
+	}
+	return state, nil
+}
+
+func (m *PluginInstaller) setInstallState(ctx context.Context, state *plugins.InstallState) error {
+	state.Updated = time.Now()
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return m.installStates.Set(ctx, state.PluginID, string(value))
 }
 
 // plugin finds a plugin with `pluginID` from the store
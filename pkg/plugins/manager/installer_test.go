@@ -68,7 +68,7 @@ func TestPluginManager_Add_Remove(t *testing.T) {
 			Store: map[string]struct{}{},
 		}
 
-		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs)
+		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs, fakes.NewFakeInstallStateStore())
 		err := inst.Add(context.Background(), pluginID, v1, plugins.CompatOpts{})
 		require.NoError(t, err)
 
@@ -182,7 +182,7 @@ func TestPluginManager_Add_Remove(t *testing.T) {
 				},
 			}
 
-			pm := New(reg, &fakes.FakeLoader{}, &fakes.FakePluginRepo{}, &fakes.FakePluginStorage{})
+			pm := New(reg, &fakes.FakeLoader{}, &fakes.FakePluginRepo{}, &fakes.FakePluginStorage{}, fakes.NewFakeInstallStateStore())
 			err := pm.Add(context.Background(), p.ID, "3.2.0", plugins.CompatOpts{})
 			require.ErrorIs(t, err, plugins.ErrInstallCorePlugin)
 
@@ -197,6 +197,88 @@ func TestPluginManager_Add_Remove(t *testing.T) {
 	})
 }
 
+func TestPluginInstaller_Pin_Rollback(t *testing.T) {
+	const pluginID, v1, v2 = "test-panel", "1.0.0", "2.0.0"
+
+	pluginV1 := createPlugin(t, pluginID, plugins.External, true, true, func(plugin *plugins.Plugin) {
+		plugin.Info.Version = v1
+	})
+	pluginV2 := createPlugin(t, pluginID, plugins.External, true, true, func(plugin *plugins.Plugin) {
+		plugin.Info.Version = v2
+	})
+
+	reg := &fakes.FakePluginRegistry{
+		Store: map[string]*plugins.Plugin{
+			pluginID: pluginV2,
+		},
+	}
+
+	var requestedVersions []string
+	pluginRepo := &fakes.FakePluginRepo{
+		GetPluginDownloadOptionsFunc: func(_ context.Context, _, version string, _ repo.CompatOpts) (*repo.PluginDownloadOptions, error) {
+			requestedVersions = append(requestedVersions, version)
+			return &repo.PluginDownloadOptions{Version: version}, nil
+		},
+	}
+	loader := &fakes.FakeLoader{
+		LoadFunc: func(_ context.Context, _ plugins.Class, _ []string) ([]*plugins.Plugin, error) {
+			return []*plugins.Plugin{pluginV1}, nil
+		},
+	}
+	states := fakes.NewFakeInstallStateStore()
+	states.Store[pluginID] = `{"pluginId":"test-panel","version":"2.0.0","previousVersion":"1.0.0"}`
+
+	inst := New(reg, loader, pluginRepo, fakes.NewFakePluginStorage(), states)
+	ctx := context.Background()
+
+	t.Run("Pinned plugin can't be changed", func(t *testing.T) {
+		require.NoError(t, inst.Pin(ctx, pluginID, true))
+
+		err := inst.Add(ctx, pluginID, "3.0.0", plugins.CompatOpts{})
+		require.ErrorIs(t, err, plugins.ErrPluginPinned)
+
+		err = inst.Rollback(ctx, pluginID, plugins.CompatOpts{})
+		require.ErrorIs(t, err, plugins.ErrPluginPinned)
+
+		require.Empty(t, requestedVersions)
+
+		state, err := inst.InstallState(ctx, pluginID)
+		require.NoError(t, err)
+		require.True(t, state.Pinned)
+		require.Equal(t, v2, state.Version)
+	})
+
+	t.Run("Rollback installs the previous version", func(t *testing.T) {
+		require.NoError(t, inst.Pin(ctx, pluginID, false))
+
+		err := inst.Rollback(ctx, pluginID, plugins.CompatOpts{})
+		require.NoError(t, err)
+		require.Equal(t, []string{v1}, requestedVersions)
+
+		reg.Store[pluginID] = pluginV1
+		state, err := inst.InstallState(ctx, pluginID)
+		require.NoError(t, err)
+		require.Equal(t, v1, state.Version)
+		require.Equal(t, v2, state.PreviousVersion)
+		require.False(t, state.Pinned)
+	})
+
+	t.Run("Rollback without previous version fails", func(t *testing.T) {
+		delete(states.Store, pluginID)
+
+		err := inst.Rollback(ctx, pluginID, plugins.CompatOpts{})
+		require.ErrorIs(t, err, plugins.ErrNoPreviousVersion)
+	})
+
+	t.Run("Removing a plugin clears its state", func(t *testing.T) {
+		require.NoError(t, inst.Pin(ctx, pluginID, true))
+		require.Contains(t, states.Store, pluginID)
+
+		require.NoError(t, inst.Remove(ctx, pluginID))
+		require.NotContains(t, states.Store, pluginID)
+	})
+}
+
 func createPlugin(t *testing.T, pluginID string, class plugins.Class, managed, backend bool, cbs ...func(*plugins.Plugin)) *plugins.Plugin {
 	t.Helper()
 
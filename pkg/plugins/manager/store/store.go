@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sort"
 
+	"github.com/grafana/grafana/pkg/infra/startup/providers"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/manager/loader"
@@ -20,6 +21,7 @@ type Service struct {
 
 func ProvideService(gCfg *setting.Cfg, cfg *config.Cfg, pluginRegistry registry.Service,
 	pluginLoader loader.Service) (*Service, error) {
+	defer providers.Track("plugins")()
 	for _, ps := range pluginSources(gCfg, cfg) {
 		if _, err := pluginLoader.Load(context.Background(), ps.Class, ps.Paths); err != nil {
 			return nil, err
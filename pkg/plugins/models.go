@@ -3,6 +3,7 @@ package plugins
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/org"
 )
@@ -15,8 +16,22 @@ var (
 	ErrInstallCorePlugin   = errors.New("cannot install a Core plugin")
 	ErrUninstallCorePlugin = errors.New("cannot uninstall a Core plugin")
 	ErrPluginNotInstalled  = errors.New("plugin is not installed")
+	ErrPluginPinned        = errors.New("plugin version is pinned")
+	ErrNoPreviousVersion   = errors.New("plugin has no previous version to roll back to")
 )
 
+// InstallState is the installation state of an external plugin tracked by the Installer.
+type InstallState struct {
+	PluginID string `json:"pluginId"`
+	// Version is the currently installed version.
+	Version string `json:"version"`
+	// PreviousVersion is the version that was installed before the current one, if any.
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// Pinned plugins can not be updated, downgraded or rolled back until they are unpinned.
+	Pinned  bool      `json:"pinned"`
+	Updated time.Time `json:"updated"`
+}
+
 type NotFoundError struct {
 	PluginID string
 }
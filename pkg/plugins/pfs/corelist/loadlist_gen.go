@@ -57,6 +57,7 @@ func coreTreeList(rt *thema.Runtime) pfs.TreeList {
 		makeTreeOrPanic("public/app/plugins/datasource/phlare", "phlare", rt),
 		makeTreeOrPanic("public/app/plugins/datasource/postgres", "postgres", rt),
 		makeTreeOrPanic("public/app/plugins/datasource/prometheus", "prometheus", rt),
+		makeTreeOrPanic("public/app/plugins/datasource/synthetics", "synthetics", rt),
 		makeTreeOrPanic("public/app/plugins/datasource/tempo", "tempo", rt),
 		makeTreeOrPanic("public/app/plugins/datasource/testdata", "testdata", rt),
 		makeTreeOrPanic("public/app/plugins/datasource/zipkin", "zipkin", rt),
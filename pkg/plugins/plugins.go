@@ -129,7 +129,8 @@ type JSONData struct {
 	SkipDataQuery bool `json:"skipDataQuery"`
 
 	// App settings
-	AutoEnabled bool `json:"autoEnabled"`
+	AutoEnabled bool   `json:"autoEnabled"`
+	Jobs        []*Job `json:"jobs,omitempty"`
 
 	// Datasource settings
 	Annotations  bool            `json:"annotations"`
@@ -160,6 +161,16 @@ func (d JSONData) DashboardIncludes() []*Includes {
 	return result
 }
 
+// Job describes a periodic background job of an app plugin
+// that is defined in the plugin.json file for a plugin.
+type Job struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Interval string `json:"interval"`
+	Timeout  string `json:"timeout"`
+	Path     string `json:"path"`
+}
+
 // Route describes a plugin route that is defined in
 // the plugin.json file for a plugin.
 type Route struct {
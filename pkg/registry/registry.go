@@ -29,6 +29,23 @@ type BackgroundService interface {
 	Run(ctx context.Context) error
 }
 
+// CanBeInitialized is implemented by background services which defer expensive initialization from
+// their constructor to startup, so that it runs in parallel with the initialization of the other
+// services.
+type CanBeInitialized interface {
+	// Init is called before Run, once the services this service depends on have been initialized.
+	// Returning an error stops the server.
+	Init(ctx context.Context) error
+}
+
+// HasStartupDependencies is implemented by background services which must not be initialized
+// before other background services have been initialized.
+type HasStartupDependencies interface {
+	// StartupDependencies returns the background services which have to be initialized before
+	// this service. Dependencies which are disabled or not registered are ignored.
+	StartupDependencies() []BackgroundService
+}
+
 // UsageStatsProvidersRegistry provides services sharing their usage stats
 type UsageStatsProvidersRegistry interface {
 	GetServices() []ProvidesUsageStats
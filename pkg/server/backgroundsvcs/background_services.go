@@ -2,7 +2,9 @@ package backgroundsvcs
 
 import (
 	"github.com/grafana/grafana/pkg/api"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/profiler"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	uss "github.com/grafana/grafana/pkg/infra/usagestats/service"
@@ -10,30 +12,53 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/manager/process"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/announcements"
 	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/cdnassets"
 	"github.com/grafana/grafana/pkg/services/cleanup"
+	"github.com/grafana/grafana/pkg/services/contentsync"
+	"github.com/grafana/grafana/pkg/services/dashboardbackup"
+	"github.com/grafana/grafana/pkg/services/dashboardcatalog"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/datasourceusage"
+	"github.com/grafana/grafana/pkg/services/embedtoken"
+	"github.com/grafana/grafana/pkg/services/grpcapi"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/ldapsync"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/logannotations"
 	"github.com/grafana/grafana/pkg/services/login/authinfoservice"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattemptimpl"
+	"github.com/grafana/grafana/pkg/services/loginhistory"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
 	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/orgtoken/orgtokenimpl"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
+	"github.com/grafana/grafana/pkg/services/pluginjobs"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/querycapture"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/scheduledreports"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	secretsMigrations "github.com/grafana/grafana/pkg/services/secrets/kvstore/migrations"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	secretsMigrator "github.com/grafana/grafana/pkg/services/secrets/migrator"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	samanager "github.com/grafana/grafana/pkg/services/serviceaccounts/manager"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/object"
 	"github.com/grafana/grafana/pkg/services/store/sanitizer"
+	"github.com/grafana/grafana/pkg/services/synthetics"
 	"github.com/grafana/grafana/pkg/services/thumbs"
+	"github.com/grafana/grafana/pkg/services/traceannotations"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
+	"github.com/grafana/grafana/pkg/services/userdeactivation"
+	"github.com/grafana/grafana/pkg/services/userexport"
+	"github.com/grafana/grafana/pkg/services/webhooks"
 )
 
 func ProvideBackgroundServiceRegistry(
@@ -48,11 +73,24 @@ func ProvideBackgroundServiceRegistry(
 	saService *samanager.ServiceAccountsService, authInfoService *authinfoservice.Implementation,
 	grpcServerProvider grpcserver.Provider,
 	secretMigrationProvider secretsMigrations.SecretMigrationProvider, loginAttemptService *loginattemptimpl.Service,
+	userExportService *userexport.UserExportService, loginHistoryService *loginhistory.LoginHistoryService,
+	pluginJobsService *pluginjobs.PluginJobsService, slowRequestProfiler *profiler.SlowRequestProfiler,
+	scheduledReportService *scheduledreports.ReportService, secretsRotation *secretsMigrator.SecretsMigrator,
+	notificationCenterService *notificationcenter.NotificationCenterService, announcementService *announcements.AnnouncementService,
+	dashboardCatalogService *dashboardcatalog.CatalogService, webhookService *webhooks.WebhookService,
+	queryCaptureService *querycapture.QueryCaptureService, leaderElectionService *leaderelection.LeaderElectionService,
+	networkPolicyService *networkpolicy.NetworkPolicyService, embedTokenService *embedtoken.EmbedTokenService,
+	dashboardBackupService *dashboardbackup.BackupService, syntheticsService *synthetics.SyntheticsService,
+	dataSourceUsageService *datasourceusage.UsageService, traceAnnotationService *traceannotations.TraceAnnotationService,
+	logAnnotationService *logannotations.LogAnnotationService, orgTokenService *orgtokenimpl.OrgTokenService,
+	contentSyncService *contentsync.ContentSyncService, cdnAssets *cdnassets.AssetService,
+	ldapSyncService *ldapsync.LDAPSyncService,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ dashboardsnapshots.Service, _ *alerting.AlertNotificationService,
 	_ serviceaccounts.Service, _ *guardian.Provider,
 	_ *plugindashboardsservice.DashboardUpdater, _ *sanitizer.Provider,
-	_ *grpcserver.HealthService, _ object.ObjectStoreServer, _ *grpcserver.ReflectionService,
+	_ *grpcserver.HealthService, _ object.ObjectStoreServer, _ *grpcserver.ReflectionService, _ *grpcapi.Service,
+	_ *userdeactivation.Service,
 ) *BackgroundServiceRegistry {
 	return NewBackgroundServiceRegistry(
 		httpServer,
@@ -83,6 +121,29 @@ func ProvideBackgroundServiceRegistry(
 		processManager,
 		secretMigrationProvider,
 		loginAttemptService,
+		userExportService,
+		loginHistoryService,
+		pluginJobsService,
+		slowRequestProfiler,
+		scheduledReportService,
+		secretsRotation,
+		notificationCenterService,
+		announcementService,
+		dashboardCatalogService,
+		webhookService,
+		queryCaptureService,
+		leaderElectionService,
+		networkPolicyService,
+		embedTokenService,
+		dashboardBackupService,
+		syntheticsService,
+		dataSourceUsageService,
+		traceAnnotationService,
+		logAnnotationService,
+		orgTokenService,
+		contentSyncService,
+		cdnAssets,
+		ldapSyncService,
 	)
 }
 
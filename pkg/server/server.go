@@ -7,9 +7,9 @@ import (
 	"net"
 	"os"
 	"path/filepath"
-	"reflect"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/usagestats/statscollector"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -19,6 +19,7 @@ import (
 	_ "github.com/grafana/grafana/pkg/extensions"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/startup"
 	"github.com/grafana/grafana/pkg/login"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/registry"
@@ -43,10 +44,14 @@ type Options struct {
 func New(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleRegistry accesscontrol.RoleRegistry,
 	provisioningService provisioning.ProvisioningService, backgroundServiceProvider registry.BackgroundServiceRegistry,
 	usageStatsProvidersRegistry registry.UsageStatsProvidersRegistry, statsCollectorService *statscollector.Service,
-	userService user.Service, loginAttemptService loginattempt.Service,
+	userService user.Service, loginAttemptService loginattempt.Service, startupTracker *startup.Tracker,
 ) (*Server, error) {
+	// Everything up to here, loading the configuration and constructing the services, happened
+	// before the server was created.
+	startupTracker.RecordPhase("construct services", startupTracker.ProcessStarted())
+
 	statsCollectorService.RegisterProviders(usageStatsProvidersRegistry.GetServices())
-	s, err := newServer(opts, cfg, httpServer, roleRegistry, provisioningService, backgroundServiceProvider, userService, loginAttemptService)
+	s, err := newServer(opts, cfg, httpServer, roleRegistry, provisioningService, backgroundServiceProvider, userService, loginAttemptService, startupTracker)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +65,7 @@ func New(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleRegistr
 
 func newServer(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleRegistry accesscontrol.RoleRegistry,
 	provisioningService provisioning.ProvisioningService, backgroundServiceProvider registry.BackgroundServiceRegistry, userService user.Service, loginAttemptService loginattempt.Service,
+	startupTracker *startup.Tracker,
 ) (*Server, error) {
 	rootCtx, shutdownFn := context.WithCancel(context.Background())
 	childRoutines, childCtx := errgroup.WithContext(rootCtx)
@@ -81,6 +87,7 @@ func newServer(opts Options, cfg *setting.Cfg, httpServer *api.HTTPServer, roleR
 		backgroundServices:  backgroundServiceProvider.GetServices(),
 		userService:         userService,
 		loginAttemptService: loginAttemptService,
+		startupTracker:      startupTracker,
 	}
 
 	return s, nil
@@ -109,6 +116,7 @@ type Server struct {
 	provisioningService provisioning.ProvisioningService
 	userService         user.Service
 	loginAttemptService loginattempt.Service
+	startupTracker      *startup.Tracker
 }
 
 // init initializes the server and its services.
@@ -129,11 +137,15 @@ func (s *Server) init() error {
 	login.ProvideService(s.HTTPServer.SQLStore, s.HTTPServer.Login, s.loginAttemptService, s.userService)
 	social.ProvideService(s.cfg, s.HTTPServer.Features)
 
-	if err := s.roleRegistry.RegisterFixedRoles(s.context); err != nil {
+	if err := s.startupTracker.TrackPhase("register fixed roles", func() error {
+		return s.roleRegistry.RegisterFixedRoles(s.context)
+	}); err != nil {
 		return err
 	}
 
-	return s.provisioningService.RunInitProvisioners(s.context)
+	return s.startupTracker.TrackPhase("run init provisioners", func() error {
+		return s.provisioningService.RunInitProvisioners(s.context)
+	})
 }
 
 // Run initializes and starts services. This will block until all services have
@@ -145,32 +157,42 @@ func (s *Server) Run() error {
 		return err
 	}
 
-	services := s.backgroundServices
+	nodes, err := buildServiceGraph(s.backgroundServices)
+	if err != nil {
+		return err
+	}
 
-	// Start background services.
-	for _, svc := range services {
-		if registry.IsDisabled(svc) {
+	// Start background services. Every service is initialized and run as soon as the services it
+	// depends on are initialized.
+	for _, n := range nodes {
+		node := n
+		if node.disabled {
+			s.startupTracker.RecordService(startup.Service{Name: node.name, Dependencies: []string{}, Disabled: true})
 			continue
 		}
 
-		service := svc
-		serviceName := reflect.TypeOf(service).String()
 		s.childRoutines.Go(func() error {
 			select {
 			case <-s.context.Done():
 				return s.context.Err()
 			default:
 			}
-			s.log.Debug("Starting background service", "service", serviceName)
-			err := service.Run(s.context)
+
+			if err := s.initService(node); err != nil {
+				s.log.Error("Failed to initialize background service", "service", node.name, "error", err)
+				return fmt.Errorf("%s init error: %w", node.name, err)
+			}
+
+			s.log.Debug("Starting background service", "service", node.name)
+			err := node.service.Run(s.context)
 			// Do not return context.Canceled error since errgroup.Group only
 			// returns the first error to the caller - thus we can miss a more
 			// interesting error.
 			if err != nil && !errors.Is(err, context.Canceled) {
-				s.log.Error("Stopped background service", "service", serviceName, "reason", err)
-				return fmt.Errorf("%s run error: %w", serviceName, err)
+				s.log.Error("Stopped background service", "service", node.name, "reason", err)
+				return fmt.Errorf("%s run error: %w", node.name, err)
 			}
-			s.log.Debug("Stopped background service", "service", serviceName, "reason", err)
+			s.log.Debug("Stopped background service", "service", node.name, "reason", err)
 			return nil
 		})
 	}
@@ -181,6 +203,40 @@ func (s *Server) Run() error {
 	return s.childRoutines.Wait()
 }
 
+// initService waits for the dependencies of a background service to be initialized and then
+// initializes the service, recording how long both took.
+func (s *Server) initService(node *serviceNode) error {
+	record := startup.Service{Name: node.name, Dependencies: node.dependencyNames()}
+	defer func() {
+		s.startupTracker.RecordService(record)
+	}()
+
+	waitStart := time.Now()
+	for _, dep := range node.deps {
+		select {
+		case <-dep.initialized:
+		case <-s.context.Done():
+			record.Error = s.context.Err().Error()
+			return s.context.Err()
+		}
+	}
+	record.Waited = time.Since(waitStart).Milliseconds()
+
+	if svc, ok := node.service.(registry.CanBeInitialized); ok {
+		initStart := time.Now()
+		err := svc.Init(s.context)
+		record.Init = time.Since(initStart).Milliseconds()
+		if err != nil {
+			record.Error = err.Error()
+			return err
+		}
+	}
+
+	record.Started = time.Now()
+	close(node.initialized)
+	return nil
+}
+
 // Shutdown initiates Grafana graceful shutdown. This shuts down all
 // running background services. Since Run blocks Shutdown supposed to
 // be run from a separate goroutine.
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/startup"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/server/backgroundsvcs"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
@@ -48,7 +50,7 @@ func (s *testService) IsDisabled() bool {
 
 func testServer(t *testing.T, services ...registry.BackgroundService) *Server {
 	t.Helper()
-	s, err := newServer(Options{}, setting.NewCfg(), nil, &acimpl.Service{}, nil, backgroundsvcs.NewBackgroundServiceRegistry(services...), usertest.NewUserServiceFake(), nil)
+	s, err := newServer(Options{}, setting.NewCfg(), nil, &acimpl.Service{}, nil, backgroundsvcs.NewBackgroundServiceRegistry(services...), usertest.NewUserServiceFake(), nil, startup.ProvideService(routing.NewRouteRegister()))
 	require.NoError(t, err)
 	// Required to skip configuration initialization that causes
 	// DI errors in this test.
@@ -92,3 +94,63 @@ func TestServer_Shutdown(t *testing.T) {
 	err = <-ch
 	require.NoError(t, err)
 }
+
+type initService struct {
+	*testService
+	name    string
+	deps    []registry.BackgroundService
+	initErr error
+	inits   chan<- string
+}
+
+func (s *initService) Init(ctx context.Context) error {
+	s.inits <- s.name
+	return s.initErr
+}
+
+func (s *initService) StartupDependencies() []registry.BackgroundService {
+	return s.deps
+}
+
+func TestServer_Run_InitializesDependenciesFirst(t *testing.T) {
+	inits := make(chan string, 3)
+	db := &initService{testService: newTestService(nil, false), name: "db", inits: inits}
+	cache := &initService{testService: newTestService(nil, false), name: "cache", deps: []registry.BackgroundService{db}, inits: inits}
+	api := &initService{testService: newTestService(nil, false), name: "api", deps: []registry.BackgroundService{cache, db}, inits: inits}
+	s := testServer(t, api, cache, db)
+
+	ch := make(chan error)
+	go func() {
+		ch <- s.Run()
+	}()
+	for _, svc := range []*initService{db, cache, api} {
+		<-svc.started
+	}
+	require.Equal(t, "db", <-inits)
+	require.Equal(t, "cache", <-inits)
+	require.Equal(t, "api", <-inits)
+
+	report := s.startupTracker.GetReport()
+	require.Len(t, report.Services, 3)
+	require.Equal(t, "*server.initService", report.Services[2].Name)
+	require.Len(t, report.Services[2].Dependencies, 2)
+
+	require.NoError(t, s.Shutdown(context.Background(), "test"))
+	require.NoError(t, <-ch)
+}
+
+func TestServer_Run_InitError(t *testing.T) {
+	testErr := errors.New("boom")
+	s := testServer(t, &initService{testService: newTestService(nil, false), initErr: testErr, inits: make(chan string, 1)})
+	err := s.Run()
+	require.ErrorIs(t, err, testErr)
+}
+
+func TestServer_Run_DependencyCycle(t *testing.T) {
+	a := &initService{testService: newTestService(nil, false), inits: make(chan string, 2)}
+	b := &initService{testService: newTestService(nil, false), deps: []registry.BackgroundService{a}, inits: a.inits}
+	a.deps = []registry.BackgroundService{b}
+	s := testServer(t, a, b)
+	err := s.Run()
+	require.ErrorContains(t, err, "startup dependency cycle")
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/registry"
+)
+
+// serviceNode is a background service in the startup dependency graph.
+type serviceNode struct {
+	service  registry.BackgroundService
+	name     string
+	disabled bool
+	deps     []*serviceNode
+	// initialized is closed once the service has been initialized, or right away if it is disabled.
+	initialized chan struct{}
+}
+
+func (n *serviceNode) dependencyNames() []string {
+	names := make([]string, 0, len(n.deps))
+	for _, dep := range n.deps {
+		names = append(names, dep.name)
+	}
+	return names
+}
+
+// buildServiceGraph links the background services to the services they depend on, so that every
+// service is initialized as soon as its dependencies are and independent services are initialized
+// in parallel. Disabled services are kept in the graph, their dependents do not wait for them.
+func buildServiceGraph(services []registry.BackgroundService) ([]*serviceNode, error) {
+	nodes := make([]*serviceNode, 0, len(services))
+	byService := make(map[registry.BackgroundService]*serviceNode, len(services))
+	for _, svc := range services {
+		node := &serviceNode{
+			service:     svc,
+			name:        reflect.TypeOf(svc).String(),
+			disabled:    registry.IsDisabled(svc),
+			initialized: make(chan struct{}),
+		}
+		if node.disabled {
+			close(node.initialized)
+		}
+		nodes = append(nodes, node)
+		byService[svc] = node
+	}
+
+	for _, node := range nodes {
+		withDeps, ok := node.service.(registry.HasStartupDependencies)
+		if !ok {
+			continue
+		}
+		for _, dep := range withDeps.StartupDependencies() {
+			if depNode, ok := byService[dep]; ok && !depNode.disabled {
+				node.deps = append(node.deps, depNode)
+			}
+		}
+	}
+
+	if cycle := findCycle(nodes); cycle != nil {
+		names := make([]string, 0, len(cycle))
+		for _, node := range cycle {
+			names = append(names, node.name)
+		}
+		return nil, fmt.Errorf("background services have a startup dependency cycle: %s", strings.Join(names, " -> "))
+	}
+
+	return nodes, nil
+}
+
+// findCycle returns the services of a dependency cycle, starting and ending with the same service,
+// or nil if there is none.
+func findCycle(nodes []*serviceNode) []*serviceNode {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*serviceNode]int, len(nodes))
+	var path []*serviceNode
+
+	var visit func(node *serviceNode) []*serviceNode
+	visit = func(node *serviceNode) []*serviceNode {
+		switch state[node] {
+		case visited:
+			return nil
+		case visiting:
+			for i, n := range path {
+				if n == node {
+					return append(append([]*serviceNode{}, path[i:]...), node)
+				}
+			}
+		}
+
+		state[node] = visiting
+		path = append(path, node)
+		for _, dep := range node.deps {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+		return nil
+	}
+
+	for _, node := range nodes {
+		if cycle := visit(node); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -16,10 +16,15 @@ import (
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/profiler"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/infra/schedule"
+	"github.com/grafana/grafana/pkg/infra/semaphore"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/startup"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	uss "github.com/grafana/grafana/pkg/infra/usagestats/service"
@@ -47,56 +52,82 @@ import (
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationsimpl"
+	"github.com/grafana/grafana/pkg/services/announcements"
 	"github.com/grafana/grafana/pkg/services/apikey/apikeyimpl"
 	"github.com/grafana/grafana/pkg/services/auth/jwt"
+	"github.com/grafana/grafana/pkg/services/cdnassets"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/comments"
+	"github.com/grafana/grafana/pkg/services/contentsync"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/contexthandler/authproxy"
 	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/dashboardbackup"
+	"github.com/grafana/grafana/pkg/services/dashboardcatalog"
 	"github.com/grafana/grafana/pkg/services/dashboardimport"
 	dashboardimportservice "github.com/grafana/grafana/pkg/services/dashboardimport/service"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/services/dashboardreview"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashboardstore "github.com/grafana/grafana/pkg/services/dashboards/database"
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/service"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapstore "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
 	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
+	"github.com/grafana/grafana/pkg/services/dashboardsubscription"
+	"github.com/grafana/grafana/pkg/services/dashboardtemplates"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashverimpl"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources/service"
+	"github.com/grafana/grafana/pkg/services/datasourceusage"
+	"github.com/grafana/grafana/pkg/services/editlock"
+	"github.com/grafana/grafana/pkg/services/embedtoken"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	encryptionservice "github.com/grafana/grafana/pkg/services/encryption/service"
 	"github.com/grafana/grafana/pkg/services/export"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder/folderimpl"
+	"github.com/grafana/grafana/pkg/services/grpcapi"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
 	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
 	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/homerouting"
 	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/ldapsync"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/logannotations"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfoservice"
 	authinfodatabase "github.com/grafana/grafana/pkg/services/login/authinfoservice/database"
 	"github.com/grafana/grafana/pkg/services/login/loginservice"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattemptimpl"
+	"github.com/grafana/grafana/pkg/services/loginhistory"
 	"github.com/grafana/grafana/pkg/services/navtree/navtreeimpl"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	ngimage "github.com/grafana/grafana/pkg/services/ngalert/image"
 	ngmetrics "github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/notifications/emailstore"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/oncall"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/orglogs"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+	"github.com/grafana/grafana/pkg/services/orgtoken/orgtokenimpl"
+	"github.com/grafana/grafana/pkg/services/panelexport"
 	"github.com/grafana/grafana/pkg/services/playlist/playlistimpl"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
+	"github.com/grafana/grafana/pkg/services/pluginjobs"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsettings/service"
 	"github.com/grafana/grafana/pkg/services/preference/prefimpl"
@@ -105,10 +136,13 @@ import (
 	publicdashboardsStore "github.com/grafana/grafana/pkg/services/publicdashboards/database"
 	publicdashboardsService "github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/querycapture"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/querylibrary/querylibraryimpl"
 	"github.com/grafana/grafana/pkg/services/quota/quotaimpl"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/resourcelabel/resourcelabelimpl"
+	"github.com/grafana/grafana/pkg/services/scheduledreports"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -117,6 +151,7 @@ import (
 	secretsMigrations "github.com/grafana/grafana/pkg/services/secrets/kvstore/migrations"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	secretsMigrator "github.com/grafana/grafana/pkg/services/secrets/migrator"
+	"github.com/grafana/grafana/pkg/services/securityheaders"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/database"
 	serviceaccountsmanager "github.com/grafana/grafana/pkg/services/serviceaccounts/manager"
@@ -130,6 +165,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/store/object/sqlstash"
 	"github.com/grafana/grafana/pkg/services/store/resolver"
 	"github.com/grafana/grafana/pkg/services/store/sanitizer"
+	"github.com/grafana/grafana/pkg/services/synthetics"
 	"github.com/grafana/grafana/pkg/services/tag"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/services/team/teamimpl"
@@ -140,8 +176,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/temp_user/tempuserimpl"
 	"github.com/grafana/grafana/pkg/services/thumbs"
 	"github.com/grafana/grafana/pkg/services/thumbs/dashboardthumbsimpl"
+	"github.com/grafana/grafana/pkg/services/traceannotations"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
+	"github.com/grafana/grafana/pkg/services/userdeactivation"
+	"github.com/grafana/grafana/pkg/services/userexport"
+	"github.com/grafana/grafana/pkg/services/variablecache"
+	"github.com/grafana/grafana/pkg/services/variableconstraints"
+	"github.com/grafana/grafana/pkg/services/webhooks"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor"
 	"github.com/grafana/grafana/pkg/tsdb/cloudmonitoring"
@@ -160,6 +202,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/phlare"
 	"github.com/grafana/grafana/pkg/tsdb/postgres"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+	"github.com/grafana/grafana/pkg/tsdb/syntheticsds"
 	"github.com/grafana/grafana/pkg/tsdb/tempo"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
 )
@@ -174,6 +217,7 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(alerting.UsageStatsQuerier), new(*alerting.AlertEngine)),
 	setting.NewCfgFromArgs,
 	New,
+	startup.ProvideService,
 	api.ProvideHTTPServer,
 	query.ProvideService,
 	bus.ProvideBus,
@@ -191,6 +235,8 @@ var wireBasicSet = wire.NewSet(
 	updatechecker.ProvidePluginsService,
 	uss.ProvideService,
 	wire.Bind(new(usagestats.Service), new(*uss.UsageStats)),
+	wire.Bind(new(usagestats.UserUsageTracker), new(*uss.UsageStats)),
+	wire.Bind(new(usagestats.DataSourceUsageTracker), new(*uss.UsageStats)),
 	registry.ProvideService,
 	wire.Bind(new(registry.Service), new(*registry.InMemory)),
 	pluginsCfg.ProvideConfig,
@@ -223,6 +269,11 @@ var wireBasicSet = wire.NewSet(
 	httpclientprovider.New,
 	wire.Bind(new(httpclient.Provider), new(*sdkhttpclient.Provider)),
 	serverlock.ProvideService,
+	schedule.ProvideRegistry,
+	leaderelection.ProvideService,
+	wire.Bind(new(leaderelection.Service), new(*leaderelection.LeaderElectionService)),
+	semaphore.ProvideService,
+	wire.Bind(new(semaphore.Service), new(*semaphore.SemaphoreService)),
 	annotationsimpl.ProvideCleanupService,
 	wire.Bind(new(annotations.Cleaner), new(*annotationsimpl.CleanupServiceImpl)),
 	cleanup.ProvideService,
@@ -230,6 +281,66 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(shorturls.Service), new(*shorturls.ShortURLService)),
 	queryhistory.ProvideService,
 	wire.Bind(new(queryhistory.Service), new(*queryhistory.QueryHistoryService)),
+	userexport.ProvideService,
+	wire.Bind(new(userexport.Service), new(*userexport.UserExportService)),
+	userdeactivation.ProvideService,
+	loginhistory.ProvideService,
+	wire.Bind(new(loginhistory.Service), new(*loginhistory.LoginHistoryService)),
+	pluginjobs.ProvideService,
+	wire.Bind(new(pluginjobs.Service), new(*pluginjobs.PluginJobsService)),
+	panelexport.ProvideService,
+	scheduledreports.ProvideService,
+	notificationcenter.ProvideService,
+	wire.Bind(new(notificationcenter.Service), new(*notificationcenter.NotificationCenterService)),
+	announcements.ProvideService,
+	wire.Bind(new(announcements.Service), new(*announcements.AnnouncementService)),
+	dashboardcatalog.ProvideService,
+	wire.Bind(new(dashboardcatalog.Service), new(*dashboardcatalog.CatalogService)),
+	dashboardlint.ProvideService,
+	wire.Bind(new(dashboardlint.Service), new(*dashboardlint.LintService)),
+	homerouting.ProvideService,
+	wire.Bind(new(homerouting.Service), new(*homerouting.HomeRoutingService)),
+	variablecache.ProvideService,
+	wire.Bind(new(variablecache.Service), new(*variablecache.VariableCacheService)),
+	querycapture.ProvideService,
+	wire.Bind(new(querycapture.Service), new(*querycapture.QueryCaptureService)),
+	networkpolicy.ProvideService,
+	wire.Bind(new(networkpolicy.Service), new(*networkpolicy.NetworkPolicyService)),
+	securityheaders.ProvideService,
+	wire.Bind(new(securityheaders.Service), new(*securityheaders.SecurityHeadersService)),
+	embedtoken.ProvideService,
+	wire.Bind(new(embedtoken.Service), new(*embedtoken.EmbedTokenService)),
+	dashboardsubscription.ProvideService,
+	wire.Bind(new(dashboardsubscription.Service), new(*dashboardsubscription.SubscriptionService)),
+	dashboardreview.ProvideService,
+	wire.Bind(new(dashboardreview.Service), new(*dashboardreview.ReviewService)),
+	dashboardbackup.ProvideService,
+	wire.Bind(new(dashboardbackup.Service), new(*dashboardbackup.BackupService)),
+	editlock.ProvideService,
+	wire.Bind(new(editlock.Service), new(*editlock.EditLockService)),
+	oncall.ProvideService,
+	wire.Bind(new(oncall.Service), new(*oncall.OnCallService)),
+	synthetics.ProvideService,
+	wire.Bind(new(synthetics.Service), new(*synthetics.SyntheticsService)),
+	datasourceusage.ProvideService,
+	wire.Bind(new(datasourceusage.Service), new(*datasourceusage.UsageService)),
+	traceannotations.ProvideService,
+	wire.Bind(new(traceannotations.Service), new(*traceannotations.TraceAnnotationService)),
+	logannotations.ProvideService,
+	wire.Bind(new(logannotations.Service), new(*logannotations.LogAnnotationService)),
+	ldapsync.ProvideService,
+	wire.Bind(new(ldapsync.Service), new(*ldapsync.LDAPSyncService)),
+	variableconstraints.ProvideService,
+	wire.Bind(new(variableconstraints.Service), new(*variableconstraints.ConstraintService)),
+	orglogs.ProvideService,
+	wire.Bind(new(orglogs.Service), new(*orglogs.OrgLogsService)),
+	orgtokenimpl.ProvideService,
+	wire.Bind(new(orgtoken.Service), new(*orgtokenimpl.OrgTokenService)),
+	contentsync.ProvideService,
+	dashboardtemplates.ProvideService,
+	cdnassets.ProvideService,
+	webhooks.ProvideService,
+	resourcelabelimpl.ProvideService,
 	correlations.ProvideService,
 	wire.Bind(new(correlations.Service), new(*correlations.CorrelationsService)),
 	quotaimpl.ProvideService,
@@ -263,8 +374,11 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(libraryelements.Service), new(*libraryelements.LibraryElementService)),
 	notifications.ProvideService,
 	notifications.ProvideSmtpService,
+	emailstore.ProvideStore,
+	wire.Bind(new(notifications.EmailStore), new(*emailstore.Store)),
 	tracing.ProvideService,
 	metrics.ProvideService,
+	profiler.ProvideService,
 	testdatasource.ProvideService,
 	opentsdb.ProvideService,
 	social.ProvideService,
@@ -290,6 +404,7 @@ var wireBasicSet = wire.NewSet(
 	secretsMigrator.ProvideSecretsMigrator,
 	wire.Bind(new(secrets.Migrator), new(*secretsMigrator.SecretsMigrator)),
 	grafanads.ProvideService,
+	syntheticsds.ProvideService,
 	wire.Bind(new(dashboardsnapshots.Store), new(*dashsnapstore.DashboardSnapshotStore)),
 	dashsnapstore.ProvideStore,
 	wire.Bind(new(dashboardsnapshots.Service), new(*dashsnapsvc.ServiceImpl)),
@@ -357,6 +472,7 @@ var wireBasicSet = wire.NewSet(
 	grpcserver.ProvideService,
 	grpcserver.ProvideHealthService,
 	grpcserver.ProvideReflectionService,
+	grpcapi.ProvideService,
 	interceptors.ProvideAuthenticator,
 	kind.ProvideService, // The registry of known kinds
 	sqlstash.ProvideSQLObjectServer,
@@ -367,6 +483,7 @@ var wireBasicSet = wire.NewSet(
 	loginattemptimpl.ProvideService,
 	wire.Bind(new(loginattempt.Service), new(*loginattemptimpl.Service)),
 	secretsMigrations.ProvideDataSourceMigrationService,
+	secretsMigrations.ProvidePluginSettingMigrationService,
 	secretsMigrations.ProvideMigrateToPluginService,
 	secretsMigrations.ProvideMigrateFromPluginService,
 	secretsMigrations.ProvideSecretMigrationProvider,
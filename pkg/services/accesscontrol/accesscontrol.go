@@ -31,6 +31,8 @@ type Service interface {
 	// DeleteUserPermissions removes all permissions user has in org and all permission to that user
 	// If orgID is set to 0 remove permissions from all orgs
 	DeleteUserPermissions(ctx context.Context, orgID, userID int64) error
+	// BatchRoleAssignments grants and revokes role assignments of users, teams and service accounts in one transaction
+	BatchRoleAssignments(ctx context.Context, user *user.SignedInUser, cmd *BatchRoleAssignmentsCommand) (*BatchRoleAssignmentsResult, error)
 	// DeclareFixedRoles allows the caller to declare, to the service, fixed roles and their
 	// assignments to organization roles ("Viewer", "Editor", "Admin") or "Grafana Admin"
 	DeclareFixedRoles(registrations ...RoleRegistration) error
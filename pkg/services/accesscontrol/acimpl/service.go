@@ -3,6 +3,8 @@ package acimpl
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -30,11 +32,11 @@ const (
 )
 
 func ProvideService(cfg *setting.Cfg, store db.DB, routeRegister routing.RouteRegister, cache *localcache.CacheService,
-	features *featuremgmt.FeatureManager) (*Service, error) {
+	accessControl accesscontrol.AccessControl, features *featuremgmt.FeatureManager) (*Service, error) {
 	service := ProvideOSSService(cfg, database.ProvideService(store), cache, features)
 
 	if !accesscontrol.IsDisabled(cfg) {
-		api.NewAccessControlAPI(routeRegister, service).RegisterAPIEndpoints()
+		api.NewAccessControlAPI(routeRegister, accessControl, service).RegisterAPIEndpoints()
 		if err := accesscontrol.DeclareFixedRoles(service); err != nil {
 			return nil, err
 		}
@@ -59,6 +61,9 @@ func ProvideOSSService(cfg *setting.Cfg, store store, cache *localcache.CacheSer
 type store interface {
 	GetUserPermissions(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.Permission, error)
 	DeleteUserPermissions(ctx context.Context, orgID, userID int64) error
+	BatchRoleAssignments(ctx context.Context, cmd *accesscontrol.BatchRoleAssignmentsCommand,
+		canAssign func(permissions []accesscontrol.Permission) bool, isDeclared func(roleUID string) bool) (*accesscontrol.BatchRoleAssignmentsResult, error)
+	GetTeamMembers(ctx context.Context, orgID int64, teamIDs []int64) ([]accesscontrol.TeamMember, error)
 }
 
 // Service is the service implementing role based access control.
@@ -159,6 +164,114 @@ func (s *Service) DeleteUserPermissions(ctx context.Context, orgID int64, userID
 	return s.store.DeleteUserPermissions(ctx, orgID, userID)
 }
 
+// BatchRoleAssignments grants and revokes the role assignments of the batch in one transaction. Users
+// other than Grafana Admins need the role assignment actions on the users, service accounts and
+// teams of the batch, and can only assign roles with permissions they have themselves.
+func (s *Service) BatchRoleAssignments(ctx context.Context, signedInUser *user.SignedInUser, cmd *accesscontrol.BatchRoleAssignmentsCommand) (*accesscontrol.BatchRoleAssignmentsResult, error) {
+	if len(cmd.Assignments) == 0 {
+		return nil, accesscontrol.ErrRoleAssignmentsRequired
+	}
+	if len(cmd.Assignments) > accesscontrol.MaxRoleAssignmentsPerBatch {
+		return nil, accesscontrol.ErrRoleAssignmentsTooMany
+	}
+
+	var granted map[string][]string
+	if !signedInUser.IsGrafanaAdmin {
+		permissions, err := s.GetUserPermissions(ctx, signedInUser, accesscontrol.Options{ReloadCache: true})
+		if err != nil {
+			return nil, err
+		}
+		granted = accesscontrol.GroupScopesByAction(permissions)
+		for i, a := range cmd.Assignments {
+			if evaluator := assignmentEvaluator(a); evaluator != nil && !evaluator.Evaluate(granted) {
+				return nil, fmt.Errorf("%w: assignment %d", accesscontrol.ErrRoleAssignmentsDenied, i)
+			}
+		}
+	}
+	canAssign := func(permissions []accesscontrol.Permission) bool {
+		if signedInUser.IsGrafanaAdmin {
+			return true
+		}
+		for _, p := range permissions {
+			evaluator := accesscontrol.EvalPermission(p.Action)
+			if p.Scope != "" {
+				evaluator = accesscontrol.EvalPermission(p.Action, p.Scope)
+			}
+			if !evaluator.Evaluate(granted) {
+				return false
+			}
+		}
+		return true
+	}
+
+	result, err := s.store.BatchRoleAssignments(ctx, cmd, canAssign, s.isDeclaredRole)
+	if err != nil || !result.Applied {
+		return result, err
+	}
+
+	teamIDs := make([]int64, 0)
+	for _, res := range result.Results {
+		switch {
+		case res.Status == accesscontrol.RoleAssignmentStatusUnchanged:
+		case res.TeamID != 0:
+			teamIDs = append(teamIDs, res.TeamID)
+		case res.ServiceAccountID != 0:
+			s.ClearUserPermissionCache(&user.SignedInUser{OrgID: cmd.OrgID, UserID: res.ServiceAccountID, IsServiceAccount: true})
+		default:
+			s.ClearUserPermissionCache(&user.SignedInUser{OrgID: cmd.OrgID, UserID: res.UserID})
+		}
+	}
+	if len(teamIDs) > 0 {
+		members, err := s.store.GetTeamMembers(ctx, cmd.OrgID, teamIDs)
+		if err != nil {
+			// the assignments are changed, the permissions of the members expire from the cache
+			s.log.Warn("Failed to clear the cached permissions of team members", "orgId", cmd.OrgID, "error", err)
+			return result, nil
+		}
+		for _, m := range members {
+			s.ClearUserPermissionCache(&user.SignedInUser{OrgID: cmd.OrgID, UserID: m.UserID, IsServiceAccount: m.IsServiceAccount})
+		}
+	}
+	return result, nil
+}
+
+// isDeclaredRole returns true if the UID belongs to a basic role or a declared fixed role. They are
+// kept in memory rather than in the database, so they can't be assigned like custom roles.
+func (s *Service) isDeclaredRole(uid string) bool {
+	if strings.HasPrefix(uid, accesscontrol.BasicRoleUIDPrefix) {
+		return true
+	}
+	for _, role := range s.roles {
+		if role.UID == uid {
+			return true
+		}
+	}
+	declared := false
+	s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+		declared = registration.Role.UID == uid || registration.Role.Name == uid
+		return !declared
+	})
+	return declared
+}
+
+// assignmentEvaluator returns the permission needed to change the role assignment, or nil if the
+// assignment has no valid target, which the store reports.
+func assignmentEvaluator(a accesscontrol.RoleAssignment) accesscontrol.Evaluator {
+	usersAction, teamsAction := accesscontrol.ActionUsersRolesAdd, accesscontrol.ActionTeamsRolesAdd
+	if a.Action == accesscontrol.RoleAssignmentRevoke {
+		usersAction, teamsAction = accesscontrol.ActionUsersRolesRemove, accesscontrol.ActionTeamsRolesRemove
+	}
+	switch {
+	case a.TeamID != 0:
+		return accesscontrol.EvalPermission(teamsAction, accesscontrol.Scope("teams", "id", strconv.FormatInt(a.TeamID, 10)))
+	case a.ServiceAccountID != 0:
+		return accesscontrol.EvalPermission(usersAction, accesscontrol.Scope("users", "id", strconv.FormatInt(a.ServiceAccountID, 10)))
+	case a.UserID != 0:
+		return accesscontrol.EvalPermission(usersAction, accesscontrol.Scope("users", "id", strconv.FormatInt(a.UserID, 10)))
+	}
+	return nil
+}
+
 // DeclareFixedRoles allow the caller to declare, to the service, fixed roles and their assignments
 // to organization roles ("Viewer", "Editor", "Admin") or "Grafana Admin"
 func (s *Service) DeclareFixedRoles(registrations ...accesscontrol.RoleRegistration) error {
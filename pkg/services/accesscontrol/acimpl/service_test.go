@@ -65,6 +65,7 @@ func TestUsageMetrics(t *testing.T) {
 				db.InitTestDB(t),
 				routing.NewRouteRegister(),
 				localcache.ProvideService(),
+				ProvideAccessControl(cfg),
 				featuremgmt.WithFeatures(),
 			)
 			require.NoError(t, errInitAc)
@@ -437,3 +438,78 @@ func TestPermissionCacheKey(t *testing.T) {
 		})
 	}
 }
+
+type fakeAssignmentStore struct {
+	store
+	permissions []accesscontrol.Permission
+	result      *accesscontrol.BatchRoleAssignmentsResult
+	members     []accesscontrol.TeamMember
+}
+
+func (f *fakeAssignmentStore) GetUserPermissions(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.Permission, error) {
+	return f.permissions, nil
+}
+
+func (f *fakeAssignmentStore) BatchRoleAssignments(ctx context.Context, cmd *accesscontrol.BatchRoleAssignmentsCommand,
+	canAssign func(permissions []accesscontrol.Permission) bool, isDeclared func(roleUID string) bool) (*accesscontrol.BatchRoleAssignmentsResult, error) {
+	return f.result, nil
+}
+
+func (f *fakeAssignmentStore) GetTeamMembers(ctx context.Context, orgID int64, teamIDs []int64) ([]accesscontrol.TeamMember, error) {
+	return f.members, nil
+}
+
+func TestService_BatchRoleAssignments(t *testing.T) {
+	signedInUser := &user.SignedInUser{OrgID: 1, UserID: 1, OrgRole: "Editor"}
+	teamGrant := accesscontrol.RoleAssignment{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", TeamID: 5}
+
+	setup := func(permissions ...accesscontrol.Permission) (*Service, *fakeAssignmentStore) {
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = true
+		fake := &fakeAssignmentStore{
+			permissions: permissions,
+			result: &accesscontrol.BatchRoleAssignmentsResult{Applied: true, Results: []accesscontrol.RoleAssignmentResult{
+				{RoleAssignment: teamGrant, Status: accesscontrol.RoleAssignmentStatusGranted},
+			}},
+			members: []accesscontrol.TeamMember{{UserID: 7}},
+		}
+		return ProvideOSSService(cfg, fake, localcache.ProvideService(), featuremgmt.WithFeatures()), fake
+	}
+
+	t.Run("should deny assignments to targets outside of the scopes of the caller", func(t *testing.T) {
+		s, _ := setup(accesscontrol.Permission{Action: accesscontrol.ActionTeamsRolesAdd, Scope: "teams:id:4"})
+		_, err := s.BatchRoleAssignments(context.Background(), signedInUser, &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID:       1,
+			Assignments: []accesscontrol.RoleAssignment{teamGrant},
+		})
+		require.ErrorIs(t, err, accesscontrol.ErrRoleAssignmentsDenied)
+	})
+
+	t.Run("should recognize basic and declared fixed roles", func(t *testing.T) {
+		s, _ := setup()
+		require.NoError(t, s.DeclareFixedRoles(accesscontrol.RoleRegistration{
+			Role:   accesscontrol.RoleDTO{Name: "fixed:reports:reader", UID: "fixed_reports_reader"},
+			Grants: []string{"Viewer"},
+		}))
+		assert.True(t, s.isDeclaredRole("fixed_reports_reader"))
+		assert.True(t, s.isDeclaredRole("fixed:reports:reader"))
+		assert.True(t, s.isDeclaredRole("basic_viewer"))
+		assert.False(t, s.isDeclaredRole("reports_reader"))
+	})
+
+	t.Run("should clear the cached permissions of the members of changed teams", func(t *testing.T) {
+		s, _ := setup(accesscontrol.Permission{Action: accesscontrol.ActionTeamsRolesAdd, Scope: "teams:id:5"})
+		member := &user.SignedInUser{OrgID: 1, UserID: 7}
+		key, err := permissionCacheKey(member)
+		require.NoError(t, err)
+		s.cache.Set(key, []accesscontrol.Permission{}, 0)
+
+		_, err = s.BatchRoleAssignments(context.Background(), signedInUser, &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID:       1,
+			Assignments: []accesscontrol.RoleAssignment{teamGrant},
+		})
+		require.NoError(t, err)
+		_, cached := s.cache.Get(key)
+		assert.False(t, cached)
+	})
+}
@@ -11,9 +11,10 @@ var _ accesscontrol.Service = new(FakeService)
 var _ accesscontrol.RoleRegistry = new(FakeService)
 
 type FakeService struct {
-	ExpectedErr         error
-	ExpectedDisabled    bool
-	ExpectedPermissions []accesscontrol.Permission
+	ExpectedErr                  error
+	ExpectedDisabled             bool
+	ExpectedPermissions          []accesscontrol.Permission
+	ExpectedRoleAssignmentResult *accesscontrol.BatchRoleAssignmentsResult
 }
 
 func (f FakeService) GetUsageStats(ctx context.Context) map[string]interface{} {
@@ -30,6 +31,10 @@ func (f FakeService) DeleteUserPermissions(ctx context.Context, orgID, userID in
 	return f.ExpectedErr
 }
 
+func (f FakeService) BatchRoleAssignments(ctx context.Context, user *user.SignedInUser, cmd *accesscontrol.BatchRoleAssignmentsCommand) (*accesscontrol.BatchRoleAssignmentsResult, error) {
+	return f.ExpectedRoleAssignmentResult, f.ExpectedErr
+}
+
 func (f FakeService) DeclareFixedRoles(registrations ...accesscontrol.RoleRegistration) error {
 	return f.ExpectedErr
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -8,25 +9,36 @@ import (
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
 )
 
-func NewAccessControlAPI(router routing.RouteRegister, service ac.Service) *AccessControlAPI {
+func NewAccessControlAPI(router routing.RouteRegister, accesscontrol ac.AccessControl, service ac.Service) *AccessControlAPI {
 	return &AccessControlAPI{
 		RouteRegister: router,
+		AccessControl: accesscontrol,
 		Service:       service,
 	}
 }
 
 type AccessControlAPI struct {
 	Service       ac.Service
+	AccessControl ac.AccessControl
 	RouteRegister routing.RouteRegister
 }
 
 func (api *AccessControlAPI) RegisterAPIEndpoints() {
+	authorize := ac.Middleware(api.AccessControl)
 	// Users
 	api.RouteRegister.Group("/api/access-control", func(rr routing.RouteRegister) {
 		rr.Get("/user/actions", middleware.ReqSignedIn, routing.Wrap(api.getUserActions))
 		rr.Get("/user/permissions", middleware.ReqSignedIn, routing.Wrap(api.getUserPermissions))
+		// the service checks the assignments of the batch against the scopes of the permissions
+		rr.Post("/assignments/batch", authorize(middleware.ReqSignedIn, ac.EvalAny(
+			ac.EvalPermission(ac.ActionUsersRolesAdd),
+			ac.EvalPermission(ac.ActionUsersRolesRemove),
+			ac.EvalPermission(ac.ActionTeamsRolesAdd),
+			ac.EvalPermission(ac.ActionTeamsRolesRemove),
+		)), routing.Wrap(api.batchRoleAssignments))
 	})
 }
 
@@ -53,3 +65,25 @@ func (api *AccessControlAPI) getUserPermissions(c *models.ReqContext) response.R
 
 	return response.JSON(http.StatusOK, ac.GroupScopesByAction(permissions))
 }
+
+// POST /api/access-control/assignments/batch
+func (api *AccessControlAPI) batchRoleAssignments(c *models.ReqContext) response.Response {
+	cmd := ac.BatchRoleAssignmentsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+
+	result, err := api.Service.BatchRoleAssignments(c.Req.Context(), c.SignedInUser, &cmd)
+	switch {
+	case errors.Is(err, ac.ErrRoleAssignmentsInvalid):
+		return response.JSON(http.StatusBadRequest, result)
+	case errors.Is(err, ac.ErrRoleAssignmentsRequired), errors.Is(err, ac.ErrRoleAssignmentsTooMany):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ac.ErrRoleAssignmentsDenied):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	case err != nil:
+		return response.Error(http.StatusInternalServerError, "Failed to change role assignments", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
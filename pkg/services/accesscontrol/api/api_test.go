@@ -3,12 +3,15 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web/webtest"
@@ -38,7 +41,7 @@ func TestAPI_getUserActions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedPermissions: tt.permissions}
-			api := NewAccessControlAPI(routing.NewRouteRegister(), acSvc)
+			api := NewAccessControlAPI(routing.NewRouteRegister(), mock.New(), acSvc)
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -91,7 +94,7 @@ func TestAPI_getUserPermissions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedPermissions: tt.permissions}
-			api := NewAccessControlAPI(routing.NewRouteRegister(), acSvc)
+			api := NewAccessControlAPI(routing.NewRouteRegister(), mock.New(), acSvc)
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -116,3 +119,84 @@ func TestAPI_getUserPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_batchRoleAssignments(t *testing.T) {
+	type testCase struct {
+		desc           string
+		permissions    map[string][]string
+		body           string
+		expectedResult *ac.BatchRoleAssignmentsResult
+		expectedErr    error
+		expectedCode   int
+	}
+
+	assignment := ac.RoleAssignment{Action: ac.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: 2}
+	tests := []testCase{
+		{
+			desc: "Should return the result of the batch",
+			permissions: map[string][]string{ac.ActionUsersRolesAdd: {ac.ScopeUsersAll}},
+			body: `{"assignments": [{"action": "grant", "roleUid": "reports_reader", "userId": 2}]}`,
+			expectedResult: &ac.BatchRoleAssignmentsResult{Applied: true, Results: []ac.RoleAssignmentResult{
+				{RoleAssignment: assignment, Status: ac.RoleAssignmentStatusGranted},
+			}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc: "Should return the result of an invalid batch as a bad request",
+			permissions: map[string][]string{ac.ActionUsersRolesAdd: {ac.ScopeUsersAll}},
+			body: `{"assignments": [{"action": "grant", "roleUid": "reports_reader", "userId": 2}]}`,
+			expectedResult: &ac.BatchRoleAssignmentsResult{Results: []ac.RoleAssignmentResult{
+				{RoleAssignment: assignment, Status: ac.RoleAssignmentStatusInvalid, Error: "role not found"},
+			}},
+			expectedErr:  ac.ErrRoleAssignmentsInvalid,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "Should reject a batch which is too large",
+			permissions:  map[string][]string{ac.ActionUsersRolesAdd: {ac.ScopeUsersAll}},
+			body:         `{"assignments": []}`,
+			expectedErr:  ac.ErrRoleAssignmentsTooMany,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "Should deny assignments the service rejects",
+			permissions:  map[string][]string{ac.ActionTeamsRolesAdd: {"teams:id:1"}},
+			body:         `{"assignments": [{"action": "grant", "roleUid": "reports_reader", "userId": 2}]}`,
+			expectedErr:  ac.ErrRoleAssignmentsDenied,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "Should not allow users without a role assignment permission",
+			permissions:  map[string][]string{ac.ActionOrgUsersWrite: {ac.ScopeUsersAll}},
+			body:         `{"assignments": []}`,
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			acSvc := actest.FakeService{ExpectedRoleAssignmentResult: tt.expectedResult, ExpectedErr: tt.expectedErr}
+			api := NewAccessControlAPI(routing.NewRouteRegister(), mock.New(), acSvc)
+			api.RegisterAPIEndpoints()
+
+			server := webtest.NewServer(t, api.RouteRegister)
+			req := server.NewPostRequest("/api/access-control/assignments/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgID:       1,
+				OrgRole:     org.RoleEditor,
+				Permissions: map[int64]map[string][]string{1: tt.permissions},
+			})
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedResult != nil {
+				var output ac.BatchRoleAssignmentsResult
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&output))
+				require.Equal(t, *tt.expectedResult, output)
+			}
+		})
+	}
+}
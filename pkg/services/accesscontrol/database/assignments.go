@@ -0,0 +1,240 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// fixedOrBasicRoleProblem is the problem of assignments of fixed and basic roles, which are granted
+// through the organization role of users rather than assigned.
+const fixedOrBasicRoleProblem = "fixed and basic roles can't be assigned, they are granted through the organization role"
+
+// BatchRoleAssignments validates the role assignments of the batch and, unless the batch is a dry run
+// or any of them is invalid, changes all of them in one transaction. canAssign tells whether a role
+// with the given permissions may be granted or revoked, and isDeclared whether a role UID belongs to
+// a fixed or basic role declared in memory.
+func (s *AccessControlStore) BatchRoleAssignments(ctx context.Context, cmd *accesscontrol.BatchRoleAssignmentsCommand,
+	canAssign func(permissions []accesscontrol.Permission) bool, isDeclared func(roleUID string) bool) (*accesscontrol.BatchRoleAssignmentsResult, error) {
+	result := &accesscontrol.BatchRoleAssignmentsResult{
+		DryRun:  cmd.DryRun,
+		Results: make([]accesscontrol.RoleAssignmentResult, 0, len(cmd.Assignments)),
+	}
+
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		checker := &assignmentChecker{
+			sess:       sess,
+			userTable:  s.sql.GetDialect().Quote("user"),
+			orgID:      cmd.OrgID,
+			canAssign:  canAssign,
+			isDeclared: isDeclared,
+			roles:      map[string]*accesscontrol.Role{},
+			allowed:    map[int64]bool{},
+			actions:    map[string]string{},
+		}
+
+		roleIDs := make([]int64, len(cmd.Assignments))
+		invalid := false
+		for i, assignment := range cmd.Assignments {
+			roleID, status, problem, err := checker.check(assignment)
+			if err != nil {
+				return err
+			}
+			if problem != "" {
+				invalid = true
+				status = accesscontrol.RoleAssignmentStatusInvalid
+			}
+			roleIDs[i] = roleID
+			result.Results = append(result.Results, accesscontrol.RoleAssignmentResult{
+				RoleAssignment: assignment,
+				Status:         status,
+				Error:          problem,
+			})
+		}
+
+		if invalid {
+			return accesscontrol.ErrRoleAssignmentsInvalid
+		}
+		if cmd.DryRun {
+			return nil
+		}
+
+		now := time.Now()
+		for i, res := range result.Results {
+			if err := applyRoleAssignment(sess, cmd.OrgID, roleIDs[i], res, now); err != nil {
+				return err
+			}
+		}
+		result.Applied = true
+		return nil
+	})
+
+	return result, err
+}
+
+// GetTeamMembers returns the members of the teams of the organization.
+func (s *AccessControlStore) GetTeamMembers(ctx context.Context, orgID int64, teamIDs []int64) ([]accesscontrol.TeamMember, error) {
+	members := make([]accesscontrol.TeamMember, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		userTable := s.sql.GetDialect().Quote("user")
+		return sess.Table("team_member").
+			Join("INNER", userTable, userTable+".id = team_member.user_id").
+			Where("team_member.org_id = ?", orgID).
+			In("team_member.team_id", teamIDs).
+			Distinct("team_member.user_id", userTable+".is_service_account").
+			Find(&members)
+	})
+	return members, err
+}
+
+// assignmentChecker validates the role assignments of a batch, caching the roles it looked up.
+type assignmentChecker struct {
+	sess       *db.Session
+	userTable  string
+	orgID      int64
+	canAssign  func(permissions []accesscontrol.Permission) bool
+	isDeclared func(roleUID string) bool
+
+	// roles are the roles by UID, nil if the role doesn't exist
+	roles map[string]*accesscontrol.Role
+	// allowed tells by role ID whether the role may be assigned
+	allowed map[int64]bool
+	// actions are the actions of the assignments checked already
+	actions map[string]string
+}
+
+// check returns the ID of the role and the status of the assignment, or the problem making it invalid.
+func (c *assignmentChecker) check(a accesscontrol.RoleAssignment) (int64, string, string, error) {
+	if a.Action != accesscontrol.RoleAssignmentGrant && a.Action != accesscontrol.RoleAssignmentRevoke {
+		return 0, "", "action must be grant or revoke", nil
+	}
+
+	targets := 0
+	for _, id := range []int64{a.UserID, a.TeamID, a.ServiceAccountID} {
+		if id != 0 {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return 0, "", "exactly one of userId, teamId and serviceAccountId is required", nil
+	}
+
+	key := fmt.Sprintf("%s:%d:%d:%d", a.RoleUID, a.UserID, a.TeamID, a.ServiceAccountID)
+	if action, ok := c.actions[key]; ok {
+		if action != a.Action {
+			return 0, "", "the assignment is both granted and revoked by the batch", nil
+		}
+		// the assignment is changed by the first occurrence only
+		return 0, accesscontrol.RoleAssignmentStatusUnchanged, "", nil
+	}
+	c.actions[key] = a.Action
+
+	role, err := c.getRole(a.RoleUID)
+	if err != nil {
+		return 0, "", "", err
+	}
+	if (role == nil && c.isDeclared(a.RoleUID)) || (role != nil && (role.IsFixed() || role.IsBasic())) {
+		return 0, "", fixedOrBasicRoleProblem, nil
+	}
+	if role == nil {
+		return 0, "", "role not found", nil
+	}
+
+	allowed, err := c.isAllowed(role.ID)
+	if err != nil {
+		return 0, "", "", err
+	}
+	if !allowed {
+		return 0, "", "the role has permissions you don't have", nil
+	}
+
+	var exists, assigned bool
+	switch {
+	case a.TeamID != 0:
+		exists, err = c.sess.Table("team").Where("org_id = ? AND id = ?", c.orgID, a.TeamID).Exist()
+		if err == nil && exists {
+			assigned, err = c.sess.Table("team_role").Where("org_id = ? AND team_id = ? AND role_id = ?", c.orgID, a.TeamID, role.ID).Exist()
+		}
+	default:
+		userID, isServiceAccount := a.UserID, false
+		if a.ServiceAccountID != 0 {
+			userID, isServiceAccount = a.ServiceAccountID, true
+		}
+		exists, err = c.sess.Table("org_user").
+			Join("INNER", c.userTable, c.userTable+".id = org_user.user_id").
+			Where("org_user.org_id = ? AND org_user.user_id = ? AND "+c.userTable+".is_service_account = ?", c.orgID, userID, isServiceAccount).
+			Exist()
+		if err == nil && exists {
+			assigned, err = c.sess.Table("user_role").Where("org_id = ? AND user_id = ? AND role_id = ?", c.orgID, userID, role.ID).Exist()
+		}
+	}
+	if err != nil {
+		return 0, "", "", err
+	}
+	if !exists {
+		return 0, "", "the user, team or service account isn't part of the organization", nil
+	}
+
+	switch {
+	case a.Action == accesscontrol.RoleAssignmentGrant && !assigned:
+		return role.ID, accesscontrol.RoleAssignmentStatusGranted, "", nil
+	case a.Action == accesscontrol.RoleAssignmentRevoke && assigned:
+		return role.ID, accesscontrol.RoleAssignmentStatusRevoked, "", nil
+	}
+	return role.ID, accesscontrol.RoleAssignmentStatusUnchanged, "", nil
+}
+
+// getRole returns the role of the organization or the global role with the UID. Managed roles
+// belong to their resources and can't be assigned.
+func (c *assignmentChecker) getRole(uid string) (*accesscontrol.Role, error) {
+	if role, ok := c.roles[uid]; ok {
+		return role, nil
+	}
+
+	role := &accesscontrol.Role{}
+	has, err := c.sess.Where("uid = ? AND (org_id = ? OR org_id = ?)", uid, c.orgID, accesscontrol.GlobalOrgID).Get(role)
+	if err != nil {
+		return nil, err
+	}
+	if !has || role.IsManaged() {
+		role = nil
+	}
+	c.roles[uid] = role
+	return role, nil
+}
+
+func (c *assignmentChecker) isAllowed(roleID int64) (bool, error) {
+	if allowed, ok := c.allowed[roleID]; ok {
+		return allowed, nil
+	}
+
+	var permissions []accesscontrol.Permission
+	if err := c.sess.Where("role_id = ?", roleID).Find(&permissions); err != nil {
+		return false, err
+	}
+	c.allowed[roleID] = c.canAssign(permissions)
+	return c.allowed[roleID], nil
+}
+
+func applyRoleAssignment(sess *db.Session, orgID, roleID int64, res accesscontrol.RoleAssignmentResult, now time.Time) error {
+	userID := res.UserID
+	if res.ServiceAccountID != 0 {
+		userID = res.ServiceAccountID
+	}
+
+	var err error
+	switch {
+	case res.Status == accesscontrol.RoleAssignmentStatusGranted && res.TeamID != 0:
+		_, err = sess.Insert(&accesscontrol.TeamRole{OrgID: orgID, TeamID: res.TeamID, RoleID: roleID, Created: now})
+	case res.Status == accesscontrol.RoleAssignmentStatusGranted:
+		_, err = sess.Insert(&accesscontrol.UserRole{OrgID: orgID, UserID: userID, RoleID: roleID, Created: now})
+	case res.Status == accesscontrol.RoleAssignmentStatusRevoked && res.TeamID != 0:
+		_, err = sess.Exec("DELETE FROM team_role WHERE org_id = ? AND team_id = ? AND role_id = ?", orgID, res.TeamID, roleID)
+	case res.Status == accesscontrol.RoleAssignmentStatusRevoked:
+		_, err = sess.Exec("DELETE FROM user_role WHERE org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, roleID)
+	}
+	return err
+}
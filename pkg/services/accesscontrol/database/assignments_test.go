@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestAccessControlStore_BatchRoleAssignments(t *testing.T) {
+	allowAll := func([]accesscontrol.Permission) bool { return true }
+	noneDeclared := func(string) bool { return false }
+
+	setup := func(t *testing.T) (*AccessControlStore, *sqlstore.SQLStore, int64, int64, int64) {
+		store, _, sql, teamSvc := setupTestEnv(t)
+		usr, team := createUserAndTeam(t, sql, teamSvc, 1)
+		sa, err := sql.CreateUser(context.Background(), user.CreateUserCommand{Login: "sa", OrgID: 1, IsServiceAccount: true})
+		require.NoError(t, err)
+
+		err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			now := time.Now()
+			if _, err := sess.Insert(&models.OrgUser{OrgId: 1, UserId: sa.ID, Role: org.RoleViewer, Created: now, Updated: now}); err != nil {
+				return err
+			}
+			for _, r := range []accesscontrol.Role{
+				{OrgID: 1, Name: "custom:reports:reader", UID: "reports_reader"},
+				{OrgID: 1, Name: "managed:users:1:permissions", UID: "managed_user"},
+				{OrgID: 1, Name: "fixed:reports:reader", UID: "fixed_reports_reader"},
+				{OrgID: 2, Name: "custom:other:reader", UID: "other_org"},
+			} {
+				r.Updated, r.Created = now, now
+				if _, err := sess.Insert(&r); err != nil {
+					return err
+				}
+				if _, err := sess.Insert(&accesscontrol.Permission{RoleID: r.ID, Action: "reports:read", Scope: "reports:*", Updated: now, Created: now}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return store, sql, usr.ID, team.Id, sa.ID
+	}
+
+	countAssignments := func(t *testing.T, sql *sqlstore.SQLStore) (int64, int64) {
+		var users, teams int64
+		err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			var err error
+			if users, err = sess.Table("user_role").Count(); err != nil {
+				return err
+			}
+			teams, err = sess.Table("team_role").Count()
+			return err
+		})
+		require.NoError(t, err)
+		return users, teams
+	}
+
+	t.Run("should grant and revoke the assignments of users, teams and service accounts", func(t *testing.T) {
+		store, sql, userID, teamID, saID := setup(t)
+
+		result, err := store.BatchRoleAssignments(context.Background(), &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID: 1,
+			Assignments: []accesscontrol.RoleAssignment{
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: userID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", TeamID: teamID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", ServiceAccountID: saID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: userID},
+			},
+		}, allowAll, noneDeclared)
+		require.NoError(t, err)
+		assert.True(t, result.Applied)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusGranted, result.Results[0].Status)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusGranted, result.Results[1].Status)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusGranted, result.Results[2].Status)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusUnchanged, result.Results[3].Status)
+
+		users, teams := countAssignments(t, sql)
+		assert.Equal(t, int64(2), users)
+		assert.Equal(t, int64(1), teams)
+
+		result, err = store.BatchRoleAssignments(context.Background(), &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID: 1,
+			Assignments: []accesscontrol.RoleAssignment{
+				{Action: accesscontrol.RoleAssignmentRevoke, RoleUID: "reports_reader", UserID: userID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", TeamID: teamID},
+			},
+		}, allowAll, noneDeclared)
+		require.NoError(t, err)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusRevoked, result.Results[0].Status)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusUnchanged, result.Results[1].Status)
+
+		users, teams = countAssignments(t, sql)
+		assert.Equal(t, int64(1), users)
+		assert.Equal(t, int64(1), teams)
+	})
+
+	t.Run("should not change anything on a dry run", func(t *testing.T) {
+		store, sql, userID, _, _ := setup(t)
+
+		result, err := store.BatchRoleAssignments(context.Background(), &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID:       1,
+			DryRun:      true,
+			Assignments: []accesscontrol.RoleAssignment{{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: userID}},
+		}, allowAll, noneDeclared)
+		require.NoError(t, err)
+		assert.False(t, result.Applied)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusGranted, result.Results[0].Status)
+
+		users, _ := countAssignments(t, sql)
+		assert.Equal(t, int64(0), users)
+	})
+
+	t.Run("should not change anything if an assignment is invalid", func(t *testing.T) {
+		store, sql, userID, teamID, saID := setup(t)
+
+		result, err := store.BatchRoleAssignments(context.Background(), &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID: 1,
+			Assignments: []accesscontrol.RoleAssignment{
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: userID},
+				{Action: "replace", RoleUID: "reports_reader", UserID: userID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: userID, TeamID: teamID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "managed_user", UserID: userID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "other_org", UserID: userID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: saID},
+				{Action: accesscontrol.RoleAssignmentRevoke, RoleUID: "reports_reader", UserID: userID},
+			},
+		}, allowAll, noneDeclared)
+		require.ErrorIs(t, err, accesscontrol.ErrRoleAssignmentsInvalid)
+		assert.False(t, result.Applied)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusGranted, result.Results[0].Status)
+		for _, res := range result.Results[1:] {
+			assert.Equal(t, accesscontrol.RoleAssignmentStatusInvalid, res.Status)
+			assert.NotEmpty(t, res.Error)
+		}
+
+		users, _ := countAssignments(t, sql)
+		assert.Equal(t, int64(0), users)
+	})
+
+	t.Run("should reject fixed and basic roles", func(t *testing.T) {
+		store, _, userID, _, _ := setup(t)
+
+		result, err := store.BatchRoleAssignments(context.Background(), &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID: 1,
+			Assignments: []accesscontrol.RoleAssignment{
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "fixed_reports_reader", UserID: userID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "fixed:datasources:reader", UserID: userID},
+				{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "unknown", UserID: userID},
+			},
+		}, allowAll, func(uid string) bool { return uid == "fixed:datasources:reader" })
+		require.ErrorIs(t, err, accesscontrol.ErrRoleAssignmentsInvalid)
+		assert.Equal(t, fixedOrBasicRoleProblem, result.Results[0].Error)
+		assert.Equal(t, fixedOrBasicRoleProblem, result.Results[1].Error)
+		assert.Equal(t, "role not found", result.Results[2].Error)
+	})
+
+	t.Run("should not assign roles the caller can't assign", func(t *testing.T) {
+		store, _, userID, _, _ := setup(t)
+
+		result, err := store.BatchRoleAssignments(context.Background(), &accesscontrol.BatchRoleAssignmentsCommand{
+			OrgID:       1,
+			Assignments: []accesscontrol.RoleAssignment{{Action: accesscontrol.RoleAssignmentGrant, RoleUID: "reports_reader", UserID: userID}},
+		}, func(permissions []accesscontrol.Permission) bool {
+			require.Len(t, permissions, 1)
+			return false
+		}, noneDeclared)
+		require.ErrorIs(t, err, accesscontrol.ErrRoleAssignmentsInvalid)
+		assert.Equal(t, accesscontrol.RoleAssignmentStatusInvalid, result.Results[0].Status)
+	})
+}
+
+func TestAccessControlStore_GetTeamMembers(t *testing.T) {
+	store, _, sql, teamSvc := setupTestEnv(t)
+	usr, team := createUserAndTeam(t, sql, teamSvc, 1)
+
+	members, err := store.GetTeamMembers(context.Background(), 1, []int64{team.Id})
+	require.NoError(t, err)
+	assert.Equal(t, []accesscontrol.TeamMember{{UserID: usr.ID}}, members)
+
+	members, err = store.GetTeamMembers(context.Background(), 2, []int64{team.Id})
+	require.NoError(t, err)
+	assert.Empty(t, members, "teams of other organizations should be ignored")
+}
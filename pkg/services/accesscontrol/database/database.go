@@ -40,10 +40,12 @@ func (s *AccessControlStore) GetUserPermissions(ctx context.Context, query acces
 			if len(query.Actions) > 0 {
 				q += "?" + strings.Repeat(",?", len(query.Actions)-1)
 			}
-			q += ")"
+			// permissions of provisioned custom roles are always fetched
+			q += ") OR role.name LIKE ?"
 			for _, a := range query.Actions {
 				params = append(params, a)
 			}
+			params = append(params, accesscontrol.CustomRolePrefix+"%")
 		}
 		if err := sess.SQL(q, params...).Find(&result); err != nil {
 			return err
@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -143,6 +145,38 @@ func TestAccessControlStore_GetUserPermissions(t *testing.T) {
 	}
 }
 
+func TestAccessControlStore_GetUserPermissions_CustomRoles(t *testing.T) {
+	store, _, sql, teamSvc := setupTestEnv(t)
+	user, _ := createUserAndTeam(t, sql, teamSvc, 1)
+
+	err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		now := time.Now()
+		for _, name := range []string{"custom:reports:reader", "fixed:reports:reader"} {
+			role := accesscontrol.Role{OrgID: 1, Name: name, UID: strings.ReplaceAll(name, ":", "_"), Updated: now, Created: now}
+			if _, err := sess.Insert(&role); err != nil {
+				return err
+			}
+			if _, err := sess.Insert(&accesscontrol.Permission{RoleID: role.ID, Action: "reports:read", Scope: "reports:*", Updated: now, Created: now}); err != nil {
+				return err
+			}
+			if _, err := sess.Insert(&accesscontrol.UserRole{OrgID: 1, RoleID: role.ID, UserID: user.ID, Created: now}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{
+		OrgID:   1,
+		UserID:  user.ID,
+		Actions: []string{"dashboards:write"},
+	})
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	assert.Equal(t, "reports:read", permissions[0].Action)
+}
+
 func TestAccessControlStore_DeleteUserPermissions(t *testing.T) {
 	t.Run("expect permissions in all orgs to be deleted", func(t *testing.T) {
 		store, permissionsStore, sql, teamSvc := setupTestEnv(t)
@@ -11,6 +11,11 @@ var (
 	ErrInvalidScope           = errors.New("invalid scope")
 	ErrResolverNotFound       = errors.New("no resolver found")
 	ErrPluginIDRequired       = errors.New("plugin ID is required")
+
+	ErrRoleAssignmentsInvalid  = errors.New("role assignments are invalid")
+	ErrRoleAssignmentsTooMany  = fmt.Errorf("a batch can change at most %d role assignments", MaxRoleAssignmentsPerBatch)
+	ErrRoleAssignmentsRequired = errors.New("a batch needs at least one role assignment")
+	ErrRoleAssignmentsDenied   = errors.New("you are not allowed to change the role assignments")
 )
 
 type ErrorInvalidRole struct{}
@@ -28,6 +28,7 @@ type Calls struct {
 	RegisterFixedRoles             []interface{}
 	RegisterAttributeScopeResolver []interface{}
 	DeleteUserPermissions          []interface{}
+	BatchRoleAssignments           []interface{}
 }
 
 type Mock struct {
@@ -52,6 +53,7 @@ type Mock struct {
 	RegisterFixedRolesFunc             func() error
 	RegisterScopeAttributeResolverFunc func(string, accesscontrol.ScopeAttributeResolver)
 	DeleteUserPermissionsFunc          func(context.Context, int64) error
+	BatchRoleAssignmentsFunc           func(context.Context, *user.SignedInUser, *accesscontrol.BatchRoleAssignmentsCommand) (*accesscontrol.BatchRoleAssignmentsResult, error)
 
 	scopeResolvers accesscontrol.Resolvers
 }
@@ -212,3 +214,14 @@ func (m *Mock) DeleteUserPermissions(ctx context.Context, orgID, userID int64) e
 	}
 	return nil
 }
+
+// BatchRoleAssignments grants and revokes role assignments in one transaction.
+// This mock returns an empty result unless an override is provided.
+func (m *Mock) BatchRoleAssignments(ctx context.Context, user *user.SignedInUser, cmd *accesscontrol.BatchRoleAssignmentsCommand) (*accesscontrol.BatchRoleAssignmentsResult, error) {
+	m.Calls.BatchRoleAssignments = append(m.Calls.BatchRoleAssignments, []interface{}{ctx, user, cmd})
+	// Use override if provided
+	if m.BatchRoleAssignmentsFunc != nil {
+		return m.BatchRoleAssignmentsFunc(ctx, user, cmd)
+	}
+	return &accesscontrol.BatchRoleAssignmentsResult{DryRun: cmd.DryRun}, nil
+}
@@ -41,6 +41,10 @@ func (r *Role) IsFixed() bool {
 	return strings.HasPrefix(r.Name, FixedRolePrefix)
 }
 
+func (r *Role) IsManaged() bool {
+	return strings.HasPrefix(r.Name, ManagedRolePrefix)
+}
+
 func (r *Role) IsBasic() bool {
 	return strings.HasPrefix(r.Name, BasicRolePrefix) || strings.HasPrefix(r.UID, BasicRoleUIDPrefix)
 }
@@ -186,6 +190,57 @@ type UserRole struct {
 	Created time.Time
 }
 
+const (
+	RoleAssignmentGrant  = "grant"
+	RoleAssignmentRevoke = "revoke"
+
+	RoleAssignmentStatusGranted   = "granted"
+	RoleAssignmentStatusRevoked   = "revoked"
+	RoleAssignmentStatusUnchanged = "unchanged"
+	RoleAssignmentStatusInvalid   = "invalid"
+
+	// MaxRoleAssignmentsPerBatch is the maximum number of role assignments changed by one batch.
+	MaxRoleAssignmentsPerBatch = 1000
+)
+
+// RoleAssignment grants or revokes a role of a user, a team or a service account.
+type RoleAssignment struct {
+	// Action is either grant or revoke.
+	Action           string `json:"action"`
+	RoleUID          string `json:"roleUid"`
+	UserID           int64  `json:"userId,omitempty"`
+	TeamID           int64  `json:"teamId,omitempty"`
+	ServiceAccountID int64  `json:"serviceAccountId,omitempty"`
+}
+
+// BatchRoleAssignmentsCommand grants and revokes role assignments of an organization in one
+// transaction. Either all of the assignments are changed or none of them is.
+type BatchRoleAssignmentsCommand struct {
+	OrgID       int64            `json:"-"`
+	DryRun      bool             `json:"dryRun"`
+	Assignments []RoleAssignment `json:"assignments"`
+}
+
+// RoleAssignmentResult is the outcome of a role assignment of a batch.
+type RoleAssignmentResult struct {
+	RoleAssignment
+	// Status is granted, revoked, unchanged or invalid.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchRoleAssignmentsResult struct {
+	DryRun  bool                   `json:"dryRun"`
+	Applied bool                   `json:"applied"`
+	Results []RoleAssignmentResult `json:"results"`
+}
+
+// TeamMember is a user or service account which is a member of a team.
+type TeamMember struct {
+	UserID           int64 `xorm:"user_id"`
+	IsServiceAccount bool  `xorm:"is_service_account"`
+}
+
 type BuiltinRole struct {
 	ID     int64 `json:"id" xorm:"pk autoincr 'id'"`
 	RoleID int64 `json:"roleId" xorm:"role_id"`
@@ -278,6 +333,7 @@ const (
 	ManagedRolePrefix  = "managed:"
 	BasicRolePrefix    = "basic:"
 	PluginRolePrefix   = "plugins:"
+	CustomRolePrefix   = "custom:"
 	BasicRoleUIDPrefix = "basic_"
 	RoleGrafanaAdmin   = "Grafana Admin"
 
@@ -310,6 +366,13 @@ const (
 	ActionUsersQuotasList        = "users.quotas:read"
 	ActionUsersQuotasUpdate      = "users.quotas:write"
 
+	// Role assignment actions, the scopes are users:id:<id> for users and service accounts and
+	// teams:id:<id> for teams
+	ActionUsersRolesAdd    = "users.roles:add"
+	ActionUsersRolesRemove = "users.roles:remove"
+	ActionTeamsRolesAdd    = "teams.roles:add"
+	ActionTeamsRolesRemove = "teams.roles:remove"
+
 	// Org actions
 	ActionOrgsRead             = "orgs:read"
 	ActionOrgsPreferencesRead  = "orgs.preferences:read"
@@ -335,7 +398,8 @@ const (
 	ActionServerStatsRead = "server.stats:read"
 
 	// Settings actions
-	ActionSettingsRead = "settings:read"
+	ActionSettingsRead  = "settings:read"
+	ActionSettingsWrite = "settings:write"
 
 	// Datasources actions
 	ActionDatasourcesExplore = "datasources:explore"
@@ -403,6 +467,20 @@ const (
 	// Alerting provisioning actions
 	ActionAlertingProvisioningRead  = "alert.provisioning:read"
 	ActionAlertingProvisioningWrite = "alert.provisioning:write"
+
+	// Live actions
+	ActionLivePublish = "live:publish"
+
+	// Short URL actions
+	ActionShortURLsRead  = "shorturls:read"
+	ActionShortURLsWrite = "shorturls:write"
+
+	// Webhook actions
+	ActionWebhooksRead  = "webhooks:read"
+	ActionWebhooksWrite = "webhooks:write"
+
+	// Org logs actions
+	ActionOrgsLogsRead = "orgs.logs:read"
 )
 
 var (
@@ -416,6 +494,11 @@ var (
 	ScopeAnnotationsID               = Scope(ScopeAnnotationsRoot, "id", Parameter(":annotationId"))
 	ScopeAnnotationsTypeDashboard    = ScopeAnnotationsProvider.GetResourceScopeType(annotations.Dashboard.String())
 	ScopeAnnotationsTypeOrganization = ScopeAnnotationsProvider.GetResourceScopeType(annotations.Organization.String())
+
+	// Live scopes. Channels are used as resource identifiers, e.g. live:channels:stream/telegraf/cpu.
+	// Use a wildcard to allow publishing to all channels with a prefix, e.g. live:channels:stream/telegraf/*.
+	ScopeLiveChannelsPrefix = "live:channels:"
+	ScopeLiveChannelsAll    = ScopeLiveChannelsPrefix + "*"
 )
 
 func BuiltInRolesWithParents(builtInRoles []string) map[string]struct{} {
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -124,6 +126,7 @@ func ProvideDashboardPermissions(
 		return query.Result, nil
 	}
 
+	dashboardsHooks := permissionsUpdatedHooks("dashboards")
 	options := resourcepermissions.Options{
 		Resource:          "dashboards",
 		ResourceAttribute: "uid",
@@ -163,9 +166,12 @@ func ProvideDashboardPermissions(
 			"Edit":  DashboardEditActions,
 			"Admin": DashboardAdminActions,
 		},
-		ReaderRoleName: "Dashboard permission reader",
-		WriterRoleName: "Dashboard permission writer",
-		RoleGroup:      "Dashboards",
+		OnSetUser:        dashboardsHooks.User,
+		OnSetTeam:        dashboardsHooks.Team,
+		OnSetBuiltInRole: dashboardsHooks.BuiltInRole,
+		ReaderRoleName:   "Dashboard permission reader",
+		WriterRoleName:   "Dashboard permission writer",
+		RoleGroup:        "Dashboards",
 	}
 
 	srv, err := resourcepermissions.New(options, cfg, router, license, ac, service, sql, teamService, userService)
@@ -175,6 +181,33 @@ func ProvideDashboardPermissions(
 	return &DashboardPermissionsService{srv}, nil
 }
 
+// permissionsUpdatedHooks returns the hooks publishing a ResourcePermissionsUpdated event once the
+// permissions of a dashboard or folder are committed, for example to update the search index.
+func permissionsUpdatedHooks(resource string) resourcepermissions.ResourceHooks {
+	publish := func(session *db.Session, orgID int64, uid string) {
+		session.PublishAfterCommit(&events.ResourcePermissionsUpdated{
+			Timestamp: time.Now(),
+			Resource:  resource,
+			UID:       uid,
+			OrgID:     orgID,
+		})
+	}
+	return resourcepermissions.ResourceHooks{
+		User: func(session *db.Session, orgID int64, _ accesscontrol.User, resourceID, _ string) error {
+			publish(session, orgID, resourceID)
+			return nil
+		},
+		Team: func(session *db.Session, orgID, _ int64, resourceID, _ string) error {
+			publish(session, orgID, resourceID)
+			return nil
+		},
+		BuiltInRole: func(session *db.Session, orgID int64, _, resourceID, _ string) error {
+			publish(session, orgID, resourceID)
+			return nil
+		},
+	}
+}
+
 type FolderPermissionsService struct {
 	*resourcepermissions.Service
 }
@@ -195,6 +228,7 @@ func ProvideFolderPermissions(
 	license models.Licensing, dashboardStore dashboards.Store, service accesscontrol.Service,
 	teamService team.Service, userService user.Service,
 ) (*FolderPermissionsService, error) {
+	foldersHooks := permissionsUpdatedHooks("folders")
 	options := resourcepermissions.Options{
 		Resource:          "folders",
 		ResourceAttribute: "uid",
@@ -220,9 +254,12 @@ func ProvideFolderPermissions(
 			"Edit":  append(DashboardEditActions, FolderEditActions...),
 			"Admin": append(DashboardAdminActions, FolderAdminActions...),
 		},
-		ReaderRoleName: "Folder permission reader",
-		WriterRoleName: "Folder permission writer",
-		RoleGroup:      "Folders",
+		OnSetUser:        foldersHooks.User,
+		OnSetTeam:        foldersHooks.Team,
+		OnSetBuiltInRole: foldersHooks.BuiltInRole,
+		ReaderRoleName:   "Folder permission reader",
+		WriterRoleName:   "Folder permission writer",
+		RoleGroup:        "Folders",
 	}
 	srv, err := resourcepermissions.New(options, cfg, router, license, accesscontrol, service, sql, teamService, userService)
 	if err != nil {
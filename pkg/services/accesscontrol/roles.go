@@ -74,6 +74,31 @@ var (
 		},
 	}
 
+	roleAssignmentsWriterRole = RoleDTO{
+		Name:        "fixed:role.assignments:writer",
+		DisplayName: "Role assignment writer",
+		Description: "Grant and revoke roles of users, service accounts and teams, limited to roles with permissions the user has.",
+		Group:       "Access control",
+		Permissions: []Permission{
+			{
+				Action: ActionUsersRolesAdd,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionUsersRolesRemove,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionTeamsRolesAdd,
+				Scope:  ScopeTeamsAll,
+			},
+			{
+				Action: ActionTeamsRolesRemove,
+				Scope:  ScopeTeamsAll,
+			},
+		},
+	}
+
 	SettingsReaderRole = RoleDTO{
 		Name:        "fixed:settings:reader",
 		DisplayName: "Setting reader",
@@ -87,6 +112,19 @@ var (
 		},
 	}
 
+	settingsWriterRole = RoleDTO{
+		Name:        "fixed:settings:writer",
+		DisplayName: "Setting writer",
+		Description: "Read and update Grafana instance settings.",
+		Group:       "Settings",
+		Permissions: ConcatPermissions(SettingsReaderRole.Permissions, []Permission{
+			{
+				Action: ActionSettingsWrite,
+				Scope:  ScopeSettingsAll,
+			},
+		}),
+	}
+
 	statsReaderRole = RoleDTO{
 		Name:        "fixed:stats:reader",
 		DisplayName: "Statistics reader",
@@ -187,10 +225,18 @@ func DeclareFixedRoles(service Service) error {
 		Role:   orgUsersWriterRole,
 		Grants: []string{RoleGrafanaAdmin, string(org.RoleAdmin)},
 	}
+	roleAssignmentsWriter := RoleRegistration{
+		Role:   roleAssignmentsWriterRole,
+		Grants: []string{RoleGrafanaAdmin, string(org.RoleAdmin)},
+	}
 	settingsReader := RoleRegistration{
 		Role:   SettingsReaderRole,
 		Grants: []string{RoleGrafanaAdmin},
 	}
+	settingsWriter := RoleRegistration{
+		Role:   settingsWriterRole,
+		Grants: []string{RoleGrafanaAdmin},
+	}
 	statsReader := RoleRegistration{
 		Role:   statsReaderRole,
 		Grants: []string{RoleGrafanaAdmin},
@@ -204,8 +250,8 @@ func DeclareFixedRoles(service Service) error {
 		Grants: []string{RoleGrafanaAdmin},
 	}
 
-	return service.DeclareFixedRoles(ldapReader, ldapWriter, orgUsersReader, orgUsersWriter,
-		settingsReader, statsReader, usersReader, usersWriter)
+	return service.DeclareFixedRoles(ldapReader, ldapWriter, orgUsersReader, orgUsersWriter, roleAssignmentsWriter,
+		settingsReader, settingsWriter, statsReader, usersReader, usersWriter)
 }
 
 func ConcatPermissions(permissions ...[]Permission) []Permission {
@@ -85,6 +85,7 @@ func (en *EmailNotifier) Notify(evalContext *alerting.EvalContext) error {
 	cmd := &models.SendEmailCommandSync{
 		SendEmailCommand: models.SendEmailCommand{
 			Subject: evalContext.GetNotificationTitle(),
+			OrgID:   evalContext.Rule.OrgID,
 			Data: map[string]interface{}{
 				"Title":         evalContext.GetNotificationTitle(),
 				"State":         evalContext.Rule.State,
@@ -0,0 +1,142 @@
+// Package annotationjobs has the parts the services running jobs, which periodically turn the
+// results of a data source query into annotations, have in common.
+package annotationjobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const maxResponseSize = 32 << 20
+
+// Scheduler tracks when the jobs of a service last ran.
+type Scheduler struct {
+	mu sync.Mutex
+	// lastRun is when each job last ran, by job id.
+	lastRun map[int64]time.Time
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{lastRun: map[int64]time.Time{}}
+}
+
+// DueJobs returns the jobs which did not run within their interval, and marks them as run. jobs are
+// all enabled jobs, the scheduler forgets the others. schedule returns the id and interval of a job.
+func DueJobs[J any](s *Scheduler, now time.Time, jobs []J, schedule func(J) (int64, time.Duration)) []J {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := make([]J, 0)
+	current := make(map[int64]bool, len(jobs))
+	for _, job := range jobs {
+		id, interval := schedule(job)
+		current[id] = true
+		if now.Sub(s.lastRun[id]) < interval {
+			continue
+		}
+		s.lastRun[id] = now
+		due = append(due, job)
+	}
+	for id := range s.lastRun {
+		if !current[id] {
+			delete(s.lastRun, id)
+		}
+	}
+	return due
+}
+
+// ListEnabledJobs returns the enabled jobs of all organizations from the table of J.
+func ListEnabledJobs[J any](ctx context.Context, store db.DB) ([]*J, error) {
+	jobs := make([]*J, 0)
+	err := store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ?", store.GetDialect().BooleanStr(true)).Find(&jobs)
+	})
+	return jobs, err
+}
+
+// UpdateRun records the outcome of a run of a job. update holds the end of the period the job
+// processed, in untilColumn, and the error of the run.
+func UpdateRun(ctx context.Context, store db.DB, orgID, jobID int64, untilColumn string, update interface{}) error {
+	return store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ?", orgID).ID(jobID).Cols(untilColumn, "last_error").Update(update)
+		return err
+	})
+}
+
+// GetDashboardID returns the id of the dashboard annotations are added to.
+func GetDashboardID(ctx context.Context, store db.DB, orgID int64, uid string) (int64, error) {
+	var ids []int64
+	err := store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT id FROM dashboard WHERE org_id = ? AND uid = ? AND is_folder = ?",
+			orgID, uid, store.GetDialect().BooleanStr(false)).Find(&ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("dashboard %s not found", uid)
+	}
+	return ids[0], nil
+}
+
+// CheckDataSourceAccess returns a response if the user can't query the data source of a job, since
+// jobs query it on behalf of the organization.
+func CheckDataSourceAccess(c *models.ReqContext, accessControl ac.AccessControl, uid string) response.Response {
+	scope := datasources.ScopeProvider.GetResourceScopeUID(uid)
+	ok, err := accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(datasources.ActionQuery, scope))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check data source permissions", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "Access denied to the data source of the job", nil)
+	}
+	return nil
+}
+
+// GetJSON sends a GET request to the API of a data source and decodes the JSON response into v.
+func GetJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the data source responded with status %d: %s", resp.StatusCode, Truncate(strings.TrimSpace(string(body)), 100))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to read the response of the data source: %w", err)
+	}
+	return nil
+}
+
+// Truncate shortens a text to at most max characters.
+func Truncate(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	return string([]rune(text)[:max])
+}
@@ -0,0 +1,36 @@
+package annotationjobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testJob struct {
+	id       int64
+	interval time.Duration
+}
+
+func schedule(j testJob) (int64, time.Duration) {
+	return j.id, j.interval
+}
+
+func TestDueJobs(t *testing.T) {
+	s := NewScheduler()
+	now := time.Now()
+	minute, hour := testJob{id: 1, interval: time.Minute}, testJob{id: 2, interval: time.Hour}
+
+	assert.Equal(t, []testJob{minute, hour}, DueJobs(s, now, []testJob{minute, hour}, schedule))
+	assert.Empty(t, DueJobs(s, now.Add(30*time.Second), []testJob{minute, hour}, schedule))
+	assert.Equal(t, []testJob{minute}, DueJobs(s, now.Add(time.Minute), []testJob{minute, hour}, schedule))
+
+	// jobs which were disabled or deleted are forgotten, so they are due once enabled again
+	assert.Empty(t, DueJobs(s, now.Add(time.Minute), []testJob{minute}, schedule))
+	assert.Equal(t, []testJob{hour}, DueJobs(s, now.Add(time.Minute), []testJob{minute, hour}, schedule))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 10))
+	assert.Equal(t, "ünï", Truncate("ünïcode", 3))
+}
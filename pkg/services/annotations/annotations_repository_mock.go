@@ -51,6 +51,27 @@ func (_m *FakeAnnotationsRepo) Find(ctx context.Context, query *ItemQuery) ([]*I
 	return r0, r1
 }
 
+// FindTagKeys provides a mock function with given fields: ctx, query
+func (_m *FakeAnnotationsRepo) FindTagKeys(ctx context.Context, query *TagKeysQuery) (FindTagKeysResult, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 FindTagKeysResult
+	if rf, ok := ret.Get(0).(func(context.Context, *TagKeysQuery) FindTagKeysResult); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Get(0).(FindTagKeysResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *TagKeysQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindTags provides a mock function with given fields: ctx, query
 func (_m *FakeAnnotationsRepo) FindTags(ctx context.Context, query *TagsQuery) (FindTagsResult, error) {
 	ret := _m.Called(ctx, query)
@@ -51,3 +51,7 @@ func (r *RepositoryImpl) Delete(ctx context.Context, params *annotations.DeleteP
 func (r *RepositoryImpl) FindTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	return r.store.GetTags(ctx, query)
 }
+
+func (r *RepositoryImpl) FindTagKeys(ctx context.Context, query *annotations.TagKeysQuery) (annotations.FindTagKeysResult, error) {
+	return r.store.GetTagKeys(ctx, query)
+}
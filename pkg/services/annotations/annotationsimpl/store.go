@@ -14,6 +14,7 @@ type store interface {
 	Get(ctx context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error)
 	Delete(ctx context.Context, params *annotations.DeleteParams) error
 	GetTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error)
+	GetTagKeys(ctx context.Context, query *annotations.TagKeysQuery) (annotations.FindTagKeysResult, error)
 	CleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error)
 	CleanOrphanedAnnotationTags(ctx context.Context) (int64, error)
 }
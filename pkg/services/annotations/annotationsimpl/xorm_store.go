@@ -8,11 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/permissions"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
@@ -104,8 +106,8 @@ func (r *xormRepositoryImpl) AddMany(ctx context.Context, items []annotations.It
 			return err
 		}
 
-		for i, item := range hasTags {
-			if _, err := sess.Table("annotation").Insert(item); err != nil {
+		for i := range hasTags {
+			if _, err := sess.Table("annotation").Insert(&hasTags[i]); err != nil {
 				return err
 			}
 			if err := r.synchronizeTags(ctx, &hasTags[i]); err != nil {
@@ -210,10 +212,12 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 				annotation.updated,
 				usr.email,
 				usr.login,
-				alert.name as alert_name
+				alert.name as alert_name,
+				dashboard.uid as dashboard_uid
 			FROM annotation
 			LEFT OUTER JOIN ` + r.db.GetDialect().Quote("user") + ` as usr on usr.id = annotation.user_id
 			LEFT OUTER JOIN alert on alert.id = annotation.alert_id
+			LEFT OUTER JOIN dashboard on dashboard.id = annotation.dashboard_id AND annotation.dashboard_id <> 0
 			INNER JOIN (
 				SELECT a.id from annotation a
 			`)
@@ -259,33 +263,27 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 		}
 
 		if len(query.Tags) > 0 {
-			keyValueFilters := []string{}
-
-			tags := tag.ParseTagPairs(query.Tags)
-			for _, tag := range tags {
-				if tag.Value == "" {
-					keyValueFilters = append(keyValueFilters, "(tag."+r.db.GetDialect().Quote("key")+" = ?)")
-					params = append(params, tag.Key)
-				} else {
-					keyValueFilters = append(keyValueFilters, "(tag."+r.db.GetDialect().Quote("key")+" = ? AND tag."+r.db.GetDialect().Quote("value")+" = ?)")
-					params = append(params, tag.Key, tag.Value)
-				}
+			tagKey := "tag." + r.db.GetDialect().Quote("key")
+			tagValue := "tag." + r.db.GetDialect().Quote("value")
+			tagsSubQuery := `EXISTS (SELECT 1 FROM annotation_tag at INNER JOIN tag ON tag.id = at.tag_id WHERE at.annotation_id = a.id AND %s)`
+
+			// Every filter is checked on its own as a filter without value or with a prefix can
+			// match several tags of the same annotation.
+			filters := tag.ParseFilters(query.Tags)
+			conditions := make([]string, 0, len(filters))
+			for _, filter := range filters {
+				where, args := filter.Where(tagKey, tagValue, r.db.GetDialect().LikeStr())
+				conditions = append(conditions, where)
+				params = append(params, args...)
 			}
 
-			if len(tags) > 0 {
-				tagsSubQuery := fmt.Sprintf(`
-			SELECT SUM(1) FROM annotation_tag at
-			INNER JOIN tag on tag.id = at.tag_id
-			WHERE at.annotation_id = a.id
-				AND (
-				%s
-				)
-		`, strings.Join(keyValueFilters, " OR "))
-
+			if len(filters) > 0 {
 				if query.MatchAny {
-					sql.WriteString(fmt.Sprintf(" AND (%s) > 0 ", tagsSubQuery))
+					sql.WriteString(" AND " + fmt.Sprintf(tagsSubQuery, "("+strings.Join(conditions, " OR ")+")"))
 				} else {
-					sql.WriteString(fmt.Sprintf(" AND (%s) = %d ", tagsSubQuery, len(tags)))
+					for _, condition := range conditions {
+						sql.WriteString(" AND " + fmt.Sprintf(tagsSubQuery, condition))
+					}
 				}
 			}
 		}
@@ -297,14 +295,30 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 			}
 			sql.WriteString(fmt.Sprintf(" AND (%s)", acFilter))
 			params = append(params, acArgs...)
+		} else if legacyFilter, legacyArgs := r.getLegacyAccessFilter(query.SignedInUser); legacyFilter != "" {
+			sql.WriteString(fmt.Sprintf(" AND (%s)", legacyFilter))
+			params = append(params, legacyArgs...)
 		}
 
 		if query.Limit == 0 {
 			query.Limit = 100
 		}
 
-		// order of ORDER BY arguments match the order of a sql index for performance
-		sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+		if query.Sort.Field == "" {
+			// order of ORDER BY arguments match the order of a sql index for performance
+			sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+		} else {
+			if query.Sort.Field != "time" {
+				return pagination.ErrInvalidSort
+			}
+			if query.After != nil {
+				where, args := pagination.Where(query.Sort, query.After, "a.epoch", "a.id")
+				sql.WriteString(" AND " + where)
+				params = append(params, args...)
+			}
+			sql.WriteString(" ORDER BY " + pagination.OrderBy(query.Sort, "a.epoch", "a.id") + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+			sql.WriteString(" ORDER BY " + pagination.OrderBy(query.Sort, "annotation.epoch", "annotation.id"))
+		}
 		if err := sess.SQL(sql.String(), params...).Find(&items); err != nil {
 			items = nil
 			return err
@@ -338,6 +352,10 @@ func getAccessControlFilter(user *user.SignedInUser) (string, []interface{}, err
 		}
 		// annotation read permission with scope annotations:type:dashboard allows listing annotations from dashboards which the user can view
 		if t == annotations.Dashboard.String() {
+			if canReadAllDashboards(user) {
+				filters = append(filters, "a.dashboard_id <> 0")
+				continue
+			}
 			dashboardFilter, dashboardParams := permissions.NewAccessControlDashboardPermissionFilter(user, models.PERMISSION_VIEW, searchstore.TypeDashboard).Where()
 			filter := fmt.Sprintf("a.dashboard_id IN(SELECT id FROM dashboard WHERE %s)", dashboardFilter)
 			filters = append(filters, filter)
@@ -347,6 +365,41 @@ func getAccessControlFilter(user *user.SignedInUser) (string, []interface{}, err
 	return strings.Join(filters, " OR "), params, nil
 }
 
+// getLegacyAccessFilter limits the dashboard annotations to the dashboards the user can view according
+// to the dashboard ACLs when RBAC is disabled. It is empty for org admins and queries without a user.
+func (r *xormRepositoryImpl) getLegacyAccessFilter(user *user.SignedInUser) (string, []interface{}) {
+	if user == nil || canReadAllDashboards(user) {
+		return "", nil
+	}
+	dashboardFilter, params := permissions.DashboardPermissionFilter{
+		OrgRole:         user.OrgRole,
+		Dialect:         r.db.GetDialect(),
+		UserId:          user.UserID,
+		OrgId:           user.OrgID,
+		PermissionLevel: models.PERMISSION_VIEW,
+	}.Where()
+	if dashboardFilter == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("a.dashboard_id = 0 OR a.dashboard_id IN(SELECT id FROM dashboard WHERE %s)", dashboardFilter), params
+}
+
+// canReadAllDashboards returns true if the user can read the dashboards in every folder, e.g. the
+// users of public dashboards, so that the annotations do not need to be filtered by dashboard.
+func canReadAllDashboards(user *user.SignedInUser) bool {
+	if user.Permissions[user.OrgID] == nil {
+		return false
+	}
+	dashWildcards := ac.WildcardsFromPrefix(dashboards.ScopeDashboardsPrefix)
+	folderWildcards := ac.WildcardsFromPrefix(dashboards.ScopeFoldersPrefix)
+	for _, scope := range user.Permissions[user.OrgID][dashboards.ActionDashboardsRead] {
+		if dashWildcards.Contains(scope) || folderWildcards.Contains(scope) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *xormRepositoryImpl) Delete(ctx context.Context, params *annotations.DeleteParams) error {
 	return r.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var (
@@ -410,6 +463,11 @@ func (r *xormRepositoryImpl) GetTags(ctx context.Context, query *annotations.Tag
 		sql.WriteString(` AND (` + tagKey + ` ` + r.db.GetDialect().LikeStr() + ` ? OR ` + tagValue + ` ` + r.db.GetDialect().LikeStr() + ` ?)`)
 		params = append(params, `%`+query.Tag+`%`, `%`+query.Tag+`%`)
 
+		if query.Key != "" {
+			sql.WriteString(` AND ` + tagKey + ` = ?`)
+			params = append(params, query.Key)
+		}
+
 		sql.WriteString(` GROUP BY ` + tagKey + `,` + tagValue)
 		sql.WriteString(` ORDER BY ` + tagKey + `,` + tagValue)
 		sql.WriteString(` ` + r.db.GetDialect().Limit(query.Limit))
@@ -435,6 +493,27 @@ func (r *xormRepositoryImpl) GetTags(ctx context.Context, query *annotations.Tag
 	return annotations.FindTagsResult{Tags: tags}, nil
 }
 
+func (r *xormRepositoryImpl) GetTagKeys(ctx context.Context, query *annotations.TagKeysQuery) (annotations.FindTagKeysResult, error) {
+	result := annotations.FindTagKeysResult{Keys: []*annotations.TagKeyDTO{}}
+	err := r.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		if query.Limit == 0 {
+			query.Limit = 100
+		}
+
+		tagKey := `tag.` + r.db.GetDialect().Quote("key")
+		sql := `SELECT ` + tagKey + ` AS ` + r.db.GetDialect().Quote("key") + `, count(*) AS count
+		FROM tag
+		INNER JOIN annotation_tag ON tag.id = annotation_tag.tag_id
+		WHERE EXISTS(SELECT 1 FROM annotation WHERE annotation.id = annotation_tag.annotation_id AND annotation.org_id = ?)
+		AND ` + tagKey + ` ` + r.db.GetDialect().LikeStr() + ` ? AND tag.` + r.db.GetDialect().Quote("value") + ` <> ''
+		GROUP BY ` + tagKey + `
+		ORDER BY ` + tagKey + ` ` + r.db.GetDialect().Limit(query.Limit)
+
+		return dbSession.SQL(sql, query.OrgID, query.Prefix+`%`).Find(&result.Keys)
+	})
+	return result, err
+}
+
 func (r *xormRepositoryImpl) validateItem(item *annotations.Item) error {
 	if err := validateTimeRange(item); err != nil {
 		return err
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -20,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashboardstore "github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -186,6 +188,27 @@ func TestIntegrationAnnotations(t *testing.T) {
 			assert.Len(t, inserted, count)
 		})
 
+		t.Run("Can page through annotations with a cursor", func(t *testing.T) {
+			sort := pagination.Sort{Field: "time", Descending: true}
+			seen := map[int64]bool{}
+			var after *pagination.Cursor
+			for _, expected := range []int{4, 4, 2} {
+				items, err := repo.Get(context.Background(), &annotations.ItemQuery{OrgId: 100, Limit: 4, Sort: sort, After: after, SignedInUser: testUser})
+				require.NoError(t, err)
+				require.Len(t, items, expected)
+				for i, item := range items {
+					assert.False(t, seen[item.Id])
+					seen[item.Id] = true
+					if i > 0 {
+						assert.Less(t, item.Id, items[i-1].Id)
+					}
+				}
+				last := items[len(items)-1]
+				after = &pagination.Cursor{Value: last.Time, ID: last.Id}
+			}
+			assert.Len(t, seen, 10)
+		})
+
 		t.Run("Can batch-insert annotations with tags", func(t *testing.T) {
 			count := 10
 			items := make([]annotations.Item, count)
@@ -205,6 +228,10 @@ func TestIntegrationAnnotations(t *testing.T) {
 			inserted, err := repo.Get(context.Background(), query)
 			require.NoError(t, err)
 			assert.Len(t, inserted, count)
+
+			tagged, err := repo.Get(context.Background(), &annotations.ItemQuery{OrgId: 101, Tags: []string{"type:test"}, SignedInUser: testUser})
+			require.NoError(t, err)
+			assert.Len(t, tagged, 1)
 		})
 
 		t.Run("Can query for annotation by id", func(t *testing.T) {
@@ -295,6 +322,38 @@ func TestIntegrationAnnotations(t *testing.T) {
 			assert.Len(t, items, 1)
 		})
 
+		t.Run("Should find annotations by tag prefix", func(t *testing.T) {
+			items, err := repo.Get(context.Background(), &annotations.ItemQuery{
+				OrgId:        1,
+				From:         1,
+				To:           25,
+				Tags:         []string{"server:server-*", "type:*"},
+				SignedInUser: testUser,
+			})
+			require.NoError(t, err)
+			assert.Len(t, items, 2)
+
+			items, err = repo.Get(context.Background(), &annotations.ItemQuery{
+				OrgId:        1,
+				From:         1,
+				To:           25,
+				Tags:         []string{"serv*", "outage"},
+				SignedInUser: testUser,
+			})
+			require.NoError(t, err)
+			assert.Len(t, items, 2)
+
+			items, err = repo.Get(context.Background(), &annotations.ItemQuery{
+				OrgId:        1,
+				From:         1,
+				To:           25,
+				Tags:         []string{"server:web-*"},
+				SignedInUser: testUser,
+			})
+			require.NoError(t, err)
+			assert.Empty(t, items)
+		})
+
 		t.Run("Can update annotation and remove all tags", func(t *testing.T) {
 			query := &annotations.ItemQuery{
 				OrgId:        1,
@@ -428,6 +487,30 @@ func TestIntegrationAnnotations(t *testing.T) {
 			require.Equal(t, int64(1), result.Tags[1].Count)
 		})
 
+		t.Run("Should find tags with a key", func(t *testing.T) {
+			result, err := repo.GetTags(context.Background(), &annotations.TagsQuery{
+				OrgID: 1,
+				Key:   "type",
+			})
+			require.NoError(t, err)
+			require.Len(t, result.Tags, 1)
+			require.Equal(t, "type:outage", result.Tags[0].Tag)
+		})
+
+		t.Run("Should find tag keys", func(t *testing.T) {
+			result, err := repo.GetTagKeys(context.Background(), &annotations.TagKeysQuery{OrgID: 1})
+			require.NoError(t, err)
+			require.Len(t, result.Keys, 2)
+			require.Equal(t, "server", result.Keys[0].Key)
+			require.Equal(t, "type", result.Keys[1].Key)
+			require.Equal(t, int64(1), result.Keys[1].Count)
+
+			result, err = repo.GetTagKeys(context.Background(), &annotations.TagKeysQuery{OrgID: 1, Prefix: "ty"})
+			require.NoError(t, err)
+			require.Len(t, result.Keys, 1)
+			require.Equal(t, "type", result.Keys[0].Key)
+		})
+
 		t.Run("Should not find tags in other org", func(t *testing.T) {
 			result, err := repo.GetTags(context.Background(), &annotations.TagsQuery{
 				OrgID: 0,
@@ -590,6 +673,87 @@ func TestIntegrationAnnotationListingWithRBAC(t *testing.T) {
 	}
 }
 
+func TestIntegrationAnnotationListingWithLegacyPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sql := db.InitTestDB(t)
+
+	cfg := setting.NewCfg()
+	cfg.RBACEnabled = false
+	repo := xormRepositoryImpl{db: sql, cfg: cfg, log: log.New("annotation.test"), tagService: tagimpl.ProvideService(sql, sql.Cfg), maximumTagsLength: 60}
+	dashboardStore, err := dashboardstore.ProvideDashboardStore(sql, sql.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sql, sql.Cfg), quotatest.New(false, nil))
+	require.NoError(t, err)
+
+	var dashboardUIDs []string
+	for _, title := range []string{"Dashboard 1", "Restricted dashboard"} {
+		dashboard, err := dashboardStore.SaveDashboard(context.Background(), models.SaveDashboardCommand{
+			UserId:    1,
+			OrgId:     1,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": title}),
+		})
+		require.NoError(t, err)
+		dashboardUIDs = append(dashboardUIDs, dashboard.Uid)
+	}
+	// the second dashboard has an ACL without any permission, which only admins can view
+	err = sql.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE dashboard SET has_acl = ? WHERE id = 2", true)
+		return err
+	})
+	require.NoError(t, err)
+
+	dash1Annotation := &annotations.Item{OrgId: 1, DashboardId: 1, Epoch: 10}
+	dash2Annotation := &annotations.Item{OrgId: 1, DashboardId: 2, Epoch: 10}
+	organizationAnnotation := &annotations.Item{OrgId: 1, Epoch: 10}
+	for _, item := range []*annotations.Item{dash1Annotation, dash2Annotation, organizationAnnotation} {
+		require.NoError(t, repo.Add(context.Background(), item))
+	}
+
+	testCases := []struct {
+		description           string
+		user                  *user.SignedInUser
+		expectedAnnotationIds []int64
+	}{
+		{
+			description:           "Should find the annotations of the dashboards a viewer can view and the organization annotations",
+			user:                  &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer},
+			expectedAnnotationIds: []int64{dash1Annotation.Id, organizationAnnotation.Id},
+		},
+		{
+			description:           "Should find all annotations for admins",
+			user:                  &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin},
+			expectedAnnotationIds: []int64{dash1Annotation.Id, dash2Annotation.Id, organizationAnnotation.Id},
+		},
+		{
+			description: "Should find all annotations for users who can read all dashboards",
+			user: &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+				1: {dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsAll}},
+			}},
+			expectedAnnotationIds: []int64{dash1Annotation.Id, dash2Annotation.Id, organizationAnnotation.Id},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			results, err := repo.Get(context.Background(), &annotations.ItemQuery{
+				OrgId:        1,
+				SignedInUser: tc.user,
+			})
+			require.NoError(t, err)
+			require.Len(t, results, len(tc.expectedAnnotationIds))
+			for _, r := range results {
+				assert.Contains(t, tc.expectedAnnotationIds, r.Id)
+				if r.DashboardId == 0 {
+					assert.Nil(t, r.DashboardUID)
+				} else {
+					require.NotNil(t, r.DashboardUID, "the dashboard UID should be selected with the annotation")
+					assert.Equal(t, dashboardUIDs[r.DashboardId-1], *r.DashboardUID)
+				}
+			}
+		})
+	}
+}
+
 func setupRBACRole(t *testing.T, repo xormRepositoryImpl, user *user.SignedInUser) *accesscontrol.Role {
 	t.Helper()
 	var role *accesscontrol.Role
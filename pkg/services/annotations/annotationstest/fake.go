@@ -83,6 +83,13 @@ func (repo *fakeAnnotationsRepo) FindTags(_ context.Context, query *annotations.
 	return result, nil
 }
 
+func (repo *fakeAnnotationsRepo) FindTagKeys(_ context.Context, query *annotations.TagKeysQuery) (annotations.FindTagKeysResult, error) {
+	result := annotations.FindTagKeysResult{
+		Keys: []*annotations.TagKeyDTO{},
+	}
+	return result, nil
+}
+
 func (repo *fakeAnnotationsRepo) Len() int {
 	repo.mtx.Lock()
 	defer repo.mtx.Unlock()
@@ -1,6 +1,7 @@
 package annotations
 
 import (
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -21,12 +22,18 @@ type ItemQuery struct {
 	SignedInUser *user.SignedInUser
 
 	Limit int64 `json:"limit"`
+	// Sort is the order of the annotations by time, by end time and time descending if not set.
+	Sort pagination.Sort `json:"-"`
+	// After returns the annotations after the cursor.
+	After *pagination.Cursor `json:"-"`
 }
 
 // TagsQuery is the query for a tags search.
 type TagsQuery struct {
 	OrgID int64  `json:"orgId"`
 	Tag   string `json:"tag"`
+	// Key limits the search to the tags with this key.
+	Key string `json:"key"`
 
 	Limit int64 `json:"limit"`
 }
@@ -49,6 +56,30 @@ type FindTagsResult struct {
 	Tags []*TagsDTO `json:"tags"`
 }
 
+// TagKeysQuery is the query for the keys of the tags of annotations.
+type TagKeysQuery struct {
+	OrgID  int64  `json:"orgId"`
+	Prefix string `json:"prefix"`
+
+	Limit int64 `json:"limit"`
+}
+
+// TagKeyDTO is a tag key together with the number of tagged annotations.
+type TagKeyDTO struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// FindTagKeysResult is the result of a tag keys search.
+type FindTagKeysResult struct {
+	Keys []*TagKeyDTO `json:"keys"`
+}
+
+// GetAnnotationTagKeysResponse is a response struct for FindTagKeysResult.
+type GetAnnotationTagKeysResponse struct {
+	Result FindTagKeysResult `json:"result"`
+}
+
 // GetAnnotationTagsResponse is a response struct for FindTagsResult.
 type GetAnnotationTagsResponse struct {
 	Result FindTagsResult `json:"result"`
@@ -92,7 +123,7 @@ type ItemDTO struct {
 	AlertId      int64            `json:"alertId"`
 	AlertName    string           `json:"alertName"`
 	DashboardId  int64            `json:"dashboardId"`
-	DashboardUID *string          `json:"dashboardUID"`
+	DashboardUID *string          `json:"dashboardUID" xorm:"dashboard_uid"`
 	PanelId      int64            `json:"panelId"`
 	UserId       int64            `json:"userId"`
 	NewState     string           `json:"newState"`
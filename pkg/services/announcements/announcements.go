@@ -0,0 +1,317 @@
+package announcements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	cleanupInterval = time.Hour
+	// expiredRetention is how long announcements are kept after they ended, so that admins can
+	// still look them up.
+	expiredRetention = 30 * 24 * time.Hour
+
+	maxTitleLength = 255
+	maxURLLength   = 1024
+)
+
+var (
+	ErrAnnouncementNotFound = errors.New("announcement not found")
+	ErrInvalidAnnouncement  = errors.New("invalid announcement")
+)
+
+// Severity decides how a banner is displayed.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+func (s Severity) IsValid() bool {
+	switch s {
+	case SeverityInfo, SeverityWarning, SeverityError:
+		return true
+	}
+	return false
+}
+
+// Announcement is a banner shown to the users of the organizations it targets while it is active.
+// swagger:model
+type Announcement struct {
+	ID       int64    `json:"id" xorm:"pk autoincr 'id'"`
+	Title    string   `json:"title" xorm:"title"`
+	Message  string   `json:"message,omitempty" xorm:"message"`
+	Severity Severity `json:"severity" xorm:"severity"`
+	URL      string   `json:"url,omitempty" xorm:"url"`
+	// Dismissible banners can be hidden by every user on their own.
+	Dismissible bool `json:"dismissible" xorm:"dismissible"`
+	// OrgIDs limits the announcement to the members of these organizations. It is shown in all
+	// organizations if it is empty.
+	OrgIDs []int64 `json:"orgIds" xorm:"jsonb org_ids"`
+	// Roles limits the announcement to users with one of these roles in the current organization.
+	// It is shown to all users if it is empty.
+	Roles []org.RoleType `json:"roles" xorm:"jsonb roles"`
+	// StartsAt and EndsAt limit the time the announcement is shown. It starts immediately and is
+	// shown until it is deleted if they are not set.
+	StartsAt *time.Time `json:"startsAt,omitempty" xorm:"starts_at"`
+	EndsAt   *time.Time `json:"endsAt,omitempty" xorm:"ends_at"`
+	UserID   int64      `json:"userId" xorm:"user_id"`
+	Created  time.Time  `json:"created" xorm:"created"`
+	Updated  time.Time  `json:"updated" xorm:"updated"`
+}
+
+func (a Announcement) TableName() string {
+	return "announcement"
+}
+
+// IsActive returns whether the announcement is shown at the given time.
+func (a *Announcement) IsActive(now time.Time) bool {
+	if a.StartsAt != nil && now.Before(*a.StartsAt) {
+		return false
+	}
+	if a.EndsAt != nil && !now.Before(*a.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Targets returns whether the announcement is shown to a user with the given role in the organization.
+func (a *Announcement) Targets(orgID int64, role org.RoleType) bool {
+	if len(a.OrgIDs) > 0 && !containsOrg(a.OrgIDs, orgID) {
+		return false
+	}
+	if len(a.Roles) == 0 {
+		return true
+	}
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Dismissal records that a user hid a dismissible announcement.
+type Dismissal struct {
+	ID             int64     `xorm:"pk autoincr 'id'"`
+	AnnouncementID int64     `xorm:"announcement_id"`
+	UserID         int64     `xorm:"user_id"`
+	Created        time.Time `xorm:"created"`
+}
+
+func (d Dismissal) TableName() string {
+	return "announcement_dismissal"
+}
+
+// CreateOrUpdateCommand is the body of the requests creating and updating announcements.
+// swagger:model CreateOrUpdateAnnouncementCommand
+type CreateOrUpdateCommand struct {
+	// required:true
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	// info, warning or error, defaults to info
+	Severity    Severity       `json:"severity"`
+	URL         string         `json:"url"`
+	Dismissible bool           `json:"dismissible"`
+	OrgIDs      []int64        `json:"orgIds"`
+	Roles       []org.RoleType `json:"roles"`
+	StartsAt    *time.Time     `json:"startsAt"`
+	EndsAt      *time.Time     `json:"endsAt"`
+}
+
+// validate checks the command and applies its defaults.
+func (cmd *CreateOrUpdateCommand) validate() error {
+	if cmd.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidAnnouncement)
+	}
+	if len(cmd.Title) > maxTitleLength {
+		return fmt.Errorf("%w: title is longer than %d characters", ErrInvalidAnnouncement, maxTitleLength)
+	}
+	if len(cmd.URL) > maxURLLength {
+		return fmt.Errorf("%w: url is longer than %d characters", ErrInvalidAnnouncement, maxURLLength)
+	}
+	if cmd.Severity == "" {
+		cmd.Severity = SeverityInfo
+	}
+	if !cmd.Severity.IsValid() {
+		return fmt.Errorf("%w: unknown severity %q", ErrInvalidAnnouncement, cmd.Severity)
+	}
+	for _, role := range cmd.Roles {
+		if !role.IsValid() {
+			return fmt.Errorf("%w: unknown role %q", ErrInvalidAnnouncement, role)
+		}
+	}
+	for _, orgID := range cmd.OrgIDs {
+		if orgID <= 0 {
+			return fmt.Errorf("%w: invalid organization id %d", ErrInvalidAnnouncement, orgID)
+		}
+	}
+	if cmd.StartsAt != nil && cmd.EndsAt != nil && !cmd.EndsAt.After(*cmd.StartsAt) {
+		return fmt.Errorf("%w: endsAt must be after startsAt", ErrInvalidAnnouncement)
+	}
+	return nil
+}
+
+func (cmd *CreateOrUpdateCommand) apply(a *Announcement) {
+	a.Title = cmd.Title
+	a.Message = cmd.Message
+	a.Severity = cmd.Severity
+	a.URL = cmd.URL
+	a.Dismissible = cmd.Dismissible
+	a.OrgIDs = cmd.OrgIDs
+	a.Roles = cmd.Roles
+	a.StartsAt = cmd.StartsAt
+	a.EndsAt = cmd.EndsAt
+	if a.OrgIDs == nil {
+		a.OrgIDs = []int64{}
+	}
+	if a.Roles == nil {
+		a.Roles = []org.RoleType{}
+	}
+}
+
+// UserQuery looks up the announcements shown to a user.
+type UserQuery struct {
+	OrgID  int64
+	UserID int64
+	Role   org.RoleType
+}
+
+type Service interface {
+	// GetActive returns the active announcements the user has not dismissed, newest first.
+	GetActive(ctx context.Context, query UserQuery) ([]*Announcement, error)
+}
+
+type AnnouncementService struct {
+	store         db.DB
+	log           log.Logger
+	routeRegister routing.RouteRegister
+}
+
+func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister) *AnnouncementService {
+	s := &AnnouncementService{
+		store:         sqlStore,
+		log:           log.New("announcements"),
+		routeRegister: routeRegister,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *AnnouncementService) Create(ctx context.Context, userID int64, cmd CreateOrUpdateCommand) (*Announcement, error) {
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	a := &Announcement{UserID: userID, Created: now, Updated: now}
+	cmd.apply(a)
+	if err := s.insert(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *AnnouncementService) Update(ctx context.Context, id int64, cmd CreateOrUpdateCommand) (*Announcement, error) {
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+
+	a, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cmd.apply(a)
+	a.Updated = time.Now()
+	if err := s.update(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *AnnouncementService) Get(ctx context.Context, id int64) (*Announcement, error) {
+	return s.get(ctx, id)
+}
+
+// GetAll returns all announcements including the scheduled and the ended ones, newest first.
+func (s *AnnouncementService) GetAll(ctx context.Context) ([]*Announcement, error) {
+	return s.list(ctx)
+}
+
+func (s *AnnouncementService) Delete(ctx context.Context, id int64) error {
+	return s.delete(ctx, id)
+}
+
+func (s *AnnouncementService) GetActive(ctx context.Context, query UserQuery) ([]*Announcement, error) {
+	now := time.Now()
+	candidates, err := s.active(ctx, query.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Announcement, 0, len(candidates))
+	for _, a := range candidates {
+		if a.Targets(query.OrgID, query.Role) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// Dismiss hides an announcement for the user. Only dismissible announcements shown to the user
+// can be dismissed.
+func (s *AnnouncementService) Dismiss(ctx context.Context, query UserQuery, id int64) error {
+	a, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !a.Targets(query.OrgID, query.Role) {
+		return ErrAnnouncementNotFound
+	}
+	if !a.Dismissible {
+		return fmt.Errorf("%w: announcement cannot be dismissed", ErrInvalidAnnouncement)
+	}
+	return s.dismiss(ctx, id, query.UserID, time.Now())
+}
+
+// Run removes announcements which ended a while ago.
+func (s *AnnouncementService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			removed, err := s.deleteEndedBefore(ctx, time.Now().Add(-expiredRetention))
+			if err != nil {
+				s.log.Error("Failed to clean up announcements", "error", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Debug("Cleaned up announcements", "removed", removed)
+			}
+		}
+	}
+}
+
+func containsOrg(orgIDs []int64, orgID int64) bool {
+	for _, id := range orgIDs {
+		if id == orgID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package announcements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestIntegrationAnnouncements(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	s := ProvideService(sqlStore, routing.NewRouteRegister())
+	ctx := context.Background()
+
+	const adminID = 1
+	viewer := UserQuery{OrgID: 1, UserID: 2, Role: org.RoleViewer}
+	otherOrgAdmin := UserQuery{OrgID: 2, UserID: 3, Role: org.RoleAdmin}
+
+	titles := func(query UserQuery) []string {
+		t.Helper()
+		result, err := s.GetActive(ctx, query)
+		require.NoError(t, err)
+		titles := make([]string, 0, len(result))
+		for _, a := range result {
+			titles = append(titles, a.Title)
+		}
+		return titles
+	}
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	maintenance, err := s.Create(ctx, adminID, CreateOrUpdateCommand{Title: "maintenance", Dismissible: true})
+	require.NoError(t, err)
+	assert.Equal(t, SeverityInfo, maintenance.Severity)
+	_, err = s.Create(ctx, adminID, CreateOrUpdateCommand{Title: "org 2 admins", Severity: SeverityWarning, OrgIDs: []int64{2}, Roles: []org.RoleType{org.RoleAdmin}})
+	require.NoError(t, err)
+	_, err = s.Create(ctx, adminID, CreateOrUpdateCommand{Title: "scheduled", StartsAt: &future})
+	require.NoError(t, err)
+	_, err = s.Create(ctx, adminID, CreateOrUpdateCommand{Title: "ended", EndsAt: &past})
+	require.NoError(t, err)
+
+	t.Run("returns the active announcements targeting the user", func(t *testing.T) {
+		assert.Equal(t, []string{"maintenance"}, titles(viewer))
+		assert.Equal(t, []string{"org 2 admins", "maintenance"}, titles(otherOrgAdmin))
+	})
+
+	t.Run("lists all announcements for admins", func(t *testing.T) {
+		all, err := s.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, 4)
+	})
+
+	t.Run("rejects invalid announcements", func(t *testing.T) {
+		_, err := s.Create(ctx, adminID, CreateOrUpdateCommand{})
+		require.ErrorIs(t, err, ErrInvalidAnnouncement)
+		_, err = s.Create(ctx, adminID, CreateOrUpdateCommand{Title: "bad role", Roles: []org.RoleType{"Owner"}})
+		require.ErrorIs(t, err, ErrInvalidAnnouncement)
+		_, err = s.Create(ctx, adminID, CreateOrUpdateCommand{Title: "bad schedule", StartsAt: &future, EndsAt: &past})
+		require.ErrorIs(t, err, ErrInvalidAnnouncement)
+	})
+
+	t.Run("dismisses an announcement for one user", func(t *testing.T) {
+		require.NoError(t, s.Dismiss(ctx, viewer, maintenance.ID))
+		require.NoError(t, s.Dismiss(ctx, viewer, maintenance.ID))
+		assert.Empty(t, titles(viewer))
+		assert.Contains(t, titles(otherOrgAdmin), "maintenance")
+	})
+
+	t.Run("only dismisses announcements shown to the user which are dismissible", func(t *testing.T) {
+		all, err := s.GetAll(ctx)
+		require.NoError(t, err)
+		for _, a := range all {
+			if a.Title == "org 2 admins" {
+				require.ErrorIs(t, s.Dismiss(ctx, viewer, a.ID), ErrAnnouncementNotFound)
+				require.ErrorIs(t, s.Dismiss(ctx, otherOrgAdmin, a.ID), ErrInvalidAnnouncement)
+			}
+		}
+	})
+
+	t.Run("updates an announcement", func(t *testing.T) {
+		updated, err := s.Update(ctx, maintenance.ID, CreateOrUpdateCommand{Title: "maintenance tonight", OrgIDs: []int64{2}})
+		require.NoError(t, err)
+		assert.Equal(t, maintenance.Created.Unix(), updated.Created.Unix())
+		assert.NotContains(t, titles(otherOrgAdmin), "maintenance")
+		assert.Contains(t, titles(otherOrgAdmin), "maintenance tonight")
+
+		_, err = s.Update(ctx, 1000, CreateOrUpdateCommand{Title: "missing"})
+		require.ErrorIs(t, err, ErrAnnouncementNotFound)
+	})
+
+	t.Run("deletes an announcement", func(t *testing.T) {
+		require.NoError(t, s.Delete(ctx, maintenance.ID))
+		require.ErrorIs(t, s.Delete(ctx, maintenance.ID), ErrAnnouncementNotFound)
+		assert.Equal(t, []string{"org 2 admins"}, titles(otherOrgAdmin))
+	})
+
+	t.Run("removes announcements which ended a while ago", func(t *testing.T) {
+		removed, err := s.deleteEndedBefore(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+	})
+}
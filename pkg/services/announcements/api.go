@@ -0,0 +1,238 @@
+package announcements
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *AnnouncementService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/admin/announcements", func(entities routing.RouteRegister) {
+		entities.Get("/", routing.Wrap(s.getAnnouncementsHandler))
+		entities.Post("/", routing.Wrap(s.createAnnouncementHandler))
+		entities.Get("/:id", routing.Wrap(s.getAnnouncementHandler))
+		entities.Put("/:id", routing.Wrap(s.updateAnnouncementHandler))
+		entities.Delete("/:id", routing.Wrap(s.deleteAnnouncementHandler))
+	}, middleware.ReqGrafanaAdmin)
+	s.routeRegister.Group("/api/user/announcements", func(entities routing.RouteRegister) {
+		entities.Get("/", routing.Wrap(s.getSignedInUserAnnouncementsHandler))
+		entities.Post("/:id/dismiss", routing.Wrap(s.dismissAnnouncementHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route GET /admin/announcements admin adminGetAnnouncements
+//
+// Get all announcements.
+//
+// Returns all announcements newest first, including the ones which are scheduled or have ended.
+//
+// Responses:
+// 200: announcementsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *AnnouncementService) getAnnouncementsHandler(c *models.ReqContext) response.Response {
+	result, err := s.GetAll(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get announcements", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /admin/announcements admin adminCreateAnnouncement
+//
+// Create an announcement.
+//
+// Publishes a banner to the users of all organizations, or of the organizations given by `orgIds`. Use `roles` to show it to users with these organization roles only, and `startsAt` and `endsAt` to schedule it.
+//
+// Responses:
+// 200: announcementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *AnnouncementService) createAnnouncementHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	a, err := s.Create(c.Req.Context(), c.UserID, cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to create announcement")
+	}
+	return response.JSON(http.StatusOK, a)
+}
+
+// swagger:route GET /admin/announcements/{announcement_id} admin adminGetAnnouncement
+//
+// Get an announcement.
+//
+// Responses:
+// 200: announcementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnouncementService) getAnnouncementHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	a, err := s.Get(c.Req.Context(), id)
+	if err != nil {
+		return errorResponse(err, "Failed to get announcement")
+	}
+	return response.JSON(http.StatusOK, a)
+}
+
+// swagger:route PUT /admin/announcements/{announcement_id} admin adminUpdateAnnouncement
+//
+// Update an announcement.
+//
+// Users who dismissed the announcement do not see it again.
+//
+// Responses:
+// 200: announcementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnouncementService) updateAnnouncementHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	cmd := CreateOrUpdateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	a, err := s.Update(c.Req.Context(), id, cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to update announcement")
+	}
+	return response.JSON(http.StatusOK, a)
+}
+
+// swagger:route DELETE /admin/announcements/{announcement_id} admin adminDeleteAnnouncement
+//
+// Delete an announcement.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnouncementService) deleteAnnouncementHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := s.Delete(c.Req.Context(), id); err != nil {
+		return errorResponse(err, "Failed to delete announcement")
+	}
+	return response.Success("Announcement deleted")
+}
+
+// swagger:route GET /user/announcements signed_in_user getSignedInUserAnnouncements
+//
+// Get the announcements for the signed in user.
+//
+// Returns the active announcements for the current organization and role of the signed in user which they have not dismissed, newest first.
+//
+// Responses:
+// 200: announcementsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *AnnouncementService) getSignedInUserAnnouncementsHandler(c *models.ReqContext) response.Response {
+	result, err := s.GetActive(c.Req.Context(), userQuery(c))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get announcements", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /user/announcements/{announcement_id}/dismiss signed_in_user dismissSignedInUserAnnouncement
+//
+// Dismiss an announcement for the signed in user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnouncementService) dismissAnnouncementHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := s.Dismiss(c.Req.Context(), userQuery(c), id); err != nil {
+		return errorResponse(err, "Failed to dismiss announcement")
+	}
+	return response.Success("Announcement dismissed")
+}
+
+func userQuery(c *models.ReqContext) UserQuery {
+	return UserQuery{OrgID: c.OrgID, UserID: c.UserID, Role: c.OrgRole}
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrAnnouncementNotFound):
+		return response.Error(http.StatusNotFound, "Announcement not found", err)
+	case errors.Is(err, ErrInvalidAnnouncement):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters adminCreateAnnouncement
+type AdminCreateAnnouncementParams struct {
+	// in:body
+	// required:true
+	Body CreateOrUpdateCommand `json:"body"`
+}
+
+// swagger:parameters adminGetAnnouncement adminDeleteAnnouncement dismissSignedInUserAnnouncement
+type AnnouncementIDParams struct {
+	// in:path
+	// required:true
+	AnnouncementID int64 `json:"announcement_id"`
+}
+
+// swagger:parameters adminUpdateAnnouncement
+type AdminUpdateAnnouncementParams struct {
+	// in:path
+	// required:true
+	AnnouncementID int64 `json:"announcement_id"`
+	// in:body
+	// required:true
+	Body CreateOrUpdateCommand `json:"body"`
+}
+
+// swagger:response announcementsResponse
+type AnnouncementsResponse struct {
+	// in:body
+	Body []*Announcement `json:"body"`
+}
+
+// swagger:response announcementResponse
+type AnnouncementResponse struct {
+	// in:body
+	Body *Announcement `json:"body"`
+}
@@ -0,0 +1,104 @@
+package announcements
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *AnnouncementService) insert(ctx context.Context, a *Announcement) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(a)
+		return err
+	})
+}
+
+func (s *AnnouncementService) update(ctx context.Context, a *Announcement) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(a.ID).AllCols().Omit("created", "user_id").Update(a)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrAnnouncementNotFound
+		}
+		return nil
+	})
+}
+
+func (s *AnnouncementService) get(ctx context.Context, id int64) (*Announcement, error) {
+	a := &Announcement{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.ID(id).Get(a)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrAnnouncementNotFound
+		}
+		return nil
+	})
+	return a, err
+}
+
+func (s *AnnouncementService) list(ctx context.Context) ([]*Announcement, error) {
+	result := make([]*Announcement, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Desc("created", "id").Find(&result)
+	})
+	return result, err
+}
+
+// active returns the announcements shown at the given time which the user has not dismissed.
+func (s *AnnouncementService) active(ctx context.Context, userID int64, now time.Time) ([]*Announcement, error) {
+	result := make([]*Announcement, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("(starts_at IS NULL OR starts_at <= ?)", now).
+			And("(ends_at IS NULL OR ends_at > ?)", now).
+			And("id NOT IN (SELECT announcement_id FROM announcement_dismissal WHERE user_id = ?)", userID).
+			Desc("created", "id").Find(&result)
+	})
+	return result, err
+}
+
+func (s *AnnouncementService) delete(ctx context.Context, id int64) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(id).Delete(&Announcement{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrAnnouncementNotFound
+		}
+		_, err = sess.Exec("DELETE FROM announcement_dismissal WHERE announcement_id = ?", id)
+		return err
+	})
+}
+
+func (s *AnnouncementService) dismiss(ctx context.Context, id, userID int64, now time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("announcement_id = ? AND user_id = ?", id, userID).Exist(&Dismissal{})
+		if err != nil || exists {
+			return err
+		}
+		_, err = sess.Insert(&Dismissal{AnnouncementID: id, UserID: userID, Created: now})
+		return err
+	})
+}
+
+func (s *AnnouncementService) deleteEndedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM announcement_dismissal WHERE announcement_id IN (SELECT id FROM announcement WHERE ends_at < ?)", before); err != nil {
+			return err
+		}
+		res, err := sess.Exec("DELETE FROM announcement WHERE ends_at < ?", before)
+		if err != nil {
+			return err
+		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return removed, err
+}
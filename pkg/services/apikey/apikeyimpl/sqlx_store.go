@@ -90,6 +90,15 @@ func (ss *sqlxStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) erro
 	if !errors.Is(err, apikey.ErrInvalid) {
 		return apikey.ErrDuplicate
 	}
+	allowedCIDRs, err := apikey.FormatCIDRs(cmd.AllowedCIDRs)
+	if err != nil {
+		return err
+	}
+	deniedCIDRs, err := apikey.FormatCIDRs(cmd.DeniedCIDRs)
+	if err != nil {
+		return err
+	}
+
 	isRevoked := false
 	t := apikey.APIKey{
 		OrgId:            cmd.OrgId,
@@ -101,10 +110,12 @@ func (ss *sqlxStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) erro
 		Expires:          expires,
 		ServiceAccountId: nil,
 		IsRevoked:        &isRevoked,
+		AllowedCIDRs:     allowedCIDRs,
+		DeniedCIDRs:      deniedCIDRs,
 	}
 
 	t.Id, err = ss.sess.ExecWithReturningId(ctx,
-		`INSERT INTO api_key (org_id, name, role, "key", created, updated, expires, service_account_id, is_revoked) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, t.OrgId, t.Name, t.Role, t.Key, t.Created, t.Updated, t.Expires, t.ServiceAccountId, t.IsRevoked)
+		`INSERT INTO api_key (org_id, name, role, "key", created, updated, expires, service_account_id, is_revoked, allowed_cidrs, denied_cidrs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, t.OrgId, t.Name, t.Role, t.Key, t.Created, t.Updated, t.Expires, t.ServiceAccountId, t.IsRevoked, t.AllowedCIDRs, t.DeniedCIDRs)
 	cmd.Result = &t
 	return err
 }
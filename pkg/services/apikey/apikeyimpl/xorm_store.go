@@ -99,6 +99,15 @@ func (ss *sqlStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) error
 			return apikey.ErrInvalidExpiration
 		}
 
+		allowedCIDRs, err := apikey.FormatCIDRs(cmd.AllowedCIDRs)
+		if err != nil {
+			return err
+		}
+		deniedCIDRs, err := apikey.FormatCIDRs(cmd.DeniedCIDRs)
+		if err != nil {
+			return err
+		}
+
 		isRevoked := false
 		t := apikey.APIKey{
 			OrgId:            cmd.OrgId,
@@ -110,6 +119,8 @@ func (ss *sqlStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) error
 			Expires:          expires,
 			ServiceAccountId: cmd.ServiceAccountID,
 			IsRevoked:        &isRevoked,
+			AllowedCIDRs:     allowedCIDRs,
+			DeniedCIDRs:      deniedCIDRs,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
@@ -2,8 +2,10 @@ package apikey
 
 import (
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -14,6 +16,7 @@ var (
 	ErrInvalid           = errors.New("invalid API key")
 	ErrInvalidExpiration = errors.New("negative value for SecondsToLive")
 	ErrDuplicate         = errors.New("API key, organization ID and name must be unique")
+	ErrInvalidCIDR       = errors.New("invalid CIDR in allow or deny list")
 )
 
 type APIKey struct {
@@ -28,10 +31,54 @@ type APIKey struct {
 	Expires          *int64       `db:"expires"`
 	ServiceAccountId *int64       `db:"service_account_id"`
 	IsRevoked        *bool        `xorm:"is_revoked" db:"is_revoked"`
+	// AllowedCIDRs is a comma separated list of networks the key can be used from.
+	// A nil or empty value allows the key to be used from any address.
+	AllowedCIDRs *string `xorm:"allowed_cidrs" db:"allowed_cidrs"`
+	// DeniedCIDRs is a comma separated list of networks the key cannot be used from,
+	// it takes precedence over AllowedCIDRs.
+	DeniedCIDRs *string `xorm:"denied_cidrs" db:"denied_cidrs"`
 }
 
 func (k APIKey) TableName() string { return "api_key" }
 
+// AllowedCIDRList returns the networks the key is restricted to.
+func (k *APIKey) AllowedCIDRList() []string {
+	return splitCIDRs(k.AllowedCIDRs)
+}
+
+// DeniedCIDRList returns the networks the key cannot be used from.
+func (k *APIKey) DeniedCIDRList() []string {
+	return splitCIDRs(k.DeniedCIDRs)
+}
+
+// IsAllowedFrom reports whether the key can be used from the given IP address.
+func (k *APIKey) IsAllowedFrom(addr string) bool {
+	return network.IsAllowed(k.AllowedCIDRList(), k.DeniedCIDRList(), addr)
+}
+
+func splitCIDRs(cidrs *string) []string {
+	if cidrs == nil || *cidrs == "" {
+		return nil
+	}
+	return strings.Split(*cidrs, ",")
+}
+
+// FormatCIDRs validates an allow or deny list and returns it in the format stored with the key.
+// Single IP addresses are converted to networks containing only that address.
+func FormatCIDRs(cidrs []string) (*string, error) {
+	normalized, err := network.NormalizeCIDRs(cidrs)
+	if err != nil {
+		return nil, ErrInvalidCIDR
+	}
+
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	result := strings.Join(normalized, ",")
+	return &result, nil
+}
+
 // swagger:model
 type AddCommand struct {
 	Name             string       `json:"name" binding:"Required"`
@@ -40,6 +87,8 @@ type AddCommand struct {
 	Key              string       `json:"-"`
 	SecondsToLive    int64        `json:"secondsToLive"`
 	ServiceAccountID *int64       `json:"-"`
+	AllowedCIDRs     []string     `json:"-"`
+	DeniedCIDRs      []string     `json:"-"`
 
 	Result *APIKey `json:"-"`
 }
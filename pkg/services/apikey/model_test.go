@@ -0,0 +1,49 @@
+package apikey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCIDRs(t *testing.T) {
+	t.Run("should normalize addresses and networks", func(t *testing.T) {
+		formatted, err := FormatCIDRs([]string{"10.1.2.3/8", " 192.168.1.10 ", "", "2001:db8::1"})
+		require.NoError(t, err)
+		require.NotNil(t, formatted)
+		assert.Equal(t, "10.0.0.0/8,192.168.1.10/32,2001:db8::1/128", *formatted)
+	})
+
+	t.Run("should return nil for an empty list", func(t *testing.T) {
+		formatted, err := FormatCIDRs(nil)
+		require.NoError(t, err)
+		assert.Nil(t, formatted)
+	})
+
+	t.Run("should reject invalid values", func(t *testing.T) {
+		_, err := FormatCIDRs([]string{"10.0.0.0/33"})
+		require.ErrorIs(t, err, ErrInvalidCIDR)
+
+		_, err = FormatCIDRs([]string{"build-network"})
+		require.ErrorIs(t, err, ErrInvalidCIDR)
+	})
+}
+
+func TestAPIKey_IsAllowedFrom(t *testing.T) {
+	allowed := "10.0.0.0/8,192.168.1.10/32"
+	key := &APIKey{AllowedCIDRs: &allowed}
+
+	assert.True(t, key.IsAllowedFrom("10.20.30.40"))
+	assert.True(t, key.IsAllowedFrom("192.168.1.10"))
+	assert.False(t, key.IsAllowedFrom("192.168.1.11"))
+	assert.False(t, key.IsAllowedFrom(""))
+
+	denied := "10.0.1.0/24"
+	key.DeniedCIDRs = &denied
+	assert.False(t, key.IsAllowedFrom("10.0.1.5"))
+	assert.True(t, key.IsAllowedFrom("10.0.2.5"))
+
+	unrestricted := &APIKey{}
+	assert.True(t, unrestricted.IsAllowedFrom("203.0.113.1"))
+}
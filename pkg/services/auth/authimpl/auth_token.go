@@ -4,10 +4,15 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"net"
+	"net/http"
 	"strings"
 	"time"
 
+	"xorm.io/xorm"
+
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
@@ -20,6 +25,8 @@ import (
 
 const urgentRotateTime = 1 * time.Minute
 
+var errConcurrentSessionLimit = errors.New("concurrent session limit reached")
+
 var getTime = time.Now
 
 func ProvideUserAuthTokenService(sqlStore db.DB, cfg *setting.Cfg, serverLockService *serverlock.ServerLockService, quotaService quota.Service) (*UserAuthTokenService, error) {
@@ -39,6 +46,8 @@ func ProvideUserAuthTokenService(sqlStore db.DB, cfg *setting.Cfg, serverLockSer
 		TargetSrv:     auth.QuotaTargetSrv,
 		DefaultLimits: defaultLimits,
 		Reporter:      s.reportActiveTokenCount,
+		// sessions expire or are revoked continuously
+		RetryAfter: time.Minute,
 	}); err != nil {
 		return s, err
 	}
@@ -81,9 +90,38 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User,
 		AuthTokenSeen: false,
 	}
 
-	err = s.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		_, err = dbSession.Insert(&userAuthToken)
-		return err
+	ctxLogger := s.log.FromContext(ctx)
+	limit := s.cfg.LoginMaxConcurrentSessions
+
+	err = s.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
+		if limit > 0 && s.cfg.LoginConcurrentSessionsEviction == setting.SessionEvictionReject {
+			active, err := s.activeUserTokensQuery(dbSession, user.ID).Table("user_auth_token").Count()
+			if err != nil {
+				return err
+			}
+			if active >= int64(limit) {
+				return &auth.CreateTokenErr{
+					StatusCode:  http.StatusForbidden,
+					InternalErr: fmt.Errorf("user %d has %d active sessions: %w", user.ID, active, errConcurrentSessionLimit),
+					ExternalErr: "Maximum number of concurrent sessions reached, sign out from another device first",
+				}
+			}
+		}
+
+		if _, err := dbSession.Insert(&userAuthToken); err != nil {
+			return err
+		}
+
+		if limit > 0 && s.cfg.LoginConcurrentSessionsEviction == setting.SessionEvictionOldest {
+			evicted, err := s.evictOldestUserTokens(dbSession, user.ID, limit)
+			if err != nil {
+				return err
+			}
+			if evicted > 0 {
+				ctxLogger.Info("Revoked the oldest sessions of the user over the concurrent session limit", "userId", user.ID, "revoked", evicted, "limit", limit)
+			}
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -92,7 +130,6 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User,
 
 	userAuthToken.UnhashedToken = token
 
-	ctxLogger := s.log.FromContext(ctx)
 	ctxLogger.Debug("user auth token created", "tokenId", userAuthToken.Id, "userId", userAuthToken.UserId, "clientIP", userAuthToken.ClientIp, "userAgent", userAuthToken.UserAgent, "authToken", userAuthToken.AuthToken)
 
 	var userToken auth.UserToken
@@ -101,6 +138,34 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User,
 	return &userToken, err
 }
 
+// evictOldestUserTokens revokes the active tokens of the user beyond the newest limit ones. The
+// tokens are revoked rather than deleted, so that the evicted devices are told their session was
+// revoked.
+func (s *UserAuthTokenService) evictOldestUserTokens(dbSession *db.Session, userID int64, limit int) (int64, error) {
+	var tokens []*userAuthToken
+	err := s.activeUserTokensQuery(dbSession, userID).
+		Desc("created_at", "id").
+		Cols("id").
+		Find(&tokens)
+	if err != nil || len(tokens) <= limit {
+		return 0, err
+	}
+
+	ids := make([]int64, 0, len(tokens)-limit)
+	for _, token := range tokens[limit:] {
+		ids = append(ids, token.Id)
+	}
+	return dbSession.In("id", ids).Cols("revoked_at").Update(&userAuthToken{RevokedAt: getTime().Unix()})
+}
+
+// activeUserTokensQuery selects the tokens of the user which are neither expired nor revoked.
+func (s *UserAuthTokenService) activeUserTokensQuery(dbSession *db.Session, userID int64) *xorm.Session {
+	return dbSession.Where("user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0",
+		userID,
+		s.createdAfterParam(),
+		s.rotatedAfterParam())
+}
+
 func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken string) (*auth.UserToken, error) {
 	hashedToken := hashToken(unhashedToken)
 	var model userAuthToken
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -537,6 +538,67 @@ func TestUserAuthToken(t *testing.T) {
 	})
 }
 
+func TestConcurrentSessionLimit(t *testing.T) {
+	now := time.Date(2018, 12, 13, 13, 45, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	defer func() { getTime = time.Now }()
+
+	login := func(ctx *testContext, usr *user.User) (*auth.UserToken, error) {
+		// the sessions are ordered by their creation
+		now = now.Add(time.Second)
+		return ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP("192.168.10.11"), "some user agent")
+	}
+
+	t.Run("Should revoke the oldest sessions over the limit", func(t *testing.T) {
+		ctx := createTestContext(t)
+		ctx.tokenService.cfg.LoginMaxConcurrentSessions = 2
+		ctx.tokenService.cfg.LoginConcurrentSessionsEviction = setting.SessionEvictionOldest
+		usr := &user.User{ID: 10}
+
+		first, err := login(ctx, usr)
+		require.NoError(t, err)
+		second, err := login(ctx, usr)
+		require.NoError(t, err)
+		other, err := login(ctx, &user.User{ID: 11})
+		require.NoError(t, err)
+		third, err := login(ctx, usr)
+		require.NoError(t, err)
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), first.UnhashedToken)
+		var revokedErr *auth.TokenRevokedError
+		require.ErrorAs(t, err, &revokedErr)
+
+		for _, token := range []*auth.UserToken{second, third, other} {
+			_, err = ctx.tokenService.LookupToken(context.Background(), token.UnhashedToken)
+			require.NoError(t, err)
+		}
+
+		active, err := ctx.tokenService.GetUserTokens(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+	})
+
+	t.Run("Should reject the login at the limit", func(t *testing.T) {
+		ctx := createTestContext(t)
+		ctx.tokenService.cfg.LoginMaxConcurrentSessions = 1
+		ctx.tokenService.cfg.LoginConcurrentSessionsEviction = setting.SessionEvictionReject
+		usr := &user.User{ID: 10}
+
+		first, err := login(ctx, usr)
+		require.NoError(t, err)
+
+		_, err = login(ctx, usr)
+		var createTokenErr *auth.CreateTokenErr
+		require.ErrorAs(t, err, &createTokenErr)
+		require.Equal(t, http.StatusForbidden, createTokenErr.StatusCode)
+		require.ErrorIs(t, createTokenErr.InternalErr, errConcurrentSessionLimit)
+
+		require.NoError(t, ctx.tokenService.RevokeToken(context.Background(), first, false))
+		_, err = login(ctx, usr)
+		require.NoError(t, err)
+	})
+}
+
 func createTestContext(t *testing.T) *testContext {
 	t.Helper()
 	maxInactiveDurationVal, _ := time.ParseDuration("168h")
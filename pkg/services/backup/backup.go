@@ -0,0 +1,136 @@
+// Package backup exports a consistent snapshot of the Grafana database, either of all organizations
+// or of a single organization, together with the files Grafana keeps on disk, and restores such
+// snapshots after verifying their integrity.
+//
+// A backup is a gzipped tar archive with one JSON lines file per table, the asset files and a
+// manifest listing the checksum and number of rows of every file. Secrets stay encrypted with the
+// data keys of the instance that created the backup, so they can only be restored into an instance
+// using the same secret key, unless they are left out.
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	manifestFile  = "manifest.json"
+	tablesDir     = "tables/"
+	assetsDir     = "assets/"
+	formatVersion = 1
+)
+
+var (
+	ErrSchemaMismatch    = errors.New("the backup was created with a different database schema")
+	ErrSecretKeyMismatch = errors.New("the backup contains secrets encrypted with a different secret key")
+	ErrChecksumMismatch  = errors.New("the backup is corrupted")
+)
+
+// excludedTables are never backed up. They hold the schema version, locks, caches and login
+// attempts, which belong to the instance and not to its content.
+var excludedTables = map[string]bool{
+	"migration_log":   true,
+	"server_lock":     true,
+	"leader_lease":    true,
+	"semaphore_lease": true,
+	"cache_data":      true,
+	"login_attempt":   true,
+}
+
+// secretTables only hold secrets, they are left out of backups without secrets.
+var secretTables = map[string]bool{
+	"data_keys": true,
+	"secrets":   true,
+}
+
+// secretColumns are replaced with an empty JSON object in backups without secrets.
+var secretColumns = map[string]string{
+	"data_source":        "secure_json_data",
+	"plugin_setting":     "secure_json_data",
+	"alert_notification": "secure_settings",
+}
+
+// childTables belong to an organization through a row of their parent table, they are filtered by
+// the ids of the parent rows of the organization in single organization backups.
+var childTables = map[string]struct {
+	parent string
+	column string
+}{
+	"dashboard_tag":          {"dashboard", "dashboard_id"},
+	"dashboard_version":      {"dashboard", "dashboard_id"},
+	"dashboard_provisioning": {"dashboard", "dashboard_id"},
+	"annotation_tag":         {"annotation", "annotation_id"},
+}
+
+// Manifest describes the content of a backup.
+type Manifest struct {
+	FormatVersion  int       `json:"formatVersion"`
+	GrafanaVersion string    `json:"grafanaVersion"`
+	Created        time.Time `json:"created"`
+	// OrgID is set for backups of a single organization.
+	OrgID int64 `json:"orgId,omitempty"`
+	// SchemaFingerprint identifies the migrations applied to the database, a backup can only be
+	// restored into a database with the same migrations.
+	SchemaFingerprint string `json:"schemaFingerprint"`
+	// SecretKeyFingerprint identifies the secret key the data keys of the secrets are encrypted with.
+	// It is empty for backups without secrets.
+	SecretKeyFingerprint string  `json:"secretKeyFingerprint,omitempty"`
+	Tables               []Entry `json:"tables"`
+	Assets               []Entry `json:"assets"`
+}
+
+// Entry is a file of a backup.
+type Entry struct {
+	// Name is the table name for tables and the path relative to the data path for assets.
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	// Rows is the number of rows of a table.
+	Rows int `json:"rows,omitempty"`
+}
+
+type BackupOptions struct {
+	// OrgID limits the backup to a single organization if set. Assets are not part of single
+	// organization backups.
+	OrgID int64
+	// SkipSecrets leaves the data keys and the encrypted settings out of the backup.
+	SkipSecrets bool
+}
+
+type RestoreOptions struct {
+	// VerifyOnly checks the integrity of the backup without restoring it.
+	VerifyOnly bool
+}
+
+type Service struct {
+	cfg   *setting.Cfg
+	store db.DB
+	log   log.Logger
+	now   func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, store db.DB) *Service {
+	return &Service{
+		cfg:   cfg,
+		store: store,
+		log:   log.New("backup"),
+		now:   time.Now,
+	}
+}
+
+// assetDirs are the directories of the data path backed up as assets: images rendered by the image
+// renderer and the files of the storage service.
+func (s *Service) assetDirs() []string {
+	return []string{"png", "storage"}
+}
+
+func (s *Service) secretKeyFingerprint() string {
+	sum := sha256.Sum256([]byte("grafana-backup:" + s.cfg.SecretKey))
+	return hex.EncodeToString(sum[:8])
+}
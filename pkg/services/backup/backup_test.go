@@ -0,0 +1,200 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestValues(t *testing.T) {
+	assert.Equal(t, "text", encodeValue([]byte("text")))
+	assert.Equal(t, map[string]string{binaryKey: "/w=="}, encodeValue([]byte{0xff}))
+	assert.Equal(t, "2022-01-02 03:04:05", encodeValue(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	decoded, err := decodeValue(map[string]interface{}{binaryKey: "/w=="})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, decoded)
+	_, err = decodeValue(map[string]interface{}{"other": "value"})
+	assert.Error(t, err)
+}
+
+func TestIntegrationBackupRestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	cfg.SecretKey = "backup-secret"
+	s := ProvideService(cfg, sqlStore)
+
+	insertDashboard(t, sqlStore, 10, 100, "tag-a")
+	insertDashboard(t, sqlStore, 20, 200, "tag-b")
+	require.NoError(t, os.MkdirAll(filepath.Join(cfg.DataPath, "png"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.DataPath, "png", "render.png"), []byte{0x89, 'P', 'N', 'G'}, 0600))
+
+	t.Run("Should restore a full backup", func(t *testing.T) {
+		var buf bytes.Buffer
+		manifest, err := s.Backup(ctx, &buf, BackupOptions{})
+		require.NoError(t, err)
+		require.Len(t, manifest.Assets, 1)
+		assert.Equal(t, "png/render.png", manifest.Assets[0].Name)
+
+		deleteDashboards(t, sqlStore)
+		require.NoError(t, os.Remove(filepath.Join(cfg.DataPath, "png", "render.png")))
+
+		_, err = s.Restore(ctx, &buf, RestoreOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count(t, sqlStore, "SELECT id FROM dashboard"))
+		assert.Equal(t, 2, count(t, sqlStore, "SELECT id FROM dashboard_tag"))
+		content, err := os.ReadFile(filepath.Join(cfg.DataPath, "png", "render.png"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, content)
+	})
+
+	t.Run("Should only restore the organization of a single organization backup", func(t *testing.T) {
+		var buf bytes.Buffer
+		manifest, err := s.Backup(ctx, &buf, BackupOptions{OrgID: 10})
+		require.NoError(t, err)
+		assert.Empty(t, manifest.Assets)
+		for _, table := range manifest.Tables {
+			if table.Name == "dashboard" || table.Name == "dashboard_tag" || table.Name == "org" {
+				assert.Equal(t, 1, table.Rows, table.Name)
+			}
+		}
+
+		deleteDashboards(t, sqlStore)
+		insertDashboard(t, sqlStore, 20, 300, "tag-c")
+
+		_, err = s.Restore(ctx, &buf, RestoreOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count(t, sqlStore, "SELECT id FROM dashboard WHERE id = 100"))
+		assert.Equal(t, 1, count(t, sqlStore, "SELECT id FROM dashboard WHERE id = 300"))
+		assert.Equal(t, 0, count(t, sqlStore, "SELECT id FROM dashboard WHERE id = 200"))
+		assert.Equal(t, 2, count(t, sqlStore, "SELECT dashboard_id FROM dashboard_tag"))
+	})
+
+	t.Run("Should not restore a corrupted backup", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := s.Backup(ctx, &buf, BackupOptions{})
+		require.NoError(t, err)
+
+		corrupted := rewriteBackup(t, buf.Bytes(), tablesDir+"dashboard.jsonl", func(content []byte) []byte {
+			return bytes.Replace(content, []byte("dashboard-100"), []byte("dashboard-999"), 1)
+		})
+		_, err = s.Restore(ctx, bytes.NewReader(corrupted), RestoreOptions{VerifyOnly: true})
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+		_, err = s.Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreOptions{VerifyOnly: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should not restore secrets encrypted with another secret key", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := s.Backup(ctx, &buf, BackupOptions{})
+		require.NoError(t, err)
+
+		otherCfg := setting.NewCfg()
+		otherCfg.SecretKey = "other-secret"
+		_, err = ProvideService(otherCfg, sqlStore).Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreOptions{VerifyOnly: true})
+		assert.ErrorIs(t, err, ErrSecretKeyMismatch)
+
+		buf.Reset()
+		_, err = s.Backup(ctx, &buf, BackupOptions{SkipSecrets: true})
+		require.NoError(t, err)
+		_, err = ProvideService(otherCfg, sqlStore).Restore(ctx, bytes.NewReader(buf.Bytes()), RestoreOptions{VerifyOnly: true})
+		assert.NoError(t, err)
+	})
+}
+
+func insertDashboard(t *testing.T, sqlStore db.DB, orgID int64, id int64, tag string) {
+	t.Helper()
+	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		exists, err := sess.SQL("SELECT 1 FROM org WHERE id = ?", orgID).Exist()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if _, err := sess.Exec("INSERT INTO org (id, version, name, created, updated) VALUES (?, 0, ?, ?, ?)",
+				orgID, fmt.Sprintf("org-%d", orgID), time.Now(), time.Now()); err != nil {
+				return err
+			}
+		}
+		if _, err := sess.Exec("INSERT INTO dashboard (id, version, slug, title, data, org_id, created, updated, uid) VALUES (?, 1, ?, ?, ?, ?, ?, ?, ?)",
+			id, "dashboard", "dashboard", `{"title":"dashboard"}`, orgID, time.Now(), time.Now(), fmt.Sprintf("dashboard-%d", id)); err != nil {
+			return err
+		}
+		_, err = sess.Exec("INSERT INTO dashboard_tag (dashboard_id, term) VALUES (?, ?)", id, tag)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func deleteDashboards(t *testing.T, sqlStore db.DB) {
+	t.Helper()
+	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM dashboard_tag"); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM dashboard")
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func count(t *testing.T, sqlStore db.DB, query string) int {
+	t.Helper()
+	var n int
+	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		rows, err := sess.QueryString(query)
+		n = len(rows)
+		return err
+	})
+	require.NoError(t, err)
+	return n
+}
+
+// rewriteBackup changes the content of a file of a backup without updating the manifest.
+func rewriteBackup(t *testing.T, backup []byte, name string, change func([]byte) []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(backup))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var out bytes.Buffer
+	gzw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gzw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		if header.Name == name {
+			content = change(content)
+		}
+		header.Size = int64(len(content))
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return out.Bytes()
+}
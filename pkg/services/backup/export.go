@@ -0,0 +1,329 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Backup writes a backup to w. All tables are read in a single transaction, so that the backup is
+// a consistent snapshot of the database.
+func (s *Service) Backup(ctx context.Context, w io.Writer, opts BackupOptions) (*Manifest, error) {
+	manifest := &Manifest{
+		FormatVersion:  formatVersion,
+		GrafanaVersion: setting.BuildVersion,
+		Created:        s.now().UTC(),
+		OrgID:          opts.OrgID,
+		Tables:         make([]Entry, 0),
+		Assets:         make([]Entry, 0),
+	}
+	if !opts.SkipSecrets {
+		manifest.SecretKeyFingerprint = s.secretKeyFingerprint()
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if s.store.GetDialect().DriverName() == migrator.Postgres {
+			if _, err := sess.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ"); err != nil {
+				return err
+			}
+		}
+
+		fingerprint, err := schemaFingerprint(sess)
+		if err != nil {
+			return err
+		}
+		manifest.SchemaFingerprint = fingerprint
+
+		tables, err := listTables(sess, s.store.GetDialect().DriverName())
+		if err != nil {
+			return err
+		}
+
+		// ids of the parent rows of the organization, by parent table
+		parentIDs := map[string]map[int64]bool{}
+		for _, child := range childTables {
+			parentIDs[child.parent] = map[int64]bool{}
+		}
+
+		for _, table := range tables {
+			entry, err := s.exportTable(sess, tw, table, opts, parentIDs)
+			if err != nil {
+				return fmt.Errorf("failed to back up table %s: %w", table, err)
+			}
+			if entry != nil {
+				manifest.Tables = append(manifest.Tables, *entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OrgID == 0 {
+		for _, dir := range s.assetDirs() {
+			entries, err := s.exportAssets(tw, dir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to back up assets: %w", err)
+			}
+			manifest.Assets = append(manifest.Assets, entries...)
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestFile, int64(len(content)), strings.NewReader(string(content))); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, gz.Close()
+}
+
+// exportTable writes the rows of a table included in the backup as JSON lines. It returns nil if
+// the table is not part of the backup.
+func (s *Service) exportTable(sess *db.Session, tw *tar.Writer, table string, opts BackupOptions, parentIDs map[string]map[int64]bool) (*Entry, error) {
+	if excludedTables[table] || (opts.SkipSecrets && secretTables[table]) {
+		return nil, nil
+	}
+
+	rows, err := sess.QueryInterface("SELECT * FROM " + s.store.Quote(table))
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "grafana-backup-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	out := io.MultiWriter(tmp, hash)
+	enc := json.NewEncoder(out)
+	count := 0
+	for _, row := range rows {
+		if opts.OrgID != 0 && !s.belongsToOrg(table, row, opts.OrgID, parentIDs) {
+			continue
+		}
+		if ids, ok := parentIDs[table]; ok {
+			if id, ok := int64Value(row["id"]); ok {
+				ids[id] = true
+			}
+		}
+
+		encoded := make(map[string]interface{}, len(row))
+		for column, value := range row {
+			encoded[column] = encodeValue(value)
+		}
+		if column, ok := secretColumns[table]; ok && opts.SkipSecrets {
+			if _, exists := encoded[column]; exists {
+				encoded[column] = "{}"
+			}
+		}
+		if err := enc.Encode(encoded); err != nil {
+			return nil, err
+		}
+		count++
+	}
+
+	// Empty tables of single organization backups are only kept if they are organization scoped,
+	// so that restoring the backup removes the rows added to the organization since.
+	if count == 0 && opts.OrgID != 0 {
+		if _, isChild := childTables[table]; !isChild {
+			orgScoped, err := hasColumn(sess, s.store.GetDialect().DriverName(), table, "org_id")
+			if err != nil {
+				return nil, err
+			}
+			if !orgScoped {
+				return nil, nil
+			}
+		}
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, tablesDir+table+".jsonl", size, tmp); err != nil {
+		return nil, err
+	}
+	return &Entry{Name: table, SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size, Rows: count}, nil
+}
+
+// belongsToOrg returns true if a row is part of the backup of a single organization: rows of
+// organization scoped tables with the organization id, the organization itself, rows of child
+// tables belonging to its parent rows, and the data keys needed to decrypt its secrets.
+func (s *Service) belongsToOrg(table string, row map[string]interface{}, orgID int64, parentIDs map[string]map[int64]bool) bool {
+	switch table {
+	case "org":
+		id, _ := int64Value(row["id"])
+		return id == orgID
+	case "data_keys":
+		return true
+	}
+	if child, ok := childTables[table]; ok {
+		id, _ := int64Value(row[child.column])
+		return parentIDs[child.parent][id]
+	}
+	if value, ok := row["org_id"]; ok {
+		id, _ := int64Value(value)
+		return id == orgID
+	}
+	return false
+}
+
+// exportAssets adds the files of a directory of the data path to the backup.
+func (s *Service) exportAssets(tw *tar.Writer, dir string) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	root := filepath.Join(s.cfg.DataPath, dir)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			// The storage cache is rebuilt by the storage service.
+			if path == filepath.Join(s.cfg.DataPath, "storage", "cache") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.cfg.DataPath, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		// #nosec G304 -- the path is below the data path
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		hash := sha256.New()
+		if err := writeEntry(tw, assetsDir+name, info.Size(), io.TeeReader(f, hash)); err != nil {
+			return err
+		}
+		entries = append(entries, Entry{Name: name, SHA256: hex.EncodeToString(hash.Sum(nil)), Size: info.Size()})
+		return nil
+	})
+	return entries, err
+}
+
+func writeEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: size, Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	_, err := io.CopyN(tw, r, size)
+	return err
+}
+
+// listTables returns the tables of the database, sorted so that parent tables come before their
+// child tables.
+func listTables(sess *db.Session, driver string) ([]string, error) {
+	var query string
+	switch driver {
+	case migrator.SQLite:
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+	case migrator.MySQL:
+		query = "SELECT table_name AS name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'"
+	case migrator.Postgres:
+		query = "SELECT tablename AS name FROM pg_tables WHERE schemaname = current_schema()"
+	default:
+		return nil, fmt.Errorf("backups are not supported for database type %s", driver)
+	}
+
+	rows, err := sess.QueryString(query)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0, len(rows))
+	for _, row := range rows {
+		name := row["name"]
+		if name == "" {
+			// MySQL 8 returns the column name of information_schema in upper case
+			name = row["NAME"]
+		}
+		tables = append(tables, name)
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		_, iChild := childTables[tables[i]]
+		_, jChild := childTables[tables[j]]
+		if iChild != jChild {
+			return jChild
+		}
+		return tables[i] < tables[j]
+	})
+	return tables, nil
+}
+
+// hasColumn returns true if a table has a column.
+func hasColumn(sess *db.Session, driver string, table string, column string) (bool, error) {
+	var rows []map[string]string
+	var err error
+	switch driver {
+	case migrator.SQLite:
+		rows, err = sess.QueryString("SELECT name FROM pragma_table_info(?) WHERE name = ?", table, column)
+	case migrator.MySQL:
+		rows, err = sess.QueryString("SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?", table, column)
+	default:
+		rows, err = sess.QueryString("SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?", table, column)
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(rows) > 0, nil
+}
+
+// schemaFingerprint hashes the ids of the migrations applied to the database.
+func schemaFingerprint(sess *db.Session) (string, error) {
+	rows, err := sess.QueryString("SELECT migration_id FROM migration_log WHERE success = ?", true)
+	if err != nil {
+		return "", err
+	}
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row["migration_id"])
+	}
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(strings.Join(ids, "\n")))
+	return hex.EncodeToString(sum[:]), nil
+}
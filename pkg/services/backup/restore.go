@@ -0,0 +1,324 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// Restore verifies the backup read from r and restores it, replacing the content of the database
+// for full backups, or the content of the organization for single organization backups. The
+// database is changed in a single transaction, so a failed restore leaves it untouched.
+func (s *Service) Restore(ctx context.Context, r io.Reader, opts RestoreOptions) (*Manifest, error) {
+	dir, err := os.MkdirTemp("", "grafana-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			s.log.Warn("Failed to remove temporary restore directory", "path", dir, "error", err)
+		}
+	}()
+
+	if err := extract(r, dir); err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	manifest, err := s.verify(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if opts.VerifyOnly {
+		return manifest, nil
+	}
+
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := s.deleteRows(sess, manifest); err != nil {
+			return err
+		}
+		for _, table := range manifest.Tables {
+			if err := s.insertRows(sess, manifest, dir, table.Name); err != nil {
+				return fmt.Errorf("failed to restore table %s: %w", table.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, asset := range manifest.Assets {
+		if err := s.restoreAsset(dir, asset.Name); err != nil {
+			return nil, fmt.Errorf("failed to restore asset %s: %w", asset.Name, err)
+		}
+	}
+	return manifest, nil
+}
+
+// verify checks the checksums and the number of rows of the files of an extracted backup, and that
+// the backup can be restored into the database.
+func (s *Service) verify(ctx context.Context, dir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing manifest", ErrChecksumMismatch)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %s", ErrChecksumMismatch, err)
+	}
+	if manifest.FormatVersion != formatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d", manifest.FormatVersion)
+	}
+
+	for _, table := range manifest.Tables {
+		file := filepath.Join(dir, tablesDir+table.Name+".jsonl")
+		if err := verifyFile(file, table); err != nil {
+			return nil, err
+		}
+		rows := 0
+		if err := readRows(file, func(map[string]interface{}) error { rows++; return nil }); err != nil {
+			return nil, fmt.Errorf("%w: table %s: %s", ErrChecksumMismatch, table.Name, err)
+		}
+		if rows != table.Rows {
+			return nil, fmt.Errorf("%w: table %s has %d rows, expected %d", ErrChecksumMismatch, table.Name, rows, table.Rows)
+		}
+	}
+	for _, asset := range manifest.Assets {
+		if err := verifyFile(filepath.Join(dir, assetsDir+asset.Name), asset); err != nil {
+			return nil, err
+		}
+	}
+
+	var fingerprint string
+	err = s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		fingerprint, err = schemaFingerprint(sess)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint != manifest.SchemaFingerprint {
+		return nil, fmt.Errorf("%w: restore it with Grafana %s", ErrSchemaMismatch, manifest.GrafanaVersion)
+	}
+	if manifest.SecretKeyFingerprint != "" && manifest.SecretKeyFingerprint != s.secretKeyFingerprint() {
+		return nil, ErrSecretKeyMismatch
+	}
+	return manifest, nil
+}
+
+func verifyFile(file string, entry Entry) error {
+	// #nosec G304 -- the file is below the extraction directory
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("%w: missing file for %s", ErrChecksumMismatch, entry.Name)
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return err
+	}
+	if size != entry.Size || hex.EncodeToString(hash.Sum(nil)) != entry.SHA256 {
+		return fmt.Errorf("%w: checksum mismatch for %s", ErrChecksumMismatch, entry.Name)
+	}
+	return nil
+}
+
+// deleteRows deletes the rows replaced by the backup. Child tables are deleted before their parent
+// tables, since their rows are found through the parent rows of the organization.
+func (s *Service) deleteRows(sess *db.Session, manifest *Manifest) error {
+	tables := make([]string, 0, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		tables = append(tables, table.Name)
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		_, iChild := childTables[tables[i]]
+		_, jChild := childTables[tables[j]]
+		return iChild && !jChild
+	})
+
+	for _, table := range tables {
+		quoted := s.store.Quote(table)
+		var err error
+		switch {
+		case manifest.OrgID == 0:
+			_, err = sess.Exec("DELETE FROM " + quoted)
+		case table == "org":
+			_, err = sess.Exec("DELETE FROM "+quoted+" WHERE id = ?", manifest.OrgID)
+		case table == "data_keys":
+			// Data keys are shared by all organizations, missing ones are added when inserting.
+		default:
+			if child, ok := childTables[table]; ok {
+				_, err = sess.Exec("DELETE FROM "+quoted+" WHERE "+s.store.Quote(child.column)+" IN (SELECT id FROM "+
+					s.store.Quote(child.parent)+" WHERE org_id = ?)", manifest.OrgID)
+			} else {
+				_, err = sess.Exec("DELETE FROM "+quoted+" WHERE org_id = ?", manifest.OrgID)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete rows of table %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) insertRows(sess *db.Session, manifest *Manifest, dir string, table string) error {
+	quoted := s.store.Quote(table)
+	var maxID int64
+	err := readRows(filepath.Join(dir, tablesDir+table+".jsonl"), func(row map[string]interface{}) error {
+		if table == "data_keys" && manifest.OrgID != 0 {
+			exists, err := sess.SQL("SELECT 1 FROM "+quoted+" WHERE id = ?", row["id"]).Exist()
+			if err != nil || exists {
+				return err
+			}
+		}
+
+		columns := make([]string, 0, len(row))
+		for column := range row {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+
+		args := make([]interface{}, 0, len(columns)+1)
+		quotedColumns := make([]string, 0, len(columns))
+		for _, column := range columns {
+			value, err := decodeValue(row[column])
+			if err != nil {
+				return fmt.Errorf("column %s: %w", column, err)
+			}
+			args = append(args, value)
+			quotedColumns = append(quotedColumns, s.store.Quote(column))
+		}
+		if id, ok := row["id"].(json.Number); ok {
+			if i, err := id.Int64(); err == nil && i > maxID {
+				maxID = i
+			}
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+		query := "INSERT INTO " + quoted + " (" + strings.Join(quotedColumns, ", ") + ") VALUES (" + placeholders + ")"
+		_, err := sess.Exec(append([]interface{}{query}, args...)...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Postgres does not advance sequences for rows inserted with an explicit id.
+	if maxID > 0 && s.store.GetDialect().DriverName() == migrator.Postgres {
+		if _, err := sess.Exec("SELECT setval(pg_get_serial_sequence(?, 'id'), GREATEST(MAX(id), 1)) FROM "+quoted, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) restoreAsset(dir string, name string) error {
+	target := filepath.Join(s.cfg.DataPath, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return err
+	}
+	// #nosec G304 -- the file is below the extraction directory
+	src, err := os.Open(filepath.Join(dir, assetsDir+name))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	// #nosec G304 -- the name was checked when extracting the backup
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// readRows calls fn for every row of a table file.
+func readRows(file string, fn func(row map[string]interface{}) error) error {
+	// #nosec G304 -- the file is below the extraction directory
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	for {
+		row := map[string]interface{}{}
+		err := dec.Decode(&row)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// extract writes the regular files of a backup to dir, rejecting names outside of it.
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unexpected entry %s", header.Name)
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid entry name %s", header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return err
+		}
+		// #nosec G304 -- the name was checked above
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		// #nosec G110 -- the size of the backup is chosen by the administrator restoring it
+		if _, err := io.Copy(f, tr); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// dateTimeFormat is the format xorm writes date times in, used so that restored rows compare the
+// same as the rows written by Grafana.
+const dateTimeFormat = "2006-01-02 15:04:05"
+
+// binaryKey marks a JSON object holding base64 encoded binary data.
+const binaryKey = "$base64"
+
+// encodeValue converts a value read from the database to a value which is preserved by JSON.
+func encodeValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case []byte:
+		if utf8.Valid(value) {
+			return string(value)
+		}
+		return map[string]string{binaryKey: base64.StdEncoding.EncodeToString(value)}
+	case time.Time:
+		return value.UTC().Format(dateTimeFormat)
+	default:
+		return value
+	}
+}
+
+// decodeValue converts a value of a backup, decoded with json.Decoder.UseNumber, to a value
+// which can be written to the database.
+func decodeValue(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case json.Number:
+		if !strings.ContainsAny(value.String(), ".eE") {
+			if i, err := value.Int64(); err == nil {
+				return i, nil
+			}
+		}
+		return value.Float64()
+	case map[string]interface{}:
+		encoded, ok := value[binaryKey].(string)
+		if !ok || len(value) != 1 {
+			return nil, fmt.Errorf("unexpected object value")
+		}
+		return base64.StdEncoding.DecodeString(encoded)
+	default:
+		return value, nil
+	}
+}
+
+// int64Value returns the value of an id column, which drivers return with different types.
+func int64Value(v interface{}) (int64, bool) {
+	switch id := v.(type) {
+	case int64:
+		return id, true
+	case int32:
+		return int64(id), true
+	case int:
+		return int64(id), true
+	case []byte:
+		i, err := strconv.ParseInt(string(id), 10, 64)
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(id, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,166 @@
+package cdnassets
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *AssetService) registerAPIEndpoints() {
+	// the CDN pulls the assets anonymously, they are protected by the signature of their URL
+	s.routeRegister.Get("/"+routePrefix+":pluginId/:hash/:token/*", s.serveAssetHandler)
+
+	s.routeRegister.Get("/api/frontend/assets", middleware.ReqSignedIn, routing.Wrap(s.getManifestHandler))
+	s.routeRegister.Post("/api/admin/frontend/assets/refresh", middleware.ReqGrafanaAdmin, routing.Wrap(s.refreshManifestHandler))
+}
+
+// ManifestDTO is the manifest of the plugin assets, with the current URLs of the plugins on the CDN.
+// swagger:model CDNAssetsManifestDTO
+type ManifestDTO struct {
+	Generated time.Time                   `json:"generated"`
+	Plugins   map[string]*PluginAssetsDTO `json:"plugins"`
+}
+
+type PluginAssetsDTO struct {
+	PluginAssets
+	BaseURL string `json:"baseUrl"`
+}
+
+// swagger:route GET /frontend/assets frontend getFrontendAssets
+//
+// Get the manifest of the plugin assets served from the CDN.
+//
+// Returns the hash of the assets of every plugin served from the CDN, with the current, signed URL of its assets.
+//
+// Responses:
+// 200: getFrontendAssetsResponse
+// 401: unauthorisedError
+func (s *AssetService) getManifestHandler(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, s.toDTO(s.GetManifest()))
+}
+
+// swagger:route POST /admin/frontend/assets/refresh admin refreshFrontendAssets
+//
+// Refresh the manifest of the plugin assets.
+//
+// Hashes the files of the plugins again, e.g. after a plugin was updated on disk. The manifest is otherwise refreshed periodically.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getFrontendAssetsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *AssetService) refreshManifestHandler(c *models.ReqContext) response.Response {
+	manifest, err := s.Refresh(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to refresh the manifest of the plugin assets", err)
+	}
+	return response.JSON(http.StatusOK, s.toDTO(manifest))
+}
+
+func (s *AssetService) toDTO(manifest *Manifest) *ManifestDTO {
+	dto := &ManifestDTO{Plugins: map[string]*PluginAssetsDTO{}}
+	if manifest == nil {
+		return dto
+	}
+	dto.Generated = manifest.Generated
+	for id, assets := range manifest.Plugins {
+		baseURL, _ := s.PluginBaseURL(id)
+		dto.Plugins[id] = &PluginAssetsDTO{PluginAssets: *assets, BaseURL: baseURL}
+	}
+	return dto
+}
+
+// serveAssetHandler serves an asset of a plugin to the CDN.
+//
+// /public/plugins-cdn/:pluginId/:hash/:token/*
+func (s *AssetService) serveAssetHandler(c *models.ReqContext) {
+	params := web.Params(c.Req)
+	pluginID, hash := params[":pluginId"], params[":hash"]
+
+	expires, err := s.verify(pluginID, hash, params[":token"])
+	if err != nil {
+		c.JsonApiErr(http.StatusForbidden, err.Error(), nil)
+		return
+	}
+
+	// the assets of another version of the plugin would be cached under the hash of this version
+	manifest := s.GetManifest()
+	if manifest == nil || manifest.Plugins[pluginID] == nil || manifest.Plugins[pluginID].Hash != hash {
+		c.JsonApiErr(http.StatusNotFound, "Plugin assets not found", nil)
+		return
+	}
+	plugin, exists := s.pluginStore.Plugin(c.Req.Context(), pluginID)
+	if !exists {
+		c.JsonApiErr(http.StatusNotFound, "Plugin not found", nil)
+		return
+	}
+
+	// prepend slash for cleaning relative paths
+	rel, err := filepath.Rel("/", filepath.Clean(filepath.Join("/", params["*"])))
+	if err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to get the relative path", err)
+		return
+	}
+	if !plugin.IncludedInSignature(rel) {
+		s.log.Warn("Access to requested plugin file will be forbidden in upcoming Grafana versions as the file "+
+			"is not included in the plugin signature", "file", rel)
+	}
+
+	dir, err := filepath.Abs(plugin.PluginDir)
+	if err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to get plugin absolute path", nil)
+		return
+	}
+	path := filepath.Join(dir, rel)
+
+	// It's safe to ignore gosec warning G304 since the requested path is cleaned above and joined to
+	// the directory of the plugin
+	// nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.JsonApiErr(http.StatusNotFound, "Plugin file not found", err)
+			return
+		}
+		c.JsonApiErr(http.StatusInternalServerError, "Could not open plugin file", err)
+		return
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			s.log.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		c.JsonApiErr(http.StatusNotFound, "Plugin file not found", err)
+		return
+	}
+
+	// the content of the URL never changes, but the CDN must not serve it once the signature expired
+	maxAge := int64(365 * 24 * time.Hour / time.Second)
+	if !expires.IsZero() {
+		maxAge = int64(expires.Sub(s.now()) / time.Second)
+	}
+	c.Resp.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", maxAge))
+	http.ServeContent(c.Resp, c.Req, path, fi.ModTime(), f)
+}
+
+// swagger:response getFrontendAssetsResponse
+type GetFrontendAssetsResponse struct {
+	// in:body
+	Body ManifestDTO `json:"body"`
+}
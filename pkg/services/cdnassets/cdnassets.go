@@ -0,0 +1,257 @@
+// Package cdnassets serves the assets of the plugins through a CDN pulling them from Grafana.
+//
+// The URLs of the assets contain a hash of the files of their plugin, kept in a manifest refreshed
+// by the backend, so they change whenever a plugin is installed or updated and can be cached by the
+// CDN and the browsers. When a signing key is configured, the URLs are also signed with an expiry,
+// and the assets are only served with a valid signature, so that the CDN URLs cannot be forged.
+package cdnassets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// routePrefix is the path the plugin assets are served from to the CDN, relative to the root URL
+// of the CDN and of this instance.
+const routePrefix = "public/plugins-cdn/"
+
+// PluginAssets is the manifest entry of the assets of a plugin.
+type PluginAssets struct {
+	PluginID string `json:"pluginId"`
+	Version  string `json:"version"`
+	// Hash changes whenever any file of the plugin changes.
+	Hash  string `json:"hash"`
+	Files int    `json:"files"`
+}
+
+// Manifest lists the hashes of the assets of the plugins.
+// swagger:model CDNAssetsManifest
+type Manifest struct {
+	Generated time.Time                `json:"generated"`
+	Plugins   map[string]*PluginAssets `json:"plugins"`
+}
+
+type fileHash struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+type AssetService struct {
+	settings      setting.CDNSettings
+	pluginStore   plugins.Store
+	routeRegister routing.RouteRegister
+	log           log.Logger
+	now           func() time.Time
+
+	mu       sync.RWMutex
+	manifest *Manifest
+
+	// refreshMu serializes the refreshes, which share the cached hashes of the files.
+	refreshMu sync.Mutex
+	files     map[string]fileHash
+}
+
+func ProvideService(cfg *setting.Cfg, pluginStore plugins.Store, routeRegister routing.RouteRegister) *AssetService {
+	s := &AssetService{
+		settings:      cfg.CDN,
+		pluginStore:   pluginStore,
+		routeRegister: routeRegister,
+		log:           log.New("cdn-assets"),
+		now:           time.Now,
+		files:         map[string]fileHash{},
+	}
+
+	if !s.IsDisabled() {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+func (s *AssetService) IsDisabled() bool {
+	return s.settings.PluginsURL == nil
+}
+
+// Run keeps the manifest up to date with the installed plugins.
+func (s *AssetService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.ManifestRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Refresh(ctx); err != nil {
+			s.log.Error("Failed to refresh the manifest of the plugin assets", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetManifest returns the current manifest, nil until it has been generated.
+func (s *AssetService) GetManifest() *Manifest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.manifest
+}
+
+// Refresh hashes the files of the plugins again. Only the files whose size or modification time
+// changed are read.
+func (s *AssetService) Refresh(ctx context.Context) (*Manifest, error) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	manifest := &Manifest{Generated: s.now(), Plugins: map[string]*PluginAssets{}}
+	seen := map[string]bool{}
+	for _, p := range s.pluginStore.Plugins(ctx) {
+		if p.IsCorePlugin() || p.PluginDir == "" {
+			// the core plugins are part of the frontend build
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		assets, err := s.hashPlugin(p, seen)
+		if err != nil {
+			// the plugin keeps being served by this instance
+			s.log.Warn("Failed to hash the assets of a plugin", "pluginId", p.ID, "error", err)
+			continue
+		}
+		manifest.Plugins[p.ID] = assets
+	}
+
+	for path := range s.files {
+		if !seen[path] {
+			delete(s.files, path)
+		}
+	}
+
+	s.mu.Lock()
+	s.manifest = manifest
+	s.mu.Unlock()
+	return manifest, nil
+}
+
+func (s *AssetService) hashPlugin(p plugins.PluginDTO, seen map[string]bool) (*PluginAssets, error) {
+	dir, err := filepath.Abs(p.PluginDir)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct{ rel, hash string }
+	var entries []entry
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		seen[path] = true
+		cached, ok := s.files[path]
+		if !ok || cached.size != info.Size() || !cached.modTime.Equal(info.ModTime()) {
+			hash, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			cached = fileHash{size: info.Size(), modTime: info.ModTime(), hash: hash}
+			s.files[path] = cached
+		}
+		entries = append(entries, entry{rel: filepath.ToSlash(rel), hash: cached.hash})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].rel < entries[j].rel })
+	hash := sha256.New()
+	for _, e := range entries {
+		// the paths cannot contain a NUL
+		fmt.Fprintf(hash, "%s\x00%s\n", e.rel, e.hash)
+	}
+	return &PluginAssets{
+		PluginID: p.ID,
+		Version:  p.Info.Version,
+		Hash:     hex.EncodeToString(hash.Sum(nil))[:20],
+		Files:    len(entries),
+	}, nil
+}
+
+func hashFile(path string) (string, error) {
+	// It's safe to ignore gosec warning G304 since the path is a file of a plugin directory
+	// nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// WithCDNURLs returns the plugin with its module and base URL on the CDN, or unchanged if its
+// assets are not served from the CDN.
+func (s *AssetService) WithCDNURLs(p plugins.PluginDTO) plugins.PluginDTO {
+	baseURL, ok := s.PluginBaseURL(p.ID)
+	if !ok {
+		return p
+	}
+	p.BaseURL = baseURL
+	p.Module = baseURL + "/module.js"
+	return p
+}
+
+// PluginBaseURL returns the URL of the assets of a plugin on the CDN, signed when a signing key is
+// configured. It returns false when the plugin is not served from the CDN, because the CDN is not
+// configured, the plugin is a core plugin or its assets have not been hashed yet.
+func (s *AssetService) PluginBaseURL(pluginID string) (string, bool) {
+	if s.IsDisabled() {
+		return "", false
+	}
+	manifest := s.GetManifest()
+	if manifest == nil || manifest.Plugins[pluginID] == nil {
+		return "", false
+	}
+
+	hash := manifest.Plugins[pluginID].Hash
+	token := unsignedToken
+	if s.settings.SigningKey != "" {
+		token = s.sign(pluginID, hash, s.expiry())
+	}
+
+	u := *s.settings.PluginsURL
+	u.Path += routePrefix + pluginID + "/" + hash + "/" + token
+	return u.String(), true
+}
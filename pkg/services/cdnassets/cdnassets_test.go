@@ -0,0 +1,167 @@
+package cdnassets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	s, dir := setupService(t, "")
+
+	manifest, err := s.Refresh(ctx)
+	require.NoError(t, err)
+	require.Len(t, manifest.Plugins, 1, "core plugins should not be hashed")
+	assets := manifest.Plugins["test-panel"]
+	require.NotNil(t, assets)
+	assert.Equal(t, 2, assets.Files)
+	assert.Equal(t, "1.0.0", assets.Version)
+
+	again, err := s.Refresh(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, assets.Hash, again.Plugins["test-panel"].Hash)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module.js"), []byte("define([], function() { return 2 })"), 0600))
+	changed, err := s.Refresh(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, assets.Hash, changed.Plugins["test-panel"].Hash)
+}
+
+func TestWithCDNURLs(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupService(t, "")
+	plugin, _ := s.pluginStore.Plugin(ctx, "test-panel")
+
+	assert.Equal(t, plugin, s.WithCDNURLs(plugin), "the plugin should be served by the instance until it is hashed")
+
+	manifest, err := s.Refresh(ctx)
+	require.NoError(t, err)
+	withURLs := s.WithCDNURLs(plugin)
+	base := "https://cdn.example.com/grafana/public/plugins-cdn/test-panel/" + manifest.Plugins["test-panel"].Hash + "/-"
+	assert.Equal(t, base, withURLs.BaseURL)
+	assert.Equal(t, base+"/module.js", withURLs.Module)
+
+	core, _ := s.pluginStore.Plugin(ctx, "timeseries")
+	assert.Equal(t, core, s.WithCDNURLs(core))
+}
+
+func TestSigning(t *testing.T) {
+	s, _ := setupService(t, "secret")
+	now := time.Unix(1669000000, 0)
+	s.now = func() time.Time { return now }
+
+	expires := s.expiry()
+	assert.True(t, expires.Sub(now) >= s.settings.SignedURLTTL, "the URLs should be valid for at least one period")
+	s.now = func() time.Time { return now.Add(time.Minute) }
+	assert.Equal(t, expires, s.expiry(), "the URLs should not change within a period")
+
+	token := s.sign("test-panel", "abc", expires)
+	got, err := s.verify("test-panel", "abc", token)
+	require.NoError(t, err)
+	assert.Equal(t, expires, got)
+
+	_, err = s.verify("test-panel", "abd", token)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = s.verify("other-panel", "abc", token)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = s.verify("test-panel", "abc", unsignedToken)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	s.now = func() time.Time { return expires }
+	_, err = s.verify("test-panel", "abc", token)
+	assert.ErrorIs(t, err, ErrSignatureExpired)
+}
+
+func TestServeAsset(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupService(t, "secret")
+	_, err := s.Refresh(ctx)
+	require.NoError(t, err)
+
+	baseURL, ok := s.PluginBaseURL("test-panel")
+	require.True(t, ok)
+	u, err := url.Parse(baseURL)
+	require.NoError(t, err)
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/grafana/"+routePrefix), "/")
+	require.Len(t, parts, 3)
+	pluginID, hash, token := parts[0], parts[1], parts[2]
+
+	t.Run("Should serve the assets of the hashed version with a valid signature", func(t *testing.T) {
+		resp := serveAsset(t, s, pluginID, hash, token, "module.js")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), "define")
+		assert.Contains(t, resp.Header().Get("Cache-Control"), "public, max-age=")
+	})
+
+	t.Run("Should not serve files outside of the plugin", func(t *testing.T) {
+		resp := serveAsset(t, s, pluginID, hash, token, "../../../../etc/passwd")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Should reject invalid signatures", func(t *testing.T) {
+		resp := serveAsset(t, s, pluginID, hash, unsignedToken, "module.js")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Should not serve other versions of the assets", func(t *testing.T) {
+		other := "0123456789abcdef0123"
+		resp := serveAsset(t, s, pluginID, other, s.sign(pluginID, other, s.expiry()), "module.js")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func setupService(t *testing.T, signingKey string) (*AssetService, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module.js"), []byte("define([], function() { return 1 })"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(`{"id": "test-panel"}`), 0600))
+
+	cfg := setting.NewCfg()
+	cfg.CDN = setting.CDNSettings{
+		PluginsURL:              &url.URL{Scheme: "https", Host: "cdn.example.com", Path: "/grafana/"},
+		SigningKey:              signingKey,
+		SignedURLTTL:            24 * time.Hour,
+		ManifestRefreshInterval: time.Minute,
+	}
+	store := plugins.FakePluginStore{PluginList: []plugins.PluginDTO{
+		{JSONData: plugins.JSONData{ID: "test-panel", Type: plugins.Panel, Info: plugins.Info{Version: "1.0.0"}}, PluginDir: dir, Class: plugins.External},
+		{JSONData: plugins.JSONData{ID: "timeseries", Type: plugins.Panel}, PluginDir: t.TempDir(), Class: plugins.Core},
+	}}
+	return ProvideService(cfg, store, routing.NewRouteRegister()), dir
+}
+
+func serveAsset(t *testing.T, s *AssetService, pluginID, hash, token, file string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "/"+routePrefix+pluginID+"/"+hash+"/"+token+"/"+file, nil)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	c := &models.ReqContext{
+		Context: &web.Context{
+			Req:  web.SetURLParams(req, map[string]string{":pluginId": pluginID, ":hash": hash, ":token": token, "*": file}),
+			Resp: web.NewResponseWriter(http.MethodGet, resp),
+		},
+		Logger: log.New("test"),
+	}
+
+	s.serveAssetHandler(c)
+	return resp
+}
@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -30,7 +31,8 @@ import (
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner,
+	leaderElection leaderelection.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -44,6 +46,7 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		tempUserService:           tempUserService,
 		tracer:                    tracer,
 		annotationCleaner:         annotationCleaner,
+		leaderElection:            leaderElection,
 	}
 	return s
 }
@@ -61,11 +64,15 @@ type CleanUpService struct {
 	deleteExpiredImageService *image.DeleteExpiredService
 	tempUserService           tempuser.Service
 	annotationCleaner         annotations.Cleaner
+	leaderElection            leaderelection.Service
 }
 
 type cleanUpJob struct {
 	name string
 	fn   func(context.Context)
+	// perInstance jobs clean up the local disk and run on every instance, the other jobs only run
+	// on the instance elected as leader.
+	perInstance bool
 }
 
 func (j cleanUpJob) String() string {
@@ -95,24 +102,32 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 	defer cancelFn()
 
 	cleanupJobs := []cleanUpJob{
-		{"clean up temporary files", srv.cleanUpTmpFiles},
-		{"delete expired snapshots", srv.deleteExpiredSnapshots},
-		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
-		{"delete expired images", srv.deleteExpiredImages},
-		{"cleanup old annotations", srv.cleanUpOldAnnotations},
-		{"expire old user invites", srv.expireOldUserInvites},
-		{"delete stale short URLs", srv.deleteStaleShortURLs},
-		{"delete stale query history", srv.deleteStaleQueryHistory},
+		{"clean up temporary files", srv.cleanUpTmpFiles, true},
+		{"delete expired snapshots", srv.deleteExpiredSnapshots, false},
+		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions, false},
+		{"delete expired images", srv.deleteExpiredImages, false},
+		{"cleanup old annotations", srv.cleanUpOldAnnotations, false},
+		{"expire old user invites", srv.expireOldUserInvites, false},
+		{"delete stale short URLs", srv.deleteStaleShortURLs, false},
+		{"delete stale query history", srv.deleteStaleQueryHistory, false},
 	}
 
 	logger := srv.log.FromContext(ctx)
 	logger.Debug("Starting cleanup jobs", "jobs", fmt.Sprintf("%v", cleanupJobs))
 
+	leader := srv.leaderElection.IsLeader(ctx, leaderelection.LeaseCleanup)
+	if !leader {
+		logger.Debug("Not leader, only running the cleanup jobs of this instance")
+	}
+
 	for _, j := range cleanupJobs {
 		if ctx.Err() != nil {
 			logger.Error("Cancelled cleanup job", "error", ctx.Err(), "duration", time.Since(start))
 			return
 		}
+		if !leader && !j.perInstance {
+			continue
+		}
 		ctx, span := srv.tracer.Start(ctx, j.name)
 		j.fn(ctx)
 		span.End()
@@ -252,9 +267,8 @@ func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 
 func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
-	// Delete query history from 14+ days ago with exception of starred queries
-	maxQueryHistoryLifetime := time.Hour * 24 * 14
-	olderThan := time.Now().Add(-maxQueryHistoryLifetime).Unix()
+	// Delete query history older than the retention with exception of starred queries
+	olderThan := time.Now().Add(-srv.Cfg.QueryHistoryRetention).Unix()
 	rowsCount, err := srv.QueryHistoryService.DeleteStaleQueriesInQueryHistory(ctx, olderThan)
 	if err != nil {
 		logger.Error("Problem deleting stale query history", "error", err.Error())
@@ -262,18 +276,16 @@ func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 		logger.Debug("Deleted stale query history", "rows affected", rowsCount)
 	}
 
-	// Enforce 200k limit for query_history table
-	queryHistoryLimit := 200000
-	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, queryHistoryLimit, false)
+	// Enforce row limit for query_history table
+	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, srv.Cfg.QueryHistoryMaxRows, false)
 	if err != nil {
 		logger.Error("Problem with enforcing row limit for query_history", "error", err.Error())
 	} else {
 		logger.Debug("Enforced row limit for query_history", "rows affected", rowsCount)
 	}
 
-	// Enforce 150k limit for query_history_star table
-	queryHistoryStarLimit := 150000
-	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, queryHistoryStarLimit, true)
+	// Enforce row limit for query_history_star table
+	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, srv.Cfg.QueryHistoryMaxStarredRows, true)
 	if err != nil {
 		logger.Error("Problem with enforcing row limit for query_history_star", "error", err.Error())
 	} else {
@@ -0,0 +1,168 @@
+// Package configvalidation checks the configuration and the provisioning files of a Grafana server
+// before it boots, so that mistakes are reported together with a hint how to fix them instead of
+// surfacing as a failed or misbehaving start.
+package configvalidation
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in the configuration or a provisioning file.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	// Section and Key locate issues of grafana.ini, File locates issues of provisioning files.
+	Section string `json:"section,omitempty"`
+	Key     string `json:"key,omitempty"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+	// Hint describes how to fix the issue.
+	Hint string `json:"hint,omitempty"`
+}
+
+func (i Issue) String() string {
+	location := i.File
+	if location == "" {
+		location = "[" + i.Section + "]"
+		if i.Key != "" {
+			location += " " + i.Key
+		}
+	}
+	s := fmt.Sprintf("%s: %s: %s", i.Severity, location, i.Message)
+	if i.Hint != "" {
+		s += " (" + i.Hint + ")"
+	}
+	return s
+}
+
+type Report struct {
+	Issues []Issue `json:"issues"`
+}
+
+func (r *Report) add(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+// HasErrors returns true if an issue is an error, or with strict set any issue was found.
+func (r *Report) HasErrors(strict bool) bool {
+	for _, issue := range r.Issues {
+		if strict || issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the loaded configuration against the schema given by conf/defaults.ini, the
+// consistency of related settings and the provisioning files.
+func Validate(cfg *setting.Cfg) *Report {
+	report := &Report{Issues: make([]Issue, 0)}
+
+	defaultsPath := filepath.Join(cfg.HomePath, "conf", "defaults.ini")
+	defaults, err := ini.Load(defaultsPath)
+	if err != nil {
+		report.add(Issue{Severity: SeverityWarning, File: defaultsPath, Message: "Failed to load the defaults, the settings are not checked against them: " + err.Error()})
+	} else {
+		raw, err := cfg.ReadConfigFiles()
+		if err != nil {
+			report.add(Issue{Severity: SeverityError, File: defaultsPath, Message: "Failed to read the configuration: " + err.Error()})
+		} else {
+			validateSchema(defaults, raw, report)
+		}
+	}
+	validateCrossFields(cfg, report)
+	validateProvisioning(cfg.ProvisioningPath, report)
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Severity == SeverityError && report.Issues[j].Severity != SeverityError
+	})
+	return report
+}
+
+// dynamicSections are sections whose keys are not declared in the defaults, such as the settings
+// of single plugins.
+var dynamicSections = []string{"plugin.", "feature_toggles", "auth.generic_oauth", "date_formats", "enterprise"}
+
+func isDynamicSection(name string) bool {
+	for _, prefix := range dynamicSections {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSchema reports unknown sections and keys, and values which do not have the type of the
+// default value of their key.
+func validateSchema(defaults *ini.File, raw *ini.File, report *Report) {
+	for _, section := range raw.Sections() {
+		name := section.Name()
+		if name == ini.DefaultSection || isDynamicSection(name) {
+			continue
+		}
+		defaultSection, err := defaults.GetSection(name)
+		if err != nil {
+			report.add(Issue{Severity: SeverityWarning, Section: name, Message: "Unknown section", Hint: "check the spelling of the section name"})
+			continue
+		}
+
+		for _, key := range section.Keys() {
+			if !defaultSection.HasKey(key.Name()) {
+				report.add(Issue{Severity: SeverityWarning, Section: name, Key: key.Name(), Message: "Unknown setting", Hint: "check the spelling of the setting name"})
+				continue
+			}
+			validateType(defaultSection.Key(key.Name()).String(), key, name, report)
+		}
+	}
+}
+
+func validateType(defaultValue string, key *ini.Key, section string, report *Report) {
+	value := key.String()
+	if value == "" || defaultValue == "" || value == defaultValue {
+		return
+	}
+
+	switch {
+	case defaultValue == "true" || defaultValue == "false":
+		if _, err := strconv.ParseBool(value); err != nil {
+			report.add(Issue{Severity: SeverityError, Section: section, Key: key.Name(), Message: fmt.Sprintf("%q is not a boolean", value), Hint: "use true or false"})
+		}
+	case isInt(defaultValue):
+		// Some numeric settings also accept fractions or durations.
+		if _, err := strconv.ParseFloat(value, 64); err != nil && !isDuration(value) {
+			report.add(Issue{Severity: SeverityError, Section: section, Key: key.Name(), Message: fmt.Sprintf("%q is not a number", value)})
+		}
+	case isDuration(defaultValue):
+		if !isDuration(value) && !isInt(value) {
+			report.add(Issue{Severity: SeverityError, Section: section, Key: key.Name(), Message: fmt.Sprintf("%q is not a duration", value), Hint: "use a duration such as 30s, 5m or 1d"})
+		}
+	}
+}
+
+func isInt(value string) bool {
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
+
+func isDuration(value string) bool {
+	if isInt(value) {
+		return false
+	}
+	_, err := gtime.ParseDuration(value)
+	return err == nil
+}
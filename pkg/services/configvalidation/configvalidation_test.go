@@ -0,0 +1,134 @@
+package configvalidation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func issuesFor(report *Report, section, key string) []Issue {
+	var issues []Issue
+	for _, issue := range report.Issues {
+		if issue.Section == section && issue.Key == key {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func TestValidateSchema(t *testing.T) {
+	defaults, err := ini.Load([]byte(`
+[server]
+http_port = 3000
+enforce_domain = false
+read_timeout = 0
+
+[query_capture]
+max_age = 24h
+`))
+	require.NoError(t, err)
+	raw, err := ini.Load([]byte(`
+[server]
+http_port = abc
+enforce_domain = yes
+enforce_domian = true
+read_timeout = 30s
+
+[query_capture]
+max_age = 1d
+
+[sever]
+http_port = 3000
+
+[plugin.grafana-image-renderer]
+rendering_timeout = 20s
+`))
+	require.NoError(t, err)
+
+	report := &Report{}
+	validateSchema(defaults, raw, report)
+
+	require.Len(t, issuesFor(report, "server", "http_port"), 1)
+	assert.Equal(t, SeverityError, issuesFor(report, "server", "http_port")[0].Severity)
+	// yes is not accepted by strconv.ParseBool
+	require.Len(t, issuesFor(report, "server", "enforce_domain"), 1)
+	require.Len(t, issuesFor(report, "server", "enforce_domian"), 1)
+	assert.Equal(t, SeverityWarning, issuesFor(report, "server", "enforce_domian")[0].Severity)
+	assert.Empty(t, issuesFor(report, "server", "read_timeout"))
+	assert.Empty(t, issuesFor(report, "query_capture", "max_age"))
+	require.Len(t, issuesFor(report, "sever", ""), 1)
+	assert.Len(t, report.Issues, 4)
+	assert.True(t, report.HasErrors(false))
+}
+
+func TestValidateCrossFields(t *testing.T) {
+	cfg := setting.NewCfg()
+	_, err := cfg.Raw.Section("leader_election").NewKey("enabled", "true")
+	require.NoError(t, err)
+	cfg.Protocol = setting.HTTPSScheme
+	cfg.CertFile = filepath.Join(t.TempDir(), "missing.crt")
+	cfg.Smtp.Enabled = true
+
+	report := &Report{}
+	validateCrossFields(cfg, report)
+
+	assert.Len(t, issuesFor(report, "database", "type"), 1)
+	assert.Len(t, issuesFor(report, "live", "ha_engine"), 1)
+	assert.Len(t, issuesFor(report, "server", "cert_file"), 1)
+	assert.Len(t, issuesFor(report, "server", "cert_key"), 1)
+	assert.Len(t, issuesFor(report, "smtp", "host"), 1)
+}
+
+func TestValidateProvisioning(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0600))
+	}
+	write("datasources/a.yaml", `
+apiVersion: 1
+datasources:
+  - name: Prometheus
+    type: prometheus
+    isDefault: true
+  - name: Loki
+`)
+	write("datasources/b.yaml", `
+apiVersion: 1
+datasources:
+  - name: Graphite
+    type: graphite
+    isDefault: true
+`)
+	write("dashboards/a.yaml", `
+apiVersion: 1
+providers:
+  - name: default
+    options:
+      path: /does/not/exist
+  - name: env
+    options:
+      path: $DASHBOARDS
+`)
+	write("alerting/a.yaml", "groups: [")
+	write("plugins/README.md", "not yaml: [")
+
+	report := &Report{}
+	validateProvisioning(dir, report)
+
+	messages := make([]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Message)
+	}
+	require.Len(t, report.Issues, 4, messages)
+	assert.Contains(t, report.Issues[0].Message, "Invalid YAML")
+	assert.Equal(t, `Path /does/not/exist of dashboard provider "default" cannot be read: stat /does/not/exist: no such file or directory`, report.Issues[1].Message)
+	assert.Equal(t, `Data source "Loki" has no type`, report.Issues[2].Message)
+	assert.Equal(t, `Data sources "Prometheus" and "Graphite" are both the default of organization 1`, report.Issues[3].Message)
+}
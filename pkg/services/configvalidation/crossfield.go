@@ -0,0 +1,81 @@
+package configvalidation
+
+import (
+	"os"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// defaultSecretKey is the secret_key of conf/defaults.ini.
+const defaultSecretKey = "SW2YcwTIb9zpOOhoPsMm"
+
+// validateCrossFields checks settings which are only valid together with other settings.
+func validateCrossFields(cfg *setting.Cfg, report *Report) {
+	dbType := cfg.Raw.Section("database").Key("type").MustString("sqlite3")
+	remoteCacheType := cfg.Raw.Section("remote_cache").Key("type").MustString("database")
+	leaderElection := cfg.Raw.Section("leader_election").Key("enabled").MustBool(false)
+	haPeers := cfg.UnifiedAlerting.HAPeers
+	ha := leaderElection || len(haPeers) > 0
+
+	if ha && dbType == "sqlite3" {
+		report.add(Issue{
+			Severity: SeverityError, Section: "database", Key: "type",
+			Message: "Grafana is configured for high availability but uses a SQLite database, which cannot be shared between instances",
+			Hint:    "use a MySQL or PostgreSQL database shared by all instances",
+		})
+	}
+	if (remoteCacheType == "redis" || remoteCacheType == "memcached") && cfg.Raw.Section("remote_cache").Key("connstr").String() == "" {
+		report.add(Issue{
+			Severity: SeverityError, Section: "remote_cache", Key: "connstr",
+			Message: "No connection string is set for the " + remoteCacheType + " remote cache",
+		})
+	}
+	if ha && cfg.LiveHAEngine == "" {
+		report.add(Issue{
+			Severity: SeverityWarning, Section: "live", Key: "ha_engine",
+			Message: "Grafana is configured for high availability but Grafana Live messages are not shared between instances",
+			Hint:    "set ha_engine to redis or nats",
+		})
+	}
+	if len(haPeers) == 1 {
+		report.add(Issue{
+			Severity: SeverityWarning, Section: "unified_alerting", Key: "ha_peers",
+			Message: "Only one alerting HA peer is configured",
+			Hint:    "list all instances of the cluster, including this one",
+		})
+	}
+
+	if cfg.Protocol == setting.HTTPSScheme || cfg.Protocol == setting.HTTP2Scheme {
+		validateFileSetting(report, "server", "cert_file", cfg.CertFile, "the certificate is required for protocol "+string(cfg.Protocol))
+		validateFileSetting(report, "server", "cert_key", cfg.KeyFile, "the key is required for protocol "+string(cfg.Protocol))
+	}
+	if cfg.Protocol == setting.SocketScheme && cfg.SocketPath == "" {
+		report.add(Issue{Severity: SeverityError, Section: "server", Key: "socket", Message: "No socket path is set for protocol socket"})
+	}
+
+	if cfg.AuthProxyEnabled && cfg.AuthProxyHeaderName == "" {
+		report.add(Issue{Severity: SeverityError, Section: "auth.proxy", Key: "header_name", Message: "The auth proxy is enabled without a header name"})
+	}
+
+	if cfg.Smtp.Enabled && cfg.Smtp.Host == "" {
+		report.add(Issue{Severity: SeverityError, Section: "smtp", Key: "host", Message: "SMTP is enabled without a host"})
+	}
+
+	if cfg.Raw.Section("security").Key("secret_key").String() == defaultSecretKey {
+		report.add(Issue{
+			Severity: SeverityWarning, Section: "security", Key: "secret_key",
+			Message: "The default secret key is used to encrypt secrets",
+			Hint:    "set a random secret key before storing secrets, it cannot be changed later without re-encrypting them",
+		})
+	}
+}
+
+func validateFileSetting(report *Report, section, key, path, reason string) {
+	if path == "" {
+		report.add(Issue{Severity: SeverityError, Section: section, Key: key, Message: "Not set, " + reason})
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		report.add(Issue{Severity: SeverityError, Section: section, Key: key, Message: "Cannot read " + path + ": " + err.Error()})
+	}
+}
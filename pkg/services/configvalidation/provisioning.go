@@ -0,0 +1,133 @@
+package configvalidation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// provisioningDirs are the directories of the provisioning path read by the provisioners.
+var provisioningDirs = []string{"access-control", "alerting", "dashboards", "datasources", "notifiers", "plugins"}
+
+type datasourcesFile struct {
+	Datasources []struct {
+		Name      string      `yaml:"name"`
+		Type      string      `yaml:"type"`
+		UID       string      `yaml:"uid"`
+		OrgID     interface{} `yaml:"orgId"`
+		IsDefault interface{} `yaml:"isDefault"`
+	} `yaml:"datasources"`
+}
+
+type dashboardsFile struct {
+	Providers []struct {
+		Name    string                 `yaml:"name"`
+		Options map[string]interface{} `yaml:"options"`
+	} `yaml:"providers"`
+}
+
+// validateProvisioning checks that the provisioning files are valid YAML, and the required fields
+// of data sources and dashboard providers.
+func validateProvisioning(provisioningPath string, report *Report) {
+	if provisioningPath == "" {
+		return
+	}
+
+	defaultDatasources := map[string]string{}
+	for _, dir := range provisioningDirs {
+		files, err := os.ReadDir(filepath.Join(provisioningPath, dir))
+		if err != nil {
+			// Missing directories are fine, there is nothing to provision.
+			continue
+		}
+		for _, file := range files {
+			name := file.Name()
+			if file.IsDir() || !(strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")) {
+				continue
+			}
+			path := filepath.Join(provisioningPath, dir, name)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				report.add(Issue{Severity: SeverityError, File: path, Message: "Cannot read file: " + err.Error()})
+				continue
+			}
+
+			var generic map[string]interface{}
+			if err := yaml.Unmarshal(content, &generic); err != nil {
+				report.add(Issue{Severity: SeverityError, File: path, Message: "Invalid YAML: " + err.Error()})
+				continue
+			}
+
+			switch dir {
+			case "datasources":
+				validateDatasources(path, content, defaultDatasources, report)
+			case "dashboards":
+				validateDashboardProviders(path, content, report)
+			}
+		}
+	}
+}
+
+func validateDatasources(path string, content []byte, defaults map[string]string, report *Report) {
+	var file datasourcesFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		report.add(Issue{Severity: SeverityError, File: path, Message: "Invalid data sources: " + err.Error()})
+		return
+	}
+
+	for i, ds := range file.Datasources {
+		if ds.Name == "" {
+			report.add(Issue{Severity: SeverityError, File: path, Message: fmt.Sprintf("Data source %d has no name", i+1)})
+		}
+		if ds.Type == "" {
+			report.add(Issue{Severity: SeverityError, File: path, Message: fmt.Sprintf("Data source %q has no type", ds.Name)})
+		}
+		if isDefault, _ := ds.IsDefault.(bool); isDefault {
+			org := fmt.Sprint(ds.OrgID)
+			if ds.OrgID == nil {
+				org = "1"
+			}
+			if other, ok := defaults[org]; ok {
+				report.add(Issue{
+					Severity: SeverityError, File: path,
+					Message: fmt.Sprintf("Data sources %q and %q are both the default of organization %s", other, ds.Name, org),
+					Hint:    "only one data source per organization can have isDefault set",
+				})
+			} else {
+				defaults[org] = ds.Name
+			}
+		}
+	}
+}
+
+func validateDashboardProviders(path string, content []byte, report *Report) {
+	var file dashboardsFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		report.add(Issue{Severity: SeverityError, File: path, Message: "Invalid dashboard providers: " + err.Error()})
+		return
+	}
+
+	for i, provider := range file.Providers {
+		if provider.Name == "" {
+			report.add(Issue{Severity: SeverityError, File: path, Message: fmt.Sprintf("Dashboard provider %d has no name", i+1)})
+		}
+		dashboardsPath, _ := provider.Options["path"].(string)
+		if dashboardsPath == "" {
+			report.add(Issue{Severity: SeverityError, File: path, Message: fmt.Sprintf("Dashboard provider %q has no path", provider.Name)})
+			continue
+		}
+		// Paths referring to environment variables are only known when provisioning.
+		if strings.Contains(dashboardsPath, "$") {
+			continue
+		}
+		if _, err := os.Stat(dashboardsPath); err != nil {
+			report.add(Issue{
+				Severity: SeverityWarning, File: path,
+				Message: fmt.Sprintf("Path %s of dashboard provider %q cannot be read: %s", dashboardsPath, provider.Name, err),
+			})
+		}
+	}
+}
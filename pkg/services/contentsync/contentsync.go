@@ -0,0 +1,249 @@
+// Package contentsync replicates the dashboards, folders and data sources of a primary instance to
+// replica instances, e.g. for disaster recovery or read-only edge deployments.
+//
+// The primary records the changes of its content in a change log shared by the instances of its
+// database, and serves them with their current content over the sync API, authenticated with a
+// shared token. The replicas poll the sync API and apply the changes. A replica which has no cursor
+// yet, or which is behind by more than the retained changes, replicates the whole content instead.
+// The secrets of the data sources are never replicated, they are set on the replicas.
+package contentsync
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// TokenHeader is the header authenticating the replicas to the sync API of the primary.
+const TokenHeader = "X-Grafana-Content-Sync-Token"
+
+type ChangeKind string
+
+const (
+	// KindDashboard is the kind of the changes of dashboards and folders, which share their UIDs.
+	KindDashboard  ChangeKind = "dashboard"
+	KindDataSource ChangeKind = "datasource"
+)
+
+type ChangeAction string
+
+const (
+	ActionUpsert ChangeAction = "upsert"
+	ActionDelete ChangeAction = "delete"
+)
+
+// change is a recorded change of the content of the primary.
+type change struct {
+	ID      int64        `xorm:"pk autoincr 'id'"`
+	OrgID   int64        `xorm:"org_id"`
+	Kind    ChangeKind   `xorm:"kind"`
+	UID     string       `xorm:"uid"`
+	Action  ChangeAction `xorm:"action"`
+	Created time.Time    `xorm:"'created'"`
+}
+
+func (c change) TableName() string {
+	return "content_sync_change"
+}
+
+// Change is a change of the content of the primary, together with the content for upserts.
+type Change struct {
+	// ID is the ID of the change, 0 for the content of a full sync.
+	ID         int64        `json:"id"`
+	OrgID      int64        `json:"orgId"`
+	Kind       ChangeKind   `json:"kind"`
+	UID        string       `json:"uid"`
+	Action     ChangeAction `json:"action"`
+	Dashboard  *Dashboard   `json:"dashboard,omitempty"`
+	DataSource *DataSource  `json:"datasource,omitempty"`
+}
+
+// Dashboard is a replicated dashboard or folder. The folder of a dashboard is referenced by its UID,
+// since the IDs differ between the instances.
+type Dashboard struct {
+	UID       string           `json:"uid"`
+	Title     string           `json:"title"`
+	IsFolder  bool             `json:"isFolder"`
+	FolderUID string           `json:"folderUid,omitempty"`
+	Version   int              `json:"version"`
+	Data      *simplejson.Json `json:"data"`
+}
+
+// DataSource is a replicated data source, without its secrets.
+type DataSource struct {
+	UID             string               `json:"uid"`
+	Name            string               `json:"name"`
+	Type            string               `json:"type"`
+	Access          datasources.DsAccess `json:"access"`
+	URL             string               `json:"url"`
+	User            string               `json:"user"`
+	Database        string               `json:"database"`
+	BasicAuth       bool                 `json:"basicAuth"`
+	BasicAuthUser   string               `json:"basicAuthUser"`
+	WithCredentials bool                 `json:"withCredentials"`
+	IsDefault       bool                 `json:"isDefault"`
+	JSONData        *simplejson.Json     `json:"jsonData"`
+}
+
+// ChangesResponse is the response of the sync API.
+// swagger:model
+type ChangesResponse struct {
+	// Cursor is the ID of the last change included, to get the next changes after.
+	Cursor int64 `json:"cursor"`
+	// Full is set when the changes are the whole content, and the content missing from them must be
+	// removed.
+	Full bool `json:"full"`
+	// More is set when there are more changes after the cursor.
+	More    bool     `json:"more"`
+	Changes []Change `json:"changes"`
+}
+
+// Status is the replication status of a replica.
+// swagger:model ContentSyncStatus
+type Status struct {
+	Mode         string     `json:"mode"`
+	PrimaryURL   string     `json:"primaryUrl,omitempty"`
+	Cursor       int64      `json:"cursor"`
+	LastSync     *time.Time `json:"lastSync,omitempty"`
+	LastFullSync *time.Time `json:"lastFullSync,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	// Applied and Failed count the changes applied and failed by this instance since it started.
+	Applied int64 `json:"applied"`
+	Failed  int64 `json:"failed"`
+}
+
+type ContentSyncService struct {
+	settings            setting.ContentSyncSettings
+	store               db.DB
+	routeRegister       routing.RouteRegister
+	dashboardService    dashboards.DashboardService
+	provisioningService dashboards.DashboardProvisioningService
+	dataSourceService   datasources.DataSourceService
+	orgService          org.Service
+	serverLock          *serverlock.ServerLockService
+	state               *kvstore.NamespacedKVStore
+	client              *http.Client
+	log                 log.Logger
+	now                 func() time.Time
+
+	statusMu sync.Mutex
+	status   Status
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, bus bus.Bus, routeRegister routing.RouteRegister,
+	dashboardService dashboards.DashboardService, provisioningService dashboards.DashboardProvisioningService,
+	dataSourceService datasources.DataSourceService, orgService org.Service, serverLock *serverlock.ServerLockService,
+	kv kvstore.KVStore) *ContentSyncService {
+	s := &ContentSyncService{
+		settings:            cfg.ContentSync,
+		store:               sqlStore,
+		routeRegister:       routeRegister,
+		dashboardService:    dashboardService,
+		provisioningService: provisioningService,
+		dataSourceService:   dataSourceService,
+		orgService:          orgService,
+		serverLock:          serverLock,
+		state:               kvstore.WithNamespace(kv, 0, "content-sync"),
+		client:              &http.Client{Timeout: time.Minute},
+		log:                 log.New("content-sync"),
+		now:                 time.Now,
+		status:              Status{Mode: cfg.ContentSync.Mode, PrimaryURL: cfg.ContentSync.PrimaryURL},
+	}
+
+	switch s.settings.Mode {
+	case setting.ContentSyncModePrimary:
+		s.addEventListeners(bus)
+		s.registerPrimaryAPIEndpoints()
+	case setting.ContentSyncModeReplica:
+		s.registerReplicaAPIEndpoints()
+	}
+
+	return s
+}
+
+func (s *ContentSyncService) IsDisabled() bool {
+	return s.settings.Mode == ""
+}
+
+// Run removes the expired changes on a primary, and replicates the changes of the primary on a
+// replica. Only one of the instances of a replica sharing a database replicates them at a time.
+func (s *ContentSyncService) Run(ctx context.Context) error {
+	if s.settings.Mode == setting.ContentSyncModePrimary {
+		return s.runPrimary(ctx)
+	}
+	return s.runReplica(ctx)
+}
+
+func (s *ContentSyncService) runPrimary(ctx context.Context) error {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.deleteChangesBefore(ctx, s.now().Add(-s.settings.ChangeRetention)); err != nil {
+				s.log.Error("Failed to delete the expired content changes", "error", err)
+			}
+		}
+	}
+}
+
+func (s *ContentSyncService) runReplica(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// the lock interval is shorter than the poll interval, so that a tick isn't skipped because
+		// the previous one ran slightly later
+		err := s.serverLock.LockAndExecute(ctx, "content sync", s.settings.PollInterval/2, func(ctx context.Context) {
+			if err := s.sync(ctx); err != nil {
+				s.log.Error("Failed to replicate the content of the primary", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to acquire the content sync lock", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetStatus returns the replication status of a replica.
+func (s *ContentSyncService) GetStatus(ctx context.Context) (Status, error) {
+	s.statusMu.Lock()
+	status := s.status
+	s.statusMu.Unlock()
+
+	// the other instances of the replica may have replicated the content since
+	state, err := s.loadState(ctx)
+	if err != nil {
+		return status, err
+	}
+	status.Cursor = state.cursor
+	if !state.lastSync.IsZero() {
+		status.LastSync = &state.lastSync
+	}
+	if !state.lastFullSync.IsZero() {
+		status.LastFullSync = &state.lastFullSync
+	}
+	return status, nil
+}
@@ -0,0 +1,223 @@
+package contentsync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIsReplicatedContentWrite(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		write  bool
+	}{
+		{http.MethodGet, "/api/dashboards/uid/abc", false},
+		{http.MethodPost, "/api/dashboards/db", true},
+		{http.MethodPost, "/api/dashboards/import", true},
+		{http.MethodDelete, "/api/dashboards/uid/abc", true},
+		{http.MethodPost, "/api/dashboards/uid/abc/restore", true},
+		{http.MethodPost, "/api/dashboards/uid/abc/permissions", false},
+		{http.MethodPost, "/api/dashboards/calculate-diff", false},
+		{http.MethodPost, "/api/folders", true},
+		{http.MethodPut, "/api/folders/abc", true},
+		{http.MethodPost, "/api/folders/abc/permissions/", false},
+		{http.MethodPost, "/api/datasources", true},
+		{http.MethodPut, "/api/datasources/uid/abc", false},
+		{http.MethodDelete, "/api/datasources/uid/abc", true},
+		{http.MethodDelete, "/api/datasources/uid/abc/credentials", false},
+		{http.MethodPost, "/api/datasources/uid/abc/resources/query", false},
+		{http.MethodPost, "/api/ds/query", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.write, isReplicatedContentWrite(tt.method, tt.path), "%s %s", tt.method, tt.path)
+	}
+}
+
+func TestLatestChanges(t *testing.T) {
+	recorded := []*change{
+		{ID: 1, OrgID: 1, Kind: KindDashboard, UID: "a", Action: ActionUpsert},
+		{ID: 2, OrgID: 1, Kind: KindDataSource, UID: "a", Action: ActionUpsert},
+		{ID: 3, OrgID: 1, Kind: KindDashboard, UID: "a", Action: ActionDelete},
+		{ID: 4, OrgID: 2, Kind: KindDashboard, UID: "a", Action: ActionUpsert},
+	}
+	kept := latestChanges(recorded)
+	require.Len(t, kept, 3)
+	assert.Equal(t, []int64{2, 3, 4}, []int64{kept[0].ID, kept[1].ID, kept[2].ID})
+}
+
+func TestIntegrationContentSync(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	folder := &models.Dashboard{OrgId: 1, Uid: "infra", Title: "Infra", Slug: "infra", IsFolder: true, Data: simplejson.NewFromAny(map[string]interface{}{"title": "Infra"}), Created: time.Now(), Updated: time.Now()}
+	dash := &models.Dashboard{OrgId: 1, Uid: "nodes", Title: "Nodes", Slug: "nodes", Version: 3, Data: simplejson.NewFromAny(map[string]interface{}{"title": "Nodes", "id": 2}), Created: time.Now(), Updated: time.Now()}
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(folder); err != nil {
+			return err
+		}
+		dash.FolderId = folder.Id
+		_, err := sess.Insert(dash)
+		return err
+	})
+	require.NoError(t, err)
+
+	primaryDataSources := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{Id: 1, OrgId: 1, Uid: "prom", Name: "Prometheus", Type: "prometheus", Url: "http://prom:9090", SecureJsonData: map[string][]byte{"password": []byte("secret")}},
+	}}
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	primary := &ContentSyncService{
+		settings:          setting.ContentSyncSettings{Mode: setting.ContentSyncModePrimary, Token: "token"},
+		store:             sqlStore,
+		dataSourceService: primaryDataSources,
+		log:               log.New("content-sync"),
+		now:               func() time.Time { return now },
+	}
+
+	t.Run("returns the whole content without a cursor", func(t *testing.T) {
+		changes, err := primary.GetChanges(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.True(t, changes.Full)
+		require.Len(t, changes.Changes, 3)
+		assert.Equal(t, "infra", changes.Changes[0].UID)
+		assert.True(t, changes.Changes[0].Dashboard.IsFolder)
+		assert.Equal(t, "nodes", changes.Changes[1].UID)
+		assert.Equal(t, "infra", changes.Changes[1].Dashboard.FolderUID)
+		assert.Equal(t, 3, changes.Changes[1].Dashboard.Version)
+		assert.Equal(t, "http://prom:9090", changes.Changes[2].DataSource.URL)
+
+		encoded, err := json.Marshal(changes)
+		require.NoError(t, err)
+		assert.NotContains(t, string(encoded), "secret")
+	})
+
+	require.NoError(t, primary.recordChange(ctx, 1, KindDashboard, "nodes", ActionUpsert))
+	first, err := primary.GetChanges(ctx, 0, 10)
+	require.NoError(t, err)
+	require.NoError(t, primary.recordChange(ctx, 1, KindDashboard, "nodes", ActionUpsert))
+	require.NoError(t, primary.recordChange(ctx, 1, KindDataSource, "prom", ActionUpsert))
+	require.NoError(t, primary.recordChange(ctx, 1, KindDashboard, "nodes", ActionUpsert))
+	require.NoError(t, primary.recordChange(ctx, 1, KindDashboard, "gone", ActionUpsert))
+	require.NoError(t, primary.recordChange(ctx, 1, KindDataSource, "loki", ActionDelete))
+
+	t.Run("returns the latest change of each content after the cursor", func(t *testing.T) {
+		changes, err := primary.GetChanges(ctx, first.Cursor, 10)
+		require.NoError(t, err)
+		assert.False(t, changes.Full)
+		assert.False(t, changes.More)
+		assert.Equal(t, first.Cursor+5, changes.Cursor)
+		require.Len(t, changes.Changes, 4)
+		assert.Equal(t, "prom", changes.Changes[0].UID)
+		assert.Equal(t, "nodes", changes.Changes[1].UID)
+		assert.NotNil(t, changes.Changes[1].Dashboard)
+		assert.Equal(t, "gone", changes.Changes[2].UID)
+		assert.Equal(t, ActionDelete, changes.Changes[2].Action)
+		assert.Equal(t, ActionDelete, changes.Changes[3].Action)
+
+		changes, err = primary.GetChanges(ctx, first.Cursor, 2)
+		require.NoError(t, err)
+		assert.True(t, changes.More)
+		assert.Equal(t, first.Cursor+2, changes.Cursor)
+	})
+
+	t.Run("replicates the whole content once the changes are deleted", func(t *testing.T) {
+		require.NoError(t, primary.deleteChangesBefore(ctx, now.Add(time.Minute)))
+		oldest, latest, err := primary.getChangeRange(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, oldest, latest)
+
+		changes, err := primary.GetChanges(ctx, first.Cursor, 10)
+		require.NoError(t, err)
+		assert.True(t, changes.Full)
+		changes, err = primary.GetChanges(ctx, latest, 10)
+		require.NoError(t, err)
+		assert.False(t, changes.Full)
+		assert.Empty(t, changes.Changes)
+	})
+
+	t.Run("replica applies the data source changes of the primary", func(t *testing.T) {
+		// the replica shares the database of the test, it must not have the dashboards of the primary
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("DELETE FROM dashboard")
+			return err
+		})
+		require.NoError(t, err)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(TokenHeader) != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+			changes, err := primary.GetChanges(r.Context(), after, defaultChangesLimit)
+			require.NoError(t, err)
+			// the dashboards are applied by the dashboard service, which isn't part of this test
+			dataSourceChanges := make([]Change, 0)
+			for _, ch := range changes.Changes {
+				if ch.Kind == KindDataSource {
+					dataSourceChanges = append(dataSourceChanges, ch)
+				}
+			}
+			changes.Changes = dataSourceChanges
+			require.NoError(t, json.NewEncoder(w).Encode(changes))
+		}))
+		defer server.Close()
+
+		replicaDataSources := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{Id: 1, OrgId: 1, Uid: "local", Name: "Local", Type: "loki"},
+		}}
+		replica := &ContentSyncService{
+			settings: setting.ContentSyncSettings{
+				Mode:             setting.ContentSyncModeReplica,
+				Token:            "token",
+				PrimaryURL:       server.URL,
+				FullSyncInterval: time.Hour,
+			},
+			store:             sqlStore,
+			dataSourceService: replicaDataSources,
+			orgService:        &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 1}},
+			state:             kvstore.WithNamespace(kvstore.ProvideService(sqlStore), 0, "content-sync-test"),
+			client:            server.Client(),
+			log:               log.New("content-sync"),
+			now:               func() time.Time { return now },
+		}
+
+		require.NoError(t, replica.sync(ctx))
+		require.Len(t, replicaDataSources.DataSources, 1)
+		assert.Equal(t, "prom", replicaDataSources.DataSources[0].Uid)
+
+		status, err := replica.GetStatus(ctx)
+		require.NoError(t, err)
+		assert.NotZero(t, status.Cursor)
+		assert.Equal(t, now, *status.LastFullSync)
+		assert.Equal(t, int64(2), status.Applied)
+
+		replica.settings.Token = "invalid"
+		now = now.Add(2 * time.Hour)
+		assert.Error(t, replica.sync(ctx))
+		status, err = replica.GetStatus(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, status.LastError, "401")
+	})
+}
@@ -0,0 +1,232 @@
+package contentsync
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const (
+	defaultChangesLimit = 500
+	maxChangesLimit     = 5000
+)
+
+func (s *ContentSyncService) addEventListeners(bus bus.Bus) {
+	bus.AddEventListener(func(ctx context.Context, e *events.DashboardSaved) error {
+		return s.recordChange(ctx, e.OrgID, KindDashboard, e.UID, ActionUpsert)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DashboardDeleted) error {
+		return s.recordChange(ctx, e.OrgID, KindDashboard, e.UID, ActionDelete)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.FolderSaved) error {
+		return s.recordChange(ctx, e.OrgID, KindDashboard, e.UID, ActionUpsert)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.FolderDeleted) error {
+		return s.recordChange(ctx, e.OrgID, KindDashboard, e.UID, ActionDelete)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceCreated) error {
+		return s.recordChange(ctx, e.OrgID, KindDataSource, e.UID, ActionUpsert)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceUpdated) error {
+		return s.recordChange(ctx, e.OrgID, KindDataSource, e.UID, ActionUpsert)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceDeleted) error {
+		return s.recordChange(ctx, e.OrgID, KindDataSource, e.UID, ActionDelete)
+	})
+}
+
+// recordChange records a change in the change log. The change is already committed, so a failure is
+// only logged, and repaired by the next full sync of the replicas.
+func (s *ContentSyncService) recordChange(ctx context.Context, orgID int64, kind ChangeKind, uid string, action ChangeAction) error {
+	err := s.insertChange(ctx, &change{OrgID: orgID, Kind: kind, UID: uid, Action: action, Created: s.now()})
+	if err != nil {
+		s.log.Error("Failed to record a content change", "orgId", orgID, "kind", kind, "uid", uid, "action", action, "error", err)
+	}
+	return nil
+}
+
+func (s *ContentSyncService) registerPrimaryAPIEndpoints() {
+	s.routeRegister.Get("/api/content-sync/changes", routing.Wrap(s.getChangesHandler))
+}
+
+// swagger:route GET /content-sync/changes content_sync getContentChanges
+//
+// Get the content changes of a primary instance.
+//
+// Returns the changes of the dashboards, folders and data sources after a cursor, together with their current content. When the cursor is 0 or older than the retained changes, the whole content is returned instead. The data sources are returned without their secrets. The request is authenticated with the X-Grafana-Content-Sync-Token header instead of a user.
+//
+// Responses:
+// 200: getContentChangesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *ContentSyncService) getChangesHandler(c *models.ReqContext) response.Response {
+	token := c.Req.Header.Get(TokenHeader)
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.settings.Token)) != 1 {
+		return response.Error(http.StatusUnauthorized, "Invalid content sync token", nil)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultChangesLimit
+	}
+	if limit > maxChangesLimit {
+		limit = maxChangesLimit
+	}
+
+	changes, err := s.GetChanges(c.Req.Context(), c.QueryInt64("after"), limit)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the content changes", err)
+	}
+	return response.JSON(http.StatusOK, changes)
+}
+
+// GetChanges returns the changes after the cursor, or the whole content if the changes after the
+// cursor are not all retained.
+func (s *ContentSyncService) GetChanges(ctx context.Context, after int64, limit int) (*ChangesResponse, error) {
+	oldest, latest, err := s.getChangeRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the cursor is after the latest change if the changes of the primary were reset
+	if after <= 0 || after < oldest-1 || after > latest {
+		return s.getContent(ctx, latest)
+	}
+
+	recorded, err := s.getChangesAfter(ctx, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChangesResponse{Cursor: after, More: len(recorded) == limit, Changes: make([]Change, 0, len(recorded))}
+	for _, ch := range latestChanges(recorded) {
+		resolved, err := s.resolveChange(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes = append(result.Changes, resolved)
+	}
+	if len(recorded) > 0 {
+		result.Cursor = recorded[len(recorded)-1].ID
+	}
+	return result, nil
+}
+
+// latestChanges keeps the latest change of each dashboard and data source, in the order of these
+// latest changes.
+func latestChanges(recorded []*change) []*change {
+	type key struct {
+		orgID int64
+		kind  ChangeKind
+		uid   string
+	}
+	latest := map[key]int{}
+	for i, ch := range recorded {
+		latest[key{ch.OrgID, ch.Kind, ch.UID}] = i
+	}
+
+	kept := make([]*change, 0, len(latest))
+	for i, ch := range recorded {
+		if latest[key{ch.OrgID, ch.Kind, ch.UID}] == i {
+			kept = append(kept, ch)
+		}
+	}
+	return kept
+}
+
+// resolveChange adds the current content to an upsert. An upsert of content which has been deleted
+// since becomes a delete.
+func (s *ContentSyncService) resolveChange(ctx context.Context, ch *change) (Change, error) {
+	resolved := Change{ID: ch.ID, OrgID: ch.OrgID, Kind: ch.Kind, UID: ch.UID, Action: ch.Action}
+	if ch.Action == ActionDelete {
+		return resolved, nil
+	}
+
+	switch ch.Kind {
+	case KindDashboard:
+		dash, err := s.getDashboard(ctx, ch.OrgID, ch.UID)
+		if err != nil {
+			return resolved, err
+		}
+		resolved.Dashboard = dash
+	case KindDataSource:
+		query := &datasources.GetDataSourceQuery{OrgId: ch.OrgID, Uid: ch.UID}
+		err := s.dataSourceService.GetDataSource(ctx, query)
+		if err != nil && !errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return resolved, err
+		}
+		if err == nil {
+			resolved.DataSource = toDataSource(query.Result)
+		}
+	}
+
+	if resolved.Dashboard == nil && resolved.DataSource == nil {
+		resolved.Action = ActionDelete
+	}
+	return resolved, nil
+}
+
+// getContent returns the whole content as upserts, the folders first so that the folders of the
+// dashboards exist when they are applied.
+func (s *ContentSyncService) getContent(ctx context.Context, cursor int64) (*ChangesResponse, error) {
+	dashboards, err := s.getDashboards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := &datasources.GetAllDataSourcesQuery{}
+	if err := s.dataSourceService.GetAllDataSources(ctx, query); err != nil {
+		return nil, err
+	}
+
+	result := &ChangesResponse{Cursor: cursor, Full: true, Changes: make([]Change, 0, len(dashboards)+len(query.Result))}
+	for _, d := range dashboards {
+		result.Changes = append(result.Changes, Change{OrgID: d.orgID, Kind: KindDashboard, UID: d.UID, Action: ActionUpsert, Dashboard: d.Dashboard})
+	}
+	for _, ds := range query.Result {
+		result.Changes = append(result.Changes, Change{OrgID: ds.OrgId, Kind: KindDataSource, UID: ds.Uid, Action: ActionUpsert, DataSource: toDataSource(ds)})
+	}
+	return result, nil
+}
+
+func toDataSource(ds *datasources.DataSource) *DataSource {
+	return &DataSource{
+		UID:             ds.Uid,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		URL:             ds.Url,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JSONData:        ds.JsonData,
+	}
+}
+
+// swagger:parameters getContentChanges
+type GetContentChangesParams struct {
+	// The ID of the last change replicated, 0 for the whole content.
+	// in:query
+	// required:false
+	After int64 `json:"after"`
+	// The maximum number of changes returned, at most 5000.
+	// in:query
+	// required:false
+	// default:500
+	Limit int `json:"limit"`
+}
+
+// swagger:response getContentChangesResponse
+type GetContentChangesResponse struct {
+	// in:body
+	Body ChangesResponse `json:"body"`
+}
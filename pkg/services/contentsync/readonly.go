@@ -0,0 +1,51 @@
+package contentsync
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ReadOnlyMiddleware rejects the changes of the replicated content made on a read-only replica, since
+// they would be overwritten by the next change on the primary. Data sources can still be updated,
+// so that their secrets, which are not replicated, can be set.
+func ReadOnlyMiddleware(cfg *setting.Cfg) web.Handler {
+	return func(c *models.ReqContext) {
+		if cfg.ContentSync.Mode != setting.ContentSyncModeReplica || !cfg.ContentSync.ReadOnly {
+			return
+		}
+		if isReplicatedContentWrite(c.Req.Method, c.Req.URL.Path) {
+			c.JsonApiErr(http.StatusForbidden, "This instance is a read-only replica, its dashboards, folders and data sources are changed on the primary instance", nil)
+		}
+	}
+}
+
+func isReplicatedContentWrite(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	path = strings.TrimSuffix(path, "/")
+	switch {
+	case strings.HasPrefix(path, "/api/dashboards/"):
+		// permissions, thumbnails and public dashboards are not replicated, and the diff, validate
+		// and trim endpoints don't change anything
+		for _, suffix := range []string{"/calculate-diff", "/validate", "/trim", "/permissions"} {
+			if strings.HasSuffix(path, suffix) {
+				return false
+			}
+		}
+		return !strings.Contains(path, "/img/") && !strings.Contains(path, "/public-dashboards")
+	case path == "/api/folders" || strings.HasPrefix(path, "/api/folders/"):
+		return !strings.HasSuffix(path, "/permissions")
+	case path == "/api/datasources":
+		return method == http.MethodPost
+	case strings.HasPrefix(path, "/api/datasources/"):
+		return method == http.MethodDelete && !strings.HasSuffix(path, "/credentials")
+	}
+	return false
+}
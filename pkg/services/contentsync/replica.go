@@ -0,0 +1,388 @@
+package contentsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	cursorKey       = "cursor"
+	lastSyncKey     = "last-sync"
+	lastFullSyncKey = "last-full-sync"
+)
+
+var replicaPermissions = []accesscontrol.Permission{
+	{Action: dashboards.ActionFoldersCreate},
+	{Action: dashboards.ActionFoldersWrite, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersAll},
+	{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeFoldersAll},
+}
+
+type replicaState struct {
+	cursor       int64
+	lastSync     time.Time
+	lastFullSync time.Time
+}
+
+func (s *ContentSyncService) loadState(ctx context.Context) (replicaState, error) {
+	state := replicaState{}
+	values, err := s.state.GetAll(ctx)
+	if err != nil {
+		return state, err
+	}
+	for key, value := range values[0] {
+		switch key {
+		case cursorKey:
+			state.cursor, _ = strconv.ParseInt(value, 10, 64)
+		case lastSyncKey:
+			state.lastSync, _ = time.Parse(time.RFC3339, value)
+		case lastFullSyncKey:
+			state.lastFullSync, _ = time.Parse(time.RFC3339, value)
+		}
+	}
+	return state, nil
+}
+
+func (s *ContentSyncService) saveState(ctx context.Context, state replicaState) error {
+	if err := s.state.Set(ctx, cursorKey, strconv.FormatInt(state.cursor, 10)); err != nil {
+		return err
+	}
+	if err := s.state.Set(ctx, lastSyncKey, state.lastSync.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if state.lastFullSync.IsZero() {
+		return nil
+	}
+	return s.state.Set(ctx, lastFullSyncKey, state.lastFullSync.UTC().Format(time.RFC3339))
+}
+
+// sync applies the changes of the primary after the cursor of the replica, or the whole content of
+// the primary when the replica has no cursor yet or its last full sync is older than the full sync
+// interval.
+func (s *ContentSyncService) sync(ctx context.Context) (err error) {
+	defer func() {
+		s.statusMu.Lock()
+		s.status.LastError = ""
+		if err != nil {
+			s.status.LastError = err.Error()
+		}
+		s.statusMu.Unlock()
+	}()
+
+	state, err := s.loadState(ctx)
+	if err != nil {
+		return err
+	}
+	if s.now().Sub(state.lastFullSync) >= s.settings.FullSyncInterval {
+		state.cursor = 0
+	}
+
+	for {
+		changes, err := s.fetchChanges(ctx, state.cursor)
+		if err != nil {
+			return err
+		}
+
+		if changes.Full {
+			if err := s.applyContent(ctx, changes.Changes); err != nil {
+				return err
+			}
+			state.lastFullSync = s.now()
+		} else {
+			s.applyChanges(ctx, changes.Changes)
+		}
+
+		state.cursor = changes.Cursor
+		state.lastSync = s.now()
+		if err := s.saveState(ctx, state); err != nil {
+			return err
+		}
+		if !changes.More {
+			return nil
+		}
+	}
+}
+
+func (s *ContentSyncService) fetchChanges(ctx context.Context, after int64) (*ChangesResponse, error) {
+	u := strings.TrimSuffix(s.settings.PrimaryURL, "/") + "/api/content-sync/changes?" + url.Values{
+		"after": []string{strconv.FormatInt(after, 10)},
+		"limit": []string{strconv.Itoa(defaultChangesLimit)},
+	}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(TokenHeader, s.settings.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the changes of the primary: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close the response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to get the changes of the primary: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var changes ChangesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&changes); err != nil {
+		return nil, fmt.Errorf("failed to decode the changes of the primary: %w", err)
+	}
+	return &changes, nil
+}
+
+// applyChanges applies the changes in order. A change which fails is logged and skipped, so that it
+// doesn't block the replication, and is repaired by the next full sync.
+func (s *ContentSyncService) applyChanges(ctx context.Context, changes []Change) {
+	orgs := map[int64]bool{}
+	for _, ch := range changes {
+		exists, ok := orgs[ch.OrgID]
+		if !ok {
+			exists = s.orgExists(ctx, ch.OrgID)
+			orgs[ch.OrgID] = exists
+		}
+		if !exists {
+			s.log.Debug("Skipping the content change of an organization which doesn't exist on the replica", "orgId", ch.OrgID, "kind", ch.Kind, "uid", ch.UID)
+			continue
+		}
+
+		err := s.applyChange(ctx, ch)
+		s.statusMu.Lock()
+		if err != nil {
+			s.status.Failed++
+		} else {
+			s.status.Applied++
+		}
+		s.statusMu.Unlock()
+		if err != nil {
+			s.log.Error("Failed to apply a content change", "orgId", ch.OrgID, "kind", ch.Kind, "uid", ch.UID, "action", ch.Action, "error", err)
+		}
+	}
+}
+
+// applyContent applies the whole content of the primary, and removes the dashboards, folders and
+// data sources which don't exist on the primary.
+func (s *ContentSyncService) applyContent(ctx context.Context, content []Change) error {
+	s.applyChanges(ctx, content)
+
+	type key struct {
+		orgID int64
+		uid   string
+	}
+	replicated := map[ChangeKind]map[key]bool{KindDashboard: {}, KindDataSource: {}}
+	for _, ch := range content {
+		replicated[ch.Kind][key{ch.OrgID, ch.UID}] = true
+	}
+
+	local, err := s.getLocalDashboards(ctx)
+	if err != nil {
+		return err
+	}
+	for _, dash := range local {
+		if !replicated[KindDashboard][key{dash.OrgID, dash.UID}] {
+			s.applyChanges(ctx, []Change{{OrgID: dash.OrgID, Kind: KindDashboard, UID: dash.UID, Action: ActionDelete}})
+		}
+	}
+
+	query := &datasources.GetAllDataSourcesQuery{}
+	if err := s.dataSourceService.GetAllDataSources(ctx, query); err != nil {
+		return err
+	}
+	for _, ds := range query.Result {
+		if !replicated[KindDataSource][key{ds.OrgId, ds.Uid}] {
+			s.applyChanges(ctx, []Change{{OrgID: ds.OrgId, Kind: KindDataSource, UID: ds.Uid, Action: ActionDelete}})
+		}
+	}
+	return nil
+}
+
+func (s *ContentSyncService) orgExists(ctx context.Context, orgID int64) bool {
+	_, err := s.orgService.GetByID(ctx, &org.GetOrgByIdQuery{ID: orgID})
+	if err != nil && !errors.Is(err, org.ErrOrgNotFound) {
+		s.log.Warn("Failed to get the organization of a content change", "orgId", orgID, "error", err)
+	}
+	return err == nil
+}
+
+func (s *ContentSyncService) applyChange(ctx context.Context, ch Change) error {
+	switch ch.Kind {
+	case KindDashboard:
+		if ch.Action == ActionDelete {
+			return s.deleteDashboard(ctx, ch.OrgID, ch.UID)
+		}
+		if ch.Dashboard == nil {
+			return fmt.Errorf("the change has no dashboard")
+		}
+		return s.saveDashboard(ctx, ch.OrgID, ch.Dashboard)
+	case KindDataSource:
+		if ch.Action == ActionDelete {
+			return s.deleteDataSource(ctx, ch.OrgID, ch.UID)
+		}
+		if ch.DataSource == nil {
+			return fmt.Errorf("the change has no data source")
+		}
+		return s.saveDataSource(ctx, ch.OrgID, ch.DataSource)
+	}
+	return fmt.Errorf("unknown change kind %q", ch.Kind)
+}
+
+func (s *ContentSyncService) getLocalDashboard(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
+	query := &models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (s *ContentSyncService) saveDashboard(ctx context.Context, orgID int64, replicated *Dashboard) error {
+	data := replicated.Data
+	if data == nil {
+		return fmt.Errorf("the dashboard has no data")
+	}
+	// the IDs differ between the instances, the dashboard is matched by its UID
+	data.Del("id")
+	dash := models.NewDashboardFromJson(data)
+	dash.SetUid(replicated.UID)
+	dash.Title = replicated.Title
+	dash.IsFolder = replicated.IsFolder
+
+	if replicated.FolderUID != "" {
+		folder, err := s.getLocalDashboard(ctx, orgID, replicated.FolderUID)
+		if err != nil {
+			return err
+		}
+		if folder == nil {
+			return fmt.Errorf("the folder %q of the dashboard is not replicated", replicated.FolderUID)
+		}
+		dash.FolderId = folder.Id
+	}
+
+	dto := &dashboards.SaveDashboardDTO{
+		OrgId:     orgID,
+		Dashboard: dash,
+		Overwrite: true,
+		Message:   "Replicated from the primary",
+	}
+	if dash.IsFolder {
+		_, err := s.provisioningService.SaveFolderForProvisionedDashboards(ctx, dto)
+		return err
+	}
+	dto.User = accesscontrol.BackgroundUser("content_sync", orgID, org.RoleAdmin, replicaPermissions)
+	_, err := s.dashboardService.SaveDashboard(ctx, dto, true)
+	return err
+}
+
+func (s *ContentSyncService) deleteDashboard(ctx context.Context, orgID int64, uid string) error {
+	dash, err := s.getLocalDashboard(ctx, orgID, uid)
+	if err != nil || dash == nil {
+		return err
+	}
+	return s.dashboardService.DeleteDashboard(ctx, dash.Id, orgID)
+}
+
+func (s *ContentSyncService) saveDataSource(ctx context.Context, orgID int64, ds *DataSource) error {
+	query := &datasources.GetDataSourceQuery{OrgId: orgID, Uid: ds.UID}
+	err := s.dataSourceService.GetDataSource(ctx, query)
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return s.dataSourceService.AddDataSource(ctx, &datasources.AddDataSourceCommand{
+			OrgId:           orgID,
+			Uid:             ds.UID,
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          ds.Access,
+			Url:             ds.URL,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			JsonData:        ds.JSONData,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// the secrets set on the replica are kept, since they are not in the command
+	return s.dataSourceService.UpdateDataSource(ctx, &datasources.UpdateDataSourceCommand{
+		Id:              query.Result.Id,
+		OrgId:           orgID,
+		Uid:             ds.UID,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		Url:             ds.URL,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JsonData:        ds.JSONData,
+		ReadOnly:        query.Result.ReadOnly,
+	})
+}
+
+func (s *ContentSyncService) deleteDataSource(ctx context.Context, orgID int64, uid string) error {
+	err := s.dataSourceService.DeleteDataSource(ctx, &datasources.DeleteDataSourceCommand{OrgID: orgID, UID: uid})
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *ContentSyncService) registerReplicaAPIEndpoints() {
+	s.routeRegister.Get("/api/admin/content-sync/status", middleware.ReqGrafanaAdmin, routing.Wrap(s.getStatusHandler))
+}
+
+// swagger:route GET /admin/content-sync/status admin getContentSyncStatus
+//
+// Get the replication status of a replica.
+//
+// Returns the cursor and the time of the last sync and full sync of the replica, shared by the instances of its database, and the last error and the number of changes applied by the instance serving the request.
+//
+// Responses:
+// 200: getContentSyncStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *ContentSyncService) getStatusHandler(c *models.ReqContext) response.Response {
+	status, err := s.GetStatus(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the content sync status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:response getContentSyncStatusResponse
+type GetContentSyncStatusResponse struct {
+	// in:body
+	Body Status `json:"body"`
+}
@@ -0,0 +1,141 @@
+package contentsync
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func (s *ContentSyncService) insertChange(ctx context.Context, ch *change) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(ch)
+		return err
+	})
+}
+
+// getChangeRange returns the IDs of the oldest and latest retained changes, 0 if there are none.
+func (s *ContentSyncService) getChangeRange(ctx context.Context) (oldest int64, latest int64, err error) {
+	err = s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var first, last change
+		if _, err := sess.Asc("id").Cols("id").Get(&first); err != nil {
+			return err
+		}
+		if _, err := sess.Desc("id").Cols("id").Get(&last); err != nil {
+			return err
+		}
+		oldest, latest = first.ID, last.ID
+		return nil
+	})
+	return oldest, latest, err
+}
+
+func (s *ContentSyncService) getChangesAfter(ctx context.Context, after int64, limit int) ([]*change, error) {
+	changes := make([]*change, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("id > ?", after).Asc("id").Limit(limit).Find(&changes)
+	})
+	return changes, err
+}
+
+// deleteChangesBefore deletes the changes created before the time. The latest change is kept, so
+// that the replicas which replicated it can tell that they are not behind.
+func (s *ContentSyncService) deleteChangesBefore(ctx context.Context, before time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var latest change
+		if _, err := sess.Desc("id").Cols("id").Get(&latest); err != nil {
+			return err
+		}
+		deleted, err := sess.Where("created < ? AND id < ?", before, latest.ID).Delete(&change{})
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			s.log.Debug("Deleted expired content changes", "count", deleted)
+		}
+		return nil
+	})
+}
+
+// getDashboard returns the dashboard or folder of a UID, nil if it doesn't exist.
+func (s *ContentSyncService) getDashboard(ctx context.Context, orgID int64, uid string) (*Dashboard, error) {
+	var result *Dashboard
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var dash models.Dashboard
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&dash)
+		if err != nil || !exists {
+			return err
+		}
+
+		var folderUID string
+		if dash.FolderId > 0 {
+			var folder models.Dashboard
+			if _, err := sess.ID(dash.FolderId).Cols("uid").Get(&folder); err != nil {
+				return err
+			}
+			folderUID = folder.Uid
+		}
+		result = toDashboard(&dash, folderUID)
+		return nil
+	})
+	return result, err
+}
+
+type orgDashboard struct {
+	*Dashboard
+	orgID int64
+}
+
+// getDashboards returns all the dashboards and folders, the folders first.
+func (s *ContentSyncService) getDashboards(ctx context.Context) ([]orgDashboard, error) {
+	var dashboards []*models.Dashboard
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Desc("is_folder").Asc("id").Find(&dashboards)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	folderUIDs := map[int64]string{}
+	for _, dash := range dashboards {
+		if dash.IsFolder {
+			folderUIDs[dash.Id] = dash.Uid
+		}
+	}
+
+	result := make([]orgDashboard, 0, len(dashboards))
+	for _, dash := range dashboards {
+		result = append(result, orgDashboard{Dashboard: toDashboard(dash, folderUIDs[dash.FolderId]), orgID: dash.OrgId})
+	}
+	return result, nil
+}
+
+func toDashboard(dash *models.Dashboard, folderUID string) *Dashboard {
+	return &Dashboard{
+		UID:       dash.Uid,
+		Title:     dash.Title,
+		IsFolder:  dash.IsFolder,
+		FolderUID: folderUID,
+		Version:   dash.Version,
+		Data:      dash.Data,
+	}
+}
+
+// localDashboard is a dashboard or folder of a replica.
+type localDashboard struct {
+	ID       int64  `xorm:"id"`
+	OrgID    int64  `xorm:"org_id"`
+	UID      string `xorm:"uid"`
+	IsFolder bool   `xorm:"is_folder"`
+}
+
+// getLocalDashboards returns the dashboards and folders of a replica, the dashboards first so that
+// they are deleted before their folders.
+func (s *ContentSyncService) getLocalDashboards(ctx context.Context) ([]localDashboard, error) {
+	dashboards := make([]localDashboard, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard").Cols("id", "org_id", "uid", "is_folder").Asc("is_folder").Asc("id").Find(&dashboards)
+	})
+	return dashboards, err
+}
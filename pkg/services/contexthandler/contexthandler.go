@@ -27,8 +27,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -49,6 +51,7 @@ func ProvideService(cfg *setting.Cfg, tokenService auth.UserTokenService, jwtSer
 	tracer tracing.Tracer, authProxy *authproxy.AuthProxy, loginService login.Service,
 	apiKeyService apikey.Service, authenticator loginpkg.Authenticator, userService user.Service,
 	orgService org.Service, oauthTokenService oauthtoken.OAuthTokenService, features *featuremgmt.FeatureManager,
+	networkPolicyService networkpolicy.Service, orgTokenService orgtoken.Service,
 ) *ContextHandler {
 	return &ContextHandler{
 		Cfg:               cfg,
@@ -66,6 +69,8 @@ func ProvideService(cfg *setting.Cfg, tokenService auth.UserTokenService, jwtSer
 		orgService:        orgService,
 		oauthTokenService: oauthTokenService,
 		features:          features,
+		networkPolicy:     networkPolicyService,
+		orgTokenService:   orgTokenService,
 	}
 }
 
@@ -86,6 +91,8 @@ type ContextHandler struct {
 	orgService        org.Service
 	oauthTokenService oauthtoken.OAuthTokenService
 	features          *featuremgmt.FeatureManager
+	networkPolicy     networkpolicy.Service
+	orgTokenService   orgtoken.Service
 	// GetTime returns the current time.
 	// Stubbable by tests.
 	GetTime func() time.Time
@@ -166,15 +173,28 @@ func (h *ContextHandler) Middleware(next http.Handler) http.Handler {
 		case h.initContextWithAnonymousUser(reqContext):
 		}
 
-		reqContext.Logger = reqContext.Logger.New("userId", reqContext.UserID, "orgId", reqContext.OrgID, "uname", reqContext.Login)
+		// the spans and contextual log lines of the rest of the request are tagged with the organization
+		orgIDValue := h.Cfg.OrgIDLogValue(reqContext.OrgID)
+		*r = *r.WithContext(tracing.ContextWithOrgID(r.Context(), orgIDValue))
+		orgEvent := tracing.EventValue{Num: reqContext.OrgID}
+		if hashed, ok := orgIDValue.(string); ok {
+			orgEvent = tracing.EventValue{Str: hashed}
+		}
+
+		reqContext.Logger = reqContext.Logger.New("userId", reqContext.UserID, "orgId", orgIDValue, "uname", reqContext.Login)
 		span.AddEvents(
 			[]string{"uname", "orgId", "userId"},
 			[]tracing.EventValue{
 				{Str: reqContext.Login},
-				{Num: reqContext.OrgID},
+				orgEvent,
 				{Num: reqContext.UserID}},
 		)
 
+		if !h.isAllowedByNetworkPolicy(reqContext) {
+			reqContext.JsonApiErr(http.StatusForbidden, "Access from this network is not allowed", nil)
+			return
+		}
+
 		// update last seen every 5min
 		if reqContext.ShouldUpdateLastSeenAt() {
 			reqContext.Logger.Debug("Updating last user_seen_at", "user_id", reqContext.UserID)
@@ -187,6 +207,46 @@ func (h *ContextHandler) Middleware(next http.Handler) http.Handler {
 	})
 }
 
+// isAllowedByNetworkPolicy checks the address of a request against the network policy of the
+// organization it is made in. Server admins and render calls are not restricted, so that an
+// organization cannot lock out its server admins.
+func (h *ContextHandler) isAllowedByNetworkPolicy(reqContext *models.ReqContext) bool {
+	if h.networkPolicy == nil || reqContext.OrgID <= 0 || reqContext.IsGrafanaAdmin || reqContext.IsRenderCall {
+		return true
+	}
+
+	allowed, err := h.networkPolicy.IsAllowed(reqContext.Req.Context(), reqContext.OrgID, h.clientAddr(reqContext))
+	if err != nil {
+		reqContext.Logger.Error("Failed to check network policy", "error", err)
+		return false
+	}
+	if !allowed {
+		h.recordNetworkPolicyRejection(reqContext, networkpolicy.Rejection{
+			OrgID:    reqContext.OrgID,
+			UserID:   reqContext.UserID,
+			APIKeyID: reqContext.ApiKeyID,
+			Policy:   networkpolicy.PolicyTypeOrg,
+		})
+	}
+	return allowed
+}
+
+func (h *ContextHandler) recordNetworkPolicyRejection(reqContext *models.ReqContext, rejection networkpolicy.Rejection) {
+	if h.networkPolicy == nil {
+		return
+	}
+	rejection.IPAddress = h.clientAddr(reqContext)
+	rejection.Method = reqContext.Req.Method
+	rejection.Path = reqContext.Req.URL.Path
+	h.networkPolicy.RecordRejection(reqContext.Req.Context(), rejection)
+}
+
+// clientAddr returns the address of the client for checking it against allow and deny lists, which
+// unlike ReqContext.RemoteAddr only trusts the forwarding headers set by the trusted proxies.
+func (h *ContextHandler) clientAddr(reqContext *models.ReqContext) string {
+	return network.ClientIP(reqContext.Req, h.Cfg.TrustedProxies)
+}
+
 func (h *ContextHandler) initContextWithAnonymousUser(reqContext *models.ReqContext) bool {
 	if !h.Cfg.AnonymousEnabled {
 		return false
@@ -274,6 +334,10 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 	ctx := WithAuthHTTPHeader(reqContext.Req.Context(), "Authorization")
 	*reqContext.Req = *reqContext.Req.WithContext(ctx)
 
+	if strings.HasPrefix(keyString, orgtoken.KeyPrefix) && h.orgTokenService != nil {
+		return h.initContextWithOrgToken(reqContext, keyString)
+	}
+
 	var (
 		apikey *apikey.APIKey
 		errKey error
@@ -309,6 +373,16 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 		return true
 	}
 
+	if !apikey.IsAllowedFrom(h.clientAddr(reqContext)) {
+		h.recordNetworkPolicyRejection(reqContext, networkpolicy.Rejection{
+			OrgID:    apikey.OrgId,
+			APIKeyID: apikey.Id,
+			Policy:   networkpolicy.PolicyTypeAPIKey,
+		})
+		reqContext.JsonApiErr(http.StatusUnauthorized, "API key is not allowed from this address", nil)
+		return true
+	}
+
 	// update api_key last used date
 	if err := h.apiKeyService.UpdateAPIKeyLastUsedDate(reqContext.Req.Context(), apikey.Id); err != nil {
 		reqContext.JsonApiErr(http.StatusInternalServerError, InvalidAPIKey, errKey)
@@ -354,6 +428,26 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 	return true
 }
 
+// initContextWithOrgToken signs the request in with the role of an organization token, without any
+// user.
+func (h *ContextHandler) initContextWithOrgToken(reqContext *models.ReqContext, key string) bool {
+	token, err := h.orgTokenService.Authenticate(reqContext.Req.Context(), key, h.clientAddr(reqContext))
+	if err != nil {
+		if errors.Is(err, orgtoken.ErrTokenInvalid) || errors.Is(err, orgtoken.ErrTokenExpired) {
+			reqContext.JsonApiErr(http.StatusUnauthorized, err.Error(), nil)
+			return true
+		}
+		reqContext.JsonApiErr(http.StatusInternalServerError, "Failed to authenticate organization token", err)
+		return true
+	}
+
+	reqContext.SignedInUser = orgtoken.SignedInUser(token)
+	reqContext.IsSignedIn = true
+	reqContext.Logger = reqContext.Logger.New("orgToken", token.UID)
+	*reqContext.Req = *reqContext.Req.WithContext(orgtoken.ContextWithToken(reqContext.Req.Context(), token))
+	return true
+}
+
 func (h *ContextHandler) initContextWithBasicAuth(reqContext *models.ReqContext, orgID int64) bool {
 	if !h.Cfg.BasicAuthEnabled {
 		return false
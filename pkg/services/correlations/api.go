@@ -23,6 +23,7 @@ func (s *CorrelationsService) registerAPIEndpoints() {
 	s.RouteRegister.Group("/api/datasources/uid/:uid/correlations", func(entities routing.RouteRegister) {
 		entities.Get("/", authorize(middleware.ReqSignedIn, ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getCorrelationsBySourceUIDHandler))
 		entities.Post("/", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.createHandler))
+		entities.Post("/resolve", authorize(middleware.ReqSignedIn, ac.EvalPermission(datasources.ActionQuery, uidScope)), routing.Wrap(s.resolveHandler))
 
 		entities.Group("/:correlationUID", func(entities routing.RouteRegister) {
 			entities.Get("/", authorize(middleware.ReqSignedIn, ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getCorrelationHandler))
@@ -320,3 +321,55 @@ type GetCorrelationsResponse struct {
 	// in: body
 	Body []Correlation `json:"body"`
 }
+
+// swagger:route POST /datasources/uid/{sourceUID}/correlations/resolve correlations resolveCorrelations
+//
+// Resolves the correlations of a row of the source data source.
+//
+// Applies the transformations of the correlations to the fields and labels of the row and
+// returns the target queries with their variables interpolated. With `maxHops` greater than 1,
+// the correlations of the target data sources are followed as well. Correlations whose
+// variables cannot be resolved or whose target data source cannot be queried are skipped.
+//
+// Responses:
+// 200: resolveCorrelationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) resolveHandler(c *models.ReqContext) response.Response {
+	query := ResolveCorrelationsQuery{}
+	if err := web.Bind(c.Req, &query); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	query.SourceUID = web.Params(c.Req)[":uid"]
+	query.OrgId = c.OrgID
+	query.User = c.SignedInUser
+
+	correlations, err := s.ResolveCorrelations(c.Req.Context(), query)
+	if err != nil {
+		if errors.Is(err, ErrSourceDataSourceDoesNotExists) {
+			return response.Error(http.StatusNotFound, "Source data source not found", err)
+		}
+
+		return response.Error(http.StatusInternalServerError, "Failed to resolve correlations", err)
+	}
+
+	return response.JSON(http.StatusOK, correlations)
+}
+
+// swagger:parameters resolveCorrelations
+type ResolveCorrelationsParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"sourceUID"`
+	// in: body
+	Body ResolveCorrelationsQuery `json:"body"`
+}
+
+//swagger:response resolveCorrelationsResponse
+type ResolveCorrelationsResponse struct {
+	// in: body
+	Body []ResolvedCorrelation `json:"body"`
+}
@@ -68,6 +68,10 @@ func (s CorrelationsService) GetCorrelations(ctx context.Context, cmd GetCorrela
 	return s.getCorrelations(ctx, cmd)
 }
 
+func (s CorrelationsService) ResolveCorrelations(ctx context.Context, query ResolveCorrelationsQuery) ([]ResolvedCorrelation, error) {
+	return s.resolveCorrelations(ctx, query)
+}
+
 func (s CorrelationsService) DeleteCorrelationsBySourceUID(ctx context.Context, cmd DeleteCorrelationsBySourceUIDCommand) error {
 	return s.deleteCorrelationsBySourceUID(ctx, cmd)
 }
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
+
+	"github.com/grafana/grafana/pkg/services/user"
 )
 
 var (
@@ -14,6 +17,7 @@ var (
 	ErrCorrelationNotFound                = errors.New("correlation not found")
 	ErrUpdateCorrelationEmptyParams       = errors.New("not enough parameters to edit correlation")
 	ErrInvalidConfigType                  = errors.New("invalid correlation config type")
+	ErrInvalidTransformation              = errors.New("invalid correlation transformation")
 )
 
 type CorrelationConfigType string
@@ -29,6 +33,67 @@ func (t CorrelationConfigType) Validate() error {
 	return nil
 }
 
+type TransformationType string
+
+const (
+	// TransformationRegex extracts a value with a regular expression.
+	TransformationRegex TransformationType = "regex"
+	// TransformationLogfmt extracts every key of a logfmt formatted value.
+	TransformationLogfmt TransformationType = "logfmt"
+	// TransformationLabel maps a field or label to a variable of another name.
+	TransformationLabel TransformationType = "label"
+)
+
+// Transformation extracts variables for the target query from the fields and labels of the
+// row the correlation is followed from.
+// swagger:model
+type Transformation struct {
+	// Type of the transformation, one of regex, logfmt or label
+	// required:true
+	// example: regex
+	Type TransformationType `json:"type"`
+	// Field or label the value is read from. Defaults to the field of the correlation.
+	// example: message
+	Field string `json:"field,omitempty"`
+	// Regular expression of regex transformations. The first capture group is used if there is one,
+	// otherwise the whole match.
+	// example: traceID=(\w+)
+	Expression string `json:"expression,omitempty"`
+	// Name of the variable the value is stored in. Defaults to the name of the field. Logfmt
+	// transformations store every key in a variable of the same name.
+	// example: traceId
+	MapValue string `json:"mapValue,omitempty"`
+}
+
+func (t Transformation) Validate() error {
+	switch t.Type {
+	case TransformationRegex:
+		if t.Expression == "" {
+			return fmt.Errorf("%w: regex transformations must have an expression", ErrInvalidTransformation)
+		}
+		if _, err := regexp.Compile(t.Expression); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidTransformation, err)
+		}
+	case TransformationLogfmt:
+	case TransformationLabel:
+		if t.Field == "" || t.MapValue == "" {
+			return fmt.Errorf("%w: label transformations must have a field and a mapValue", ErrInvalidTransformation)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type \"%s\"", ErrInvalidTransformation, t.Type)
+	}
+	return nil
+}
+
+func validateTransformations(transformations []Transformation) error {
+	for _, t := range transformations {
+		if err := t.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // swagger:model
 type CorrelationConfig struct {
 	// Field used to attach the correlation link
@@ -42,6 +107,9 @@ type CorrelationConfig struct {
 	// required:true
 	// example: { "expr": "job=app" }
 	Target map[string]interface{} `json:"target" binding:"Required"`
+	// Transformations extracting the variables used in the target query, e.g. ${traceId}.
+	// The fields and labels of the row are variables as well.
+	Transformations []Transformation `json:"transformations,omitempty"`
 }
 
 func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
@@ -50,13 +118,15 @@ func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
 		target = map[string]interface{}{}
 	}
 	return json.Marshal(struct {
-		Type   CorrelationConfigType  `json:"type"`
-		Field  string                 `json:"field"`
-		Target map[string]interface{} `json:"target"`
+		Type            CorrelationConfigType  `json:"type"`
+		Field           string                 `json:"field"`
+		Target          map[string]interface{} `json:"target"`
+		Transformations []Transformation       `json:"transformations,omitempty"`
 	}{
-		Type:   ConfigTypeQuery,
-		Field:  c.Field,
-		Target: target,
+		Type:            ConfigTypeQuery,
+		Field:           c.Field,
+		Target:          target,
+		Transformations: c.Transformations,
 	})
 }
 
@@ -117,7 +187,7 @@ func (c CreateCorrelationCommand) Validate() error {
 	if c.TargetUID == nil && c.Config.Type == ConfigTypeQuery {
 		return fmt.Errorf("correlations of type \"%s\" must have a targetUID", ConfigTypeQuery)
 	}
-	return nil
+	return validateTransformations(c.Config.Transformations)
 }
 
 // swagger:model
@@ -151,6 +221,8 @@ type CorrelationConfigUpdateDTO struct {
 	// Target data query
 	// example: { "expr": "job=app" }
 	Target *map[string]interface{} `json:"target"`
+	// Transformations extracting the variables used in the target query
+	Transformations *[]Transformation `json:"transformations"`
 }
 
 func (c CorrelationConfigUpdateDTO) Validate() error {
@@ -159,6 +231,9 @@ func (c CorrelationConfigUpdateDTO) Validate() error {
 			return err
 		}
 	}
+	if c.Transformations != nil {
+		return validateTransformations(*c.Transformations)
+	}
 
 	return nil
 }
@@ -188,7 +263,7 @@ func (c UpdateCorrelationCommand) Validate() error {
 		}
 	}
 
-	if c.Label == nil && c.Description == nil && (c.Config == nil || (c.Config.Field == nil && c.Config.Type == nil && c.Config.Target == nil)) {
+	if c.Label == nil && c.Description == nil && (c.Config == nil || (c.Config.Field == nil && c.Config.Type == nil && c.Config.Target == nil && c.Config.Transformations == nil)) {
 		return ErrUpdateCorrelationEmptyParams
 	}
 
@@ -222,3 +297,51 @@ type DeleteCorrelationsBySourceUIDCommand struct {
 type DeleteCorrelationsByTargetUIDCommand struct {
 	TargetUID string
 }
+
+// ResolveCorrelationsQuery is the query to resolve the correlations of a row of the source data source
+// swagger:model
+type ResolveCorrelationsQuery struct {
+	SourceUID string             `json:"-"`
+	OrgId     int64              `json:"-"`
+	User      *user.SignedInUser `json:"-"`
+	// Field the correlations are followed from. Correlations attached to other fields are skipped.
+	// All correlations of the source data source are resolved if empty.
+	// example: message
+	Field string `json:"field"`
+	// Values of the fields of the row by field name
+	// example: { "message": "level=error traceID=1a2b3c" }
+	Fields map[string]string `json:"fields"`
+	// Labels of the row
+	// example: { "job": "app" }
+	Labels map[string]string `json:"labels"`
+	// Number of correlations followed from the source data source. Correlations of a target
+	// data source are followed with the variables resolved so far. Defaults to 1, at most 3.
+	// example: 2
+	MaxHops int `json:"maxHops"`
+}
+
+// ResolvedCorrelation is a correlation with the variables of its target query interpolated
+// swagger:model
+type ResolvedCorrelation struct {
+	// Unique identifier of the correlation
+	// example: 50xhMlg9k
+	UID string `json:"uid"`
+	// UID of the data source the correlation originates from
+	// example:d0oxYRg4z
+	SourceUID string `json:"sourceUID"`
+	// UID of the data source the query runs against
+	// example:PE1C5CBDA0504A6A3
+	TargetUID string `json:"targetUID"`
+	// example: My Label
+	Label string `json:"label"`
+	// example: Logs to Traces
+	Description string `json:"description"`
+	// Target query with the variables interpolated
+	// example: { "query": "1a2b3c" }
+	Query map[string]interface{} `json:"query"`
+	// Variables available to the target query
+	// example: { "traceId": "1a2b3c" }
+	Variables map[string]string `json:"variables"`
+	// UIDs of the correlations followed from the source data source, ending with this correlation
+	Path []string `json:"path"`
+}
@@ -57,6 +57,25 @@ func TestCorrelationModels(t *testing.T) {
 		})
 	})
 
+	t.Run("Transformation Validate", func(t *testing.T) {
+		tests := []struct {
+			input     Transformation
+			assertion require.ErrorAssertionFunc
+		}{
+			{input: Transformation{Type: TransformationRegex, Expression: `id=(\w+)`}, assertion: require.NoError},
+			{input: Transformation{Type: TransformationRegex}, assertion: require.Error},
+			{input: Transformation{Type: TransformationRegex, Expression: "("}, assertion: require.Error},
+			{input: Transformation{Type: TransformationLogfmt}, assertion: require.NoError},
+			{input: Transformation{Type: TransformationLabel, Field: "job", MapValue: "service"}, assertion: require.NoError},
+			{input: Transformation{Type: TransformationLabel, Field: "job"}, assertion: require.Error},
+			{input: Transformation{Type: "jsonpath"}, assertion: require.Error},
+		}
+
+		for _, tc := range tests {
+			tc.assertion(t, tc.input.Validate())
+		}
+	})
+
 	t.Run("CorrelationConfigType Validate", func(t *testing.T) {
 		t.Run("Successfully validates a correct type", func(t *testing.T) {
 			type test struct {
@@ -0,0 +1,109 @@
+package correlations
+
+import (
+	"context"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const (
+	defaultMaxHops = 1
+	maxHopsLimit   = 3
+)
+
+// hop is a data source reached while following correlations, with the variables resolved on the way.
+type hop struct {
+	dataSourceUID string
+	vars          map[string]string
+	path          []string
+	visited       map[string]bool
+}
+
+// resolveCorrelations follows the correlations of the source data source breadth first and
+// interpolates their target queries. Correlations whose target query uses variables which
+// cannot be resolved, or whose target data source the user cannot query, are skipped. A data
+// source is not visited twice on the same path.
+func (s CorrelationsService) resolveCorrelations(ctx context.Context, query ResolveCorrelationsQuery) ([]ResolvedCorrelation, error) {
+	maxHops := query.MaxHops
+	if maxHops <= 0 {
+		maxHops = defaultMaxHops
+	}
+	if maxHops > maxHopsLimit {
+		maxHops = maxHopsLimit
+	}
+
+	resolved := make([]ResolvedCorrelation, 0)
+	canQuery := make(map[string]bool)
+	frontier := []hop{{
+		dataSourceUID: query.SourceUID,
+		vars:          rowVariables(query.Fields, query.Labels),
+		visited:       map[string]bool{query.SourceUID: true},
+	}}
+
+	for depth := 0; depth < maxHops && len(frontier) > 0; depth++ {
+		next := make([]hop, 0)
+		for _, h := range frontier {
+			correlations, err := s.getCorrelationsBySourceUID(ctx, GetCorrelationsBySourceUIDQuery{
+				SourceUID: h.dataSourceUID,
+				OrgId:     query.OrgId,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, correlation := range correlations {
+				// the field only identifies the correlations of the row the user clicked
+				if depth == 0 && query.Field != "" && correlation.Config.Field != query.Field {
+					continue
+				}
+				if correlation.TargetUID == nil || h.visited[*correlation.TargetUID] {
+					continue
+				}
+				targetUID := *correlation.TargetUID
+
+				allowed, ok := canQuery[targetUID]
+				if !ok {
+					allowed, err = s.AccessControl.Evaluate(ctx, query.User,
+						ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(targetUID)))
+					if err != nil {
+						return nil, err
+					}
+					canQuery[targetUID] = allowed
+				}
+				if !allowed {
+					continue
+				}
+
+				vars := applyTransformations(correlation.Config, h.vars)
+				target, missing := interpolate(correlation.Config.Target, vars)
+				if len(missing) > 0 {
+					s.log.Debug("Skipping correlation with unresolved variables", "correlation", correlation.UID, "variables", missing)
+					continue
+				}
+
+				path := append(append(make([]string, 0, len(h.path)+1), h.path...), correlation.UID)
+				resolved = append(resolved, ResolvedCorrelation{
+					UID:         correlation.UID,
+					SourceUID:   correlation.SourceUID,
+					TargetUID:   targetUID,
+					Label:       correlation.Label,
+					Description: correlation.Description,
+					Query:       target,
+					Variables:   vars,
+					Path:        path,
+				})
+
+				visited := make(map[string]bool, len(h.visited)+1)
+				for uid := range h.visited {
+					visited[uid] = true
+				}
+				visited[targetUID] = true
+				next = append(next, hop{dataSourceUID: targetUID, vars: vars, path: path, visited: visited})
+			}
+		}
+		frontier = next
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,153 @@
+package correlations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// variableRegex matches the variables of a target query, written as $name or ${name}.
+var variableRegex = regexp.MustCompile(`\$(?:\{([\w.]+)\}|(\w+))`)
+
+// rowVariables returns the fields and labels of a row by name. Fields take precedence
+// over labels of the same name.
+func rowVariables(fields, labels map[string]string) map[string]string {
+	vars := make(map[string]string, len(fields)+len(labels))
+	for name, value := range labels {
+		vars[name] = value
+	}
+	for name, value := range fields {
+		vars[name] = value
+	}
+	return vars
+}
+
+// applyTransformations returns a copy of the variables with the variables extracted by the
+// transformations of a correlation added. Transformations reading a variable which is not
+// set are skipped, later transformations can read the variables of earlier ones.
+func applyTransformations(config CorrelationConfig, vars map[string]string) map[string]string {
+	result := make(map[string]string, len(vars))
+	for name, value := range vars {
+		result[name] = value
+	}
+
+	for _, t := range config.Transformations {
+		field := t.Field
+		if field == "" {
+			field = config.Field
+		}
+		value, ok := result[field]
+		if !ok {
+			continue
+		}
+		name := t.MapValue
+		if name == "" {
+			name = field
+		}
+
+		switch t.Type {
+		case TransformationRegex:
+			re, err := regexp.Compile(t.Expression)
+			if err != nil {
+				continue
+			}
+			match := re.FindStringSubmatch(value)
+			if match == nil {
+				continue
+			}
+			if len(match) > 1 {
+				result[name] = match[1]
+			} else {
+				result[name] = match[0]
+			}
+		case TransformationLogfmt:
+			for k, v := range parseLogfmt(value) {
+				result[k] = v
+			}
+		case TransformationLabel:
+			result[name] = value
+		}
+	}
+	return result
+}
+
+// parseLogfmt returns the keys and values of a logfmt formatted line, e.g. level=info msg="done".
+// Keys without a value are skipped.
+func parseLogfmt(line string) map[string]string {
+	values := make(map[string]string)
+	for i := 0; i < len(line); {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		key := line[start:i]
+		if i >= len(line) || line[i] != '=' {
+			continue
+		}
+		i++
+
+		var value strings.Builder
+		if i < len(line) && line[i] == '"' {
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				value.WriteByte(line[i])
+			}
+			i++
+		} else {
+			for ; i < len(line) && line[i] != ' '; i++ {
+				value.WriteByte(line[i])
+			}
+		}
+		if key != "" {
+			values[key] = value.String()
+		}
+	}
+	return values
+}
+
+// interpolate replaces the variables in the string values of a target query. It returns the
+// names of the variables which are not set. Built-in variables such as $__interval are left
+// for the query editor to replace.
+func interpolate(target map[string]interface{}, vars map[string]string) (map[string]interface{}, []string) {
+	missing := make([]string, 0)
+	var replace func(v interface{}) interface{}
+	replace = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case string:
+			return variableRegex.ReplaceAllStringFunc(v, func(match string) string {
+				groups := variableRegex.FindStringSubmatch(match)
+				name := groups[1] + groups[2]
+				if value, ok := vars[name]; ok {
+					return value
+				}
+				if !strings.HasPrefix(name, "__") {
+					missing = append(missing, name)
+				}
+				return match
+			})
+		case map[string]interface{}:
+			m := make(map[string]interface{}, len(v))
+			for k, value := range v {
+				m[k] = replace(value)
+			}
+			return m
+		case []interface{}:
+			list := make([]interface{}, len(v))
+			for i, value := range v {
+				list[i] = replace(value)
+			}
+			return list
+		}
+		return v
+	}
+
+	query := make(map[string]interface{}, len(target))
+	for k, v := range target {
+		query[k] = replace(v)
+	}
+	return query, missing
+}
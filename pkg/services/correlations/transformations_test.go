@@ -0,0 +1,70 @@
+package correlations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTransformations(t *testing.T) {
+	config := CorrelationConfig{
+		Field: "message",
+		Transformations: []Transformation{
+			{Type: TransformationRegex, Expression: `traceID=(\w+)`, MapValue: "traceId"},
+			{Type: TransformationLogfmt},
+			{Type: TransformationLabel, Field: "service_name", MapValue: "service"},
+			{Type: TransformationRegex, Field: "missing", Expression: `.*`},
+		},
+	}
+	vars := rowVariables(
+		map[string]string{"message": `level=error traceID=abc123 msg="request failed"`},
+		map[string]string{"service_name": "checkout", "message": "overridden by the field"},
+	)
+
+	result := applyTransformations(config, vars)
+	require.Equal(t, map[string]string{
+		"message":      `level=error traceID=abc123 msg="request failed"`,
+		"service_name": "checkout",
+		"traceId":      "abc123",
+		"traceID":      "abc123",
+		"level":        "error",
+		"msg":          "request failed",
+		"service":      "checkout",
+	}, result)
+	require.Len(t, vars, 2, "the variables of the row are not changed")
+
+	t.Run("uses the whole match without capture group", func(t *testing.T) {
+		config := CorrelationConfig{Field: "id", Transformations: []Transformation{{Type: TransformationRegex, Expression: `\d+`}}}
+		require.Equal(t, "42", applyTransformations(config, map[string]string{"id": "order-42"})["id"])
+	})
+}
+
+func TestParseLogfmt(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"a":     "1",
+		"b":     `quoted "value"`,
+		"empty": "",
+	}, parseLogfmt(` a=1 flag b="quoted \"value\"" empty= =orphan`))
+}
+
+func TestInterpolate(t *testing.T) {
+	target := map[string]interface{}{
+		"expr":    `{job="$job"} |= "${traceId}"`,
+		"step":    "$__interval",
+		"limit":   100,
+		"filters": []interface{}{map[string]interface{}{"value": "$job"}},
+	}
+
+	query, missing := interpolate(target, map[string]string{"job": "app", "traceId": "abc"})
+	require.Empty(t, missing)
+	require.Equal(t, map[string]interface{}{
+		"expr":    `{job="app"} |= "abc"`,
+		"step":    "$__interval",
+		"limit":   100,
+		"filters": []interface{}{map[string]interface{}{"value": "app"}},
+	}, query)
+	require.Equal(t, `{job="$job"} |= "${traceId}"`, target["expr"], "the target is not changed")
+
+	_, missing = interpolate(target, map[string]string{"job": "app"})
+	require.Equal(t, []string{"traceId"}, missing)
+}
@@ -0,0 +1,170 @@
+package dashboardbackup
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *BackupService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/admin/dashboard-backups", func(backupRoute routing.RouteRegister) {
+		backupRoute.Get("/", routing.Wrap(s.listBackupsHandler))
+		backupRoute.Post("/", routing.Wrap(s.backupHandler))
+		backupRoute.Get("/:id", routing.Wrap(s.getBackupHandler))
+		backupRoute.Post("/:id/restore", routing.Wrap(s.restoreHandler))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+// swagger:route GET /admin/dashboard-backups admin_dashboard_backups adminListDashboardBackups
+//
+// List dashboard backups.
+//
+// Returns the complete dashboard backups in the configured storage, newest first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: listDashboardBackupsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *BackupService) listBackupsHandler(c *models.ReqContext) response.Response {
+	backups, err := s.ListBackups(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list dashboard backups", err)
+	}
+	return response.JSON(http.StatusOK, backups)
+}
+
+// swagger:route POST /admin/dashboard-backups admin_dashboard_backups adminCreateDashboardBackup
+//
+// Back up dashboards.
+//
+// Exports the dashboards of all organizations to the configured storage and returns the summary of the backup once it is complete.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: createDashboardBackupResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *BackupService) backupHandler(c *models.ReqContext) response.Response {
+	manifest, err := s.Backup(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, ErrBackupInProgress) {
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to back up dashboards", err)
+	}
+	return response.JSON(http.StatusOK, manifest.summary())
+}
+
+// swagger:route GET /admin/dashboard-backups/{id} admin_dashboard_backups adminGetDashboardBackup
+//
+// Get dashboard backup.
+//
+// Returns the manifest of the backup, which lists its dashboards.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getDashboardBackupResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *BackupService) getBackupHandler(c *models.ReqContext) response.Response {
+	manifest, err := s.GetBackup(c.Req.Context(), web.Params(c.Req)[":id"])
+	if err != nil {
+		if errors.Is(err, ErrBackupNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard backup not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard backup", err)
+	}
+	return response.JSON(http.StatusOK, manifest)
+}
+
+// swagger:route POST /admin/dashboard-backups/{id}/restore admin_dashboard_backups adminRestoreDashboardBackup
+//
+// Restore dashboards from a backup.
+//
+// Restores all dashboards of the backup, or those of an organization or with the given uids. Missing folders are created. Dashboards which exist are skipped unless overwrite is set.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: restoreDashboardBackupResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *BackupService) restoreHandler(c *models.ReqContext) response.Response {
+	cmd := RestoreCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.BackupID = web.Params(c.Req)[":id"]
+	cmd.UserID = c.UserID
+
+	result, err := s.Restore(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, ErrBackupNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard backup not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to restore dashboards", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters adminGetDashboardBackup
+type GetDashboardBackupParams struct {
+	// in:path
+	// required:true
+	ID string `json:"id"`
+}
+
+// swagger:parameters adminRestoreDashboardBackup
+type RestoreDashboardBackupParams struct {
+	// in:path
+	// required:true
+	ID string `json:"id"`
+	// in:body
+	// required:true
+	Body RestoreCommand `json:"body"`
+}
+
+// swagger:response listDashboardBackupsResponse
+type ListDashboardBackupsResponse struct {
+	// in:body
+	Body []*Summary `json:"body"`
+}
+
+// swagger:response createDashboardBackupResponse
+type CreateDashboardBackupResponse struct {
+	// in:body
+	Body *Summary `json:"body"`
+}
+
+// swagger:response getDashboardBackupResponse
+type GetDashboardBackupResponse struct {
+	// in:body
+	Body *Manifest `json:"body"`
+}
+
+// swagger:response restoreDashboardBackupResponse
+type RestoreDashboardBackupResponse struct {
+	// in:body
+	Body *RestoreResult `json:"body"`
+}
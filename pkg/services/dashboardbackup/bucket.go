@@ -0,0 +1,250 @@
+package dashboardbackup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+// bucket stores the objects of backups under keys separated by slashes.
+type bucket interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns errObjectNotFound if there is no object with the key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the keys starting with the prefix in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func newBucket(ctx context.Context, s settings) (bucket, error) {
+	switch s.storage {
+	case "local":
+		return &localBucket{root: s.path}, nil
+	case "s3":
+		return newS3Bucket(s)
+	case "gcs":
+		return newGCSBucket(ctx, s)
+	}
+	return nil, fmt.Errorf("unknown dashboard backup storage %q", s.storage)
+}
+
+// localBucket stores objects as files below a directory. It is meant for development and for
+// directories mounted from a network file system.
+type localBucket struct {
+	root string
+}
+
+func (b *localBucket) Put(_ context.Context, key string, data []byte) error {
+	path := filepath.Join(b.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0640)
+}
+
+func (b *localBucket) Get(_ context.Context, key string) ([]byte, error) {
+	// #nosec G304 -- the keys are created by the backup service
+	data, err := os.ReadFile(filepath.Join(b.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errObjectNotFound
+	}
+	return data, err
+}
+
+func (b *localBucket) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(b.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// Delete also removes the directories left empty, up to the root.
+func (b *localBucket) Delete(_ context.Context, key string) error {
+	path := filepath.Join(b.root, filepath.FromSlash(key))
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for dir := filepath.Dir(path); dir != b.root && strings.HasPrefix(dir, b.root); dir = filepath.Dir(dir) {
+		// fails if the directory is not empty
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+type s3Bucket struct {
+	client *s3.S3
+	name   string
+}
+
+// newS3Bucket uses static credentials when access_key_id is set, otherwise the default AWS
+// credential chain (environment, shared credentials file, instance role).
+func newS3Bucket(s settings) (*s3Bucket, error) {
+	cfg := aws.NewConfig().WithS3ForcePathStyle(s.pathStyleAccess)
+	if s.region != "" {
+		cfg = cfg.WithRegion(s.region)
+	}
+	if s.endpoint != "" {
+		cfg = cfg.WithEndpoint(s.endpoint)
+	}
+	if s.accessKeyID != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(s.accessKeyID, s.secretAccessKey, ""))
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Bucket{client: s3.New(sess), name: s.bucket}, nil
+}
+
+func (b *s3Bucket) Put(ctx context.Context, key string, data []byte) error {
+	_, err := b.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.name),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+func (b *s3Bucket) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, errObjectNotFound
+		}
+		return nil, err
+	}
+	defer func() { _ = out.Body.Close() }()
+	return io.ReadAll(out.Body)
+}
+
+func (b *s3Bucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := b.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.name),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	return keys, err
+}
+
+func (b *s3Bucket) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+type gcsBucket struct {
+	bucket *gcs.BucketHandle
+}
+
+// newGCSBucket uses the service account key file when key_file is set, otherwise the application
+// default credentials.
+func newGCSBucket(ctx context.Context, s settings) (*gcsBucket, error) {
+	var opts []option.ClientOption
+	if s.keyFile != "" {
+		opts = append(opts, option.WithCredentialsFile(s.keyFile))
+	}
+	client, err := gcs.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &gcsBucket{bucket: client.Bucket(s.bucket)}, nil
+}
+
+func (b *gcsBucket) Put(ctx context.Context, key string, data []byte) error {
+	w := b.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = "application/json"
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (b *gcsBucket) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := b.bucket.Object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return nil, errObjectNotFound
+		}
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+func (b *gcsBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	it := b.bucket.Objects(ctx, &gcs.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attrs.Name)
+	}
+}
+
+func (b *gcsBucket) Delete(ctx context.Context, key string) error {
+	err := b.bucket.Object(key).Delete(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
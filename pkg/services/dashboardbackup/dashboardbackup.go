@@ -0,0 +1,285 @@
+// Package dashboardbackup exports the JSON models of all dashboards to object storage on a schedule,
+// independent of database backups, and restores dashboards from these exports.
+//
+// Every backup is a set of objects below a prefix named by the time the backup was created, with one
+// object per dashboard laid out by organization and folder:
+//
+//	<prefix>/<backup id>/org-<org id>/<folder>/<dashboard uid>.json
+//
+// The manifest of a backup lists its dashboards together with their checksums. It is written to
+// <prefix>/manifests/<backup id>.json once all dashboards are exported, so backups without a manifest
+// are incomplete and never listed or restored.
+package dashboardbackup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	idLayout        = "20060102T150405Z"
+	manifestsPrefix = "manifests/"
+	scheduleTick    = 5 * time.Minute
+	pageSize        = 500
+)
+
+var (
+	ErrBackupNotFound   = errors.New("dashboard backup not found")
+	ErrBackupInProgress = errors.New("a dashboard backup is already in progress")
+)
+
+type settings struct {
+	enabled  bool
+	interval time.Duration
+	// retention is how long backups are kept, 0 keeps them forever. The latest backup is never deleted.
+	retention time.Duration
+	storage   string
+	prefix    string
+
+	// path is the directory of the local storage.
+	path string
+
+	bucket          string
+	region          string
+	endpoint        string
+	accessKeyID     string
+	secretAccessKey string
+	pathStyleAccess bool
+	keyFile         string
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("dashboard_backup")
+	s := settings{
+		enabled:         section.Key("enabled").MustBool(false),
+		storage:         section.Key("storage").MustString("local"),
+		prefix:          strings.Trim(section.Key("prefix").MustString(""), "/"),
+		path:            section.Key("path").MustString("dashboard-backups"),
+		bucket:          section.Key("bucket").MustString(""),
+		region:          section.Key("region").MustString(""),
+		endpoint:        section.Key("endpoint").MustString(""),
+		accessKeyID:     section.Key("access_key_id").MustString(""),
+		secretAccessKey: section.Key("secret_access_key").MustString(""),
+		pathStyleAccess: section.Key("path_style_access").MustBool(false),
+		keyFile:         section.Key("key_file").MustString(""),
+	}
+	if s.prefix != "" {
+		s.prefix += "/"
+	}
+	if !filepath.IsAbs(s.path) {
+		s.path = filepath.Join(cfg.DataPath, s.path)
+	}
+
+	s.interval = parseDuration(section.Key("interval").MustString("24h"), 24*time.Hour)
+	s.retention = parseDuration(section.Key("retention").MustString("30d"), 30*24*time.Hour)
+	return s
+}
+
+func parseDuration(value string, defaultValue time.Duration) time.Duration {
+	if value == "" || value == "0" {
+		return 0
+	}
+	d, err := gtime.ParseDuration(value)
+	if err != nil || d < 0 {
+		return defaultValue
+	}
+	return d
+}
+
+// Manifest lists the dashboards of a backup.
+// swagger:model DashboardBackupManifest
+type Manifest struct {
+	ID             string    `json:"id"`
+	GrafanaVersion string    `json:"grafanaVersion"`
+	Created        time.Time `json:"created"`
+	Dashboards     []*Entry  `json:"dashboards"`
+}
+
+// Entry is a dashboard of a backup.
+type Entry struct {
+	OrgID       int64  `json:"orgId"`
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Version     int    `json:"version"`
+	FolderUID   string `json:"folderUid,omitempty"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+	// Key is the object of the dashboard, relative to the prefix of the backups.
+	Key    string `json:"key"`
+	SHA256 string `json:"sha256"`
+}
+
+// Summary describes a backup without listing its dashboards.
+// swagger:model DashboardBackupSummary
+type Summary struct {
+	ID             string    `json:"id"`
+	GrafanaVersion string    `json:"grafanaVersion"`
+	Created        time.Time `json:"created"`
+	Orgs           int       `json:"orgs"`
+	Dashboards     int       `json:"dashboards"`
+}
+
+func (m *Manifest) summary() *Summary {
+	orgs := map[int64]bool{}
+	for _, e := range m.Dashboards {
+		orgs[e.OrgID] = true
+	}
+	return &Summary{
+		ID:             m.ID,
+		GrafanaVersion: m.GrafanaVersion,
+		Created:        m.Created,
+		Orgs:           len(orgs),
+		Dashboards:     len(m.Dashboards),
+	}
+}
+
+// RestoreCommand restores the dashboards of a backup, either all of them or those of a single
+// organization or with the given uids.
+// swagger:model
+type RestoreCommand struct {
+	BackupID      string   `json:"-"`
+	UserID        int64    `json:"-"`
+	OrgID         int64    `json:"orgId"`
+	DashboardUIDs []string `json:"dashboardUids"`
+	// Overwrite replaces dashboards which exist with the same uid, or with the same title in the same
+	// folder. They are skipped otherwise.
+	Overwrite bool `json:"overwrite"`
+}
+
+// RestoreResult counts the restored dashboards and lists those which were not restored.
+// swagger:model DashboardBackupRestoreResult
+type RestoreResult struct {
+	Restored int             `json:"restored"`
+	Skipped  []*RestoreIssue `json:"skipped"`
+	Failed   []*RestoreIssue `json:"failed"`
+}
+
+type RestoreIssue struct {
+	OrgID  int64  `json:"orgId"`
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
+type Service interface {
+	// Backup exports all dashboards of all organizations and deletes backups past their retention.
+	Backup(ctx context.Context) (*Manifest, error)
+	// ListBackups returns the complete backups, newest first.
+	ListBackups(ctx context.Context) ([]*Summary, error)
+	GetBackup(ctx context.Context, id string) (*Manifest, error)
+	Restore(ctx context.Context, cmd RestoreCommand) (*RestoreResult, error)
+}
+
+type BackupService struct {
+	settings       settings
+	store          db.DB
+	dashboardStore dashboards.Store
+	leaderElection leaderelection.Service
+	routeRegister  routing.RouteRegister
+	log            log.Logger
+	now            func() time.Time
+
+	// newBucket is replaced in tests.
+	newBucket func(ctx context.Context, s settings) (bucket, error)
+
+	bucketMu sync.Mutex
+	bucket   bucket
+
+	// running is held while a backup is in progress.
+	running sync.Mutex
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, dashboardStore dashboards.Store,
+	leaderElection leaderelection.Service, routeRegister routing.RouteRegister) *BackupService {
+	s := &BackupService{
+		settings:       readSettings(cfg),
+		store:          sqlStore,
+		dashboardStore: dashboardStore,
+		leaderElection: leaderElection,
+		routeRegister:  routeRegister,
+		log:            log.New("dashboard-backup"),
+		now:            time.Now,
+		newBucket:      newBucket,
+	}
+	if s.settings.enabled {
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+// IsDisabled disables the scheduled backups unless backups are enabled with an interval. Backups
+// can still be started through the admin API when the interval is 0.
+func (s *BackupService) IsDisabled() bool {
+	return !s.settings.enabled || s.settings.interval <= 0
+}
+
+// Run backs up the dashboards every interval on the node elected as leader.
+func (s *BackupService) Run(ctx context.Context) error {
+	tick := scheduleTick
+	if s.settings.interval < tick {
+		tick = s.settings.interval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !s.leaderElection.IsLeader(ctx, leaderelection.LeaseDashboardBackup) {
+				continue
+			}
+			if err := s.backupIfDue(ctx); err != nil && !errors.Is(err, ErrBackupInProgress) {
+				s.log.Error("Scheduled dashboard backup failed", "error", err)
+			}
+		}
+	}
+}
+
+// backupIfDue backs up the dashboards if the latest backup, which may have been created by another
+// node, is older than the interval.
+func (s *BackupService) backupIfDue(ctx context.Context) error {
+	b, err := s.getBucket(ctx)
+	if err != nil {
+		return err
+	}
+	ids, err := listBackupIDs(ctx, b, s.settings.prefix)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		latest, err := time.Parse(idLayout, ids[0])
+		if err == nil && s.now().Sub(latest) < s.settings.interval {
+			return nil
+		}
+	}
+	_, err = s.Backup(ctx)
+	return err
+}
+
+// getBucket connects to the storage on first use.
+func (s *BackupService) getBucket(ctx context.Context) (bucket, error) {
+	s.bucketMu.Lock()
+	defer s.bucketMu.Unlock()
+	if s.bucket == nil {
+		b, err := s.newBucket(ctx, s.settings)
+		if err != nil {
+			return nil, err
+		}
+		s.bucket = b
+	}
+	return s.bucket, nil
+}
@@ -0,0 +1,178 @@
+package dashboardbackup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/models"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationDashboardBackup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	sqlStore.Cfg.AutoAssignOrg = true
+	sqlStore.Cfg.AutoAssignOrgId = 1
+	ctx := context.Background()
+
+	admin, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: "admin", Email: "admin@example.com"})
+	require.NoError(t, err)
+	orgID := admin.OrgID
+
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures(),
+		tagimpl.ProvideService(sqlStore, sqlStore.Cfg), quotatest.New(false, nil))
+	require.NoError(t, err)
+
+	save := func(t *testing.T, cmd models.SaveDashboardCommand) *models.Dashboard {
+		t.Helper()
+		cmd.OrgId = orgID
+		cmd.UserId = admin.ID
+		dash, err := dashboardStore.SaveDashboard(ctx, cmd)
+		require.NoError(t, err)
+		return dash
+	}
+	folder := save(t, models.SaveDashboardCommand{
+		IsFolder:  true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "prod", "title": "Production"}),
+	})
+	payments := save(t, models.SaveDashboardCommand{
+		FolderId:  folder.Id,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "payments", "title": "Payments", "refresh": "10s"}),
+	})
+	save(t, models.SaveDashboardCommand{
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "home", "title": "Home"}),
+	})
+
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	section := cfg.Raw.Section("dashboard_backup")
+	section.Key("enabled").SetValue("true")
+	section.Key("prefix").SetValue("/grafana/")
+	section.Key("retention").SetValue("7d")
+
+	now := time.Date(2022, 11, 28, 2, 0, 0, 0, time.UTC)
+	s := ProvideService(cfg, sqlStore, dashboardStore,
+		leaderelection.ProvideService(setting.NewCfg(), sqlStore, routing.NewRouteRegister()), routing.NewRouteRegister())
+	s.now = func() time.Time { return now }
+	root := filepath.Join(cfg.DataPath, "dashboard-backups")
+
+	var backupID string
+	t.Run("backup exports dashboards by organization and folder", func(t *testing.T) {
+		manifest, err := s.Backup(ctx)
+		require.NoError(t, err)
+		backupID = manifest.ID
+		assert.Equal(t, "20221128T020000Z", backupID)
+		require.Len(t, manifest.Dashboards, 2)
+
+		entries := map[string]*Entry{}
+		for _, e := range manifest.Dashboards {
+			entries[e.UID] = e
+		}
+		assert.Equal(t, "20221128T020000Z/org-1/production/payments.json", entries["payments"].Key)
+		assert.Equal(t, "Production", entries["payments"].FolderTitle)
+		assert.Equal(t, "20221128T020000Z/org-1/general/home.json", entries["home"].Key)
+
+		data, err := os.ReadFile(filepath.Join(root, "grafana", "20221128T020000Z", "org-1", "production", "payments.json"))
+		require.NoError(t, err)
+		assert.Equal(t, entries["payments"].SHA256, checksum(data))
+		assert.FileExists(t, filepath.Join(root, "grafana", "manifests", "20221128T020000Z.json"))
+
+		backups, err := s.ListBackups(ctx)
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		assert.Equal(t, 2, backups[0].Dashboards)
+		assert.Equal(t, 1, backups[0].Orgs)
+	})
+
+	t.Run("backup is not due before the interval", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		require.NoError(t, s.backupIfDue(ctx))
+		backups, err := s.ListBackups(ctx)
+		require.NoError(t, err)
+		assert.Len(t, backups, 1)
+	})
+
+	t.Run("restore skips existing dashboards", func(t *testing.T) {
+		result, err := s.Restore(ctx, RestoreCommand{BackupID: backupID, UserID: admin.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Restored)
+		assert.Len(t, result.Skipped, 2)
+		assert.Empty(t, result.Failed)
+	})
+
+	t.Run("restore with overwrite replaces changed dashboards", func(t *testing.T) {
+		payments.Data.Set("id", payments.Id)
+		payments.Data.Set("refresh", "1m")
+		save(t, models.SaveDashboardCommand{FolderId: folder.Id, Dashboard: payments.Data, Overwrite: true})
+
+		result, err := s.Restore(ctx, RestoreCommand{BackupID: backupID, UserID: admin.ID, DashboardUIDs: []string{"payments"}, Overwrite: true})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Restored)
+
+		dash, err := dashboardStore.GetDashboard(ctx, &models.GetDashboardQuery{OrgId: orgID, Uid: "payments"})
+		require.NoError(t, err)
+		assert.Equal(t, "10s", dash.Data.Get("refresh").MustString())
+		assert.Equal(t, folder.Id, dash.FolderId)
+	})
+
+	t.Run("restore recreates deleted dashboards and folders", func(t *testing.T) {
+		require.NoError(t, dashboardStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: orgID}))
+
+		result, err := s.Restore(ctx, RestoreCommand{BackupID: backupID, UserID: admin.ID, OrgID: orgID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Restored)
+		assert.Len(t, result.Skipped, 1)
+
+		restoredFolder, err := dashboardStore.GetFolderByUID(ctx, orgID, "prod")
+		require.NoError(t, err)
+		dash, err := dashboardStore.GetDashboard(ctx, &models.GetDashboardQuery{OrgId: orgID, Uid: "payments"})
+		require.NoError(t, err)
+		assert.Equal(t, restoredFolder.ID, dash.FolderId)
+	})
+
+	t.Run("restore fails corrupted dashboards", func(t *testing.T) {
+		path := filepath.Join(root, "grafana", "20221128T020000Z", "org-1", "general", "home.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"uid":"home","title":"Tampered"}`), 0600))
+
+		result, err := s.Restore(ctx, RestoreCommand{BackupID: backupID, UserID: admin.ID, DashboardUIDs: []string{"home"}, Overwrite: true})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Restored)
+		require.Len(t, result.Failed, 1)
+		assert.Equal(t, "home", result.Failed[0].UID)
+	})
+
+	t.Run("restore of unknown backup", func(t *testing.T) {
+		_, err := s.Restore(ctx, RestoreCommand{BackupID: "20200101T000000Z"})
+		assert.ErrorIs(t, err, ErrBackupNotFound)
+		_, err = s.GetBackup(ctx, "../manifests")
+		assert.ErrorIs(t, err, ErrBackupNotFound)
+	})
+
+	t.Run("backups past their retention are deleted", func(t *testing.T) {
+		now = now.Add(8 * 24 * time.Hour)
+		require.NoError(t, s.backupIfDue(ctx))
+
+		backups, err := s.ListBackups(ctx)
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		assert.NotEqual(t, backupID, backups[0].ID)
+		assert.NoDirExists(t, filepath.Join(root, "grafana", backupID))
+	})
+}
@@ -0,0 +1,246 @@
+package dashboardbackup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// dashboardRow is a dashboard together with the uid and title of its folder.
+type dashboardRow struct {
+	ID          int64  `xorm:"id"`
+	OrgID       int64  `xorm:"org_id"`
+	UID         string `xorm:"uid"`
+	Title       string `xorm:"title"`
+	Version     int    `xorm:"version"`
+	Data        string `xorm:"data"`
+	FolderUID   string `xorm:"folder_uid"`
+	FolderTitle string `xorm:"folder_title"`
+}
+
+func (s *BackupService) Backup(ctx context.Context) (*Manifest, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBackupInProgress
+	}
+	defer s.running.Unlock()
+
+	b, err := s.getBucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := s.now()
+	created := start.UTC().Truncate(time.Second)
+	manifest := &Manifest{
+		ID:             created.Format(idLayout),
+		GrafanaVersion: setting.BuildVersion,
+		Created:        created,
+		Dashboards:     []*Entry{},
+	}
+
+	var lastID int64
+	for {
+		rows, err := s.getDashboards(ctx, lastID)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			entry, err := s.exportDashboard(ctx, b, manifest.ID, row)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export dashboard %s of organization %d: %w", row.UID, row.OrgID, err)
+			}
+			manifest.Dashboards = append(manifest.Dashboards, entry)
+			lastID = row.ID
+		}
+		if len(rows) < pageSize {
+			break
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Put(ctx, manifestKey(s.settings.prefix, manifest.ID), data); err != nil {
+		return nil, err
+	}
+	s.log.Info("Dashboards backed up", "backup", manifest.ID, "dashboards", len(manifest.Dashboards), "duration", s.now().Sub(start))
+
+	if err := s.deleteExpired(ctx, b); err != nil {
+		s.log.Error("Failed to delete expired dashboard backups", "error", err)
+	}
+	return manifest, nil
+}
+
+func (s *BackupService) exportDashboard(ctx context.Context, b bucket, backupID string, row *dashboardRow) (*Entry, error) {
+	// the dashboard JSON is indented so backups can be read and diffed as they are
+	var dashboard interface{}
+	if err := json.Unmarshal([]byte(row.Data), &dashboard); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	folder := "general"
+	if row.FolderUID != "" {
+		folder = models.SlugifyTitle(row.FolderTitle)
+	}
+	entry := &Entry{
+		OrgID:       row.OrgID,
+		UID:         row.UID,
+		Title:       row.Title,
+		Version:     row.Version,
+		FolderUID:   row.FolderUID,
+		FolderTitle: row.FolderTitle,
+		Key:         fmt.Sprintf("%s/org-%d/%s/%s.json", backupID, row.OrgID, folder, row.UID),
+		SHA256:      checksum(data),
+	}
+	if err := b.Put(ctx, s.settings.prefix+entry.Key, data); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// getDashboards returns the next page of dashboards, ordered by id, of all organizations.
+func (s *BackupService) getDashboards(ctx context.Context, afterID int64) ([]*dashboardRow, error) {
+	var rows []*dashboardRow
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "SELECT d.id, d.org_id, d.uid, d.title, d.version, d.data," +
+			" COALESCE(f.uid, '') AS folder_uid, COALESCE(f.title, '') AS folder_title" +
+			" FROM dashboard AS d LEFT JOIN dashboard AS f ON f.id = d.folder_id AND f.org_id = d.org_id" +
+			" WHERE d.is_folder = ? AND d.id > ? ORDER BY d.id " + s.store.GetDialect().Limit(pageSize)
+		return sess.SQL(rawSQL, s.store.GetDialect().BooleanStr(false), afterID).Find(&rows)
+	})
+	return rows, err
+}
+
+func (s *BackupService) ListBackups(ctx context.Context) ([]*Summary, error) {
+	b, err := s.getBucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := listBackupIDs(ctx, b, s.settings.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Summary, 0, len(ids))
+	for _, id := range ids {
+		manifest, err := s.getManifest(ctx, b, id)
+		if err != nil {
+			// the backup was deleted in the meantime
+			if errors.Is(err, ErrBackupNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, manifest.summary())
+	}
+	return result, nil
+}
+
+func (s *BackupService) GetBackup(ctx context.Context, id string) (*Manifest, error) {
+	b, err := s.getBucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.getManifest(ctx, b, id)
+}
+
+func (s *BackupService) getManifest(ctx context.Context, b bucket, id string) (*Manifest, error) {
+	if _, err := time.Parse(idLayout, id); err != nil {
+		return nil, ErrBackupNotFound
+	}
+	data, err := b.Get(ctx, manifestKey(s.settings.prefix, id))
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return nil, ErrBackupNotFound
+		}
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// deleteExpired deletes the backups older than the retention, except for the latest backup.
+func (s *BackupService) deleteExpired(ctx context.Context, b bucket) error {
+	if s.settings.retention <= 0 {
+		return nil
+	}
+	ids, err := listBackupIDs(ctx, b, s.settings.prefix)
+	if err != nil {
+		return err
+	}
+
+	threshold := s.now().Add(-s.settings.retention)
+	for i, id := range ids {
+		created, err := time.Parse(idLayout, id)
+		if i == 0 || err != nil || !created.Before(threshold) {
+			continue
+		}
+		if err := s.deleteBackup(ctx, b, id); err != nil {
+			return err
+		}
+		s.log.Info("Deleted expired dashboard backup", "backup", id)
+	}
+	return nil
+}
+
+// deleteBackup deletes the manifest first, so that a partially deleted backup is never listed.
+func (s *BackupService) deleteBackup(ctx context.Context, b bucket, id string) error {
+	if err := b.Delete(ctx, manifestKey(s.settings.prefix, id)); err != nil {
+		return err
+	}
+	keys, err := b.List(ctx, s.settings.prefix+id+"/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := b.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listBackupIDs returns the ids of the complete backups, newest first.
+func listBackupIDs(ctx context.Context, b bucket, prefix string) ([]string, error) {
+	keys, err := b.List(ctx, prefix+manifestsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		id := strings.TrimSuffix(strings.TrimPrefix(key, prefix+manifestsPrefix), ".json")
+		if _, err := time.Parse(idLayout, id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	// ids are timestamps which sort lexically
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+func manifestKey(prefix, id string) string {
+	return prefix + manifestsPrefix + id + ".json"
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+package dashboardbackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func (s *BackupService) Restore(ctx context.Context, cmd RestoreCommand) (*RestoreResult, error) {
+	b, err := s.getBucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := s.getManifest(ctx, b, cmd.BackupID)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := s.getOrgIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make(map[string]bool, len(cmd.DashboardUIDs))
+	for _, uid := range cmd.DashboardUIDs {
+		uids[uid] = true
+	}
+
+	result := &RestoreResult{Skipped: []*RestoreIssue{}, Failed: []*RestoreIssue{}}
+	// folders maps the uids of the folders of the backup to the ids of the restored folders, by organization
+	folders := map[int64]map[string]int64{}
+	for _, entry := range manifest.Dashboards {
+		if cmd.OrgID != 0 && entry.OrgID != cmd.OrgID {
+			continue
+		}
+		if len(uids) > 0 && !uids[entry.UID] {
+			continue
+		}
+		issue := &RestoreIssue{OrgID: entry.OrgID, UID: entry.UID, Title: entry.Title}
+		if !orgs[entry.OrgID] {
+			issue.Reason = "organization does not exist"
+			result.Failed = append(result.Failed, issue)
+			continue
+		}
+
+		if folders[entry.OrgID] == nil {
+			folders[entry.OrgID] = map[string]int64{}
+		}
+		folderID, ok := folders[entry.OrgID][entry.FolderUID]
+		if !ok {
+			folderID, err = s.restoreFolder(ctx, entry, cmd.UserID)
+			if err != nil {
+				issue.Reason = fmt.Sprintf("failed to restore folder %q: %s", entry.FolderTitle, err)
+				result.Failed = append(result.Failed, issue)
+				continue
+			}
+			folders[entry.OrgID][entry.FolderUID] = folderID
+		}
+
+		restored, err := s.restoreDashboard(ctx, b, manifest.ID, entry, folderID, cmd)
+		switch {
+		case err != nil:
+			issue.Reason = err.Error()
+			result.Failed = append(result.Failed, issue)
+		case !restored:
+			issue.Reason = "dashboard exists"
+			result.Skipped = append(result.Skipped, issue)
+		default:
+			result.Restored++
+		}
+	}
+
+	s.log.Info("Dashboards restored from backup", "backup", manifest.ID, "restored", result.Restored,
+		"skipped", len(result.Skipped), "failed", len(result.Failed))
+	return result, nil
+}
+
+// restoreDashboard returns false if the dashboard exists and is not overwritten.
+func (s *BackupService) restoreDashboard(ctx context.Context, b bucket, backupID string, entry *Entry, folderID int64, cmd RestoreCommand) (bool, error) {
+	data, err := b.Get(ctx, s.settings.prefix+entry.Key)
+	if err != nil {
+		return false, err
+	}
+	if checksum(data) != entry.SHA256 {
+		return false, errors.New("the dashboard in the backup is corrupted")
+	}
+	dashboard, err := simplejson.NewJson(data)
+	if err != nil {
+		return false, err
+	}
+
+	// ids differ between instances, existing dashboards are found by uid
+	dashboard.Del("id")
+	dash := models.NewDashboardFromJson(dashboard)
+	dash.OrgId = entry.OrgID
+	dash.FolderId = folderID
+
+	if _, err := s.dashboardStore.ValidateDashboardBeforeSave(ctx, dash, cmd.Overwrite); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardWithSameUIDExists) || errors.Is(err, dashboards.ErrDashboardWithSameNameInFolderExists) {
+			return false, nil
+		}
+		return false, err
+	}
+	// validation sets the id of the existing dashboard with the same uid or title
+	if dash.Id != 0 && !cmd.Overwrite {
+		return false, nil
+	}
+
+	_, err = s.dashboardStore.SaveDashboard(ctx, models.SaveDashboardCommand{
+		Dashboard: dash.Data,
+		OrgId:     entry.OrgID,
+		FolderId:  folderID,
+		UserId:    cmd.UserID,
+		// the version of the backup is older than the version of an existing dashboard
+		Overwrite: true,
+		Message:   fmt.Sprintf("Restored from backup %s", backupID),
+	})
+	return err == nil, err
+}
+
+// restoreFolder returns the id of the folder of a dashboard, which is found by uid or title, or
+// created if it does not exist.
+func (s *BackupService) restoreFolder(ctx context.Context, entry *Entry, userID int64) (int64, error) {
+	if entry.FolderUID == "" {
+		return 0, nil
+	}
+
+	folder, err := s.dashboardStore.GetFolderByUID(ctx, entry.OrgID, entry.FolderUID)
+	if err == nil {
+		return folder.ID, nil
+	}
+	if !errors.Is(err, dashboards.ErrFolderNotFound) {
+		return 0, err
+	}
+	folder, err = s.dashboardStore.GetFolderByTitle(ctx, entry.OrgID, entry.FolderTitle)
+	if err == nil {
+		return folder.ID, nil
+	}
+	if !errors.Is(err, dashboards.ErrFolderNotFound) {
+		return 0, err
+	}
+
+	created, err := s.dashboardStore.SaveDashboard(ctx, models.SaveDashboardCommand{
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   entry.FolderUID,
+			"title": entry.FolderTitle,
+		}),
+		OrgId:    entry.OrgID,
+		UserId:   userID,
+		IsFolder: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created.Id, nil
+}
+
+func (s *BackupService) getOrgIDs(ctx context.Context) (map[int64]bool, error) {
+	result := map[int64]bool{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var ids []int64
+		if err := sess.Table("org").Cols("id").Find(&ids); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			result[id] = true
+		}
+		return nil
+	})
+	return result, err
+}
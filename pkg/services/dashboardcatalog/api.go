@@ -0,0 +1,231 @@
+package dashboardcatalog
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *CatalogService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/dashboards/catalog", func(entities routing.RouteRegister) {
+		entities.Get("/", middleware.ReqOrgAdmin, routing.Wrap(s.getEntriesHandler))
+		entities.Post("/", middleware.ReqGrafanaAdmin, routing.Wrap(s.publishHandler))
+		entities.Delete("/:uid", middleware.ReqGrafanaAdmin, routing.Wrap(s.unpublishHandler))
+		entities.Post("/:uid/subscribe", middleware.ReqOrgAdmin, routing.Wrap(s.subscribeHandler))
+		entities.Get("/subscriptions", middleware.ReqOrgAdmin, routing.Wrap(s.getSubscriptionsHandler))
+		entities.Delete("/subscriptions/:id", middleware.ReqOrgAdmin, routing.Wrap(s.unsubscribeHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route GET /dashboards/catalog dashboard_catalog getCatalogDashboards
+//
+// Get the dashboards published to the catalog.
+//
+// Returns the dashboards all organizations published, which the current organization can subscribe to.
+//
+// Responses:
+// 200: catalogEntriesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *CatalogService) getEntriesHandler(c *models.ReqContext) response.Response {
+	result, err := s.GetEntries(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get catalog dashboards", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /dashboards/catalog dashboard_catalog publishCatalogDashboard
+//
+// Publish a dashboard of the current organization to the catalog.
+//
+// Other organizations can subscribe to published dashboards to get a read-only copy which is kept up to date.
+//
+// Responses:
+// 200: catalogEntryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *CatalogService) publishHandler(c *models.ReqContext) response.Response {
+	cmd := PublishCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.DashboardUID == "" {
+		return response.Error(http.StatusBadRequest, "dashboardUid is required", nil)
+	}
+
+	entry, err := s.Publish(c.Req.Context(), c.OrgID, c.UserID, cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to publish dashboard")
+	}
+	return response.JSON(http.StatusOK, entry)
+}
+
+// swagger:route DELETE /dashboards/catalog/{entry_uid} dashboard_catalog unpublishCatalogDashboard
+//
+// Remove a dashboard of the current organization from the catalog.
+//
+// The copies in the subscribed organizations are kept as regular dashboards, which are no longer updated.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CatalogService) unpublishHandler(c *models.ReqContext) response.Response {
+	if err := s.Unpublish(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err, "Failed to unpublish dashboard")
+	}
+	return response.Success("Dashboard removed from the catalog")
+}
+
+// swagger:route POST /dashboards/catalog/{entry_uid}/subscribe dashboard_catalog subscribeCatalogDashboard
+//
+// Subscribe the current organization to a catalog dashboard.
+//
+// Creates a read-only copy of the dashboard, which is updated when the published dashboard changes.
+//
+// Responses:
+// 200: catalogSubscriptionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *CatalogService) subscribeHandler(c *models.ReqContext) response.Response {
+	cmd := SubscribeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	sub, err := s.Subscribe(c.Req.Context(), c.OrgID, c.UserID, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to subscribe to dashboard")
+	}
+	return response.JSON(http.StatusOK, sub)
+}
+
+// swagger:route GET /dashboards/catalog/subscriptions dashboard_catalog getCatalogSubscriptions
+//
+// Get the catalog subscriptions of the current organization.
+//
+// Responses:
+// 200: catalogSubscriptionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *CatalogService) getSubscriptionsHandler(c *models.ReqContext) response.Response {
+	result, err := s.GetSubscriptions(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get catalog subscriptions", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /dashboards/catalog/subscriptions/{subscription_id} dashboard_catalog unsubscribeCatalogDashboard
+//
+// Unsubscribe the current organization from a catalog dashboard.
+//
+// The copy is kept as a regular dashboard, which is no longer updated.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CatalogService) unsubscribeHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := s.Unsubscribe(c.Req.Context(), c.OrgID, id); err != nil {
+		return errorResponse(err, "Failed to unsubscribe from dashboard")
+	}
+	return response.Success("Unsubscribed from dashboard")
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrEntryNotFound), errors.Is(err, ErrSubscriptionNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, dashboards.ErrDashboardNotFound), errors.Is(err, dashboards.ErrFolderNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrAlreadyPublished), errors.Is(err, ErrAlreadySubscribed):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidSubscription):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters publishCatalogDashboard
+type PublishCatalogDashboardParams struct {
+	// in:body
+	// required:true
+	Body PublishCommand `json:"body"`
+}
+
+// swagger:parameters unpublishCatalogDashboard
+type CatalogEntryUIDParams struct {
+	// in:path
+	// required:true
+	EntryUID string `json:"entry_uid"`
+}
+
+// swagger:parameters subscribeCatalogDashboard
+type SubscribeCatalogDashboardParams struct {
+	// in:path
+	// required:true
+	EntryUID string `json:"entry_uid"`
+	// in:body
+	// required:true
+	Body SubscribeCommand `json:"body"`
+}
+
+// swagger:parameters unsubscribeCatalogDashboard
+type CatalogSubscriptionIDParams struct {
+	// in:path
+	// required:true
+	SubscriptionID int64 `json:"subscription_id"`
+}
+
+// swagger:response catalogEntriesResponse
+type CatalogEntriesResponse struct {
+	// in:body
+	Body []*Entry `json:"body"`
+}
+
+// swagger:response catalogEntryResponse
+type CatalogEntryResponse struct {
+	// in:body
+	Body *Entry `json:"body"`
+}
+
+// swagger:response catalogSubscriptionsResponse
+type CatalogSubscriptionsResponse struct {
+	// in:body
+	Body []*Subscription `json:"body"`
+}
+
+// swagger:response catalogSubscriptionResponse
+type CatalogSubscriptionResponse struct {
+	// in:body
+	Body *Subscription `json:"body"`
+}
@@ -0,0 +1,400 @@
+package dashboardcatalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const syncInterval = time.Minute
+
+var (
+	ErrEntryNotFound        = errors.New("catalog entry not found")
+	ErrAlreadyPublished     = errors.New("dashboard is already published to the catalog")
+	ErrSubscriptionNotFound = errors.New("catalog subscription not found")
+	ErrAlreadySubscribed    = errors.New("organization is already subscribed to the catalog entry")
+	ErrInvalidSubscription  = errors.New("invalid catalog subscription")
+)
+
+// Entry is a dashboard an organization published to the catalog shared by all organizations.
+// swagger:model DashboardCatalogEntry
+type Entry struct {
+	ID  int64  `json:"-" xorm:"pk autoincr 'id'"`
+	UID string `json:"uid" xorm:"uid"`
+	// OrgID is the organization publishing the dashboard.
+	OrgID        int64  `json:"orgId" xorm:"org_id"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	Title        string `json:"title" xorm:"title"`
+	Description  string `json:"description" xorm:"description"`
+	// Version is the version of the published dashboard the subscriptions are synced to.
+	Version     int       `json:"version" xorm:"'version'"`
+	PublishedBy int64     `json:"publishedBy" xorm:"published_by"`
+	Created     time.Time `json:"created" xorm:"created"`
+	Updated     time.Time `json:"updated" xorm:"updated"`
+}
+
+func (e Entry) TableName() string {
+	return "dashboard_catalog_entry"
+}
+
+// Subscription keeps a read-only copy of a catalog entry in the subscribing organization.
+// swagger:model DashboardCatalogSubscription
+type Subscription struct {
+	ID       int64  `json:"id" xorm:"pk autoincr 'id'"`
+	EntryUID string `json:"entryUid" xorm:"entry_uid"`
+	// OrgID is the subscribing organization.
+	OrgID int64 `json:"orgId" xorm:"org_id"`
+	// DashboardUID is the uid of the copy in the subscribing organization.
+	DashboardUID  string     `json:"dashboardUid" xorm:"dashboard_uid"`
+	FolderID      int64      `json:"folderId" xorm:"folder_id"`
+	SyncedVersion int        `json:"syncedVersion" xorm:"synced_version"`
+	LastSynced    *time.Time `json:"lastSynced,omitempty" xorm:"last_synced"`
+	LastError     string     `json:"lastError,omitempty" xorm:"last_error"`
+	CreatedBy     int64      `json:"createdBy" xorm:"created_by"`
+	Created       time.Time  `json:"created" xorm:"created"`
+}
+
+func (s Subscription) TableName() string {
+	return "dashboard_catalog_subscription"
+}
+
+// PublishCommand publishes a dashboard of the current organization.
+// swagger:model PublishCatalogDashboardCommand
+type PublishCommand struct {
+	// required:true
+	DashboardUID string `json:"dashboardUid"`
+	Description  string `json:"description"`
+}
+
+// SubscribeCommand subscribes the current organization to a catalog entry.
+// swagger:model SubscribeCatalogDashboardCommand
+type SubscribeCommand struct {
+	// FolderUID is the folder the copy is created in, the General folder if it is empty.
+	FolderUID string `json:"folderUid"`
+}
+
+type Service interface {
+	// GetSubscriptions returns the subscriptions of an organization.
+	GetSubscriptions(ctx context.Context, orgID int64) ([]*Subscription, error)
+}
+
+type CatalogService struct {
+	store               db.DB
+	log                 log.Logger
+	routeRegister       routing.RouteRegister
+	dashboardService    dashboards.DashboardService
+	provisioningService dashboards.DashboardProvisioningService
+}
+
+func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister, dashboardService dashboards.DashboardService,
+	provisioningService dashboards.DashboardProvisioningService) *CatalogService {
+	s := &CatalogService{
+		store:               sqlStore,
+		log:                 log.New("dashboard-catalog"),
+		routeRegister:       routeRegister,
+		dashboardService:    dashboardService,
+		provisioningService: provisioningService,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// GetEntries returns all catalog entries ordered by title.
+func (s *CatalogService) GetEntries(ctx context.Context) ([]*Entry, error) {
+	return s.listEntries(ctx)
+}
+
+// Publish adds a dashboard of the organization to the catalog.
+func (s *CatalogService) Publish(ctx context.Context, orgID, userID int64, cmd PublishCommand) (*Entry, error) {
+	dash, err := s.getDashboard(ctx, orgID, cmd.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	if dash.IsFolder {
+		return nil, dashboards.ErrDashboardNotFound
+	}
+
+	now := time.Now()
+	entry := &Entry{
+		UID:          util.GenerateShortUID(),
+		OrgID:        orgID,
+		DashboardUID: dash.Uid,
+		Title:        dash.Title,
+		Description:  cmd.Description,
+		Version:      dash.Version,
+		PublishedBy:  userID,
+		Created:      now,
+		Updated:      now,
+	}
+	if err := s.insertEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Unpublish removes an entry of the organization from the catalog. The copies in the subscribed
+// organizations are kept as regular dashboards which are no longer updated.
+func (s *CatalogService) Unpublish(ctx context.Context, orgID int64, uid string) error {
+	entry, err := s.getEntry(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if entry.OrgID != orgID {
+		return ErrEntryNotFound
+	}
+	return s.unpublish(ctx, entry)
+}
+
+// Subscribe creates a copy of a catalog entry in the organization, which is kept up to date with
+// the published dashboard.
+func (s *CatalogService) Subscribe(ctx context.Context, orgID, userID int64, entryUID string, cmd SubscribeCommand) (*Subscription, error) {
+	entry, err := s.getEntry(ctx, entryUID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.OrgID == orgID {
+		return nil, fmt.Errorf("%w: organization cannot subscribe to its own dashboard", ErrInvalidSubscription)
+	}
+
+	var folderID int64
+	if cmd.FolderUID != "" {
+		folder, err := s.getDashboard(ctx, orgID, cmd.FolderUID)
+		if err != nil {
+			return nil, err
+		}
+		if !folder.IsFolder {
+			return nil, fmt.Errorf("%w: %s is not a folder", ErrInvalidSubscription, cmd.FolderUID)
+		}
+		folderID = folder.Id
+	}
+
+	source, err := s.source(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		EntryUID:     entry.UID,
+		OrgID:        orgID,
+		DashboardUID: util.GenerateShortUID(),
+		FolderID:     folderID,
+		CreatedBy:    userID,
+		Created:      time.Now(),
+	}
+	if err := s.insertSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	if err := s.copy(ctx, sub, source); err != nil {
+		if err := s.deleteSubscription(ctx, sub.ID); err != nil {
+			s.log.Error("Failed to remove subscription", "entry", entry.UID, "orgId", orgID, "error", err)
+		}
+		return nil, err
+	}
+	if err := s.updateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *CatalogService) GetSubscriptions(ctx context.Context, orgID int64) ([]*Subscription, error) {
+	return s.listSubscriptions(ctx, orgID)
+}
+
+// Unsubscribe stops updating the copy of a catalog entry. The copy is kept as a regular dashboard.
+func (s *CatalogService) Unsubscribe(ctx context.Context, orgID, id int64) error {
+	sub, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sub.OrgID != orgID {
+		return ErrSubscriptionNotFound
+	}
+	return s.detach(ctx, sub)
+}
+
+// Run syncs the subscriptions with the published dashboards.
+func (s *CatalogService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.sync(ctx); err != nil {
+				s.log.Error("Failed to sync dashboard catalog subscriptions", "error", err)
+			}
+		}
+	}
+}
+
+// sync updates the copies of the entries whose dashboards changed since the last sync. Entries
+// whose dashboard has been deleted are removed from the catalog, and subscriptions to removed
+// entries are detached.
+func (s *CatalogService) sync(ctx context.Context) error {
+	entries, err := s.listEntries(ctx)
+	if err != nil {
+		return err
+	}
+	subs, err := s.listSubscriptions(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	subsByEntry := make(map[string][]*Subscription, len(entries))
+	for _, sub := range subs {
+		subsByEntry[sub.EntryUID] = append(subsByEntry[sub.EntryUID], sub)
+	}
+
+	for _, entry := range entries {
+		entrySubs := subsByEntry[entry.UID]
+		delete(subsByEntry, entry.UID)
+
+		source, err := s.source(ctx, entry)
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			s.log.Info("Removing catalog entry of deleted dashboard", "entry", entry.UID, "orgId", entry.OrgID, "dashboardUid", entry.DashboardUID)
+			if err := s.unpublish(ctx, entry); err != nil {
+				s.log.Error("Failed to remove catalog entry", "entry", entry.UID, "error", err)
+			}
+			continue
+		}
+		if err != nil {
+			s.log.Error("Failed to get published dashboard", "entry", entry.UID, "error", err)
+			continue
+		}
+
+		if entry.Version != source.Version || entry.Title != source.Title {
+			entry.Version = source.Version
+			entry.Title = source.Title
+			entry.Updated = time.Now()
+			if err := s.updateEntry(ctx, entry); err != nil {
+				s.log.Error("Failed to update catalog entry", "entry", entry.UID, "error", err)
+			}
+		}
+
+		for _, sub := range entrySubs {
+			if sub.SyncedVersion == source.Version && sub.LastError == "" {
+				continue
+			}
+			if err := s.copy(ctx, sub, source); err != nil {
+				s.log.Warn("Failed to sync catalog subscription", "entry", entry.UID, "orgId", sub.OrgID, "error", err)
+				sub.LastError = err.Error()
+			}
+			if err := s.updateSubscription(ctx, sub); err != nil {
+				s.log.Error("Failed to update catalog subscription", "entry", entry.UID, "orgId", sub.OrgID, "error", err)
+			}
+		}
+	}
+
+	// the entries of these subscriptions were removed together with their organization
+	for _, orphans := range subsByEntry {
+		for _, sub := range orphans {
+			if err := s.detach(ctx, sub); err != nil {
+				s.log.Error("Failed to detach catalog subscription", "entry", sub.EntryUID, "orgId", sub.OrgID, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *CatalogService) source(ctx context.Context, entry *Entry) (*models.Dashboard, error) {
+	return s.getDashboard(ctx, entry.OrgID, entry.DashboardUID)
+}
+
+func (s *CatalogService) getDashboard(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
+	query := &models.GetDashboardQuery{Uid: uid, OrgId: orgID}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+// copy saves the published dashboard as the read-only copy of the subscription, and records the
+// synced version in the subscription.
+func (s *CatalogService) copy(ctx context.Context, sub *Subscription, source *models.Dashboard) error {
+	encoded, err := source.Data.Encode()
+	if err != nil {
+		return err
+	}
+	data, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return err
+	}
+	data.Del("id")
+	data.Set("uid", sub.DashboardUID)
+
+	dash := models.NewDashboardFromJson(data)
+	dash.OrgId = sub.OrgID
+	dash.FolderId = sub.FolderID
+
+	existing, err := s.getDashboard(ctx, sub.OrgID, sub.DashboardUID)
+	switch {
+	case err == nil:
+		// keep the copy where the organization moved it to
+		dash.SetId(existing.Id)
+		dash.FolderId = existing.FolderId
+	case !errors.Is(err, dashboards.ErrDashboardNotFound):
+		return err
+	}
+
+	_, err = s.provisioningService.SaveProvisionedDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgId:     sub.OrgID,
+		UpdatedAt: time.Now(),
+		Message:   fmt.Sprintf("Synced version %d from the dashboard catalog", source.Version),
+		Overwrite: true,
+		Dashboard: dash,
+	}, &models.DashboardProvisioning{
+		Name:       dashboards.CatalogProvisionerName,
+		ExternalId: sub.EntryUID,
+		CheckSum:   fmt.Sprint(source.Version),
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sub.SyncedVersion = source.Version
+	sub.LastSynced = &now
+	sub.LastError = ""
+	return nil
+}
+
+// detach removes the subscription and turns its copy into a regular dashboard.
+func (s *CatalogService) detach(ctx context.Context, sub *Subscription) error {
+	existing, err := s.getDashboard(ctx, sub.OrgID, sub.DashboardUID)
+	switch {
+	case err == nil:
+		if err := s.provisioningService.UnprovisionDashboard(ctx, existing.Id); err != nil {
+			return err
+		}
+	case !errors.Is(err, dashboards.ErrDashboardNotFound):
+		return err
+	}
+	return s.deleteSubscription(ctx, sub.ID)
+}
+
+func (s *CatalogService) unpublish(ctx context.Context, entry *Entry) error {
+	subs, err := s.listEntrySubscriptions(ctx, entry.UID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := s.detach(ctx, sub); err != nil {
+			return err
+		}
+	}
+	return s.deleteEntry(ctx, entry.ID)
+}
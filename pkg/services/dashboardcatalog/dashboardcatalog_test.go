@@ -0,0 +1,139 @@
+package dashboardcatalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/database"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/service"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+)
+
+func TestIntegrationDashboardCatalog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore, cfg := db.InitTestDBwithCfg(t)
+	cfg.RBACEnabled = false
+	dashboardStore, err := database.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg), quotatest.New(false, nil))
+	require.NoError(t, err)
+	dashboardService := dashboardservice.ProvideDashboardService(
+		cfg, dashboardStore, alerting.ProvideDashAlertExtractorService(nil, nil, nil),
+		featuremgmt.WithFeatures(), acmock.NewMockedPermissionsService(), acmock.NewMockedPermissionsService(), acmock.New(),
+	)
+	s := ProvideService(sqlStore, routing.NewRouteRegister(), dashboardService, dashboardService)
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+	ctx := context.Background()
+
+	const publisherOrg, subscriberOrg, adminID = 1, 2, 1
+
+	saveDashboard := func(orgID int64, isFolder bool, data map[string]interface{}) *models.Dashboard {
+		t.Helper()
+		dash, err := dashboardStore.SaveDashboard(ctx, models.SaveDashboardCommand{
+			OrgId:     orgID,
+			IsFolder:  isFolder,
+			Overwrite: true,
+			Dashboard: simplejson.NewFromAny(data),
+		})
+		require.NoError(t, err)
+		return dash
+	}
+	golden := saveDashboard(publisherOrg, false, map[string]interface{}{"uid": "golden", "title": "Golden"})
+	folder := saveDashboard(subscriberOrg, true, map[string]interface{}{"uid": "shared", "title": "Shared"})
+
+	copyOf := func(sub *Subscription) *models.Dashboard {
+		t.Helper()
+		dash, err := s.getDashboard(ctx, sub.OrgID, sub.DashboardUID)
+		require.NoError(t, err)
+		return dash
+	}
+	isProvisioned := func(dash *models.Dashboard) bool {
+		t.Helper()
+		_, err := dashboardService.GetProvisionedDashboardDataByDashboardUID(ctx, dash.OrgId, dash.Uid)
+		if errors.Is(err, dashboards.ErrProvisionedDashboardNotFound) {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+
+	entry, err := s.Publish(ctx, publisherOrg, adminID, PublishCommand{DashboardUID: golden.Uid, Description: "The golden dashboard"})
+	require.NoError(t, err)
+	assert.Equal(t, "Golden", entry.Title)
+
+	t.Run("does not publish a dashboard twice", func(t *testing.T) {
+		_, err := s.Publish(ctx, publisherOrg, adminID, PublishCommand{DashboardUID: golden.Uid})
+		require.ErrorIs(t, err, ErrAlreadyPublished)
+		_, err = s.Publish(ctx, publisherOrg, adminID, PublishCommand{DashboardUID: "missing"})
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+	})
+
+	var sub *Subscription
+	t.Run("subscribing creates a read-only copy", func(t *testing.T) {
+		sub, err = s.Subscribe(ctx, subscriberOrg, adminID, entry.UID, SubscribeCommand{FolderUID: "shared"})
+		require.NoError(t, err)
+		assert.Equal(t, golden.Version, sub.SyncedVersion)
+
+		dash := copyOf(sub)
+		assert.Equal(t, "Golden", dash.Title)
+		assert.Equal(t, folder.Id, dash.FolderId)
+		assert.True(t, isProvisioned(dash))
+
+		subs, err := s.GetSubscriptions(ctx, subscriberOrg)
+		require.NoError(t, err)
+		assert.Len(t, subs, 1)
+	})
+
+	t.Run("rejects invalid subscriptions", func(t *testing.T) {
+		_, err := s.Subscribe(ctx, subscriberOrg, adminID, entry.UID, SubscribeCommand{})
+		require.ErrorIs(t, err, ErrAlreadySubscribed)
+		_, err = s.Subscribe(ctx, publisherOrg, adminID, entry.UID, SubscribeCommand{})
+		require.ErrorIs(t, err, ErrInvalidSubscription)
+		_, err = s.Subscribe(ctx, 3, adminID, "missing", SubscribeCommand{})
+		require.ErrorIs(t, err, ErrEntryNotFound)
+	})
+
+	t.Run("sync updates the copies", func(t *testing.T) {
+		golden = saveDashboard(publisherOrg, false, map[string]interface{}{"id": golden.Id, "uid": "golden", "title": "Golden v2"})
+		require.NoError(t, s.sync(ctx))
+
+		assert.Equal(t, "Golden v2", copyOf(sub).Title)
+		entries, err := s.GetEntries(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Golden v2", entries[0].Title)
+		assert.Equal(t, golden.Version, entries[0].Version)
+	})
+
+	t.Run("unsubscribing keeps the copy as a regular dashboard", func(t *testing.T) {
+		require.ErrorIs(t, s.Unsubscribe(ctx, publisherOrg, sub.ID), ErrSubscriptionNotFound)
+		require.NoError(t, s.Unsubscribe(ctx, subscriberOrg, sub.ID))
+		assert.False(t, isProvisioned(copyOf(sub)))
+	})
+
+	t.Run("removes the entries of deleted dashboards", func(t *testing.T) {
+		sub, err = s.Subscribe(ctx, 3, adminID, entry.UID, SubscribeCommand{})
+		require.NoError(t, err)
+		require.NoError(t, dashboardStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: golden.Id, OrgId: publisherOrg}))
+		require.NoError(t, s.sync(ctx))
+
+		entries, err := s.GetEntries(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.False(t, isProvisioned(copyOf(sub)))
+	})
+}
@@ -0,0 +1,122 @@
+package dashboardcatalog
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *CatalogService) insertEntry(ctx context.Context, entry *Entry) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND dashboard_uid = ?", entry.OrgID, entry.DashboardUID).Exist(&Entry{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrAlreadyPublished
+		}
+		_, err = sess.Insert(entry)
+		return err
+	})
+}
+
+func (s *CatalogService) updateEntry(ctx context.Context, entry *Entry) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(entry.ID).Cols("title", "version", "updated").Update(entry)
+		return err
+	})
+}
+
+func (s *CatalogService) getEntry(ctx context.Context, uid string) (*Entry, error) {
+	entry := &Entry{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("uid = ?", uid).Get(entry)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrEntryNotFound
+		}
+		return nil
+	})
+	return entry, err
+}
+
+func (s *CatalogService) listEntries(ctx context.Context) ([]*Entry, error) {
+	result := make([]*Entry, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("title", "id").Find(&result)
+	})
+	return result, err
+}
+
+func (s *CatalogService) deleteEntry(ctx context.Context, id int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Delete(&Entry{})
+		return err
+	})
+}
+
+func (s *CatalogService) insertSubscription(ctx context.Context, sub *Subscription) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("entry_uid = ? AND org_id = ?", sub.EntryUID, sub.OrgID).Exist(&Subscription{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrAlreadySubscribed
+		}
+		_, err = sess.Insert(sub)
+		return err
+	})
+}
+
+func (s *CatalogService) updateSubscription(ctx context.Context, sub *Subscription) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(sub.ID).Cols("synced_version", "last_synced", "last_error").Update(sub)
+		return err
+	})
+}
+
+func (s *CatalogService) getSubscription(ctx context.Context, id int64) (*Subscription, error) {
+	sub := &Subscription{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.ID(id).Get(sub)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrSubscriptionNotFound
+		}
+		return nil
+	})
+	return sub, err
+}
+
+// listSubscriptions returns the subscriptions of an organization, or of all organizations if
+// orgID is 0.
+func (s *CatalogService) listSubscriptions(ctx context.Context, orgID int64) ([]*Subscription, error) {
+	result := make([]*Subscription, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if orgID != 0 {
+			sess.Where("org_id = ?", orgID)
+		}
+		return sess.Asc("id").Find(&result)
+	})
+	return result, err
+}
+
+func (s *CatalogService) listEntrySubscriptions(ctx context.Context, entryUID string) ([]*Subscription, error) {
+	result := make([]*Subscription, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("entry_uid = ?", entryUID).Asc("id").Find(&result)
+	})
+	return result, err
+}
+
+func (s *CatalogService) deleteSubscription(ctx context.Context, id int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Delete(&Subscription{})
+		return err
+	})
+}
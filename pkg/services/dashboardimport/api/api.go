@@ -18,12 +18,12 @@ import (
 
 type ImportDashboardAPI struct {
 	dashboardImportService dashboardimport.Service
-	quotaService           QuotaService
+	quotaService           quota.Service
 	pluginStore            plugins.Store
 	ac                     accesscontrol.AccessControl
 }
 
-func New(dashboardImportService dashboardimport.Service, quotaService QuotaService,
+func New(dashboardImportService dashboardimport.Service, quotaService quota.Service,
 	pluginStore plugins.Store, ac accesscontrol.AccessControl) *ImportDashboardAPI {
 	return &ImportDashboardAPI{
 		dashboardImportService: dashboardImportService,
@@ -35,10 +35,12 @@ func New(dashboardImportService dashboardimport.Service, quotaService QuotaServi
 
 func (api *ImportDashboardAPI) RegisterAPIEndpoints(routeRegister routing.RouteRegister) {
 	authorize := accesscontrol.Middleware(api.ac)
+	quota := middleware.Quota(api.quotaService)
 	routeRegister.Group("/api/dashboards", func(route routing.RouteRegister) {
 		route.Post(
 			"/import",
 			authorize(middleware.ReqSignedIn, accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
+			quota(string(dashboards.QuotaTargetSrv)),
 			routing.Wrap(api.ImportDashboard),
 		)
 	}, middleware.ReqSignedIn)
@@ -65,15 +67,6 @@ func (api *ImportDashboardAPI) ImportDashboard(c *models.ReqContext) response.Re
 		return response.Error(http.StatusUnprocessableEntity, "Dashboard must be set", nil)
 	}
 
-	limitReached, err := api.quotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
-	if err != nil {
-		return response.Err(err)
-	}
-
-	if limitReached {
-		return response.Error(403, "Quota reached", nil)
-	}
-
 	req.User = c.SignedInUser
 	resp, err := api.dashboardImportService.ImportDashboard(c.Req.Context(), &req)
 	if err != nil {
@@ -83,16 +76,6 @@ func (api *ImportDashboardAPI) ImportDashboard(c *models.ReqContext) response.Re
 	return response.JSON(http.StatusOK, resp)
 }
 
-type QuotaService interface {
-	QuotaReached(c *models.ReqContext, target quota.TargetSrv) (bool, error)
-}
-
-type quotaServiceFunc func(c *models.ReqContext, target quota.TargetSrv) (bool, error)
-
-func (fn quotaServiceFunc) QuotaReached(c *models.ReqContext, target quota.TargetSrv) (bool, error) {
-	return fn(c, target)
-}
-
 // swagger:parameters importDashboard
 type ImportDashboardParams struct {
 	// in:body
@@ -0,0 +1,118 @@
+package dashboardlint
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *LintService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/dashboards/lint", func(lint routing.RouteRegister) {
+		lint.Post("/", routing.Wrap(s.lintDashboardHandler))
+		lint.Get("/policy", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(ac.ActionOrgsPreferencesRead)), routing.Wrap(s.getPolicyHandler))
+		lint.Put("/policy", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(s.updatePolicyHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /dashboards/lint dashboards lintDashboard
+//
+// Lint a dashboard.
+//
+// Checks the dashboard JSON for deprecated panels, missing units, too many queries, long default time ranges and template variables selecting all values by default. The dashboard is not saved.
+//
+// Responses:
+// 200: lintDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *LintService) lintDashboardHandler(c *models.ReqContext) response.Response {
+	cmd := LintDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Dashboard == nil {
+		return response.Error(http.StatusBadRequest, "dashboard is required", nil)
+	}
+
+	report, err := s.Lint(c.Req.Context(), c.OrgID, cmd.Dashboard)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to lint dashboard", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route GET /dashboards/lint/policy dashboards getDashboardLintPolicy
+//
+// Get the dashboard lint policy of the current organization.
+//
+// Responses:
+// 200: getDashboardLintPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *LintService) getPolicyHandler(c *models.ReqContext) response.Response {
+	policy, err := s.GetPolicy(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard lint policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /dashboards/lint/policy dashboards updateDashboardLintPolicy
+//
+// Update the dashboard lint policy of the current organization.
+//
+// Responses:
+// 200: getDashboardLintPolicyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *LintService) updatePolicyHandler(c *models.ReqContext) response.Response {
+	policy := Policy{}
+	if err := web.Bind(c.Req, &policy); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetPolicy(c.Req.Context(), c.OrgID, policy); err != nil {
+		if errors.Is(err, ErrInvalidPolicy) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update dashboard lint policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:parameters lintDashboard
+type LintDashboardParams struct {
+	// in:body
+	// required:true
+	Body LintDashboardCommand `json:"body"`
+}
+
+// swagger:parameters updateDashboardLintPolicy
+type UpdateDashboardLintPolicyParams struct {
+	// in:body
+	// required:true
+	Body Policy `json:"body"`
+}
+
+// swagger:response lintDashboardResponse
+type LintDashboardResponse struct {
+	// in:body
+	Body *Report `json:"body"`
+}
+
+// swagger:response getDashboardLintPolicyResponse
+type GetDashboardLintPolicyResponse struct {
+	// in:body
+	Body *Policy `json:"body"`
+}
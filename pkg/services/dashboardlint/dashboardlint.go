@@ -0,0 +1,104 @@
+// Package dashboardlint checks dashboards for common issues and scores them against best practices.
+//
+// Every organization has a policy which configures the thresholds of the rules and can reject
+// saving dashboards that score below a minimum.
+package dashboardlint
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	kvNamespace = "dashboardlint"
+	policyKey   = "policy"
+)
+
+type Service interface {
+	// Lint checks a dashboard against the policy of the organization.
+	Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*Report, error)
+	// CheckOnSave returns a RejectedError if the organization enforces its policy on save and the
+	// dashboard scores below the minimum score.
+	CheckOnSave(ctx context.Context, orgID int64, dashboard *simplejson.Json) error
+	GetPolicy(ctx context.Context, orgID int64) (*Policy, error)
+	SetPolicy(ctx context.Context, orgID int64, policy Policy) error
+}
+
+type LintService struct {
+	kvStore       kvstore.KVStore
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	now           func() time.Time
+}
+
+func ProvideService(kvStore kvstore.KVStore, accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *LintService {
+	s := &LintService{
+		kvStore:       kvStore,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+		now:           time.Now,
+	}
+	s.registerAPIEndpoints()
+	return s
+}
+
+func (s *LintService) Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*Report, error) {
+	policy, err := s.GetPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return Lint(dashboard, *policy, s.now())
+}
+
+func (s *LintService) CheckOnSave(ctx context.Context, orgID int64, dashboard *simplejson.Json) error {
+	policy, err := s.GetPolicy(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !policy.EnforceOnSave {
+		return nil
+	}
+
+	report, err := Lint(dashboard, *policy, s.now())
+	if err != nil {
+		return err
+	}
+	if report.Score < policy.MinScore {
+		return &RejectedError{MinScore: policy.MinScore, Report: report}
+	}
+	return nil
+}
+
+// GetPolicy returns the policy of the organization, or the default policy if it did not set one.
+func (s *LintService) GetPolicy(ctx context.Context, orgID int64) (*Policy, error) {
+	value, exists, err := kvstore.WithNamespace(s.kvStore, orgID, kvNamespace).Get(ctx, policyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := DefaultPolicy
+	if !exists {
+		return &policy, nil
+	}
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (s *LintService) SetPolicy(ctx context.Context, orgID int64, policy Policy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return kvstore.WithNamespace(s.kvStore, orgID, kvNamespace).Set(ctx, policyKey, string(value))
+}
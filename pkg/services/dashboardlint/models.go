@@ -0,0 +1,120 @@
+package dashboardlint
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrInvalidPolicy = errors.New("invalid dashboard lint policy")
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// penalty is the number of points an issue of the severity deducts from the score of a dashboard.
+func (s Severity) penalty() int {
+	switch s {
+	case SeverityError:
+		return 15
+	case SeverityWarning:
+		return 5
+	default:
+		return 1
+	}
+}
+
+// Rules checked by the linter.
+const (
+	RuleDeprecatedPanel         = "deprecated-panel"
+	RuleMissingUnit             = "missing-unit"
+	RuleTooManyPanelQueries     = "too-many-panel-queries"
+	RuleTooManyQueries          = "too-many-queries"
+	RuleUnboundedTimeRange      = "unbounded-time-range"
+	RuleWildcardVariableDefault = "wildcard-variable-default"
+)
+
+// Issue is a problem found in a dashboard together with a suggestion how to fix it.
+// swagger:model DashboardLintIssue
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// PanelID is set for issues of a panel.
+	PanelID    int64  `json:"panelId,omitempty"`
+	PanelTitle string `json:"panelTitle,omitempty"`
+	// Variable is set for issues of a template variable.
+	Variable   string `json:"variable,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// Report is the result of linting a dashboard.
+// swagger:model DashboardLintReport
+type Report struct {
+	// Score from 0 to 100. Every issue deducts points depending on its severity.
+	Score  int      `json:"score"`
+	Issues []*Issue `json:"issues"`
+}
+
+// Policy configures the linter for an organization.
+// swagger:model DashboardLintPolicy
+type Policy struct {
+	// EnforceOnSave rejects saving dashboards which score below MinScore.
+	EnforceOnSave bool `json:"enforceOnSave"`
+	MinScore      int  `json:"minScore"`
+	// MaxQueriesPerPanel is the number of queries of a panel above which it is reported.
+	MaxQueriesPerPanel int `json:"maxQueriesPerPanel"`
+	// MaxQueriesPerDashboard is the number of queries of all panels above which the dashboard is reported.
+	MaxQueriesPerDashboard int `json:"maxQueriesPerDashboard"`
+	// MaxTimeRange is the longest time range dashboards and panels may show by default, e.g. `30d`.
+	MaxTimeRange string `json:"maxTimeRange"`
+}
+
+// DefaultPolicy is used by organizations which did not configure the linter.
+var DefaultPolicy = Policy{
+	EnforceOnSave:          false,
+	MinScore:               50,
+	MaxQueriesPerPanel:     10,
+	MaxQueriesPerDashboard: 100,
+	MaxTimeRange:           "30d",
+}
+
+func (p *Policy) validate() error {
+	if p.MinScore < 0 || p.MinScore > 100 {
+		return fmt.Errorf("%w: minScore must be between 0 and 100", ErrInvalidPolicy)
+	}
+	if p.MaxQueriesPerPanel < 1 || p.MaxQueriesPerDashboard < 1 {
+		return fmt.Errorf("%w: maxQueriesPerPanel and maxQueriesPerDashboard must be positive", ErrInvalidPolicy)
+	}
+	if d, err := gtime.ParseDuration(p.MaxTimeRange); err != nil || d <= 0 {
+		return fmt.Errorf("%w: maxTimeRange must be a positive duration such as 30d", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// LintDashboardCommand is the body of the request linting a dashboard.
+// swagger:model
+type LintDashboardCommand struct {
+	// required:true
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+// RejectedError is returned when saving a dashboard which scores below the minimum score of the
+// organization.
+type RejectedError struct {
+	MinScore int
+	Report   *Report
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("dashboard lint score %d is below the minimum score %d of the organization", e.Report.Score, e.MinScore)
+}
@@ -0,0 +1,238 @@
+package dashboardlint
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+// deprecatedPanels maps deprecated panel types to the panels replacing them.
+var deprecatedPanels = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+// unitPanels are the panel types which show values that should have a unit.
+var unitPanels = map[string]bool{
+	"timeseries": true,
+	"stat":       true,
+	"gauge":      true,
+	"bargauge":   true,
+	"barchart":   true,
+}
+
+// wildcardValues are template variable values which select everything.
+var wildcardValues = map[string]bool{
+	"$__all": true,
+	"*":      true,
+	".*":     true,
+}
+
+type linter struct {
+	policy       Policy
+	maxTimeRange time.Duration
+	now          time.Time
+	issues       []*Issue
+}
+
+// Lint checks a dashboard against the rules of the policy.
+func Lint(dashboard *simplejson.Json, policy Policy, now time.Time) (*Report, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	maxTimeRange, err := gtime.ParseDuration(policy.MaxTimeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{policy: policy, maxTimeRange: maxTimeRange, now: now, issues: make([]*Issue, 0)}
+
+	queries := 0
+	for _, panel := range panels(dashboard) {
+		queries += l.lintPanel(panel)
+	}
+	if queries > policy.MaxQueriesPerDashboard {
+		l.report(&Issue{
+			Rule:       RuleTooManyQueries,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("The dashboard runs %d queries on every load, more than the %d allowed.", queries, policy.MaxQueriesPerDashboard),
+			Suggestion: "Split the dashboard, move rarely used panels into collapsed rows or combine queries.",
+		})
+	}
+
+	l.lintTimeRange(dashboard.GetPath("time", "from").MustString(), dashboard.GetPath("time", "to").MustString())
+
+	for _, variable := range dashboard.GetPath("templating", "list").MustArray() {
+		l.lintVariable(simplejson.NewFromAny(variable))
+	}
+
+	score := 100
+	for _, issue := range l.issues {
+		score -= issue.Severity.penalty()
+	}
+	if score < 0 {
+		score = 0
+	}
+	return &Report{Score: score, Issues: l.issues}, nil
+}
+
+func (l *linter) report(issue *Issue) {
+	l.issues = append(l.issues, issue)
+}
+
+// panels returns the panels of a dashboard, including those nested in collapsed rows.
+func panels(dashboard *simplejson.Json) []*simplejson.Json {
+	var result []*simplejson.Json
+	for _, p := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		if panel.Get("type").MustString() == "row" {
+			for _, nested := range panel.Get("panels").MustArray() {
+				result = append(result, simplejson.NewFromAny(nested))
+			}
+			continue
+		}
+		result = append(result, panel)
+	}
+	return result
+}
+
+// lintPanel checks a panel and returns the number of queries it runs.
+func (l *linter) lintPanel(panel *simplejson.Json) int {
+	id := panel.Get("id").MustInt64()
+	title := panel.Get("title").MustString()
+	panelType := panel.Get("type").MustString()
+
+	if replacement, ok := deprecatedPanels[panelType]; ok {
+		l.report(&Issue{
+			Rule:       RuleDeprecatedPanel,
+			Severity:   SeverityWarning,
+			PanelID:    id,
+			PanelTitle: title,
+			Message:    fmt.Sprintf("The %s panel is deprecated.", panelType),
+			Suggestion: fmt.Sprintf("Migrate the panel to the %s panel.", replacement),
+		})
+	}
+
+	if unitPanels[panelType] && !hasUnit(panel) {
+		l.report(&Issue{
+			Rule:       RuleMissingUnit,
+			Severity:   SeverityInfo,
+			PanelID:    id,
+			PanelTitle: title,
+			Message:    "The panel does not set a unit for its values.",
+			Suggestion: "Set the unit in the standard options of the panel.",
+		})
+	}
+
+	queries := 0
+	for _, t := range panel.Get("targets").MustArray() {
+		if !simplejson.NewFromAny(t).Get("hide").MustBool() {
+			queries++
+		}
+	}
+	if queries > l.policy.MaxQueriesPerPanel {
+		l.report(&Issue{
+			Rule:       RuleTooManyPanelQueries,
+			Severity:   SeverityWarning,
+			PanelID:    id,
+			PanelTitle: title,
+			Message:    fmt.Sprintf("The panel runs %d queries, more than the %d allowed.", queries, l.policy.MaxQueriesPerPanel),
+			Suggestion: "Combine the queries or split the panel.",
+		})
+	}
+
+	if timeFrom := panel.Get("timeFrom").MustString(); timeFrom != "" {
+		if d, err := gtime.ParseInterval(timeFrom); err == nil && d > l.maxTimeRange {
+			l.report(&Issue{
+				Rule:       RuleUnboundedTimeRange,
+				Severity:   SeverityError,
+				PanelID:    id,
+				PanelTitle: title,
+				Message:    fmt.Sprintf("The panel overrides the time range to the last %s, longer than the %s allowed.", timeFrom, l.policy.MaxTimeRange),
+				Suggestion: "Use a shorter relative time for the panel.",
+			})
+		}
+	}
+
+	return queries
+}
+
+func hasUnit(panel *simplejson.Json) bool {
+	if panel.GetPath("fieldConfig", "defaults", "unit").MustString() != "" {
+		return true
+	}
+	for _, o := range panel.GetPath("fieldConfig", "overrides").MustArray() {
+		for _, p := range simplejson.NewFromAny(o).Get("properties").MustArray() {
+			if simplejson.NewFromAny(p).Get("id").MustString() == "unit" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lintTimeRange checks the default time range of the dashboard.
+func (l *linter) lintTimeRange(from, to string) {
+	if from == "" {
+		l.report(&Issue{
+			Rule:       RuleUnboundedTimeRange,
+			Severity:   SeverityError,
+			Message:    "The dashboard does not set a default time range.",
+			Suggestion: fmt.Sprintf("Set a default time range of at most %s.", l.policy.MaxTimeRange),
+		})
+		return
+	}
+	if to == "" {
+		to = "now"
+	}
+
+	tr := legacydata.NewDataTimeRange(from, to)
+	start, err := tr.ParseFrom(legacydata.WithNow(l.now))
+	if err != nil {
+		return
+	}
+	end, err := tr.ParseTo(legacydata.WithNow(l.now))
+	if err != nil {
+		return
+	}
+	if end.Sub(start) > l.maxTimeRange {
+		l.report(&Issue{
+			Rule:       RuleUnboundedTimeRange,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("The default time range from %s to %s is longer than the %s allowed.", from, to, l.policy.MaxTimeRange),
+			Suggestion: fmt.Sprintf("Set a default time range of at most %s.", l.policy.MaxTimeRange),
+		})
+	}
+}
+
+// lintVariable checks that a template variable does not select all values by default.
+func (l *linter) lintVariable(variable *simplejson.Json) {
+	name := variable.Get("name").MustString()
+	current := variable.GetPath("current", "value")
+
+	values := current.MustStringArray()
+	if s, err := current.String(); err == nil {
+		values = []string{s}
+	}
+	for _, v := range values {
+		if !wildcardValues[v] {
+			continue
+		}
+		l.report(&Issue{
+			Rule:       RuleWildcardVariableDefault,
+			Severity:   SeverityWarning,
+			Variable:   name,
+			Message:    fmt.Sprintf("The variable %s selects all values by default, which queries every series.", name),
+			Suggestion: "Select a specific value as the default of the variable.",
+		})
+		return
+	}
+}
@@ -0,0 +1,122 @@
+package dashboardlint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestLint(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	lint := func(t *testing.T, dashboard string, policy Policy) *Report {
+		t.Helper()
+		json, err := simplejson.NewJson([]byte(dashboard))
+		require.NoError(t, err)
+		report, err := Lint(json, policy, now)
+		require.NoError(t, err)
+		return report
+	}
+
+	rules := func(report *Report) []string {
+		var result []string
+		for _, issue := range report.Issues {
+			result = append(result, issue.Rule)
+		}
+		return result
+	}
+
+	t.Run("Should score a clean dashboard 100", func(t *testing.T) {
+		report := lint(t, `{
+			"time": {"from": "now-6h", "to": "now"},
+			"panels": [{"id": 1, "type": "timeseries", "fieldConfig": {"defaults": {"unit": "bytes"}}, "targets": [{"refId": "A"}]}],
+			"templating": {"list": [{"name": "job", "current": {"value": "api"}}]}
+		}`, DefaultPolicy)
+		assert.Equal(t, 100, report.Score)
+		assert.Empty(t, report.Issues)
+	})
+
+	t.Run("Should report deprecated panels and missing units in collapsed rows", func(t *testing.T) {
+		report := lint(t, `{
+			"time": {"from": "now-6h", "to": "now"},
+			"panels": [
+				{"id": 1, "type": "row", "collapsed": true, "panels": [{"id": 2, "type": "graph"}, {"id": 3, "type": "stat"}]},
+				{"id": 4, "type": "stat", "fieldConfig": {"overrides": [{"properties": [{"id": "unit", "value": "s"}]}]}}
+			]
+		}`, DefaultPolicy)
+		assert.Equal(t, []string{RuleDeprecatedPanel, RuleMissingUnit}, rules(report))
+		assert.Equal(t, int64(2), report.Issues[0].PanelID)
+		assert.Equal(t, int64(3), report.Issues[1].PanelID)
+		assert.Equal(t, 94, report.Score)
+	})
+
+	t.Run("Should report panels and dashboards with too many queries", func(t *testing.T) {
+		policy := DefaultPolicy
+		policy.MaxQueriesPerPanel = 2
+		policy.MaxQueriesPerDashboard = 3
+
+		report := lint(t, `{
+			"time": {"from": "now-6h", "to": "now"},
+			"panels": [
+				{"id": 1, "type": "table", "targets": [{"refId": "A"}, {"refId": "B"}, {"refId": "C", "hide": true}]},
+				{"id": 2, "type": "table", "targets": [{"refId": "A"}, {"refId": "B"}, {"refId": "C"}]}
+			]
+		}`, policy)
+		assert.Equal(t, []string{RuleTooManyPanelQueries, RuleTooManyQueries}, rules(report))
+		assert.Equal(t, int64(2), report.Issues[0].PanelID)
+	})
+
+	t.Run("Should report long and missing time ranges", func(t *testing.T) {
+		report := lint(t, `{
+			"time": {"from": "now-1y", "to": "now"},
+			"panels": [{"id": 1, "type": "table", "timeFrom": "90d"}, {"id": 2, "type": "table", "timeFrom": "1h"}]
+		}`, DefaultPolicy)
+		assert.Equal(t, []string{RuleUnboundedTimeRange, RuleUnboundedTimeRange}, rules(report))
+		assert.Equal(t, int64(1), report.Issues[0].PanelID)
+		assert.Equal(t, int64(0), report.Issues[1].PanelID)
+
+		report = lint(t, `{"panels": []}`, DefaultPolicy)
+		assert.Equal(t, []string{RuleUnboundedTimeRange}, rules(report))
+	})
+
+	t.Run("Should report variables selecting all values by default", func(t *testing.T) {
+		report := lint(t, `{
+			"time": {"from": "now-6h", "to": "now"},
+			"templating": {"list": [
+				{"name": "job", "current": {"value": "$__all"}},
+				{"name": "instance", "current": {"value": ["a", "*"]}},
+				{"name": "env", "current": {"value": ["prod"]}}
+			]}
+		}`, DefaultPolicy)
+		assert.Equal(t, []string{RuleWildcardVariableDefault, RuleWildcardVariableDefault}, rules(report))
+		assert.Equal(t, "job", report.Issues[0].Variable)
+		assert.Equal(t, "instance", report.Issues[1].Variable)
+	})
+
+	t.Run("Should not score below 0", func(t *testing.T) {
+		policy := DefaultPolicy
+		policy.MaxTimeRange = "1h"
+		report := lint(t, `{
+			"time": {"from": "now-6h", "to": "now"},
+			"panels": [
+				{"id": 1, "type": "table", "timeFrom": "2h"}, {"id": 2, "type": "table", "timeFrom": "2h"},
+				{"id": 3, "type": "table", "timeFrom": "2h"}, {"id": 4, "type": "table", "timeFrom": "2h"},
+				{"id": 5, "type": "table", "timeFrom": "2h"}, {"id": 6, "type": "table", "timeFrom": "2h"},
+				{"id": 7, "type": "table", "timeFrom": "2h"}
+			]
+		}`, policy)
+		assert.Len(t, report.Issues, 8)
+		assert.Equal(t, 0, report.Score)
+	})
+
+	t.Run("Should reject invalid policies", func(t *testing.T) {
+		policy := DefaultPolicy
+		policy.MaxTimeRange = "forever"
+		_, err := Lint(simplejson.New(), policy, now)
+		require.ErrorIs(t, err, ErrInvalidPolicy)
+	})
+}
@@ -0,0 +1,488 @@
+package dashboardreview
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *ReviewService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	folderScope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.routeRegister.Group("/api/folders/:uid/review-policy", func(policyRoute routing.RouteRegister) {
+		policyRoute.Get("/", authorize(middleware.ReqSignedIn, ac.EvalPermission(dashboards.ActionFoldersRead, folderScope)), routing.Wrap(s.getPolicyHandler))
+		policyRoute.Put("/", authorize(middleware.ReqSignedIn, ac.EvalPermission(dashboards.ActionFoldersPermissionsWrite, folderScope)), routing.Wrap(s.updatePolicyHandler))
+		policyRoute.Delete("/", authorize(middleware.ReqSignedIn, ac.EvalPermission(dashboards.ActionFoldersPermissionsWrite, folderScope)), routing.Wrap(s.deletePolicyHandler))
+	})
+
+	s.routeRegister.Group("/api/dashboards/drafts", func(draftRoute routing.RouteRegister) {
+		draftRoute.Get("/", routing.Wrap(s.listDraftsHandler))
+		draftRoute.Post("/", routing.Wrap(s.submitDraftHandler))
+		draftRoute.Get("/:uid", routing.Wrap(s.getDraftHandler))
+		draftRoute.Delete("/:uid", routing.Wrap(s.withdrawDraftHandler))
+		draftRoute.Get("/:uid/diff", routing.Wrap(s.diffDraftHandler))
+		draftRoute.Post("/:uid/approve", routing.Wrap(s.reviewHandler(DecisionApprove)))
+		draftRoute.Post("/:uid/reject", routing.Wrap(s.reviewHandler(DecisionReject)))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route GET /folders/{folder_uid}/review-policy folders getFolderReviewPolicy
+//
+// Get the review policy of a folder.
+//
+// Responses:
+// 200: getFolderReviewPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReviewService) getPolicyHandler(c *models.ReqContext) response.Response {
+	policy, err := s.GetPolicy(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return policyErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /folders/{folder_uid}/review-policy folders updateFolderReviewPolicy
+//
+// Create or replace the review policy of a folder.
+//
+// Once a folder has a review policy, changes to its dashboards can no longer be saved directly. They have to be submitted as drafts, which become the live version of the dashboard once the required number of reviewers approved them.
+//
+// Responses:
+// 200: getFolderReviewPolicyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReviewService) updatePolicyHandler(c *models.ReqContext) response.Response {
+	cmd := UpdatePolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.FolderUID = web.Params(c.Req)[":uid"]
+
+	if _, err := s.getFolder(c.Req.Context(), c.OrgID, cmd.FolderUID); err != nil {
+		return policyErrorResponse(err)
+	}
+	policy, err := s.UpdatePolicy(c.Req.Context(), cmd)
+	if err != nil {
+		return policyErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route DELETE /folders/{folder_uid}/review-policy folders deleteFolderReviewPolicy
+//
+// Delete the review policy of a folder.
+//
+// Pending drafts of the dashboards in the folder can no longer be approved, and changes are saved directly again.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReviewService) deletePolicyHandler(c *models.ReqContext) response.Response {
+	if err := s.DeletePolicy(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return policyErrorResponse(err)
+	}
+	return response.Success("Review policy deleted")
+}
+
+// swagger:route GET /dashboards/drafts dashboards listDashboardDrafts
+//
+// List dashboard drafts.
+//
+// Returns the drafts the signed in user submitted or can review, newest first, without their dashboard JSON.
+//
+// Responses:
+// 200: listDashboardDraftsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *ReviewService) listDraftsHandler(c *models.ReqContext) response.Response {
+	query := ListDraftsQuery{
+		OrgID:        c.OrgID,
+		Status:       Status(c.Query("status")),
+		DashboardUID: c.Query("dashboardUid"),
+		Limit:        c.QueryInt("limit"),
+	}
+	drafts, err := s.ListDrafts(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list dashboard drafts", err)
+	}
+
+	result := make([]*Draft, 0, len(drafts))
+	for _, draft := range drafts {
+		canView, err := s.canViewDraft(c, draft)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to list dashboard drafts", err)
+		}
+		if canView {
+			result = append(result, draft)
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /dashboards/drafts dashboards submitDashboardDraft
+//
+// Submit a dashboard draft for review.
+//
+// Changes to dashboards in folders with a review policy have to be submitted as drafts. The reviewers of the folder are notified.
+//
+// Responses:
+// 200: submitDashboardDraftResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *ReviewService) submitDraftHandler(c *models.ReqContext) response.Response {
+	cmd := SubmitDraftCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Dashboard == nil {
+		return response.Error(http.StatusBadRequest, "dashboard is required", nil)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.User = c.SignedInUser
+
+	if rsp := s.checkCanSave(c, cmd); rsp != nil {
+		return rsp
+	}
+	draft, err := s.SubmitDraft(c.Req.Context(), cmd)
+	if err != nil {
+		return draftErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, draft)
+}
+
+// swagger:route GET /dashboards/drafts/{draft_uid} dashboards getDashboardDraft
+//
+// Get a dashboard draft together with its reviews.
+//
+// Responses:
+// 200: getDashboardDraftResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReviewService) getDraftHandler(c *models.ReqContext) response.Response {
+	dto, err := s.GetDraft(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return draftErrorResponse(err)
+	}
+	if rsp := s.checkCanView(c, dto.Draft); rsp != nil {
+		return rsp
+	}
+	return response.JSON(http.StatusOK, dto)
+}
+
+// swagger:route DELETE /dashboards/drafts/{draft_uid} dashboards withdrawDashboardDraft
+//
+// Withdraw a pending dashboard draft.
+//
+// Only the author of a draft can withdraw it.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *ReviewService) withdrawDraftHandler(c *models.ReqContext) response.Response {
+	if err := s.Withdraw(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"], c.UserID); err != nil {
+		return draftErrorResponse(err)
+	}
+	return response.Success("Dashboard draft withdrawn")
+}
+
+// swagger:route GET /dashboards/drafts/{draft_uid}/diff dashboards diffDashboardDraft
+//
+// Compare a dashboard draft to the live version of its dashboard.
+//
+// The diffType query parameter is one of `basic`, `json` or `delta`, `basic` by default.
+//
+// Produces:
+// - application/json
+// - text/html
+//
+// Responses:
+// 200: calculateDashboardDiffResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReviewService) diffDraftHandler(c *models.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	draft, err := s.getDraft(c.Req.Context(), c.OrgID, uid)
+	if err != nil {
+		return draftErrorResponse(err)
+	}
+	if rsp := s.checkCanView(c, draft); rsp != nil {
+		return rsp
+	}
+
+	diffType := dashdiffs.ParseDiffType(c.Query("diffType"))
+	result, err := s.Diff(c.Req.Context(), c.OrgID, uid, diffType)
+	if err != nil {
+		return draftErrorResponse(err)
+	}
+	if diffType == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+// swagger:route POST /dashboards/drafts/{draft_uid}/approve dashboards approveDashboardDraft
+//
+// Approve a dashboard draft.
+//
+// Once the number of approvals required by the policy of the folder is reached, the draft is saved as the live version of the dashboard. Approving fails with 409 if the dashboard was changed after the draft was submitted.
+//
+// Responses:
+// 200: getDashboardDraftResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+
+// swagger:route POST /dashboards/drafts/{draft_uid}/reject dashboards rejectDashboardDraft
+//
+// Reject a dashboard draft.
+//
+// Responses:
+// 200: getDashboardDraftResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *ReviewService) reviewHandler(decision Decision) func(c *models.ReqContext) response.Response {
+	return func(c *models.ReqContext) response.Response {
+		cmd := ReviewCommand{}
+		if err := web.Bind(c.Req, &cmd); err != nil {
+			return response.Error(http.StatusBadRequest, "bad request data", err)
+		}
+		cmd.OrgID = c.OrgID
+		cmd.User = c.SignedInUser
+		cmd.DraftUID = web.Params(c.Req)[":uid"]
+		cmd.Decision = decision
+
+		dto, err := s.Review(c.Req.Context(), cmd)
+		if err != nil {
+			return draftErrorResponse(err)
+		}
+		return response.JSON(http.StatusOK, dto)
+	}
+}
+
+// checkCanSave checks that the user could save the dashboard of a draft if the folder did not
+// require a review.
+func (s *ReviewService) checkCanSave(c *models.ReqContext, cmd SubmitDraftCommand) response.Response {
+	ctx := c.Req.Context()
+	var folderID int64
+	if cmd.FolderUID != "" {
+		folder, err := s.getFolder(ctx, c.OrgID, cmd.FolderUID)
+		if err != nil {
+			return draftErrorResponse(err)
+		}
+		folderID = folder.Id
+	}
+
+	var dashID int64
+	if uid := cmd.Dashboard.Get("uid").MustString(); uid != "" {
+		query := &models.GetDashboardQuery{Uid: uid, OrgId: c.OrgID}
+		err := s.dashboardService.GetDashboard(ctx, query)
+		if err != nil && !errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+		}
+		if err == nil {
+			dashID = query.Result.Id
+		}
+	}
+
+	var canSave bool
+	var err error
+	if dashID != 0 {
+		canSave, err = guardian.New(ctx, dashID, c.OrgID, c.SignedInUser).CanSave()
+	} else {
+		canSave, err = guardian.New(ctx, 0, c.OrgID, c.SignedInUser).CanCreate(folderID, false)
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+	if !canSave {
+		return response.Error(http.StatusForbidden, "Access denied to save dashboard", nil)
+	}
+	return nil
+}
+
+func (s *ReviewService) checkCanView(c *models.ReqContext, draft *Draft) response.Response {
+	canView, err := s.canViewDraft(c, draft)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check dashboard draft permissions", err)
+	}
+	if !canView {
+		return response.Error(http.StatusForbidden, "Access denied to dashboard draft", nil)
+	}
+	return nil
+}
+
+// canViewDraft returns true for the author and the reviewers of a draft, and for admins of the organization.
+func (s *ReviewService) canViewDraft(c *models.ReqContext, draft *Draft) (bool, error) {
+	if draft.CreatedBy == c.UserID || c.OrgRole == org.RoleAdmin {
+		return true, nil
+	}
+	policy, err := s.getPolicyForDraft(c.Req.Context(), draft)
+	if err != nil {
+		// the folder of the draft was deleted
+		if errors.Is(err, dashboards.ErrFolderNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return policy != nil && policy.IsReviewer(c.SignedInUser), nil
+}
+
+func policyErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrPolicyNotFound):
+		return response.Error(http.StatusNotFound, "Review policy not found", err)
+	case errors.Is(err, dashboards.ErrFolderNotFound):
+		return response.Error(http.StatusNotFound, "Folder not found", err)
+	case errors.Is(err, ErrInvalidPolicy):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to update review policy", err)
+}
+
+func draftErrorResponse(err error) response.Response {
+	var dashboardErr dashboards.DashboardErr
+	switch {
+	case errors.Is(err, ErrDraftNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard draft not found", err)
+	case errors.Is(err, dashboards.ErrFolderNotFound):
+		return response.Error(http.StatusBadRequest, "Folder not found", err)
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard not found", err)
+	case errors.Is(err, ErrReviewNotRequired):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrNotReviewer), errors.Is(err, ErrSelfReview), errors.Is(err, ErrNotAuthor):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, ErrDraftNotPending), errors.Is(err, ErrDraftOutdated):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.As(err, &dashboardErr):
+		return response.Error(dashboardErr.StatusCode, dashboardErr.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process dashboard draft", err)
+}
+
+// swagger:parameters getFolderReviewPolicy deleteFolderReviewPolicy
+type FolderReviewPolicyParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+}
+
+// swagger:parameters updateFolderReviewPolicy
+type UpdateFolderReviewPolicyParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:body
+	// required:true
+	Body UpdatePolicyCommand `json:"body"`
+}
+
+// swagger:parameters listDashboardDrafts
+type ListDashboardDraftsParams struct {
+	// in:query
+	// required:false
+	// enum: pending,approved,rejected
+	Status string `json:"status"`
+	// in:query
+	// required:false
+	DashboardUID string `json:"dashboardUid"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters submitDashboardDraft
+type SubmitDashboardDraftParams struct {
+	// in:body
+	// required:true
+	Body SubmitDraftCommand `json:"body"`
+}
+
+// swagger:parameters getDashboardDraft withdrawDashboardDraft
+type DashboardDraftParams struct {
+	// in:path
+	// required:true
+	DraftUID string `json:"draft_uid"`
+}
+
+// swagger:parameters diffDashboardDraft
+type DiffDashboardDraftParams struct {
+	// in:path
+	// required:true
+	DraftUID string `json:"draft_uid"`
+	// in:query
+	// required:false
+	// enum: basic,json,delta
+	DiffType string `json:"diffType"`
+}
+
+// swagger:parameters approveDashboardDraft rejectDashboardDraft
+type ReviewDashboardDraftParams struct {
+	// in:path
+	// required:true
+	DraftUID string `json:"draft_uid"`
+	// in:body
+	// required:true
+	Body ReviewCommand `json:"body"`
+}
+
+// swagger:response getFolderReviewPolicyResponse
+type GetFolderReviewPolicyResponse struct {
+	// in:body
+	Body *Policy `json:"body"`
+}
+
+// swagger:response listDashboardDraftsResponse
+type ListDashboardDraftsResponse struct {
+	// in:body
+	Body []*Draft `json:"body"`
+}
+
+// swagger:response submitDashboardDraftResponse
+type SubmitDashboardDraftResponse struct {
+	// in:body
+	Body *Draft `json:"body"`
+}
+
+// swagger:response getDashboardDraftResponse
+type GetDashboardDraftResponse struct {
+	// in:body
+	Body *DraftDTO `json:"body"`
+}
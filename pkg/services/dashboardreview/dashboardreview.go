@@ -0,0 +1,448 @@
+// Package dashboardreview lets folders require changes to their dashboards to be submitted as drafts
+// and approved by reviewers before they become the live version.
+package dashboardreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type Service interface {
+	// CheckDirectSave returns ErrReviewRequired if the dashboard is saved to, or moved out of, a
+	// folder with a review policy.
+	CheckDirectSave(ctx context.Context, dash *models.Dashboard) error
+
+	GetPolicy(ctx context.Context, orgID int64, folderUID string) (*Policy, error)
+	UpdatePolicy(ctx context.Context, cmd UpdatePolicyCommand) (*Policy, error)
+	DeletePolicy(ctx context.Context, orgID int64, folderUID string) error
+
+	SubmitDraft(ctx context.Context, cmd SubmitDraftCommand) (*Draft, error)
+	GetDraft(ctx context.Context, orgID int64, uid string) (*DraftDTO, error)
+	ListDrafts(ctx context.Context, query ListDraftsQuery) ([]*Draft, error)
+	// Review records the decision of a reviewer. The draft is rejected by the first rejection, and
+	// saved as the live version of the dashboard once enough reviewers approved it.
+	Review(ctx context.Context, cmd ReviewCommand) (*DraftDTO, error)
+	// Withdraw deletes a pending draft.
+	Withdraw(ctx context.Context, orgID int64, uid string, userID int64) error
+	// Diff compares a draft to the live version of its dashboard.
+	Diff(ctx context.Context, orgID int64, uid string, diffType dashdiffs.DiffType) (*dashdiffs.Result, error)
+}
+
+type ReviewService struct {
+	store            db.DB
+	log              log.Logger
+	dashboardService dashboards.DashboardService
+	notifications    notificationcenter.Service
+	routeRegister    routing.RouteRegister
+	accessControl    accesscontrol.AccessControl
+	now              func() time.Time
+}
+
+func ProvideService(sqlStore db.DB, bus bus.Bus, dashboardService dashboards.DashboardService,
+	notificationCenter notificationcenter.Service, routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl) *ReviewService {
+	s := &ReviewService{
+		store:            sqlStore,
+		log:              log.New("dashboard-review"),
+		dashboardService: dashboardService,
+		notifications:    notificationCenter,
+		routeRegister:    routeRegister,
+		accessControl:    accessControl,
+		now:              time.Now,
+	}
+
+	bus.AddEventListener(s.handleDashboardDeleted)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *ReviewService) CheckDirectSave(ctx context.Context, dash *models.Dashboard) error {
+	policy, err := s.getPolicyForSave(ctx, dash.OrgId, dash.Id, dash.Uid, dash.FolderId)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		return ErrReviewRequired
+	}
+	return nil
+}
+
+func (s *ReviewService) GetPolicy(ctx context.Context, orgID int64, folderUID string) (*Policy, error) {
+	policy, err := s.getPolicy(ctx, orgID, folderUID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, ErrPolicyNotFound
+	}
+	return policy, nil
+}
+
+func (s *ReviewService) UpdatePolicy(ctx context.Context, cmd UpdatePolicyCommand) (*Policy, error) {
+	policy := &Policy{
+		OrgID:             cmd.OrgID,
+		FolderUID:         cmd.FolderUID,
+		ReviewerUserIDs:   cmd.ReviewerUserIDs,
+		ReviewerTeamIDs:   cmd.ReviewerTeamIDs,
+		RequiredApprovals: cmd.RequiredApprovals,
+		Updated:           s.now(),
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPolicy, err)
+	}
+	if err := s.upsertPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *ReviewService) DeletePolicy(ctx context.Context, orgID int64, folderUID string) error {
+	return s.deletePolicy(ctx, orgID, folderUID)
+}
+
+func (s *ReviewService) SubmitDraft(ctx context.Context, cmd SubmitDraftCommand) (*Draft, error) {
+	if cmd.Dashboard == nil {
+		return nil, dashboards.ErrDashboardNotFound
+	}
+	dash := models.NewDashboardFromJson(cmd.Dashboard)
+	if dash.Title == "" {
+		return nil, dashboards.ErrDashboardTitleEmpty
+	}
+
+	draft := &Draft{
+		OrgID:     cmd.OrgID,
+		UID:       util.GenerateShortUID(),
+		FolderUID: cmd.FolderUID,
+		Title:     dash.Title,
+		Message:   cmd.Message,
+		Status:    StatusPending,
+		CreatedBy: cmd.User.UserID,
+		Created:   s.now(),
+		Updated:   s.now(),
+	}
+
+	var folderID int64
+	if cmd.FolderUID != "" {
+		folder, err := s.getFolder(ctx, cmd.OrgID, cmd.FolderUID)
+		if err != nil {
+			return nil, err
+		}
+		folderID = folder.Id
+	}
+
+	var dashID int64
+	if dash.Uid != "" {
+		query := &models.GetDashboardQuery{Uid: dash.Uid, OrgId: cmd.OrgID}
+		err := s.dashboardService.GetDashboard(ctx, query)
+		switch {
+		case err == nil:
+			if query.Result.IsFolder {
+				return nil, dashboards.ErrDashboardTypeMismatch
+			}
+			dashID = query.Result.Id
+			draft.ParentVersion = query.Result.Version
+		case !errors.Is(err, dashboards.ErrDashboardNotFound):
+			return nil, err
+		}
+	} else {
+		// the approved draft creates the dashboard with the uid of the draft
+		dash.Uid = util.GenerateShortUID()
+	}
+	draft.DashboardUID = dash.Uid
+
+	policy, err := s.getPolicyForSave(ctx, cmd.OrgID, dashID, dash.Uid, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, ErrReviewNotRequired
+	}
+
+	// the live dashboard is identified by its uid, and versioned when the draft is approved
+	draft.Data = cmd.Dashboard
+	draft.Data.Set("uid", dash.Uid)
+	draft.Data.Del("id")
+	draft.Data.Del("version")
+
+	if err := s.insertDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	s.notifyReviewers(ctx, policy, draft)
+	return draft, nil
+}
+
+func (s *ReviewService) GetDraft(ctx context.Context, orgID int64, uid string) (*DraftDTO, error) {
+	draft, err := s.getDraft(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDTO(ctx, draft)
+}
+
+func (s *ReviewService) ListDrafts(ctx context.Context, query ListDraftsQuery) ([]*Draft, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultLimit
+	}
+	if query.Limit > maxLimit {
+		query.Limit = maxLimit
+	}
+	return s.listDrafts(ctx, query)
+}
+
+func (s *ReviewService) Review(ctx context.Context, cmd ReviewCommand) (*DraftDTO, error) {
+	draft, err := s.getDraft(ctx, cmd.OrgID, cmd.DraftUID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != StatusPending {
+		return nil, ErrDraftNotPending
+	}
+	if draft.CreatedBy == cmd.User.UserID {
+		return nil, ErrSelfReview
+	}
+
+	policy, err := s.getPolicyForDraft(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil || !policy.IsReviewer(cmd.User) {
+		return nil, ErrNotReviewer
+	}
+
+	review := &Review{
+		OrgID:    cmd.OrgID,
+		DraftID:  draft.ID,
+		UserID:   cmd.User.UserID,
+		Decision: cmd.Decision,
+		Comment:  cmd.Comment,
+		Created:  s.now(),
+	}
+
+	switch cmd.Decision {
+	case DecisionReject:
+		draft.Status = StatusRejected
+	case DecisionApprove:
+		reviews, err := s.getReviews(ctx, draft.ID)
+		if err != nil {
+			return nil, err
+		}
+		approvals := 1
+		for _, r := range reviews {
+			if r.Decision == DecisionApprove && r.UserID != cmd.User.UserID {
+				approvals++
+			}
+		}
+		if approvals >= policy.RequiredApprovals {
+			// the draft is saved before the review is recorded, so it stays pending when the
+			// dashboard was changed in the meantime
+			version, err := s.apply(ctx, draft, cmd)
+			if err != nil {
+				return nil, err
+			}
+			draft.Status = StatusApproved
+			draft.AppliedVersion = version
+		}
+	default:
+		return nil, fmt.Errorf("unknown review decision %q", cmd.Decision)
+	}
+
+	draft.Updated = s.now()
+	if err := s.saveReview(ctx, draft, review); err != nil {
+		return nil, err
+	}
+	if draft.Status != StatusPending {
+		s.notifyAuthor(ctx, draft, review)
+	}
+	return s.toDTO(ctx, draft)
+}
+
+func (s *ReviewService) Withdraw(ctx context.Context, orgID int64, uid string, userID int64) error {
+	draft, err := s.getDraft(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if draft.CreatedBy != userID {
+		return ErrNotAuthor
+	}
+	if draft.Status != StatusPending {
+		return ErrDraftNotPending
+	}
+	return s.deleteDraft(ctx, draft.ID)
+}
+
+func (s *ReviewService) Diff(ctx context.Context, orgID int64, uid string, diffType dashdiffs.DiffType) (*dashdiffs.Result, error) {
+	draft, err := s.getDraft(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	base := simplejson.New()
+	if draft.ParentVersion > 0 {
+		query := &models.GetDashboardQuery{Uid: draft.DashboardUID, OrgId: orgID}
+		if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+			return nil, err
+		}
+		// ids and versions of the live dashboard are not part of the change
+		base = query.Result.Data
+		base.Del("id")
+		base.Del("version")
+	}
+	return dashdiffs.CalculateDiff(ctx, &dashdiffs.Options{OrgId: orgID, DiffType: diffType}, base, draft.Data)
+}
+
+// apply saves an approved draft as the live version of its dashboard and returns the new version.
+func (s *ReviewService) apply(ctx context.Context, draft *Draft, cmd ReviewCommand) (int, error) {
+	dash := models.NewDashboardFromJson(draft.Data)
+	if draft.FolderUID != "" {
+		folder, err := s.getFolder(ctx, draft.OrgID, draft.FolderUID)
+		if err != nil {
+			return 0, err
+		}
+		dash.FolderId = folder.Id
+	}
+	if draft.ParentVersion > 0 {
+		query := &models.GetDashboardQuery{Uid: draft.DashboardUID, OrgId: draft.OrgID}
+		if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				return 0, ErrDraftOutdated
+			}
+			return 0, err
+		}
+		dash.Id = query.Result.Id
+		dash.SetVersion(draft.ParentVersion)
+	}
+
+	message := fmt.Sprintf("Approved draft %s", draft.UID)
+	if draft.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, draft.Message)
+	}
+	saved, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgId:     draft.OrgID,
+		Dashboard: dash,
+		Message:   message,
+		User:      cmd.User,
+		Overwrite: false,
+	}, true)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardVersionMismatch) || errors.Is(err, dashboards.ErrDashboardWithSameUIDExists) {
+			return 0, ErrDraftOutdated
+		}
+		return 0, err
+	}
+	return saved.Version, nil
+}
+
+func (s *ReviewService) toDTO(ctx context.Context, draft *Draft) (*DraftDTO, error) {
+	reviews, err := s.getReviews(ctx, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	dto := &DraftDTO{Draft: draft, Reviews: reviews}
+
+	policy, err := s.getPolicyForDraft(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		dto.RequiredApprovals = policy.RequiredApprovals
+	}
+	return dto, nil
+}
+
+func (s *ReviewService) getFolder(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
+	query := &models.GetDashboardQuery{Uid: uid, OrgId: orgID}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, dashboards.ErrFolderNotFound
+		}
+		return nil, err
+	}
+	if !query.Result.IsFolder {
+		return nil, dashboards.ErrFolderNotFound
+	}
+	return query.Result, nil
+}
+
+func (s *ReviewService) handleDashboardDeleted(ctx context.Context, e *events.DashboardDeleted) error {
+	if err := s.deleteDashboardDrafts(ctx, e.OrgID, e.UID); err != nil {
+		s.log.Error("Failed to delete drafts of deleted dashboard", "orgId", e.OrgID, "dashboardUid", e.UID, "error", err)
+	}
+	return nil
+}
+
+func (s *ReviewService) notifyReviewers(ctx context.Context, policy *Policy, draft *Draft) {
+	if s.notifications == nil {
+		return
+	}
+
+	commands := []notificationcenter.NotifyCommand{{UserIDs: policy.ReviewerUserIDs}}
+	for _, teamID := range policy.ReviewerTeamIDs {
+		commands = append(commands, notificationcenter.NotifyCommand{TeamID: teamID})
+	}
+	for _, cmd := range commands {
+		if len(cmd.UserIDs) == 0 && cmd.TeamID == 0 {
+			continue
+		}
+		cmd.OrgID = draft.OrgID
+		cmd.ExcludeUserID = draft.CreatedBy
+		cmd.Kind = notificationcenter.KindDashboard
+		cmd.Severity = notificationcenter.SeverityInfo
+		cmd.Title = fmt.Sprintf("Changes to dashboard %q are waiting for your review", draft.Title)
+		cmd.Body = draft.Message
+		cmd.URL = draftPath(draft)
+		if _, err := s.notifications.Notify(ctx, cmd); err != nil {
+			s.log.Warn("Failed to notify reviewers of dashboard draft", "orgId", draft.OrgID, "draftUid", draft.UID, "error", err)
+		}
+	}
+}
+
+func (s *ReviewService) notifyAuthor(ctx context.Context, draft *Draft, review *Review) {
+	if s.notifications == nil {
+		return
+	}
+
+	severity := notificationcenter.SeveritySuccess
+	if draft.Status == StatusRejected {
+		severity = notificationcenter.SeverityWarning
+	}
+	_, err := s.notifications.Notify(ctx, notificationcenter.NotifyCommand{
+		OrgID:    draft.OrgID,
+		UserIDs:  []int64{draft.CreatedBy},
+		Kind:     notificationcenter.KindDashboard,
+		Severity: severity,
+		Title:    fmt.Sprintf("Your changes to dashboard %q were %s", draft.Title, draft.Status),
+		Body:     review.Comment,
+		URL:      draftPath(draft),
+	})
+	if err != nil {
+		s.log.Warn("Failed to notify author of dashboard draft", "orgId", draft.OrgID, "draftUid", draft.UID, "error", err)
+	}
+}
+
+func draftPath(draft *Draft) string {
+	if draft.Status == StatusApproved {
+		return fmt.Sprintf("/d/%s/%s", draft.DashboardUID, models.SlugifyTitle(draft.Title))
+	}
+	return fmt.Sprintf("/dashboard/drafts/%s", draft.UID)
+}
@@ -0,0 +1,264 @@
+package dashboardreview
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakeNotificationCenter struct {
+	commands []notificationcenter.NotifyCommand
+}
+
+func (f *fakeNotificationCenter) Notify(_ context.Context, cmd notificationcenter.NotifyCommand) (int, error) {
+	f.commands = append(f.commands, cmd)
+	return len(cmd.UserIDs), nil
+}
+
+func TestIntegrationDashboardReview(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	insert := func(dash *models.Dashboard) *models.Dashboard {
+		dash.OrgId = 1
+		dash.Slug = models.SlugifyTitle(dash.Title)
+		dash.Created = time.Now()
+		dash.Updated = time.Now()
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Insert(dash)
+			return err
+		})
+		require.NoError(t, err)
+		return dash
+	}
+	audited := insert(&models.Dashboard{Uid: "audited", Title: "Audited", IsFolder: true, Data: simplejson.New(), Version: 1})
+	open := insert(&models.Dashboard{Uid: "open", Title: "Open", IsFolder: true, Data: simplejson.New(), Version: 1})
+	dash := insert(&models.Dashboard{
+		Uid: "dash", Title: "Payments", FolderId: audited.Id, Version: 3,
+		Data: simplejson.NewFromAny(map[string]interface{}{"uid": "dash", "title": "Payments", "refresh": "1m"}),
+	})
+	other := insert(&models.Dashboard{Uid: "other", Title: "Other", FolderId: open.Id, Version: 1, Data: simplejson.New()})
+
+	var saved []*dashboards.SaveDashboardDTO
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(func(_ context.Context, query *models.GetDashboardQuery) error {
+		for _, d := range []*models.Dashboard{audited, open, dash, other} {
+			if d.Uid == query.Uid {
+				query.Result = d
+				return nil
+			}
+		}
+		return dashboards.ErrDashboardNotFound
+	}).Maybe()
+	isOutdated := func(dto *dashboards.SaveDashboardDTO) bool {
+		return dto.Dashboard.Uid == "dash" && dto.Dashboard.Version != dash.Version
+	}
+	dashboardService.On("SaveDashboard", mock.Anything, mock.Anything, true).Return(func(_ context.Context, dto *dashboards.SaveDashboardDTO, _ bool) *models.Dashboard {
+		if isOutdated(dto) {
+			return nil
+		}
+		saved = append(saved, dto)
+		result := *dto.Dashboard
+		result.Version = dto.Dashboard.Version + 1
+		return &result
+	}, func(_ context.Context, dto *dashboards.SaveDashboardDTO, _ bool) error {
+		if isOutdated(dto) {
+			return dashboards.ErrDashboardVersionMismatch
+		}
+		return nil
+	}).Maybe()
+
+	eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	notificationCenter := &fakeNotificationCenter{}
+	s := ProvideService(sqlStore, eventBus, dashboardService, notificationCenter, routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	author := &user.SignedInUser{UserID: 10, OrgID: 1}
+	reviewer := &user.SignedInUser{UserID: 20, OrgID: 1}
+	teamReviewer := &user.SignedInUser{UserID: 30, OrgID: 1, Teams: []int64{5}}
+	outsider := &user.SignedInUser{UserID: 40, OrgID: 1}
+
+	t.Run("policies must name reviewers and require approvals", func(t *testing.T) {
+		_, err := s.UpdatePolicy(ctx, UpdatePolicyCommand{OrgID: 1, FolderUID: "audited", RequiredApprovals: 1})
+		require.ErrorIs(t, err, ErrInvalidPolicy)
+		_, err = s.UpdatePolicy(ctx, UpdatePolicyCommand{OrgID: 1, FolderUID: "audited", ReviewerUserIDs: []int64{20}})
+		require.ErrorIs(t, err, ErrInvalidPolicy)
+
+		_, err = s.GetPolicy(ctx, 1, "audited")
+		require.ErrorIs(t, err, ErrPolicyNotFound)
+	})
+
+	_, err := s.UpdatePolicy(ctx, UpdatePolicyCommand{
+		OrgID: 1, FolderUID: "audited", ReviewerUserIDs: []int64{20}, ReviewerTeamIDs: []int64{5}, RequiredApprovals: 2,
+	})
+	require.NoError(t, err)
+
+	t.Run("direct saves are rejected in and out of folders with a policy", func(t *testing.T) {
+		err := s.CheckDirectSave(ctx, &models.Dashboard{OrgId: 1, Uid: "dash", FolderId: audited.Id})
+		require.ErrorIs(t, err, ErrReviewRequired)
+		err = s.CheckDirectSave(ctx, &models.Dashboard{OrgId: 1, Uid: "dash", FolderId: open.Id})
+		require.ErrorIs(t, err, ErrReviewRequired)
+		err = s.CheckDirectSave(ctx, &models.Dashboard{OrgId: 1, FolderId: audited.Id})
+		require.ErrorIs(t, err, ErrReviewRequired)
+
+		require.NoError(t, s.CheckDirectSave(ctx, &models.Dashboard{OrgId: 1, Uid: "other", FolderId: open.Id}))
+		require.NoError(t, s.CheckDirectSave(ctx, &models.Dashboard{OrgId: 1}))
+	})
+
+	t.Run("drafts are only accepted for folders with a policy", func(t *testing.T) {
+		_, err := s.SubmitDraft(ctx, SubmitDraftCommand{
+			OrgID: 1, User: author, FolderUID: "open",
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "other", "title": "Other"}),
+		})
+		require.ErrorIs(t, err, ErrReviewNotRequired)
+	})
+
+	var draft *Draft
+	t.Run("submitting a draft notifies the reviewers", func(t *testing.T) {
+		draft, err = s.SubmitDraft(ctx, SubmitDraftCommand{
+			OrgID: 1, User: author, FolderUID: "audited", Message: "Faster refresh",
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"id": dash.Id, "uid": "dash", "title": "Payments", "refresh": "10s", "version": 3}),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, draft.Status)
+		assert.Equal(t, "dash", draft.DashboardUID)
+		assert.Equal(t, 3, draft.ParentVersion)
+
+		require.Len(t, notificationCenter.commands, 2)
+		assert.Equal(t, []int64{20}, notificationCenter.commands[0].UserIDs)
+		assert.Equal(t, int64(5), notificationCenter.commands[1].TeamID)
+		assert.Equal(t, author.UserID, notificationCenter.commands[0].ExcludeUserID)
+		assert.Equal(t, "/dashboard/drafts/"+draft.UID, notificationCenter.commands[0].URL)
+
+		drafts, err := s.ListDrafts(ctx, ListDraftsQuery{OrgID: 1, Status: StatusPending})
+		require.NoError(t, err)
+		require.Len(t, drafts, 1)
+		assert.Nil(t, drafts[0].Data)
+	})
+
+	t.Run("diff against the live dashboard", func(t *testing.T) {
+		result, err := s.Diff(ctx, 1, draft.UID, dashdiffs.DiffDelta)
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Delta), `"10s"`)
+		assert.NotContains(t, string(result.Delta), `"version"`)
+	})
+
+	t.Run("only reviewers other than the author can review", func(t *testing.T) {
+		_, err := s.Review(ctx, ReviewCommand{OrgID: 1, User: author, DraftUID: draft.UID, Decision: DecisionApprove})
+		require.ErrorIs(t, err, ErrSelfReview)
+		_, err = s.Review(ctx, ReviewCommand{OrgID: 1, User: outsider, DraftUID: draft.UID, Decision: DecisionApprove})
+		require.ErrorIs(t, err, ErrNotReviewer)
+	})
+
+	t.Run("the draft is saved once enough reviewers approved it", func(t *testing.T) {
+		dto, err := s.Review(ctx, ReviewCommand{OrgID: 1, User: reviewer, DraftUID: draft.UID, Decision: DecisionApprove})
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, dto.Status)
+		assert.Empty(t, saved)
+
+		// approving twice does not count twice
+		dto, err = s.Review(ctx, ReviewCommand{OrgID: 1, User: reviewer, DraftUID: draft.UID, Decision: DecisionApprove})
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, dto.Status)
+		assert.Len(t, dto.Reviews, 1)
+
+		notificationCenter.commands = nil
+		dto, err = s.Review(ctx, ReviewCommand{OrgID: 1, User: teamReviewer, DraftUID: draft.UID, Decision: DecisionApprove, Comment: "LGTM"})
+		require.NoError(t, err)
+		assert.Equal(t, StatusApproved, dto.Status)
+		assert.Equal(t, 4, dto.AppliedVersion)
+		assert.Equal(t, 2, dto.RequiredApprovals)
+
+		require.Len(t, saved, 1)
+		assert.Equal(t, dash.Id, saved[0].Dashboard.Id)
+		assert.Equal(t, audited.Id, saved[0].Dashboard.FolderId)
+		assert.Equal(t, "10s", saved[0].Dashboard.Data.Get("refresh").MustString())
+		assert.Equal(t, "Approved draft "+draft.UID+": Faster refresh", saved[0].Message)
+
+		require.Len(t, notificationCenter.commands, 1)
+		assert.Equal(t, []int64{author.UserID}, notificationCenter.commands[0].UserIDs)
+		assert.Equal(t, `Your changes to dashboard "Payments" were approved`, notificationCenter.commands[0].Title)
+
+		_, err = s.Review(ctx, ReviewCommand{OrgID: 1, User: reviewer, DraftUID: draft.UID, Decision: DecisionReject})
+		require.ErrorIs(t, err, ErrDraftNotPending)
+	})
+
+	t.Run("outdated drafts cannot be approved", func(t *testing.T) {
+		saved = nil
+		_, err := s.UpdatePolicy(ctx, UpdatePolicyCommand{OrgID: 1, FolderUID: "audited", ReviewerUserIDs: []int64{20}, RequiredApprovals: 1})
+		require.NoError(t, err)
+
+		outdated, err := s.SubmitDraft(ctx, SubmitDraftCommand{
+			OrgID: 1, User: author, FolderUID: "audited",
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "dash", "title": "Payments"}),
+		})
+		require.NoError(t, err)
+		dash.Version = 4
+
+		_, err = s.Review(ctx, ReviewCommand{OrgID: 1, User: reviewer, DraftUID: outdated.UID, Decision: DecisionApprove})
+		require.ErrorIs(t, err, ErrDraftOutdated)
+		assert.Empty(t, saved)
+		dto, err := s.GetDraft(ctx, 1, outdated.UID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, dto.Status)
+		assert.Empty(t, dto.Reviews)
+	})
+
+	t.Run("rejecting and withdrawing drafts", func(t *testing.T) {
+		rejected, err := s.SubmitDraft(ctx, SubmitDraftCommand{
+			OrgID: 1, User: author, FolderUID: "audited",
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "New dashboard"}),
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, rejected.DashboardUID)
+		assert.Equal(t, rejected.DashboardUID, rejected.Data.Get("uid").MustString())
+
+		dto, err := s.Review(ctx, ReviewCommand{OrgID: 1, User: reviewer, DraftUID: rejected.UID, Decision: DecisionReject})
+		require.NoError(t, err)
+		assert.Equal(t, StatusRejected, dto.Status)
+		require.ErrorIs(t, s.Withdraw(ctx, 1, rejected.UID, author.UserID), ErrDraftNotPending)
+
+		pending, err := s.SubmitDraft(ctx, SubmitDraftCommand{
+			OrgID: 1, User: author, FolderUID: "audited",
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "Another dashboard"}),
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Withdraw(ctx, 1, pending.UID, reviewer.UserID), ErrNotAuthor)
+		require.NoError(t, s.Withdraw(ctx, 1, pending.UID, author.UserID))
+		_, err = s.GetDraft(ctx, 1, pending.UID)
+		require.ErrorIs(t, err, ErrDraftNotFound)
+	})
+
+	t.Run("drafts are deleted together with their dashboard", func(t *testing.T) {
+		err := eventBus.Publish(ctx, &events.DashboardDeleted{UID: "dash", OrgID: 1})
+		require.NoError(t, err)
+		drafts, err := s.ListDrafts(ctx, ListDraftsQuery{OrgID: 1, DashboardUID: "dash"})
+		require.NoError(t, err)
+		assert.Empty(t, drafts)
+	})
+
+	t.Run("delete policy", func(t *testing.T) {
+		require.NoError(t, s.DeletePolicy(ctx, 1, "audited"))
+		require.ErrorIs(t, s.DeletePolicy(ctx, 1, "audited"), ErrPolicyNotFound)
+		require.NoError(t, s.CheckDirectSave(ctx, &models.Dashboard{OrgId: 1, Uid: "dash", FolderId: audited.Id}))
+	})
+}
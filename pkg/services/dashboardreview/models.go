@@ -0,0 +1,173 @@
+package dashboardreview
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var (
+	ErrPolicyNotFound    = errors.New("folder has no review policy")
+	ErrInvalidPolicy     = errors.New("invalid review policy")
+	ErrReviewRequired    = errors.New("changes to dashboards in this folder must be submitted for review")
+	ErrReviewNotRequired = errors.New("dashboard is not in a folder with a review policy and can be saved directly")
+	ErrDraftNotFound     = errors.New("dashboard draft not found")
+	ErrDraftNotPending   = errors.New("dashboard draft is not pending review")
+	ErrDraftOutdated     = errors.New("dashboard was changed after the draft was submitted")
+	ErrNotReviewer       = errors.New("user is not a reviewer of the dashboard draft")
+	ErrSelfReview        = errors.New("authors cannot review their own drafts")
+	ErrNotAuthor         = errors.New("only the author can withdraw a dashboard draft")
+)
+
+// Policy requires changes to the dashboards of a folder to be approved by reviewers before they
+// become the live version.
+type Policy struct {
+	ID        int64  `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID     int64  `json:"orgId" xorm:"org_id"`
+	FolderUID string `json:"folderUid" xorm:"folder_uid"`
+	// ReviewerUserIDs and ReviewerTeamIDs are the users, and the members of the teams, who can
+	// review drafts.
+	ReviewerUserIDs []int64 `json:"reviewerUserIds" xorm:"reviewer_user_ids"`
+	ReviewerTeamIDs []int64 `json:"reviewerTeamIds" xorm:"reviewer_team_ids"`
+	// RequiredApprovals is the number of reviewers who must approve a draft.
+	RequiredApprovals int       `json:"requiredApprovals" xorm:"required_approvals"`
+	Updated           time.Time `json:"updated" xorm:"updated"`
+}
+
+func (p Policy) TableName() string {
+	return "dashboard_review_policy"
+}
+
+// IsReviewer returns true if the user is one of the reviewers or a member of one of the reviewer teams.
+func (p *Policy) IsReviewer(u *user.SignedInUser) bool {
+	for _, id := range p.ReviewerUserIDs {
+		if id == u.UserID {
+			return true
+		}
+	}
+	for _, teamID := range p.ReviewerTeamIDs {
+		for _, id := range u.Teams {
+			if id == teamID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Policy) validate() error {
+	if len(p.ReviewerUserIDs) == 0 && len(p.ReviewerTeamIDs) == 0 {
+		return errors.New("at least one reviewer or reviewer team is required")
+	}
+	if p.RequiredApprovals < 1 {
+		return errors.New("requiredApprovals must be at least 1")
+	}
+	return nil
+}
+
+// UpdatePolicyCommand creates or replaces the review policy of a folder.
+// swagger:model
+type UpdatePolicyCommand struct {
+	OrgID             int64   `json:"-"`
+	FolderUID         string  `json:"-"`
+	ReviewerUserIDs   []int64 `json:"reviewerUserIds"`
+	ReviewerTeamIDs   []int64 `json:"reviewerTeamIds"`
+	RequiredApprovals int     `json:"requiredApprovals"`
+}
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+// Draft is a change to a dashboard which becomes the live version once it is approved.
+type Draft struct {
+	ID    int64  `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	UID   string `json:"uid" xorm:"uid"`
+	// DashboardUID is the dashboard the draft changes or, for new dashboards, creates.
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	// FolderUID is the folder the dashboard is saved to, empty for the General folder.
+	FolderUID string           `json:"folderUid" xorm:"folder_uid"`
+	Title     string           `json:"title" xorm:"title"`
+	Data      *simplejson.Json `json:"dashboard" xorm:"data"`
+	Message   string           `json:"message" xorm:"message"`
+	// ParentVersion is the version of the live dashboard the draft is based on, 0 for new dashboards.
+	ParentVersion int `json:"parentVersion" xorm:"parent_version"`
+	// AppliedVersion is the version of the live dashboard the draft became once it was approved.
+	AppliedVersion int       `json:"appliedVersion,omitempty" xorm:"applied_version"`
+	Status         Status    `json:"status" xorm:"status"`
+	CreatedBy      int64     `json:"createdBy" xorm:"created_by"`
+	Created        time.Time `json:"created" xorm:"created"`
+	Updated        time.Time `json:"updated" xorm:"updated"`
+}
+
+func (d Draft) TableName() string {
+	return "dashboard_draft"
+}
+
+// DraftDTO is a draft together with its reviews.
+// swagger:model
+type DraftDTO struct {
+	*Draft
+	Reviews           []*Review `json:"reviews"`
+	RequiredApprovals int       `json:"requiredApprovals"`
+}
+
+type Decision string
+
+const (
+	DecisionApprove Decision = "approve"
+	DecisionReject  Decision = "reject"
+)
+
+// Review is the decision of a reviewer on a draft.
+type Review struct {
+	ID       int64     `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID    int64     `json:"-" xorm:"org_id"`
+	DraftID  int64     `json:"-" xorm:"draft_id"`
+	UserID   int64     `json:"userId" xorm:"user_id"`
+	Decision Decision  `json:"decision" xorm:"decision"`
+	Comment  string    `json:"comment" xorm:"comment"`
+	Created  time.Time `json:"created" xorm:"created"`
+}
+
+func (r Review) TableName() string {
+	return "dashboard_draft_review"
+}
+
+// SubmitDraftCommand submits a dashboard change for review.
+// swagger:model
+type SubmitDraftCommand struct {
+	OrgID int64              `json:"-"`
+	User  *user.SignedInUser `json:"-"`
+	// Dashboard is the complete dashboard JSON, with the uid of the dashboard to change or without
+	// one to create a new dashboard.
+	Dashboard *simplejson.Json `json:"dashboard"`
+	// FolderUID is the folder to save the dashboard to, empty for the General folder.
+	FolderUID string `json:"folderUid"`
+	Message   string `json:"message"`
+}
+
+// ReviewCommand approves or rejects a draft.
+// swagger:model
+type ReviewCommand struct {
+	OrgID    int64              `json:"-"`
+	User     *user.SignedInUser `json:"-"`
+	DraftUID string             `json:"-"`
+	Decision Decision           `json:"-"`
+	Comment  string             `json:"comment"`
+}
+
+// ListDraftsQuery lists the drafts of an organization, newest first.
+type ListDraftsQuery struct {
+	OrgID        int64
+	Status       Status
+	DashboardUID string
+	Limit        int
+}
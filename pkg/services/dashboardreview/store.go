@@ -0,0 +1,189 @@
+package dashboardreview
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *ReviewService) getPolicy(ctx context.Context, orgID int64, folderUID string) (*Policy, error) {
+	var policy *Policy
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var p Policy
+		exists, err := sess.Where("org_id = ? AND folder_uid = ?", orgID, folderUID).Get(&p)
+		if exists {
+			policy = &p
+		}
+		return err
+	})
+	return policy, err
+}
+
+// getPolicyForSave returns the policy which applies when a dashboard is saved to a folder: the policy
+// of that folder or, if it has none, of the folder the dashboard is currently in.
+func (s *ReviewService) getPolicyForSave(ctx context.Context, orgID, dashID int64, dashUID string, folderID int64) (*Policy, error) {
+	var policy *Policy
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		folderIDs := []int64{folderID}
+		if dashID != 0 || dashUID != "" {
+			var current []int64
+			err := sess.Table("dashboard").Where("org_id = ? AND (id = ? OR uid = ?)", orgID, dashID, dashUID).Cols("folder_id").Find(&current)
+			if err != nil {
+				return err
+			}
+			folderIDs = append(folderIDs, current...)
+		}
+
+		for _, id := range folderIDs {
+			// dashboards in the General folder cannot require reviews
+			if id == 0 {
+				continue
+			}
+			var p Policy
+			exists, err := sess.Table("dashboard_review_policy").
+				Select("dashboard_review_policy.*").
+				Join("INNER", "dashboard", "dashboard.org_id = dashboard_review_policy.org_id AND dashboard.uid = dashboard_review_policy.folder_uid").
+				Where("dashboard_review_policy.org_id = ? AND dashboard.id = ?", orgID, id).
+				Get(&p)
+			if err != nil {
+				return err
+			}
+			if exists {
+				policy = &p
+				return nil
+			}
+		}
+		return nil
+	})
+	return policy, err
+}
+
+// getPolicyForDraft returns the policy which applies to a draft, nil if the policy was deleted after
+// the draft was submitted.
+func (s *ReviewService) getPolicyForDraft(ctx context.Context, draft *Draft) (*Policy, error) {
+	var folderID int64
+	if draft.FolderUID != "" {
+		folder, err := s.getFolder(ctx, draft.OrgID, draft.FolderUID)
+		if err != nil {
+			return nil, err
+		}
+		folderID = folder.Id
+	}
+	return s.getPolicyForSave(ctx, draft.OrgID, 0, draft.DashboardUID, folderID)
+}
+
+func (s *ReviewService) upsertPolicy(ctx context.Context, policy *Policy) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing Policy
+		exists, err := sess.Where("org_id = ? AND folder_uid = ?", policy.OrgID, policy.FolderUID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err := sess.Insert(policy)
+			return err
+		}
+
+		policy.ID = existing.ID
+		_, err = sess.ID(existing.ID).Cols("reviewer_user_ids", "reviewer_team_ids", "required_approvals", "updated").Update(policy)
+		return err
+	})
+}
+
+func (s *ReviewService) deletePolicy(ctx context.Context, orgID int64, folderUID string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND folder_uid = ?", orgID, folderUID).Delete(&Policy{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrPolicyNotFound
+		}
+		return nil
+	})
+}
+
+func (s *ReviewService) insertDraft(ctx context.Context, draft *Draft) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(draft)
+		return err
+	})
+}
+
+func (s *ReviewService) getDraft(ctx context.Context, orgID int64, uid string) (*Draft, error) {
+	var draft Draft
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&draft)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrDraftNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (s *ReviewService) listDrafts(ctx context.Context, query ListDraftsQuery) ([]*Draft, error) {
+	result := make([]*Draft, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ?", query.OrgID)
+		if query.Status != "" {
+			sess.And("status = ?", query.Status)
+		}
+		if query.DashboardUID != "" {
+			sess.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		// the dashboard JSON is only returned for single drafts
+		return sess.Omit("data").Desc("created").Limit(query.Limit).Find(&result)
+	})
+	return result, err
+}
+
+func (s *ReviewService) deleteDraft(ctx context.Context, id int64) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("draft_id = ?", id).Delete(&Review{}); err != nil {
+			return err
+		}
+		_, err := sess.ID(id).Delete(&Draft{})
+		return err
+	})
+}
+
+func (s *ReviewService) deleteDashboardDrafts(ctx context.Context, orgID int64, dashboardUID string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM dashboard_draft_review WHERE draft_id IN (SELECT id FROM dashboard_draft WHERE org_id = ? AND dashboard_uid = ?)", orgID, dashboardUID)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&Draft{})
+		return err
+	})
+}
+
+func (s *ReviewService) getReviews(ctx context.Context, draftID int64) ([]*Review, error) {
+	result := make([]*Review, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("draft_id = ?", draftID).Asc("created").Find(&result)
+	})
+	return result, err
+}
+
+// saveReview records a review, replacing an earlier review of the same reviewer, together with the
+// resulting status of the draft.
+func (s *ReviewService) saveReview(ctx context.Context, draft *Draft, review *Review) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("draft_id = ? AND user_id = ?", review.DraftID, review.UserID).Delete(&Review{}); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(review); err != nil {
+			return err
+		}
+		_, err := sess.ID(draft.ID).Cols("status", "applied_version", "updated").Update(draft)
+		return err
+	})
+}
@@ -18,6 +18,7 @@ type DashboardService interface {
 	GetDashboard(ctx context.Context, query *models.GetDashboardQuery) error
 	GetDashboardACLInfoList(ctx context.Context, query *models.GetDashboardACLInfoListQuery) error
 	GetDashboards(ctx context.Context, query *models.GetDashboardsQuery) error
+	GetDashboardTagKeys(ctx context.Context, query *models.GetDashboardTagKeysQuery) error
 	GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error
 	GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error
 	HasAdminPermissionInDashboardsOrFolders(ctx context.Context, query *models.HasAdminPermissionInDashboardsOrFoldersQuery) error
@@ -45,6 +46,10 @@ type DashboardProvisioningService interface {
 	GetProvisionedDashboardData(ctx context.Context, name string) ([]*models.DashboardProvisioning, error)
 	GetProvisionedDashboardDataByDashboardID(ctx context.Context, dashboardID int64) (*models.DashboardProvisioning, error)
 	GetProvisionedDashboardDataByDashboardUID(ctx context.Context, orgID int64, dashboardUID string) (*models.DashboardProvisioning, error)
+	// ReserveUID reserves a UID of a dashboard or folder for a provisioner.
+	ReserveUID(ctx context.Context, cmd *ReserveUIDCommand) (*UIDReservation, error)
+	GetUIDReservations(ctx context.Context, orgID int64) ([]*UIDReservation, error)
+	DeleteUIDReservation(ctx context.Context, orgID int64, uid string) error
 	SaveFolderForProvisionedDashboards(context.Context, *SaveDashboardDTO) (*models.Dashboard, error)
 	SaveProvisionedDashboard(ctx context.Context, dto *SaveDashboardDTO, provisioning *models.DashboardProvisioning) (*models.Dashboard, error)
 	UnprovisionDashboard(ctx context.Context, dashboardID int64) error
@@ -63,6 +68,7 @@ type Store interface {
 	GetDashboards(ctx context.Context, query *models.GetDashboardsQuery) error
 	// GetDashboardsByPluginID retrieves dashboards identified by plugin.
 	GetDashboardsByPluginID(ctx context.Context, query *models.GetDashboardsByPluginIdQuery) error
+	GetDashboardTagKeys(ctx context.Context, query *models.GetDashboardTagKeysQuery) error
 	GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error
 	GetProvisionedDashboardData(ctx context.Context, name string) ([]*models.DashboardProvisioning, error)
 	GetProvisionedDataByDashboardID(ctx context.Context, dashboardID int64) (*models.DashboardProvisioning, error)
@@ -78,6 +84,11 @@ type Store interface {
 	// ValidateDashboardBeforeSave validates a dashboard before save.
 	ValidateDashboardBeforeSave(ctx context.Context, dashboard *models.Dashboard, overwrite bool) (bool, error)
 	DeleteACLByUser(context.Context, int64) error
+	// ValidateUIDOwnership validates that the provisioner, or a user if it is empty, owns the UID of the dashboard.
+	ValidateUIDOwnership(ctx context.Context, dashboard *models.Dashboard, provisioner string) error
+	ReserveUID(ctx context.Context, cmd *ReserveUIDCommand) (*UIDReservation, error)
+	GetUIDReservations(ctx context.Context, orgID int64) ([]*UIDReservation, error)
+	DeleteUIDReservation(ctx context.Context, orgID int64, uid string) error
 
 	Count(context.Context, *quota.ScopeParameters) (*quota.Map, error)
 	// CountDashboardsInFolder returns the number of dashboards associated with
@@ -42,6 +42,20 @@ func (_m *FakeDashboardProvisioning) DeleteProvisionedDashboard(ctx context.Cont
 	return r0
 }
 
+// DeleteUIDReservation provides a mock function with given fields: ctx, orgID, uid
+func (_m *FakeDashboardProvisioning) DeleteUIDReservation(ctx context.Context, orgID int64, uid string) error {
+	ret := _m.Called(ctx, orgID, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, orgID, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetProvisionedDashboardData provides a mock function with given fields: ctx, name
 func (_m *FakeDashboardProvisioning) GetProvisionedDashboardData(ctx context.Context, name string) ([]*models.DashboardProvisioning, error) {
 	ret := _m.Called(ctx, name)
@@ -111,6 +125,52 @@ func (_m *FakeDashboardProvisioning) GetProvisionedDashboardDataByDashboardUID(c
 	return r0, r1
 }
 
+// GetUIDReservations provides a mock function with given fields: ctx, orgID
+func (_m *FakeDashboardProvisioning) GetUIDReservations(ctx context.Context, orgID int64) ([]*UIDReservation, error) {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 []*UIDReservation
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*UIDReservation); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*UIDReservation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReserveUID provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardProvisioning) ReserveUID(ctx context.Context, cmd *ReserveUIDCommand) (*UIDReservation, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *UIDReservation
+	if rf, ok := ret.Get(0).(func(context.Context, *ReserveUIDCommand) *UIDReservation); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*UIDReservation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *ReserveUIDCommand) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveFolderForProvisionedDashboards provides a mock function with given fields: _a0, _a1
 func (_m *FakeDashboardProvisioning) SaveFolderForProvisionedDashboards(_a0 context.Context, _a1 *SaveDashboardDTO) (*models.Dashboard, error) {
 	ret := _m.Called(_a0, _a1)
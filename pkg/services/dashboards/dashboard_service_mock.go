@@ -137,6 +137,20 @@ func (_m *FakeDashboardService) GetDashboardACLInfoList(ctx context.Context, que
 	return r0
 }
 
+// GetDashboardTagKeys provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardService) GetDashboardTagKeys(ctx context.Context, query *models.GetDashboardTagKeysQuery) error {
+	ret := _m.Called(ctx, query)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetDashboardTagKeysQuery) error); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetDashboardTags provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardService) GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error {
 	ret := _m.Called(ctx, query)
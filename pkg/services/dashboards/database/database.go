@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"xorm.io/xorm"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
@@ -40,6 +42,8 @@ type DashboardTag struct {
 	Id          int64
 	DashboardId int64
 	Term        string
+	TagKey      string
+	TagValue    string
 }
 
 // DashboardStore implements the Store interface
@@ -251,8 +255,24 @@ func (d *DashboardStore) UpdateDashboardACL(ctx context.Context, dashboardID int
 
 		// Update dashboard HasACL flag
 		dashboard := models.Dashboard{HasACL: true}
-		_, err = sess.Cols("has_acl").Where("id=?", dashboardID).Update(&dashboard)
-		return err
+		if _, err = sess.Cols("has_acl").Where("id=?", dashboardID).Update(&dashboard); err != nil {
+			return err
+		}
+
+		if _, err := sess.Cols("uid", "org_id", "is_folder").Where("id=?", dashboardID).Get(&dashboard); err != nil {
+			return err
+		}
+		resource := "dashboards"
+		if dashboard.IsFolder {
+			resource = "folders"
+		}
+		sess.PublishAfterCommit(&events.ResourcePermissionsUpdated{
+			Timestamp: time.Now(),
+			Resource:  resource,
+			UID:       dashboard.Uid,
+			OrgID:     dashboard.OrgId,
+		})
+		return nil
 	})
 }
 
@@ -483,6 +503,7 @@ func saveDashboard(sess *db.Session, cmd *models.SaveDashboardCommand, emitEntit
 		userId = -1
 	}
 
+	previousFolderID := dash.FolderId
 	if dash.Id > 0 {
 		var existing models.Dashboard
 		dashWithIdExists, err := sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Get(&existing)
@@ -492,6 +513,7 @@ func saveDashboard(sess *db.Session, cmd *models.SaveDashboardCommand, emitEntit
 		if !dashWithIdExists {
 			return dashboards.ErrDashboardNotFound
 		}
+		previousFolderID = existing.FolderId
 
 		// check for is someone else has written in between
 		if dash.Version != existing.Version {
@@ -576,8 +598,9 @@ func saveDashboard(sess *db.Session, cmd *models.SaveDashboardCommand, emitEntit
 	// insert new tags
 	tags := dash.GetTags()
 	if len(tags) > 0 {
-		for _, tag := range tags {
-			if _, err := sess.Insert(DashboardTag{DashboardId: dash.Id, Term: tag}); err != nil {
+		for _, term := range tags {
+			key, value := tag.SplitTerm(term)
+			if _, err := sess.Insert(DashboardTag{DashboardId: dash.Id, Term: term, TagKey: key, TagValue: value}); err != nil {
 				return err
 			}
 		}
@@ -591,6 +614,30 @@ func saveDashboard(sess *db.Session, cmd *models.SaveDashboardCommand, emitEntit
 			return err
 		}
 	}
+
+	if !dash.IsFolder {
+		sess.PublishAfterCommit(&events.DashboardSaved{
+			Timestamp: dash.Updated,
+			Title:     dash.Title,
+			ID:        dash.Id,
+			UID:       dash.Uid,
+			OrgID:     dash.OrgId,
+			FolderID:  dash.FolderId,
+			Version:   dash.Version,
+			UserID:    cmd.UserId,
+
+			PreviousFolderID: previousFolderID,
+		})
+	} else {
+		sess.PublishAfterCommit(&events.FolderSaved{
+			Timestamp: dash.Updated,
+			Title:     dash.Title,
+			ID:        dash.Id,
+			UID:       dash.Uid,
+			OrgID:     dash.OrgId,
+			Version:   dash.Version,
+		})
+	}
 	return nil
 }
 
@@ -778,6 +825,8 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 		"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
 		"DELETE FROM star WHERE dashboard_id = ? ",
 		"DELETE FROM dashboard_public WHERE dashboard_uid = (SELECT uid FROM dashboard WHERE id = ?)",
+		"DELETE FROM resource_label WHERE resource_kind IN ('dashboard', 'folder') AND EXISTS (SELECT 1 FROM dashboard WHERE dashboard.id = ? AND dashboard.org_id = resource_label.org_id AND dashboard.uid = resource_label.resource_uid)",
+		"DELETE FROM dashboard_review_policy WHERE EXISTS (SELECT 1 FROM dashboard WHERE dashboard.id = ? AND dashboard.org_id = dashboard_review_policy.org_id AND dashboard.uid = dashboard_review_policy.folder_uid)",
 		"DELETE FROM dashboard WHERE id = ?",
 		"DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?",
 		"DELETE FROM dashboard_version WHERE dashboard_id = ?",
@@ -790,10 +839,11 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 		deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
 
 		var dashIds []struct {
-			Id  int64
-			Uid string
+			Id    int64
+			Uid   string
+			Title string
 		}
-		err := sess.SQL("SELECT id, uid FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
+		err := sess.SQL("SELECT id, uid, title FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
 		if err != nil {
 			return err
 		}
@@ -816,6 +866,14 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 			if err != nil {
 				return err
 			}
+
+			sess.PublishAfterCommit(&events.DashboardDeleted{
+				Timestamp: time.Now(),
+				Title:     dash.Title,
+				ID:        dash.Id,
+				UID:       dash.Uid,
+				OrgID:     dashboard.OrgId,
+			})
 		}
 
 		if len(dashIds) > 0 {
@@ -827,6 +885,7 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 				"DELETE FROM dashboard_provisioning WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_acl WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_public WHERE dashboard_uid IN (SELECT uid FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM resource_label WHERE resource_kind = 'dashboard' AND EXISTS (SELECT 1 FROM dashboard WHERE dashboard.org_id = ? AND dashboard.folder_id = ? AND dashboard.org_id = resource_label.org_id AND dashboard.uid = resource_label.resource_uid)",
 			}
 			for _, sql := range childrenDeletes {
 				_, err := sess.Exec(sql, dashboard.OrgId, dashboard.Id)
@@ -859,11 +918,27 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 				}
 			}
 		}
+
+		sess.PublishAfterCommit(&events.FolderDeleted{
+			Timestamp: time.Now(),
+			Title:     dashboard.Title,
+			ID:        dashboard.Id,
+			UID:       dashboard.Uid,
+			OrgID:     dashboard.OrgId,
+		})
 	} else {
 		_, err = sess.Exec("DELETE FROM permission WHERE scope = ?", ac.GetResourceScopeUID("dashboards", dashboard.Uid))
 		if err != nil {
 			return err
 		}
+
+		sess.PublishAfterCommit(&events.DashboardDeleted{
+			Timestamp: time.Now(),
+			Title:     dashboard.Title,
+			ID:        dashboard.Id,
+			UID:       dashboard.Uid,
+			OrgID:     dashboard.OrgId,
+		})
 	}
 
 	if err := d.deleteAlertDefinition(dashboard.Id, sess); err != nil {
@@ -1011,7 +1086,23 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 	}
 
 	if len(query.Tags) > 0 {
-		filters = append(filters, searchstore.TagsFilter{Tags: query.Tags})
+		// Tags ending with * are prefix queries on the key or the value of namespaced tags,
+		// all other tags are matched exactly.
+		terms := make([]string, 0, len(query.Tags))
+		prefixes := make([]string, 0)
+		for _, t := range query.Tags {
+			if strings.HasSuffix(strings.TrimSpace(t), "*") {
+				prefixes = append(prefixes, t)
+			} else {
+				terms = append(terms, t)
+			}
+		}
+		if len(terms) > 0 {
+			filters = append(filters, searchstore.TagsFilter{Tags: terms})
+		}
+		for _, filter := range tag.ParseFilters(prefixes) {
+			filters = append(filters, searchstore.TagFilter{Dialect: d.store.GetDialect(), Filter: filter})
+		}
 	}
 
 	if len(query.DashboardUIDs) > 0 {
@@ -1064,22 +1155,45 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 
 func (d *DashboardStore) GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error {
 	return d.store.WithDbSession(ctx, func(dbSession *db.Session) error {
+		params := []interface{}{query.OrgId}
+		keyFilter := ""
+		if query.Key != "" {
+			keyFilter = "AND dashboard_tag.tag_key = ?"
+			params = append(params, query.Key)
+		}
+
 		sql := `SELECT
 					  COUNT(*) as count,
 						term
 					FROM dashboard
 					INNER JOIN dashboard_tag on dashboard_tag.dashboard_id = dashboard.id
-					WHERE dashboard.org_id=?
+					WHERE dashboard.org_id=? ` + keyFilter + `
 					GROUP BY term
 					ORDER BY term`
 
 		query.Result = make([]*models.DashboardTagCloudItem, 0)
-		sess := dbSession.SQL(sql, query.OrgId)
+		sess := dbSession.SQL(sql, params...)
 		err := sess.Find(&query.Result)
 		return err
 	})
 }
 
+func (d *DashboardStore) GetDashboardTagKeys(ctx context.Context, query *models.GetDashboardTagKeysQuery) error {
+	return d.store.WithDbSession(ctx, func(dbSession *db.Session) error {
+		sql := `SELECT
+					  COUNT(DISTINCT dashboard.id) as count,
+						dashboard_tag.tag_key as ` + d.store.GetDialect().Quote("key") + `
+					FROM dashboard
+					INNER JOIN dashboard_tag on dashboard_tag.dashboard_id = dashboard.id
+					WHERE dashboard.org_id=? AND dashboard_tag.tag_key ` + d.store.GetDialect().LikeStr() + ` ? AND dashboard_tag.tag_value <> ''
+					GROUP BY dashboard_tag.tag_key
+					ORDER BY dashboard_tag.tag_key`
+
+		query.Result = make([]*models.DashboardTagKeyItem, 0)
+		return dbSession.SQL(sql, query.OrgId, query.Prefix+"%").Find(&query.Result)
+	})
+}
+
 // CountDashboardsInFolder returns a count of all dashboards associated with the
 // given parent folder ID.
 //
@@ -19,6 +19,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
 	publicDashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
+	"github.com/grafana/grafana/pkg/services/resourcelabel/resourcelabelimpl"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/star"
@@ -388,6 +390,61 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 		require.Equal(t, len(query.Result), 2)
 	})
 
+	t.Run("Should be able to get namespaced dashboard tags", func(t *testing.T) {
+		setup()
+		insertTestDashboard(t, dashboardStore, "env dash", 1, 0, false, "env:prod", "env:dev", "team:ops")
+
+		query := models.GetDashboardTagsQuery{OrgId: 1, Key: "env"}
+		err := dashboardStore.GetDashboardTags(context.Background(), &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 2)
+		require.Equal(t, "env:dev", query.Result[0].Term)
+		require.Equal(t, "env:prod", query.Result[1].Term)
+
+		keysQuery := models.GetDashboardTagKeysQuery{OrgId: 1}
+		err = dashboardStore.GetDashboardTagKeys(context.Background(), &keysQuery)
+		require.NoError(t, err)
+		require.Len(t, keysQuery.Result, 2)
+		require.Equal(t, "env", keysQuery.Result[0].Key)
+		require.Equal(t, 1, keysQuery.Result[0].Count)
+		require.Equal(t, "team", keysQuery.Result[1].Key)
+
+		keysQuery = models.GetDashboardTagKeysQuery{OrgId: 1, Prefix: "te"}
+		err = dashboardStore.GetDashboardTagKeys(context.Background(), &keysQuery)
+		require.NoError(t, err)
+		require.Len(t, keysQuery.Result, 1)
+		require.Equal(t, "team", keysQuery.Result[0].Key)
+	})
+
+	t.Run("Should be able to search dashboards by tag prefix", func(t *testing.T) {
+		setup()
+		insertTestDashboard(t, dashboardStore, "env dash", 1, 0, false, "env:prod-eu", "team:ops")
+		insertTestDashboard(t, dashboardStore, "other env dash", 1, 0, false, "env:dev", "team:ops")
+		signedInUser := &user.SignedInUser{
+			OrgID:   1,
+			OrgRole: org.RoleEditor,
+			Permissions: map[int64]map[string][]string{
+				1: {dashboards.ActionDashboardsRead: []string{dashboards.ScopeDashboardsAll}},
+			},
+		}
+
+		query := models.FindPersistedDashboardsQuery{OrgId: 1, Tags: []string{"env:prod*"}, SignedInUser: signedInUser}
+		err := testSearchDashboards(dashboardStore, &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 1)
+		require.Equal(t, "env dash", query.Result[0].Title)
+
+		query = models.FindPersistedDashboardsQuery{OrgId: 1, Tags: []string{"env:*", "team:ops"}, SignedInUser: signedInUser}
+		err = testSearchDashboards(dashboardStore, &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 2)
+
+		query = models.FindPersistedDashboardsQuery{OrgId: 1, Tags: []string{"env:prod*", "env:dev*"}, SignedInUser: signedInUser}
+		err = testSearchDashboards(dashboardStore, &query)
+		require.NoError(t, err)
+		require.Empty(t, query.Result)
+	})
+
 	t.Run("Should be able to find dashboard folder", func(t *testing.T) {
 		setup()
 		query := models.FindPersistedDashboardsQuery{
@@ -709,6 +766,47 @@ func TestIntegrationDashboard_Filter(t *testing.T) {
 	assert.Equal(t, dashB.Id, results[0].ID)
 }
 
+func TestIntegrationDashboard_LabelFilter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+	dashboardStore, err := ProvideDashboardStore(sqlStore, cfg, testFeatureToggles, tagimpl.ProvideService(sqlStore, cfg), quotatest.New(false, nil))
+	require.NoError(t, err)
+	labelService := resourcelabelimpl.ProvideService(sqlStore)
+	ctx := context.Background()
+
+	insertTestDashboard(t, dashboardStore, "Alfa", 1, 0, false)
+	dashB := insertTestDashboard(t, dashboardStore, "Beta", 1, 0, false)
+	require.NoError(t, labelService.Set(ctx, &resourcelabel.SetLabelsCommand{
+		OrgID: 1, Kind: resourcelabel.KindDashboard, UID: dashB.Uid, Labels: resourcelabel.Labels{"team": "checkout"},
+	}))
+
+	selector, err := resourcelabel.ParseSelector("team=checkout")
+	require.NoError(t, err)
+	results, err := dashboardStore.FindDashboards(ctx, &models.FindPersistedDashboardsQuery{
+		SignedInUser: &user.SignedInUser{
+			OrgID:   1,
+			UserID:  1,
+			OrgRole: org.RoleAdmin,
+			Permissions: map[int64]map[string][]string{
+				1: {dashboards.ActionDashboardsRead: []string{dashboards.ScopeDashboardsAll}},
+			},
+		},
+		Filters: []interface{}{searchstore.LabelFilter{Selector: selector}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, dashB.Id, results[0].ID)
+
+	require.NoError(t, dashboardStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dashB.Id, OrgId: 1}))
+	labels, err := labelService.Get(ctx, &resourcelabel.GetLabelsQuery{OrgID: 1, Kind: resourcelabel.KindDashboard, UID: dashB.Uid})
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+}
+
 func insertTestRule(t *testing.T, sqlStore sqlstore.Store, foderOrgID int64, folderUID string) {
 	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
 		type alertQuery struct {
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+// ValidateUIDOwnership checks that the dashboard may be saved with its UID by the provisioner, or by a
+// user if the provisioner is empty. A reserved UID can only be used by the provisioner it is
+// reserved for, and a provisioner can't take over a dashboard it doesn't provision.
+func (d *DashboardStore) ValidateUIDOwnership(ctx context.Context, dashboard *models.Dashboard, provisioner string) error {
+	if dashboard.Uid == "" {
+		return nil
+	}
+
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		kind := reservationKind(dashboard.IsFolder)
+		reservation, err := getUIDReservation(sess, dashboard.OrgId, dashboard.Uid)
+		if err != nil {
+			return err
+		}
+		if reservation != nil {
+			if reservation.Kind != kind {
+				return dashboards.ErrDashboardUIDReservedForOtherKind
+			}
+			if reservation.Owner != provisioner {
+				return fmt.Errorf("%w: the uid %q is reserved for %q", dashboards.ErrDashboardUIDReserved, dashboard.Uid, reservation.Owner)
+			}
+		}
+
+		// the provisioned dashboards are protected from users by the provisioning already, and folders
+		// aren't recorded as provisioned, so they are only protected by reservations
+		if provisioner == "" || dashboard.IsFolder {
+			return nil
+		}
+
+		conflict, err := getUIDConflict(sess, dashboard.OrgId, dashboard.Uid, kind, provisioner)
+		if err != nil {
+			return err
+		}
+		if conflict != "" {
+			return fmt.Errorf("%w: %s", dashboards.ErrDashboardUIDOwnedByOther, conflict)
+		}
+		return nil
+	})
+}
+
+// ReserveUID reserves a UID for a provisioner. Reserving a UID the provisioner has reserved already
+// returns the existing reservation.
+func (d *DashboardStore) ReserveUID(ctx context.Context, cmd *dashboards.ReserveUIDCommand) (*dashboards.UIDReservation, error) {
+	var reservation *dashboards.UIDReservation
+	err := d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := getUIDReservation(sess, cmd.OrgID, cmd.UID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if existing.Owner != cmd.Owner {
+				return fmt.Errorf("%w: the uid %q is reserved for %q", dashboards.ErrDashboardUIDReserved, cmd.UID, existing.Owner)
+			}
+			if existing.Kind != cmd.Kind {
+				return dashboards.ErrDashboardUIDReservedForOtherKind
+			}
+			reservation = existing
+			return nil
+		}
+
+		conflict, err := getUIDConflict(sess, cmd.OrgID, cmd.UID, cmd.Kind, cmd.Owner)
+		if err != nil {
+			return err
+		}
+		if conflict != "" {
+			return fmt.Errorf("%w: %s", dashboards.ErrDashboardUIDOwnedByOther, conflict)
+		}
+
+		reservation = &dashboards.UIDReservation{
+			OrgID:     cmd.OrgID,
+			UID:       cmd.UID,
+			Kind:      cmd.Kind,
+			Owner:     cmd.Owner,
+			CreatedBy: cmd.CreatedBy,
+			Created:   time.Now(),
+		}
+		_, err = sess.Insert(reservation)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// GetUIDReservations returns the UID reservations of the organization, with the conflicting
+// dashboards and folders using their UIDs.
+func (d *DashboardStore) GetUIDReservations(ctx context.Context, orgID int64) ([]*dashboards.UIDReservation, error) {
+	reservations := make([]*dashboards.UIDReservation, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if err := sess.Where("org_id = ?", orgID).Asc("uid").Find(&reservations); err != nil {
+			return err
+		}
+
+		for _, reservation := range reservations {
+			conflict, err := getUIDConflict(sess, orgID, reservation.UID, reservation.Kind, reservation.Owner)
+			if err != nil {
+				return err
+			}
+			reservation.Conflict = conflict
+		}
+		return nil
+	})
+	return reservations, err
+}
+
+// DeleteUIDReservation releases the reservation of a UID.
+func (d *DashboardStore) DeleteUIDReservation(ctx context.Context, orgID int64, uid string) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&dashboards.UIDReservation{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return dashboards.ErrDashboardUIDReservationNotFound
+		}
+		return nil
+	})
+}
+
+func getUIDReservation(sess *db.Session, orgID int64, uid string) (*dashboards.UIDReservation, error) {
+	var reservation dashboards.UIDReservation
+	has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&reservation)
+	if err != nil || !has {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// getUIDConflict describes the dashboard or folder using the UID if the owner can't save it as the
+// kind, or returns an empty string.
+func getUIDConflict(sess *db.Session, orgID int64, uid string, kind dashboards.UIDReservationKind, owner string) (string, error) {
+	var existing struct {
+		Title       string `xorm:"title"`
+		IsFolder    bool   `xorm:"is_folder"`
+		Provisioner string `xorm:"provisioner"`
+	}
+	has, err := sess.SQL(`SELECT dashboard.title, dashboard.is_folder, COALESCE(dashboard_provisioning.name, '') AS provisioner
+		FROM dashboard
+		LEFT JOIN dashboard_provisioning ON dashboard_provisioning.dashboard_id = dashboard.id
+		WHERE dashboard.org_id = ? AND dashboard.uid = ?`, orgID, uid).Get(&existing)
+	if err != nil || !has {
+		return "", err
+	}
+
+	existingKind := reservationKind(existing.IsFolder)
+	switch {
+	case existingKind != kind:
+		return fmt.Sprintf("the uid is used by the %s %q", existingKind, existing.Title), nil
+	case kind == dashboards.UIDReservationKindFolder:
+		return "", nil
+	case existing.Provisioner == "":
+		return fmt.Sprintf("the dashboard %q using the uid is not provisioned", existing.Title), nil
+	case existing.Provisioner != owner:
+		return fmt.Sprintf("the dashboard %q using the uid is provisioned by %q", existing.Title, existing.Provisioner), nil
+	}
+	return "", nil
+}
+
+func reservationKind(isFolder bool) dashboards.UIDReservationKind {
+	if isFolder {
+		return dashboards.UIDReservationKindFolder
+	}
+	return dashboards.UIDReservationKindDashboard
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+)
+
+func TestIntegrationDashboardUIDReservations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	quotaService := quotatest.New(false, nil)
+	dashboardStore, err := ProvideDashboardStore(sqlStore, sqlStore.Cfg, testFeatureToggles, tagimpl.ProvideService(sqlStore, sqlStore.Cfg), quotaService)
+	require.NoError(t, err)
+
+	userDash := insertTestDashboard(t, dashboardStore, "user dashboard", 1, 0, false)
+	provisionedDash, err := dashboardStore.SaveProvisionedDashboard(context.Background(), models.SaveDashboardCommand{
+		OrgId: 1,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"title": "provisioned dashboard",
+			"uid":   "gitops-dash",
+		}),
+	}, &models.DashboardProvisioning{Name: "gitops", ExternalId: "/var/dash.json", Updated: time.Now().Unix()})
+	require.NoError(t, err)
+
+	t.Run("Should reserve a uid for the provisioner of the dashboard using it", func(t *testing.T) {
+		reservation, err := dashboardStore.ReserveUID(context.Background(), &dashboards.ReserveUIDCommand{
+			OrgID: 1, UID: provisionedDash.Uid, Kind: dashboards.UIDReservationKindDashboard, Owner: "gitops",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "gitops", reservation.Owner)
+
+		again, err := dashboardStore.ReserveUID(context.Background(), &dashboards.ReserveUIDCommand{
+			OrgID: 1, UID: provisionedDash.Uid, Kind: dashboards.UIDReservationKindDashboard, Owner: "gitops",
+		})
+		require.NoError(t, err)
+		require.Equal(t, reservation.ID, again.ID)
+	})
+
+	t.Run("Should not reserve a uid reserved for another provisioner", func(t *testing.T) {
+		_, err := dashboardStore.ReserveUID(context.Background(), &dashboards.ReserveUIDCommand{
+			OrgID: 1, UID: provisionedDash.Uid, Kind: dashboards.UIDReservationKindDashboard, Owner: "other",
+		})
+		require.ErrorIs(t, err, dashboards.ErrDashboardUIDReserved)
+	})
+
+	t.Run("Should not reserve a uid used by a user dashboard", func(t *testing.T) {
+		_, err := dashboardStore.ReserveUID(context.Background(), &dashboards.ReserveUIDCommand{
+			OrgID: 1, UID: userDash.Uid, Kind: dashboards.UIDReservationKindDashboard, Owner: "gitops",
+		})
+		require.ErrorIs(t, err, dashboards.ErrDashboardUIDOwnedByOther)
+	})
+
+	t.Run("Should only let the owner save a dashboard with a reserved uid", func(t *testing.T) {
+		_, err := dashboardStore.ReserveUID(context.Background(), &dashboards.ReserveUIDCommand{
+			OrgID: 1, UID: "reserved", Kind: dashboards.UIDReservationKindDashboard, Owner: "gitops",
+		})
+		require.NoError(t, err)
+
+		dash := &models.Dashboard{OrgId: 1, Uid: "reserved"}
+		require.ErrorIs(t, dashboardStore.ValidateUIDOwnership(context.Background(), dash, ""), dashboards.ErrDashboardUIDReserved)
+		require.ErrorIs(t, dashboardStore.ValidateUIDOwnership(context.Background(), dash, "other"), dashboards.ErrDashboardUIDReserved)
+		require.NoError(t, dashboardStore.ValidateUIDOwnership(context.Background(), dash, "gitops"))
+
+		folder := &models.Dashboard{OrgId: 1, Uid: "reserved", IsFolder: true}
+		require.ErrorIs(t, dashboardStore.ValidateUIDOwnership(context.Background(), folder, "gitops"), dashboards.ErrDashboardUIDReservedForOtherKind)
+	})
+
+	t.Run("Should not let a provisioner take over a user dashboard", func(t *testing.T) {
+		dash := &models.Dashboard{OrgId: 1, Uid: userDash.Uid}
+		require.ErrorIs(t, dashboardStore.ValidateUIDOwnership(context.Background(), dash, "gitops"), dashboards.ErrDashboardUIDOwnedByOther)
+		require.NoError(t, dashboardStore.ValidateUIDOwnership(context.Background(), dash, ""))
+	})
+
+	t.Run("Should report conflicts and delete reservations", func(t *testing.T) {
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(&dashboards.UIDReservation{
+				OrgID: 1, UID: userDash.Uid, Kind: dashboards.UIDReservationKindDashboard, Owner: "gitops", Created: time.Now(),
+			})
+			return err
+		})
+		require.NoError(t, err)
+
+		reservations, err := dashboardStore.GetUIDReservations(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, reservations, 3)
+		for _, reservation := range reservations {
+			if reservation.UID == userDash.Uid {
+				require.Contains(t, reservation.Conflict, "not provisioned")
+			} else {
+				require.Empty(t, reservation.Conflict)
+			}
+		}
+
+		require.NoError(t, dashboardStore.DeleteUIDReservation(context.Background(), 1, "reserved"))
+		require.ErrorIs(t, dashboardStore.DeleteUIDReservation(context.Background(), 1, "reserved"), dashboards.ErrDashboardUIDReservationNotFound)
+	})
+}
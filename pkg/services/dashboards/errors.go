@@ -91,6 +91,29 @@ var (
 		Reason:     "Cannot save provisioned dashboard",
 		StatusCode: 400,
 	}
+	ErrDashboardUIDReserved = DashboardErr{
+		Reason:     "The uid is reserved for a provisioner",
+		StatusCode: 412,
+		Status:     "uid-reserved",
+	}
+	ErrDashboardUIDReservedForOtherKind = DashboardErr{
+		Reason:     "The uid is reserved for another kind of resource",
+		StatusCode: 400,
+	}
+	ErrDashboardUIDOwnedByOther = DashboardErr{
+		Reason:     "A dashboard with the same uid is not owned by the provisioner",
+		StatusCode: 412,
+		Status:     "uid-conflict",
+	}
+	ErrDashboardUIDReservationInvalid = DashboardErr{
+		Reason:     "A uid reservation needs an owner and the kind dashboard or folder",
+		StatusCode: 400,
+	}
+	ErrDashboardUIDReservationNotFound = DashboardErr{
+		Reason:     "UID reservation not found",
+		StatusCode: 404,
+		Status:     "not-found",
+	}
 	ErrDashboardRefreshIntervalTooShort = DashboardErr{
 		Reason:     "Dashboard refresh interval is too low",
 		StatusCode: 400,
@@ -8,6 +8,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// CatalogProvisionerName is the provisioner name of the read-only copies of the dashboards
+// subscribed to from the dashboard catalog.
+const CatalogProvisionerName = "dashboard-catalog"
+
 type SaveDashboardDTO struct {
 	OrgId     int64
 	UpdatedAt time.Time
@@ -15,6 +19,49 @@ type SaveDashboardDTO struct {
 	Message   string
 	Overwrite bool
 	Dashboard *models.Dashboard
+	// Provisioner is the name of the provisioner saving the dashboard, it is empty for users.
+	Provisioner string
+}
+
+// UIDReservationKind is the kind of resource a UID is reserved for.
+type UIDReservationKind string
+
+const (
+	UIDReservationKindDashboard UIDReservationKind = "dashboard"
+	UIDReservationKindFolder    UIDReservationKind = "folder"
+)
+
+// IsValid returns whether the kind is a known kind.
+func (k UIDReservationKind) IsValid() bool {
+	return k == UIDReservationKindDashboard || k == UIDReservationKindFolder
+}
+
+// UIDReservation reserves the UID of a dashboard or folder of an organization for a provisioner, so
+// that only the provisioner can save a dashboard or folder with it.
+type UIDReservation struct {
+	ID        int64              `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID     int64              `json:"-" xorm:"org_id"`
+	UID       string             `json:"uid" xorm:"uid"`
+	Kind      UIDReservationKind `json:"kind" xorm:"kind"`
+	Owner     string             `json:"owner" xorm:"owner"`
+	CreatedBy int64              `json:"createdBy" xorm:"created_by"`
+	Created   time.Time          `json:"created" xorm:"created"`
+	// Conflict describes the dashboard or folder using the UID without being owned by the
+	// provisioner the UID is reserved for, it is empty if there is none.
+	Conflict string `json:"conflict,omitempty" xorm:"-"`
+}
+
+func (r UIDReservation) TableName() string {
+	return "dashboard_uid_reservation"
+}
+
+// ReserveUIDCommand reserves a UID for a provisioner.
+type ReserveUIDCommand struct {
+	OrgID     int64              `json:"-"`
+	UID       string             `json:"uid"`
+	Kind      UIDReservationKind `json:"kind"`
+	Owner     string             `json:"owner"`
+	CreatedBy int64              `json:"-"`
 }
 
 type DashboardSearchProjection struct {
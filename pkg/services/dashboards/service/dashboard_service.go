@@ -75,6 +75,31 @@ func (dr *DashboardServiceImpl) GetProvisionedDashboardDataByDashboardUID(ctx co
 	return dr.dashboardStore.GetProvisionedDataByDashboardUID(ctx, orgID, dashboardUID)
 }
 
+// ReserveUID reserves a UID of a dashboard or folder for a provisioner, so that neither users nor other
+// provisioners can save a dashboard or folder with it.
+func (dr *DashboardServiceImpl) ReserveUID(ctx context.Context, cmd *dashboards.ReserveUIDCommand) (*dashboards.UIDReservation, error) {
+	cmd.UID = strings.TrimSpace(cmd.UID)
+	cmd.Owner = strings.TrimSpace(cmd.Owner)
+	if cmd.UID == "" || !util.IsValidShortUID(cmd.UID) {
+		return nil, dashboards.ErrDashboardInvalidUid
+	} else if util.IsShortUIDTooLong(cmd.UID) {
+		return nil, dashboards.ErrDashboardUidTooLong
+	}
+	if !cmd.Kind.IsValid() || cmd.Owner == "" {
+		return nil, dashboards.ErrDashboardUIDReservationInvalid
+	}
+
+	return dr.dashboardStore.ReserveUID(ctx, cmd)
+}
+
+func (dr *DashboardServiceImpl) GetUIDReservations(ctx context.Context, orgID int64) ([]*dashboards.UIDReservation, error) {
+	return dr.dashboardStore.GetUIDReservations(ctx, orgID)
+}
+
+func (dr *DashboardServiceImpl) DeleteUIDReservation(ctx context.Context, orgID int64, uid string) error {
+	return dr.dashboardStore.DeleteUIDReservation(ctx, orgID, uid)
+}
+
 func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, dto *dashboards.SaveDashboardDTO, shouldValidateAlerts bool,
 	validateProvisionedDashboard bool) (*models.SaveDashboardCommand, error) {
 	dash := dto.Dashboard
@@ -106,6 +131,10 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		return nil, err
 	}
 
+	if err := dr.dashboardStore.ValidateUIDOwnership(ctx, dash, dto.Provisioner); err != nil {
+		return nil, err
+	}
+
 	if shouldValidateAlerts {
 		dashAlertInfo := alerting.DashAlertInfo{Dash: dash, User: dto.User, OrgID: dash.OrgId}
 		if err := dr.dashAlertExtractor.ValidateAlerts(ctx, dashAlertInfo); err != nil {
@@ -219,6 +248,9 @@ func (dr *DashboardServiceImpl) SaveProvisionedDashboard(ctx context.Context, dt
 	}
 
 	dto.User = accesscontrol.BackgroundUser("dashboard_provisioning", dto.OrgId, org.RoleAdmin, provisionerPermissions)
+	if provisioning != nil {
+		dto.Provisioner = provisioning.Name
+	}
 
 	cmd, err := dr.BuildSaveDashboardCommand(ctx, dto, setting.IsLegacyAlertingEnabled(), false)
 	if err != nil {
@@ -585,6 +617,10 @@ func (dr *DashboardServiceImpl) GetDashboardTags(ctx context.Context, query *mod
 	return dr.dashboardStore.GetDashboardTags(ctx, query)
 }
 
+func (dr *DashboardServiceImpl) GetDashboardTagKeys(ctx context.Context, query *models.GetDashboardTagKeysQuery) error {
+	return dr.dashboardStore.GetDashboardTagKeys(ctx, query)
+}
+
 func (dr *DashboardServiceImpl) DeleteACLByUser(ctx context.Context, userID int64) error {
 	return dr.dashboardStore.DeleteACLByUser(ctx, userID)
 }
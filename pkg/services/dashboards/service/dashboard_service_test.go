@@ -24,6 +24,7 @@ func TestDashboardService(t *testing.T) {
 	t.Run("Dashboard service tests", func(t *testing.T) {
 		fakeStore := dashboards.FakeDashboardStore{}
 		defer fakeStore.AssertExpectations(t)
+		fakeStore.On("ValidateUIDOwnership", mock.Anything, mock.Anything, mock.AnythingOfType("string")).Return(nil).Maybe()
 
 		service := &DashboardServiceImpl{
 			cfg:                setting.NewCfg(),
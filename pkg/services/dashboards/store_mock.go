@@ -79,6 +79,20 @@ func (_m *FakeDashboardStore) DeleteOrphanedProvisionedDashboards(ctx context.Co
 	return r0
 }
 
+// DeleteUIDReservation provides a mock function with given fields: ctx, orgID, uid
+func (_m *FakeDashboardStore) DeleteUIDReservation(ctx context.Context, orgID int64, uid string) error {
+	ret := _m.Called(ctx, orgID, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, orgID, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FindDashboards provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardStore) FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error) {
 	ret := _m.Called(ctx, query)
@@ -139,6 +153,20 @@ func (_m *FakeDashboardStore) GetDashboardACLInfoList(ctx context.Context, query
 	return r0
 }
 
+// GetDashboardTagKeys provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardStore) GetDashboardTagKeys(ctx context.Context, query *models.GetDashboardTagKeysQuery) error {
+	ret := _m.Called(ctx, query)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetDashboardTagKeysQuery) error); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetDashboardTags provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardStore) GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error {
 	ret := _m.Called(ctx, query)
@@ -333,6 +361,29 @@ func (_m *FakeDashboardStore) GetProvisionedDataByDashboardUID(ctx context.Conte
 	return r0, r1
 }
 
+// GetUIDReservations provides a mock function with given fields: ctx, orgID
+func (_m *FakeDashboardStore) GetUIDReservations(ctx context.Context, orgID int64) ([]*UIDReservation, error) {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 []*UIDReservation
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*UIDReservation); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*UIDReservation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HasAdminPermissionInDashboardsOrFolders provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardStore) HasAdminPermissionInDashboardsOrFolders(ctx context.Context, query *models.HasAdminPermissionInDashboardsOrFoldersQuery) error {
 	ret := _m.Called(ctx, query)
@@ -361,6 +412,29 @@ func (_m *FakeDashboardStore) HasEditPermissionInFolders(ctx context.Context, qu
 	return r0
 }
 
+// ReserveUID provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardStore) ReserveUID(ctx context.Context, cmd *ReserveUIDCommand) (*UIDReservation, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *UIDReservation
+	if rf, ok := ret.Get(0).(func(context.Context, *ReserveUIDCommand) *UIDReservation); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*UIDReservation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *ReserveUIDCommand) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveAlerts provides a mock function with given fields: ctx, dashID, alerts
 func (_m *FakeDashboardStore) SaveAlerts(ctx context.Context, dashID int64, alerts []*models.Alert) error {
 	ret := _m.Called(ctx, dashID, alerts)
@@ -470,6 +544,20 @@ func (_m *FakeDashboardStore) ValidateDashboardBeforeSave(ctx context.Context, d
 	return r0, r1
 }
 
+// ValidateUIDOwnership provides a mock function with given fields: ctx, dashboard, provisioner
+func (_m *FakeDashboardStore) ValidateUIDOwnership(ctx context.Context, dashboard *models.Dashboard, provisioner string) error {
+	ret := _m.Called(ctx, dashboard, provisioner)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Dashboard, string) error); ok {
+		r0 = rf(ctx, dashboard, provisioner)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewFakeDashboardStore interface {
 	mock.TestingT
 	Cleanup(func())
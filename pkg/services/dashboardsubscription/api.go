@@ -0,0 +1,124 @@
+package dashboardsubscription
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *SubscriptionService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.routeRegister.Group("/api/user/subscriptions", func(subscriptionRoute routing.RouteRegister) {
+		subscriptionRoute.Get("/", routing.Wrap(s.getSubscriptionsHandler))
+		subscriptionRoute.Put("/dashboard/uid/:uid", authorize(middleware.ReqSignedInNoAnonymous, ac.EvalPermission(dashboards.ActionDashboardsRead, uidScope)), routing.Wrap(s.subscribeHandler))
+		subscriptionRoute.Delete("/dashboard/uid/:uid", routing.Wrap(s.unsubscribeHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route GET /user/subscriptions signed_in_user getSignedInUserDashboardSubscriptions
+//
+// Get the dashboard subscriptions of the signed in user.
+//
+// Returns the dashboards of the current organization the signed in user is notified about, ordered by title.
+//
+// Responses:
+// 200: getDashboardSubscriptionsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *SubscriptionService) getSubscriptionsHandler(c *models.ReqContext) response.Response {
+	subscriptions, err := s.GetUserSubscriptions(c.Req.Context(), c.OrgID, c.UserID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard subscriptions", err)
+	}
+	return response.JSON(http.StatusOK, subscriptions)
+}
+
+// swagger:route PUT /user/subscriptions/dashboard/uid/{uid} signed_in_user subscribeToDashboard
+//
+// Subscribe to a dashboard.
+//
+// The signed in user is notified when the dashboard is modified, moved or deleted by someone else. Subscribing again changes whether notifications are also sent by email.
+//
+// Responses:
+// 200: subscribeToDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *SubscriptionService) subscribeHandler(c *models.ReqContext) response.Response {
+	cmd := SubscribeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.UserID = c.UserID
+	cmd.DashboardUID = web.Params(c.Req)[":uid"]
+
+	sub, err := s.Subscribe(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to subscribe to dashboard", err)
+	}
+	return response.JSON(http.StatusOK, sub)
+}
+
+// swagger:route DELETE /user/subscriptions/dashboard/uid/{uid} signed_in_user unsubscribeFromDashboard
+//
+// Unsubscribe from a dashboard.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *SubscriptionService) unsubscribeHandler(c *models.ReqContext) response.Response {
+	err := s.Unsubscribe(c.Req.Context(), c.OrgID, c.UserID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, ErrSubscriptionNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard subscription not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to unsubscribe from dashboard", err)
+	}
+	return response.Success("Unsubscribed from dashboard")
+}
+
+// swagger:parameters subscribeToDashboard
+type SubscribeToDashboardParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body SubscribeCommand `json:"body"`
+}
+
+// swagger:parameters unsubscribeFromDashboard
+type UnsubscribeFromDashboardParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response getDashboardSubscriptionsResponse
+type GetDashboardSubscriptionsResponse struct {
+	// in:body
+	Body []*SubscriptionDTO `json:"body"`
+}
+
+// swagger:response subscribeToDashboardResponse
+type SubscribeToDashboardResponse struct {
+	// in:body
+	Body *Subscription `json:"body"`
+}
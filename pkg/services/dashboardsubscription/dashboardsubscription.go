@@ -0,0 +1,147 @@
+// Package dashboardsubscription lets users subscribe to dashboards, to be notified when they are
+// modified, moved or deleted.
+package dashboardsubscription
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ErrSubscriptionNotFound = errors.New("dashboard subscription not found")
+
+// Subscription notifies a user in the notification center when a dashboard is modified, moved or
+// deleted, and by email when Email is set.
+type Subscription struct {
+	ID           int64     `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID        int64     `json:"orgId" xorm:"org_id"`
+	UserID       int64     `json:"userId" xorm:"user_id"`
+	DashboardUID string    `json:"dashboardUid" xorm:"dashboard_uid"`
+	Email        bool      `json:"email" xorm:"email"`
+	Created      time.Time `json:"created" xorm:"created"`
+}
+
+func (s Subscription) TableName() string {
+	return "dashboard_subscription"
+}
+
+// SubscriptionDTO is a subscription together with the title and URL of its dashboard.
+// swagger:model
+type SubscriptionDTO struct {
+	Subscription
+	DashboardTitle string `json:"dashboardTitle"`
+	DashboardURL   string `json:"dashboardUrl"`
+}
+
+// SubscribeCommand subscribes a user to a dashboard, or changes the subscription if it exists.
+// swagger:model
+type SubscribeCommand struct {
+	OrgID        int64  `json:"-"`
+	UserID       int64  `json:"-"`
+	DashboardUID string `json:"-"`
+	// Email also notifies the user by email.
+	Email bool `json:"email"`
+}
+
+type Service interface {
+	Subscribe(ctx context.Context, cmd SubscribeCommand) (*Subscription, error)
+	Unsubscribe(ctx context.Context, orgID, userID int64, dashboardUID string) error
+	// GetUserSubscriptions returns the subscriptions of a user in an organization, ordered by dashboard title.
+	GetUserSubscriptions(ctx context.Context, orgID, userID int64) ([]*SubscriptionDTO, error)
+}
+
+type SubscriptionService struct {
+	store            db.DB
+	cfg              *setting.Cfg
+	log              log.Logger
+	dashboardService dashboards.DashboardService
+	notifications    notificationcenter.Service
+	emailSender      notifications.EmailSender
+	routeRegister    routing.RouteRegister
+	accessControl    accesscontrol.AccessControl
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, bus bus.Bus, dashboardService dashboards.DashboardService,
+	notificationCenter notificationcenter.Service, emailSender notifications.EmailSender,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *SubscriptionService {
+	s := &SubscriptionService{
+		store:            sqlStore,
+		cfg:              cfg,
+		log:              log.New("dashboard-subscription"),
+		dashboardService: dashboardService,
+		notifications:    notificationCenter,
+		emailSender:      emailSender,
+		routeRegister:    routeRegister,
+		accessControl:    accessControl,
+	}
+
+	bus.AddEventListener(s.handleDashboardSaved)
+	bus.AddEventListener(s.handleDashboardDeleted)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *SubscriptionService) Subscribe(ctx context.Context, cmd SubscribeCommand) (*Subscription, error) {
+	query := &models.GetDashboardQuery{Uid: cmd.DashboardUID, OrgId: cmd.OrgID}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return nil, err
+	}
+	if query.Result.IsFolder {
+		return nil, dashboards.ErrDashboardNotFound
+	}
+
+	sub := &Subscription{
+		OrgID:        cmd.OrgID,
+		UserID:       cmd.UserID,
+		DashboardUID: cmd.DashboardUID,
+		Email:        cmd.Email,
+		Created:      time.Now(),
+	}
+	if err := s.upsert(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *SubscriptionService) Unsubscribe(ctx context.Context, orgID, userID int64, dashboardUID string) error {
+	return s.delete(ctx, orgID, userID, dashboardUID)
+}
+
+func (s *SubscriptionService) GetUserSubscriptions(ctx context.Context, orgID, userID int64) ([]*SubscriptionDTO, error) {
+	return s.getUserSubscriptions(ctx, orgID, userID)
+}
+
+func (s *SubscriptionService) handleDashboardSaved(ctx context.Context, e *events.DashboardSaved) error {
+	// subscriptions can only exist for dashboards which were saved before
+	if e.Version <= 1 {
+		return nil
+	}
+
+	change := changeModified
+	if e.FolderID != e.PreviousFolderID {
+		change = changeMoved
+	}
+	s.notify(ctx, e.OrgID, e.UID, e.Title, change, e.UserID)
+	return nil
+}
+
+func (s *SubscriptionService) handleDashboardDeleted(ctx context.Context, e *events.DashboardDeleted) error {
+	s.notify(ctx, e.OrgID, e.UID, e.Title, changeDeleted, 0)
+	if err := s.deleteDashboardSubscriptions(ctx, e.OrgID, e.UID); err != nil {
+		s.log.Error("Failed to delete subscriptions of deleted dashboard", "orgId", e.OrgID, "dashboardUid", e.UID, "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package dashboardsubscription
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeNotificationCenter struct {
+	commands []notificationcenter.NotifyCommand
+}
+
+func (f *fakeNotificationCenter) Notify(_ context.Context, cmd notificationcenter.NotifyCommand) (int, error) {
+	f.commands = append(f.commands, cmd)
+	return len(cmd.UserIDs), nil
+}
+
+func TestIntegrationDashboardSubscriptions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	sqlStore.Cfg.AutoAssignOrg = true
+	sqlStore.Cfg.AutoAssignOrgId = 1
+	ctx := context.Background()
+
+	owner, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: "owner", Email: "owner@example.com"})
+	require.NoError(t, err)
+	viewer, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: "viewer", Email: "viewer@example.com", Name: "Viewer"})
+	require.NoError(t, err)
+
+	dash := &models.Dashboard{
+		Uid:     "dash",
+		OrgId:   owner.OrgID,
+		Title:   "Production Overview",
+		Slug:    "production-overview",
+		Data:    simplejson.New(),
+		Version: 1,
+		Created: time.Now(),
+		Updated: time.Now(),
+	}
+	err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(dash)
+		return err
+	})
+	require.NoError(t, err)
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		query := args.Get(1).(*models.GetDashboardQuery)
+		query.Result = dash
+	}).Return(nil).Maybe()
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	notificationCenter := &fakeNotificationCenter{}
+	emailSender := &notifications.NotificationServiceMock{}
+	s := ProvideService(cfg, sqlStore, eventBus, dashboardService, notificationCenter, emailSender,
+		routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	t.Run("subscribe and list", func(t *testing.T) {
+		_, err := s.Subscribe(ctx, SubscribeCommand{OrgID: owner.OrgID, UserID: viewer.ID, DashboardUID: "dash"})
+		require.NoError(t, err)
+		// subscribing again updates the subscription
+		sub, err := s.Subscribe(ctx, SubscribeCommand{OrgID: owner.OrgID, UserID: viewer.ID, DashboardUID: "dash", Email: true})
+		require.NoError(t, err)
+		assert.True(t, sub.Email)
+
+		subscriptions, err := s.GetUserSubscriptions(ctx, owner.OrgID, viewer.ID)
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		assert.Equal(t, "dash", subscriptions[0].DashboardUID)
+		assert.True(t, subscriptions[0].Email)
+		assert.Equal(t, "Production Overview", subscriptions[0].DashboardTitle)
+		assert.Equal(t, "/d/dash/production-overview", subscriptions[0].DashboardURL)
+
+		subscriptions, err = s.GetUserSubscriptions(ctx, owner.OrgID, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, subscriptions)
+	})
+
+	t.Run("subscribers are notified when the dashboard is modified by someone else", func(t *testing.T) {
+		notificationCenter.commands = nil
+		err := eventBus.Publish(ctx, &events.DashboardSaved{
+			Title: dash.Title, UID: "dash", OrgID: owner.OrgID, Version: 2, UserID: owner.ID,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, notificationCenter.commands, 1)
+		cmd := notificationCenter.commands[0]
+		assert.Equal(t, []int64{viewer.ID}, cmd.UserIDs)
+		assert.Equal(t, notificationcenter.KindDashboard, cmd.Kind)
+		assert.Equal(t, `Dashboard "Production Overview" was modified`, cmd.Title)
+		assert.Equal(t, "/d/dash/production-overview", cmd.URL)
+
+		assert.Equal(t, subscriptionEmail, emailSender.Email.Template)
+		assert.Equal(t, []string{"viewer@example.com"}, emailSender.Email.To)
+		assert.Equal(t, "http://localhost:3000/d/dash/production-overview", emailSender.Email.Data["DashboardUrl"])
+	})
+
+	t.Run("subscribers are not notified about their own changes", func(t *testing.T) {
+		notificationCenter.commands = nil
+		err := eventBus.Publish(ctx, &events.DashboardSaved{
+			Title: dash.Title, UID: "dash", OrgID: owner.OrgID, Version: 3, UserID: viewer.ID,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, notificationCenter.commands)
+	})
+
+	t.Run("moving the dashboard is reported as a move", func(t *testing.T) {
+		notificationCenter.commands = nil
+		err := eventBus.Publish(ctx, &events.DashboardSaved{
+			Title: dash.Title, UID: "dash", OrgID: owner.OrgID, Version: 4, UserID: owner.ID, FolderID: 3,
+		})
+		require.NoError(t, err)
+		require.Len(t, notificationCenter.commands, 1)
+		assert.Equal(t, `Dashboard "Production Overview" was moved`, notificationCenter.commands[0].Title)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		err := s.Unsubscribe(ctx, owner.OrgID, viewer.ID, "dash")
+		require.NoError(t, err)
+		err = s.Unsubscribe(ctx, owner.OrgID, viewer.ID, "dash")
+		require.ErrorIs(t, err, ErrSubscriptionNotFound)
+	})
+
+	t.Run("deleting the dashboard notifies and removes its subscriptions", func(t *testing.T) {
+		_, err := s.Subscribe(ctx, SubscribeCommand{OrgID: owner.OrgID, UserID: viewer.ID, DashboardUID: "dash"})
+		require.NoError(t, err)
+
+		notificationCenter.commands = nil
+		err = eventBus.Publish(ctx, &events.DashboardDeleted{Title: dash.Title, UID: "dash", OrgID: owner.OrgID})
+		require.NoError(t, err)
+
+		require.Len(t, notificationCenter.commands, 1)
+		assert.Equal(t, `Dashboard "Production Overview" was deleted`, notificationCenter.commands[0].Title)
+		assert.Empty(t, notificationCenter.commands[0].URL)
+
+		err = s.Unsubscribe(ctx, owner.OrgID, viewer.ID, "dash")
+		require.ErrorIs(t, err, ErrSubscriptionNotFound)
+	})
+}
@@ -0,0 +1,104 @@
+package dashboardsubscription
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+)
+
+const subscriptionEmail = "dashboard_subscription"
+
+type change string
+
+const (
+	changeModified change = "modified"
+	changeMoved    change = "moved"
+	changeDeleted  change = "deleted"
+)
+
+// subscriber is a user subscribed to a dashboard.
+type subscriber struct {
+	UserID int64  `xorm:"user_id"`
+	Email  bool   `xorm:"email"`
+	Login  string `xorm:"login"`
+	Name   string `xorm:"name"`
+	// Address is the email address of the user.
+	Address string `xorm:"address"`
+}
+
+// notify lets the subscribers of a dashboard, other than the user who changed it, know about the change.
+func (s *SubscriptionService) notify(ctx context.Context, orgID int64, uid, title string, c change, actorID int64) {
+	subscribers, err := s.getSubscribers(ctx, orgID, uid, actorID)
+	if err != nil {
+		s.log.Error("Failed to get dashboard subscribers", "orgId", orgID, "dashboardUid", uid, "error", err)
+		return
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	// deleted dashboards have nothing to link to
+	var path string
+	if c != changeDeleted {
+		path = fmt.Sprintf("/d/%s/%s", uid, models.SlugifyTitle(title))
+	}
+
+	subject := fmt.Sprintf("Dashboard %q was %s", title, c)
+	if s.notifications != nil {
+		userIDs := make([]int64, 0, len(subscribers))
+		for _, sub := range subscribers {
+			userIDs = append(userIDs, sub.UserID)
+		}
+		severity := notificationcenter.SeverityInfo
+		if c == changeDeleted {
+			severity = notificationcenter.SeverityWarning
+		}
+
+		_, err := s.notifications.Notify(ctx, notificationcenter.NotifyCommand{
+			OrgID:    orgID,
+			UserIDs:  userIDs,
+			Kind:     notificationcenter.KindDashboard,
+			Severity: severity,
+			Title:    subject,
+			URL:      path,
+		})
+		if err != nil {
+			s.log.Warn("Failed to notify dashboard subscribers", "orgId", orgID, "dashboardUid", uid, "error", err)
+		}
+	}
+
+	for _, sub := range subscribers {
+		if !sub.Email || sub.Address == "" {
+			continue
+		}
+		if err := s.sendEmail(ctx, sub, title, subject, c, path); err != nil {
+			s.log.Warn("Failed to email dashboard subscriber", "orgId", orgID, "dashboardUid", uid, "userId", sub.UserID, "error", err)
+		}
+	}
+}
+
+func (s *SubscriptionService) sendEmail(ctx context.Context, sub *subscriber, title, subject string, c change, path string) error {
+	name := sub.Name
+	if name == "" {
+		name = sub.Login
+	}
+
+	data := map[string]interface{}{
+		"Name":           name,
+		"Title":          subject,
+		"DashboardTitle": title,
+		"Change":         string(c),
+		"SettingsUrl":    s.cfg.AppURL + "profile",
+	}
+	if path != "" {
+		data["DashboardUrl"] = s.cfg.AppURL + path[1:]
+	}
+
+	return s.emailSender.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
+		To:       []string{sub.Address},
+		Template: subscriptionEmail,
+		Data:     data,
+	})
+}
@@ -0,0 +1,92 @@
+package dashboardsubscription
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func (s *SubscriptionService) upsert(ctx context.Context, sub *Subscription) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing Subscription
+		exists, err := sess.Where("org_id = ? AND user_id = ? AND dashboard_uid = ?", sub.OrgID, sub.UserID, sub.DashboardUID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err := sess.Insert(sub)
+			return err
+		}
+
+		sub.ID = existing.ID
+		sub.Created = existing.Created
+		_, err = sess.ID(existing.ID).Cols("email").Update(sub)
+		return err
+	})
+}
+
+func (s *SubscriptionService) delete(ctx context.Context, orgID, userID int64, dashboardUID string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND user_id = ? AND dashboard_uid = ?", orgID, userID, dashboardUID).Delete(&Subscription{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrSubscriptionNotFound
+		}
+		return nil
+	})
+}
+
+func (s *SubscriptionService) deleteDashboardSubscriptions(ctx context.Context, orgID int64, dashboardUID string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&Subscription{})
+		return err
+	})
+}
+
+func (s *SubscriptionService) getUserSubscriptions(ctx context.Context, orgID, userID int64) ([]*SubscriptionDTO, error) {
+	result := make([]*SubscriptionDTO, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var rows []struct {
+			Subscription `xorm:"extends"`
+			Title        string
+			Slug         string
+		}
+		err := sess.Table("dashboard_subscription").
+			Select("dashboard_subscription.*, dashboard.title, dashboard.slug").
+			Join("INNER", "dashboard", "dashboard.org_id = dashboard_subscription.org_id AND dashboard.uid = dashboard_subscription.dashboard_uid").
+			Where("dashboard_subscription.org_id = ? AND dashboard_subscription.user_id = ?", orgID, userID).
+			Asc("dashboard.title").
+			Find(&rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			result = append(result, &SubscriptionDTO{
+				Subscription:   row.Subscription,
+				DashboardTitle: row.Title,
+				DashboardURL:   models.GetDashboardUrl(row.DashboardUID, row.Slug),
+			})
+		}
+		return nil
+	})
+	return result, err
+}
+
+// getSubscribers returns the subscribers of a dashboard which are active members of the organization,
+// except for excludeUserID.
+func (s *SubscriptionService) getSubscribers(ctx context.Context, orgID int64, dashboardUID string, excludeUserID int64) ([]*subscriber, error) {
+	var result []*subscriber
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.store.GetDialect()
+		rawSQL := "SELECT ds.user_id, ds.email, u.login, u.name, u.email AS address FROM dashboard_subscription AS ds" +
+			" INNER JOIN org_user ON org_user.org_id = ds.org_id AND org_user.user_id = ds.user_id" +
+			" INNER JOIN " + dialect.Quote("user") + " AS u ON u.id = ds.user_id" +
+			" WHERE ds.org_id = ? AND ds.dashboard_uid = ? AND ds.user_id <> ? AND u.is_disabled = ?"
+		return sess.SQL(rawSQL, orgID, dashboardUID, excludeUserID, dialect.BooleanStr(false)).Find(&result)
+	})
+	return result, err
+}
@@ -0,0 +1,266 @@
+package dashboardtemplates
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *TemplateService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	quota := middleware.Quota(s.quotaService)
+	// the templates are used to create and update dashboards, the permissions of the user on the
+	// folders of the generated dashboards are checked when they are saved
+	canEdit := authorize(middleware.ReqEditorRole, ac.EvalAny(
+		ac.EvalPermission(dashboards.ActionDashboardsCreate),
+		ac.EvalPermission(dashboards.ActionDashboardsWrite),
+	))
+
+	s.routeRegister.Group("/api/dashboard-templates", func(templates routing.RouteRegister) {
+		templates.Get("/", routing.Wrap(s.getTemplatesHandler))
+		templates.Post("/", routing.Wrap(s.createTemplateHandler))
+		templates.Get("/:uid", routing.Wrap(s.getTemplateHandler))
+		templates.Put("/:uid", routing.Wrap(s.updateTemplateHandler))
+		templates.Delete("/:uid", routing.Wrap(s.deleteTemplateHandler))
+		templates.Post("/:uid/instantiate", quota(string(dashboards.QuotaTargetSrv)), routing.Wrap(s.instantiateHandler))
+		templates.Get("/:uid/instances", routing.Wrap(s.getInstancesHandler))
+	}, canEdit)
+}
+
+// swagger:route GET /dashboard-templates dashboard_templates getDashboardTemplates
+//
+// Get the dashboard templates of the current organization.
+//
+// Responses:
+// 200: getDashboardTemplatesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *TemplateService) getTemplatesHandler(c *models.ReqContext) response.Response {
+	templates, err := s.GetTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, templates)
+}
+
+// swagger:route POST /dashboard-templates dashboard_templates createDashboardTemplate
+//
+// Create a dashboard template.
+//
+// The string values of the dashboard model can reference the parameters of the template as {{tpl.name}}, e.g. a query filtering on the service `up{service="{{tpl.service}}"}` and a title `Service {{tpl.service}}`.
+//
+// Responses:
+// 200: dashboardTemplateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *TemplateService) createTemplateHandler(c *models.ReqContext) response.Response {
+	cmd := CreateTemplateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.CreatedBy = c.UserID
+
+	template, err := s.CreateTemplate(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, template)
+}
+
+// swagger:route GET /dashboard-templates/{uid} dashboard_templates getDashboardTemplate
+//
+// Get a dashboard template by UID.
+//
+// Responses:
+// 200: dashboardTemplateResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *TemplateService) getTemplateHandler(c *models.ReqContext) response.Response {
+	template, err := s.GetTemplate(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, template)
+}
+
+// swagger:route PUT /dashboard-templates/{uid} dashboard_templates updateDashboardTemplate
+//
+// Update a dashboard template.
+//
+// The dashboards generated from the template are regenerated with the permissions of the current user, and stay in their folders. The outcome of every dashboard is returned, the template is updated even if some of them fail. Generated dashboards which were deleted are no longer updated.
+//
+// Responses:
+// 200: updateDashboardTemplateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *TemplateService) updateTemplateHandler(c *models.ReqContext) response.Response {
+	cmd := UpdateTemplateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.User = c.SignedInUser
+
+	result, err := s.UpdateTemplate(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /dashboard-templates/{uid} dashboard_templates deleteDashboardTemplate
+//
+// Delete a dashboard template.
+//
+// The dashboards generated from the template are kept, but no longer updated.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *TemplateService) deleteTemplateHandler(c *models.ReqContext) response.Response {
+	if err := s.DeleteTemplate(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("Dashboard template deleted")
+}
+
+// swagger:route POST /dashboard-templates/{uid}/instantiate dashboard_templates instantiateDashboardTemplate
+//
+// Generate dashboards from a dashboard template.
+//
+// Generates a dashboard for each set of parameter values, with the permissions of the current user. When the template was already instantiated with the same values, its dashboard is updated and moved to the folder instead. The outcome of every dashboard is returned.
+//
+// Responses:
+// 200: instantiateDashboardTemplateResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *TemplateService) instantiateHandler(c *models.ReqContext) response.Response {
+	cmd := InstantiateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.TemplateUID = web.Params(c.Req)[":uid"]
+	cmd.User = c.SignedInUser
+
+	result, err := s.Instantiate(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /dashboard-templates/{uid}/instances dashboard_templates getDashboardTemplateInstances
+//
+// Get the dashboards generated from a dashboard template.
+//
+// Responses:
+// 200: getDashboardTemplateInstancesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *TemplateService) getInstancesHandler(c *models.ReqContext) response.Response {
+	instances, err := s.GetInstances(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, instances)
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrTemplateNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard template not found", err)
+	case errors.Is(err, dashboards.ErrFolderNotFound):
+		return response.Error(http.StatusNotFound, "Folder not found", err)
+	case errors.Is(err, ErrTemplateExists), errors.Is(err, ErrTemplateVersionMismatch):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidTemplate), errors.Is(err, ErrInvalidParameters):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process dashboard template", err)
+}
+
+// swagger:parameters getDashboardTemplate updateDashboardTemplate deleteDashboardTemplate instantiateDashboardTemplate getDashboardTemplateInstances
+type DashboardTemplateUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters createDashboardTemplate
+type CreateDashboardTemplateParams struct {
+	// in:body
+	// required:true
+	Body CreateTemplateCommand `json:"body"`
+}
+
+// swagger:parameters updateDashboardTemplate
+type UpdateDashboardTemplateParams struct {
+	// in:body
+	// required:true
+	Body UpdateTemplateCommand `json:"body"`
+}
+
+// swagger:parameters instantiateDashboardTemplate
+type InstantiateDashboardTemplateParams struct {
+	// in:body
+	// required:true
+	Body InstantiateCommand `json:"body"`
+}
+
+// swagger:response getDashboardTemplatesResponse
+type GetDashboardTemplatesResponse struct {
+	// in:body
+	Body []*Template `json:"body"`
+}
+
+// swagger:response dashboardTemplateResponse
+type DashboardTemplateResponse struct {
+	// in:body
+	Body *Template `json:"body"`
+}
+
+// swagger:response updateDashboardTemplateResponse
+type UpdateDashboardTemplateResponse struct {
+	// in:body
+	Body *UpdateTemplateResult `json:"body"`
+}
+
+// swagger:response instantiateDashboardTemplateResponse
+type InstantiateDashboardTemplateResponse struct {
+	// in:body
+	Body *InstantiateResult `json:"body"`
+}
+
+// swagger:response getDashboardTemplateInstancesResponse
+type GetDashboardTemplateInstancesResponse struct {
+	// in:body
+	Body []*Instance `json:"body"`
+}
@@ -0,0 +1,256 @@
+package dashboardtemplates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	params := []Parameter{{Name: "service", Required: true}, {Name: "env", Default: "prod"}}
+	valid := testTemplateDashboard()
+
+	require.NoError(t, validateTemplate("Service", "", params, valid))
+	assert.ErrorIs(t, validateTemplate(" ", "", params, valid), ErrInvalidTemplate)
+	assert.ErrorIs(t, validateTemplate("Service", "", nil, valid), ErrInvalidTemplate, "the placeholders should be declared")
+	assert.ErrorIs(t, validateTemplate("Service", "", append(params, Parameter{Name: "env"}), valid), ErrInvalidTemplate)
+	assert.ErrorIs(t, validateTemplate("Service", "", append(params, Parameter{Name: "my-param"}), valid), ErrInvalidTemplate)
+	assert.ErrorIs(t, validateTemplate("Service", "", params, simplejson.NewFromAny([]interface{}{})), ErrInvalidTemplate)
+	assert.ErrorIs(t, validateTemplate("Service", "", params, simplejson.NewFromAny(map[string]interface{}{"panels": []interface{}{}})), ErrInvalidTemplate)
+}
+
+func TestResolveParams(t *testing.T) {
+	params := []Parameter{{Name: "service", Required: true}, {Name: "env", Default: "prod"}, {Name: "team"}}
+
+	values, err := resolveParams(params, map[string]string{"service": "api"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"service": "api", "env": "prod", "team": ""}, values)
+
+	_, err = resolveParams(params, map[string]string{"env": "dev"})
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+
+	_, err = resolveParams(params, map[string]string{"service": "api", "cluster": "eu"})
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+}
+
+func TestRender(t *testing.T) {
+	template := testTemplateDashboard()
+	rendered, err := render(template, map[string]string{"service": `api"}`, "env": "dev"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `Service api"} (dev)`, rendered.Get("title").MustString())
+	target := rendered.Get("panels").GetIndex(0).Get("targets").GetIndex(0)
+	assert.Equal(t, `up{service="api"}", env="dev"}`, target.Get("expr").MustString())
+	assert.Equal(t, "{{instance}}", target.Get("legendFormat").MustString(), "the legend labels should be kept")
+	assert.Equal(t, "${datasource}", rendered.Get("panels").GetIndex(0).Get("datasource").MustString())
+	assert.Equal(t, "Service {{tpl.service}} ({{tpl.env}})", template.Get("title").MustString(), "the template should not change")
+}
+
+func TestParamsKey(t *testing.T) {
+	assert.Equal(t, paramsKey(map[string]string{"a": "1", "b": "2"}), paramsKey(map[string]string{"b": "2", "a": "1"}))
+	assert.NotEqual(t, paramsKey(map[string]string{"a": "1", "b": "2"}), paramsKey(map[string]string{"a": "1", "b": "3"}))
+	assert.NotEqual(t, paramsKey(map[string]string{"a": "1\x00b\x002"}), paramsKey(map[string]string{"a": "1", "b": "2"}))
+}
+
+func TestIntegrationDashboardTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, dashboardService, bus := setupService(t)
+	signedInUser := &user.SignedInUser{OrgID: 1, UserID: 10}
+
+	template, err := s.CreateTemplate(ctx, CreateTemplateCommand{
+		OrgID:      1,
+		UID:        "service",
+		Name:       "Service",
+		Parameters: []Parameter{{Name: "service", Required: true}, {Name: "env", Default: "prod"}},
+		Dashboard:  testTemplateDashboard(),
+		CreatedBy:  10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, template.Version)
+
+	_, err = s.CreateTemplate(ctx, CreateTemplateCommand{OrgID: 1, UID: "service", Name: "Other", Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "Other"})})
+	assert.ErrorIs(t, err, ErrTemplateExists)
+
+	t.Run("Should generate a dashboard per set of parameter values", func(t *testing.T) {
+		result, err := s.Instantiate(ctx, InstantiateCommand{
+			OrgID:       1,
+			TemplateUID: "service",
+			FolderUID:   "folder",
+			Instances: []InstantiateRequest{
+				{Params: map[string]string{"service": "api"}, DashboardUID: "api"},
+				{Params: map[string]string{"service": "web", "env": "dev"}},
+			},
+			User: signedInUser,
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Instances, 2)
+		for _, instance := range result.Instances {
+			assert.Equal(t, StatusCreated, instance.Status, instance.Error)
+		}
+		assert.Equal(t, "api", result.Instances[0].DashboardUID)
+
+		api := dashboardService.dashboards["api"]
+		assert.Equal(t, "Service api (prod)", api.Title)
+		assert.Equal(t, int64(100), api.FolderId)
+		web := dashboardService.dashboards[result.Instances[1].DashboardUID]
+		assert.Equal(t, "Service web (dev)", web.Title)
+
+		instances, err := s.GetInstances(ctx, 1, "service")
+		require.NoError(t, err)
+		assert.Len(t, instances, 2)
+	})
+
+	t.Run("Should update the dashboard of the same parameter values", func(t *testing.T) {
+		result, err := s.Instantiate(ctx, InstantiateCommand{
+			OrgID:       1,
+			TemplateUID: "service",
+			Instances:   []InstantiateRequest{{Params: map[string]string{"service": "api", "env": "prod"}, DashboardUID: "other"}},
+			User:        signedInUser,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, StatusUpdated, result.Instances[0].Status, result.Instances[0].Error)
+		assert.Equal(t, "api", result.Instances[0].DashboardUID)
+		assert.Equal(t, int64(0), dashboardService.dashboards["api"].FolderId, "the dashboard should move to the folder")
+	})
+
+	t.Run("Should reject invalid parameters before generating any dashboard", func(t *testing.T) {
+		saved := len(dashboardService.dashboards)
+		_, err := s.Instantiate(ctx, InstantiateCommand{
+			OrgID:       1,
+			TemplateUID: "service",
+			Instances:   []InstantiateRequest{{Params: map[string]string{"service": "db"}}, {Params: map[string]string{"env": "dev"}}},
+			User:        signedInUser,
+		})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Len(t, dashboardService.dashboards, saved)
+
+		_, err = s.Instantiate(ctx, InstantiateCommand{OrgID: 1, TemplateUID: "service", FolderUID: "missing",
+			Instances: []InstantiateRequest{{Params: map[string]string{"service": "db"}}}, User: signedInUser})
+		assert.ErrorIs(t, err, dashboards.ErrFolderNotFound)
+	})
+
+	t.Run("Should regenerate the dashboards when the template is updated", func(t *testing.T) {
+		dashboard := testTemplateDashboard()
+		dashboard.Set("title", "{{tpl.service}} in {{tpl.env}}")
+		_, err := s.UpdateTemplate(ctx, UpdateTemplateCommand{OrgID: 1, UID: "service", Name: "Service", Parameters: template.Parameters, Dashboard: dashboard, Version: 5, User: signedInUser})
+		assert.ErrorIs(t, err, ErrTemplateVersionMismatch)
+
+		result, err := s.UpdateTemplate(ctx, UpdateTemplateCommand{OrgID: 1, UID: "service", Name: "Service", Parameters: template.Parameters, Dashboard: dashboard, Version: 1, User: signedInUser})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Template.Version)
+		require.Len(t, result.Instances, 2)
+		for _, instance := range result.Instances {
+			assert.Equal(t, StatusUpdated, instance.Status, instance.Error)
+		}
+		assert.Equal(t, "api in prod", dashboardService.dashboards["api"].Title)
+		assert.Equal(t, int64(0), dashboardService.dashboards["api"].FolderId, "the dashboard should stay in its folder")
+		assert.Equal(t, "web in dev", dashboardService.dashboards[result.Instances[1].DashboardUID].Title)
+
+		instances, err := s.GetInstances(ctx, 1, "service")
+		require.NoError(t, err)
+		for _, instance := range instances {
+			assert.Equal(t, 2, instance.TemplateVersion)
+		}
+	})
+
+	t.Run("Should stop updating deleted dashboards", func(t *testing.T) {
+		delete(dashboardService.dashboards, "api")
+		require.NoError(t, bus.Publish(ctx, &events.DashboardDeleted{OrgID: 1, UID: "api"}))
+
+		instances, err := s.GetInstances(ctx, 1, "service")
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+
+		// without the event, the instance is removed when the template is updated
+		delete(dashboardService.dashboards, instances[0].DashboardUID)
+		result, err := s.UpdateTemplate(ctx, UpdateTemplateCommand{OrgID: 1, UID: "service", Name: "Service", Parameters: template.Parameters, Dashboard: testTemplateDashboard(), User: signedInUser})
+		require.NoError(t, err)
+		require.Len(t, result.Instances, 1)
+		assert.Equal(t, StatusRemoved, result.Instances[0].Status)
+
+		instances, err = s.GetInstances(ctx, 1, "service")
+		require.NoError(t, err)
+		assert.Empty(t, instances)
+	})
+
+	t.Run("Should delete templates", func(t *testing.T) {
+		require.NoError(t, s.DeleteTemplate(ctx, 1, "service"))
+		_, err := s.GetTemplate(ctx, 1, "service")
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+		assert.ErrorIs(t, s.DeleteTemplate(ctx, 1, "service"), ErrTemplateNotFound)
+	})
+}
+
+// fakeDashboards stores the dashboards saved through the dashboard service, by UID.
+type fakeDashboards struct {
+	dashboards map[string]*models.Dashboard
+}
+
+func setupService(t *testing.T) (*TemplateService, *fakeDashboards, bus.Bus) {
+	t.Helper()
+
+	fake := &fakeDashboards{dashboards: map[string]*models.Dashboard{
+		"folder": {Id: 100, Uid: "folder", OrgId: 1, Title: "Folder", IsFolder: true},
+	}}
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(func(ctx context.Context, query *models.GetDashboardQuery) error {
+		dash, ok := fake.dashboards[query.Uid]
+		if !ok {
+			return dashboards.ErrDashboardNotFound
+		}
+		query.Result = dash
+		return nil
+	}).Maybe()
+	dashboardService.On("SaveDashboard", mock.Anything, mock.Anything, false).Return(func(ctx context.Context, dto *dashboards.SaveDashboardDTO, allowUiUpdate bool) *models.Dashboard {
+		dash := dto.Dashboard
+		dash.OrgId = dto.OrgId
+		if existing, ok := fake.dashboards[dash.Uid]; ok {
+			dash.Id = existing.Id
+		} else {
+			dash.Id = int64(len(fake.dashboards) + 1)
+		}
+		dash.Version++
+		fake.dashboards[dash.Uid] = dash
+		return dash
+	}, nil).Maybe()
+
+	bus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	s := ProvideService(db.InitTestDB(t), bus, dashboardService, quotatest.New(false, nil), routing.NewRouteRegister(), actest.FakeAccessControl{})
+	return s, fake, bus
+}
+
+func testTemplateDashboard() *simplejson.Json {
+	return simplejson.NewFromAny(map[string]interface{}{
+		"title": "Service {{tpl.service}} ({{tpl.env}})",
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id":         1,
+				"datasource": "${datasource}",
+				"targets": []interface{}{
+					map[string]interface{}{
+						"expr":         `up{service="{{ tpl.service }}", env="{{tpl.env}}"}`,
+						"legendFormat": "{{instance}}",
+					},
+				},
+			},
+		},
+	})
+}
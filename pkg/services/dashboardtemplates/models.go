@@ -0,0 +1,157 @@
+package dashboardtemplates
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	maxNameLength        = 190
+	maxDescriptionLength = 255
+	maxParameters        = 20
+	// maxInstances is the maximum number of dashboards generated by a single request.
+	maxInstances = 100
+)
+
+var (
+	ErrTemplateNotFound        = errors.New("dashboard template not found")
+	ErrTemplateExists          = errors.New("a dashboard template with the same uid already exists")
+	ErrInvalidTemplate         = errors.New("invalid dashboard template")
+	ErrInvalidParameters       = errors.New("invalid template parameters")
+	ErrTemplateVersionMismatch = errors.New("the dashboard template has been changed by someone else")
+)
+
+// Parameter is a parameter of a template, referenced as {{tpl.name}} from the string values of the
+// dashboard model.
+type Parameter struct {
+	Name  string `json:"name"`
+	Label string `json:"label,omitempty"`
+	// Default is the value used when an instance doesn't set the parameter.
+	Default string `json:"default,omitempty"`
+	// Required parameters must be set by every instance, unless they have a default.
+	Required bool `json:"required,omitempty"`
+}
+
+// Template is a parameterized dashboard model, which generates a dashboard per set of parameter
+// values, e.g. one per service or cluster.
+// swagger:model DashboardTemplate
+type Template struct {
+	ID          int64            `json:"id" xorm:"pk autoincr 'id'"`
+	UID         string           `json:"uid" xorm:"uid"`
+	OrgID       int64            `json:"orgId" xorm:"org_id"`
+	Name        string           `json:"name" xorm:"name"`
+	Description string           `json:"description" xorm:"description"`
+	Parameters  []Parameter      `json:"parameters" xorm:"jsonb parameters"`
+	Dashboard   *simplejson.Json `json:"dashboard" xorm:"dashboard"`
+	// Version is incremented on every update of the template.
+	Version   int       `json:"version" xorm:"'version'"`
+	CreatedBy int64     `json:"createdBy" xorm:"created_by"`
+	Created   time.Time `json:"created" xorm:"'created'"`
+	Updated   time.Time `json:"updated" xorm:"'updated'"`
+}
+
+func (t Template) TableName() string {
+	return "dashboard_template"
+}
+
+// Instance is a dashboard generated from a template.
+// swagger:model DashboardTemplateInstance
+type Instance struct {
+	ID           int64             `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID        int64             `json:"orgId" xorm:"org_id"`
+	TemplateID   int64             `json:"-" xorm:"template_id"`
+	DashboardUID string            `json:"dashboardUid" xorm:"dashboard_uid"`
+	Params       map[string]string `json:"params" xorm:"jsonb params"`
+	// ParamsKey identifies the parameter values, so that instantiating a template twice with the
+	// same values updates the same dashboard.
+	ParamsKey string `json:"-" xorm:"params_key"`
+	// TemplateVersion is the version of the template the dashboard was last generated from.
+	TemplateVersion int       `json:"templateVersion" xorm:"template_version"`
+	Created         time.Time `json:"created" xorm:"'created'"`
+	Updated         time.Time `json:"updated" xorm:"'updated'"`
+}
+
+func (i Instance) TableName() string {
+	return "dashboard_template_instance"
+}
+
+type CreateTemplateCommand struct {
+	OrgID int64 `json:"-"`
+	// UID is generated when it is empty.
+	UID         string           `json:"uid"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Parameters  []Parameter      `json:"parameters"`
+	Dashboard   *simplejson.Json `json:"dashboard"`
+	CreatedBy   int64            `json:"-"`
+}
+
+// UpdateTemplateCommand replaces a template, and regenerates its dashboards with the permissions of
+// the user.
+type UpdateTemplateCommand struct {
+	OrgID       int64            `json:"-"`
+	UID         string           `json:"-"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Parameters  []Parameter      `json:"parameters"`
+	Dashboard   *simplejson.Json `json:"dashboard"`
+	// Version is the version of the template the update is based on. The update is rejected if the
+	// template has been changed since, unless it is 0.
+	Version int                `json:"version"`
+	User    *user.SignedInUser `json:"-"`
+}
+
+// InstantiateCommand generates or updates a dashboard for each set of parameter values.
+type InstantiateCommand struct {
+	OrgID       int64  `json:"-"`
+	TemplateUID string `json:"-"`
+	// FolderUID is the folder of the generated dashboards, the General folder when it is empty.
+	FolderUID string               `json:"folderUid"`
+	Instances []InstantiateRequest `json:"instances"`
+	User      *user.SignedInUser   `json:"-"`
+}
+
+type InstantiateRequest struct {
+	Params map[string]string `json:"params"`
+	// DashboardUID is the UID of a new dashboard, generated when it is empty. It is ignored when the
+	// template was already instantiated with the same parameter values.
+	DashboardUID string `json:"dashboardUid"`
+}
+
+type InstanceStatus string
+
+const (
+	StatusCreated InstanceStatus = "created"
+	StatusUpdated InstanceStatus = "updated"
+	// StatusRemoved is the status of an instance whose dashboard was deleted, and which is no longer
+	// updated.
+	StatusRemoved InstanceStatus = "removed"
+	StatusFailed  InstanceStatus = "failed"
+)
+
+// InstanceResult is the outcome of generating the dashboard of an instance.
+type InstanceResult struct {
+	Params       map[string]string `json:"params"`
+	DashboardUID string            `json:"dashboardUid,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	Status       InstanceStatus    `json:"status"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// InstantiateResult lists the outcome of every instance. The instances are generated independently,
+// so some of them may fail while the others succeed.
+// swagger:model DashboardTemplateInstantiateResult
+type InstantiateResult struct {
+	Instances []InstanceResult `json:"instances"`
+}
+
+// UpdateTemplateResult is the updated template, together with the outcome of regenerating its
+// dashboards.
+// swagger:model DashboardTemplateUpdateResult
+type UpdateTemplateResult struct {
+	Template  *Template        `json:"template"`
+	Instances []InstanceResult `json:"instances"`
+}
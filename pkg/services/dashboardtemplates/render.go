@@ -0,0 +1,149 @@
+package dashboardtemplates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	// placeholders don't use the ${var} syntax of the dashboard variables, nor the {{label}} syntax
+	// of the legends, so that both can be used in templates
+	placeholderPattern   = regexp.MustCompile(`\{\{\s*tpl\.([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+	parameterNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+func validateTemplate(name, description string, parameters []Parameter, dashboard *simplejson.Json) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return fmt.Errorf("%w: the name must be between 1 and %d characters", ErrInvalidTemplate, maxNameLength)
+	}
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return fmt.Errorf("%w: the description cannot exceed %d characters", ErrInvalidTemplate, maxDescriptionLength)
+	}
+	if len(parameters) > maxParameters {
+		return fmt.Errorf("%w: a template cannot have more than %d parameters", ErrInvalidTemplate, maxParameters)
+	}
+
+	declared := make(map[string]bool, len(parameters))
+	for _, p := range parameters {
+		if !parameterNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrInvalidTemplate, p.Name)
+		}
+		if declared[p.Name] {
+			return fmt.Errorf("%w: duplicate parameter %q", ErrInvalidTemplate, p.Name)
+		}
+		declared[p.Name] = true
+	}
+
+	if dashboard == nil {
+		return fmt.Errorf("%w: the dashboard is missing", ErrInvalidTemplate)
+	}
+	if _, err := dashboard.Map(); err != nil {
+		return fmt.Errorf("%w: the dashboard must be a JSON object", ErrInvalidTemplate)
+	}
+	if strings.TrimSpace(dashboard.Get("title").MustString()) == "" {
+		return fmt.Errorf("%w: the dashboard must have a title", ErrInvalidTemplate)
+	}
+
+	var undeclared []string
+	walkStrings(dashboard.Interface(), func(s string) string {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+			if !declared[m[1]] {
+				undeclared = append(undeclared, m[1])
+			}
+		}
+		return s
+	})
+	if len(undeclared) > 0 {
+		return fmt.Errorf("%w: the dashboard references undeclared parameters: %s", ErrInvalidTemplate, strings.Join(undeclared, ", "))
+	}
+	return nil
+}
+
+// resolveParams returns the values of all the parameters of a template, with the defaults of the
+// parameters which are not set.
+func resolveParams(parameters []Parameter, params map[string]string) (map[string]string, error) {
+	declared := make(map[string]bool, len(parameters))
+	resolved := make(map[string]string, len(parameters))
+	for _, p := range parameters {
+		declared[p.Name] = true
+		value, ok := params[p.Name]
+		if !ok || value == "" {
+			value = p.Default
+		}
+		if p.Required && value == "" {
+			return nil, fmt.Errorf("%w: the parameter %q is required", ErrInvalidParameters, p.Name)
+		}
+		resolved[p.Name] = value
+	}
+	for name := range params {
+		if !declared[name] {
+			return nil, fmt.Errorf("%w: unknown parameter %q", ErrInvalidParameters, name)
+		}
+	}
+	return resolved, nil
+}
+
+// render returns a copy of the dashboard model of a template, with the placeholders replaced by the
+// values of the parameters. The values are only substituted into strings, so they cannot change the
+// structure of the dashboard.
+func render(dashboard *simplejson.Json, values map[string]string) (*simplejson.Json, error) {
+	raw, err := dashboard.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	walkStrings(rendered.Interface(), func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+			return values[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+		})
+	})
+	return rendered, nil
+}
+
+// walkStrings replaces every string value of a JSON document, not its keys.
+func walkStrings(value interface{}, replace func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return replace(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = walkStrings(item, replace)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = walkStrings(item, replace)
+		}
+	}
+	return value
+}
+
+// paramsKey identifies a set of parameter values, independently of their order.
+func paramsKey(values map[string]string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([][2]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, [2]string{name, values[name]})
+	}
+	// encoding the pairs as JSON keeps the names and values apart, whatever they contain
+	raw, _ := json.Marshal(pairs)
+	hash := sha256.Sum256(raw)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,125 @@
+package dashboardtemplates
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *TemplateService) insertTemplate(ctx context.Context, template *Template) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(template)
+		if err != nil && s.store.GetDialect().IsUniqueConstraintViolation(err) {
+			return ErrTemplateExists
+		}
+		return err
+	})
+}
+
+// updateTemplate saves a template if its version is still the previous one.
+func (s *TemplateService) updateTemplate(ctx context.Context, template *Template, previousVersion int) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		updated, err := sess.ID(template.ID).Where("version = ?", previousVersion).
+			Cols("name", "description", "parameters", "dashboard", "version", "updated").Update(template)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrTemplateVersionMismatch
+		}
+		return nil
+	})
+}
+
+func (s *TemplateService) getTemplate(ctx context.Context, orgID int64, uid string) (*Template, error) {
+	var template Template
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&template)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrTemplateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s *TemplateService) getTemplates(ctx context.Context, orgID int64) ([]*Template, error) {
+	templates := make([]*Template, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&templates)
+	})
+	return templates, err
+}
+
+// deleteTemplate deletes a template and its instances. The generated dashboards are kept.
+func (s *TemplateService) deleteTemplate(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var template Template
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Cols("id").Get(&template)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrTemplateNotFound
+		}
+		if _, err := sess.Where("template_id = ?", template.ID).Delete(&Instance{}); err != nil {
+			return err
+		}
+		_, err = sess.ID(template.ID).Delete(&Template{})
+		return err
+	})
+}
+
+func (s *TemplateService) getInstances(ctx context.Context, templateID int64) ([]*Instance, error) {
+	instances := make([]*Instance, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("template_id = ?", templateID).Asc("id").Find(&instances)
+	})
+	return instances, err
+}
+
+// getInstance returns the instance of a template with the parameter values, nil if there is none.
+func (s *TemplateService) getInstance(ctx context.Context, templateID int64, key string) (*Instance, error) {
+	var instance Instance
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("template_id = ? AND params_key = ?", templateID, key).Get(&instance)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+func (s *TemplateService) saveInstance(ctx context.Context, instance *Instance) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if instance.ID == 0 {
+			_, err := sess.Insert(instance)
+			return err
+		}
+		_, err := sess.ID(instance.ID).Cols("dashboard_uid", "template_version", "updated").Update(instance)
+		return err
+	})
+}
+
+func (s *TemplateService) deleteInstance(ctx context.Context, id int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Delete(&Instance{})
+		return err
+	})
+}
+
+func (s *TemplateService) deleteInstancesOfDashboard(ctx context.Context, orgID int64, dashboardUID string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&Instance{})
+		return err
+	})
+}
@@ -0,0 +1,339 @@
+// Package dashboardtemplates stores parameterized dashboard models, and generates a dashboard from
+// them for each set of parameter values, e.g. one per service or cluster. The generated dashboards
+// are regenerated when their template is updated.
+package dashboardtemplates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type Service interface {
+	CreateTemplate(ctx context.Context, cmd CreateTemplateCommand) (*Template, error)
+	// UpdateTemplate replaces a template and regenerates its dashboards.
+	UpdateTemplate(ctx context.Context, cmd UpdateTemplateCommand) (*UpdateTemplateResult, error)
+	GetTemplate(ctx context.Context, orgID int64, uid string) (*Template, error)
+	GetTemplates(ctx context.Context, orgID int64) ([]*Template, error)
+	// DeleteTemplate deletes a template. The dashboards generated from it are kept, but no longer
+	// updated.
+	DeleteTemplate(ctx context.Context, orgID int64, uid string) error
+	// Instantiate generates a dashboard for each set of parameter values, or updates it when the
+	// template was already instantiated with the same values.
+	Instantiate(ctx context.Context, cmd InstantiateCommand) (*InstantiateResult, error)
+	GetInstances(ctx context.Context, orgID int64, uid string) ([]*Instance, error)
+}
+
+type TemplateService struct {
+	store            db.DB
+	dashboardService dashboards.DashboardService
+	quotaService     quota.Service
+	routeRegister    routing.RouteRegister
+	accessControl    accesscontrol.AccessControl
+	log              log.Logger
+	now              func() time.Time
+}
+
+func ProvideService(sqlStore db.DB, bus bus.Bus, dashboardService dashboards.DashboardService, quotaService quota.Service,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *TemplateService {
+	s := &TemplateService{
+		store:            sqlStore,
+		dashboardService: dashboardService,
+		quotaService:     quotaService,
+		routeRegister:    routeRegister,
+		accessControl:    accessControl,
+		log:              log.New("dashboard-templates"),
+		now:              time.Now,
+	}
+
+	bus.AddEventListener(s.handleDashboardDeleted)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *TemplateService) CreateTemplate(ctx context.Context, cmd CreateTemplateCommand) (*Template, error) {
+	if err := validateTemplate(cmd.Name, cmd.Description, cmd.Parameters, cmd.Dashboard); err != nil {
+		return nil, err
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(cmd.UID) || util.IsShortUIDTooLong(cmd.UID) {
+		return nil, fmt.Errorf("%w: invalid uid %q", ErrInvalidTemplate, cmd.UID)
+	}
+	if cmd.Parameters == nil {
+		cmd.Parameters = []Parameter{}
+	}
+
+	now := s.now()
+	template := &Template{
+		UID:         cmd.UID,
+		OrgID:       cmd.OrgID,
+		Name:        cmd.Name,
+		Description: cmd.Description,
+		Parameters:  cmd.Parameters,
+		Dashboard:   cmd.Dashboard,
+		Version:     1,
+		CreatedBy:   cmd.CreatedBy,
+		Created:     now,
+		Updated:     now,
+	}
+	if err := s.insertTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Dashboard template created", "uid", template.UID, "orgId", template.OrgID, "createdBy", template.CreatedBy)
+	return template, nil
+}
+
+func (s *TemplateService) UpdateTemplate(ctx context.Context, cmd UpdateTemplateCommand) (*UpdateTemplateResult, error) {
+	if err := validateTemplate(cmd.Name, cmd.Description, cmd.Parameters, cmd.Dashboard); err != nil {
+		return nil, err
+	}
+
+	template, err := s.getTemplate(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Version != 0 && cmd.Version != template.Version {
+		return nil, ErrTemplateVersionMismatch
+	}
+	if cmd.Parameters == nil {
+		cmd.Parameters = []Parameter{}
+	}
+
+	previousVersion := template.Version
+	template.Name = cmd.Name
+	template.Description = cmd.Description
+	template.Parameters = cmd.Parameters
+	template.Dashboard = cmd.Dashboard
+	template.Version++
+	template.Updated = s.now()
+	if err := s.updateTemplate(ctx, template, previousVersion); err != nil {
+		return nil, err
+	}
+	s.log.Info("Dashboard template updated", "uid", template.UID, "orgId", template.OrgID, "version", template.Version)
+
+	instances, err := s.getInstances(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := &UpdateTemplateResult{Template: template, Instances: make([]InstanceResult, 0, len(instances))}
+	for _, instance := range instances {
+		result.Instances = append(result.Instances, s.regenerate(ctx, template, instance, cmd.User))
+	}
+	return result, nil
+}
+
+func (s *TemplateService) GetTemplate(ctx context.Context, orgID int64, uid string) (*Template, error) {
+	return s.getTemplate(ctx, orgID, uid)
+}
+
+func (s *TemplateService) GetTemplates(ctx context.Context, orgID int64) ([]*Template, error) {
+	return s.getTemplates(ctx, orgID)
+}
+
+func (s *TemplateService) DeleteTemplate(ctx context.Context, orgID int64, uid string) error {
+	if err := s.deleteTemplate(ctx, orgID, uid); err != nil {
+		return err
+	}
+	s.log.Info("Dashboard template deleted", "uid", uid, "orgId", orgID)
+	return nil
+}
+
+func (s *TemplateService) GetInstances(ctx context.Context, orgID int64, uid string) ([]*Instance, error) {
+	template, err := s.getTemplate(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.getInstances(ctx, template.ID)
+}
+
+func (s *TemplateService) Instantiate(ctx context.Context, cmd InstantiateCommand) (*InstantiateResult, error) {
+	if len(cmd.Instances) == 0 || len(cmd.Instances) > maxInstances {
+		return nil, fmt.Errorf("%w: between 1 and %d instances can be generated at once", ErrInvalidParameters, maxInstances)
+	}
+
+	template, err := s.getTemplate(ctx, cmd.OrgID, cmd.TemplateUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var folderID int64
+	if cmd.FolderUID != "" {
+		query := &models.GetDashboardQuery{OrgId: cmd.OrgID, Uid: cmd.FolderUID}
+		if err := s.dashboardService.GetDashboard(ctx, query); err != nil || !query.Result.IsFolder {
+			if err == nil || errors.Is(err, dashboards.ErrDashboardNotFound) {
+				return nil, dashboards.ErrFolderNotFound
+			}
+			return nil, err
+		}
+		folderID = query.Result.Id
+	}
+
+	// the parameters are validated before generating any dashboard, so that a mistake in one of the
+	// instances doesn't leave the others half generated
+	values := make([]map[string]string, len(cmd.Instances))
+	for i, req := range cmd.Instances {
+		if values[i], err = resolveParams(template.Parameters, req.Params); err != nil {
+			return nil, err
+		}
+		if req.DashboardUID != "" && (!util.IsValidShortUID(req.DashboardUID) || util.IsShortUIDTooLong(req.DashboardUID)) {
+			return nil, fmt.Errorf("%w: invalid dashboard uid %q", ErrInvalidParameters, req.DashboardUID)
+		}
+	}
+
+	result := &InstantiateResult{Instances: make([]InstanceResult, 0, len(cmd.Instances))}
+	for i, req := range cmd.Instances {
+		result.Instances = append(result.Instances, s.instantiate(ctx, template, values[i], req.DashboardUID, folderID, cmd.User))
+	}
+	return result, nil
+}
+
+func (s *TemplateService) instantiate(ctx context.Context, template *Template, values map[string]string, dashboardUID string,
+	folderID int64, user *user.SignedInUser) InstanceResult {
+	result := InstanceResult{Params: values}
+
+	key := paramsKey(values)
+	instance, err := s.getInstance(ctx, template.ID, key)
+	if err != nil {
+		return failed(result, err)
+	}
+
+	var existing *models.Dashboard
+	if instance != nil {
+		if existing, err = s.getDashboard(ctx, template.OrgID, instance.DashboardUID); err != nil {
+			return failed(result, err)
+		}
+	}
+	if instance == nil {
+		if dashboardUID == "" {
+			dashboardUID = util.GenerateShortUID()
+		}
+		instance = &Instance{OrgID: template.OrgID, TemplateID: template.ID, DashboardUID: dashboardUID, Params: values, ParamsKey: key, Created: s.now()}
+	} else if existing == nil && dashboardUID != "" {
+		// the dashboard of the instance was deleted, it is generated again
+		instance.DashboardUID = dashboardUID
+	}
+
+	dash, err := s.saveDashboard(ctx, template, values, instance.DashboardUID, existing, folderID, user)
+	if err != nil {
+		return failed(result, err)
+	}
+
+	result.Status = StatusCreated
+	if existing != nil {
+		result.Status = StatusUpdated
+	}
+	instance.TemplateVersion = template.Version
+	instance.Updated = s.now()
+	if err := s.saveInstance(ctx, instance); err != nil {
+		return failed(result, err)
+	}
+
+	result.DashboardUID = dash.Uid
+	result.URL = dash.GetUrl()
+	return result
+}
+
+// regenerate updates the dashboard of an instance after its template changed. The dashboard stays in
+// its current folder. An instance whose dashboard was deleted is removed.
+func (s *TemplateService) regenerate(ctx context.Context, template *Template, instance *Instance, user *user.SignedInUser) InstanceResult {
+	result := InstanceResult{Params: instance.Params, DashboardUID: instance.DashboardUID}
+
+	existing, err := s.getDashboard(ctx, template.OrgID, instance.DashboardUID)
+	if err != nil {
+		return failed(result, err)
+	}
+	if existing == nil {
+		if err := s.deleteInstance(ctx, instance.ID); err != nil {
+			return failed(result, err)
+		}
+		result.Status = StatusRemoved
+		return result
+	}
+
+	values, err := resolveParams(template.Parameters, instance.Params)
+	if err != nil {
+		return failed(result, err)
+	}
+	dash, err := s.saveDashboard(ctx, template, values, instance.DashboardUID, existing, existing.FolderId, user)
+	if err != nil {
+		return failed(result, err)
+	}
+
+	instance.TemplateVersion = template.Version
+	instance.Updated = s.now()
+	if err := s.saveInstance(ctx, instance); err != nil {
+		return failed(result, err)
+	}
+	result.Status = StatusUpdated
+	result.URL = dash.GetUrl()
+	return result
+}
+
+// saveDashboard renders the template and saves the dashboard with the permissions of the user. An
+// existing dashboard is updated from its current version, instead of being overwritten, so that a
+// dashboard with the same title in the folder is never replaced.
+func (s *TemplateService) saveDashboard(ctx context.Context, template *Template, values map[string]string, uid string,
+	existing *models.Dashboard, folderID int64, user *user.SignedInUser) (*models.Dashboard, error) {
+	data, err := render(template.Dashboard, values)
+	if err != nil {
+		return nil, err
+	}
+	data.Del("id")
+	data.Del("version")
+	data.Set("uid", uid)
+	if existing != nil {
+		data.Set("id", existing.Id)
+		data.Set("version", existing.Version)
+	}
+
+	dash := models.NewDashboardFromJson(data)
+	dash.FolderId = folderID
+	dto := &dashboards.SaveDashboardDTO{
+		OrgId:     template.OrgID,
+		User:      user,
+		Message:   fmt.Sprintf("Generated from the dashboard template %q version %d", template.Name, template.Version),
+		Dashboard: dash,
+	}
+	return s.dashboardService.SaveDashboard(ctx, dto, false)
+}
+
+// getDashboard returns a dashboard, nil if it doesn't exist.
+func (s *TemplateService) getDashboard(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
+	query := &models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (s *TemplateService) handleDashboardDeleted(ctx context.Context, e *events.DashboardDeleted) error {
+	if err := s.deleteInstancesOfDashboard(ctx, e.OrgID, e.UID); err != nil {
+		s.log.Error("Failed to remove the template instance of a deleted dashboard", "orgId", e.OrgID, "dashboardUid", e.UID, "error", err)
+	}
+	return nil
+}
+
+func failed(result InstanceResult, err error) InstanceResult {
+	result.Status = StatusFailed
+	result.Error = err.Error()
+	return result
+}
@@ -96,6 +96,10 @@ func toAPIError(c *models.ReqContext, err error) {
 		c.JsonApiErr(http.StatusForbidden, "Access denied to datasource", err)
 		return
 	}
+	if errors.Is(err, datasources.ErrUserCredentialsNotFound) {
+		c.JsonApiErr(http.StatusForbidden, "Credentials of the user are required to query the data source", err)
+		return
+	}
 	if errors.Is(err, datasources.ErrDataSourceNotFound) {
 		c.JsonApiErr(http.StatusNotFound, "Unable to find datasource", err)
 		return
@@ -54,6 +54,17 @@ type DataSourceService interface {
 	// DecryptedPassword decrypts the encrypted datasource password and returns the
 	// decrypted value.
 	DecryptedPassword(ctx context.Context, ds *DataSource) (string, error)
+
+	// GetUserCredentials gets the credentials a user supplied for a data source using
+	// per-user credentials.
+	GetUserCredentials(ctx context.Context, ds *DataSource, userID int64) (*UserCredentials, error)
+
+	// SetUserCredentials stores the credentials of a user for a data source using per-user
+	// credentials in the secrets store.
+	SetUserCredentials(ctx context.Context, cmd *SetUserCredentialsCommand) error
+
+	// DeleteUserCredentials deletes the credentials of a user for a data source.
+	DeleteUserCredentials(ctx context.Context, ds *DataSource, userID int64) error
 }
 
 // CacheService interface for retrieving a cached datasource.
@@ -11,4 +11,6 @@ var (
 	ErrDataSourceFailedGenerateUniqueUid = errors.New("failed to generate unique datasource ID")
 	ErrDataSourceIdentifierNotSet        = errors.New("unique identifier and org id are needed to be able to get or delete a datasource")
 	ErrDatasourceIsReadOnly              = errors.New("data source is readonly, can only be updated from configuration")
+	ErrUserCredentialsNotSupported       = errors.New("data source does not use per-user credentials")
+	ErrUserCredentialsNotFound           = errors.New("data source requires credentials of the user, which are not set")
 )
@@ -134,3 +134,15 @@ func (s *FakeDataSourceService) DecryptedBasicAuthPassword(ctx context.Context,
 func (s *FakeDataSourceService) DecryptedPassword(ctx context.Context, ds *datasources.DataSource) (string, error) {
 	return "", nil
 }
+
+func (s *FakeDataSourceService) GetUserCredentials(ctx context.Context, ds *datasources.DataSource, userID int64) (*datasources.UserCredentials, error) {
+	return nil, datasources.ErrUserCredentialsNotFound
+}
+
+func (s *FakeDataSourceService) SetUserCredentials(ctx context.Context, cmd *datasources.SetUserCredentialsCommand) error {
+	return nil
+}
+
+func (s *FakeDataSourceService) DeleteUserCredentials(ctx context.Context, ds *datasources.DataSource, userID int64) error {
+	return nil
+}
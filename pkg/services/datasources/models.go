@@ -57,6 +57,14 @@ type DataSource struct {
 
 	Created time.Time `json:"created,omitempty"`
 	Updated time.Time `json:"updated,omitempty"`
+
+	// UserSecureJsonData are the decrypted credentials of the user the data source was loaded
+	// for, if it uses per-user credentials. They take precedence over the secureJsonData.
+	// swagger:ignore
+	UserSecureJsonData map[string]string `json:"-" xorm:"-"`
+	// CredentialsUserID is the id of the user whose credentials are in UserSecureJsonData.
+	// swagger:ignore
+	CredentialsUserID int64 `json:"-" xorm:"-"`
 }
 
 // AllowedCookies parses the jsondata.keepCookies and returns a list of
@@ -71,6 +79,12 @@ func (ds DataSource) AllowedCookies() []string {
 	return []string{}
 }
 
+// PerUserCredentials returns true if each user querying the data source supplies their own
+// credentials, set with jsondata.perUserCredentials.
+func (ds DataSource) PerUserCredentials() bool {
+	return ds.JsonData != nil && ds.JsonData.Get("perUserCredentials").MustBool()
+}
+
 // Specific error type for grpc secrets management so that we can show more detailed plugin errors to users
 type ErrDatasourceSecretsPluginUserFriendly struct {
 	Err string
@@ -148,6 +162,23 @@ type DeleteDataSourceCommand struct {
 	UpdateSecretFn UpdateSecretFn
 }
 
+// SetUserCredentialsCommand stores the credentials of a user for a data source using per-user credentials.
+type SetUserCredentialsCommand struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+
+	UserID     int64       `json:"-"`
+	DataSource *DataSource `json:"-"`
+}
+
+// UserCredentials are the credentials a user supplied for a data source. They replace the user
+// and password, and the basic authentication user and password if enabled, of the data source.
+type UserCredentials struct {
+	User     string    `json:"user"`
+	Password string    `json:"password"`
+	Updated  time.Time `json:"updated"`
+}
+
 // Function for updating secrets along with datasources, to ensure atomicity
 type UpdateSecretFn func() error
 
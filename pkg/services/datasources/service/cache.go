@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -16,20 +17,24 @@ const (
 	DefaultCacheTTL = 5 * time.Second
 )
 
-func ProvideCacheService(cacheService *localcache.CacheService, sqlStore db.DB) *CacheServiceImpl {
+func ProvideCacheService(cacheService *localcache.CacheService, sqlStore db.DB, secretsStore kvstore.SecretsKVStore) *CacheServiceImpl {
 	return &CacheServiceImpl{
 		logger:       log.New("datasources"),
 		cacheTTL:     DefaultCacheTTL,
 		CacheService: cacheService,
 		SQLStore:     sqlStore,
+		secretsStore: secretsStore,
 	}
 }
 
+// CacheServiceImpl returns data sources using per-user credentials with the credentials of the
+// user they are requested for.
 type CacheServiceImpl struct {
 	logger       log.Logger
 	cacheTTL     time.Duration
 	CacheService *localcache.CacheService
 	SQLStore     db.DB
+	secretsStore kvstore.SecretsKVStore
 }
 
 func (dc *CacheServiceImpl) GetDatasource(
@@ -44,7 +49,7 @@ func (dc *CacheServiceImpl) GetDatasource(
 		if cached, found := dc.CacheService.Get(cacheKey); found {
 			ds := cached.(*datasources.DataSource)
 			if ds.OrgId == user.OrgID {
-				return ds, nil
+				return withUserCredentials(ctx, dc.secretsStore, ds, user)
 			}
 		}
 	}
@@ -64,7 +69,7 @@ func (dc *CacheServiceImpl) GetDatasource(
 		dc.CacheService.Set(uidKey(ds.OrgId, ds.Uid), ds, time.Second*5)
 	}
 	dc.CacheService.Set(cacheKey, ds, dc.cacheTTL)
-	return ds, nil
+	return withUserCredentials(ctx, dc.secretsStore, ds, user)
 }
 
 func (dc *CacheServiceImpl) GetDatasourceByUID(
@@ -85,7 +90,7 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 		if cached, found := dc.CacheService.Get(uidCacheKey); found {
 			ds := cached.(*datasources.DataSource)
 			if ds.OrgId == user.OrgID {
-				return ds, nil
+				return withUserCredentials(ctx, dc.secretsStore, ds, user)
 			}
 		}
 	}
@@ -102,7 +107,7 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 
 	dc.CacheService.Set(uidCacheKey, ds, dc.cacheTTL)
 	dc.CacheService.Set(idKey(ds.Id), ds, dc.cacheTTL)
-	return ds, nil
+	return withUserCredentials(ctx, dc.secretsStore, ds, user)
 }
 
 func idKey(id int64) string {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -41,10 +42,16 @@ type Service struct {
 }
 
 type proxyTransportCache struct {
-	cache map[int64]cachedRoundTripper
+	cache map[transportKey]cachedRoundTripper
 	sync.Mutex
 }
 
+// transportKey keeps the transports of data sources using per-user credentials apart for each user.
+type transportKey struct {
+	dataSourceID int64
+	userID       int64
+}
+
 type cachedRoundTripper struct {
 	updated      time.Time
 	roundTripper http.RoundTripper
@@ -62,7 +69,7 @@ func ProvideService(
 		SecretsStore:   secretsStore,
 		SecretsService: secretsService,
 		ptc: proxyTransportCache{
-			cache: make(map[int64]cachedRoundTripper),
+			cache: make(map[transportKey]cachedRoundTripper),
 		},
 		cfg:                cfg,
 		features:           features,
@@ -173,7 +180,7 @@ func (s *Service) AddDataSource(ctx context.Context, cmd *datasources.AddDataSou
 
 		cmd.EncryptedSecureJsonData = make(map[string][]byte)
 		if !s.features.IsEnabled(featuremgmt.FlagDisableSecretsCompatibility) {
-			cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithoutScope())
+			cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithOrgScope(cmd.OrgId))
 			if err != nil {
 				return err
 			}
@@ -216,7 +223,17 @@ func (s *Service) AddDataSource(ctx context.Context, cmd *datasources.AddDataSou
 
 func (s *Service) DeleteDataSource(ctx context.Context, cmd *datasources.DeleteDataSourceCommand) error {
 	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		query := &datasources.GetDataSourceQuery{Id: cmd.ID, Uid: cmd.UID, Name: cmd.Name, OrgId: cmd.OrgID}
+		if err := s.SQLStore.GetDataSource(ctx, query); err != nil && !errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return err
+		}
+
 		cmd.UpdateSecretFn = func() error {
+			if query.Result != nil && query.Result.PerUserCredentials() {
+				if err := s.deleteAllUserCredentials(ctx, query.Result); err != nil {
+					return err
+				}
+			}
 			return s.SecretsStore.Del(ctx, cmd.OrgID, cmd.Name, kvstore.DataSourceSecretType)
 		}
 
@@ -285,7 +302,8 @@ func (s *Service) GetHTTPTransport(ctx context.Context, ds *datasources.DataSour
 	s.ptc.Lock()
 	defer s.ptc.Unlock()
 
-	if t, present := s.ptc.cache[ds.Id]; present && ds.Updated.Equal(t.updated) {
+	key := transportKey{dataSourceID: ds.Id, userID: ds.CredentialsUserID}
+	if t, present := s.ptc.cache[key]; present && ds.Updated.Equal(t.updated) {
 		return t.roundTripper, nil
 	}
 
@@ -301,7 +319,7 @@ func (s *Service) GetHTTPTransport(ctx context.Context, ds *datasources.DataSour
 		return nil, err
 	}
 
-	s.ptc.cache[ds.Id] = cachedRoundTripper{
+	s.ptc.cache[key] = cachedRoundTripper{
 		roundTripper: rt,
 		updated:      ds.Updated,
 	}
@@ -338,11 +356,16 @@ func (s *Service) DecryptedValues(ctx context.Context, ds *datasources.DataSourc
 		}
 	}
 
+	for k, v := range ds.UserSecureJsonData {
+		decryptedValues[k] = v
+	}
+
 	return decryptedValues, nil
 }
 
 func (s *Service) decryptLegacySecrets(ctx context.Context, ds *datasources.DataSource) (map[string]string, error) {
 	secureJsonData := make(map[string]string)
+	ctx = secrets.ContextWithOrgScope(ctx, ds.OrgId)
 	for k, v := range ds.SecureJsonData {
 		decrypted, err := s.SecretsService.Decrypt(ctx, v)
 		if err != nil {
@@ -601,7 +624,7 @@ func (s *Service) fillWithSecureJSONData(ctx context.Context, cmd *datasources.U
 
 	cmd.EncryptedSecureJsonData = make(map[string][]byte)
 	if !s.features.IsEnabled(featuremgmt.FlagDisableSecretsCompatibility) {
-		cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithoutScope())
+		cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithOrgScope(cmd.OrgId))
 		if err != nil {
 			return err
 		}
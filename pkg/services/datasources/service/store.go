@@ -17,6 +17,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -151,6 +152,11 @@ func (ss *SqlStore) DeleteDataSource(ctx context.Context, cmd *datasources.Delet
 				ac.Scope(datasources.ScopeProvider.GetResourceScope(dsQuery.Result.Uid))); errDeletingPerms != nil {
 				return errDeletingPerms
 			}
+
+			if _, err := sess.Exec("DELETE FROM resource_label WHERE org_id = ? AND resource_kind = ? AND resource_uid = ?",
+				ds.OrgId, resourcelabel.KindDataSource, ds.Uid); err != nil {
+				return err
+			}
 		}
 
 		if cmd.UpdateSecretFn != nil {
@@ -371,6 +377,24 @@ func (ss *SqlStore) UpdateDataSource(ctx context.Context, cmd *datasources.Updat
 		}
 
 		cmd.Result = ds
+
+		if err == nil {
+			uid := ds.Uid
+			if uid == "" {
+				// the uid is optional in the command, in which case it is left unchanged
+				if _, err := sess.Table("data_source").Where("id = ?", ds.Id).Cols("uid").Get(&uid); err != nil {
+					return err
+				}
+			}
+			sess.PublishAfterCommit(&events.DataSourceUpdated{
+				Timestamp: ds.Updated,
+				Name:      ds.Name,
+				ID:        ds.Id,
+				UID:       uid,
+				OrgID:     ds.OrgId,
+				Version:   ds.Version,
+			})
+		}
 		return err
 	})
 }
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func (s *Service) GetUserCredentials(ctx context.Context, ds *datasources.DataSource, userID int64) (*datasources.UserCredentials, error) {
+	return getUserCredentials(ctx, s.SecretsStore, ds, userID)
+}
+
+func (s *Service) SetUserCredentials(ctx context.Context, cmd *datasources.SetUserCredentialsCommand) error {
+	ds := cmd.DataSource
+	if !ds.PerUserCredentials() {
+		return datasources.ErrUserCredentialsNotSupported
+	}
+	secret, err := json.Marshal(datasources.UserCredentials{User: cmd.User, Password: cmd.Password, Updated: time.Now()})
+	if err != nil {
+		return err
+	}
+	return s.SecretsStore.Set(ctx, ds.OrgId, ds.Uid, userCredentialsType(cmd.UserID), string(secret))
+}
+
+func (s *Service) DeleteUserCredentials(ctx context.Context, ds *datasources.DataSource, userID int64) error {
+	_, exists, err := s.SecretsStore.Get(ctx, ds.OrgId, ds.Uid, userCredentialsType(userID))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return datasources.ErrUserCredentialsNotFound
+	}
+	return s.SecretsStore.Del(ctx, ds.OrgId, ds.Uid, userCredentialsType(userID))
+}
+
+// deleteAllUserCredentials deletes the credentials the members of the organization of a data
+// source supplied for it.
+func (s *Service) deleteAllUserCredentials(ctx context.Context, ds *datasources.DataSource) error {
+	var userIDs []int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("org_user").Where("org_id = ?", ds.OrgId).Cols("user_id").Find(&userIDs)
+	})
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := s.SecretsStore.Del(ctx, ds.OrgId, ds.Uid, userCredentialsType(userID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func userCredentialsType(userID int64) string {
+	return fmt.Sprintf("%s:%d", kvstore.DataSourceUserCredentialsSecretType, userID)
+}
+
+func getUserCredentials(ctx context.Context, store kvstore.SecretsKVStore, ds *datasources.DataSource, userID int64) (*datasources.UserCredentials, error) {
+	secret, exists, err := store.Get(ctx, ds.OrgId, ds.Uid, userCredentialsType(userID))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, datasources.ErrUserCredentialsNotFound
+	}
+	credentials := &datasources.UserCredentials{}
+	if err := json.Unmarshal([]byte(secret), credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// withUserCredentials returns a copy of a data source using per-user credentials, which uses the
+// credentials of the user. Other data sources are returned unchanged.
+func withUserCredentials(ctx context.Context, store kvstore.SecretsKVStore, ds *datasources.DataSource, u *user.SignedInUser) (*datasources.DataSource, error) {
+	if !ds.PerUserCredentials() {
+		return ds, nil
+	}
+	credentials, err := getUserCredentials(ctx, store, ds, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	userDS := *ds
+	userDS.User = credentials.User
+	userDS.UserSecureJsonData = map[string]string{"password": credentials.Password}
+	if ds.BasicAuth {
+		userDS.BasicAuthUser = credentials.User
+		userDS.UserSecureJsonData["basicAuthPassword"] = credentials.Password
+	}
+
+	userDS.CredentialsUserID = u.UserID
+	// instances of backend plugins and transports of the data source proxy are kept for each
+	// user, and recreated once the user changes their credentials
+	if credentials.Updated.After(ds.Updated) {
+		userDS.Updated = credentials.Updated
+	}
+	return &userDS, nil
+}
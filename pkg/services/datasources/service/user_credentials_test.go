@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	secretsmng "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationUserCredentials(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	secretsService := secretsmng.SetupTestService(t, fakes.NewFakeSecretsStore())
+	secretsStore := secretskvs.NewSQLSecretsKVStore(sqlStore, secretsService, log.New("test.logger"))
+	permissionsService := acmock.NewMockedPermissionsService()
+	permissionsService.On("SetPermissions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]accesscontrol.ResourcePermission{}, nil)
+	dsService, err := ProvideService(sqlStore, secretsService, secretsStore, setting.NewCfg(), featuremgmt.WithFeatures(), acmock.New(), permissionsService, quotatest.New(false, nil))
+	require.NoError(t, err)
+	cache := ProvideCacheService(localcache.ProvideService(), sqlStore, secretsStore)
+
+	err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&org.OrgUser{OrgID: 1, UserID: 1, Role: org.RoleViewer, Created: time.Now(), Updated: time.Now()})
+		return err
+	})
+	require.NoError(t, err)
+
+	add := func(uid string, jsonData map[string]interface{}) *datasources.DataSource {
+		cmd := &datasources.AddDataSourceCommand{
+			OrgId: 1, Uid: uid, Name: uid, Type: datasources.DS_POSTGRES, Access: datasources.DS_ACCESS_PROXY,
+			User: "shared", BasicAuth: true, BasicAuthUser: "shared",
+			JsonData:       simplejson.NewFromAny(jsonData),
+			SecureJsonData: map[string]string{"password": "shared", "basicAuthPassword": "shared", "tlsClientKey": "key"},
+		}
+		require.NoError(t, dsService.AddDataSource(ctx, cmd))
+		return cmd.Result
+	}
+	perUser := add("per-user", map[string]interface{}{"perUserCredentials": true})
+	shared := add("shared", map[string]interface{}{})
+
+	user1 := &user.SignedInUser{UserID: 1, OrgID: 1}
+	user2 := &user.SignedInUser{UserID: 2, OrgID: 1}
+
+	t.Run("credentials can only be set for data sources using per-user credentials", func(t *testing.T) {
+		err := dsService.SetUserCredentials(ctx, &datasources.SetUserCredentialsCommand{DataSource: shared, UserID: 1, User: "alice", Password: "secret"})
+		require.ErrorIs(t, err, datasources.ErrUserCredentialsNotSupported)
+
+		ds, err := cache.GetDatasourceByUID(ctx, "shared", user1, false)
+		require.NoError(t, err)
+		require.Equal(t, "shared", ds.User)
+	})
+
+	t.Run("users without credentials cannot use the data source", func(t *testing.T) {
+		_, err := cache.GetDatasourceByUID(ctx, "per-user", user1, false)
+		require.ErrorIs(t, err, datasources.ErrUserCredentialsNotFound)
+		_, err = dsService.GetUserCredentials(ctx, perUser, 1)
+		require.ErrorIs(t, err, datasources.ErrUserCredentialsNotFound)
+	})
+
+	require.NoError(t, dsService.SetUserCredentials(ctx, &datasources.SetUserCredentialsCommand{DataSource: perUser, UserID: 1, User: "alice", Password: "alice-secret"}))
+	require.NoError(t, dsService.SetUserCredentials(ctx, &datasources.SetUserCredentialsCommand{DataSource: perUser, UserID: 2, User: "bob", Password: "bob-secret"}))
+
+	t.Run("data sources are loaded with the credentials of the user", func(t *testing.T) {
+		credentials, err := dsService.GetUserCredentials(ctx, perUser, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", credentials.User)
+
+		alice, err := cache.GetDatasourceByUID(ctx, "per-user", user1, false)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", alice.User)
+		assert.Equal(t, "alice", alice.BasicAuthUser)
+		values, err := dsService.DecryptedValues(ctx, alice)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "alice-secret", "basicAuthPassword": "alice-secret", "tlsClientKey": "key"}, values)
+
+		bob, err := cache.GetDatasource(ctx, perUser.Id, user2, false)
+		require.NoError(t, err)
+		assert.Equal(t, "bob", bob.User)
+		password, err := dsService.DecryptedPassword(ctx, bob)
+		require.NoError(t, err)
+		assert.Equal(t, "bob-secret", password)
+
+		cached, err := cache.GetDatasourceByUID(ctx, "per-user", user2, false)
+		require.NoError(t, err)
+		assert.Equal(t, "shared", perUser.User)
+		assert.Equal(t, "bob", cached.User)
+		assert.Equal(t, bob.Updated, cached.Updated)
+	})
+
+	t.Run("instances and transports are kept apart for each user", func(t *testing.T) {
+		alice, err := cache.GetDatasourceByUID(ctx, "per-user", user1, false)
+		require.NoError(t, err)
+		bob, err := cache.GetDatasourceByUID(ctx, "per-user", user2, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), alice.CredentialsUserID)
+		assert.Equal(t, int64(2), bob.CredentialsUserID)
+
+		settings := func(ds *datasources.DataSource) *backend.DataSourceInstanceSettings {
+			s, err := adapters.ModelToInstanceSettings(ds, func(ds *datasources.DataSource) (map[string]string, error) {
+				return dsService.DecryptedValues(ctx, ds)
+			})
+			require.NoError(t, err)
+			return s
+		}
+		aliceSettings, bobSettings := settings(alice), settings(bob)
+		assert.NotEqual(t, aliceSettings.ID, bobSettings.ID, "instances of plugins must not be shared between users")
+		assert.Equal(t, aliceSettings.ID, settings(alice).ID)
+		assert.Equal(t, shared.Id, settings(shared).ID)
+
+		provider := httpclient.NewProvider()
+		for _, ds := range []*datasources.DataSource{alice, bob, alice} {
+			_, err := dsService.GetHTTPTransport(ctx, ds, provider)
+			require.NoError(t, err)
+		}
+		require.Len(t, dsService.ptc.cache, 2)
+		assert.Contains(t, dsService.ptc.cache, transportKey{dataSourceID: perUser.Id, userID: 1})
+		assert.Contains(t, dsService.ptc.cache, transportKey{dataSourceID: perUser.Id, userID: 2})
+	})
+
+	t.Run("credentials can be deleted", func(t *testing.T) {
+		require.NoError(t, dsService.DeleteUserCredentials(ctx, perUser, 2))
+		require.ErrorIs(t, dsService.DeleteUserCredentials(ctx, perUser, 2), datasources.ErrUserCredentialsNotFound)
+		_, err := cache.GetDatasourceByUID(ctx, "per-user", user2, false)
+		require.ErrorIs(t, err, datasources.ErrUserCredentialsNotFound)
+	})
+
+	t.Run("credentials are deleted with the data source", func(t *testing.T) {
+		require.NoError(t, dsService.DeleteDataSource(ctx, &datasources.DeleteDataSourceCommand{OrgID: 1, UID: "per-user", Name: "per-user"}))
+		_, exists, err := secretsStore.Get(ctx, 1, "per-user", userCredentialsType(1))
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+}
@@ -0,0 +1,104 @@
+package datasourceusage
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *UsageService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.routeRegister.Get("/api/datasources/usage", middleware.ReqSignedIn, authorize(middleware.ReqOrgAdmin, ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getUsageHandler))
+	s.routeRegister.Get("/api/datasources/uid/:uid/usage", middleware.ReqSignedIn, authorize(middleware.ReqOrgAdmin, ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(s.getDataSourceUsageHandler))
+}
+
+// swagger:route GET /datasources/usage datasources getDataSourcesUsage
+//
+// Get the usage of all data sources.
+//
+// Returns the dashboards, alert rules, library panels and correlations referencing each data source the signed in user can read, ordered by name. Unused data sources are flagged.
+//
+// Responses:
+// 200: getDataSourcesUsageResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *UsageService) getUsageHandler(c *models.ReqContext) response.Response {
+	usage, err := s.GetUsage(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+
+	unusedOnly := c.QueryBool("unused")
+	result := make([]*Usage, 0, len(usage))
+	for _, u := range usage {
+		if unusedOnly && !u.Unused {
+			continue
+		}
+		scope := datasources.ScopeProvider.GetResourceScopeUID(u.UID)
+		if ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(datasources.ActionRead, scope)); err != nil || !ok {
+			continue
+		}
+		result = append(result, u)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /datasources/uid/{uid}/usage datasources getDataSourceUsageByUID
+//
+// Get the usage of a data source.
+//
+// Returns the dashboards, alert rules, library panels and correlations referencing the data source.
+//
+// Responses:
+// 200: getDataSourceUsageResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *UsageService) getDataSourceUsageHandler(c *models.ReqContext) response.Response {
+	usage, err := s.GetDataSourceUsage(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+	return response.JSON(http.StatusOK, usage)
+}
+
+// swagger:parameters getDataSourcesUsage
+type GetDataSourcesUsageParams struct {
+	// Only return the data sources which are not referenced by any resource.
+	// in:query
+	// required:false
+	Unused bool `json:"unused"`
+}
+
+// swagger:parameters getDataSourceUsageByUID
+type GetDataSourceUsageByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response getDataSourcesUsageResponse
+type GetDataSourcesUsageResponse struct {
+	// in: body
+	Body []*Usage `json:"body"`
+}
+
+// swagger:response getDataSourceUsageResponse
+type GetDataSourceUsageResponse struct {
+	// in: body
+	Body *Usage `json:"body"`
+}
@@ -0,0 +1,205 @@
+// Package datasourceusage keeps an inventory of the dashboards, alert rules, library panels and
+// correlations referencing each data source, to find the data sources which are no longer used.
+//
+// The references of dashboards, alert rules and library panels are indexed when they are saved,
+// rather than by reading all of them when the inventory is requested.
+package datasourceusage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const (
+	KindDashboard    = "dashboard"
+	KindAlertRule    = "alert_rule"
+	KindLibraryPanel = "library_panel"
+)
+
+const (
+	kvNamespace = "datasource-usage"
+	// indexedKey is set once the references of the resources saved before the inventory existed
+	// have been indexed.
+	indexedKey = "indexed"
+)
+
+// reference is a data source referenced by a dashboard, alert rule or library panel.
+type reference struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	Kind          string `xorm:"kind"`
+	ResourceUID   string `xorm:"resource_uid"`
+	Title         string `xorm:"title"`
+	DataSourceUID string `xorm:"data_source_uid"`
+}
+
+func (r reference) TableName() string {
+	return "data_source_usage"
+}
+
+// Resource is a resource referencing a data source.
+type Resource struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// Usage lists the resources referencing a data source.
+// swagger:model
+type Usage struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	IsDefault bool   `json:"isDefault"`
+	// Dashboards with panels or data source variables querying the data source.
+	Dashboards []Resource `json:"dashboards"`
+	// AlertRules with queries of the data source.
+	AlertRules []Resource `json:"alertRules"`
+	// LibraryPanels querying the data source.
+	LibraryPanels []Resource `json:"libraryPanels"`
+	// Correlations from or to the data source, titled by their label.
+	Correlations []Resource `json:"correlations"`
+	// Unused is true if no resource references the data source.
+	Unused bool `json:"unused"`
+}
+
+func (u *Usage) add(kind string, resource Resource) {
+	switch kind {
+	case KindDashboard:
+		u.Dashboards = append(u.Dashboards, resource)
+	case KindAlertRule:
+		u.AlertRules = append(u.AlertRules, resource)
+	case KindLibraryPanel:
+		u.LibraryPanels = append(u.LibraryPanels, resource)
+	}
+	u.Unused = false
+}
+
+type Service interface {
+	// GetUsage returns the usage of the data sources of an organization, ordered by name.
+	GetUsage(ctx context.Context, orgID int64) ([]*Usage, error)
+	// GetDataSourceUsage returns the usage of a data source.
+	GetDataSourceUsage(ctx context.Context, orgID int64, uid string) (*Usage, error)
+}
+
+type UsageService struct {
+	store             db.DB
+	log               log.Logger
+	kvStore           kvstore.KVStore
+	dataSourceService datasources.DataSourceService
+	routeRegister     routing.RouteRegister
+	accessControl     accesscontrol.AccessControl
+}
+
+func ProvideService(sqlStore db.DB, bus bus.Bus, kvStore kvstore.KVStore, dataSourceService datasources.DataSourceService,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *UsageService {
+	s := &UsageService{
+		store:             sqlStore,
+		log:               log.New("datasource-usage"),
+		kvStore:           kvStore,
+		dataSourceService: dataSourceService,
+		routeRegister:     routeRegister,
+		accessControl:     accessControl,
+	}
+
+	s.registerEventListeners(bus)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *UsageService) registerEventListeners(bus bus.Bus) {
+	bus.AddEventListener(func(ctx context.Context, e *events.DashboardSaved) error {
+		s.handleIndexError(s.indexDashboard(ctx, e.OrgID, e.UID), KindDashboard, e.OrgID, e.UID)
+		return nil
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DashboardDeleted) error {
+		s.handleIndexError(s.deleteReferences(ctx, e.OrgID, KindDashboard, e.UID), KindDashboard, e.OrgID, e.UID)
+		return nil
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.AlertRuleCreated) error {
+		s.handleIndexError(s.indexAlertRule(ctx, e.OrgID, e.UID), KindAlertRule, e.OrgID, e.UID)
+		return nil
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.AlertRuleUpdated) error {
+		s.handleIndexError(s.indexAlertRule(ctx, e.OrgID, e.UID), KindAlertRule, e.OrgID, e.UID)
+		return nil
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.AlertRuleDeleted) error {
+		s.handleIndexError(s.deleteReferences(ctx, e.OrgID, KindAlertRule, e.UID), KindAlertRule, e.OrgID, e.UID)
+		return nil
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.LibraryElementSaved) error {
+		if e.Kind != int64(models.PanelElement) {
+			return nil
+		}
+		if e.PreviousUID != e.UID {
+			s.handleIndexError(s.deleteReferences(ctx, e.OrgID, KindLibraryPanel, e.PreviousUID), KindLibraryPanel, e.OrgID, e.PreviousUID)
+		}
+		s.handleIndexError(s.indexLibraryPanel(ctx, e.OrgID, e.UID), KindLibraryPanel, e.OrgID, e.UID)
+		return nil
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.LibraryElementDeleted) error {
+		s.handleIndexError(s.deleteReferences(ctx, e.OrgID, KindLibraryPanel, e.UID), KindLibraryPanel, e.OrgID, e.UID)
+		return nil
+	})
+}
+
+// handleIndexError only logs failures, so saving the resource and the listeners after this one are
+// not affected. The references of the resource are indexed again the next time it is saved.
+func (s *UsageService) handleIndexError(err error, kind string, orgID int64, uid string) {
+	if err != nil {
+		s.log.Error("Failed to index data source references", "kind", kind, "orgId", orgID, "uid", uid, "error", err)
+	}
+}
+
+// Run indexes the references of the resources saved before the inventory existed, once.
+func (s *UsageService) Run(ctx context.Context) error {
+	kv := kvstore.WithNamespace(s.kvStore, 0, kvNamespace)
+	_, indexed, err := kv.Get(ctx, indexedKey)
+	if err != nil {
+		return err
+	}
+	if indexed {
+		return nil
+	}
+
+	s.log.Info("Indexing data source references of existing resources")
+	if err := s.indexAll(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		s.log.Error("Failed to index data source references of existing resources", "error", err)
+		return nil
+	}
+	return kv.Set(ctx, indexedKey, "true")
+}
+
+func (s *UsageService) GetUsage(ctx context.Context, orgID int64) ([]*Usage, error) {
+	query := &datasources.GetDataSourcesQuery{OrgId: orgID}
+	if err := s.dataSourceService.GetDataSources(ctx, query); err != nil {
+		return nil, err
+	}
+	return s.getUsage(ctx, orgID, query.Result)
+}
+
+func (s *UsageService) GetDataSourceUsage(ctx context.Context, orgID int64, uid string) (*Usage, error) {
+	query := &datasources.GetDataSourceQuery{OrgId: orgID, Uid: uid}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err != nil {
+		return nil, err
+	}
+	usage, err := s.getUsage(ctx, orgID, []*datasources.DataSource{query.Result})
+	if err != nil {
+		return nil, err
+	}
+	return usage[0], nil
+}
@@ -0,0 +1,198 @@
+package datasourceusage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+func TestDashboardDataSources(t *testing.T) {
+	lookup := dashboard.CreateDatasourceLookup([]*dashboard.DatasourceQueryResult{
+		{UID: "prom", Type: "prometheus", Name: "Prometheus", IsDefault: true},
+		{UID: "loki", Type: "loki", Name: "Loki"},
+		{UID: "tempo", Type: "tempo", Name: "Tempo"},
+		{UID: "pg", Type: "postgres", Name: "Postgres"},
+	})
+	data := []byte(`{
+		"panels": [
+			{"type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"}},
+			{"type": "logs", "datasource": "Loki"},
+			{"type": "table"},
+			{"type": "row", "collapsed": true, "panels": [
+				{"type": "traces", "datasource": {"uid": "tempo"}}
+			]},
+			{"type": "stat", "datasource": {"uid": "-- Mixed --"}, "targets": [{"datasource": {"uid": "$ds"}}]},
+			{"libraryPanel": {"uid": "shared", "name": "Shared"}}
+		],
+		"templating": {"list": [
+			{"name": "ds", "type": "datasource", "query": "postgres", "current": {"value": "pg"}}
+		]}
+	}`)
+
+	uids, err := dashboardDataSources(data, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"loki", "pg", "prom", "tempo"}, uids)
+
+	uids, err = libraryPanelDataSources([]byte(`{"type": "logs", "targets": [{"datasource": {"uid": "loki"}}]}`), lookup)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"loki"}, uids)
+
+	_, err = dashboardDataSources([]byte(`{"panels": [`), lookup)
+	assert.Error(t, err)
+}
+
+func TestAlertRuleDataSources(t *testing.T) {
+	uids, err := alertRuleDataSources([]byte(`[
+		{"refId": "A", "datasourceUid": "prom"},
+		{"refId": "B", "datasourceUid": "prom"},
+		{"refId": "C", "datasourceUid": "__expr__"},
+		{"refId": "D", "datasourceUid": "-100"},
+		{"refId": "E", "datasourceUid": "loki"}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"loki", "prom"}, uids)
+}
+
+func TestIntegrationDataSourceUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	dataSources := []*datasources.DataSource{
+		{OrgId: 1, Uid: "prom", Name: "Prometheus", Type: "prometheus", IsDefault: true},
+		{OrgId: 1, Uid: "loki", Name: "Loki", Type: "loki"},
+		{OrgId: 1, Uid: "tempo", Name: "Tempo", Type: "tempo"},
+		{OrgId: 1, Uid: "unused", Name: "Unused", Type: "postgres"},
+	}
+	dashboardJSON := simplejson.NewFromAny(map[string]interface{}{
+		"panels": []interface{}{
+			map[string]interface{}{"type": "timeseries"},
+			map[string]interface{}{"type": "logs", "datasource": "Loki"},
+		},
+	})
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, ds := range dataSources {
+			ds.Access = datasources.DS_ACCESS_PROXY
+			ds.JsonData = simplejson.New()
+			ds.Created, ds.Updated = now, now
+			if _, err := sess.Insert(ds); err != nil {
+				return err
+			}
+		}
+		if _, err := sess.Insert(&models.Dashboard{Uid: "overview", OrgId: 1, Title: "Overview", Slug: "overview",
+			Data: dashboardJSON, Version: 1, Created: now, Updated: now}); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&ngmodels.AlertRule{OrgID: 1, UID: "high-latency", Title: "High latency", Condition: "B",
+			Data:    []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prom"}, {RefID: "B", DatasourceUID: "__expr__"}},
+			Updated: now, IntervalSeconds: 60, NamespaceUID: "folder", RuleGroup: "group",
+			NoDataState: ngmodels.NoData, ExecErrState: ngmodels.AlertingErrState}); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&libraryelements.LibraryElement{OrgID: 1, UID: "errors", Name: "Errors", Kind: int64(models.PanelElement),
+			Model: json.RawMessage(`{"type": "logs", "datasource": {"uid": "loki"}}`), Version: 1, Created: now, Updated: now}); err != nil {
+			return err
+		}
+		target := "tempo"
+		_, err := sess.Insert(&correlations.Correlation{UID: "logs-to-traces", SourceUID: "loki", TargetUID: &target, Label: "Trace"})
+		return err
+	})
+	require.NoError(t, err)
+
+	eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	s := ProvideService(sqlStore, eventBus, kvstore.ProvideService(sqlStore), &fakeDatasources.FakeDataSourceService{DataSources: dataSources},
+		routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	usageByUID := func(t *testing.T) map[string]*Usage {
+		t.Helper()
+		usage, err := s.GetUsage(ctx, 1)
+		require.NoError(t, err)
+		byUID := make(map[string]*Usage)
+		for _, u := range usage {
+			byUID[u.UID] = u
+		}
+		return byUID
+	}
+
+	t.Run("existing resources are indexed once", func(t *testing.T) {
+		require.NoError(t, s.Run(ctx))
+
+		usage := usageByUID(t)
+		assert.Equal(t, []Resource{{UID: "overview", Title: "Overview"}}, usage["prom"].Dashboards)
+		assert.Equal(t, []Resource{{UID: "high-latency", Title: "High latency"}}, usage["prom"].AlertRules)
+		assert.Equal(t, []Resource{{UID: "overview", Title: "Overview"}}, usage["loki"].Dashboards)
+		assert.Equal(t, []Resource{{UID: "errors", Title: "Errors"}}, usage["loki"].LibraryPanels)
+		assert.Equal(t, []Resource{{UID: "logs-to-traces", Title: "Trace"}}, usage["loki"].Correlations)
+		assert.Equal(t, []Resource{{UID: "logs-to-traces", Title: "Trace"}}, usage["tempo"].Correlations)
+		assert.Empty(t, usage["tempo"].Dashboards)
+		assert.False(t, usage["tempo"].Unused)
+		assert.True(t, usage["unused"].Unused)
+
+		_, indexed, err := kvstore.WithNamespace(kvstore.ProvideService(sqlStore), 0, kvNamespace).Get(ctx, indexedKey)
+		require.NoError(t, err)
+		assert.True(t, indexed)
+	})
+
+	t.Run("saved dashboards are indexed again", func(t *testing.T) {
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			data := simplejson.NewFromAny(map[string]interface{}{
+				"panels": []interface{}{map[string]interface{}{"type": "table", "datasource": map[string]interface{}{"uid": "unused"}}},
+			})
+			_, err := sess.Where("uid = ?", "overview").Cols("data", "title").Update(&models.Dashboard{Data: data, Title: "Tables"})
+			return err
+		})
+		require.NoError(t, err)
+		require.NoError(t, eventBus.Publish(ctx, &events.DashboardSaved{OrgID: 1, UID: "overview", Title: "Tables"}))
+
+		usage := usageByUID(t)
+		assert.Empty(t, usage["prom"].Dashboards)
+		assert.Empty(t, usage["loki"].Dashboards)
+		assert.Equal(t, []Resource{{UID: "overview", Title: "Tables"}}, usage["unused"].Dashboards)
+		assert.False(t, usage["unused"].Unused)
+	})
+
+	t.Run("deleted resources are removed", func(t *testing.T) {
+		require.NoError(t, eventBus.Publish(ctx, &events.DashboardDeleted{OrgID: 1, UID: "overview"}))
+		require.NoError(t, eventBus.Publish(ctx, &events.AlertRuleDeleted{OrgID: 1, UID: "high-latency"}))
+		require.NoError(t, eventBus.Publish(ctx, &events.LibraryElementDeleted{OrgID: 1, UID: "errors"}))
+
+		usage := usageByUID(t)
+		assert.True(t, usage["prom"].Unused)
+		assert.True(t, usage["unused"].Unused)
+		assert.Empty(t, usage["loki"].LibraryPanels)
+		assert.False(t, usage["loki"].Unused)
+	})
+
+	t.Run("get the usage of a data source", func(t *testing.T) {
+		usage, err := s.GetDataSourceUsage(ctx, 1, "tempo")
+		require.NoError(t, err)
+		assert.Equal(t, "Tempo", usage.Name)
+		assert.Len(t, usage.Correlations, 1)
+
+		_, err = s.GetDataSourceUsage(ctx, 1, "missing")
+		assert.ErrorIs(t, err, datasources.ErrDataSourceNotFound)
+	})
+}
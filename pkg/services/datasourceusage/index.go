@@ -0,0 +1,176 @@
+package datasourceusage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+// indexBatchSize is the number of resources read at once when indexing the existing resources.
+const indexBatchSize = 100
+
+// dashboardDataSources returns the uids of the data sources queried by the panels of a dashboard.
+// Data sources referenced by name and panels using the default data source are resolved with the
+// data sources of the organization at the time the dashboard is saved.
+func dashboardDataSources(data []byte, lookup dashboard.DatasourceLookup) ([]string, error) {
+	refs, err := dashboard.ReadDatasourceRefs(data, lookup)
+	if err != nil {
+		return nil, err
+	}
+	uids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		uids = append(uids, ref.UID)
+	}
+	return distinct(uids), nil
+}
+
+// libraryPanelDataSources returns the uids of the data sources queried by a library panel.
+func libraryPanelDataSources(model []byte, lookup dashboard.DatasourceLookup) ([]string, error) {
+	// a library panel is read as the only panel of a dashboard
+	data, err := json.Marshal(map[string][]json.RawMessage{"panels": {model}})
+	if err != nil {
+		return nil, err
+	}
+	return dashboardDataSources(data, lookup)
+}
+
+// alertRuleDataSources returns the uids of the data sources queried by an alert rule, without
+// server-side expressions.
+func alertRuleDataSources(data []byte) ([]string, error) {
+	var queries []struct {
+		DatasourceUID string `json:"datasourceUid"`
+	}
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, err
+	}
+	uids := make([]string, 0, len(queries))
+	for _, q := range queries {
+		if !expr.IsDataSource(q.DatasourceUID) {
+			uids = append(uids, q.DatasourceUID)
+		}
+	}
+	return distinct(uids), nil
+}
+
+func distinct(uids []string) []string {
+	seen := make(map[string]bool, len(uids))
+	result := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if uid != "" && !seen[uid] {
+			seen[uid] = true
+			result = append(result, uid)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *UsageService) indexDashboard(ctx context.Context, orgID int64, uid string) error {
+	dash, err := s.getDashboard(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if dash == nil {
+		return s.deleteReferences(ctx, orgID, KindDashboard, uid)
+	}
+	lookup, err := dashboard.LoadDatasourceLookup(ctx, orgID, s.store)
+	if err != nil {
+		return err
+	}
+	return s.indexResource(ctx, dash, lookup)
+}
+
+func (s *UsageService) indexAlertRule(ctx context.Context, orgID int64, uid string) error {
+	rule, err := s.getAlertRule(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return s.deleteReferences(ctx, orgID, KindAlertRule, uid)
+	}
+	return s.indexResource(ctx, rule, nil)
+}
+
+func (s *UsageService) indexLibraryPanel(ctx context.Context, orgID int64, uid string) error {
+	panel, err := s.getLibraryPanel(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if panel == nil {
+		return s.deleteReferences(ctx, orgID, KindLibraryPanel, uid)
+	}
+	lookup, err := dashboard.LoadDatasourceLookup(ctx, orgID, s.store)
+	if err != nil {
+		return err
+	}
+	return s.indexResource(ctx, panel, lookup)
+}
+
+// indexResource replaces the references of a resource. Resources which can't be parsed are indexed
+// without references.
+func (s *UsageService) indexResource(ctx context.Context, r *resource, lookup dashboard.DatasourceLookup) error {
+	var uids []string
+	var err error
+	switch r.Kind {
+	case KindDashboard:
+		uids, err = dashboardDataSources(r.Data, lookup)
+	case KindAlertRule:
+		uids, err = alertRuleDataSources(r.Data)
+	case KindLibraryPanel:
+		uids, err = libraryPanelDataSources(r.Data, lookup)
+	}
+	if err != nil {
+		s.log.Warn("Failed to read data source references", "kind", r.Kind, "orgId", r.OrgID, "uid", r.UID, "error", err)
+	}
+	return s.replaceReferences(ctx, r, uids)
+}
+
+// indexAll indexes the references of all dashboards, alert rules and library panels.
+func (s *UsageService) indexAll(ctx context.Context) error {
+	lookups := make(map[int64]dashboard.DatasourceLookup)
+	for _, kind := range []string{KindDashboard, KindAlertRule, KindLibraryPanel} {
+		var lastID int64
+		count := 0
+		for {
+			batch, err := s.getResources(ctx, kind, lastID, indexBatchSize)
+			if err != nil {
+				return err
+			}
+			for _, r := range batch {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				lookup, ok := lookups[r.OrgID]
+				if !ok && kind != KindAlertRule {
+					if lookup, err = dashboard.LoadDatasourceLookup(ctx, r.OrgID, s.store); err != nil {
+						return err
+					}
+					lookups[r.OrgID] = lookup
+				}
+				if err := s.indexResource(ctx, r, lookup); err != nil {
+					return err
+				}
+				lastID = r.ID
+			}
+			count += len(batch)
+			if len(batch) < indexBatchSize {
+				break
+			}
+		}
+		s.log.Info("Indexed data source references", "kind", kind, "count", count)
+	}
+	return nil
+}
+
+// resource is a dashboard, alert rule or library panel with the JSON referencing data sources.
+type resource struct {
+	ID    int64  `xorm:"id"`
+	OrgID int64  `xorm:"org_id"`
+	Kind  string `xorm:"-"`
+	UID   string `xorm:"uid"`
+	Title string `xorm:"title"`
+	Data  []byte `xorm:"data"`
+}
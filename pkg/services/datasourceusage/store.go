@@ -0,0 +1,155 @@
+package datasourceusage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// resourceQuery returns the query selecting the resources of a kind, which the caller extends with
+// further AND conditions.
+func (s *UsageService) resourceQuery(kind string) (string, []interface{}) {
+	switch kind {
+	case KindDashboard:
+		return "SELECT id, org_id, uid, title, data FROM dashboard WHERE is_folder = ?",
+			[]interface{}{s.store.GetDialect().BooleanStr(false)}
+	case KindAlertRule:
+		return "SELECT id, org_id, uid, title, data FROM alert_rule WHERE 1 = 1", nil
+	default:
+		return "SELECT id, org_id, uid, name AS title, model AS data FROM library_element WHERE kind = ?",
+			[]interface{}{int64(models.PanelElement)}
+	}
+}
+
+// getResource returns nil if the resource doesn't exist.
+func (s *UsageService) getResource(ctx context.Context, kind string, orgID int64, uid string) (*resource, error) {
+	var resources []*resource
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sql, args := s.resourceQuery(kind)
+		return sess.SQL(sql+" AND org_id = ? AND uid = ?", append(args, orgID, uid)...).Find(&resources)
+	})
+	if err != nil || len(resources) == 0 {
+		return nil, err
+	}
+	resources[0].Kind = kind
+	return resources[0], nil
+}
+
+func (s *UsageService) getDashboard(ctx context.Context, orgID int64, uid string) (*resource, error) {
+	return s.getResource(ctx, KindDashboard, orgID, uid)
+}
+
+func (s *UsageService) getAlertRule(ctx context.Context, orgID int64, uid string) (*resource, error) {
+	return s.getResource(ctx, KindAlertRule, orgID, uid)
+}
+
+func (s *UsageService) getLibraryPanel(ctx context.Context, orgID int64, uid string) (*resource, error) {
+	return s.getResource(ctx, KindLibraryPanel, orgID, uid)
+}
+
+// getResources returns the resources of a kind with an id greater than afterID, ordered by id.
+func (s *UsageService) getResources(ctx context.Context, kind string, afterID int64, limit int64) ([]*resource, error) {
+	var resources []*resource
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sql, args := s.resourceQuery(kind)
+		sql += " AND id > ? ORDER BY id " + s.store.GetDialect().Limit(limit)
+		return sess.SQL(sql, append(args, afterID)...).Find(&resources)
+	})
+	for _, r := range resources {
+		r.Kind = kind
+	}
+	return resources, err
+}
+
+func (s *UsageService) replaceReferences(ctx context.Context, r *resource, dataSourceUIDs []string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ? AND kind = ? AND resource_uid = ?", r.OrgID, r.Kind, r.UID).Delete(&reference{}); err != nil {
+			return err
+		}
+		for _, uid := range dataSourceUIDs {
+			ref := &reference{OrgID: r.OrgID, Kind: r.Kind, ResourceUID: r.UID, Title: r.Title, DataSourceUID: uid}
+			if _, err := sess.Insert(ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *UsageService) deleteReferences(ctx context.Context, orgID int64, kind string, uid string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND kind = ? AND resource_uid = ?", orgID, kind, uid).Delete(&reference{})
+		return err
+	})
+}
+
+// getUsage returns the usage of data sources in the order of the data sources.
+func (s *UsageService) getUsage(ctx context.Context, orgID int64, dataSources []*datasources.DataSource) ([]*Usage, error) {
+	result := make([]*Usage, 0, len(dataSources))
+	byUID := make(map[string]*Usage, len(dataSources))
+	for _, ds := range dataSources {
+		usage := &Usage{
+			UID:           ds.Uid,
+			Name:          ds.Name,
+			Type:          ds.Type,
+			IsDefault:     ds.IsDefault,
+			Dashboards:    []Resource{},
+			AlertRules:    []Resource{},
+			LibraryPanels: []Resource{},
+			Correlations:  []Resource{},
+			Unused:        true,
+		}
+		result = append(result, usage)
+		byUID[ds.Uid] = usage
+	}
+	if len(dataSources) == 0 {
+		return result, nil
+	}
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var refs []*reference
+		query := sess.Where("org_id = ?", orgID)
+		if len(dataSources) == 1 {
+			query = query.And("data_source_uid = ?", dataSources[0].Uid)
+		}
+		if err := query.Asc("title", "resource_uid").Find(&refs); err != nil {
+			return fmt.Errorf("failed to get data source references: %w", err)
+		}
+		for _, ref := range refs {
+			if usage, ok := byUID[ref.DataSourceUID]; ok {
+				usage.add(ref.Kind, Resource{UID: ref.ResourceUID, Title: ref.Title})
+			}
+		}
+
+		// correlations are stored with the uids of the data sources, so they don't need to be indexed
+		var correlations []struct {
+			UID       string  `xorm:"uid"`
+			Label     string  `xorm:"label"`
+			SourceUID string  `xorm:"source_uid"`
+			TargetUID *string `xorm:"target_uid"`
+		}
+		err := sess.SQL("SELECT c.uid, c.label, c.source_uid, c.target_uid FROM correlation c "+
+			"INNER JOIN data_source ds ON ds.uid = c.source_uid WHERE ds.org_id = ? ORDER BY c.label, c.uid", orgID).Find(&correlations)
+		if err != nil {
+			return fmt.Errorf("failed to get correlations: %w", err)
+		}
+		for _, c := range correlations {
+			resource := Resource{UID: c.UID, Title: c.Label}
+			if usage, ok := byUID[c.SourceUID]; ok {
+				usage.Correlations = append(usage.Correlations, resource)
+				usage.Unused = false
+			}
+			if c.TargetUID != nil && *c.TargetUID != c.SourceUID {
+				if usage, ok := byUID[*c.TargetUID]; ok {
+					usage.Correlations = append(usage.Correlations, resource)
+					usage.Unused = false
+				}
+			}
+		}
+		return nil
+	})
+	return result, err
+}
@@ -0,0 +1,221 @@
+package editlock
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *EditLockService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/edit-locks/:kind/:uid", func(lockRoute routing.RouteRegister) {
+		lockRoute.Get("/", routing.Wrap(s.getLockHandler))
+		lockRoute.Post("/", routing.Wrap(s.acquireHandler))
+		lockRoute.Post("/heartbeat", routing.Wrap(s.heartbeatHandler))
+		lockRoute.Delete("/", routing.Wrap(s.releaseHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route GET /edit-locks/{kind}/{uid} edit_locks getEditLock
+//
+// Get the edit lock of a dashboard or alert rule.
+//
+// Returns the lock if another user, or the signed in user, is editing the resource. Requires the permission to read the resource.
+//
+// Responses:
+// 200: editLockResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *EditLockService) getLockHandler(c *models.ReqContext) response.Response {
+	kind, uid := lockParams(c)
+	if resp := s.authorize(c, kind, uid, false); resp != nil {
+		return resp
+	}
+
+	lock, err := s.GetLock(c.Req.Context(), c.OrgID, kind, uid)
+	if err != nil {
+		return errorResponse(err, "Failed to get edit lock")
+	}
+	return response.JSON(http.StatusOK, lock)
+}
+
+// swagger:route POST /edit-locks/{kind}/{uid} edit_locks acquireEditLock
+//
+// Acquire the edit lock of a dashboard or alert rule.
+//
+// The lock expires unless it is extended with heartbeats. Acquiring a lock the signed in user holds extends it. Organization admins can take over the lock of another user with force, who is then notified. Requires the permission to write the resource.
+//
+// Responses:
+// 200: editLockResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: editLockHeldError
+// 500: internalServerError
+func (s *EditLockService) acquireHandler(c *models.ReqContext) response.Response {
+	cmd := AcquireCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.Kind, cmd.ResourceUID = lockParams(c)
+	if resp := s.authorize(c, cmd.Kind, cmd.ResourceUID, true); resp != nil {
+		return resp
+	}
+	cmd.OrgID = c.OrgID
+	cmd.User = c.SignedInUser
+
+	lock, err := s.Acquire(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to acquire edit lock")
+	}
+	return response.JSON(http.StatusOK, lock)
+}
+
+// swagger:route POST /edit-locks/{kind}/{uid}/heartbeat edit_locks heartbeatEditLock
+//
+// Extend the edit lock of a dashboard or alert rule.
+//
+// Fails with 409 if another user took over the lock.
+//
+// Responses:
+// 200: editLockResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: editLockHeldError
+// 500: internalServerError
+func (s *EditLockService) heartbeatHandler(c *models.ReqContext) response.Response {
+	kind, uid := lockParams(c)
+	if resp := s.authorize(c, kind, uid, true); resp != nil {
+		return resp
+	}
+
+	lock, err := s.Heartbeat(c.Req.Context(), c.OrgID, kind, uid, c.UserID)
+	if err != nil {
+		return errorResponse(err, "Failed to extend edit lock")
+	}
+	return response.JSON(http.StatusOK, lock)
+}
+
+// swagger:route DELETE /edit-locks/{kind}/{uid} edit_locks releaseEditLock
+//
+// Release the edit lock of a dashboard or alert rule held by the signed in user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *EditLockService) releaseHandler(c *models.ReqContext) response.Response {
+	kind, uid := lockParams(c)
+	if err := s.Release(c.Req.Context(), c.OrgID, kind, uid, c.UserID); err != nil {
+		return errorResponse(err, "Failed to release edit lock")
+	}
+	return response.Success("Edit lock released")
+}
+
+func lockParams(c *models.ReqContext) (Kind, string) {
+	params := web.Params(c.Req)
+	return Kind(params[":kind"]), params[":uid"]
+}
+
+// authorize requires the permission to read or write the resource of a lock.
+func (s *EditLockService) authorize(c *models.ReqContext, kind Kind, uid string, write bool) response.Response {
+	var evaluator ac.Evaluator
+	switch kind {
+	case KindDashboard:
+		action := dashboards.ActionDashboardsRead
+		if write {
+			action = dashboards.ActionDashboardsWrite
+		}
+		evaluator = ac.EvalPermission(action, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
+	case KindAlertRule:
+		// alert rules are scoped by folder, which is checked when the rule is saved
+		action := ac.ActionAlertingRuleRead
+		if write {
+			action = ac.ActionAlertingRuleUpdate
+		}
+		evaluator = ac.EvalPermission(action)
+	default:
+		return response.Error(http.StatusBadRequest, ErrInvalidKind.Error(), ErrInvalidKind)
+	}
+
+	ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "Access denied", nil)
+	}
+	return nil
+}
+
+func errorResponse(err error, message string) response.Response {
+	var held LockHeldError
+	switch {
+	case errors.As(err, &held):
+		return response.JSON(http.StatusConflict, EditLockHeldBody{Message: ErrLockHeld.Error(), Lock: held.Lock})
+	case errors.Is(err, ErrLockHeld):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrLockNotFound):
+		return response.Error(http.StatusNotFound, "Edit lock not found", err)
+	case errors.Is(err, ErrTakeoverDenied):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, ErrInvalidKind), errors.Is(err, ErrResourceRequired):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// EditLockHeldBody tells who holds the lock of a resource.
+type EditLockHeldBody struct {
+	Message string   `json:"message"`
+	Lock    *LockDTO `json:"lock"`
+}
+
+// swagger:parameters getEditLock heartbeatEditLock releaseEditLock
+type EditLockParams struct {
+	// One of dashboard and alert-rule.
+	// in:path
+	// required:true
+	Kind string `json:"kind"`
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters acquireEditLock
+type AcquireEditLockParams struct {
+	// One of dashboard and alert-rule.
+	// in:path
+	// required:true
+	Kind string `json:"kind"`
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	Body AcquireCommand `json:"body"`
+}
+
+// swagger:response editLockResponse
+type EditLockResponse struct {
+	// in:body
+	Body *LockDTO `json:"body"`
+}
+
+// swagger:response editLockHeldError
+type EditLockHeldError struct {
+	// in:body
+	Body EditLockHeldBody `json:"body"`
+}
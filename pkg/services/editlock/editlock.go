@@ -0,0 +1,266 @@
+// Package editlock provides advisory edit locks on dashboards and alert rules. An editor acquires
+// the lock when starting to edit a resource and keeps it with heartbeats, so that others are warned
+// before they start editing the same resource. Locks are not enforced when saving, where the version
+// check still applies, and expire when the heartbeats stop.
+package editlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Kind is the kind of resource a lock is held on.
+type Kind string
+
+const (
+	KindDashboard Kind = "dashboard"
+	KindAlertRule Kind = "alert-rule"
+)
+
+const defaultTimeout = time.Minute
+
+var (
+	ErrLockNotFound = errors.New("edit lock not found")
+	// ErrLockHeld is wrapped by LockHeldError.
+	ErrLockHeld         = errors.New("the resource is being edited by another user")
+	ErrTakeoverDenied   = errors.New("only admins can take over edit locks")
+	ErrInvalidKind      = errors.New("invalid edit lock kind")
+	ErrResourceRequired = errors.New("edit lock resource uid is required")
+)
+
+// LockHeldError is returned when a lock is held by another user.
+type LockHeldError struct {
+	Lock *LockDTO
+}
+
+func (e LockHeldError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrLockHeld, e.Lock.Login)
+}
+
+func (e LockHeldError) Unwrap() error {
+	return ErrLockHeld
+}
+
+// Lock is held by a user on a resource until it expires.
+type Lock struct {
+	ID          int64     `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID       int64     `json:"orgId" xorm:"org_id"`
+	Kind        Kind      `json:"kind" xorm:"kind"`
+	ResourceUID string    `json:"resourceUid" xorm:"resource_uid"`
+	UserID      int64     `json:"userId" xorm:"user_id"`
+	Acquired    time.Time `json:"acquired" xorm:"acquired"`
+	Expires     time.Time `json:"expires" xorm:"expires"`
+}
+
+func (l Lock) TableName() string {
+	return "edit_lock"
+}
+
+// LockDTO is a lock together with the user holding it.
+// swagger:model EditLock
+type LockDTO struct {
+	Lock  `xorm:"extends"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// AcquireCommand acquires a lock, or extends it if it is held by the same user.
+// swagger:model
+type AcquireCommand struct {
+	OrgID       int64              `json:"-"`
+	Kind        Kind               `json:"-"`
+	ResourceUID string             `json:"-"`
+	User        *user.SignedInUser `json:"-"`
+	// Force takes over the lock of another user. Requires the organization admin role.
+	Force bool `json:"force"`
+}
+
+type Service interface {
+	// GetLock returns the lock on a resource, or ErrLockNotFound if nobody holds it.
+	GetLock(ctx context.Context, orgID int64, kind Kind, resourceUID string) (*LockDTO, error)
+	// Acquire returns a LockHeldError if another user holds the lock and it is not taken over.
+	Acquire(ctx context.Context, cmd AcquireCommand) (*LockDTO, error)
+	// Heartbeat extends the lock of a user. It returns a LockHeldError if the lock was taken over.
+	Heartbeat(ctx context.Context, orgID int64, kind Kind, resourceUID string, userID int64) (*LockDTO, error)
+	Release(ctx context.Context, orgID int64, kind Kind, resourceUID string, userID int64) error
+}
+
+type EditLockService struct {
+	store         db.DB
+	log           log.Logger
+	timeout       time.Duration
+	notifications notificationcenter.Service
+	routeRegister routing.RouteRegister
+	accessControl accesscontrol.AccessControl
+	now           func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, bus bus.Bus, notificationCenter notificationcenter.Service,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *EditLockService {
+	s := &EditLockService{
+		store:         sqlStore,
+		log:           log.New("edit-lock"),
+		timeout:       readTimeout(cfg),
+		notifications: notificationCenter,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+		now:           time.Now,
+	}
+
+	bus.AddEventListener(s.handleDashboardDeleted)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func readTimeout(cfg *setting.Cfg) time.Duration {
+	value := cfg.Raw.Section("edit_locks").Key("timeout").MustString("1m")
+	timeout, err := gtime.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+func (s *EditLockService) GetLock(ctx context.Context, orgID int64, kind Kind, resourceUID string) (*LockDTO, error) {
+	lock, err := s.getLock(ctx, orgID, kind, resourceUID)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil || s.isExpired(&lock.Lock) {
+		return nil, ErrLockNotFound
+	}
+	return lock, nil
+}
+
+func (s *EditLockService) Acquire(ctx context.Context, cmd AcquireCommand) (*LockDTO, error) {
+	if err := validate(cmd.Kind, cmd.ResourceUID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	lock := &Lock{
+		OrgID:       cmd.OrgID,
+		Kind:        cmd.Kind,
+		ResourceUID: cmd.ResourceUID,
+		UserID:      cmd.User.UserID,
+		Acquired:    now,
+		Expires:     now.Add(s.timeout),
+	}
+	previous, err := s.acquire(ctx, lock, func(existing *LockDTO) error {
+		if existing.UserID == cmd.User.UserID || s.isExpired(&existing.Lock) {
+			return nil
+		}
+		if !cmd.Force {
+			return LockHeldError{Lock: existing}
+		}
+		if !cmd.User.HasRole(org.RoleAdmin) {
+			return ErrTakeoverDenied
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.UserID != cmd.User.UserID && !s.isExpired(&previous.Lock) {
+		s.notifyTakeover(ctx, previous, cmd.User)
+	}
+
+	return &LockDTO{Lock: *lock, Login: cmd.User.Login, Name: cmd.User.Name}, nil
+}
+
+func (s *EditLockService) Heartbeat(ctx context.Context, orgID int64, kind Kind, resourceUID string, userID int64) (*LockDTO, error) {
+	if err := validate(kind, resourceUID); err != nil {
+		return nil, err
+	}
+	lock, err := s.getLock(ctx, orgID, kind, resourceUID)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, ErrLockNotFound
+	}
+	if lock.UserID != userID {
+		if s.isExpired(&lock.Lock) {
+			return nil, ErrLockNotFound
+		}
+		return nil, LockHeldError{Lock: lock}
+	}
+
+	lock.Expires = s.now().Add(s.timeout)
+	if err := s.extend(ctx, &lock.Lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (s *EditLockService) Release(ctx context.Context, orgID int64, kind Kind, resourceUID string, userID int64) error {
+	if err := validate(kind, resourceUID); err != nil {
+		return err
+	}
+	return s.release(ctx, orgID, kind, resourceUID, userID)
+}
+
+func (s *EditLockService) isExpired(lock *Lock) bool {
+	return !s.now().Before(lock.Expires)
+}
+
+func (s *EditLockService) handleDashboardDeleted(ctx context.Context, e *events.DashboardDeleted) error {
+	if err := s.deleteLocks(ctx, e.OrgID, KindDashboard, e.UID); err != nil {
+		s.log.Error("Failed to delete edit lock of deleted dashboard", "orgId", e.OrgID, "dashboardUid", e.UID, "error", err)
+	}
+	return nil
+}
+
+// notifyTakeover tells the user who held a lock that they can no longer save their changes safely.
+func (s *EditLockService) notifyTakeover(ctx context.Context, previous *LockDTO, by *user.SignedInUser) {
+	if s.notifications == nil {
+		return
+	}
+
+	kind := notificationcenter.KindDashboard
+	url := "/d/" + previous.ResourceUID
+	resource := "dashboard"
+	if previous.Kind == KindAlertRule {
+		kind = notificationcenter.KindAlertRule
+		url = "/alerting/grafana/" + previous.ResourceUID + "/view"
+		resource = "alert rule"
+	}
+	_, err := s.notifications.Notify(ctx, notificationcenter.NotifyCommand{
+		OrgID:    previous.OrgID,
+		UserIDs:  []int64{previous.UserID},
+		Kind:     kind,
+		Severity: notificationcenter.SeverityWarning,
+		Title:    fmt.Sprintf("%s took over the edit lock of the %s you were editing", by.Login, resource),
+		Body:     "Saving your changes may overwrite theirs.",
+		URL:      url,
+	})
+	if err != nil {
+		s.log.Warn("Failed to notify user of edit lock takeover", "orgId", previous.OrgID, "resourceUid", previous.ResourceUID, "error", err)
+	}
+}
+
+func validate(kind Kind, resourceUID string) error {
+	if kind != KindDashboard && kind != KindAlertRule {
+		return ErrInvalidKind
+	}
+	if resourceUID == "" {
+		return ErrResourceRequired
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package editlock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeNotificationCenter struct {
+	commands []notificationcenter.NotifyCommand
+}
+
+func (f *fakeNotificationCenter) Notify(_ context.Context, cmd notificationcenter.NotifyCommand) (int, error) {
+	f.commands = append(f.commands, cmd)
+	return len(cmd.UserIDs), nil
+}
+
+func TestIntegrationEditLocks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	sqlStore.Cfg.AutoAssignOrg = true
+	sqlStore.Cfg.AutoAssignOrgId = 1
+	ctx := context.Background()
+
+	signedIn := func(login string, role org.RoleType) *user.SignedInUser {
+		u, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: login, Email: login + "@example.com", Name: login})
+		require.NoError(t, err)
+		return &user.SignedInUser{UserID: u.ID, OrgID: u.OrgID, Login: u.Login, Name: u.Name, OrgRole: role}
+	}
+	alice := signedIn("alice", org.RoleEditor)
+	bob := signedIn("bob", org.RoleEditor)
+	admin := signedIn("admin", org.RoleAdmin)
+	orgID := alice.OrgID
+
+	eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	notificationCenter := &fakeNotificationCenter{}
+	s := ProvideService(setting.NewCfg(), sqlStore, eventBus, notificationCenter, routing.NewRouteRegister(), actest.FakeAccessControl{})
+	now := time.Date(2022, 11, 28, 8, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	acquire := func(u *user.SignedInUser, uid string, force bool) (*LockDTO, error) {
+		return s.Acquire(ctx, AcquireCommand{OrgID: orgID, Kind: KindDashboard, ResourceUID: uid, User: u, Force: force})
+	}
+
+	t.Run("acquire and get lock", func(t *testing.T) {
+		lock, err := acquire(alice, "dash", false)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(time.Minute), lock.Expires)
+
+		lock, err = s.GetLock(ctx, orgID, KindDashboard, "dash")
+		require.NoError(t, err)
+		assert.Equal(t, alice.UserID, lock.UserID)
+		assert.Equal(t, "alice", lock.Login)
+
+		_, err = s.GetLock(ctx, orgID, KindAlertRule, "dash")
+		assert.ErrorIs(t, err, ErrLockNotFound)
+	})
+
+	t.Run("acquiring again extends the lock", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		lock, err := acquire(alice, "dash", false)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-30*time.Second), lock.Acquired)
+		assert.Equal(t, now.Add(time.Minute), lock.Expires)
+	})
+
+	t.Run("lock held by another user", func(t *testing.T) {
+		_, err := acquire(bob, "dash", false)
+		var held LockHeldError
+		require.True(t, errors.As(err, &held))
+		assert.ErrorIs(t, err, ErrLockHeld)
+		assert.Equal(t, "alice", held.Lock.Login)
+
+		_, err = acquire(bob, "dash", true)
+		assert.ErrorIs(t, err, ErrTakeoverDenied)
+
+		_, err = s.Heartbeat(ctx, orgID, KindDashboard, "dash", bob.UserID)
+		assert.ErrorIs(t, err, ErrLockHeld)
+		assert.ErrorIs(t, s.Release(ctx, orgID, KindDashboard, "dash", bob.UserID), ErrLockNotFound)
+	})
+
+	t.Run("admins take over locks", func(t *testing.T) {
+		lock, err := acquire(admin, "dash", true)
+		require.NoError(t, err)
+		assert.Equal(t, admin.UserID, lock.UserID)
+
+		require.Len(t, notificationCenter.commands, 1)
+		assert.Equal(t, []int64{alice.UserID}, notificationCenter.commands[0].UserIDs)
+
+		_, err = s.Heartbeat(ctx, orgID, KindDashboard, "dash", alice.UserID)
+		assert.ErrorIs(t, err, ErrLockHeld)
+	})
+
+	t.Run("heartbeats keep the lock", func(t *testing.T) {
+		now = now.Add(50 * time.Second)
+		lock, err := s.Heartbeat(ctx, orgID, KindDashboard, "dash", admin.UserID)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(time.Minute), lock.Expires)
+
+		now = now.Add(50 * time.Second)
+		_, err = acquire(bob, "dash", false)
+		assert.ErrorIs(t, err, ErrLockHeld)
+	})
+
+	t.Run("expired locks can be acquired", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, err := s.GetLock(ctx, orgID, KindDashboard, "dash")
+		assert.ErrorIs(t, err, ErrLockNotFound)
+
+		lock, err := acquire(bob, "dash", false)
+		require.NoError(t, err)
+		assert.Equal(t, now, lock.Acquired)
+		// nobody is notified when an expired lock is replaced
+		assert.Len(t, notificationCenter.commands, 1)
+
+		_, err = s.Heartbeat(ctx, orgID, KindDashboard, "dash", admin.UserID)
+		assert.ErrorIs(t, err, ErrLockHeld)
+	})
+
+	t.Run("release lock", func(t *testing.T) {
+		require.NoError(t, s.Release(ctx, orgID, KindDashboard, "dash", bob.UserID))
+		_, err := s.GetLock(ctx, orgID, KindDashboard, "dash")
+		assert.ErrorIs(t, err, ErrLockNotFound)
+		_, err = s.Heartbeat(ctx, orgID, KindDashboard, "dash", bob.UserID)
+		assert.ErrorIs(t, err, ErrLockNotFound)
+	})
+
+	t.Run("locks of deleted dashboards are deleted", func(t *testing.T) {
+		_, err := acquire(alice, "deleted", false)
+		require.NoError(t, err)
+		require.NoError(t, eventBus.Publish(ctx, &events.DashboardDeleted{OrgID: orgID, UID: "deleted"}))
+		_, err = s.GetLock(ctx, orgID, KindDashboard, "deleted")
+		assert.ErrorIs(t, err, ErrLockNotFound)
+	})
+
+	t.Run("invalid kind", func(t *testing.T) {
+		_, err := s.Acquire(ctx, AcquireCommand{OrgID: orgID, Kind: "folder", ResourceUID: "dash", User: alice})
+		assert.ErrorIs(t, err, ErrInvalidKind)
+	})
+}
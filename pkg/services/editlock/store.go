@@ -0,0 +1,89 @@
+package editlock
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *EditLockService) getLock(ctx context.Context, orgID int64, kind Kind, resourceUID string) (*LockDTO, error) {
+	var lock *LockDTO
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		lock, err = s.getLockInSession(sess, orgID, kind, resourceUID)
+		return err
+	})
+	return lock, err
+}
+
+func (s *EditLockService) getLockInSession(sess *db.Session, orgID int64, kind Kind, resourceUID string) (*LockDTO, error) {
+	var lock LockDTO
+	rawSQL := "SELECT edit_lock.*, u.login, u.name FROM edit_lock" +
+		" LEFT JOIN " + s.store.GetDialect().Quote("user") + " AS u ON u.id = edit_lock.user_id" +
+		" WHERE edit_lock.org_id = ? AND edit_lock.kind = ? AND edit_lock.resource_uid = ?"
+	has, err := sess.SQL(rawSQL, orgID, kind, resourceUID).Get(&lock)
+	if err != nil || !has {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// acquire inserts the lock, or replaces the existing lock if check allows it. It returns the lock it
+// replaced, if any.
+func (s *EditLockService) acquire(ctx context.Context, lock *Lock, check func(existing *LockDTO) error) (*LockDTO, error) {
+	var previous *LockDTO
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := s.getLockInSession(sess, lock.OrgID, lock.Kind, lock.ResourceUID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			_, err := sess.Insert(lock)
+			if err != nil && s.store.GetDialect().IsUniqueConstraintViolation(err) {
+				// another user acquired the lock in the meantime
+				return ErrLockHeld
+			}
+			return err
+		}
+
+		if err := check(existing); err != nil {
+			return err
+		}
+		// acquiring a lock again keeps the time it was first acquired
+		if existing.UserID == lock.UserID && !s.isExpired(&existing.Lock) {
+			lock.Acquired = existing.Acquired
+		}
+		lock.ID = existing.ID
+		previous = existing
+		_, err = sess.ID(existing.ID).Cols("user_id", "acquired", "expires").Update(lock)
+		return err
+	})
+	return previous, err
+}
+
+func (s *EditLockService) extend(ctx context.Context, lock *Lock) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(lock.ID).Cols("expires").Update(lock)
+		return err
+	})
+}
+
+func (s *EditLockService) release(ctx context.Context, orgID int64, kind Kind, resourceUID string, userID int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND kind = ? AND resource_uid = ? AND user_id = ?", orgID, kind, resourceUID, userID).Delete(&Lock{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrLockNotFound
+		}
+		return nil
+	})
+}
+
+func (s *EditLockService) deleteLocks(ctx context.Context, orgID int64, kind Kind, resourceUID string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND kind = ? AND resource_uid = ?", orgID, kind, resourceUID).Delete(&Lock{})
+		return err
+	})
+}
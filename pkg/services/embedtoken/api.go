@@ -0,0 +1,209 @@
+package embedtoken
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *EmbedTokenService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+	canWrite := authorize(middleware.ReqSignedInNoAnonymous, ac.EvalPermission(dashboards.ActionDashboardsWrite, uidScope))
+
+	s.routeRegister.Group("/api/dashboards/uid/:uid", func(dashboardRoute routing.RouteRegister) {
+		dashboardRoute.Post("/embed-token", canWrite, routing.Wrap(s.createTokenHandler))
+		dashboardRoute.Get("/embed-tokens", canWrite, routing.Wrap(s.getTokensHandler))
+		dashboardRoute.Delete("/embed-tokens/:tokenUid", canWrite, routing.Wrap(s.revokeTokenHandler))
+	})
+
+	s.routeRegister.Group("/api/embed/:embedToken", func(embedRoute routing.RouteRegister) {
+		embedRoute.Get("/dashboard", routing.Wrap(s.getDashboardHandler))
+		embedRoute.Post("/panels/:panelId/query", routing.Wrap(s.queryPanelHandler))
+	}, RequireEmbedToken(s))
+}
+
+// swagger:route POST /dashboards/uid/{uid}/embed-token dashboards createDashboardEmbedToken
+//
+// Create an embed token for a dashboard.
+//
+// The token gives view-only access to the dashboard, or to a single panel of it, until it expires. The returned URL can be used as the source of an iframe, without enabling anonymous access. The token is only returned once.
+//
+// Responses:
+// 200: createEmbedTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *EmbedTokenService) createTokenHandler(c *models.ReqContext) response.Response {
+	cmd := CreateTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.DashboardUID = web.Params(c.Req)[":uid"]
+	cmd.CreatedBy = c.UserID
+
+	result, err := s.CreateToken(c.Req.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidLifetime), errors.Is(err, ErrPanelNotFound):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, dashboards.ErrDashboardNotFound):
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to create embed token", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /dashboards/uid/{uid}/embed-tokens dashboards getDashboardEmbedTokens
+//
+// Get the embed tokens of a dashboard.
+//
+// Responses:
+// 200: getEmbedTokensResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *EmbedTokenService) getTokensHandler(c *models.ReqContext) response.Response {
+	tokens, err := s.GetTokens(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get embed tokens", err)
+	}
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route DELETE /dashboards/uid/{uid}/embed-tokens/{token_uid} dashboards revokeDashboardEmbedToken
+//
+// Revoke an embed token of a dashboard.
+//
+// Other Grafana instances may accept a revoked token for up to a minute.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *EmbedTokenService) revokeTokenHandler(c *models.ReqContext) response.Response {
+	err := s.RevokeToken(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"], web.Params(c.Req)[":tokenUid"])
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return response.Error(http.StatusNotFound, "Embed token not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to revoke embed token", err)
+	}
+	return response.Success("Embed token revoked")
+}
+
+// getDashboardHandler returns the embedded dashboard with read-only permissions.
+// GET /api/embed/:embedToken/dashboard
+func (s *EmbedTokenService) getDashboardHandler(c *models.ReqContext) response.Response {
+	v, ok := viewFromContext(c.Req.Context())
+	if !ok {
+		return response.Error(http.StatusUnauthorized, ErrTokenInvalid.Error(), nil)
+	}
+
+	data, err := viewData(v.token, v.dashboard)
+	if err != nil {
+		if errors.Is(err, ErrPanelNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+
+	meta := dtos.DashboardMeta{
+		Slug:     v.dashboard.Slug,
+		Type:     models.DashTypeDB,
+		Created:  v.dashboard.Created,
+		Updated:  v.dashboard.Updated,
+		Version:  v.dashboard.Version,
+		FolderId: v.dashboard.FolderId,
+	}
+	return response.JSON(http.StatusOK, dtos.DashboardFullWithMeta{Meta: meta, Dashboard: data})
+}
+
+// queryPanelHandler runs the queries of an embedded panel. The time range defaults to the one
+// of the dashboard.
+// POST /api/embed/:embedToken/panels/:panelId/query
+func (s *EmbedTokenService) queryPanelHandler(c *models.ReqContext) response.Response {
+	v, ok := viewFromContext(c.Req.Context())
+	if !ok {
+		return response.Error(http.StatusUnauthorized, ErrTokenInvalid.Error(), nil)
+	}
+
+	cmd := QueryPanelCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	metricReq, err := buildMetricRequest(v.dashboard, web.Params(c.Req)[":panelId"], cmd)
+	if err != nil {
+		if errors.Is(err, ErrPanelNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	resp, err := s.queryService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipCache, metricReq)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to query panel", err)
+	}
+
+	statusCode := http.StatusOK
+	for _, res := range resp.Responses {
+		if res.Error != nil {
+			statusCode = http.StatusBadRequest
+		}
+	}
+	return response.JSONStreaming(statusCode, resp)
+}
+
+// swagger:parameters createDashboardEmbedToken
+type CreateDashboardEmbedTokenParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body CreateTokenCommand `json:"body"`
+}
+
+// swagger:parameters getDashboardEmbedTokens
+type GetDashboardEmbedTokensParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters revokeDashboardEmbedToken
+type RevokeDashboardEmbedTokenParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:path
+	// required:true
+	TokenUID string `json:"token_uid"`
+}
+
+// swagger:response createEmbedTokenResponse
+type CreateEmbedTokenResponse struct {
+	// in:body
+	Body *CreateTokenResult `json:"body"`
+}
+
+// swagger:response getEmbedTokensResponse
+type GetEmbedTokensResponse struct {
+	// in:body
+	Body []*EmbedToken `json:"body"`
+}
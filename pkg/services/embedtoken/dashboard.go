@@ -0,0 +1,176 @@
+package embedtoken
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// embedLogin is the login of the user requests made with an embed token are made as.
+const embedLogin = "embed-token"
+
+// QueryPanelCommand is the time range and resolution the queries of an embedded panel are run with.
+type QueryPanelCommand struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	IntervalMs    int64  `json:"intervalMs"`
+	MaxDataPoints int64  `json:"maxDataPoints"`
+}
+
+// panels returns the panels of a dashboard, including the panels of collapsed rows.
+func panels(data *simplejson.Json) []*simplejson.Json {
+	var result []*simplejson.Json
+	for _, panelObj := range data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		result = append(result, panel)
+		for _, nestedObj := range panel.Get("panels").MustArray() {
+			result = append(result, simplejson.NewFromAny(nestedObj))
+		}
+	}
+	return result
+}
+
+func findPanel(data *simplejson.Json, panelID int64) (*simplejson.Json, bool) {
+	for _, panel := range panels(data) {
+		if panel.Get("id").MustInt64() == panelID {
+			return panel, true
+		}
+	}
+	return nil, false
+}
+
+// embeddedPanels returns the panels a token gives access to.
+func embeddedPanels(token *EmbedToken, dash *models.Dashboard) []*simplejson.Json {
+	if token.PanelID == 0 {
+		return panels(dash.Data)
+	}
+	if panel, ok := findPanel(dash.Data, token.PanelID); ok {
+		return []*simplejson.Json{panel}
+	}
+	return nil
+}
+
+// viewData returns the dashboard JSON served to embed views, which only contains the embedded panel
+// for tokens limited to a single panel.
+func viewData(token *EmbedToken, dash *models.Dashboard) (*simplejson.Json, error) {
+	encoded, err := dash.Data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	data, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if token.PanelID == 0 {
+		return data, nil
+	}
+
+	panel, ok := findPanel(data, token.PanelID)
+	if !ok {
+		return nil, ErrPanelNotFound
+	}
+	panel.SetPath([]string{"gridPos", "x"}, 0)
+	panel.SetPath([]string{"gridPos", "y"}, 0)
+	data.Set("panels", []interface{}{panel.Interface()})
+	return data, nil
+}
+
+// panelQueries returns the queries of a panel which are not hidden. Queries without a data source use
+// the data source of the panel.
+func panelQueries(panel *simplejson.Json) []*simplejson.Json {
+	var queries []*simplejson.Json
+	for _, queryObj := range panel.Get("targets").MustArray() {
+		query := simplejson.NewFromAny(queryObj)
+		if query.Get("hide").MustBool() {
+			continue
+		}
+		if _, ok := query.CheckGet("datasource"); !ok {
+			query.Set("datasource", panel.Get("datasource").Interface())
+		}
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+// datasourceUID returns the UID of the data source of a panel or query. Before 8.3 the data
+// source was referenced by name.
+func datasourceUID(obj *simplejson.Json) string {
+	if uid := obj.Get("datasource").Get("uid").MustString(); uid != "" {
+		return uid
+	}
+	return obj.Get("datasource").MustString()
+}
+
+// embedUser returns the view-only user requests made with a token are made as. It can read the
+// embedded dashboard and query the data sources of the embedded panels.
+func embedUser(token *EmbedToken, dash *models.Dashboard) *user.SignedInUser {
+	queryScopes := make([]string, 0)
+	seen := map[string]bool{}
+	for _, panel := range embeddedPanels(token, dash) {
+		for _, query := range panelQueries(panel) {
+			uid := datasourceUID(query)
+			if uid == "" || seen[uid] {
+				continue
+			}
+			seen[uid] = true
+			queryScopes = append(queryScopes, datasources.ScopeProvider.GetResourceScopeUID(uid))
+		}
+	}
+
+	return &user.SignedInUser{
+		OrgID:       token.OrgID,
+		OrgRole:     org.RoleViewer,
+		Login:       embedLogin,
+		IsAnonymous: true,
+		Permissions: map[int64]map[string][]string{
+			token.OrgID: {
+				dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsProvider.GetResourceScopeUID(token.DashboardUID)},
+				datasources.ActionQuery:         queryScopes,
+				datasources.ActionRead:          queryScopes,
+			},
+		},
+	}
+}
+
+// buildMetricRequest returns the request running the queries of a panel, the time range of the
+// dashboard is used unless the command sets one.
+func buildMetricRequest(dash *models.Dashboard, panelParam string, cmd QueryPanelCommand) (dtos.MetricRequest, error) {
+	panelID, err := strconv.ParseInt(panelParam, 10, 64)
+	if err != nil {
+		return dtos.MetricRequest{}, fmt.Errorf("invalid panel id %q", panelParam)
+	}
+	panel, ok := findPanel(dash.Data, panelID)
+	if !ok {
+		return dtos.MetricRequest{}, ErrPanelNotFound
+	}
+
+	queries := panelQueries(panel)
+	if len(queries) == 0 {
+		return dtos.MetricRequest{}, fmt.Errorf("panel %d has no queries", panelID)
+	}
+	for _, query := range queries {
+		if cmd.IntervalMs > 0 {
+			query.Set("intervalMs", cmd.IntervalMs)
+		}
+		if cmd.MaxDataPoints > 0 {
+			query.Set("maxDataPoints", cmd.MaxDataPoints)
+		}
+	}
+
+	from, to := cmd.From, cmd.To
+	if from == "" {
+		from = dash.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dash.Data.Get("time").Get("to").MustString("now")
+	}
+
+	return dtos.MetricRequest{From: from, To: to, Queries: queries}, nil
+}
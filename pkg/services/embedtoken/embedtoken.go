@@ -0,0 +1,244 @@
+// Package embedtoken issues signed tokens which allow a single dashboard or panel to be embedded
+// with view-only access, without enabling anonymous access for the whole instance.
+package embedtoken
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultLifetime = 24 * time.Hour
+
+	// tokenCacheTTL is how long an instance accepts a token after it was revoked on another instance.
+	tokenCacheTTL   = time.Minute
+	cleanupInterval = time.Hour
+)
+
+var (
+	ErrTokenInvalid    = errors.New("invalid embed token")
+	ErrTokenExpired    = errors.New("embed token has expired")
+	ErrTokenNotFound   = errors.New("embed token not found")
+	ErrPanelNotFound   = errors.New("panel not found in dashboard")
+	ErrInvalidLifetime = errors.New("invalid embed token lifetime")
+)
+
+// EmbedToken gives view-only access to a dashboard, or to a single panel of it when PanelID is set.
+// The signed token itself is only returned when it is created.
+type EmbedToken struct {
+	ID           int64     `json:"-" xorm:"pk autoincr 'id'"`
+	UID          string    `json:"uid" xorm:"uid"`
+	OrgID        int64     `json:"orgId" xorm:"org_id"`
+	DashboardUID string    `json:"dashboardUid" xorm:"dashboard_uid"`
+	PanelID      int64     `json:"panelId,omitempty" xorm:"panel_id"`
+	CreatedBy    int64     `json:"createdBy" xorm:"created_by"`
+	Created      time.Time `json:"created" xorm:"created"`
+	Expires      time.Time `json:"expires" xorm:"expires"`
+}
+
+func (t EmbedToken) TableName() string {
+	return "embed_token"
+}
+
+type CreateTokenCommand struct {
+	OrgID        int64  `json:"-"`
+	DashboardUID string `json:"-"`
+	// PanelID limits the token to a single panel of the dashboard.
+	PanelID int64 `json:"panelId"`
+	// ExpiresIn is the lifetime of the token, such as 12h or 7d. It defaults to 24h and cannot
+	// exceed the configured maximum lifetime.
+	ExpiresIn string `json:"expiresIn"`
+	CreatedBy int64  `json:"-"`
+}
+
+// CreateTokenResult is a created token, together with the URL the dashboard or panel can be embedded from.
+// swagger:model
+type CreateTokenResult struct {
+	EmbedToken
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type Service interface {
+	CreateToken(ctx context.Context, cmd CreateTokenCommand) (*CreateTokenResult, error)
+	// GetTokens returns the tokens of a dashboard, newest first.
+	GetTokens(ctx context.Context, orgID int64, dashboardUID string) ([]*EmbedToken, error)
+	RevokeToken(ctx context.Context, orgID int64, dashboardUID string, uid string) error
+	// Verify checks the signature and expiry of a token, and that it was not revoked.
+	Verify(ctx context.Context, token string) (*EmbedToken, error)
+}
+
+type EmbedTokenService struct {
+	cfg              *setting.Cfg
+	store            db.DB
+	dashboardService dashboards.DashboardService
+	queryService     *query.Service
+	routeRegister    routing.RouteRegister
+	accessControl    accesscontrol.AccessControl
+	cache            *localcache.CacheService
+	signingKey       []byte
+	log              log.Logger
+	now              func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, dashboardService dashboards.DashboardService, queryService *query.Service,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *EmbedTokenService {
+	key := sha256.Sum256([]byte("embed-token:" + cfg.SecretKey))
+	s := &EmbedTokenService{
+		cfg:              cfg,
+		store:            sqlStore,
+		dashboardService: dashboardService,
+		queryService:     queryService,
+		routeRegister:    routeRegister,
+		accessControl:    accessControl,
+		cache:            localcache.New(tokenCacheTTL, 2*tokenCacheTTL),
+		signingKey:       key[:],
+		log:              log.New("embed-token"),
+		now:              time.Now,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *EmbedTokenService) CreateToken(ctx context.Context, cmd CreateTokenCommand) (*CreateTokenResult, error) {
+	lifetime := defaultLifetime
+	if cmd.ExpiresIn != "" {
+		var err error
+		if lifetime, err = gtime.ParseDuration(cmd.ExpiresIn); err != nil || lifetime <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLifetime, cmd.ExpiresIn)
+		}
+	}
+	if s.cfg.EmbedTokenMaxLifetime > 0 && lifetime > s.cfg.EmbedTokenMaxLifetime {
+		return nil, fmt.Errorf("%w: it cannot exceed %s", ErrInvalidLifetime, s.cfg.EmbedTokenMaxLifetime)
+	}
+
+	dash, err := s.getDashboard(ctx, cmd.OrgID, cmd.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.PanelID != 0 {
+		if _, ok := findPanel(dash.Data, cmd.PanelID); !ok {
+			return nil, ErrPanelNotFound
+		}
+	}
+
+	now := s.now()
+	token := &EmbedToken{
+		UID:          util.GenerateShortUID(),
+		OrgID:        cmd.OrgID,
+		DashboardUID: dash.Uid,
+		PanelID:      cmd.PanelID,
+		CreatedBy:    cmd.CreatedBy,
+		Created:      now,
+		Expires:      now.Add(lifetime),
+	}
+
+	signed, err := s.sign(claimsOf(token))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.insertToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Embed token created", "uid", token.UID, "orgId", token.OrgID, "dashboardUid", token.DashboardUID,
+		"panelId", token.PanelID, "createdBy", token.CreatedBy, "expires", token.Expires)
+
+	return &CreateTokenResult{
+		EmbedToken: *token,
+		Token:      signed,
+		URL:        s.cfg.AppURL + "embed/" + signed,
+	}, nil
+}
+
+func (s *EmbedTokenService) GetTokens(ctx context.Context, orgID int64, dashboardUID string) ([]*EmbedToken, error) {
+	return s.getTokens(ctx, orgID, dashboardUID)
+}
+
+func (s *EmbedTokenService) RevokeToken(ctx context.Context, orgID int64, dashboardUID string, uid string) error {
+	if err := s.deleteToken(ctx, orgID, dashboardUID, uid); err != nil {
+		return err
+	}
+	s.cache.Delete(cacheKey(uid))
+	s.log.Info("Embed token revoked", "uid", uid, "orgId", orgID, "dashboardUid", dashboardUID)
+	return nil
+}
+
+func (s *EmbedTokenService) Verify(ctx context.Context, signed string) (*EmbedToken, error) {
+	claims, err := s.parse(signed)
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(time.Unix(claims.Expires, 0)) {
+		return nil, ErrTokenExpired
+	}
+
+	var token *EmbedToken
+	if cached, ok := s.cache.Get(cacheKey(claims.UID)); ok {
+		token = cached.(*EmbedToken)
+	} else {
+		if token, err = s.getToken(ctx, claims.UID); err != nil {
+			return nil, err
+		}
+		s.cache.Set(cacheKey(claims.UID), token, tokenCacheTTL)
+	}
+
+	if claimsOf(token) != *claims {
+		return nil, ErrTokenInvalid
+	}
+	return token, nil
+}
+
+// Run removes expired tokens.
+func (s *EmbedTokenService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			removed, err := s.deleteTokensExpiredBefore(ctx, s.now())
+			if err != nil {
+				s.log.Error("Failed to clean up expired embed tokens", "error", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Debug("Cleaned up expired embed tokens", "removed", removed)
+			}
+		}
+	}
+}
+
+func (s *EmbedTokenService) getDashboard(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
+	query := &models.GetDashboardQuery{Uid: uid, OrgId: orgID}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return nil, err
+	}
+	if query.Result.IsFolder {
+		return nil, dashboards.ErrDashboardNotFound
+	}
+	return query.Result, nil
+}
+
+func cacheKey(uid string) string {
+	return "embed-token-" + uid
+}
@@ -0,0 +1,241 @@
+package embedtoken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIntegrationEmbedToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s := setupService(t)
+
+	t.Run("Should verify a created token", func(t *testing.T) {
+		result, err := s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash", PanelID: 2, ExpiresIn: "12h", CreatedBy: 10})
+		require.NoError(t, err)
+		assert.Equal(t, s.cfg.AppURL+"embed/"+result.Token, result.URL)
+		assert.Equal(t, int64(2), result.PanelID)
+
+		token, err := s.Verify(ctx, result.Token)
+		require.NoError(t, err)
+		assert.Equal(t, result.UID, token.UID)
+		assert.Equal(t, "dash", token.DashboardUID)
+		assert.Equal(t, int64(2), token.PanelID)
+
+		tokens, err := s.GetTokens(ctx, 1, "dash")
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, result.UID, tokens[0].UID)
+	})
+
+	t.Run("Should not create invalid tokens", func(t *testing.T) {
+		_, err := s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash", PanelID: 99})
+		assert.ErrorIs(t, err, ErrPanelNotFound)
+
+		_, err = s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash", ExpiresIn: "31d"})
+		assert.ErrorIs(t, err, ErrInvalidLifetime)
+
+		_, err = s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash", ExpiresIn: "soon"})
+		assert.ErrorIs(t, err, ErrInvalidLifetime)
+	})
+
+	t.Run("Should reject tampered tokens", func(t *testing.T) {
+		result, err := s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash", PanelID: 2})
+		require.NoError(t, err)
+
+		other, err := s.sign(tokenClaims{UID: result.UID, OrgID: 1, DashboardUID: "dash", Expires: result.Expires.Unix()})
+		require.NoError(t, err)
+		_, err = s.Verify(ctx, other)
+		assert.ErrorIs(t, err, ErrTokenInvalid, "the claims should match the stored token")
+
+		_, err = s.Verify(ctx, result.Token+"x")
+		assert.ErrorIs(t, err, ErrTokenInvalid)
+
+		_, err = s.Verify(ctx, "not-a-token")
+		assert.ErrorIs(t, err, ErrTokenInvalid)
+	})
+
+	t.Run("Should reject expired tokens", func(t *testing.T) {
+		result, err := s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash", ExpiresIn: "1h"})
+		require.NoError(t, err)
+
+		s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		t.Cleanup(func() { s.now = time.Now })
+
+		_, err = s.Verify(ctx, result.Token)
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("Should reject revoked tokens", func(t *testing.T) {
+		result, err := s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash"})
+		require.NoError(t, err)
+		_, err = s.Verify(ctx, result.Token)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, s.RevokeToken(ctx, 1, "other", result.UID), ErrTokenNotFound)
+		require.NoError(t, s.RevokeToken(ctx, 1, "dash", result.UID))
+
+		_, err = s.Verify(ctx, result.Token)
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+}
+
+func TestIntegrationRequireEmbedToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s := setupService(t)
+
+	panelToken, err := s.CreateToken(ctx, CreateTokenCommand{OrgID: 1, DashboardUID: "dash", PanelID: 2})
+	require.NoError(t, err)
+
+	t.Run("Should sign requests in as a view-only user of the dashboard", func(t *testing.T) {
+		c, resp := runMiddleware(t, s, map[string]string{":embedToken": panelToken.Token, ":panelId": "2"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.True(t, c.IsEmbedView)
+		assert.False(t, c.IsSignedIn)
+		assert.Equal(t, int64(1), c.OrgID)
+		assert.Equal(t, []string{"dashboards:uid:dash"}, c.SignedInUser.Permissions[1][dashboards.ActionDashboardsRead])
+		assert.Equal(t, []string{"datasources:uid:prom"}, c.SignedInUser.Permissions[1][datasources.ActionQuery],
+			"only the data sources of the embedded panel should be queried")
+
+		v, ok := viewFromContext(c.Req.Context())
+		require.True(t, ok)
+		assert.Equal(t, panelToken.UID, v.token.UID)
+	})
+
+	t.Run("Should reject requests for other panels", func(t *testing.T) {
+		_, resp := runMiddleware(t, s, map[string]string{":embedToken": panelToken.Token, ":panelId": "3"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Should reject invalid tokens", func(t *testing.T) {
+		c, resp := runMiddleware(t, s, map[string]string{":embedToken": "invalid"})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.False(t, c.IsEmbedView)
+	})
+}
+
+func TestBuildMetricRequest(t *testing.T) {
+	dash := testDashboard()
+
+	req, err := buildMetricRequest(dash, "3", QueryPanelCommand{MaxDataPoints: 500})
+	require.NoError(t, err)
+	assert.Equal(t, "now-24h", req.From)
+	assert.Equal(t, "now", req.To)
+	require.Len(t, req.Queries, 1, "hidden queries should not run")
+	assert.Equal(t, "loki", req.Queries[0].Get("datasource").Get("uid").MustString(), "queries should default to the data source of the panel")
+	assert.Equal(t, int64(500), req.Queries[0].Get("maxDataPoints").MustInt64())
+
+	req, err = buildMetricRequest(dash, "2", QueryPanelCommand{From: "now-1h", To: "now-5m"})
+	require.NoError(t, err)
+	assert.Equal(t, "now-1h", req.From)
+	assert.Equal(t, "now-5m", req.To)
+
+	_, err = buildMetricRequest(dash, "99", QueryPanelCommand{})
+	assert.ErrorIs(t, err, ErrPanelNotFound)
+}
+
+func TestViewData(t *testing.T) {
+	dash := testDashboard()
+
+	data, err := viewData(&EmbedToken{PanelID: 3}, dash)
+	require.NoError(t, err)
+	require.Len(t, data.Get("panels").MustArray(), 1)
+	panel := data.Get("panels").GetIndex(0)
+	assert.Equal(t, int64(3), panel.Get("id").MustInt64())
+	assert.Equal(t, 0, panel.GetPath("gridPos", "y").MustInt())
+
+	assert.Len(t, dash.Data.Get("panels").MustArray(), 2, "the stored dashboard should not change")
+}
+
+func setupService(t *testing.T) *EmbedTokenService {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.SecretKey = "secret"
+	cfg.EmbedTokenMaxLifetime = 30 * 24 * time.Hour
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		query := args.Get(1).(*models.GetDashboardQuery)
+		query.Result = testDashboard()
+		query.Result.OrgId = query.OrgId
+	}).Return(nil).Maybe()
+
+	return ProvideService(cfg, db.InitTestDB(t), dashboardService, nil, routing.NewRouteRegister(), actest.FakeAccessControl{})
+}
+
+// testDashboard returns a dashboard with a prometheus panel and a collapsed row with a loki panel.
+func testDashboard() *models.Dashboard {
+	dash := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+		"uid":   "dash",
+		"title": "Embedded",
+		"time":  map[string]interface{}{"from": "now-24h", "to": "now"},
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id":         2,
+				"gridPos":    map[string]interface{}{"x": 0, "y": 0, "w": 12, "h": 8},
+				"datasource": map[string]interface{}{"type": "prometheus", "uid": "prom"},
+				"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": "up"}},
+			},
+			map[string]interface{}{
+				"id":        10,
+				"type":      "row",
+				"collapsed": true,
+				"panels": []interface{}{
+					map[string]interface{}{
+						"id":         3,
+						"gridPos":    map[string]interface{}{"x": 12, "y": 9, "w": 12, "h": 8},
+						"datasource": map[string]interface{}{"type": "loki", "uid": "loki"},
+						"targets": []interface{}{
+							map[string]interface{}{"refId": "A", "expr": "{job=\"app\"}"},
+							map[string]interface{}{"refId": "B", "expr": "{job=\"db\"}", "hide": true},
+						},
+					},
+				},
+			},
+		},
+	}))
+	dash.OrgId = 1
+	return dash
+}
+
+func runMiddleware(t *testing.T, s *EmbedTokenService, params map[string]string) (*models.ReqContext, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/embed/token/dashboard", nil)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	c := &models.ReqContext{
+		Context:      &web.Context{Req: web.SetURLParams(req, params), Resp: web.NewResponseWriter(http.MethodGet, resp)},
+		SignedInUser: &user.SignedInUser{},
+		Logger:       log.New("test"),
+	}
+
+	RequireEmbedToken(s)(c)
+	return c, resp
+}
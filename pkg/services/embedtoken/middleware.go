@@ -0,0 +1,65 @@
+package embedtoken
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type viewKey struct{}
+
+// view is the token and dashboard of an embed view request.
+type view struct {
+	token     *EmbedToken
+	dashboard *models.Dashboard
+}
+
+// RequireEmbedToken verifies the token in the :embedToken route parameter, and signs the request in as a
+// view-only user of the embedded dashboard. Requests for other panels than the embedded one are rejected.
+func RequireEmbedToken(s *EmbedTokenService) func(c *models.ReqContext) {
+	return func(c *models.ReqContext) {
+		token, err := s.Verify(c.Req.Context(), web.Params(c.Req)[":embedToken"])
+		if err != nil {
+			if errors.Is(err, ErrTokenInvalid) || errors.Is(err, ErrTokenExpired) || errors.Is(err, ErrTokenNotFound) {
+				c.JsonApiErr(http.StatusUnauthorized, err.Error(), nil)
+				return
+			}
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to verify embed token", err)
+			return
+		}
+
+		if panelParam, ok := web.Params(c.Req)[":panelId"]; ok && token.PanelID != 0 {
+			if panelID, err := strconv.ParseInt(panelParam, 10, 64); err != nil || panelID != token.PanelID {
+				c.JsonApiErr(http.StatusForbidden, "The embed token does not give access to this panel", nil)
+				return
+			}
+		}
+
+		dash, err := s.getDashboard(c.Req.Context(), token.OrgID, token.DashboardUID)
+		if err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				c.JsonApiErr(http.StatusNotFound, "Dashboard not found", nil)
+				return
+			}
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to get dashboard", err)
+			return
+		}
+
+		c.SignedInUser = embedUser(token, dash)
+		c.IsSignedIn = false
+		c.AllowAnonymous = true
+		c.IsEmbedView = true
+		c.Logger = c.Logger.New("embedToken", token.UID)
+		*c.Req = *c.Req.WithContext(context.WithValue(c.Req.Context(), viewKey{}, &view{token: token, dashboard: dash}))
+	}
+}
+
+func viewFromContext(ctx context.Context) (*view, bool) {
+	v, ok := ctx.Value(viewKey{}).(*view)
+	return v, ok
+}
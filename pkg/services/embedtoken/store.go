@@ -0,0 +1,64 @@
+package embedtoken
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *EmbedTokenService) insertToken(ctx context.Context, token *EmbedToken) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(token)
+		return err
+	})
+}
+
+func (s *EmbedTokenService) getToken(ctx context.Context, uid string) (*EmbedToken, error) {
+	var token EmbedToken
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("uid = ?", uid).Get(&token)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrTokenNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *EmbedTokenService) getTokens(ctx context.Context, orgID int64, dashboardUID string) ([]*EmbedToken, error) {
+	tokens := make([]*EmbedToken, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Desc("created", "id").Find(&tokens)
+	})
+	return tokens, err
+}
+
+func (s *EmbedTokenService) deleteToken(ctx context.Context, orgID int64, dashboardUID string, uid string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND dashboard_uid = ? AND uid = ?", orgID, dashboardUID, uid).Delete(&EmbedToken{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrTokenNotFound
+		}
+		return nil
+	})
+}
+
+func (s *EmbedTokenService) deleteTokensExpiredBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		deleted, err = sess.Where("expires < ?", before).Delete(&EmbedToken{})
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,65 @@
+package embedtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// tokenClaims are the signed content of a token, the token is the base64 encoded claims and their
+// HMAC-SHA256 signature separated by a dot.
+type tokenClaims struct {
+	UID          string `json:"uid"`
+	OrgID        int64  `json:"org"`
+	DashboardUID string `json:"dash"`
+	PanelID      int64  `json:"panel,omitempty"`
+	Expires      int64  `json:"exp"`
+}
+
+func claimsOf(token *EmbedToken) tokenClaims {
+	return tokenClaims{
+		UID:          token.UID,
+		OrgID:        token.OrgID,
+		DashboardUID: token.DashboardUID,
+		PanelID:      token.PanelID,
+		Expires:      token.Expires.Unix(),
+	}
+}
+
+func (s *EmbedTokenService) sign(claims tokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.signature(encoded)), nil
+}
+
+func (s *EmbedTokenService) parse(token string) (*tokenClaims, error) {
+	encoded, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrTokenInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.signature(encoded)) {
+		return nil, ErrTokenInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.UID == "" {
+		return nil, ErrTokenInvalid
+	}
+	return &claims, nil
+}
+
+func (s *EmbedTokenService) signature(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -3,6 +3,7 @@ package export
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/semaphore"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -160,6 +162,7 @@ type StandardExport struct {
 	orgService                org.Service
 	datasourceService         datasources.DataSourceService
 	store                     object.ObjectStoreServer
+	semaphore                 semaphore.Service
 
 	// updated with mutex
 	exportJob Job
@@ -167,7 +170,7 @@ type StandardExport struct {
 
 func ProvideService(db db.DB, features featuremgmt.FeatureToggles, gl *live.GrafanaLive, cfg *setting.Cfg,
 	dashboardsnapshotsService dashboardsnapshots.Service, playlistService playlist.Service, orgService org.Service,
-	datasourceService datasources.DataSourceService, store object.ObjectStoreServer) ExportService {
+	datasourceService datasources.DataSourceService, store object.ObjectStoreServer, semaphore semaphore.Service) ExportService {
 	if !features.IsEnabled(featuremgmt.FlagExport) {
 		return &StubExport{}
 	}
@@ -182,6 +185,7 @@ func ProvideService(db db.DB, features featuremgmt.FeatureToggles, gl *live.Graf
 		exportJob:                 &stoppedJob{},
 		dataDir:                   cfg.DataPath,
 		store:                     store,
+		semaphore:                 semaphore,
 		db:                        db,
 	}
 }
@@ -225,10 +229,23 @@ func (ex *StandardExport) HandleRequestExport(c *models.ReqContext) response.Res
 		return response.Error(http.StatusLocked, "export already running", nil)
 	}
 
-	ctx := store.ContextWithUser(context.Background(), c.SignedInUser)
+	// Only a limited number of instances export at the same time, the job holds its slot until it
+	// finishes.
+	lease, err := ex.semaphore.TryAcquire(store.ContextWithUser(context.Background(), c.SignedInUser), semaphore.OperationExport)
+	if err != nil {
+		if errors.Is(err, semaphore.ErrLimitReached) {
+			return response.Error(http.StatusLocked, "export already running on other instances", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to start export job", err)
+	}
+
+	ctx := lease.Context()
 	var job Job
 	broadcast := func(s ExportStatus) {
 		ex.broadcastStatus(c.OrgID, s)
+		if !s.Running {
+			lease.Release()
+		}
 	}
 	switch cfg.Format {
 	case "dummy":
@@ -238,14 +255,17 @@ func (ex *StandardExport) HandleRequestExport(c *models.ReqContext) response.Res
 	case "git":
 		dir := filepath.Join(ex.dataDir, "export_git", fmt.Sprintf("git_%d", time.Now().Unix()))
 		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			lease.Release()
 			return response.Error(http.StatusBadRequest, "Error creating export folder", nil)
 		}
 		job, err = startGitExportJob(ctx, cfg, ex.db, ex.dashboardsnapshotsService, dir, c.OrgID, broadcast, ex.playlistService, ex.orgService, ex.datasourceService)
 	default:
+		lease.Release()
 		return response.Error(http.StatusBadRequest, "Unsupported job format", nil)
 	}
 
 	if err != nil {
+		lease.Release()
 		ex.logger.Error("failed to start export job", "err", err)
 		return response.Error(http.StatusBadRequest, "failed to start export job", err)
 	}
@@ -362,5 +362,10 @@ var (
 			Description: "Use Elasticsearch as backend data source",
 			State:       FeatureStateAlpha,
 		},
+		{
+			Name:        "orgLogStreaming",
+			Description: "Lets organization admins stream the log lines of their organization",
+			State:       FeatureStateAlpha,
+		},
 	}
 )
@@ -266,4 +266,8 @@ const (
 	// FlagElasticsearchBackendMigration
 	// Use Elasticsearch as backend data source
 	FlagElasticsearchBackendMigration = "elasticsearchBackendMigration"
+
+	// FlagOrgLogStreaming
+	// Lets organization admins stream the log lines of their organization
+	FlagOrgLogStreaming = "orgLogStreaming"
 )
@@ -51,6 +51,7 @@ func TestIntegrationFolderService(t *testing.T) {
 	}
 	t.Run("Folder service tests", func(t *testing.T) {
 		dashStore := &dashboards.FakeDashboardStore{}
+		dashStore.On("ValidateUIDOwnership", mock.Anything, mock.AnythingOfType("*models.Dashboard"), mock.AnythingOfType("string")).Return(nil).Maybe()
 		db := sqlstore.InitTestDB(t)
 		store := ProvideStore(db, db.Cfg, featuremgmt.WithFeatures([]interface{}{"nestedFolders"}))
 
@@ -0,0 +1,192 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/api/pagination"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const defaultAnnotationsLimit = 100
+
+// annotationsSort is the order of listed annotations, the cursors of
+// the HTTP API can be used with the gRPC API and vice versa.
+var annotationsSort = pagination.Sort{Field: "time", Descending: true}
+
+func (s *Service) ListAnnotations(ctx context.Context, req *ListAnnotationsRequest) (*ListAnnotationsResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(ac.ActionAnnotationsRead))
+	if err != nil {
+		return nil, err
+	}
+
+	paging := pagination.Params{Limit: int(req.Limit), Sort: annotationsSort}
+	if paging.Limit <= 0 {
+		paging.Limit = defaultAnnotationsLimit
+	}
+	if req.Cursor != "" {
+		after, err := pagination.DecodeCursor(req.Cursor)
+		if err != nil || after.Sort != annotationsSort.String() {
+			return nil, status.Error(codes.InvalidArgument, pagination.ErrInvalidCursor.Error())
+		}
+		paging.After = after
+	}
+
+	query := &annotations.ItemQuery{
+		OrgId:        signedInUser.OrgID,
+		From:         req.From,
+		To:           req.To,
+		PanelId:      req.PanelId,
+		Tags:         req.Tags,
+		MatchAny:     req.MatchAny,
+		Type:         req.Type,
+		Limit:        int64(paging.Limit),
+		Sort:         paging.Sort,
+		After:        paging.After,
+		SignedInUser: signedInUser,
+	}
+	if req.DashboardUid != "" {
+		dash, err := s.getDashboard(ctx, signedInUser, req.DashboardUid)
+		if err != nil {
+			return nil, err
+		}
+		query.DashboardId = dash.Id
+	}
+
+	items, err := s.annotationsRepo.Find(ctx, query)
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	res := &ListAnnotationsResponse{Annotations: make([]*Annotation, 0, len(items))}
+	for _, item := range items {
+		var dashboardUID string
+		if item.DashboardUID != nil {
+			dashboardUID = *item.DashboardUID
+		}
+		res.Annotations = append(res.Annotations, &Annotation{
+			Id:           item.Id,
+			DashboardUid: dashboardUID,
+			PanelId:      item.PanelId,
+			Time:         item.Time,
+			TimeEnd:      item.TimeEnd,
+			Text:         item.Text,
+			Tags:         item.Tags,
+			UserId:       item.UserId,
+			AlertId:      item.AlertId,
+			CreatedAt:    item.Created,
+			UpdatedAt:    item.Updated,
+		})
+	}
+	if n := len(items); n > 0 {
+		res.NextCursor = paging.NextCursor(n, items[n-1].Time, items[n-1].Id)
+	}
+	return res, nil
+}
+
+func (s *Service) CreateAnnotation(ctx context.Context, req *CreateAnnotationRequest) (*Annotation, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(ac.ActionAnnotationsCreate))
+	if err != nil {
+		return nil, err
+	}
+	if req.Text == "" {
+		return nil, status.Error(codes.InvalidArgument, "text is required")
+	}
+
+	var dashboardID int64
+	if req.DashboardUid != "" {
+		dash, err := s.getDashboard(ctx, signedInUser, req.DashboardUid)
+		if err != nil {
+			return nil, err
+		}
+		dashboardID = dash.Id
+	}
+	if err := s.canSaveAnnotation(ctx, signedInUser, ac.ActionAnnotationsCreate, dashboardID); err != nil {
+		return nil, err
+	}
+
+	item := annotations.Item{
+		OrgId:       signedInUser.OrgID,
+		UserId:      signedInUser.UserID,
+		DashboardId: dashboardID,
+		PanelId:     req.PanelId,
+		Epoch:       req.Time,
+		EpochEnd:    req.TimeEnd,
+		Text:        req.Text,
+		Tags:        req.Tags,
+	}
+	if err := s.annotationsRepo.Save(ctx, &item); err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	return &Annotation{
+		Id:           item.Id,
+		DashboardUid: req.DashboardUid,
+		PanelId:      item.PanelId,
+		Time:         item.Epoch,
+		TimeEnd:      item.EpochEnd,
+		Text:         item.Text,
+		Tags:         item.Tags,
+		UserId:       item.UserId,
+		CreatedAt:    item.Created,
+		UpdatedAt:    item.Updated,
+	}, nil
+}
+
+func (s *Service) DeleteAnnotation(ctx context.Context, req *DeleteAnnotationRequest) (*DeleteAnnotationResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(ac.ActionAnnotationsDelete))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.annotationsRepo.Find(ctx, &annotations.ItemQuery{
+		AnnotationId: req.Id,
+		OrgId:        signedInUser.OrgID,
+		SignedInUser: signedInUser,
+	})
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	if len(items) == 0 {
+		return nil, status.Error(codes.NotFound, "annotation not found")
+	}
+	if err := s.canSaveAnnotation(ctx, signedInUser, ac.ActionAnnotationsDelete, items[0].DashboardId); err != nil {
+		return nil, err
+	}
+
+	if err := s.annotationsRepo.Delete(ctx, &annotations.DeleteParams{OrgId: signedInUser.OrgID, Id: req.Id}); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return &DeleteAnnotationResponse{}, nil
+}
+
+// canSaveAnnotation checks the permission to change annotations of the dashboard, or organization
+// annotations if dashboardID is zero, like the HTTP API.
+func (s *Service) canSaveAnnotation(ctx context.Context, signedInUser *user.SignedInUser, action string, dashboardID int64) error {
+	scope := ac.ScopeAnnotationsTypeOrganization
+	if dashboardID != 0 {
+		scope = ac.ScopeAnnotationsTypeDashboard
+	}
+	if _, err := s.authorize(ctx, ac.EvalPermission(action, scope)); err != nil {
+		return err
+	}
+
+	if dashboardID == 0 {
+		if s.accessControl.IsDisabled() && !signedInUser.HasRole(org.RoleEditor) {
+			return status.Error(codes.PermissionDenied, "access denied to organization annotations")
+		}
+		return nil
+	}
+
+	g := guardian.New(ctx, dashboardID, signedInUser.OrgID, signedInUser)
+	if canEdit, err := g.CanEdit(); err != nil || !canEdit {
+		return s.guardianError(err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const defaultSearchLimit = 1000
+
+func (s *Service) GetDashboard(ctx context.Context, req *GetDashboardRequest) (*Dashboard, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionDashboardsRead))
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := s.getDashboard(ctx, signedInUser, req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	g := guardian.New(ctx, dash.Id, signedInUser.OrgID, signedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return nil, s.guardianError(err)
+	}
+	return s.toDashboard(ctx, dash)
+}
+
+func (s *Service) SearchDashboards(ctx context.Context, req *SearchDashboardsRequest) (*SearchDashboardsResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionDashboardsRead))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	query := search.Query{
+		Title:        req.Query,
+		Tags:         req.Tags,
+		OrgId:        signedInUser.OrgID,
+		SignedInUser: signedInUser,
+		Limit:        limit,
+		Page:         req.Page,
+		Type:         string(models.DashHitDB),
+		Permission:   models.PERMISSION_VIEW,
+	}
+	if err := s.searchService.SearchHandler(ctx, &query); err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	res := &SearchDashboardsResponse{Dashboards: make([]*Dashboard, 0, len(query.Result))}
+	for _, hit := range query.Result {
+		res.Dashboards = append(res.Dashboards, &Dashboard{
+			Id:        hit.ID,
+			Uid:       hit.UID,
+			Title:     hit.Title,
+			FolderUid: hit.FolderUID,
+			Tags:      hit.Tags,
+			Url:       hit.URL,
+		})
+	}
+	return res, nil
+}
+
+func (s *Service) SaveDashboard(ctx context.Context, req *SaveDashboardRequest) (*Dashboard, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalAny(
+		ac.EvalPermission(dashboards.ActionDashboardsCreate),
+		ac.EvalPermission(dashboards.ActionDashboardsWrite),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := simplejson.NewJson(req.Body)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid dashboard JSON: %s", err)
+	}
+	cmd := models.SaveDashboardCommand{
+		Dashboard: data,
+		OrgId:     signedInUser.OrgID,
+		UserId:    signedInUser.UserID,
+		FolderUid: req.FolderUid,
+		Message:   req.Message,
+		Overwrite: req.Overwrite,
+	}
+	if cmd.FolderUid != "" {
+		f, err := s.folderService.Get(ctx, &folder.GetFolderQuery{
+			OrgID:        signedInUser.OrgID,
+			UID:          &cmd.FolderUid,
+			SignedInUser: signedInUser,
+		})
+		if err != nil {
+			return nil, s.toStatusError(err)
+		}
+		cmd.FolderId = f.ID
+	}
+
+	dash := cmd.GetDashboardModel()
+	if dash.Id == 0 {
+		if err := s.canCreate(ctx, signedInUser, dashboards.QuotaTargetSrv); err != nil {
+			return nil, err
+		}
+	}
+
+	// Provisioned dashboards can only be changed by updating their provisioning files,
+	// regardless of the allowUiUpdates option.
+	dash, err = s.dashboardService.SaveDashboard(alerting.WithUAEnabled(ctx, s.cfg.UnifiedAlerting.IsEnabled()), &dashboards.SaveDashboardDTO{
+		Dashboard: dash,
+		Message:   cmd.Message,
+		OrgId:     signedInUser.OrgID,
+		User:      signedInUser,
+		Overwrite: cmd.Overwrite,
+	}, false)
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return s.toDashboard(ctx, dash)
+}
+
+func (s *Service) DeleteDashboard(ctx context.Context, req *DeleteDashboardRequest) (*DeleteDashboardResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionDashboardsDelete))
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := s.getDashboard(ctx, signedInUser, req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	g := guardian.New(ctx, dash.Id, signedInUser.OrgID, signedInUser)
+	if canDelete, err := g.CanDelete(); err != nil || !canDelete {
+		return nil, s.guardianError(err)
+	}
+
+	if err := s.libraryElementService.DisconnectElementsFromDashboard(ctx, dash.Id); err != nil {
+		s.log.Error("Failed to disconnect library elements", "dashboard", dash.Id, "error", err)
+	}
+	if err := s.dashboardService.DeleteDashboard(ctx, dash.Id, signedInUser.OrgID); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return &DeleteDashboardResponse{}, nil
+}
+
+func (s *Service) getDashboard(ctx context.Context, signedInUser *user.SignedInUser, uid string) (*models.Dashboard, error) {
+	if uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+	query := models.GetDashboardQuery{Uid: uid, OrgId: signedInUser.OrgID}
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return query.Result, nil
+}
+
+func (s *Service) guardianError(err error) error {
+	if err != nil {
+		return s.toStatusError(err)
+	}
+	return status.Error(codes.PermissionDenied, "access denied to dashboard")
+}
+
+func (s *Service) toDashboard(ctx context.Context, dash *models.Dashboard) (*Dashboard, error) {
+	d := &Dashboard{
+		Id:        dash.Id,
+		Uid:       dash.Uid,
+		Title:     dash.Title,
+		Tags:      dash.GetTags(),
+		Version:   int64(dash.Version),
+		Url:       dash.GetUrl(),
+		CreatedAt: dash.Created.UnixMilli(),
+		UpdatedAt: dash.Updated.UnixMilli(),
+	}
+
+	if dash.FolderId > 0 {
+		query := models.GetDashboardQuery{Id: dash.FolderId, OrgId: dash.OrgId}
+		if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+			return nil, s.toStatusError(err)
+		}
+		d.FolderUid = query.Result.Uid
+	}
+
+	body, err := dash.Data.Encode()
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	d.Body = body
+	return d, nil
+}
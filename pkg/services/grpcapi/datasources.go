@@ -0,0 +1,234 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func (s *Service) ListDataSources(ctx context.Context, req *ListDataSourcesRequest) (*ListDataSourcesResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(datasources.ActionRead))
+	if err != nil {
+		return nil, err
+	}
+
+	query := datasources.GetDataSourcesQuery{OrgId: signedInUser.OrgID, DataSourceLimit: s.cfg.DataSourceLimit}
+	if err := s.dataSourceService.GetDataSources(ctx, &query); err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	filter := datasources.DatasourcesPermissionFilterQuery{
+		User:        signedInUser,
+		Datasources: query.Result,
+		Result:      query.Result,
+	}
+	if err := s.dsPermissionsService.FilterDatasourcesBasedOnQueryPermissions(ctx, &filter); err != nil && !errors.Is(err, permissions.ErrNotImplemented) {
+		return nil, s.toStatusError(err)
+	}
+
+	res := &ListDataSourcesResponse{DataSources: make([]*DataSource, 0, len(filter.Result))}
+	for _, ds := range filter.Result {
+		d, err := s.toDataSource(ctx, ds)
+		if err != nil {
+			return nil, err
+		}
+		res.DataSources = append(res.DataSources, d)
+	}
+	return res, nil
+}
+
+func (s *Service) GetDataSource(ctx context.Context, req *GetDataSourceRequest) (*DataSource, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(req.Uid)))
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := s.getDataSource(ctx, signedInUser, req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDataSource(ctx, ds)
+}
+
+func (s *Service) CreateDataSource(ctx context.Context, req *CreateDataSourceRequest) (*DataSource, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(datasources.ActionCreate))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canCreate(ctx, signedInUser, datasources.QuotaTargetSrv); err != nil {
+		return nil, err
+	}
+
+	d := req.DataSource
+	if d == nil {
+		return nil, status.Error(codes.InvalidArgument, "data_source is required")
+	}
+	jsonData, err := parseJSONData(d.JsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := datasources.AddDataSourceCommand{
+		Name:            d.Name,
+		Type:            d.Type,
+		Access:          datasources.DsAccess(d.Access),
+		Url:             d.Url,
+		Database:        d.Database,
+		User:            d.User,
+		BasicAuth:       d.BasicAuth,
+		BasicAuthUser:   d.BasicAuthUser,
+		WithCredentials: d.WithCredentials,
+		IsDefault:       d.IsDefault,
+		JsonData:        jsonData,
+		SecureJsonData:  req.SecureJsonData,
+		Uid:             d.Uid,
+		OrgId:           signedInUser.OrgID,
+		UserId:          signedInUser.UserID,
+	}
+	if err := s.dataSourceService.AddDataSource(ctx, &cmd); err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	// Clear the permission cache of the service account, so that it can access the data source right away
+	if !s.accessControl.IsDisabled() {
+		s.accessControlService.ClearUserPermissionCache(signedInUser)
+	}
+	return s.toDataSource(ctx, cmd.Result)
+}
+
+func (s *Service) UpdateDataSource(ctx context.Context, req *UpdateDataSourceRequest) (*DataSource, error) {
+	d := req.DataSource
+	if d == nil {
+		return nil, status.Error(codes.InvalidArgument, "data_source is required")
+	}
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(datasources.ActionWrite, datasources.ScopeProvider.GetResourceScopeUID(d.Uid)))
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := s.getDataSource(ctx, signedInUser, d.Uid)
+	if err != nil {
+		return nil, err
+	}
+	if ds.ReadOnly {
+		return nil, status.Error(codes.PermissionDenied, "cannot update read-only data source")
+	}
+	jsonData, err := parseJSONData(d.JsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := datasources.UpdateDataSourceCommand{
+		Name:            d.Name,
+		Type:            d.Type,
+		Access:          datasources.DsAccess(d.Access),
+		Url:             d.Url,
+		User:            d.User,
+		Database:        d.Database,
+		BasicAuth:       d.BasicAuth,
+		BasicAuthUser:   d.BasicAuthUser,
+		WithCredentials: d.WithCredentials,
+		IsDefault:       d.IsDefault,
+		JsonData:        jsonData,
+		SecureJsonData:  req.SecureJsonData,
+		Version:         int(d.Version),
+		Uid:             d.Uid,
+		OrgId:           signedInUser.OrgID,
+		Id:              ds.Id,
+	}
+	if err := s.dataSourceService.UpdateDataSource(ctx, &cmd); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return s.toDataSource(ctx, cmd.Result)
+}
+
+func (s *Service) DeleteDataSource(ctx context.Context, req *DeleteDataSourceRequest) (*DeleteDataSourceResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(datasources.ActionDelete, datasources.ScopeProvider.GetResourceScopeUID(req.Uid)))
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := s.getDataSource(ctx, signedInUser, req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	if ds.ReadOnly {
+		return nil, status.Error(codes.PermissionDenied, "cannot delete read-only data source")
+	}
+
+	cmd := &datasources.DeleteDataSourceCommand{UID: ds.Uid, OrgID: signedInUser.OrgID, Name: ds.Name}
+	if err := s.dataSourceService.DeleteDataSource(ctx, cmd); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return &DeleteDataSourceResponse{}, nil
+}
+
+func (s *Service) getDataSource(ctx context.Context, signedInUser *user.SignedInUser, uid string) (*datasources.DataSource, error) {
+	if uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+	query := datasources.GetDataSourceQuery{Uid: uid, OrgId: signedInUser.OrgID}
+	if err := s.dataSourceService.GetDataSource(ctx, &query); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return query.Result, nil
+}
+
+func parseJSONData(b []byte) (*simplejson.Json, error) {
+	if len(b) == 0 {
+		return simplejson.New(), nil
+	}
+	jsonData, err := simplejson.NewJson(b)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid json_data: %s", err)
+	}
+	return jsonData, nil
+}
+
+// toDataSource returns the data source without its secure settings, only listing which are set.
+func (s *Service) toDataSource(ctx context.Context, ds *datasources.DataSource) (*DataSource, error) {
+	d := &DataSource{
+		Id:               ds.Id,
+		Uid:              ds.Uid,
+		Name:             ds.Name,
+		Type:             ds.Type,
+		Access:           string(ds.Access),
+		Url:              ds.Url,
+		User:             ds.User,
+		Database:         ds.Database,
+		BasicAuth:        ds.BasicAuth,
+		BasicAuthUser:    ds.BasicAuthUser,
+		WithCredentials:  ds.WithCredentials,
+		IsDefault:        ds.IsDefault,
+		SecureJsonFields: map[string]bool{},
+		Version:          int64(ds.Version),
+		ReadOnly:         ds.ReadOnly,
+	}
+
+	if ds.JsonData != nil {
+		jsonData, err := ds.JsonData.Encode()
+		if err != nil {
+			return nil, s.toStatusError(err)
+		}
+		d.JsonData = jsonData
+	}
+
+	secrets, err := s.dataSourceService.DecryptedValues(ctx, ds)
+	if err != nil {
+		s.log.Debug("Failed to retrieve data source secrets to list secure json fields", "uid", ds.Uid, "error", err)
+	}
+	for k, v := range secrets {
+		if len(v) > 0 {
+			d.SecureJsonFields[k] = true
+		}
+	}
+	return d, nil
+}
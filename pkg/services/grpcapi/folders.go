@@ -0,0 +1,143 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+func (s *Service) ListFolders(ctx context.Context, req *ListFoldersRequest) (*ListFoldersResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionFoldersRead))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	folders, err := s.folderService.GetFolders(ctx, signedInUser, signedInUser.OrgID, limit, req.Page)
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	res := &ListFoldersResponse{Folders: make([]*Folder, 0, len(folders))}
+	for _, f := range folders {
+		res.Folders = append(res.Folders, &Folder{
+			Id:        f.Id,
+			Uid:       f.Uid,
+			Title:     f.Title,
+			Version:   int64(f.Version),
+			Url:       f.Url,
+			CreatedAt: f.Created.UnixMilli(),
+			UpdatedAt: f.Updated.UnixMilli(),
+		})
+	}
+	return res, nil
+}
+
+func (s *Service) GetFolder(ctx context.Context, req *GetFolderRequest) (*Folder, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionFoldersRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(req.Uid)))
+	if err != nil {
+		return nil, err
+	}
+	if req.Uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	f, err := s.folderService.Get(ctx, &folder.GetFolderQuery{
+		UID:          &req.Uid,
+		OrgID:        signedInUser.OrgID,
+		SignedInUser: signedInUser,
+	})
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return toFolder(f), nil
+}
+
+func (s *Service) CreateFolder(ctx context.Context, req *CreateFolderRequest) (*Folder, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionFoldersCreate))
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := s.folderService.Create(ctx, &folder.CreateFolderCommand{
+		UID:          req.Uid,
+		OrgID:        signedInUser.OrgID,
+		Title:        req.Title,
+		SignedInUser: signedInUser,
+	})
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	// Clear the permission cache of the service account, so that it can access the folder right away
+	if !s.accessControl.IsDisabled() {
+		s.accessControlService.ClearUserPermissionCache(signedInUser)
+	}
+	return toFolder(f), nil
+}
+
+func (s *Service) UpdateFolder(ctx context.Context, req *UpdateFolderRequest) (*Folder, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionFoldersWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(req.Uid)))
+	if err != nil {
+		return nil, err
+	}
+	if req.Uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	f, err := s.folderService.Update(ctx, signedInUser, signedInUser.OrgID, req.Uid, &models.UpdateFolderCommand{
+		Uid:       req.NewUid,
+		Title:     req.Title,
+		Version:   int(req.Version),
+		Overwrite: req.Overwrite,
+	})
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return toFolder(f), nil
+}
+
+func (s *Service) DeleteFolder(ctx context.Context, req *DeleteFolderRequest) (*DeleteFolderResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(dashboards.ActionFoldersDelete, dashboards.ScopeFoldersProvider.GetResourceScopeUID(req.Uid)))
+	if err != nil {
+		return nil, err
+	}
+	if req.Uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	if err := s.libraryElementService.DeleteLibraryElementsInFolder(ctx, signedInUser, req.Uid); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	err = s.folderService.DeleteFolder(ctx, &folder.DeleteFolderCommand{
+		UID:              req.Uid,
+		OrgID:            signedInUser.OrgID,
+		ForceDeleteRules: req.ForceDeleteRules,
+		SignedInUser:     signedInUser,
+	})
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return &DeleteFolderResponse{}, nil
+}
+
+func toFolder(f *folder.Folder) *Folder {
+	return &Folder{
+		Id:        f.ID,
+		Uid:       f.UID,
+		Title:     f.Title,
+		Version:   int64(f.Version),
+		Url:       f.Url,
+		CreatedAt: f.Created.UnixMilli(),
+		UpdatedAt: f.Updated.UnixMilli(),
+	}
+}
@@ -0,0 +1,198 @@
+// Package grpcapi exposes the core resources, dashboards, folders, data sources,
+// annotations and organizations, on the gRPC server.
+//
+// Requests are authenticated with service account tokens by the gRPC server
+// and authorized with the same permissions as the equivalent HTTP API.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// Service implements the gRPC services of the core resources.
+type Service struct {
+	cfg                   *setting.Cfg
+	log                   log.Logger
+	accessControl         accesscontrol.AccessControl
+	accessControlService  accesscontrol.Service
+	dashboardService      dashboards.DashboardService
+	folderService         folder.Service
+	libraryElementService libraryelements.Service
+	searchService         search.Service
+	dataSourceService     datasources.DataSourceService
+	dsPermissionsService  permissions.DatasourcePermissionsService
+	annotationsRepo       annotations.Repository
+	orgService            org.Service
+	quotaService          quota.Service
+}
+
+func ProvideService(cfg *setting.Cfg, grpcServerProvider grpcserver.Provider, accessControl accesscontrol.AccessControl,
+	accessControlService accesscontrol.Service, dashboardService dashboards.DashboardService, folderService folder.Service,
+	libraryElementService libraryelements.Service, searchService *search.SearchService, dataSourceService datasources.DataSourceService,
+	dsPermissionsService permissions.DatasourcePermissionsService, annotationsRepo annotations.Repository, orgService org.Service, quotaService quota.Service) *Service {
+	s := &Service{
+		cfg:                   cfg,
+		log:                   log.New("grpc-api"),
+		accessControl:         accessControl,
+		accessControlService:  accessControlService,
+		dashboardService:      dashboardService,
+		folderService:         folderService,
+		libraryElementService: libraryElementService,
+		searchService:         searchService,
+		dataSourceService:     dataSourceService,
+		dsPermissionsService:  dsPermissionsService,
+		annotationsRepo:       annotationsRepo,
+		orgService:            orgService,
+		quotaService:          quotaService,
+	}
+
+	server := grpcServerProvider.GetServer()
+	RegisterDashboardServiceServer(server, s)
+	RegisterFolderServiceServer(server, s)
+	RegisterDataSourceServiceServer(server, s)
+	RegisterAnnotationServiceServer(server, s)
+	RegisterOrgServiceServer(server, s)
+	return s
+}
+
+// authorize returns the signed in user of the request if they are granted access by the evaluator.
+// With access control disabled the request is authorized, as the gRPC server only authenticates
+// service accounts with the Admin role.
+func (s *Service) authorize(ctx context.Context, evaluator accesscontrol.Evaluator) (*user.SignedInUser, error) {
+	signedInUser, err := appcontext.User(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "no signed in user")
+	}
+	if s.accessControl.IsDisabled() {
+		return signedInUser, nil
+	}
+
+	ok, err := s.accessControl.Evaluate(ctx, signedInUser, evaluator)
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "missing permissions: %s", evaluator.String())
+	}
+	return signedInUser, nil
+}
+
+// canCreate returns an error if the quota of the target in the organization of the user is reached.
+func (s *Service) canCreate(ctx context.Context, signedInUser *user.SignedInUser, target quota.TargetSrv) error {
+	reached, err := s.quotaService.CheckQuotaReached(ctx, target, &quota.ScopeParameters{
+		OrgID:  signedInUser.OrgID,
+		UserID: signedInUser.UserID,
+	})
+	if err != nil {
+		return s.toStatusError(err)
+	}
+	if reached {
+		return status.Error(codes.ResourceExhausted, "quota reached")
+	}
+	return nil
+}
+
+// statusErrors are the HTTP status codes of errors which are neither an
+// errutil.Error nor a dashboards.DashboardErr.
+var statusErrors = []struct {
+	err    error
+	status int
+}{
+	{dashboards.ErrFolderNotFound, http.StatusNotFound},
+	{dashboards.ErrFolderVersionMismatch, http.StatusPreconditionFailed},
+	{dashboards.ErrFolderTitleEmpty, http.StatusBadRequest},
+	{dashboards.ErrFolderWithSameUIDExists, http.StatusConflict},
+	{dashboards.ErrFolderInvalidUID, http.StatusBadRequest},
+	{dashboards.ErrFolderSameNameExists, http.StatusConflict},
+	{dashboards.ErrFolderAccessDenied, http.StatusForbidden},
+	{dashboards.ErrFolderContainsAlertRules, http.StatusBadRequest},
+	{datasources.ErrDataSourceNotFound, http.StatusNotFound},
+	{datasources.ErrDataSourceNameExists, http.StatusConflict},
+	{datasources.ErrDataSourceUidExists, http.StatusConflict},
+	{datasources.ErrDataSourceUpdatingOldVersion, http.StatusPreconditionFailed},
+	{annotations.ErrTimerangeMissing, http.StatusBadRequest},
+	{models.ErrOrgNotFound, http.StatusNotFound},
+	{models.ErrOrgNameTaken, http.StatusConflict},
+	{org.ErrOrgNotFound, http.StatusNotFound},
+	{org.ErrOrgNameTaken, http.StatusConflict},
+}
+
+// toStatusError converts an error of the underlying services to a gRPC status error.
+func (s *Service) toStatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	httpStatus := http.StatusInternalServerError
+	var grafanaErr errutil.Error
+	var dashboardErr dashboards.DashboardErr
+	switch {
+	case errors.As(err, &grafanaErr):
+		httpStatus = grafanaErr.Reason.Status().HTTPStatus()
+	case errors.As(err, &dashboardErr):
+		httpStatus = dashboardErr.StatusCode
+	default:
+		for _, e := range statusErrors {
+			if errors.Is(err, e.err) {
+				httpStatus = e.status
+				break
+			}
+		}
+	}
+
+	code := codeFromHTTPStatus(httpStatus)
+	if code == codes.Internal {
+		s.log.Error("Request failed", "error", err)
+		return status.Error(code, "internal error")
+	}
+	return status.Error(code, err.Error())
+}
+
+func codeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
@@ -0,0 +1,181 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+func TestService_GetOrg(t *testing.T) {
+	s, orgService := setupTestService(t)
+	orgService.ExpectedOrg = &org.Org{ID: 1, Name: "main"}
+
+	t.Run("returns the organization of the user", func(t *testing.T) {
+		ctx := withUser(map[string][]string{accesscontrol.ActionOrgsRead: {}})
+		res, err := s.GetOrg(ctx, &GetOrgRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.Id)
+		assert.Equal(t, "main", res.Name)
+	})
+
+	t.Run("denies users without permission", func(t *testing.T) {
+		ctx := withUser(map[string][]string{})
+		_, err := s.GetOrg(ctx, &GetOrgRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("rejects requests without user", func(t *testing.T) {
+		_, err := s.GetOrg(context.Background(), &GetOrgRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestService_UpdateOrg(t *testing.T) {
+	s, orgService := setupTestService(t)
+	ctx := withUser(map[string][]string{accesscontrol.ActionOrgsWrite: {}})
+
+	_, err := s.UpdateOrg(ctx, &UpdateOrgRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	orgService.ExpectedError = models.ErrOrgNameTaken
+	_, err = s.UpdateOrg(ctx, &UpdateOrgRequest{Name: "taken"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestService_ListOrgUsers(t *testing.T) {
+	s, orgService := setupTestService(t)
+	s.cfg.HiddenUsers = map[string]struct{}{"hidden": {}}
+	orgService.ExpectedOrgUsers = []*org.OrgUserDTO{
+		{UserID: 1, Login: "admin", Role: "Admin"},
+		{UserID: 2, Login: "hidden", Role: "Viewer"},
+	}
+
+	res, err := s.ListOrgUsers(withUser(map[string][]string{accesscontrol.ActionOrgUsersRead: {}}), &ListOrgUsersRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Users, 1)
+	assert.Equal(t, "admin", res.Users[0].Login)
+	assert.Equal(t, "Admin", res.Users[0].Role)
+}
+
+func TestService_ListAnnotations(t *testing.T) {
+	s, _ := setupTestService(t)
+	ctx := withUser(map[string][]string{accesscontrol.ActionAnnotationsRead: {accesscontrol.ScopeAnnotationsAll}})
+
+	_, err := s.ListAnnotations(ctx, &ListAnnotationsRequest{Cursor: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_toStatusError(t *testing.T) {
+	s, _ := setupTestService(t)
+
+	for _, tc := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{dashboards.ErrDashboardNotFound, codes.NotFound},
+		{dashboards.ErrDashboardVersionMismatch, codes.FailedPrecondition},
+		{fmt.Errorf("save: %w", dashboards.ErrFolderSameNameExists), codes.AlreadyExists},
+		{datasources.ErrDataSourceNotFound, codes.NotFound},
+		{errutil.NewBase(errutil.StatusBadRequest, "test.invalid").Errorf("invalid"), codes.InvalidArgument},
+		{status.Error(codes.Unavailable, "unavailable"), codes.Unavailable},
+		{errors.New("unknown"), codes.Internal},
+	} {
+		assert.Equal(t, tc.code, status.Code(s.toStatusError(tc.err)), tc.err.Error())
+	}
+
+	assert.Equal(t, "internal error", status.Convert(s.toStatusError(errors.New("secret"))).Message())
+}
+
+func TestService_Server(t *testing.T) {
+	orgService := orgtest.NewOrgServiceFake()
+	orgService.ExpectedOrg = &org.Org{ID: 1, Name: "main"}
+	signedInUser := &user.SignedInUser{
+		OrgID:       1,
+		Permissions: map[int64]map[string][]string{1: {accesscontrol.ActionOrgsRead: {}}},
+	}
+
+	provider := &fakeGRPCServerProvider{
+		server: grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(appcontext.WithUser(ctx, signedInUser), req)
+		})),
+	}
+	cfg := setting.NewCfg()
+	cfg.RBACEnabled = true
+	ProvideService(cfg, provider, acimpl.ProvideAccessControl(cfg), nil, nil, nil, nil, nil, nil, nil, nil, orgService, nil)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = provider.server.Serve(listener)
+	}()
+	t.Cleanup(provider.server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	res, err := NewOrgServiceClient(conn).GetOrg(context.Background(), &GetOrgRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "main", res.Name)
+
+	_, err = NewOrgServiceClient(conn).UpdateOrg(context.Background(), &UpdateOrgRequest{Name: "other"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func setupTestService(t *testing.T) (*Service, *orgtest.FakeOrgService) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.RBACEnabled = true
+	orgService := orgtest.NewOrgServiceFake()
+	return &Service{
+		cfg:           cfg,
+		log:           log.NewNopLogger(),
+		accessControl: acimpl.ProvideAccessControl(cfg),
+		orgService:    orgService,
+	}, orgService
+}
+
+func withUser(permissions map[string][]string) context.Context {
+	return appcontext.WithUser(context.Background(), &user.SignedInUser{
+		UserID:      1,
+		OrgID:       1,
+		Login:       "sa-automation",
+		Permissions: map[int64]map[string][]string{1: permissions},
+	})
+}
+
+type fakeGRPCServerProvider struct {
+	server *grpc.Server
+}
+
+func (p *fakeGRPCServerProvider) Run(context.Context) error { return nil }
+func (p *fakeGRPCServerProvider) GetServer() *grpc.Server   { return p.server }
+func (p *fakeGRPCServerProvider) GetAddress() string        { return "bufnet" }
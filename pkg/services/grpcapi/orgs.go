@@ -0,0 +1,75 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func (s *Service) GetOrg(ctx context.Context, req *GetOrgRequest) (*Org, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(ac.ActionOrgsRead))
+	if err != nil {
+		return nil, err
+	}
+	return s.getOrg(ctx, signedInUser.OrgID)
+}
+
+func (s *Service) UpdateOrg(ctx context.Context, req *UpdateOrgRequest) (*Org, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(ac.ActionOrgsWrite))
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	if err := s.orgService.UpdateOrg(ctx, &org.UpdateOrgCommand{Name: req.Name, OrgId: signedInUser.OrgID}); err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return s.getOrg(ctx, signedInUser.OrgID)
+}
+
+func (s *Service) ListOrgUsers(ctx context.Context, req *ListOrgUsersRequest) (*ListOrgUsersResponse, error) {
+	signedInUser, err := s.authorize(ctx, ac.EvalPermission(ac.ActionOrgUsersRead))
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID: signedInUser.OrgID,
+		Query: req.Query,
+		Limit: int(req.Limit),
+		User:  signedInUser,
+	})
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+
+	res := &ListOrgUsersResponse{Users: make([]*OrgUser, 0, len(users))}
+	for _, u := range users {
+		if dtos.IsHiddenUser(u.Login, signedInUser, s.cfg) {
+			continue
+		}
+		res.Users = append(res.Users, &OrgUser{
+			UserId: u.UserID,
+			Login:  u.Login,
+			Email:  u.Email,
+			Name:   u.Name,
+			Role:   u.Role,
+		})
+	}
+	return res, nil
+}
+
+func (s *Service) getOrg(ctx context.Context, orgID int64) (*Org, error) {
+	o, err := s.orgService.GetByID(ctx, &org.GetOrgByIdQuery{ID: orgID})
+	if err != nil {
+		return nil, s.toStatusError(err)
+	}
+	return &Org{Id: o.ID, Name: o.Name}, nil
+}
@@ -3,17 +3,23 @@ package interceptors
 import (
 	"context"
 	"strings"
+	"time"
 
 	apikeygenprefix "github.com/grafana/grafana/pkg/components/apikeygenprefixed"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -25,20 +31,27 @@ type Authenticator interface {
 type authenticator struct {
 	contextHandler grpccontext.ContextHandler
 	logger         log.Logger
+	trustedProxies []string
+	now            func() time.Time
 
 	APIKeyService        apikey.Service
 	UserService          user.Service
 	AccessControlService accesscontrol.Service
+	NetworkPolicyService networkpolicy.Service
 }
 
-func ProvideAuthenticator(apiKeyService apikey.Service, userService user.Service, accessControlService accesscontrol.Service, contextHandler grpccontext.ContextHandler) Authenticator {
+func ProvideAuthenticator(cfg *setting.Cfg, apiKeyService apikey.Service, userService user.Service, accessControlService accesscontrol.Service,
+	networkPolicyService networkpolicy.Service, contextHandler grpccontext.ContextHandler) Authenticator {
 	return &authenticator{
 		contextHandler: contextHandler,
 		logger:         log.New("grpc-server-authenticator"),
+		trustedProxies: cfg.TrustedProxies,
+		now:            time.Now,
 
 		AccessControlService: accessControlService,
 		APIKeyService:        apiKeyService,
 		UserService:          userService,
+		NetworkPolicyService: networkPolicyService,
 	}
 }
 
@@ -69,6 +82,9 @@ func (a *authenticator) tokenAuth(ctx context.Context) (context.Context, error)
 
 	signedInUser, err := a.getSignedInUser(ctx, token)
 	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return ctx, err
+		}
 		a.logger.Warn("request with invalid token", "error", err, "token", token)
 		return ctx, status.Error(codes.Unauthenticated, "invalid token")
 	}
@@ -98,6 +114,24 @@ func (a *authenticator) getSignedInUser(ctx context.Context, token string) (*use
 		return nil, status.Error(codes.Unauthenticated, "api key does not have a service account")
 	}
 
+	if apikey.Expires != nil && *apikey.Expires <= a.now().Unix() {
+		return nil, status.Error(codes.Unauthenticated, "expired token")
+	}
+
+	if apikey.IsRevoked != nil && *apikey.IsRevoked {
+		return nil, status.Error(codes.Unauthenticated, "revoked token")
+	}
+
+	addr := a.clientAddr(ctx)
+	if !apikey.IsAllowedFrom(addr) {
+		a.recordRejection(ctx, addr, networkpolicy.Rejection{
+			OrgID:    apikey.OrgId,
+			APIKeyID: apikey.Id,
+			Policy:   networkpolicy.PolicyTypeAPIKey,
+		})
+		return nil, status.Error(codes.Unauthenticated, "token is not allowed from this address")
+	}
+
 	querySignedInUser := user.GetSignedInUserQuery{UserID: *apikey.ServiceAccountId, OrgID: apikey.OrgId}
 	signedInUser, err := a.UserService.GetSignedInUserWithCacheCtx(ctx, &querySignedInUser)
 	if err != nil {
@@ -117,6 +151,20 @@ func (a *authenticator) getSignedInUser(ctx context.Context, token string) (*use
 		return nil, status.Error(codes.PermissionDenied, "service account is disabled")
 	}
 
+	allowed, err := a.NetworkPolicyService.IsAllowed(ctx, signedInUser.OrgID, addr)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		a.recordRejection(ctx, addr, networkpolicy.Rejection{
+			OrgID:    signedInUser.OrgID,
+			UserID:   signedInUser.UserID,
+			APIKeyID: apikey.Id,
+			Policy:   networkpolicy.PolicyTypeOrg,
+		})
+		return nil, status.Error(codes.PermissionDenied, "access denied by the network policy of the organization")
+	}
+
 	if signedInUser.Permissions == nil {
 		signedInUser.Permissions = make(map[int64]map[string][]string)
 	}
@@ -132,6 +180,28 @@ func (a *authenticator) getSignedInUser(ctx context.Context, token string) (*use
 	return signedInUser, nil
 }
 
+// clientAddr returns the address of the client, like the HTTP context handler, trusting the
+// forwarding headers only when they are set by one of the trusted proxies.
+func (a *authenticator) clientAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var realIP string
+	if values := md.Get("x-real-ip"); len(values) > 0 {
+		realIP = values[0]
+	}
+	return network.ForwardedClientIP(p.Addr.String(), md.Get("x-forwarded-for"), realIP, a.trustedProxies)
+}
+
+func (a *authenticator) recordRejection(ctx context.Context, addr string, rejection networkpolicy.Rejection) {
+	rejection.IPAddress = addr
+	rejection.Method = "GRPC"
+	rejection.Path, _ = grpc.Method(ctx)
+	a.NetworkPolicyService.RecordRejection(ctx, rejection)
+}
+
 func extractAuthorization(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
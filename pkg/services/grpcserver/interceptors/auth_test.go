@@ -2,7 +2,9 @@ package interceptors
 
 import (
 	"context"
+	"net"
 	"testing"
+	"time"
 
 	apikeygenprefix "github.com/grafana/grafana/pkg/components/apikeygenprefixed"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -10,10 +12,16 @@ import (
 	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
+	"github.com/grafana/grafana/pkg/services/networkpolicy/networkpolicytest"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func TestAuthenticator_Authenticate(t *testing.T) {
@@ -28,7 +36,7 @@ func TestAuthenticator_Authenticate(t *testing.T) {
 			ServiceAccountId: &serviceAccountId,
 		}, nil)
 		ac := accesscontrolmock.New()
-		a := ProvideAuthenticator(s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, grpccontext.ProvideContextHandler(tracer))
+		a := ProvideAuthenticator(setting.NewCfg(), s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, &networkpolicytest.FakeService{}, grpccontext.ProvideContextHandler(tracer))
 		ctx, err := setupContext()
 		require.NoError(t, err)
 		_, err = a.Authenticate(ctx)
@@ -44,7 +52,7 @@ func TestAuthenticator_Authenticate(t *testing.T) {
 			ServiceAccountId: &serviceAccountId,
 		}, nil)
 		ac := accesscontrolmock.New()
-		a := ProvideAuthenticator(s, &fakeUserService{OrgRole: org.RoleEditor}, ac, grpccontext.ProvideContextHandler(tracer))
+		a := ProvideAuthenticator(setting.NewCfg(), s, &fakeUserService{OrgRole: org.RoleEditor}, ac, &networkpolicytest.FakeService{}, grpccontext.ProvideContextHandler(tracer))
 		ctx, err := setupContext()
 		require.NoError(t, err)
 		_, err = a.Authenticate(ctx)
@@ -60,7 +68,7 @@ func TestAuthenticator_Authenticate(t *testing.T) {
 			ServiceAccountId: &serviceAccountId,
 		}, nil)
 		ac := accesscontrolmock.New()
-		a := ProvideAuthenticator(s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, grpccontext.ProvideContextHandler(tracer))
+		a := ProvideAuthenticator(setting.NewCfg(), s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, &networkpolicytest.FakeService{}, grpccontext.ProvideContextHandler(tracer))
 		ctx, err := setupContext()
 		require.NoError(t, err)
 		md, ok := metadata.FromIncomingContext(ctx)
@@ -82,7 +90,7 @@ func TestAuthenticator_Authenticate(t *testing.T) {
 			ServiceAccountId: &serviceAccountId,
 		}, nil)
 		ac := accesscontrolmock.New()
-		a := ProvideAuthenticator(s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, grpccontext.ProvideContextHandler(tracer))
+		a := ProvideAuthenticator(setting.NewCfg(), s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, &networkpolicytest.FakeService{}, grpccontext.ProvideContextHandler(tracer))
 		ctx, err := setupContext()
 		require.NoError(t, err)
 		ctx, err = a.Authenticate(ctx)
@@ -106,7 +114,7 @@ func TestAuthenticator_Authenticate(t *testing.T) {
 			},
 		}
 		ac := accesscontrolmock.New().WithPermissions(permissions)
-		a := ProvideAuthenticator(s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, grpccontext.ProvideContextHandler(tracer))
+		a := ProvideAuthenticator(setting.NewCfg(), s, &fakeUserService{OrgRole: org.RoleAdmin}, ac, &networkpolicytest.FakeService{}, grpccontext.ProvideContextHandler(tracer))
 		ctx, err := setupContext()
 		require.NoError(t, err)
 		ctx, err = a.Authenticate(ctx)
@@ -115,6 +123,57 @@ func TestAuthenticator_Authenticate(t *testing.T) {
 		require.Equal(t, serviceAccountId, signedInUser.UserID)
 		require.Equal(t, []string{accesscontrol.ScopeAPIKeysAll}, signedInUser.Permissions[1][accesscontrol.ActionAPIKeyRead])
 	})
+
+	t.Run("rejects expired and revoked tokens", func(t *testing.T) {
+		expired := time.Now().Add(-time.Hour).Unix()
+		revoked := true
+		for _, key := range []*apikey.APIKey{
+			{Id: 1, OrgId: 1, ServiceAccountId: &serviceAccountId, Expires: &expired},
+			{Id: 1, OrgId: 1, ServiceAccountId: &serviceAccountId, IsRevoked: &revoked},
+		} {
+			a := ProvideAuthenticator(setting.NewCfg(), newFakeAPIKey(key, nil), &fakeUserService{OrgRole: org.RoleAdmin}, accesscontrolmock.New(),
+				&networkpolicytest.FakeService{}, grpccontext.ProvideContextHandler(tracer))
+			ctx, err := setupContext()
+			require.NoError(t, err)
+			_, err = a.Authenticate(ctx)
+			require.Equal(t, codes.Unauthenticated, status.Code(err))
+		}
+	})
+
+	t.Run("rejects tokens not allowed from the address", func(t *testing.T) {
+		allowed := "10.0.0.0/8"
+		s := newFakeAPIKey(&apikey.APIKey{Id: 1, OrgId: 1, ServiceAccountId: &serviceAccountId, AllowedCIDRs: &allowed}, nil)
+		policies := &networkpolicytest.FakeService{}
+		a := ProvideAuthenticator(setting.NewCfg(), s, &fakeUserService{OrgRole: org.RoleAdmin}, accesscontrolmock.New(), policies,
+			grpccontext.ProvideContextHandler(tracer))
+		ctx, err := setupContext()
+		require.NoError(t, err)
+
+		_, err = a.Authenticate(withPeer(ctx, "10.1.2.3"))
+		require.NoError(t, err)
+		_, err = a.Authenticate(withPeer(ctx, "192.168.1.1"))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Len(t, policies.Rejections, 1)
+		require.Equal(t, networkpolicy.PolicyTypeAPIKey, policies.Rejections[0].Policy)
+		require.Equal(t, "192.168.1.1", policies.Rejections[0].IPAddress)
+	})
+
+	t.Run("rejects requests denied by the network policy of the organization", func(t *testing.T) {
+		s := newFakeAPIKey(&apikey.APIKey{Id: 1, OrgId: 1, ServiceAccountId: &serviceAccountId}, nil)
+		policies := &networkpolicytest.FakeService{ExpectedDenied: true}
+		a := ProvideAuthenticator(setting.NewCfg(), s, &fakeUserService{OrgRole: org.RoleAdmin}, accesscontrolmock.New(), policies,
+			grpccontext.ProvideContextHandler(tracer))
+		ctx, err := setupContext()
+		require.NoError(t, err)
+		_, err = a.Authenticate(withPeer(ctx, "192.168.1.1"))
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		require.Len(t, policies.Rejections, 1)
+		require.Equal(t, networkpolicy.PolicyTypeOrg, policies.Rejections[0].Policy)
+	})
+}
+
+func withPeer(ctx context.Context, ip string) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}})
 }
 
 type fakeAPIKey struct {
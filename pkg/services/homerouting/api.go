@@ -0,0 +1,82 @@
+package homerouting
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *HomeRoutingService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/org/home-dashboard-rules", func(rules routing.RouteRegister) {
+		rules.Get("/", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(ac.ActionOrgsPreferencesRead)), routing.Wrap(s.getRulesHandler))
+		rules.Put("/", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(s.updateRulesHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /org/home-dashboard-rules org getHomeDashboardRules
+//
+// Get the home dashboard rules of the current organization.
+//
+// Responses:
+// 200: getHomeDashboardRulesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *HomeRoutingService) getRulesHandler(c *models.ReqContext) response.Response {
+	rules, err := s.GetRules(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get home dashboard rules", err)
+	}
+	return response.JSON(http.StatusOK, rules)
+}
+
+// swagger:route PUT /org/home-dashboard-rules org updateHomeDashboardRules
+//
+// Update the home dashboard rules of the current organization.
+//
+// Replaces the rules routing teams and roles to home dashboards. The rules are evaluated in order when a user loads the home dashboard and the first matching rule wins. A home dashboard chosen by the user in their preferences takes precedence over the rules, and the rules take precedence over the team and organization preferences.
+//
+// Responses:
+// 200: getHomeDashboardRulesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *HomeRoutingService) updateRulesHandler(c *models.ReqContext) response.Response {
+	rules := Rules{}
+	if err := web.Bind(c.Req, &rules); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetRules(c.Req.Context(), c.OrgID, rules); err != nil {
+		if errors.Is(err, ErrInvalidRules) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update home dashboard rules", err)
+	}
+	if rules.Rules == nil {
+		rules.Rules = []Rule{}
+	}
+	return response.JSON(http.StatusOK, rules)
+}
+
+// swagger:parameters updateHomeDashboardRules
+type UpdateHomeDashboardRulesParams struct {
+	// in:body
+	// required:true
+	Body Rules `json:"body"`
+}
+
+// swagger:response getHomeDashboardRulesResponse
+type GetHomeDashboardRulesResponse struct {
+	// in:body
+	Body *Rules `json:"body"`
+}
@@ -0,0 +1,110 @@
+// Package homerouting routes users to different home dashboards based on their teams and role.
+//
+// Every organization has an ordered list of rules mapping a team, a role or both to a dashboard.
+// The first rule matching the user is evaluated when the home dashboard is loaded, e.g. after
+// login, so that the rules apply without configuring the preferences of every user.
+package homerouting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	kvNamespace = "homerouting"
+	rulesKey    = "rules"
+)
+
+type Service interface {
+	GetRules(ctx context.Context, orgID int64) (*Rules, error)
+	// SetRules replaces the rules of the organization. Every rule must route to an existing
+	// dashboard of the organization.
+	SetRules(ctx context.Context, orgID int64, rules Rules) error
+	// Resolve returns the first rule of the organization matching the user, or nil.
+	Resolve(ctx context.Context, usr *user.SignedInUser) (*Rule, error)
+}
+
+type HomeRoutingService struct {
+	kvStore          kvstore.KVStore
+	dashboardService dashboards.DashboardService
+	accessControl    accesscontrol.AccessControl
+	routeRegister    routing.RouteRegister
+}
+
+func ProvideService(kvStore kvstore.KVStore, dashboardService dashboards.DashboardService,
+	accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *HomeRoutingService {
+	s := &HomeRoutingService{
+		kvStore:          kvStore,
+		dashboardService: dashboardService,
+		accessControl:    accessControl,
+		routeRegister:    routeRegister,
+	}
+	s.registerAPIEndpoints()
+	return s
+}
+
+// GetRules returns the rules of the organization, which are empty if it did not set any.
+func (s *HomeRoutingService) GetRules(ctx context.Context, orgID int64) (*Rules, error) {
+	value, exists, err := kvstore.WithNamespace(s.kvStore, orgID, kvNamespace).Get(ctx, rulesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &Rules{Rules: []Rule{}}
+	if !exists {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(value), rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *HomeRoutingService) SetRules(ctx context.Context, orgID int64, rules Rules) error {
+	if rules.Rules == nil {
+		rules.Rules = []Rule{}
+	}
+	if err := rules.validate(); err != nil {
+		return err
+	}
+	for i, rule := range rules.Rules {
+		query := &models.GetDashboardQuery{Uid: rule.DashboardUID, OrgId: orgID}
+		if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				return fmt.Errorf("%w: rule %d routes to the dashboard %q which does not exist", ErrInvalidRules, i+1, rule.DashboardUID)
+			}
+			return err
+		}
+		if query.Result.IsFolder {
+			return fmt.Errorf("%w: rule %d routes to the folder %q", ErrInvalidRules, i+1, rule.DashboardUID)
+		}
+	}
+
+	value, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	return kvstore.WithNamespace(s.kvStore, orgID, kvNamespace).Set(ctx, rulesKey, string(value))
+}
+
+func (s *HomeRoutingService) Resolve(ctx context.Context, usr *user.SignedInUser) (*Rule, error) {
+	rules, err := s.GetRules(ctx, usr.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules.Rules {
+		if rules.Rules[i].matches(usr.OrgRole, usr.Teams) {
+			return &rules.Rules[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,100 @@
+package homerouting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/models"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+
+	require.NoError(t, s.SetRules(ctx, 1, Rules{Rules: []Rule{
+		{TeamID: 2, DashboardUID: "infra"},
+		{TeamID: 3, Role: org.RoleAdmin, DashboardUID: "kpis"},
+		{Role: org.RoleViewer, DashboardUID: "kpis"},
+	}}))
+
+	tests := []struct {
+		name     string
+		usr      *user.SignedInUser
+		expected string
+	}{
+		{name: "team", usr: &user.SignedInUser{OrgID: 1, OrgRole: org.RoleEditor, Teams: []int64{1, 2}}, expected: "infra"},
+		{name: "first matching rule", usr: &user.SignedInUser{OrgID: 1, OrgRole: org.RoleViewer, Teams: []int64{2}}, expected: "infra"},
+		{name: "team and role", usr: &user.SignedInUser{OrgID: 1, OrgRole: org.RoleAdmin, Teams: []int64{3}}, expected: "kpis"},
+		{name: "team without the role", usr: &user.SignedInUser{OrgID: 1, OrgRole: org.RoleEditor, Teams: []int64{3}}},
+		{name: "role", usr: &user.SignedInUser{OrgID: 1, OrgRole: org.RoleViewer}, expected: "kpis"},
+		{name: "other organization", usr: &user.SignedInUser{OrgID: 2, OrgRole: org.RoleViewer, Teams: []int64{2}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := s.Resolve(ctx, tc.usr)
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			assert.Equal(t, tc.expected, rule.DashboardUID)
+		})
+	}
+}
+
+func TestSetRules(t *testing.T) {
+	ctx := context.Background()
+	s := setupService(t)
+
+	rules, err := s.GetRules(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, rules.Rules)
+
+	for name, rule := range map[string]Rule{
+		"no team or role":   {DashboardUID: "infra"},
+		"invalid role":      {Role: "Owner", DashboardUID: "infra"},
+		"missing dashboard": {TeamID: 1, DashboardUID: "missing"},
+		"folder":            {TeamID: 1, DashboardUID: "folder"},
+	} {
+		t.Run("Should reject a rule with "+name, func(t *testing.T) {
+			err := s.SetRules(ctx, 1, Rules{Rules: []Rule{rule}})
+			assert.ErrorIs(t, err, ErrInvalidRules)
+		})
+	}
+
+	require.NoError(t, s.SetRules(ctx, 1, Rules{Rules: []Rule{{TeamID: 1, DashboardUID: "infra"}}}))
+	rules, err = s.GetRules(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{{TeamID: 1, DashboardUID: "infra"}}, rules.Rules)
+}
+
+func setupService(t *testing.T) *HomeRoutingService {
+	t.Helper()
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(func(ctx context.Context, query *models.GetDashboardQuery) error {
+		switch query.Uid {
+		case "infra", "kpis":
+			query.Result = &models.Dashboard{Uid: query.Uid, OrgId: query.OrgId}
+		case "folder":
+			query.Result = &models.Dashboard{Uid: query.Uid, OrgId: query.OrgId, IsFolder: true}
+		default:
+			return dashboards.ErrDashboardNotFound
+		}
+		return nil
+	}).Maybe()
+
+	return ProvideService(kvstore.ProvideService(db.InitTestDB(t)), dashboardService, accesscontrolmock.New(), routing.NewRouteRegister())
+}
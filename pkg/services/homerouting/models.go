@@ -0,0 +1,62 @@
+package homerouting
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var (
+	ErrInvalidRules = errors.New("invalid home dashboard rules")
+)
+
+// Rule routes the users of a team, with a role or both to a home dashboard.
+// swagger:model HomeDashboardRule
+type Rule struct {
+	// TeamID matches the members of the team, 0 matches any team.
+	TeamID int64 `json:"teamId,omitempty"`
+	// Role matches the users with exactly this role in the organization, empty matches any role.
+	Role         org.RoleType `json:"role,omitempty"`
+	DashboardUID string       `json:"dashboardUid"`
+}
+
+// Rules are the home dashboard rules of an organization. They are evaluated in order and the
+// first matching rule wins.
+// swagger:model HomeDashboardRules
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+func (r Rule) matches(role org.RoleType, teams []int64) bool {
+	if r.Role != "" && r.Role != role {
+		return false
+	}
+	if r.TeamID == 0 {
+		return true
+	}
+	for _, teamID := range teams {
+		if teamID == r.TeamID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rules) validate() error {
+	for i, rule := range r.Rules {
+		if rule.TeamID == 0 && rule.Role == "" {
+			return fmt.Errorf("%w: rule %d must match a team or a role", ErrInvalidRules, i+1)
+		}
+		if rule.TeamID < 0 {
+			return fmt.Errorf("%w: rule %d has an invalid teamId", ErrInvalidRules, i+1)
+		}
+		if rule.Role != "" && !rule.Role.IsValid() {
+			return fmt.Errorf("%w: rule %d has an invalid role %q", ErrInvalidRules, i+1, rule.Role)
+		}
+		if rule.DashboardUID == "" {
+			return fmt.Errorf("%w: rule %d must have a dashboardUid", ErrInvalidRules, i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package kmsproviders
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// cachedProvider keeps the data keys decrypted by an external provider in memory,
+// so that a key management service is not called every time a data key is used.
+type cachedProvider struct {
+	secrets.Provider
+
+	ttl             time.Duration
+	cleanupInterval time.Duration
+
+	mtx     sync.RWMutex
+	entries map[string]cachedDataKey
+}
+
+type cachedDataKey struct {
+	dataKey   []byte
+	expiresAt time.Time
+}
+
+// WithCache wraps the provider to cache the data keys it decrypts for the given TTL.
+// Expired data keys are removed every cleanupInterval while the secrets service runs.
+func WithCache(p secrets.Provider, ttl, cleanupInterval time.Duration) secrets.Provider {
+	return &cachedProvider{
+		Provider:        p,
+		ttl:             ttl,
+		cleanupInterval: cleanupInterval,
+		entries:         make(map[string]cachedDataKey),
+	}
+}
+
+func (p *cachedProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	key := string(blob)
+
+	p.mtx.RLock()
+	entry, ok := p.entries[key]
+	p.mtx.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.dataKey, nil
+	}
+
+	dataKey, err := p.Provider.Decrypt(ctx, blob)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	p.entries[key] = cachedDataKey{dataKey: dataKey, expiresAt: time.Now().Add(p.ttl)}
+	p.mtx.Unlock()
+
+	return dataKey, nil
+}
+
+func (p *cachedProvider) removeExpired() {
+	now := time.Now()
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for key, entry := range p.entries {
+		if now.After(entry.expiresAt) {
+			delete(p.entries, key)
+		}
+	}
+}
+
+// Run implements secrets.BackgroundProvider.
+func (p *cachedProvider) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.removeExpired()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
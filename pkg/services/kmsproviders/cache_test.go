@@ -0,0 +1,57 @@
+package kmsproviders
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	decrypted int
+}
+
+func (p *countingProvider) Encrypt(_ context.Context, blob []byte) ([]byte, error) {
+	return blob, nil
+}
+
+func (p *countingProvider) Decrypt(_ context.Context, blob []byte) ([]byte, error) {
+	p.decrypted++
+	return blob, nil
+}
+
+func TestCachedProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("decrypts a data key once until it expires", func(t *testing.T) {
+		p := &countingProvider{}
+		cached := WithCache(p, time.Hour, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			dataKey, err := cached.Decrypt(ctx, []byte("key"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("key"), dataKey)
+		}
+		assert.Equal(t, 1, p.decrypted)
+
+		_, err := cached.Decrypt(ctx, []byte("other key"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, p.decrypted)
+	})
+
+	t.Run("decrypts expired data keys again", func(t *testing.T) {
+		p := &countingProvider{}
+		cached := WithCache(p, -time.Second, time.Minute)
+
+		_, err := cached.Decrypt(ctx, []byte("key"))
+		require.NoError(t, err)
+		_, err = cached.Decrypt(ctx, []byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, p.decrypted)
+
+		cached.(*cachedProvider).removeExpired()
+		assert.Empty(t, cached.(*cachedProvider).entries)
+	})
+}
@@ -0,0 +1,65 @@
+package googlekms
+
+import (
+	"context"
+	"errors"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"google.golang.org/api/option"
+	kmspb "google.golang.org/genproto/googleapis/cloud/kms/v1"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type googleKMSProvider struct {
+	client *kms.KeyManagementClient
+	keyID  string
+}
+
+// New creates a provider encrypting data keys with a symmetric key of Google Cloud KMS.
+// The service account key in credentials_file is used when set, otherwise the
+// application default credentials.
+func New(section setting.Section) (secrets.Provider, error) {
+	keyID := section.KeyValue("key_id").MustString("")
+	if keyID == "" {
+		return nil, errors.New("key_id is required")
+	}
+
+	var opts []option.ClientOption
+	if file := section.KeyValue("credentials_file").MustString(""); file != "" {
+		opts = append(opts, option.WithCredentialsFile(file))
+	}
+
+	client, err := kms.NewKeyManagementClient(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &googleKMSProvider{
+		client: client,
+		keyID:  keyID,
+	}, nil
+}
+
+func (p *googleKMSProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	resp, err := p.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:      p.keyID,
+		Plaintext: blob,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+func (p *googleKMSProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	resp, err := p.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       p.keyID,
+		Ciphertext: blob,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
@@ -12,6 +12,12 @@ const (
 	// which fallbacks to Grafana's secret key. See the
 	// defaultprovider package for further information.
 	Default = "secretKey.v1"
+
+	// AWSKMS, AzureKeyVault and GoogleKMS are the kinds of the external
+	// providers, configured in [security.encryption.<kind>.<key name>] sections.
+	AWSKMS        = "awskms"
+	AzureKeyVault = "azurekv"
+	GoogleKMS     = "googlekms"
 )
 
 type Service interface {
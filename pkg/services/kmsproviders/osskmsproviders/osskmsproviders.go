@@ -1,14 +1,31 @@
 package osskmsproviders
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/kmsproviders"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/awskms"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/azurekv"
 	grafana "github.com/grafana/grafana/pkg/services/kmsproviders/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/googlekms"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+var logger = log.New("kmsproviders")
+
+// providerKinds are the kinds of external providers available in OSS.
+var providerKinds = map[string]func(setting.Section) (secrets.Provider, error){
+	kmsproviders.AWSKMS:        awskms.New,
+	kmsproviders.AzureKeyVault: azurekv.New,
+	kmsproviders.GoogleKMS:     googlekms.New,
+}
+
 type Service struct {
 	enc      encryption.Internal
 	settings setting.Provider
@@ -23,8 +40,40 @@ func ProvideService(enc encryption.Internal, settings setting.Provider, features
 	}
 }
 
+// Provide returns the default provider and the external providers listed in
+// available_encryption_providers of the [security] section.
 func (s Service) Provide() (map[secrets.ProviderID]secrets.Provider, error) {
-	return map[secrets.ProviderID]secrets.Provider{
+	providers := map[secrets.ProviderID]secrets.Provider{
 		kmsproviders.Default: grafana.New(s.settings, s.enc),
-	}, nil
+	}
+
+	ttl := s.settings.KeyValue("security.encryption", "data_keys_cache_ttl").MustDuration(15 * time.Minute)
+	cleanupInterval := s.settings.KeyValue("security.encryption", "data_keys_cache_cleanup_interval").MustDuration(time.Minute)
+
+	available := s.settings.KeyValue("security", "available_encryption_providers").MustString("")
+	for _, id := range strings.Fields(available) {
+		providerID := kmsproviders.NormalizeProviderID(secrets.ProviderID(id))
+		if providerID == kmsproviders.Default {
+			continue
+		}
+
+		kind, err := providerID.Kind()
+		if err != nil {
+			return nil, err
+		}
+
+		newProvider, ok := providerKinds[kind]
+		if !ok {
+			logger.Warn("Skipping encryption provider of unknown kind", "provider", providerID, "kind", kind)
+			continue
+		}
+
+		provider, err := newProvider(s.settings.Section("security.encryption." + string(providerID)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure encryption provider %s: %w", providerID, err)
+		}
+		providers[providerID] = kmsproviders.WithCache(provider, ttl, cleanupInterval)
+	}
+
+	return providers, nil
 }
@@ -0,0 +1,190 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ldap.v3"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	// highestCommittedUSN and dsServiceName are read from the root DSE of Active Directory servers,
+	// uSNChanged is set on every entry they update.
+	highestCommittedUSNAttribute = "highestCommittedUSN"
+	dsServiceNameAttribute       = "dsServiceName"
+	usnChangedAttribute          = "uSNChanged"
+	modifyTimestampAttribute     = "modifyTimestamp"
+
+	generalizedTimeLayout = "20060102150405Z"
+	// noAttributes requests the entries without any of their attributes.
+	noAttributes = "1.1"
+)
+
+// clockSkew is subtracted from the time of the Grafana server when marking the position in
+// directories without update sequence numbers, so that changes aren't missed if the clocks differ.
+const clockSkew = 5 * time.Minute
+
+// ErrIncompatibleChangeMarker is returned if the changes since a marker can't be read from the
+// server, e.g. because the marker was read from another domain controller. All users have to be
+// synced again.
+var ErrIncompatibleChangeMarker = errors.New("change marker does not match the LDAP server")
+
+// timeNow is replaced in tests.
+var timeNow = time.Now
+
+// ChangeMarker is the position in a directory up to which its changes were read.
+type ChangeMarker struct {
+	// USN is the highest committed update sequence number of an Active Directory server.
+	USN int64 `json:"usn,omitempty"`
+	// ServiceName identifies the Active Directory server the USN was read from, since every domain
+	// controller numbers its updates separately.
+	ServiceName string `json:"serviceName,omitempty"`
+	// ModifyTimestamp is compared against the modifyTimestamp of the entries of servers without
+	// update sequence numbers.
+	ModifyTimestamp string `json:"modifyTimestamp,omitempty"`
+}
+
+// Changes are the users and groups which changed since a marker.
+type Changes struct {
+	// Users are the users which changed. They include the current members of the changed groups
+	// if the groups of the users are read from their member_of attribute.
+	Users []*models.ExternalUserInfo
+	// Groups are the group mappings whose group changed or was deleted.
+	Groups []*GroupToOrgRole
+	// Marker is the position the next changes are read from.
+	Marker *ChangeMarker
+}
+
+// Marker returns the current position in the directory, using the update sequence numbers of
+// Active Directory when available and the time otherwise.
+//
+// Dial() sets the connection with the server for this Struct. Therefore, we require a
+// call to Dial() before being able to execute this function.
+func (server *Server) Marker() (*ChangeMarker, error) {
+	result, err := server.Connection.Search(&ldap.SearchRequest{
+		Scope:        ldap.ScopeBaseObject,
+		DerefAliases: ldap.NeverDerefAliases,
+		Filter:       "(objectClass=*)",
+		Attributes:   []string{highestCommittedUSNAttribute, dsServiceNameAttribute},
+	})
+	if err != nil {
+		// not all servers allow reading the root DSE
+		server.log.Debug("Failed to read the root DSE of the LDAP server", "error", err)
+	} else if len(result.Entries) > 0 {
+		if value := result.Entries[0].GetAttributeValue(highestCommittedUSNAttribute); value != "" {
+			usn, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", highestCommittedUSNAttribute, value, err)
+			}
+			return &ChangeMarker{
+				USN:         usn,
+				ServiceName: result.Entries[0].GetAttributeValue(dsServiceNameAttribute),
+			}, nil
+		}
+	}
+
+	return &ChangeMarker{ModifyTimestamp: timeNow().UTC().Add(-clockSkew).Format(generalizedTimeLayout)}, nil
+}
+
+// Changes returns the users and mapped groups which changed since the marker. Users deleted from
+// the directory aren't returned.
+//
+// Dial() sets the connection with the server for this Struct. Therefore, we require a
+// call to Dial() before being able to execute this function.
+func (server *Server) Changes(since *ChangeMarker) (*Changes, error) {
+	marker, err := server.Marker()
+	if err != nil {
+		return nil, err
+	}
+
+	var changedFilter string
+	switch {
+	case since.USN > 0 && marker.USN > 0 && since.ServiceName == marker.ServiceName:
+		changedFilter = fmt.Sprintf("(%s>=%d)", usnChangedAttribute, since.USN+1)
+	case since.ModifyTimestamp != "" && marker.ModifyTimestamp != "":
+		changedFilter = fmt.Sprintf("(%s>=%s)", modifyTimestampAttribute, ldap.EscapeFilter(since.ModifyTimestamp))
+	default:
+		return nil, ErrIncompatibleChangeMarker
+	}
+
+	changes := &Changes{Marker: marker}
+	changes.Groups, err = server.changedGroups(changedFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := changedFilter
+	if server.Config.GroupSearchFilter == "" && server.Config.Attr.MemberOf != "" {
+		for _, group := range changes.Groups {
+			filters += fmt.Sprintf("(%s=%s)", server.Config.Attr.MemberOf, ldap.EscapeFilter(group.GroupDN))
+		}
+	}
+	filter := fmt.Sprintf("(&%s(|%s))", strings.ReplaceAll(server.Config.SearchFilter, "%s", "*"), filters)
+
+	var entries [][]*ldap.Entry
+	for _, base := range server.Config.SearchBaseDNs {
+		request := &ldap.SearchRequest{
+			BaseDN:       base,
+			Scope:        ldap.ScopeWholeSubtree,
+			DerefAliases: ldap.NeverDerefAliases,
+			Attributes:   server.userAttributes(),
+			Filter:       filter,
+		}
+		server.log.Debug("LDAP SearchRequest", "searchRequest", fmt.Sprintf("%+v\n", request))
+
+		result, err := server.Connection.SearchWithPaging(request, UsersMaxRequest)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Entries) > 0 {
+			entries = append(entries, result.Entries)
+		}
+	}
+
+	changes.Users, err = server.serializeUsers(entries)
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// changedGroups returns the group mappings whose group matches the filter or doesn't exist anymore.
+func (server *Server) changedGroups(filter string) ([]*GroupToOrgRole, error) {
+	var groups []*GroupToOrgRole
+	changed := map[string]bool{}
+	for _, group := range server.Config.Groups {
+		if group.GroupDN == "*" {
+			continue
+		}
+
+		key := strings.ToLower(group.GroupDN)
+		if _, ok := changed[key]; !ok {
+			result, err := server.Connection.Search(&ldap.SearchRequest{
+				BaseDN:       group.GroupDN,
+				Scope:        ldap.ScopeBaseObject,
+				DerefAliases: ldap.NeverDerefAliases,
+				Attributes:   []string{noAttributes},
+				Filter:       filter,
+			})
+			var ldapErr *ldap.Error
+			switch {
+			case errors.As(err, &ldapErr) && ldapErr.ResultCode == ldap.LDAPResultNoSuchObject:
+				changed[key] = true
+			case err != nil:
+				return nil, err
+			default:
+				changed[key] = len(result.Entries) > 0
+			}
+		}
+
+		if changed[key] {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
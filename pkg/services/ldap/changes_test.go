@@ -0,0 +1,131 @@
+package ldap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ldap.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestServer_Marker(t *testing.T) {
+	t.Run("Should use the update sequence number of Active Directory", func(t *testing.T) {
+		server, connection := changesServer()
+		connection.setSearchResult(&ldap.SearchResult{Entries: []*ldap.Entry{
+			ldap.NewEntry("", map[string][]string{
+				highestCommittedUSNAttribute: {"1234"},
+				dsServiceNameAttribute:       {"CN=NTDS Settings,CN=DC1"},
+			}),
+		}})
+
+		marker, err := server.Marker()
+		require.NoError(t, err)
+		assert.Equal(t, &ChangeMarker{USN: 1234, ServiceName: "CN=NTDS Settings,CN=DC1"}, marker)
+	})
+
+	t.Run("Should use the time without update sequence numbers", func(t *testing.T) {
+		timeNow = func() time.Time { return time.Date(2022, 10, 4, 12, 0, 0, 0, time.UTC) }
+		t.Cleanup(func() { timeNow = time.Now })
+
+		server, connection := changesServer()
+		connection.setSearchResult(&ldap.SearchResult{Entries: []*ldap.Entry{ldap.NewEntry("", nil)}})
+
+		marker, err := server.Marker()
+		require.NoError(t, err)
+		assert.Equal(t, &ChangeMarker{ModifyTimestamp: "20221004115500Z"}, marker)
+	})
+}
+
+func TestServer_Changes(t *testing.T) {
+	t.Run("Should search the users changed since the marker and the members of the changed groups", func(t *testing.T) {
+		server, connection := changesServer()
+		var userFilter string
+		connection.setSearchFunc(func(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			switch request.BaseDN {
+			case "":
+				return &ldap.SearchResult{Entries: []*ldap.Entry{
+					ldap.NewEntry("", map[string][]string{highestCommittedUSNAttribute: {"200"}}),
+				}}, nil
+			case "cn=admins,dc=grafana,dc=org":
+				assert.Equal(t, "(uSNChanged>=101)", request.Filter)
+				return &ldap.SearchResult{Entries: []*ldap.Entry{ldap.NewEntry(request.BaseDN, nil)}}, nil
+			case "cn=editors,dc=grafana,dc=org":
+				return &ldap.SearchResult{}, nil
+			case "cn=removed,dc=grafana,dc=org":
+				return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, nil)
+			default:
+				userFilter = request.Filter
+				return &ldap.SearchResult{Entries: []*ldap.Entry{
+					ldap.NewEntry("cn=roel,dc=grafana,dc=org", map[string][]string{
+						"username": {"roel"},
+						"memberof": {"cn=admins,dc=grafana,dc=org"},
+					}),
+				}}, nil
+			}
+		})
+
+		changes, err := server.Changes(&ChangeMarker{USN: 100})
+		require.NoError(t, err)
+		assert.Equal(t, &ChangeMarker{USN: 200}, changes.Marker)
+		require.Len(t, changes.Groups, 2)
+		assert.Equal(t, "cn=admins,dc=grafana,dc=org", changes.Groups[0].GroupDN)
+		assert.Equal(t, "cn=removed,dc=grafana,dc=org", changes.Groups[1].GroupDN)
+		assert.Equal(t, "(&(cn=*)(|(uSNChanged>=101)(memberof=cn=admins,dc=grafana,dc=org)(memberof=cn=removed,dc=grafana,dc=org)))", userFilter)
+		require.Len(t, changes.Users, 1)
+		assert.Equal(t, "roel", changes.Users[0].Login)
+		assert.Equal(t, org.RoleAdmin, changes.Users[0].OrgRoles[1])
+	})
+
+	t.Run("Should compare the modifyTimestamp without update sequence numbers", func(t *testing.T) {
+		server, connection := changesServer()
+		var filters []string
+		connection.setSearchFunc(func(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			filters = append(filters, request.Filter)
+			return &ldap.SearchResult{}, nil
+		})
+
+		changes, err := server.Changes(&ChangeMarker{ModifyTimestamp: "20221004115500Z"})
+		require.NoError(t, err)
+		assert.Empty(t, changes.Groups)
+		assert.Contains(t, filters, "(&(cn=*)(|(modifyTimestamp>=20221004115500Z)))")
+	})
+
+	t.Run("Should not read the changes since a marker of another server", func(t *testing.T) {
+		server, connection := changesServer()
+		connection.setSearchResult(&ldap.SearchResult{Entries: []*ldap.Entry{
+			ldap.NewEntry("", map[string][]string{
+				highestCommittedUSNAttribute: {"200"},
+				dsServiceNameAttribute:       {"CN=NTDS Settings,CN=DC2"},
+			}),
+		}})
+
+		_, err := server.Changes(&ChangeMarker{USN: 100, ServiceName: "CN=NTDS Settings,CN=DC1"})
+		assert.ErrorIs(t, err, ErrIncompatibleChangeMarker)
+	})
+}
+
+func changesServer() (*Server, *MockConnection) {
+	connection := &MockConnection{}
+	return &Server{
+		Config: &ServerConfig{
+			Attr: AttributeMap{
+				Username: "username",
+				MemberOf: "memberof",
+			},
+			SearchFilter:  "(cn=%s)",
+			SearchBaseDNs: []string{"dc=grafana,dc=org"},
+			Groups: []*GroupToOrgRole{
+				{GroupDN: "cn=admins,dc=grafana,dc=org", OrgId: 1, OrgRole: org.RoleAdmin},
+				{GroupDN: "cn=editors,dc=grafana,dc=org", OrgId: 1, OrgRole: org.RoleEditor},
+				{GroupDN: "cn=removed,dc=grafana,dc=org", OrgId: 2, OrgRole: org.RoleViewer},
+				{GroupDN: "*", OrgId: 1, OrgRole: org.RoleViewer},
+			},
+		},
+		Connection: connection,
+		log:        log.New("test-logger"),
+	}, connection
+}
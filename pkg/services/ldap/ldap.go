@@ -27,6 +27,7 @@ type IConnection interface {
 	Add(*ldap.AddRequest) error
 	Del(*ldap.DelRequest) error
 	Search(*ldap.SearchRequest) (*ldap.SearchResult, error)
+	SearchWithPaging(*ldap.SearchRequest, uint32) (*ldap.SearchResult, error)
 	StartTLS(*tls.Config) error
 	Close()
 }
@@ -35,6 +36,8 @@ type IConnection interface {
 type IServer interface {
 	Login(*models.LoginUserQuery) (*models.ExternalUserInfo, error)
 	Users([]string) ([]*models.ExternalUserInfo, error)
+	Marker() (*ChangeMarker, error)
+	Changes(*ChangeMarker) (*Changes, error)
 	Bind() error
 	UserBind(string, string) error
 	Dial() error
@@ -380,21 +383,6 @@ func (server *Server) getSearchRequest(
 	base string,
 	logins []string,
 ) *ldap.SearchRequest {
-	attributes := []string{}
-
-	inputs := server.Config.Attr
-	attributes = appendIfNotEmpty(
-		attributes,
-		inputs.Username,
-		inputs.Surname,
-		inputs.Email,
-		inputs.Name,
-		inputs.MemberOf,
-
-		// In case for the POSIX LDAP schema server
-		server.Config.GroupSearchFilterUserAttribute,
-	)
-
 	search := ""
 	for _, login := range logins {
 		query := strings.ReplaceAll(
@@ -411,7 +399,7 @@ func (server *Server) getSearchRequest(
 		BaseDN:       base,
 		Scope:        ldap.ScopeWholeSubtree,
 		DerefAliases: ldap.NeverDerefAliases,
-		Attributes:   attributes,
+		Attributes:   server.userAttributes(),
 		Filter:       filter,
 	}
 
@@ -422,6 +410,22 @@ func (server *Server) getSearchRequest(
 	return searchRequest
 }
 
+// userAttributes returns the attributes requested for users
+func (server *Server) userAttributes() []string {
+	inputs := server.Config.Attr
+	return appendIfNotEmpty(
+		[]string{},
+		inputs.Username,
+		inputs.Surname,
+		inputs.Email,
+		inputs.Name,
+		inputs.MemberOf,
+
+		// In case for the POSIX LDAP schema server
+		server.Config.GroupSearchFilterUserAttribute,
+	)
+}
+
 // buildGrafanaUser extracts info from UserInfo model to ExternalUserInfo
 func (server *Server) buildGrafanaUser(user *ldap.Entry) (*models.ExternalUserInfo, error) {
 	memberOf, err := server.getMemberOf(user)
@@ -83,6 +83,11 @@ func (c *MockConnection) Search(sr *ldap.SearchRequest) (*ldap.SearchResult, err
 	return c.SearchFunc(sr)
 }
 
+// SearchWithPaging mocks SearchWithPaging connection function
+func (c *MockConnection) SearchWithPaging(sr *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error) {
+	return c.Search(sr)
+}
+
 // Add mocks Add connection function
 func (c *MockConnection) Add(request *ldap.AddRequest) error {
 	c.AddCalled = true
@@ -0,0 +1,423 @@
+// Package ldapsync syncs the users who signed in with LDAP with the directory in the background,
+// so that changes of their groups apply without waiting for them to sign in again.
+//
+// Syncs are incremental: only the users and mapped groups changed since the previous sync are read,
+// using the update sequence numbers of Active Directory where available and the modifyTimestamp of
+// the entries otherwise. The users of the changed groups are synced too, and only the users whose
+// information, organization roles or Grafana Admin permission differ from the directory are
+// updated. Users deleted from the directory aren't part of the changes, they are disabled by the
+// full syncs running at the full sync interval, or whenever the LDAP configuration changes.
+package ldapsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/schedule"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace = "ldapsync"
+	stateKey    = "state"
+)
+
+var (
+	getLDAPConfig = multildap.GetConfig
+	newLDAP       = ldap.New
+
+	ErrSyncInProgress = errors.New("an LDAP sync is already in progress")
+)
+
+type settings struct {
+	enabled  bool
+	schedule *schedule.Schedule
+	// fullSyncInterval is how often all users are synced, every sync is a full sync if it is 0.
+	fullSyncInterval time.Duration
+}
+
+func readSettings(cfg *setting.Cfg, logger log.Logger) settings {
+	section := cfg.Raw.Section("auth.ldap")
+	s := settings{
+		enabled:          cfg.LDAPEnabled && section.Key("active_sync_enabled").MustBool(false),
+		fullSyncInterval: 24 * time.Hour,
+	}
+
+	sched, err := schedule.Parse(section.Key("sync_cron").MustString("0 1 * * *"), section.Key("sync_timezone").MustString("Local"))
+	if err != nil {
+		if s.enabled {
+			logger.Error("Invalid LDAP sync schedule, the background sync is disabled", "error", err)
+		}
+		s.enabled = false
+	}
+	s.schedule = sched
+
+	value := section.Key("full_sync_interval").MustString("24h")
+	if value == "0" {
+		s.fullSyncInterval = 0
+	} else if d, err := gtime.ParseDuration(value); err == nil && d >= 0 {
+		s.fullSyncInterval = d
+	}
+	return s
+}
+
+// SyncResult counts the users of a sync.
+type SyncResult struct {
+	Full     bool          `json:"full"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	// Checked is the number of users compared with the directory.
+	Checked   int `json:"checked"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	// Disabled is the number of users disabled because they were deleted from the directory or
+	// don't belong to any of the mapped groups anymore.
+	Disabled int `json:"disabled"`
+	Failed   int `json:"failed"`
+}
+
+// state is persisted between syncs.
+type state struct {
+	LastFullSync time.Time `json:"lastFullSync"`
+	// ConfigHash is the hash of the LDAP configuration of the last sync, all users are synced again
+	// when it changes.
+	ConfigHash string `json:"configHash"`
+	// Markers are the positions in the directories of the last sync, by server.
+	Markers map[string]*ldap.ChangeMarker `json:"markers"`
+}
+
+type Service interface {
+	// Sync syncs the users with the directory, incrementally unless full is set or a full sync is
+	// due.
+	Sync(ctx context.Context, full bool) (*SyncResult, error)
+}
+
+type LDAPSyncService struct {
+	settings         settings
+	cfg              *setting.Cfg
+	store            db.DB
+	kvStore          kvstore.KVStore
+	loginService     login.Service
+	authTokenService auth.UserTokenService
+	leaderElection   leaderelection.Service
+	log              log.Logger
+	now              func() time.Time
+
+	// running is held while a sync is in progress.
+	running sync.Mutex
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, kvStore kvstore.KVStore, loginService login.Service,
+	authTokenService auth.UserTokenService, leaderElection leaderelection.Service) *LDAPSyncService {
+	logger := log.New("ldap.sync")
+	return &LDAPSyncService{
+		settings:         readSettings(cfg, logger),
+		cfg:              cfg,
+		store:            sqlStore,
+		kvStore:          kvStore,
+		loginService:     loginService,
+		authTokenService: authTokenService,
+		leaderElection:   leaderElection,
+		log:              logger,
+		now:              time.Now,
+	}
+}
+
+func (s *LDAPSyncService) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+// Run syncs the users on the schedule of sync_cron on the node elected as leader.
+func (s *LDAPSyncService) Run(ctx context.Context) error {
+	for {
+		timer := time.NewTimer(s.settings.schedule.Next(s.now()).Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			if !s.leaderElection.IsLeader(ctx, leaderelection.LeaseLDAPSync) {
+				continue
+			}
+			if _, err := s.Sync(ctx, false); err != nil && !errors.Is(err, ErrSyncInProgress) {
+				s.log.Error("LDAP sync failed", "error", err)
+			}
+		}
+	}
+}
+
+func (s *LDAPSyncService) Sync(ctx context.Context, full bool) (*SyncResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrSyncInProgress
+	}
+	defer s.running.Unlock()
+
+	config, err := getLDAPConfig(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || len(config.Servers) == 0 {
+		return nil, multildap.ErrNoLDAPServers
+	}
+	hash, err := configHash(config)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.getState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.getLDAPUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{Started: s.now()}
+	result.Full = full || previous.ConfigHash != hash || s.settings.fullSyncInterval == 0 ||
+		result.Started.Sub(previous.LastFullSync) >= s.settings.fullSyncInterval
+	next := &state{LastFullSync: previous.LastFullSync, ConfigHash: hash, Markers: map[string]*ldap.ChangeMarker{}}
+
+	if !result.Full {
+		err = s.incrementalSync(ctx, config.Servers, users, previous.Markers, next.Markers, result)
+		if errors.Is(err, ldap.ErrIncompatibleChangeMarker) {
+			s.log.Info("Changes since the last LDAP sync can't be read, syncing all users", "error", err)
+			result = &SyncResult{Full: true, Started: result.Started}
+			next.Markers = map[string]*ldap.ChangeMarker{}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if result.Full {
+		if err := s.fullSync(ctx, config.Servers, users, next.Markers, result); err != nil {
+			return nil, err
+		}
+		next.LastFullSync = result.Started
+	}
+
+	if err := s.setState(ctx, next); err != nil {
+		return nil, err
+	}
+	result.Duration = s.now().Sub(result.Started)
+	s.log.Info("LDAP sync finished", "full", result.Full, "duration", result.Duration, "checked", result.Checked,
+		"updated", result.Updated, "unchanged", result.Unchanged, "disabled", result.Disabled, "failed", result.Failed)
+	return result, nil
+}
+
+// fullSync syncs all users with the servers, and disables the users which none of the servers has.
+func (s *LDAPSyncService) fullSync(ctx context.Context, configs []*ldap.ServerConfig, users *ldapUsers,
+	markers map[string]*ldap.ChangeMarker, result *SyncResult) error {
+	found := map[int64]*models.ExternalUserInfo{}
+	for _, config := range configs {
+		err := withServer(config, func(server ldap.IServer) error {
+			// the marker is read first so that changes made during the sync are read by the next one
+			marker, err := server.Marker()
+			if err != nil {
+				return err
+			}
+			markers[serverKey(config)] = marker
+
+			extUsers, err := server.Users(users.logins(found))
+			if err != nil {
+				return err
+			}
+			for _, extUser := range extUsers {
+				if u := users.match(extUser); u != nil {
+					found[u.ID] = extUser
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, u := range users.all {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		result.Checked++
+		if extUser, ok := found[u.ID]; ok {
+			s.syncUser(ctx, u, extUser, result)
+		} else {
+			s.disableUser(ctx, u, result)
+		}
+	}
+	return nil
+}
+
+// incrementalSync syncs the users changed since the previous markers, and the users of the changed
+// groups.
+func (s *LDAPSyncService) incrementalSync(ctx context.Context, configs []*ldap.ServerConfig, users *ldapUsers,
+	previous, markers map[string]*ldap.ChangeMarker, result *SyncResult) error {
+	changed := map[int64]*models.ExternalUserInfo{}
+	for _, config := range configs {
+		since, ok := previous[serverKey(config)]
+		if !ok {
+			return fmt.Errorf("%w: no marker for %s", ldap.ErrIncompatibleChangeMarker, serverKey(config))
+		}
+
+		err := withServer(config, func(server ldap.IServer) error {
+			changes, err := server.Changes(since)
+			if err != nil {
+				return err
+			}
+			markers[serverKey(config)] = changes.Marker
+
+			extUsers := changes.Users
+			if len(changes.Groups) > 0 {
+				// users removed from the groups aren't part of the changes, so the users of the
+				// organizations the groups are mapped to are read again
+				logins := users.loginsOf(changes.Groups)
+				if config.GroupSearchFilter != "" {
+					// the groups of the users are searched, the members of the groups are unknown
+					logins = users.logins(nil)
+				}
+				groupUsers, err := server.Users(logins)
+				if err != nil {
+					return err
+				}
+				extUsers = append(extUsers, groupUsers...)
+			}
+
+			for _, extUser := range extUsers {
+				if u := users.match(extUser); u != nil {
+					if _, ok := changed[u.ID]; !ok {
+						changed[u.ID] = extUser
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, u := range users.all {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if extUser, ok := changed[u.ID]; ok {
+			result.Checked++
+			s.syncUser(ctx, u, extUser, result)
+		}
+	}
+	return nil
+}
+
+// syncUser updates the user if it differs from the directory.
+func (s *LDAPSyncService) syncUser(ctx context.Context, u *ldapUser, extUser *models.ExternalUserInfo, result *SyncResult) {
+	if extUser.IsDisabled {
+		s.disableUser(ctx, u, result)
+		return
+	}
+	if !u.differsFrom(extUser) {
+		result.Unchanged++
+		return
+	}
+
+	cmd := &models.UpsertUserCommand{
+		ExternalUser:     extUser,
+		UserLookupParams: models.UserLookupParams{UserID: &u.ID},
+	}
+	if err := s.loginService.UpsertUser(ctx, cmd); err != nil {
+		s.log.Error("Failed to sync the user with LDAP", "userId", u.ID, "login", u.Login, "error", err)
+		result.Failed++
+		return
+	}
+	result.Updated++
+}
+
+// disableUser disables the user and revokes their sessions, unless it's the Grafana Admin of the
+// configuration.
+func (s *LDAPSyncService) disableUser(ctx context.Context, u *ldapUser, result *SyncResult) {
+	if u.IsDisabled {
+		result.Unchanged++
+		return
+	}
+	if u.Login == s.cfg.AdminUser {
+		s.log.Warn("Refusing to disable the Grafana Admin user, it was not found in LDAP", "login", u.Login)
+		result.Unchanged++
+		return
+	}
+
+	if err := s.loginService.DisableExternalUser(ctx, u.Login); err != nil {
+		s.log.Error("Failed to disable the user", "userId", u.ID, "login", u.Login, "error", err)
+		result.Failed++
+		return
+	}
+	if err := s.authTokenService.RevokeAllUserTokens(ctx, u.ID); err != nil {
+		s.log.Error("Failed to revoke the sessions of the disabled user", "userId", u.ID, "login", u.Login, "error", err)
+	}
+	result.Disabled++
+}
+
+func (s *LDAPSyncService) getState(ctx context.Context) (*state, error) {
+	value, exists, err := kvstore.WithNamespace(s.kvStore, kvstore.AllOrganizations, kvNamespace).Get(ctx, stateKey)
+	if err != nil {
+		return nil, err
+	}
+	st := &state{}
+	if !exists {
+		return st, nil
+	}
+	if err := json.Unmarshal([]byte(value), st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *LDAPSyncService) setState(ctx context.Context, st *state) error {
+	value, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return kvstore.WithNamespace(s.kvStore, kvstore.AllOrganizations, kvNamespace).Set(ctx, stateKey, string(value))
+}
+
+// withServer connects to the server and binds before calling fn.
+func withServer(config *ldap.ServerConfig, fn func(server ldap.IServer) error) error {
+	server := newLDAP(config)
+	if err := server.Dial(); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", serverKey(config), err)
+	}
+	defer server.Close()
+
+	if err := server.Bind(); err != nil {
+		return err
+	}
+	return fn(server)
+}
+
+func serverKey(config *ldap.ServerConfig) string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(config.Host), config.Port)
+}
+
+func configHash(config *ldap.Config) (string, error) {
+	value, err := json.Marshal(config.Servers)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,220 @@
+package ldapsync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/logintest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationSync(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	s, sqlStore, server, loginService := setupService(t)
+	createLDAPUser(t, sqlStore, "unchanged", org.RoleViewer)
+	createLDAPUser(t, sqlStore, "promoted", org.RoleViewer)
+	createLDAPUser(t, sqlStore, "deleted", org.RoleViewer)
+	createLDAPUser(t, sqlStore, "removed", org.RoleEditor)
+
+	server.users = []*models.ExternalUserInfo{
+		externalUser("unchanged", org.RoleViewer),
+		externalUser("promoted", org.RoleEditor),
+		externalUser("removed", org.RoleEditor),
+	}
+	server.marker = &ldap.ChangeMarker{USN: 100}
+
+	t.Run("Should sync all users the first time", func(t *testing.T) {
+		result, err := s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.True(t, result.Full)
+		assert.Equal(t, 4, result.Checked)
+		assert.Equal(t, 1, result.Updated)
+		assert.Equal(t, 2, result.Unchanged)
+		assert.Equal(t, 1, result.Disabled)
+		assert.Equal(t, []string{"promoted"}, loginService.upserted)
+		assert.Equal(t, []string{"deleted"}, loginService.disabled)
+
+		st, err := s.getState(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]*ldap.ChangeMarker{"ldap.example.org:389": {USN: 100}}, st.Markers)
+	})
+
+	t.Run("Should sync the changed users and the users of the changed groups", func(t *testing.T) {
+		loginService.upserted, loginService.disabled = nil, nil
+		removed := externalUser("removed", "")
+		removed.IsDisabled = true
+		server.users = []*models.ExternalUserInfo{removed}
+		server.changes = &ldap.Changes{
+			Users:  []*models.ExternalUserInfo{externalUser("unchanged", org.RoleAdmin)},
+			Groups: []*ldap.GroupToOrgRole{{GroupDN: "cn=editors", OrgId: 1, OrgRole: org.RoleEditor}},
+			Marker: &ldap.ChangeMarker{USN: 150},
+		}
+
+		result, err := s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.False(t, result.Full)
+		assert.Equal(t, &ldap.ChangeMarker{USN: 100}, server.since)
+		assert.ElementsMatch(t, []string{"unchanged", "promoted", "deleted", "removed"}, server.requested,
+			"the users of the organization of the changed group are read again")
+		assert.Equal(t, 2, result.Checked)
+		assert.Equal(t, []string{"unchanged"}, loginService.upserted)
+		assert.Equal(t, []string{"removed"}, loginService.disabled)
+
+		st, err := s.getState(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &ldap.ChangeMarker{USN: 150}, st.Markers["ldap.example.org:389"])
+	})
+
+	t.Run("Should sync all users if the changes can't be read", func(t *testing.T) {
+		server.users = nil
+		server.changesErr = ldap.ErrIncompatibleChangeMarker
+
+		result, err := s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.True(t, result.Full)
+		assert.Equal(t, 4, result.Checked)
+	})
+}
+
+func TestUserDiffersFrom(t *testing.T) {
+	u := &ldapUser{ID: 1, Login: "roel", Email: "roel@example.org", AuthID: "cn=roel", OrgID: 1,
+		OrgRoles: map[int64]org.RoleType{1: org.RoleViewer}}
+	isAdmin := true
+
+	for name, tc := range map[string]struct {
+		extUser  *models.ExternalUserInfo
+		expected bool
+	}{
+		"same user":              {&models.ExternalUserInfo{Login: "roel", AuthId: "cn=roel", OrgRoles: map[int64]org.RoleType{1: org.RoleViewer}}, false},
+		"without org roles":      {&models.ExternalUserInfo{Login: "roel", AuthId: "cn=roel"}, false},
+		"changed email":          {&models.ExternalUserInfo{Email: "roel@grafana.com", AuthId: "cn=roel"}, true},
+		"moved entry":            {&models.ExternalUserInfo{AuthId: "cn=roel,ou=people"}, true},
+		"grafana admin":          {&models.ExternalUserInfo{AuthId: "cn=roel", IsGrafanaAdmin: &isAdmin}, true},
+		"changed role":           {&models.ExternalUserInfo{AuthId: "cn=roel", OrgRoles: map[int64]org.RoleType{1: org.RoleEditor}}, true},
+		"added organization":     {&models.ExternalUserInfo{AuthId: "cn=roel", OrgRoles: map[int64]org.RoleType{1: org.RoleViewer, 2: org.RoleViewer}}, true},
+		"removed current org":    {&models.ExternalUserInfo{AuthId: "cn=roel", OrgRoles: map[int64]org.RoleType{2: org.RoleViewer}}, true},
+		"changed name and login": {&models.ExternalUserInfo{Login: "roelg", Name: "Roel", AuthId: "cn=roel"}, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, u.differsFrom(tc.extUser))
+		})
+	}
+}
+
+type fakeServer struct {
+	users      []*models.ExternalUserInfo
+	marker     *ldap.ChangeMarker
+	changes    *ldap.Changes
+	changesErr error
+
+	since     *ldap.ChangeMarker
+	requested []string
+}
+
+func (f *fakeServer) Login(*models.LoginUserQuery) (*models.ExternalUserInfo, error) { return nil, nil }
+func (f *fakeServer) Bind() error                                                    { return nil }
+func (f *fakeServer) UserBind(string, string) error                                  { return nil }
+func (f *fakeServer) Dial() error                                                    { return nil }
+func (f *fakeServer) Close()                                                         {}
+func (f *fakeServer) Marker() (*ldap.ChangeMarker, error)                            { return f.marker, nil }
+
+func (f *fakeServer) Users(logins []string) ([]*models.ExternalUserInfo, error) {
+	f.requested = logins
+	var users []*models.ExternalUserInfo
+	for _, u := range f.users {
+		for _, login := range logins {
+			if u.Login == login {
+				users = append(users, u)
+			}
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeServer) Changes(since *ldap.ChangeMarker) (*ldap.Changes, error) {
+	f.since = since
+	return f.changes, f.changesErr
+}
+
+type fakeLoginService struct {
+	logintest.LoginServiceFake
+	upserted []string
+	disabled []string
+}
+
+func (f *fakeLoginService) UpsertUser(ctx context.Context, cmd *models.UpsertUserCommand) error {
+	f.upserted = append(f.upserted, cmd.ExternalUser.Login)
+	return nil
+}
+
+func (f *fakeLoginService) DisableExternalUser(ctx context.Context, username string) error {
+	f.disabled = append(f.disabled, username)
+	return nil
+}
+
+func setupService(t *testing.T) (*LDAPSyncService, *sqlstore.SQLStore, *fakeServer, *fakeLoginService) {
+	t.Helper()
+
+	server := &fakeServer{}
+	config := &ldap.Config{Servers: []*ldap.ServerConfig{{Host: "ldap.example.org", Port: 389}}}
+	getLDAPConfig = func(*setting.Cfg) (*ldap.Config, error) { return config, nil }
+	newLDAP = func(*ldap.ServerConfig) ldap.IServer { return server }
+	t.Cleanup(func() {
+		getLDAPConfig = ldap.GetConfig
+		newLDAP = ldap.New
+	})
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	loginService := &fakeLoginService{}
+	s := ProvideService(cfg, sqlStore, kvstore.ProvideService(sqlStore), loginService,
+		authtest.NewFakeUserAuthTokenService(), leaderelection.ProvideService(cfg, sqlStore, routing.NewRouteRegister()))
+	s.now = func() time.Time { return time.Date(2022, 10, 4, 12, 0, 0, 0, time.UTC) }
+	return s, sqlStore, server, loginService
+}
+
+func createLDAPUser(t *testing.T, store *sqlstore.SQLStore, name string, role org.RoleType) {
+	t.Helper()
+
+	usr, err := store.CreateUser(context.Background(), user.CreateUserCommand{Login: name, SkipOrgSetup: true})
+	require.NoError(t, err)
+	err = store.WithDbSession(context.Background(), func(sess *db.Session) error {
+		now := time.Now()
+		if _, err := sess.Insert(&models.UserAuth{UserId: usr.ID, AuthModule: login.LDAPAuthModule, AuthId: "cn=" + name, Created: now}); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&org.OrgUser{OrgID: 1, UserID: usr.ID, Role: role, Created: now, Updated: now}); err != nil {
+			return err
+		}
+		_, err := sess.Exec("UPDATE "+store.GetDialect().Quote("user")+" SET org_id = ? WHERE id = ?", 1, usr.ID)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func externalUser(name string, role org.RoleType) *models.ExternalUserInfo {
+	extUser := &models.ExternalUserInfo{AuthModule: login.LDAPAuthModule, AuthId: "cn=" + name, Login: name, OrgRoles: map[int64]org.RoleType{}}
+	if role != "" {
+		extUser.OrgRoles[1] = role
+	}
+	return extUser
+}
@@ -0,0 +1,150 @@
+package ldapsync
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// ldapUser is a user who signed in with LDAP, as stored in Grafana.
+type ldapUser struct {
+	ID         int64
+	Login      string
+	Email      string
+	Name       string
+	AuthID     string
+	OrgID      int64
+	IsAdmin    bool
+	IsDisabled bool
+	OrgRoles   map[int64]org.RoleType
+}
+
+// differsFrom is whether syncing the user with the directory would change it, comparing what the
+// login service syncs.
+func (u *ldapUser) differsFrom(extUser *models.ExternalUserInfo) bool {
+	if u.IsDisabled || u.AuthID != extUser.AuthId {
+		return true
+	}
+	if (extUser.Login != "" && extUser.Login != u.Login) || (extUser.Email != "" && extUser.Email != u.Email) ||
+		(extUser.Name != "" && extUser.Name != u.Name) {
+		return true
+	}
+	if extUser.IsGrafanaAdmin != nil && *extUser.IsGrafanaAdmin != u.IsAdmin {
+		return true
+	}
+
+	// organization roles aren't synced if the user has none
+	if len(extUser.OrgRoles) == 0 {
+		return false
+	}
+	if len(extUser.OrgRoles) != len(u.OrgRoles) || extUser.OrgRoles[u.OrgID] == "" {
+		return true
+	}
+	for orgID, role := range extUser.OrgRoles {
+		if u.OrgRoles[orgID] != role {
+			return true
+		}
+	}
+	return false
+}
+
+type ldapUsers struct {
+	all      []*ldapUser
+	byAuthID map[string]*ldapUser
+	byLogin  map[string]*ldapUser
+}
+
+// match returns the user the directory entry belongs to, looking it up like the login service does.
+func (users *ldapUsers) match(extUser *models.ExternalUserInfo) *ldapUser {
+	if u, ok := users.byAuthID[strings.ToLower(extUser.AuthId)]; ok {
+		return u
+	}
+	return users.byLogin[strings.ToLower(extUser.Login)]
+}
+
+// logins returns the logins of the users, except the excluded ones.
+func (users *ldapUsers) logins(exclude map[int64]*models.ExternalUserInfo) []string {
+	logins := make([]string, 0, len(users.all))
+	for _, u := range users.all {
+		if _, ok := exclude[u.ID]; !ok {
+			logins = append(logins, u.Login)
+		}
+	}
+	return logins
+}
+
+// loginsOf returns the logins of the users the groups may have granted roles or the Grafana Admin
+// permission.
+func (users *ldapUsers) loginsOf(groups []*ldap.GroupToOrgRole) []string {
+	var logins []string
+	for _, u := range users.all {
+		for _, group := range groups {
+			_, member := u.OrgRoles[group.OrgId]
+			if member || (group.IsGrafanaAdmin != nil && *group.IsGrafanaAdmin && u.IsAdmin) {
+				logins = append(logins, u.Login)
+				break
+			}
+		}
+	}
+	return logins
+}
+
+// getLDAPUsers returns the users who signed in with LDAP, together with their organization roles.
+func (s *LDAPSyncService) getLDAPUsers(ctx context.Context) (*ldapUsers, error) {
+	var rows []struct {
+		ID          int64        `xorm:"id"`
+		Login       string       `xorm:"login"`
+		Email       string       `xorm:"email"`
+		Name        string       `xorm:"name"`
+		OrgID       int64        `xorm:"org_id"`
+		IsAdmin     bool         `xorm:"is_admin"`
+		IsDisabled  bool         `xorm:"is_disabled"`
+		AuthID      string       `xorm:"auth_id"`
+		MemberOrgID int64        `xorm:"member_org_id"`
+		Role        org.RoleType `xorm:"role"`
+	}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		userTable := s.store.GetDialect().Quote("user")
+		// the latest auth info of a user is the one the login service looks up
+		return sess.SQL(`SELECT u.id, u.login, u.email, u.name, u.org_id, u.is_admin, u.is_disabled, user_auth.auth_id,
+			COALESCE(org_user.org_id, 0) AS member_org_id, COALESCE(org_user.role, '') AS role
+			FROM user_auth
+			INNER JOIN `+userTable+` u ON u.id = user_auth.user_id
+			LEFT JOIN org_user ON org_user.user_id = u.id
+			WHERE user_auth.auth_module = ?
+			ORDER BY u.id, user_auth.id DESC`, login.LDAPAuthModule).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := &ldapUsers{byAuthID: map[string]*ldapUser{}, byLogin: map[string]*ldapUser{}}
+	var u *ldapUser
+	for _, row := range rows {
+		if u == nil || u.ID != row.ID {
+			u = &ldapUser{
+				ID:         row.ID,
+				Login:      row.Login,
+				Email:      row.Email,
+				Name:       row.Name,
+				AuthID:     row.AuthID,
+				OrgID:      row.OrgID,
+				IsAdmin:    row.IsAdmin,
+				IsDisabled: row.IsDisabled,
+				OrgRoles:   map[int64]org.RoleType{},
+			}
+			users.all = append(users.all, u)
+			users.byAuthID[strings.ToLower(u.AuthID)] = u
+			users.byLogin[strings.ToLower(u.Login)] = u
+		}
+		if row.MemberOrgID != 0 {
+			u.OrgRoles[row.MemberOrgID] = row.Role
+		}
+	}
+	return users, nil
+}
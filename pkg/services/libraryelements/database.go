@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -148,6 +149,15 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
+		session.PublishAfterCommit(&events.LibraryElementSaved{
+			Timestamp:   element.Created,
+			Name:        element.Name,
+			UID:         element.UID,
+			OrgID:       element.OrgID,
+			Kind:        element.Kind,
+			Version:     element.Version,
+			PreviousUID: element.UID,
+		})
 		return nil
 	})
 
@@ -220,6 +230,11 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 		}
 
 		elementID = element.ID
+		session.PublishAfterCommit(&events.LibraryElementDeleted{
+			Timestamp: time.Now(),
+			UID:       element.UID,
+			OrgID:     element.OrgID,
+		})
 		return nil
 	})
 	return elementID, err
@@ -525,6 +540,15 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		} else if rowsAffected != 1 {
 			return ErrLibraryElementNotFound
 		}
+		session.PublishAfterCommit(&events.LibraryElementSaved{
+			Timestamp:   libraryElement.Updated,
+			Name:        libraryElement.Name,
+			UID:         libraryElement.UID,
+			OrgID:       libraryElement.OrgID,
+			Kind:        libraryElement.Kind,
+			Version:     libraryElement.Version,
+			PreviousUID: elementInDB.UID,
+		})
 
 		dto = LibraryElementDTO{
 			ID:          libraryElement.ID,
@@ -744,9 +768,10 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 		}
 
 		var elementIDs []struct {
-			ID int64 `xorm:"id"`
+			ID  int64  `xorm:"id"`
+			UID string `xorm:"uid"`
 		}
-		err = session.SQL("SELECT id from library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.OrgID).Find(&elementIDs)
+		err = session.SQL("SELECT id, uid from library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.OrgID).Find(&elementIDs)
 		if err != nil {
 			return err
 		}
@@ -759,6 +784,13 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.OrgID); err != nil {
 			return err
 		}
+		for _, elementID := range elementIDs {
+			session.PublishAfterCommit(&events.LibraryElementDeleted{
+				Timestamp: time.Now(),
+				UID:       elementID.UID,
+				OrgID:     signedInUser.OrgID,
+			})
+		}
 
 		return nil
 	})
@@ -0,0 +1,36 @@
+package features
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/user"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// UserNotificationHandler manages all the `grafana/user-notifications/*` channels.
+type UserNotificationHandler struct{}
+
+func NewUserNotificationHandler() *UserNotificationHandler {
+	return &UserNotificationHandler{}
+}
+
+// GetHandlerForPath called on init.
+func (h *UserNotificationHandler) GetHandlerForPath(_ string) (models.ChannelHandler, error) {
+	return h, nil // all users share the same handler
+}
+
+// OnSubscribe only lets users subscribe to the channel of their own notifications.
+func (h *UserNotificationHandler) OnSubscribe(_ context.Context, user *user.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if e.Path != strconv.FormatInt(user.UserID, 10) || user.IsAnonymous {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+	return models.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish is not used for user notifications, they are published by the server.
+func (h *UserNotificationHandler) OnPublish(_ context.Context, _ *user.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
@@ -0,0 +1,192 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/gobwas/glob"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// historyPolicy decides which channels keep the history of published messages.
+// History is stored by the Centrifuge broker: in memory on a single instance or
+// in Redis when the HA engine is configured.
+type historyPolicy struct {
+	channels []glob.Glob
+	size     int
+	ttl      time.Duration
+}
+
+func newHistoryPolicy(cfg *setting.Cfg) (*historyPolicy, error) {
+	p := &historyPolicy{
+		size: cfg.LiveHistorySize,
+		ttl:  cfg.LiveHistoryTTL,
+	}
+	for _, pattern := range cfg.LiveHistoryChannels {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		p.channels = append(p.channels, g)
+	}
+	return p, nil
+}
+
+// enabled returns true if history is kept for the channel. The channel must not
+// contain the org prefix.
+func (p *historyPolicy) enabled(channel string) bool {
+	if p == nil {
+		return false
+	}
+	for _, g := range p.channels {
+		if g.Match(channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// publishOptions returns the options to publish a message to the channel with.
+func (p *historyPolicy) publishOptions(channel string) []centrifuge.PublishOption {
+	if !p.enabled(channel) {
+		return nil
+	}
+	return []centrifuge.PublishOption{centrifuge.WithHistory(p.size, p.ttl)}
+}
+
+type livePublication struct {
+	Offset uint64          `json:"offset"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type channelHistoryResponse struct {
+	Channel string `json:"channel"`
+	// Offset and Epoch are the current position in the channel stream. Clients
+	// pass them as since and epoch on the next catch-up request.
+	Offset uint64 `json:"offset"`
+	Epoch  string `json:"epoch"`
+	// Recovered is false if messages since the requested position are no longer
+	// in the history. Clients should reload their state in this case.
+	Recovered    bool              `json:"recovered"`
+	Publications []livePublication `json:"publications"`
+}
+
+// HandleHistoryHTTP returns the messages published to a channel since a stream position,
+// so clients reconnecting after a network failure can catch up on messages they missed.
+func (g *GrafanaLive) HandleHistoryHTTP(c *models.ReqContext) response.Response {
+	channel := web.Params(c.Req)["*"]
+	if _, err := live.ParseChannel(channel); err != nil {
+		return response.Error(http.StatusBadRequest, "invalid channel ID", nil)
+	}
+	if !g.history.enabled(channel) {
+		return response.Error(http.StatusNotFound, "History is not enabled for this channel", nil)
+	}
+
+	var since *centrifuge.StreamPosition
+	if sinceParam := c.Query("since"); sinceParam != "" {
+		offset, err := strconv.ParseUint(sinceParam, 10, 64)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "since must be a stream offset", err)
+		}
+		since = &centrifuge.StreamPosition{Offset: offset, Epoch: c.Query("epoch")}
+	}
+	limit := c.QueryInt("limit")
+	if limit <= 0 || limit > g.history.size {
+		limit = g.history.size
+	}
+
+	status, err := g.checkSubscribe(c.Req.Context(), c.SignedInUser, channel)
+	if err != nil {
+		if errors.Is(err, live.ErrInvalidChannelID) {
+			return response.Error(http.StatusBadRequest, "invalid channel ID", nil)
+		}
+		logger.Error("Error checking subscribe permissions", "user", c.SignedInUser.UserID, "channel", channel, "error", err)
+		return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+	}
+	if status != backend.SubscribeStreamStatusOK {
+		code, text := subscribeStatusToHTTPError(status)
+		return response.Error(code, text, nil)
+	}
+
+	opts := []centrifuge.HistoryOption{centrifuge.WithLimit(limit)}
+	if since != nil {
+		opts = append(opts, centrifuge.WithSince(since))
+	}
+	result, err := g.node.History(orgchannel.PrependOrgID(c.OrgID, channel), opts...)
+	recovered := true
+	if err != nil {
+		if !errors.Is(err, centrifuge.ErrorUnrecoverablePosition) {
+			logger.Error("Error getting channel history", "channel", channel, "error", err)
+			return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+		}
+		// the stream was lost, e.g. since the history expired
+		recovered = false
+	}
+
+	resp := channelHistoryResponse{
+		Channel:      channel,
+		Offset:       result.Offset,
+		Epoch:        result.Epoch,
+		Recovered:    recovered,
+		Publications: make([]livePublication, 0, len(result.Publications)),
+	}
+	if since != nil && recovered {
+		// messages were trimmed from the history if the first returned one does not
+		// directly follow the requested position.
+		if len(result.Publications) > 0 {
+			resp.Recovered = result.Publications[0].Offset == since.Offset+1
+		} else {
+			resp.Recovered = result.Offset == since.Offset
+		}
+	}
+	for _, pub := range result.Publications {
+		resp.Publications = append(resp.Publications, livePublication{Offset: pub.Offset, Data: pub.Data})
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// checkSubscribe checks if the user is allowed to subscribe to the channel, using the
+// channel rule when one exists and the channel handler otherwise.
+func (g *GrafanaLive) checkSubscribe(ctx context.Context, u *user.SignedInUser, channel string) (backend.SubscribeStreamStatus, error) {
+	if g.Pipeline != nil {
+		rule, ok, err := g.Pipeline.Get(u.OrgID, channel)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			if rule.SubscribeAuth != nil {
+				ok, err := rule.SubscribeAuth.CanSubscribe(ctx, u)
+				if err != nil {
+					return 0, err
+				}
+				if !ok {
+					return backend.SubscribeStreamStatusPermissionDenied, nil
+				}
+			}
+			return backend.SubscribeStreamStatusOK, nil
+		}
+	}
+
+	handler, addr, err := g.GetChannelHandler(ctx, u, channel)
+	if err != nil {
+		return 0, err
+	}
+	_, status, err := handler.OnSubscribe(ctx, u, models.SubscribeEvent{
+		Channel: channel,
+		Path:    addr.Path,
+	})
+	return status, err
+}
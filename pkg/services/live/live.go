@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/startup/providers"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
@@ -36,8 +37,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/natsbroker"
 	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/live/pushauth"
 	"github.com/grafana/grafana/pkg/services/live/pushws"
 	"github.com/grafana/grafana/pkg/services/live/runstream"
 	"github.com/grafana/grafana/pkg/services/live/survey"
@@ -56,6 +59,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/nats-io/nats.go"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -78,6 +82,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	usageStatsService usagestats.Service, queryDataService *query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
 	orgService org.Service) (*GrafanaLive, error) {
+	defer providers.Track("live")()
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -112,7 +117,14 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	}
 	g.node = node
 
-	if g.IsHA() {
+	g.history, err = newHistoryPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var natsJetStream nats.JetStreamContext
+	switch g.Cfg.LiveHAEngine {
+	case "redis":
 		// Configure HA with Redis. In this case Centrifuge nodes
 		// will be connected over Redis PUB/SUB. Presence will work
 		// globally since kept inside Redis.
@@ -154,12 +166,32 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			return nil, fmt.Errorf("error creating Live Redis presence manager: %v", err)
 		}
 		node.SetPresenceManager(presenceManager)
+	case "nats":
+		// Configure HA with NATS. In this case Centrifuge nodes will be
+		// connected over NATS PUB/SUB. Presence is kept in a JetStream
+		// key-value bucket to work globally.
+		natsConn, err := natsbroker.Connect(g.Cfg.LiveHAEngineAddress)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to Live NATS: %v", err)
+		}
+		natsJetStream, err = natsConn.JetStream()
+		if err != nil {
+			return nil, fmt.Errorf("error creating Live NATS JetStream context: %v", err)
+		}
+		node.SetBroker(natsbroker.NewBroker(node, natsConn, "gf_live"))
+
+		presenceManager, err := natsbroker.NewPresenceManager(natsJetStream, "gf_live")
+		if err != nil {
+			return nil, fmt.Errorf("error creating Live NATS presence manager: %v", err)
+		}
+		node.SetPresenceManager(presenceManager)
 	}
 
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
 	var managedStreamRunner *managedstream.Runner
-	if g.IsHA() {
+	switch g.Cfg.LiveHAEngine {
+	case "redis":
 		redisClient := redis.NewClient(&redis.Options{
 			Addr: g.Cfg.LiveHAEngineAddress,
 		})
@@ -172,7 +204,17 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
 		)
-	} else {
+	case "nats":
+		frameCache, err := managedstream.NewNATSFrameCache(natsJetStream)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Live NATS frame cache: %v", err)
+		}
+		managedStreamRunner = managedstream.NewRunner(
+			g.Publish,
+			channelLocalPublisher,
+			frameCache,
+		)
+	default:
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
@@ -246,6 +288,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.GrafanaScope.Features["dashboard"] = dash
 	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
 	g.GrafanaScope.Features["comment"] = features.NewCommentHandler(commentmodel.NewPermissionChecker(g.SQLStore, g.Features, accessControl, dashboardService, annotationsRepo))
+	g.GrafanaScope.Features["user-notifications"] = features.NewUserNotificationHandler()
 
 	g.surveyCaller = survey.NewCaller(managedStreamRunner, node)
 	err = g.surveyCaller.SetupHandlers()
@@ -337,13 +380,15 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		CheckOrigin:     checkOrigin,
 	})
 
-	pushWSHandler := pushws.NewHandler(g.ManagedStreamRunner, pushws.Config{
+	g.PushAuthorizer = pushauth.New(accessControl, cfg)
+
+	pushWSHandler := pushws.NewHandler(g.ManagedStreamRunner, g.PushAuthorizer, pushws.Config{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     checkOrigin,
 	})
 
-	pushPipelineWSHandler := pushws.NewPipelinePushHandler(g.Pipeline, pushws.Config{
+	pushPipelineWSHandler := pushws.NewPipelinePushHandler(g.Pipeline, g.PushAuthorizer, pushws.Config{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     checkOrigin,
@@ -382,10 +427,12 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		group.Get("/ws", g.websocketHandler)
 	}, middleware.ReqSignedIn)
 
+	// Permissions for the pushed channels are checked by the push handlers.
+	authorize := accesscontrol.Middleware(accessControl)
 	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
 		group.Get("/push/:streamId", g.pushWebsocketHandler)
 		group.Get("/pipeline/push/*", g.pushPipelineWebsocketHandler)
-	}, middleware.ReqOrgAdmin)
+	}, authorize(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(accesscontrol.ActionLivePublish)))
 
 	g.registerUsageMetrics()
 
@@ -412,6 +459,7 @@ type GrafanaLive struct {
 
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
+	history      *historyPolicy
 
 	// Websocket handlers
 	websocketHandler             interface{}
@@ -426,6 +474,7 @@ type GrafanaLive struct {
 	GrafanaScope CoreGrafanaScope
 
 	ManagedStreamRunner *managedstream.Runner
+	PushAuthorizer      *pushauth.Authorizer
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
 
@@ -707,7 +756,7 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 			EmitPresence:   reply.Presence,
 			EmitJoinLeave:  reply.JoinLeave,
 			PushJoinLeave:  reply.JoinLeave,
-			EnableRecovery: reply.Recover,
+			EnableRecovery: reply.Recover || g.history.enabled(channel),
 			Data:           reply.Data,
 		},
 	}, nil
@@ -801,10 +850,14 @@ func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Cl
 			HistoryTTL:  reply.HistoryTTL,
 		},
 	}
+	if reply.HistorySize == 0 && g.history.enabled(channel) {
+		centrifugeReply.Options.HistorySize = g.history.size
+		centrifugeReply.Options.HistoryTTL = g.history.ttl
+	}
 	if reply.Data != nil {
 		// If data is not nil then we published it manually and tell Centrifuge
 		// publication result so Centrifuge won't publish itself.
-		result, err := g.node.Publish(e.Channel, reply.Data)
+		result, err := g.node.Publish(e.Channel, reply.Data, centrifuge.WithHistory(centrifugeReply.Options.HistorySize, centrifugeReply.Options.HistoryTTL))
 		if err != nil {
 			logger.Error("Error publishing", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err, "data", string(reply.Data))
 			return centrifuge.PublishReply{}, centrifuge.ErrorInternal
@@ -941,7 +994,7 @@ func (g *GrafanaLive) handleDatasourceScope(ctx context.Context, user *user.Sign
 
 // Publish sends the data to the channel without checking permissions etc.
 func (g *GrafanaLive) Publish(orgID int64, channel string, data []byte) error {
-	_, err := g.node.Publish(orgchannel.PrependOrgID(orgID, channel), data)
+	_, err := g.node.Publish(orgchannel.PrependOrgID(orgID, channel), data, g.history.publishOptions(channel)...)
 	return err
 }
 
@@ -199,3 +199,23 @@ func Test_getHistogramMetric(t *testing.T) {
 		})
 	}
 }
+
+func Test_historyPolicy(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LiveHistoryChannels = []string{"grafana/dashboard/*", "stream/telegraf/cpu"}
+	cfg.LiveHistorySize = 10
+	cfg.LiveHistoryTTL = time.Minute
+
+	p, err := newHistoryPolicy(cfg)
+	require.NoError(t, err)
+
+	require.True(t, p.enabled("grafana/dashboard/uid/abc"))
+	require.True(t, p.enabled("stream/telegraf/cpu"))
+	require.False(t, p.enabled("stream/telegraf/mem"))
+	require.False(t, p.enabled("grafana/broadcast/test"))
+	require.Len(t, p.publishOptions("grafana/dashboard/uid/abc"), 1)
+	require.Empty(t, p.publishOptions("grafana/broadcast/test"))
+
+	var disabled *historyPolicy
+	require.False(t, disabled.enabled("grafana/dashboard/uid/abc"))
+}
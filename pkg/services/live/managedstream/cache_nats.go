@@ -0,0 +1,113 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/nats-io/nats.go"
+)
+
+// NATSFrameCache keeps frames in a NATS JetStream key-value bucket.
+type NATSFrameCache struct {
+	mu     sync.RWMutex
+	kv     nats.KeyValue
+	frames map[int64]map[string]data.FrameJSONCache
+}
+
+// NewNATSFrameCache creates a frame cache using the bucket gf_live_managed_stream.
+func NewNATSFrameCache(js nats.JetStreamContext) (*NATSFrameCache, error) {
+	kv, err := js.KeyValue(natsCacheBucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: natsCacheBucket, TTL: frameCacheTTL})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &NATSFrameCache{
+		frames: map[int64]map[string]data.FrameJSONCache{},
+		kv:     kv,
+	}, nil
+}
+
+const natsCacheBucket = "gf_live_managed_stream"
+
+type natsCachedFrame struct {
+	Schema string          `json:"schema"`
+	Frame  json.RawMessage `json:"frame"`
+}
+
+func (c *NATSFrameCache) GetActiveChannels(orgID int64) (map[string]json.RawMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	frames, ok := c.frames[orgID]
+	if !ok {
+		return nil, nil
+	}
+	info := make(map[string]json.RawMessage, len(frames))
+	for k, v := range frames {
+		info[k] = v.Bytes(data.IncludeSchemaOnly)
+	}
+	return info, nil
+}
+
+func (c *NATSFrameCache) get(orgID int64, channel string) (*natsCachedFrame, error) {
+	entry, err := c.kv.Get(getNATSCacheKey(orgchannel.PrependOrgID(orgID, channel)))
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var cached natsCachedFrame
+	if err := json.Unmarshal(entry.Value(), &cached); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+func (c *NATSFrameCache) GetFrame(_ context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	cached, err := c.get(orgID, channel)
+	if err != nil || cached == nil {
+		return nil, false, err
+	}
+	return cached.Frame, true, nil
+}
+
+func (c *NATSFrameCache) Update(_ context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
+	c.mu.Lock()
+	if _, ok := c.frames[orgID]; !ok {
+		c.frames[orgID] = map[string]data.FrameJSONCache{}
+	}
+	c.frames[orgID][channel] = jsonFrame
+	c.mu.Unlock()
+
+	stringSchema := string(jsonFrame.Bytes(data.IncludeSchemaOnly))
+
+	previous, err := c.get(orgID, channel)
+	if err != nil {
+		return false, err
+	}
+
+	value, err := json.Marshal(natsCachedFrame{
+		Schema: stringSchema,
+		Frame:  jsonFrame.Bytes(data.IncludeAll),
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := c.kv.Put(getNATSCacheKey(orgchannel.PrependOrgID(orgID, channel)), value); err != nil {
+		return false, err
+	}
+	return previous == nil || previous.Schema != stringSchema, nil
+}
+
+// getNATSCacheKey encodes the channel since keys can't contain all characters allowed in channels.
+func getNATSCacheKey(channelID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(channelID))
+}
@@ -0,0 +1,23 @@
+//go:build nats
+// +build nats
+
+package managedstream
+
+import (
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATSCacheStorage(t *testing.T) {
+	conn, err := nats.Connect(nats.DefaultURL)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+
+	c, err := NewNATSFrameCache(js)
+	require.NoError(t, err)
+	testFrameCache(t, c)
+}
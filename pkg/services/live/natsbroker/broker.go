@@ -0,0 +1,201 @@
+package natsbroker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/nats-io/nats.go"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var logger = log.New("live.nats")
+
+// Connect connects to the NATS server at address. The connection reconnects
+// automatically when the server becomes unavailable.
+func Connect(address string) (*nats.Conn, error) {
+	return nats.Connect(address, nats.Name("grafana-live"), nats.MaxReconnects(-1))
+}
+
+type messageType string
+
+const (
+	publicationMessage messageType = "publication"
+	joinMessage        messageType = "join"
+	leaveMessage       messageType = "leave"
+)
+
+// message is sent over NATS to deliver publications and join/leave messages
+// to the Grafana instances with subscribers of a channel.
+type message struct {
+	Type messageType            `json:"type"`
+	Data []byte                 `json:"data,omitempty"`
+	Info *centrifuge.ClientInfo `json:"info,omitempty"`
+	Tags map[string]string      `json:"tags,omitempty"`
+}
+
+// Broker is a Centrifuge broker delivering publications, join/leave messages and
+// control commands between Grafana instances over NATS PUB/SUB. NATS does not keep
+// messages, so the broker does not support channel history.
+type Broker struct {
+	node    *centrifuge.Node
+	conn    *nats.Conn
+	prefix  string
+	handler centrifuge.BrokerEventHandler
+
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription
+}
+
+var _ centrifuge.Broker = (*Broker)(nil)
+
+// NewBroker creates a broker which uses subjects starting with prefix.
+func NewBroker(node *centrifuge.Node, conn *nats.Conn, prefix string) *Broker {
+	return &Broker{
+		node:   node,
+		conn:   conn,
+		prefix: prefix,
+		subs:   make(map[string]*nats.Subscription),
+	}
+}
+
+// channelSubject returns the subject of a channel. Channels are encoded since they
+// can contain characters with a special meaning in NATS subjects, like dots.
+func (b *Broker) channelSubject(ch string) string {
+	return b.prefix + ".channel." + encode(ch)
+}
+
+func (b *Broker) controlSubject(nodeID string) string {
+	if nodeID == "" {
+		return b.prefix + ".control"
+	}
+	return b.prefix + ".node." + nodeID
+}
+
+func (b *Broker) Run(h centrifuge.BrokerEventHandler) error {
+	b.handler = h
+	handleControl := func(m *nats.Msg) {
+		if err := h.HandleControl(m.Data); err != nil {
+			logger.Error("Error handling control message", "error", err)
+		}
+	}
+	if _, err := b.conn.Subscribe(b.controlSubject(""), handleControl); err != nil {
+		return fmt.Errorf("error subscribing to control subject: %w", err)
+	}
+	if _, err := b.conn.Subscribe(b.controlSubject(b.node.ID()), handleControl); err != nil {
+		return fmt.Errorf("error subscribing to node control subject: %w", err)
+	}
+	return nil
+}
+
+func (b *Broker) Subscribe(ch string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[ch]; ok {
+		return nil
+	}
+	sub, err := b.conn.Subscribe(b.channelSubject(ch), func(m *nats.Msg) {
+		b.handleMessage(ch, m.Data)
+	})
+	if err != nil {
+		return err
+	}
+	b.subs[ch] = sub
+	return nil
+}
+
+func (b *Broker) Unsubscribe(ch string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub, ok := b.subs[ch]
+	if !ok {
+		return nil
+	}
+	delete(b.subs, ch)
+	return sub.Unsubscribe()
+}
+
+func (b *Broker) handleMessage(ch string, data []byte) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		logger.Error("Error decoding message", "channel", ch, "error", err)
+		return
+	}
+
+	var err error
+	switch msg.Type {
+	case publicationMessage:
+		err = b.handler.HandlePublication(ch, &centrifuge.Publication{
+			Data: msg.Data,
+			Info: msg.Info,
+			Tags: msg.Tags,
+		}, centrifuge.StreamPosition{})
+	case joinMessage:
+		err = b.handler.HandleJoin(ch, msg.Info)
+	case leaveMessage:
+		err = b.handler.HandleLeave(ch, msg.Info)
+	default:
+		err = fmt.Errorf("unknown message type %q", msg.Type)
+	}
+	if err != nil {
+		logger.Error("Error handling message", "channel", ch, "type", msg.Type, "error", err)
+	}
+}
+
+func (b *Broker) publish(ch string, msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(b.channelSubject(ch), data)
+}
+
+// Publish sends the publication to all Grafana instances. History options are
+// ignored since NATS does not keep messages.
+func (b *Broker) Publish(ch string, data []byte, opts centrifuge.PublishOptions) (centrifuge.StreamPosition, error) {
+	return centrifuge.StreamPosition{}, b.publish(ch, message{
+		Type: publicationMessage,
+		Data: data,
+		Info: opts.ClientInfo,
+		Tags: opts.Tags,
+	})
+}
+
+func (b *Broker) PublishJoin(ch string, info *centrifuge.ClientInfo) error {
+	return b.publish(ch, message{Type: joinMessage, Info: info})
+}
+
+func (b *Broker) PublishLeave(ch string, info *centrifuge.ClientInfo) error {
+	return b.publish(ch, message{Type: leaveMessage, Info: info})
+}
+
+func (b *Broker) PublishControl(data []byte, nodeID, _ string) error {
+	return b.conn.Publish(b.controlSubject(nodeID), data)
+}
+
+func (b *Broker) History(_ string, _ centrifuge.HistoryFilter) ([]*centrifuge.Publication, centrifuge.StreamPosition, error) {
+	return nil, centrifuge.StreamPosition{}, centrifuge.ErrorNotAvailable
+}
+
+func (b *Broker) RemoveHistory(_ string) error {
+	return centrifuge.ErrorNotAvailable
+}
+
+// KeyValue returns the JetStream key-value bucket, which is created when it does not exist.
+func KeyValue(js nats.JetStreamContext, bucket string, ttl time.Duration) (nats.KeyValue, error) {
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		return js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, TTL: ttl})
+	}
+	return kv, err
+}
+
+// encode encodes a channel into a single token of a NATS subject or key.
+func encode(ch string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(ch))
+}
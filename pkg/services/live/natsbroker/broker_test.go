@@ -0,0 +1,63 @@
+package natsbroker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/require"
+)
+
+type testEventHandler struct {
+	publications []*centrifuge.Publication
+	joins        []*centrifuge.ClientInfo
+}
+
+func (h *testEventHandler) HandlePublication(_ string, pub *centrifuge.Publication, _ centrifuge.StreamPosition) error {
+	h.publications = append(h.publications, pub)
+	return nil
+}
+
+func (h *testEventHandler) HandleJoin(_ string, info *centrifuge.ClientInfo) error {
+	h.joins = append(h.joins, info)
+	return nil
+}
+
+func (h *testEventHandler) HandleLeave(_ string, _ *centrifuge.ClientInfo) error {
+	return nil
+}
+
+func (h *testEventHandler) HandleControl(_ []byte) error {
+	return nil
+}
+
+func TestBroker_channelSubject(t *testing.T) {
+	b := NewBroker(nil, nil, "gf_live")
+	// dots in the channel must not create additional subject tokens.
+	require.Equal(t, "gf_live.channel.MS9zdHJlYW0vdGVsZWdyYWYvY3B1LnRvdGFs", b.channelSubject("1/stream/telegraf/cpu.total"))
+	require.Equal(t, "gf_live.control", b.controlSubject(""))
+	require.Equal(t, "gf_live.node.abc", b.controlSubject("abc"))
+}
+
+func TestBroker_handleMessage(t *testing.T) {
+	h := &testEventHandler{}
+	b := NewBroker(nil, nil, "gf_live")
+	b.handler = h
+
+	info := &centrifuge.ClientInfo{ClientID: "client", UserID: "1"}
+	for _, msg := range []message{
+		{Type: publicationMessage, Data: []byte(`{"value":1}`), Info: info, Tags: map[string]string{"a": "b"}},
+		{Type: joinMessage, Info: info},
+		{Type: "unknown"},
+	} {
+		data, err := json.Marshal(msg)
+		require.NoError(t, err)
+		b.handleMessage("1/grafana/broadcast/test", data)
+	}
+
+	require.Len(t, h.publications, 1)
+	require.Equal(t, []byte(`{"value":1}`), h.publications[0].Data)
+	require.Equal(t, info, h.publications[0].Info)
+	require.Equal(t, map[string]string{"a": "b"}, h.publications[0].Tags)
+	require.Equal(t, []*centrifuge.ClientInfo{info}, h.joins)
+}
@@ -0,0 +1,91 @@
+package natsbroker
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/nats-io/nats.go"
+)
+
+// presenceTTL is how long presence information is kept when it is not updated.
+// Centrifuge updates the presence of connected clients every 25 seconds.
+const presenceTTL = time.Minute
+
+// PresenceManager keeps the presence information of channels in a NATS JetStream
+// key-value bucket, so it is shared by all Grafana instances.
+type PresenceManager struct {
+	kv nats.KeyValue
+}
+
+var _ centrifuge.PresenceManager = (*PresenceManager)(nil)
+
+// NewPresenceManager creates a presence manager using the bucket <prefix>_presence.
+// The NATS server must have JetStream enabled.
+func NewPresenceManager(js nats.JetStreamContext, prefix string) (*PresenceManager, error) {
+	kv, err := KeyValue(js, prefix+"_presence", presenceTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &PresenceManager{kv: kv}, nil
+}
+
+func presenceKey(ch string, clientID string) string {
+	return encode(ch) + "." + clientID
+}
+
+func (m *PresenceManager) Presence(ch string) (map[string]*centrifuge.ClientInfo, error) {
+	w, err := m.kv.Watch(presenceKey(ch, "*"), nats.IgnoreDeletes())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = w.Stop() }()
+
+	presence := make(map[string]*centrifuge.ClientInfo)
+	// the watcher sends the current values first, followed by nil.
+	for entry := range w.Updates() {
+		if entry == nil {
+			break
+		}
+		var info centrifuge.ClientInfo
+		if err := json.Unmarshal(entry.Value(), &info); err != nil {
+			return nil, err
+		}
+		presence[entry.Key()[strings.LastIndex(entry.Key(), ".")+1:]] = &info
+	}
+	return presence, nil
+}
+
+func (m *PresenceManager) PresenceStats(ch string) (centrifuge.PresenceStats, error) {
+	presence, err := m.Presence(ch)
+	if err != nil {
+		return centrifuge.PresenceStats{}, err
+	}
+	users := make(map[string]struct{}, len(presence))
+	for _, info := range presence {
+		users[info.UserID] = struct{}{}
+	}
+	return centrifuge.PresenceStats{
+		NumClients: len(presence),
+		NumUsers:   len(users),
+	}, nil
+}
+
+func (m *PresenceManager) AddPresence(ch string, clientID string, info *centrifuge.ClientInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	_, err = m.kv.Put(presenceKey(ch, clientID), data)
+	return err
+}
+
+func (m *PresenceManager) RemovePresence(ch string, clientID string) error {
+	err := m.kv.Delete(presenceKey(ch, clientID))
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package pushauth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// limiterTTL is how long the rate limiter of a user is kept after its last push.
+const limiterTTL = 10 * time.Minute
+
+// Authorizer checks the permissions and rate limits of pushes to Live channels.
+// Publishing to a channel requires the live:publish action with the scope of the
+// channel, e.g. live:channels:stream/telegraf/cpu, so telemetry agents can be limited
+// to the channels they push to.
+type Authorizer struct {
+	accessControl accesscontrol.AccessControl
+	limit         rate.Limit
+	burst         int
+
+	mu        sync.Mutex
+	limiters  map[string]*userLimiter
+	lastPrune time.Time
+}
+
+type userLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func New(accessControl accesscontrol.AccessControl, cfg *setting.Cfg) *Authorizer {
+	a := &Authorizer{
+		accessControl: accessControl,
+		limit:         rate.Inf,
+		burst:         cfg.LivePushRateLimitBurst,
+		limiters:      make(map[string]*userLimiter),
+	}
+	if cfg.LivePushRateLimit > 0 {
+		a.limit = rate.Limit(cfg.LivePushRateLimit)
+	}
+	return a
+}
+
+// CanPublish returns true if the user is allowed to publish to the channel. Without
+// access control only organization admins are allowed to push.
+func (a *Authorizer) CanPublish(ctx context.Context, u *user.SignedInUser, channel string) (bool, error) {
+	if a.accessControl.IsDisabled() {
+		return u.HasRole(org.RoleAdmin), nil
+	}
+	return a.accessControl.Evaluate(ctx, u, accesscontrol.EvalPermission(accesscontrol.ActionLivePublish, accesscontrol.ScopeLiveChannelsPrefix+channel))
+}
+
+// Allow returns false if the user exceeded the push rate limit. Every call counts as one push.
+func (a *Authorizer) Allow(u *user.SignedInUser) bool {
+	if a.limit == rate.Inf {
+		return true
+	}
+
+	now := time.Now()
+	key := fmt.Sprintf("%d-%d", u.OrgID, u.UserID)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if now.Sub(a.lastPrune) > limiterTTL {
+		for k, l := range a.limiters {
+			if now.Sub(l.lastSeen) > limiterTTL {
+				delete(a.limiters, k)
+			}
+		}
+		a.lastPrune = now
+	}
+
+	l, ok := a.limiters[key]
+	if !ok {
+		l = &userLimiter{limiter: rate.NewLimiter(a.limit, a.burst)}
+		a.limiters[key] = l
+	}
+	l.lastSeen = now
+	return l.limiter.AllowN(now, 1)
+}
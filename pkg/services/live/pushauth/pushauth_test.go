@@ -0,0 +1,54 @@
+package pushauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestAuthorizer_CanPublish(t *testing.T) {
+	a := New(acimpl.ProvideAccessControl(setting.NewCfg()), setting.NewCfg())
+	u := &user.SignedInUser{OrgID: 1, UserID: 2, Permissions: map[int64]map[string][]string{
+		1: {accesscontrol.ActionLivePublish: {"live:channels:stream/telegraf/*"}},
+	}}
+
+	ok, err := a.CanPublish(context.Background(), u, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = a.CanPublish(context.Background(), u, "grafana/dashboard/uid/abc")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestAuthorizer_Allow(t *testing.T) {
+	t.Run("unlimited by default", func(t *testing.T) {
+		a := New(nil, setting.NewCfg())
+		u := &user.SignedInUser{OrgID: 1, UserID: 2}
+		for i := 0; i < 100; i++ {
+			require.True(t, a.Allow(u))
+		}
+	})
+
+	t.Run("limits pushes per user", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.LivePushRateLimit = 0.001
+		cfg.LivePushRateLimitBurst = 2
+		a := New(nil, cfg)
+
+		u := &user.SignedInUser{OrgID: 1, UserID: 2}
+		require.True(t, a.Allow(u))
+		require.True(t, a.Allow(u))
+		require.False(t, a.Allow(u))
+
+		// other users have their own limit
+		require.True(t, a.Allow(&user.SignedInUser{OrgID: 1, UserID: 3}))
+		require.True(t, a.Allow(&user.SignedInUser{OrgID: 2, UserID: 2}))
+	})
+}
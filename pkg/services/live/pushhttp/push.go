@@ -3,6 +3,7 @@ package pushhttp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 
@@ -48,6 +49,11 @@ func (g *Gateway) Run(ctx context.Context) error {
 func (g *Gateway) Handle(ctx *models.ReqContext) {
 	streamID := web.Params(ctx.Req)[":streamId"]
 
+	if !g.GrafanaLive.PushAuthorizer.Allow(ctx.SignedInUser) {
+		ctx.Resp.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
 	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(ctx.SignedInUser.OrgID, liveDto.ScopeStream, streamID)
 	if err != nil {
 		logger.Error("Error getting stream", "error", err)
@@ -83,6 +89,20 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 		return
 	}
 
+	for _, mf := range metricFrames {
+		channel := fmt.Sprintf("%s/%s/%s", liveDto.ScopeStream, streamID, mf.Key())
+		allowed, err := g.GrafanaLive.PushAuthorizer.CanPublish(ctx.Req.Context(), ctx.SignedInUser, channel)
+		if err != nil {
+			logger.Error("Error checking publish permissions", "error", err, "channel", channel)
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			ctx.Resp.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
 	// TODO -- make sure all packets are combined together!
 	// interval = "1s" vs flush_interval = "5s"
 
@@ -101,6 +121,21 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 func (g *Gateway) HandlePipelinePush(ctx *models.ReqContext) {
 	channelID := web.Params(ctx.Req)["*"]
 
+	allowed, err := g.GrafanaLive.PushAuthorizer.CanPublish(ctx.Req.Context(), ctx.SignedInUser, channelID)
+	if err != nil {
+		logger.Error("Error checking publish permissions", "error", err, "channel", channelID)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if !g.GrafanaLive.PushAuthorizer.Allow(ctx.SignedInUser) {
+		ctx.Resp.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
 	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
 		logger.Error("Error reading body", "error", err)
@@ -6,20 +6,22 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/convert"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/live/pushauth"
 
 	"github.com/gorilla/websocket"
 )
 
 // PipelinePushHandler handles WebSocket client connections that push data to Live Pipeline.
 type PipelinePushHandler struct {
-	pipeline  *pipeline.Pipeline
-	config    Config
-	upgrade   *websocket.Upgrader
-	converter *convert.Converter
+	pipeline   *pipeline.Pipeline
+	authorizer *pushauth.Authorizer
+	config     Config
+	upgrade    *websocket.Upgrader
+	converter  *convert.Converter
 }
 
 // NewPathHandler creates new PipelinePushHandler.
-func NewPipelinePushHandler(pipeline *pipeline.Pipeline, c Config) *PipelinePushHandler {
+func NewPipelinePushHandler(pipeline *pipeline.Pipeline, authorizer *pushauth.Authorizer, c Config) *PipelinePushHandler {
 	if c.CheckOrigin == nil {
 		c.CheckOrigin = sameHostOriginCheck()
 	}
@@ -29,10 +31,11 @@ func NewPipelinePushHandler(pipeline *pipeline.Pipeline, c Config) *PipelinePush
 		CheckOrigin:     c.CheckOrigin,
 	}
 	return &PipelinePushHandler{
-		pipeline:  pipeline,
-		config:    c,
-		upgrade:   upgrade,
-		converter: convert.NewConverter(),
+		pipeline:   pipeline,
+		authorizer: authorizer,
+		config:     c,
+		upgrade:    upgrade,
+		converter:  convert.NewConverter(),
 	}
 }
 
@@ -51,6 +54,17 @@ func (s *PipelinePushHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	allowed, err := s.authorizer.CanPublish(r.Context(), user, channelID)
+	if err != nil {
+		logger.Error("Error checking publish permissions", "error", err, "channel", channelID)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	conn, err := s.upgrade.Upgrade(rw, r, nil)
 	if err != nil {
 		return
@@ -65,6 +79,11 @@ func (s *PipelinePushHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request)
 			break
 		}
 
+		if !s.authorizer.Allow(user) {
+			logger.Warn("Push rate limit exceeded, dropping message", "user", user.UserID, "channel", channelID)
+			continue
+		}
+
 		logger.Debug("Live channel push request",
 			"protocol", "http",
 			"channel", channelID,
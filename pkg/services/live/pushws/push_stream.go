@@ -1,11 +1,13 @@
 package pushws
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/services/live/convert"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/pushauth"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
 
 	"github.com/gorilla/websocket"
@@ -15,13 +17,14 @@ import (
 // Handler handles WebSocket client connections that push data to Live.
 type Handler struct {
 	managedStreamRunner *managedstream.Runner
+	authorizer          *pushauth.Authorizer
 	config              Config
 	upgrade             *websocket.Upgrader
 	converter           *convert.Converter
 }
 
 // NewHandler creates new Handler.
-func NewHandler(managedStreamRunner *managedstream.Runner, c Config) *Handler {
+func NewHandler(managedStreamRunner *managedstream.Runner, authorizer *pushauth.Authorizer, c Config) *Handler {
 	if c.CheckOrigin == nil {
 		c.CheckOrigin = sameHostOriginCheck()
 	}
@@ -32,6 +35,7 @@ func NewHandler(managedStreamRunner *managedstream.Runner, c Config) *Handler {
 	}
 	return &Handler{
 		managedStreamRunner: managedStreamRunner,
+		authorizer:          authorizer,
 		config:              c,
 		upgrade:             upgrade,
 		converter:           convert.NewConverter(),
@@ -60,6 +64,9 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	defer func() { _ = conn.Close() }()
 	setupWSConn(r.Context(), conn, s.config)
 
+	// permissions are checked once per channel of the connection.
+	allowedChannels := map[string]bool{}
+
 	for {
 		_, body, err := conn.ReadMessage()
 		if err != nil {
@@ -67,6 +74,11 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			break
 		}
 
+		if !s.authorizer.Allow(user) {
+			logger.Warn("Push rate limit exceeded, dropping message", "user", user.UserID, "streamId", streamID)
+			continue
+		}
+
 		stream, err := s.managedStreamRunner.GetOrCreateStream(user.OrgID, liveDto.ScopeStream, streamID)
 		if err != nil {
 			logger.Error("Error getting stream", "error", err)
@@ -91,6 +103,19 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		}
 
 		for _, mf := range metricFrames {
+			channel := fmt.Sprintf("%s/%s/%s", liveDto.ScopeStream, streamID, mf.Key())
+			if _, ok := allowedChannels[channel]; !ok {
+				allowed, err := s.authorizer.CanPublish(r.Context(), user, channel)
+				if err != nil {
+					logger.Error("Error checking publish permissions", "error", err, "channel", channel)
+					return
+				}
+				allowedChannels[channel] = allowed
+			}
+			if !allowedChannels[channel] {
+				logger.Warn("Push to channel not allowed, closing connection", "user", user.UserID, "channel", channel)
+				return
+			}
 			err := stream.Push(r.Context(), mf.Key(), mf.Frame())
 			if err != nil {
 				logger.Error("Error pushing frame", "error", err, "data", string(body))
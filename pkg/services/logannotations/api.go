@@ -0,0 +1,215 @@
+package logannotations
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *LogAnnotationService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/log-annotations", func(logAnnotations routing.RouteRegister) {
+		logAnnotations.Get("/jobs", routing.Wrap(s.getJobsHandler))
+		logAnnotations.Post("/jobs", middleware.ReqEditorRole, routing.Wrap(s.createJobHandler))
+		logAnnotations.Get("/jobs/:uid", routing.Wrap(s.getJobHandler))
+		logAnnotations.Put("/jobs/:uid", middleware.ReqEditorRole, routing.Wrap(s.updateJobHandler))
+		logAnnotations.Delete("/jobs/:uid", middleware.ReqEditorRole, routing.Wrap(s.deleteJobHandler))
+		logAnnotations.Post("/jobs/:uid/backfill", middleware.ReqEditorRole, routing.Wrap(s.backfillHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /log-annotations/jobs log_annotations getLogAnnotationJobs
+//
+// Get all log annotation jobs of the current organization.
+//
+// Responses:
+// 200: getLogAnnotationJobsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *LogAnnotationService) getJobsHandler(c *models.ReqContext) response.Response {
+	jobs, err := s.GetJobs(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, jobs)
+}
+
+// swagger:route GET /log-annotations/jobs/{job_uid} log_annotations getLogAnnotationJob
+//
+// Get a log annotation job by uid.
+//
+// Responses:
+// 200: logAnnotationJobResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *LogAnnotationService) getJobHandler(c *models.ReqContext) response.Response {
+	job, err := s.GetJob(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route POST /log-annotations/jobs log_annotations createLogAnnotationJob
+//
+// Create a log annotation job.
+//
+// Requires the editor role and the permission to query the data source of the job. The first run
+// materializes the log lines of the last interval.
+//
+// Responses:
+// 200: logAnnotationJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *LogAnnotationService) createJobHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateJobCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	if resp := annotationjobs.CheckDataSourceAccess(c, s.accessControl, cmd.DataSourceUID); resp != nil {
+		return resp
+	}
+	job, err := s.CreateJob(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route PUT /log-annotations/jobs/{job_uid} log_annotations updateLogAnnotationJob
+//
+// Replace the settings of a log annotation job.
+//
+// Requires the editor role and the permission to query the data source of the job.
+//
+// Responses:
+// 200: logAnnotationJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *LogAnnotationService) updateJobHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateJobCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	if resp := annotationjobs.CheckDataSourceAccess(c, s.accessControl, cmd.DataSourceUID); resp != nil {
+		return resp
+	}
+	job, err := s.UpdateJob(c.Req.Context(), web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route DELETE /log-annotations/jobs/{job_uid} log_annotations deleteLogAnnotationJob
+//
+// Delete a log annotation job.
+//
+// The annotations created by the job are kept. Requires the editor role.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *LogAnnotationService) deleteJobHandler(c *models.ReqContext) response.Response {
+	if err := s.DeleteJob(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("Log annotation job deleted")
+}
+
+// swagger:route POST /log-annotations/jobs/{job_uid}/backfill log_annotations backfillLogAnnotationJob
+//
+// Request the log lines of a past period to be materialized by a log annotation job.
+//
+// The backfill is materialized in the background, replacing a pending backfill of the job. Requires
+// the editor role and the permission to query the data source of the job.
+//
+// Responses:
+// 202: logAnnotationJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *LogAnnotationService) backfillHandler(c *models.ReqContext) response.Response {
+	cmd := BackfillCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.UID = web.Params(c.Req)[":uid"]
+	job, err := s.GetJob(c.Req.Context(), cmd.OrgID, cmd.UID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if resp := annotationjobs.CheckDataSourceAccess(c, s.accessControl, job.DataSourceUID); resp != nil {
+		return resp
+	}
+	job, err = s.Backfill(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusAccepted, job)
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		return response.Error(http.StatusNotFound, "Log annotation job not found", err)
+	case errors.Is(err, ErrJobExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidJob), errors.Is(err, ErrInvalidBackfill):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process log annotation job", err)
+}
+
+// swagger:parameters getLogAnnotationJob updateLogAnnotationJob deleteLogAnnotationJob backfillLogAnnotationJob
+type JobUIDParams struct {
+	// in:path
+	// required:true
+	JobUID string `json:"job_uid"`
+}
+
+// swagger:parameters createLogAnnotationJob updateLogAnnotationJob
+type CreateOrUpdateJobParams struct {
+	// in:body
+	// required:true
+	Body CreateOrUpdateJobCommand `json:"body"`
+}
+
+// swagger:parameters backfillLogAnnotationJob
+type BackfillParams struct {
+	// in:body
+	// required:true
+	Body BackfillCommand `json:"body"`
+}
+
+// swagger:response getLogAnnotationJobsResponse
+type GetJobsResponse struct {
+	// in:body
+	Body []*Job `json:"body"`
+}
+
+// swagger:response logAnnotationJobResponse
+type JobResponse struct {
+	// in:body
+	Body *Job `json:"body"`
+}
@@ -0,0 +1,496 @@
+// Package logannotations materializes log lines of Loki data sources as annotations, so that
+// important log events appear on dashboards, including for users who can't query the logs.
+//
+// Jobs run a LogQL query at an interval for the log lines written since the previous run, and
+// create an annotation for each line according to their mapping. Materialized lines are recorded,
+// so that runs and backfills overlapping the same period don't annotate a line twice.
+package logannotations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	scheduleTick = 10 * time.Second
+	// maxPeriod is the longest period of log lines materialized by a run. Lines of jobs which did
+	// not run for longer, e.g. because Grafana was stopped, are skipped.
+	maxPeriod       = 24 * time.Hour
+	cleanupInterval = time.Hour
+)
+
+type settings struct {
+	enabled bool
+	// delay is how long log lines are given to be ingested by the data source before they are
+	// materialized.
+	delay time.Duration
+	// maxLines limits the log lines returned by a query, the remaining lines are materialized by
+	// the following runs.
+	maxLines int
+	// dedupeRetention is how long materialized lines are recorded, which limits how far back
+	// backfills go.
+	dedupeRetention time.Duration
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("log_annotations")
+	s := settings{
+		enabled:         section.Key("enabled").MustBool(false),
+		delay:           time.Minute,
+		maxLines:        section.Key("max_lines_per_run").MustInt(1000),
+		dedupeRetention: 30 * 24 * time.Hour,
+	}
+	if d, err := gtime.ParseDuration(section.Key("delay").MustString("1m")); err == nil && d >= 0 {
+		s.delay = d
+	}
+	if d, err := gtime.ParseDuration(section.Key("dedupe_retention").MustString("30d")); err == nil && d >= maxPeriod {
+		s.dedupeRetention = d
+	}
+	if s.maxLines < 1 {
+		s.maxLines = 1
+	}
+	return s
+}
+
+type Service interface {
+	GetJobs(ctx context.Context, orgID int64) ([]*Job, error)
+	GetJob(ctx context.Context, orgID int64, uid string) (*Job, error)
+	CreateJob(ctx context.Context, cmd *CreateOrUpdateJobCommand) (*Job, error)
+	// UpdateJob replaces the settings of a job. Log lines are materialized from the end of the
+	// previous run, unless the data source of the job changes.
+	UpdateJob(ctx context.Context, uid string, cmd *CreateOrUpdateJobCommand) (*Job, error)
+	// DeleteJob deletes a job, the annotations it created are kept.
+	DeleteJob(ctx context.Context, orgID int64, uid string) error
+	// Backfill requests the log lines of a past period to be materialized by a job, replacing a
+	// pending backfill of the job.
+	Backfill(ctx context.Context, cmd *BackfillCommand) (*Job, error)
+}
+
+type LogAnnotationService struct {
+	settings           settings
+	store              db.DB
+	dataSourceService  datasources.DataSourceService
+	httpClientProvider httpclient.Provider
+	annotationsRepo    annotations.Repository
+	leaderElection     leaderelection.Service
+	routeRegister      routing.RouteRegister
+	accessControl      accesscontrol.AccessControl
+	log                log.Logger
+	now                func() time.Time
+	scheduler          *annotationjobs.Scheduler
+	lastCleanup        time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, dataSourceService datasources.DataSourceService,
+	httpClientProvider httpclient.Provider, annotationsRepo annotations.Repository, leaderElection leaderelection.Service,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *LogAnnotationService {
+	s := &LogAnnotationService{
+		settings:           readSettings(cfg),
+		store:              sqlStore,
+		dataSourceService:  dataSourceService,
+		httpClientProvider: httpClientProvider,
+		annotationsRepo:    annotationsRepo,
+		leaderElection:     leaderElection,
+		routeRegister:      routeRegister,
+		accessControl:      accessControl,
+		log:                log.New("log-annotations"),
+		now:                time.Now,
+		scheduler:          annotationjobs.NewScheduler(),
+	}
+	if s.settings.enabled {
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+func (s *LogAnnotationService) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+// Run executes the jobs at their interval and their pending backfills on the node elected as
+// leader.
+func (s *LogAnnotationService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !s.leaderElection.IsLeader(ctx, leaderelection.LeaseLogAnnotations) {
+				continue
+			}
+			s.tick(ctx)
+		}
+	}
+}
+
+func (s *LogAnnotationService) tick(ctx context.Context) {
+	if now := s.now(); now.Sub(s.lastCleanup) >= cleanupInterval {
+		s.lastCleanup = now
+		if err := s.deleteExpiredLines(ctx, now.Add(-s.settings.dedupeRetention)); err != nil {
+			s.log.Error("Failed to delete expired materialized log lines", "error", err)
+		}
+	}
+
+	jobs, err := s.dueJobs(ctx)
+	if err != nil {
+		s.log.Error("Failed to get the log annotation jobs", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		s.execute(ctx, job)
+	}
+
+	// backfills are materialized one period per tick, so that they don't delay the runs of other
+	// jobs
+	jobs, err = s.listBackfillJobs(ctx)
+	if err != nil {
+		s.log.Error("Failed to get the log annotation backfills", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		s.backfill(ctx, job)
+	}
+}
+
+// dueJobs returns the enabled jobs which did not run within their interval, and marks them as run.
+func (s *LogAnnotationService) dueJobs(ctx context.Context) ([]*Job, error) {
+	jobs, err := annotationjobs.ListEnabledJobs[Job](ctx, s.store)
+	if err != nil {
+		return nil, err
+	}
+	return annotationjobs.DueJobs(s.scheduler, s.now(), jobs, (*Job).schedule), nil
+}
+
+// execute materializes the log lines written since the previous run of a job, and records the
+// outcome.
+func (s *LogAnnotationService) execute(ctx context.Context, job *Job) {
+	to := s.now().Add(-s.settings.delay)
+	from := job.MaterializedUntil
+	if from.IsZero() {
+		from = to.Add(-job.interval())
+	}
+	if to.Sub(from) > maxPeriod {
+		s.log.Warn("Skipping log lines of a log annotation job which did not run for too long", "org", job.OrgID, "uid", job.UID, "from", from)
+		from = to.Add(-maxPeriod)
+	}
+	if !to.After(from) {
+		return
+	}
+
+	until, count, err := s.materialize(ctx, job, from, to)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		s.log.Warn("Failed to materialize log lines as annotations", "org", job.OrgID, "uid", job.UID, "error", err)
+		err = s.updateRun(ctx, job, job.MaterializedUntil, annotationjobs.Truncate(err.Error(), maxErrorLength))
+	} else {
+		s.log.Debug("Materialized log lines as annotations", "org", job.OrgID, "uid", job.UID, "count", count)
+		err = s.updateRun(ctx, job, until, "")
+	}
+	if err != nil {
+		s.log.Error("Failed to update log annotation job", "org", job.OrgID, "uid", job.UID, "error", err)
+	}
+}
+
+// backfill materializes the next period of the pending backfill of a job. A failed backfill is
+// cancelled, and its error recorded as the error of the job.
+func (s *LogAnnotationService) backfill(ctx context.Context, job *Job) {
+	if job.BackfillFrom == nil || job.BackfillUntil == nil {
+		return
+	}
+	from, to := *job.BackfillFrom, *job.BackfillUntil
+	if to.Sub(from) > maxPeriod {
+		to = from.Add(maxPeriod)
+	}
+
+	until, count, err := s.materialize(ctx, job, from, to)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		s.log.Warn("Failed to backfill log lines as annotations", "org", job.OrgID, "uid", job.UID, "error", err)
+		if err := s.updateRun(ctx, job, job.MaterializedUntil, annotationjobs.Truncate("backfill failed: "+err.Error(), maxErrorLength)); err != nil {
+			s.log.Error("Failed to update log annotation job", "org", job.OrgID, "uid", job.UID, "error", err)
+		}
+		until = *job.BackfillUntil
+	} else {
+		s.log.Debug("Backfilled log lines as annotations", "org", job.OrgID, "uid", job.UID, "count", count, "until", until)
+	}
+
+	var next *time.Time
+	if until.Before(*job.BackfillUntil) {
+		next = &until
+	}
+	if err := s.updateBackfill(ctx, job, next, job.BackfillUntil); err != nil {
+		s.log.Error("Failed to update log annotation backfill", "org", job.OrgID, "uid", job.UID, "error", err)
+	}
+}
+
+// materialize creates the annotations of the log lines of a job written in the period [from, to)
+// which were not materialized before, and returns the end of the period they were materialized
+// for. It ends before to if the query returned the maximum number of lines.
+func (s *LogAnnotationService) materialize(ctx context.Context, job *Job, from, to time.Time) (time.Time, int, error) {
+	ds, err := s.getDataSource(ctx, job.OrgID, job.DataSourceUID)
+	if err != nil {
+		return from, 0, err
+	}
+	var dashboardID int64
+	if job.Mapping.DashboardUID != "" {
+		if dashboardID, err = annotationjobs.GetDashboardID(ctx, s.store, job.OrgID, job.Mapping.DashboardUID); err != nil {
+			return from, 0, err
+		}
+	}
+	lines, err := s.fetchLines(ctx, ds, job.Query, from, to)
+	if err != nil {
+		return from, 0, err
+	}
+
+	until := to
+	if len(lines) >= s.settings.maxLines {
+		// the lines of the last timestamp may be incomplete, so the next period starts with it and
+		// the returned lines of the timestamp are skipped as duplicates
+		until = lines[len(lines)-1].Time
+		if !until.After(from) {
+			s.log.Warn("Skipping log lines of a timestamp exceeding the maximum lines per run", "org", job.OrgID, "uid", job.UID, "time", from)
+			until = from.Add(time.Nanosecond)
+		}
+	}
+	if len(lines) == 0 {
+		return until, 0, nil
+	}
+
+	seen, err := s.getLineHashes(ctx, job, from, lines[len(lines)-1].Time)
+	if err != nil {
+		return from, 0, err
+	}
+	text, err := template.New("text").Parse(job.Mapping.Text)
+	if err != nil {
+		return from, 0, err
+	}
+	items := make([]annotations.Item, 0, len(lines))
+	keys := make([]*line, 0, len(lines))
+	for _, l := range lines {
+		hash := hashLine(l)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		items = append(items, newAnnotation(job, l, text, dashboardID))
+		keys = append(keys, &line{OrgID: job.OrgID, JobID: job.ID, Hash: hash, Time: l.Time})
+	}
+	if len(items) == 0 {
+		return until, 0, nil
+	}
+	if err := s.annotationsRepo.SaveMany(ctx, items); err != nil {
+		return from, 0, err
+	}
+	// the lines are recorded after their annotations were saved, so that a failure can at worst
+	// annotate them twice rather than never
+	if err := s.insertLines(ctx, keys); err != nil {
+		return from, 0, err
+	}
+	return until, len(items), nil
+}
+
+func (s *LogAnnotationService) getDataSource(ctx context.Context, orgID int64, uid string) (*datasources.DataSource, error) {
+	query := &datasources.GetDataSourceQuery{OrgId: orgID, Uid: uid}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return nil, fmt.Errorf("data source %s not found", uid)
+		}
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (s *LogAnnotationService) fetchLines(ctx context.Context, ds *datasources.DataSource, query string, from, to time.Time) ([]LogLine, error) {
+	if ds.Type != DataSourceTypeLoki {
+		return nil, fmt.Errorf("data source %s is not a Loki data source", ds.Uid)
+	}
+	transport, err := s.dataSourceService.GetHTTPTransport(ctx, ds, s.httpClientProvider)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: transport, Timeout: requestTimeout}
+	return fetchLokiLines(ctx, client, ds.Url, query, from, to, s.settings.maxLines)
+}
+
+// newAnnotation returns the annotation of a log line, linked to the job with its data.
+func newAnnotation(job *Job, l LogLine, text *template.Template, dashboardID int64) annotations.Item {
+	item := annotations.Item{
+		OrgId:       job.OrgID,
+		DashboardId: dashboardID,
+		PanelId:     job.Mapping.PanelID,
+		Epoch:       l.Time.UnixMilli(),
+		EpochEnd:    l.Time.UnixMilli(),
+		Text:        renderText(text, l),
+		Tags:        append([]string{Tag}, job.Mapping.Tags...),
+		Data: simplejson.NewFromAny(map[string]interface{}{
+			"labels":        l.Labels,
+			"datasourceUid": job.DataSourceUID,
+			"jobUid":        job.UID,
+		}),
+	}
+	for _, key := range job.Mapping.TagLabels {
+		if value, ok := l.Labels[key]; ok && value != "" {
+			item.Tags = append(item.Tags, key+":"+annotationjobs.Truncate(value, maxTagValueLength))
+		}
+	}
+	return item
+}
+
+// renderText executes the text template of a mapping, falling back to the log line if it fails.
+func renderText(text *template.Template, l LogLine) string {
+	var buf bytes.Buffer
+	if err := text.Execute(&buf, l); err != nil || buf.Len() == 0 {
+		return annotationjobs.Truncate(l.Line, maxTextLength)
+	}
+	return annotationjobs.Truncate(buf.String(), maxTextLength)
+}
+
+func (s *LogAnnotationService) GetJobs(ctx context.Context, orgID int64) ([]*Job, error) {
+	return s.listJobs(ctx, orgID)
+}
+
+func (s *LogAnnotationService) GetJob(ctx context.Context, orgID int64, uid string) (*Job, error) {
+	return s.getJob(ctx, orgID, uid)
+}
+
+// validate checks a command against the type of its data source and the dashboard of its mapping.
+func (s *LogAnnotationService) validate(ctx context.Context, cmd *CreateOrUpdateJobCommand) error {
+	ds, err := s.getDataSource(ctx, cmd.OrgID, cmd.DataSourceUID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidJob, err)
+	}
+	if err := cmd.validate(ds.Type); err != nil {
+		return err
+	}
+	if cmd.Mapping.DashboardUID != "" {
+		if _, err := annotationjobs.GetDashboardID(ctx, s.store, cmd.OrgID, cmd.Mapping.DashboardUID); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidJob, err)
+		}
+	}
+	return nil
+}
+
+func (s *LogAnnotationService) CreateJob(ctx context.Context, cmd *CreateOrUpdateJobCommand) (*Job, error) {
+	if err := s.validate(ctx, cmd); err != nil {
+		return nil, err
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+
+	now := s.now()
+	job := &Job{
+		OrgID:   cmd.OrgID,
+		UID:     cmd.UID,
+		Created: now,
+	}
+	applyCommand(job, cmd, now)
+	if err := s.insertJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *LogAnnotationService) UpdateJob(ctx context.Context, uid string, cmd *CreateOrUpdateJobCommand) (*Job, error) {
+	cmd.UID = ""
+	if err := s.validate(ctx, cmd); err != nil {
+		return nil, err
+	}
+	job, err := s.getJob(ctx, cmd.OrgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.DataSourceUID != cmd.DataSourceUID {
+		job.MaterializedUntil = time.Time{}
+		job.BackfillFrom, job.BackfillUntil = nil, nil
+	}
+	applyCommand(job, cmd, s.now())
+	if err := s.updateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func applyCommand(job *Job, cmd *CreateOrUpdateJobCommand, now time.Time) {
+	job.Name = cmd.Name
+	job.DataSourceUID = cmd.DataSourceUID
+	job.Query = cmd.Query
+	job.Interval = cmd.Interval
+	job.Mapping = cmd.Mapping
+	job.Enabled = cmd.Enabled == nil || *cmd.Enabled
+	job.LastError = ""
+	job.Updated = now
+}
+
+func (s *LogAnnotationService) DeleteJob(ctx context.Context, orgID int64, uid string) error {
+	return s.deleteJob(ctx, orgID, uid)
+}
+
+func (s *LogAnnotationService) Backfill(ctx context.Context, cmd *BackfillCommand) (*Job, error) {
+	job, err := s.getJob(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Enabled {
+		return nil, fmt.Errorf("%w: the job is disabled", ErrInvalidBackfill)
+	}
+
+	now := s.now()
+	// later log lines are materialized by the runs of the job
+	latest := job.MaterializedUntil
+	if latest.IsZero() {
+		latest = now.Add(-s.settings.delay)
+	}
+	from, to := cmd.From, cmd.To
+	if to.IsZero() || to.After(latest) {
+		to = latest
+	}
+	if from.IsZero() || !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before %s", ErrInvalidBackfill, to.UTC().Format(time.RFC3339))
+	}
+	// materialized lines are only recorded for the dedupe retention, older lines could be
+	// annotated twice
+	if earliest := now.Add(-s.settings.dedupeRetention); from.Before(earliest) {
+		return nil, fmt.Errorf("%w: from must be after %s", ErrInvalidBackfill, earliest.UTC().Format(time.RFC3339))
+	}
+
+	if err := s.updateBackfill(ctx, job, &from, &to); err != nil {
+		return nil, err
+	}
+	job.BackfillFrom, job.BackfillUntil = &from, &to
+	return job, nil
+}
@@ -0,0 +1,284 @@
+package logannotations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"text/template"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCreateOrUpdateJobCommandValidate(t *testing.T) {
+	valid := func() *CreateOrUpdateJobCommand {
+		return &CreateOrUpdateJobCommand{Name: " Panics ", DataSourceUID: "loki", Query: ` {app="shop"} |= "panic" `}
+	}
+
+	cmd := valid()
+	require.NoError(t, cmd.validate(DataSourceTypeLoki))
+	assert.Equal(t, "Panics", cmd.Name)
+	assert.Equal(t, `{app="shop"} |= "panic"`, cmd.Query)
+	assert.Equal(t, defaultInterval, cmd.Interval)
+	assert.Equal(t, defaultText, cmd.Mapping.Text)
+
+	for name, modify := range map[string]func(*CreateOrUpdateJobCommand){
+		"empty name":              func(c *CreateOrUpdateJobCommand) { c.Name = " " },
+		"uid longer than 40ch":    func(c *CreateOrUpdateJobCommand) { c.UID = string(make([]byte, 41)) },
+		"empty query":             func(c *CreateOrUpdateJobCommand) { c.Query = " " },
+		"metric query":            func(c *CreateOrUpdateJobCommand) { c.Query = `rate({app="shop"}[1m])` },
+		"interval too short":      func(c *CreateOrUpdateJobCommand) { c.Interval = "10s" },
+		"interval too long":       func(c *CreateOrUpdateJobCommand) { c.Interval = "2d" },
+		"invalid text template":   func(c *CreateOrUpdateJobCommand) { c.Mapping.Text = "{{.Line" },
+		"too many tags":           func(c *CreateOrUpdateJobCommand) { c.Mapping.Tags = make([]string, maxTags+1) },
+		"too many labels":         func(c *CreateOrUpdateJobCommand) { c.Mapping.TagLabels = make([]string, maxLabelTags+1) },
+		"panel without dashboard": func(c *CreateOrUpdateJobCommand) { c.Mapping.PanelID = 2 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := valid()
+			modify(cmd)
+			assert.ErrorIs(t, cmd.validate(DataSourceTypeLoki), ErrInvalidJob)
+		})
+	}
+
+	assert.ErrorIs(t, valid().validate("prometheus"), ErrInvalidJob)
+}
+
+func TestFetchLokiLines(t *testing.T) {
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		switch r.URL.Query().Get("query") {
+		case "matrix":
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "matrix", "result": []}}`))
+		default:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "streams", "result": [
+				{"stream": {"app": "shop", "level": "error"}, "values": [["1667304002000000000", "panic: b"], ["1667304003000000000", "panic: d"]]},
+				{"stream": {"app": "shop", "level": "warn"}, "values": [["1667304001000000000", "panic: a"]]}
+			]}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	lines, err := fetchLokiLines(ctx, server.Client(), server.URL+"/", `{app="shop"}`, start, start.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{app="shop"}`}, query["query"])
+	assert.Equal(t, []string{"1667304000000000000"}, query["start"])
+	assert.Equal(t, []string{"1667304060000000000"}, query["end"])
+	assert.Equal(t, []string{"10"}, query["limit"])
+	assert.Equal(t, []string{"forward"}, query["direction"])
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"panic: a", "panic: b", "panic: d"}, []string{lines[0].Line, lines[1].Line, lines[2].Line})
+	assert.True(t, start.Add(time.Second).Equal(lines[0].Time))
+	assert.Equal(t, map[string]string{"app": "shop", "level": "warn"}, lines[0].Labels)
+
+	_, err = fetchLokiLines(ctx, server.Client(), server.URL, "matrix", start, start.Add(time.Minute), 10)
+	assert.ErrorIs(t, err, errNotLogQuery)
+}
+
+func TestHashLine(t *testing.T) {
+	at := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	l := LogLine{Time: at, Line: "panic", Labels: map[string]string{"app": "shop", "level": "error"}}
+
+	hash := hashLine(l)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, hashLine(LogLine{Time: at.In(time.Local), Line: "panic", Labels: map[string]string{"level": "error", "app": "shop"}}))
+	assert.NotEqual(t, hash, hashLine(LogLine{Time: at.Add(time.Nanosecond), Line: "panic", Labels: l.Labels}))
+	assert.NotEqual(t, hash, hashLine(LogLine{Time: at, Line: "panic!", Labels: l.Labels}))
+	assert.NotEqual(t, hash, hashLine(LogLine{Time: at, Line: "panic", Labels: map[string]string{"app": "shop"}}))
+}
+
+func TestNewAnnotation(t *testing.T) {
+	at := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	l := LogLine{Time: at, Line: "panic: nil map", Labels: map[string]string{"app": "shop", "pod": "shop-1", "empty": ""}}
+	job := &Job{OrgID: 1, UID: "panics", DataSourceUID: "loki", Mapping: LineMapping{
+		Text: `{{index .Labels "pod"}}: {{.Line}}`, Tags: []string{"panic"}, TagLabels: []string{"pod", "empty", "missing"},
+	}}
+
+	item := newAnnotation(job, l, template.Must(template.New("text").Parse(job.Mapping.Text)), 3)
+	assert.Equal(t, "shop-1: panic: nil map", item.Text)
+	assert.Equal(t, []string{Tag, "panic", "pod:shop-1"}, item.Tags)
+	assert.Equal(t, int64(3), item.DashboardId)
+	assert.Equal(t, at.UnixMilli(), item.Epoch)
+	assert.Equal(t, at.UnixMilli(), item.EpochEnd)
+	assert.Equal(t, "panics", item.Data.Get("jobUid").MustString())
+	assert.Equal(t, l.Labels, item.Data.Get("labels").Interface())
+
+	item = newAnnotation(job, l, template.Must(template.New("text").Parse("{{.Missing}}")), 0)
+	assert.Equal(t, "panic: nil map", item.Text)
+}
+
+func TestIntegrationLogAnnotations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the same lines are logged for all queries, of which those in the period are returned
+		// like Loki does, oldest first up to the limit
+		from, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+		to, _ := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		type entry struct {
+			pod string
+			ts  int64
+		}
+		entries := make([]entry, 0)
+		for i, offset := range []time.Duration{-240 * time.Second, -200 * time.Second, -90 * time.Second, -80 * time.Second, -30 * time.Second} {
+			ts := start.Add(offset).UnixNano()
+			if ts >= from && ts < to {
+				entries = append(entries, entry{pod: "shop-" + strconv.Itoa(i%2), ts: ts})
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].ts < entries[j].ts })
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+		streams := map[string][][2]string{}
+		for _, e := range entries {
+			streams[e.pod] = append(streams[e.pod], [2]string{strconv.FormatInt(e.ts, 10), "panic"})
+		}
+		result := make([]interface{}, 0)
+		for pod, values := range streams {
+			result = append(result, map[string]interface{}{"stream": map[string]string{"app": "shop", "pod": pod}, "values": values})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"resultType": "streams", "result": result}})
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	repo := annotationstest.NewFakeAnnotationsRepo()
+	dataSources := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{OrgId: 1, Uid: "loki", Type: DataSourceTypeLoki, Url: server.URL},
+		{OrgId: 1, Uid: "prom", Type: "prometheus"},
+	}}
+	s := ProvideService(cfg, sqlStore, dataSources, httpclient.NewProvider(sdkhttpclient.ProviderOptions{}), repo,
+		leaderelection.ProvideService(cfg, sqlStore, routing.NewRouteRegister()), routing.NewRouteRegister(), actest.FakeAccessControl{})
+	s.now = func() time.Time { return now }
+
+	job, err := s.CreateJob(ctx, &CreateOrUpdateJobCommand{OrgID: 1, Name: "Panics", DataSourceUID: "loki", Query: `{app="shop"} |= "panic"`})
+	require.NoError(t, err)
+
+	_, err = s.CreateJob(ctx, &CreateOrUpdateJobCommand{OrgID: 1, UID: job.UID, Name: "Panics", DataSourceUID: "loki", Query: `{app="shop"}`})
+	assert.ErrorIs(t, err, ErrJobExists)
+	_, err = s.CreateJob(ctx, &CreateOrUpdateJobCommand{OrgID: 1, Name: "Panics", DataSourceUID: "prom", Query: `{app="shop"}`})
+	assert.ErrorIs(t, err, ErrInvalidJob)
+
+	t.Run("first run materializes the lines of the last interval", func(t *testing.T) {
+		jobs, err := s.dueJobs(ctx)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		s.execute(ctx, jobs[0])
+
+		// the period is [now - 2m, now - 1m) with the default delay of a minute
+		assert.Equal(t, 2, repo.Len())
+		job, err = s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		assert.True(t, now.Add(-time.Minute).Equal(job.MaterializedUntil), job.MaterializedUntil)
+		assert.Empty(t, job.LastError)
+	})
+
+	t.Run("invalid backfills", func(t *testing.T) {
+		_, err := s.Backfill(ctx, &BackfillCommand{OrgID: 1, UID: job.UID, From: now.Add(-31 * 24 * time.Hour)})
+		assert.ErrorIs(t, err, ErrInvalidBackfill)
+		_, err = s.Backfill(ctx, &BackfillCommand{OrgID: 1, UID: job.UID, From: now})
+		assert.ErrorIs(t, err, ErrInvalidBackfill)
+		_, err = s.Backfill(ctx, &BackfillCommand{OrgID: 1, UID: "missing", From: now.Add(-time.Hour)})
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("backfill materializes the lines not materialized before", func(t *testing.T) {
+		s.settings.maxLines = 2
+		t.Cleanup(func() { s.settings.maxLines = 1000 })
+
+		job, err = s.Backfill(ctx, &BackfillCommand{OrgID: 1, UID: job.UID, From: now.Add(-5 * time.Minute)})
+		require.NoError(t, err)
+		require.NotNil(t, job.BackfillUntil)
+		assert.True(t, job.MaterializedUntil.Equal(*job.BackfillUntil))
+
+		// the backfill takes several ticks as the queries return at most 2 lines
+		for i := 0; i < 10; i++ {
+			jobs, err := s.listBackfillJobs(ctx)
+			require.NoError(t, err)
+			if len(jobs) == 0 {
+				break
+			}
+			s.backfill(ctx, jobs[0])
+		}
+		job, err = s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		assert.Nil(t, job.BackfillFrom)
+		assert.Nil(t, job.BackfillUntil)
+		assert.Equal(t, 4, repo.Len())
+	})
+
+	t.Run("next run materializes the lines since the previous run", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		s.execute(ctx, job)
+		assert.Equal(t, 5, repo.Len())
+
+		// a run of the same period doesn't annotate the lines again
+		require.NoError(t, s.updateRun(ctx, job, time.Time{}, ""))
+		job, err = s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		s.execute(ctx, job)
+		assert.Equal(t, 5, repo.Len())
+	})
+
+	t.Run("failed run is recorded", func(t *testing.T) {
+		dataSources.DataSources[0].Url = server.URL + "/missing/%"
+		now = now.Add(time.Minute)
+		job, err = s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		s.execute(ctx, job)
+
+		failed, err := s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, failed.LastError)
+		assert.True(t, job.MaterializedUntil.Equal(failed.MaterializedUntil))
+	})
+
+	t.Run("expired lines are deleted", func(t *testing.T) {
+		seen, err := s.getLineHashes(ctx, job, start.Add(-time.Hour), start)
+		require.NoError(t, err)
+		assert.Len(t, seen, 5)
+
+		require.NoError(t, s.deleteExpiredLines(ctx, start.Add(-time.Minute)))
+		seen, err = s.getLineHashes(ctx, job, start.Add(-time.Hour), start)
+		require.NoError(t, err)
+		assert.Len(t, seen, 1)
+	})
+
+	t.Run("deleted job", func(t *testing.T) {
+		require.NoError(t, s.DeleteJob(ctx, 1, job.UID))
+		assert.ErrorIs(t, s.DeleteJob(ctx, 1, job.UID), ErrJobNotFound)
+		jobs, err := s.GetJobs(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+		seen, err := s.getLineHashes(ctx, job, start.Add(-time.Hour), start)
+		require.NoError(t, err)
+		assert.Empty(t, seen)
+	})
+}
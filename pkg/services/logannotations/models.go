@@ -0,0 +1,191 @@
+package logannotations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+const (
+	DataSourceTypeLoki = "loki"
+
+	// Tag is added to all annotations created from log lines.
+	Tag = "log"
+
+	maxNameLength  = 190
+	maxTags        = 10
+	maxLabelTags   = 5
+	maxQueryLength = 2000
+	// maxTagValueLength limits the values of labels added as tags, so that the tags of an
+	// annotation fit the tags column.
+	maxTagValueLength = 50
+	minInterval       = 30 * time.Second
+	maxInterval       = 24 * time.Hour
+	defaultInterval   = "1m"
+	defaultText       = "{{.Line}}"
+	maxErrorLength    = 255
+	maxTextLength     = 2000
+)
+
+var (
+	ErrJobNotFound     = errors.New("log annotation job not found")
+	ErrInvalidJob      = errors.New("invalid log annotation job")
+	ErrJobExists       = errors.New("a log annotation job with the same uid already exists")
+	ErrInvalidBackfill = errors.New("invalid log annotation backfill")
+)
+
+// Job periodically materializes the log lines returned by a LogQL query of a Loki data source as
+// annotations.
+// swagger:model LogAnnotationJob
+type Job struct {
+	ID            int64  `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID         int64  `json:"orgId" xorm:"org_id"`
+	UID           string `json:"uid" xorm:"uid"`
+	Name          string `json:"name" xorm:"name"`
+	DataSourceUID string `json:"dataSourceUid" xorm:"data_source_uid"`
+	// LogQL query returning log lines, e.g. `{app="shop"} |= "panic"`.
+	Query string `json:"query" xorm:"query"`
+	// How often new log lines are materialized, e.g. `1m` or `1h`.
+	Interval string      `json:"interval" xorm:"run_interval"`
+	Mapping  LineMapping `json:"mapping" xorm:"jsonb mapping"`
+	Enabled  bool        `json:"enabled" xorm:"enabled"`
+	// MaterializedUntil is the end of the period up to which log lines were materialized.
+	MaterializedUntil time.Time `json:"materializedUntil" xorm:"materialized_until"`
+	// BackfillFrom and BackfillUntil are the period of a requested backfill which is still to be
+	// materialized, nil if there is none.
+	BackfillFrom  *time.Time `json:"backfillFrom,omitempty" xorm:"backfill_from"`
+	BackfillUntil *time.Time `json:"backfillUntil,omitempty" xorm:"backfill_until"`
+	// LastError is the error of the last run, empty if it succeeded.
+	LastError string    `json:"lastError,omitempty" xorm:"last_error"`
+	Created   time.Time `json:"created" xorm:"created"`
+	Updated   time.Time `json:"updated" xorm:"updated"`
+}
+
+func (j Job) TableName() string {
+	return "log_annotation_job"
+}
+
+func (j *Job) interval() time.Duration {
+	d, _ := gtime.ParseDuration(j.Interval)
+	return d
+}
+
+func (j *Job) schedule() (int64, time.Duration) {
+	return j.ID, j.interval()
+}
+
+// LineMapping configures the annotations created from log lines.
+type LineMapping struct {
+	// Text is a Go template of the text of annotations, executed with the log line. Defaults to
+	// `{{.Line}}`.
+	Text string `json:"text"`
+	// Tags added to all annotations, besides `log`.
+	Tags []string `json:"tags,omitempty"`
+	// TagLabels are the labels of the stream added as `key:value` tags.
+	TagLabels []string `json:"tagLabels,omitempty"`
+	// DashboardUID of the dashboard the annotations are added to, organization annotations are
+	// created if empty.
+	DashboardUID string `json:"dashboardUid,omitempty"`
+	PanelID      int64  `json:"panelId,omitempty"`
+}
+
+// LogLine is a log line returned by the query of a job, as passed to the text template of the
+// mapping.
+type LogLine struct {
+	Time   time.Time
+	Line   string
+	Labels map[string]string
+}
+
+// line is the dedupe key of a materialized log line.
+type line struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	OrgID int64  `xorm:"org_id"`
+	JobID int64  `xorm:"job_id"`
+	Hash  string `xorm:"line_hash"`
+	// Time of the log line.
+	Time time.Time `xorm:"line_time"`
+}
+
+func (l line) TableName() string {
+	return "log_annotation_line"
+}
+
+// CreateOrUpdateJobCommand creates a job, or replaces the settings of a job.
+// swagger:model
+type CreateOrUpdateJobCommand struct {
+	OrgID int64 `json:"-"`
+	// UID of a new job, generated if empty.
+	UID           string      `json:"uid"`
+	Name          string      `json:"name"`
+	DataSourceUID string      `json:"dataSourceUid"`
+	Query         string      `json:"query"`
+	Interval      string      `json:"interval"`
+	Mapping       LineMapping `json:"mapping"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+// validate checks the command for a data source of the given type.
+func (cmd *CreateOrUpdateJobCommand) validate(dataSourceType string) error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if cmd.Name == "" || utf8.RuneCountInString(cmd.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be between 1 and %d characters", ErrInvalidJob, maxNameLength)
+	}
+	if len(cmd.UID) > 40 {
+		return fmt.Errorf("%w: uid must be at most 40 characters", ErrInvalidJob)
+	}
+	if dataSourceType != DataSourceTypeLoki {
+		return fmt.Errorf("%w: the data source must be a Loki data source", ErrInvalidJob)
+	}
+	cmd.Query = strings.TrimSpace(cmd.Query)
+	// metric queries are only rejected by the first run, as they can't be told apart without
+	// parsing them
+	if !strings.HasPrefix(cmd.Query, "{") || utf8.RuneCountInString(cmd.Query) > maxQueryLength {
+		return fmt.Errorf("%w: query must be a LogQL log query of at most %d characters", ErrInvalidJob, maxQueryLength)
+	}
+
+	if cmd.Interval == "" {
+		cmd.Interval = defaultInterval
+	}
+	interval, err := gtime.ParseDuration(cmd.Interval)
+	if err != nil || interval < minInterval || interval > maxInterval {
+		return fmt.Errorf("%w: interval must be between %s and %s", ErrInvalidJob, minInterval, maxInterval)
+	}
+
+	return cmd.Mapping.validate()
+}
+
+func (m *LineMapping) validate() error {
+	if strings.TrimSpace(m.Text) == "" {
+		m.Text = defaultText
+	}
+	if _, err := template.New("text").Parse(m.Text); err != nil {
+		return fmt.Errorf("%w: invalid text template: %s", ErrInvalidJob, err)
+	}
+	if len(m.Tags) > maxTags {
+		return fmt.Errorf("%w: a mapping has at most %d tags", ErrInvalidJob, maxTags)
+	}
+	if len(m.TagLabels) > maxLabelTags {
+		return fmt.Errorf("%w: at most %d labels are added as tags", ErrInvalidJob, maxLabelTags)
+	}
+	if m.PanelID != 0 && m.DashboardUID == "" {
+		return fmt.Errorf("%w: the panel of annotations needs a dashboard", ErrInvalidJob)
+	}
+	return nil
+}
+
+// BackfillCommand requests the log lines of a past period to be materialized.
+// swagger:model
+type BackfillCommand struct {
+	OrgID int64     `json:"-"`
+	UID   string    `json:"-"`
+	From  time.Time `json:"from"`
+	// To defaults to, and is at most, the end of the period materialized by the runs of the job.
+	To time.Time `json:"to"`
+}
@@ -0,0 +1,94 @@
+package logannotations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+)
+
+var errNotLogQuery = errors.New("the query must return log lines, metric queries are not supported")
+
+// fetchLokiLines returns the log lines matched by a LogQL query in the period [from, to), oldest
+// first. At most limit lines are returned.
+func fetchLokiLines(ctx context.Context, client *http.Client, baseURL string, query string, from, to time.Time, limit int) ([]LogLine, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(from.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(to.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("direction", "forward")
+
+	var resp struct {
+		Data struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := annotationjobs.GetJSON(ctx, client, strings.TrimSuffix(baseURL, "/")+"/loki/api/v1/query_range?"+params.Encode(), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.ResultType != "streams" {
+		return nil, errNotLogQuery
+	}
+
+	var streams []struct {
+		Stream map[string]string `json:"stream"`
+		// Values are pairs of a timestamp in nanoseconds and a log line.
+		Values [][2]string `json:"values"`
+	}
+	if err := json.Unmarshal(resp.Data.Result, &streams); err != nil {
+		return nil, fmt.Errorf("failed to read the response of the data source: %w", err)
+	}
+
+	lines := make([]LogLine, 0)
+	for _, stream := range streams {
+		for _, value := range stream.Values {
+			ts, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the response of the data source: invalid timestamp %q", value[0])
+			}
+			lines = append(lines, LogLine{
+				Time:   time.Unix(0, ts),
+				Line:   value[1],
+				Labels: stream.Stream,
+			})
+		}
+	}
+	// the lines of each stream are ordered, but not the lines of different streams
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time.Before(lines[j].Time)
+	})
+	return lines, nil
+}
+
+// hashLine returns the dedupe key of a log line, which identifies it by its stream, timestamp and
+// content like Loki does.
+func hashLine(l LogLine) string {
+	keys := make([]string, 0, len(l.Labels))
+	for key := range l.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(h, "%q=%q,", key, l.Labels[key])
+	}
+	_, _ = fmt.Fprintf(h, "\n%d\n%s", l.Time.UnixNano(), l.Line)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,152 @@
+package logannotations
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+)
+
+// lineBatchSize limits the materialized lines inserted by a statement.
+const lineBatchSize = 100
+
+func (s *LogAnnotationService) insertJob(ctx context.Context, job *Job) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", job.OrgID, job.UID).Exist(&Job{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrJobExists
+		}
+		_, err = sess.Insert(job)
+		return err
+	})
+}
+
+func (s *LogAnnotationService) updateJob(ctx context.Context, job *Job) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ?", job.OrgID).ID(job.ID).
+			Cols("name", "data_source_uid", "query", "run_interval", "mapping", "enabled", "materialized_until",
+				"backfill_from", "backfill_until", "last_error", "updated").
+			Update(job)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrJobNotFound
+		}
+		return nil
+	})
+}
+
+// updateRun records the outcome of a run of a job.
+func (s *LogAnnotationService) updateRun(ctx context.Context, job *Job, materializedUntil time.Time, lastError string) error {
+	return annotationjobs.UpdateRun(ctx, s.store, job.OrgID, job.ID, "materialized_until", &Job{MaterializedUntil: materializedUntil, LastError: lastError})
+}
+
+// updateBackfill records the period of the backfill of a job which is still to be materialized,
+// from is nil once the backfill is complete.
+func (s *LogAnnotationService) updateBackfill(ctx context.Context, job *Job, from, until *time.Time) error {
+	if from == nil {
+		until = nil
+	}
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ?", job.OrgID).ID(job.ID).Cols("backfill_from", "backfill_until").
+			Update(&Job{BackfillFrom: from, BackfillUntil: until})
+		return err
+	})
+}
+
+// deleteJob deletes a job with its materialized lines.
+func (s *LogAnnotationService) deleteJob(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		job := &Job{}
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(job)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrJobNotFound
+		}
+		if _, err := sess.Where("job_id = ?", job.ID).Delete(&line{}); err != nil {
+			return err
+		}
+		_, err = sess.ID(job.ID).Delete(&Job{})
+		return err
+	})
+}
+
+func (s *LogAnnotationService) getJob(ctx context.Context, orgID int64, uid string) (*Job, error) {
+	job := &Job{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(job)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrJobNotFound
+		}
+		return nil
+	})
+	return job, err
+}
+
+func (s *LogAnnotationService) listJobs(ctx context.Context, orgID int64) ([]*Job, error) {
+	jobs := make([]*Job, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&jobs)
+	})
+	return jobs, err
+}
+
+// listBackfillJobs returns the enabled jobs of all organizations with a pending backfill.
+func (s *LogAnnotationService) listBackfillJobs(ctx context.Context) ([]*Job, error) {
+	jobs := make([]*Job, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ? AND backfill_from IS NOT NULL", s.store.GetDialect().BooleanStr(true)).Find(&jobs)
+	})
+	return jobs, err
+}
+
+// getLineHashes returns the hashes of the lines of a job materialized in the period [from, to].
+func (s *LogAnnotationService) getLineHashes(ctx context.Context, job *Job, from, to time.Time) (map[string]bool, error) {
+	var hashes []string
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		// times are stored with a precision of seconds by some databases
+		return sess.Table(&line{}).Where("job_id = ? AND line_time >= ? AND line_time <= ?",
+			job.ID, from.Add(-time.Second), to.Add(time.Second)).Cols("line_hash").Find(&hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		seen[hash] = true
+	}
+	return seen, nil
+}
+
+func (s *LogAnnotationService) insertLines(ctx context.Context, lines []*line) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(lines); start += lineBatchSize {
+			end := start + lineBatchSize
+			if end > len(lines) {
+				end = len(lines)
+			}
+			if _, err := sess.InsertMulti(lines[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteExpiredLines deletes the materialized lines older than a time, of all jobs.
+func (s *LogAnnotationService) deleteExpiredLines(ctx context.Context, before time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("line_time < ?", before).Delete(&line{})
+		return err
+	})
+}
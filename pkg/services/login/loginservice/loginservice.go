@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -68,22 +69,31 @@ func (ls *Implementation) UpsertUser(ctx context.Context, cmd *models.UpsertUser
 		}
 
 		// we may insert in both user and org_user tables
-		// therefore we need to query check quota for both user and org services
+		// therefore we need to query check quota for both user and org services.
+		// The quota middleware can't be used, since the quotas only apply to users signing in for the
+		// first time, which is only known once the user has been looked up.
+		statuses := make([]*quota.Status, 0, 2)
 		for _, srv := range []string{user.QuotaTargetSrv, org.QuotaTargetSrv} {
-			limitReached, errLimit := ls.QuotaService.QuotaReached(cmd.ReqContext, quota.TargetSrv(srv))
+			status, errLimit := ls.QuotaService.Check(cmd.ReqContext, quota.TargetSrv(srv))
 			if errLimit != nil {
 				cmd.ReqContext.Logger.Warn("Error getting user quota.", "error", errLimit)
 				return login.ErrGettingUserQuota
 			}
-			if limitReached {
+			if status.Reached {
 				return login.ErrUsersQuotaReached
 			}
+			statuses = append(statuses, status)
 		}
 
 		result, errCreateUser := ls.createUser(extUser)
 		if errCreateUser != nil {
 			return errCreateUser
 		}
+		for _, status := range statuses {
+			if status.AddUsage != nil {
+				status.AddUsage()
+			}
+		}
 
 		cmd.Result = &user.User{
 			ID:               result.ID,
@@ -289,7 +299,7 @@ func (ls *Implementation) syncOrgRoles(ctx context.Context, usr *user.User, extU
 			deleteOrgIds = append(deleteOrgIds, orga.OrgID)
 		} else if extRole != orga.Role {
 			// update role
-			cmd := &org.UpdateOrgUserCommand{OrgID: orga.OrgID, UserID: usr.ID, Role: extRole}
+			cmd := &org.UpdateOrgUserCommand{OrgID: orga.OrgID, UserID: usr.ID, Role: extRole, Source: events.OrgUserSourceSync}
 			if err := ls.orgService.UpdateOrgUser(ctx, cmd); err != nil {
 				return err
 			}
@@ -303,7 +313,7 @@ func (ls *Implementation) syncOrgRoles(ctx context.Context, usr *user.User, extU
 		}
 
 		// add role
-		cmd := &org.AddOrgUserCommand{UserID: usr.ID, Role: orgRole, OrgID: orgId}
+		cmd := &org.AddOrgUserCommand{UserID: usr.ID, Role: orgRole, OrgID: orgId, Source: events.OrgUserSourceSync}
 		err := ls.orgService.AddOrgUser(ctx, cmd)
 		if err != nil && !errors.Is(err, models.ErrOrgNotFound) {
 			return err
@@ -314,7 +324,7 @@ func (ls *Implementation) syncOrgRoles(ctx context.Context, usr *user.User, extU
 	for _, orgId := range deleteOrgIds {
 		logger.Debug("Removing user's organization membership as part of syncing with OAuth login",
 			"userId", usr.ID, "orgId", orgId)
-		cmd := &org.RemoveOrgUserCommand{OrgID: orgId, UserID: usr.ID}
+		cmd := &org.RemoveOrgUserCommand{OrgID: orgId, UserID: usr.ID, Source: events.OrgUserSourceSync}
 		if err := ls.orgService.RemoveOrgUser(ctx, cmd); err != nil {
 			if errors.Is(err, models.ErrLastOrgAdmin) {
 				logger.Error(err.Error(), "userId", cmd.UserID, "orgId", cmd.OrgID)
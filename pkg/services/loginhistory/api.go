@@ -0,0 +1,127 @@
+package loginhistory
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *LoginHistoryService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	userIDScope := ac.Scope("global.users", "id", ac.Parameter(":id"))
+
+	s.routeRegister.Get("/api/user/auth-history", middleware.ReqSignedInNoAnonymous, routing.Wrap(s.getSignedInUserHistoryHandler))
+	s.routeRegister.Group("/api/admin", func(entities routing.RouteRegister) {
+		entities.Get("/auth-history", authorize(middleware.ReqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersAuthTokenList, ac.ScopeGlobalUsersAll)), routing.Wrap(s.getHistoryHandler))
+		entities.Get("/users/:id/auth-history", authorize(middleware.ReqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersAuthTokenList, userIDScope)), routing.Wrap(s.getUserHistoryHandler))
+	})
+}
+
+// swagger:route GET /user/auth-history signed_in_user getSignedInUserAuthHistory
+//
+// Get the login history of the signed in user.
+//
+// Returns the successful and failed login attempts of the signed in user, newest first.
+//
+// Responses:
+// 200: loginHistoryResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *LoginHistoryService) getSignedInUserHistoryHandler(c *models.ReqContext) response.Response {
+	if c.SignedInUser.IsServiceAccount {
+		return response.Error(http.StatusBadRequest, "Service accounts have no login history", nil)
+	}
+	return s.searchResponse(c, c.UserID)
+}
+
+// swagger:route GET /admin/auth-history admin_users adminGetAuthHistory
+//
+// Get the login history of all users.
+//
+// Use `success=false` to list failed login attempts only, including attempts for logins that do not exist.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.authtoken:read` and scope `global.users:*`.
+//
+// Responses:
+// 200: loginHistoryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *LoginHistoryService) getHistoryHandler(c *models.ReqContext) response.Response {
+	return s.searchResponse(c, 0)
+}
+
+// swagger:route GET /admin/users/{user_id}/auth-history admin_users adminGetUserAuthHistory
+//
+// Get the login history of a user.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.authtoken:read` and scope `global.users:1` (userIDScope).
+//
+// Responses:
+// 200: loginHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *LoginHistoryService) getUserHistoryHandler(c *models.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	return s.searchResponse(c, userID)
+}
+
+func (s *LoginHistoryService) searchResponse(c *models.ReqContext, userID int64) response.Response {
+	query := SearchQuery{
+		UserID:  userID,
+		Page:    c.QueryInt("page"),
+		PerPage: c.QueryInt("perpage"),
+	}
+	if success := c.Query("success"); success != "" {
+		value, err := strconv.ParseBool(success)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "success is invalid", err)
+		}
+		query.Success = &value
+	}
+
+	result, err := s.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get login history", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters getSignedInUserAuthHistory adminGetAuthHistory adminGetUserAuthHistory
+type GetAuthHistoryParams struct {
+	// in:query
+	// required:false
+	Success bool `json:"success"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default:50
+	PerPage int `json:"perpage"`
+}
+
+// swagger:parameters adminGetUserAuthHistory
+type AdminGetUserAuthHistoryParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:response loginHistoryResponse
+type LoginHistoryResponse struct {
+	// in:body
+	Body SearchResult `json:"body"`
+}
@@ -0,0 +1,153 @@
+package loginhistory
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	cleanupInterval = time.Hour
+	newDeviceEmail  = "new_device_login"
+
+	defaultPerPage = 50
+	maxPerPage     = 1000
+)
+
+// Entry is a single successful or failed login attempt.
+type Entry struct {
+	ID         int64     `json:"id" xorm:"pk autoincr 'id'"`
+	UserID     int64     `json:"userId" xorm:"user_id"`
+	Login      string    `json:"login" xorm:"login"`
+	AuthModule string    `json:"authModule" xorm:"auth_module"`
+	IPAddress  string    `json:"ipAddress" xorm:"ip_address"`
+	UserAgent  string    `json:"userAgent" xorm:"user_agent"`
+	Success    bool      `json:"success" xorm:"success"`
+	Error      string    `json:"error,omitempty" xorm:"error"`
+	Created    time.Time `json:"created" xorm:"created"`
+}
+
+func (e Entry) TableName() string {
+	return "login_history"
+}
+
+// SearchQuery filters the login history. Entries are returned newest first.
+type SearchQuery struct {
+	UserID int64
+	// Success filters on successful or failed logins when set.
+	Success *bool
+	Page    int
+	PerPage int
+}
+
+// SearchResult is a page of the login history.
+// swagger:model
+type SearchResult struct {
+	TotalCount int64   `json:"totalCount"`
+	Entries    []Entry `json:"entries"`
+	Page       int     `json:"page"`
+	PerPage    int     `json:"perPage"`
+}
+
+type Service interface {
+	// Search returns a page of the login history.
+	Search(ctx context.Context, query SearchQuery) (*SearchResult, error)
+}
+
+type LoginHistoryService struct {
+	store         db.DB
+	cfg           *setting.Cfg
+	log           log.Logger
+	emailSender   notifications.EmailSender
+	routeRegister routing.RouteRegister
+	accessControl accesscontrol.AccessControl
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, hooksService *hooks.HooksService, emailSender notifications.EmailSender,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *LoginHistoryService {
+	s := &LoginHistoryService{
+		store:         sqlStore,
+		cfg:           cfg,
+		log:           log.New("login-history"),
+		emailSender:   emailSender,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+	}
+
+	hooksService.AddLoginHook(s.loginHook)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// loginHook records every login attempt and notifies users about successful logins from new devices.
+func (s *LoginHistoryService) loginHook(info *models.LoginInfo, c *models.ReqContext) {
+	ctx := c.Req.Context()
+
+	entry := newEntry(info, c.RemoteAddr(), c.Req.UserAgent(), time.Now())
+	if entry.UserID == 0 && entry.Login != "" {
+		userID, err := s.lookupUserID(ctx, entry.Login)
+		if err != nil {
+			s.log.Warn("Failed to look up user of login attempt", "login", entry.Login, "error", err)
+		}
+		entry.UserID = userID
+	}
+
+	newDevice, err := s.record(ctx, entry)
+	if err != nil {
+		s.log.Error("Failed to record login attempt", "login", entry.Login, "error", err)
+		return
+	}
+
+	if newDevice && s.cfg.LoginNewDeviceNotification && info.User != nil && info.User.Email != "" {
+		if err := s.notifyNewDevice(ctx, info, entry); err != nil {
+			s.log.Warn("Failed to send new device notification", "userId", entry.UserID, "error", err)
+		}
+	}
+}
+
+func (s *LoginHistoryService) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	if query.PerPage <= 0 {
+		query.PerPage = defaultPerPage
+	}
+	if query.PerPage > maxPerPage {
+		query.PerPage = maxPerPage
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.search(ctx, query)
+}
+
+// Run removes entries that are older than the configured retention.
+func (s *LoginHistoryService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if s.cfg.LoginHistoryRetention <= 0 {
+				continue
+			}
+			removed, err := s.deleteOlderThan(ctx, time.Now().Add(-s.cfg.LoginHistoryRetention))
+			if err != nil {
+				s.log.Error("Failed to clean up login history", "error", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Debug("Cleaned up login history", "removed", removed)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package loginhistory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestNewEntry(t *testing.T) {
+	now := time.Now()
+
+	t.Run("successful login", func(t *testing.T) {
+		entry := newEntry(&models.LoginInfo{
+			AuthModule: "grafana",
+			User:       &user.User{ID: 2, Login: "admin"},
+			HTTPStatus: http.StatusOK,
+		}, "10.0.0.1:5000", "Mozilla/5.0", now)
+
+		assert.Equal(t, &Entry{
+			UserID:     2,
+			Login:      "admin",
+			AuthModule: "grafana",
+			IPAddress:  "10.0.0.1",
+			UserAgent:  "Mozilla/5.0",
+			Success:    true,
+			Created:    now,
+		}, entry)
+	})
+
+	t.Run("failed login", func(t *testing.T) {
+		entry := newEntry(&models.LoginInfo{
+			LoginUsername: "admin",
+			HTTPStatus:    http.StatusUnauthorized,
+			Error:         errors.New("invalid username or password"),
+		}, "[::1]:5000", "", now)
+
+		assert.False(t, entry.Success)
+		assert.Equal(t, int64(0), entry.UserID)
+		assert.Equal(t, "admin", entry.Login)
+		assert.Equal(t, "::1", entry.IPAddress)
+		assert.Equal(t, "invalid username or password", entry.Error)
+	})
+
+	t.Run("long values are cut to the length of their columns", func(t *testing.T) {
+		entry := newEntry(&models.LoginInfo{
+			LoginUsername: strings.Repeat("ü", 200),
+			HTTPStatus:    http.StatusUnauthorized,
+			Error:         errors.New(strings.Repeat("e", 300)),
+		}, "10.0.0.1:5000", strings.Repeat("€", 300), now)
+
+		assert.Equal(t, strings.Repeat("ü", loginLength), entry.Login)
+		assert.Equal(t, strings.Repeat("€", userAgentLength), entry.UserAgent)
+		assert.Equal(t, strings.Repeat("e", errorLength), entry.Error)
+	})
+}
+
+func TestIntegrationLoginHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	usr, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: "viewer", Email: "viewer@example.com"})
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.LoginNewDeviceNotification = true
+	emailSender := &notifications.NotificationServiceMock{}
+	hooksService := hooks.ProvideService()
+	s := ProvideService(cfg, sqlStore, hooksService, emailSender, routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	login := func(remoteAddr, userAgent string, success bool) {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("User-Agent", userAgent)
+		c := &models.ReqContext{Context: &web.Context{Req: req}}
+
+		info := &models.LoginInfo{AuthModule: "grafana", LoginUsername: usr.Email, HTTPStatus: http.StatusOK}
+		if success {
+			info.User = usr
+		} else {
+			info.HTTPStatus = http.StatusUnauthorized
+			info.Error = errors.New("invalid username or password")
+		}
+		hooksService.RunLoginHook(info, c)
+	}
+
+	t.Run("should record login attempts and notify about new devices", func(t *testing.T) {
+		login("10.0.0.1:5000", "firefox", true)
+		assert.Empty(t, emailSender.Email.To, "first login should not be notified")
+
+		login("10.0.0.1:5000", "firefox", true)
+		assert.Empty(t, emailSender.Email.To, "known device should not be notified")
+
+		login("10.0.0.2:5000", "firefox", false)
+		assert.Empty(t, emailSender.Email.To, "failed login should not be notified")
+
+		login("10.0.0.2:5000", "firefox", true)
+		require.Equal(t, []string{"viewer@example.com"}, emailSender.Email.To)
+		assert.Equal(t, newDeviceEmail, emailSender.Email.Template)
+		assert.Equal(t, "10.0.0.2", emailSender.Email.Data["IPAddress"])
+	})
+
+	t.Run("should search the login history of a user", func(t *testing.T) {
+		result, err := s.Search(ctx, SearchQuery{UserID: usr.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), result.TotalCount)
+		require.Len(t, result.Entries, 4)
+		assert.Equal(t, "10.0.0.2", result.Entries[0].IPAddress)
+		assert.Equal(t, "viewer", result.Entries[0].Login)
+
+		failed := false
+		result, err = s.Search(ctx, SearchQuery{UserID: usr.ID, Success: &failed, PerPage: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.TotalCount)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, usr.ID, result.Entries[0].UserID, "failed login should be matched to the user by email")
+		assert.Equal(t, "invalid username or password", result.Entries[0].Error)
+	})
+
+	t.Run("should remove entries older than the retention", func(t *testing.T) {
+		removed, err := s.deleteOlderThan(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), removed)
+	})
+}
@@ -0,0 +1,28 @@
+package loginhistory
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func (s *LoginHistoryService) notifyNewDevice(ctx context.Context, info *models.LoginInfo, entry *Entry) error {
+	name := info.User.Name
+	if name == "" {
+		name = info.User.Login
+	}
+
+	return s.emailSender.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
+		To:       []string{info.User.Email},
+		Template: newDeviceEmail,
+		Data: map[string]interface{}{
+			"Name":       name,
+			"Login":      info.User.Login,
+			"IPAddress":  entry.IPAddress,
+			"UserAgent":  entry.UserAgent,
+			"Time":       entry.Created.UTC().Format(time.RFC1123),
+			"ProfileUrl": s.cfg.AppURL + "profile",
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package loginhistory
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// The lengths of the string columns of the login_history table, in characters.
+const (
+	loginLength      = 190
+	authModuleLength = 190
+	ipAddressLength  = 255
+	userAgentLength  = 255
+	errorLength      = 255
+)
+
+func newEntry(info *models.LoginInfo, remoteAddr, userAgent string, now time.Time) *Entry {
+	entry := &Entry{
+		AuthModule: truncate(info.AuthModule, authModuleLength),
+		UserAgent:  truncate(userAgent, userAgentLength),
+		Success:    info.User != nil && info.Error == nil && (info.HTTPStatus == 0 || info.HTTPStatus == http.StatusOK),
+		Created:    now,
+	}
+
+	entry.IPAddress = truncate(remoteAddr, ipAddressLength)
+	if ip, err := network.GetIPFromAddress(remoteAddr); err == nil {
+		entry.IPAddress = ip.String()
+	}
+
+	switch {
+	case info.User != nil:
+		entry.UserID = info.User.ID
+		entry.Login = info.User.Login
+	case info.LoginUsername != "":
+		entry.Login = info.LoginUsername
+	case info.ExternalUser.Login != "":
+		entry.Login = info.ExternalUser.Login
+	default:
+		entry.Login = info.ExternalUser.Email
+	}
+	entry.Login = truncate(entry.Login, loginLength)
+
+	if info.Error != nil {
+		entry.Error = truncate(info.Error.Error(), errorLength)
+	}
+
+	return entry
+}
+
+// truncate cuts a string to at most length characters, without splitting a multi-byte character.
+func truncate(s string, length int) string {
+	if utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	return string([]rune(s)[:length])
+}
+
+// lookupUserID returns the ID of the user with the given login or email, or 0 if there is none.
+func (s *LoginHistoryService) lookupUserID(ctx context.Context, login string) (int64, error) {
+	var userID int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Table("user").Cols("id").
+			Where("login = ? OR email = ?", login, login).Get(&userID)
+		return err
+	})
+	return userID, err
+}
+
+// record stores the entry and reports whether it is a successful login from a device
+// the user has not signed in from before. The first login of a user is not reported.
+func (s *LoginHistoryService) record(ctx context.Context, entry *Entry) (bool, error) {
+	newDevice := false
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if entry.Success && entry.UserID != 0 {
+			previous, err := sess.Table("login_history").
+				Where("user_id = ? AND success = ?", entry.UserID, s.store.GetDialect().BooleanStr(true)).Count()
+			if err != nil {
+				return err
+			}
+
+			sameDevice, err := sess.Table("login_history").
+				Where("user_id = ? AND success = ? AND ip_address = ? AND user_agent = ?",
+					entry.UserID, s.store.GetDialect().BooleanStr(true), entry.IPAddress, entry.UserAgent).Count()
+			if err != nil {
+				return err
+			}
+
+			newDevice = previous > 0 && sameDevice == 0
+		}
+
+		_, err := sess.Insert(entry)
+		return err
+	})
+	return newDevice, err
+}
+
+func (s *LoginHistoryService) search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	result := &SearchResult{
+		Entries: []Entry{},
+		Page:    query.Page,
+		PerPage: query.PerPage,
+	}
+
+	where := []string{"1 = 1"}
+	args := []interface{}{}
+	if query.UserID != 0 {
+		where = append(where, "user_id = ?")
+		args = append(args, query.UserID)
+	}
+	if query.Success != nil {
+		where = append(where, "success = ?")
+		args = append(args, s.store.GetDialect().BooleanStr(*query.Success))
+	}
+	whereSQL := strings.Join(where, " AND ")
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		total, err := sess.Table("login_history").Where(whereSQL, args...).Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = total
+
+		return sess.Table("login_history").Where(whereSQL, args...).
+			Desc("created", "id").
+			Limit(query.PerPage, (query.Page-1)*query.PerPage).
+			Find(&result.Entries)
+	})
+
+	return result, err
+}
+
+func (s *LoginHistoryService) deleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM login_history WHERE created < ?", before)
+		if err != nil {
+			return err
+		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return removed, err
+}
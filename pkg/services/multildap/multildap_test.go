@@ -486,6 +486,16 @@ func (mock *mockLDAP) Users([]string) ([]*models.ExternalUserInfo, error) {
 	return mock.usersRestReturn, mock.usersErrReturn
 }
 
+// Marker test fn
+func (mock *mockLDAP) Marker() (*ldap.ChangeMarker, error) {
+	return &ldap.ChangeMarker{}, nil
+}
+
+// Changes test fn
+func (mock *mockLDAP) Changes(*ldap.ChangeMarker) (*ldap.Changes, error) {
+	return &ldap.Changes{}, nil
+}
+
 // UserBind test fn
 func (mock *mockLDAP) UserBind(string, string) error {
 	return nil
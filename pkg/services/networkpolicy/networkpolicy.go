@@ -0,0 +1,264 @@
+// Package networkpolicy restricts the networks an organization can be accessed from, and keeps an
+// audit log of the requests rejected by network policies.
+package networkpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/network"
+)
+
+const (
+	// policyCacheTTL is how long an instance uses a policy before reading it again, it is the
+	// delay after which a changed policy is enforced by the other instances of a cluster.
+	policyCacheTTL = time.Minute
+
+	cleanupInterval    = time.Hour
+	rejectionRetention = 30 * 24 * time.Hour
+
+	// rejectionFlushInterval is how often the rejected requests are written to the database, so
+	// that requests from a denied network don't write to the database.
+	rejectionFlushInterval = 30 * time.Second
+	rejectionFlushTimeout  = 5 * time.Second
+	// maxPendingRejections bounds the distinct rejections kept between two flushes, the others
+	// are only counted.
+	maxPendingRejections = 1000
+
+	defaultPerPage = 50
+	maxPerPage     = 1000
+)
+
+var (
+	ErrInvalidCIDR = network.ErrInvalidCIDR
+	// ErrRequesterDenied is returned when a policy would deny the address the policy is changed from.
+	ErrRequesterDenied = errors.New("the policy would deny access from your own address")
+)
+
+// PolicyType tells which policy rejected a request.
+type PolicyType string
+
+const (
+	PolicyTypeOrg    PolicyType = "org"
+	PolicyTypeAPIKey PolicyType = "api_key"
+)
+
+// Policy restricts the networks an organization can be accessed from. Denied networks take
+// precedence, and an empty allow list allows every network which is not denied.
+type Policy struct {
+	OrgID        int64     `json:"orgId"`
+	AllowedCIDRs []string  `json:"allowedCidrs"`
+	DeniedCIDRs  []string  `json:"deniedCidrs"`
+	Updated      time.Time `json:"updated,omitempty"`
+}
+
+// IsAllowed reports whether the address, optionally with a port, can access the organization.
+func (p *Policy) IsAllowed(addr string) bool {
+	return network.IsAllowed(p.AllowedCIDRs, p.DeniedCIDRs, addr)
+}
+
+type SetPolicyCommand struct {
+	OrgID        int64    `json:"-"`
+	AllowedCIDRs []string `json:"allowedCidrs"`
+	DeniedCIDRs  []string `json:"deniedCidrs"`
+	// RequesterAddr is the address of the user changing the policy, the policy is rejected if it
+	// would deny it.
+	RequesterAddr string `json:"-"`
+}
+
+// Rejection is a request rejected by a network policy. The identical requests rejected between two
+// writes to the database are recorded once, with their count and the time of the last of them.
+type Rejection struct {
+	ID        int64      `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID     int64      `json:"orgId" xorm:"org_id"`
+	UserID    int64      `json:"userId" xorm:"user_id"`
+	APIKeyID  int64      `json:"apiKeyId" xorm:"api_key_id"`
+	Policy    PolicyType `json:"policy" xorm:"policy"`
+	IPAddress string     `json:"ipAddress" xorm:"ip_address"`
+	Method    string     `json:"method" xorm:"method"`
+	Path      string     `json:"path" xorm:"path"`
+	Count     int64      `json:"count" xorm:"count"`
+	Created   time.Time  `json:"created" xorm:"created"`
+}
+
+func (r Rejection) TableName() string {
+	return "network_policy_rejection"
+}
+
+// SearchRejectionsQuery filters the rejections of an organization, newest first.
+type SearchRejectionsQuery struct {
+	OrgID   int64
+	Page    int
+	PerPage int
+}
+
+// SearchRejectionsResult is a page of rejections.
+// swagger:model
+type SearchRejectionsResult struct {
+	TotalCount int64       `json:"totalCount"`
+	Rejections []Rejection `json:"rejections"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"perPage"`
+}
+
+type Service interface {
+	// GetPolicy returns the policy of an organization, which is empty if none was set.
+	GetPolicy(ctx context.Context, orgID int64) (*Policy, error)
+	SetPolicy(ctx context.Context, cmd SetPolicyCommand) (*Policy, error)
+	// IsAllowed reports whether the address can access the organization. Policies are cached, so
+	// that it can be called for every request.
+	IsAllowed(ctx context.Context, orgID int64, addr string) (bool, error)
+	// RecordRejection adds a rejected request to the audit log. Rejections are written to the
+	// database periodically.
+	RecordRejection(ctx context.Context, rejection Rejection)
+	SearchRejections(ctx context.Context, query SearchRejectionsQuery) (*SearchRejectionsResult, error)
+}
+
+type NetworkPolicyService struct {
+	store      db.DB
+	cache      *localcache.CacheService
+	rejections *rejectionTracker
+	log        log.Logger
+	audit      log.Logger
+	now        func() time.Time
+}
+
+func ProvideService(sqlStore db.DB) *NetworkPolicyService {
+	return &NetworkPolicyService{
+		store:      sqlStore,
+		cache:      localcache.New(policyCacheTTL, 2*policyCacheTTL),
+		rejections: newRejectionTracker(maxPendingRejections),
+		log:        log.New("network-policy"),
+		audit:      log.New("network-policy.audit"),
+		now:        time.Now,
+	}
+}
+
+func (s *NetworkPolicyService) GetPolicy(ctx context.Context, orgID int64) (*Policy, error) {
+	return s.getPolicy(ctx, orgID)
+}
+
+func (s *NetworkPolicyService) SetPolicy(ctx context.Context, cmd SetPolicyCommand) (*Policy, error) {
+	allowed, err := network.NormalizeCIDRs(cmd.AllowedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("%w in allowed networks", ErrInvalidCIDR)
+	}
+	denied, err := network.NormalizeCIDRs(cmd.DeniedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("%w in denied networks", ErrInvalidCIDR)
+	}
+
+	policy := &Policy{OrgID: cmd.OrgID, AllowedCIDRs: allowed, DeniedCIDRs: denied, Updated: s.now()}
+	if cmd.RequesterAddr != "" && !policy.IsAllowed(cmd.RequesterAddr) {
+		return nil, ErrRequesterDenied
+	}
+
+	if err := s.setPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	s.cache.Delete(cacheKey(cmd.OrgID))
+	s.log.Info("Network policy changed", "orgId", cmd.OrgID, "allowed", allowed, "denied", denied)
+	return policy, nil
+}
+
+func (s *NetworkPolicyService) IsAllowed(ctx context.Context, orgID int64, addr string) (bool, error) {
+	if cached, ok := s.cache.Get(cacheKey(orgID)); ok {
+		return cached.(*Policy).IsAllowed(addr), nil
+	}
+
+	policy, err := s.getPolicy(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	s.cache.Set(cacheKey(orgID), policy, policyCacheTTL)
+	return policy.IsAllowed(addr), nil
+}
+
+func (s *NetworkPolicyService) RecordRejection(ctx context.Context, rejection Rejection) {
+	rejection.Created = s.now()
+	if ip, err := network.GetIPFromAddress(rejection.IPAddress); err == nil {
+		rejection.IPAddress = ip.String()
+	}
+	rejection.Path = truncate(rejection.Path, 255)
+	rejection.IPAddress = truncate(rejection.IPAddress, 50)
+
+	// only the first of the identical rejections between two flushes is logged
+	if s.rejections.record(rejection) {
+		s.audit.Warn("Request rejected by network policy", "policy", rejection.Policy, "orgId", rejection.OrgID,
+			"userId", rejection.UserID, "apiKeyId", rejection.APIKeyID, "remoteAddr", rejection.IPAddress,
+			"method", rejection.Method, "path", rejection.Path)
+	}
+}
+
+func (s *NetworkPolicyService) SearchRejections(ctx context.Context, query SearchRejectionsQuery) (*SearchRejectionsResult, error) {
+	if query.PerPage <= 0 {
+		query.PerPage = defaultPerPage
+	}
+	if query.PerPage > maxPerPage {
+		query.PerPage = maxPerPage
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.searchRejections(ctx, query)
+}
+
+// Run writes the rejected requests to the database, and removes rejections older than the
+// retention of the audit log.
+func (s *NetworkPolicyService) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(rejectionFlushInterval)
+	defer flushTicker.Stop()
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the context is done, but the rejections recorded since the last flush are still written
+			flushCtx, cancel := context.WithTimeout(context.Background(), rejectionFlushTimeout)
+			s.flushRejections(flushCtx)
+			cancel()
+			return ctx.Err()
+		case <-flushTicker.C:
+			s.flushRejections(ctx)
+		case <-ticker.C:
+			removed, err := s.deleteRejectionsOlderThan(ctx, s.now().Add(-rejectionRetention))
+			if err != nil {
+				s.log.Error("Failed to clean up rejected requests", "error", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Debug("Cleaned up rejected requests", "removed", removed)
+			}
+		}
+	}
+}
+
+func (s *NetworkPolicyService) flushRejections(ctx context.Context) {
+	rejections, dropped := s.rejections.take()
+	if dropped > 0 {
+		s.log.Warn("Too many distinct rejected requests, some were not recorded", "dropped", dropped)
+	}
+	if len(rejections) == 0 {
+		return
+	}
+	if err := s.insertRejections(ctx, rejections); err != nil {
+		s.log.Error("Failed to record rejected requests", "count", len(rejections), "error", err)
+	}
+}
+
+func cacheKey(orgID int64) string {
+	return fmt.Sprintf("network-policy-%d", orgID)
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package networkpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func TestIntegrationNetworkPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s := ProvideService(db.InitTestDB(t))
+
+	t.Run("Should allow every address without a policy", func(t *testing.T) {
+		policy, err := s.GetPolicy(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, policy.AllowedCIDRs)
+		assert.Empty(t, policy.DeniedCIDRs)
+
+		allowed, err := s.IsAllowed(ctx, 1, "203.0.113.10:4000")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("Should enforce a changed policy", func(t *testing.T) {
+		policy, err := s.SetPolicy(ctx, SetPolicyCommand{
+			OrgID:         1,
+			AllowedCIDRs:  []string{"10.0.0.0/8", "192.168.1.1"},
+			DeniedCIDRs:   []string{"10.1.0.0/16"},
+			RequesterAddr: "192.168.1.1:5000",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1/32"}, policy.AllowedCIDRs)
+
+		for addr, expected := range map[string]bool{
+			"10.2.0.1:4000":     true,
+			"10.1.0.1:4000":     false,
+			"203.0.113.10:4000": false,
+		} {
+			allowed, err := s.IsAllowed(ctx, 1, addr)
+			require.NoError(t, err)
+			assert.Equal(t, expected, allowed, addr)
+		}
+
+		allowed, err := s.IsAllowed(ctx, 2, "203.0.113.10:4000")
+		require.NoError(t, err)
+		assert.True(t, allowed, "policies should only apply to their organization")
+	})
+
+	t.Run("Should not set an invalid policy", func(t *testing.T) {
+		_, err := s.SetPolicy(ctx, SetPolicyCommand{OrgID: 1, AllowedCIDRs: []string{"10.0.0.0/33"}})
+		assert.ErrorIs(t, err, ErrInvalidCIDR)
+
+		_, err = s.SetPolicy(ctx, SetPolicyCommand{OrgID: 1, DeniedCIDRs: []string{"192.168.0.0/16"}, RequesterAddr: "192.168.1.1:5000"})
+		assert.ErrorIs(t, err, ErrRequesterDenied)
+	})
+
+	t.Run("Should search and clean up rejections", func(t *testing.T) {
+		s.RecordRejection(ctx, Rejection{OrgID: 1, UserID: 2, Policy: PolicyTypeOrg, IPAddress: "10.1.0.1:4000", Method: "GET", Path: "/api/search"})
+		s.RecordRejection(ctx, Rejection{OrgID: 1, APIKeyID: 3, Policy: PolicyTypeAPIKey, IPAddress: "10.1.0.2:4000", Method: "GET", Path: "/api/dashboards"})
+		s.RecordRejection(ctx, Rejection{OrgID: 2, UserID: 2, Policy: PolicyTypeOrg, IPAddress: "10.1.0.3:4000", Method: "GET", Path: "/api/search"})
+		s.RecordRejection(ctx, Rejection{OrgID: 2, UserID: 2, Policy: PolicyTypeOrg, IPAddress: "10.1.0.3:4001", Method: "GET", Path: "/api/search"})
+
+		result, err := s.SearchRejections(ctx, SearchRejectionsQuery{OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), result.TotalCount, "rejections should only be written when flushed")
+
+		s.flushRejections(ctx)
+		result, err = s.SearchRejections(ctx, SearchRejectionsQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+		assert.Equal(t, "10.1.0.2", result.Rejections[0].IPAddress)
+		assert.Equal(t, PolicyTypeAPIKey, result.Rejections[0].Policy)
+
+		result, err = s.SearchRejections(ctx, SearchRejectionsQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		assert.Equal(t, int64(2), result.Rejections[0].Count)
+
+		removed, err := s.deleteRejectionsOlderThan(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), removed)
+	})
+}
+
+func TestRejectionTracker(t *testing.T) {
+	tracker := newRejectionTracker(2)
+	first := time.Date(2022, time.November, 7, 9, 0, 0, 0, time.UTC)
+
+	assert.True(t, tracker.record(Rejection{OrgID: 1, IPAddress: "10.1.0.1", Path: "/api/search", Created: first}))
+	assert.False(t, tracker.record(Rejection{OrgID: 1, IPAddress: "10.1.0.1", Path: "/api/search", Created: first.Add(time.Second)}))
+	assert.True(t, tracker.record(Rejection{OrgID: 1, IPAddress: "10.1.0.2", Path: "/api/search", Created: first}))
+	assert.False(t, tracker.record(Rejection{OrgID: 1, IPAddress: "10.1.0.3", Path: "/api/search", Created: first}), "rejections beyond the limit should be dropped")
+
+	rejections, dropped := tracker.take()
+	require.Len(t, rejections, 2)
+	assert.Equal(t, int64(1), dropped)
+	assert.Equal(t, "10.1.0.1", rejections[0].IPAddress)
+	assert.Equal(t, int64(2), rejections[0].Count)
+	assert.Equal(t, first.Add(time.Second), rejections[0].Created)
+	assert.Equal(t, int64(1), rejections[1].Count)
+
+	rejections, dropped = tracker.take()
+	assert.Empty(t, rejections)
+	assert.Zero(t, dropped)
+}
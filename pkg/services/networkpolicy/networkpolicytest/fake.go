@@ -0,0 +1,37 @@
+package networkpolicytest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
+)
+
+type FakeService struct {
+	ExpectedPolicy     *networkpolicy.Policy
+	ExpectedRejections *networkpolicy.SearchRejectionsResult
+	ExpectedError      error
+	// ExpectedDenied makes IsAllowed reject every address.
+	ExpectedDenied bool
+
+	Rejections []networkpolicy.Rejection
+}
+
+func (s *FakeService) GetPolicy(ctx context.Context, orgID int64) (*networkpolicy.Policy, error) {
+	return s.ExpectedPolicy, s.ExpectedError
+}
+
+func (s *FakeService) SetPolicy(ctx context.Context, cmd networkpolicy.SetPolicyCommand) (*networkpolicy.Policy, error) {
+	return s.ExpectedPolicy, s.ExpectedError
+}
+
+func (s *FakeService) IsAllowed(ctx context.Context, orgID int64, addr string) (bool, error) {
+	return !s.ExpectedDenied, s.ExpectedError
+}
+
+func (s *FakeService) RecordRejection(ctx context.Context, rejection networkpolicy.Rejection) {
+	s.Rejections = append(s.Rejections, rejection)
+}
+
+func (s *FakeService) SearchRejections(ctx context.Context, query networkpolicy.SearchRejectionsQuery) (*networkpolicy.SearchRejectionsResult, error) {
+	return s.ExpectedRejections, s.ExpectedError
+}
@@ -0,0 +1,80 @@
+package networkpolicy
+
+import (
+	"sync"
+)
+
+// rejectionKey identifies identical rejected requests.
+type rejectionKey struct {
+	orgID     int64
+	userID    int64
+	apiKeyID  int64
+	policy    PolicyType
+	ipAddress string
+	method    string
+	path      string
+}
+
+// rejectionTracker aggregates the rejected requests between two writes to the database, so that a
+// denied network sending many requests cannot flood the database with writes.
+type rejectionTracker struct {
+	mu         sync.Mutex
+	maxPending int
+	pending    map[rejectionKey]*Rejection
+	// order keeps the rejections in the order they were first seen
+	order   []rejectionKey
+	dropped int64
+}
+
+func newRejectionTracker(maxPending int) *rejectionTracker {
+	return &rejectionTracker{maxPending: maxPending, pending: map[rejectionKey]*Rejection{}}
+}
+
+// record adds a rejection, and returns true if no identical rejection was recorded since the last
+// take.
+func (t *rejectionTracker) record(rejection Rejection) bool {
+	key := rejectionKey{
+		orgID:     rejection.OrgID,
+		userID:    rejection.UserID,
+		apiKeyID:  rejection.APIKeyID,
+		policy:    rejection.Policy,
+		ipAddress: rejection.IPAddress,
+		method:    rejection.Method,
+		path:      rejection.Path,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pending, ok := t.pending[key]; ok {
+		pending.Count++
+		if rejection.Created.After(pending.Created) {
+			pending.Created = rejection.Created
+		}
+		return false
+	}
+	if len(t.pending) >= t.maxPending {
+		t.dropped++
+		return false
+	}
+	rejection.Count = 1
+	t.pending[key] = &rejection
+	t.order = append(t.order, key)
+	return true
+}
+
+// take returns the recorded rejections and the number of dropped ones, and resets them.
+func (t *rejectionTracker) take() ([]Rejection, int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rejections := make([]Rejection, 0, len(t.order))
+	for _, key := range t.order {
+		rejections = append(rejections, *t.pending[key])
+	}
+	dropped := t.dropped
+	t.pending = map[rejectionKey]*Rejection{}
+	t.order = nil
+	t.dropped = 0
+	return rejections, dropped
+}
@@ -0,0 +1,109 @@
+package networkpolicy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// policyRow is the stored form of a policy, networks are stored as comma separated lists.
+type policyRow struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	AllowedCIDRs string    `xorm:"allowed_cidrs"`
+	DeniedCIDRs  string    `xorm:"denied_cidrs"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (r policyRow) TableName() string {
+	return "org_network_policy"
+}
+
+func (s *NetworkPolicyService) getPolicy(ctx context.Context, orgID int64) (*Policy, error) {
+	policy := &Policy{OrgID: orgID, AllowedCIDRs: []string{}, DeniedCIDRs: []string{}}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var row policyRow
+		exists, err := sess.Where("org_id = ?", orgID).Get(&row)
+		if err != nil || !exists {
+			return err
+		}
+		policy.AllowedCIDRs = splitCIDRs(row.AllowedCIDRs)
+		policy.DeniedCIDRs = splitCIDRs(row.DeniedCIDRs)
+		policy.Updated = row.Updated
+		return nil
+	})
+	return policy, err
+}
+
+func (s *NetworkPolicyService) setPolicy(ctx context.Context, policy *Policy) error {
+	row := &policyRow{
+		OrgID:        policy.OrgID,
+		AllowedCIDRs: strings.Join(policy.AllowedCIDRs, ","),
+		DeniedCIDRs:  strings.Join(policy.DeniedCIDRs, ","),
+		Updated:      policy.Updated,
+	}
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		updated, err := sess.Where("org_id = ?", policy.OrgID).Cols("allowed_cidrs", "denied_cidrs", "updated").Update(row)
+		if err != nil || updated > 0 {
+			return err
+		}
+		_, err = sess.Insert(row)
+		return err
+	})
+}
+
+func (s *NetworkPolicyService) searchRejections(ctx context.Context, query SearchRejectionsQuery) (*SearchRejectionsResult, error) {
+	result := &SearchRejectionsResult{
+		Rejections: []Rejection{},
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	}
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		total, err := sess.Table("network_policy_rejection").Where("org_id = ?", query.OrgID).Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = total
+
+		return sess.Table("network_policy_rejection").Where("org_id = ?", query.OrgID).
+			Desc("created", "id").
+			Limit(query.PerPage, (query.Page-1)*query.PerPage).
+			Find(&result.Rejections)
+	})
+
+	return result, err
+}
+
+func (s *NetworkPolicyService) insertRejections(ctx context.Context, rejections []Rejection) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.BulkInsert("network_policy_rejection", rejections, sqlstore.NativeSettingsForDialect(s.store.GetDialect()))
+		return err
+	})
+}
+
+func (s *NetworkPolicyService) deleteRejectionsOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM network_policy_rejection WHERE created < ?", before)
+		if err != nil {
+			return err
+		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return removed, err
+}
+
+func splitCIDRs(value string) []string {
+	cidrs := []string{}
+	for _, cidr := range strings.Split(value, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return cidrs
+}
@@ -19,8 +19,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/org"
 
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
 )
 
 type PrometheusSrv struct {
@@ -28,6 +30,7 @@ type PrometheusSrv struct {
 	manager state.AlertInstanceManager
 	store   RuleStore
 	ac      accesscontrol.AccessControl
+	orgs    org.Service
 }
 
 const queryIncludeInternalLabels = "includeInternalLabels"
@@ -48,26 +51,92 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *models.ReqContext) response.Re
 	}
 
 	for _, alertState := range srv.manager.GetAll(c.OrgID) {
-		startsAt := alertState.StartsAt
-		valString := ""
+		alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, toAlert(alertState, labelOptions))
+	}
+
+	return response.JSON(http.StatusOK, alertResponse)
+}
 
-		if alertState.State == eval.Alerting || alertState.State == eval.Pending {
-			valString = formatValues(alertState)
+func (srv PrometheusSrv) RoutePostAlertAcknowledgement(c *models.ReqContext, body apimodels.PostableAlertAcknowledgement) response.Response {
+	cmd := state.AcknowledgementCommand{
+		OrgID:      c.OrgID,
+		RuleUID:    body.RuleUID,
+		Labels:     body.Labels,
+		UserID:     c.UserID,
+		Action:     state.AcknowledgementAction(body.Action),
+		AssigneeID: body.AssigneeID,
+	}
+	if cmd.Action == state.SnoozeAction {
+		d, err := model.ParseDuration(body.SnoozeFor)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid snooze duration")
+		}
+		cmd.SnoozeFor = time.Duration(d)
+	}
+	if cmd.Action == state.AssignAction && cmd.AssigneeID != 0 {
+		users, err := srv.orgs.GetOrgUsers(c.Req.Context(), &org.GetOrgUsersQuery{
+			OrgID:                    c.OrgID,
+			UserID:                   cmd.AssigneeID,
+			DontEnforceAccessControl: true,
+		})
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get the assignee")
+		}
+		if len(users) == 0 {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("user %d is not a member of the organization", cmd.AssigneeID), "invalid assignee")
+		}
+	}
+
+	alertState, err := srv.manager.UpdateAcknowledgement(c.Req.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, state.ErrAlertInstanceNotFound):
+			return ErrResp(http.StatusNotFound, err, "")
+		case errors.Is(err, state.ErrAlertInstanceNotFiring), errors.Is(err, state.ErrInvalidAcknowledgement):
+			return ErrResp(http.StatusBadRequest, err, "")
 		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to update the acknowledgement")
+	}
+	return response.JSON(http.StatusOK, toAlert(alertState, []ngmodels.LabelOption{ngmodels.WithoutInternalLabels()}))
+}
 
-		alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, &apimodels.Alert{
-			Labels:      alertState.GetLabels(labelOptions...),
-			Annotations: alertState.Annotations,
+func toAlert(alertState *state.State, labelOptions []ngmodels.LabelOption) *apimodels.Alert {
+	activeAt := alertState.StartsAt
+	valString := ""
+	if alertState.State == eval.Alerting || alertState.State == eval.Pending {
+		valString = formatValues(alertState)
+	}
 
-			// TODO: or should we make this two fields? Using one field lets the
-			// frontend use the same logic for parsing text on annotations and this.
-			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-			ActiveAt: &startsAt,
-			Value:    valString,
-		})
+	return &apimodels.Alert{
+		Labels:      alertState.GetLabels(labelOptions...),
+		Annotations: alertState.Annotations,
+
+		// TODO: or should we make this two fields? Using one field lets the
+		// frontend use the same logic for parsing text on annotations and this.
+		State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+		ActiveAt:        &activeAt,
+		Value:           valString,
+		Acknowledgement: toAlertAcknowledgement(alertState.Acknowledgement),
 	}
+}
 
-	return response.JSON(http.StatusOK, alertResponse)
+func toAlertAcknowledgement(ack *state.Acknowledgement) *apimodels.AlertAcknowledgement {
+	if ack == nil {
+		return nil
+	}
+	result := &apimodels.AlertAcknowledgement{
+		AcknowledgedBy: ack.AcknowledgedBy,
+		AssigneeID:     ack.AssigneeID,
+	}
+	if !ack.AcknowledgedAt.IsZero() {
+		acknowledgedAt := ack.AcknowledgedAt
+		result.AcknowledgedAt = &acknowledgedAt
+	}
+	if !ack.SnoozedUntil.IsZero() {
+		snoozedUntil := ack.SnoozedUntil
+		result.SnoozedUntil = &snoozedUntil
+	}
+	return result
 }
 
 func formatValues(alertState *state.State) string {
@@ -203,25 +272,11 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *folder.Folder, ru
 			Health:         "ok",
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
+			Warning:        srv.manager.GetRuleWarning(rule.OrgID, rule.UID),
 		}
 
 		for _, alertState := range srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID) {
-			activeAt := alertState.StartsAt
-			valString := ""
-			if alertState.State == eval.Alerting || alertState.State == eval.Pending {
-				valString = formatValues(alertState)
-			}
-
-			alert := &apimodels.Alert{
-				Labels:      alertState.GetLabels(labelOptions...),
-				Annotations: alertState.Annotations,
-
-				// TODO: or should we make this two fields? Using one field lets the
-				// frontend use the same logic for parsing text on annotations and this.
-				State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-				ActiveAt: &activeAt,
-				Value:    valString,
-			}
+			alert := toAlert(alertState, labelOptions)
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
 				newRule.LastEvaluation = alertState.LastEvaluationTime
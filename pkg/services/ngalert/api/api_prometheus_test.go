@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
@@ -22,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -237,6 +239,61 @@ func TestRouteGetAlertStatuses(t *testing.T) {
 	})
 }
 
+func TestRoutePostAlertAcknowledgement(t *testing.T) {
+	orgID := int64(1)
+	ruleUID := util.GenerateShortUID()
+	labels := map[string]string{
+		"__alert_rule_namespace_uid__": "test_namespace_uid",
+		"__alert_rule_uid__":           "test_alert_rule_uid_0",
+		"alertname":                    "test_title_0",
+		"instance_label":               "test",
+		"label":                        "test",
+	}
+	post := func(api PrometheusSrv, body apimodels.PostableAlertAcknowledgement) response.Response {
+		req, err := http.NewRequest("POST", "/api/v1/alerts/acknowledgement", nil)
+		require.NoError(t, err)
+		c := &models.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID, UserID: 2}}
+		return api.RoutePostAlertAcknowledgement(c, body)
+	}
+
+	t.Run("acknowledges the alert", func(t *testing.T) {
+		_, fakeAIM, _, api := setupAPI(t)
+		fakeAIM.GenerateAlertInstances(orgID, ruleUID, 1, withAlertingState())
+
+		r := post(api, apimodels.PostableAlertAcknowledgement{RuleUID: ruleUID, Labels: labels, Action: "acknowledge"})
+		require.Equal(t, http.StatusOK, r.Status())
+		alert := apimodels.Alert{}
+		require.NoError(t, json.Unmarshal(r.Body(), &alert))
+		require.Equal(t, &apimodels.AlertAcknowledgement{AcknowledgedBy: 2}, alert.Acknowledgement)
+		require.NotContains(t, alert.Labels, "__alert_rule_uid__")
+	})
+
+	t.Run("returns 404 for unknown alerts", func(t *testing.T) {
+		_, _, _, api := setupAPI(t)
+		r := post(api, apimodels.PostableAlertAcknowledgement{RuleUID: ruleUID, Labels: labels, Action: "acknowledge"})
+		require.Equal(t, http.StatusNotFound, r.Status())
+	})
+
+	t.Run("rejects invalid snooze durations", func(t *testing.T) {
+		_, fakeAIM, _, api := setupAPI(t)
+		fakeAIM.GenerateAlertInstances(orgID, ruleUID, 1, withAlertingState())
+		r := post(api, apimodels.PostableAlertAcknowledgement{RuleUID: ruleUID, Labels: labels, Action: "snooze", SnoozeFor: "soon"})
+		require.Equal(t, http.StatusBadRequest, r.Status())
+	})
+
+	t.Run("rejects assignees outside of the organization", func(t *testing.T) {
+		_, fakeAIM, _, api := setupAPI(t)
+		fakeAIM.GenerateAlertInstances(orgID, ruleUID, 1, withAlertingState())
+		api.orgs = &orgtest.FakeOrgService{}
+		r := post(api, apimodels.PostableAlertAcknowledgement{RuleUID: ruleUID, Labels: labels, Action: "assign", AssigneeID: 3})
+		require.Equal(t, http.StatusBadRequest, r.Status())
+
+		api.orgs = &orgtest.FakeOrgService{ExpectedOrgUsers: []*org.OrgUserDTO{{OrgID: orgID, UserID: 3}}}
+		r = post(api, apimodels.PostableAlertAcknowledgement{RuleUID: ruleUID, Labels: labels, Action: "assign", AssigneeID: 3})
+		require.Equal(t, http.StatusOK, r.Status())
+	})
+}
+
 func withAlertingState() forEachState {
 	return func(s *state.State) *state.State {
 		s.State = eval.Alerting
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/grafana/grafana/pkg/api/resourceversion"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -252,7 +253,8 @@ func (srv *ProvisioningSrv) RouteRouteGetAlertRule(c *models.ReqContext, UID str
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
-	return response.JSON(http.StatusOK, definitions.NewAlertRule(rule, provenace))
+	return response.JSON(http.StatusOK, definitions.NewAlertRule(rule, provenace)).
+		SetHeader(resourceversion.ETagHeader, resourceversion.ETag(rule.Version))
 }
 
 func (srv *ProvisioningSrv) RoutePostAlertRule(c *models.ReqContext, ar definitions.ProvisionedAlertRule) response.Response {
@@ -287,6 +289,14 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *models.ReqContext, ar definitio
 	}
 	updated.OrgID = c.OrgID
 	updated.UID = UID
+	conditional := resourceversion.IsConditional(c.Req)
+	if conditional {
+		// Updating fails with store.ErrOptimisticLock if the rule is changed after the check
+		updated.Version, err = srv.checkAlertRuleResourceVersion(c, UID)
+		if err != nil {
+			return alertRuleResourceVersionErrResp(err)
+		}
+	}
 	provenance := determineProvenance(c)
 	updatedAlertRule, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), updated, provenance)
 	if errors.Is(err, alerting_models.ErrAlertRuleNotFound) {
@@ -297,16 +307,25 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *models.ReqContext, ar definitio
 	}
 	if err != nil {
 		if errors.Is(err, store.ErrOptimisticLock) {
+			if conditional {
+				return ErrResp(http.StatusPreconditionFailed, resourceversion.ErrMismatch, "")
+			}
 			return ErrResp(http.StatusConflict, err, "")
 		}
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 
 	resp := definitions.NewAlertRule(updatedAlertRule, provenance)
-	return response.JSON(http.StatusOK, resp)
+	return response.JSON(http.StatusOK, resp).
+		SetHeader(resourceversion.ETagHeader, resourceversion.ETag(updatedAlertRule.Version))
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *models.ReqContext, UID string) response.Response {
+	if resourceversion.IsConditional(c.Req) {
+		if _, err := srv.checkAlertRuleResourceVersion(c, UID); err != nil {
+			return alertRuleResourceVersionErrResp(err)
+		}
+	}
 	err := srv.alertRules.DeleteAlertRule(c.Req.Context(), c.OrgID, UID, alerting_models.ProvenanceAPI)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
@@ -314,6 +333,29 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *models.ReqContext, UID strin
 	return response.JSON(http.StatusNoContent, "")
 }
 
+// checkAlertRuleResourceVersion checks the If-Match header of the request against the stored
+// version of the alert rule and returns the version.
+func (srv *ProvisioningSrv) checkAlertRuleResourceVersion(c *models.ReqContext, UID string) (int64, error) {
+	rule, _, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.OrgID, UID)
+	if err != nil {
+		return 0, err
+	}
+	if err := resourceversion.Check(c.Req, rule.Version); err != nil {
+		return 0, err
+	}
+	return rule.Version, nil
+}
+
+func alertRuleResourceVersionErrResp(err error) response.Response {
+	switch {
+	case errors.Is(err, alerting_models.ErrAlertRuleNotFound):
+		return response.Empty(http.StatusNotFound)
+	case errors.Is(err, resourceversion.ErrMismatch):
+		return ErrResp(http.StatusPreconditionFailed, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRuleGroup(c *models.ReqContext, folder string, group string) response.Response {
 	g, err := srv.alertRules.GetRuleGroup(c.Req.Context(), c.OrgID, folder, group)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -303,6 +304,44 @@ func TestProvisioningApi(t *testing.T) {
 			require.Equal(t, 404, response.Status())
 		})
 
+		t.Run("are changed since the If-Match version", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rule := createTestAlertRule("rule", 1)
+			rule.UID = t.Name()
+			insertRule(t, sut, rule)
+			rc := createTestRequestCtx()
+			etag := sut.RouteRouteGetAlertRule(&rc, rule.UID).(*response.NormalResponse).Header().Get("ETag")
+			require.NotEmpty(t, etag)
+
+			t.Run("PUT returns 412", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Header = http.Header{"If-Match": {`"999"`}}
+
+				response := sut.RoutePutAlertRule(&rc, rule, rule.UID)
+
+				require.Equal(t, 412, response.Status())
+			})
+
+			t.Run("DELETE returns 412", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Header = http.Header{"If-Match": {`"999"`}}
+
+				response := sut.RouteDeleteAlertRule(&rc, rule.UID)
+
+				require.Equal(t, 412, response.Status())
+			})
+
+			t.Run("PUT with the current version returns 200", func(t *testing.T) {
+				rc := createTestRequestCtx()
+				rc.Req.Header = http.Header{"If-Match": {etag}}
+
+				resp := sut.RoutePutAlertRule(&rc, rule, rule.UID)
+
+				require.Equal(t, 200, resp.Status())
+				require.NotEqual(t, etag, resp.(*response.NormalResponse).Header().Get("ETag"))
+			})
+		})
+
 		t.Run("have reached the rule quota, POST returns 403", func(t *testing.T) {
 			env := createTestEnv(t)
 			quotas := provisioning.MockQuotaChecker{}
@@ -433,7 +472,7 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		policies:            newFakeNotificationPolicyService(),
 		contactPointService: provisioning.NewContactPointService(env.configs, env.secrets, env.prov, env.xact, env.log),
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
-		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
+		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, nil, env.log),
 		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.quotas, env.xact, 60, 10, env.log),
 	}
 }
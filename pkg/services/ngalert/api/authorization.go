@@ -118,6 +118,8 @@ func (api *API) authorize(method, path string) web.Handler {
 	// Grafana Prometheus-compatible Paths
 	case http.MethodGet + "/api/prometheus/grafana/api/v1/alerts":
 		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
+	case http.MethodPost + "/api/prometheus/grafana/api/v1/alerts/acknowledgement":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceUpdate)
 
 	// Silences. External AM.
 	case http.MethodDelete + "/api/alertmanager/{DatasourceUID}/api/v2/silence/{SilenceId}":
@@ -46,6 +46,10 @@ func (f *PrometheusApiHandler) handleRouteGetGrafanaRuleStatuses(ctx *models.Req
 	return f.GrafanaSvc.RouteGetRuleStatuses(ctx)
 }
 
+func (f *PrometheusApiHandler) handleRoutePostGrafanaAlertAcknowledgement(ctx *models.ReqContext, body apimodels.PostableAlertAcknowledgement) response.Response {
+	return f.GrafanaSvc.RoutePostAlertAcknowledgement(ctx, body)
+}
+
 func (f *PrometheusApiHandler) getService(ctx *models.ReqContext) (*LotexProm, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -22,6 +23,7 @@ type PrometheusApi interface {
 	RouteGetGrafanaAlertStatuses(*models.ReqContext) response.Response
 	RouteGetGrafanaRuleStatuses(*models.ReqContext) response.Response
 	RouteGetRuleStatuses(*models.ReqContext) response.Response
+	RoutePostGrafanaAlertAcknowledgement(*models.ReqContext) response.Response
 }
 
 func (f *PrometheusApiHandler) RouteGetAlertStatuses(ctx *models.ReqContext) response.Response {
@@ -40,6 +42,14 @@ func (f *PrometheusApiHandler) RouteGetRuleStatuses(ctx *models.ReqContext) resp
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
 	return f.handleRouteGetRuleStatuses(ctx, datasourceUIDParam)
 }
+func (f *PrometheusApiHandler) RoutePostGrafanaAlertAcknowledgement(ctx *models.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableAlertAcknowledgement{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaAlertAcknowledgement(ctx, conf)
+}
 
 func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -83,5 +93,15 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/alerts/acknowledgement"),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/alerts/acknowledgement"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/alerts/acknowledgement",
+				srv.RoutePostGrafanaAlertAcknowledgement,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
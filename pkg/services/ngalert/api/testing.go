@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
@@ -46,6 +47,22 @@ func (f *fakeAlertInstanceManager) GetStatesForRuleUID(orgID int64, alertRuleUID
 	return f.states[orgID][alertRuleUID]
 }
 
+func (f *fakeAlertInstanceManager) GetRuleWarning(orgID int64, alertRuleUID string) string {
+	return ""
+}
+
+func (f *fakeAlertInstanceManager) UpdateAcknowledgement(_ context.Context, cmd state.AcknowledgementCommand) (*state.State, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, s := range f.states[cmd.OrgID][cmd.RuleUID] {
+		if s.Labels.String() == cmd.Labels.String() {
+			s.Acknowledgement = &state.Acknowledgement{AcknowledgedBy: cmd.UserID, AssigneeID: cmd.AssigneeID}
+			return s, nil
+		}
+	}
+	return nil, state.ErrAlertInstanceNotFound
+}
+
 // forEachState represents the callback used when generating alert instances that allows us to modify the generated result
 type forEachState func(s *state.State) *state.State
 
@@ -36,6 +36,18 @@ import (
 //       200: AlertResponse
 //       404: NotFound
 
+// swagger:route POST /api/prometheus/grafana/api/v1/alerts/acknowledgement prometheus RoutePostGrafanaAlertAcknowledgement
+//
+// acknowledges, snoozes or assigns a firing alert
+//
+// Notifications of acknowledged alerts are suppressed until they stop firing, and notifications of
+// snoozed alerts until the snooze ends.
+//
+//     Responses:
+//       200: Alert
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:model
 type RuleResponse struct {
 	// in: body
@@ -121,6 +133,8 @@ type Rule struct {
 	// required: true
 	Health    string `json:"health"`
 	LastError string `json:"lastError,omitempty"`
+	// Warning is set when the last evaluation produced more series than the alert instance limit.
+	Warning string `json:"warning,omitempty"`
 	// required: true
 	Type           v1.RuleType `json:"type"`
 	LastEvaluation time.Time   `json:"lastEvaluation"`
@@ -139,6 +153,20 @@ type Alert struct {
 	ActiveAt *time.Time `json:"activeAt"`
 	// required: true
 	Value string `json:"value"`
+	// Acknowledgement is set while users acknowledged, snoozed or are assigned to the alert.
+	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
+}
+
+// AlertAcknowledgement records how responders handle a firing alert.
+// swagger:model
+type AlertAcknowledgement struct {
+	// The user who acknowledged the alert.
+	AcknowledgedBy int64      `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	// Notifications of the alert are suppressed until this time.
+	SnoozedUntil *time.Time `json:"snoozedUntil,omitempty"`
+	// The user the alert is assigned to.
+	AssigneeID int64 `json:"assigneeId,omitempty"`
 }
 
 // override the labels type with a map for generation.
@@ -172,3 +200,25 @@ type GetGrafanaRuleStatusesParams struct {
 	// required: false
 	PanelID int64
 }
+
+// swagger:parameters RoutePostGrafanaAlertAcknowledgement
+type PostAlertAcknowledgementParams struct {
+	// in:body
+	Body PostableAlertAcknowledgement
+}
+
+// swagger:model
+type PostableAlertAcknowledgement struct {
+	// required: true
+	RuleUID string `json:"ruleUid"`
+	// Labels of the alert, with or without the internal labels of Grafana.
+	// required: true
+	Labels map[string]string `json:"labels"`
+	// required: true
+	// enum: acknowledge,unacknowledge,snooze,assign
+	Action string `json:"action"`
+	// Duration of a snooze, e.g. 1h.
+	SnoozeFor string `json:"snoozeFor,omitempty"`
+	// The user to assign the alert to, or 0 to remove the assignment.
+	AssigneeID int64 `json:"assigneeId,omitempty"`
+}
@@ -3,6 +3,7 @@ package definitions
 import (
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/resourceversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/common/model"
 )
@@ -101,6 +102,9 @@ type ProvisionedAlertRule struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// readonly: true
 	Provenance models.Provenance `json:"provenance,omitempty"`
+	// Version of the alert rule to send in the If-Match header of updates
+	// readonly: true
+	ResourceVersion string `json:"resourceVersion,omitempty"`
 }
 
 func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
@@ -124,21 +128,22 @@ func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
 
 func NewAlertRule(rule models.AlertRule, provenance models.Provenance) ProvisionedAlertRule {
 	return ProvisionedAlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         rule.Data,
-		Updated:      rule.Updated,
-		NoDataState:  rule.NoDataState,
-		ExecErrState: rule.ExecErrState,
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   provenance,
+		ID:              rule.ID,
+		UID:             rule.UID,
+		OrgID:           rule.OrgID,
+		FolderUID:       rule.NamespaceUID,
+		RuleGroup:       rule.RuleGroup,
+		Title:           rule.Title,
+		For:             model.Duration(rule.For),
+		Condition:       rule.Condition,
+		Data:            rule.Data,
+		Updated:         rule.Updated,
+		NoDataState:     rule.NoDataState,
+		ExecErrState:    rule.ExecErrState,
+		Annotations:     rule.Annotations,
+		Labels:          rule.Labels,
+		Provenance:      provenance,
+		ResourceVersion: resourceversion.Format(rule.Version),
 	}
 }
 
@@ -111,7 +111,7 @@ func (e *EmbeddedContactPoint) Valid(decryptFunc channels.GetDecryptedValueFn) e
 	cfg, _ := channels.NewFactoryConfig(&channels.NotificationChannelConfig{
 		Settings: e.Settings,
 		Type:     e.Type,
-	}, nil, decryptFunc, nil, nil)
+	}, nil, decryptFunc, nil, nil, nil)
 	if _, err := factory(cfg); err != nil {
 		return err
 	}
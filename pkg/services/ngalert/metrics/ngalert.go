@@ -76,8 +76,10 @@ type Alertmanager struct {
 }
 
 type State struct {
-	GroupRules *prometheus.GaugeVec
-	AlertState *prometheus.GaugeVec
+	GroupRules         *prometheus.GaugeVec
+	AlertState         *prometheus.GaugeVec
+	LimitedEvaluations *prometheus.CounterVec
+	LimitedSeries      *prometheus.CounterVec
 }
 
 func (ng *NGAlert) GetSchedulerMetrics() *Scheduler {
@@ -231,6 +233,18 @@ func newStateMetrics(r prometheus.Registerer) *State {
 			Name:      "alerts",
 			Help:      "How many alerts by state.",
 		}, []string{"state"}),
+		LimitedEvaluations: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "rule_instance_limit_evaluations_total",
+			Help:      "The number of rule evaluations which produced more series than the alert instance limit.",
+		}, []string{"org", "action"}),
+		LimitedSeries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "rule_instance_limit_series_total",
+			Help:      "The number of series which were aggregated or dropped because of the alert instance limit.",
+		}, []string{"org", "action"}),
 	}
 }
 
@@ -114,6 +114,13 @@ const (
 
 	ValuesAnnotation      = "__values__"
 	ValueStringAnnotation = "__value_string__"
+
+	// AcknowledgedAnnotation is set on alerts acknowledged by a user. The Grafana Alertmanager does not
+	// send notifications for them.
+	AcknowledgedAnnotation = "__acknowledged__"
+	// SnoozedUntilAnnotation contains the time until which the Grafana Alertmanager does not send
+	// notifications for a snoozed alert, in RFC 3339 format.
+	SnoozedUntilAnnotation = "__snoozed_until__"
 )
 
 var (
@@ -14,6 +14,10 @@ type AlertInstance struct {
 	CurrentStateSince time.Time
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
+	AcknowledgedBy    int64
+	AcknowledgedAt    time.Time
+	SnoozedUntil      time.Time
+	AssigneeID        int64 `xorm:"assignee_id"`
 }
 
 type AlertInstanceKey struct {
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/startup/providers"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -34,7 +35,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/oncall"
+	"github.com/grafana/grafana/pkg/services/org"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -62,7 +67,12 @@ func ProvideService(
 	bus bus.Bus,
 	accesscontrolService accesscontrol.Service,
 	annotationsRepo annotations.Repository,
+	notificationCenter notificationcenter.Service,
+	onCallService oncall.Service,
+	orgService org.Service,
+	preferenceService pref.Service,
 ) (*AlertNG, error) {
+	defer providers.Track("ngalert")()
 	ng := &AlertNG{
 		Cfg:                  cfg,
 		FeatureToggles:       featureToggles,
@@ -85,6 +95,10 @@ func ProvideService(
 		bus:                  bus,
 		accesscontrolService: accesscontrolService,
 		annotationsRepo:      annotationsRepo,
+		notificationCenter:   notificationCenter,
+		onCallService:        onCallService,
+		orgService:           orgService,
+		preferenceService:    preferenceService,
 	}
 
 	if ng.IsDisabled() {
@@ -127,6 +141,10 @@ type AlertNG struct {
 	accesscontrol        accesscontrol.AccessControl
 	accesscontrolService accesscontrol.Service
 	annotationsRepo      annotations.Repository
+	notificationCenter   notificationcenter.Service
+	onCallService        oncall.Service
+	orgService           org.Service
+	preferenceService    pref.Service
 	store                *store.DBstore
 
 	bus bus.Bus
@@ -148,7 +166,7 @@ func (ng *AlertNG) init() error {
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
-	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, store, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"), ng.SecretsService)
+	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, store, decryptFn, multiOrgMetrics, ng.NotificationService, ng.onCallService, log.New("ngalert.multiorg.alertmanager"), ng.SecretsService)
 	if err != nil {
 		return err
 	}
@@ -159,11 +177,6 @@ func (ng *AlertNG) init() error {
 	}
 	ng.imageService = imageService
 
-	// Let's make sure we're able to complete an initial sync of Alertmanagers before we start the alerting components.
-	if err := ng.MultiOrgAlertmanager.LoadAndSyncAlertmanagersForOrgs(context.Background()); err != nil {
-		return fmt.Errorf("failed to initialize alerting because multiorg alertmanager manager failed to warm up: %w", err)
-	}
-
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
 	if err != nil {
 		ng.Log.Error("Failed to parse application URL. Continue without it.", "error", err)
@@ -175,11 +188,6 @@ func (ng *AlertNG) init() error {
 	alertsRouter := sender.NewAlertsRouter(ng.MultiOrgAlertmanager, store, clk, appUrl, ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		ng.Cfg.UnifiedAlerting.AdminConfigPollInterval, ng.DataSourceService, ng.SecretsService)
 
-	// Make sure we sync at least once as Grafana starts to get the router up and running before we start sending any alerts.
-	if err := alertsRouter.SyncAndApplyConfigFromDatabase(); err != nil {
-		return fmt.Errorf("failed to initialize alerting because alert notifications router failed to warm up: %w", err)
-	}
-
 	ng.AlertsRouter = alertsRouter
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService)
@@ -190,10 +198,15 @@ func (ng *AlertNG) init() error {
 		RuleStore:        store,
 		Metrics:          ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:      alertsRouter,
+		Notifications:    ng.notificationCenter,
 	}
 
 	historian := historian.NewAnnotationHistorian(ng.annotationsRepo, ng.dashboardService)
-	stateManager := state.NewManager(ng.Metrics.GetStateMetrics(), appUrl, store, ng.imageService, clk, historian)
+	instanceLimit := state.InstanceLimit{
+		MaxInstances: int(ng.Cfg.UnifiedAlerting.InstanceLimits.MaxInstancesPerRule),
+		Action:       state.InstanceLimitAction(ng.Cfg.UnifiedAlerting.InstanceLimits.Action),
+	}
+	stateManager := state.NewManager(ng.Metrics.GetStateMetrics(), appUrl, store, ng.imageService, clk, historian, instanceLimit)
 	scheduler := schedule.NewScheduler(schedCfg, appUrl, stateManager)
 
 	// if it is required to include folder title to the alerts, we need to subscribe to changes of alert title
@@ -208,7 +221,7 @@ func (ng *AlertNG) init() error {
 	policyService := provisioning.NewNotificationPolicyService(store, store, store, ng.Cfg.UnifiedAlerting, ng.Log)
 	contactPointService := provisioning.NewContactPointService(store, ng.SecretsService, store, store, ng.Log)
 	templateService := provisioning.NewTemplateService(store, store, store, ng.Log)
-	muteTimingService := provisioning.NewMuteTimingService(store, store, store, ng.Log)
+	muteTimingService := provisioning.NewMuteTimingService(store, store, store, ng.preferenceService, ng.Log)
 	alertRuleService := provisioning.NewAlertRuleService(store, store, ng.QuotaService, store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
@@ -236,6 +249,7 @@ func (ng *AlertNG) init() error {
 		AlertRules:           alertRuleService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
+		OrgService:           ng.orgService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -288,6 +302,18 @@ func subscribeToFolderChanges(logger log.Logger, bus bus.Bus, dbStore api.RuleSt
 	})
 }
 
+// Init loads the Alertmanagers of the organizations and the configuration of the alerts router,
+// which have to be synced at least once before the alerting components start.
+func (ng *AlertNG) Init(ctx context.Context) error {
+	if err := ng.MultiOrgAlertmanager.LoadAndSyncAlertmanagersForOrgs(ctx); err != nil {
+		return fmt.Errorf("failed to initialize alerting because multiorg alertmanager manager failed to warm up: %w", err)
+	}
+	if err := ng.AlertsRouter.SyncAndApplyConfigFromDatabase(); err != nil {
+		return fmt.Errorf("failed to initialize alerting because alert notifications router failed to warm up: %w", err)
+	}
+	return nil
+}
+
 // Run starts the scheduler and Alertmanager.
 func (ng *AlertNG) Run(ctx context.Context) error {
 	ng.Log.Debug("Starting")
@@ -322,6 +348,19 @@ func (ng *AlertNG) IsDisabled() bool {
 	return !ng.Cfg.UnifiedAlerting.IsEnabled()
 }
 
+// GetAlertRuleByUID returns an alert rule of the organization. It returns models.ErrAlertRuleNotFound
+// if the rule does not exist or the alerting service is disabled.
+func (ng *AlertNG) GetAlertRuleByUID(ctx context.Context, orgID int64, uid string) (*models.AlertRule, error) {
+	if ng.store == nil {
+		return nil, models.ErrAlertRuleNotFound
+	}
+	query := &models.GetAlertRuleByUIDQuery{UID: uid, OrgID: orgID}
+	if err := ng.store.GetAlertRuleByUID(ctx, query); err != nil {
+		return nil, err
+	}
+	return query.Result, nil
+}
+
 func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
 	limits := &quota.Map{}
 
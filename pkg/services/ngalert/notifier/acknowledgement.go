@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// acknowledgementStage drops the firing alerts which users acknowledged or snoozed, so that
+// responders are not notified again while they handle them. Resolved alerts pass, and the dedup
+// stage decides whether their resolution is sent.
+type acknowledgementStage struct {
+	now func() time.Time
+}
+
+func newAcknowledgementStage() *acknowledgementStage {
+	return &acknowledgementStage{now: time.Now}
+}
+
+func (s *acknowledgementStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	now := s.now()
+	var filtered []*types.Alert
+	for _, a := range alerts {
+		if a.ResolvedAt(now) || !suppressed(a.Annotations, now) {
+			filtered = append(filtered, a)
+		}
+	}
+	return ctx, filtered, nil
+}
+
+// suppressed returns true if the annotations of an alert suppress its notifications at a time.
+func suppressed(annotations model.LabelSet, at time.Time) bool {
+	if _, ok := annotations[ngmodels.AcknowledgedAnnotation]; ok {
+		return true
+	}
+	until, ok := annotations[ngmodels.SnoozedUntilAnnotation]
+	if !ok {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, string(until))
+	return err == nil && at.Before(t)
+}
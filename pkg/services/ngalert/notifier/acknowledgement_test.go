@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAcknowledgementStage(t *testing.T) {
+	now := time.Date(2022, 11, 28, 9, 0, 0, 0, time.UTC)
+	stage := &acknowledgementStage{now: func() time.Time { return now }}
+
+	newAlert := func(name string, endsAt time.Time, annotations model.LabelSet) *types.Alert {
+		return &types.Alert{Alert: model.Alert{
+			Labels:      model.LabelSet{model.AlertNameLabel: model.LabelValue(name)},
+			Annotations: annotations,
+			StartsAt:    now.Add(-time.Hour),
+			EndsAt:      endsAt,
+		}}
+	}
+	firing := now.Add(time.Minute)
+	alerts := []*types.Alert{
+		newAlert("plain", firing, model.LabelSet{}),
+		newAlert("acknowledged", firing, model.LabelSet{ngmodels.AcknowledgedAnnotation: "true"}),
+		newAlert("snoozed", firing, model.LabelSet{ngmodels.SnoozedUntilAnnotation: model.LabelValue(now.Add(time.Hour).Format(time.RFC3339))}),
+		newAlert("snooze ended", firing, model.LabelSet{ngmodels.SnoozedUntilAnnotation: model.LabelValue(now.Add(-time.Second).Format(time.RFC3339))}),
+		newAlert("resolved", now.Add(-time.Minute), model.LabelSet{ngmodels.AcknowledgedAnnotation: "true"}),
+	}
+
+	_, filtered, err := stage.Exec(context.Background(), nil, alerts...)
+	require.NoError(t, err)
+	var names []string
+	for _, a := range filtered {
+		names = append(names, string(a.Labels[model.AlertNameLabel]))
+	}
+	require.Equal(t, []string{"plain", "snooze ended", "resolved"}, names)
+}
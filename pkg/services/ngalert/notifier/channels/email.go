@@ -30,6 +30,7 @@ type EmailNotifier struct {
 	ns          notifications.EmailSender
 	images      ImageStore
 	tmpl        *template.Template
+	orgID       int64
 }
 
 type EmailConfig struct {
@@ -86,6 +87,7 @@ func NewEmailNotifier(config *EmailConfig, ns notifications.EmailSender, images
 		ns:          ns,
 		images:      images,
 		tmpl:        t,
+		orgID:       config.OrgID,
 	}
 }
 
@@ -145,6 +147,7 @@ func (en *EmailNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bo
 			To:            en.Addresses,
 			SingleEmail:   en.SingleEmail,
 			Template:      "ng_alert_notification",
+			OrgID:         en.orgID,
 		},
 	}
 
@@ -18,6 +18,8 @@ type FactoryConfig struct {
 	ImageStore          ImageStore
 	// Used to retrieve image URLs for messages, or data for uploads.
 	Template *template.Template
+	// OnCall resolves the users on call for the oncall notifier. It is nil if schedules are not available.
+	OnCall OnCallResolver
 }
 
 type ImageStore interface {
@@ -25,7 +27,7 @@ type ImageStore interface {
 }
 
 func NewFactoryConfig(config *NotificationChannelConfig, notificationService notifications.Service,
-	decryptFunc GetDecryptedValueFn, template *template.Template, imageStore ImageStore) (FactoryConfig, error) {
+	decryptFunc GetDecryptedValueFn, template *template.Template, imageStore ImageStore, onCall OnCallResolver) (FactoryConfig, error) {
 	if config.Settings == nil {
 		return FactoryConfig{}, errors.New("no settings supplied")
 	}
@@ -44,6 +46,7 @@ func NewFactoryConfig(config *NotificationChannelConfig, notificationService not
 		DecryptFunc:         decryptFunc,
 		Template:            template,
 		ImageStore:          imageStore,
+		OnCall:              onCall,
 	}, nil
 }
 
@@ -55,6 +58,7 @@ var receiverFactories = map[string]func(FactoryConfig) (NotificationChannel, err
 	"googlechat":              GoogleChatFactory,
 	"kafka":                   KafkaFactory,
 	"line":                    LineFactory,
+	"oncall":                  OnCallFactory,
 	"opsgenie":                OpsgenieFactory,
 	"pagerduty":               PagerdutyFactory,
 	"pushover":                PushoverFactory,
@@ -0,0 +1,140 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// OnCallResolver returns the email addresses of the users who are currently on call for an
+// on-call schedule.
+type OnCallResolver interface {
+	OnCallAddresses(ctx context.Context, orgID int64, scheduleUID string) ([]string, error)
+}
+
+// OnCallNotifier sends alert notifications by email to the users who are on call for a
+// schedule when the notification is sent.
+type OnCallNotifier struct {
+	*Base
+	ScheduleUID       string
+	FallbackAddresses []string
+	SingleEmail       bool
+	Message           string
+	Subject           string
+	log               log.Logger
+	resolver          OnCallResolver
+	ns                notifications.EmailSender
+	images            ImageStore
+	tmpl              *template.Template
+	config            *NotificationChannelConfig
+}
+
+type OnCallConfig struct {
+	*NotificationChannelConfig
+	ScheduleUID       string
+	FallbackAddresses []string
+	SingleEmail       bool
+	Message           string
+	Subject           string
+}
+
+func OnCallFactory(fc FactoryConfig) (NotificationChannel, error) {
+	cfg, err := NewOnCallConfig(fc.Config)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return NewOnCallNotifier(cfg, fc.OnCall, fc.NotificationService, fc.ImageStore, fc.Template), nil
+}
+
+func NewOnCallConfig(config *NotificationChannelConfig) (*OnCallConfig, error) {
+	scheduleUID := config.Settings.Get("scheduleUid").MustString()
+	if scheduleUID == "" {
+		return nil, errors.New("could not find scheduleUid in settings")
+	}
+	var fallbackAddresses []string
+	if addresses := config.Settings.Get("fallbackAddresses").MustString(); addresses != "" {
+		fallbackAddresses = util.SplitEmails(addresses)
+	}
+	return &OnCallConfig{
+		NotificationChannelConfig: config,
+		ScheduleUID:               scheduleUID,
+		FallbackAddresses:         fallbackAddresses,
+		SingleEmail:               config.Settings.Get("singleEmail").MustBool(false),
+		Message:                   config.Settings.Get("message").MustString(),
+		Subject:                   config.Settings.Get("subject").MustString(DefaultMessageTitleEmbed),
+	}, nil
+}
+
+// NewOnCallNotifier is the constructor function
+// for the OnCallNotifier.
+func NewOnCallNotifier(config *OnCallConfig, resolver OnCallResolver, ns notifications.EmailSender, images ImageStore, t *template.Template) *OnCallNotifier {
+	return &OnCallNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   config.UID,
+			Name:                  config.Name,
+			Type:                  config.Type,
+			DisableResolveMessage: config.DisableResolveMessage,
+			Settings:              config.Settings,
+		}),
+		ScheduleUID:       config.ScheduleUID,
+		FallbackAddresses: config.FallbackAddresses,
+		SingleEmail:       config.SingleEmail,
+		Message:           config.Message,
+		Subject:           config.Subject,
+		log:               log.New("alerting.notifier.oncall"),
+		resolver:          resolver,
+		ns:                ns,
+		images:            images,
+		tmpl:              t,
+		config:            config.NotificationChannelConfig,
+	}
+}
+
+// Notify looks up who is on call and sends them the alert notification by email.
+func (on *OnCallNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	addresses, err := on.addresses(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	email := NewEmailNotifier(&EmailConfig{
+		NotificationChannelConfig: on.config,
+		SingleEmail:               on.SingleEmail,
+		Addresses:                 addresses,
+		Message:                   on.Message,
+		Subject:                   on.Subject,
+	}, on.ns, on.images, on.tmpl)
+	return email.Notify(ctx, alerts...)
+}
+
+// addresses returns the addresses of the users on call, or the fallback addresses if nobody is on
+// call or the schedule cannot be resolved.
+func (on *OnCallNotifier) addresses(ctx context.Context) ([]string, error) {
+	if on.resolver != nil {
+		addresses, err := on.resolver.OnCallAddresses(ctx, on.config.OrgID, on.ScheduleUID)
+		if err != nil {
+			on.log.Warn("failed to look up who is on call", "schedule", on.ScheduleUID, "error", err)
+		} else if len(addresses) > 0 {
+			return addresses, nil
+		}
+	}
+	if len(on.FallbackAddresses) > 0 {
+		return on.FallbackAddresses, nil
+	}
+	return nil, fmt.Errorf("nobody is on call for schedule %s and no fallback addresses are configured", on.ScheduleUID)
+}
+
+func (on *OnCallNotifier) SendResolved() bool {
+	return !on.GetDisableResolveMessage()
+}
@@ -0,0 +1,93 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type fakeOnCallResolver struct {
+	addresses []string
+	err       error
+}
+
+func (f *fakeOnCallResolver) OnCallAddresses(_ context.Context, _ int64, _ string) ([]string, error) {
+	return f.addresses, f.err
+}
+
+func TestOnCallNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost/base")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "AlwaysFiring", "severity": "warning"},
+			},
+		},
+	}
+
+	newConfig := func(t *testing.T, json string) *OnCallConfig {
+		settingsJSON, err := simplejson.NewJson([]byte(json))
+		require.NoError(t, err)
+		cfg, err := NewOnCallConfig(&NotificationChannelConfig{
+			Name:     "ops",
+			Type:     "oncall",
+			OrgID:    1,
+			Settings: settingsJSON,
+		})
+		require.NoError(t, err)
+		return cfg
+	}
+
+	t.Run("settings without schedule should return error", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{ "fallbackAddresses": "ops@example.com" }`))
+		require.NoError(t, err)
+		_, err = NewOnCallConfig(&NotificationChannelConfig{Name: "ops", Type: "oncall", Settings: settingsJSON})
+		require.Error(t, err)
+	})
+
+	t.Run("sends the notification to the users on call", func(t *testing.T) {
+		emailSender := mockNotificationService()
+		resolver := &fakeOnCallResolver{addresses: []string{"alice@example.com"}}
+		cfg := newConfig(t, `{ "scheduleUid": "primary", "fallbackAddresses": "ops@example.com" }`)
+		notifier := NewOnCallNotifier(cfg, resolver, emailSender, &UnavailableImageStore{}, tmpl)
+
+		ok, err := notifier.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []string{"alice@example.com"}, emailSender.EmailSync.To)
+		require.Equal(t, "[FIRING:1]  (AlwaysFiring warning)", emailSender.EmailSync.Subject)
+	})
+
+	t.Run("sends the notification to the fallback addresses if nobody is on call", func(t *testing.T) {
+		cfg := newConfig(t, `{ "scheduleUid": "primary", "fallbackAddresses": "ops@example.com;dev@example.com" }`)
+		for _, resolver := range []OnCallResolver{nil, &fakeOnCallResolver{}, &fakeOnCallResolver{err: errors.New("schedule not found")}} {
+			emailSender := mockNotificationService()
+			notifier := NewOnCallNotifier(cfg, resolver, emailSender, &UnavailableImageStore{}, tmpl)
+
+			_, err := notifier.Notify(context.Background(), alerts...)
+			require.NoError(t, err)
+			require.Equal(t, []string{"ops@example.com", "dev@example.com"}, emailSender.EmailSync.To)
+		}
+	})
+
+	t.Run("fails without anybody to notify", func(t *testing.T) {
+		cfg := newConfig(t, `{ "scheduleUid": "primary" }`)
+		notifier := NewOnCallNotifier(cfg, &fakeOnCallResolver{}, mockNotificationService(), &UnavailableImageStore{}, tmpl)
+
+		ok, err := notifier.Notify(context.Background(), alerts...)
+		require.ErrorContains(t, err, "nobody is on call")
+		require.False(t, ok)
+	})
+}
@@ -223,6 +223,48 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "oncall",
+			Name:        "On-call schedule",
+			Description: "Sends emails to the users currently on call for an on-call schedule",
+			Heading:     "On-call settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Schedule UID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "UID of the on-call schedule",
+					PropertyName: "scheduleUid",
+					Required:     true,
+				},
+				{
+					Label:        "Fallback addresses",
+					Description:  "Addresses notified when nobody is on call. You can enter multiple email addresses using a \";\" separator",
+					Element:      ElementTypeTextArea,
+					PropertyName: "fallbackAddresses",
+				},
+				{
+					Label:        "Single email",
+					Description:  "Send a single email to all recipients",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "singleEmail",
+				},
+				{
+					Label:        "Message",
+					Description:  "Optional message to include with the email. You can use template variables",
+					Element:      ElementTypeTextArea,
+					PropertyName: "message",
+				},
+				{
+					Label:        "Subject",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated subject of the email",
+					PropertyName: "subject",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+				},
+			},
+		},
 		{
 			Type:        "pagerduty",
 			Name:        "PagerDuty",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -86,6 +87,7 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule model
 		}
 		if id, ok := ids[rule.UID]; ok {
 			rule.ID = id
+			rule.Version = 1
 		} else {
 			return errors.New("couldn't find newly created id")
 		}
@@ -264,7 +266,8 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 
 // CreateAlertRule creates a new alert rule. This function will ignore any
 // interval that is set in the rule struct and fetch the current group interval
-// from database.
+// from database. If the version of the rule is set, the update fails with
+// store.ErrOptimisticLock unless it is the version of the stored rule.
 func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule models.AlertRule, provenance models.Provenance) (models.AlertRule, error) {
 	storedRule, storedProvenance, err := service.GetAlertRule(ctx, rule.OrgID, rule.UID)
 	if err != nil {
@@ -273,6 +276,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
 		return models.AlertRule{}, fmt.Errorf("cannot changed provenance from '%s' to '%s'", storedProvenance, provenance)
 	}
+	if rule.Version != 0 && rule.Version != storedRule.Version {
+		return models.AlertRule{}, fmt.Errorf("%w: alert rule UID %s version %d", store.ErrOptimisticLock, rule.UID, storedRule.Version)
+	}
 	rule.Updated = time.Now()
 	rule.ID = storedRule.ID
 	rule.IntervalSeconds = storedRule.IntervalSeconds
@@ -295,6 +301,7 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	rule.Version = storedRule.Version + 1
 	return rule, err
 }
 
@@ -316,6 +323,22 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, orgID int6
 	})
 }
 
+// GetAlertRuleUIDsByProvenance returns the UIDs of the alert rules in the organization with the provenance.
+func (service *AlertRuleService) GetAlertRuleUIDsByProvenance(ctx context.Context, orgID int64, provenance models.Provenance) ([]string, error) {
+	provenances, err := service.provenanceStore.GetProvenances(ctx, orgID, (&models.AlertRule{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+	uids := make([]string, 0, len(provenances))
+	for uid, p := range provenances {
+		if p == provenance {
+			uids = append(uids, uid)
+		}
+	}
+	sort.Strings(uids)
+	return uids, nil
+}
+
 // checkLimitsTransactionCtx checks whether the current transaction (as identified by the ctx) breaches configured alert rule limits.
 func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context, orgID, userID int64) error {
 	limitReached, err := service.quotas.CheckQuotaReached(ctx, "alert_rule", &quota.ScopeParameters{
@@ -3,25 +3,32 @@ package provisioning
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
 )
 
 type MuteTimingService struct {
 	config AMConfigStore
 	prov   ProvisioningStore
 	xact   TransactionManager
-	log    log.Logger
+	// prefs is optional, without it time intervals without a location are evaluated in UTC.
+	prefs pref.Service
+	log   log.Logger
 }
 
-func NewMuteTimingService(config AMConfigStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *MuteTimingService {
+func NewMuteTimingService(config AMConfigStore, prov ProvisioningStore, xact TransactionManager, prefs pref.Service, log log.Logger) *MuteTimingService {
 	return &MuteTimingService{
 		config: config,
 		prov:   prov,
 		xact:   xact,
+		prefs:  prefs,
 		log:    log,
 	}
 }
@@ -49,6 +56,9 @@ func (svc *MuteTimingService) CreateMuteTiming(ctx context.Context, mt definitio
 	if err := mt.Validate(); err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 	}
+	if err := svc.defaultLocation(ctx, &mt, orgID); err != nil {
+		return nil, err
+	}
 
 	revision, err := getLastConfiguration(ctx, orgID, svc.config)
 	if err != nil {
@@ -99,6 +109,9 @@ func (svc *MuteTimingService) UpdateMuteTiming(ctx context.Context, mt definitio
 	if err := mt.Validate(); err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 	}
+	if err := svc.defaultLocation(ctx, &mt, orgID); err != nil {
+		return nil, err
+	}
 
 	revision, err := getLastConfiguration(ctx, orgID, svc.config)
 	if err != nil {
@@ -194,6 +207,32 @@ func (svc *MuteTimingService) DeleteMuteTiming(ctx context.Context, name string,
 	})
 }
 
+// defaultLocation sets the location of the time intervals without one to the time zone of the
+// preferences of the organization, so that mute timings agree with the other schedules of the
+// organization on when a time like "Monday 9:00" is.
+func (svc *MuteTimingService) defaultLocation(ctx context.Context, mt *definitions.MuteTimeInterval, orgID int64) error {
+	if svc.prefs == nil {
+		return nil
+	}
+	var loc *time.Location
+	for i := range mt.TimeIntervals {
+		if mt.TimeIntervals[i].Location != nil {
+			continue
+		}
+		if loc == nil {
+			prefs, err := svc.prefs.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{OrgID: orgID})
+			if err != nil {
+				return err
+			}
+			if loc, err = schedule.LoadLocation(schedule.ResolveTimezone(prefs.Timezone)); err != nil {
+				return err
+			}
+		}
+		mt.TimeIntervals[i].Location = &timeinterval.Location{Location: loc}
+	}
+	return nil
+}
+
 func isMuteTimeInUse(name string, routes []*definitions.Route) bool {
 	if len(routes) == 0 {
 		return false
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/preference/preftest"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
 	mock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -165,6 +169,25 @@ func TestMuteTimingService(t *testing.T) {
 		})
 	})
 
+	t.Run("defaults the location of time intervals to the time zone of the organization", func(t *testing.T) {
+		sut := createMuteTimingSvcSut()
+		prefs := preftest.NewPreferenceServiceFake()
+		prefs.ExpectedPreference = &pref.Preference{Timezone: "Europe/Berlin"}
+		sut.prefs = prefs
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		require.NoError(t, err)
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+		timing := createMuteTiming()
+		timing.TimeIntervals = []timeinterval.TimeInterval{{}, {Location: &timeinterval.Location{Location: tokyo}}}
+
+		err = sut.defaultLocation(context.Background(), &timing, 1)
+
+		require.NoError(t, err)
+		require.Equal(t, berlin, timing.TimeIntervals[0].Location.Location)
+		require.Equal(t, tokyo, timing.TimeIntervals[1].Location.Location)
+	})
+
 	t.Run("updating mute timings", func(t *testing.T) {
 		t.Run("rejects mute timings that fail validation", func(t *testing.T) {
 			sut := createMuteTimingSvcSut()
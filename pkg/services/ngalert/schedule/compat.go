@@ -30,6 +30,7 @@ const (
 // stateToPostableAlert converts a state to a model that is accepted by Alertmanager. Annotations and Labels are copied from the state.
 // - if state has at least one result, a new label '__value_string__' is added to the label set
 // - the alert's GeneratorURL is constructed to point to the alert detail view
+// - if the alert is acknowledged or snoozed, the annotation '__acknowledged__' or '__snoozed_until__' is added
 // - if evaluation state is either NoData or Error, the resulting set of labels is changed:
 //   - original alert name (label: model.AlertNameLabel) is backed up to OriginalAlertName
 //   - label model.AlertNameLabel is overwritten to either NoDataAlertName or ErrorAlertName
@@ -60,6 +61,15 @@ func stateToPostableAlert(alertState *state.State, appURL *url.URL) *models.Post
 		nA[ngModels.OrgIDAnnotation] = strconv.FormatInt(alertState.OrgID, 10)
 	}
 
+	if ack := alertState.Acknowledgement; ack != nil {
+		if ack.Acknowledged() {
+			nA[ngModels.AcknowledgedAnnotation] = "true"
+		}
+		if !ack.SnoozedUntil.IsZero() {
+			nA[ngModels.SnoozedUntilAnnotation] = ack.SnoozedUntil.UTC().Format(time.RFC3339)
+		}
+	}
+
 	var urlStr string
 	if uid := nL[ngModels.RuleUIDLabel]; len(uid) > 0 && appURL != nil {
 		u := *appURL
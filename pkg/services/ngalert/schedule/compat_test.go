@@ -133,6 +133,24 @@ func Test_stateToPostableAlert(t *testing.T) {
 
 					require.Equal(t, expected, result.Annotations)
 				})
+
+				t.Run("add __acknowledged__ and __snoozed_until__ if acknowledged and snoozed", func(t *testing.T) {
+					alertState := randomState(tc.state)
+					alertState.Annotations = randomMapOfStrings()
+					snoozedUntil := time.Date(2022, 11, 28, 10, 0, 0, 0, time.UTC)
+					alertState.Acknowledgement = &state.Acknowledgement{AcknowledgedBy: 1, SnoozedUntil: snoozedUntil}
+
+					result := stateToPostableAlert(alertState, appURL)
+
+					expected := make(models.LabelSet, len(alertState.Annotations)+2)
+					for k, v := range alertState.Annotations {
+						expected[k] = v
+					}
+					expected["__acknowledged__"] = "true"
+					expected["__snoozed_until__"] = "2022-11-28T10:00:00Z"
+
+					require.Equal(t, expected, result.Annotations)
+				})
 			})
 
 			t.Run("should add state reason annotation if not empty", func(t *testing.T) {
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -89,6 +90,9 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	// notifications is told when alert rules cannot be evaluated anymore. Optional.
+	notifications notificationcenter.Service
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
@@ -104,6 +108,7 @@ type SchedulerCfg struct {
 	RuleStore        RulesStore
 	Metrics          *metrics.Scheduler
 	AlertSender      AlertsSender
+	Notifications    notificationcenter.Service
 }
 
 // NewScheduler returns a new schedule.
@@ -123,6 +128,7 @@ func NewScheduler(cfg SchedulerCfg, appURL *url.URL, stateManager *state.Manager
 		minRuleInterval:       cfg.Cfg.MinInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		notifications:         cfg.Notifications,
 	}
 
 	return &sch
@@ -320,6 +326,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 		}
 	}
 
+	// broken is set while the rule fails to evaluate, so that the admins are notified only once
+	broken := false
+
 	evaluate := func(ctx context.Context, attempt int64, e *evaluation) {
 		logger := logger.New("version", e.rule.Version, "attempt", attempt, "now", e.scheduledAt)
 		start := sch.clock.Now()
@@ -360,7 +369,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			if results == nil {
 				results = append(results, eval.NewResultFromError(err, e.scheduledAt, dur))
 			}
+			if !broken {
+				broken = true
+				sch.notifyBrokenRule(ctx, e.rule, results)
+			}
 		} else {
+			broken = false
 			logger.Debug("Alert rule evaluated", "results", results, "duration", dur)
 		}
 		if ctx.Err() != nil { // check if the context is not cancelled. The evaluation can be a long-running task.
@@ -430,6 +444,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 							clearState()
 						}
 						currentRuleVersion = newVersion
+						broken = false
 					}
 					evaluate(grafanaCtx, attempt, ctx)
 					return nil
@@ -449,6 +464,34 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	}
 }
 
+// notifyBrokenRule lets the admins of the organization know that an alert rule cannot be evaluated.
+func (sch *schedule) notifyBrokenRule(ctx context.Context, rule *ngmodels.AlertRule, results eval.Results) {
+	if sch.notifications == nil {
+		return
+	}
+
+	var body string
+	for _, r := range results {
+		if r.State == eval.Error && r.Error != nil {
+			body = r.Error.Error()
+			break
+		}
+	}
+
+	_, err := sch.notifications.Notify(ctx, notificationcenter.NotifyCommand{
+		OrgID:     rule.OrgID,
+		OrgAdmins: true,
+		Kind:      notificationcenter.KindAlertRule,
+		Severity:  notificationcenter.SeverityError,
+		Title:     fmt.Sprintf("Alert rule %q cannot be evaluated", rule.Title),
+		Body:      body,
+		URL:       fmt.Sprintf("/alerting/grafana/%s/view", rule.UID),
+	})
+	if err != nil {
+		sch.log.Warn("Failed to notify about broken alert rule", "rule", rule.UID, "orgId", rule.OrgID, "error", err)
+	}
+}
+
 // evalApplied is only used on tests.
 func (sch *schedule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if sch.evalAppliedFunc == nil {
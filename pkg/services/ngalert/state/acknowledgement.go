@@ -0,0 +1,164 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var (
+	ErrAlertInstanceNotFound  = errors.New("alert instance not found")
+	ErrAlertInstanceNotFiring = errors.New("alert instance is not firing")
+	ErrInvalidAcknowledgement = errors.New("invalid acknowledgement")
+)
+
+// AcknowledgementAction is a change of the acknowledgement of a firing alert instance.
+type AcknowledgementAction string
+
+const (
+	// AcknowledgeAction suppresses the notifications of the alert until it stops firing.
+	AcknowledgeAction AcknowledgementAction = "acknowledge"
+	// UnacknowledgeAction removes the acknowledgement and the snooze of the alert.
+	UnacknowledgeAction AcknowledgementAction = "unacknowledge"
+	// SnoozeAction suppresses the notifications of the alert for a duration.
+	SnoozeAction AcknowledgementAction = "snooze"
+	// AssignAction assigns the alert to a user, or removes the assignment.
+	AssignAction AcknowledgementAction = "assign"
+)
+
+// Acknowledgement records how responders handle a firing alert instance. It is cleared when the
+// alert stops firing.
+type Acknowledgement struct {
+	AcknowledgedBy int64
+	AcknowledgedAt time.Time
+	SnoozedUntil   time.Time
+	AssigneeID     int64
+}
+
+// Acknowledged returns true if a user acknowledged the alert.
+func (a *Acknowledgement) Acknowledged() bool {
+	return a != nil && a.AcknowledgedBy != 0
+}
+
+// Snoozed returns true if the alert is snoozed at a time.
+func (a *Acknowledgement) Snoozed(at time.Time) bool {
+	return a != nil && at.Before(a.SnoozedUntil)
+}
+
+func (a *Acknowledgement) empty(at time.Time) bool {
+	return !a.Acknowledged() && !a.Snoozed(at) && (a == nil || a.AssigneeID == 0)
+}
+
+// acknowledgementFromInstance returns the acknowledgement of a stored alert instance, or nil if it
+// has none.
+func acknowledgementFromInstance(instance *ngModels.AlertInstance, at time.Time) *Acknowledgement {
+	ack := &Acknowledgement{
+		AcknowledgedBy: instance.AcknowledgedBy,
+		SnoozedUntil:   instance.SnoozedUntil,
+		AssigneeID:     instance.AssigneeID,
+	}
+	if ack.AcknowledgedBy != 0 {
+		ack.AcknowledgedAt = instance.AcknowledgedAt
+	}
+	if !ack.Snoozed(at) {
+		ack.SnoozedUntil = time.Time{}
+	}
+	if ack.empty(at) {
+		return nil
+	}
+	return ack
+}
+
+// AcknowledgementCommand changes the acknowledgement of a firing alert instance.
+type AcknowledgementCommand struct {
+	OrgID   int64
+	RuleUID string
+	// Labels of the alert instance, with or without the internal labels of Grafana.
+	Labels data.Labels
+	// UserID is the user changing the acknowledgement.
+	UserID int64
+	Action AcknowledgementAction
+	// SnoozeFor is the duration of a snooze.
+	SnoozeFor time.Duration
+	// AssigneeID is the user to assign the alert to, or zero to remove the assignment.
+	AssigneeID int64
+}
+
+// UpdateAcknowledgement changes the acknowledgement of a firing alert instance and saves it with
+// the state of the instance. The instance is sent to the Alertmanager again with the next
+// evaluation of its rule, so that the notifications are suppressed or resumed.
+func (st *Manager) UpdateAcknowledgement(ctx context.Context, cmd AcknowledgementCommand) (*State, error) {
+	s := st.findState(cmd.OrgID, cmd.RuleUID, cmd.Labels)
+	if s == nil {
+		return nil, ErrAlertInstanceNotFound
+	}
+	if s.State == eval.Normal || s.State == eval.Pending {
+		return nil, ErrAlertInstanceNotFiring
+	}
+
+	now := st.clock.Now()
+	ack := Acknowledgement{}
+	if s.Acknowledgement != nil {
+		ack = *s.Acknowledgement
+	}
+	switch cmd.Action {
+	case AcknowledgeAction:
+		ack.AcknowledgedBy = cmd.UserID
+		ack.AcknowledgedAt = now
+	case UnacknowledgeAction:
+		ack.AcknowledgedBy = 0
+		ack.AcknowledgedAt = time.Time{}
+		ack.SnoozedUntil = time.Time{}
+	case SnoozeAction:
+		if cmd.SnoozeFor <= 0 {
+			return nil, fmt.Errorf("%w: the duration of a snooze must be positive", ErrInvalidAcknowledgement)
+		}
+		ack.SnoozedUntil = now.Add(cmd.SnoozeFor)
+	case AssignAction:
+		ack.AssigneeID = cmd.AssigneeID
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidAcknowledgement, cmd.Action)
+	}
+
+	if ack.empty(now) {
+		s.Acknowledgement = nil
+	} else {
+		s.Acknowledgement = &ack
+	}
+	// Send the alert with the next evaluation even if it was sent recently.
+	s.LastSentAt = time.Time{}
+	st.cache.set(s)
+
+	logger := st.log.FromContext(ctx).New(s.GetRuleKey().LogContext()...)
+	logger.Info("Alert acknowledgement changed", "action", cmd.Action, "user", cmd.UserID, "labels", s.Labels.String())
+	st.saveAlertStates(ctx, logger, StateTransition{State: s, PreviousState: s.State, PreviousStateReason: s.StateReason})
+	return s, nil
+}
+
+// findState returns the state of a rule with the labels, which may lack the internal labels.
+func (st *Manager) findState(orgID int64, ruleUID string, labels data.Labels) *State {
+	for _, s := range st.GetStatesForRuleUID(orgID, ruleUID) {
+		if labelsEqual(s.Labels, labels) || labelsEqual(s.GetLabels(ngModels.WithoutInternalLabels()), labels) {
+			return s
+		}
+	}
+	return nil
+}
+
+func labelsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// InstanceLimitAction is what happens with the results of a rule which produces more alert
+// instances than the limit allows.
+type InstanceLimitAction string
+
+const (
+	// InstanceLimitAggregate removes the labels with the most distinct values from the results
+	// until they fit in the limit, and combines results with the same remaining labels.
+	InstanceLimitAggregate InstanceLimitAction = "aggregate"
+	// InstanceLimitDrop keeps the results up to the limit, preferring alerting and failed ones.
+	InstanceLimitDrop InstanceLimitAction = "drop"
+)
+
+// InstanceLimit protects the state manager and the notification pipeline from rules which
+// produce a huge number of series.
+type InstanceLimit struct {
+	// MaxInstances is the maximum number of alert instances of a rule. Zero disables the limit.
+	MaxInstances int
+	Action       InstanceLimitAction
+}
+
+// ruleWarnings holds the warnings of the rules whose last evaluation exceeded the instance limit.
+type ruleWarnings struct {
+	mtx      sync.RWMutex
+	warnings map[ngModels.AlertRuleKey]string
+}
+
+func (w *ruleWarnings) set(key ngModels.AlertRuleKey, warning string) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if warning == "" {
+		delete(w.warnings, key)
+		return
+	}
+	w.warnings[key] = warning
+}
+
+func (w *ruleWarnings) get(key ngModels.AlertRuleKey) string {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	return w.warnings[key]
+}
+
+// applyInstanceLimit returns the results of a rule reduced to the instance limit, and a warning
+// if the results exceeded it.
+func applyInstanceLimit(limit InstanceLimit, results eval.Results) (eval.Results, string) {
+	if limit.MaxInstances <= 0 || len(results) <= limit.MaxInstances {
+		return results, ""
+	}
+
+	if limit.Action == InstanceLimitDrop {
+		limited := dropResults(results, limit.MaxInstances)
+		return limited, fmt.Sprintf("the rule produced %d series, more than the limit of %d alert instances: %d series were dropped",
+			len(results), limit.MaxInstances, len(results)-len(limited))
+	}
+
+	limited, removed := aggregateResults(results, limit.MaxInstances)
+	return limited, fmt.Sprintf("the rule produced %d series, more than the limit of %d alert instances: series were aggregated into %d instances without the labels %s",
+		len(results), limit.MaxInstances, len(limited), strings.Join(removed, ", "))
+}
+
+// resultSeverity orders results by how important it is to keep them.
+func resultSeverity(state eval.State) int {
+	switch state {
+	case eval.Alerting:
+		return 4
+	case eval.Error:
+		return 3
+	case eval.NoData:
+		return 2
+	case eval.Pending:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// dropResults keeps max results, preferring the most severe ones, in their original order.
+func dropResults(results eval.Results, max int) eval.Results {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return resultSeverity(results[order[i]].State) > resultSeverity(results[order[j]].State)
+	})
+	kept := order[:max]
+	sort.Ints(kept)
+
+	limited := make(eval.Results, 0, max)
+	for _, i := range kept {
+		limited = append(limited, results[i])
+	}
+	return limited
+}
+
+// aggregateResults removes the label with the most distinct values from all results until the
+// results with equal labels fit in max groups. Each group results in the most severe result of the
+// group. It returns the results and the removed labels.
+func aggregateResults(results eval.Results, max int) (eval.Results, []string) {
+	labels := make([]data.Labels, len(results))
+	for i, result := range results {
+		labels[i] = result.Instance.Copy()
+	}
+
+	var removed []string
+	for countGroups(labels) > max {
+		key := mostDistinctLabel(labels)
+		if key == "" {
+			break
+		}
+		removed = append(removed, key)
+		for _, l := range labels {
+			delete(l, key)
+		}
+	}
+
+	var order []string
+	groups := map[string][]int{}
+	for i, l := range labels {
+		key := l.String()
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	limited := make(eval.Results, 0, len(order))
+	for _, key := range order {
+		members := groups[key]
+		representative := members[0]
+		for _, i := range members[1:] {
+			if resultSeverity(results[i].State) > resultSeverity(results[representative].State) {
+				representative = i
+			}
+		}
+		result := results[representative]
+		result.Instance = labels[representative]
+		result.EvaluationString = fmt.Sprintf("[aggregated %d series] %s", len(members), result.EvaluationString)
+		limited = append(limited, result)
+	}
+	return limited, removed
+}
+
+func countGroups(labels []data.Labels) int {
+	groups := make(map[string]struct{}, len(labels))
+	for _, l := range labels {
+		groups[l.String()] = struct{}{}
+	}
+	return len(groups)
+}
+
+// mostDistinctLabel returns the label key with the most distinct values, or an empty string if
+// there are no labels left.
+func mostDistinctLabel(labels []data.Labels) string {
+	values := map[string]map[string]struct{}{}
+	for _, l := range labels {
+		for key, value := range l {
+			if values[key] == nil {
+				values[key] = map[string]struct{}{}
+			}
+			values[key][value] = struct{}{}
+		}
+	}
+
+	best, bestCount := "", 0
+	for key, distinct := range values {
+		if len(distinct) > bestCount || (len(distinct) == bestCount && key < best) {
+			best, bestCount = key, len(distinct)
+		}
+	}
+	return best
+}
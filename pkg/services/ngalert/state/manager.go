@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"time"
 
@@ -23,6 +24,10 @@ var (
 type AlertInstanceManager interface {
 	GetAll(orgID int64) []*State
 	GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State
+	// GetRuleWarning returns the warning of the last evaluation of a rule, if any.
+	GetRuleWarning(orgID int64, alertRuleUID string) string
+	// UpdateAcknowledgement changes the acknowledgement of a firing alert instance.
+	UpdateAcknowledgement(ctx context.Context, cmd AcknowledgementCommand) (*State, error)
 }
 
 type Manager struct {
@@ -37,9 +42,12 @@ type Manager struct {
 	images        ImageCapturer
 	historian     Historian
 	externalURL   *url.URL
+
+	instanceLimit InstanceLimit
+	warnings      *ruleWarnings
 }
 
-func NewManager(metrics *metrics.State, externalURL *url.URL, instanceStore InstanceStore, images ImageCapturer, clock clock.Clock, historian Historian) *Manager {
+func NewManager(metrics *metrics.State, externalURL *url.URL, instanceStore InstanceStore, images ImageCapturer, clock clock.Clock, historian Historian, instanceLimit InstanceLimit) *Manager {
 	return &Manager{
 		cache:         newCache(),
 		ResendDelay:   ResendDelay, // TODO: make this configurable
@@ -50,6 +58,8 @@ func NewManager(metrics *metrics.State, externalURL *url.URL, instanceStore Inst
 		historian:     historian,
 		clock:         clock,
 		externalURL:   externalURL,
+		instanceLimit: instanceLimit,
+		warnings:      &ruleWarnings{warnings: make(map[ngModels.AlertRuleKey]string)},
 	}
 }
 
@@ -138,6 +148,7 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				EndsAt:               entry.CurrentStateEnd,
 				LastEvaluationTime:   entry.LastEvalTime,
 				Annotations:          ruleForEntry.Annotations,
+				Acknowledgement:      acknowledgementFromInstance(entry, st.clock.Now()),
 			}
 			statesCount++
 		}
@@ -155,6 +166,7 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, ruleKey ngModels.Ale
 	logger := st.log.New(ruleKey.LogContext()...)
 	logger.Debug("Resetting state of the rule")
 	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.warnings.set(ruleKey, "")
 	if len(states) > 0 && st.instanceStore != nil {
 		err := st.instanceStore.DeleteAlertInstancesByRule(ctx, ruleKey)
 		if err != nil {
@@ -170,6 +182,7 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, ruleKey ngModels.Ale
 func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels) []*State {
 	logger := st.log.FromContext(ctx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
+	results = st.limitInstances(logger, alertRule, results)
 	var states []StateTransition
 
 	for _, result := range results {
@@ -239,6 +252,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
 
+	// The acknowledgement only lasts while the alert is firing.
+	if currentState.State == eval.Normal || currentState.State == eval.Pending {
+		currentState.Acknowledgement = nil
+	}
+
 	if shouldTakeImage(currentState.State, oldState, currentState.Image, currentState.Resolved) {
 		image, err := takeImage(ctx, st.images, alertRule)
 		if err != nil {
@@ -262,6 +280,28 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	return nextState
 }
 
+// limitInstances reduces the results of a rule to the instance limit and records a warning for the
+// rule if they exceed it.
+func (st *Manager) limitInstances(logger log.Logger, alertRule *ngModels.AlertRule, results eval.Results) eval.Results {
+	limited, warning := applyInstanceLimit(st.instanceLimit, results)
+	st.warnings.set(alertRule.GetKey(), warning)
+	if warning == "" {
+		return results
+	}
+
+	logger.Warn("Rule exceeded the alert instance limit", "series", len(results), "limit", st.instanceLimit.MaxInstances, "action", st.instanceLimit.Action, "instances", len(limited))
+	org := fmt.Sprint(alertRule.OrgID)
+	action := string(st.instanceLimit.Action)
+	st.metrics.LimitedEvaluations.WithLabelValues(org, action).Inc()
+	st.metrics.LimitedSeries.WithLabelValues(org, action).Add(float64(len(results) - len(limited)))
+	return limited
+}
+
+// GetRuleWarning returns the warning of the last evaluation of a rule, if any.
+func (st *Manager) GetRuleWarning(orgID int64, alertRuleUID string) string {
+	return st.warnings.get(ngModels.AlertRuleKey{OrgID: orgID, UID: alertRuleUID})
+}
+
 func (st *Manager) GetAll(orgID int64) []*State {
 	return st.cache.getAll(orgID)
 }
@@ -300,6 +340,12 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
 		}
+		if ack := s.Acknowledgement; ack != nil {
+			fields.AcknowledgedBy = ack.AcknowledgedBy
+			fields.AcknowledgedAt = ack.AcknowledgedAt
+			fields.SnoozedUntil = ack.SnoozedUntil
+			fields.AssigneeID = ack.AssigneeID
+		}
 		instances = append(instances, fields)
 	}
 
@@ -102,7 +102,7 @@ func TestWarmStateCache(t *testing.T) {
 		Labels:            labels,
 	}
 	_ = dbstore.SaveAlertInstances(ctx, instance2)
-	st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{}, state.InstanceLimit{})
 	st.Warm(ctx, dbstore)
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
@@ -126,7 +126,7 @@ func TestDashboardAnnotations(t *testing.T) {
 
 	fakeAnnoRepo := annotationstest.NewFakeAnnotationsRepo()
 	hist := historian.NewAnnotationHistorian(fakeAnnoRepo, &dashboards.FakeDashboardService{})
-	st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.New(), hist)
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.New(), hist, state.InstanceLimit{})
 
 	const mainOrgID int64 = 1
 
@@ -2107,7 +2107,7 @@ func TestProcessEvalResults(t *testing.T) {
 	for _, tc := range testCases {
 		fakeAnnoRepo := annotationstest.NewFakeAnnotationsRepo()
 		hist := historian.NewAnnotationHistorian(fakeAnnoRepo, &dashboards.FakeDashboardService{})
-		st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NotAvailableImageService{}, clock.New(), hist)
+		st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NotAvailableImageService{}, clock.New(), hist, state.InstanceLimit{})
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
 				_ = st.ProcessEvalResults(context.Background(), evaluationTime, tc.alertRule, res, data.Labels{
@@ -2134,7 +2134,7 @@ func TestProcessEvalResults(t *testing.T) {
 	t.Run("should save state to database", func(t *testing.T) {
 		instanceStore := &state.FakeInstanceStore{}
 		clk := clock.New()
-		st := state.NewManager(testMetrics.GetStateMetrics(), nil, instanceStore, &state.NotAvailableImageService{}, clk, &state.FakeHistorian{})
+		st := state.NewManager(testMetrics.GetStateMetrics(), nil, instanceStore, &state.NotAvailableImageService{}, clk, &state.FakeHistorian{}, state.InstanceLimit{})
 		rule := models.AlertRuleGen()()
 		var results = eval.GenerateResults(rand.Intn(4)+1, eval.ResultGen(eval.WithEvaluatedAt(clk.Now())))
 
@@ -2156,6 +2156,88 @@ func TestProcessEvalResults(t *testing.T) {
 			require.Contains(t, savedStates, s.CacheID)
 		}
 	})
+
+	t.Run("should limit alert instances of a rule", func(t *testing.T) {
+		clk := clock.New()
+		rule := models.AlertRuleGen()()
+		rule.For = 0
+		results := make(eval.Results, 0, 10)
+		for i := 0; i < 10; i++ {
+			results = append(results, eval.Result{
+				Instance:    data.Labels{"pod": fmt.Sprintf("pod-%d", i), "zone": fmt.Sprintf("zone-%d", i%2)},
+				State:       eval.Normal,
+				EvaluatedAt: clk.Now(),
+			})
+		}
+		results[7].State = eval.Alerting
+
+		st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NotAvailableImageService{}, clk, &state.FakeHistorian{}, state.InstanceLimit{MaxInstances: 3, Action: state.InstanceLimitAggregate})
+		states := st.ProcessEvalResults(context.Background(), clk.Now(), rule, results, make(data.Labels))
+		require.Len(t, states, 2)
+		for _, s := range states {
+			require.NotContains(t, s.Labels, "pod")
+		}
+		require.Equal(t, eval.Alerting, states[1].State)
+		require.Contains(t, st.GetRuleWarning(rule.OrgID, rule.UID), "without the labels pod")
+
+		st = state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NotAvailableImageService{}, clk, &state.FakeHistorian{}, state.InstanceLimit{MaxInstances: 3, Action: state.InstanceLimitDrop})
+		states = st.ProcessEvalResults(context.Background(), clk.Now(), rule, results, make(data.Labels))
+		require.Len(t, states, 3)
+		require.Equal(t, "pod-0", states[0].Labels["pod"])
+		require.Equal(t, "pod-7", states[2].Labels["pod"])
+		require.Contains(t, st.GetRuleWarning(rule.OrgID, rule.UID), "7 series were dropped")
+
+		states = st.ProcessEvalResults(context.Background(), clk.Now(), rule, results[:2], make(data.Labels))
+		require.NotEmpty(t, states)
+		require.Empty(t, st.GetRuleWarning(rule.OrgID, rule.UID))
+	})
+
+	t.Run("should keep the acknowledgement while the alert is firing", func(t *testing.T) {
+		clk := clock.NewMock()
+		rule := models.AlertRuleGen()()
+		rule.For = 0
+		result := func(s eval.State) eval.Results {
+			return eval.Results{{Instance: data.Labels{"pod": "pod-1"}, State: s, EvaluatedAt: clk.Now()}}
+		}
+		instanceStore := &state.FakeInstanceStore{}
+		st := state.NewManager(testMetrics.GetStateMetrics(), nil, instanceStore, &state.NotAvailableImageService{}, clk, &state.FakeHistorian{}, state.InstanceLimit{})
+		ctx := context.Background()
+
+		states := st.ProcessEvalResults(ctx, clk.Now(), rule, result(eval.Normal), make(data.Labels))
+		labels := data.Labels(states[0].GetLabels(models.WithoutInternalLabels()))
+		cmd := state.AcknowledgementCommand{OrgID: rule.OrgID, RuleUID: rule.UID, Labels: labels, UserID: 2, Action: state.AcknowledgeAction}
+		_, err := st.UpdateAcknowledgement(ctx, cmd)
+		require.ErrorIs(t, err, state.ErrAlertInstanceNotFiring)
+
+		clk.Add(time.Minute)
+		states = st.ProcessEvalResults(ctx, clk.Now(), rule, result(eval.Alerting), make(data.Labels))
+		states[0].LastSentAt = clk.Now()
+		s, err := st.UpdateAcknowledgement(ctx, cmd)
+		require.NoError(t, err)
+		require.True(t, s.Acknowledgement.Acknowledged())
+		require.Equal(t, clk.Now(), s.Acknowledgement.AcknowledgedAt)
+		require.True(t, s.LastSentAt.IsZero())
+		saved := instanceStore.RecordedOps[len(instanceStore.RecordedOps)-1].(models.AlertInstance)
+		require.Equal(t, int64(2), saved.AcknowledgedBy)
+
+		s, err = st.UpdateAcknowledgement(ctx, state.AcknowledgementCommand{OrgID: rule.OrgID, RuleUID: rule.UID, Labels: labels, Action: state.SnoozeAction, SnoozeFor: time.Hour})
+		require.NoError(t, err)
+		require.True(t, s.Acknowledgement.Snoozed(clk.Now()))
+		s, err = st.UpdateAcknowledgement(ctx, state.AcknowledgementCommand{OrgID: rule.OrgID, RuleUID: rule.UID, Labels: labels, Action: state.AssignAction, AssigneeID: 3})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), s.Acknowledgement.AssigneeID)
+
+		_, err = st.UpdateAcknowledgement(ctx, state.AcknowledgementCommand{OrgID: rule.OrgID, RuleUID: rule.UID, Labels: data.Labels{"pod": "pod-2"}, Action: state.AcknowledgeAction})
+		require.ErrorIs(t, err, state.ErrAlertInstanceNotFound)
+
+		clk.Add(time.Minute)
+		states = st.ProcessEvalResults(ctx, clk.Now(), rule, result(eval.Alerting), make(data.Labels))
+		require.True(t, states[0].Acknowledgement.Acknowledged())
+
+		clk.Add(time.Minute)
+		states = st.ProcessEvalResults(ctx, clk.Now(), rule, result(eval.Normal), make(data.Labels))
+		require.Nil(t, states[0].Acknowledgement)
+	})
 }
 
 func printAllAnnotations(annos map[int64]annotations.Item) string {
@@ -2263,7 +2345,7 @@ func TestStaleResultsHandler(t *testing.T) {
 
 	for _, tc := range testCases {
 		ctx := context.Background()
-		st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.New(), &state.FakeHistorian{})
+		st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clock.New(), &state.FakeHistorian{}, state.InstanceLimit{})
 		st.Warm(ctx, dbstore)
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
@@ -2325,7 +2407,7 @@ func TestStaleResults(t *testing.T) {
 		clk := clock.NewMock()
 		clk.Set(time.Now())
 
-		st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clk, &state.FakeHistorian{})
+		st := state.NewManager(testMetrics.GetStateMetrics(), nil, dbstore, &state.NoopImageService{}, clk, &state.FakeHistorian{}, state.InstanceLimit{})
 
 		orgID := rand.Int63()
 		rule := tests.CreateTestAlertRule(t, ctx, dbstore, 10, orgID)
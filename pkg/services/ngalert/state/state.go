@@ -57,6 +57,9 @@ type State struct {
 	// conditions.
 	Values map[string]float64
 
+	// Acknowledgement is set while users acknowledged, snoozed or are assigned to the alert.
+	Acknowledgement *Acknowledgement
+
 	StartsAt             time.Time
 	EndsAt               time.Time
 	LastSentAt           time.Time
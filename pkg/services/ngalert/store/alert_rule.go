@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -45,12 +47,26 @@ func getAlertRuleByUID(sess *db.Session, alertRuleUID string, orgID int64) (*ngm
 func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUID ...string) error {
 	logger := st.Logger.New("org_id", orgID, "rule_uids", ruleUID)
 	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var deleted []string
+		if err := sess.Table("alert_rule").Where("org_id = ?", orgID).In("uid", ruleUID).Cols("uid").Find(&deleted); err != nil {
+			return err
+		}
+
 		rows, err := sess.Table("alert_rule").Where("org_id = ?", orgID).In("uid", ruleUID).Delete(ngmodels.AlertRule{})
 		if err != nil {
 			return err
 		}
 		logger.Debug("deleted alert rules", "count", rows)
 
+		now := TimeNow()
+		for _, uid := range deleted {
+			sess.PublishAfterCommit(&events.AlertRuleDeleted{
+				Timestamp: now,
+				UID:       uid,
+				OrgID:     orgID,
+			})
+		}
+
 		rows, err = sess.Table("alert_rule_version").Where("rule_org_id = ?", orgID).In("rule_uid", ruleUID).Delete(ngmodels.AlertRule{})
 		if err != nil {
 			return err
@@ -62,7 +78,9 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("deleted alert instances", "count", rows)
-		return nil
+
+		_, err = sess.Where("org_id = ? AND resource_kind = ?", orgID, resourcelabel.KindAlertRule).In("resource_uid", ruleUID).Delete(&resourcelabel.Label{})
+		return err
 	})
 }
 
@@ -162,6 +180,15 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 					return fmt.Errorf("failed to create new rules: %w", err)
 				}
 				ids[newRules[i].UID] = newRules[i].ID
+
+				sess.PublishAfterCommit(&events.AlertRuleCreated{
+					Timestamp: newRules[i].Updated,
+					Title:     newRules[i].Title,
+					UID:       newRules[i].UID,
+					OrgID:     newRules[i].OrgID,
+					FolderUID: newRules[i].NamespaceUID,
+					RuleGroup: newRules[i].RuleGroup,
+				})
 			}
 		}
 
@@ -218,6 +245,16 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
 			})
+
+			sess.PublishAfterCommit(&events.AlertRuleUpdated{
+				Timestamp: r.New.Updated,
+				Title:     r.New.Title,
+				UID:       r.New.UID,
+				OrgID:     r.New.OrgID,
+				FolderUID: r.New.NamespaceUID,
+				RuleGroup: r.New.RuleGroup,
+				Version:   r.New.Version + 1,
+			})
 		}
 		if len(ruleVersions) > 0 {
 			if _, err := sess.Insert(&ruleVersions); err != nil {
@@ -68,6 +68,7 @@ func (st DBstore) SaveAlertInstances(ctx context.Context, cmd ...models.AlertIns
 		fieldNames := []string{
 			"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state",
 			"current_reason", "current_state_since", "current_state_end", "last_eval_time",
+			"acknowledged_by", "acknowledged_at", "snoozed_until", "assignee_id",
 		}
 		fieldsPerRow := len(fieldNames)
 		maxRows := 20
@@ -100,7 +101,8 @@ func (st DBstore) SaveAlertInstances(ctx context.Context, cmd ...models.AlertIns
 			args = append(args,
 				alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash,
 				alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(),
-				alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+				alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(),
+				alertInstance.AcknowledgedBy, alertInstance.AcknowledgedAt.Unix(), alertInstance.SnoozedUntil.Unix(), alertInstance.AssigneeID)
 
 			// If we've reached the maximum batch size, write to the database.
 			if values(args) >= maxArgs {
@@ -152,12 +154,14 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 		if err != nil {
 			return err
 		}
-		params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+		params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(),
+			alertInstance.AcknowledgedBy, alertInstance.AcknowledgedAt.Unix(), alertInstance.SnoozedUntil.Unix(), alertInstance.AssigneeID)
 
 		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
 			"alert_instance",
 			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time"})
+			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time",
+				"acknowledged_by", "acknowledged_at", "snoozed_until", "assignee_id"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
@@ -99,9 +99,12 @@ func SetupTestEnv(tb testing.TB, baseInterval time.Duration) (*ngalert.AlertNG,
 
 	ng, err := ngalert.ProvideService(
 		cfg, &FakeFeatures{}, nil, nil, routing.NewRouteRegister(), sqlStore, nil, nil, nil, quotatest.New(false, nil),
-		secretsService, nil, m, folderService, ac, &dashboards.FakeDashboardService{}, nil, bus, ac, annotationstest.NewFakeAnnotationsRepo(),
+		secretsService, nil, m, folderService, ac, &dashboards.FakeDashboardService{}, nil, bus, ac, annotationstest.NewFakeAnnotationsRepo(), nil, nil, nil, nil,
 	)
 	require.NoError(tb, err)
+	if !ng.IsDisabled() {
+		require.NoError(tb, ng.Init(context.Background()))
+	}
 	return ng, &store.DBstore{
 		FeatureToggles: ng.FeatureToggles,
 		SQLStore:       ng.SQLStore,
@@ -0,0 +1,173 @@
+package notificationcenter
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *NotificationCenterService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/user/notifications", func(entities routing.RouteRegister) {
+		entities.Get("/", routing.Wrap(s.getNotificationsHandler))
+		entities.Post("/read", routing.Wrap(s.markReadHandler))
+		entities.Delete("/:id", routing.Wrap(s.deleteNotificationHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+	s.routeRegister.Post("/api/admin/notifications/broadcast", middleware.ReqGrafanaAdmin, routing.Wrap(s.broadcastHandler))
+}
+
+// swagger:route GET /user/notifications signed_in_user getSignedInUserNotifications
+//
+// Get the notifications of the signed in user.
+//
+// Returns the notifications of the signed in user in the current organization, newest first, together with the number of unread notifications.
+//
+// Responses:
+// 200: userNotificationsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *NotificationCenterService) getNotificationsHandler(c *models.ReqContext) response.Response {
+	result, err := s.Search(c.Req.Context(), SearchQuery{
+		OrgID:      c.OrgID,
+		UserID:     c.UserID,
+		UnreadOnly: c.QueryBool("unreadOnly"),
+		Limit:      c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get notifications", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /user/notifications/read signed_in_user markSignedInUserNotificationsRead
+//
+// Mark notifications of the signed in user as read.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *NotificationCenterService) markReadHandler(c *models.ReqContext) response.Response {
+	cmd := MarkReadCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.UserID = c.UserID
+
+	if _, err := s.MarkRead(c.Req.Context(), cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to mark notifications as read", err)
+	}
+	return response.Success("Notifications marked as read")
+}
+
+// swagger:route DELETE /user/notifications/{notification_id} signed_in_user deleteSignedInUserNotification
+//
+// Delete a notification of the signed in user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *NotificationCenterService) deleteNotificationHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := s.Delete(c.Req.Context(), c.OrgID, c.UserID, id); err != nil {
+		if errors.Is(err, ErrNotificationNotFound) {
+			return response.Error(http.StatusNotFound, "Notification not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete notification", err)
+	}
+	return response.Success("Notification deleted")
+}
+
+// swagger:route POST /admin/notifications/broadcast admin adminBroadcastNotification
+//
+// Send a notification to all users.
+//
+// The notification is stored in the notification center of every member of the organization given by `orgId`, or of every user if `orgId` is not set.
+//
+// Responses:
+// 200: broadcastNotificationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *NotificationCenterService) broadcastHandler(c *models.ReqContext) response.Response {
+	cmd := BroadcastCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	recipients, err := s.Broadcast(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, ErrInvalidNotification) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to send notification", err)
+	}
+	return response.JSON(http.StatusOK, BroadcastResult{Message: "Notification sent", Recipients: recipients})
+}
+
+// BroadcastResult is the result of a broadcast.
+// swagger:model
+type BroadcastResult struct {
+	Message string `json:"message"`
+	// Number of notified users.
+	Recipients int `json:"recipients"`
+}
+
+// swagger:parameters getSignedInUserNotifications
+type GetSignedInUserNotificationsParams struct {
+	// in:query
+	// required:false
+	UnreadOnly bool `json:"unreadOnly"`
+	// in:query
+	// required:false
+	// default:50
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters markSignedInUserNotificationsRead
+type MarkSignedInUserNotificationsReadParams struct {
+	// in:body
+	// required:true
+	Body MarkReadCommand `json:"body"`
+}
+
+// swagger:parameters deleteSignedInUserNotification
+type DeleteSignedInUserNotificationParams struct {
+	// in:path
+	// required:true
+	NotificationID int64 `json:"notification_id"`
+}
+
+// swagger:parameters adminBroadcastNotification
+type AdminBroadcastNotificationParams struct {
+	// in:body
+	// required:true
+	Body BroadcastCommand `json:"body"`
+}
+
+// swagger:response userNotificationsResponse
+type UserNotificationsResponse struct {
+	// in:body
+	Body SearchResult `json:"body"`
+}
+
+// swagger:response broadcastNotificationResponse
+type BroadcastNotificationResponse struct {
+	// in:body
+	Body BroadcastResult `json:"body"`
+}
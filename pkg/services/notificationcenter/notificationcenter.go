@@ -0,0 +1,311 @@
+package notificationcenter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	cleanupInterval = time.Hour
+
+	defaultLimit = 50
+	maxLimit     = 500
+
+	// maxColumnLength is the length of the title column of the user_notification table.
+	maxColumnLength = 255
+	maxURLLength    = 1024
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidNotification  = errors.New("invalid notification")
+)
+
+// Kind is what caused a notification.
+type Kind string
+
+const (
+	KindShare     Kind = "share"
+	KindReport    Kind = "report"
+	KindAlertRule Kind = "alert_rule"
+	KindBroadcast Kind = "broadcast"
+	KindDashboard Kind = "dashboard"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeveritySuccess Severity = "success"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+func (s Severity) IsValid() bool {
+	switch s {
+	case SeverityInfo, SeveritySuccess, SeverityWarning, SeverityError:
+		return true
+	}
+	return false
+}
+
+// Notification is a message for a user in an organization. It stays in the notification
+// center of the user until it expires or the user deletes it.
+type Notification struct {
+	ID       int64      `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID    int64      `json:"orgId" xorm:"org_id"`
+	UserID   int64      `json:"userId" xorm:"user_id"`
+	Kind     Kind       `json:"kind" xorm:"kind"`
+	Severity Severity   `json:"severity" xorm:"severity"`
+	Title    string     `json:"title" xorm:"title"`
+	Body     string     `json:"body,omitempty" xorm:"body"`
+	URL      string     `json:"url,omitempty" xorm:"url"`
+	ReadAt   *time.Time `json:"readAt,omitempty" xorm:"read_at"`
+	Created  time.Time  `json:"created" xorm:"created"`
+}
+
+func (n Notification) TableName() string {
+	return "user_notification"
+}
+
+// NotifyCommand sends a notification to the union of its recipients. Only active users which are
+// members of the organization are notified, each of them once.
+type NotifyCommand struct {
+	// OrgID is the organization of the recipients. It may only be 0 together with AllUsers.
+	OrgID   int64
+	UserIDs []int64
+	// TeamID notifies the members of the team.
+	TeamID int64
+	// OrgAdmins notifies the admins of the organization.
+	OrgAdmins bool
+	// AllUsers notifies all members of the organization, or of all organizations if OrgID is 0.
+	AllUsers bool
+	// ExcludeUserID is never notified, usually it is the user who caused the notification.
+	ExcludeUserID int64
+
+	Kind     Kind
+	Severity Severity
+	Title    string
+	Body     string
+	URL      string
+}
+
+// SearchQuery lists the notifications of a user, newest first.
+type SearchQuery struct {
+	OrgID      int64
+	UserID     int64
+	UnreadOnly bool
+	Limit      int
+}
+
+// SearchResult is the list of notifications of a user together with the number of unread ones.
+// swagger:model
+type SearchResult struct {
+	UnreadCount   int64          `json:"unreadCount"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// MarkReadCommand marks notifications of a user as read.
+// swagger:model
+type MarkReadCommand struct {
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+	// IDs of the notifications to mark as read.
+	IDs []int64 `json:"ids"`
+	// All marks all notifications of the user in the current organization as read.
+	All bool `json:"all"`
+}
+
+// BroadcastCommand sends a notification to all users.
+// swagger:model
+type BroadcastCommand struct {
+	// OrgID limits the broadcast to the members of an organization. All users are notified if it is 0.
+	OrgID    int64    `json:"orgId"`
+	Severity Severity `json:"severity"`
+	// required: true
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+}
+
+// Event is pushed to the Live channel of a user when their notifications change.
+type Event struct {
+	Action       string        `json:"action"`
+	Notification *Notification `json:"notification,omitempty"`
+	IDs          []int64       `json:"ids,omitempty"`
+	All          bool          `json:"all,omitempty"`
+}
+
+const (
+	EventCreated = "created"
+	EventRead    = "read"
+	EventDeleted = "deleted"
+)
+
+// Channel returns the Live channel the events of a user are pushed to. It is scoped to the
+// organization the events are published in.
+func Channel(userID int64) string {
+	return fmt.Sprintf("grafana/user-notifications/%d", userID)
+}
+
+type Service interface {
+	// Notify stores a notification for every recipient of the command and pushes it to the ones
+	// who are connected. It returns the number of notified users.
+	Notify(ctx context.Context, cmd NotifyCommand) (int, error)
+}
+
+type NotificationCenterService struct {
+	store         db.DB
+	cfg           *setting.Cfg
+	log           log.Logger
+	live          *live.GrafanaLive
+	routeRegister routing.RouteRegister
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, live *live.GrafanaLive, routeRegister routing.RouteRegister) *NotificationCenterService {
+	s := &NotificationCenterService{
+		store:         sqlStore,
+		cfg:           cfg,
+		log:           log.New("notification-center"),
+		live:          live,
+		routeRegister: routeRegister,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *NotificationCenterService) Notify(ctx context.Context, cmd NotifyCommand) (int, error) {
+	if cmd.Title == "" {
+		return 0, fmt.Errorf("%w: title is required", ErrInvalidNotification)
+	}
+	if cmd.OrgID == 0 && !cmd.AllUsers {
+		return 0, fmt.Errorf("%w: organization is required", ErrInvalidNotification)
+	}
+	if cmd.Severity == "" {
+		cmd.Severity = SeverityInfo
+	}
+	if !cmd.Severity.IsValid() {
+		return 0, fmt.Errorf("%w: unknown severity %q", ErrInvalidNotification, cmd.Severity)
+	}
+	if len(cmd.URL) > maxURLLength {
+		return 0, fmt.Errorf("%w: url is longer than %d characters", ErrInvalidNotification, maxURLLength)
+	}
+	cmd.Title = truncate(cmd.Title)
+
+	notifications, err := s.insert(ctx, cmd, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range notifications {
+		s.push(notifications[i].OrgID, notifications[i].UserID, Event{Action: EventCreated, Notification: &notifications[i]})
+	}
+	return len(notifications), nil
+}
+
+// Broadcast notifies all users, or all members of an organization.
+func (s *NotificationCenterService) Broadcast(ctx context.Context, cmd BroadcastCommand) (int, error) {
+	return s.Notify(ctx, NotifyCommand{
+		OrgID:    cmd.OrgID,
+		AllUsers: true,
+		Kind:     KindBroadcast,
+		Severity: cmd.Severity,
+		Title:    cmd.Title,
+		Body:     cmd.Body,
+		URL:      cmd.URL,
+	})
+}
+
+func (s *NotificationCenterService) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultLimit
+	}
+	if query.Limit > maxLimit {
+		query.Limit = maxLimit
+	}
+	return s.search(ctx, query)
+}
+
+// MarkRead marks notifications as read and returns the number of notifications which were unread.
+func (s *NotificationCenterService) MarkRead(ctx context.Context, cmd MarkReadCommand) (int64, error) {
+	if !cmd.All && len(cmd.IDs) == 0 {
+		return 0, nil
+	}
+
+	updated, err := s.markRead(ctx, cmd, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		s.push(cmd.OrgID, cmd.UserID, Event{Action: EventRead, IDs: cmd.IDs, All: cmd.All})
+	}
+	return updated, nil
+}
+
+func (s *NotificationCenterService) Delete(ctx context.Context, orgID, userID, id int64) error {
+	if err := s.delete(ctx, orgID, userID, id); err != nil {
+		return err
+	}
+	s.push(orgID, userID, Event{Action: EventDeleted, IDs: []int64{id}})
+	return nil
+}
+
+// push publishes an event to the user. Users who are not connected pick up the change the next
+// time they load their notifications.
+func (s *NotificationCenterService) push(orgID, userID int64, event Event) {
+	if s.live == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.log.Error("Failed to marshal notification event", "error", err)
+		return
+	}
+	if err := s.live.Publish(orgID, Channel(userID), data); err != nil {
+		s.log.Warn("Failed to push notification event", "orgId", orgID, "userId", userID, "error", err)
+	}
+}
+
+// Run removes notifications that are older than the configured retention.
+func (s *NotificationCenterService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if s.cfg.UserNotificationRetention <= 0 {
+				continue
+			}
+			removed, err := s.deleteOlderThan(ctx, time.Now().Add(-s.cfg.UserNotificationRetention))
+			if err != nil {
+				s.log.Error("Failed to clean up notifications", "error", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Debug("Cleaned up notifications", "removed", removed)
+			}
+		}
+	}
+}
+
+func truncate(s string) string {
+	if len(s) > maxColumnLength {
+		return s[:maxColumnLength]
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package notificationcenter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationNotificationCenter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	sqlStore.Cfg.AutoAssignOrg = true
+	sqlStore.Cfg.AutoAssignOrgId = 1
+	ctx := context.Background()
+
+	createUser := func(login string, cmd user.CreateUserCommand) *user.User {
+		cmd.Login = login
+		cmd.Email = login + "@example.com"
+		usr, err := sqlStore.CreateUser(ctx, cmd)
+		require.NoError(t, err)
+		return usr
+	}
+	admin := createUser("admin", user.CreateUserCommand{IsAdmin: true})
+	editor := createUser("editor", user.CreateUserCommand{DefaultOrgRole: "Editor"})
+	viewer := createUser("viewer", user.CreateUserCommand{DefaultOrgRole: "Viewer"})
+	createUser("disabled", user.CreateUserCommand{DefaultOrgRole: "Viewer", IsDisabled: true})
+	createUser("sa-robot", user.CreateUserCommand{DefaultOrgRole: "Admin", IsServiceAccount: true})
+
+	const teamID = 10
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, userID := range []int64{editor.ID, viewer.ID} {
+			if _, err := sess.Insert(&models.TeamMember{OrgId: 1, TeamId: teamID, UserId: userID, Created: time.Now(), Updated: time.Now()}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	s := ProvideService(setting.NewCfg(), sqlStore, nil, routing.NewRouteRegister())
+
+	search := func(userID int64, unreadOnly bool) *SearchResult {
+		t.Helper()
+		result, err := s.Search(ctx, SearchQuery{OrgID: 1, UserID: userID, UnreadOnly: unreadOnly})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("notifies the members of a team except the actor", func(t *testing.T) {
+		count, err := s.Notify(ctx, NotifyCommand{OrgID: 1, TeamID: teamID, ExcludeUserID: editor.ID, Kind: KindShare, Title: "editor shared a query"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		result := search(viewer.ID, false)
+		require.Len(t, result.Notifications, 1)
+		assert.Equal(t, int64(1), result.UnreadCount)
+		assert.Equal(t, KindShare, result.Notifications[0].Kind)
+		assert.Equal(t, SeverityInfo, result.Notifications[0].Severity)
+		assert.Empty(t, search(editor.ID, false).Notifications)
+	})
+
+	t.Run("notifies the organization admins", func(t *testing.T) {
+		count, err := s.Notify(ctx, NotifyCommand{OrgID: 1, OrgAdmins: true, Kind: KindAlertRule, Severity: SeverityError, Title: "rule is broken"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Len(t, search(admin.ID, false).Notifications, 1)
+	})
+
+	t.Run("broadcasts to all active users once", func(t *testing.T) {
+		count, err := s.Broadcast(ctx, BroadcastCommand{Title: "maintenance tonight"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		count, err = s.Notify(ctx, NotifyCommand{OrgID: 1, UserIDs: []int64{viewer.ID}, AllUsers: true, Title: "duplicate recipient"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("rejects invalid notifications", func(t *testing.T) {
+		_, err := s.Notify(ctx, NotifyCommand{UserIDs: []int64{viewer.ID}, Title: "no organization"})
+		require.ErrorIs(t, err, ErrInvalidNotification)
+		_, err = s.Notify(ctx, NotifyCommand{OrgID: 1, UserIDs: []int64{viewer.ID}, Title: "bad severity", Severity: "critical"})
+		require.ErrorIs(t, err, ErrInvalidNotification)
+	})
+
+	t.Run("marks notifications as read", func(t *testing.T) {
+		result := search(viewer.ID, false)
+		require.Len(t, result.Notifications, 3)
+		assert.Equal(t, "duplicate recipient", result.Notifications[0].Title)
+
+		updated, err := s.MarkRead(ctx, MarkReadCommand{OrgID: 1, UserID: viewer.ID, IDs: []int64{result.Notifications[0].ID}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+		assert.Len(t, search(viewer.ID, true).Notifications, 2)
+
+		// notifications of other users are left alone
+		updated, err = s.MarkRead(ctx, MarkReadCommand{OrgID: 1, UserID: editor.ID, IDs: []int64{result.Notifications[1].ID}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), updated)
+
+		updated, err = s.MarkRead(ctx, MarkReadCommand{OrgID: 1, UserID: viewer.ID, All: true})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated)
+		assert.Equal(t, int64(0), search(viewer.ID, false).UnreadCount)
+	})
+
+	t.Run("deletes a notification of the user", func(t *testing.T) {
+		result := search(viewer.ID, false)
+		require.ErrorIs(t, s.Delete(ctx, 1, editor.ID, result.Notifications[0].ID), ErrNotificationNotFound)
+		require.NoError(t, s.Delete(ctx, 1, viewer.ID, result.Notifications[0].ID))
+		assert.Len(t, search(viewer.ID, false).Notifications, 2)
+	})
+
+	t.Run("removes old notifications", func(t *testing.T) {
+		removed, err := s.deleteOlderThan(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), removed)
+	})
+}
@@ -0,0 +1,167 @@
+package notificationcenter
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+type recipient struct {
+	OrgID  int64 `xorm:"org_id"`
+	UserID int64 `xorm:"user_id"`
+}
+
+// recipients resolves the members of the organization the command is sent to. Service accounts
+// and disabled users are never notified.
+func (s *NotificationCenterService) recipients(sess *db.Session, cmd NotifyCommand) ([]recipient, error) {
+	var targets []string
+	var targetArgs []interface{}
+	if len(cmd.UserIDs) > 0 {
+		targets = append(targets, "org_user.user_id IN (?"+strings.Repeat(",?", len(cmd.UserIDs)-1)+")")
+		for _, id := range cmd.UserIDs {
+			targetArgs = append(targetArgs, id)
+		}
+	}
+	if cmd.TeamID != 0 {
+		targets = append(targets, "org_user.user_id IN (SELECT user_id FROM team_member WHERE org_id = org_user.org_id AND team_id = ?)")
+		targetArgs = append(targetArgs, cmd.TeamID)
+	}
+	if cmd.OrgAdmins {
+		targets = append(targets, "org_user.role = ?")
+		targetArgs = append(targetArgs, org.RoleAdmin)
+	}
+	if cmd.AllUsers {
+		targets = append(targets, "1 = 1")
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	dialect := s.store.GetDialect()
+	where := []string{"u.is_service_account = ?", "u.is_disabled = ?"}
+	args := []interface{}{dialect.BooleanStr(false), dialect.BooleanStr(false)}
+	if cmd.OrgID != 0 {
+		where = append(where, "org_user.org_id = ?")
+		args = append(args, cmd.OrgID)
+	}
+	if cmd.ExcludeUserID != 0 {
+		where = append(where, "org_user.user_id <> ?")
+		args = append(args, cmd.ExcludeUserID)
+	}
+	where = append(where, "("+strings.Join(targets, " OR ")+")")
+	args = append(args, targetArgs...)
+
+	rawSQL := "SELECT DISTINCT org_user.org_id, org_user.user_id FROM org_user" +
+		" INNER JOIN " + dialect.Quote("user") + " AS u ON u.id = org_user.user_id" +
+		" WHERE " + strings.Join(where, " AND ")
+
+	var result []recipient
+	err := sess.SQL(rawSQL, args...).Find(&result)
+	return result, err
+}
+
+func (s *NotificationCenterService) insert(ctx context.Context, cmd NotifyCommand, now time.Time) ([]Notification, error) {
+	var notifications []Notification
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		recipients, err := s.recipients(sess, cmd)
+		if err != nil {
+			return err
+		}
+
+		notifications = make([]Notification, 0, len(recipients))
+		for _, r := range recipients {
+			n := Notification{
+				OrgID:    r.OrgID,
+				UserID:   r.UserID,
+				Kind:     cmd.Kind,
+				Severity: cmd.Severity,
+				Title:    cmd.Title,
+				Body:     cmd.Body,
+				URL:      cmd.URL,
+				Created:  now,
+			}
+			if _, err := sess.Insert(&n); err != nil {
+				return err
+			}
+			notifications = append(notifications, n)
+		}
+		return nil
+	})
+	return notifications, err
+}
+
+func (s *NotificationCenterService) search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	result := &SearchResult{Notifications: []Notification{}}
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		unread, err := sess.Table("user_notification").
+			Where("org_id = ? AND user_id = ? AND read_at IS NULL", query.OrgID, query.UserID).Count()
+		if err != nil {
+			return err
+		}
+		result.UnreadCount = unread
+
+		q := sess.Table("user_notification").Where("org_id = ? AND user_id = ?", query.OrgID, query.UserID)
+		if query.UnreadOnly {
+			q = q.And("read_at IS NULL")
+		}
+		return q.Desc("created", "id").Limit(query.Limit).Find(&result.Notifications)
+	})
+
+	return result, err
+}
+
+func (s *NotificationCenterService) markRead(ctx context.Context, cmd MarkReadCommand, now time.Time) (int64, error) {
+	var updated int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "UPDATE user_notification SET read_at = ? WHERE org_id = ? AND user_id = ? AND read_at IS NULL"
+		args := []interface{}{now, cmd.OrgID, cmd.UserID}
+		if !cmd.All {
+			rawSQL += " AND id IN (?" + strings.Repeat(",?", len(cmd.IDs)-1) + ")"
+			for _, id := range cmd.IDs {
+				args = append(args, id)
+			}
+		}
+
+		res, err := sess.Exec(append([]interface{}{rawSQL}, args...)...)
+		if err != nil {
+			return err
+		}
+		updated, err = res.RowsAffected()
+		return err
+	})
+	return updated, err
+}
+
+func (s *NotificationCenterService) delete(ctx context.Context, orgID, userID, id int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_notification WHERE id = ? AND org_id = ? AND user_id = ?", id, orgID, userID)
+		if err != nil {
+			return err
+		}
+		deleted, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrNotificationNotFound
+		}
+		return nil
+	})
+}
+
+func (s *NotificationCenterService) deleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_notification WHERE created < ?", before)
+		if err != nil {
+			return err
+		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return removed, err
+}
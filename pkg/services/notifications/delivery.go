@@ -0,0 +1,113 @@
+package notifications
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	deliveryCleanupInterval = time.Hour
+
+	defaultDeliveriesLimit = 100
+	maxDeliveriesLimit     = 1000
+
+	// maxDeliveryColumnLength is the length of the short string columns of the email_delivery table.
+	maxDeliveryColumnLength = 255
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent   DeliveryStatus = "sent"
+	DeliveryStatusFailed DeliveryStatus = "failed"
+)
+
+// Delivery is the outcome of sending one email message.
+type Delivery struct {
+	ID                int64          `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID             int64          `json:"orgId" xorm:"org_id"`
+	Provider          string         `json:"provider" xorm:"provider"`
+	ProviderMessageID string         `json:"providerMessageId,omitempty" xorm:"provider_message_id"`
+	Recipients        string         `json:"recipients" xorm:"recipients"`
+	Subject           string         `json:"subject" xorm:"subject"`
+	Template          string         `json:"template,omitempty" xorm:"template"`
+	Status            DeliveryStatus `json:"status" xorm:"status"`
+	Error             string         `json:"error,omitempty" xorm:"error"`
+	Created           time.Time      `json:"created" xorm:"created"`
+}
+
+func (d Delivery) TableName() string {
+	return "email_delivery"
+}
+
+// DeliveriesQuery filters the delivery history. Deliveries are returned newest first.
+type DeliveriesQuery struct {
+	OrgID  int64
+	Status DeliveryStatus
+	Limit  int
+}
+
+// recordDelivery stores the outcome of sending msg. Failing to store it does not fail the send.
+func (ns *NotificationService) recordDelivery(msg *Message, sendErr error) {
+	if ns.emailStore == nil {
+		return
+	}
+
+	delivery := &Delivery{
+		OrgID:             msg.OrgID,
+		Provider:          msg.Provider,
+		ProviderMessageID: truncateDeliveryColumn(msg.ProviderMessageID),
+		Recipients:        strings.Join(msg.To, ", "),
+		Subject:           truncateDeliveryColumn(msg.Subject),
+		Template:          msg.Template,
+		Status:            DeliveryStatusSent,
+		Created:           time.Now(),
+	}
+	if sendErr != nil {
+		delivery.Status = DeliveryStatusFailed
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := ns.emailStore.InsertDelivery(context.Background(), delivery); err != nil {
+		ns.log.Warn("Failed to record email delivery", "recipients", delivery.Recipients, "error", err)
+	}
+}
+
+// GetDeliveries returns the most recent email deliveries.
+func (ns *NotificationService) GetDeliveries(ctx context.Context, query DeliveriesQuery) ([]Delivery, error) {
+	if ns.emailStore == nil {
+		return make([]Delivery, 0), nil
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultDeliveriesLimit
+	}
+	if query.Limit > maxDeliveriesLimit {
+		query.Limit = maxDeliveriesLimit
+	}
+
+	return ns.emailStore.GetDeliveries(ctx, query)
+}
+
+func (ns *NotificationService) cleanUpDeliveries(ctx context.Context) {
+	if ns.emailStore == nil || ns.Cfg.Smtp.DeliveryHistoryRetention <= 0 {
+		return
+	}
+
+	removed, err := ns.emailStore.DeleteDeliveriesOlderThan(ctx, time.Now().Add(-ns.Cfg.Smtp.DeliveryHistoryRetention))
+	if err != nil {
+		ns.log.Error("Failed to clean up email delivery history", "error", err)
+		return
+	}
+	if removed > 0 {
+		ns.log.Debug("Cleaned up email delivery history", "removed", removed)
+	}
+}
+
+func truncateDeliveryColumn(s string) string {
+	if len(s) > maxDeliveryColumnLength {
+		return s[:maxDeliveryColumnLength]
+	}
+	return s
+}
@@ -22,6 +22,12 @@ type Message struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*AttachedFile
+	// OrgID is the organization the message is sent on behalf of, zero for server-wide emails.
+	OrgID    int64
+	Template string
+	// Provider and ProviderMessageID are set by the mailer which delivered the message.
+	Provider          string
+	ProviderMessageID string
 }
 
 func setDefaultTemplateData(cfg *setting.Cfg, data map[string]interface{}, u *user.User) {
@@ -0,0 +1,163 @@
+package emailstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+// Store is the SQL implementation of notifications.EmailStore.
+type Store struct {
+	db db.DB
+}
+
+func ProvideStore(sqlStore db.DB) *Store {
+	return &Store{db: sqlStore}
+}
+
+func (s *Store) InsertDelivery(ctx context.Context, delivery *notifications.Delivery) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(delivery)
+		return err
+	})
+}
+
+func (s *Store) GetDeliveries(ctx context.Context, query notifications.DeliveriesQuery) ([]notifications.Delivery, error) {
+	deliveries := make([]notifications.Delivery, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("email_delivery")
+		if query.OrgID != 0 {
+			q = q.Where("org_id = ?", query.OrgID)
+		}
+		if query.Status != "" {
+			q = q.Where("status = ?", query.Status)
+		}
+		return q.Desc("id").Limit(query.Limit).Find(&deliveries)
+	})
+	return deliveries, err
+}
+
+func (s *Store) DeleteDeliveriesOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	var removed int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM email_delivery WHERE created < ?", olderThan)
+		if err != nil {
+			return err
+		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return removed, err
+}
+
+func (s *Store) GetSenderIdentity(ctx context.Context, orgID int64) (*notifications.SenderIdentity, error) {
+	var identity notifications.SenderIdentity
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ?", orgID).Get(&identity)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return notifications.ErrSenderIdentityNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (s *Store) SetSenderIdentity(ctx context.Context, identity *notifications.SenderIdentity) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing notifications.SenderIdentity
+		exists, err := sess.Where("org_id = ?", identity.OrgID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			_, err = sess.Insert(identity)
+			return err
+		}
+
+		identity.ID = existing.ID
+		identity.Created = existing.Created
+		_, err = sess.ID(existing.ID).Cols("from_address", "from_name", "reply_to", "updated").Update(identity)
+		return err
+	})
+}
+
+func (s *Store) DeleteSenderIdentity(ctx context.Context, orgID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ?", orgID).Delete(&notifications.SenderIdentity{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return notifications.ErrSenderIdentityNotFound
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetEmailTemplates(ctx context.Context, orgID int64) ([]notifications.EmailTemplate, error) {
+	templates := make([]notifications.EmailTemplate, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Find(&templates)
+	})
+	return templates, err
+}
+
+func (s *Store) GetEmailTemplate(ctx context.Context, orgID int64, name string) (*notifications.EmailTemplate, error) {
+	var template notifications.EmailTemplate
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND name = ?", orgID, name).Get(&template)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return notifications.ErrEmailTemplateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s *Store) SetEmailTemplate(ctx context.Context, template *notifications.EmailTemplate) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing notifications.EmailTemplate
+		exists, err := sess.Where("org_id = ? AND name = ?", template.OrgID, template.Name).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			_, err = sess.Insert(template)
+			return err
+		}
+
+		template.ID = existing.ID
+		template.Created = existing.Created
+		_, err = sess.ID(existing.ID).Cols("subject", "html_body", "text_body", "updated").Update(template)
+		return err
+	})
+}
+
+func (s *Store) DeleteEmailTemplate(ctx context.Context, orgID int64, name string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND name = ?", orgID, name).Delete(&notifications.EmailTemplate{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return notifications.ErrEmailTemplateNotFound
+		}
+		return nil
+	})
+}
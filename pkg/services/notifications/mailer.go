@@ -6,6 +6,7 @@ package notifications
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"net/mail"
@@ -51,10 +52,22 @@ func (ns *NotificationService) Send(msg *Message) (int, error) {
 		}
 	}
 
-	return ns.mailer.Send(messages...)
+	sentEmailsCount := 0
+	var err error
+	for _, m := range messages {
+		_, sendErr := ns.mailer.Send(m)
+		ns.recordDelivery(m, sendErr)
+		if sendErr != nil {
+			err = sendErr
+			continue
+		}
+		sentEmailsCount++
+	}
+
+	return sentEmailsCount, err
 }
 
-func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (*Message, error) {
+func (ns *NotificationService) buildEmailMessage(ctx context.Context, cmd *models.SendEmailCommand) (*Message, error) {
 	if !ns.Cfg.Smtp.Enabled {
 		return nil, models.ErrSmtpNotEnabled
 	}
@@ -66,12 +79,29 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 
 	setDefaultTemplateData(ns.Cfg, data, nil)
 
+	custom, err := ns.getCustomTemplate(ctx, cmd.OrgID, cmd.Template)
+	if err != nil {
+		ns.log.Warn("Failed to look up custom email template, using the built-in one", "orgId", cmd.OrgID, "template", cmd.Template, "error", err)
+	}
+
 	body := make(map[string]string)
 	for _, contentType := range ns.Cfg.Smtp.ContentTypes {
 		fileExtension, err := getFileExtensionByContentType(contentType)
 		if err != nil {
 			return nil, err
 		}
+
+		if custom != nil {
+			rendered, ok, err := customTemplateBody(custom, contentType, data)
+			if err != nil {
+				// A broken custom template must not prevent sending, e.g. invites.
+				ns.log.Warn("Failed to render custom email template, using the built-in one", "orgId", cmd.OrgID, "template", cmd.Template, "error", err)
+			} else if ok {
+				body[contentType] = rendered
+				continue
+			}
+		}
+
 		var buffer bytes.Buffer
 		err = mailTemplates.ExecuteTemplate(&buffer, cmd.Template+fileExtension, data)
 		if err != nil {
@@ -81,6 +111,10 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 		body[contentType] = buffer.String()
 	}
 
+	if custom != nil && custom.Subject != "" {
+		data["Subject"].(map[string]interface{})["value"] = custom.Subject
+	}
+
 	subject := cmd.Subject
 	if cmd.Subject == "" {
 		var subjectText interface{}
@@ -106,7 +140,7 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 	}
 
 	addr := mail.Address{Name: ns.Cfg.Smtp.FromName, Address: ns.Cfg.Smtp.FromAddress}
-	return &Message{
+	msg := &Message{
 		To:            cmd.To,
 		SingleEmail:   cmd.SingleEmail,
 		From:          addr.String(),
@@ -115,7 +149,12 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 		EmbeddedFiles: cmd.EmbeddedFiles,
 		AttachedFiles: buildAttachedFiles(cmd.AttachedFiles),
 		ReplyTo:       cmd.ReplyTo,
-	}, nil
+		OrgID:         cmd.OrgID,
+		Template:      cmd.Template,
+	}
+	ns.applySenderIdentity(ctx, msg)
+
+	return msg, nil
 }
 
 // buildAttachedFiles build attached files
@@ -144,3 +183,36 @@ func getFileExtensionByContentType(contentType string) (string, error) {
 		return "", fmt.Errorf("unrecognized content type %q", contentType)
 	}
 }
+
+// SendTestEmail synchronously sends a short message to the given address through the configured
+// provider, using the sender identity of the organization if it has one.
+func (ns *NotificationService) SendTestEmail(ctx context.Context, orgID int64, to string) (*Message, error) {
+	if !ns.Cfg.Smtp.Enabled {
+		return nil, models.ErrSmtpNotEnabled
+	}
+
+	addr := mail.Address{Name: ns.Cfg.Smtp.FromName, Address: ns.Cfg.Smtp.FromAddress}
+	msg := &Message{
+		To:          []string{to},
+		SingleEmail: true,
+		From:        addr.String(),
+		Subject:     "Grafana test email",
+		Body:        map[string]string{},
+		OrgID:       orgID,
+		Template:    "test",
+	}
+	for _, contentType := range ns.Cfg.Smtp.ContentTypes {
+		switch contentType {
+		case "text/html":
+			msg.Body[contentType] = "<p>This is a test email sent by Grafana.</p>"
+		default:
+			msg.Body[contentType] = "This is a test email sent by Grafana."
+		}
+	}
+	ns.applySenderIdentity(ctx, msg)
+
+	if _, err := ns.Send(msg); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
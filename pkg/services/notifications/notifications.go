@@ -8,8 +8,8 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/Masterminds/sprig/v3"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -36,7 +36,7 @@ var tmplResetPassword = "reset_password"
 var tmplSignUpStarted = "signup_started"
 var tmplWelcomeOnSignUp = "welcome_on_signup"
 
-func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore) (*NotificationService, error) {
+func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore, emailStore EmailStore) (*NotificationService, error) {
 	ns := &NotificationService{
 		Bus:          bus,
 		Cfg:          cfg,
@@ -45,16 +45,13 @@ func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUser
 		webhookQueue: make(chan *Webhook, 10),
 		mailer:       mailer,
 		store:        store,
+		emailStore:   emailStore,
 	}
 
 	ns.Bus.AddEventListener(ns.signUpStartedHandler)
 	ns.Bus.AddEventListener(ns.signUpCompletedHandler)
 
-	mailTemplates = template.New("name")
-	mailTemplates.Funcs(template.FuncMap{
-		"Subject": subjectTemplateFunc,
-	})
-	mailTemplates.Funcs(sprig.FuncMap())
+	mailTemplates = newMailTemplate("name")
 
 	for _, pattern := range ns.Cfg.Smtp.TemplatesPatterns {
 		templatePattern := filepath.Join(ns.Cfg.StaticRootPath, pattern)
@@ -64,6 +61,10 @@ func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUser
 		}
 	}
 
+	if err := ns.loadTemplateFiles(); err != nil {
+		return nil, err
+	}
+
 	if !util.IsEmail(ns.Cfg.Smtp.FromAddress) {
 		return nil, errors.New("invalid email address for SMTP from_address config")
 	}
@@ -87,11 +88,21 @@ type NotificationService struct {
 	mailer       Mailer
 	log          log.Logger
 	store        TempUserStore
+	// templateFiles maps the file name of the built-in email templates to their path.
+	templateFiles map[string]string
+	// emailStore keeps the email delivery history and the sender identities and email templates
+	// of the organizations. They are disabled when it is nil.
+	emailStore EmailStore
 }
 
 func (ns *NotificationService) Run(ctx context.Context) error {
+	cleanupTicker := time.NewTicker(deliveryCleanupInterval)
+	defer cleanupTicker.Stop()
+
 	for {
 		select {
+		case <-cleanupTicker.C:
+			ns.cleanUpDeliveries(ctx)
 		case webhook := <-ns.webhookQueue:
 			err := ns.sendWebRequestSync(context.Background(), webhook)
 
@@ -139,7 +150,7 @@ func subjectTemplateFunc(obj map[string]interface{}, value string) string {
 }
 
 func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context, cmd *models.SendEmailCommandSync) error {
-	message, err := ns.buildEmailMessage(&models.SendEmailCommand{
+	message, err := ns.buildEmailMessage(ctx, &models.SendEmailCommand{
 		Data:          cmd.Data,
 		Info:          cmd.Info,
 		Template:      cmd.Template,
@@ -149,6 +160,7 @@ func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context,
 		AttachedFiles: cmd.AttachedFiles,
 		Subject:       cmd.Subject,
 		ReplyTo:       cmd.ReplyTo,
+		OrgID:         cmd.OrgID,
 	})
 
 	if err != nil {
@@ -160,7 +172,7 @@ func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context,
 }
 
 func (ns *NotificationService) SendEmailCommandHandler(ctx context.Context, cmd *models.SendEmailCommand) error {
-	message, err := ns.buildEmailMessage(cmd)
+	message, err := ns.buildEmailMessage(ctx, cmd)
 
 	if err != nil {
 		return err
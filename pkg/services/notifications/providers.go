@@ -0,0 +1,400 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Email providers supported by the [smtp] provider setting.
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSES      = "ses"
+	ProviderSendGrid = "sendgrid"
+	ProviderMailgun  = "mailgun"
+)
+
+const (
+	defaultSendGridURL = "https://api.sendgrid.com"
+	defaultMailgunURL  = "https://api.mailgun.net"
+)
+
+var providerHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// providerMailer sends emails through the provider of the current settings and
+// switches to another provider when the settings are reloaded.
+type providerMailer struct {
+	mu       sync.RWMutex
+	provider string
+	mailer   Mailer
+}
+
+func newProviderMailer(cfg setting.SmtpSettings) (*providerMailer, error) {
+	pm := &providerMailer{}
+	if err := pm.setSettings(cfg); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+func (pm *providerMailer) setSettings(cfg setting.SmtpSettings) error {
+	provider := cfg.Provider
+	if provider == "" {
+		provider = ProviderSMTP
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if client, ok := pm.mailer.(*SmtpClient); ok && provider == ProviderSMTP {
+		client.setSettings(cfg)
+		return nil
+	}
+
+	mailer, err := newMailer(provider, cfg)
+	if err != nil {
+		return err
+	}
+	pm.provider = provider
+	pm.mailer = mailer
+	return nil
+}
+
+func (pm *providerMailer) Send(messages ...*Message) (int, error) {
+	pm.mu.RLock()
+	provider, mailer := pm.provider, pm.mailer
+	pm.mu.RUnlock()
+
+	for _, msg := range messages {
+		msg.Provider = provider
+	}
+	return mailer.Send(messages...)
+}
+
+func newMailer(provider string, cfg setting.SmtpSettings) (Mailer, error) {
+	switch provider {
+	case ProviderSMTP:
+		return NewSmtpClient(cfg)
+	case ProviderSES:
+		return newSESMailer(cfg)
+	case ProviderSendGrid:
+		return newSendGridMailer(cfg)
+	case ProviderMailgun:
+		return newMailgunMailer(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported email provider %q", provider)
+	}
+}
+
+// sendEach sends the messages one by one and records the message ID returned by the provider.
+func sendEach(messages []*Message, send func(msg *Message) (string, error)) (int, error) {
+	sentEmailsCount := 0
+	var err error
+
+	for _, msg := range messages {
+		id, innerError := send(msg)
+		emailsSentTotal.Inc()
+		if innerError != nil {
+			emailsSentFailed.Inc()
+			err = fmt.Errorf("failed to send notification to email addresses: %s: %w", strings.Join(msg.To, ";"), innerError)
+			continue
+		}
+
+		msg.ProviderMessageID = id
+		sentEmailsCount++
+	}
+
+	return sentEmailsCount, err
+}
+
+func buildRawEmail(msg *Message, contentTypes []string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := buildMIMEMessage(msg, contentTypes).WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func doProviderRequest(provider string, req *http.Request, result interface{}) (*http.Response, error) {
+	resp, err := providerHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s API returned status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return nil, fmt.Errorf("failed to parse %s API response: %w", provider, err)
+		}
+	}
+	return resp, nil
+}
+
+// sesMailer sends emails with the Amazon SES SendRawEmail API. Credentials are
+// taken from the default AWS credential chain.
+type sesMailer struct {
+	client       sesiface.SESAPI
+	contentTypes []string
+}
+
+func newSESMailer(cfg setting.SmtpSettings) (*sesMailer, error) {
+	awsCfg := aws.NewConfig()
+	if cfg.APIRegion != "" {
+		awsCfg = awsCfg.WithRegion(cfg.APIRegion)
+	}
+	if cfg.APIURL != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.APIURL)
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session for the ses email provider: %w", err)
+	}
+
+	return &sesMailer{client: ses.New(sess), contentTypes: cfg.ContentTypes}, nil
+}
+
+func (m *sesMailer) Send(messages ...*Message) (int, error) {
+	return sendEach(messages, func(msg *Message) (string, error) {
+		raw, err := buildRawEmail(msg, m.contentTypes)
+		if err != nil {
+			return "", err
+		}
+
+		out, err := m.client.SendRawEmail(&ses.SendRawEmailInput{
+			Destinations: aws.StringSlice(msg.To),
+			RawMessage:   &ses.RawMessage{Data: raw},
+		})
+		if err != nil {
+			return "", err
+		}
+		return aws.StringValue(out.MessageId), nil
+	})
+}
+
+// sendGridMailer sends emails with the SendGrid v3 mail send API.
+type sendGridMailer struct {
+	apiKey       string
+	url          string
+	contentTypes []string
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition,omitempty"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyToList      []sendGridAddress         `json:"reply_to_list,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+func newSendGridMailer(cfg setting.SmtpSettings) (*sendGridMailer, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("the sendgrid email provider requires [smtp] api_key")
+	}
+
+	url := cfg.APIURL
+	if url == "" {
+		url = defaultSendGridURL
+	}
+	return &sendGridMailer{apiKey: cfg.APIKey, url: url, contentTypes: cfg.ContentTypes}, nil
+}
+
+func (m *sendGridMailer) Send(messages ...*Message) (int, error) {
+	return sendEach(messages, func(msg *Message) (string, error) {
+		mail, err := m.buildMail(msg)
+		if err != nil {
+			return "", err
+		}
+
+		body, err := json.Marshal(mail)
+		if err != nil {
+			return "", err
+		}
+
+		req, err := http.NewRequest(http.MethodPost, m.url+"/v3/mail/send", bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := doProviderRequest(ProviderSendGrid, req, nil)
+		if err != nil {
+			return "", err
+		}
+		return resp.Header.Get("X-Message-Id"), nil
+	})
+}
+
+func (m *sendGridMailer) buildMail(msg *Message) (*sendGridMail, error) {
+	from, err := parseSendGridAddress(msg.From)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sendGridMail{From: from, Subject: msg.Subject}
+
+	to := make([]sendGridAddress, 0, len(msg.To))
+	for _, address := range msg.To {
+		a, err := parseSendGridAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		to = append(to, a)
+	}
+	result.Personalizations = []sendGridPersonalization{{To: to}}
+
+	for _, address := range msg.ReplyTo {
+		a, err := parseSendGridAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		result.ReplyToList = append(result.ReplyToList, a)
+	}
+
+	// SendGrid requires the plain text content to come before the HTML content.
+	for _, contentType := range []string{"text/plain", "text/html"} {
+		for _, configured := range m.contentTypes {
+			if configured == contentType {
+				result.Content = append(result.Content, sendGridContent{Type: contentType, Value: msg.Body[contentType]})
+			}
+		}
+	}
+
+	for _, file := range msg.EmbeddedFiles {
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded file: %w", err)
+		}
+		name := filepath.Base(file)
+		result.Attachments = append(result.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(content),
+			Filename:    name,
+			Disposition: "inline",
+			ContentID:   name,
+		})
+	}
+
+	for _, file := range msg.AttachedFiles {
+		result.Attachments = append(result.Attachments, sendGridAttachment{
+			Content:  base64.StdEncoding.EncodeToString(file.Content),
+			Filename: file.Name,
+		})
+	}
+
+	return result, nil
+}
+
+func parseSendGridAddress(address string) (sendGridAddress, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return sendGridAddress{}, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	return sendGridAddress{Email: parsed.Address, Name: parsed.Name}, nil
+}
+
+// mailgunMailer sends emails with the Mailgun MIME messages API.
+type mailgunMailer struct {
+	apiKey       string
+	url          string
+	domain       string
+	contentTypes []string
+}
+
+func newMailgunMailer(cfg setting.SmtpSettings) (*mailgunMailer, error) {
+	if cfg.APIKey == "" || cfg.MailgunDomain == "" {
+		return nil, fmt.Errorf("the mailgun email provider requires [smtp] api_key and mailgun_domain")
+	}
+
+	url := cfg.APIURL
+	if url == "" {
+		url = defaultMailgunURL
+	}
+	return &mailgunMailer{apiKey: cfg.APIKey, url: url, domain: cfg.MailgunDomain, contentTypes: cfg.ContentTypes}, nil
+}
+
+func (m *mailgunMailer) Send(messages ...*Message) (int, error) {
+	return sendEach(messages, func(msg *Message) (string, error) {
+		raw, err := buildRawEmail(msg, m.contentTypes)
+		if err != nil {
+			return "", err
+		}
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		if err := writer.WriteField("to", strings.Join(msg.To, ",")); err != nil {
+			return "", err
+		}
+		part, err := writer.CreateFormFile("message", "message.mime")
+		if err != nil {
+			return "", err
+		}
+		if _, err := part.Write(raw); err != nil {
+			return "", err
+		}
+		if err := writer.Close(); err != nil {
+			return "", err
+		}
+
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v3/%s/messages.mime", m.url, m.domain), &body)
+		if err != nil {
+			return "", err
+		}
+		req.SetBasicAuth("api", m.apiKey)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		var result struct {
+			ID string `json:"id"`
+		}
+		if _, err := doProviderRequest(ProviderMailgun, req, &result); err != nil {
+			return "", err
+		}
+		return result.ID, nil
+	})
+}
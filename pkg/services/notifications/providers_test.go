@@ -0,0 +1,134 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestProviderMailer(t *testing.T) {
+	message := func() *Message {
+		return &Message{
+			To:      []string{"to@address.com"},
+			From:    "Grafana <from@address.com>",
+			Subject: "Some subject",
+			Body: map[string]string{
+				"text/html":  "Some HTML body",
+				"text/plain": "Some plain text body",
+			},
+		}
+	}
+
+	t.Run("SendGrid posts the message as JSON", func(t *testing.T) {
+		var mail sendGridMail
+		var auth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v3/mail/send", r.URL.Path)
+			auth = r.Header.Get("Authorization")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&mail))
+			w.Header().Set("X-Message-Id", "sendgrid-id")
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		t.Cleanup(server.Close)
+
+		mailer, err := newProviderMailer(setting.SmtpSettings{
+			Provider:     ProviderSendGrid,
+			APIKey:       "key",
+			APIURL:       server.URL,
+			ContentTypes: []string{"text/html", "text/plain"},
+		})
+		require.NoError(t, err)
+
+		msg := message()
+		count, err := mailer.Send(msg)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, ProviderSendGrid, msg.Provider)
+		assert.Equal(t, "sendgrid-id", msg.ProviderMessageID)
+
+		assert.Equal(t, "Bearer key", auth)
+		assert.Equal(t, sendGridAddress{Email: "from@address.com", Name: "Grafana"}, mail.From)
+		require.Len(t, mail.Content, 2)
+		assert.Equal(t, "text/plain", mail.Content[0].Type)
+		assert.Equal(t, "text/html", mail.Content[1].Type)
+	})
+
+	t.Run("Mailgun posts the MIME message", func(t *testing.T) {
+		var to, raw string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v3/mg.address.com/messages.mime", r.URL.Path)
+			user, pass, _ := r.BasicAuth()
+			assert.Equal(t, "api", user)
+			assert.Equal(t, "key", pass)
+
+			to = r.FormValue("to")
+			file, _, err := r.FormFile("message")
+			require.NoError(t, err)
+			content, err := io.ReadAll(file)
+			require.NoError(t, err)
+			raw = string(content)
+
+			_, _ = w.Write([]byte(`{"id":"<mailgun-id>","message":"Queued. Thank you."}`))
+		}))
+		t.Cleanup(server.Close)
+
+		mailer, err := newProviderMailer(setting.SmtpSettings{
+			Provider:      ProviderMailgun,
+			APIKey:        "key",
+			APIURL:        server.URL,
+			MailgunDomain: "mg.address.com",
+			ContentTypes:  []string{"text/html"},
+		})
+		require.NoError(t, err)
+
+		msg := message()
+		count, err := mailer.Send(msg)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, "<mailgun-id>", msg.ProviderMessageID)
+		assert.Equal(t, "to@address.com", to)
+		assert.Contains(t, raw, "Some HTML body")
+	})
+
+	t.Run("API errors fail the send", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}))
+		t.Cleanup(server.Close)
+
+		mailer, err := newProviderMailer(setting.SmtpSettings{Provider: ProviderSendGrid, APIKey: "key", APIURL: server.URL})
+		require.NoError(t, err)
+
+		count, err := mailer.Send(message())
+		require.ErrorContains(t, err, "sendgrid API returned status 403")
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("Reloading the settings switches the provider", func(t *testing.T) {
+		mailer, err := newProviderMailer(setting.SmtpSettings{Host: "localhost:25"})
+		require.NoError(t, err)
+		assert.IsType(t, &SmtpClient{}, mailer.mailer)
+
+		require.NoError(t, mailer.setSettings(setting.SmtpSettings{Provider: ProviderSES, APIRegion: "eu-west-1"}))
+		assert.IsType(t, &sesMailer{}, mailer.mailer)
+
+		err = mailer.setSettings(setting.SmtpSettings{Provider: "unknown"})
+		require.Error(t, err)
+		assert.IsType(t, &sesMailer{}, mailer.mailer)
+	})
+
+	t.Run("API providers require their settings", func(t *testing.T) {
+		_, err := newProviderMailer(setting.SmtpSettings{Provider: ProviderSendGrid})
+		require.Error(t, err)
+
+		_, err = newProviderMailer(setting.SmtpSettings{Provider: ProviderMailgun, APIKey: "key"})
+		require.Error(t, err)
+	})
+}
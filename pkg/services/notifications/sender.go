@@ -0,0 +1,147 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrSenderIdentityNotFound = errors.New("organization has no email sender identity")
+	ErrInvalidSenderAddress   = errors.New("invalid sender address")
+	ErrSenderDomainNotAllowed = errors.New("the domain of the sender address is not allowed")
+)
+
+// SenderIdentity overrides the sender of the emails sent on behalf of an organization.
+type SenderIdentity struct {
+	ID          int64     `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID       int64     `json:"orgId" xorm:"org_id"`
+	FromAddress string    `json:"fromAddress" xorm:"from_address"`
+	FromName    string    `json:"fromName" xorm:"from_name"`
+	ReplyTo     string    `json:"replyTo,omitempty" xorm:"reply_to"`
+	Created     time.Time `json:"created" xorm:"created"`
+	Updated     time.Time `json:"updated" xorm:"updated"`
+}
+
+func (s SenderIdentity) TableName() string {
+	return "org_email_sender"
+}
+
+// SetSenderIdentityCommand sets the sender identity of an organization.
+type SetSenderIdentityCommand struct {
+	OrgID       int64  `json:"-"`
+	FromAddress string `json:"fromAddress"`
+	FromName    string `json:"fromName"`
+	ReplyTo     string `json:"replyTo"`
+}
+
+// GetSenderIdentity returns the sender identity of the organization.
+func (ns *NotificationService) GetSenderIdentity(ctx context.Context, orgID int64) (*SenderIdentity, error) {
+	if ns.emailStore == nil {
+		return nil, ErrSenderIdentityNotFound
+	}
+	return ns.emailStore.GetSenderIdentity(ctx, orgID)
+}
+
+// SetSenderIdentity validates and stores the sender identity of an organization.
+func (ns *NotificationService) SetSenderIdentity(ctx context.Context, cmd SetSenderIdentityCommand) (*SenderIdentity, error) {
+	if ns.emailStore == nil {
+		return nil, errors.New("sender identities are not available")
+	}
+
+	if err := ns.validateSenderAddress(cmd.FromAddress); err != nil {
+		return nil, err
+	}
+	if cmd.ReplyTo != "" && !util.IsEmail(cmd.ReplyTo) {
+		return nil, fmt.Errorf("%w: reply-to %q", ErrInvalidSenderAddress, cmd.ReplyTo)
+	}
+
+	now := time.Now()
+	identity := &SenderIdentity{
+		OrgID:       cmd.OrgID,
+		FromAddress: cmd.FromAddress,
+		FromName:    cmd.FromName,
+		ReplyTo:     cmd.ReplyTo,
+		Created:     now,
+		Updated:     now,
+	}
+	if err := ns.emailStore.SetSenderIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// DeleteSenderIdentity removes the sender identity of the organization, emails are then
+// sent with the server-wide from_address again.
+func (ns *NotificationService) DeleteSenderIdentity(ctx context.Context, orgID int64) error {
+	if ns.emailStore == nil {
+		return ErrSenderIdentityNotFound
+	}
+	return ns.emailStore.DeleteSenderIdentity(ctx, orgID)
+}
+
+// validateSenderAddress allows addresses in the domain of from_address or in one of the
+// allowed_sender_domains, so that organizations cannot impersonate arbitrary senders.
+func (ns *NotificationService) validateSenderAddress(address string) error {
+	if !util.IsEmail(address) {
+		return fmt.Errorf("%w: %q", ErrInvalidSenderAddress, address)
+	}
+
+	domain := emailDomain(address)
+	if domain == emailDomain(ns.Cfg.Smtp.FromAddress) {
+		return nil
+	}
+	for _, allowed := range ns.Cfg.Smtp.AllowedSenderDomains {
+		if domain == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSenderDomainNotAllowed, domain)
+}
+
+// applySenderIdentity replaces the sender of msg with the identity of its organization, if any.
+func (ns *NotificationService) applySenderIdentity(ctx context.Context, msg *Message) {
+	if msg.OrgID == 0 || ns.emailStore == nil {
+		return
+	}
+
+	identity, err := ns.GetSenderIdentity(ctx, msg.OrgID)
+	if err != nil {
+		if !errors.Is(err, ErrSenderIdentityNotFound) {
+			ns.log.Warn("Failed to look up email sender identity, using the default sender", "orgId", msg.OrgID, "error", err)
+		}
+		return
+	}
+
+	// The allowed domains may have changed since the identity was stored.
+	if err := ns.validateSenderAddress(identity.FromAddress); err != nil {
+		ns.log.Warn("Ignoring email sender identity", "orgId", msg.OrgID, "error", err)
+		return
+	}
+
+	name := identity.FromName
+	if name == "" {
+		name = ns.Cfg.Smtp.FromName
+	}
+	addr := mail.Address{Name: name, Address: identity.FromAddress}
+	msg.From = addr.String()
+	if identity.ReplyTo != "" && len(msg.ReplyTo) == 0 {
+		msg.ReplyTo = []string{identity.ReplyTo}
+	}
+}
+
+func emailDomain(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+	idx := strings.LastIndex(address, "@")
+	if idx < 0 {
+		return ""
+	}
+	return strings.ToLower(address[idx+1:])
+}
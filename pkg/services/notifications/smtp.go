@@ -7,17 +7,31 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/setting"
 	gomail "gopkg.in/mail.v2"
 )
 
 type SmtpClient struct {
+	mu  sync.RWMutex
 	cfg setting.SmtpSettings
 }
 
-func ProvideSmtpService(cfg *setting.Cfg) (Mailer, error) {
-	return NewSmtpClient(cfg.Smtp)
+// ProvideSmtpService returns the Mailer of the provider configured in [smtp] provider.
+func ProvideSmtpService(cfg *setting.Cfg, settingsProvider setting.Provider) (Mailer, error) {
+	mailer, err := newProviderMailer(cfg.Smtp)
+	if err != nil {
+		return nil, err
+	}
+
+	// the SMTP settings are read again by the settings provider before the handler is called
+	reload := setting.ReloadFunc(func(setting.Section) error {
+		return mailer.setSettings(cfg.Smtp)
+	})
+	settingsProvider.RegisterReloadHandler("smtp", reload)
+	settingsProvider.RegisterReloadHandler("emails", reload)
+	return mailer, nil
 }
 
 func NewSmtpClient(cfg setting.SmtpSettings) (*SmtpClient, error) {
@@ -28,6 +42,18 @@ func NewSmtpClient(cfg setting.SmtpSettings) (*SmtpClient, error) {
 	return client, nil
 }
 
+func (sc *SmtpClient) setSettings(cfg setting.SmtpSettings) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.cfg = cfg
+}
+
+func (sc *SmtpClient) settings() setting.SmtpSettings {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.cfg
+}
+
 func (sc *SmtpClient) Send(messages ...*Message) (int, error) {
 	sentEmailsCount := 0
 	dialer, err := sc.createDialer()
@@ -60,21 +86,27 @@ func (sc *SmtpClient) Send(messages ...*Message) (int, error) {
 
 // buildEmail converts the Message DTO to a gomail message.
 func (sc *SmtpClient) buildEmail(msg *Message) *gomail.Message {
+	return buildMIMEMessage(msg, sc.settings().ContentTypes)
+}
+
+// buildMIMEMessage converts the Message DTO to a gomail message whose alternative bodies
+// follow the order of contentTypes.
+func buildMIMEMessage(msg *Message, contentTypes []string) *gomail.Message {
 	m := gomail.NewMessage()
 	m.SetHeader("From", msg.From)
 	m.SetHeader("To", msg.To...)
 	m.SetHeader("Subject", msg.Subject)
-	sc.setFiles(m, msg)
+	setFiles(m, msg)
 	for _, replyTo := range msg.ReplyTo {
 		m.SetAddressHeader("Reply-To", replyTo, "")
 	}
 	// loop over content types from settings in reverse order as they are ordered in according to descending
 	// preference while the alternatives should be ordered according to ascending preference
-	for i := len(sc.cfg.ContentTypes) - 1; i >= 0; i-- {
-		if i == len(sc.cfg.ContentTypes)-1 {
-			m.SetBody(sc.cfg.ContentTypes[i], msg.Body[sc.cfg.ContentTypes[i]])
+	for i := len(contentTypes) - 1; i >= 0; i-- {
+		if i == len(contentTypes)-1 {
+			m.SetBody(contentTypes[i], msg.Body[contentTypes[i]])
 		} else {
-			m.AddAlternative(sc.cfg.ContentTypes[i], msg.Body[sc.cfg.ContentTypes[i]])
+			m.AddAlternative(contentTypes[i], msg.Body[contentTypes[i]])
 		}
 	}
 
@@ -82,7 +114,7 @@ func (sc *SmtpClient) buildEmail(msg *Message) *gomail.Message {
 }
 
 // setFiles attaches files in various forms.
-func (sc *SmtpClient) setFiles(
+func setFiles(
 	m *gomail.Message,
 	msg *Message,
 ) {
@@ -100,7 +132,8 @@ func (sc *SmtpClient) setFiles(
 }
 
 func (sc *SmtpClient) createDialer() (*gomail.Dialer, error) {
-	host, port, err := net.SplitHostPort(sc.cfg.Host)
+	cfg := sc.settings()
+	host, port, err := net.SplitHostPort(cfg.Host)
 	if err != nil {
 		return nil, err
 	}
@@ -110,24 +143,24 @@ func (sc *SmtpClient) createDialer() (*gomail.Dialer, error) {
 	}
 
 	tlsconfig := &tls.Config{
-		InsecureSkipVerify: sc.cfg.SkipVerify,
+		InsecureSkipVerify: cfg.SkipVerify,
 		ServerName:         host,
 	}
 
-	if sc.cfg.CertFile != "" {
-		cert, err := tls.LoadX509KeyPair(sc.cfg.CertFile, sc.cfg.KeyFile)
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
 		if err != nil {
 			return nil, fmt.Errorf("could not load cert or key file: %w", err)
 		}
 		tlsconfig.Certificates = []tls.Certificate{cert}
 	}
 
-	d := gomail.NewDialer(host, iPort, sc.cfg.User, sc.cfg.Password)
+	d := gomail.NewDialer(host, iPort, cfg.User, cfg.Password)
 	d.TLSConfig = tlsconfig
-	d.StartTLSPolicy = getStartTLSPolicy(sc.cfg.StartTLSPolicy)
+	d.StartTLSPolicy = getStartTLSPolicy(cfg.StartTLSPolicy)
 
-	if sc.cfg.EhloIdentity != "" {
-		d.LocalName = sc.cfg.EhloIdentity
+	if cfg.EhloIdentity != "" {
+		d.LocalName = cfg.EhloIdentity
 	} else {
 		d.LocalName = setting.InstanceName
 	}
@@ -0,0 +1,28 @@
+package notifications
+
+import (
+	"context"
+	"time"
+)
+
+// EmailStore keeps the email delivery history and the sender identities and custom email
+// templates of the organizations. It lives outside of this package as the database packages
+// depend on it.
+type EmailStore interface {
+	InsertDelivery(ctx context.Context, delivery *Delivery) error
+	GetDeliveries(ctx context.Context, query DeliveriesQuery) ([]Delivery, error)
+	DeleteDeliveriesOlderThan(ctx context.Context, olderThan time.Time) (int64, error)
+
+	// GetSenderIdentity returns ErrSenderIdentityNotFound if the organization has none.
+	GetSenderIdentity(ctx context.Context, orgID int64) (*SenderIdentity, error)
+	// SetSenderIdentity creates or replaces the sender identity of identity.OrgID.
+	SetSenderIdentity(ctx context.Context, identity *SenderIdentity) error
+	DeleteSenderIdentity(ctx context.Context, orgID int64) error
+
+	GetEmailTemplates(ctx context.Context, orgID int64) ([]EmailTemplate, error)
+	// GetEmailTemplate returns ErrEmailTemplateNotFound if the organization did not customize it.
+	GetEmailTemplate(ctx context.Context, orgID int64, name string) (*EmailTemplate, error)
+	// SetEmailTemplate creates or replaces the template named template.Name of template.OrgID.
+	SetEmailTemplate(ctx context.Context, template *EmailTemplate) error
+	DeleteEmailTemplate(ctx context.Context, orgID int64, name string) error
+}
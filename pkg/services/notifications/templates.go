@@ -0,0 +1,273 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// maxTemplateBodySize limits the size of each body of a custom email template.
+const maxTemplateBodySize = 512 * 1024
+
+var (
+	ErrEmailTemplateNotFound = errors.New("email template not found")
+	ErrInvalidEmailTemplate  = errors.New("invalid email template")
+)
+
+// EmailTemplate replaces the email template of the same name for an organization.
+type EmailTemplate struct {
+	ID      int64     `xorm:"pk autoincr 'id'"`
+	OrgID   int64     `xorm:"org_id"`
+	Name    string    `xorm:"name"`
+	Subject string    `xorm:"subject"`
+	HTML    string    `xorm:"html_body"`
+	Text    string    `xorm:"text_body"`
+	Created time.Time `xorm:"created"`
+	Updated time.Time `xorm:"updated"`
+}
+
+func (t EmailTemplate) TableName() string {
+	return "email_template"
+}
+
+// EmailTemplateDTO is an email template as seen by an organization. It is the custom
+// template of the organization when Customized is set and the built-in one otherwise.
+type EmailTemplateDTO struct {
+	Name       string     `json:"name"`
+	Subject    string     `json:"subject,omitempty"`
+	HTML       string     `json:"html,omitempty"`
+	Text       string     `json:"text,omitempty"`
+	Customized bool       `json:"customized"`
+	Updated    *time.Time `json:"updated,omitempty"`
+}
+
+// SetEmailTemplateCommand customizes an email template for an organization.
+type SetEmailTemplateCommand struct {
+	OrgID int64  `json:"-"`
+	Name  string `json:"-"`
+	// Subject overrides the subject set by the template with the Subject function. It is a
+	// template itself, executed with the same data as the bodies.
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+func newMailTemplate(name string) *template.Template {
+	return template.New(name).Funcs(template.FuncMap{
+		"Subject": subjectTemplateFunc,
+	}).Funcs(sprig.FuncMap())
+}
+
+// loadTemplateFiles remembers the file of every built-in template so that its source can be
+// returned to organizations customizing it.
+func (ns *NotificationService) loadTemplateFiles() error {
+	ns.templateFiles = map[string]string{}
+	for _, pattern := range ns.Cfg.Smtp.TemplatesPatterns {
+		files, err := filepath.Glob(filepath.Join(ns.Cfg.StaticRootPath, pattern))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			ns.templateFiles[filepath.Base(file)] = file
+		}
+	}
+	return nil
+}
+
+// templateNames returns the names of the built-in templates, without file extension.
+func (ns *NotificationService) templateNames() []string {
+	seen := map[string]bool{}
+	names := make([]string, 0, len(ns.templateFiles))
+	for file := range ns.templateFiles {
+		name := strings.TrimSuffix(file, filepath.Ext(file))
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (ns *NotificationService) isTemplate(name string) bool {
+	_, html := ns.templateFiles[name+".html"]
+	_, txt := ns.templateFiles[name+".txt"]
+	return html || txt
+}
+
+func (ns *NotificationService) defaultTemplate(name string) (*EmailTemplateDTO, error) {
+	dto := &EmailTemplateDTO{Name: name}
+	for ext, body := range map[string]*string{".html": &dto.HTML, ".txt": &dto.Text} {
+		file, ok := ns.templateFiles[name+ext]
+		if !ok {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, err
+		}
+		*body = string(content)
+	}
+	return dto, nil
+}
+
+// GetEmailTemplates lists the email templates of the organization.
+func (ns *NotificationService) GetEmailTemplates(ctx context.Context, orgID int64) ([]EmailTemplateDTO, error) {
+	custom := map[string]EmailTemplate{}
+	if ns.emailStore != nil {
+		rows, err := ns.emailStore.GetEmailTemplates(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			custom[row.Name] = row
+		}
+	}
+
+	result := make([]EmailTemplateDTO, 0, len(ns.templateFiles))
+	for _, name := range ns.templateNames() {
+		dto := EmailTemplateDTO{Name: name}
+		if row, ok := custom[name]; ok {
+			updated := row.Updated
+			dto.Customized = true
+			dto.Updated = &updated
+		}
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+// GetEmailTemplate returns the template the organization sends emails with, either its custom
+// template or the built-in one.
+func (ns *NotificationService) GetEmailTemplate(ctx context.Context, orgID int64, name string) (*EmailTemplateDTO, error) {
+	if !ns.isTemplate(name) {
+		return nil, ErrEmailTemplateNotFound
+	}
+
+	custom, err := ns.getCustomTemplate(ctx, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	if custom == nil {
+		return ns.defaultTemplate(name)
+	}
+
+	return &EmailTemplateDTO{
+		Name:       custom.Name,
+		Subject:    custom.Subject,
+		HTML:       custom.HTML,
+		Text:       custom.Text,
+		Customized: true,
+		Updated:    &custom.Updated,
+	}, nil
+}
+
+// SetEmailTemplate validates and stores a custom template for the organization.
+func (ns *NotificationService) SetEmailTemplate(ctx context.Context, cmd SetEmailTemplateCommand) (*EmailTemplateDTO, error) {
+	if ns.emailStore == nil {
+		return nil, errors.New("custom email templates are not available")
+	}
+	if !ns.isTemplate(cmd.Name) {
+		return nil, ErrEmailTemplateNotFound
+	}
+	if err := validateEmailTemplate(cmd); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	row := &EmailTemplate{
+		OrgID:   cmd.OrgID,
+		Name:    cmd.Name,
+		Subject: cmd.Subject,
+		HTML:    cmd.HTML,
+		Text:    cmd.Text,
+		Created: now,
+		Updated: now,
+	}
+	if err := ns.emailStore.SetEmailTemplate(ctx, row); err != nil {
+		return nil, err
+	}
+
+	return &EmailTemplateDTO{
+		Name:       row.Name,
+		Subject:    row.Subject,
+		HTML:       row.HTML,
+		Text:       row.Text,
+		Customized: true,
+		Updated:    &row.Updated,
+	}, nil
+}
+
+// DeleteEmailTemplate removes the custom template of the organization, which then sends the
+// built-in template again.
+func (ns *NotificationService) DeleteEmailTemplate(ctx context.Context, orgID int64, name string) error {
+	if ns.emailStore == nil {
+		return ErrEmailTemplateNotFound
+	}
+	return ns.emailStore.DeleteEmailTemplate(ctx, orgID, name)
+}
+
+// getCustomTemplate returns the custom template of the organization, or nil if it has none.
+func (ns *NotificationService) getCustomTemplate(ctx context.Context, orgID int64, name string) (*EmailTemplate, error) {
+	if ns.emailStore == nil || orgID == 0 {
+		return nil, nil
+	}
+
+	template, err := ns.emailStore.GetEmailTemplate(ctx, orgID, name)
+	if errors.Is(err, ErrEmailTemplateNotFound) {
+		return nil, nil
+	}
+	return template, err
+}
+
+func validateEmailTemplate(cmd SetEmailTemplateCommand) error {
+	if cmd.HTML == "" && cmd.Text == "" {
+		return fmt.Errorf("%w: html or text is required", ErrInvalidEmailTemplate)
+	}
+
+	if cmd.Subject == "" && !strings.Contains(cmd.HTML+cmd.Text, "Subject") {
+		return fmt.Errorf("%w: set the subject or call the Subject function in the template", ErrInvalidEmailTemplate)
+	}
+
+	for part, content := range map[string]string{"subject": cmd.Subject, "html": cmd.HTML, "text": cmd.Text} {
+		if len(content) > maxTemplateBodySize {
+			return fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidEmailTemplate, part, maxTemplateBodySize)
+		}
+		if _, err := newMailTemplate(part).Parse(content); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidEmailTemplate, err.Error())
+		}
+	}
+	return nil
+}
+
+// customTemplateBody renders the body of the custom template for the content type. It returns
+// false when the custom template has no body for it, so that the built-in one is used instead.
+func customTemplateBody(custom *EmailTemplate, contentType string, data map[string]interface{}) (string, bool, error) {
+	content := custom.HTML
+	if contentType == "text/plain" {
+		content = custom.Text
+	}
+	if content == "" {
+		return "", false, nil
+	}
+
+	tmpl, err := newMailTemplate(custom.Name).Parse(content)
+	if err != nil {
+		return "", false, err
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", false, err
+	}
+	return buffer.String(), true, nil
+}
@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEmailTemplate(t *testing.T) {
+	t.Run("accepts a template setting its subject", func(t *testing.T) {
+		err := validateEmailTemplate(SetEmailTemplateCommand{HTML: `{{ Subject .Subject "Hi {{ .Name }}" }}<p>Hello</p>`})
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts a subject field", func(t *testing.T) {
+		err := validateEmailTemplate(SetEmailTemplateCommand{Subject: "Hi {{ .Name }}", Text: "Hello {{ .Name }}"})
+		require.NoError(t, err)
+	})
+
+	t.Run("requires a body", func(t *testing.T) {
+		err := validateEmailTemplate(SetEmailTemplateCommand{Subject: "Hi"})
+		require.ErrorIs(t, err, ErrInvalidEmailTemplate)
+	})
+
+	t.Run("requires a subject", func(t *testing.T) {
+		err := validateEmailTemplate(SetEmailTemplateCommand{HTML: "<p>Hello</p>"})
+		require.ErrorIs(t, err, ErrInvalidEmailTemplate)
+	})
+
+	t.Run("rejects invalid templates", func(t *testing.T) {
+		err := validateEmailTemplate(SetEmailTemplateCommand{Subject: "Hi", HTML: "{{ .Name "})
+		require.ErrorIs(t, err, ErrInvalidEmailTemplate)
+	})
+}
+
+func TestCustomTemplateBody(t *testing.T) {
+	custom := &EmailTemplate{Name: "invited_to_org", HTML: `{{ Subject .Subject "Welcome to {{ .OrgName }}" }}<p>Join {{ .OrgName | upper }}</p>`}
+
+	data := map[string]interface{}{"OrgName": "Acme", "Subject": map[string]interface{}{}}
+	body, ok, err := customTemplateBody(custom, "text/html", data)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "<p>Join ACME</p>", body)
+	assert.Equal(t, "Welcome to {{ .OrgName }}", data["Subject"].(map[string]interface{})["value"])
+
+	_, ok, err = customTemplateBody(custom, "text/plain", data)
+	require.NoError(t, err)
+	assert.False(t, ok, "the built-in text template is used when the custom template has no text body")
+}
@@ -0,0 +1,353 @@
+package oncall
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *OnCallService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/oncall/schedules", func(schedules routing.RouteRegister) {
+		schedules.Get("/", routing.Wrap(s.getSchedulesHandler))
+		schedules.Post("/", middleware.ReqOrgAdmin, routing.Wrap(s.createScheduleHandler))
+		schedules.Get("/:uid", routing.Wrap(s.getScheduleHandler))
+		schedules.Put("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.updateScheduleHandler))
+		schedules.Delete("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.deleteScheduleHandler))
+		schedules.Get("/:uid/oncall", routing.Wrap(s.getOnCallHandler))
+		schedules.Get("/:uid/shifts", routing.Wrap(s.getShiftsHandler))
+		schedules.Get("/:uid/overrides", routing.Wrap(s.getOverridesHandler))
+		schedules.Post("/:uid/overrides", middleware.ReqEditorRole, routing.Wrap(s.createOverrideHandler))
+		schedules.Delete("/:uid/overrides/:id", middleware.ReqEditorRole, routing.Wrap(s.deleteOverrideHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /oncall/schedules oncall getOnCallSchedules
+//
+// Get all on-call schedules of the current organization.
+//
+// Responses:
+// 200: getOnCallSchedulesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *OnCallService) getSchedulesHandler(c *models.ReqContext) response.Response {
+	schedules, err := s.GetSchedules(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, schedules)
+}
+
+// swagger:route GET /oncall/schedules/{schedule_uid} oncall getOnCallSchedule
+//
+// Get an on-call schedule by uid.
+//
+// Responses:
+// 200: onCallScheduleResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) getScheduleHandler(c *models.ReqContext) response.Response {
+	schedule, err := s.GetSchedule(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route POST /oncall/schedules oncall createOnCallSchedule
+//
+// Create an on-call schedule.
+//
+// All users of the rotations must be members of the organization. Requires the organization admin role.
+//
+// Responses:
+// 200: onCallScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *OnCallService) createScheduleHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	schedule, err := s.CreateSchedule(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route PUT /oncall/schedules/{schedule_uid} oncall updateOnCallSchedule
+//
+// Update the name and rotations of an on-call schedule.
+//
+// Requires the organization admin role.
+//
+// Responses:
+// 200: onCallScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) updateScheduleHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	schedule, err := s.UpdateSchedule(c.Req.Context(), web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route DELETE /oncall/schedules/{schedule_uid} oncall deleteOnCallSchedule
+//
+// Delete an on-call schedule and its overrides.
+//
+// Contact points referencing the schedule fall back to their fallback addresses. Requires the organization admin role.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) deleteScheduleHandler(c *models.ReqContext) response.Response {
+	if err := s.DeleteSchedule(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("On-call schedule deleted")
+}
+
+// swagger:route GET /oncall/schedules/{schedule_uid}/oncall oncall getOnCall
+//
+// Get the users on call for a schedule.
+//
+// Returns the users on call now, or at the time of the at parameter.
+//
+// Responses:
+// 200: onCallResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) getOnCallHandler(c *models.ReqContext) response.Response {
+	at := s.now()
+	if raw := c.Query("at"); raw != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, raw); err != nil {
+			return response.Error(http.StatusBadRequest, "at must be an RFC 3339 time", err)
+		}
+	}
+	onCall, err := s.GetOnCall(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"], at)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, onCall)
+}
+
+// swagger:route GET /oncall/schedules/{schedule_uid}/shifts oncall getOnCallShifts
+//
+// Get the upcoming shifts of a schedule.
+//
+// Returns the shifts of the rotations and the overrides from now on, ordered by their start.
+//
+// Responses:
+// 200: getOnCallShiftsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) getShiftsHandler(c *models.ReqContext) response.Response {
+	days := c.QueryInt("days")
+	if days == 0 {
+		days = defaultUpcomingDays
+	}
+	if days < 0 || days > maxUpcomingDays {
+		return response.Error(http.StatusBadRequest, "days must be between 1 and 90", nil)
+	}
+	from := s.now()
+	shifts, err := s.GetShifts(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"], from, from.AddDate(0, 0, days))
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, shifts)
+}
+
+// swagger:route GET /oncall/schedules/{schedule_uid}/overrides oncall getOnCallOverrides
+//
+// Get the overrides of a schedule.
+//
+// Overrides are kept for 30 days after they ended.
+//
+// Responses:
+// 200: getOnCallOverridesResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) getOverridesHandler(c *models.ReqContext) response.Response {
+	overrides, err := s.GetOverrides(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, overrides)
+}
+
+// swagger:route POST /oncall/schedules/{schedule_uid}/overrides oncall createOnCallOverride
+//
+// Put a user on call for a schedule instead of its rotations for a period.
+//
+// Requires the editor role.
+//
+// Responses:
+// 200: onCallOverrideResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) createOverrideHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOverrideCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.ScheduleUID = web.Params(c.Req)[":uid"]
+	cmd.CreatedBy = c.UserID
+	override, err := s.CreateOverride(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, override)
+}
+
+// swagger:route DELETE /oncall/schedules/{schedule_uid}/overrides/{override_id} oncall deleteOnCallOverride
+//
+// Delete an override of a schedule.
+//
+// Requires the editor role.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OnCallService) deleteOverrideHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if err := s.DeleteOverride(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"], id); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("On-call override deleted")
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrScheduleNotFound):
+		return response.Error(http.StatusNotFound, "On-call schedule not found", err)
+	case errors.Is(err, ErrOverrideNotFound):
+		return response.Error(http.StatusNotFound, "On-call override not found", err)
+	case errors.Is(err, ErrScheduleExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidSchedule), errors.Is(err, ErrInvalidOverride):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process on-call schedule", err)
+}
+
+// swagger:parameters getOnCallSchedule updateOnCallSchedule deleteOnCallSchedule getOnCall getOnCallShifts getOnCallOverrides createOnCallOverride deleteOnCallOverride
+type ScheduleUIDParams struct {
+	// in:path
+	// required:true
+	ScheduleUID string `json:"schedule_uid"`
+}
+
+// swagger:parameters createOnCallSchedule updateOnCallSchedule
+type CreateOrUpdateScheduleParams struct {
+	// in:body
+	// required:true
+	Body CreateOrUpdateScheduleCommand `json:"body"`
+}
+
+// swagger:parameters getOnCall
+type GetOnCallParams struct {
+	// RFC 3339 time to look up, defaults to now.
+	// in:query
+	// required:false
+	At string `json:"at"`
+}
+
+// swagger:parameters getOnCallShifts
+type GetShiftsParams struct {
+	// Number of days of shifts to return, defaults to 14.
+	// in:query
+	// required:false
+	Days int `json:"days"`
+}
+
+// swagger:parameters createOnCallOverride
+type CreateOverrideParams struct {
+	// in:body
+	// required:true
+	Body CreateOverrideCommand `json:"body"`
+}
+
+// swagger:parameters deleteOnCallOverride
+type OverrideIDParams struct {
+	// in:path
+	// required:true
+	OverrideID int64 `json:"override_id"`
+}
+
+// swagger:response getOnCallSchedulesResponse
+type GetSchedulesResponse struct {
+	// in:body
+	Body []*Schedule `json:"body"`
+}
+
+// swagger:response onCallScheduleResponse
+type ScheduleResponse struct {
+	// in:body
+	Body *Schedule `json:"body"`
+}
+
+// swagger:response onCallResponse
+type OnCallResponse struct {
+	// in:body
+	Body *OnCall `json:"body"`
+}
+
+// swagger:response getOnCallShiftsResponse
+type GetShiftsResponse struct {
+	// in:body
+	Body []Shift `json:"body"`
+}
+
+// swagger:response getOnCallOverridesResponse
+type GetOverridesResponse struct {
+	// in:body
+	Body []*Override `json:"body"`
+}
+
+// swagger:response onCallOverrideResponse
+type OverrideResponse struct {
+	// in:body
+	Body *Override `json:"body"`
+}
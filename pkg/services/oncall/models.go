@@ -0,0 +1,202 @@
+package oncall
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	maxNameLength       = 190
+	maxRotations        = 10
+	maxRotationUsers    = 100
+	minShiftLength      = time.Hour
+	defaultUpcomingDays = 14
+	maxUpcomingDays     = 90
+)
+
+var (
+	ErrScheduleNotFound = errors.New("on-call schedule not found")
+	ErrOverrideNotFound = errors.New("on-call override not found")
+	ErrInvalidSchedule  = errors.New("invalid on-call schedule")
+	ErrInvalidOverride  = errors.New("invalid on-call override")
+	ErrScheduleExists   = errors.New("an on-call schedule with the same uid already exists")
+)
+
+// Schedule is a set of rotations which hand over being on call between users. Notification
+// policies reference schedules by uid through the oncall contact point.
+// swagger:model OnCallSchedule
+type Schedule struct {
+	ID        int64      `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID     int64      `json:"orgId" xorm:"org_id"`
+	UID       string     `json:"uid" xorm:"uid"`
+	Name      string     `json:"name" xorm:"name"`
+	Rotations []Rotation `json:"rotations" xorm:"jsonb rotations"`
+	Created   time.Time  `json:"created" xorm:"created"`
+	Updated   time.Time  `json:"updated" xorm:"updated"`
+}
+
+func (s Schedule) TableName() string {
+	return "oncall_schedule"
+}
+
+// Rotation hands over being on call to the next of its users after every shift. The first user
+// is on call for the shift beginning at start. All rotations of a schedule are on call at the same
+// time, for example a primary and a secondary rotation.
+type Rotation struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	// Duration of a shift, e.g. `1d` or `1w`.
+	ShiftLength string  `json:"shiftLength"`
+	UserIDs     []int64 `json:"userIds"`
+}
+
+// Override puts a user on call for a schedule instead of its rotations for a period, for example
+// to cover the shift of somebody on vacation.
+// swagger:model OnCallOverride
+type Override struct {
+	ID         int64     `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID      int64     `json:"orgId" xorm:"org_id"`
+	ScheduleID int64     `json:"-" xorm:"schedule_id"`
+	UserID     int64     `json:"userId" xorm:"user_id"`
+	Start      time.Time `json:"start" xorm:"start_time"`
+	End        time.Time `json:"end" xorm:"end_time"`
+	CreatedBy  int64     `json:"createdBy" xorm:"created_by"`
+	Created    time.Time `json:"created" xorm:"created"`
+}
+
+func (o Override) TableName() string {
+	return "oncall_override"
+}
+
+// OnCallUser is a user who is on call. The email address is only used for notifications.
+type OnCallUser struct {
+	ID    int64  `json:"id" xorm:"id"`
+	Login string `json:"login" xorm:"login"`
+	Name  string `json:"name" xorm:"name"`
+	Email string `json:"-" xorm:"email"`
+}
+
+// OnCall are the users who are on call for a schedule at a time.
+// swagger:model OnCall
+type OnCall struct {
+	ScheduleUID string        `json:"scheduleUid"`
+	At          time.Time     `json:"at"`
+	Users       []*OnCallUser `json:"users"`
+	// Override is true if the users are on call because of an override.
+	Override bool `json:"override"`
+}
+
+// Shift is a period in which a user is on call for a schedule.
+// swagger:model OnCallShift
+type Shift struct {
+	// Rotation is the name of the rotation of the shift, empty for overrides.
+	Rotation string    `json:"rotation,omitempty"`
+	UserID   int64     `json:"userId"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Override bool      `json:"override"`
+}
+
+// CreateOrUpdateScheduleCommand is the body of the requests creating and updating schedules.
+// swagger:model CreateOrUpdateOnCallScheduleCommand
+type CreateOrUpdateScheduleCommand struct {
+	// Generated if empty when creating a schedule, ignored when updating one.
+	UID string `json:"uid"`
+	// required:true
+	Name      string     `json:"name"`
+	Rotations []Rotation `json:"rotations"`
+
+	OrgID int64 `json:"-"`
+}
+
+// validate checks the command and applies its defaults.
+func (cmd *CreateOrUpdateScheduleCommand) validate() error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if cmd.Name == "" || utf8.RuneCountInString(cmd.Name) > maxNameLength {
+		return fmt.Errorf("%w: name is required and must be at most %d characters", ErrInvalidSchedule, maxNameLength)
+	}
+	if cmd.UID != "" && !util.IsValidShortUID(cmd.UID) {
+		return fmt.Errorf("%w: uid contains illegal characters", ErrInvalidSchedule)
+	}
+	if len(cmd.Rotations) > maxRotations {
+		return fmt.Errorf("%w: a schedule can have at most %d rotations", ErrInvalidSchedule, maxRotations)
+	}
+	if cmd.Rotations == nil {
+		cmd.Rotations = []Rotation{}
+	}
+	for i := range cmd.Rotations {
+		r := &cmd.Rotations[i]
+		r.Name = strings.TrimSpace(r.Name)
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("Rotation %d", i+1)
+		}
+		if r.Start.IsZero() {
+			return fmt.Errorf("%w: rotation %q has no start", ErrInvalidSchedule, r.Name)
+		}
+		if _, err := r.shiftLength(); err != nil {
+			return err
+		}
+		if len(r.UserIDs) == 0 || len(r.UserIDs) > maxRotationUsers {
+			return fmt.Errorf("%w: rotation %q must have between 1 and %d users", ErrInvalidSchedule, r.Name, maxRotationUsers)
+		}
+	}
+	return nil
+}
+
+func (r Rotation) shiftLength() (time.Duration, error) {
+	length, err := gtime.ParseDuration(r.ShiftLength)
+	if err != nil {
+		return 0, fmt.Errorf("%w: rotation %q has an invalid shift length %q", ErrInvalidSchedule, r.Name, r.ShiftLength)
+	}
+	if length < minShiftLength {
+		return 0, fmt.Errorf("%w: shifts of rotation %q must be at least %s", ErrInvalidSchedule, r.Name, minShiftLength)
+	}
+	return length, nil
+}
+
+// userIDs returns the users of all rotations without duplicates.
+func (cmd *CreateOrUpdateScheduleCommand) userIDs() []int64 {
+	seen := map[int64]bool{}
+	var ids []int64
+	for _, r := range cmd.Rotations {
+		for _, id := range r.UserIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// CreateOverrideCommand is the body of the request creating an override.
+// swagger:model CreateOnCallOverrideCommand
+type CreateOverrideCommand struct {
+	// required:true
+	UserID int64 `json:"userId"`
+	// required:true
+	Start time.Time `json:"start"`
+	// required:true
+	End time.Time `json:"end"`
+
+	OrgID       int64  `json:"-"`
+	ScheduleUID string `json:"-"`
+	CreatedBy   int64  `json:"-"`
+}
+
+func (cmd *CreateOverrideCommand) validate() error {
+	if cmd.UserID == 0 {
+		return fmt.Errorf("%w: userId is required", ErrInvalidOverride)
+	}
+	if cmd.Start.IsZero() || !cmd.End.After(cmd.Start) {
+		return fmt.Errorf("%w: end must be after start", ErrInvalidOverride)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// overrideRetention is how long overrides are kept after they ended.
+const overrideRetention = 30 * 24 * time.Hour
+
+// Service manages on-call schedules and looks up who is on call. The oncall contact point of
+// Grafana Alerting notifies the users on call for a schedule.
+type Service interface {
+	GetSchedules(ctx context.Context, orgID int64) ([]*Schedule, error)
+	GetSchedule(ctx context.Context, orgID int64, uid string) (*Schedule, error)
+	CreateSchedule(ctx context.Context, cmd *CreateOrUpdateScheduleCommand) (*Schedule, error)
+	UpdateSchedule(ctx context.Context, uid string, cmd *CreateOrUpdateScheduleCommand) (*Schedule, error)
+	DeleteSchedule(ctx context.Context, orgID int64, uid string) error
+	GetOverrides(ctx context.Context, orgID int64, uid string) ([]*Override, error)
+	CreateOverride(ctx context.Context, cmd *CreateOverrideCommand) (*Override, error)
+	DeleteOverride(ctx context.Context, orgID int64, uid string, id int64) error
+	// GetOnCall returns the users on call for a schedule at a time.
+	GetOnCall(ctx context.Context, orgID int64, uid string, at time.Time) (*OnCall, error)
+	// GetShifts returns the shifts of a schedule which overlap a period.
+	GetShifts(ctx context.Context, orgID int64, uid string, from, to time.Time) ([]Shift, error)
+	// OnCallAddresses returns the email addresses of the users currently on call for a schedule.
+	OnCallAddresses(ctx context.Context, orgID int64, uid string) ([]string, error)
+}
+
+// OnCallService stores on-call schedules in the database. Users who are removed from the
+// organization stay in the rotations of a schedule, but are skipped when looking up who is on
+// call.
+type OnCallService struct {
+	store         db.DB
+	log           log.Logger
+	routeRegister routing.RouteRegister
+
+	now func() time.Time
+}
+
+func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister) *OnCallService {
+	s := &OnCallService{
+		store:         sqlStore,
+		log:           log.New("oncall"),
+		routeRegister: routeRegister,
+		now:           time.Now,
+	}
+	s.registerAPIEndpoints()
+	return s
+}
+
+func (s *OnCallService) GetSchedules(ctx context.Context, orgID int64) ([]*Schedule, error) {
+	return s.listSchedules(ctx, orgID)
+}
+
+func (s *OnCallService) GetSchedule(ctx context.Context, orgID int64, uid string) (*Schedule, error) {
+	return s.getSchedule(ctx, orgID, uid)
+}
+
+func (s *OnCallService) CreateSchedule(ctx context.Context, cmd *CreateOrUpdateScheduleCommand) (*Schedule, error) {
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkMembers(ctx, cmd.OrgID, cmd.userIDs()...); err != nil {
+		return nil, err
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+
+	now := s.now()
+	schedule := &Schedule{
+		OrgID:     cmd.OrgID,
+		UID:       cmd.UID,
+		Name:      cmd.Name,
+		Rotations: cmd.Rotations,
+		Created:   now,
+		Updated:   now,
+	}
+	if err := s.insertSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *OnCallService) UpdateSchedule(ctx context.Context, uid string, cmd *CreateOrUpdateScheduleCommand) (*Schedule, error) {
+	cmd.UID = ""
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	schedule, err := s.getSchedule(ctx, cmd.OrgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMembers(ctx, cmd.OrgID, cmd.userIDs()...); err != nil {
+		return nil, err
+	}
+
+	schedule.Name = cmd.Name
+	schedule.Rotations = cmd.Rotations
+	schedule.Updated = s.now()
+	if err := s.updateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *OnCallService) DeleteSchedule(ctx context.Context, orgID int64, uid string) error {
+	schedule, err := s.getSchedule(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	return s.deleteSchedule(ctx, schedule)
+}
+
+func (s *OnCallService) GetOverrides(ctx context.Context, orgID int64, uid string) ([]*Override, error) {
+	schedule, err := s.getSchedule(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.listOverrides(ctx, schedule, time.Time{}, time.Time{})
+}
+
+func (s *OnCallService) CreateOverride(ctx context.Context, cmd *CreateOverrideCommand) (*Override, error) {
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	schedule, err := s.getSchedule(ctx, cmd.OrgID, cmd.ScheduleUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMembers(ctx, cmd.OrgID, cmd.UserID); err != nil {
+		return nil, fmt.Errorf("%w: user %d is not a member of the organization", ErrInvalidOverride, cmd.UserID)
+	}
+
+	now := s.now()
+	override := &Override{
+		OrgID:      cmd.OrgID,
+		ScheduleID: schedule.ID,
+		UserID:     cmd.UserID,
+		Start:      cmd.Start,
+		End:        cmd.End,
+		CreatedBy:  cmd.CreatedBy,
+		Created:    now,
+	}
+	if err := s.insertOverride(ctx, override, now.Add(-overrideRetention)); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+func (s *OnCallService) DeleteOverride(ctx context.Context, orgID int64, uid string, id int64) error {
+	schedule, err := s.getSchedule(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	return s.deleteOverride(ctx, schedule, id)
+}
+
+func (s *OnCallService) GetOnCall(ctx context.Context, orgID int64, uid string, at time.Time) (*OnCall, error) {
+	schedule, err := s.getSchedule(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.listOverrides(ctx, schedule, at, at)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, override := onCallAt(schedule, overrides, at)
+	users, err := s.getOrgUsers(ctx, orgID, userIDs...)
+	if err != nil {
+		return nil, err
+	}
+	return &OnCall{
+		ScheduleUID: schedule.UID,
+		At:          at,
+		Users:       users,
+		Override:    override,
+	}, nil
+}
+
+func (s *OnCallService) GetShifts(ctx context.Context, orgID int64, uid string, from, to time.Time) ([]Shift, error) {
+	schedule, err := s.getSchedule(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.listOverrides(ctx, schedule, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return shiftsBetween(schedule, overrides, from, to), nil
+}
+
+func (s *OnCallService) OnCallAddresses(ctx context.Context, orgID int64, uid string) ([]string, error) {
+	onCall, err := s.GetOnCall(ctx, orgID, uid, s.now())
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(onCall.Users))
+	for _, u := range onCall.Users {
+		if u.Email != "" {
+			addresses = append(addresses, u.Email)
+		}
+	}
+	return addresses, nil
+}
+
+// checkMembers returns an error if any of the users is not a member of the organization.
+func (s *OnCallService) checkMembers(ctx context.Context, orgID int64, userIDs ...int64) error {
+	users, err := s.getOrgUsers(ctx, orgID, userIDs...)
+	if err != nil {
+		return err
+	}
+	members := make(map[int64]bool, len(users))
+	for _, u := range users {
+		members[u.ID] = true
+	}
+	for _, id := range userIDs {
+		if !members[id] {
+			return fmt.Errorf("%w: user %d is not a member of the organization", ErrInvalidSchedule, id)
+		}
+	}
+	return nil
+}
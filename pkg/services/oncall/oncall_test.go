@@ -0,0 +1,204 @@
+package oncall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestRotationShiftAt(t *testing.T) {
+	start := time.Date(2022, 11, 28, 9, 0, 0, 0, time.UTC)
+	r := Rotation{Name: "primary", Start: start, ShiftLength: "1d", UserIDs: []int64{1, 2, 3}}
+
+	_, ok := r.shiftAt(start.Add(-time.Minute))
+	assert.False(t, ok)
+
+	shift, ok := r.shiftAt(start)
+	require.True(t, ok)
+	assert.Equal(t, Shift{Rotation: "primary", UserID: 1, Start: start, End: start.Add(24 * time.Hour)}, shift)
+
+	shift, ok = r.shiftAt(start.Add(49 * time.Hour))
+	require.True(t, ok)
+	assert.Equal(t, int64(3), shift.UserID)
+	assert.Equal(t, start.Add(48*time.Hour), shift.Start)
+
+	shift, ok = r.shiftAt(start.Add(72 * time.Hour))
+	require.True(t, ok)
+	assert.Equal(t, int64(1), shift.UserID)
+}
+
+func TestOnCallAt(t *testing.T) {
+	start := time.Date(2022, 11, 28, 9, 0, 0, 0, time.UTC)
+	schedule := &Schedule{Rotations: []Rotation{
+		{Name: "primary", Start: start, ShiftLength: "1w", UserIDs: []int64{1, 2}},
+		{Name: "secondary", Start: start, ShiftLength: "1w", UserIDs: []int64{2, 1}},
+		{Name: "later", Start: start.Add(24 * time.Hour), ShiftLength: "1d", UserIDs: []int64{3}},
+	}}
+	overrides := []*Override{{UserID: 4, Start: start.Add(2 * time.Hour), End: start.Add(4 * time.Hour)}}
+
+	userIDs, override := onCallAt(schedule, overrides, start)
+	assert.Equal(t, []int64{1, 2}, userIDs)
+	assert.False(t, override)
+
+	userIDs, override = onCallAt(schedule, overrides, start.Add(3*time.Hour))
+	assert.Equal(t, []int64{4}, userIDs)
+	assert.True(t, override)
+
+	userIDs, _ = onCallAt(schedule, overrides, start.Add(4*time.Hour))
+	assert.Equal(t, []int64{1, 2}, userIDs)
+
+	userIDs, _ = onCallAt(schedule, overrides, start.Add(8*24*time.Hour))
+	assert.Equal(t, []int64{2, 1, 3}, userIDs)
+}
+
+func TestShiftsBetween(t *testing.T) {
+	start := time.Date(2022, 11, 28, 9, 0, 0, 0, time.UTC)
+	schedule := &Schedule{Rotations: []Rotation{
+		{Name: "primary", Start: start, ShiftLength: "1d", UserIDs: []int64{1, 2}},
+	}}
+	overrides := []*Override{{UserID: 3, Start: start.Add(30 * time.Hour), End: start.Add(32 * time.Hour)}}
+
+	shifts := shiftsBetween(schedule, overrides, start.Add(-time.Hour), start.Add(48*time.Hour))
+	require.Len(t, shifts, 3)
+	assert.Equal(t, int64(1), shifts[0].UserID)
+	assert.Equal(t, int64(2), shifts[1].UserID)
+	assert.Equal(t, Shift{UserID: 3, Start: start.Add(30 * time.Hour), End: start.Add(32 * time.Hour), Override: true}, shifts[2])
+}
+
+func TestCreateOrUpdateScheduleCommandValidate(t *testing.T) {
+	start := time.Date(2022, 11, 28, 9, 0, 0, 0, time.UTC)
+	valid := func() *CreateOrUpdateScheduleCommand {
+		return &CreateOrUpdateScheduleCommand{Name: " Ops ", Rotations: []Rotation{
+			{Start: start, ShiftLength: "1d", UserIDs: []int64{1}},
+		}}
+	}
+
+	cmd := valid()
+	require.NoError(t, cmd.validate())
+	assert.Equal(t, "Ops", cmd.Name)
+	assert.Equal(t, "Rotation 1", cmd.Rotations[0].Name)
+
+	for name, change := range map[string]func(cmd *CreateOrUpdateScheduleCommand){
+		"empty name":         func(cmd *CreateOrUpdateScheduleCommand) { cmd.Name = " " },
+		"invalid uid":        func(cmd *CreateOrUpdateScheduleCommand) { cmd.UID = "a/b" },
+		"no start":           func(cmd *CreateOrUpdateScheduleCommand) { cmd.Rotations[0].Start = time.Time{} },
+		"short shift":        func(cmd *CreateOrUpdateScheduleCommand) { cmd.Rotations[0].ShiftLength = "30m" },
+		"invalid shift":      func(cmd *CreateOrUpdateScheduleCommand) { cmd.Rotations[0].ShiftLength = "daily" },
+		"rotation w/o users": func(cmd *CreateOrUpdateScheduleCommand) { cmd.Rotations[0].UserIDs = nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := valid()
+			change(cmd)
+			assert.ErrorIs(t, cmd.validate(), ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestIntegrationOnCallSchedules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	sqlStore.Cfg.AutoAssignOrg = true
+	sqlStore.Cfg.AutoAssignOrgId = 1
+	ctx := context.Background()
+
+	createUser := func(login string) *user.User {
+		u, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: login, Email: login + "@example.com", Name: login})
+		require.NoError(t, err)
+		return u
+	}
+	alice := createUser("alice")
+	bob := createUser("bob")
+	carol := createUser("carol")
+	orgID := alice.OrgID
+
+	s := ProvideService(sqlStore, routing.NewRouteRegister())
+	start := time.Date(2022, 11, 28, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	s.now = func() time.Time { return now }
+
+	schedule, err := s.CreateSchedule(ctx, &CreateOrUpdateScheduleCommand{
+		OrgID: orgID,
+		UID:   "ops",
+		Name:  "Ops",
+		Rotations: []Rotation{
+			{Name: "primary", Start: start, ShiftLength: "1d", UserIDs: []int64{alice.ID, bob.ID}},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("creating a schedule with the same uid fails", func(t *testing.T) {
+		_, err := s.CreateSchedule(ctx, &CreateOrUpdateScheduleCommand{OrgID: orgID, UID: "ops", Name: "Other"})
+		assert.ErrorIs(t, err, ErrScheduleExists)
+	})
+
+	t.Run("rotations with users outside of the organization are rejected", func(t *testing.T) {
+		_, err := s.CreateSchedule(ctx, &CreateOrUpdateScheduleCommand{OrgID: orgID, Name: "Other", Rotations: []Rotation{
+			{Start: start, ShiftLength: "1d", UserIDs: []int64{alice.ID, 9999}},
+		}})
+		assert.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+
+	t.Run("get on call and addresses", func(t *testing.T) {
+		onCall, err := s.GetOnCall(ctx, orgID, "ops", start.Add(25*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, onCall.Users, 1)
+		assert.Equal(t, "bob", onCall.Users[0].Login)
+		assert.False(t, onCall.Override)
+
+		addresses, err := s.OnCallAddresses(ctx, orgID, "ops")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice@example.com"}, addresses)
+
+		_, err = s.OnCallAddresses(ctx, orgID, "missing")
+		assert.ErrorIs(t, err, ErrScheduleNotFound)
+	})
+
+	t.Run("overrides replace the rotations", func(t *testing.T) {
+		override, err := s.CreateOverride(ctx, &CreateOverrideCommand{
+			OrgID: orgID, ScheduleUID: "ops", UserID: carol.ID, Start: start, End: start.Add(2 * time.Hour), CreatedBy: alice.ID,
+		})
+		require.NoError(t, err)
+
+		addresses, err := s.OnCallAddresses(ctx, orgID, "ops")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"carol@example.com"}, addresses)
+
+		shifts, err := s.GetShifts(ctx, orgID, "ops", start, start.Add(48*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, shifts, 3)
+		assert.True(t, shifts[1].Override)
+
+		require.NoError(t, s.DeleteOverride(ctx, orgID, "ops", override.ID))
+		assert.ErrorIs(t, s.DeleteOverride(ctx, orgID, "ops", override.ID), ErrOverrideNotFound)
+
+		_, err = s.CreateOverride(ctx, &CreateOverrideCommand{
+			OrgID: orgID, ScheduleUID: "ops", UserID: 9999, Start: start, End: start.Add(time.Hour),
+		})
+		assert.ErrorIs(t, err, ErrInvalidOverride)
+	})
+
+	t.Run("update and delete schedule", func(t *testing.T) {
+		updated, err := s.UpdateSchedule(ctx, "ops", &CreateOrUpdateScheduleCommand{OrgID: orgID, Name: "Operations", Rotations: []Rotation{
+			{Name: "primary", Start: start, ShiftLength: "1d", UserIDs: []int64{carol.ID}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, schedule.ID, updated.ID)
+
+		addresses, err := s.OnCallAddresses(ctx, orgID, "ops")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"carol@example.com"}, addresses)
+
+		require.NoError(t, s.DeleteSchedule(ctx, orgID, "ops"))
+		_, err = s.GetSchedule(ctx, orgID, "ops")
+		assert.ErrorIs(t, err, ErrScheduleNotFound)
+	})
+}
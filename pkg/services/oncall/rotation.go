@@ -0,0 +1,82 @@
+package oncall
+
+import (
+	"sort"
+	"time"
+)
+
+// shiftAt returns the shift of the rotation at a time. It returns false before the rotation starts.
+func (r Rotation) shiftAt(at time.Time) (Shift, bool) {
+	length, err := r.shiftLength()
+	if err != nil || len(r.UserIDs) == 0 || at.Before(r.Start) {
+		return Shift{}, false
+	}
+	n := int64(at.Sub(r.Start) / length)
+	start := r.Start.Add(time.Duration(n) * length)
+	return Shift{
+		Rotation: r.Name,
+		UserID:   r.UserIDs[n%int64(len(r.UserIDs))],
+		Start:    start,
+		End:      start.Add(length),
+	}, true
+}
+
+// onCallAt returns the users on call at a time. Overrides replace the rotations of the schedule
+// while they last. It returns true if the users are on call because of overrides.
+func onCallAt(schedule *Schedule, overrides []*Override, at time.Time) ([]int64, bool) {
+	var userIDs []int64
+	for _, o := range overrides {
+		if !at.Before(o.Start) && at.Before(o.End) {
+			userIDs = appendUnique(userIDs, o.UserID)
+		}
+	}
+	if len(userIDs) > 0 {
+		return userIDs, true
+	}
+
+	for _, r := range schedule.Rotations {
+		if shift, ok := r.shiftAt(at); ok {
+			userIDs = appendUnique(userIDs, shift.UserID)
+		}
+	}
+	return userIDs, false
+}
+
+// shiftsBetween returns the shifts of the rotations and the overrides which overlap the period,
+// ordered by their start.
+func shiftsBetween(schedule *Schedule, overrides []*Override, from, to time.Time) []Shift {
+	var shifts []Shift
+	for _, r := range schedule.Rotations {
+		at := from
+		if at.Before(r.Start) {
+			at = r.Start
+		}
+		for at.Before(to) {
+			shift, ok := r.shiftAt(at)
+			if !ok {
+				break
+			}
+			shifts = append(shifts, shift)
+			at = shift.End
+		}
+	}
+	for _, o := range overrides {
+		if o.Start.Before(to) && o.End.After(from) {
+			shifts = append(shifts, Shift{UserID: o.UserID, Start: o.Start, End: o.End, Override: true})
+		}
+	}
+
+	sort.SliceStable(shifts, func(i, j int) bool {
+		return shifts[i].Start.Before(shifts[j].Start)
+	})
+	return shifts
+}
+
+func appendUnique(ids []int64, id int64) []int64 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,145 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *OnCallService) insertSchedule(ctx context.Context, schedule *Schedule) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", schedule.OrgID, schedule.UID).Exist(&Schedule{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrScheduleExists
+		}
+		_, err = sess.Insert(schedule)
+		return err
+	})
+}
+
+func (s *OnCallService) updateSchedule(ctx context.Context, schedule *Schedule) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ?", schedule.OrgID).ID(schedule.ID).Cols("name", "rotations", "updated").Update(schedule)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrScheduleNotFound
+		}
+		return nil
+	})
+}
+
+func (s *OnCallService) deleteSchedule(ctx context.Context, schedule *Schedule) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ?", schedule.OrgID).ID(schedule.ID).Delete(&Schedule{}); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM oncall_override WHERE org_id = ? AND schedule_id = ?", schedule.OrgID, schedule.ID)
+		return err
+	})
+}
+
+func (s *OnCallService) getSchedule(ctx context.Context, orgID int64, uid string) (*Schedule, error) {
+	schedule := &Schedule{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(schedule)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrScheduleNotFound
+		}
+		return nil
+	})
+	return schedule, err
+}
+
+func (s *OnCallService) listSchedules(ctx context.Context, orgID int64) ([]*Schedule, error) {
+	schedules := make([]*Schedule, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&schedules)
+	})
+	return schedules, err
+}
+
+// insertOverride adds an override to a schedule and deletes the overrides of the schedule which
+// ended before the retention.
+func (s *OnCallService) insertOverride(ctx context.Context, override *Override, retention time.Time) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM oncall_override WHERE org_id = ? AND schedule_id = ? AND end_time < ?",
+			override.OrgID, override.ScheduleID, retention); err != nil {
+			return err
+		}
+		_, err := sess.Insert(override)
+		return err
+	})
+}
+
+func (s *OnCallService) deleteOverride(ctx context.Context, schedule *Schedule, id int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND schedule_id = ?", schedule.OrgID, schedule.ID).ID(id).Delete(&Override{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrOverrideNotFound
+		}
+		return nil
+	})
+}
+
+// listOverrides returns the overrides of a schedule which overlap the period, or all overrides
+// if from is zero.
+func (s *OnCallService) listOverrides(ctx context.Context, schedule *Schedule, from, to time.Time) ([]*Override, error) {
+	overrides := make([]*Override, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ? AND schedule_id = ?", schedule.OrgID, schedule.ID)
+		if !from.IsZero() {
+			sess.And("end_time > ? AND start_time <= ?", from, to)
+		}
+		return sess.Asc("start_time").Find(&overrides)
+	})
+	return overrides, err
+}
+
+// getOrgUsers returns the users who are members of the organization, in the order of the ids.
+func (s *OnCallService) getOrgUsers(ctx context.Context, orgID int64, userIDs ...int64) ([]*OnCallUser, error) {
+	result := make([]*OnCallUser, 0, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	users := make([]*OnCallUser, 0, len(userIDs))
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		params := []interface{}{orgID}
+		for _, id := range userIDs {
+			params = append(params, id)
+		}
+		rawSQL := fmt.Sprintf(`SELECT u.id, u.login, u.name, u.email FROM org_user AS ou
+			INNER JOIN %s AS u ON u.id = ou.user_id
+			WHERE ou.org_id = ? AND ou.user_id IN (?%s)`,
+			s.store.GetDialect().Quote("user"), strings.Repeat(",?", len(userIDs)-1))
+		return sess.SQL(rawSQL, params...).Find(&users)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*OnCallUser, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	for _, id := range userIDs {
+		if u, ok := byID[id]; ok {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
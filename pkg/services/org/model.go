@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/models/roletype"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -50,7 +51,7 @@ const (
 type CreateOrgCommand struct {
 	Name string `json:"name" binding:"Required"`
 
-	// initial admin user for account
+	// initial admin user for account, the organization is created without members if it is not set
 	UserID int64 `json:"-" xorm:"user_id"`
 }
 
@@ -83,6 +84,10 @@ type SearchOrgsQuery struct {
 	Limit int
 	Page  int
 	IDs   []int64 `xorm:"ids"`
+	// Sort is the order of the organizations by name, unordered if not set.
+	Sort pagination.Sort
+	// After returns the organizations after the cursor instead of a page.
+	After *pagination.Cursor
 }
 
 type OrgDTO struct {
@@ -125,6 +130,8 @@ type AddOrgUserCommand struct {
 
 	// internal use: avoid adding service accounts to orgs via user routes
 	AllowAddingServiceAccount bool `json:"-"`
+	// Source of the change, one of the events.OrgUserSource values, defaults to manual.
+	Source string `json:"-"`
 }
 
 type UpdateOrgUserCommand struct {
@@ -132,6 +139,8 @@ type UpdateOrgUserCommand struct {
 
 	OrgID  int64 `json:"-"`
 	UserID int64 `json:"-"`
+	// Source of the change, one of the events.OrgUserSource values, defaults to manual.
+	Source string `json:"-"`
 }
 
 type OrgUserDTO struct {
@@ -155,6 +164,77 @@ type RemoveOrgUserCommand struct {
 	OrgID                    int64 `xorm:"org_id"`
 	ShouldDeleteOrphanedUser bool
 	UserWasDeleted           bool
+	// Source of the change, one of the events.OrgUserSource values, defaults to manual.
+	Source string
+	// Preview only sets the Inventory of the access of the user, without removing the user.
+	Preview bool
+	// RevokeOrphanedAccess revokes the tokens of the service accounts the removal leaves without
+	// any user or team with permissions on them, and deletes the organization tokens created by the
+	// user.
+	RevokeOrphanedAccess bool
+	// Inventory is the access of the user in the organization at the time of the removal.
+	Inventory *OrgUserAccessInventory
+}
+
+// OrgUserAccessInventory is the access a user has in an organization beyond their membership, which
+// could otherwise linger after the user is removed from the organization.
+type OrgUserAccessInventory struct {
+	// ServiceAccounts the user has permissions on.
+	ServiceAccounts []*OrgUserServiceAccount `json:"serviceAccounts"`
+	// OrgTokens are the organization tokens created by the user.
+	OrgTokens []*OrgUserToken `json:"orgTokens"`
+	// Dashboards are the dashboards and folders the user has permissions on, which are removed
+	// together with the user.
+	Dashboards []*OrgUserDashboard `json:"dashboards"`
+	// Revoked is whether the orphaned service account tokens and the organization tokens were revoked.
+	Revoked bool `json:"revoked"`
+}
+
+type OrgUserServiceAccount struct {
+	ID    int64  `json:"id" xorm:"id"`
+	Login string `json:"login" xorm:"login"`
+	// Orphaned is whether no other user or team has permissions on the service account.
+	Orphaned bool `json:"orphaned" xorm:"-"`
+	// Tokens are the tokens of the service account which are not revoked.
+	Tokens []*OrgUserToken `json:"tokens" xorm:"-"`
+}
+
+type OrgUserToken struct {
+	ID   int64  `json:"id" xorm:"id"`
+	Name string `json:"name" xorm:"name"`
+}
+
+type OrgUserDashboard struct {
+	UID      string `json:"uid" xorm:"uid"`
+	Title    string `json:"title" xorm:"title"`
+	IsFolder bool   `json:"isFolder" xorm:"is_folder"`
+}
+
+// Auth modules of the OrgUsersFilter matching several auth modules.
+const (
+	// AuthModuleLocal matches the users without an external auth module.
+	AuthModuleLocal = "local"
+	// AuthModuleOAuth matches the users of any OAuth provider.
+	AuthModuleOAuth = "oauth"
+)
+
+// OrgUserSortFields are the fields the users of an organization can be sorted by.
+var OrgUserSortFields = []string{"login", "email", "name", "role", "lastSeenAt"}
+
+// OrgUsersFilter narrows down the users of an organization. The zero value matches all users.
+type OrgUsersFilter struct {
+	// Roles matches the users with any of the roles in the organization.
+	Roles []RoleType
+	// IsDisabled matches the disabled or the enabled users, when set.
+	IsDisabled *bool
+	// AuthModule matches the users by the auth module they last logged in with, e.g. ldap or
+	// oauth_github, or AuthModuleLocal or AuthModuleOAuth.
+	AuthModule string
+	// LastSeenAfter and LastSeenBefore match the users last seen in the range, when set.
+	LastSeenAfter  time.Time
+	LastSeenBefore time.Time
+	// Sort is the order of the users by one of the OrgUserSortFields, by email and login if not set.
+	Sort pagination.Sort
 }
 
 type GetOrgUsersQuery struct {
@@ -162,6 +242,7 @@ type GetOrgUsersQuery struct {
 	OrgID  int64 `xorm:"org_id"`
 	Query  string
 	Limit  int
+	OrgUsersFilter
 	// Flag used to allow oss edition to query users without access control
 	DontEnforceAccessControl bool
 
@@ -173,6 +254,7 @@ type SearchOrgUsersQuery struct {
 	Query string
 	Page  int
 	Limit int
+	OrgUsersFilter
 
 	User *user.SignedInUser
 }
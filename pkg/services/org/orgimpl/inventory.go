@@ -0,0 +1,165 @@
+package orgimpl
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var serviceAccountScopePrefix = accesscontrol.Scope("serviceaccounts", "id", "")
+
+// getOrgUserAccessInventory returns the service accounts and dashboards the user has been granted
+// permissions on in the organization, and the organization tokens the user created.
+func (ss *sqlStore) getOrgUserAccessInventory(sess *db.Session, orgID, userID int64) (*org.OrgUserAccessInventory, error) {
+	inventory := &org.OrgUserAccessInventory{
+		ServiceAccounts: []*org.OrgUserServiceAccount{},
+		OrgTokens:       []*org.OrgUserToken{},
+		Dashboards:      []*org.OrgUserDashboard{},
+	}
+
+	var scopes []string
+	if err := sess.SQL(`SELECT DISTINCT permission.scope FROM permission
+		INNER JOIN role ON role.id = permission.role_id
+		WHERE role.org_id = ? AND role.name = ?`, orgID, accesscontrol.ManagedUserRoleName(userID)).Find(&scopes); err != nil {
+		return nil, err
+	}
+	serviceAccountIDs := make([]int64, 0)
+	dashboardUIDs := make([]string, 0)
+	for _, scope := range scopes {
+		switch {
+		case strings.HasPrefix(scope, serviceAccountScopePrefix):
+			if id, err := strconv.ParseInt(strings.TrimPrefix(scope, serviceAccountScopePrefix), 10, 64); err == nil {
+				serviceAccountIDs = append(serviceAccountIDs, id)
+			}
+		case strings.HasPrefix(scope, dashboards.ScopeDashboardsPrefix):
+			dashboardUIDs = append(dashboardUIDs, strings.TrimPrefix(scope, dashboards.ScopeDashboardsPrefix))
+		case strings.HasPrefix(scope, dashboards.ScopeFoldersPrefix):
+			dashboardUIDs = append(dashboardUIDs, strings.TrimPrefix(scope, dashboards.ScopeFoldersPrefix))
+		}
+	}
+
+	if err := ss.addServiceAccounts(sess, inventory, orgID, userID, serviceAccountIDs); err != nil {
+		return nil, err
+	}
+
+	if err := sess.Table("org_token").Cols("id", "name").Where("org_id = ? AND created_by = ?", orgID, userID).
+		Asc("name").Find(&inventory.OrgTokens); err != nil {
+		return nil, err
+	}
+
+	// the legacy permissions and the permissions managed with role based access control
+	if err := sess.SQL(`SELECT DISTINCT dashboard.uid, dashboard.title, dashboard.is_folder FROM dashboard_acl
+		INNER JOIN dashboard ON dashboard.id = dashboard_acl.dashboard_id
+		WHERE dashboard_acl.org_id = ? AND dashboard_acl.user_id = ?`, orgID, userID).Find(&inventory.Dashboards); err != nil {
+		return nil, err
+	}
+	if len(dashboardUIDs) > 0 {
+		var managed []*org.OrgUserDashboard
+		if err := sess.Table("dashboard").Cols("uid", "title", "is_folder").Where("org_id = ?", orgID).
+			In("uid", dashboardUIDs).Find(&managed); err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(inventory.Dashboards))
+		for _, d := range inventory.Dashboards {
+			seen[d.UID] = true
+		}
+		for _, d := range managed {
+			if !seen[d.UID] {
+				inventory.Dashboards = append(inventory.Dashboards, d)
+			}
+		}
+	}
+	sort.Slice(inventory.Dashboards, func(i, j int) bool {
+		return inventory.Dashboards[i].Title < inventory.Dashboards[j].Title
+	})
+
+	return inventory, nil
+}
+
+// addServiceAccounts adds the service accounts to the inventory, with their tokens and whether any
+// other user or team has permissions on them.
+func (ss *sqlStore) addServiceAccounts(sess *db.Session, inventory *org.OrgUserAccessInventory, orgID, userID int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := sess.Table("user").Cols("id", "login").Where("org_id = ? AND is_service_account = ?", orgID, ss.dialect.BooleanStr(true)).
+		In("id", ids).Asc("login").Find(&inventory.ServiceAccounts); err != nil {
+		return err
+	}
+	if len(inventory.ServiceAccounts) == 0 {
+		return nil
+	}
+
+	scopes := make([]interface{}, 0, len(inventory.ServiceAccounts))
+	byScope := make(map[string]*org.OrgUserServiceAccount, len(inventory.ServiceAccounts))
+	byID := make(map[int64]*org.OrgUserServiceAccount, len(inventory.ServiceAccounts))
+	for _, sa := range inventory.ServiceAccounts {
+		scope := serviceAccountScopePrefix + strconv.FormatInt(sa.ID, 10)
+		scopes = append(scopes, scope)
+		byScope[scope] = sa
+		byID[sa.ID] = sa
+		sa.Orphaned = true
+		sa.Tokens = []*org.OrgUserToken{}
+	}
+
+	var shared []string
+	params := append([]interface{}{orgID, accesscontrol.ManagedUserRoleName(userID)}, scopes...)
+	if err := sess.SQL(`SELECT DISTINCT permission.scope FROM permission
+		INNER JOIN role ON role.id = permission.role_id
+		WHERE role.org_id = ? AND role.name <> ? AND permission.scope IN (?`+strings.Repeat(",?", len(scopes)-1)+`)`,
+		params...).Find(&shared); err != nil {
+		return err
+	}
+	for _, scope := range shared {
+		byScope[scope].Orphaned = false
+	}
+
+	var tokens []struct {
+		ID               int64  `xorm:"id"`
+		Name             string `xorm:"name"`
+		ServiceAccountID int64  `xorm:"service_account_id"`
+	}
+	if err := sess.Table("api_key").Cols("id", "name", "service_account_id").
+		Where("org_id = ? AND (is_revoked = ? OR is_revoked IS NULL)", orgID, ss.dialect.BooleanStr(false)).
+		In("service_account_id", ids).Asc("name").Find(&tokens); err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if sa, ok := byID[token.ServiceAccountID]; ok {
+			sa.Tokens = append(sa.Tokens, &org.OrgUserToken{ID: token.ID, Name: token.Name})
+		}
+	}
+	return nil
+}
+
+// revokeOrphanedAccess revokes the tokens of the orphaned service accounts of the inventory and
+// deletes the organization tokens created by the user.
+func (ss *sqlStore) revokeOrphanedAccess(sess *db.Session, inventory *org.OrgUserAccessInventory, orgID, userID int64) error {
+	orphaned := make([]interface{}, 0)
+	for _, sa := range inventory.ServiceAccounts {
+		if sa.Orphaned {
+			orphaned = append(orphaned, sa.ID)
+		}
+	}
+	if len(orphaned) > 0 {
+		params := append([]interface{}{
+			"UPDATE api_key SET is_revoked = ? WHERE org_id = ? AND service_account_id IN (?" + strings.Repeat(",?", len(orphaned)-1) + ")",
+			ss.dialect.BooleanStr(true), orgID,
+		}, orphaned...)
+		if _, err := sess.Exec(params...); err != nil {
+			return err
+		}
+	}
+
+	if _, err := sess.Exec("DELETE FROM org_token WHERE org_id = ? AND created_by = ?", orgID, userID); err != nil {
+		return err
+	}
+	inventory.Revoked = true
+	return nil
+}
@@ -3,33 +3,48 @@ package orgimpl
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// orgAdminEvaluator matches the permissions of an organization admin over the members of the
+// organization, which are enough to restore the role of any other admin.
+var orgAdminEvaluator = accesscontrol.EvalAll(
+	accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersAdd, accesscontrol.ScopeUsersAll),
+	accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersWrite, accesscontrol.ScopeUsersAll),
+	accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRemove, accesscontrol.ScopeUsersAll),
+)
+
 type Service struct {
-	store store
-	cfg   *setting.Cfg
-	log   log.Logger
+	store     store
+	cfg       *setting.Cfg
+	log       log.Logger
+	acService accesscontrol.Service
 }
 
-func ProvideService(db db.DB, cfg *setting.Cfg, quotaService quota.Service) (org.Service, error) {
+func ProvideService(db db.DB, cfg *setting.Cfg, quotaService quota.Service, acService accesscontrol.Service) (org.Service, error) {
 	log := log.New("org service")
 	s := &Service{
 		store: &sqlStore{
-			db:      db,
-			dialect: db.GetDialect(),
-			log:     log,
-			cfg:     cfg,
+			db:                  db,
+			dialect:             db.GetDialect(),
+			log:                 log,
+			cfg:                 cfg,
+			adminsByPermissions: !accesscontrol.IsDisabled(cfg),
 		},
-		cfg: cfg,
-		log: log,
+		cfg:       cfg,
+		log:       log,
+		acService: acService,
 	}
 
 	defaultLimits, err := readQuotaConfig(cfg)
@@ -181,12 +196,101 @@ func (s *Service) AddOrgUser(ctx context.Context, cmd *org.AddOrgUserCommand) er
 
 // TODO: refactor service to call store CRUD method
 func (s *Service) UpdateOrgUser(ctx context.Context, cmd *org.UpdateOrgUserCommand) error {
-	return s.store.UpdateOrgUser(ctx, cmd)
+	if accesscontrol.IsDisabled(s.cfg) {
+		return s.store.UpdateOrgUser(ctx, cmd)
+	}
+	// the check and the change are made in one transaction, so that concurrent changes of the
+	// last admins cannot all pass the check
+	return s.store.WithOrgMembersLocked(ctx, cmd.OrgID, func(ctx context.Context) error {
+		if err := s.validateAdminLeft(ctx, cmd.OrgID, cmd.UserID, cmd.Role); err != nil {
+			return err
+		}
+		return s.store.UpdateOrgUser(ctx, cmd)
+	})
 }
 
 // TODO: refactor service to call store CRUD method
 func (s *Service) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCommand) error {
-	return s.store.RemoveOrgUser(ctx, cmd)
+	if accesscontrol.IsDisabled(s.cfg) {
+		return s.store.RemoveOrgUser(ctx, cmd)
+	}
+	return s.store.WithOrgMembersLocked(ctx, cmd.OrgID, func(ctx context.Context) error {
+		if err := s.validateAdminLeft(ctx, cmd.OrgID, cmd.UserID, ""); err != nil {
+			return err
+		}
+		return s.store.RemoveOrgUser(ctx, cmd)
+	})
+}
+
+// validateAdminLeft returns models.ErrLastOrgAdmin if the organization would lose its last member
+// holding the permissions of an admin once the user has the role, or is removed if role is empty.
+// The permissions can come from the basic role of the members, from custom roles assigned to them or
+// to their teams, and from being a Grafana Admin. Without role based access control, the store
+// validates that the organization keeps a member with the Admin role instead.
+func (s *Service) validateAdminLeft(ctx context.Context, orgID, userID int64, role org.RoleType) error {
+	if accesscontrol.IsDisabled(s.cfg) || role == org.RoleAdmin {
+		return nil
+	}
+
+	candidates, err := s.store.GetAdminCandidates(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	var target *adminCandidate
+	others := make([]*adminCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.UserID == userID {
+			target = c
+		} else {
+			others = append(others, c)
+		}
+	}
+	// only the members holding the permissions can leave the organization without an admin
+	if target == nil {
+		return nil
+	}
+	if isAdmin, err := s.holdsAdminPermissions(ctx, orgID, target); err != nil || !isAdmin {
+		return err
+	}
+	if role != "" {
+		changed := *target
+		changed.Role = role
+		isAdmin, err := s.holdsAdminPermissions(ctx, orgID, &changed)
+		// the permissions of the new role must not be cached before the role is changed
+		s.acService.ClearUserPermissionCache(&user.SignedInUser{UserID: userID, OrgID: orgID})
+		if err != nil || isAdmin {
+			return err
+		}
+	}
+
+	// the members with the Admin role don't need their permissions to be fetched
+	sort.SliceStable(others, func(i, j int) bool {
+		return others[i].Role == org.RoleAdmin && others[j].Role != org.RoleAdmin
+	})
+	for _, c := range others {
+		if isAdmin, err := s.holdsAdminPermissions(ctx, orgID, c); err != nil || isAdmin {
+			return err
+		}
+	}
+	return models.ErrLastOrgAdmin
+}
+
+func (s *Service) holdsAdminPermissions(ctx context.Context, orgID int64, c *adminCandidate) (bool, error) {
+	if c.Role == org.RoleAdmin {
+		return true, nil
+	}
+	permissions, err := s.acService.GetUserPermissions(ctx, &user.SignedInUser{
+		UserID:         c.UserID,
+		OrgID:          orgID,
+		OrgRole:        c.Role,
+		IsGrafanaAdmin: c.IsAdmin,
+		Teams:          c.Teams,
+	}, accesscontrol.Options{ReloadCache: true})
+	if err != nil {
+		return false, err
+	}
+	return orgAdminEvaluator.Evaluate(accesscontrol.GroupScopesByAction(permissions)), nil
 }
 
 // TODO: refactor service to call store CRUD method
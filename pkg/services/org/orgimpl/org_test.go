@@ -4,9 +4,14 @@ import (
 	"context"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -54,6 +59,93 @@ func TestOrgService(t *testing.T) {
 	})
 }
 
+func TestValidateAdminLeft(t *testing.T) {
+	ctx := context.Background()
+	adminPermissions := []accesscontrol.Permission{
+		{Action: accesscontrol.ActionOrgUsersAdd, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionOrgUsersWrite, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionOrgUsersRemove, Scope: accesscontrol.ScopeUsersAll},
+	}
+	// user 2 holds the permissions of an admin through a custom role, user 3 has no roles
+	acService := &fakeACService{permissions: map[int64][]accesscontrol.Permission{2: adminPermissions}}
+
+	tests := []struct {
+		desc       string
+		candidates []*adminCandidate
+		userID     int64
+		role       org.RoleType
+		expected   error
+	}{
+		{
+			desc:       "demoting the last Admin when a member holds the permissions through a custom role",
+			candidates: []*adminCandidate{{UserID: 1, Role: org.RoleAdmin}, {UserID: 2, Role: org.RoleEditor}},
+			userID:     1,
+			role:       org.RoleViewer,
+		},
+		{
+			desc:       "demoting the last Admin",
+			candidates: []*adminCandidate{{UserID: 1, Role: org.RoleAdmin}, {UserID: 3, Role: org.RoleEditor}},
+			userID:     1,
+			role:       org.RoleViewer,
+			expected:   models.ErrLastOrgAdmin,
+		},
+		{
+			desc:       "removing the last Admin",
+			candidates: []*adminCandidate{{UserID: 1, Role: org.RoleAdmin}, {UserID: 3, Role: org.RoleEditor}},
+			userID:     1,
+			expected:   models.ErrLastOrgAdmin,
+		},
+		{
+			desc:       "removing the last holder of the permissions through a custom role",
+			candidates: []*adminCandidate{{UserID: 2, Role: org.RoleViewer}, {UserID: 3, Role: org.RoleEditor}},
+			userID:     2,
+			expected:   models.ErrLastOrgAdmin,
+		},
+		{
+			desc:       "changing the role of the last holder of the permissions through a custom role",
+			candidates: []*adminCandidate{{UserID: 2, Role: org.RoleViewer}},
+			userID:     2,
+			role:       org.RoleEditor,
+		},
+		{
+			desc:       "removing a member without the permissions",
+			candidates: []*adminCandidate{{UserID: 1, Role: org.RoleAdmin}, {UserID: 3, Role: org.RoleEditor}},
+			userID:     3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			orgService := Service{
+				store:     &FakeOrgStore{ExpectedAdminCandidates: tc.candidates},
+				cfg:       setting.NewCfg(),
+				acService: acService,
+			}
+			err := orgService.validateAdminLeft(ctx, 1, tc.userID, tc.role)
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+
+	t.Run("should not validate the permissions without role based access control", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		orgService := Service{
+			store:     &FakeOrgStore{ExpectedAdminCandidates: []*adminCandidate{{UserID: 1, Role: org.RoleAdmin}}},
+			cfg:       cfg,
+			acService: acService,
+		}
+		assert.NoError(t, orgService.validateAdminLeft(ctx, 1, 1, ""))
+	})
+}
+
+type fakeACService struct {
+	actest.FakeService
+	permissions map[int64][]accesscontrol.Permission
+}
+
+func (f *fakeACService) GetUserPermissions(ctx context.Context, user *user.SignedInUser, options accesscontrol.Options) ([]accesscontrol.Permission, error) {
+	return f.permissions[user.UserID], nil
+}
+
 type FakeOrgStore struct {
 	ExpectedOrg                       *org.Org
 	ExpectedOrgID                     int64
@@ -63,6 +155,7 @@ type FakeOrgStore struct {
 	ExpectedOrgs                      []*org.OrgDTO
 	ExpectedOrgUsers                  []*org.OrgUserDTO
 	ExpectedSearchOrgUsersQueryResult *org.SearchOrgUsersQueryResult
+	ExpectedAdminCandidates           []*adminCandidate
 }
 
 func newOrgStoreFake() *FakeOrgStore {
@@ -137,6 +230,14 @@ func (f *FakeOrgStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUser
 	return f.ExpectedError
 }
 
+func (f *FakeOrgStore) GetAdminCandidates(ctx context.Context, orgID int64) ([]*adminCandidate, error) {
+	return f.ExpectedAdminCandidates, f.ExpectedError
+}
+
+func (f *FakeOrgStore) WithOrgMembersLocked(ctx context.Context, orgID int64, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func (f *FakeOrgStore) Count(ctx context.Context, _ *quota.ScopeParameters) (*quota.Map, error) {
 	return nil, nil
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -24,6 +25,10 @@ import (
 
 const MainOrgName = "Main Org."
 
+// auditLogger logs the changes to the members of the organizations, so that they can be reconciled
+// with the entitlements of the users.
+var auditLogger = log.New("org.audit")
+
 type store interface {
 	Get(context.Context, int64) (*org.Org, error)
 	Insert(context.Context, *org.Org) (int64, error)
@@ -44,6 +49,10 @@ type store interface {
 	GetByName(context.Context, *org.GetOrgByNameQuery) (*org.Org, error)
 	SearchOrgUsers(context.Context, *org.SearchOrgUsersQuery) (*org.SearchOrgUsersQueryResult, error)
 	RemoveOrgUser(context.Context, *org.RemoveOrgUserCommand) error
+	GetAdminCandidates(ctx context.Context, orgID int64) ([]*adminCandidate, error)
+	// WithOrgMembersLocked calls fn in a transaction which other changes of the members of the
+	// organization made through it wait for.
+	WithOrgMembersLocked(ctx context.Context, orgID int64, fn func(ctx context.Context) error) error
 
 	Count(context.Context, *quota.ScopeParameters) (*quota.Map, error)
 }
@@ -54,6 +63,17 @@ type sqlStore struct {
 	//TODO: moved to service
 	log log.Logger
 	cfg *setting.Cfg
+	// adminsByPermissions is set when the service validates that an organization keeps an admin from
+	// the permissions of its members, rather than the store from their role.
+	adminsByPermissions bool
+}
+
+// adminCandidate is a member of an organization who may hold the permissions of an admin.
+type adminCandidate struct {
+	UserID  int64        `xorm:"user_id"`
+	Role    org.RoleType `xorm:"role"`
+	IsAdmin bool         `xorm:"is_admin"`
+	Teams   []int64      `xorm:"-"`
 }
 
 func (ss *sqlStore) Get(ctx context.Context, orgID int64) (*org.Org, error) {
@@ -102,16 +122,27 @@ func (ss *sqlStore) Insert(ctx context.Context, org *org.Org) (int64, error) {
 
 func (ss *sqlStore) InsertOrgUser(ctx context.Context, cmd *org.OrgUser) (int64, error) {
 	var orgID int64
+	var added *events.OrgUserAdded
 	var err error
-	err = ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+	err = ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		if orgID, err = sess.Insert(cmd); err != nil {
 			return err
 		}
+
+		added = &events.OrgUserAdded{
+			Timestamp: cmd.Created,
+			OrgID:     cmd.OrgID,
+			UserID:    cmd.UserID,
+			Role:      string(cmd.Role),
+			Source:    events.OrgUserSourceManual,
+		}
+		sess.PublishAfterCommit(added)
 		return nil
 	})
 	if err != nil {
 		return 0, err
 	}
+	auditOrgUserEvent(added)
 	return orgID, nil
 }
 
@@ -230,6 +261,19 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM alert WHERE org_id = ?",
 			"DELETE FROM annotation WHERE org_id = ?",
 			"DELETE FROM kv_store WHERE org_id = ?",
+			"DELETE FROM user_notification WHERE org_id = ?",
+			"DELETE FROM dashboard_subscription WHERE org_id = ?",
+			"DELETE FROM dashboard_catalog_entry WHERE org_id = ?",
+			"DELETE FROM dashboard_catalog_subscription WHERE org_id = ?",
+			"DELETE FROM resource_label WHERE org_id = ?",
+			"DELETE FROM webhook WHERE org_id = ?",
+			"DELETE FROM webhook_delivery WHERE org_id = ?",
+			"DELETE FROM data_source_usage WHERE org_id = ?",
+			"DELETE FROM trace_annotation_job WHERE org_id = ?",
+			"DELETE FROM log_annotation_line WHERE org_id = ?",
+			"DELETE FROM log_annotation_job WHERE org_id = ?",
+			"DELETE FROM dashboard_variable_constraint WHERE org_id = ?",
+			"DELETE FROM dashboard_uid_reservation WHERE org_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -285,8 +329,23 @@ func (ss *sqlStore) Search(ctx context.Context, query *org.SearchOrgsQuery) ([]*
 			sess.In("id", query.IDs)
 		}
 
+		if query.Sort.Field != "" {
+			if query.Sort.Field != "name" {
+				return pagination.ErrInvalidSort
+			}
+			sess.OrderBy(pagination.OrderBy(query.Sort, "name", "id"))
+			if query.After != nil {
+				where, params := pagination.Where(query.Sort, query.After, "name", "id")
+				sess.Where(where, params...)
+			}
+		}
+
 		if query.Limit > 0 {
-			sess.Limit(query.Limit, query.Limit*query.Page)
+			offset := query.Limit * query.Page
+			if query.After != nil {
+				offset = 0
+			}
+			sess.Limit(query.Limit, offset)
 		}
 
 		sess.Cols("id", "name")
@@ -299,13 +358,15 @@ func (ss *sqlStore) Search(ctx context.Context, query *org.SearchOrgsQuery) ([]*
 	return result, nil
 }
 
-// CreateWithMember creates an organization with a certain name and a certain user as member.
+// CreateWithMember creates an organization with a certain name and a certain user as member, or
+// without members if no user is set.
 func (ss *sqlStore) CreateWithMember(ctx context.Context, cmd *org.CreateOrgCommand) (*org.Org, error) {
 	orga := org.Org{
 		Name:    cmd.Name,
 		Created: time.Now(),
 		Updated: time.Now(),
 	}
+	var added *events.OrgUserAdded
 	if err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		if isNameTaken, err := isOrgNameTaken(cmd.Name, 0, sess); err != nil {
 			return err
@@ -317,6 +378,18 @@ func (ss *sqlStore) CreateWithMember(ctx context.Context, cmd *org.CreateOrgComm
 			return err
 		}
 
+		sess.PublishAfterCommit(&events.OrgCreated{
+			Timestamp: orga.Created,
+			Id:        orga.ID,
+			Name:      orga.Name,
+		})
+
+		// organizations created on the login of a user are created without a member, the user is
+		// added with the role they are mapped to
+		if cmd.UserID == 0 {
+			return nil
+		}
+
 		user := org.OrgUser{
 			OrgID:   orga.ID,
 			UserID:  cmd.UserID,
@@ -327,21 +400,28 @@ func (ss *sqlStore) CreateWithMember(ctx context.Context, cmd *org.CreateOrgComm
 
 		_, err := sess.Insert(&user)
 
-		sess.PublishAfterCommit(&events.OrgCreated{
-			Timestamp: orga.Created,
-			Id:        orga.ID,
-			Name:      orga.Name,
-		})
+		added = &events.OrgUserAdded{
+			Timestamp: user.Created,
+			OrgID:     user.OrgID,
+			UserID:    user.UserID,
+			Role:      string(user.Role),
+			Source:    events.OrgUserSourceManual,
+		}
+		sess.PublishAfterCommit(added)
 
 		return err
 	}); err != nil {
 		return &orga, err
 	}
+	if added != nil {
+		auditOrgUserEvent(added)
+	}
 	return &orga, nil
 }
 
 func (ss *sqlStore) AddOrgUser(ctx context.Context, cmd *org.AddOrgUserCommand) error {
-	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	var added *events.OrgUserAdded
+	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		// check if user exists
 		var usr user.User
 		session := sess.ID(cmd.UserID)
@@ -380,6 +460,15 @@ func (ss *sqlStore) AddOrgUser(ctx context.Context, cmd *org.AddOrgUserCommand)
 			return err
 		}
 
+		added = &events.OrgUserAdded{
+			Timestamp: entity.Created,
+			OrgID:     entity.OrgID,
+			UserID:    entity.UserID,
+			Role:      string(entity.Role),
+			Source:    orgUserSource(cmd.Source),
+		}
+		sess.PublishAfterCommit(added)
+
 		var userOrgs []*org.UserOrgDTO
 		sess.Table("org_user")
 		sess.Join("INNER", "org", "org_user.org_id=org.id")
@@ -397,6 +486,11 @@ func (ss *sqlStore) AddOrgUser(ctx context.Context, cmd *org.AddOrgUserCommand)
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	auditOrgUserEvent(added)
+	return nil
 }
 
 func (ss *sqlStore) Count(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
@@ -476,7 +570,8 @@ func setUsingOrgInTransaction(sess *db.Session, userID int64, orgID int64) error
 }
 
 func (ss *sqlStore) UpdateOrgUser(ctx context.Context, cmd *org.UpdateOrgUserCommand) error {
-	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	var changed *events.OrgUserRoleChanged
+	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var orgUser org.OrgUser
 		exists, err := sess.Where("org_id=? AND user_id=?", cmd.OrgID, cmd.UserID).Get(&orgUser)
 		if err != nil {
@@ -487,6 +582,7 @@ func (ss *sqlStore) UpdateOrgUser(ctx context.Context, cmd *org.UpdateOrgUserCom
 			return models.ErrOrgUserNotFound
 		}
 
+		previousRole := orgUser.Role
 		orgUser.Role = cmd.Role
 		orgUser.Updated = time.Now()
 		_, err = sess.ID(orgUser.ID).Update(&orgUser)
@@ -494,8 +590,97 @@ func (ss *sqlStore) UpdateOrgUser(ctx context.Context, cmd *org.UpdateOrgUserCom
 			return err
 		}
 
-		return validateOneAdminLeftInOrg(cmd.OrgID, sess)
+		if !ss.adminsByPermissions {
+			if err := validateOneAdminLeftInOrg(cmd.OrgID, sess); err != nil {
+				return err
+			}
+		}
+
+		if previousRole != cmd.Role {
+			changed = &events.OrgUserRoleChanged{
+				Timestamp:    orgUser.Updated,
+				OrgID:        cmd.OrgID,
+				UserID:       cmd.UserID,
+				Role:         string(cmd.Role),
+				PreviousRole: string(previousRole),
+				Source:       orgUserSource(cmd.Source),
+			}
+			sess.PublishAfterCommit(changed)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if changed != nil {
+		auditOrgUserEvent(changed)
+	}
+	return nil
+}
+
+func (ss *sqlStore) WithOrgMembersLocked(ctx context.Context, orgID int64, fn func(ctx context.Context) error) error {
+	return ss.db.InTransaction(ctx, func(ctx context.Context) error {
+		// writing the org row holds a lock on it until the transaction ends, on every database
+		if err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE org SET version = version + 1 WHERE id = ?", orgID)
+			return err
+		}); err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
+}
+
+// GetAdminCandidates returns the members of the organization who have the Admin role, are Grafana
+// Admins or are assigned roles directly, through their teams or through their basic role, along with
+// their teams. The other members only have the permissions of their basic role.
+func (ss *sqlStore) GetAdminCandidates(ctx context.Context, orgID int64) ([]*adminCandidate, error) {
+	candidates := make([]*adminCandidate, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := `SELECT org_user.user_id, org_user.role, u.is_admin
+			FROM org_user
+			INNER JOIN ` + ss.dialect.Quote("user") + ` AS u ON u.id = org_user.user_id
+			WHERE org_user.org_id = ? AND u.is_service_account = ? AND (
+				org_user.role = ? OR u.is_admin = ?
+				OR EXISTS (SELECT 1 FROM user_role WHERE user_role.user_id = org_user.user_id AND user_role.org_id IN (?, ?))
+				OR EXISTS (SELECT 1 FROM team_member INNER JOIN team_role ON team_role.team_id = team_member.team_id
+					WHERE team_member.user_id = org_user.user_id AND team_member.org_id = ? AND team_role.org_id = ?)
+				OR org_user.role IN (SELECT builtin_role.role FROM builtin_role WHERE builtin_role.org_id IN (?, ?))
+			)`
+		params := []interface{}{
+			orgID, ss.dialect.BooleanStr(false),
+			org.RoleAdmin, ss.dialect.BooleanStr(true),
+			orgID, accesscontrol.GlobalOrgID,
+			orgID, orgID,
+			orgID, accesscontrol.GlobalOrgID,
+		}
+		if err := sess.SQL(rawSQL, params...).Find(&candidates); err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+
+		byUserID := make(map[int64]*adminCandidate, len(candidates))
+		userIDs := make([]int64, 0, len(candidates))
+		for _, c := range candidates {
+			byUserID[c.UserID] = c
+			userIDs = append(userIDs, c.UserID)
+		}
+		var memberships []struct {
+			UserID int64 `xorm:"user_id"`
+			TeamID int64 `xorm:"team_id"`
+		}
+		if err := sess.Table("team_member").Cols("user_id", "team_id").
+			Where("org_id = ?", orgID).In("user_id", userIDs).Find(&memberships); err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			byUserID[m.UserID].Teams = append(byUserID[m.UserID].Teams, m.TeamID)
+		}
+		return nil
 	})
+	return candidates, err
 }
 
 // validate that there is an org admin user left
@@ -551,6 +736,13 @@ func (ss *sqlStore) GetOrgUsers(ctx context.Context, query *org.GetOrgUsersQuery
 			whereParams = append(whereParams, queryWithWildcards, queryWithWildcards, queryWithWildcards)
 		}
 
+		filterConditions, filterParams := ss.orgUsersFilterWhere(query.OrgUsersFilter)
+		whereConditions = append(whereConditions, filterConditions...)
+		whereParams = append(whereParams, filterParams...)
+		if query.AuthModule != "" {
+			sess.Join("LEFT", "user_auth", ss.latestUserAuthJoinCondition())
+		}
+
 		if len(whereConditions) > 0 {
 			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
 		}
@@ -571,7 +763,15 @@ func (ss *sqlStore) GetOrgUsers(ctx context.Context, query *org.GetOrgUsersQuery
 			"user.updated",
 			"user.is_disabled",
 		)
-		sess.Asc("user.email", "user.login")
+		orderBy, err := ss.orgUsersOrderBy(query.Sort)
+		if err != nil {
+			return err
+		}
+		if orderBy == "" {
+			sess.Asc("user.email", "user.login")
+		} else {
+			sess.OrderBy(orderBy)
+		}
 
 		if err := sess.Find(&result); err != nil {
 			return err
@@ -639,6 +839,13 @@ func (ss *sqlStore) SearchOrgUsers(ctx context.Context, query *org.SearchOrgUser
 			whereParams = append(whereParams, queryWithWildcards, queryWithWildcards, queryWithWildcards)
 		}
 
+		filterConditions, filterParams := ss.orgUsersFilterWhere(query.OrgUsersFilter)
+		whereConditions = append(whereConditions, filterConditions...)
+		whereParams = append(whereParams, filterParams...)
+		if query.AuthModule != "" {
+			sess.Join("LEFT", "user_auth", ss.latestUserAuthJoinCondition())
+		}
+
 		if len(whereConditions) > 0 {
 			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
 		}
@@ -656,8 +863,17 @@ func (ss *sqlStore) SearchOrgUsers(ctx context.Context, query *org.SearchOrgUser
 			"user.login",
 			"org_user.role",
 			"user.last_seen_at",
+			"user.is_disabled",
 		)
-		sess.Asc("user.email", "user.login")
+		orderBy, err := ss.orgUsersOrderBy(query.Sort)
+		if err != nil {
+			return err
+		}
+		if orderBy == "" {
+			sess.Asc("user.email", "user.login")
+		} else {
+			sess.OrderBy(orderBy)
+		}
 
 		if err := sess.Find(&result.OrgUsers); err != nil {
 			return err
@@ -667,6 +883,9 @@ func (ss *sqlStore) SearchOrgUsers(ctx context.Context, query *org.SearchOrgUser
 		orgUser := org.OrgUser{}
 		countSess := dbSession.Table("org_user").
 			Join("INNER", ss.dialect.Quote("user"), fmt.Sprintf("org_user.user_id=%s.id", ss.dialect.Quote("user")))
+		if query.AuthModule != "" {
+			countSess.Join("LEFT", "user_auth", ss.latestUserAuthJoinCondition())
+		}
 
 		if len(whereConditions) > 0 {
 			countSess.Where(strings.Join(whereConditions, " AND "), whereParams...)
@@ -690,6 +909,74 @@ func (ss *sqlStore) SearchOrgUsers(ctx context.Context, query *org.SearchOrgUser
 	return &result, nil
 }
 
+// orgUsersFilterWhere returns the conditions of the filter on the joined org_user, user and, when
+// filtering by auth module, user_auth tables.
+func (ss *sqlStore) orgUsersFilterWhere(filter org.OrgUsersFilter) ([]string, []interface{}) {
+	var conditions []string
+	var params []interface{}
+	userTable := ss.dialect.Quote("user")
+
+	if len(filter.Roles) > 0 {
+		conditions = append(conditions, "org_user.role IN (?"+strings.Repeat(",?", len(filter.Roles)-1)+")")
+		for _, role := range filter.Roles {
+			params = append(params, role)
+		}
+	}
+
+	if filter.IsDisabled != nil {
+		conditions = append(conditions, userTable+".is_disabled = ?")
+		params = append(params, *filter.IsDisabled)
+	}
+
+	switch filter.AuthModule {
+	case "":
+	case org.AuthModuleLocal:
+		conditions = append(conditions, "user_auth.auth_module IS NULL")
+	case org.AuthModuleOAuth:
+		conditions = append(conditions, "user_auth.auth_module "+ss.dialect.LikeStr()+" ?")
+		params = append(params, "oauth_%")
+	default:
+		conditions = append(conditions, "user_auth.auth_module = ?")
+		params = append(params, filter.AuthModule)
+	}
+
+	if !filter.LastSeenAfter.IsZero() {
+		conditions = append(conditions, userTable+".last_seen_at > ?")
+		params = append(params, filter.LastSeenAfter)
+	}
+	if !filter.LastSeenBefore.IsZero() {
+		conditions = append(conditions, userTable+".last_seen_at < ?")
+		params = append(params, filter.LastSeenBefore)
+	}
+
+	return conditions, params
+}
+
+// latestUserAuthJoinCondition joins the auth module the user last logged in with, like the user search.
+func (ss *sqlStore) latestUserAuthJoinCondition() string {
+	return fmt.Sprintf("user_auth.id = (SELECT id FROM user_auth WHERE user_auth.user_id = %s.id ORDER BY user_auth.created DESC%s)",
+		ss.dialect.Quote("user"), ss.dialect.Limit(1))
+}
+
+// orgUsersOrderBy returns the ORDER BY expression of the sort, or an empty string for the default order.
+func (ss *sqlStore) orgUsersOrderBy(sort pagination.Sort) (string, error) {
+	userTable := ss.dialect.Quote("user")
+	var column string
+	switch sort.Field {
+	case "":
+		return "", nil
+	case "login", "email", "name":
+		column = userTable + "." + sort.Field
+	case "role":
+		column = "org_user.role"
+	case "lastSeenAt":
+		column = userTable + ".last_seen_at"
+	default:
+		return "", fmt.Errorf("%w: can only sort by %s", pagination.ErrInvalidSort, strings.Join(org.OrgUserSortFields, ", "))
+	}
+	return pagination.OrderBy(sort, column, "org_user.user_id"), nil
+}
+
 func (ss *sqlStore) GetByName(ctx context.Context, query *org.GetOrgByNameQuery) (*org.Org, error) {
 	var orga org.Org
 	err := ss.db.WithDbSession(ctx, func(dbSession *db.Session) error {
@@ -710,7 +997,8 @@ func (ss *sqlStore) GetByName(ctx context.Context, query *org.GetOrgByNameQuery)
 }
 
 func (ss *sqlStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCommand) error {
-	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	var removed *events.OrgUserRemoved
+	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		// check if user exists
 		var usr user.User
 		if exists, err := sess.ID(cmd.UserID).Where(ss.notServiceAccountFilter()).Get(&usr); err != nil {
@@ -719,11 +1007,32 @@ func (ss *sqlStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCom
 			return user.ErrUserNotFound
 		}
 
+		var orgUser org.OrgUser
+		isMember, err := sess.Where("org_id=? AND user_id=?", cmd.OrgID, cmd.UserID).Get(&orgUser)
+		if err != nil {
+			return err
+		}
+
+		cmd.Inventory, err = ss.getOrgUserAccessInventory(sess, cmd.OrgID, cmd.UserID)
+		if err != nil {
+			return err
+		}
+		if cmd.Preview {
+			return nil
+		}
+		if cmd.RevokeOrphanedAccess {
+			if err := ss.revokeOrphanedAccess(sess, cmd.Inventory, cmd.OrgID, cmd.UserID); err != nil {
+				return err
+			}
+		}
+
 		deletes := []string{
 			"DELETE FROM org_user WHERE org_id=? and user_id=?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and user_id = ?",
 			"DELETE FROM team_member WHERE org_id=? and user_id = ?",
 			"DELETE FROM query_history_star WHERE org_id=? and user_id = ?",
+			"DELETE FROM user_notification WHERE org_id=? and user_id = ?",
+			"DELETE FROM dashboard_subscription WHERE org_id=? and user_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -734,8 +1043,10 @@ func (ss *sqlStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCom
 		}
 
 		// validate that after delete, there is at least one user with admin role in org
-		if err := validateOneAdminLeftInOrg(cmd.OrgID, sess); err != nil {
-			return err
+		if !ss.adminsByPermissions {
+			if err := validateOneAdminLeftInOrg(cmd.OrgID, sess); err != nil {
+				return err
+			}
 		}
 
 		// check user other orgs and update user current org
@@ -744,7 +1055,7 @@ func (ss *sqlStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCom
 		sess.Join("INNER", "org", "org_user.org_id=org.id")
 		sess.Where("org_user.user_id=?", usr.ID)
 		sess.Cols("org.name", "org_user.role", "org_user.org_id")
-		err := sess.Find(&userOrgs)
+		err = sess.Find(&userOrgs)
 
 		if err != nil {
 			return err
@@ -780,8 +1091,47 @@ func (ss *sqlStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCom
 			}
 		}
 
+		if isMember {
+			removed = &events.OrgUserRemoved{
+				Timestamp:   time.Now(),
+				OrgID:       cmd.OrgID,
+				UserID:      cmd.UserID,
+				Role:        string(orgUser.Role),
+				Source:      orgUserSource(cmd.Source),
+				UserDeleted: cmd.UserWasDeleted,
+			}
+			sess.PublishAfterCommit(removed)
+		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	if removed != nil {
+		auditOrgUserEvent(removed)
+	}
+	return nil
+}
+
+func orgUserSource(source string) string {
+	if source == "" {
+		return events.OrgUserSourceManual
+	}
+	return source
+}
+
+// auditOrgUserEvent writes a committed change to the members of an organization to the audit log.
+func auditOrgUserEvent(e interface{}) {
+	switch e := e.(type) {
+	case *events.OrgUserAdded:
+		auditLogger.Info("User added to organization", "orgId", e.OrgID, "userId", e.UserID, "role", e.Role, "source", e.Source)
+	case *events.OrgUserRoleChanged:
+		auditLogger.Info("Organization role of user changed", "orgId", e.OrgID, "userId", e.UserID, "role", e.Role,
+			"previousRole", e.PreviousRole, "source", e.Source)
+	case *events.OrgUserRemoved:
+		auditLogger.Info("User removed from organization", "orgId", e.OrgID, "userId", e.UserID, "role", e.Role,
+			"source", e.Source, "userDeleted", e.UserDeleted)
+	}
 }
 
 func (ss *sqlStore) deleteUserInTransaction(sess *db.Session, cmd *models.DeleteUserCommand) error {
@@ -855,6 +1205,9 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_notification WHERE user_id = ?",
+		"DELETE FROM dashboard_subscription WHERE user_id = ?",
+		"DELETE FROM announcement_dismissal WHERE user_id = ?",
 	}
 	return deletes
 }
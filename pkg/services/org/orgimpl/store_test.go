@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -123,6 +125,19 @@ func TestIntegrationOrgDataAccess(t *testing.T) {
 		assert.Equal(t, 3, len(result))
 	})
 
+	t.Run("Given an organization created without a user, it has no members", func(t *testing.T) {
+		result, err := orgStore.CreateWithMember(context.Background(), &org.CreateOrgCommand{Name: "without members"})
+		require.NoError(t, err)
+
+		var members int64
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			members, err = sess.Where("org_id = ?", result.ID).Count(&org.OrgUser{})
+			return err
+		})
+		require.NoError(t, err)
+		assert.Zero(t, members)
+	})
+
 	t.Run("Given we have organizations, we can limit and paginate search", func(t *testing.T) {
 		ss = db.InitTestDB(t)
 		for i := 1; i < 4; i++ {
@@ -155,6 +170,24 @@ func TestIntegrationOrgDataAccess(t *testing.T) {
 			assert.Equal(t, 1, len(result))
 		})
 
+		t.Run("Should be able to sort and paginate search with a cursor", func(t *testing.T) {
+			sort := pagination.Sort{Field: "name", Descending: true}
+			query := &org.SearchOrgsQuery{Limit: 2, Sort: sort}
+			result, err := orgStore.Search(context.Background(), query)
+
+			require.NoError(t, err)
+			require.Equal(t, 2, len(result))
+			assert.Equal(t, "Orga #3", result[0].Name)
+			assert.Equal(t, "Orga #2", result[1].Name)
+
+			query = &org.SearchOrgsQuery{Limit: 2, Sort: sort, After: &pagination.Cursor{Value: result[1].Name, ID: result[1].ID}}
+			result, err = orgStore.Search(context.Background(), query)
+
+			require.NoError(t, err)
+			require.Equal(t, 1, len(result))
+			assert.Equal(t, "Orga #1", result[0].Name)
+		})
+
 		t.Run("Get org by ID", func(t *testing.T) {
 			query := &org.GetOrgByIdQuery{ID: 1}
 			result, err := orgStore.GetByID(context.Background(), query)
@@ -533,6 +566,258 @@ func TestIntegrationSQLStore_AddOrgUser(t *testing.T) {
 	require.Equal(t, saFound.OrgID, u.OrgID)
 }
 
+func TestIntegrationSQLStore_OrgUserEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	store := db.InitTestDB(t)
+	orgUserStore := sqlStore{
+		db:      store,
+		dialect: store.GetDialect(),
+		cfg:     setting.NewCfg(),
+	}
+
+	var published []interface{}
+	store.Bus().AddEventListener(func(ctx context.Context, e *events.OrgUserAdded) error {
+		published = append(published, *e)
+		return nil
+	})
+	store.Bus().AddEventListener(func(ctx context.Context, e *events.OrgUserRoleChanged) error {
+		published = append(published, *e)
+		return nil
+	})
+	store.Bus().AddEventListener(func(ctx context.Context, e *events.OrgUserRemoved) error {
+		published = append(published, *e)
+		return nil
+	})
+
+	admin, err := store.CreateUser(ctx, user.CreateUserCommand{Login: "admin", SkipOrgSetup: true})
+	require.NoError(t, err)
+	member, err := store.CreateUser(ctx, user.CreateUserCommand{Login: "member", SkipOrgSetup: true})
+	require.NoError(t, err)
+	orga, err := orgUserStore.CreateWithMember(ctx, &org.CreateOrgCommand{Name: "events", UserID: admin.ID})
+	require.NoError(t, err)
+	published = nil
+
+	require.NoError(t, orgUserStore.AddOrgUser(ctx, &org.AddOrgUserCommand{OrgID: orga.ID, UserID: member.ID, Role: org.RoleViewer}))
+	require.NoError(t, orgUserStore.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{OrgID: orga.ID, UserID: member.ID, Role: org.RoleViewer, Source: events.OrgUserSourceSync}))
+	require.NoError(t, orgUserStore.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{OrgID: orga.ID, UserID: member.ID, Role: org.RoleEditor, Source: events.OrgUserSourceSync}))
+	err = orgUserStore.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{OrgID: orga.ID, UserID: admin.ID, Role: org.RoleViewer})
+	require.ErrorIs(t, err, models.ErrLastOrgAdmin)
+	require.NoError(t, orgUserStore.RemoveOrgUser(ctx, &org.RemoveOrgUserCommand{OrgID: orga.ID, UserID: member.ID, Source: events.OrgUserSourceSCIM}))
+
+	require.Len(t, published, 3, "unchanged roles and rolled back changes should not publish events")
+	added := published[0].(events.OrgUserAdded)
+	assert.Equal(t, events.OrgUserAdded{Timestamp: added.Timestamp, OrgID: orga.ID, UserID: member.ID, Role: "Viewer", Source: events.OrgUserSourceManual}, added)
+	changed := published[1].(events.OrgUserRoleChanged)
+	assert.Equal(t, events.OrgUserRoleChanged{Timestamp: changed.Timestamp, OrgID: orga.ID, UserID: member.ID, Role: "Editor", PreviousRole: "Viewer", Source: events.OrgUserSourceSync}, changed)
+	removed := published[2].(events.OrgUserRemoved)
+	assert.Equal(t, events.OrgUserRemoved{Timestamp: removed.Timestamp, OrgID: orga.ID, UserID: member.ID, Role: "Editor", Source: events.OrgUserSourceSCIM}, removed)
+}
+
+func TestIntegrationSQLStore_WithOrgMembersLocked(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	store := db.InitTestDB(t)
+	orgUserStore := sqlStore{
+		db:                  store,
+		dialect:             store.GetDialect(),
+		cfg:                 setting.NewCfg(),
+		adminsByPermissions: true,
+	}
+
+	admin, err := store.CreateUser(ctx, user.CreateUserCommand{Login: "admin", SkipOrgSetup: true})
+	require.NoError(t, err)
+	orga, err := orgUserStore.CreateWithMember(ctx, &org.CreateOrgCommand{Name: "locked", UserID: admin.ID})
+	require.NoError(t, err)
+
+	err = orgUserStore.WithOrgMembersLocked(ctx, orga.ID, func(ctx context.Context) error {
+		require.NoError(t, orgUserStore.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{OrgID: orga.ID, UserID: admin.ID, Role: org.RoleViewer}))
+		return models.ErrLastOrgAdmin
+	})
+	require.ErrorIs(t, err, models.ErrLastOrgAdmin)
+
+	users, err := orgUserStore.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID: orga.ID,
+		User: &user.SignedInUser{
+			OrgID:       orga.ID,
+			Permissions: map[int64]map[string][]string{orga.ID: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, string(org.RoleAdmin), users[0].Role, "a change rejected by the check should be rolled back")
+}
+
+func TestIntegrationSQLStore_GetAdminCandidates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	store := db.InitTestDB(t)
+	orgUserStore := sqlStore{
+		db:      store,
+		dialect: store.GetDialect(),
+		cfg:     setting.NewCfg(),
+	}
+
+	users := map[string]*user.User{}
+	for _, login := range []string{"admin", "assigned", "team-member", "editor", "viewer", "server-admin"} {
+		u, err := store.CreateUser(ctx, user.CreateUserCommand{Login: login, SkipOrgSetup: true, IsAdmin: login == "server-admin"})
+		require.NoError(t, err)
+		users[login] = u
+	}
+	orga, err := orgUserStore.CreateWithMember(ctx, &org.CreateOrgCommand{Name: "candidates", UserID: users["admin"].ID})
+	require.NoError(t, err)
+	for login, role := range map[string]org.RoleType{"assigned": org.RoleViewer, "team-member": org.RoleViewer, "editor": org.RoleEditor, "viewer": org.RoleViewer, "server-admin": org.RoleViewer} {
+		require.NoError(t, orgUserStore.AddOrgUser(ctx, &org.AddOrgUserCommand{OrgID: orga.ID, UserID: users[login].ID, Role: role}))
+	}
+
+	now := time.Now()
+	err = store.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, q := range []struct {
+			sql  string
+			args []interface{}
+		}{
+			{"INSERT INTO user_role (org_id, user_id, role_id, created) VALUES (?, ?, ?, ?)", []interface{}{orga.ID, users["assigned"].ID, 1, now}},
+			{"INSERT INTO team_member (org_id, team_id, user_id, created, updated) VALUES (?, ?, ?, ?, ?)", []interface{}{orga.ID, 10, users["team-member"].ID, now, now}},
+			{"INSERT INTO team_member (org_id, team_id, user_id, created, updated) VALUES (?, ?, ?, ?, ?)", []interface{}{orga.ID, 11, users["viewer"].ID, now, now}},
+			{"INSERT INTO team_role (org_id, team_id, role_id, created) VALUES (?, ?, ?, ?)", []interface{}{orga.ID, 10, 1, now}},
+			{"INSERT INTO builtin_role (org_id, role, role_id, created, updated) VALUES (?, ?, ?, ?, ?)", []interface{}{orga.ID, "Editor", 1, now, now}},
+		} {
+			if _, err := sess.Exec(append([]interface{}{q.sql}, q.args...)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	candidates, err := orgUserStore.GetAdminCandidates(ctx, orga.ID)
+	require.NoError(t, err)
+	byLogin := map[string]*adminCandidate{}
+	for login, u := range users {
+		for _, c := range candidates {
+			if c.UserID == u.ID {
+				byLogin[login] = c
+			}
+		}
+	}
+	assert.Len(t, candidates, 5, "the viewer has no roles assigned")
+	assert.Equal(t, org.RoleAdmin, byLogin["admin"].Role)
+	assert.NotNil(t, byLogin["assigned"])
+	assert.Equal(t, []int64{10}, byLogin["team-member"].Teams)
+	assert.Equal(t, org.RoleEditor, byLogin["editor"].Role)
+	assert.True(t, byLogin["server-admin"].IsAdmin)
+	assert.Nil(t, byLogin["viewer"])
+}
+
+func TestIntegrationSQLStore_RemoveOrgUserInventory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	store := db.InitTestDB(t)
+	orgUserStore := sqlStore{
+		db:      store,
+		dialect: store.GetDialect(),
+		cfg:     setting.NewCfg(),
+	}
+
+	admin, err := store.CreateUser(ctx, user.CreateUserCommand{Login: "admin", SkipOrgSetup: true})
+	require.NoError(t, err)
+	member, err := store.CreateUser(ctx, user.CreateUserCommand{Login: "member", SkipOrgSetup: true})
+	require.NoError(t, err)
+	orga, err := orgUserStore.CreateWithMember(ctx, &org.CreateOrgCommand{Name: "inventory", UserID: admin.ID})
+	require.NoError(t, err)
+	require.NoError(t, orgUserStore.AddOrgUser(ctx, &org.AddOrgUserCommand{OrgID: orga.ID, UserID: member.ID, Role: org.RoleEditor}))
+	orphaned, err := store.CreateUser(ctx, user.CreateUserCommand{Login: "sa-orphaned", IsServiceAccount: true, SkipOrgSetup: true})
+	require.NoError(t, err)
+	shared, err := store.CreateUser(ctx, user.CreateUserCommand{Login: "sa-shared", IsServiceAccount: true, SkipOrgSetup: true})
+	require.NoError(t, err)
+
+	now := time.Now()
+	var managedDashboard, aclDashboard *models.Dashboard
+	err = store.WithDbSession(ctx, func(sess *db.Session) error {
+		managedDashboard = models.NewDashboard("managed")
+		managedDashboard.OrgId, managedDashboard.Uid = orga.ID, "managed"
+		aclDashboard = models.NewDashboard("acl")
+		aclDashboard.OrgId, aclDashboard.Uid = orga.ID, "acl"
+		if _, err := sess.Insert(managedDashboard, aclDashboard); err != nil {
+			return err
+		}
+
+		for _, q := range []struct {
+			sql  string
+			args []interface{}
+		}{
+			{"INSERT INTO role (id, name, version, org_id, uid, created, updated) VALUES (?, ?, ?, ?, ?, ?, ?)", []interface{}{100, accesscontrol.ManagedUserRoleName(member.ID), 1, orga.ID, "member", now, now}},
+			{"INSERT INTO role (id, name, version, org_id, uid, created, updated) VALUES (?, ?, ?, ?, ?, ?, ?)", []interface{}{101, "managed:teams:1:permissions", 1, orga.ID, "team", now, now}},
+			{"INSERT INTO permission (role_id, action, scope, created, updated) VALUES (?, ?, ?, ?, ?)", []interface{}{100, "serviceaccounts:write", fmt.Sprintf("serviceaccounts:id:%d", orphaned.ID), now, now}},
+			{"INSERT INTO permission (role_id, action, scope, created, updated) VALUES (?, ?, ?, ?, ?)", []interface{}{100, "serviceaccounts:write", fmt.Sprintf("serviceaccounts:id:%d", shared.ID), now, now}},
+			{"INSERT INTO permission (role_id, action, scope, created, updated) VALUES (?, ?, ?, ?, ?)", []interface{}{101, "serviceaccounts:read", fmt.Sprintf("serviceaccounts:id:%d", shared.ID), now, now}},
+			{"INSERT INTO permission (role_id, action, scope, created, updated) VALUES (?, ?, ?, ?, ?)", []interface{}{100, "dashboards:read", "dashboards:uid:managed", now, now}},
+			{"INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", []interface{}{orga.ID, aclDashboard.Id, member.ID, 1, now, now}},
+			{"INSERT INTO api_key (org_id, name, role, " + store.GetDialect().Quote("key") + ", created, updated, service_account_id) VALUES (?, ?, ?, ?, ?, ?, ?)", []interface{}{orga.ID, "orphaned-token", "Viewer", "hash1", now, now, orphaned.ID}},
+			{"INSERT INTO api_key (org_id, name, role, " + store.GetDialect().Quote("key") + ", created, updated, service_account_id) VALUES (?, ?, ?, ?, ?, ?, ?)", []interface{}{orga.ID, "shared-token", "Viewer", "hash2", now, now, shared.ID}},
+			{"INSERT INTO org_token (uid, org_id, name, role, scopes, key_hash, previous_key_hash, created_by, created, use_count) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", []interface{}{"token", orga.ID, "pipeline", "Editor", "[]", "hash3", "", member.ID, now, 0}},
+			{"UPDATE " + store.GetDialect().Quote("user") + " SET org_id = ? WHERE id IN (?, ?)", []interface{}{orga.ID, orphaned.ID, shared.ID}},
+		} {
+			if _, err := sess.Exec(append([]interface{}{q.sql}, q.args...)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("Should return the inventory without removing the user in preview", func(t *testing.T) {
+		cmd := &org.RemoveOrgUserCommand{OrgID: orga.ID, UserID: member.ID, Preview: true, RevokeOrphanedAccess: true}
+		require.NoError(t, orgUserStore.RemoveOrgUser(ctx, cmd))
+
+		inventory := cmd.Inventory
+		require.Len(t, inventory.ServiceAccounts, 2)
+		assert.Equal(t, "sa-orphaned", inventory.ServiceAccounts[0].Login)
+		assert.True(t, inventory.ServiceAccounts[0].Orphaned)
+		assert.Equal(t, []*org.OrgUserToken{{ID: inventory.ServiceAccounts[0].Tokens[0].ID, Name: "orphaned-token"}}, inventory.ServiceAccounts[0].Tokens)
+		assert.Equal(t, "sa-shared", inventory.ServiceAccounts[1].Login)
+		assert.False(t, inventory.ServiceAccounts[1].Orphaned)
+		require.Len(t, inventory.OrgTokens, 1)
+		assert.Equal(t, "pipeline", inventory.OrgTokens[0].Name)
+		assert.Equal(t, []*org.OrgUserDashboard{{UID: "acl", Title: "acl"}, {UID: "managed", Title: "managed"}}, inventory.Dashboards)
+		assert.False(t, inventory.Revoked)
+
+		users, err := orgUserStore.GetOrgUsers(ctx, &org.GetOrgUsersQuery{OrgID: orga.ID, User: &user.SignedInUser{}, DontEnforceAccessControl: true})
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("Should revoke the orphaned access when removing the user", func(t *testing.T) {
+		cmd := &org.RemoveOrgUserCommand{OrgID: orga.ID, UserID: member.ID, RevokeOrphanedAccess: true}
+		require.NoError(t, orgUserStore.RemoveOrgUser(ctx, cmd))
+		assert.True(t, cmd.Inventory.Revoked)
+
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			var revoked []string
+			if err := sess.Table("api_key").Cols("name").Where("is_revoked = ?", store.GetDialect().BooleanStr(true)).Find(&revoked); err != nil {
+				return err
+			}
+			assert.Equal(t, []string{"orphaned-token"}, revoked)
+			tokens, err := sess.Table("org_token").Where("org_id = ?", orga.ID).Count()
+			assert.Zero(t, tokens)
+			return err
+		})
+		require.NoError(t, err)
+	})
+}
+
 func TestIntegration_SQLStore_GetOrgUsers(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -778,6 +1063,92 @@ func TestIntegration_SQLStore_SearchOrgUsers(t *testing.T) {
 	}
 }
 
+func TestIntegration_SQLStore_SearchOrgUsers_Filters(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := db.InitTestDB(t, sqlstore.InitTestDBOpt{})
+	orgUserStore := sqlStore{
+		db:      store,
+		dialect: store.GetDialect(),
+		cfg:     setting.NewCfg(),
+	}
+	signedInUser := &user.SignedInUser{
+		OrgID:       1,
+		Permissions: map[int64]map[string][]string{1: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}}},
+	}
+
+	// the first user creates the organization and is its admin
+	users := map[string]*user.User{}
+	for _, u := range []struct {
+		login      string
+		role       org.RoleType
+		disabled   bool
+		authModule string
+	}{
+		{login: "admin", role: org.RoleAdmin},
+		{login: "ldap-user", role: org.RoleViewer, authModule: "ldap"},
+		{login: "oauth-user", role: org.RoleEditor, authModule: "oauth_github", disabled: true},
+		{login: "local-user", role: org.RoleViewer},
+	} {
+		usr, err := store.CreateUser(context.Background(), user.CreateUserCommand{Login: u.login, Email: u.login + "@localhost", OrgID: 1, IsDisabled: u.disabled, SkipOrgSetup: u.role != org.RoleAdmin})
+		require.NoError(t, err)
+		users[u.login] = usr
+		if u.role != org.RoleAdmin {
+			require.NoError(t, orgUserStore.AddOrgUser(context.Background(), &org.AddOrgUserCommand{Role: u.role, OrgID: 1, UserID: usr.ID}))
+		}
+		if u.authModule != "" {
+			err = store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+				_, err := sess.Insert(&models.UserAuth{UserId: usr.ID, AuthModule: u.authModule, AuthId: u.login, Created: time.Now()})
+				return err
+			})
+			require.NoError(t, err)
+		}
+	}
+	err := store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE "+store.GetDialect().Quote("user")+" SET last_seen_at = ? WHERE id = ?", time.Now(), users["ldap-user"].ID)
+		return err
+	})
+	require.NoError(t, err)
+
+	disabled := true
+	tests := []struct {
+		desc           string
+		filter         org.OrgUsersFilter
+		expectedLogins []string
+	}{
+		{desc: "should filter by role", filter: org.OrgUsersFilter{Roles: []org.RoleType{org.RoleViewer, org.RoleEditor}}, expectedLogins: []string{"ldap-user", "local-user", "oauth-user"}},
+		{desc: "should filter by disabled status", filter: org.OrgUsersFilter{IsDisabled: &disabled}, expectedLogins: []string{"oauth-user"}},
+		{desc: "should filter by auth module", filter: org.OrgUsersFilter{AuthModule: "ldap"}, expectedLogins: []string{"ldap-user"}},
+		{desc: "should filter OAuth users", filter: org.OrgUsersFilter{AuthModule: org.AuthModuleOAuth}, expectedLogins: []string{"oauth-user"}},
+		{desc: "should filter local users", filter: org.OrgUsersFilter{AuthModule: org.AuthModuleLocal}, expectedLogins: []string{"admin", "local-user"}},
+		{desc: "should filter by last seen", filter: org.OrgUsersFilter{LastSeenAfter: time.Now().Add(-time.Hour)}, expectedLogins: []string{"ldap-user"}},
+		{desc: "should filter users not seen recently", filter: org.OrgUsersFilter{LastSeenBefore: time.Now().Add(-time.Hour), Roles: []org.RoleType{org.RoleViewer}}, expectedLogins: []string{"local-user"}},
+		{desc: "should sort", filter: org.OrgUsersFilter{Sort: pagination.Sort{Field: "login", Descending: true}}, expectedLogins: []string{"oauth-user", "local-user", "ldap-user", "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			result, err := orgUserStore.SearchOrgUsers(context.Background(), &org.SearchOrgUsersQuery{OrgID: 1, User: signedInUser, OrgUsersFilter: tt.filter})
+			require.NoError(t, err)
+			logins := make([]string, 0, len(result.OrgUsers))
+			for _, u := range result.OrgUsers {
+				logins = append(logins, u.Login)
+			}
+			assert.Equal(t, tt.expectedLogins, logins)
+			assert.Equal(t, int64(len(tt.expectedLogins)), result.TotalCount)
+
+			users, err := orgUserStore.GetOrgUsers(context.Background(), &org.GetOrgUsersQuery{OrgID: 1, User: signedInUser, OrgUsersFilter: tt.filter})
+			require.NoError(t, err)
+			assert.Len(t, users, len(tt.expectedLogins))
+		})
+	}
+
+	t.Run("should reject an unknown sort field", func(t *testing.T) {
+		_, err := orgUserStore.SearchOrgUsers(context.Background(), &org.SearchOrgUsersQuery{OrgID: 1, User: signedInUser, OrgUsersFilter: org.OrgUsersFilter{Sort: pagination.Sort{Field: "password"}}})
+		assert.ErrorIs(t, err, pagination.ErrInvalidSort)
+	})
+}
+
 func TestIntegration_SQLStore_RemoveOrgUser(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
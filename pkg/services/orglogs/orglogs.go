@@ -0,0 +1,211 @@
+// Package orglogs keeps the recent log lines of each organization, so that organization admins can
+// follow the requests made in their organization without access to the logs of the server.
+//
+// Log lines are attributed to an organization by their orgId value, which the log lines of
+// requests carry once the request is authenticated. Lines are only kept in memory, by the instance
+// which logged them.
+package orglogs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// subscriberBuffer is the number of lines a subscriber can lag behind before lines are dropped.
+const subscriberBuffer = 100
+
+// Line is a log line of an organization.
+type Line struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Logger  string    `json:"logger,omitempty"`
+	Message string    `json:"msg"`
+	// Fields are the other values of the line.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type Service interface {
+	// Recent returns up to limit of the most recent log lines of the organization, oldest first.
+	Recent(orgID int64, limit int) []Line
+	// Subscribe returns up to limit of the most recent log lines of the organization, a channel
+	// receiving the following log lines as they are logged, and a function which must be called to
+	// stop the subscription. Lines are dropped when the subscriber doesn't keep up.
+	Subscribe(orgID int64, limit int) ([]Line, <-chan Line, func())
+}
+
+// OrgLogsService is a log sink keeping the log lines of each organization in a ring buffer.
+type OrgLogsService struct {
+	cfg *setting.Cfg
+	now func() time.Time
+
+	mu          sync.Mutex
+	buffers     map[string]*ring
+	subscribers map[string]map[chan Line]struct{}
+}
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles) *OrgLogsService {
+	s := &OrgLogsService{
+		cfg:         cfg,
+		now:         time.Now,
+		buffers:     map[string]*ring{},
+		subscribers: map[string]map[chan Line]struct{}{},
+	}
+	if features.IsEnabled(featuremgmt.FlagOrgLogStreaming) && cfg.OrgLogs.BufferSize > 0 {
+		log.AddSink(s, cfg.OrgLogs.Level)
+	}
+	return s
+}
+
+func (s *OrgLogsService) Recent(orgID int64, limit int) []Line {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buffer, ok := s.buffers[s.key(orgID)]
+	if !ok {
+		return []Line{}
+	}
+	return buffer.last(limit)
+}
+
+func (s *OrgLogsService) Subscribe(orgID int64, limit int) ([]Line, <-chan Line, func()) {
+	key := s.key(orgID)
+	ch := make(chan Line, subscriberBuffer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[key] == nil {
+		s.subscribers[key] = map[chan Line]struct{}{}
+	}
+	s.subscribers[key][ch] = struct{}{}
+	recent := []Line{}
+	if buffer, ok := s.buffers[key]; ok {
+		recent = buffer.last(limit)
+	}
+
+	var once sync.Once
+	return recent, ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers[key], ch)
+			if len(s.subscribers[key]) == 0 {
+				delete(s.subscribers, key)
+			}
+		})
+	}
+}
+
+// Log implements the go-kit logger interface. It must not log, since it is called by the loggers.
+func (s *OrgLogsService) Log(keyvals ...interface{}) error {
+	var key string
+	line := Line{Time: s.now()}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		k := fmt.Sprint(keyvals[i])
+		v := keyvals[i+1]
+		switch k {
+		case "t":
+		case "level":
+			line.Level = fmt.Sprint(v)
+		case "logger":
+			line.Logger = fmt.Sprint(v)
+		case "msg":
+			line.Message = fmt.Sprint(v)
+		case tracing.OrgIDKey:
+			if key == "" {
+				key = s.orgKey(v)
+			}
+		default:
+			if line.Fields == nil {
+				line.Fields = map[string]string{}
+			}
+			line.Fields[k] = fmt.Sprint(v)
+		}
+	}
+	if key == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buffer, ok := s.buffers[key]
+	if !ok {
+		buffer = newRing(s.cfg.OrgLogs.BufferSize)
+		s.buffers[key] = buffer
+	}
+	buffer.add(line)
+	for ch := range s.subscribers[key] {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+	return nil
+}
+
+// key returns the key of the log lines of an organization, the value identifying it in logs.
+func (s *OrgLogsService) key(orgID int64) string {
+	return fmt.Sprint(s.cfg.OrgIDLogValue(orgID))
+}
+
+// orgKey returns the key of the organization a log line is attributed to by its orgId value, which
+// is either an ID or an already hashed ID. Lines of requests without organization are ignored.
+func (s *OrgLogsService) orgKey(v interface{}) string {
+	var orgID int64
+	switch id := v.(type) {
+	case int64:
+		orgID = id
+	case int:
+		orgID = int64(id)
+	case string:
+		if strings.HasPrefix(id, setting.OrgIDHashPrefix) {
+			return id
+		}
+		orgID, _ = strconv.ParseInt(id, 10, 64)
+	}
+	if orgID <= 0 {
+		return ""
+	}
+	return s.key(orgID)
+}
+
+// ring keeps the last lines added to it.
+type ring struct {
+	lines []Line
+	next  int
+	full  bool
+}
+
+func newRing(size int) *ring {
+	return &ring{lines: make([]Line, size)}
+}
+
+func (r *ring) add(line Line) {
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *ring) last(limit int) []Line {
+	count := r.next
+	if r.full {
+		count = len(r.lines)
+	}
+	if limit > 0 && limit < count {
+		count = limit
+	}
+	lines := make([]Line, 0, count)
+	for i := count; i > 0; i-- {
+		lines = append(lines, r.lines[(r.next-i+len(r.lines))%len(r.lines)])
+	}
+	return lines
+}
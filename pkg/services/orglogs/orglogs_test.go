@@ -0,0 +1,92 @@
+package orglogs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestService(t *testing.T, hash bool) *OrgLogsService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.SecretKey = "secret"
+	cfg.OrgLogs = setting.OrgLogsSettings{HashOrgID: hash, BufferSize: 3, Level: "info"}
+	s := ProvideService(cfg, featuremgmt.WithFeatures())
+	now := time.Date(2022, 11, 2, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestOrgLogsService(t *testing.T) {
+	t.Run("lines are kept for the organization they are logged in", func(t *testing.T) {
+		s := newTestService(t, false)
+		require.NoError(t, s.Log("logger", "context", "userId", int64(3), "orgId", int64(2), "t", "now", "level", "info", "msg", "Request Completed", "status", 200))
+		require.NoError(t, s.Log("logger", "context", "orgId", int64(1), "level", "info", "msg", "other org"))
+		require.NoError(t, s.Log("logger", "context", "orgId", int64(0), "level", "info", "msg", "not signed in"))
+		require.NoError(t, s.Log("logger", "server", "level", "info", "msg", "no org"))
+
+		assert.Equal(t, []Line{{
+			Time:    s.now(),
+			Level:   "info",
+			Logger:  "context",
+			Message: "Request Completed",
+			Fields:  map[string]string{"userId": "3", "status": "200"},
+		}}, s.Recent(2, 0))
+		assert.Len(t, s.Recent(1, 0), 1)
+		assert.Empty(t, s.Recent(0, 0))
+		assert.Empty(t, s.Recent(3, 0))
+	})
+
+	t.Run("only the most recent lines are kept", func(t *testing.T) {
+		s := newTestService(t, false)
+		for _, msg := range []string{"1", "2", "3", "4", "5"} {
+			require.NoError(t, s.Log("orgId", int64(1), "msg", msg))
+		}
+
+		messages := func(lines []Line) []string {
+			msgs := []string{}
+			for _, line := range lines {
+				msgs = append(msgs, line.Message)
+			}
+			return msgs
+		}
+		assert.Equal(t, []string{"3", "4", "5"}, messages(s.Recent(1, 0)))
+		assert.Equal(t, []string{"4", "5"}, messages(s.Recent(1, 2)))
+	})
+
+	t.Run("lines with hashed org IDs are attributed to the organization", func(t *testing.T) {
+		s := newTestService(t, true)
+		hashed := s.cfg.OrgIDLogValue(2)
+		require.IsType(t, "", hashed)
+		require.NotEqual(t, hashed, s.cfg.OrgIDLogValue(1))
+
+		require.NoError(t, s.Log("orgId", hashed, "msg", "hashed"))
+		require.NoError(t, s.Log("orgId", int64(2), "msg", "not hashed"))
+		assert.Len(t, s.Recent(2, 0), 2)
+		assert.Empty(t, s.Recent(1, 0))
+	})
+
+	t.Run("subscribers receive the lines of their organization", func(t *testing.T) {
+		s := newTestService(t, false)
+		require.NoError(t, s.Log("orgId", int64(1), "msg", "before"))
+		recent, lines, unsubscribe := s.Subscribe(1, 10)
+		require.Len(t, recent, 1)
+		assert.Equal(t, "before", recent[0].Message)
+
+		require.NoError(t, s.Log("orgId", int64(2), "msg", "other org"))
+		require.NoError(t, s.Log("orgId", int64(1), "msg", "hello"))
+		line := <-lines
+		assert.Equal(t, "hello", line.Message)
+
+		unsubscribe()
+		unsubscribe()
+		require.NoError(t, s.Log("orgId", int64(1), "msg", "after unsubscribe"))
+		assert.Empty(t, lines)
+		assert.Empty(t, s.subscribers)
+	})
+}
@@ -0,0 +1,108 @@
+// Package orgtoken issues API tokens owned by an organization rather than by a user, for
+// infrastructure which acts on behalf of the organization, like provisioning pipelines.
+//
+// Unlike API keys and service account tokens, an organization token is not linked to any user or
+// service account. Requests made with it are signed in with the role of the token, and the RBAC
+// permissions of the role can be restricted to a list of scopes. Tokens can be rotated with a grace
+// period during which the previous secret is still accepted, and their use is tracked.
+package orgtoken
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apikeygenprefix "github.com/grafana/grafana/pkg/components/apikeygenprefixed"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// ServiceID is the service ID of the prefixed keys of organization tokens, which start with glot_.
+const ServiceID = "ot"
+
+// KeyPrefix is the prefix of the keys of organization tokens.
+const KeyPrefix = apikeygenprefix.GrafanaPrefix + ServiceID + "_"
+
+const (
+	ActionRead  = "org.tokens:read"
+	ActionWrite = "org.tokens:write"
+)
+
+var (
+	ErrTokenNotFound      = errors.New("organization token not found")
+	ErrTokenInvalid       = errors.New("invalid organization token")
+	ErrTokenExpired       = errors.New("organization token has expired")
+	ErrTokenNameTaken     = errors.New("an organization token with this name already exists")
+	ErrInvalidName        = errors.New("organization token name is required")
+	ErrInvalidRole        = errors.New("invalid organization token role")
+	ErrRoleNotAllowed     = errors.New("cannot create an organization token with a role higher than your own role")
+	ErrInvalidScope       = errors.New("invalid organization token scope")
+	ErrInvalidLifetime    = errors.New("invalid organization token lifetime")
+	ErrInvalidGracePeriod = errors.New("invalid organization token grace period")
+)
+
+// OrgToken is an API token of an organization. The key itself is only returned when the token is
+// created or rotated.
+type OrgToken struct {
+	ID    int64  `json:"id" xorm:"pk autoincr 'id'"`
+	UID   string `json:"uid" xorm:"uid"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	Name  string `json:"name" xorm:"name"`
+	// Role is the basic role requests made with the token are signed in with.
+	Role org.RoleType `json:"role" xorm:"role"`
+	// Scopes restrict the RBAC permissions of the role, empty if they are not restricted.
+	Scopes  []string `json:"scopes" xorm:"jsonb scopes"`
+	KeyHash string   `json:"-" xorm:"key_hash"`
+	// PreviousKeyHash is the hash of the key replaced by the last rotation, accepted until
+	// PreviousKeyExpires.
+	PreviousKeyHash    string     `json:"-" xorm:"previous_key_hash"`
+	PreviousKeyExpires *time.Time `json:"previousKeyExpires,omitempty" xorm:"previous_key_expires"`
+	CreatedBy          int64      `json:"createdBy" xorm:"created_by"`
+	Created            time.Time  `json:"created" xorm:"created"`
+	Rotated            *time.Time `json:"rotated,omitempty" xorm:"rotated"`
+	Expires            *time.Time `json:"expires,omitempty" xorm:"expires"`
+	LastUsedAt         *time.Time `json:"lastUsedAt,omitempty" xorm:"last_used_at"`
+	LastUsedFrom       string     `json:"lastUsedFrom,omitempty" xorm:"last_used_from"`
+	UseCount           int64      `json:"useCount" xorm:"use_count"`
+}
+
+func (t OrgToken) TableName() string {
+	return "org_token"
+}
+
+type CreateTokenCommand struct {
+	OrgID  int64        `json:"-"`
+	Name   string       `json:"name"`
+	Role   org.RoleType `json:"role"`
+	Scopes []string     `json:"scopes"`
+	// ExpiresIn is the lifetime of the token, such as 90d. The token doesn't expire if it is empty.
+	ExpiresIn string `json:"expiresIn"`
+	CreatedBy int64  `json:"-"`
+	// CreatorRole is the organization role of the creator, which must include the role of the token.
+	CreatorRole org.RoleType `json:"-"`
+}
+
+type RotateTokenCommand struct {
+	OrgID int64  `json:"-"`
+	UID   string `json:"-"`
+	// GracePeriod is how long the previous key is still accepted, such as 1h, so that the clients
+	// using it can be updated. The previous key is rejected immediately if it is empty.
+	GracePeriod string `json:"gracePeriod"`
+}
+
+// TokenWithKey is a created or rotated token, together with its key.
+// swagger:model
+type TokenWithKey struct {
+	OrgToken
+	Key string `json:"key"`
+}
+
+type Service interface {
+	CreateToken(ctx context.Context, cmd CreateTokenCommand) (*TokenWithKey, error)
+	// GetTokens returns the tokens of an organization, ordered by name.
+	GetTokens(ctx context.Context, orgID int64) ([]*OrgToken, error)
+	// RotateToken replaces the key of a token.
+	RotateToken(ctx context.Context, cmd RotateTokenCommand) (*TokenWithKey, error)
+	DeleteToken(ctx context.Context, orgID int64, uid string) error
+	// Authenticate returns the token of a key, and records its use from the address.
+	Authenticate(ctx context.Context, key string, remoteAddr string) (*OrgToken, error)
+}
@@ -0,0 +1,178 @@
+package orgtokenimpl
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *OrgTokenService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/org/tokens", func(tokenRoute routing.RouteRegister) {
+		tokenRoute.Get("/", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(orgtoken.ActionRead)), routing.Wrap(s.getTokensHandler))
+		tokenRoute.Post("/", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(orgtoken.ActionWrite)), routing.Wrap(s.createTokenHandler))
+		tokenRoute.Post("/:uid/rotate", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(orgtoken.ActionWrite)), routing.Wrap(s.rotateTokenHandler))
+		tokenRoute.Delete("/:uid", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(orgtoken.ActionWrite)), routing.Wrap(s.deleteTokenHandler))
+	}, middleware.ReqSignedIn, rejectOrgTokens)
+}
+
+// rejectOrgTokens rejects the requests made with an organization token, so that a token cannot
+// issue tokens without its restrictions.
+func rejectOrgTokens(c *models.ReqContext) {
+	if _, ok := orgtoken.FromContext(c.Req.Context()); ok {
+		c.JsonApiErr(http.StatusForbidden, "Organization tokens cannot be managed with an organization token", nil)
+	}
+}
+
+// swagger:route GET /org/tokens org getOrgTokens
+//
+// Get the API tokens of the current organization.
+//
+// The tokens are returned without their keys, with the time, address and count of their use. The use is recorded with a delay of up to a minute.
+//
+// Responses:
+// 200: getOrgTokensResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *OrgTokenService) getTokensHandler(c *models.ReqContext) response.Response {
+	tokens, err := s.GetTokens(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get organization tokens", err)
+	}
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route POST /org/tokens org createOrgToken
+//
+// Create an API token for the current organization.
+//
+// Requests made with the token are signed in with its role in the organization, without any user. The RBAC permissions of the role can be restricted to a list of scopes. The key is only returned once.
+//
+// Responses:
+// 200: orgTokenWithKeyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *OrgTokenService) createTokenHandler(c *models.ReqContext) response.Response {
+	cmd := orgtoken.CreateTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.CreatedBy = c.UserID
+	cmd.CreatorRole = c.OrgRole
+
+	result, err := s.CreateToken(c.Req.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, orgtoken.ErrInvalidName), errors.Is(err, orgtoken.ErrInvalidRole), errors.Is(err, orgtoken.ErrInvalidScope), errors.Is(err, orgtoken.ErrInvalidLifetime):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, orgtoken.ErrRoleNotAllowed):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		case errors.Is(err, orgtoken.ErrTokenNameTaken):
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to create organization token", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /org/tokens/{token_uid}/rotate org rotateOrgToken
+//
+// Rotate the key of an API token of the current organization.
+//
+// The previous key is accepted until the end of the grace period, so that the clients using it can be updated. The new key is only returned once.
+//
+// Responses:
+// 200: orgTokenWithKeyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OrgTokenService) rotateTokenHandler(c *models.ReqContext) response.Response {
+	cmd := orgtoken.RotateTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.UID = web.Params(c.Req)[":uid"]
+
+	result, err := s.RotateToken(c.Req.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, orgtoken.ErrInvalidGracePeriod):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, orgtoken.ErrTokenNotFound):
+			return response.Error(http.StatusNotFound, "Organization token not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to rotate organization token", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /org/tokens/{token_uid} org deleteOrgToken
+//
+// Delete an API token of the current organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *OrgTokenService) deleteTokenHandler(c *models.ReqContext) response.Response {
+	if err := s.DeleteToken(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		if errors.Is(err, orgtoken.ErrTokenNotFound) {
+			return response.Error(http.StatusNotFound, "Organization token not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete organization token", err)
+	}
+	return response.Success("Organization token deleted")
+}
+
+// swagger:parameters createOrgToken
+type CreateOrgTokenParams struct {
+	// in:body
+	// required:true
+	Body orgtoken.CreateTokenCommand `json:"body"`
+}
+
+// swagger:parameters rotateOrgToken
+type RotateOrgTokenParams struct {
+	// in:path
+	// required:true
+	TokenUID string `json:"token_uid"`
+	// in:body
+	Body orgtoken.RotateTokenCommand `json:"body"`
+}
+
+// swagger:parameters deleteOrgToken
+type DeleteOrgTokenParams struct {
+	// in:path
+	// required:true
+	TokenUID string `json:"token_uid"`
+}
+
+// swagger:response getOrgTokensResponse
+type GetOrgTokensResponse struct {
+	// in:body
+	Body []*orgtoken.OrgToken `json:"body"`
+}
+
+// swagger:response orgTokenWithKeyResponse
+type OrgTokenWithKeyResponse struct {
+	// in:body
+	Body *orgtoken.TokenWithKey `json:"body"`
+}
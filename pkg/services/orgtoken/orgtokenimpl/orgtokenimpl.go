@@ -0,0 +1,240 @@
+package orgtokenimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/api/routing"
+	apikeygenprefix "github.com/grafana/grafana/pkg/components/apikeygenprefixed"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// maxGracePeriod is the longest time the previous secret of a rotated token is accepted for.
+	maxGracePeriod  = 7 * 24 * time.Hour
+	cleanupInterval = time.Hour
+)
+
+type OrgTokenService struct {
+	cfg           *setting.Cfg
+	store         db.DB
+	routeRegister routing.RouteRegister
+	accessControl accesscontrol.AccessControl
+	usage         *usageTracker
+	log           log.Logger
+	now           func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl, accessControlService accesscontrol.Service) (*OrgTokenService, error) {
+	s := &OrgTokenService{
+		cfg:           cfg,
+		store:         sqlStore,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+		usage:         newUsageTracker(),
+		log:           log.New("org-token"),
+		now:           time.Now,
+	}
+
+	if err := registerRoles(accessControlService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *OrgTokenService) CreateToken(ctx context.Context, cmd orgtoken.CreateTokenCommand) (*orgtoken.TokenWithKey, error) {
+	if cmd.Name == "" {
+		return nil, orgtoken.ErrInvalidName
+	}
+	if !cmd.Role.IsValid() {
+		return nil, fmt.Errorf("%w: %q", orgtoken.ErrInvalidRole, cmd.Role)
+	}
+	if !cmd.CreatorRole.Includes(cmd.Role) {
+		return nil, orgtoken.ErrRoleNotAllowed
+	}
+	scopes, err := validateScopes(cmd.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	token := &orgtoken.OrgToken{
+		UID:       util.GenerateShortUID(),
+		OrgID:     cmd.OrgID,
+		Name:      cmd.Name,
+		Role:      cmd.Role,
+		Scopes:    scopes,
+		CreatedBy: cmd.CreatedBy,
+		Created:   now,
+	}
+	if cmd.ExpiresIn != "" {
+		lifetime, err := gtime.ParseDuration(cmd.ExpiresIn)
+		if err != nil || lifetime <= 0 {
+			return nil, fmt.Errorf("%w: %q", orgtoken.ErrInvalidLifetime, cmd.ExpiresIn)
+		}
+		expires := now.Add(lifetime)
+		token.Expires = &expires
+	}
+
+	key, err := apikeygenprefix.New(orgtoken.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	token.KeyHash = key.HashedKey
+
+	if err := s.insertToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Organization token created", "uid", token.UID, "orgId", token.OrgID, "name", token.Name,
+		"role", token.Role, "scopes", token.Scopes, "createdBy", token.CreatedBy)
+
+	return &orgtoken.TokenWithKey{OrgToken: *token, Key: key.ClientSecret}, nil
+}
+
+func (s *OrgTokenService) GetTokens(ctx context.Context, orgID int64) ([]*orgtoken.OrgToken, error) {
+	return s.getTokens(ctx, orgID)
+}
+
+func (s *OrgTokenService) RotateToken(ctx context.Context, cmd orgtoken.RotateTokenCommand) (*orgtoken.TokenWithKey, error) {
+	var gracePeriod time.Duration
+	if cmd.GracePeriod != "" {
+		var err error
+		gracePeriod, err = gtime.ParseDuration(cmd.GracePeriod)
+		if err != nil || gracePeriod < 0 || gracePeriod > maxGracePeriod {
+			return nil, fmt.Errorf("%w: %q, it must be a duration of at most %s", orgtoken.ErrInvalidGracePeriod, cmd.GracePeriod, maxGracePeriod)
+		}
+	}
+
+	key, err := apikeygenprefix.New(orgtoken.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rotated *orgtoken.OrgToken
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		token, err := getToken(sess, cmd.OrgID, cmd.UID)
+		if err != nil {
+			return err
+		}
+
+		now := s.now()
+		token.PreviousKeyHash = ""
+		token.PreviousKeyExpires = nil
+		if gracePeriod > 0 {
+			previousExpires := now.Add(gracePeriod)
+			token.PreviousKeyHash = token.KeyHash
+			token.PreviousKeyExpires = &previousExpires
+		}
+		token.KeyHash = key.HashedKey
+		token.Rotated = &now
+
+		if _, err := sess.ID(token.ID).Cols("key_hash", "previous_key_hash", "previous_key_expires", "rotated").Update(token); err != nil {
+			return err
+		}
+		rotated = token
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Organization token rotated", "uid", rotated.UID, "orgId", rotated.OrgID, "gracePeriod", gracePeriod)
+
+	return &orgtoken.TokenWithKey{OrgToken: *rotated, Key: key.ClientSecret}, nil
+}
+
+func (s *OrgTokenService) DeleteToken(ctx context.Context, orgID int64, uid string) error {
+	if err := s.deleteToken(ctx, orgID, uid); err != nil {
+		return err
+	}
+	s.log.Info("Organization token deleted", "uid", uid, "orgId", orgID)
+	return nil
+}
+
+func (s *OrgTokenService) Authenticate(ctx context.Context, key string, remoteAddr string) (*orgtoken.OrgToken, error) {
+	decoded, err := apikeygenprefix.Decode(key)
+	if err != nil || decoded.ServiceID != orgtoken.ServiceID {
+		return nil, orgtoken.ErrTokenInvalid
+	}
+	hash, err := decoded.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	token, err := s.getTokenByHash(ctx, hash, now)
+	if err != nil {
+		if errors.Is(err, orgtoken.ErrTokenNotFound) {
+			return nil, orgtoken.ErrTokenInvalid
+		}
+		return nil, err
+	}
+	if token.Expires != nil && !now.Before(*token.Expires) {
+		return nil, orgtoken.ErrTokenExpired
+	}
+
+	s.usage.record(token.ID, now, remoteAddr)
+	return token, nil
+}
+
+// Run writes the use of the tokens to the database, and removes the expired previous keys of
+// rotated tokens.
+func (s *OrgTokenService) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(usageFlushInterval)
+	defer flushTicker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the context is done, but the use recorded since the last flush is still written
+			flushCtx, cancel := context.WithTimeout(context.Background(), usageFlushTimeout)
+			s.flushUsage(flushCtx)
+			cancel()
+			return ctx.Err()
+		case <-flushTicker.C:
+			s.flushUsage(ctx)
+		case <-cleanupTicker.C:
+			if err := s.clearExpiredPreviousKeys(ctx, s.now()); err != nil {
+				s.log.Error("Failed to clear the previous keys of rotated organization tokens", "error", err)
+			}
+		}
+	}
+}
+
+func (s *OrgTokenService) flushUsage(ctx context.Context) {
+	for tokenID, u := range s.usage.take() {
+		if err := s.updateUsage(ctx, tokenID, u); err != nil {
+			s.log.Warn("Failed to record the use of an organization token", "id", tokenID, "error", err)
+		}
+	}
+}
+
+// validateScopes checks that the scopes are RBAC scopes, and removes duplicates.
+func validateScopes(scopes []string) ([]string, error) {
+	valid := make([]string, 0, len(scopes))
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		if scope == "" || !accesscontrol.ValidateScope(scope) {
+			return nil, fmt.Errorf("%w: %q", orgtoken.ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			valid = append(valid, scope)
+		}
+	}
+	return valid, nil
+}
@@ -0,0 +1,111 @@
+package orgtokenimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationOrgToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	s, err := ProvideService(setting.NewCfg(), sqlStore, routing.NewRouteRegister(), actest.FakeAccessControl{}, actest.FakeService{})
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+
+	created, err := s.CreateToken(ctx, orgtoken.CreateTokenCommand{
+		OrgID:       1,
+		Name:        "provisioning",
+		Role:        org.RoleEditor,
+		Scopes:      []string{"folders:uid:infra", "folders:uid:infra"},
+		ExpiresIn:   "30d",
+		CreatedBy:   2,
+		CreatorRole: org.RoleAdmin,
+	})
+	require.NoError(t, err)
+	assert.True(t, len(created.Key) > len(orgtoken.KeyPrefix))
+	assert.Equal(t, orgtoken.KeyPrefix, created.Key[:len(orgtoken.KeyPrefix)])
+	assert.Equal(t, []string{"folders:uid:infra"}, created.Scopes)
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		_, err := s.CreateToken(ctx, orgtoken.CreateTokenCommand{OrgID: 1, Name: "provisioning", Role: org.RoleViewer, CreatorRole: org.RoleAdmin})
+		assert.ErrorIs(t, err, orgtoken.ErrTokenNameTaken)
+		_, err = s.CreateToken(ctx, orgtoken.CreateTokenCommand{OrgID: 1, Name: "other", Role: "Owner", CreatorRole: org.RoleAdmin})
+		assert.ErrorIs(t, err, orgtoken.ErrInvalidRole)
+		_, err = s.CreateToken(ctx, orgtoken.CreateTokenCommand{OrgID: 1, Name: "other", Role: org.RoleViewer, CreatorRole: org.RoleAdmin, Scopes: []string{"folders:*:infra"}})
+		assert.ErrorIs(t, err, orgtoken.ErrInvalidScope)
+		_, err = s.CreateToken(ctx, orgtoken.CreateTokenCommand{OrgID: 1, Name: "other", Role: org.RoleViewer, CreatorRole: org.RoleAdmin, ExpiresIn: "soon"})
+		assert.ErrorIs(t, err, orgtoken.ErrInvalidLifetime)
+		_, err = s.CreateToken(ctx, orgtoken.CreateTokenCommand{OrgID: 1, Name: "other", Role: org.RoleAdmin, CreatorRole: org.RoleEditor})
+		assert.ErrorIs(t, err, orgtoken.ErrRoleNotAllowed)
+	})
+
+	t.Run("authenticates the key and records its use", func(t *testing.T) {
+		token, err := s.Authenticate(ctx, created.Key, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, created.UID, token.UID)
+		_, err = s.Authenticate(ctx, created.Key, "10.0.0.2")
+		require.NoError(t, err)
+
+		_, err = s.Authenticate(ctx, orgtoken.KeyPrefix+"invalid", "10.0.0.1")
+		assert.ErrorIs(t, err, orgtoken.ErrTokenInvalid)
+
+		s.flushUsage(ctx)
+		tokens, err := s.GetTokens(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, int64(2), tokens[0].UseCount)
+		assert.Equal(t, "10.0.0.2", tokens[0].LastUsedFrom)
+	})
+
+	t.Run("accepts the previous key during the grace period", func(t *testing.T) {
+		_, err := s.RotateToken(ctx, orgtoken.RotateTokenCommand{OrgID: 1, UID: created.UID, GracePeriod: "8d"})
+		assert.ErrorIs(t, err, orgtoken.ErrInvalidGracePeriod)
+
+		rotated, err := s.RotateToken(ctx, orgtoken.RotateTokenCommand{OrgID: 1, UID: created.UID, GracePeriod: "1h"})
+		require.NoError(t, err)
+		assert.NotEqual(t, created.Key, rotated.Key)
+
+		_, err = s.Authenticate(ctx, rotated.Key, "10.0.0.1")
+		require.NoError(t, err)
+		_, err = s.Authenticate(ctx, created.Key, "10.0.0.1")
+		require.NoError(t, err)
+
+		now = now.Add(time.Hour)
+		_, err = s.Authenticate(ctx, created.Key, "10.0.0.1")
+		assert.ErrorIs(t, err, orgtoken.ErrTokenInvalid)
+		require.NoError(t, s.clearExpiredPreviousKeys(ctx, now))
+		_, err = s.Authenticate(ctx, rotated.Key, "10.0.0.1")
+		require.NoError(t, err)
+
+		_, err = s.RotateToken(ctx, orgtoken.RotateTokenCommand{OrgID: 2, UID: created.UID})
+		assert.ErrorIs(t, err, orgtoken.ErrTokenNotFound)
+
+		now = now.Add(30 * 24 * time.Hour)
+		_, err = s.Authenticate(ctx, rotated.Key, "10.0.0.1")
+		assert.ErrorIs(t, err, orgtoken.ErrTokenExpired)
+	})
+
+	t.Run("deletes the token", func(t *testing.T) {
+		assert.ErrorIs(t, s.DeleteToken(ctx, 2, created.UID), orgtoken.ErrTokenNotFound)
+		require.NoError(t, s.DeleteToken(ctx, 1, created.UID))
+		tokens, err := s.GetTokens(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
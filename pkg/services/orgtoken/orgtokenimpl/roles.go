@@ -0,0 +1,37 @@
+package orgtokenimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+)
+
+func registerRoles(service accesscontrol.Service) error {
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:org.tokens:reader",
+			DisplayName: "Organization tokens reader",
+			Description: "Read the API tokens of the organization and their use.",
+			Group:       "Organizations",
+			Permissions: []accesscontrol.Permission{
+				{Action: orgtoken.ActionRead},
+			},
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	writer := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:org.tokens:writer",
+			DisplayName: "Organization tokens writer",
+			Description: "Create, rotate, delete and read the API tokens of the organization.",
+			Group:       "Organizations",
+			Permissions: accesscontrol.ConcatPermissions(reader.Role.Permissions, []accesscontrol.Permission{
+				{Action: orgtoken.ActionWrite},
+			}),
+		},
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(reader, writer)
+}
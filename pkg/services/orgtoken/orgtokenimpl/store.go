@@ -0,0 +1,89 @@
+package orgtokenimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+)
+
+func (s *OrgTokenService) insertToken(ctx context.Context, token *orgtoken.OrgToken) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(token); err != nil {
+			if s.store.GetDialect().IsUniqueConstraintViolation(err) {
+				return orgtoken.ErrTokenNameTaken
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func getToken(sess *db.Session, orgID int64, uid string) (*orgtoken.OrgToken, error) {
+	var token orgtoken.OrgToken
+	exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&token)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, orgtoken.ErrTokenNotFound
+	}
+	return &token, nil
+}
+
+// getTokenByHash returns the token of a key hash, which is either its current key or its previous
+// key until the previous key expires.
+func (s *OrgTokenService) getTokenByHash(ctx context.Context, hash string, now time.Time) (*orgtoken.OrgToken, error) {
+	var token orgtoken.OrgToken
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("key_hash = ? OR (previous_key_hash = ? AND previous_key_expires > ?)", hash, hash, now).Get(&token)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return orgtoken.ErrTokenNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *OrgTokenService) getTokens(ctx context.Context, orgID int64) ([]*orgtoken.OrgToken, error) {
+	tokens := make([]*orgtoken.OrgToken, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&tokens)
+	})
+	return tokens, err
+}
+
+func (s *OrgTokenService) deleteToken(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&orgtoken.OrgToken{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return orgtoken.ErrTokenNotFound
+		}
+		return nil
+	})
+}
+
+func (s *OrgTokenService) updateUsage(ctx context.Context, tokenID int64, u usage) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE org_token SET use_count = use_count + ?, last_used_at = ?, last_used_from = ? WHERE id = ?",
+			u.count, u.lastUsedAt, u.lastUsedFrom, tokenID)
+		return err
+	})
+}
+
+func (s *OrgTokenService) clearExpiredPreviousKeys(ctx context.Context, now time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE org_token SET previous_key_hash = '', previous_key_expires = NULL WHERE previous_key_expires <= ?", now)
+		return err
+	})
+}
@@ -0,0 +1,52 @@
+package orgtokenimpl
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// usageFlushInterval is how often the use of the tokens is written to the database, so that
+	// requests don't write to the database.
+	usageFlushInterval = 30 * time.Second
+	usageFlushTimeout  = 5 * time.Second
+)
+
+// usage is the use of a token since the last flush.
+type usage struct {
+	count        int64
+	lastUsedAt   time.Time
+	lastUsedFrom string
+}
+
+type usageTracker struct {
+	mu      sync.Mutex
+	byToken map[int64]usage
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{byToken: map[int64]usage{}}
+}
+
+func (t *usageTracker) record(tokenID int64, at time.Time, from string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.byToken[tokenID]
+	u.count++
+	if !at.Before(u.lastUsedAt) {
+		u.lastUsedAt = at
+		u.lastUsedFrom = from
+	}
+	t.byToken[tokenID] = u
+}
+
+// take returns the recorded use and resets it.
+func (t *usageTracker) take() map[int64]usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	taken := t.byToken
+	t.byToken = map[int64]usage{}
+	return taken
+}
@@ -0,0 +1,40 @@
+package orgtokentest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/orgtoken"
+)
+
+type FakeService struct {
+	ExpectedError  error
+	ExpectedToken  *orgtoken.OrgToken
+	ExpectedTokens []*orgtoken.OrgToken
+	ExpectedKey    string
+}
+
+func (s *FakeService) CreateToken(ctx context.Context, cmd orgtoken.CreateTokenCommand) (*orgtoken.TokenWithKey, error) {
+	if s.ExpectedError != nil {
+		return nil, s.ExpectedError
+	}
+	return &orgtoken.TokenWithKey{OrgToken: *s.ExpectedToken, Key: s.ExpectedKey}, nil
+}
+
+func (s *FakeService) GetTokens(ctx context.Context, orgID int64) ([]*orgtoken.OrgToken, error) {
+	return s.ExpectedTokens, s.ExpectedError
+}
+
+func (s *FakeService) RotateToken(ctx context.Context, cmd orgtoken.RotateTokenCommand) (*orgtoken.TokenWithKey, error) {
+	if s.ExpectedError != nil {
+		return nil, s.ExpectedError
+	}
+	return &orgtoken.TokenWithKey{OrgToken: *s.ExpectedToken, Key: s.ExpectedKey}, nil
+}
+
+func (s *FakeService) DeleteToken(ctx context.Context, orgID int64, uid string) error {
+	return s.ExpectedError
+}
+
+func (s *FakeService) Authenticate(ctx context.Context, key string, remoteAddr string) (*orgtoken.OrgToken, error) {
+	return s.ExpectedToken, s.ExpectedError
+}
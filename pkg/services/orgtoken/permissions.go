@@ -0,0 +1,97 @@
+package orgtoken
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type tokenKey struct{}
+
+// ContextWithToken returns a context of a request made with the token.
+func ContextWithToken(ctx context.Context, token *OrgToken) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// FromContext returns the token a request was made with, if any.
+func FromContext(ctx context.Context) (*OrgToken, bool) {
+	token, ok := ctx.Value(tokenKey{}).(*OrgToken)
+	return token, ok
+}
+
+// SignedInUser returns the identity of the requests made with a token. It isn't a user, so its
+// user ID is 0, and it only belongs to the organization of the token.
+func SignedInUser(token *OrgToken) *user.SignedInUser {
+	return &user.SignedInUser{
+		OrgID:   token.OrgID,
+		OrgRole: token.Role,
+		Login:   "org-token:" + token.Name,
+		Name:    token.Name,
+	}
+}
+
+// RestrictPermissions restricts the RBAC permissions of the requests made with a token to its
+// scopes. It must run after the permissions of the request are loaded.
+func RestrictPermissions(c *models.ReqContext) {
+	token, ok := FromContext(c.Req.Context())
+	if !ok || len(token.Scopes) == 0 || c.SignedInUser.Permissions == nil {
+		return
+	}
+	c.SignedInUser.Permissions[c.OrgID] = restrict(c.SignedInUser.Permissions[c.OrgID], token.Scopes)
+}
+
+// restrict keeps the permissions within the scopes. Permissions without scope are kept, and
+// wildcard scopes are narrowed down to the scopes they cover, so that an editor role restricted to
+// a folder can edit the dashboards of the folder only.
+func restrict(permissions map[string][]string, scopes []string) map[string][]string {
+	restricted := make(map[string][]string, len(permissions))
+	for action, actionScopes := range permissions {
+		seen := map[string]bool{}
+		kept := make([]string, 0, len(actionScopes))
+		keep := func(scope string) {
+			if !seen[scope] {
+				seen[scope] = true
+				kept = append(kept, scope)
+			}
+		}
+
+		for _, scope := range actionScopes {
+			if scope == "" {
+				keep(scope)
+				continue
+			}
+			for _, allowed := range scopes {
+				switch {
+				case covers(action, allowed, scope):
+					keep(scope)
+				case covers(action, scope, allowed):
+					keep(allowed)
+				}
+			}
+		}
+		if len(kept) > 0 {
+			restricted[action] = kept
+		}
+	}
+	return restricted
+}
+
+// covers returns whether the scope includes the target scope for the action. For dashboard actions,
+// the dashboard wildcards include the folder scopes, since the permissions on dashboards can be
+// granted on their folders. For other actions, such as deleting folders, they don't.
+func covers(action, scope, target string) bool {
+	if scope == target {
+		return true
+	}
+	if !strings.HasSuffix(scope, "*") {
+		return false
+	}
+	prefix := strings.TrimSuffix(scope, "*")
+	if strings.HasPrefix(target, prefix) {
+		return true
+	}
+	return strings.HasPrefix(action, "dashboards:") &&
+		strings.HasPrefix("dashboards:uid:", prefix) && strings.HasPrefix(target, "folders:")
+}
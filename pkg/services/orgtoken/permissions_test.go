@@ -0,0 +1,52 @@
+package orgtoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrict(t *testing.T) {
+	permissions := map[string][]string{
+		"dashboards:read":  {"dashboards:*", "folders:*"},
+		"dashboards:write": {"dashboards:uid:other"},
+		"datasources:read": {"datasources:*"},
+		"alert.rules:read": {"folders:uid:infra"},
+		"users:read":       {""},
+	}
+
+	restricted := restrict(permissions, []string{"folders:uid:infra", "dashboards:uid:home"})
+
+	assert.Equal(t, map[string][]string{
+		"dashboards:read":  {"folders:uid:infra", "dashboards:uid:home"},
+		"alert.rules:read": {"folders:uid:infra"},
+		"users:read":       {""},
+	}, restricted)
+}
+
+func TestRestrict_FolderActions(t *testing.T) {
+	permissions := map[string][]string{
+		"dashboards:read":           {"folders:*"},
+		"folders:read":              {"folders:*"},
+		"folders:write":             {"folders:*"},
+		"folders:delete":            {"folders:*"},
+		"folders.permissions:write": {"folders:*"},
+	}
+
+	for _, scope := range []string{"dashboards:*", "dashboards:uid:*"} {
+		assert.Equal(t, map[string][]string{
+			"dashboards:read": {"folders:*"},
+		}, restrict(permissions, []string{scope}), scope)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	assert.True(t, covers("folders:read", "folders:uid:infra", "folders:uid:infra"))
+	assert.True(t, covers("folders:read", "folders:*", "folders:uid:infra"))
+	assert.True(t, covers("dashboards:read", "dashboards:*", "folders:uid:infra"))
+	assert.True(t, covers("datasources:read", "*", "datasources:uid:prom"))
+	assert.False(t, covers("folders:read", "folders:uid:infra", "folders:*"))
+	assert.False(t, covers("folders:read", "datasources:*", "folders:uid:infra"))
+	assert.False(t, covers("folders:delete", "dashboards:*", "folders:uid:infra"))
+	assert.False(t, covers("folders:write", "dashboards:uid:*", "folders:*"))
+}
@@ -0,0 +1,175 @@
+package panelexport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	csvMimeType  = "text/csv; charset=utf-8"
+	xlsxMimeType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.AccessControl)
+
+	s.RouteRegister.Post("/api/dashboards/uid/:uid/panels/:panelId/export", middleware.ReqSignedIn,
+		authorize(middleware.ReqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead)), routing.Wrap(s.exportHandler))
+}
+
+// swagger:route POST /dashboards/uid/{uid}/panels/{panelId}/export dashboards exportPanelData
+//
+// Export the data of a dashboard panel as CSV or XLSX.
+//
+// Runs the queries of the panel with the permissions of the signed in user, applies the
+// transformations of the panel and streams the result as a file. CSV exports of panels with
+// several data frames are joined by time, XLSX exports contain a worksheet per data frame.
+// Template variables in the queries are not interpolated.
+//
+// Produces:
+// - text/csv
+// - application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//
+// Responses:
+// 200: contentResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) exportHandler(c *models.ReqContext) response.Response {
+	cmd := ExportPanelCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	panelID, err := strconv.ParseInt(web.Params(c.Req)[":panelId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "panelId is invalid", err)
+	}
+	cmd.DashboardUID = web.Params(c.Req)[":uid"]
+	cmd.PanelID = panelID
+	cmd.OrgID = c.OrgID
+	if cmd.Format == "" {
+		cmd.Format = FormatCSV
+	}
+	if cmd.Format != FormatCSV && cmd.Format != FormatXLSX {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Unsupported format %q, use csv or xlsx", cmd.Format), nil)
+	}
+
+	export, err := s.ExportPanel(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return s.errorResponse(err)
+	}
+
+	if cmd.Format == FormatXLSX {
+		return &fileResponse{
+			fileName: FileName(export.Panel, ".xlsx"),
+			mimeType: xlsxMimeType,
+			write: func(w io.Writer) error {
+				return WriteXLSX(w, export.Frames, export.Location)
+			},
+		}
+	}
+
+	frame, err := singleFrame(export.Frames)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "The panel data cannot be exported as a single CSV file, export it as xlsx or add a join transformation", err)
+	}
+	return &fileResponse{
+		fileName: FileName(export.Panel, ".csv"),
+		mimeType: csvMimeType,
+		write: func(w io.Writer) error {
+			return WriteCSV(w, frame, export.Location)
+		},
+	}
+}
+
+func (s *Service) errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard not found", err)
+	case errors.Is(err, ErrPanelNotFound):
+		return response.Error(http.StatusNotFound, "Panel not found", err)
+	case errors.Is(err, ErrDashboardAccessDenied):
+		return response.Error(http.StatusForbidden, "Access denied to this dashboard", err)
+	case errors.Is(err, datasources.ErrDataSourceAccessDenied):
+		return response.Error(http.StatusForbidden, "Access denied to data source", err)
+	case errors.Is(err, datasources.ErrDataSourceNotFound):
+		return response.Error(http.StatusNotFound, "Data source not found", err)
+	case errors.Is(err, ErrInvalidExport), errors.Is(err, ErrQueryFailed),
+		errors.Is(err, ErrUnsupportedTransformation), errors.Is(err, ErrInvalidTransformation):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, "Failed to export panel data", err)
+}
+
+// singleFrame returns the only frame, or joins several frames by their time field.
+func singleFrame(frames data.Frames) (*data.Frame, error) {
+	switch len(frames) {
+	case 0:
+		return data.NewFrame(""), nil
+	case 1:
+		return frames[0], nil
+	}
+	return joinFrames(frames, "", false)
+}
+
+// fileResponse streams a file to the client as it is written.
+type fileResponse struct {
+	fileName string
+	mimeType string
+	write    func(w io.Writer) error
+}
+
+func (r *fileResponse) Status() int {
+	return http.StatusOK
+}
+
+func (r *fileResponse) Body() []byte {
+	return nil
+}
+
+func (r *fileResponse) WriteTo(ctx *models.ReqContext) {
+	header := ctx.Resp.Header()
+	header.Set("Content-Type", r.mimeType)
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": r.fileName}))
+	header.Set("Cache-Control", "no-store")
+	ctx.Resp.WriteHeader(http.StatusOK)
+
+	start := time.Now()
+	if err := r.write(ctx.Resp); err != nil {
+		// the status has been sent already, the client sees a truncated file
+		ctx.Logger.Error("Failed to write panel export", "file", r.fileName, "error", err)
+		return
+	}
+	ctx.Logger.Debug("Panel data exported", "file", r.fileName, "duration", time.Since(start))
+}
+
+// swagger:parameters exportPanelData
+type ExportPanelDataParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:path
+	// required:true
+	PanelID int64 `json:"panelId"`
+	// in:body
+	// required:true
+	Body ExportPanelCommand `json:"body"`
+}
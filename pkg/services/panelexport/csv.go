@@ -0,0 +1,86 @@
+package panelexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// WriteCSV writes a data frame as CSV with a column per field. Times are formatted in the time zone.
+func WriteCSV(out io.Writer, frame *data.Frame, loc *time.Location) error {
+	w := csv.NewWriter(out)
+
+	header := make([]string, len(frame.Fields))
+	for i, f := range frame.Fields {
+		header[i] = escapeFormula(displayName(f))
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	rows, err := frame.RowLen()
+	if err != nil {
+		return err
+	}
+	record := make([]string, len(frame.Fields))
+	for row := 0; row < rows; row++ {
+		for i, f := range frame.Fields {
+			v, ok := f.ConcreteAt(row)
+			if !ok {
+				record[i] = ""
+				continue
+			}
+			record[i] = formatValue(v, loc)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func formatValue(v interface{}, loc *time.Location) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.In(loc).Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case string:
+		return escapeFormula(v)
+	case json.RawMessage:
+		return escapeFormula(string(v))
+	}
+	return fmt.Sprint(v)
+}
+
+// escapeFormula keeps spreadsheet applications from evaluating text values as formulas.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// FileName returns a file name for the data of a panel, safe to use in a Content-Disposition header.
+func FileName(p Panel, suffix string) string {
+	title := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '"' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(p.Title))
+	if title == "" {
+		title = "panel"
+	}
+	return fmt.Sprintf("%s-%d%s", title, p.ID, suffix)
+}
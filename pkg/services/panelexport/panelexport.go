@@ -0,0 +1,166 @@
+package panelexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var (
+	ErrPanelNotFound             = errors.New("panel not found")
+	ErrDashboardAccessDenied     = errors.New("access to the dashboard denied")
+	ErrInvalidExport             = errors.New("invalid export")
+	ErrQueryFailed               = errors.New("query failed")
+	ErrUnsupportedTransformation = errors.New("transformation cannot be applied server-side")
+	ErrInvalidTransformation     = errors.New("transformation failed")
+)
+
+// queryDataService runs the queries of the exported panels.
+type queryDataService interface {
+	QueryData(ctx context.Context, user *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error)
+}
+
+// Service exports the data of dashboard panels. The queries of a panel are run with the
+// permissions of the user and the transformations of the panel are applied server-side.
+type Service struct {
+	RouteRegister    routing.RouteRegister
+	AccessControl    accesscontrol.AccessControl
+	log              log.Logger
+	queryService     queryDataService
+	dashboardService dashboards.DashboardService
+}
+
+func ProvideService(routeRegister routing.RouteRegister, ac accesscontrol.AccessControl, queryService *query.Service,
+	dashboardService dashboards.DashboardService) *Service {
+	s := &Service{
+		RouteRegister:    routeRegister,
+		AccessControl:    ac,
+		log:              log.New("panel-export"),
+		queryService:     queryService,
+		dashboardService: dashboardService,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// ExportPanelCommand are the options of a panel export.
+type ExportPanelCommand struct {
+	DashboardUID string `json:"-"`
+	PanelID      int64  `json:"-"`
+	OrgID        int64  `json:"-"`
+
+	// The file format, either csv or xlsx. Defaults to csv.
+	Format string `json:"format"`
+	// The start of the time range, absolute or relative, e.g. now-6h. Defaults to the time range of the dashboard.
+	From string `json:"from"`
+	// The end of the time range. Defaults to the time range of the dashboard.
+	To string `json:"to"`
+	// The IANA time zone times are exported in. Defaults to the time zone of the dashboard, or UTC.
+	Timezone      string `json:"timezone"`
+	MaxDataPoints int64  `json:"maxDataPoints"`
+	// Export the data returned by the queries without applying the transformations of the panel.
+	SkipTransformations bool `json:"skipTransformations"`
+}
+
+// Export is the data of an exported panel.
+type Export struct {
+	Panel    Panel
+	Frames   data.Frames
+	Location *time.Location
+}
+
+// ExportPanel queries a panel of a dashboard the user can view and applies its transformations.
+func (s *Service) ExportPanel(ctx context.Context, signedInUser *user.SignedInUser, cmd ExportPanelCommand) (*Export, error) {
+	if cmd.MaxDataPoints < 0 || cmd.MaxDataPoints > MaxDataPointsLimit {
+		return nil, fmt.Errorf("%w: maxDataPoints must be between 1 and %d", ErrInvalidExport, MaxDataPointsLimit)
+	}
+
+	query := models.GetDashboardQuery{Uid: cmd.DashboardUID, OrgId: cmd.OrgID}
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		return nil, err
+	}
+	canView, err := guardian.New(ctx, query.Result.Id, cmd.OrgID, signedInUser).CanView()
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, ErrDashboardAccessDenied
+	}
+
+	var panel *Panel
+	for _, p := range DashboardPanels(query.Result.Data) {
+		if p.ID == cmd.PanelID {
+			p := p
+			panel = &p
+			break
+		}
+	}
+	if panel == nil {
+		return nil, ErrPanelNotFound
+	}
+
+	location, err := exportLocation(cmd.Timezone, query.Result.Data.Get("timezone").MustString())
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := cmd.From, cmd.To
+	if from == "" {
+		from = query.Result.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if to == "" {
+		to = query.Result.Data.Get("time").Get("to").MustString("now")
+	}
+
+	frames, err := s.QueryPanel(ctx, signedInUser, *panel, QueryOptions{
+		From:                from,
+		To:                  to,
+		MaxDataPoints:       cmd.MaxDataPoints,
+		SkipTransformations: cmd.SkipTransformations,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Export{Panel: *panel, Frames: frames, Location: location}, nil
+}
+
+// exportLocation returns the time zone of the request, falling back to the time zone of the
+// dashboard. The dashboard settings browser and utc are exported as UTC.
+func exportLocation(timezone, dashboardTimezone string) (*time.Location, error) {
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidExport, timezone)
+		}
+		return location, nil
+	}
+	switch dashboardTimezone {
+	case "", "browser", "utc":
+		return time.UTC, nil
+	}
+	if location, err := time.LoadLocation(dashboardTimezone); err == nil {
+		return location, nil
+	}
+	return time.UTC, nil
+}
@@ -0,0 +1,304 @@
+package panelexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestDashboardPanels(t *testing.T) {
+	dashboard := simplejson.MustJson([]byte(`{
+		"panels": [
+			{"id": 1, "title": "Requests", "type": "timeseries",
+				"datasource": {"uid": "prom"},
+				"targets": [{"refId": "A"}, {"refId": "B", "datasource": {"uid": "loki"}}, {"refId": "C", "hide": true}],
+				"transformations": [{"id": "organize", "options": {"excludeByName": {"Time": true}}}, {"id": "limit", "disabled": true}]},
+			{"id": 2, "title": "Notes", "type": "text"},
+			{"id": 3, "title": "Details", "type": "row", "collapsed": true,
+				"panels": [{"id": 4, "title": "Errors", "targets": [{"refId": "A", "datasource": {"uid": "prom"}}]}]}
+		]
+	}`))
+
+	panels := DashboardPanels(dashboard)
+	require.Len(t, panels, 2)
+
+	assert.Equal(t, int64(1), panels[0].ID)
+	require.Len(t, panels[0].Targets, 2)
+	assert.Equal(t, "prom", panels[0].Targets[0].Get("datasource").Get("uid").MustString())
+	assert.Equal(t, "loki", panels[0].Targets[1].Get("datasource").Get("uid").MustString())
+	require.Len(t, panels[0].Transformations, 2)
+	assert.Equal(t, "organize", panels[0].Transformations[0].ID)
+	assert.True(t, panels[0].Transformations[1].Disabled)
+
+	assert.Equal(t, int64(4), panels[1].ID)
+	assert.Equal(t, "Errors", panels[1].Title)
+	assert.Empty(t, panels[1].Transformations)
+}
+
+type fakeQueryService struct {
+	request  dtos.MetricRequest
+	response *backend.QueryDataResponse
+	err      error
+}
+
+func (f *fakeQueryService) QueryData(_ context.Context, _ *user.SignedInUser, _ bool, req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	f.request = req
+	return f.response, f.err
+}
+
+func TestQueryPanel(t *testing.T) {
+	queryService := &fakeQueryService{response: &backend.QueryDataResponse{Responses: backend.Responses{
+		"B": {Frames: data.Frames{data.NewFrame("b", data.NewField("value", nil, []float64{2}))}},
+		"A": {Frames: data.Frames{data.NewFrame("a", data.NewField("value", nil, []float64{1}))}},
+	}}}
+	s := &Service{queryService: queryService}
+	p := Panel{
+		ID:      1,
+		Targets: []*simplejson.Json{simplejson.MustJson([]byte(`{"refId": "A"}`)), simplejson.MustJson([]byte(`{"refId": "B"}`))},
+		Transformations: []Transformation{
+			{ID: "filterByRefId", Options: simplejson.MustJson([]byte(`{"include": "B"}`))},
+		},
+	}
+
+	t.Run("applies the transformations of the panel", func(t *testing.T) {
+		frames, err := s.QueryPanel(context.Background(), &user.SignedInUser{}, p, QueryOptions{From: "1668600000000", To: "1668603600000"})
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, "b", frames[0].Name)
+
+		require.Len(t, queryService.request.Queries, 2)
+		assert.Equal(t, int64(DefaultMaxDataPoints), queryService.request.Queries[0].Get("maxDataPoints").MustInt64())
+		assert.Equal(t, int64(3600), queryService.request.Queries[0].Get("intervalMs").MustInt64())
+		_, changed := p.Targets[0].CheckGet("maxDataPoints")
+		assert.False(t, changed)
+	})
+
+	t.Run("returns the frames ordered by ref ID without transformations", func(t *testing.T) {
+		frames, err := s.QueryPanel(context.Background(), &user.SignedInUser{}, p, QueryOptions{From: "now-1h", To: "now", SkipTransformations: true})
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		assert.Equal(t, "A", frames[0].RefID)
+		assert.Equal(t, "B", frames[1].RefID)
+	})
+
+	t.Run("fails if a query fails", func(t *testing.T) {
+		queryService.response.Responses["C"] = backend.DataResponse{Error: errors.New("boom")}
+		_, err := s.QueryPanel(context.Background(), &user.SignedInUser{}, p, QueryOptions{From: "now-1h", To: "now"})
+		require.ErrorIs(t, err, ErrQueryFailed)
+	})
+
+	t.Run("fails for unsupported transformations", func(t *testing.T) {
+		delete(queryService.response.Responses, "C")
+		p := p
+		p.Transformations = []Transformation{{ID: "calculateField"}}
+		_, err := s.QueryPanel(context.Background(), &user.SignedInUser{}, p, QueryOptions{From: "now-1h", To: "now"})
+		require.ErrorIs(t, err, ErrUnsupportedTransformation)
+	})
+}
+
+func testFrames() data.Frames {
+	start := time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC)
+	return data.Frames{
+		data.NewFrame("cpu",
+			data.NewField("Time", nil, []time.Time{start.Add(time.Minute), start}),
+			data.NewField("Value", data.Labels{"host": "a"}, []float64{2, 1}),
+		),
+		data.NewFrame("mem",
+			data.NewField("Time", nil, []time.Time{start, start.Add(2 * time.Minute)}),
+			data.NewField("Value", data.Labels{"host": "a"}, []float64{10, 30}),
+		),
+	}
+}
+
+func transform(t *testing.T, frames data.Frames, id string, options string) data.Frames {
+	t.Helper()
+	frames, err := applyTransformations(frames, []Transformation{{ID: id, Options: simplejson.MustJson([]byte(options))}})
+	require.NoError(t, err)
+	return frames
+}
+
+func TestTransformations(t *testing.T) {
+	t.Run("organize", func(t *testing.T) {
+		frames := transform(t, testFrames(), "organize", `{
+			"excludeByName": {"Time": true},
+			"renameByName": {"Value {host=a}": "CPU"}
+		}`)
+		require.Len(t, frames[0].Fields, 1)
+		assert.Equal(t, "CPU", displayName(frames[0].Fields[0]))
+	})
+
+	t.Run("filterFieldsByName", func(t *testing.T) {
+		frames := transform(t, testFrames(), "filterFieldsByName", `{"include": {"pattern": "/^value/i"}}`)
+		require.Len(t, frames[1].Fields, 1)
+		assert.Equal(t, "Value", frames[1].Fields[0].Name)
+	})
+
+	t.Run("filterByRefId", func(t *testing.T) {
+		frames := testFrames()
+		frames[0].RefID, frames[1].RefID = "A", "B"
+		frames = transform(t, frames, "filterByRefId", `{"include": "A|C"}`)
+		require.Len(t, frames, 1)
+		assert.Equal(t, "cpu", frames[0].Name)
+	})
+
+	t.Run("renameByRegex", func(t *testing.T) {
+		frames := transform(t, testFrames(), "renameByRegex", `{"regex": "Value \\{host=(.*)\\}", "renamePattern": "host $1"}`)
+		assert.Equal(t, "host a", displayName(frames[0].Fields[1]))
+		assert.Equal(t, "Time", displayName(frames[0].Fields[0]))
+	})
+
+	t.Run("sortBy", func(t *testing.T) {
+		frames := transform(t, testFrames(), "sortBy", `{"sort": [{"field": "Time"}]}`)
+		assert.Equal(t, 1.0, frames[0].Fields[1].At(0))
+		frames = transform(t, testFrames(), "sortBy", `{"sort": [{"field": "Value {host=a}", "desc": true}]}`)
+		assert.Equal(t, 30.0, frames[1].Fields[1].At(0))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		frames := transform(t, testFrames(), "limit", `{"limitField": 1}`)
+		rows, err := frames[0].RowLen()
+		require.NoError(t, err)
+		assert.Equal(t, 1, rows)
+	})
+
+	t.Run("disabled transformations are skipped", func(t *testing.T) {
+		frames, err := applyTransformations(testFrames(), []Transformation{{ID: "calculateField", Disabled: true}})
+		require.NoError(t, err)
+		assert.Len(t, frames, 2)
+	})
+
+	t.Run("invalid options fail", func(t *testing.T) {
+		_, err := applyTransformations(testFrames(), []Transformation{{ID: "renameByRegex", Options: simplejson.MustJson([]byte(`{"regex": "/("}`))}})
+		assert.ErrorIs(t, err, ErrInvalidTransformation)
+	})
+}
+
+func TestJoinFrames(t *testing.T) {
+	t.Run("outer join by time", func(t *testing.T) {
+		frames := transform(t, testFrames(), "joinByField", `{}`)
+		require.Len(t, frames, 1)
+		joined := frames[0]
+		require.Len(t, joined.Fields, 3)
+		assert.Equal(t, "Time", displayName(joined.Fields[0]))
+		assert.Equal(t, "Value {host=a}", displayName(joined.Fields[1]))
+		assert.Equal(t, "mem", displayName(joined.Fields[2]))
+
+		rows, err := joined.RowLen()
+		require.NoError(t, err)
+		require.Equal(t, 3, rows)
+		assert.Equal(t, time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC), joined.Fields[0].At(0))
+		v, ok := joined.Fields[2].ConcreteAt(1)
+		assert.False(t, ok, v)
+		v, ok = joined.Fields[2].ConcreteAt(2)
+		assert.True(t, ok)
+		assert.Equal(t, 30.0, v)
+	})
+
+	t.Run("inner join", func(t *testing.T) {
+		frames := transform(t, testFrames(), "joinByField", `{"mode": "inner"}`)
+		rows, err := frames[0].RowLen()
+		require.NoError(t, err)
+		assert.Equal(t, 1, rows)
+	})
+
+	t.Run("fails without the field to join by", func(t *testing.T) {
+		_, err := joinFrames(data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}, "", false)
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	value := 1.5
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC), time.Date(2022, 11, 16, 12, 1, 0, 0, time.UTC)}),
+		data.NewField("value", data.Labels{"job": "api"}, []*float64{&value, nil}),
+		data.NewField("comment", nil, []string{"=SUM(A1:A2)", "ok, done"}),
+	)
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	var contents bytes.Buffer
+	require.NoError(t, WriteCSV(&contents, frame, loc))
+
+	expected := "time,value {job=api},comment\n" +
+		"2022-11-16T13:00:00+01:00,1.5,'=SUM(A1:A2)\n" +
+		"2022-11-16T13:01:00+01:00,,\"ok, done\"\n"
+	assert.Equal(t, expected, contents.String())
+}
+
+func TestWriteXLSX(t *testing.T) {
+	frames := testFrames()
+	frames[1].Name = "cpu"
+	frames = append(frames, data.NewFrame("", data.NewField("note", nil, []string{"a < b & c"})))
+
+	var contents bytes.Buffer
+	require.NoError(t, WriteXLSX(&contents, frames, time.UTC))
+
+	r, err := zip.NewReader(bytes.NewReader(contents.Bytes()), int64(contents.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(b)
+	}
+
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/styles.xml")
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="cpu" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="cpu (2)" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Sheet" sheetId="3" r:id="rId3"/>`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="B1" s="2" t="inlineStr"><is><t>Value {host=a}</t></is></c>`)
+	// 2022-11-16 12:01 is 44881 days, 12 hours and one minute after the Excel epoch
+	assert.Contains(t, sheet, `<c r="A2" s="1"><v>44881.50069444445</v></c>`)
+	assert.Contains(t, sheet, `<c r="B2"><v>2</v></c>`)
+	assert.Contains(t, files["xl/worksheets/sheet3.xml"], `<t xml:space="preserve">a &lt; b &amp; c</t>`)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
+
+func TestExportLocation(t *testing.T) {
+	loc, err := exportLocation("", "Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	loc, err = exportLocation("America/New_York", "browser")
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", loc.String())
+
+	loc, err = exportLocation("", "browser")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	_, err = exportLocation("Mars/Olympus", "")
+	assert.ErrorIs(t, err, ErrInvalidExport)
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "Errors _ 5m-4.xlsx", FileName(Panel{ID: 4, Title: "Errors / 5m"}, ".xlsx"))
+	assert.Equal(t, "panel-7.csv", FileName(Panel{ID: 7}, ".csv"))
+}
@@ -0,0 +1,144 @@
+package panelexport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+const (
+	DefaultMaxDataPoints = 1000
+	MaxDataPointsLimit   = 100000
+
+	minIntervalMs = 1000
+)
+
+// Panel is a panel of a dashboard with queries.
+type Panel struct {
+	ID              int64
+	Title           string
+	Targets         []*simplejson.Json
+	Transformations []Transformation
+}
+
+// DashboardPanels returns the panels of a dashboard which have queries, including the panels
+// in collapsed rows. The data source of a panel is set on the targets without their own.
+func DashboardPanels(dashboard *simplejson.Json) []Panel {
+	panels := make([]Panel, 0)
+	var collect func(list []interface{})
+	collect = func(list []interface{}) {
+		for i := range list {
+			p := simplejson.NewFromAny(list[i])
+			if nested := p.Get("panels").MustArray(); len(nested) > 0 {
+				collect(nested)
+			}
+
+			targets := p.Get("targets").MustArray()
+			if len(targets) == 0 {
+				continue
+			}
+			result := Panel{
+				ID:              p.Get("id").MustInt64(),
+				Title:           p.Get("title").MustString(),
+				Transformations: parseTransformations(p.Get("transformations")),
+			}
+			ds, hasDS := p.CheckGet("datasource")
+			for j := range targets {
+				target := simplejson.NewFromAny(targets[j])
+				if target.Get("hide").MustBool(false) {
+					continue
+				}
+				if _, ok := target.CheckGet("datasource"); !ok && hasDS {
+					target.Set("datasource", ds.Interface())
+				}
+				result.Targets = append(result.Targets, target)
+			}
+			if len(result.Targets) > 0 {
+				panels = append(panels, result)
+			}
+		}
+	}
+	collect(dashboard.Get("panels").MustArray())
+	return panels
+}
+
+// QueryOptions are the options the queries of a panel are run with.
+type QueryOptions struct {
+	From          string
+	To            string
+	MaxDataPoints int64
+	// SkipTransformations returns the frames as returned by the queries.
+	SkipTransformations bool
+}
+
+// QueryPanel runs the queries of a panel with the permissions of the user and applies the
+// transformations of the panel. The frames are ordered by the ref ID of the queries.
+func (s *Service) QueryPanel(ctx context.Context, signedInUser *user.SignedInUser, p Panel, opts QueryOptions) (data.Frames, error) {
+	maxDataPoints := opts.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = DefaultMaxDataPoints
+	}
+	timeRange := legacydata.NewDataTimeRange(opts.From, opts.To)
+	intervalMs := (timeRange.GetToAsMsEpoch() - timeRange.GetFromAsMsEpoch()) / maxDataPoints
+	if intervalMs < minIntervalMs {
+		intervalMs = minIntervalMs
+	}
+
+	queries := make([]*simplejson.Json, 0, len(p.Targets))
+	for _, target := range p.Targets {
+		query := simplejson.NewFromAny(copyMap(target.MustMap()))
+		query.Set("maxDataPoints", maxDataPoints)
+		query.Set("intervalMs", intervalMs)
+		queries = append(queries, query)
+	}
+
+	resp, err := s.queryService.QueryData(ctx, signedInUser, false, dtos.MetricRequest{
+		From:    opts.From,
+		To:      opts.To,
+		Queries: queries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refIDs := make([]string, 0, len(resp.Responses))
+	for refID := range resp.Responses {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	frames := make(data.Frames, 0)
+	for _, refID := range refIDs {
+		r := resp.Responses[refID]
+		if r.Error != nil {
+			return nil, fmt.Errorf("%w: query %s failed: %s", ErrQueryFailed, refID, r.Error)
+		}
+		for _, frame := range r.Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			frames = append(frames, frame)
+		}
+	}
+
+	if opts.SkipTransformations {
+		return frames, nil
+	}
+	return applyTransformations(frames, p.Transformations)
+}
+
+// copyMap copies a query so that setting the query options does not change the dashboard.
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
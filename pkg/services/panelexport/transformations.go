@@ -0,0 +1,538 @@
+package panelexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Transformation is a data transformation configured on a panel.
+type Transformation struct {
+	ID       string
+	Disabled bool
+	Options  *simplejson.Json
+}
+
+type transformFunc func(frames data.Frames, options *simplejson.Json) (data.Frames, error)
+
+// transformers are the panel transformations which can be applied server-side. They
+// follow the behaviour of the transformations of the same ID in the frontend.
+var transformers = map[string]transformFunc{
+	"organize":           organizeFields,
+	"filterFieldsByName": filterFieldsByName,
+	"filterByRefId":      filterByRefID,
+	"renameByRegex":      renameByRegex,
+	"sortBy":             sortBy,
+	"limit":              limitRows,
+	"joinByField":        joinByField,
+	"seriesToColumns":    joinByField,
+}
+
+func parseTransformations(list *simplejson.Json) []Transformation {
+	transformations := make([]Transformation, 0)
+	for _, t := range list.MustArray() {
+		j := simplejson.NewFromAny(t)
+		transformations = append(transformations, Transformation{
+			ID:       j.Get("id").MustString(),
+			Disabled: j.Get("disabled").MustBool(false),
+			Options:  j.Get("options"),
+		})
+	}
+	return transformations
+}
+
+func applyTransformations(frames data.Frames, transformations []Transformation) (data.Frames, error) {
+	for _, t := range transformations {
+		if t.Disabled {
+			continue
+		}
+		transform, ok := transformers[t.ID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedTransformation, t.ID)
+		}
+
+		var err error
+		if frames, err = transform(frames, t.Options); err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidTransformation, t.ID, err)
+		}
+	}
+	return frames, nil
+}
+
+// displayName returns the name of a field as shown in panels and used as column header.
+func displayName(f *data.Field) string {
+	if f.Config != nil && f.Config.DisplayName != "" {
+		return f.Config.DisplayName
+	}
+	name := f.Name
+	if f.Config != nil && f.Config.DisplayNameFromDS != "" {
+		name = f.Config.DisplayNameFromDS
+	}
+	if len(f.Labels) > 0 {
+		name += " {" + f.Labels.String() + "}"
+	}
+	return name
+}
+
+func rename(f *data.Field, name string) {
+	config := data.FieldConfig{}
+	if f.Config != nil {
+		config = *f.Config
+	}
+	config.DisplayName = name
+	f.Config = &config
+}
+
+// jsRegex compiles a pattern the way the frontend does. Patterns enclosed in slashes are
+// regular expressions with optional flags, other patterns have to match the whole string.
+func jsRegex(pattern string) (*regexp.Regexp, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return regexp.Compile("^" + pattern + "$")
+	}
+	end := strings.LastIndex(pattern, "/")
+	if end == 0 {
+		return nil, fmt.Errorf("invalid regular expression %q", pattern)
+	}
+	expr := pattern[1:end]
+	if strings.Contains(pattern[end+1:], "i") {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+func boolMap(j *simplejson.Json) map[string]bool {
+	m := make(map[string]bool)
+	for k, v := range j.MustMap() {
+		if b, ok := v.(bool); ok {
+			m[k] = b
+		}
+	}
+	return m
+}
+
+// organizeFields excludes, orders and renames the fields of the frames.
+func organizeFields(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	exclude := boolMap(options.Get("excludeByName"))
+	indexByName := options.Get("indexByName")
+	renameByName := options.Get("renameByName")
+
+	for _, frame := range frames {
+		fields := make([]*data.Field, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			if !exclude[displayName(f)] {
+				fields = append(fields, f)
+			}
+		}
+
+		index := func(f *data.Field) int64 {
+			if i, err := indexByName.Get(displayName(f)).Int64(); err == nil {
+				return i
+			}
+			return math.MaxInt64
+		}
+		sort.SliceStable(fields, func(i, j int) bool { return index(fields[i]) < index(fields[j]) })
+
+		for _, f := range fields {
+			if name := renameByName.Get(displayName(f)).MustString(); name != "" {
+				rename(f, name)
+			}
+		}
+		frame.Fields = fields
+	}
+	return frames, nil
+}
+
+type nameMatcher struct {
+	names   map[string]bool
+	pattern *regexp.Regexp
+}
+
+func newNameMatcher(options *simplejson.Json) (*nameMatcher, error) {
+	if options.Interface() == nil {
+		return nil, nil
+	}
+	m := &nameMatcher{names: make(map[string]bool)}
+	for _, name := range options.Get("names").MustStringArray() {
+		m.names[name] = true
+	}
+	if pattern := options.Get("pattern").MustString(); pattern != "" {
+		var err error
+		if m.pattern, err = jsRegex(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *nameMatcher) matches(name string) bool {
+	return m.names[name] || (m.pattern != nil && m.pattern.MatchString(name))
+}
+
+// filterFieldsByName keeps the fields matching the include options and removes the
+// fields matching the exclude options.
+func filterFieldsByName(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	include, err := newNameMatcher(options.Get("include"))
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newNameMatcher(options.Get("exclude"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, frame := range frames {
+		fields := make([]*data.Field, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			name := displayName(f)
+			if include != nil && !include.matches(name) {
+				continue
+			}
+			if exclude != nil && exclude.matches(name) {
+				continue
+			}
+			fields = append(fields, f)
+		}
+		frame.Fields = fields
+	}
+	return frames, nil
+}
+
+// filterByRefID keeps the frames of the queries whose ref ID matches the include pattern.
+func filterByRefID(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	pattern := options.Get("include").MustString()
+	if pattern == "" {
+		return frames, nil
+	}
+	include, err := jsRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		if include.MatchString(frame.RefID) {
+			filtered = append(filtered, frame)
+		}
+	}
+	return filtered, nil
+}
+
+// renameByRegex renames the fields matching a regular expression by a replacement
+// pattern, which may refer to capture groups as $1.
+func renameByRegex(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	pattern := options.Get("regex").MustString()
+	if pattern == "" {
+		return frames, nil
+	}
+	regex, err := jsRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	replacement := options.Get("renamePattern").MustString()
+
+	for _, frame := range frames {
+		for _, f := range frame.Fields {
+			name := displayName(f)
+			if regex.MatchString(name) {
+				rename(f, regex.ReplaceAllString(name, replacement))
+			}
+		}
+	}
+	return frames, nil
+}
+
+// sortBy sorts the rows of the frames by a field. Only the first sort option is
+// used, like in the frontend.
+func sortBy(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	sortOptions := options.Get("sort").MustArray()
+	if len(sortOptions) == 0 {
+		return frames, nil
+	}
+	sortOption := simplejson.NewFromAny(sortOptions[0])
+	name := sortOption.Get("field").MustString()
+	desc := sortOption.Get("desc").MustBool(false)
+
+	for i, frame := range frames {
+		var by *data.Field
+		for _, f := range frame.Fields {
+			if displayName(f) == name {
+				by = f
+				break
+			}
+		}
+		if by == nil {
+			continue
+		}
+
+		rows := make([]int, by.Len())
+		for j := range rows {
+			rows[j] = j
+		}
+		sort.SliceStable(rows, func(a, b int) bool {
+			va, okA := by.ConcreteAt(rows[a])
+			vb, okB := by.ConcreteAt(rows[b])
+			// empty values go last in either direction
+			if !okA || !okB {
+				return okA && !okB
+			}
+			if desc {
+				return compareValues(vb, va) < 0
+			}
+			return compareValues(va, vb) < 0
+		})
+		frames[i] = selectRows(frame, rows)
+	}
+	return frames, nil
+}
+
+// limitRows keeps the first rows of every frame.
+func limitRows(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	limit := options.Get("limitField").MustInt(10)
+	if limit < 0 {
+		limit = 0
+	}
+
+	for i, frame := range frames {
+		rows, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		if rows <= limit {
+			continue
+		}
+		selected := make([]int, limit)
+		for j := range selected {
+			selected[j] = j
+		}
+		frames[i] = selectRows(frame, selected)
+	}
+	return frames, nil
+}
+
+// joinByField joins the frames into one frame by the values of a field, by default the
+// first time field. In outer mode all values are kept, in inner mode only the values
+// found in all frames.
+func joinByField(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	joined, err := joinFrames(frames, options.Get("byField").MustString(), options.Get("mode").MustString() == "inner")
+	if err != nil {
+		return nil, err
+	}
+	return data.Frames{joined}, nil
+}
+
+func joinFrames(frames data.Frames, byField string, inner bool) (*data.Frame, error) {
+	if byField == "" {
+		for _, frame := range frames {
+			if idx := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime); len(idx) > 0 {
+				byField = displayName(frame.Fields[idx[0]])
+				break
+			}
+		}
+	}
+
+	type joinSource struct {
+		frame *data.Frame
+		key   *data.Field
+		rows  map[interface{}]int
+	}
+	sources := make([]joinSource, 0, len(frames))
+	for _, frame := range frames {
+		for _, f := range frame.Fields {
+			if displayName(f) == byField {
+				sources = append(sources, joinSource{frame: frame, key: f, rows: make(map[interface{}]int)})
+				break
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no frame has the field %q to join by", byField)
+	}
+
+	// collect the keys in the order they appear, counting the frames they appear in
+	keys := make([]interface{}, 0)
+	values := make(map[interface{}]interface{})
+	counts := make(map[interface{}]int)
+	for _, source := range sources {
+		for row := 0; row < source.key.Len(); row++ {
+			v, ok := source.key.ConcreteAt(row)
+			if !ok {
+				continue
+			}
+			k := joinKey(v)
+			if _, seen := source.rows[k]; seen {
+				continue
+			}
+			source.rows[k] = row
+			if _, seen := values[k]; !seen {
+				keys = append(keys, k)
+				values[k] = v
+			}
+			counts[k]++
+		}
+	}
+	if inner {
+		filtered := keys[:0]
+		for _, k := range keys {
+			if counts[k] == len(sources) {
+				filtered = append(filtered, k)
+			}
+		}
+		keys = filtered
+	}
+	if t := sources[0].key.Type(); t.Time() || t.Numeric() {
+		sort.SliceStable(keys, func(i, j int) bool { return compareValues(values[keys[i]], values[keys[j]]) < 0 })
+	}
+
+	keyField := data.NewFieldFromFieldType(sources[0].key.Type().NonNullableType(), len(keys))
+	keyField.Name = sources[0].key.Name
+	keyField.Labels = sources[0].key.Labels
+	keyField.Config = sources[0].key.Config
+	for i, k := range keys {
+		keyField.SetConcrete(i, values[k])
+	}
+
+	joined := data.NewFrame("", keyField)
+	names := map[string]bool{displayName(keyField): true}
+	for _, source := range sources {
+		for _, f := range source.frame.Fields {
+			if f == source.key {
+				continue
+			}
+			field := data.NewFieldFromFieldType(f.Type().NullableType(), len(keys))
+			field.Name = f.Name
+			field.Labels = f.Labels
+			field.Config = f.Config
+			for i, k := range keys {
+				row, ok := source.rows[k]
+				if !ok {
+					continue
+				}
+				if v, ok := f.ConcreteAt(row); ok {
+					field.SetConcrete(i, v)
+				}
+			}
+			uniqueName(field, source.frame.Name, names)
+			joined.Fields = append(joined.Fields, field)
+		}
+	}
+	return joined, nil
+}
+
+// uniqueName renames a field of a joined frame whose name is already taken, preferring
+// the name of the frame it comes from.
+func uniqueName(f *data.Field, frameName string, names map[string]bool) {
+	name := displayName(f)
+	if names[name] && frameName != "" && !names[frameName] {
+		name = frameName
+	}
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s %d", displayName(f), i)
+	}
+	if name != displayName(f) {
+		rename(f, name)
+	}
+	names[name] = true
+}
+
+func joinKey(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UnixNano()
+	case json.RawMessage:
+		return string(v)
+	}
+	return v
+}
+
+// selectRows returns a copy of the frame with the rows at the given indices.
+func selectRows(frame *data.Frame, rows []int) *data.Frame {
+	selected := data.NewFrame(frame.Name)
+	selected.RefID = frame.RefID
+	selected.Meta = frame.Meta
+	for _, f := range frame.Fields {
+		field := data.NewFieldFromFieldType(f.Type(), len(rows))
+		field.Name = f.Name
+		field.Labels = f.Labels
+		field.Config = f.Config
+		for i, row := range rows {
+			field.Set(i, f.CopyAt(row))
+		}
+		selected.Fields = append(selected.Fields, field)
+	}
+	return selected
+}
+
+// compareValues compares two concrete values of the same field type.
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			switch {
+			case a.Before(b):
+				return -1
+			case a.After(b):
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+	case bool:
+		if b, ok := b.(bool); ok && a != b {
+			if a {
+				return 1
+			}
+			return -1
+		}
+		return 0
+	}
+
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,258 @@
+package panelexport
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The parts of an Office Open XML workbook which do not depend on the data.
+const (
+	xlsxContentTypesHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	// styles: 0 is the default, 1 formats dates and 2 is the bold header
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+
+	xlsxStyleDate   = 1
+	xlsxStyleHeader = 2
+
+	xlsxMaxSheetName = 31
+)
+
+// excelEpoch is the day Excel counts dates from.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// WriteXLSX writes the data frames as an Excel workbook with a worksheet per frame.
+// The worksheets are streamed, so the workbook is never held in memory.
+func WriteXLSX(out io.Writer, frames data.Frames, loc *time.Location) error {
+	z := zip.NewWriter(out)
+	names := sheetNames(frames)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes(len(frames))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(names)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(frames))},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		w, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	for i, frame := range frames {
+		w, err := z.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeWorksheet(w, frame, loc); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func xlsxContentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xlsxContentTypesHeader)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func xlsxWorkbook(names []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range names {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func xlsxWorkbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func writeWorksheet(out io.Writer, frame *data.Frame, loc *time.Location) error {
+	w := bufio.NewWriter(out)
+	if _, err := w.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="1">`); err != nil {
+		return err
+	}
+	for col, f := range frame.Fields {
+		fmt.Fprintf(w, `<c r="%s1" s="%d" t="inlineStr"><is><t>%s</t></is></c>`, columnName(col), xlsxStyleHeader, xmlEscape(displayName(f)))
+	}
+	if _, err := w.WriteString(`</row>`); err != nil {
+		return err
+	}
+
+	rows, err := frame.RowLen()
+	if err != nil {
+		return err
+	}
+	for row := 0; row < rows; row++ {
+		fmt.Fprintf(w, `<row r="%d">`, row+2)
+		for col, f := range frame.Fields {
+			v, ok := f.ConcreteAt(row)
+			if !ok {
+				continue
+			}
+			writeCell(w, fmt.Sprintf("%s%d", columnName(col), row+2), v, loc)
+		}
+		if _, err := w.WriteString(`</row>`); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func writeCell(w *bufio.Writer, ref string, v interface{}, loc *time.Location) {
+	switch v := v.(type) {
+	case time.Time:
+		fmt.Fprintf(w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, strconv.FormatFloat(excelDate(v, loc), 'f', -1, 64))
+		return
+	case bool:
+		value := 0
+		if v {
+			value = 1
+		}
+		fmt.Fprintf(w, `<c r="%s" t="b"><v>%d</v></c>`, ref, value)
+		return
+	case string:
+		writeStringCell(w, ref, v)
+		return
+	case json.RawMessage:
+		writeStringCell(w, ref, string(v))
+		return
+	}
+
+	if f, ok := toFloat(v); ok && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		fmt.Fprintf(w, `<c r="%s"><v>%s</v></c>`, ref, formatValue(v, loc))
+		return
+	}
+	writeStringCell(w, ref, fmt.Sprint(v))
+}
+
+func writeStringCell(w *bufio.Writer, ref string, s string) {
+	fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(s))
+}
+
+// excelDate returns the wall clock time in the time zone as the number of days since the Excel epoch.
+func excelDate(t time.Time, loc *time.Location) float64 {
+	t = t.In(loc)
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return float64(wall.Unix()-excelEpoch.Unix())/86400 + float64(wall.Nanosecond())/86400e9
+}
+
+// columnName returns the letters of a zero-based column index, e.g. A, Z, AA.
+func columnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
+
+// sheetNames returns unique and valid worksheet names for the frames.
+func sheetNames(frames data.Frames) []string {
+	names := make([]string, len(frames))
+	used := make(map[string]bool)
+	for i, frame := range frames {
+		name := frame.Name
+		if name == "" {
+			name = frame.RefID
+		}
+		name = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`[]:*?/\`, r) || r < ' ' {
+				return '_'
+			}
+			return r
+		}, strings.Trim(name, "' "))
+		if name == "" {
+			name = "Sheet"
+		}
+		name = truncateRunes(name, xlsxMaxSheetName)
+
+		unique := name
+		for n := 2; used[strings.ToLower(unique)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			unique = truncateRunes(name, xlsxMaxSheetName-len(suffix)) + suffix
+		}
+		used[strings.ToLower(unique)] = true
+		names[i] = unique
+	}
+	return names
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// xmlEscape escapes text for XML and drops the characters XML cannot represent.
+func xmlEscape(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= ' ' && r != utf8.RuneError && r != 0xFFFE && r != 0xFFFF) {
+			return r
+		}
+		return -1
+	}, s)
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package pluginjobs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *PluginJobsService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	pluginIDScope := plugins.ScopeProvider.GetResourceScope(ac.Parameter(":pluginId"))
+
+	s.routeRegister.Group("/api/plugins/:pluginId/jobs", func(entities routing.RouteRegister) {
+		entities.Get("/", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(plugins.ActionWrite, pluginIDScope)), routing.Wrap(s.getJobsHandler))
+		entities.Get("/:jobId/runs", authorize(middleware.ReqOrgAdmin, ac.EvalPermission(plugins.ActionWrite, pluginIDScope)), routing.Wrap(s.getRunsHandler))
+	})
+}
+
+// swagger:route GET /plugins/{plugin_id}/jobs plugins getPluginJobs
+//
+// Get the background jobs of an app plugin.
+//
+// Returns the jobs declared in the plugin.json of the app plugin together with their last run in the current organization.
+//
+// Responses:
+// 200: pluginJobsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *PluginJobsService) getJobsHandler(c *models.ReqContext) response.Response {
+	jobs, err := s.GetJobs(c.Req.Context(), c.OrgID, web.Params(c.Req)[":pluginId"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, jobs)
+}
+
+// swagger:route GET /plugins/{plugin_id}/jobs/{job_id}/runs plugins getPluginJobRuns
+//
+// Get the run history of a background job of an app plugin.
+//
+// Returns the runs of the job in the current organization, newest first. Runs are kept for 30 days.
+//
+// Responses:
+// 200: pluginJobRunsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *PluginJobsService) getRunsHandler(c *models.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	runs, err := s.GetRuns(c.Req.Context(), c.OrgID, params[":pluginId"], params[":jobId"], c.QueryInt("limit"))
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, runs)
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, plugins.ErrPluginNotInstalled):
+		return response.Error(http.StatusNotFound, "Plugin not found", err)
+	case errors.Is(err, ErrJobNotFound):
+		return response.Error(http.StatusNotFound, "Job not found", err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to get plugin jobs", err)
+}
+
+// swagger:parameters getPluginJobs
+type GetPluginJobsParams struct {
+	// in:path
+	// required:true
+	PluginID string `json:"plugin_id"`
+}
+
+// swagger:parameters getPluginJobRuns
+type GetPluginJobRunsParams struct {
+	// in:path
+	// required:true
+	PluginID string `json:"plugin_id"`
+	// in:path
+	// required:true
+	JobID string `json:"job_id"`
+	// Maximum number of runs to return.
+	// in:query
+	// required:false
+	// default:50
+	Limit int `json:"limit"`
+}
+
+// swagger:response pluginJobsResponse
+type PluginJobsResponse struct {
+	// in:body
+	Body []*JobDTO `json:"body"`
+}
+
+// swagger:response pluginJobRunsResponse
+type PluginJobRunsResponse struct {
+	// in:body
+	Body []*Run `json:"body"`
+}
@@ -0,0 +1,145 @@
+package pluginjobs
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	minInterval    = time.Minute
+	maxTimeout     = 10 * time.Minute
+	maxMessageSize = 255
+)
+
+var (
+	ErrJobNotFound = errors.New("plugin job not found")
+
+	jobIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+)
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Job is a periodic background job declared in the plugin.json of an app plugin.
+type Job struct {
+	PluginID string
+	ID       string
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration
+	// Path is the resource path of the plugin called to run the job.
+	Path string
+}
+
+// newJob validates a job declared in plugin.json and applies its defaults.
+func newJob(pluginID string, j *plugins.Job) (*Job, error) {
+	if !jobIDPattern.MatchString(j.ID) {
+		return nil, fmt.Errorf("invalid job id %q", j.ID)
+	}
+
+	interval, err := time.ParseDuration(j.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval of job %q: %w", j.ID, err)
+	}
+	if interval < minInterval {
+		return nil, fmt.Errorf("interval of job %q must be at least %s", j.ID, minInterval)
+	}
+
+	timeout := interval
+	if j.Timeout != "" {
+		if timeout, err = time.ParseDuration(j.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout of job %q: %w", j.ID, err)
+		}
+	}
+	if timeout <= 0 || timeout > maxTimeout {
+		timeout = maxTimeout
+	}
+
+	job := &Job{
+		PluginID: pluginID,
+		ID:       j.ID,
+		Name:     j.Name,
+		Interval: interval,
+		Timeout:  timeout,
+		Path:     j.Path,
+	}
+	if job.Name == "" {
+		job.Name = job.ID
+	}
+	if job.Path == "" {
+		job.Path = "jobs/" + job.ID
+	}
+	return job, nil
+}
+
+// lockName is the server lock used to run the job once per interval across Grafana instances.
+// The plugin and job IDs are hashed since lock names are limited to 100 characters.
+func (j *Job) lockName(orgID int64) string {
+	return fmt.Sprintf("plugin-job-%d-%x", orgID, sha256.Sum256([]byte(j.PluginID+"/"+j.ID)))
+}
+
+// schedulerUser is the user set in the plugin context of job runs.
+func schedulerUser(orgID int64) *user.SignedInUser {
+	return &user.SignedInUser{
+		OrgID:   orgID,
+		OrgRole: org.RoleAdmin,
+		Login:   "grafana-scheduler",
+		Name:    "Grafana scheduler",
+	}
+}
+
+// Run is a single execution of a job in an organization.
+type Run struct {
+	ID       int64      `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID    int64      `json:"orgId" xorm:"org_id"`
+	PluginID string     `json:"pluginId" xorm:"plugin_id"`
+	JobID    string     `json:"jobId" xorm:"job_id"`
+	Status   Status     `json:"status" xorm:"status"`
+	Message  string     `json:"message,omitempty" xorm:"message"`
+	Started  time.Time  `json:"started" xorm:"started"`
+	Finished *time.Time `json:"finished,omitempty" xorm:"finished"`
+}
+
+func (r Run) TableName() string {
+	return "plugin_job_run"
+}
+
+// JobDTO is a job of an app plugin together with its last run in the organization.
+// swagger:model
+type JobDTO struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Interval string `json:"interval"`
+	Timeout  string `json:"timeout"`
+	LastRun  *Run   `json:"lastRun,omitempty"`
+}
+
+// runRequest is the body sent to the plugin when a job runs.
+func runRequest(job *Job, run *Run) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"jobId":   job.ID,
+		"runId":   run.ID,
+		"orgId":   run.OrgID,
+		"started": run.Started.UTC().Format(time.RFC3339),
+	})
+}
+
+func truncate(s string) string {
+	if len(s) > maxMessageSize {
+		return s[:maxMessageSize]
+	}
+	return s
+}
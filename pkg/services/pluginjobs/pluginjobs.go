@@ -0,0 +1,309 @@
+package pluginjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	scheduleInterval = 30 * time.Second
+	cleanupInterval  = time.Hour
+	// runRetention is how long the history of job runs is kept.
+	runRetention = 30 * 24 * time.Hour
+
+	defaultRunsLimit = 50
+	maxRunsLimit     = 1000
+)
+
+type Service interface {
+	// GetJobs returns the jobs of an app plugin with their last run in the organization.
+	GetJobs(ctx context.Context, orgID int64, pluginID string) ([]*JobDTO, error)
+	// GetRuns returns the runs of a job in the organization, newest first.
+	GetRuns(ctx context.Context, orgID int64, pluginID, jobID string, limit int) ([]*Run, error)
+}
+
+// PluginJobsService runs the background jobs declared by app plugins in every organization
+// where the app is enabled. Jobs are run through the resource handler of the plugin backend,
+// and server locks make sure each job runs once per interval across Grafana instances.
+type PluginJobsService struct {
+	store                 db.DB
+	log                   log.Logger
+	pluginStore           plugins.Store
+	pluginClient          plugins.Client
+	pluginContextProvider *plugincontext.Provider
+	serverLock            *serverlock.ServerLockService
+	routeRegister         routing.RouteRegister
+	accessControl         accesscontrol.AccessControl
+
+	mu      sync.Mutex
+	running map[string]bool
+	invalid map[string]bool
+	wg      sync.WaitGroup
+}
+
+func ProvideService(sqlStore db.DB, pluginStore plugins.Store, pluginClient plugins.Client,
+	pluginContextProvider *plugincontext.Provider, serverLock *serverlock.ServerLockService,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *PluginJobsService {
+	s := &PluginJobsService{
+		store:                 sqlStore,
+		log:                   log.New("plugin.jobs"),
+		pluginStore:           pluginStore,
+		pluginClient:          pluginClient,
+		pluginContextProvider: pluginContextProvider,
+		serverLock:            serverLock,
+		routeRegister:         routeRegister,
+		accessControl:         accessControl,
+		running:               make(map[string]bool),
+		invalid:               make(map[string]bool),
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *PluginJobsService) Run(ctx context.Context) error {
+	scheduleTicker := time.NewTicker(scheduleInterval)
+	defer scheduleTicker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return ctx.Err()
+		case <-scheduleTicker.C:
+			s.schedule(ctx)
+		case <-cleanupTicker.C:
+			if err := s.deleteRunsBefore(ctx, time.Now().Add(-runRetention)); err != nil {
+				s.log.Error("Failed to clean up plugin job runs", "error", err)
+			}
+		}
+	}
+}
+
+func (s *PluginJobsService) GetJobs(ctx context.Context, orgID int64, pluginID string) ([]*JobDTO, error) {
+	p, exists := s.pluginStore.Plugin(ctx, pluginID)
+	if !exists {
+		return nil, plugins.ErrPluginNotInstalled
+	}
+
+	result := make([]*JobDTO, 0)
+	for _, job := range s.pluginJobs(p) {
+		lastRun, err := s.lastRun(ctx, orgID, job)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &JobDTO{
+			ID:       job.ID,
+			Name:     job.Name,
+			Interval: job.Interval.String(),
+			Timeout:  job.Timeout.String(),
+			LastRun:  lastRun,
+		})
+	}
+	return result, nil
+}
+
+func (s *PluginJobsService) GetRuns(ctx context.Context, orgID int64, pluginID, jobID string, limit int) ([]*Run, error) {
+	p, exists := s.pluginStore.Plugin(ctx, pluginID)
+	if !exists {
+		return nil, plugins.ErrPluginNotInstalled
+	}
+
+	for _, job := range s.pluginJobs(p) {
+		if job.ID != jobID {
+			continue
+		}
+		if limit <= 0 {
+			limit = defaultRunsLimit
+		}
+		if limit > maxRunsLimit {
+			limit = maxRunsLimit
+		}
+		return s.runs(ctx, orgID, job, limit)
+	}
+	return nil, ErrJobNotFound
+}
+
+// pluginJobs returns the valid jobs of a backend app plugin. Invalid jobs are logged once.
+func (s *PluginJobsService) pluginJobs(p plugins.PluginDTO) []*Job {
+	if !p.IsApp() || !p.Backend {
+		return nil
+	}
+
+	jobs := make([]*Job, 0, len(p.Jobs))
+	for _, j := range p.Jobs {
+		job, err := newJob(p.ID, j)
+		if err != nil {
+			s.mu.Lock()
+			if !s.invalid[p.ID+"/"+j.ID] {
+				s.invalid[p.ID+"/"+j.ID] = true
+				s.log.Warn("Ignoring invalid plugin job", "pluginId", p.ID, "error", err)
+			}
+			s.mu.Unlock()
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// schedule starts the jobs which are not running on this instance yet. Whether a job is due is
+// decided by its server lock, which is only acquired once per interval by any of the instances.
+func (s *PluginJobsService) schedule(ctx context.Context) {
+	for _, p := range s.pluginStore.Plugins(ctx, plugins.App) {
+		jobs := s.pluginJobs(p)
+		if len(jobs) == 0 {
+			continue
+		}
+
+		orgIDs, err := s.enabledOrgs(ctx, p.ID, p.AutoEnabled)
+		if err != nil {
+			s.log.Error("Failed to get organizations of app plugin", "pluginId", p.ID, "error", err)
+			continue
+		}
+
+		for _, job := range jobs {
+			for _, orgID := range orgIDs {
+				s.start(ctx, job, orgID)
+			}
+		}
+	}
+}
+
+func (s *PluginJobsService) start(ctx context.Context, job *Job, orgID int64) {
+	lockName := job.lockName(orgID)
+
+	s.mu.Lock()
+	if s.running[lockName] {
+		s.mu.Unlock()
+		return
+	}
+	s.running[lockName] = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, lockName)
+			s.mu.Unlock()
+			s.wg.Done()
+		}()
+
+		err := s.serverLock.LockAndExecute(ctx, lockName, job.Interval, func(ctx context.Context) {
+			s.execute(ctx, job, orgID)
+		})
+		if err != nil {
+			s.log.Error("Failed to lock plugin job", "pluginId", job.PluginID, "jobId", job.ID, "orgId", orgID, "error", err)
+		}
+	}()
+}
+
+// execute runs the job in the organization and records the run.
+func (s *PluginJobsService) execute(ctx context.Context, job *Job, orgID int64) {
+	logger := s.log.New("pluginId", job.PluginID, "jobId", job.ID, "orgId", orgID)
+
+	run := &Run{
+		OrgID:    orgID,
+		PluginID: job.PluginID,
+		JobID:    job.ID,
+		Status:   StatusRunning,
+		Started:  time.Now(),
+	}
+	if err := s.insertRun(ctx, run); err != nil {
+		logger.Error("Failed to record plugin job run", "error", err)
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+	message, err := s.callPlugin(runCtx, job, run)
+	cancel()
+
+	finished := time.Now()
+	run.Finished = &finished
+	run.Status = StatusSucceeded
+	run.Message = truncate(message)
+	if err != nil {
+		logger.Warn("Plugin job failed", "error", err)
+		run.Status = StatusFailed
+		run.Message = truncate(err.Error())
+	} else {
+		logger.Debug("Plugin job succeeded", "duration", finished.Sub(run.Started))
+	}
+
+	// the run is recorded even if Grafana is shutting down
+	if err := s.updateRun(context.Background(), run); err != nil {
+		logger.Error("Failed to record plugin job run", "error", err)
+	}
+}
+
+// callPlugin calls the resource handler of the plugin at the path of the job.
+// The body of a successful response is returned as message of the run.
+func (s *PluginJobsService) callPlugin(ctx context.Context, job *Job, run *Run) (string, error) {
+	pCtx, found, err := s.pluginContextProvider.Get(ctx, job.PluginID, schedulerUser(run.OrgID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get plugin context: %w", err)
+	}
+	if !found {
+		return "", plugins.ErrPluginNotInstalled
+	}
+
+	body, err := runRequest(job, run)
+	if err != nil {
+		return "", err
+	}
+
+	req := &backend.CallResourceRequest{
+		PluginContext: pCtx,
+		Path:          job.Path,
+		Method:        http.MethodPost,
+		URL:           job.Path,
+		Headers:       map[string][]string{"Content-Type": {"application/json"}},
+		Body:          body,
+	}
+
+	sender := &jobResponseSender{}
+	if err := s.pluginClient.CallResource(ctx, req, sender); err != nil {
+		return "", err
+	}
+	status, respBody := sender.status, sender.body
+
+	if status == 0 {
+		return "", errors.New("plugin did not respond")
+	}
+	if status >= http.StatusBadRequest {
+		return "", fmt.Errorf("plugin responded with status %d: %s", status, respBody)
+	}
+	return string(respBody), nil
+}
+
+// jobResponseSender collects the response of a job, which may be sent in several chunks.
+type jobResponseSender struct {
+	status int
+	body   []byte
+}
+
+func (r *jobResponseSender) Send(resp *backend.CallResourceResponse) error {
+	if r.status == 0 {
+		r.status = resp.Status
+	}
+	r.body = append(r.body, resp.Body...)
+	return nil
+}
@@ -0,0 +1,167 @@
+package pluginjobs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/pluginsettings"
+)
+
+func TestNewJob(t *testing.T) {
+	t.Run("applies defaults", func(t *testing.T) {
+		job, err := newJob("test-app", &plugins.Job{ID: "report", Interval: "1h"})
+		require.NoError(t, err)
+		assert.Equal(t, &Job{
+			PluginID: "test-app",
+			ID:       "report",
+			Name:     "report",
+			Interval: time.Hour,
+			Timeout:  maxTimeout,
+			Path:     "jobs/report",
+		}, job)
+	})
+
+	t.Run("uses the interval as timeout when it is shorter than the maximum", func(t *testing.T) {
+		job, err := newJob("test-app", &plugins.Job{ID: "sync", Interval: "2m"})
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Minute, job.Timeout)
+	})
+
+	for name, j := range map[string]*plugins.Job{
+		"invalid id":         {ID: "Report Job", Interval: "1h"},
+		"invalid interval":   {ID: "report", Interval: "hourly"},
+		"interval too short": {ID: "report", Interval: "10s"},
+		"invalid timeout":    {ID: "report", Interval: "1h", Timeout: "soon"},
+		"missing interval":   {ID: "report"},
+		"missing id":         {Interval: "1h"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newJob("test-app", j)
+			require.Error(t, err)
+		})
+	}
+}
+
+type fakePluginClient struct {
+	plugins.Client
+
+	mu       sync.Mutex
+	status   int
+	requests []*backend.CallResourceRequest
+}
+
+func (c *fakePluginClient) CallResource(_ context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+	return sender.Send(&backend.CallResourceResponse{Status: c.status, Body: []byte("done")})
+}
+
+func TestIntegrationPluginJobs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	pluginStore := &plugins.FakePluginStore{PluginList: []plugins.PluginDTO{
+		{JSONData: plugins.JSONData{ID: "report-app", Type: plugins.App, Backend: true, Jobs: []*plugins.Job{
+			{ID: "daily", Name: "Daily report", Interval: "24h"},
+			{ID: "invalid", Interval: "1s"},
+		}}},
+		{JSONData: plugins.JSONData{ID: "frontend-app", Type: plugins.App, Jobs: []*plugins.Job{
+			{ID: "daily", Interval: "24h"},
+		}}},
+	}}
+	pluginSettings := &pluginsettings.FakePluginSettings{Plugins: map[string]*pluginsettings.DTO{
+		"report-app": {OrgID: 1, PluginID: "report-app", Enabled: true},
+	}}
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&models.PluginSetting{OrgId: 1, PluginId: "report-app", Enabled: true, Created: time.Now(), Updated: time.Now()})
+		return err
+	})
+	require.NoError(t, err)
+
+	client := &fakePluginClient{status: http.StatusOK}
+	s := ProvideService(sqlStore, pluginStore, client,
+		plugincontext.ProvideService(localcache.ProvideService(), pluginStore, nil, nil, pluginSettings),
+		serverlock.ProvideService(sqlStore, tracing.InitializeTracerForTest()),
+		routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	t.Run("runs the jobs of backend apps in organizations where they are enabled", func(t *testing.T) {
+		s.schedule(ctx)
+		s.wg.Wait()
+
+		require.Len(t, client.requests, 1)
+		req := client.requests[0]
+		assert.Equal(t, "jobs/daily", req.Path)
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, int64(1), req.PluginContext.OrgID)
+		assert.Equal(t, "report-app", req.PluginContext.PluginID)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(req.Body, &body))
+		assert.Equal(t, "daily", body["jobId"])
+
+		runs, err := s.GetRuns(ctx, 1, "report-app", "daily", 0)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, StatusSucceeded, runs[0].Status)
+		assert.Equal(t, "done", runs[0].Message)
+		assert.NotNil(t, runs[0].Finished)
+	})
+
+	t.Run("does not run jobs again within their interval", func(t *testing.T) {
+		s.schedule(ctx)
+		s.wg.Wait()
+
+		require.Len(t, client.requests, 1)
+	})
+
+	t.Run("records failed runs", func(t *testing.T) {
+		job, err := newJob("report-app", &plugins.Job{ID: "daily", Interval: "24h"})
+		require.NoError(t, err)
+
+		client.status = http.StatusInternalServerError
+		s.execute(ctx, job, 1)
+
+		jobs, err := s.GetJobs(ctx, 1, "report-app")
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		assert.Equal(t, "Daily report", jobs[0].Name)
+		require.NotNil(t, jobs[0].LastRun)
+		assert.Equal(t, StatusFailed, jobs[0].LastRun.Status)
+		assert.Contains(t, jobs[0].LastRun.Message, "status 500")
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		_, err := s.GetRuns(ctx, 1, "report-app", "weekly", 0)
+		require.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("removes runs after the retention period", func(t *testing.T) {
+		require.NoError(t, s.deleteRunsBefore(ctx, time.Now().Add(time.Minute)))
+
+		runs, err := s.GetRuns(ctx, 1, "report-app", "daily", 0)
+		require.NoError(t, err)
+		require.Empty(t, runs)
+	})
+}
@@ -0,0 +1,62 @@
+package pluginjobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// enabledOrgs returns the organizations where the app plugin is enabled. Apps which are
+// enabled automatically run in every organization where they were not disabled explicitly.
+func (s *PluginJobsService) enabledOrgs(ctx context.Context, pluginID string, autoEnabled bool) ([]int64, error) {
+	orgIDs := make([]int64, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if autoEnabled {
+			return sess.SQL(`SELECT id FROM org WHERE id NOT IN
+				(SELECT org_id FROM plugin_setting WHERE plugin_id = ? AND enabled = ?) ORDER BY id`,
+				pluginID, s.store.GetDialect().BooleanStr(false)).Find(&orgIDs)
+		}
+		return sess.SQL("SELECT org_id FROM plugin_setting WHERE plugin_id = ? AND enabled = ? ORDER BY org_id",
+			pluginID, s.store.GetDialect().BooleanStr(true)).Find(&orgIDs)
+	})
+	return orgIDs, err
+}
+
+func (s *PluginJobsService) insertRun(ctx context.Context, run *Run) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(run)
+		return err
+	})
+}
+
+func (s *PluginJobsService) updateRun(ctx context.Context, run *Run) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(run.ID).Cols("status", "message", "finished").Update(run)
+		return err
+	})
+}
+
+func (s *PluginJobsService) lastRun(ctx context.Context, orgID int64, job *Job) (*Run, error) {
+	runs, err := s.runs(ctx, orgID, job, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return runs[0], nil
+}
+
+func (s *PluginJobsService) runs(ctx context.Context, orgID int64, job *Job, limit int) ([]*Run, error) {
+	runs := make([]*Run, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND plugin_id = ? AND job_id = ?", orgID, job.PluginID, job.ID).
+			Desc("started", "id").Limit(limit).Find(&runs)
+	})
+	return runs, err
+}
+
+func (s *PluginJobsService) deleteRunsBefore(ctx context.Context, before time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM plugin_job_run WHERE started < ?", before)
+		return err
+	})
+}
@@ -2,6 +2,7 @@ package pluginsettings
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -75,3 +76,20 @@ func (ps *FakePluginSettings) DecryptedValues(dto *DTO) map[string]string {
 	// TODO: Implement
 	return nil
 }
+
+// LegacySecrets returns the plugin settings which have secureJSONData.
+func (ps *FakePluginSettings) LegacySecrets(_ context.Context) ([]*LegacySecretsInfo, error) {
+	res := []*LegacySecretsInfo{}
+	for _, dto := range ps.Plugins {
+		if len(dto.SecureJSONData) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(dto.SecureJSONData))
+		for k := range dto.SecureJSONData {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		res = append(res, &LegacySecretsInfo{OrgID: dto.OrgID, PluginID: dto.PluginID, Keys: keys})
+	}
+	return res, nil
+}
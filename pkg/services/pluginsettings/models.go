@@ -49,3 +49,12 @@ type GetByPluginIDArgs struct {
 	PluginID string
 	OrgID    int64
 }
+
+// LegacySecretsInfo describes the secrets of a plugin setting stored in the legacy secure_json_data column.
+type LegacySecretsInfo struct {
+	OrgID    int64    `json:"orgId"`
+	PluginID string   `json:"pluginId"`
+	Keys     []string `json:"keys"`
+	// Migrated is true when the secrets are also stored in the secrets store.
+	Migrated bool `json:"migrated"`
+}
@@ -16,4 +16,10 @@ type Service interface {
 	// DecryptedValues decrypts the encrypted secureJSONData of the provided plugin setting and
 	// returns the decrypted values.
 	DecryptedValues(ps *DTO) map[string]string
+	// MigrateSecrets copies the secureJSONData of all plugin settings to the secrets store. The legacy
+	// column is re-encrypted with the current data key, or cleared if secrets compatibility is disabled.
+	MigrateSecrets(ctx context.Context) error
+	// LegacySecrets returns the plugin settings which still hold secrets in the legacy
+	// secure_json_data column.
+	LegacySecrets(ctx context.Context) ([]*LegacySecretsInfo, error)
 }
@@ -2,24 +2,34 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(db db.DB, secretsService secrets.Service) *Service {
+func ProvideService(db db.DB, secretsService secrets.Service, secretsStore kvstore.SecretsKVStore,
+	features featuremgmt.FeatureToggles, cfg *setting.Cfg) *Service {
 	s := &Service{
 		db: db,
 		decryptionCache: secureJSONDecryptionCache{
 			cache: make(map[int64]cachedDecryptedJSON),
 		},
-		secretsService: secretsService,
-		logger:         log.New("pluginsettings"),
+		secretsService:    secretsService,
+		secretsStore:      secretsStore,
+		features:          features,
+		referencesEnabled: cfg.PluginSecretReferencesEnabled,
+		logger:            log.New("pluginsettings"),
 	}
 
 	return s
@@ -29,6 +39,10 @@ type Service struct {
 	db              db.DB
 	decryptionCache secureJSONDecryptionCache
 	secretsService  secrets.Service
+	secretsStore    kvstore.SecretsKVStore
+	features        featuremgmt.FeatureToggles
+	// referencesEnabled resolves secret references like $__file{} in secure settings.
+	referencesEnabled bool
 
 	logger log.Logger
 }
@@ -88,9 +102,13 @@ func (s *Service) GetPluginSettingByPluginID(ctx context.Context, args *pluginse
 }
 
 func (s *Service) UpdatePluginSetting(ctx context.Context, args *pluginsettings.UpdateArgs) error {
-	encryptedSecureJsonData, err := s.secretsService.EncryptJsonData(ctx, args.SecureJSONData, secrets.WithoutScope())
-	if err != nil {
-		return err
+	encryptedSecureJsonData := make(map[string][]byte)
+	if !s.features.IsEnabled(featuremgmt.FlagDisableSecretsCompatibility) {
+		var err error
+		encryptedSecureJsonData, err = s.secretsService.EncryptJsonData(ctx, args.SecureJSONData, secrets.WithOrgScope(args.OrgID))
+		if err != nil {
+			return err
+		}
 	}
 
 	return s.updatePluginSetting(ctx, &models.UpdatePluginSettingCmd{
@@ -118,10 +136,10 @@ func (s *Service) DecryptedValues(ps *pluginsettings.DTO) map[string]string {
 	defer s.decryptionCache.Unlock()
 
 	if item, present := s.decryptionCache.cache[ps.ID]; present && ps.Updated.Equal(item.updated) {
-		return item.json
+		return s.resolveReferences(ps.PluginID, item.json)
 	}
 
-	json, err := s.secretsService.DecryptJsonData(context.Background(), ps.SecureJSONData)
+	json, err := s.decryptedValues(context.Background(), ps.OrgID, ps.PluginID, ps.SecureJSONData)
 	if err != nil {
 		s.logger.Error("Failed to decrypt secure json data", "error", err)
 		return map[string]string{}
@@ -132,7 +150,138 @@ func (s *Service) DecryptedValues(ps *pluginsettings.DTO) map[string]string {
 		json:    json,
 	}
 
-	return json
+	return s.resolveReferences(ps.PluginID, json)
+}
+
+// decryptedValues returns the secure settings of a plugin from the secrets store, falling back
+// to the legacy secure_json_data column for settings that have not been migrated yet.
+func (s *Service) decryptedValues(ctx context.Context, orgID int64, pluginID string, legacy map[string][]byte) (map[string]string, error) {
+	secret, exist, err := s.secretsStore.Get(ctx, orgID, pluginID, kvstore.PluginSettingSecretType)
+	if err != nil {
+		return nil, err
+	}
+
+	if exist {
+		decryptedValues := make(map[string]string)
+		err = json.Unmarshal([]byte(secret), &decryptedValues)
+		if err == nil {
+			return decryptedValues, nil
+		}
+		s.logger.Debug("failed to unmarshal secret value, using legacy secrets", "err", err)
+	}
+
+	return s.secretsService.DecryptJsonData(secrets.ContextWithOrgScope(ctx, orgID), legacy)
+}
+
+// resolveReferences replaces secret references like $__file{/run/secrets/token} by the value they
+// point to. The values are resolved each time so rotated secrets are picked up without a restart.
+func (s *Service) resolveReferences(pluginID string, values map[string]string) map[string]string {
+	if !s.referencesEnabled {
+		return values
+	}
+
+	resolved := make(map[string]string, len(values))
+	for key, value := range values {
+		if !setting.GetExpanderRegex().MatchString(value) {
+			resolved[key] = value
+			continue
+		}
+
+		expanded, err := setting.ExpandVar(value)
+		if err != nil {
+			s.logger.Error("Failed to resolve secret reference", "pluginId", pluginID, "key", key, "error", err)
+			continue
+		}
+		resolved[key] = expanded
+	}
+	return resolved
+}
+
+func (s *Service) MigrateSecrets(ctx context.Context) error {
+	var settings []*models.PluginSetting
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Find(&settings)
+	})
+	if err != nil {
+		return err
+	}
+
+	disableSecretsCompatibility := s.features.IsEnabled(featuremgmt.FlagDisableSecretsCompatibility)
+	for _, ps := range settings {
+		values, err := s.decryptedValues(ctx, ps.OrgId, ps.PluginId, ps.SecureJsonData)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt secrets of plugin %s in org %d: %w", ps.PluginId, ps.OrgId, err)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		legacy := map[string][]byte{}
+		if !disableSecretsCompatibility {
+			legacy, err = s.secretsService.EncryptJsonData(ctx, values, secrets.WithOrgScope(ps.OrgId))
+			if err != nil {
+				return err
+			}
+		}
+
+		secret, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+
+		err = s.db.InTransaction(ctx, func(ctx context.Context) error {
+			if err := s.secretsStore.Set(ctx, ps.OrgId, ps.PluginId, kvstore.PluginSettingSecretType, string(secret)); err != nil {
+				return err
+			}
+			// updated is left as is since the decrypted values do not change
+			return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.ID(ps.Id).Cols("secure_json_data").Update(&models.PluginSetting{SecureJsonData: legacy})
+				return err
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) LegacySecrets(ctx context.Context) ([]*pluginsettings.LegacySecretsInfo, error) {
+	var settings []*models.PluginSetting
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Cols("id", "org_id", "plugin_id", "secure_json_data").OrderBy("org_id, plugin_id").Find(&settings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pluginsettings.LegacySecretsInfo, 0)
+	for _, ps := range settings {
+		if len(ps.SecureJsonData) == 0 {
+			continue
+		}
+
+		keys := make([]string, 0, len(ps.SecureJsonData))
+		for key := range ps.SecureJsonData {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		_, migrated, err := s.secretsStore.Get(ctx, ps.OrgId, ps.PluginId, kvstore.PluginSettingSecretType)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &pluginsettings.LegacySecretsInfo{
+			OrgID:    ps.OrgId,
+			PluginID: ps.PluginId,
+			Keys:     keys,
+			Migrated: migrated,
+		})
+	}
+
+	return result, nil
 }
 
 func (s *Service) getPluginSettingsInfo(ctx context.Context, orgID int64) ([]*models.PluginSettingInfo, error) {
@@ -170,6 +319,13 @@ func (s *Service) getPluginSettingById(ctx context.Context, query *models.GetPlu
 }
 
 func (s *Service) updatePluginSetting(ctx context.Context, cmd *models.UpdatePluginSettingCmd) error {
+	// the secrets store uses the session of the context so both are updated in the same transaction
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.updatePluginSettingInTransaction(ctx, cmd)
+	})
+}
+
+func (s *Service) updatePluginSettingInTransaction(ctx context.Context, cmd *models.UpdatePluginSettingCmd) error {
 	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var pluginSetting models.PluginSetting
 
@@ -179,6 +335,11 @@ func (s *Service) updatePluginSetting(ctx context.Context, cmd *models.UpdatePlu
 		}
 		sess.UseBool("enabled")
 		sess.UseBool("pinned")
+
+		if err := s.storeSecrets(ctx, cmd, &pluginSetting, exists); err != nil {
+			return err
+		}
+
 		if !exists {
 			pluginSetting = models.PluginSetting{
 				PluginId:       cmd.PluginId,
@@ -203,7 +364,14 @@ func (s *Service) updatePluginSetting(ctx context.Context, cmd *models.UpdatePlu
 			return err
 		}
 
+		if s.features.IsEnabled(featuremgmt.FlagDisableSecretsCompatibility) {
+			pluginSetting.SecureJsonData = map[string][]byte{}
+			sess.MustCols("secure_json_data")
+		}
 		for key, encryptedData := range cmd.EncryptedSecureJsonData {
+			if pluginSetting.SecureJsonData == nil {
+				pluginSetting.SecureJsonData = map[string][]byte{}
+			}
 			pluginSetting.SecureJsonData[key] = encryptedData
 		}
 
@@ -227,6 +395,32 @@ func (s *Service) updatePluginSetting(ctx context.Context, cmd *models.UpdatePlu
 	})
 }
 
+// storeSecrets merges the updated secure settings into the ones already stored for the plugin
+// and saves them in the secrets store.
+func (s *Service) storeSecrets(ctx context.Context, cmd *models.UpdatePluginSettingCmd, existing *models.PluginSetting, exists bool) error {
+	if len(cmd.SecureJsonData) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string)
+	if exists {
+		var err error
+		values, err = s.decryptedValues(ctx, cmd.OrgId, cmd.PluginId, existing.SecureJsonData)
+		if err != nil {
+			return err
+		}
+	}
+	for key, value := range cmd.SecureJsonData {
+		values[key] = value
+	}
+
+	secret, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return s.secretsStore.Set(ctx, cmd.OrgId, cmd.PluginId, kvstore.PluginSettingSecretType, string(secret))
+}
+
 func (s *Service) updatePluginSettingVersion(ctx context.Context, cmd *models.UpdatePluginSettingVersionCmd) error {
 	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		_, err := sess.Exec("UPDATE plugin_setting SET plugin_version=? WHERE org_id=? AND plugin_id=?", cmd.PluginVersion, cmd.OrgId, cmd.PluginId)
@@ -9,10 +9,13 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestService_DecryptedValuesCache(t *testing.T) {
@@ -20,7 +23,7 @@ func TestService_DecryptedValuesCache(t *testing.T) {
 		ctx := context.Background()
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		psService := ProvideService(nil, secretsService)
+		psService := ProvideService(nil, secretsService, kvstore.NewFakeSecretsKVStore(), featuremgmt.WithFeatures(), setting.NewCfg())
 
 		encryptedJsonData, err := secretsService.EncryptJsonData(
 			ctx,
@@ -58,7 +61,7 @@ func TestService_DecryptedValuesCache(t *testing.T) {
 		ctx := context.Background()
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		psService := ProvideService(nil, secretsService)
+		psService := ProvideService(nil, secretsService, kvstore.NewFakeSecretsKVStore(), featuremgmt.WithFeatures(), setting.NewCfg())
 
 		encryptedJsonData, err := secretsService.EncryptJsonData(
 			ctx,
@@ -94,13 +97,45 @@ func TestService_DecryptedValuesCache(t *testing.T) {
 	})
 }
 
+func TestService_DecryptedValuesReferences(t *testing.T) {
+	t.Setenv("GF_TEST_PLUGIN_TOKEN", "token")
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+
+	encryptedJsonData, err := secretsService.EncryptJsonData(context.Background(), map[string]string{
+		"token":   "$__env{GF_TEST_PLUGIN_TOKEN}",
+		"invalid": "$__file{/does/not/exist}",
+	}, secrets.WithoutScope())
+	require.NoError(t, err)
+
+	ps := &pluginsettings.DTO{ID: 1, SecureJSONData: encryptedJsonData}
+
+	t.Run("References should not be resolved by default", func(t *testing.T) {
+		psService := ProvideService(nil, secretsService, kvstore.NewFakeSecretsKVStore(), featuremgmt.WithFeatures(), setting.NewCfg())
+		require.Equal(t, "$__env{GF_TEST_PLUGIN_TOKEN}", psService.DecryptedValues(ps)["token"])
+	})
+
+	t.Run("References should be resolved when enabled", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.PluginSecretReferencesEnabled = true
+		psService := ProvideService(nil, secretsService, kvstore.NewFakeSecretsKVStore(), featuremgmt.WithFeatures(), cfg)
+
+		values := psService.DecryptedValues(ps)
+		require.Equal(t, map[string]string{"token": "token"}, values)
+
+		// cached values are resolved again
+		values = psService.DecryptedValues(ps)
+		require.Equal(t, map[string]string{"token": "token"}, values)
+	})
+}
+
 func TestIntegrationPluginSettings(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
 	}
 	store := db.InitTestDB(t)
 	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-	psService := ProvideService(store, secretsService)
+	secretsStore := kvstore.NewFakeSecretsKVStore()
+	psService := ProvideService(store, secretsService, secretsStore, featuremgmt.WithFeatures(), setting.NewCfg())
 
 	t.Run("Existing plugin settings", func(t *testing.T) {
 		secureJsonData, err := secretsService.EncryptJsonData(context.Background(), map[string]string{"secureKey": "secureValue"}, secrets.WithoutScope())
@@ -220,6 +255,75 @@ func TestIntegrationPluginSettings(t *testing.T) {
 				require.Equal(t, "1.0.2", ps.PluginVersion)
 				require.True(t, ps.Pinned)
 			})
+
+			t.Run("Secrets should be stored in the secrets store", func(t *testing.T) {
+				secret, exist, err := secretsStore.Get(context.Background(), existing.OrgId, existing.PluginId, kvstore.PluginSettingSecretType)
+				require.NoError(t, err)
+				require.True(t, exist)
+				require.JSONEq(t, `{"secureKey":"secureValue","secureKey2":"secureValue2"}`, secret)
+			})
+		})
+	})
+
+	t.Run("Legacy secrets", func(t *testing.T) {
+		secureJsonData, err := secretsService.EncryptJsonData(context.Background(), map[string]string{"token": "secret"}, secrets.WithoutScope())
+		require.NoError(t, err)
+
+		legacy := models.PluginSetting{
+			OrgId:          2,
+			PluginId:       "legacy",
+			JsonData:       map[string]interface{}{},
+			SecureJsonData: secureJsonData,
+			Created:        time.Now(),
+			Updated:        time.Now(),
+		}
+		err = store.WithTransactionalDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(&legacy)
+			return err
+		})
+		require.NoError(t, err)
+
+		t.Run("LegacySecrets should list plugin settings with secrets in the legacy column", func(t *testing.T) {
+			infos, err := psService.LegacySecrets(context.Background())
+			require.NoError(t, err)
+			require.Len(t, infos, 2)
+			require.Equal(t, "existing", infos[0].PluginID)
+			require.Equal(t, []string{"secureKey", "secureKey2"}, infos[0].Keys)
+			require.True(t, infos[0].Migrated)
+			require.Equal(t, "legacy", infos[1].PluginID)
+			require.Equal(t, []string{"token"}, infos[1].Keys)
+			require.False(t, infos[1].Migrated)
+		})
+
+		t.Run("MigrateSecrets should copy legacy secrets to the secrets store", func(t *testing.T) {
+			err := psService.MigrateSecrets(context.Background())
+			require.NoError(t, err)
+
+			secret, exist, err := secretsStore.Get(context.Background(), legacy.OrgId, legacy.PluginId, kvstore.PluginSettingSecretType)
+			require.NoError(t, err)
+			require.True(t, exist)
+			require.JSONEq(t, `{"token":"secret"}`, secret)
+
+			infos, err := psService.LegacySecrets(context.Background())
+			require.NoError(t, err)
+			for _, info := range infos {
+				require.True(t, info.Migrated)
+			}
+		})
+
+		t.Run("MigrateSecrets should clear the legacy column when secrets compatibility is disabled", func(t *testing.T) {
+			psService := ProvideService(store, secretsService, secretsStore,
+				featuremgmt.WithFeatures(featuremgmt.FlagDisableSecretsCompatibility), setting.NewCfg())
+			err := psService.MigrateSecrets(context.Background())
+			require.NoError(t, err)
+
+			infos, err := psService.LegacySecrets(context.Background())
+			require.NoError(t, err)
+			require.Empty(t, infos)
+
+			ps, err := psService.GetPluginSettingByPluginID(context.Background(), &pluginsettings.GetByPluginIDArgs{OrgID: legacy.OrgId, PluginID: legacy.PluginId})
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"token": "secret"}, psService.DecryptedValues(ps))
 		})
 	})
 
@@ -0,0 +1,263 @@
+package accesscontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	memberPermission = "Member"
+	adminPermission  = "Admin"
+)
+
+// Provision scans a directory for provisioning config files and provisions the teams, team
+// memberships, custom roles and role bindings in those files. It returns the number of teams
+// and roles which were applied.
+func Provision(ctx context.Context, configDirectory string, sqlStore db.DB, teamService team.Service,
+	teamPermissionsService ac.TeamPermissionsService, userService user.Service, orgService org.Service) (int, error) {
+	logger := log.New("provisioning.accesscontrol")
+	p := &AccessControlProvisioner{
+		log:                    logger,
+		cfgProvider:            &configReader{log: logger, orgService: orgService},
+		sqlStore:               sqlStore,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		userService:            userService,
+	}
+	err := p.applyChanges(ctx, configDirectory)
+	return p.applied, err
+}
+
+// AccessControlProvisioner is responsible for provisioning teams and custom roles based on
+// configuration read by the `configReader`.
+type AccessControlProvisioner struct {
+	log                    log.Logger
+	cfgProvider            *configReader
+	sqlStore               db.DB
+	teamService            team.Service
+	teamPermissionsService ac.TeamPermissionsService
+	userService            user.Service
+	applied                int
+}
+
+func (p *AccessControlProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := p.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range configs {
+		if err := p.apply(ctx, cfg); err != nil {
+			return err
+		}
+		p.applied += len(cfg.Teams) + len(cfg.DeleteTeams) + len(cfg.Roles) + len(cfg.DeleteRoles)
+	}
+
+	return nil
+}
+
+// apply reconciles the teams before the roles, so roles can be bound to teams declared in the same file.
+func (p *AccessControlProvisioner) apply(ctx context.Context, cfg *accessControlConfig) error {
+	for _, role := range cfg.DeleteRoles {
+		p.log.Info("Deleting role from configuration", "name", role.Name, "orgId", role.OrgID)
+		if err := p.deleteRole(ctx, role.OrgID, role.Name); err != nil {
+			return fmt.Errorf("failed to delete role %q: %w", role.Name, err)
+		}
+	}
+
+	for _, t := range cfg.DeleteTeams {
+		if err := p.deleteTeam(ctx, t); err != nil {
+			return fmt.Errorf("failed to delete team %q: %w", t.Name, err)
+		}
+	}
+
+	for _, t := range cfg.Teams {
+		if err := p.saveTeam(ctx, t); err != nil {
+			return fmt.Errorf("failed to provision team %q: %w", t.Name, err)
+		}
+	}
+
+	for _, role := range cfg.Roles {
+		bindings, err := p.resolveBindings(ctx, role)
+		if err != nil {
+			return fmt.Errorf("failed to provision role %q: %w", role.Name, err)
+		}
+		p.log.Debug("Saving role from configuration", "name", role.Name, "orgId", role.OrgID)
+		if err := p.saveRole(ctx, role, bindings); err != nil {
+			return fmt.Errorf("failed to provision role %q: %w", role.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *AccessControlProvisioner) deleteTeam(ctx context.Context, t *deleteTeamConfig) error {
+	existing, err := p.getTeamByName(ctx, t.OrgID, t.Name)
+	if err != nil || existing == nil {
+		return err
+	}
+
+	p.log.Info("Deleting team from configuration", "name", t.Name, "orgId", t.OrgID)
+	return p.teamService.DeleteTeam(ctx, &models.DeleteTeamCommand{OrgId: t.OrgID, Id: existing.Id})
+}
+
+// saveTeam creates or updates the team and reconciles its members with the declared ones. Members
+// synchronized from an external auth provider are kept.
+func (p *AccessControlProvisioner) saveTeam(ctx context.Context, t *teamFromConfig) error {
+	existing, err := p.getTeamByName(ctx, t.OrgID, t.Name)
+	if err != nil {
+		return err
+	}
+
+	var teamID int64
+	if existing == nil {
+		p.log.Info("Inserting team from configuration", "name", t.Name, "orgId", t.OrgID)
+		created, err := p.teamService.CreateTeam(t.Name, t.Email, t.OrgID)
+		if err != nil {
+			return err
+		}
+		teamID = created.Id
+	} else {
+		p.log.Debug("Updating team from configuration", "name", t.Name, "orgId", t.OrgID)
+		if err := p.teamService.UpdateTeam(ctx, &models.UpdateTeamCommand{Id: existing.Id, Name: t.Name, Email: t.Email, OrgId: t.OrgID}); err != nil {
+			return err
+		}
+		teamID = existing.Id
+	}
+
+	query := &models.GetTeamMembersQuery{OrgId: t.OrgID, TeamId: teamID, SignedInUser: provisionerUser(t.OrgID)}
+	if err := p.teamService.GetTeamMembers(ctx, query); err != nil {
+		return err
+	}
+	current := make(map[int64]*models.TeamMemberDTO, len(query.Result))
+	for _, m := range query.Result {
+		current[m.UserId] = m
+	}
+
+	declared := make(map[int64]bool, len(t.Members))
+	for _, member := range t.Members {
+		userID, err := p.getUserID(ctx, member.Login, member.Email)
+		if err != nil {
+			return err
+		}
+		declared[userID] = true
+
+		permission := member.Permission
+		if permission == "" {
+			permission = memberPermission
+		}
+		if m, ok := current[userID]; ok && getPermissionName(m.Permission) == permission {
+			continue
+		}
+		if err := p.setTeamPermission(ctx, t.OrgID, teamID, userID, permission); err != nil {
+			return err
+		}
+	}
+
+	for userID, m := range current {
+		if declared[userID] || m.External {
+			continue
+		}
+		p.log.Info("Removing team member missing in configuration", "team", t.Name, "userId", userID)
+		if err := p.setTeamPermission(ctx, t.OrgID, teamID, userID, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveBindings returns the IDs of the teams and users the role is bound to.
+func (p *AccessControlProvisioner) resolveBindings(ctx context.Context, role *roleFromConfig) (roleBindings, error) {
+	bindings := roleBindings{builtInRoles: role.BuiltInRoles}
+	for _, name := range role.Teams {
+		t, err := p.getTeamByName(ctx, role.OrgID, name)
+		if err != nil {
+			return bindings, err
+		}
+		if t == nil {
+			return bindings, fmt.Errorf("team %q: %w", name, models.ErrTeamNotFound)
+		}
+		bindings.teamIDs = append(bindings.teamIDs, t.Id)
+	}
+	for _, loginOrEmail := range role.Users {
+		userID, err := p.getUserID(ctx, loginOrEmail, "")
+		if err != nil {
+			return bindings, err
+		}
+		bindings.userIDs = append(bindings.userIDs, userID)
+	}
+	return bindings, nil
+}
+
+func (p *AccessControlProvisioner) getTeamByName(ctx context.Context, orgID int64, name string) (*models.TeamDTO, error) {
+	query := &models.SearchTeamsQuery{
+		OrgId:        orgID,
+		Name:         name,
+		Limit:        1,
+		Page:         1,
+		UserIdFilter: models.FilterIgnoreUser,
+		SignedInUser: provisionerUser(orgID),
+	}
+	if err := p.teamService.SearchTeams(ctx, query); err != nil {
+		return nil, err
+	}
+	if len(query.Result.Teams) == 0 {
+		return nil, nil
+	}
+	return query.Result.Teams[0], nil
+}
+
+func (p *AccessControlProvisioner) getUserID(ctx context.Context, login, email string) (int64, error) {
+	loginOrEmail := login
+	if loginOrEmail == "" {
+		loginOrEmail = email
+	}
+	u, err := p.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: loginOrEmail})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return 0, fmt.Errorf("user %q: %w", loginOrEmail, err)
+		}
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// setTeamPermission adds, updates or, with an empty permission, removes the team member through
+// the team permissions, so the managed permissions of the member are updated as well.
+func (p *AccessControlProvisioner) setTeamPermission(ctx context.Context, orgID, teamID, userID int64, permission string) error {
+	_, err := p.teamPermissionsService.SetUserPermission(ctx, orgID, ac.User{ID: userID}, strconv.FormatInt(teamID, 10), permission)
+	return err
+}
+
+func getPermissionName(permission models.PermissionType) string {
+	if permission == models.PERMISSION_ADMIN {
+		return adminPermission
+	}
+	return memberPermission
+}
+
+// provisionerUser is the identity used to look up teams and their members.
+func provisionerUser(orgID int64) *user.SignedInUser {
+	return &user.SignedInUser{
+		OrgID:   orgID,
+		OrgRole: org.RoleAdmin,
+		Login:   "grafana-provisioning",
+		Permissions: map[int64]map[string][]string{
+			orgID: {
+				ac.ActionTeamsRead:    {ac.ScopeTeamsAll},
+				ac.ActionOrgUsersRead: {ac.ScopeUsersAll},
+			},
+		},
+	}
+}
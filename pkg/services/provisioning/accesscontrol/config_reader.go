@@ -0,0 +1,125 @@
+package accesscontrol
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	log        log.Logger
+	orgService org.Service
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*accessControlConfig, error) {
+	var configs []*accessControlConfig
+	cr.log.Debug("Looking for access control provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read access control provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+
+		filename := filepath.Join(path, file.Name())
+		cr.log.Debug("Parsing access control provisioning file", "path", path, "file.Name", file.Name())
+		cfg, err := cr.parseConfig(filename)
+		if err != nil {
+			return nil, utils.NewFileError(filename, nil, err)
+		}
+		if err := cr.validate(ctx, cfg); err != nil {
+			return nil, utils.NewFileError(filename, nil, err)
+		}
+		configs = append(configs, cfg)
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(filename string) (*accessControlConfig, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *accessControlConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+	return cfg.mapToAccessControlConfig(), nil
+}
+
+func (cr *configReader) validate(ctx context.Context, cfg *accessControlConfig) error {
+	orgIDs := map[int64]bool{}
+
+	for i, team := range cfg.Teams {
+		if team.Name == "" {
+			return fmt.Errorf("team item %d in configuration doesn't contain required field name", i+1)
+		}
+		for j, member := range team.Members {
+			if member.Login == "" && member.Email == "" {
+				return fmt.Errorf("member %d of team %q doesn't contain required field login or email", j+1, team.Name)
+			}
+			if member.Permission != "" && member.Permission != memberPermission && member.Permission != adminPermission {
+				return fmt.Errorf("member %d of team %q has invalid permission %q, must be %s or %s", j+1, team.Name, member.Permission, memberPermission, adminPermission)
+			}
+		}
+		orgIDs[team.OrgID] = true
+	}
+
+	for i, team := range cfg.DeleteTeams {
+		if team.Name == "" {
+			return fmt.Errorf("deleted team item %d in configuration doesn't contain required field name", i+1)
+		}
+	}
+
+	for i, role := range cfg.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("role item %d in configuration doesn't contain required field name", i+1)
+		}
+		if !strings.HasPrefix(role.Name, ac.CustomRolePrefix) {
+			return fmt.Errorf("role %q must have a name starting with %q", role.Name, ac.CustomRolePrefix)
+		}
+		for j, permission := range role.Permissions {
+			if permission.Action == "" {
+				return fmt.Errorf("permission %d of role %q doesn't contain required field action", j+1, role.Name)
+			}
+		}
+		for _, builtInRole := range role.BuiltInRoles {
+			if !org.RoleType(builtInRole).IsValid() && builtInRole != ac.RoleGrafanaAdmin {
+				return fmt.Errorf("role %q is assigned to invalid basic role %q", role.Name, builtInRole)
+			}
+		}
+		orgIDs[role.OrgID] = true
+	}
+
+	for i, role := range cfg.DeleteRoles {
+		if role.Name == "" {
+			return fmt.Errorf("deleted role item %d in configuration doesn't contain required field name", i+1)
+		}
+	}
+
+	for orgID := range orgIDs {
+		if err := utils.CheckOrgExists(ctx, cr.orgService, orgID); err != nil {
+			return fmt.Errorf("failed to provision teams and roles in org %d: %w", orgID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package accesscontrol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+)
+
+const (
+	correctConfig     = "./testdata/test-configs/correct"
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	invalidRoleName   = "./testdata/test-configs/invalid-role-name"
+	invalidPermission = "./testdata/test-configs/invalid-permission"
+)
+
+func TestConfigReader(t *testing.T) {
+	reader := &configReader{log: log.New("test logger"), orgService: orgtest.NewOrgServiceFake()}
+
+	t.Run("Can read correct config", func(t *testing.T) {
+		cfgs, err := reader.readConfig(context.Background(), correctConfig)
+		require.NoError(t, err)
+		require.Len(t, cfgs, 1)
+		cfg := cfgs[0]
+
+		require.Equal(t, []*deleteTeamConfig{{OrgID: 1, Name: "Old team"}}, cfg.DeleteTeams)
+		require.Equal(t, []*teamFromConfig{{
+			OrgID: 1,
+			Name:  "Platform",
+			Email: "platform@example.com",
+			Members: []*memberFromConfig{
+				{Login: "alice", Permission: adminPermission},
+				{Email: "bob@example.com"},
+			},
+		}}, cfg.Teams)
+
+		require.Len(t, cfg.Roles, 1)
+		role := cfg.Roles[0]
+		require.Equal(t, int64(1), role.OrgID)
+		require.Equal(t, "custom:dashboards:reader", role.Name)
+		require.Equal(t, "Dashboards reader", role.DisplayName)
+		require.Equal(t, []*permissionFromConfig{
+			{Action: "dashboards:read", Scope: "dashboards:*"},
+			{Action: "folders:read", Scope: "folders:*"},
+		}, role.Permissions)
+		require.Equal(t, []string{"Viewer"}, role.BuiltInRoles)
+		require.Equal(t, []string{"Platform"}, role.Teams)
+		require.Equal(t, []string{"alice"}, role.Users)
+
+		require.Equal(t, []*deleteRoleConfig{{OrgID: 1, Name: "custom:old"}}, cfg.DeleteRoles)
+	})
+
+	t.Run("Broken yaml should return error with file", func(t *testing.T) {
+		_, err := reader.readConfig(context.Background(), brokenYaml)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "access-control.yaml")
+	})
+
+	t.Run("Role without custom prefix should return error", func(t *testing.T) {
+		_, err := reader.readConfig(context.Background(), invalidRoleName)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `role "dashboards:reader" must have a name starting with "custom:"`)
+	})
+
+	t.Run("Invalid member permission should return error", func(t *testing.T) {
+		_, err := reader.readConfig(context.Background(), invalidPermission)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `member 1 of team "Platform" has invalid permission "Owner"`)
+	})
+
+	t.Run("Missing directory should be skipped", func(t *testing.T) {
+		cfgs, err := reader.readConfig(context.Background(), "./testdata/test-configs/missing")
+		require.NoError(t, err)
+		require.Len(t, cfgs, 0)
+	})
+}
@@ -0,0 +1,109 @@
+package accesscontrol
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// roleBindings are the IDs of the basic roles, teams and users a role is assigned to.
+type roleBindings struct {
+	builtInRoles []string
+	teamIDs      []int64
+	userIDs      []int64
+}
+
+// saveRole creates or updates the custom role in its organization and replaces its permissions
+// and bindings.
+func (p *AccessControlProvisioner) saveRole(ctx context.Context, role *roleFromConfig, bindings roleBindings) error {
+	return p.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		now := time.Now()
+
+		existing := ac.Role{}
+		exists, err := sess.Where("org_id = ? AND name = ?", role.OrgID, role.Name).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		r := ac.Role{
+			OrgID:       role.OrgID,
+			Name:        role.Name,
+			DisplayName: role.DisplayName,
+			Description: role.Description,
+			Group:       role.Group,
+			Hidden:      role.Hidden,
+			Updated:     now,
+		}
+		if exists {
+			r.ID = existing.ID
+			r.UID = existing.UID
+			r.Version = existing.Version + 1
+			if _, err := sess.ID(r.ID).AllCols().Omit("created").Update(&r); err != nil {
+				return err
+			}
+		} else {
+			r.UID = util.GenerateShortUID()
+			r.Version = 1
+			r.Created = now
+			if _, err := sess.Insert(&r); err != nil {
+				return err
+			}
+		}
+
+		if err := deleteRoleAssignments(sess, r.ID); err != nil {
+			return err
+		}
+
+		for _, permission := range role.Permissions {
+			if _, err := sess.Insert(&ac.Permission{RoleID: r.ID, Action: permission.Action, Scope: permission.Scope, Created: now, Updated: now}); err != nil {
+				return err
+			}
+		}
+		for _, builtInRole := range bindings.builtInRoles {
+			if _, err := sess.Insert(&ac.BuiltinRole{RoleID: r.ID, OrgID: role.OrgID, Role: builtInRole, Created: now, Updated: now}); err != nil {
+				return err
+			}
+		}
+		for _, teamID := range bindings.teamIDs {
+			if _, err := sess.Insert(&ac.TeamRole{RoleID: r.ID, OrgID: role.OrgID, TeamID: teamID, Created: now}); err != nil {
+				return err
+			}
+		}
+		for _, userID := range bindings.userIDs {
+			if _, err := sess.Insert(&ac.UserRole{RoleID: r.ID, OrgID: role.OrgID, UserID: userID, Created: now}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteRole deletes the custom role from its organization with its permissions and bindings.
+func (p *AccessControlProvisioner) deleteRole(ctx context.Context, orgID int64, name string) error {
+	return p.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		role := ac.Role{}
+		exists, err := sess.Where("org_id = ? AND name = ?", orgID, name).Get(&role)
+		if err != nil || !exists {
+			return err
+		}
+
+		if err := deleteRoleAssignments(sess, role.ID); err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM role WHERE id = ?", role.ID)
+		return err
+	})
+}
+
+// deleteRoleAssignments deletes the permissions and the bindings of a role.
+func deleteRoleAssignments(sess *db.Session, roleID int64) error {
+	for _, table := range []string{"permission", "builtin_role", "team_role", "user_role"} {
+		if _, err := sess.Exec("DELETE FROM "+table+" WHERE role_id = ?", roleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package accesscontrol
+
+import (
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// configVersion is used to figure out which API version a config uses.
+type configVersion struct {
+	APIVersion values.Int64Value `json:"apiVersion" yaml:"apiVersion"`
+}
+
+// accessControlConfig is a normalized data object for teams and roles config data.
+type accessControlConfig struct {
+	Teams       []*teamFromConfig
+	DeleteTeams []*deleteTeamConfig
+	Roles       []*roleFromConfig
+	DeleteRoles []*deleteRoleConfig
+}
+
+type teamFromConfig struct {
+	OrgID   int64
+	Name    string
+	Email   string
+	Members []*memberFromConfig
+}
+
+type memberFromConfig struct {
+	Login      string
+	Email      string
+	Permission string
+}
+
+type deleteTeamConfig struct {
+	OrgID int64
+	Name  string
+}
+
+type roleFromConfig struct {
+	OrgID       int64
+	Name        string
+	DisplayName string
+	Description string
+	Group       string
+	Hidden      bool
+	Permissions []*permissionFromConfig
+	// BuiltInRoles, Teams and Users are the bindings of the role.
+	BuiltInRoles []string
+	Teams        []string
+	Users        []string
+}
+
+type permissionFromConfig struct {
+	Action string
+	Scope  string
+}
+
+type deleteRoleConfig struct {
+	OrgID int64
+	Name  string
+}
+
+type accessControlConfigV1 struct {
+	configVersion
+
+	Teams       []*teamFromConfigV1   `json:"teams" yaml:"teams"`
+	DeleteTeams []*deleteTeamConfigV1 `json:"deleteTeams" yaml:"deleteTeams"`
+	Roles       []*roleFromConfigV1   `json:"roles" yaml:"roles"`
+	DeleteRoles []*deleteRoleConfigV1 `json:"deleteRoles" yaml:"deleteRoles"`
+}
+
+type teamFromConfigV1 struct {
+	OrgID   values.Int64Value     `json:"orgId" yaml:"orgId"`
+	Name    values.StringValue    `json:"name" yaml:"name"`
+	Email   values.StringValue    `json:"email" yaml:"email"`
+	Members []*memberFromConfigV1 `json:"members" yaml:"members"`
+}
+
+type memberFromConfigV1 struct {
+	Login      values.StringValue `json:"login" yaml:"login"`
+	Email      values.StringValue `json:"email" yaml:"email"`
+	Permission values.StringValue `json:"permission" yaml:"permission"`
+}
+
+type deleteTeamConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name  values.StringValue `json:"name" yaml:"name"`
+}
+
+type roleFromConfigV1 struct {
+	OrgID        values.Int64Value         `json:"orgId" yaml:"orgId"`
+	Name         values.StringValue        `json:"name" yaml:"name"`
+	DisplayName  values.StringValue        `json:"displayName" yaml:"displayName"`
+	Description  values.StringValue        `json:"description" yaml:"description"`
+	Group        values.StringValue        `json:"group" yaml:"group"`
+	Hidden       values.BoolValue          `json:"hidden" yaml:"hidden"`
+	Permissions  []*permissionFromConfigV1 `json:"permissions" yaml:"permissions"`
+	BuiltInRoles []values.StringValue      `json:"builtInRoles" yaml:"builtInRoles"`
+	Teams        []values.StringValue      `json:"teams" yaml:"teams"`
+	Users        []values.StringValue      `json:"users" yaml:"users"`
+}
+
+type permissionFromConfigV1 struct {
+	Action values.StringValue `json:"action" yaml:"action"`
+	Scope  values.StringValue `json:"scope" yaml:"scope"`
+}
+
+type deleteRoleConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name  values.StringValue `json:"name" yaml:"name"`
+}
+
+// mapToAccessControlConfig maps config syntax to a normalized accessControlConfig object.
+func (cfg *accessControlConfigV1) mapToAccessControlConfig() *accessControlConfig {
+	r := &accessControlConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, team := range cfg.Teams {
+		t := &teamFromConfig{
+			OrgID: orgIDOrDefault(team.OrgID.Value()),
+			Name:  team.Name.Value(),
+			Email: team.Email.Value(),
+		}
+		for _, member := range team.Members {
+			t.Members = append(t.Members, &memberFromConfig{
+				Login:      member.Login.Value(),
+				Email:      member.Email.Value(),
+				Permission: member.Permission.Value(),
+			})
+		}
+		r.Teams = append(r.Teams, t)
+	}
+
+	for _, team := range cfg.DeleteTeams {
+		r.DeleteTeams = append(r.DeleteTeams, &deleteTeamConfig{
+			OrgID: orgIDOrDefault(team.OrgID.Value()),
+			Name:  team.Name.Value(),
+		})
+	}
+
+	for _, role := range cfg.Roles {
+		ro := &roleFromConfig{
+			OrgID:        orgIDOrDefault(role.OrgID.Value()),
+			Name:         role.Name.Value(),
+			DisplayName:  role.DisplayName.Value(),
+			Description:  role.Description.Value(),
+			Group:        role.Group.Value(),
+			Hidden:       role.Hidden.Value(),
+			BuiltInRoles: stringValues(role.BuiltInRoles),
+			Teams:        stringValues(role.Teams),
+			Users:        stringValues(role.Users),
+		}
+		for _, permission := range role.Permissions {
+			ro.Permissions = append(ro.Permissions, &permissionFromConfig{
+				Action: permission.Action.Value(),
+				Scope:  permission.Scope.Value(),
+			})
+		}
+		r.Roles = append(r.Roles, ro)
+	}
+
+	for _, role := range cfg.DeleteRoles {
+		r.DeleteRoles = append(r.DeleteRoles, &deleteRoleConfig{
+			OrgID: orgIDOrDefault(role.OrgID.Value()),
+			Name:  role.Name.Value(),
+		})
+	}
+
+	return r
+}
+
+func orgIDOrDefault(orgID int64) int64 {
+	if orgID < 1 {
+		return 1
+	}
+	return orgID
+}
+
+func stringValues(vals []values.StringValue) []string {
+	r := make([]string, 0, len(vals))
+	for _, v := range vals {
+		r = append(r, v.Value())
+	}
+	return r
+}
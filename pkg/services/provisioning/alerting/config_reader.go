@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"gopkg.in/yaml.v2"
 )
 
@@ -40,13 +41,13 @@ func (cr *rulesConfigReader) readConfig(ctx context.Context, path string) ([]*Al
 		}
 		alertFileV1, err := cr.parseConfig(path, file)
 		if err != nil {
-			return nil, fmt.Errorf("failure to parse file %s: %w", file.Name(), err)
+			return nil, utils.NewFileError(filepath.Join(path, file.Name()), nil, fmt.Errorf("failure to parse file: %w", err))
 		}
 		if alertFileV1 != nil {
 			alertFileV1.Filename = file.Name()
 			alertFile, err := alertFileV1.MapToModel()
 			if err != nil {
-				return nil, fmt.Errorf("failure to map file %s: %w", alertFileV1.Filename, err)
+				return nil, utils.NewFileError(filepath.Join(path, file.Name()), nil, fmt.Errorf("failure to map file: %w", err))
 			}
 			alertFiles = append(alertFiles, &alertFile)
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/org"
 )
 
 type ProvisionerConfig struct {
@@ -18,14 +19,17 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	OrgService                 org.Service
 }
 
-func Provision(ctx context.Context, cfg ProvisionerConfig) error {
+// Provision applies the alerting files of the provisioning path and returns the number of
+// alerting resources which were applied.
+func Provision(ctx context.Context, cfg ProvisionerConfig) (int, error) {
 	logger := log.New("provisioning.alerting")
 	cfgReader := newRulesConfigReader(logger)
 	files, err := cfgReader.readConfig(ctx, cfg.Path)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	logger.Info("starting to provision alerting")
 	logger.Debug("read all alerting files", "file_count", len(files))
@@ -36,44 +40,57 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 		cfg.RuleService)
 	err = ruleProvisioner.Provision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("alert rules: %w", err)
+		return 0, fmt.Errorf("alert rules: %w", err)
 	}
 	cpProvisioner := NewContactPointProvisoner(logger, cfg.ContactPointService)
 	err = cpProvisioner.Provision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("contact points: %w", err)
+		return 0, fmt.Errorf("contact points: %w", err)
 	}
 	mtProvisioner := NewMuteTimesProvisioner(logger, cfg.MuteTimingService)
 	err = mtProvisioner.Provision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("mute times: %w", err)
+		return 0, fmt.Errorf("mute times: %w", err)
 	}
 	ttProvsioner := NewTextTemplateProvisioner(logger, cfg.TemplateService)
 	err = ttProvsioner.Provision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("text templates: %w", err)
+		return 0, fmt.Errorf("text templates: %w", err)
 	}
 	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService)
 	err = npProvisioner.Provision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("notification policies: %w", err)
+		return 0, fmt.Errorf("notification policies: %w", err)
 	}
 	err = npProvisioner.Unprovision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("notification policies: %w", err)
+		return 0, fmt.Errorf("notification policies: %w", err)
 	}
 	err = cpProvisioner.Unprovision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("contact points: %w", err)
+		return 0, fmt.Errorf("contact points: %w", err)
 	}
 	err = mtProvisioner.Unprovision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("mute times: %w", err)
+		return 0, fmt.Errorf("mute times: %w", err)
 	}
 	err = ttProvsioner.Unprovision(ctx, files)
 	if err != nil {
-		return fmt.Errorf("text templates: %w", err)
+		return 0, fmt.Errorf("text templates: %w", err)
 	}
 	logger.Info("finished to provision alerting")
-	return nil
+	return countResources(files), nil
+}
+
+func countResources(files []*AlertingFile) int {
+	count := 0
+	for _, file := range files {
+		for _, group := range file.Groups {
+			count += len(group.Rules)
+		}
+		count += len(file.DeleteRules) + len(file.ContactPoints) + len(file.DeleteContactPoints) +
+			len(file.Policies) + len(file.ResetPolicies) + len(file.MuteTimes) + len(file.DeleteMuteTimes) +
+			len(file.Templates) + len(file.DeleteTemplates)
+	}
+	return count
 }
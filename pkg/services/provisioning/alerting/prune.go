@@ -0,0 +1,68 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	alert_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// Prune removes the alert rules provisioned from files which are no longer declared in the
+// alerting files of the provisioning path. With dryRun, the alert rules are only returned.
+func Prune(ctx context.Context, cfg ProvisionerConfig, dryRun bool) ([]utils.PrunedResource, error) {
+	logger := log.New("provisioning.alerting")
+	reader := newRulesConfigReader(logger)
+	files, err := reader.readConfig(ctx, cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		// a missing or misconfigured provisioning directory must not remove all alert rules.
+		logger.Warn("Not pruning alert rules since no alerting files were found", "path", cfg.Path)
+		return nil, nil
+	}
+
+	declared := map[int64]map[string]bool{}
+	for _, file := range files {
+		for _, group := range file.Groups {
+			if declared[group.OrgID] == nil {
+				declared[group.OrgID] = map[string]bool{}
+			}
+			for _, rule := range group.Rules {
+				declared[group.OrgID][rule.UID] = true
+			}
+		}
+	}
+
+	orgIDs, err := utils.ListOrgIDs(ctx, cfg.OrgService)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := make([]utils.PrunedResource, 0)
+	for _, orgID := range orgIDs {
+		uids, err := cfg.RuleService.GetAlertRuleUIDsByProvenance(ctx, orgID, alert_models.ProvenanceFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, uid := range uids {
+			if declared[orgID][uid] {
+				continue
+			}
+			rule, _, err := cfg.RuleService.GetAlertRule(ctx, orgID, uid)
+			if err != nil && !errors.Is(err, alert_models.ErrAlertRuleNotFound) {
+				return nil, err
+			}
+			pruned = append(pruned, utils.PrunedResource{Kind: "alert rule", OrgID: orgID, UID: uid, Name: rule.Title})
+			if dryRun {
+				continue
+			}
+			if err := cfg.RuleService.DeleteAlertRule(ctx, orgID, uid, alert_models.ProvenanceFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pruned, nil
+}
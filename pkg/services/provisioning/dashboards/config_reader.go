@@ -81,7 +81,7 @@ func (cr *configReader) readConfig(ctx context.Context) ([]*config, error) {
 
 		parsedDashboards, err := cr.parseConfigs(file)
 		if err != nil {
-			return nil, fmt.Errorf("could not parse provisioning config file: %s error: %v", file.Name(), err)
+			return nil, utils.NewFileError(filepath.Join(cr.path, file.Name()), nil, fmt.Errorf("could not parse provisioning config file: %w", err))
 		}
 
 		if len(parsedDashboards) > 0 {
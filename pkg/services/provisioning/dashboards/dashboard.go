@@ -21,6 +21,7 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	GetStatus() []utils.ProvisioningStatus
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
@@ -85,11 +86,13 @@ func (provider *Provisioner) Provision(ctx context.Context) error {
 
 // CleanUpOrphanedDashboards deletes provisioned dashboards missing a linked reader.
 func (provider *Provisioner) CleanUpOrphanedDashboards(ctx context.Context) {
-	currentReaders := make([]string, len(provider.fileReaders))
+	currentReaders := make([]string, len(provider.fileReaders), len(provider.fileReaders)+1)
 
 	for index, reader := range provider.fileReaders {
 		currentReaders[index] = reader.Cfg.Name
 	}
+	// the copies of catalog dashboards are not provisioned from files
+	currentReaders = append(currentReaders, dashboards.CatalogProvisionerName)
 
 	if err := provider.provisioner.DeleteOrphanedProvisionedDashboards(ctx, &models.DeleteOrphanedProvisionedDashboardsCommand{ReaderNames: currentReaders}); err != nil {
 		provider.log.Warn("Failed to delete orphaned provisioned dashboards", "err", err)
@@ -127,6 +130,17 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// GetStatus returns the status of the last run of every dashboard provider which ran already.
+func (provider *Provisioner) GetStatus() []utils.ProvisioningStatus {
+	statuses := make([]utils.ProvisioningStatus, 0, len(provider.fileReaders))
+	for _, reader := range provider.fileReaders {
+		if status := reader.getStatus(); status != nil {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses
+}
+
 func getFileReaders(
 	configs []*config, logger log.Logger, service dashboards.DashboardProvisioningService, store utils.DashboardStore,
 ) ([]*FileReader, error) {
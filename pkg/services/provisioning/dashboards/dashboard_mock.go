@@ -1,6 +1,10 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -65,5 +69,10 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// GetStatus not implemented for mocks
+func (dpm *ProvisionerMock) GetStatus() []utils.ProvisioningStatus {
+	return nil
+}
+
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
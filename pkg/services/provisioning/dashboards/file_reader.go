@@ -39,6 +39,7 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+	status                  *utils.ProvisioningStatus
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -87,8 +88,18 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
-func (fr *FileReader) walkDisk(ctx context.Context) error {
+func (fr *FileReader) walkDisk(ctx context.Context) (err error) {
 	fr.log.Debug("Start walking disk", "path", fr.Path)
+	status := utils.NewProvisioningStatus("dashboards", fr.Cfg.Name, 0, nil)
+	defer func() {
+		if err != nil {
+			status.Errors = append(status.Errors, utils.ToProvisioningError(err))
+		}
+		fr.mux.Lock()
+		fr.status = &status
+		fr.mux.Unlock()
+	}()
+
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
 		return err
@@ -109,9 +120,9 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 
 	usageTracker := newUsageTracker()
 	if fr.FoldersFromFilesStructure {
-		err = fr.storeDashboardsInFoldersFromFileStructure(ctx, filesFoundOnDisk, provisionedDashboardRefs, resolvedPath, usageTracker)
+		err = fr.storeDashboardsInFoldersFromFileStructure(ctx, filesFoundOnDisk, provisionedDashboardRefs, resolvedPath, usageTracker, &status)
 	} else {
-		err = fr.storeDashboardsInFolder(ctx, filesFoundOnDisk, provisionedDashboardRefs, usageTracker)
+		err = fr.storeDashboardsInFolder(ctx, filesFoundOnDisk, provisionedDashboardRefs, usageTracker, &status)
 	}
 	if err != nil {
 		return err
@@ -124,6 +135,14 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 	return nil
 }
 
+// getStatus returns the status of the last walk of the disk, or nil if the disk was not walked yet.
+func (fr *FileReader) getStatus() *utils.ProvisioningStatus {
+	fr.mux.RLock()
+	defer fr.mux.RUnlock()
+
+	return fr.status
+}
+
 func (fr *FileReader) changeWritePermissions(restrict bool) {
 	fr.mux.Lock()
 	defer fr.mux.Unlock()
@@ -140,7 +159,7 @@ func (fr *FileReader) isDatabaseAccessRestricted() bool {
 
 // storeDashboardsInFolder saves dashboards from the filesystem on disk to the folder from config
 func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnDisk map[string]os.FileInfo,
-	dashboardRefs map[string]*models.DashboardProvisioning, usageTracker *usageTracker) error {
+	dashboardRefs map[string]*models.DashboardProvisioning, usageTracker *usageTracker, status *utils.ProvisioningStatus) error {
 	folderID, err := fr.getOrCreateFolderID(ctx, fr.Cfg, fr.dashboardProvisioningService, fr.Cfg.Folder)
 	if err != nil && !errors.Is(err, ErrFolderNameMissing) {
 		return err
@@ -151,10 +170,12 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, fileInfo, dashboardRefs)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
+			status.Errors = append(status.Errors, toFileError(path, err))
 			continue
 		}
 
 		usageTracker.track(provisioningMetadata)
+		status.Applied++
 	}
 	return nil
 }
@@ -162,7 +183,7 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 // storeDashboardsInFoldersFromFilesystemStructure saves dashboards from the filesystem on disk to the same folder
 // in Grafana as they are in on the filesystem.
 func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Context, filesFoundOnDisk map[string]os.FileInfo,
-	dashboardRefs map[string]*models.DashboardProvisioning, resolvedPath string, usageTracker *usageTracker) error {
+	dashboardRefs map[string]*models.DashboardProvisioning, resolvedPath string, usageTracker *usageTracker, status *utils.ProvisioningStatus) error {
 	for path, fileInfo := range filesFoundOnDisk {
 		folderName := ""
 
@@ -180,7 +201,10 @@ func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Cont
 		usageTracker.track(provisioningMetadata)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
+			status.Errors = append(status.Errors, toFileError(path, err))
+			continue
 		}
+		status.Applied++
 	}
 	return nil
 }
@@ -232,8 +256,7 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 
 	jsonFile, err := fr.readDashboardFromFile(path, resolvedFileInfo.ModTime(), folderID)
 	if err != nil {
-		fr.log.Error("failed to load dashboard from ", "file", path, "error", err)
-		return provisioningMetadata, nil
+		return provisioningMetadata, fmt.Errorf("failed to load dashboard: %w", err)
 	}
 
 	upToDate := alreadyProvisioned
@@ -313,6 +336,7 @@ func (fr *FileReader) getOrCreateFolderID(ctx context.Context, cfg *config, serv
 		dash.Dashboard.IsFolder = true
 		dash.Overwrite = true
 		dash.OrgId = cfg.OrgID
+		dash.Provisioner = cfg.Name
 		// set dashboard folderUid if given
 		if cfg.FolderUID == accesscontrol.GeneralFolderUID {
 			return 0, dashboards.ErrFolderInvalidUID
@@ -409,7 +433,7 @@ func (fr *FileReader) readDashboardFromFile(path string, lastModified time.Time,
 
 	data, err := simplejson.NewJson(all)
 	if err != nil {
-		return nil, err
+		return nil, utils.NewFileError(path, all, err)
 	}
 
 	dash, err := createDashboardJSON(data, lastModified, fr.Cfg, folderID)
@@ -424,6 +448,15 @@ func (fr *FileReader) readDashboardFromFile(path string, lastModified time.Time,
 	}, nil
 }
 
+// toFileError returns err as ProvisioningError of the dashboard file at path.
+func toFileError(path string, err error) utils.ProvisioningError {
+	provErr := utils.ToProvisioningError(err)
+	if provErr.File == "" {
+		provErr.File = path
+	}
+	return provErr
+}
+
 func (fr *FileReader) resolvedPath() string {
 	if _, err := os.Stat(fr.Path); os.IsNotExist(err) {
 		fr.log.Error("Cannot read directory", "error", err)
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -338,6 +339,47 @@ func validateDatasourceV1(t *testing.T, dsCfg *configs) {
 	}}, ds.Correlations)
 }
 
+func TestPruneDatasources(t *testing.T) {
+	newStore := func() *spyStore {
+		return &spyStore{items: []*datasources.DataSource{
+			{Name: "Graphite", OrgId: 1, Uid: "graphite", ReadOnly: true},
+			{Name: "Removed", OrgId: 1, Uid: "removed", ReadOnly: true},
+			{Name: "Created in UI", OrgId: 1, Uid: "ui"},
+		}}
+	}
+	orgFake := &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 1}, ExpectedOrgs: []*org.OrgDTO{{ID: 1}}}
+
+	t.Run("dry run only reports provisioned data sources which are no longer declared", func(t *testing.T) {
+		store := newStore()
+		dc := newDatasourceProvisioner(logger, store, &mockCorrelationsStore{}, orgFake)
+		pruned, err := dc.prune(context.Background(), twoDatasourcesConfig, store, true)
+		require.NoError(t, err)
+		require.Equal(t, []utils.PrunedResource{{Kind: "datasource", OrgID: 1, UID: "removed", Name: "Removed"}}, pruned)
+		require.Empty(t, store.deleted)
+	})
+
+	t.Run("removes provisioned data sources which are no longer declared", func(t *testing.T) {
+		store := newStore()
+		correlationsStore := &mockCorrelationsStore{}
+		dc := newDatasourceProvisioner(logger, store, correlationsStore, orgFake)
+		pruned, err := dc.prune(context.Background(), twoDatasourcesConfig, store, false)
+		require.NoError(t, err)
+		require.Len(t, pruned, 1)
+		require.Len(t, store.deleted, 1)
+		require.Equal(t, "Removed", store.deleted[0].Name)
+		require.Len(t, correlationsStore.deletedBySourceUID, 1)
+	})
+
+	t.Run("does not prune without config files", func(t *testing.T) {
+		store := newStore()
+		dc := newDatasourceProvisioner(logger, store, &mockCorrelationsStore{}, orgFake)
+		pruned, err := dc.prune(context.Background(), "testdata/zero-datasources", store, false)
+		require.NoError(t, err)
+		require.Empty(t, pruned)
+		require.Empty(t, store.deleted)
+	})
+}
+
 type mockCorrelationsStore struct {
 	created            []correlations.CreateCorrelationCommand
 	deletedBySourceUID []correlations.DeleteCorrelationsBySourceUIDCommand
@@ -377,6 +419,15 @@ func (s *spyStore) GetDataSource(ctx context.Context, query *datasources.GetData
 	return datasources.ErrDataSourceNotFound
 }
 
+func (s *spyStore) GetDataSources(ctx context.Context, query *datasources.GetDataSourcesQuery) error {
+	for _, v := range s.items {
+		if query.OrgId == v.OrgId {
+			query.Result = append(query.Result, v)
+		}
+	}
+	return nil
+}
+
 func (s *spyStore) DeleteDataSource(ctx context.Context, cmd *datasources.DeleteDataSourceCommand) error {
 	s.deleted = append(s.deleted, cmd)
 	for _, v := range s.items {
@@ -33,10 +33,12 @@ var (
 )
 
 // Provision scans a directory for provisioning config files
-// and provisions the datasource in those files.
-func Provision(ctx context.Context, configDirectory string, store Store, correlationsStore CorrelationsStore, orgService org.Service) error {
+// and provisions the datasource in those files. It returns the
+// number of data sources which were applied.
+func Provision(ctx context.Context, configDirectory string, store Store, correlationsStore CorrelationsStore, orgService org.Service) (int, error) {
 	dc := newDatasourceProvisioner(log.New("provisioning.datasources"), store, correlationsStore, orgService)
-	return dc.applyChanges(ctx, configDirectory)
+	err := dc.applyChanges(ctx, configDirectory)
+	return dc.applied, err
 }
 
 // DatasourceProvisioner is responsible for provisioning datasources based on
@@ -46,6 +48,7 @@ type DatasourceProvisioner struct {
 	cfgProvider       *configReader
 	store             Store
 	correlationsStore CorrelationsStore
+	applied           int
 }
 
 func newDatasourceProvisioner(log log.Logger, store Store, correlationsStore CorrelationsStore, orgService org.Service) DatasourceProvisioner {
@@ -131,6 +134,7 @@ func (dc *DatasourceProvisioner) applyChanges(ctx context.Context, configPath st
 		if err := dc.apply(ctx, cfg); err != nil {
 			return err
 		}
+		dc.applied += len(cfg.Datasources) + len(cfg.DeleteDatasources)
 	}
 
 	return nil
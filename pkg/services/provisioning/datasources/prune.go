@@ -0,0 +1,75 @@
+package datasources
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// PruneStore is the store needed to find the data sources to prune.
+type PruneStore interface {
+	Store
+	GetDataSources(ctx context.Context, query *datasources.GetDataSourcesQuery) error
+}
+
+// Prune removes the provisioned data sources which are no longer declared in the config files
+// of configDirectory. Only read-only data sources are removed, since data sources can only be
+// made read-only by provisioning. With dryRun, the data sources are only returned.
+func Prune(ctx context.Context, configDirectory string, store PruneStore, correlationsStore CorrelationsStore, orgService org.Service, dryRun bool) ([]utils.PrunedResource, error) {
+	dc := newDatasourceProvisioner(log.New("provisioning.datasources"), store, correlationsStore, orgService)
+	return dc.prune(ctx, configDirectory, store, dryRun)
+}
+
+func (dc *DatasourceProvisioner) prune(ctx context.Context, configPath string, store PruneStore, dryRun bool) ([]utils.PrunedResource, error) {
+	configs, err := dc.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		// a missing or misconfigured provisioning directory must not remove all data sources.
+		dc.log.Warn("Not pruning data sources since no data source config files were found", "path", configPath)
+		return nil, nil
+	}
+
+	declared := map[int64]map[string]bool{}
+	for _, cfg := range configs {
+		for _, ds := range cfg.Datasources {
+			if ds == nil {
+				continue
+			}
+			if declared[ds.OrgID] == nil {
+				declared[ds.OrgID] = map[string]bool{}
+			}
+			declared[ds.OrgID][ds.Name] = true
+		}
+	}
+
+	orgIDs, err := utils.ListOrgIDs(ctx, dc.cfgProvider.orgService)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := make([]utils.PrunedResource, 0)
+	for _, orgID := range orgIDs {
+		query := &datasources.GetDataSourcesQuery{OrgId: orgID}
+		if err := store.GetDataSources(ctx, query); err != nil {
+			return nil, err
+		}
+		for _, ds := range query.Result {
+			if !ds.ReadOnly || declared[orgID][ds.Name] {
+				continue
+			}
+			pruned = append(pruned, utils.PrunedResource{Kind: "datasource", OrgID: orgID, UID: ds.Uid, Name: ds.Name})
+			if dryRun {
+				continue
+			}
+			if err := dc.deleteDatasources(ctx, []*deleteDatasourceConfig{{OrgID: orgID, Name: ds.Name}}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pruned, nil
+}
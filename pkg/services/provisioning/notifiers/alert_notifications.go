@@ -25,10 +25,11 @@ type Manager interface {
 	UpdateAlertNotificationWithUid(ctx context.Context, cmd *models.UpdateAlertNotificationWithUidCommand) error
 }
 
-// Provision alert notifiers
-func Provision(ctx context.Context, configDirectory string, alertingService Manager, orgService org.Service, encryptionService encryption.Internal, notificationService *notifications.NotificationService) error {
+// Provision alert notifiers and return the number of alert notifiers which were applied.
+func Provision(ctx context.Context, configDirectory string, alertingService Manager, orgService org.Service, encryptionService encryption.Internal, notificationService *notifications.NotificationService) (int, error) {
 	dc := newNotificationProvisioner(orgService, alertingService, encryptionService, notificationService, log.New("provisioning.notifiers"))
-	return dc.applyChanges(ctx, configDirectory)
+	err := dc.applyChanges(ctx, configDirectory)
+	return dc.applied, err
 }
 
 // NotificationProvisioner is responsible for provsioning alert notifiers
@@ -37,6 +38,7 @@ type NotificationProvisioner struct {
 	cfgProvider     *configReader
 	alertingManager Manager
 	orgService      org.Service
+	applied         int
 }
 
 func newNotificationProvisioner(orgService org.Service, alertingManager Manager, encryptionService encryption.Internal, notifiationService *notifications.NotificationService, log log.Logger) NotificationProvisioner {
@@ -168,6 +170,7 @@ func (dc *NotificationProvisioner) applyChanges(ctx context.Context, configPath
 		if err := dc.apply(ctx, cfg); err != nil {
 			return err
 		}
+		dc.applied += len(cfg.Notifications) + len(cfg.DeleteNotifications)
 	}
 
 	return nil
@@ -41,7 +41,7 @@ func (cr *configReader) readConfig(ctx context.Context, path string) ([]*notific
 			cr.log.Debug("Parsing alert notifications provisioning file", "path", path, "file.Name", file.Name())
 			notifs, err := cr.parseNotificationConfig(path, file)
 			if err != nil {
-				return nil, err
+				return nil, utils.NewFileError(filepath.Join(path, file.Name()), nil, err)
 			}
 
 			if notifs != nil {
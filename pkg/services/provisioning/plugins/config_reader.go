@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"gopkg.in/yaml.v2"
 )
 
@@ -41,7 +42,7 @@ func (cr *configReaderImpl) readConfig(ctx context.Context, path string) ([]*plu
 			cr.log.Debug("Parsing plugin provisioning file", "path", path, "file.Name", file.Name())
 			app, err := cr.parsePluginConfig(path, file)
 			if err != nil {
-				return nil, err
+				return nil, utils.NewFileError(filepath.Join(path, file.Name()), nil, err)
 			}
 
 			if app != nil {
@@ -12,8 +12,9 @@ import (
 )
 
 // Provision scans a directory for provisioning config files
-// and provisions the app in those files.
-func Provision(ctx context.Context, configDirectory string, pluginStore plugins.Store, pluginSettings pluginsettings.Service, orgService org.Service) error {
+// and provisions the app in those files. It returns the number
+// of apps which were applied.
+func Provision(ctx context.Context, configDirectory string, pluginStore plugins.Store, pluginSettings pluginsettings.Service, orgService org.Service) (int, error) {
 	logger := log.New("provisioning.plugins")
 	ap := PluginProvisioner{
 		log:            logger,
@@ -21,7 +22,8 @@ func Provision(ctx context.Context, configDirectory string, pluginStore plugins.
 		pluginSettings: pluginSettings,
 		orgService:     orgService,
 	}
-	return ap.applyChanges(ctx, configDirectory)
+	err := ap.applyChanges(ctx, configDirectory)
+	return ap.applied, err
 }
 
 // PluginProvisioner is responsible for provisioning apps based on
@@ -31,6 +33,7 @@ type PluginProvisioner struct {
 	cfgProvider    configReader
 	pluginSettings pluginsettings.Service
 	orgService     org.Service
+	applied        int
 }
 
 func (ap *PluginProvisioner) apply(ctx context.Context, cfg *pluginsAsConfig) error {
@@ -85,6 +88,7 @@ func (ap *PluginProvisioner) applyChanges(ctx context.Context, configPath string
 		if err := ap.apply(ctx, cfg); err != nil {
 			return err
 		}
+		ap.applied += len(cfg.Apps)
 	}
 
 	return nil
@@ -117,3 +117,11 @@ func (m *mockStore) GetPluginSettings(_ context.Context, _ *pluginsettings.GetAr
 func (m *mockStore) DecryptedValues(_ *pluginsettings.DTO) map[string]string {
 	return nil
 }
+
+func (m *mockStore) MigrateSecrets(_ context.Context) error {
+	return nil
+}
+
+func (m *mockStore) LegacySecrets(_ context.Context) ([]*pluginsettings.LegacySecretsInfo, error) {
+	return nil, nil
+}
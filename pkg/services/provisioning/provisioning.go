@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/log"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
@@ -22,14 +24,19 @@ import (
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
+	prov_accesscontrol "github.com/grafana/grafana/pkg/services/provisioning/accesscontrol"
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -51,6 +58,10 @@ func ProvideService(
 	quotaService quota.Service,
 	secrectService secrets.Service,
 	orgService org.Service,
+	teamService team.Service,
+	teamPermissionsService accesscontrol.TeamPermissionsService,
+	userService user.Service,
+	leaderElection leaderelection.Service,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -64,6 +75,7 @@ func ProvideService(
 		provisionDatasources:         datasources.Provision,
 		provisionPlugins:             plugins.Provision,
 		provisionAlerting:            prov_alerting.Provision,
+		provisionAccessControl:       prov_accesscontrol.Provision,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
@@ -75,6 +87,10 @@ func ProvideService(
 		secretService:                secrectService,
 		log:                          log.New("provisioning"),
 		orgService:                   orgService,
+		teamService:                  teamService,
+		teamPermissionsService:       teamPermissionsService,
+		userService:                  userService,
+		leaderElection:               leaderElection,
 	}
 	return s, nil
 }
@@ -87,8 +103,10 @@ type ProvisioningService interface {
 	ProvisionNotifications(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	ProvisionAlerting(ctx context.Context) error
+	ProvisionAccessControl(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetStatus() Status
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -106,9 +124,9 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 // Used for testing purposes
 func newProvisioningServiceImpl(
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory,
-	provisionNotifiers func(context.Context, string, notifiers.Manager, org.Service, encryption.Internal, *notifications.NotificationService) error,
-	provisionDatasources func(context.Context, string, datasources.Store, datasources.CorrelationsStore, org.Service) error,
-	provisionPlugins func(context.Context, string, plugifaces.Store, pluginsettings.Service, org.Service) error,
+	provisionNotifiers func(context.Context, string, notifiers.Manager, org.Service, encryption.Internal, *notifications.NotificationService) (int, error),
+	provisionDatasources func(context.Context, string, datasources.Store, datasources.CorrelationsStore, org.Service) (int, error),
+	provisionPlugins func(context.Context, string, plugifaces.Store, pluginsettings.Service, org.Service) (int, error),
 ) *ProvisioningServiceImpl {
 	return &ProvisioningServiceImpl{
 		log:                     log.New("provisioning"),
@@ -131,10 +149,11 @@ type ProvisioningServiceImpl struct {
 	pollingCtxCancel             context.CancelFunc
 	newDashboardProvisioner      dashboards.DashboardProvisionerFactory
 	dashboardProvisioner         dashboards.DashboardProvisioner
-	provisionNotifiers           func(context.Context, string, notifiers.Manager, org.Service, encryption.Internal, *notifications.NotificationService) error
-	provisionDatasources         func(context.Context, string, datasources.Store, datasources.CorrelationsStore, org.Service) error
-	provisionPlugins             func(context.Context, string, plugifaces.Store, pluginsettings.Service, org.Service) error
-	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
+	provisionNotifiers           func(context.Context, string, notifiers.Manager, org.Service, encryption.Internal, *notifications.NotificationService) (int, error)
+	provisionDatasources         func(context.Context, string, datasources.Store, datasources.CorrelationsStore, org.Service) (int, error)
+	provisionPlugins             func(context.Context, string, plugifaces.Store, pluginsettings.Service, org.Service) (int, error)
+	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) (int, error)
+	provisionAccessControl       func(context.Context, string, db.DB, team.Service, accesscontrol.TeamPermissionsService, user.Service, org.Service) (int, error)
 	mutex                        sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
@@ -145,6 +164,27 @@ type ProvisioningServiceImpl struct {
 	searchService                searchV2.SearchService
 	quotaService                 quota.Service
 	secretService                secrets.Service
+	teamService                  team.Service
+	teamPermissionsService       accesscontrol.TeamPermissionsService
+	userService                  user.Service
+	leaderElection               leaderelection.Service
+	statusMutex                  sync.RWMutex
+	statuses                     map[string]utils.ProvisioningStatus
+	pruneReport                  *PruneReport
+}
+
+// Status is the result of the last runs of the provisioners.
+type Status struct {
+	Provisioners []utils.ProvisioningStatus `json:"provisioners"`
+	// Prune is the report of the last prune, if pruning is enabled.
+	Prune *PruneReport `json:"prune,omitempty"`
+}
+
+// PruneReport lists the provisioned resources removed, or only found with dry run, by the last prune.
+type PruneReport struct {
+	DryRun    bool                   `json:"dryRun"`
+	Time      time.Time              `json:"time"`
+	Resources []utils.PrunedResource `json:"resources"`
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -158,6 +198,11 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionAccessControl(ctx)
+	if err != nil {
+		return err
+	}
+
 	err = ps.ProvisionNotifications(ctx)
 	if err != nil {
 		return err
@@ -168,9 +213,86 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	// Only one instance of a cluster prunes, so that the instances do not remove the same resources
+	// concurrently.
+	if ps.Cfg.ProvisioningPrune && ps.leaderElection.IsLeader(ctx, leaderelection.LeaseProvisioningPrune) {
+		ps.prune(ctx)
+	}
+
 	return nil
 }
 
+// prune removes the provisioned data sources and alert rules which are no longer declared in the
+// provisioning files. Dashboards are not pruned here, since the dashboard provisioner already removes
+// the dashboards of removed files and orphaned dashboards.
+func (ps *ProvisioningServiceImpl) prune(ctx context.Context) {
+	dryRun := ps.Cfg.ProvisioningPruneDryRun
+	report := &PruneReport{DryRun: dryRun, Time: time.Now(), Resources: make([]utils.PrunedResource, 0)}
+
+	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
+	pruned, err := datasources.Prune(ctx, datasourcePath, ps.datasourceService, ps.correlationsService, ps.orgService, dryRun)
+	if err != nil {
+		ps.log.Error("Failed to prune provisioned data sources", "error", err)
+	}
+	report.Resources = append(report.Resources, pruned...)
+
+	pruned, err = prov_alerting.Prune(ctx, ps.alertingProvisionerConfig(), dryRun)
+	if err != nil {
+		ps.log.Error("Failed to prune provisioned alert rules", "error", err)
+	}
+	report.Resources = append(report.Resources, pruned...)
+
+	for _, r := range report.Resources {
+		if dryRun {
+			ps.log.Info("Would prune provisioned resource", "kind", r.Kind, "orgId", r.OrgID, "uid", r.UID, "name", r.Name)
+		} else {
+			ps.log.Info("Pruned provisioned resource", "kind", r.Kind, "orgId", r.OrgID, "uid", r.UID, "name", r.Name)
+		}
+	}
+
+	ps.statusMutex.Lock()
+	ps.pruneReport = report
+	ps.statusMutex.Unlock()
+}
+
+// GetStatus returns the status of the last run of every provisioner which ran already, and the report
+// of the last prune.
+func (ps *ProvisioningServiceImpl) GetStatus() Status {
+	ps.statusMutex.RLock()
+	status := Status{
+		Provisioners: make([]utils.ProvisioningStatus, 0, len(ps.statuses)),
+		Prune:        ps.pruneReport,
+	}
+	for _, provisionerType := range []string{"datasources", "plugins", "accesscontrol", "notifiers", "alerting", "dashboards"} {
+		if s, ok := ps.statuses[provisionerType]; ok {
+			status.Provisioners = append(status.Provisioners, s)
+		}
+	}
+	ps.statusMutex.RUnlock()
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	if ps.dashboardProvisioner != nil {
+		status.Provisioners = append(status.Provisioners, ps.dashboardProvisioner.GetStatus()...)
+	}
+	return status
+}
+
+func (ps *ProvisioningServiceImpl) clearStatus(provisionerType string) {
+	ps.statusMutex.Lock()
+	defer ps.statusMutex.Unlock()
+	delete(ps.statuses, provisionerType)
+}
+
+func (ps *ProvisioningServiceImpl) setStatus(provisionerType string, applied int, err error) {
+	ps.statusMutex.Lock()
+	defer ps.statusMutex.Unlock()
+	if ps.statuses == nil {
+		ps.statuses = make(map[string]utils.ProvisioningStatus)
+	}
+	ps.statuses[provisionerType] = utils.NewProvisioningStatus(provisionerType, "", applied, err)
+}
+
 func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 	err := ps.ProvisionDashboards(ctx)
 	if err != nil {
@@ -181,6 +303,10 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 		ps.searchService.TriggerReIndex()
 	}
 
+	if ps.Cfg.ProvisioningSecretsRefreshInterval > 0 {
+		go ps.watchSecrets(ctx, ps.Cfg.ProvisioningSecretsRefreshInterval)
+	}
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -203,9 +329,42 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 	}
 }
 
+// watchSecrets provisions the resources which can contain secrets again when a secret referenced in the
+// provisioning files was rotated.
+func (ps *ProvisioningServiceImpl) watchSecrets(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotated, err := values.SecretsRotated()
+			if err != nil {
+				ps.log.Warn("Failed to read secrets referenced in provisioning files", "error", err)
+			}
+			if !rotated {
+				continue
+			}
+
+			ps.log.Info("Secrets referenced in provisioning files were rotated, provisioning again")
+			// errors are logged and reported in the provisioning status by each provisioner
+			_ = ps.ProvisionDatasources(ctx)
+			_ = ps.ProvisionPlugins(ctx)
+			_ = ps.ProvisionNotifications(ctx)
+			if err := ps.ProvisionAlerting(ctx); err != nil {
+				ps.log.Error("Failed to provision alerting", "error", err)
+			}
+		}
+	}
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) error {
 	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
-	if err := ps.provisionDatasources(ctx, datasourcePath, ps.datasourceService, ps.correlationsService, ps.orgService); err != nil {
+	applied, err := ps.provisionDatasources(ctx, datasourcePath, ps.datasourceService, ps.correlationsService, ps.orgService)
+	ps.setStatus("datasources", applied, err)
+	if err != nil {
 		err = fmt.Errorf("%v: %w", "Datasource provisioning error", err)
 		ps.log.Error("Failed to provision data sources", "error", err)
 		return err
@@ -215,7 +374,9 @@ func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) err
 
 func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	appPath := filepath.Join(ps.Cfg.ProvisioningPath, "plugins")
-	if err := ps.provisionPlugins(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService); err != nil {
+	applied, err := ps.provisionPlugins(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService)
+	ps.setStatus("plugins", applied, err)
+	if err != nil {
 		err = fmt.Errorf("%v: %w", "app provisioning error", err)
 		ps.log.Error("Failed to provision plugins", "error", err)
 		return err
@@ -223,9 +384,23 @@ func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	return nil
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionAccessControl(ctx context.Context) error {
+	accessControlPath := filepath.Join(ps.Cfg.ProvisioningPath, "access-control")
+	applied, err := ps.provisionAccessControl(ctx, accessControlPath, ps.SQLStore, ps.teamService, ps.teamPermissionsService, ps.userService, ps.orgService)
+	ps.setStatus("accesscontrol", applied, err)
+	if err != nil {
+		err = fmt.Errorf("%v: %w", "Access control provisioning error", err)
+		ps.log.Error("Failed to provision teams and roles", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) error {
 	alertNotificationsPath := filepath.Join(ps.Cfg.ProvisioningPath, "notifiers")
-	if err := ps.provisionNotifiers(ctx, alertNotificationsPath, ps.alertingService, ps.orgService, ps.EncryptionService, ps.NotificationService); err != nil {
+	applied, err := ps.provisionNotifiers(ctx, alertNotificationsPath, ps.alertingService, ps.orgService, ps.EncryptionService, ps.NotificationService)
+	ps.setStatus("notifiers", applied, err)
+	if err != nil {
 		err = fmt.Errorf("%v: %w", "Alert notification provisioning error", err)
 		ps.log.Error("Failed to provision alert notifications", "error", err)
 		return err
@@ -237,6 +412,7 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService)
 	if err != nil {
+		ps.setStatus("dashboards", 0, err)
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
 
@@ -250,13 +426,22 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 	if err != nil {
 		// If we fail to provision with the new provisioner, the mutex will unlock and the polling will restart with the
 		// old provisioner as we did not switch them yet.
+		ps.setStatus("dashboards", 0, err)
 		return fmt.Errorf("%v: %w", "Failed to provision dashboards", err)
 	}
 	ps.dashboardProvisioner = dashProvisioner
+	// the status of the dashboard providers is reported by the dashboard provisioner
+	ps.clearStatus("dashboards")
 	return nil
 }
 
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	applied, err := ps.provisionAlerting(ctx, ps.alertingProvisionerConfig())
+	ps.setStatus("alerting", applied, err)
+	return err
+}
+
+func (ps *ProvisioningServiceImpl) alertingProvisionerConfig() prov_alerting.ProvisionerConfig {
 	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
 	st := store.DBstore{
 		Cfg:              ps.Cfg.UnifiedAlerting,
@@ -278,9 +463,9 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		st, ps.SQLStore, ps.log)
 	notificationPolicyService := provisioning.NewNotificationPolicyService(&st,
 		st, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
-	mutetimingsService := provisioning.NewMuteTimingService(&st, st, &st, ps.log)
+	mutetimingsService := provisioning.NewMuteTimingService(&st, st, &st, nil, ps.log)
 	templateService := provisioning.NewTemplateService(&st, st, &st, ps.log)
-	cfg := prov_alerting.ProvisionerConfig{
+	return prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
 		DashboardService:           ps.dashboardService,
@@ -289,8 +474,8 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		OrgService:                 ps.orgService,
 	}
-	return ps.provisionAlerting(ctx, cfg)
 }
 
 func (ps *ProvisioningServiceImpl) GetDashboardProvisionerResolvedPath(name string) string {
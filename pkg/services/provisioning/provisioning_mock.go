@@ -9,8 +9,10 @@ type Calls struct {
 	ProvisionNotifications              []interface{}
 	ProvisionDashboards                 []interface{}
 	ProvisionAlerting                   []interface{}
+	ProvisionAccessControl              []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
+	GetStatus                           []interface{}
 	Run                                 []interface{}
 }
 
@@ -23,6 +25,7 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	GetStatusFunc                           func() Status
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -77,6 +80,11 @@ func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) erro
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionAccessControl(ctx context.Context) error {
+	mock.Calls.ProvisionAccessControl = append(mock.Calls.ProvisionAccessControl, nil)
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
@@ -93,6 +101,14 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) GetStatus() Status {
+	mock.Calls.GetStatus = append(mock.Calls.GetStatus, nil)
+	if mock.GetStatusFunc != nil {
+		return mock.GetStatusFunc()
+	}
+	return Status{}
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ProvisioningStatus is the result of the last run of a provisioner.
+type ProvisioningStatus struct {
+	// Type is the type of the provisioned resources, e.g. datasources or dashboards.
+	Type string `json:"type"`
+	// Name is the name of the provider for provisioners configured with several providers.
+	Name    string    `json:"name,omitempty"`
+	LastRun time.Time `json:"lastRun"`
+	// Applied is the number of resources declared in the provisioning files which were applied.
+	Applied int                 `json:"applied"`
+	Errors  []ProvisioningError `json:"errors"`
+}
+
+// NewProvisioningStatus returns the status of a provisioner run which ended with err.
+func NewProvisioningStatus(provisionerType, name string, applied int, err error) ProvisioningStatus {
+	status := ProvisioningStatus{
+		Type:    provisionerType,
+		Name:    name,
+		LastRun: time.Now(),
+		Applied: applied,
+		Errors:  make([]ProvisioningError, 0),
+	}
+	if err != nil {
+		status.Errors = append(status.Errors, ToProvisioningError(err))
+	}
+	return status
+}
+
+// ProvisioningError is a provisioning failure, with the file and line it was caused by when known.
+type ProvisioningError struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Reason string `json:"reason"`
+
+	err error
+}
+
+func (e *ProvisioningError) Error() string {
+	switch {
+	case e.File != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
+	case e.File != "":
+		return fmt.Sprintf("%s: %s", e.File, e.Reason)
+	}
+	return e.Reason
+}
+
+func (e *ProvisioningError) Unwrap() error {
+	return e.err
+}
+
+var yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+
+// NewFileError returns err as ProvisioningError of the file. The line is taken from YAML errors,
+// and from JSON syntax errors if the content of the file is given.
+func NewFileError(file string, content []byte, err error) *ProvisioningError {
+	e := &ProvisioningError{File: file, Reason: err.Error(), err: err}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) && content != nil {
+		e.Line = lineOfOffset(content, syntaxErr.Offset)
+	} else if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+// ToProvisioningError returns the ProvisioningError wrapped in err, or one with err as reason.
+func ToProvisioningError(err error) ProvisioningError {
+	var provErr *ProvisioningError
+	if errors.As(err, &provErr) {
+		return *provErr
+	}
+	return ProvisioningError{Reason: err.Error(), err: err}
+}
+
+func lineOfOffset(content []byte, offset int64) int {
+	line := 1
+	for i := int64(0); i < offset && i < int64(len(content)); i++ {
+		if content[i] == '\n' {
+			line++
+		}
+	}
+	return line
+}
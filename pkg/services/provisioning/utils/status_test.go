@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestNewFileError(t *testing.T) {
+	t.Run("takes the line from YAML errors", func(t *testing.T) {
+		var v map[string]interface{}
+		err := yaml.Unmarshal([]byte("apiVersion: 1\n\ndatasources:\n  - name: a\n   url: b\n"), &v)
+		require.Error(t, err)
+
+		provErr := NewFileError("datasources.yaml", nil, err)
+		assert.Equal(t, "datasources.yaml", provErr.File)
+		assert.Equal(t, 4, provErr.Line)
+		assert.ErrorIs(t, provErr, err)
+	})
+
+	t.Run("takes the line from JSON syntax errors", func(t *testing.T) {
+		content := []byte("{\n  \"title\": \"a\",\n  \"panels\": [,]\n}")
+		var v map[string]interface{}
+		err := json.Unmarshal(content, &v)
+		require.Error(t, err)
+
+		provErr := NewFileError("dashboard.json", content, err)
+		assert.Equal(t, 3, provErr.Line)
+	})
+
+	t.Run("has no line for other errors", func(t *testing.T) {
+		provErr := NewFileError("dashboard.json", nil, errors.New("dashboard title cannot be empty"))
+		assert.Equal(t, 0, provErr.Line)
+		assert.Equal(t, "dashboard.json: dashboard title cannot be empty", provErr.Error())
+	})
+}
+
+func TestToProvisioningError(t *testing.T) {
+	fileErr := NewFileError("plugins.yaml", nil, errors.New("yaml: line 2: did not find expected key"))
+	provErr := ToProvisioningError(fmt.Errorf("alert rules: %w", fileErr))
+	assert.Equal(t, "plugins.yaml", provErr.File)
+	assert.Equal(t, 2, provErr.Line)
+
+	provErr = ToProvisioningError(errors.New("plugin not installed"))
+	assert.Equal(t, ProvisioningError{Reason: "plugin not installed", err: provErr.err}, provErr)
+}
@@ -24,3 +24,25 @@ func CheckOrgExists(ctx context.Context, orgService org.Service, orgID int64) er
 	}
 	return nil
 }
+
+// PrunedResource is a provisioned resource which was removed, or would be removed in a
+// dry run, since it is no longer declared in the provisioning files.
+type PrunedResource struct {
+	Kind  string `json:"kind"`
+	OrgID int64  `json:"orgId"`
+	UID   string `json:"uid,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// ListOrgIDs returns the IDs of all organizations.
+func ListOrgIDs(ctx context.Context, orgService org.Service) ([]int64, error) {
+	orgs, err := orgService.Search(ctx, &org.SearchOrgsQuery{})
+	if err != nil {
+		return nil, err
+	}
+	orgIDs := make([]int64, 0, len(orgs))
+	for _, o := range orgs {
+		orgIDs = append(orgIDs, o.ID)
+	}
+	return orgIDs, nil
+}
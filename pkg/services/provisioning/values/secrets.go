@@ -0,0 +1,59 @@
+package values
+
+import (
+	"sync"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// secretReferences holds the last expanded value of every value in the provisioning files which references
+// a secret through an expander, such as $__file{} or $__vault{}, to detect when one of the secrets was rotated.
+var secretReferences = struct {
+	sync.Mutex
+	values map[string]string
+}{values: map[string]string{}}
+
+func recordSecretReference(val, expanded string) {
+	if !hasSecretReference(val) {
+		return
+	}
+	secretReferences.Lock()
+	defer secretReferences.Unlock()
+	secretReferences.values[val] = expanded
+}
+
+// hasSecretReference returns true if the value references an expander other than the environment variables
+// of the short ${VAR} syntax, which os.ExpandEnv expands as well.
+func hasSecretReference(val string) bool {
+	for _, match := range setting.GetExpanderRegex().FindAllStringSubmatch(val, -1) {
+		if match[1] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretsRotated expands the values which reference secrets again and returns true if any of them changed
+// since it was last expanded, in which case the provisioning files need to be applied again. Values which
+// fail to expand are reported as not rotated, together with the first error.
+func SecretsRotated() (bool, error) {
+	secretReferences.Lock()
+	defer secretReferences.Unlock()
+
+	var firstErr error
+	rotated := false
+	for val, previous := range secretReferences.values {
+		expanded, err := setting.ExpandVar(val)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if expanded != previous {
+			secretReferences.values[val] = expanded
+			rotated = true
+		}
+	}
+	return rotated, firstErr
+}
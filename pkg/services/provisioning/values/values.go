@@ -295,6 +295,7 @@ func interpolateValue(val string) (string, string, error) {
 		if err != nil {
 			return val, val, fmt.Errorf("failed to interpolate value '%s': %w", val, err)
 		}
+		recordSecretReference(v, expanded)
 		v = expanded
 		interpolated[i] = os.ExpandEnv(v)
 	}
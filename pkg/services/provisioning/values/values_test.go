@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/setting"
@@ -354,3 +355,35 @@ func (f failExpander) SetupExpander(file *ini.File) error {
 func (f failExpander) Expand(s string) (string, error) {
 	return "", errExpand
 }
+
+func TestValues_secretsRotated(t *testing.T) {
+	type Data struct {
+		Val StringValue `yaml:"val"`
+	}
+
+	// the references recorded by other tests may point to removed files
+	secretReferences.Lock()
+	secretReferences.values = map[string]string{}
+	secretReferences.Unlock()
+
+	file := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(file, []byte("first"), 0600))
+
+	data := &Data{}
+	err := yaml.Unmarshal([]byte(fmt.Sprintf("val: $__file{%s}", file)), data)
+	require.NoError(t, err)
+	assert.Equal(t, "first", data.Val.Value())
+
+	rotated, err := SecretsRotated()
+	require.NoError(t, err)
+	assert.False(t, rotated)
+
+	require.NoError(t, os.WriteFile(file, []byte("second"), 0600))
+	rotated, err = SecretsRotated()
+	require.NoError(t, err)
+	assert.True(t, rotated)
+
+	rotated, err = SecretsRotated()
+	require.NoError(t, err)
+	assert.False(t, rotated)
+}
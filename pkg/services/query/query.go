@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
@@ -21,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/tsdb/syntheticsds"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
 
@@ -44,6 +46,7 @@ func ProvideService(
 	dataSourceService datasources.DataSourceService,
 	pluginClient plugins.Client,
 	oAuthTokenService oauthtoken.OAuthTokenService,
+	dataSourceUsage usagestats.DataSourceUsageTracker,
 ) *Service {
 	g := &Service{
 		cfg:                    cfg,
@@ -53,6 +56,7 @@ func ProvideService(
 		dataSourceService:      dataSourceService,
 		pluginClient:           pluginClient,
 		oAuthTokenService:      oAuthTokenService,
+		dataSourceUsage:        dataSourceUsage,
 		log:                    log.New("query_data"),
 	}
 	g.log.Info("Query Service initialization")
@@ -67,6 +71,7 @@ type Service struct {
 	dataSourceService      datasources.DataSourceService
 	pluginClient           plugins.Client
 	oAuthTokenService      oauthtoken.OAuthTokenService
+	dataSourceUsage        usagestats.DataSourceUsageTracker
 	log                    log.Logger
 }
 
@@ -194,7 +199,7 @@ func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.Si
 			User:                       adapters.BackendUserFromSignedInUser(user),
 			DataSourceInstanceSettings: instanceSettings,
 		},
-		Headers: map[string]string{},
+		Headers: adapters.BackendUserHeaders(user),
 		Queries: []backend.DataQuery{},
 	}
 
@@ -230,7 +235,37 @@ func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.Si
 
 	ctx = httpclient.WithContextualMiddleware(ctx, middlewares...)
 
-	return s.pluginClient.QueryData(ctx, req)
+	start := time.Now()
+	resp, err := s.pluginClient.QueryData(ctx, req)
+	s.recordDataSourceUsage(ds, parsedReq, time.Since(start), resp, err)
+	return resp, err
+}
+
+// recordDataSourceUsage records the query request for the usage insights of the data source.
+func (s *Service) recordDataSourceUsage(ds *datasources.DataSource, parsedReq *parsedRequest, duration time.Duration, resp *backend.QueryDataResponse, err error) {
+	if s.dataSourceUsage == nil {
+		return
+	}
+
+	query := usagestats.DataSourceQuery{
+		OrgID:          ds.OrgId,
+		DataSourceUID:  ds.Uid,
+		DataSourceType: ds.Type,
+		Duration:       duration,
+		Failed:         err != nil,
+	}
+	if parsedReq.httpRequest != nil {
+		query.DashboardUID = parsedReq.httpRequest.Header.Get(HeaderDashboardUID)
+	}
+	if resp != nil {
+		for _, r := range resp.Responses {
+			if r.Error != nil {
+				query.Failed = true
+				break
+			}
+		}
+	}
+	s.dataSourceUsage.RecordDataSourceQuery(query)
 }
 
 type parsedQuery struct {
@@ -399,6 +434,10 @@ func (s *Service) getDataSourceFromQuery(ctx context.Context, user *user.SignedI
 		return grafanads.DataSourceModel(user.OrgID), nil
 	}
 
+	if uid == syntheticsds.DatasourceUID {
+		return syntheticsds.DataSourceModel(user.OrgID), nil
+	}
+
 	// use datasourceId if it exists
 	id := query.Get("datasourceId").MustInt64(0)
 	if id > 0 {
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
@@ -420,6 +421,27 @@ func TestQueryData(t *testing.T) {
 
 		require.Equal(t, map[string]string{"Cookie": "bar=rab"}, tc.pluginContext.req.Headers)
 	})
+
+	t.Run("it records the data source usage", func(t *testing.T) {
+		tc := setup(t)
+		tc.dataSourceCache.ds.Uid = "prom"
+		tc.dataSourceCache.ds.Type = "prometheus"
+
+		metricReq := metricRequest()
+		httpReq, err := http.NewRequest(http.MethodPost, "/api/ds/query", nil)
+		require.NoError(t, err)
+		httpReq.Header.Set(HeaderDashboardUID, "dash")
+		metricReq.HTTPRequest = httpReq
+		_, err = tc.queryService.QueryData(context.Background(), tc.signedInUser, true, metricReq)
+		require.NoError(t, err)
+
+		require.Len(t, tc.dataSourceUsage.queries, 1)
+		query := tc.dataSourceUsage.queries[0]
+		assert.Equal(t, "prom", query.DataSourceUID)
+		assert.Equal(t, "prometheus", query.DataSourceType)
+		assert.Equal(t, "dash", query.DashboardUID)
+		assert.False(t, query.Failed)
+	})
 }
 
 func setup(t *testing.T) *testContext {
@@ -441,9 +463,11 @@ func setup(t *testing.T) *testContext {
 		SimulatePluginFailure: false,
 	}
 	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, pc, fakeDatasourceService)
-	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, ds, pc, tc) // provider belonging to this package
+	du := &fakeDataSourceUsageTracker{}
+	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, ds, pc, tc, du) // provider belonging to this package
 	return &testContext{
 		pluginContext:          pc,
+		dataSourceUsage:        du,
 		secretStore:            ss,
 		dataSourceCache:        dc,
 		oauthTokenService:      tc,
@@ -455,6 +479,7 @@ func setup(t *testing.T) *testContext {
 
 type testContext struct {
 	pluginContext          *fakePluginClient
+	dataSourceUsage        *fakeDataSourceUsageTracker
 	secretStore            secretskvs.SecretsKVStore
 	dataSourceCache        *fakeDataSourceCache
 	oauthTokenService      *fakeOAuthTokenService
@@ -555,3 +580,15 @@ func (c *fakePluginClient) QueryData(ctx context.Context, req *backend.QueryData
 
 	return &backend.QueryDataResponse{Responses: make(backend.Responses)}, nil
 }
+
+type fakeDataSourceUsageTracker struct {
+	queries []usagestats.DataSourceQuery
+}
+
+func (t *fakeDataSourceUsageTracker) RecordDataSourceQuery(query usagestats.DataSourceQuery) {
+	t.queries = append(t.queries, query)
+}
+
+func (t *fakeDataSourceUsageTracker) GetDataSourcesUsage(int64) usagestats.DataSourceUsageWindow {
+	return usagestats.DataSourceUsageWindow{}
+}
@@ -0,0 +1,130 @@
+package querycapture
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+func (s *QueryCaptureService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/admin/query-captures", func(captures routing.RouteRegister) {
+		captures.Get("/", middleware.ReqGrafanaAdmin, routing.Wrap(s.listCapturesHandler))
+		captures.Get("/:id", middleware.ReqGrafanaAdmin, routing.Wrap(s.getCaptureHandler))
+	})
+}
+
+// swagger:route GET /admin/query-captures admin listQueryCaptures
+//
+// List the captured query requests of panels.
+//
+// Lists the query requests of panels captured by the query capture, newest first. The requests
+// and responses are not included.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: listQueryCapturesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *QueryCaptureService) listCapturesHandler(c *models.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	captures, err := s.ListCaptures(c.Req.Context(), ListCapturesQuery{
+		OrgID:        c.QueryInt64("orgId"),
+		DashboardUID: c.Query("dashboardUid"),
+		PanelID:      c.QueryInt64("panelId"),
+		Limit:        limit,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list query captures", err)
+	}
+	return response.JSON(http.StatusOK, captures)
+}
+
+// swagger:route GET /admin/query-captures/{capture_id} admin getQueryCapture
+//
+// Get a captured query request of a panel together with its response.
+//
+// The response is left out if it was larger than the configured maximum, the summary of the
+// frames returned for every query is always included.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getQueryCaptureResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryCaptureService) getCaptureHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	capture, err := s.GetCapture(c.Req.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrCaptureNotFound) {
+			return response.Error(http.StatusNotFound, "Query capture not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get query capture", err)
+	}
+	return response.JSON(http.StatusOK, capture)
+}
+
+// swagger:parameters listQueryCaptures
+type ListQueryCapturesParams struct {
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	DashboardUID string `json:"dashboardUid"`
+	// in:query
+	// required:false
+	PanelID int64 `json:"panelId"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters getQueryCapture
+type GetQueryCaptureParams struct {
+	// in:path
+	// required:true
+	ID int64 `json:"capture_id"`
+}
+
+// swagger:response listQueryCapturesResponse
+type ListQueryCapturesResponse struct {
+	// in:body
+	Body []*Capture `json:"body"`
+}
+
+// swagger:response getQueryCaptureResponse
+type GetQueryCaptureResponse struct {
+	// in:body
+	Body *CaptureDTO `json:"body"`
+}
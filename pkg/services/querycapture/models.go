@@ -0,0 +1,66 @@
+package querycapture
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrCaptureNotFound = errors.New("query capture not found")
+
+// Capture is a query request of a panel together with the response the data sources returned.
+// swagger:model QueryCapture
+type Capture struct {
+	ID           int64  `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID        int64  `json:"orgId" xorm:"org_id"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	PanelID      int64  `json:"panelId" xorm:"panel_id"`
+	UserID       int64  `json:"userId" xorm:"user_id"`
+	// Duration of the query in milliseconds.
+	Duration int64 `json:"duration" xorm:"duration"`
+	// Error is set if the request failed as a whole. Errors of single queries are part of the response.
+	Error    string `json:"error,omitempty" xorm:"error"`
+	Request  string `json:"-" xorm:"request"`
+	Response string `json:"-" xorm:"response"`
+	// Summary lists the frames returned for every query, even if the response was too large to keep.
+	Summary string `json:"-" xorm:"summary"`
+	// ResponseSize is the size of the response in bytes.
+	ResponseSize int `json:"responseSize" xorm:"response_size"`
+	// Truncated is true if the response was larger than the configured maximum and was not kept.
+	Truncated bool      `json:"truncated" xorm:"truncated"`
+	Created   time.Time `json:"created" xorm:"created"`
+}
+
+func (c Capture) TableName() string {
+	return "query_capture"
+}
+
+// CaptureDTO is a capture together with its request and response.
+// swagger:model QueryCaptureDTO
+type CaptureDTO struct {
+	*Capture
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// Summary is keyed by the refId of the queries.
+	Summary map[string]QuerySummary `json:"summary"`
+}
+
+// QuerySummary describes the response to a single query.
+type QuerySummary struct {
+	Error  string         `json:"error,omitempty"`
+	Frames []FrameSummary `json:"frames"`
+}
+
+type FrameSummary struct {
+	Name   string   `json:"name,omitempty"`
+	Fields []string `json:"fields"`
+	Rows   int      `json:"rows"`
+}
+
+type ListCapturesQuery struct {
+	// OrgID, DashboardUID and PanelID filter the captures if set.
+	OrgID        int64
+	DashboardUID string
+	PanelID      int64
+	Limit        int
+}
@@ -0,0 +1,224 @@
+// Package querycapture keeps the last query requests and responses of every panel, so that reports
+// of panels showing wrong data can be investigated without the user reproducing them with the
+// query inspector open.
+//
+// Capturing is opt-in, sampled and the size of the kept responses is capped.
+package querycapture
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// queueSize is the number of captures waiting to be stored above which new captures are dropped.
+	queueSize       = 100
+	cleanupInterval = time.Hour
+)
+
+type settings struct {
+	enabled          bool
+	sampleRate       float64
+	capturesPerPanel int
+	maxResponseSize  int
+	maxAge           time.Duration
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("query_capture")
+	return settings{
+		enabled:          section.Key("enabled").MustBool(false),
+		sampleRate:       section.Key("sample_rate").MustFloat64(1),
+		capturesPerPanel: section.Key("captures_per_panel").MustInt(10),
+		maxResponseSize:  section.Key("max_response_size").MustInt(256 * 1024),
+		maxAge:           section.Key("max_age").MustDuration(24 * time.Hour),
+	}
+}
+
+type Service interface {
+	// Capture queues a query request of a panel and its response to be stored. Requests which do
+	// not belong to a panel, or are not sampled, are ignored.
+	Capture(c *models.ReqContext, req dtos.MetricRequest, resp *backend.QueryDataResponse, queryErr error, duration time.Duration)
+}
+
+type QueryCaptureService struct {
+	settings      settings
+	store         db.DB
+	routeRegister routing.RouteRegister
+	log           log.Logger
+
+	queue chan *Capture
+	now   func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister) *QueryCaptureService {
+	s := &QueryCaptureService{
+		settings:      readSettings(cfg),
+		store:         sqlStore,
+		routeRegister: routeRegister,
+		log:           log.New("query-capture"),
+		queue:         make(chan *Capture, queueSize),
+		now:           time.Now,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *QueryCaptureService) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+func (s *QueryCaptureService) Capture(c *models.ReqContext, req dtos.MetricRequest, resp *backend.QueryDataResponse, queryErr error, duration time.Duration) {
+	if !s.settings.enabled {
+		return
+	}
+	panelID, err := strconv.ParseInt(c.Req.Header.Get(query.HeaderPanelID), 10, 64)
+	if err != nil {
+		return
+	}
+	// nolint:gosec
+	if rand.Float64() >= s.settings.sampleRate {
+		return
+	}
+
+	capture, err := s.newCapture(c.OrgID, c.Req.Header.Get(query.HeaderDashboardUID), panelID, c.UserID, req, resp, queryErr, duration)
+	if err != nil {
+		s.log.Warn("Failed to capture query", "error", err)
+		return
+	}
+
+	select {
+	case s.queue <- capture:
+	default:
+		s.log.Debug("Dropping query capture, too many captures waiting to be stored")
+	}
+}
+
+func (s *QueryCaptureService) newCapture(orgID int64, dashboardUID string, panelID int64, userID int64, req dtos.MetricRequest, resp *backend.QueryDataResponse, queryErr error, duration time.Duration) (*Capture, error) {
+	capture := &Capture{
+		OrgID:        orgID,
+		DashboardUID: dashboardUID,
+		PanelID:      panelID,
+		UserID:       userID,
+		Duration:     duration.Milliseconds(),
+		Created:      s.now(),
+	}
+	if queryErr != nil {
+		capture.Error = queryErr.Error()
+	}
+
+	request, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	capture.Request = string(request)
+
+	if resp == nil {
+		return capture, nil
+	}
+
+	response, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	capture.ResponseSize = len(response)
+	if len(response) > s.settings.maxResponseSize {
+		capture.Truncated = true
+	} else {
+		capture.Response = string(response)
+	}
+
+	summary, err := json.Marshal(summarize(resp))
+	if err != nil {
+		return nil, err
+	}
+	capture.Summary = string(summary)
+
+	return capture, nil
+}
+
+// summarize lists the frames, fields and number of rows of every query of a response.
+func summarize(resp *backend.QueryDataResponse) map[string]QuerySummary {
+	result := make(map[string]QuerySummary, len(resp.Responses))
+	for refID, r := range resp.Responses {
+		summary := QuerySummary{Frames: make([]FrameSummary, 0, len(r.Frames))}
+		if r.Error != nil {
+			summary.Error = r.Error.Error()
+		}
+		for _, frame := range r.Frames {
+			fields := make([]string, 0, len(frame.Fields))
+			for _, field := range frame.Fields {
+				fields = append(fields, field.Name)
+			}
+			rows, _ := frame.RowLen()
+			summary.Frames = append(summary.Frames, FrameSummary{Name: frame.Name, Fields: fields, Rows: rows})
+		}
+		result[refID] = summary
+	}
+	return result
+}
+
+func (s *QueryCaptureService) Run(ctx context.Context) error {
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case capture := <-s.queue:
+			if err := s.insertCapture(ctx, capture, s.settings.capturesPerPanel); err != nil {
+				s.log.Error("Failed to store query capture", "error", err)
+			}
+		case <-cleanupTicker.C:
+			if s.settings.maxAge <= 0 {
+				continue
+			}
+			removed, err := s.deleteCapturesBefore(ctx, s.now().Add(-s.settings.maxAge))
+			if err != nil {
+				s.log.Error("Failed to clean up query captures", "error", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Debug("Cleaned up query captures", "removed", removed)
+			}
+		}
+	}
+}
+
+func (s *QueryCaptureService) ListCaptures(ctx context.Context, q ListCapturesQuery) ([]*Capture, error) {
+	return s.listCaptures(ctx, q)
+}
+
+func (s *QueryCaptureService) GetCapture(ctx context.Context, id int64) (*CaptureDTO, error) {
+	capture, err := s.getCapture(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := &CaptureDTO{Capture: capture, Request: json.RawMessage(capture.Request), Summary: map[string]QuerySummary{}}
+	if capture.Response != "" {
+		dto.Response = json.RawMessage(capture.Response)
+	}
+	if capture.Summary != "" {
+		if err := json.Unmarshal([]byte(capture.Summary), &dto.Summary); err != nil {
+			return nil, err
+		}
+	}
+	return dto, nil
+}
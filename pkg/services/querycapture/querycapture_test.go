@@ -0,0 +1,120 @@
+package querycapture
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupService(t *testing.T, sqlStore db.DB) *QueryCaptureService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	_, err := cfg.Raw.Section("query_capture").NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = cfg.Raw.Section("query_capture").NewKey("max_response_size", "1000")
+	require.NoError(t, err)
+	return ProvideService(cfg, sqlStore, routing.NewRouteRegister())
+}
+
+func testResponse(rows int) *backend.QueryDataResponse {
+	values := make([]float64, rows)
+	resp := backend.NewQueryDataResponse()
+	resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("cpu", data.NewField("value", nil, values))}}
+	resp.Responses["B"] = backend.DataResponse{Error: errors.New("timeout")}
+	return resp
+}
+
+func TestNewCapture(t *testing.T) {
+	s := setupService(t, nil)
+	req := dtos.MetricRequest{From: "now-1h", To: "now"}
+
+	t.Run("Should keep small responses", func(t *testing.T) {
+		capture, err := s.newCapture(1, "dash", 2, 3, req, testResponse(1), nil, time.Second)
+		require.NoError(t, err)
+		assert.False(t, capture.Truncated)
+		assert.NotEmpty(t, capture.Response)
+		assert.Equal(t, int64(1000), capture.Duration)
+		assert.Contains(t, capture.Request, `"from":"now-1h"`)
+	})
+
+	t.Run("Should only summarize large responses", func(t *testing.T) {
+		capture, err := s.newCapture(1, "dash", 2, 3, req, testResponse(1000), nil, time.Second)
+		require.NoError(t, err)
+		assert.True(t, capture.Truncated)
+		assert.Empty(t, capture.Response)
+		assert.Greater(t, capture.ResponseSize, 1000)
+		assert.JSONEq(t, `{"A":{"frames":[{"name":"cpu","fields":["value"],"rows":1000}]},"B":{"error":"timeout","frames":[]}}`, capture.Summary)
+	})
+
+	t.Run("Should keep the error of failed requests", func(t *testing.T) {
+		capture, err := s.newCapture(1, "dash", 2, 3, req, nil, errors.New("data source not found"), time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "data source not found", capture.Error)
+		assert.Empty(t, capture.Summary)
+	})
+}
+
+func TestIntegrationQueryCaptureStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := setupService(t, db.InitTestDB(t))
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		capture, err := s.newCapture(1, "dash", 2, 3, dtos.MetricRequest{From: "now-1h", To: "now"}, testResponse(1), nil, time.Second)
+		require.NoError(t, err)
+		require.NoError(t, s.insertCapture(ctx, capture, 3))
+	}
+	other, err := s.newCapture(1, "dash", 5, 3, dtos.MetricRequest{}, nil, nil, time.Second)
+	require.NoError(t, err)
+	require.NoError(t, s.insertCapture(ctx, other, 3))
+
+	t.Run("Should keep the newest captures of a panel", func(t *testing.T) {
+		captures, err := s.ListCaptures(ctx, ListCapturesQuery{OrgID: 1, DashboardUID: "dash", PanelID: 2, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, captures, 3)
+		assert.True(t, captures[0].ID > captures[1].ID)
+		assert.Empty(t, captures[0].Request)
+
+		captures, err = s.ListCaptures(ctx, ListCapturesQuery{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, captures, 4)
+	})
+
+	t.Run("Should get a capture with its request and response", func(t *testing.T) {
+		captures, err := s.ListCaptures(ctx, ListCapturesQuery{PanelID: 2, Limit: 1})
+		require.NoError(t, err)
+
+		capture, err := s.GetCapture(ctx, captures[0].ID)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"from":"now-1h","to":"now","queries":null,"debug":false,"publicDashboardAccessToken":""}`, string(capture.Request))
+		assert.NotEmpty(t, capture.Response)
+		assert.Equal(t, "timeout", capture.Summary["B"].Error)
+
+		_, err = s.GetCapture(ctx, 1000)
+		require.ErrorIs(t, err, ErrCaptureNotFound)
+	})
+
+	t.Run("Should delete old captures", func(t *testing.T) {
+		removed, err := s.deleteCapturesBefore(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), removed)
+
+		removed, err = s.deleteCapturesBefore(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), removed)
+	})
+}
@@ -0,0 +1,77 @@
+package querycapture
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// insertCapture stores a capture and removes the oldest captures of its panel, so that at most
+// keep captures are kept per panel.
+func (s *QueryCaptureService) insertCapture(ctx context.Context, capture *Capture, keep int) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(capture); err != nil {
+			return err
+		}
+
+		var ids []int64
+		err := sess.Table("query_capture").Cols("id").
+			Where("org_id = ? AND dashboard_uid = ? AND panel_id = ?", capture.OrgID, capture.DashboardUID, capture.PanelID).
+			Desc("id").Find(&ids)
+		if err != nil {
+			return err
+		}
+		if len(ids) <= keep {
+			return nil
+		}
+		_, err = sess.In("id", ids[keep:]).Delete(&Capture{})
+		return err
+	})
+}
+
+func (s *QueryCaptureService) getCapture(ctx context.Context, id int64) (*Capture, error) {
+	capture := &Capture{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.ID(id).Get(capture)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCaptureNotFound
+		}
+		return nil
+	})
+	return capture, err
+}
+
+func (s *QueryCaptureService) listCaptures(ctx context.Context, q ListCapturesQuery) ([]*Capture, error) {
+	captures := make([]*Capture, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Omit("request", "response", "summary")
+		if q.OrgID > 0 {
+			sess.And("org_id = ?", q.OrgID)
+		}
+		if q.DashboardUID != "" {
+			sess.And("dashboard_uid = ?", q.DashboardUID)
+		}
+		if q.PanelID > 0 {
+			sess.And("panel_id = ?", q.PanelID)
+		}
+		return sess.Desc("id").Limit(q.Limit).Find(&captures)
+	})
+	return captures, err
+}
+
+func (s *QueryCaptureService) deleteCapturesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM query_capture WHERE created < ?", before)
+		if err != nil {
+			return err
+		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return removed, err
+}
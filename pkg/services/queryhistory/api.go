@@ -1,7 +1,9 @@
 package queryhistory
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -19,6 +21,9 @@ func (s *QueryHistoryService) registerAPIEndpoints() {
 		entities.Delete("/:uid", middleware.ReqSignedIn, routing.Wrap(s.deleteHandler))
 		entities.Post("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.starHandler))
 		entities.Delete("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.unstarHandler))
+		entities.Post("/share/:uid", middleware.ReqSignedIn, routing.Wrap(s.shareHandler))
+		entities.Delete("/share/:uid/:teamId", middleware.ReqSignedIn, routing.Wrap(s.unshareHandler))
+		entities.Get("/sync", middleware.ReqSignedIn, routing.Wrap(s.syncHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, routing.Wrap(s.patchCommentHandler))
 		// Remove migrate endpoint in Grafana v10 as breaking change
 		entities.Post("/migrate", middleware.ReqSignedIn, routing.Wrap(s.migrateHandler))
@@ -69,6 +74,7 @@ func (s *QueryHistoryService) searchHandler(c *models.ReqContext) response.Respo
 		DatasourceUIDs: c.QueryStrings("datasourceUid"),
 		SearchString:   c.Query("searchString"),
 		OnlyStarred:    c.QueryBoolWithDefault("onlyStarred", false),
+		SharedWithMe:   c.QueryBoolWithDefault("sharedWithMe", false),
 		Sort:           c.Query("sort"),
 		Page:           c.QueryInt("page"),
 		Limit:          c.QueryInt("limit"),
@@ -189,6 +195,98 @@ func (s *QueryHistoryService) unstarHandler(c *models.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
 }
 
+// swagger:route POST /query-history/share/{query_history_uid} query_history shareQuery
+//
+// Share starred query in query history with a team.
+//
+// Shares a starred query with a team the user is a member of. Members of the team find the query with the `sharedWithMe` search parameter.
+// Unstarring the query stops sharing it.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) shareHandler(c *models.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := ShareQueryInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.ShareQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrQueryNotFound):
+			return response.Error(http.StatusNotFound, "Query in query history not found", err)
+		case errors.Is(err, ErrQueryNotStarred):
+			return response.Error(http.StatusBadRequest, "Only starred queries can be shared", err)
+		case errors.Is(err, ErrNotTeamMember):
+			return response.Error(http.StatusForbidden, "Queries can only be shared with teams you are a member of", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to share query in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// swagger:route DELETE /query-history/share/{query_history_uid}/{team_id} query_history unshareQuery
+//
+// Stop sharing query in query history with a team.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) unshareHandler(c *models.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	query, err := s.UnshareQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, teamID)
+	if err != nil {
+		if errors.Is(err, ErrQueryNotFound) || errors.Is(err, ErrQueryNotShared) {
+			return response.Error(http.StatusNotFound, "Shared query in query history not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to unshare query in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// swagger:route GET /query-history/sync query_history syncQueries
+//
+// Sync query history.
+//
+// Returns the queries of the user created or changed since the given time and the UIDs of the queries deleted since, so that
+// devices can keep a local copy of the query history. Pass the returned `syncedAt` as `since` to the next sync.
+//
+// Responses:
+// 200: getQueryHistorySyncResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *QueryHistoryService) syncHandler(c *models.ReqContext) response.Response {
+	result, err := s.SyncQueryHistory(c.Req.Context(), c.SignedInUser, c.QueryInt64("since"))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to sync query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistorySyncResponse{Result: result})
+}
+
 // swagger:route POST /query-history/migrate query_history migrateQueries
 //
 // Migrate queries to query history.
@@ -214,7 +312,7 @@ func (s *QueryHistoryService) migrateHandler(c *models.ReqContext) response.Resp
 	return response.JSON(http.StatusOK, QueryHistoryMigrationResponse{Message: "Query history successfully migrated", TotalCount: totalCount, StarredCount: starredCount})
 }
 
-// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery
+// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery shareQuery unshareQuery
 type QueryHistoryByUID struct {
 	// in:path
 	// required:true
@@ -229,7 +327,7 @@ type SearchQueriesParams struct {
 	// type: array
 	// collectionFormat: multi
 	DatasourceUid []string `json:"datasourceUid"`
-	// Text inside query or comments that is searched for
+	// Text inside query or comments that is searched for. Every word must be found, use double quotes to search for phrases.
 	// in:query
 	// required: false
 	SearchString string `json:"searchString"`
@@ -237,6 +335,10 @@ type SearchQueriesParams struct {
 	// in:query
 	// required: false
 	OnlyStarred bool `json:"onlyStarred"`
+	// Flag indicating if the queries shared with the teams of the user should be returned instead of their own queries
+	// in:query
+	// required: false
+	SharedWithMe bool `json:"sharedWithMe"`
 	// Sort method
 	// in:query
 	// required: false
@@ -275,6 +377,28 @@ type PatchQueryCommentParams struct {
 	Body PatchQueryCommentInQueryHistoryCommand `json:"body"`
 }
 
+// swagger:parameters shareQuery
+type ShareQueryParams struct {
+	// in:body
+	// required:true
+	Body ShareQueryInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:parameters unshareQuery
+type UnshareQueryParams struct {
+	// in:path
+	// required:true
+	TeamID int64 `json:"team_id"`
+}
+
+// swagger:parameters syncQueries
+type SyncQueriesParams struct {
+	// Returns the changes since this time, in seconds since epoch. All queries are returned if empty.
+	// in:query
+	// required: false
+	Since int64 `json:"since"`
+}
+
 // swagger:parameters migrateQueries
 type MigrateQueriesParams struct {
 	// in:body
@@ -305,3 +429,9 @@ type GetQueryHistoryMigrationResponse struct {
 	// in: body
 	Body QueryHistoryMigrationResponse `json:"body"`
 }
+
+// swagger:response getQueryHistorySyncResponse
+type GetQueryHistorySyncResponse struct {
+	// in: body
+	Body QueryHistorySyncResponse `json:"body"`
+}
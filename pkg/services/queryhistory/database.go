@@ -13,13 +13,15 @@ import (
 
 // createQuery adds a query into query history
 func (s QueryHistoryService) createQuery(ctx context.Context, user *user.SignedInUser, cmd CreateQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	now := time.Now().Unix()
 	queryHistory := QueryHistory{
 		OrgID:         user.OrgID,
 		UID:           util.GenerateShortUID(),
 		Queries:       cmd.Queries,
 		DatasourceUID: cmd.DatasourceUID,
 		CreatedBy:     user.UserID,
-		CreatedAt:     time.Now().Unix(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
 		Comment:       "",
 	}
 
@@ -92,7 +94,14 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 		writeStarredSQL(query, s.store, &countBuilder)
 		writeFiltersSQL(query, user, s.store, &countBuilder)
 		err = session.SQL(countBuilder.GetSQLString(), countBuilder.GetParams()...).Find(&allQueries)
-		return err
+		if err != nil {
+			return err
+		}
+
+		if query.SharedWithMe {
+			return nil
+		}
+		return loadSharedTeams(session, dtos)
 	})
 
 	if err != nil {
@@ -127,6 +136,21 @@ func (s QueryHistoryService) deleteQuery(ctx context.Context, user *user.SignedI
 			return ErrQueryNotFound
 		}
 
+		if _, err := session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID).Delete(QueryHistoryShare{}); err != nil {
+			return err
+		}
+
+		// Keep track of the deletion for the other devices of the user
+		_, err = session.Insert(&QueryHistoryDeleted{
+			OrgID:     user.OrgID,
+			UserID:    user.UserID,
+			QueryUID:  UID,
+			DeletedAt: time.Now().Unix(),
+		})
+		if err != nil {
+			return err
+		}
+
 		queryID = id
 		return nil
 	})
@@ -149,7 +173,8 @@ func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.S
 		}
 
 		queryHistory.Comment = cmd.Comment
-		_, err = session.ID(queryHistory.ID).Update(queryHistory)
+		queryHistory.UpdatedAt = time.Now().Unix()
+		_, err = session.ID(queryHistory.ID).Cols("comment", "updated_at").Update(queryHistory)
 		if err != nil {
 			return err
 		}
@@ -209,7 +234,7 @@ func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInU
 		}
 
 		isStarred = true
-		return nil
+		return touchQuery(session, &queryHistory)
 	})
 
 	if err != nil {
@@ -251,8 +276,13 @@ func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedI
 			return err
 		}
 
+		// Only starred queries are shared
+		if _, err := session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID).Delete(QueryHistoryShare{}); err != nil {
+			return err
+		}
+
 		isStarred = false
-		return nil
+		return touchQuery(session, &queryHistory)
 	})
 
 	if err != nil {
@@ -276,6 +306,7 @@ func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedI
 func (s QueryHistoryService) migrateQueries(ctx context.Context, usr *user.SignedInUser, cmd MigrateQueriesToQueryHistoryCommand) (int, int, error) {
 	queryHistories := make([]*QueryHistory, 0, len(cmd.Queries))
 	starredQueries := make([]*QueryHistoryStar, 0)
+	now := time.Now().Unix()
 
 	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
 		for _, query := range cmd.Queries {
@@ -287,6 +318,7 @@ func (s QueryHistoryService) migrateQueries(ctx context.Context, usr *user.Signe
 				DatasourceUID: query.DatasourceUID,
 				CreatedBy:     usr.UserID,
 				CreatedAt:     query.CreatedAt,
+				UpdatedAt:     now,
 				Comment:       query.Comment,
 			})
 
@@ -359,7 +391,9 @@ func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan i
 			return err
 		}
 
-		return nil
+		// Devices which did not sync since are expected to drop queries older than the retention themselves
+		_, err = session.Exec("DELETE FROM query_history_deleted WHERE deleted_at <= ?", olderThan)
+		return err
 	})
 
 	if err != nil {
@@ -436,3 +470,243 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 
 	return int(deletedRowsCount), nil
 }
+
+// shareQuery shares a starred query of the user with a team the user is a member of
+func (s QueryHistoryService) shareQuery(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	var queryHistory QueryHistory
+	var sharedWith []int64
+	newShare := false
+
+	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		starred, err := session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserID, UID).Exist()
+		if err != nil {
+			return err
+		}
+		if !starred {
+			return ErrQueryNotStarred
+		}
+
+		isMember, err := session.Table("team_member").Where("org_id = ? AND team_id = ? AND user_id = ?", user.OrgID, cmd.TeamID, user.UserID).Exist()
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return ErrNotTeamMember
+		}
+
+		shared, err := session.Where("query_uid = ? AND team_id = ?", UID, cmd.TeamID).Exist(&QueryHistoryShare{})
+		if err != nil {
+			return err
+		}
+		newShare = !shared
+		if !shared {
+			_, err = session.Insert(&QueryHistoryShare{
+				OrgID:     user.OrgID,
+				QueryUID:  UID,
+				TeamID:    cmd.TeamID,
+				SharedBy:  user.UserID,
+				CreatedAt: time.Now().Unix(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if sharedWith, err = sharedTeams(session, UID); err != nil {
+			return err
+		}
+		return touchQuery(session, &queryHistory)
+	})
+
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	dto := QueryHistoryDTO{
+		UID:             queryHistory.UID,
+		DatasourceUID:   queryHistory.DatasourceUID,
+		CreatedBy:       queryHistory.CreatedBy,
+		CreatedAt:       queryHistory.CreatedAt,
+		Comment:         queryHistory.Comment,
+		Queries:         queryHistory.Queries,
+		Starred:         true,
+		SharedWithTeams: sharedWith,
+	}
+
+	if newShare {
+		s.notifyShare(ctx, user, cmd.TeamID, queryHistory)
+	}
+
+	return dto, nil
+}
+
+// unshareQuery stops sharing a query of the user with a team
+func (s QueryHistoryService) unshareQuery(ctx context.Context, user *user.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error) {
+	var queryHistory QueryHistory
+	var sharedWith []int64
+
+	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		id, err := session.Where("org_id = ? AND query_uid = ? AND team_id = ?", user.OrgID, UID, teamID).Delete(QueryHistoryShare{})
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			return ErrQueryNotShared
+		}
+
+		if sharedWith, err = sharedTeams(session, UID); err != nil {
+			return err
+		}
+		return touchQuery(session, &queryHistory)
+	})
+
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	dto := QueryHistoryDTO{
+		UID:             queryHistory.UID,
+		DatasourceUID:   queryHistory.DatasourceUID,
+		CreatedBy:       queryHistory.CreatedBy,
+		CreatedAt:       queryHistory.CreatedAt,
+		Comment:         queryHistory.Comment,
+		Queries:         queryHistory.Queries,
+		Starred:         true,
+		SharedWithTeams: sharedWith,
+	}
+
+	return dto, nil
+}
+
+// syncQueryLimit is the maximum number of changed queries returned by a sync
+const syncQueryLimit = 1000
+
+// syncQueries returns the queries of the user which changed since the given time, and the UIDs of
+// the queries which were deleted since. Changes of the same second as since are returned again so
+// that no change is missed, clients are expected to merge queries by UID.
+func (s QueryHistoryService) syncQueries(ctx context.Context, user *user.SignedInUser, since int64) (QueryHistorySyncResult, error) {
+	result := QueryHistorySyncResult{
+		Queries:     make([]QueryHistoryDTO, 0),
+		DeletedUIDs: make([]string, 0),
+		SyncedAt:    time.Now().Unix(),
+	}
+
+	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		var changed []QueryHistory
+		err := session.Where("org_id = ? AND created_by = ? AND updated_at >= ?", user.OrgID, user.UserID, since).
+			OrderBy("updated_at ASC, id ASC").
+			Limit(syncQueryLimit + 1).
+			Find(&changed)
+		if err != nil {
+			return err
+		}
+		if len(changed) > syncQueryLimit {
+			changed = changed[:syncQueryLimit]
+			result.HasMore = true
+			result.SyncedAt = changed[len(changed)-1].UpdatedAt
+		}
+
+		starred := make(map[string]bool)
+		if len(changed) > 0 {
+			uids := make([]string, 0, len(changed))
+			for _, q := range changed {
+				uids = append(uids, q.UID)
+			}
+			var stars []QueryHistoryStar
+			if err := session.Where("user_id = ?", user.UserID).In("query_uid", uids).Find(&stars); err != nil {
+				return err
+			}
+			for _, star := range stars {
+				starred[star.QueryUID] = true
+			}
+		}
+
+		for _, q := range changed {
+			result.Queries = append(result.Queries, QueryHistoryDTO{
+				UID:           q.UID,
+				DatasourceUID: q.DatasourceUID,
+				CreatedBy:     q.CreatedBy,
+				CreatedAt:     q.CreatedAt,
+				Comment:       q.Comment,
+				Queries:       q.Queries,
+				Starred:       starred[q.UID],
+			})
+		}
+		if err := loadSharedTeams(session, result.Queries); err != nil {
+			return err
+		}
+
+		var deleted []QueryHistoryDeleted
+		err = session.Where("org_id = ? AND user_id = ? AND deleted_at >= ? AND deleted_at <= ?", user.OrgID, user.UserID, since, result.SyncedAt).
+			OrderBy("deleted_at ASC").
+			Find(&deleted)
+		if err != nil {
+			return err
+		}
+		for _, d := range deleted {
+			result.DeletedUIDs = append(result.DeletedUIDs, d.QueryUID)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return QueryHistorySyncResult{}, err
+	}
+
+	return result, nil
+}
+
+// touchQuery marks a query as changed so that it is synced to the other devices of the user
+func touchQuery(session *db.Session, queryHistory *QueryHistory) error {
+	queryHistory.UpdatedAt = time.Now().Unix()
+	_, err := session.Exec("UPDATE query_history SET updated_at = ? WHERE id = ?", queryHistory.UpdatedAt, queryHistory.ID)
+	return err
+}
+
+func sharedTeams(session *db.Session, UID string) ([]int64, error) {
+	teamIDs := make([]int64, 0)
+	err := session.Table("query_history_share").Where("query_uid = ?", UID).OrderBy("team_id").Cols("team_id").Find(&teamIDs)
+	return teamIDs, err
+}
+
+// loadSharedTeams sets the teams the queries are shared with
+func loadSharedTeams(session *db.Session, dtos []QueryHistoryDTO) error {
+	if len(dtos) == 0 {
+		return nil
+	}
+
+	uids := make([]string, 0, len(dtos))
+	for _, dto := range dtos {
+		uids = append(uids, dto.UID)
+	}
+
+	var shares []QueryHistoryShare
+	if err := session.In("query_uid", uids).OrderBy("team_id").Find(&shares); err != nil {
+		return err
+	}
+
+	byQuery := make(map[string][]int64)
+	for _, share := range shares {
+		byQuery[share.QueryUID] = append(byQuery[share.QueryUID], share.TeamID)
+	}
+	for i := range dtos {
+		dtos[i].SharedWithTeams = byQuery[dtos[i].UID]
+	}
+	return nil
+}
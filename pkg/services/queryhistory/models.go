@@ -10,6 +10,9 @@ var (
 	ErrQueryNotFound        = errors.New("query in query history not found")
 	ErrStarredQueryNotFound = errors.New("starred query not found")
 	ErrQueryAlreadyStarred  = errors.New("query was already starred")
+	ErrQueryNotStarred      = errors.New("only starred queries can be shared")
+	ErrNotTeamMember        = errors.New("queries can only be shared with teams the user is a member of")
+	ErrQueryNotShared       = errors.New("query is not shared with the team")
 )
 
 // QueryHistory is the model for query history definitions
@@ -20,8 +23,10 @@ type QueryHistory struct {
 	OrgID         int64  `xorm:"org_id"`
 	CreatedBy     int64
 	CreatedAt     int64
-	Comment       string
-	Queries       *simplejson.Json
+	// UpdatedAt is when the query, its comment, its star or its shares last changed.
+	UpdatedAt int64
+	Comment   string
+	Queries   *simplejson.Json
 }
 
 // QueryHistory is the model for query history star definitions
@@ -31,15 +36,37 @@ type QueryHistoryStar struct {
 	UserID   int64  `xorm:"user_id"`
 }
 
+// QueryHistoryShare is the model for starred queries shared with a team
+type QueryHistoryShare struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	QueryUID  string `xorm:"query_uid"`
+	TeamID    int64  `xorm:"team_id"`
+	SharedBy  int64
+	CreatedAt int64
+}
+
+// QueryHistoryDeleted records deleted queries so that other devices of the user can remove them
+// when syncing
+type QueryHistoryDeleted struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	UserID    int64  `xorm:"user_id"`
+	QueryUID  string `xorm:"query_uid"`
+	DeletedAt int64
+}
+
 type SearchInQueryHistoryQuery struct {
 	DatasourceUIDs []string `json:"datasourceUids"`
 	SearchString   string   `json:"searchString"`
 	OnlyStarred    bool     `json:"onlyStarred"`
-	Sort           string   `json:"sort"`
-	Page           int      `json:"page"`
-	Limit          int      `json:"limit"`
-	From           int64    `json:"from"`
-	To             int64    `json:"to"`
+	// SharedWithMe returns the queries shared with the teams of the user instead of their own queries
+	SharedWithMe bool   `json:"sharedWithMe"`
+	Sort         string `json:"sort"`
+	Page         int    `json:"page"`
+	Limit        int    `json:"limit"`
+	From         int64  `json:"from"`
+	To           int64  `json:"to"`
 }
 
 type QueryHistoryDTO struct {
@@ -50,6 +77,8 @@ type QueryHistoryDTO struct {
 	Comment       string           `json:"comment"`
 	Queries       *simplejson.Json `json:"queries"`
 	Starred       bool             `json:"starred"`
+	// IDs of the teams the query is shared with
+	SharedWithTeams []int64 `json:"sharedWithTeams,omitempty" xorm:"-"`
 }
 
 // QueryHistoryResponse is a response struct for QueryHistoryDTO
@@ -112,3 +141,28 @@ type MigrateQueriesToQueryHistoryCommand struct {
 	// Array of queries to store in query history.
 	Queries []QueryToMigrate `json:"queries"`
 }
+
+// ShareQueryInQueryHistoryCommand is the command for sharing a starred query with a team
+// swagger:model
+type ShareQueryInQueryHistoryCommand struct {
+	// ID of the team the query is shared with. The user must be a member of the team.
+	// required: true
+	// example: 2
+	TeamID int64 `json:"teamId"`
+}
+
+// QueryHistorySyncResult lists the changes of the query history of a user since the last sync
+type QueryHistorySyncResult struct {
+	// Queries created or changed since the last sync
+	Queries []QueryHistoryDTO `json:"queries"`
+	// UIDs of the queries deleted since the last sync
+	DeletedUIDs []string `json:"deletedUids"`
+	// Time to pass as since to the next sync, in seconds since epoch
+	SyncedAt int64 `json:"syncedAt"`
+	// HasMore is set when there are more changes than returned. Sync again right away with syncedAt.
+	HasMore bool `json:"hasMore"`
+}
+
+type QueryHistorySyncResponse struct {
+	Result QueryHistorySyncResult `json:"result"`
+}
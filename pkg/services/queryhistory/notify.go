@@ -0,0 +1,29 @@
+package queryhistory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// notifyShare lets the members of a team know that a query was shared with them.
+func (s QueryHistoryService) notifyShare(ctx context.Context, user *user.SignedInUser, teamID int64, query QueryHistory) {
+	if s.notifications == nil {
+		return
+	}
+
+	_, err := s.notifications.Notify(ctx, notificationcenter.NotifyCommand{
+		OrgID:         user.OrgID,
+		TeamID:        teamID,
+		ExcludeUserID: user.UserID,
+		Kind:          notificationcenter.KindShare,
+		Title:         fmt.Sprintf("%s shared a query with your team", user.NameOrFallback()),
+		Body:          query.Comment,
+		URL:           "/explore",
+	})
+	if err != nil {
+		s.log.Warn("Failed to notify team about shared query", "query", query.UID, "teamId", teamID, "error", err)
+	}
+}
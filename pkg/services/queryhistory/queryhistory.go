@@ -6,16 +6,18 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister) *QueryHistoryService {
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, notifications notificationcenter.Service) *QueryHistoryService {
 	s := &QueryHistoryService{
 		store:         sqlStore,
 		Cfg:           cfg,
 		RouteRegister: routeRegister,
 		log:           log.New("query-history"),
+		notifications: notifications,
 	}
 
 	// Register routes only when query history is enabled
@@ -33,6 +35,9 @@ type Service interface {
 	PatchQueryCommentInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd PatchQueryCommentInQueryHistoryCommand) (QueryHistoryDTO, error)
 	StarQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error)
 	UnstarQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error)
+	ShareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error)
+	UnshareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error)
+	SyncQueryHistory(ctx context.Context, user *user.SignedInUser, since int64) (QueryHistorySyncResult, error)
 	MigrateQueriesToQueryHistory(ctx context.Context, user *user.SignedInUser, cmd MigrateQueriesToQueryHistoryCommand) (int, int, error)
 	DeleteStaleQueriesInQueryHistory(ctx context.Context, olderThan int64) (int, error)
 	EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error)
@@ -43,6 +48,7 @@ type QueryHistoryService struct {
 	Cfg           *setting.Cfg
 	RouteRegister routing.RouteRegister
 	log           log.Logger
+	notifications notificationcenter.Service
 }
 
 func (s QueryHistoryService) CreateQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, cmd CreateQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
@@ -69,6 +75,18 @@ func (s QueryHistoryService) UnstarQueryInQueryHistory(ctx context.Context, user
 	return s.unstarQuery(ctx, user, UID)
 }
 
+func (s QueryHistoryService) ShareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.shareQuery(ctx, user, UID, cmd)
+}
+
+func (s QueryHistoryService) UnshareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, teamID int64) (QueryHistoryDTO, error) {
+	return s.unshareQuery(ctx, user, UID, teamID)
+}
+
+func (s QueryHistoryService) SyncQueryHistory(ctx context.Context, user *user.SignedInUser, since int64) (QueryHistorySyncResult, error) {
+	return s.syncQueries(ctx, user, since)
+}
+
 func (s QueryHistoryService) MigrateQueriesToQueryHistory(ctx context.Context, user *user.SignedInUser, cmd MigrateQueriesToQueryHistoryCommand) (int, int, error) {
 	return s.migrateQueries(ctx, user, cmd)
 }
@@ -0,0 +1,143 @@
+package queryhistory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIntegrationShareQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	const teamID = int64(3)
+	addTeamMember := func(t *testing.T, sc scenarioContext, userID int64) {
+		t.Helper()
+		err := sc.sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("INSERT INTO team_member (org_id, team_id, user_id, created, updated) VALUES (?, ?, ?, ?, ?)",
+				testOrgID, teamID, userID, time.Now(), time.Now())
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	testScenarioWithQueryInQueryHistory(t, "When users tries to share query that is not starred, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			addTeamMember(t, sc, testUserID)
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users tries to share query with a team they are not a member of, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.service.starHandler(sc.reqContext)
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users share a starred query with their team, team members should find it",
+		func(t *testing.T, sc scenarioContext) {
+			addTeamMember(t, sc, testUserID)
+			addTeamMember(t, sc, 2)
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.service.starHandler(sc.reqContext)
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID})
+			result := validateAndUnMarshalResponse(t, sc.service.shareHandler(sc.reqContext))
+			require.Equal(t, []int64{teamID}, result.Result.SharedWithTeams)
+
+			teamMember := &user.SignedInUser{UserID: 2, OrgID: testOrgID}
+			search, err := sc.service.SearchInQueryHistory(context.Background(), teamMember, SearchInQueryHistoryQuery{SharedWithMe: true})
+			require.NoError(t, err)
+			require.Equal(t, 1, search.TotalCount)
+			require.Equal(t, sc.initialResult.Result.UID, search.QueryHistory[0].UID)
+			require.True(t, search.QueryHistory[0].Starred)
+
+			own, err := sc.service.SearchInQueryHistory(context.Background(), sc.reqContext.SignedInUser, SearchInQueryHistoryQuery{SharedWithMe: true})
+			require.NoError(t, err)
+			require.Equal(t, 0, own.TotalCount)
+
+			// unstarring stops sharing the query
+			sc.service.unstarHandler(sc.reqContext)
+			search, err = sc.service.SearchInQueryHistory(context.Background(), teamMember, SearchInQueryHistoryQuery{SharedWithMe: true})
+			require.NoError(t, err)
+			require.Equal(t, 0, search.TotalCount)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users unshare a query, it should no longer be shared with the team",
+		func(t *testing.T, sc scenarioContext) {
+			addTeamMember(t, sc, testUserID)
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.service.starHandler(sc.reqContext)
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{TeamID: teamID})
+			sc.service.shareHandler(sc.reqContext)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":teamId": "3"})
+			result := validateAndUnMarshalResponse(t, sc.service.unshareHandler(sc.reqContext))
+			require.Empty(t, result.Result.SharedWithTeams)
+
+			resp := sc.service.unshareHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+}
+
+func TestIntegrationSyncQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When users sync their query history, it should return changed and deleted queries",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.syncHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var response QueryHistorySyncResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &response))
+			require.Len(t, response.Result.Queries, 1)
+			require.Empty(t, response.Result.DeletedUIDs)
+			require.False(t, response.Result.HasMore)
+
+			since := response.Result.SyncedAt + 1
+			result, err := sc.service.SyncQueryHistory(context.Background(), sc.reqContext.SignedInUser, since)
+			require.NoError(t, err)
+			require.Len(t, result.Queries, 0)
+
+			time.Sleep(1 * time.Second)
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.service.deleteHandler(sc.reqContext)
+
+			result, err = sc.service.SyncQueryHistory(context.Background(), sc.reqContext.SignedInUser, since)
+			require.NoError(t, err)
+			require.Len(t, result.Queries, 0)
+			require.Equal(t, []string{sc.initialResult.Result.UID}, result.DeletedUIDs)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users star a query, it should be synced again",
+		func(t *testing.T, sc scenarioContext) {
+			since := time.Now().Unix() + 1
+			time.Sleep(time.Until(time.Unix(since, 0)))
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.service.starHandler(sc.reqContext)
+
+			result, err := sc.service.SyncQueryHistory(context.Background(), sc.reqContext.SignedInUser, since)
+			require.NoError(t, err)
+			require.Len(t, result.Queries, 1)
+			require.True(t, result.Queries[0].Starred)
+		})
+}
+
+func TestSearchTerms(t *testing.T) {
+	require.Equal(t, []string{}, searchTerms(""))
+	require.Equal(t, []string{"rate", "http_requests"}, searchTerms("  rate http_requests rate "))
+	require.Equal(t, []string{"sum", "status=500 and"}, searchTerms(`sum "status=500 and"`))
+	require.Len(t, searchTerms("a b c d e f g h i j k l"), maxSearchTerms)
+}
@@ -3,6 +3,7 @@ package queryhistory
 import (
 	"bytes"
 	"strings"
+	"unicode"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -23,9 +24,27 @@ func writeStarredSQL(query SearchInQueryHistoryQuery, sqlStore db.DB, builder *d
 }
 
 func writeFiltersSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, sqlStore db.DB, builder *db.SQLBuilder) {
-	params := []interface{}{user.OrgID, user.UserID, query.From, query.To, "%" + query.SearchString + "%", "%" + query.SearchString + "%"}
+	params := []interface{}{user.OrgID, query.From, query.To}
 	var sql bytes.Buffer
-	sql.WriteString(" WHERE query_history.org_id = ? AND query_history.created_by = ? AND query_history.created_at >= ? AND query_history.created_at <= ? AND (query_history.queries " + sqlStore.GetDialect().LikeStr() + " ? OR query_history.comment " + sqlStore.GetDialect().LikeStr() + " ?) ")
+	sql.WriteString(" WHERE query_history.org_id = ? AND query_history.created_at >= ? AND query_history.created_at <= ? ")
+
+	if query.SharedWithMe {
+		sql.WriteString(` AND query_history.created_by <> ? AND query_history.uid IN (
+				SELECT query_history_share.query_uid FROM query_history_share
+				INNER JOIN team_member ON team_member.team_id = query_history_share.team_id
+				WHERE query_history_share.org_id = ? AND team_member.user_id = ?
+			) `)
+		params = append(params, user.UserID, user.OrgID, user.UserID)
+	} else {
+		sql.WriteString(" AND query_history.created_by = ? ")
+		params = append(params, user.UserID)
+	}
+
+	// every term must be found in the queries or the comment
+	for _, term := range searchTerms(query.SearchString) {
+		sql.WriteString(" AND (query_history.queries " + sqlStore.GetDialect().LikeStr() + " ? OR query_history.comment " + sqlStore.GetDialect().LikeStr() + " ?) ")
+		params = append(params, "%"+term+"%", "%"+term+"%")
+	}
 
 	if len(query.DatasourceUIDs) > 0 {
 		for _, uid := range query.DatasourceUIDs {
@@ -36,6 +55,40 @@ func writeFiltersSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, s
 	builder.Write(sql.String(), params...)
 }
 
+// maxSearchTerms limits the number of LIKE conditions of a search.
+const maxSearchTerms = 10
+
+// searchTerms splits a search string into the terms which must all be found in a query. Terms are
+// separated by whitespace, unless they are quoted, e.g. rate "status=500" returns rate and status=500.
+func searchTerms(search string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(term string) {
+		if term != "" && !seen[term] && len(terms) < maxSearchTerms {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	var term strings.Builder
+	quoted := false
+	for _, r := range search {
+		switch {
+		case r == '"':
+			add(term.String())
+			term.Reset()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			add(term.String())
+			term.Reset()
+		default:
+			term.WriteRune(r)
+		}
+	}
+	add(term.String())
+	return terms
+}
+
 func writeSortSQL(query SearchInQueryHistoryQuery, sqlStore db.DB, builder *db.SQLBuilder) {
 	if query.Sort == "time-asc" {
 		builder.Write(" ORDER BY created_at ASC ")
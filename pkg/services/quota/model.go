@@ -1,6 +1,7 @@
 package quota
 
 import (
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +16,8 @@ var ErrInvalidTarget = errutil.NewBase(errutil.StatusInternal, "quota.invalid-ta
 var ErrTargetSrvConflict = errutil.NewBase(errutil.StatusBadRequest, "quota.target-srv-conflict")
 var ErrDisabled = errutil.NewBase(errutil.StatusForbidden, "quota.disabled", errutil.WithPublicMessage("Quotas not enabled"))
 var ErrInvalidTagFormat = errutil.NewBase(errutil.StatusInternal, "quota.invalid-invalid-tag-format")
+var ErrReached = errutil.NewBase(errutil.StatusForbidden, "quota.reached")
+var ErrReachedTemporarily = errutil.NewBase(errutil.StatusTooManyRequests, "quota.reached-temporarily")
 
 type ScopeParameters struct {
 	OrgID  int64
@@ -127,17 +130,16 @@ func (m *Map) Merge(l2 *Map) {
 	}
 }
 
+// Iter returns a snapshot of the items, so the map can be updated while iterating over it.
 func (m *Map) Iter() <-chan Item {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	ch := make(chan Item)
-	go func() {
-		defer close(ch)
-		for t, v := range m.m {
-			ch <- Item{Tag: t, Value: v}
-		}
-	}()
+	ch := make(chan Item, len(m.m))
+	for t, v := range m.m {
+		ch <- Item{Tag: t, Value: v}
+	}
+	close(ch)
 
 	return ch
 }
@@ -213,4 +215,49 @@ type NewUsageReporter struct {
 	TargetSrv     TargetSrv
 	DefaultLimits *Map
 	Reporter      UsageReporterFunc
+	// RetryAfter is set for targets whose usage decreases on its own, like sessions which expire.
+	// Requests rejected by their quotas respond with 429 Too Many Requests and this retry hint,
+	// instead of 403 Forbidden.
+	RetryAfter time.Duration
+}
+
+// Status is the result of checking the quotas of a target service.
+type Status struct {
+	// Reached is true if the request must be rejected.
+	Reached bool
+	// Burst is true if the usage exceeds a limit, but is within the burst allowance.
+	Burst bool
+	// Tag, Limit and Used describe the reached or exceeded quota.
+	Tag   Tag
+	Limit int64
+	Used  int64
+	// RetryAfter is how long to wait before retrying, 0 if retrying doesn't help until the usage
+	// is reduced or the limit is raised.
+	RetryAfter time.Duration
+	// AddUsage counts the resource created by an allowed request in the cached usage, until the usage
+	// is counted again. It must only be called once the resource was created, and is nil if the usage
+	// is not cached.
+	AddUsage func()
+}
+
+// Err returns the error responded to requests rejected by the quota.
+func (s *Status) Err() error {
+	target, _ := s.Tag.GetTarget()
+	scope, _ := s.Tag.GetScope()
+	payload := map[string]interface{}{
+		"target": target,
+		"scope":  scope,
+		"limit":  s.Limit,
+		"used":   s.Used,
+	}
+
+	base, hint := ErrReached, "remove unused resources or ask an administrator to raise the quota"
+	if s.RetryAfter > 0 {
+		base, hint = ErrReachedTemporarily, "retry later"
+		payload["retryAfter"] = int64(s.RetryAfter.Seconds())
+	}
+	err := base.Errorf("%s quota reached, %d of %d used", s.Tag, s.Used, s.Limit)
+	err.PublicMessage = fmt.Sprintf("Quota reached for %s %s, %s", scope, target, hint)
+	err.PublicPayload = payload
+	return err
 }
@@ -15,9 +15,14 @@ type Service interface {
 	// If the cmd.OrgID is set, then the organization quota are updated.
 	// If the cmd.UseID is set, then the user quota are updated.
 	Update(ctx context.Context, cmd *UpdateQuotaCmd) error
-	// QuotaReached is called by the quota middleware for applying quota enforcement to API handlers
+	// Check is called by the quota middleware for applying quota enforcement to API handlers. It
+	// counts the usage at most once per usage_cache_ttl, and the middleware adds the resources created
+	// by the allowed requests to the cached usage.
+	Check(c *models.ReqContext, targetSrv TargetSrv) (*Status, error)
+	// QuotaReached checks the quotas like Check, for API handlers which can't use the middleware
 	QuotaReached(c *models.ReqContext, targetSrv TargetSrv) (bool, error)
-	// CheckQuotaReached checks if the quota limitations have been reached for a specific service
+	// CheckQuotaReached checks if the quota limitations have been reached for a specific service,
+	// counting the current usage
 	CheckQuotaReached(ctx context.Context, targetSrv TargetSrv, scopeParams *ScopeParameters) (bool, error)
 	// DeleteQuotaForUser deletes custom quota limitations for the user
 	DeleteQuotaForUser(ctx context.Context, userID int64) error
@@ -0,0 +1,85 @@
+package quotaimpl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+type usageKey struct {
+	targetSrv quota.TargetSrv
+	orgID     int64
+	userID    int64
+}
+
+type cachedUsage struct {
+	usage   map[quota.Tag]int64
+	expires time.Time
+}
+
+// usageCache keeps the usage counted for the quota checks of requests, so that the resources are
+// not counted on every request. The usage of each instance is cached separately.
+type usageCache struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[usageKey]*cachedUsage
+}
+
+func newUsageCache() *usageCache {
+	return &usageCache{
+		now:     time.Now,
+		entries: map[usageKey]*cachedUsage{},
+	}
+}
+
+// get returns a copy of the cached usage, if it hasn't expired.
+func (c *usageCache) get(key usageKey) (map[quota.Tag]int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	usage := make(map[quota.Tag]int64, len(entry.usage))
+	for tag, used := range entry.usage {
+		usage[tag] = used
+	}
+	return usage, true
+}
+
+func (c *usageCache) set(key usageKey, usage map[quota.Tag]int64, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = &cachedUsage{usage: usage, expires: now.Add(ttl)}
+}
+
+// add counts a resource created by an allowed request in the cached usage of the tags, until the
+// usage is counted again.
+func (c *usageCache) add(key usageKey, tags []quota.Tag) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	for _, tag := range tags {
+		if _, ok := entry.usage[tag]; ok {
+			entry.usage[tag]++
+		}
+	}
+}
@@ -3,7 +3,10 @@ package quotaimpl
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -16,6 +19,10 @@ import (
 type serviceDisabled struct {
 }
 
+func (s *serviceDisabled) Check(c *models.ReqContext, targetSrv quota.TargetSrv) (*quota.Status, error) {
+	return &quota.Status{}, nil
+}
+
 func (s *serviceDisabled) QuotaReached(c *models.ReqContext, targetSrv quota.TargetSrv) (bool, error) {
 	return false, nil
 }
@@ -45,21 +52,25 @@ type service struct {
 	Cfg    *setting.Cfg
 	Logger log.Logger
 
-	mutex     sync.RWMutex
-	reporters map[quota.TargetSrv]quota.UsageReporterFunc
+	mutex      sync.RWMutex
+	reporters  map[quota.TargetSrv]quota.UsageReporterFunc
+	retryAfter map[quota.TargetSrv]time.Duration
+	usage      *usageCache
 
 	defaultLimits *quota.Map
 
 	targetToSrv *quota.TargetToSrv
 }
 
-func ProvideService(db db.DB, cfg *setting.Cfg) quota.Service {
+func ProvideService(db db.DB, cfg *setting.Cfg, settingsProvider setting.Provider) quota.Service {
 	logger := log.New("quota_service")
 	s := service{
 		store:         &sqlStore{db: db, logger: logger},
 		Cfg:           cfg,
 		Logger:        logger,
 		reporters:     make(map[quota.TargetSrv]quota.UsageReporterFunc),
+		retryAfter:    make(map[quota.TargetSrv]time.Duration),
+		usage:         newUsageCache(),
 		defaultLimits: &quota.Map{},
 		targetToSrv:   quota.NewTargetToSrv(),
 	}
@@ -68,6 +79,7 @@ func ProvideService(db db.DB, cfg *setting.Cfg) quota.Service {
 		return &serviceDisabled{}
 	}
 
+	settingsProvider.RegisterReloadHandler("quota", &s)
 	return &s
 }
 
@@ -75,11 +87,13 @@ func (s *service) IsDisabled() bool {
 	return !s.Cfg.Quota.Enabled
 }
 
-// QuotaReached checks that quota is reached for a target. Runs CheckQuotaReached and take context and scope parameters from the request context
-func (s *service) QuotaReached(c *models.ReqContext, targetSrv quota.TargetSrv) (bool, error) {
+// Check checks the quotas of a target for a request, taking the scope parameters from the request
+// context. The usage is cached for usage_cache_ttl, and the resources created by the requests which
+// pass the check are added to the cached usage with Status.AddUsage.
+func (s *service) Check(c *models.ReqContext, targetSrv quota.TargetSrv) (*quota.Status, error) {
 	// No request context means this is a background service, like LDAP Background Sync
 	if c == nil {
-		return false, nil
+		return &quota.Status{}, nil
 	}
 
 	params := &quota.ScopeParameters{}
@@ -87,7 +101,52 @@ func (s *service) QuotaReached(c *models.ReqContext, targetSrv quota.TargetSrv)
 		params.OrgID = c.OrgID
 		params.UserID = c.UserID
 	}
-	return s.CheckQuotaReached(c.Req.Context(), targetSrv, params)
+	ctx := c.Req.Context()
+
+	limits, err := s.getOverridenLimits(ctx, targetSrv, params)
+	if err != nil {
+		return nil, err
+	}
+
+	key := usageKey{targetSrv: targetSrv, orgID: params.OrgID, userID: params.UserID}
+	ttl := s.Cfg.Quota.UsageCacheTTL
+	usage, ok := s.usage.get(key)
+	if !ok {
+		usage, err = s.countUsage(ctx, targetSrv, params)
+		if err != nil {
+			return nil, err
+		}
+		if ttl > 0 {
+			s.usage.set(key, usage, ttl)
+		}
+	}
+
+	status, err := s.evaluate(targetSrv, limits, usage)
+	if err != nil {
+		return nil, err
+	}
+	if status.Burst {
+		s.Logger.FromContext(ctx).Warn("Quota exceeded within the burst allowance", "quota", status.Tag, "limit", status.Limit, "used", status.Used)
+	}
+	if !status.Reached && ttl > 0 {
+		tags := make([]quota.Tag, 0, len(limits))
+		for tag := range limits {
+			tags = append(tags, tag)
+		}
+		status.AddUsage = func() {
+			s.usage.add(key, tags)
+		}
+	}
+	return status, nil
+}
+
+// QuotaReached checks that quota is reached for a target, like Check.
+func (s *service) QuotaReached(c *models.ReqContext, targetSrv quota.TargetSrv) (bool, error) {
+	status, err := s.Check(c, targetSrv)
+	if err != nil {
+		return false, err
+	}
+	return status.Reached, nil
 }
 
 func (s *service) GetQuotasByScope(ctx context.Context, scope quota.Scope, id int64) ([]quota.QuotaDTO, error) {
@@ -189,32 +248,87 @@ func (s *service) CheckQuotaReached(ctx context.Context, targetSrv quota.TargetS
 		return false, err
 	}
 
+	targetUsage, err := s.countUsage(ctx, targetSrv, scopeParams)
+	if err != nil {
+		return false, err
+	}
+
+	status, err := s.evaluate(targetSrv, targetSrvLimits, targetUsage)
+	if err != nil {
+		return false, err
+	}
+	return status.Reached, nil
+}
+
+func (s *service) countUsage(ctx context.Context, targetSrv quota.TargetSrv, scopeParams *quota.ScopeParameters) (map[quota.Tag]int64, error) {
 	usageReporterFunc, ok := s.getReporter(targetSrv)
 	if !ok {
-		return false, quota.ErrInvalidTargetSrv
+		return nil, quota.ErrInvalidTargetSrv
 	}
 	targetUsage, err := usageReporterFunc(ctx, scopeParams)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	for t, limit := range targetSrvLimits {
+	usage := make(map[quota.Tag]int64)
+	for item := range targetUsage.Iter() {
+		usage[item.Tag] = item.Value
+	}
+	return usage, nil
+}
+
+// evaluate compares the usage of a target service with its limits. The usage may exceed a limit by
+// the burst allowance, except for limits of 0 which disable the target.
+func (s *service) evaluate(targetSrv quota.TargetSrv, limits map[quota.Tag]int64, usage map[quota.Tag]int64) (*quota.Status, error) {
+	tags := make([]quota.Tag, 0, len(limits))
+	for tag := range limits {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	status := &quota.Status{}
+	for _, t := range tags {
+		limit := limits[t]
 		switch {
 		case limit < 0:
 			continue
 		case limit == 0:
-			return true, nil
+			return s.reached(targetSrv, t, limit, usage[t]), nil
 		default:
-			u, ok := targetUsage.Get(t)
+			u, ok := usage[t]
 			if !ok {
-				return false, fmt.Errorf("no usage for target:%s", t)
+				return nil, fmt.Errorf("no usage for target:%s", t)
 			}
-			if u >= limit {
-				return true, nil
+			if u < limit {
+				continue
 			}
+			if u < limit+s.burst(limit) {
+				if !status.Burst {
+					status = &quota.Status{Burst: true, Tag: t, Limit: limit, Used: u}
+				}
+				continue
+			}
+			return s.reached(targetSrv, t, limit, u), nil
 		}
 	}
-	return false, nil
+	return status, nil
+}
+
+func (s *service) reached(targetSrv quota.TargetSrv, tag quota.Tag, limit int64, used int64) *quota.Status {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	retryAfter := s.retryAfter[targetSrv]
+	// the cached usage is not counted again before it expires
+	if retryAfter > 0 && retryAfter < s.Cfg.Quota.UsageCacheTTL {
+		retryAfter = s.Cfg.Quota.UsageCacheTTL
+	}
+	return &quota.Status{Reached: true, Tag: tag, Limit: limit, Used: used, RetryAfter: retryAfter}
+}
+
+// burst returns the number of resources by which the usage may exceed a limit.
+func (s *service) burst(limit int64) int64 {
+	return (limit*s.Cfg.Quota.BurstPercent + 99) / 100
 }
 
 func (s *service) DeleteQuotaForUser(ctx context.Context, userID int64) error {
@@ -235,6 +349,9 @@ func (s *service) RegisterQuotaReporter(e *quota.NewUsageReporter) error {
 	}
 
 	s.reporters[e.TargetSrv] = e.Reporter
+	if e.RetryAfter > 0 {
+		s.retryAfter[e.TargetSrv] = e.RetryAfter
+	}
 
 	for item := range e.DefaultLimits.Iter() {
 		target, err := item.Tag.GetTarget()
@@ -252,6 +369,52 @@ func (s *service) RegisterQuotaReporter(e *quota.NewUsageReporter) error {
 	return nil
 }
 
+// Reload updates the default limits after the quota settings were read again.
+func (s *service) Reload(setting.Section) error {
+	limits := map[quota.Scope]map[quota.Target]int64{
+		quota.GlobalScope: limitsByTarget(s.Cfg.Quota.Global),
+		quota.OrgScope:    limitsByTarget(s.Cfg.Quota.Org),
+		quota.UserScope:   limitsByTarget(s.Cfg.Quota.User),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for item := range s.defaultLimits.Iter() {
+		scope, err := item.Tag.GetScope()
+		if err != nil {
+			return err
+		}
+		target, err := item.Tag.GetTarget()
+		if err != nil {
+			return err
+		}
+		if limit, ok := limits[scope][target]; ok {
+			s.defaultLimits.Set(item.Tag, limit)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) Validate(setting.Section) error {
+	return nil
+}
+
+// limitsByTarget returns the limits of quota settings by the target in their struct tags.
+func limitsByTarget(settings interface{}) map[quota.Target]int64 {
+	v := reflect.ValueOf(settings)
+	limits := make(map[quota.Target]int64, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		target := v.Type().Field(i).Tag.Get("target")
+		if target == "" || target == "-" {
+			continue
+		}
+		limits[quota.Target(target)] = v.Field(i).Int()
+	}
+	return limits
+}
+
 func (s *service) getReporter(target quota.TargetSrv) (quota.UsageReporterFunc, bool) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -2,6 +2,9 @@ package quotaimpl
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -9,6 +12,8 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -38,6 +43,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"github.com/xorcare/pointer"
@@ -55,6 +62,127 @@ func TestQuotaService(t *testing.T) {
 	})
 }
 
+func TestQuotaService_Reload(t *testing.T) {
+	cfg := setting.NewCfg()
+	quotaService := service{
+		Cfg:           cfg,
+		defaultLimits: &quota.Map{},
+	}
+
+	orgUserTag, err := quota.NewTag(quota.TargetSrv(org.QuotaTargetSrv), quota.Target(org.OrgUserQuotaTarget), quota.OrgScope)
+	require.NoError(t, err)
+	userOrgTag, err := quota.NewTag(quota.TargetSrv(org.QuotaTargetSrv), quota.Target(org.OrgUserQuotaTarget), quota.UserScope)
+	require.NoError(t, err)
+	sessionTag, err := quota.NewTag(auth.QuotaTargetSrv, auth.QuotaTarget, quota.GlobalScope)
+	require.NoError(t, err)
+	quotaService.defaultLimits.Set(orgUserTag, 10)
+	quotaService.defaultLimits.Set(userOrgTag, 10)
+	quotaService.defaultLimits.Set(sessionTag, -1)
+
+	cfg.Quota.Org.User = 20
+	cfg.Quota.User.Org = 5
+	cfg.Quota.Global.Session = 100
+	require.NoError(t, quotaService.Reload(nil))
+
+	for tag, limit := range map[quota.Tag]int64{orgUserTag: 20, userOrgTag: 5, sessionTag: 100} {
+		actual, ok := quotaService.defaultLimits.Get(tag)
+		require.True(t, ok)
+		require.Equal(t, limit, actual, tag)
+	}
+}
+
+func TestQuotaService_Check(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.Quota.Enabled = true
+	cfg.Quota.UsageCacheTTL = time.Minute
+	cfg.Quota.BurstPercent = 50
+	quotaService := ProvideService(nil, cfg, setting.ProvideProvider(cfg)).(*service)
+	quotaService.store = &quotatest.FakeQuotaStore{}
+	now := time.Now()
+	quotaService.usage.now = func() time.Time { return now }
+
+	tag, err := quota.NewTag(auth.QuotaTargetSrv, auth.QuotaTarget, quota.GlobalScope)
+	require.NoError(t, err)
+	limits := &quota.Map{}
+	limits.Set(tag, 2)
+	var used, counted int64
+	require.NoError(t, quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     auth.QuotaTargetSrv,
+		DefaultLimits: limits,
+		Reporter: func(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+			counted++
+			u := &quota.Map{}
+			u.Set(tag, used)
+			return u, nil
+		},
+		RetryAfter: time.Second,
+	}))
+
+	c := &models.ReqContext{
+		Context: &web.Context{Req: httptest.NewRequest(http.MethodPost, "/login", nil)},
+	}
+	// check checks the quota for a request which creates a resource if it is allowed
+	check := func() *quota.Status {
+		status, err := quotaService.Check(c, auth.QuotaTargetSrv)
+		require.NoError(t, err)
+		if status.AddUsage != nil {
+			status.AddUsage()
+		}
+		return status
+	}
+
+	t.Run("Should count the usage once per cache ttl and add allowed requests", func(t *testing.T) {
+		used = 1
+		require.False(t, check().Reached)
+		require.Equal(t, int64(1), counted)
+
+		status := check()
+		require.False(t, status.Reached)
+		require.True(t, status.Burst)
+		require.Equal(t, int64(2), status.Used)
+		require.Equal(t, int64(1), counted)
+	})
+
+	t.Run("Should reject requests once the burst allowance is used", func(t *testing.T) {
+		status := check()
+		require.True(t, status.Reached)
+		require.Equal(t, tag, status.Tag)
+		require.Equal(t, int64(2), status.Limit)
+		require.Equal(t, int64(3), status.Used)
+		require.Equal(t, time.Minute, status.RetryAfter, "retry after the cached usage expires")
+
+		var gfErr errutil.Error
+		require.True(t, errors.As(status.Err(), &gfErr))
+		require.Equal(t, http.StatusTooManyRequests, gfErr.Public().StatusCode)
+		require.Equal(t, int64(60), gfErr.Public().Extra["retryAfter"])
+	})
+
+	t.Run("Should count the usage again once the cache expires", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		used = 0
+		require.False(t, check().Reached)
+		require.Equal(t, int64(2), counted)
+	})
+
+	t.Run("Should not add requests which didn't create a resource", func(t *testing.T) {
+		failed, err := quotaService.Check(c, auth.QuotaTargetSrv)
+		require.NoError(t, err)
+		require.NotNil(t, failed.AddUsage)
+
+		status := check()
+		require.Equal(t, failed.Used, status.Used)
+		require.Equal(t, int64(2), counted)
+	})
+
+	t.Run("Should always count the usage of direct checks", func(t *testing.T) {
+		used = 3
+		reached, err := quotaService.CheckQuotaReached(context.Background(), auth.QuotaTargetSrv, nil)
+		require.NoError(t, err)
+		require.True(t, reached)
+		require.Equal(t, int64(3), counted)
+	})
+}
+
 func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -86,8 +214,8 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 	}
 
 	b := bus.ProvideBus(tracing.InitializeTracerForTest())
-	quotaService := ProvideService(sqlStore, sqlStore.Cfg)
-	orgService, err := orgimpl.ProvideService(sqlStore, sqlStore.Cfg, quotaService)
+	quotaService := ProvideService(sqlStore, sqlStore.Cfg, setting.ProvideProvider(sqlStore.Cfg))
+	orgService, err := orgimpl.ProvideService(sqlStore, sqlStore.Cfg, quotaService, actest.FakeService{})
 	require.NoError(t, err)
 	userService, err := userimpl.ProvideService(sqlStore, orgService, sqlStore.Cfg, nil, nil, quotaService)
 	require.NoError(t, err)
@@ -221,7 +349,7 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 			cfg := *sqlStore.Cfg
 			cfg.UnifiedAlerting = setting.UnifiedAlertingSettings{Enabled: pointer.Bool(false)}
 
-			quotaSrv := ProvideService(sqlStore, &cfg)
+			quotaSrv := ProvideService(sqlStore, &cfg, setting.ProvideProvider(&cfg))
 			q, err := getQuotaBySrvTargetScope(t, quotaSrv, ngalertmodels.QuotaTargetSrv, ngalertmodels.QuotaTarget, quota.OrgScope, &quota.ScopeParameters{OrgID: o.ID})
 
 			require.NoError(t, err)
@@ -476,7 +604,7 @@ func setupEnv(t *testing.T, sqlStore *sqlstore.SQLStore, b bus.Bus, quotaService
 	m := metrics.NewNGAlert(prometheus.NewRegistry())
 	_, err = ngalert.ProvideService(
 		sqlStore.Cfg, &ngalerttests.FakeFeatures{}, nil, nil, routing.NewRouteRegister(), sqlStore, nil, nil, nil, quotaService,
-		secretsService, nil, m, &foldertest.FakeService{}, &acmock.Mock{}, &dashboards.FakeDashboardService{}, nil, b, &acmock.Mock{}, annotationstest.NewFakeAnnotationsRepo(),
+		secretsService, nil, m, &foldertest.FakeService{}, &acmock.Mock{}, &dashboards.FakeDashboardService{}, nil, b, &acmock.Mock{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil, nil, nil,
 	)
 	require.NoError(t, err)
 	_, err = storesrv.ProvideService(sqlStore, featuremgmt.WithFeatures(), sqlStore.Cfg, quotaService, storesrv.ProvideSystemUsersService())
@@ -24,6 +24,13 @@ func (f *FakeQuotaService) Update(ctx context.Context, cmd *quota.UpdateQuotaCmd
 	return nil
 }
 
+func (f *FakeQuotaService) Check(c *models.ReqContext, target quota.TargetSrv) (*quota.Status, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &quota.Status{Reached: f.reached}, nil
+}
+
 func (f *FakeQuotaService) QuotaReached(c *models.ReqContext, target quota.TargetSrv) (bool, error) {
 	return f.reached, f.err
 }
@@ -49,7 +56,7 @@ func (f *FakeQuotaStore) DeleteByUser(ctx quota.Context, userID int64) error {
 }
 
 func (f *FakeQuotaStore) Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
-	return nil, f.ExpectedError
+	return &quota.Map{}, f.ExpectedError
 }
 
 func (f *FakeQuotaStore) Update(ctx quota.Context, cmd *quota.UpdateQuotaCmd) error {
@@ -31,9 +31,8 @@ var netClient = &http.Client{
 const authTokenHeader = "X-Auth-Token" //#nosec G101 -- This is a false positive
 
 var (
-	remoteVersionFetchInterval   time.Duration = time.Second * 15
-	remoteVersionFetchRetries    uint          = 4
-	remoteVersionRefreshInterval               = time.Minute * 15
+	remoteVersionFetchInterval time.Duration = time.Second * 15
+	remoteVersionFetchRetries  uint          = 4
 )
 
 func (rs *RenderingService) renderViaHTTP(ctx context.Context, renderKey string, opts Opts) (*RenderResult, error) {
@@ -42,12 +41,7 @@ func (rs *RenderingService) renderViaHTTP(ctx context.Context, renderKey string,
 		return nil, err
 	}
 
-	rendererURL, err := url.Parse(rs.Cfg.RendererUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	queryParams := rendererURL.Query()
+	queryParams := url.Values{}
 	url := rs.getURL(opts.Path)
 	queryParams.Add("url", url)
 	queryParams.Add("renderKey", renderKey)
@@ -59,13 +53,11 @@ func (rs *RenderingService) renderViaHTTP(ctx context.Context, renderKey string,
 	queryParams.Add("timeout", strconv.Itoa(int(opts.Timeout.Seconds())))
 	queryParams.Add("deviceScaleFactor", fmt.Sprintf("%f", opts.DeviceScaleFactor))
 
-	rendererURL.RawQuery = queryParams.Encode()
-
 	// gives service some additional time to timeout and return possible errors.
 	reqContext, cancel := context.WithTimeout(ctx, getRequestTimeout(opts.TimeoutOpts))
 	defer cancel()
 
-	resp, err := rs.doRequest(reqContext, rendererURL, opts.Headers)
+	resp, err := rs.doRendererRequest(reqContext, "", queryParams, opts.Headers)
 	if err != nil {
 		return nil, err
 	}
@@ -91,12 +83,7 @@ func (rs *RenderingService) renderCSVViaHTTP(ctx context.Context, renderKey stri
 		return nil, err
 	}
 
-	rendererURL, err := url.Parse(rs.Cfg.RendererUrl + "/csv")
-	if err != nil {
-		return nil, err
-	}
-
-	queryParams := rendererURL.Query()
+	queryParams := url.Values{}
 	url := rs.getURL(opts.Path)
 	queryParams.Add("url", url)
 	queryParams.Add("renderKey", renderKey)
@@ -105,13 +92,11 @@ func (rs *RenderingService) renderCSVViaHTTP(ctx context.Context, renderKey stri
 	queryParams.Add("encoding", opts.Encoding)
 	queryParams.Add("timeout", strconv.Itoa(int(opts.Timeout.Seconds())))
 
-	rendererURL.RawQuery = queryParams.Encode()
-
 	// gives service some additional time to timeout and return possible errors.
 	reqContext, cancel := context.WithTimeout(ctx, getRequestTimeout(opts.TimeoutOpts))
 	defer cancel()
 
-	resp, err := rs.doRequest(reqContext, rendererURL, opts.Headers)
+	resp, err := rs.doRendererRequest(reqContext, "/csv", queryParams, opts.Headers)
 	if err != nil {
 		return nil, err
 	}
@@ -230,14 +215,23 @@ func (rs *RenderingService) getRemotePluginVersionWithRetry(callback func(string
 	}()
 }
 
+// getRemotePluginVersion checks the health of the remote renderers and returns the
+// lowest version reported by them.
 func (rs *RenderingService) getRemotePluginVersion() (string, error) {
-	rendererURL, err := url.Parse(rs.Cfg.RendererUrl + "/version")
+	return rs.checkRenderers(context.Background())
+}
+
+func (rs *RenderingService) getRendererVersion(ctx context.Context, rendererURL string) (string, error) {
+	versionURL, err := url.Parse(rendererURL + "/version")
 	if err != nil {
 		return "", err
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
 	headers := make(map[string][]string)
-	resp, err := rs.doRequest(context.Background(), rendererURL, headers)
+	resp, err := rs.doRequest(ctx, versionURL, headers)
 	if err != nil {
 		return "", err
 	}
@@ -268,7 +262,8 @@ func (rs *RenderingService) getRemotePluginVersion() (string, error) {
 func (rs *RenderingService) refreshRemotePluginVersion() {
 	newVersion, err := rs.getRemotePluginVersion()
 	if err != nil {
-		rs.log.Info("Failed to refresh remote plugin version", "err", err)
+		// unhealthy renderers are logged by the health check
+		rs.log.Debug("Failed to refresh remote plugin version", "err", err)
 		return
 	}
 
@@ -0,0 +1,208 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	healthCheckTimeout         = 5 * time.Second
+)
+
+// remoteRenderer is one of the remote image renderers configured in server_url.
+type remoteRenderer struct {
+	url         string
+	sanitizeURL string
+
+	healthy    int32
+	inProgress int32
+}
+
+func (r *remoteRenderer) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// rendererPool balances render requests across the remote image renderers.
+type rendererPool struct {
+	renderers []*remoteRenderer
+	next      uint32
+}
+
+// newRendererPool returns a pool of the comma-separated renderer URLs. Renderers
+// are considered healthy until their first health check fails.
+func newRendererPool(serverURL string) *rendererPool {
+	p := &rendererPool{}
+	for _, u := range strings.Split(serverURL, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		p.renderers = append(p.renderers, &remoteRenderer{
+			url:         u,
+			sanitizeURL: getSanitizerURL(u),
+			healthy:     1,
+		})
+	}
+	metrics.MRenderingRenderersHealthy.Set(float64(len(p.renderers)))
+	return p
+}
+
+// pick returns the healthy renderer with the fewest requests in progress, skipping
+// the renderers already tried. Ties are broken round-robin. It returns nil if no
+// healthy renderer is left.
+func (p *rendererPool) pick(tried map[*remoteRenderer]bool) *remoteRenderer {
+	if len(p.renderers) == 0 {
+		return nil
+	}
+
+	start := int(atomic.AddUint32(&p.next, 1))
+	var picked *remoteRenderer
+	for i := range p.renderers {
+		r := p.renderers[(start+i)%len(p.renderers)]
+		if tried[r] || !r.isHealthy() {
+			continue
+		}
+		if picked == nil || atomic.LoadInt32(&r.inProgress) < atomic.LoadInt32(&picked.inProgress) {
+			picked = r
+		}
+	}
+	return picked
+}
+
+func (p *rendererPool) available() bool {
+	for _, r := range p.renderers {
+		if r.isHealthy() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *rendererPool) setHealthy(r *remoteRenderer, healthy bool) bool {
+	var value int32
+	if healthy {
+		value = 1
+	}
+	changed := atomic.SwapInt32(&r.healthy, value) != value
+
+	count := 0
+	for _, r := range p.renderers {
+		if r.isHealthy() {
+			count++
+		}
+	}
+	metrics.MRenderingRenderersHealthy.Set(float64(count))
+	return changed
+}
+
+// trackedBody counts a request as in progress on its renderer until the response body is closed.
+type trackedBody struct {
+	io.ReadCloser
+	once     sync.Once
+	renderer *remoteRenderer
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(func() { atomic.AddInt32(&b.renderer.inProgress, -1) })
+	return b.ReadCloser.Close()
+}
+
+// doRendererRequest sends a request to a healthy renderer of the pool. If the
+// renderer cannot be reached, it is marked as unhealthy and the request is sent
+// to the next one. It returns ErrRenderUnavailable if no renderer is healthy.
+func (rs *RenderingService) doRendererRequest(ctx context.Context, endpoint string, params url.Values, headers map[string][]string) (*http.Response, error) {
+	tried := make(map[*remoteRenderer]bool)
+	var lastErr error
+	for {
+		renderer := rs.pool.pick(tried)
+		if renderer == nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, ErrRenderUnavailable
+		}
+		tried[renderer] = true
+
+		u, err := url.Parse(renderer.url + endpoint)
+		if err != nil {
+			return nil, err
+		}
+		query := u.Query()
+		for k, v := range params {
+			query[k] = v
+		}
+		u.RawQuery = query.Encode()
+
+		atomic.AddInt32(&renderer.inProgress, 1)
+		resp, err := rs.doRequest(ctx, u, headers)
+		if err != nil {
+			atomic.AddInt32(&renderer.inProgress, -1)
+			if ctx.Err() != nil || errors.Is(err, ErrServerTimeout) {
+				return nil, err
+			}
+
+			if rs.pool.setHealthy(renderer, false) {
+				rs.log.Warn("Remote renderer is unreachable, marking it as unhealthy", "url", renderer.url, "err", err)
+			}
+			lastErr = err
+			continue
+		}
+
+		resp.Body = &trackedBody{ReadCloser: resp.Body, renderer: renderer}
+		return resp, nil
+	}
+}
+
+// checkRenderers requests the version of every renderer of the pool and updates
+// their health. It returns the lowest version reported, so that only capabilities
+// supported by all renderers are used.
+func (rs *RenderingService) checkRenderers(ctx context.Context) (string, error) {
+	var (
+		lowest     *semver.Version
+		lowestName string
+		lastErr    error
+	)
+	for _, renderer := range rs.pool.renderers {
+		version, err := rs.getRendererVersion(ctx, renderer.url)
+		if err != nil {
+			if rs.pool.setHealthy(renderer, false) {
+				rs.log.Warn("Remote renderer failed its health check", "url", renderer.url, "err", err)
+			}
+			lastErr = err
+			continue
+		}
+		if rs.pool.setHealthy(renderer, true) {
+			rs.log.Info("Remote renderer passed its health check", "url", renderer.url, "version", version)
+		}
+
+		parsed, err := semver.NewVersion(version)
+		if err != nil {
+			rs.log.Warn("Remote renderer reported an invalid version", "url", renderer.url, "version", version)
+			continue
+		}
+		if lowest == nil || parsed.LessThan(lowest) {
+			lowest = parsed
+			lowestName = version
+		}
+	}
+
+	if lowest == nil {
+		if lastErr == nil {
+			lastErr = errors.New("no remote renderer reported a valid version")
+		}
+		return "", lastErr
+	}
+	return lowestName, nil
+}
@@ -0,0 +1,214 @@
+package rendering
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRendererPool(t *testing.T) {
+	t.Run("Should parse a comma-separated list of renderers", func(t *testing.T) {
+		pool := newRendererPool("http://renderer-1:8081/render, http://renderer-2:8081/render,")
+		require.Len(t, pool.renderers, 2)
+		assert.Equal(t, "http://renderer-2:8081/render", pool.renderers[1].url)
+		assert.Equal(t, "http://renderer-2:8081/sanitize", pool.renderers[1].sanitizeURL)
+		assert.True(t, pool.available())
+	})
+
+	t.Run("Should pick the healthy renderer with the fewest requests in progress", func(t *testing.T) {
+		pool := newRendererPool("http://renderer-1,http://renderer-2,http://renderer-3")
+		pool.renderers[0].inProgress = 1
+		pool.renderers[1].inProgress = 3
+		pool.setHealthy(pool.renderers[2], false)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, pool.renderers[0], pool.pick(nil))
+		}
+		assert.Equal(t, pool.renderers[1], pool.pick(map[*remoteRenderer]bool{pool.renderers[0]: true}))
+
+		pool.setHealthy(pool.renderers[0], false)
+		pool.setHealthy(pool.renderers[1], false)
+		assert.Nil(t, pool.pick(nil))
+		assert.False(t, pool.available())
+	})
+
+	t.Run("Should take turns between idle renderers", func(t *testing.T) {
+		pool := newRendererPool("http://renderer-1,http://renderer-2")
+		first := pool.pick(nil)
+		second := pool.pick(nil)
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestDoRendererRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/render/csv", r.URL.Path)
+		assert.Equal(t, "bar", r.URL.Query().Get("foo"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// a listener which was closed refuses connections
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	rs := &RenderingService{
+		Cfg:  setting.NewCfg(),
+		log:  log.New("rendering-test"),
+		pool: newRendererPool(unreachable.URL + "/render," + server.URL + "/render"),
+	}
+	params := url.Values{"foo": []string{"bar"}}
+
+	t.Run("Should fail over to the next renderer if a renderer is unreachable", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			resp, err := rs.doRendererRequest(context.Background(), "/csv", params, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), rs.pool.renderers[1].inProgress)
+			require.NoError(t, resp.Body.Close())
+			assert.Equal(t, int32(0), rs.pool.renderers[1].inProgress)
+		}
+		assert.False(t, rs.pool.renderers[0].isHealthy())
+		assert.True(t, rs.pool.renderers[1].isHealthy())
+	})
+
+	t.Run("Should return ErrRenderUnavailable if no renderer is healthy", func(t *testing.T) {
+		rs.pool.setHealthy(rs.pool.renderers[1], false)
+		_, err := rs.doRendererRequest(context.Background(), "/csv", params, nil)
+		assert.ErrorIs(t, err, ErrRenderUnavailable)
+	})
+}
+
+func TestCheckRenderers(t *testing.T) {
+	versionHandler := func(version string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(`{"version":"` + version + `"}`))
+			require.NoError(t, err)
+		}
+	}
+	newer := httptest.NewServer(versionHandler("3.6.1"))
+	defer newer.Close()
+	older := httptest.NewServer(versionHandler("3.4.0"))
+	defer older.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	rs := &RenderingService{
+		Cfg:  setting.NewCfg(),
+		log:  log.New("rendering-test"),
+		pool: newRendererPool(newer.URL + "/render," + failing.URL + "/render," + older.URL + "/render"),
+	}
+
+	version, err := rs.checkRenderers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "3.4.0", version)
+	assert.True(t, rs.pool.renderers[0].isHealthy())
+	assert.False(t, rs.pool.renderers[1].isHealthy())
+	assert.True(t, rs.pool.renderers[2].isHealthy())
+
+	t.Run("Should render an error image if no renderer is healthy", func(t *testing.T) {
+		path, err := filepath.Abs("../../../")
+		require.NoError(t, err)
+
+		rs := &RenderingService{
+			Cfg:  &setting.Cfg{HomePath: path, RendererUrl: failing.URL + "/render"},
+			log:  log.New("rendering-test"),
+			pool: newRendererPool(failing.URL + "/render"),
+		}
+		_, err = rs.checkRenderers(context.Background())
+		require.Error(t, err)
+
+		result, err := rs.Render(context.Background(), Opts{ConcurrentLimit: 1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, path+"/public/img/rendering_error_dark.png", result.FilePath)
+
+		_, err = rs.Render(context.Background(), Opts{ConcurrentLimit: 1, ErrorOpts: ErrorOpts{ErrorRenderUnavailable: true}}, nil)
+		assert.ErrorIs(t, err, ErrRenderUnavailable)
+	})
+}
+
+func TestRenderQueue(t *testing.T) {
+	t.Run("Should take turns between organizations", func(t *testing.T) {
+		q := renderQueue{}
+		renders := make([]*queuedRender, 4)
+		for i := range renders {
+			renders[i] = &queuedRender{limit: 10}
+		}
+		q.push(1, renders[0])
+		q.push(1, renders[1])
+		q.push(1, renders[2])
+		q.push(2, renders[3])
+
+		assert.Equal(t, renders[0], q.pop(0))
+		assert.Equal(t, renders[3], q.pop(0))
+		assert.Equal(t, renders[1], q.pop(0))
+		assert.Equal(t, renders[2], q.pop(0))
+		assert.Nil(t, q.pop(0))
+		assert.Equal(t, 0, q.size)
+	})
+
+	t.Run("Should skip requests whose limit is reached", func(t *testing.T) {
+		q := renderQueue{}
+		strict := &queuedRender{limit: 1}
+		loose := &queuedRender{limit: 10}
+		q.push(1, strict)
+		q.push(2, loose)
+
+		assert.Equal(t, loose, q.pop(5))
+		assert.Nil(t, q.pop(5))
+		assert.Equal(t, strict, q.pop(1))
+	})
+
+	t.Run("Should remove requests which stopped waiting", func(t *testing.T) {
+		q := renderQueue{}
+		r := &queuedRender{limit: 10}
+		q.push(1, r)
+		assert.True(t, q.remove(1, r))
+		assert.False(t, q.remove(1, r))
+		assert.Empty(t, q.orgs)
+		assert.Nil(t, q.pop(0))
+	})
+}
+
+func TestAcquireRenderSlot(t *testing.T) {
+	t.Run("Should fail immediately without queue timeout", func(t *testing.T) {
+		rs := &RenderingService{Cfg: &setting.Cfg{}, inProgressCount: 2}
+		assert.False(t, rs.acquireRenderSlot(context.Background(), 1, 1))
+	})
+
+	t.Run("Should wait for a free slot", func(t *testing.T) {
+		rs := &RenderingService{Cfg: &setting.Cfg{RendererQueueTimeout: time.Minute}, inProgressCount: 2}
+
+		acquired := make(chan bool)
+		go func() {
+			acquired <- rs.acquireRenderSlot(context.Background(), 1, 1)
+		}()
+		require.Eventually(t, func() bool {
+			rs.queueMu.Lock()
+			defer rs.queueMu.Unlock()
+			return rs.queue.size == 1
+		}, time.Second, time.Millisecond)
+
+		rs.releaseRenderSlot()
+		assert.True(t, <-acquired)
+		assert.Equal(t, int32(2), rs.inProgressCount)
+	})
+
+	t.Run("Should give up after the queue timeout", func(t *testing.T) {
+		rs := &RenderingService{Cfg: &setting.Cfg{RendererQueueTimeout: time.Millisecond}, inProgressCount: 2}
+		assert.False(t, rs.acquireRenderSlot(context.Background(), 1, 1))
+		assert.Equal(t, 0, rs.queue.size)
+	})
+}
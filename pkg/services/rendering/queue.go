@@ -0,0 +1,139 @@
+package rendering
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+// renderQueue holds the render requests waiting for a free rendering slot. The
+// requests are grouped by organization and the organizations take turns, so one
+// organization rendering many images cannot starve the others.
+type renderQueue struct {
+	orgs    []int64
+	waiting map[int64][]*queuedRender
+	size    int
+}
+
+type queuedRender struct {
+	limit   int
+	granted chan struct{}
+}
+
+func (q *renderQueue) push(orgID int64, r *queuedRender) {
+	if q.waiting == nil {
+		q.waiting = make(map[int64][]*queuedRender)
+	}
+	if len(q.waiting[orgID]) == 0 {
+		q.orgs = append(q.orgs, orgID)
+	}
+	q.waiting[orgID] = append(q.waiting[orgID], r)
+	q.size++
+}
+
+// pop removes the oldest request of the first organization in turn whose
+// concurrent limit allows another render. The organization then moves to the
+// end of the turn order.
+func (q *renderQueue) pop(inProgress int) *queuedRender {
+	for i, orgID := range q.orgs {
+		r := q.waiting[orgID][0]
+		if inProgress > r.limit {
+			continue
+		}
+
+		q.waiting[orgID] = q.waiting[orgID][1:]
+		q.orgs = append(q.orgs[:i:i], q.orgs[i+1:]...)
+		if len(q.waiting[orgID]) > 0 {
+			q.orgs = append(q.orgs, orgID)
+		} else {
+			delete(q.waiting, orgID)
+		}
+		q.size--
+		return r
+	}
+	return nil
+}
+
+// remove removes a request which stopped waiting. It returns false if the
+// request is not queued anymore because it was granted a slot.
+func (q *renderQueue) remove(orgID int64, r *queuedRender) bool {
+	waiting := q.waiting[orgID]
+	for i := range waiting {
+		if waiting[i] != r {
+			continue
+		}
+
+		q.waiting[orgID] = append(waiting[:i:i], waiting[i+1:]...)
+		if len(q.waiting[orgID]) == 0 {
+			delete(q.waiting, orgID)
+			for j := range q.orgs {
+				if q.orgs[j] == orgID {
+					q.orgs = append(q.orgs[:j:j], q.orgs[j+1:]...)
+					break
+				}
+			}
+		}
+		q.size--
+		return true
+	}
+	return false
+}
+
+// acquireRenderSlot reserves a rendering slot. If the concurrent limit is reached,
+// it waits for a free slot up to the configured queue timeout. It returns false
+// if no slot became free in time.
+func (rs *RenderingService) acquireRenderSlot(ctx context.Context, orgID int64, limit int) bool {
+	rs.queueMu.Lock()
+	if int(atomic.LoadInt32(&rs.inProgressCount)) <= limit {
+		metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
+		rs.queueMu.Unlock()
+		return true
+	}
+
+	timeout := rs.Cfg.RendererQueueTimeout
+	if timeout <= 0 {
+		rs.queueMu.Unlock()
+		return false
+	}
+
+	r := &queuedRender{limit: limit, granted: make(chan struct{})}
+	rs.queue.push(orgID, r)
+	metrics.MRenderingQueueWaiting.Set(float64(rs.queue.size))
+	rs.queueMu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-r.granted:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	rs.queueMu.Lock()
+	defer rs.queueMu.Unlock()
+
+	if !rs.queue.remove(orgID, r) {
+		// the slot was granted while we stopped waiting
+		return true
+	}
+	metrics.MRenderingQueueWaiting.Set(float64(rs.queue.size))
+	return false
+}
+
+// releaseRenderSlot frees a rendering slot and hands it to the next waiting request.
+func (rs *RenderingService) releaseRenderSlot() {
+	rs.queueMu.Lock()
+	defer rs.queueMu.Unlock()
+
+	inProgress := atomic.AddInt32(&rs.inProgressCount, -1)
+	if next := rs.queue.pop(int(inProgress)); next != nil {
+		inProgress = atomic.AddInt32(&rs.inProgressCount, 1)
+		metrics.MRenderingQueueWaiting.Set(float64(rs.queue.size))
+		close(next.granted)
+	}
+	metrics.MRenderingQueue.Set(float64(inProgress))
+}
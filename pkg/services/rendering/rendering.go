@@ -11,7 +11,6 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -37,9 +36,11 @@ type RenderingService struct {
 	renderAction      renderFunc
 	renderCSVAction   renderCSVFunc
 	sanitizeSVGAction sanitizeFunc
-	sanitizeURL       string
+	pool              *rendererPool
 	domain            string
 	inProgressCount   int32
+	queueMu           sync.Mutex
+	queue             renderQueue
 	version           string
 	versionMutex      sync.RWMutex
 	capabilities      []Capability
@@ -65,8 +66,8 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm p
 
 	logger := log.New("rendering")
 
-	// URL for HTTP sanitize API
-	var sanitizeURL string
+	// pool of the remote renderers requests are balanced across
+	var pool *rendererPool
 
 	//  value used for domain attribute of renderKey cookie
 	var domain string
@@ -79,7 +80,7 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm p
 			return nil, err
 		}
 
-		sanitizeURL = getSanitizerURL(cfg.RendererUrl)
+		pool = newRendererPool(cfg.RendererUrl)
 		domain = u.Hostname()
 	case cfg.HTTPAddr != setting.DefaultHTTPAddr:
 		domain = cfg.HTTPAddr
@@ -112,7 +113,7 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm p
 		RendererPluginManager: rm,
 		log:                   logger,
 		domain:                domain,
-		sanitizeURL:           sanitizeURL,
+		pool:                  pool,
 	}
 	return s, nil
 }
@@ -142,15 +143,19 @@ func (rs *RenderingService) Run(ctx context.Context) error {
 		rs.renderCSVAction = rs.renderCSVViaHTTP
 		rs.sanitizeSVGAction = rs.sanitizeViaHTTP
 
-		refreshTicker := time.NewTicker(remoteVersionRefreshInterval)
+		healthCheckInterval := rs.Cfg.RendererHealthCheckInterval
+		if healthCheckInterval <= 0 {
+			healthCheckInterval = defaultHealthCheckInterval
+		}
+		healthCheckTicker := time.NewTicker(healthCheckInterval)
 
 		for {
 			select {
-			case <-refreshTicker.C:
-				go rs.refreshRemotePluginVersion()
+			case <-healthCheckTicker.C:
+				rs.refreshRemotePluginVersion()
 			case <-ctx.Done():
-				rs.log.Debug("Grafana is shutting down - stopping image-renderer version refresh")
-				refreshTicker.Stop()
+				rs.log.Debug("Grafana is shutting down - stopping image-renderer health checks")
+				healthCheckTicker.Stop()
 				return nil
 			}
 		}
@@ -255,7 +260,7 @@ func (rs *RenderingService) Render(ctx context.Context, opts Opts, session Sessi
 }
 
 func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProvider renderKeyProvider) (*RenderResult, error) {
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
+	if !rs.acquireRenderSlot(ctx, opts.OrgID, opts.ConcurrentLimit) {
 		rs.log.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "path", opts.Path)
 		if opts.ErrorConcurrentLimitReached {
 			return nil, ErrConcurrentLimitReached
@@ -270,6 +275,7 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProv
 			FilePath: filepath.Join(rs.Cfg.HomePath, filePath),
 		}, nil
 	}
+	defer rs.releaseRenderSlot()
 
 	if !rs.IsAvailable(ctx) {
 		rs.log.Warn("Could not render image, no image renderer found/installed. " +
@@ -281,6 +287,14 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProv
 		return rs.renderUnavailableImage(), nil
 	}
 
+	if !rs.healthyRendererAvailable() {
+		rs.log.Warn("Could not render image, none of the remote image renderers is healthy", "path", opts.Path)
+		if opts.ErrorRenderUnavailable {
+			return nil, ErrRenderUnavailable
+		}
+		return rs.RenderErrorImage(opts.Theme, ErrRenderUnavailable)
+	}
+
 	rs.log.Info("Rendering", "path", opts.Path)
 	if math.IsInf(opts.DeviceScaleFactor, 0) || math.IsNaN(opts.DeviceScaleFactor) || opts.DeviceScaleFactor == 0 {
 		opts.DeviceScaleFactor = 1
@@ -292,11 +306,6 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProv
 
 	defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
 
-	defer func() {
-		metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, -1)))
-	}()
-
-	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
 	return rs.renderAction(ctx, renderKey, opts)
 }
 
@@ -334,11 +343,12 @@ func (rs *RenderingService) SanitizeSVG(ctx context.Context, req *SanitizeSVGReq
 }
 
 func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderKeyProvider renderKeyProvider) (*RenderCSVResult, error) {
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
+	if !rs.acquireRenderSlot(ctx, opts.OrgID, opts.ConcurrentLimit) {
 		return nil, ErrConcurrentLimitReached
 	}
+	defer rs.releaseRenderSlot()
 
-	if !rs.IsAvailable(ctx) {
+	if !rs.IsAvailable(ctx) || !rs.healthyRendererAvailable() {
 		return nil, ErrRenderUnavailable
 	}
 
@@ -350,14 +360,15 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderK
 
 	defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
 
-	defer func() {
-		metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, -1)))
-	}()
-
-	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
 	return rs.renderCSVAction(ctx, renderKey, opts)
 }
 
+// healthyRendererAvailable returns false if remote renderers are configured but
+// none of them passed its last health check.
+func (rs *RenderingService) healthyRendererAvailable() bool {
+	return rs.pool == nil || rs.pool.available()
+}
+
 func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
 	rand, err := util.GetRandomString(20)
 	if err != nil {
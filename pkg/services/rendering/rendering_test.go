@@ -196,6 +196,7 @@ func TestRenderingServiceGetRemotePluginVersion(t *testing.T) {
 		defer server.Close()
 
 		rs.Cfg.RendererUrl = server.URL + "/render"
+		rs.pool = newRendererPool(rs.Cfg.RendererUrl)
 		version, err := rs.getRemotePluginVersion()
 
 		require.NoError(t, err)
@@ -209,6 +210,7 @@ func TestRenderingServiceGetRemotePluginVersion(t *testing.T) {
 		defer server.Close()
 
 		rs.Cfg.RendererUrl = server.URL + "/render"
+		rs.pool = newRendererPool(rs.Cfg.RendererUrl)
 		version, err := rs.getRemotePluginVersion()
 
 		require.NoError(t, err)
@@ -234,6 +236,7 @@ func TestRenderingServiceGetRemotePluginVersion(t *testing.T) {
 		defer server.Close()
 
 		rs.Cfg.RendererUrl = server.URL + "/render"
+		rs.pool = newRendererPool(rs.Cfg.RendererUrl)
 		remoteVersionFetchInterval = time.Millisecond
 		remoteVersionFetchRetries = 5
 		go func() {
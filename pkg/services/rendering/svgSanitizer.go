@@ -67,7 +67,12 @@ func createMultipartRequestBody(values []formFile) (bytes.Buffer, string, error)
 }
 
 func (rs *RenderingService) sanitizeViaHTTP(ctx context.Context, req *SanitizeSVGRequest) (*SanitizeSVGResponse, error) {
-	sanitizerUrl, err := url.Parse(rs.sanitizeURL)
+	renderer := rs.pool.pick(nil)
+	if renderer == nil {
+		return nil, ErrRenderUnavailable
+	}
+
+	sanitizerUrl, err := url.Parse(renderer.sanitizeURL)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,308 @@
+package resourcelabel
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxLabelsPerResource = 64
+
+var (
+	ErrInvalidKind     = errors.New("invalid resource kind")
+	ErrInvalidLabel    = errors.New("invalid label")
+	ErrInvalidSelector = errors.New("invalid label selector")
+
+	// keys and values follow the syntax of Kubernetes label names and values
+	keyPattern   = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	valuePattern = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+	setPattern   = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+// Kind is the kind of resource labels are attached to.
+type Kind string
+
+const (
+	KindDashboard  Kind = "dashboard"
+	KindFolder     Kind = "folder"
+	KindDataSource Kind = "datasource"
+	KindAlertRule  Kind = "alert-rule"
+)
+
+func (k Kind) IsValid() bool {
+	switch k {
+	case KindDashboard, KindFolder, KindDataSource, KindAlertRule:
+		return true
+	}
+	return false
+}
+
+// Label is a key/value label of a resource. Resources are identified by UID, which is only
+// unique within an organization.
+type Label struct {
+	ID          int64     `xorm:"pk autoincr 'id'"`
+	OrgID       int64     `xorm:"org_id"`
+	Kind        Kind      `xorm:"resource_kind"`
+	ResourceUID string    `xorm:"resource_uid"`
+	Key         string    `xorm:"label_key"`
+	Value       string    `xorm:"label_value"`
+	Created     time.Time `xorm:"created"`
+}
+
+func (l Label) TableName() string {
+	return "resource_label"
+}
+
+// Labels are the labels of a resource by key.
+type Labels map[string]string
+
+func (l Labels) Validate() error {
+	if len(l) > maxLabelsPerResource {
+		return fmt.Errorf("%w: resources can have up to %d labels", ErrInvalidLabel, maxLabelsPerResource)
+	}
+	for key, value := range l {
+		if err := validateKey(key); err != nil {
+			return err
+		}
+		if err := validateValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("%w: invalid key %q", ErrInvalidLabel, key)
+	}
+	return nil
+}
+
+func validateValue(value string) error {
+	if !valuePattern.MatchString(value) {
+		return fmt.Errorf("%w: invalid value %q", ErrInvalidLabel, value)
+	}
+	return nil
+}
+
+// Resource is a labeled resource.
+type Resource struct {
+	Kind   Kind   `json:"kind"`
+	UID    string `json:"uid"`
+	Labels Labels `json:"labels"`
+}
+
+type GetLabelsQuery struct {
+	OrgID int64
+	Kind  Kind
+	UID   string
+}
+
+// SetLabelsCommand replaces the labels of a resource.
+type SetLabelsCommand struct {
+	OrgID  int64
+	Kind   Kind
+	UID    string
+	Labels Labels
+}
+
+func (cmd *SetLabelsCommand) Validate() error {
+	if !cmd.Kind.IsValid() {
+		return ErrInvalidKind
+	}
+	if cmd.OrgID == 0 || cmd.UID == "" {
+		return fmt.Errorf("%w: organization and resource uid are required", ErrInvalidLabel)
+	}
+	return cmd.Labels.Validate()
+}
+
+// FindResourcesQuery finds the labeled resources of a kind matching a selector. Resources without
+// labels are not found, even if they match the selector.
+type FindResourcesQuery struct {
+	OrgID    int64
+	Kind     Kind
+	Selector Selector
+}
+
+type DeleteResourceCommand struct {
+	OrgID int64
+	Kind  Kind
+	UID   string
+}
+
+// Operator is the operator of a selector requirement.
+type Operator string
+
+const (
+	OperatorEquals       Operator = "="
+	OperatorNotEquals    Operator = "!="
+	OperatorIn           Operator = "in"
+	OperatorNotIn        Operator = "notin"
+	OperatorExists       Operator = "exists"
+	OperatorDoesNotExist Operator = "!"
+)
+
+// Requirement is a single condition of a selector, e.g. `team=checkout` or `env in (dev,test)`.
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+// Selector selects resources by their labels like Kubernetes label selectors. A resource is
+// selected if it matches all requirements. Resources without a label match the `!=`, `notin`
+// and `!` requirements of the label's key.
+type Selector []Requirement
+
+// ParseSelector parses a comma separated list of requirements. Supported are `key=value`,
+// `key==value`, `key!=value`, `key in (v1,v2)`, `key notin (v1,v2)`, `key` and `!key`.
+func ParseSelector(selector string) (Selector, error) {
+	result := make(Selector, 0)
+	for _, term := range splitTerms(selector) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		r, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// splitTerms splits a selector at the commas outside of parentheses.
+func splitTerms(selector string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, selector[start:])
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	var r Requirement
+	switch {
+	case strings.HasPrefix(term, "!") && !strings.Contains(term, "="):
+		r = Requirement{Key: strings.TrimSpace(term[1:]), Operator: OperatorDoesNotExist}
+	case setPattern.MatchString(term):
+		m := setPattern.FindStringSubmatch(term)
+		r = Requirement{Key: m[1], Operator: Operator(m[2])}
+		for _, v := range strings.Split(m[3], ",") {
+			r.Values = append(r.Values, strings.TrimSpace(v))
+		}
+	case strings.Contains(term, "!="):
+		parts := strings.SplitN(term, "!=", 2)
+		r = Requirement{Key: strings.TrimSpace(parts[0]), Operator: OperatorNotEquals, Values: []string{strings.TrimSpace(parts[1])}}
+	case strings.Contains(term, "="):
+		parts := strings.SplitN(strings.Replace(term, "==", "=", 1), "=", 2)
+		r = Requirement{Key: strings.TrimSpace(parts[0]), Operator: OperatorEquals, Values: []string{strings.TrimSpace(parts[1])}}
+	default:
+		r = Requirement{Key: term, Operator: OperatorExists}
+	}
+
+	if !keyPattern.MatchString(r.Key) {
+		return r, fmt.Errorf("%w: invalid key in %q", ErrInvalidSelector, term)
+	}
+	for _, v := range r.Values {
+		if !valuePattern.MatchString(v) {
+			return r, fmt.Errorf("%w: invalid value in %q", ErrInvalidSelector, term)
+		}
+	}
+	return r, nil
+}
+
+// Matches returns whether labels match all requirements of the selector.
+func (s Selector) Matches(labels Labels) bool {
+	for _, r := range s {
+		value, exists := labels[r.Key]
+		switch r.Operator {
+		case OperatorExists:
+			if !exists {
+				return false
+			}
+		case OperatorDoesNotExist:
+			if exists {
+				return false
+			}
+		case OperatorEquals, OperatorIn:
+			if !exists || !contains(r.Values, value) {
+				return false
+			}
+		case OperatorNotEquals, OperatorNotIn:
+			if exists && contains(r.Values, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Where returns the SQL condition selecting the resources of the given kinds whose organization
+// and UID are in the given columns.
+func (s Selector) Where(orgIDColumn, uidColumn string, kinds ...Kind) (string, []interface{}) {
+	conditions := make([]string, 0, len(s))
+	params := make([]interface{}, 0)
+	for _, r := range s {
+		sql := `EXISTS (SELECT 1 FROM resource_label rl WHERE rl.org_id = ` + orgIDColumn + ` AND rl.resource_uid = ` + uidColumn +
+			` AND rl.resource_kind IN (?` + strings.Repeat(",?", len(kinds)-1) + `) AND rl.label_key = ?`
+		for _, k := range kinds {
+			params = append(params, string(k))
+		}
+		params = append(params, r.Key)
+		if len(r.Values) > 0 {
+			sql += ` AND rl.label_value IN (?` + strings.Repeat(",?", len(r.Values)-1) + `)`
+			for _, v := range r.Values {
+				params = append(params, v)
+			}
+		}
+		sql += `)`
+
+		switch r.Operator {
+		case OperatorNotEquals, OperatorNotIn, OperatorDoesNotExist:
+			sql = "NOT " + sql
+		}
+		conditions = append(conditions, sql)
+	}
+	return strings.Join(conditions, " AND "), params
+}
+
+func (s Selector) String() string {
+	terms := make([]string, 0, len(s))
+	for _, r := range s {
+		switch r.Operator {
+		case OperatorExists:
+			terms = append(terms, r.Key)
+		case OperatorDoesNotExist:
+			terms = append(terms, "!"+r.Key)
+		case OperatorIn, OperatorNotIn:
+			terms = append(terms, fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ",")))
+		default:
+			terms = append(terms, r.Key+string(r.Operator)+strings.Join(r.Values, ""))
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package resourcelabel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	selector, err := ParseSelector("team=checkout, env in (dev, test),tier!=db,owner,!deprecated,region notin (eu),app==shop")
+	require.NoError(t, err)
+	assert.Equal(t, Selector{
+		{Key: "team", Operator: OperatorEquals, Values: []string{"checkout"}},
+		{Key: "env", Operator: OperatorIn, Values: []string{"dev", "test"}},
+		{Key: "tier", Operator: OperatorNotEquals, Values: []string{"db"}},
+		{Key: "owner", Operator: OperatorExists},
+		{Key: "deprecated", Operator: OperatorDoesNotExist},
+		{Key: "region", Operator: OperatorNotIn, Values: []string{"eu"}},
+		{Key: "app", Operator: OperatorEquals, Values: []string{"shop"}},
+	}, selector)
+	assert.Equal(t, "team=checkout,env in (dev,test),tier!=db,owner,!deprecated,region notin (eu),app=shop", selector.String())
+
+	selector, err = ParseSelector("")
+	require.NoError(t, err)
+	assert.Empty(t, selector)
+
+	for _, invalid := range []string{"team=check out", "=checkout", "env in (dev,te$t)", "-team"} {
+		_, err := ParseSelector(invalid)
+		require.ErrorIs(t, err, ErrInvalidSelector, invalid)
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	labels := Labels{"team": "checkout", "env": "prod"}
+
+	for selector, matches := range map[string]bool{
+		"":                        true,
+		"team=checkout":           true,
+		"team=checkout,env=dev":   false,
+		"env in (dev,prod)":       true,
+		"env notin (dev,prod)":    false,
+		"tier!=db":                true,
+		"team!=checkout":          false,
+		"team":                    true,
+		"tier":                    false,
+		"!tier":                   true,
+		"!team":                   false,
+		"region notin (eu),team":  true,
+		"team in (search,alerts)": false,
+	} {
+		s, err := ParseSelector(selector)
+		require.NoError(t, err)
+		assert.Equal(t, matches, s.Matches(labels), selector)
+	}
+}
+
+func TestLabelsValidate(t *testing.T) {
+	require.NoError(t, Labels{"team": "checkout", "grafana.com/env": "", "a_b.c-d": "1.0"}.Validate())
+	require.ErrorIs(t, Labels{"team": "check out"}.Validate(), ErrInvalidLabel)
+	require.ErrorIs(t, Labels{"-team": "checkout"}.Validate(), ErrInvalidLabel)
+	require.ErrorIs(t, Labels{"Grafana.com/team": "checkout"}.Validate(), ErrInvalidLabel)
+}
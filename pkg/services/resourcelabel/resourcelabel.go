@@ -0,0 +1,12 @@
+package resourcelabel
+
+import (
+	"context"
+)
+
+type Service interface {
+	Get(context.Context, *GetLabelsQuery) (Labels, error)
+	Set(context.Context, *SetLabelsCommand) error
+	Find(context.Context, *FindResourcesQuery) ([]*Resource, error)
+	DeleteResource(context.Context, *DeleteResourceCommand) error
+}
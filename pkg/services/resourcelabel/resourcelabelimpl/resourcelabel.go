@@ -0,0 +1,45 @@
+package resourcelabelimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
+)
+
+type Service struct {
+	store store
+}
+
+func ProvideService(db db.DB) resourcelabel.Service {
+	return &Service{
+		store: &sqlStore{
+			db: db,
+		},
+	}
+}
+
+func (s *Service) Get(ctx context.Context, query *resourcelabel.GetLabelsQuery) (resourcelabel.Labels, error) {
+	if !query.Kind.IsValid() {
+		return nil, resourcelabel.ErrInvalidKind
+	}
+	return s.store.Get(ctx, query)
+}
+
+func (s *Service) Set(ctx context.Context, cmd *resourcelabel.SetLabelsCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	return s.store.Set(ctx, cmd)
+}
+
+func (s *Service) Find(ctx context.Context, query *resourcelabel.FindResourcesQuery) ([]*resourcelabel.Resource, error) {
+	if !query.Kind.IsValid() {
+		return nil, resourcelabel.ErrInvalidKind
+	}
+	return s.store.Find(ctx, query)
+}
+
+func (s *Service) DeleteResource(ctx context.Context, cmd *resourcelabel.DeleteResourceCommand) error {
+	return s.store.DeleteResource(ctx, cmd)
+}
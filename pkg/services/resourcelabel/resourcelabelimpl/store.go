@@ -0,0 +1,14 @@
+package resourcelabelimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
+)
+
+type store interface {
+	Get(context.Context, *resourcelabel.GetLabelsQuery) (resourcelabel.Labels, error)
+	Set(context.Context, *resourcelabel.SetLabelsCommand) error
+	Find(context.Context, *resourcelabel.FindResourcesQuery) ([]*resourcelabel.Resource, error)
+	DeleteResource(context.Context, *resourcelabel.DeleteResourceCommand) error
+}
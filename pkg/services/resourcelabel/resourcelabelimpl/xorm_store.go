@@ -0,0 +1,79 @@
+package resourcelabelimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Get(ctx context.Context, query *resourcelabel.GetLabelsQuery) (resourcelabel.Labels, error) {
+	result := resourcelabel.Labels{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var labels []*resourcelabel.Label
+		if err := sess.Where("org_id = ? AND resource_kind = ? AND resource_uid = ?", query.OrgID, query.Kind, query.UID).Find(&labels); err != nil {
+			return err
+		}
+		for _, l := range labels {
+			result[l.Key] = l.Value
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *sqlStore) Set(ctx context.Context, cmd *resourcelabel.SetLabelsCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM resource_label WHERE org_id = ? AND resource_kind = ? AND resource_uid = ?", cmd.OrgID, cmd.Kind, cmd.UID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for key, value := range cmd.Labels {
+			label := &resourcelabel.Label{OrgID: cmd.OrgID, Kind: cmd.Kind, ResourceUID: cmd.UID, Key: key, Value: value, Created: now}
+			if _, err := sess.Insert(label); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) Find(ctx context.Context, query *resourcelabel.FindResourcesQuery) ([]*resourcelabel.Resource, error) {
+	result := make([]*resourcelabel.Resource, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := "SELECT * FROM resource_label l WHERE l.org_id = ? AND l.resource_kind = ?"
+		params := []interface{}{query.OrgID, query.Kind}
+		if len(query.Selector) > 0 {
+			where, selectorParams := query.Selector.Where("l.org_id", "l.resource_uid", query.Kind)
+			sql += " AND " + where
+			params = append(params, selectorParams...)
+		}
+		sql += " ORDER BY l.resource_uid, l.label_key"
+
+		var labels []*resourcelabel.Label
+		if err := sess.SQL(sql, params...).Find(&labels); err != nil {
+			return err
+		}
+		for _, l := range labels {
+			if len(result) == 0 || result[len(result)-1].UID != l.ResourceUID {
+				result = append(result, &resourcelabel.Resource{Kind: l.Kind, UID: l.ResourceUID, Labels: resourcelabel.Labels{}})
+			}
+			result[len(result)-1].Labels[l.Key] = l.Value
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *sqlStore) DeleteResource(ctx context.Context, cmd *resourcelabel.DeleteResourceCommand) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM resource_label WHERE org_id = ? AND resource_kind = ? AND resource_uid = ?", cmd.OrgID, cmd.Kind, cmd.UID)
+		return err
+	})
+}
@@ -0,0 +1,75 @@
+package resourcelabelimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
+)
+
+func TestIntegrationResourceLabelsDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := db.InitTestDB(t)
+	s := &sqlStore{db: ss}
+	ctx := context.Background()
+
+	set := func(orgID int64, kind resourcelabel.Kind, uid string, labels resourcelabel.Labels) {
+		t.Helper()
+		require.NoError(t, s.Set(ctx, &resourcelabel.SetLabelsCommand{OrgID: orgID, Kind: kind, UID: uid, Labels: labels}))
+	}
+	find := func(kind resourcelabel.Kind, selector string) []string {
+		t.Helper()
+		sel, err := resourcelabel.ParseSelector(selector)
+		require.NoError(t, err)
+		resources, err := s.Find(ctx, &resourcelabel.FindResourcesQuery{OrgID: 1, Kind: kind, Selector: sel})
+		require.NoError(t, err)
+		uids := make([]string, 0, len(resources))
+		for _, r := range resources {
+			assert.True(t, sel.Matches(r.Labels))
+			uids = append(uids, r.UID)
+		}
+		return uids
+	}
+
+	set(1, resourcelabel.KindDashboard, "a", resourcelabel.Labels{"team": "checkout", "env": "prod"})
+	set(1, resourcelabel.KindDashboard, "b", resourcelabel.Labels{"team": "checkout", "env": "dev"})
+	set(1, resourcelabel.KindDashboard, "c", resourcelabel.Labels{"team": "search"})
+	set(1, resourcelabel.KindFolder, "a", resourcelabel.Labels{"team": "checkout"})
+	set(2, resourcelabel.KindDashboard, "d", resourcelabel.Labels{"team": "checkout"})
+
+	t.Run("gets and replaces the labels of a resource", func(t *testing.T) {
+		set(1, resourcelabel.KindDashboard, "c", resourcelabel.Labels{"team": "search", "tier": "db"})
+		set(1, resourcelabel.KindDashboard, "c", resourcelabel.Labels{"team": "search", "env": "prod"})
+
+		labels, err := s.Get(ctx, &resourcelabel.GetLabelsQuery{OrgID: 1, Kind: resourcelabel.KindDashboard, UID: "c"})
+		require.NoError(t, err)
+		assert.Equal(t, resourcelabel.Labels{"team": "search", "env": "prod"}, labels)
+
+		labels, err = s.Get(ctx, &resourcelabel.GetLabelsQuery{OrgID: 2, Kind: resourcelabel.KindDashboard, UID: "c"})
+		require.NoError(t, err)
+		assert.Empty(t, labels)
+	})
+
+	t.Run("finds the resources matching a selector", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "c"}, find(resourcelabel.KindDashboard, ""))
+		assert.Equal(t, []string{"a", "b"}, find(resourcelabel.KindDashboard, "team=checkout"))
+		assert.Equal(t, []string{"a"}, find(resourcelabel.KindDashboard, "team=checkout,env=prod"))
+		assert.Equal(t, []string{"a", "c"}, find(resourcelabel.KindDashboard, "env in (prod,test)"))
+		assert.Equal(t, []string{"a", "c"}, find(resourcelabel.KindDashboard, "env notin (dev),!tier"))
+		assert.Equal(t, []string{"c"}, find(resourcelabel.KindDashboard, "team!=checkout"))
+		assert.Equal(t, []string{"a"}, find(resourcelabel.KindFolder, "team"))
+		assert.Empty(t, find(resourcelabel.KindDataSource, "team"))
+	})
+
+	t.Run("deletes the labels of a resource", func(t *testing.T) {
+		require.NoError(t, s.DeleteResource(ctx, &resourcelabel.DeleteResourceCommand{OrgID: 1, Kind: resourcelabel.KindDashboard, UID: "a"}))
+		assert.Equal(t, []string{"b"}, find(resourcelabel.KindDashboard, "team=checkout"))
+		assert.Equal(t, []string{"a"}, find(resourcelabel.KindFolder, "team=checkout"))
+	})
+}
@@ -0,0 +1,254 @@
+package scheduledreports
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *ReportService) registerAPIEndpoints() {
+	quota := middleware.Quota(s.quotaService)
+
+	s.routeRegister.Group("/api/scheduled-reports", func(reports routing.RouteRegister) {
+		reports.Get("/", middleware.ReqOrgAdmin, routing.Wrap(s.getReportsHandler))
+		reports.Post("/", middleware.ReqOrgAdmin, quota(string(QuotaTargetSrv)), routing.Wrap(s.createReportHandler))
+		reports.Get("/:id", middleware.ReqOrgAdmin, routing.Wrap(s.getReportHandler))
+		reports.Put("/:id", middleware.ReqOrgAdmin, routing.Wrap(s.updateReportHandler))
+		reports.Delete("/:id", middleware.ReqOrgAdmin, routing.Wrap(s.deleteReportHandler))
+		reports.Get("/:id/runs", middleware.ReqOrgAdmin, routing.Wrap(s.getRunsHandler))
+		reports.Post("/:id/send", middleware.ReqOrgAdmin, routing.Wrap(s.sendReportHandler))
+	})
+}
+
+// swagger:route GET /scheduled-reports scheduled_reports getScheduledReports
+//
+// Get all reports of the current organization.
+//
+// Responses:
+// 200: getScheduledReportsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *ReportService) getReportsHandler(c *models.ReqContext) response.Response {
+	reports, err := s.GetReports(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, reports)
+}
+
+// swagger:route GET /scheduled-reports/{report_id} scheduled_reports getScheduledReport
+//
+// Get a report by ID.
+//
+// Responses:
+// 200: getScheduledReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) getReportHandler(c *models.ReqContext) response.Response {
+	id, err := reportID(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	report, err := s.GetReport(c.Req.Context(), c.OrgID, id)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route POST /scheduled-reports scheduled_reports createScheduledReport
+//
+// Create a report.
+//
+// The report is owned by the signed in user. The dashboard is rendered or queried with the
+// permissions of the owner every time the report is sent.
+//
+// Responses:
+// 200: scheduledReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *ReportService) createReportHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	report, err := s.CreateReport(c.Req.Context(), c.SignedInUser, &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route PUT /scheduled-reports/{report_id} scheduled_reports updateScheduledReport
+//
+// Update a report.
+//
+// Responses:
+// 200: scheduledReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) updateReportHandler(c *models.ReqContext) response.Response {
+	id, err := reportID(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	cmd := CreateOrUpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	report, err := s.UpdateReport(c.Req.Context(), c.SignedInUser, id, &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route DELETE /scheduled-reports/{report_id} scheduled_reports deleteScheduledReport
+//
+// Delete a report and its run history.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) deleteReportHandler(c *models.ReqContext) response.Response {
+	id, err := reportID(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if err := s.DeleteReport(c.Req.Context(), c.OrgID, id); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("Report deleted")
+}
+
+// swagger:route GET /scheduled-reports/{report_id}/runs scheduled_reports getScheduledReportRuns
+//
+// Get the run history of a report.
+//
+// Returns the runs of the report, newest first. Runs are kept for 90 days.
+//
+// Responses:
+// 200: getScheduledReportRunsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) getRunsHandler(c *models.ReqContext) response.Response {
+	id, err := reportID(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	runs, err := s.GetRuns(c.Req.Context(), c.OrgID, id, c.QueryInt("limit"))
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, runs)
+}
+
+// swagger:route POST /scheduled-reports/{report_id}/send scheduled_reports sendScheduledReport
+//
+// Send a report now.
+//
+// The report is sent in the background, independent of its schedule. The returned run can be
+// followed in the run history.
+//
+// Responses:
+// 202: scheduledReportRunResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) sendReportHandler(c *models.ReqContext) response.Response {
+	id, err := reportID(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	run, err := s.SendReport(c.Req.Context(), c.OrgID, id)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusAccepted, run)
+}
+
+func reportID(c *models.ReqContext) (int64, error) {
+	return strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		return response.Error(http.StatusNotFound, "Report not found", err)
+	case errors.Is(err, ErrInvalidReport):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process report", err)
+}
+
+// swagger:parameters getScheduledReport updateScheduledReport deleteScheduledReport getScheduledReportRuns sendScheduledReport
+type ReportIDParams struct {
+	// in:path
+	// required:true
+	ReportID int64 `json:"report_id"`
+}
+
+// swagger:parameters createScheduledReport updateScheduledReport
+type CreateOrUpdateReportParams struct {
+	// in:body
+	// required:true
+	Body CreateOrUpdateReportCommand `json:"body"`
+}
+
+// swagger:parameters getScheduledReportRuns
+type GetReportRunsParams struct {
+	// Maximum number of runs returned, defaults to 50.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+}
+
+// swagger:response getScheduledReportsResponse
+type GetReportsResponse struct {
+	// in:body
+	Body []*ReportDTO `json:"body"`
+}
+
+// swagger:response getScheduledReportResponse
+type GetReportResponse struct {
+	// in:body
+	Body *ReportDTO `json:"body"`
+}
+
+// swagger:response scheduledReportResponse
+type ReportResponse struct {
+	// in:body
+	Body *Report `json:"body"`
+}
+
+// swagger:response getScheduledReportRunsResponse
+type GetReportRunsResponse struct {
+	// in:body
+	Body []*Run `json:"body"`
+}
+
+// swagger:response scheduledReportRunResponse
+type ReportRunResponse struct {
+	// in:body
+	Body *Run `json:"body"`
+}
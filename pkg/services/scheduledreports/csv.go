@@ -0,0 +1,35 @@
+package scheduledreports
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/panelexport"
+)
+
+const (
+	// the size of a row of the dashboard grid in pixels, including the margin between panels
+	gridRowHeight = 38
+	// the space above the grid taken by the dashboard navigation bar
+	dashboardHeaderHeight = 80
+)
+
+// dashboardHeight returns the height in pixels needed to render all rows of a dashboard.
+func dashboardHeight(dashboard *simplejson.Json) int {
+	rows := 0
+	for _, p := range dashboard.Get("panels").MustArray() {
+		gridPos := simplejson.NewFromAny(p).Get("gridPos")
+		if bottom := gridPos.Get("y").MustInt() + gridPos.Get("h").MustInt(); bottom > rows {
+			rows = bottom
+		}
+	}
+	return dashboardHeaderHeight + rows*gridRowHeight
+}
+
+// csvFileName returns a file name for the data of a panel which is unique within the report.
+func csvFileName(p panelexport.Panel, frameIdx, frames int) string {
+	if frames > 1 {
+		return panelexport.FileName(p, fmt.Sprintf("-%d.csv", frameIdx+1))
+	}
+	return panelexport.FileName(p, ".csv")
+}
@@ -0,0 +1,261 @@
+package scheduledreports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/schedule"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/panelexport"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+const (
+	reportEmailTemplate = "scheduled_report"
+	timeRangeFormat     = "2006-01-02 15:04:05 MST"
+)
+
+// delivery is the dashboard of a report prepared for rendering or exporting.
+type delivery struct {
+	report    *Report
+	user      *user.SignedInUser
+	dashboard *models.Dashboard
+	from      string
+	to        string
+	timeRange legacydata.DataTimeRange
+	location  *time.Location
+}
+
+// deliver renders or exports the dashboard of a report and emails it to the recipients.
+// It returns a summary of the run.
+func (s *ReportService) deliver(ctx context.Context, report *Report) (string, error) {
+	d, err := s.prepare(ctx, report)
+	if err != nil {
+		return "", err
+	}
+
+	var attachments []*models.SendEmailAttachFile
+	var skipped []string
+	switch report.Format {
+	case FormatCSV:
+		attachments, skipped = s.exportPanels(ctx, d)
+		if len(attachments) == 0 {
+			return "", fmt.Errorf("no panel data could be exported")
+		}
+	default:
+		attachment, err := s.renderDashboard(ctx, d)
+		if err != nil {
+			return "", err
+		}
+		attachments = []*models.SendEmailAttachFile{attachment}
+	}
+
+	recipients, err := parseRecipients(report.Recipients)
+	if err != nil {
+		return "", err
+	}
+	var replyTo []string
+	if report.ReplyTo != "" {
+		replyTo = []string{report.ReplyTo}
+	}
+
+	err = s.emailSender.SendEmailCommandHandlerSync(ctx, &models.SendEmailCommandSync{
+		SendEmailCommand: models.SendEmailCommand{
+			To:       recipients,
+			Template: reportEmailTemplate,
+			ReplyTo:  replyTo,
+			OrgID:    report.OrgID,
+			Data: map[string]interface{}{
+				"Name":           report.Name,
+				"Message":        report.Message,
+				"DashboardTitle": d.dashboard.Title,
+				"DashboardUrl":   s.dashboardURL(d),
+				"TimeRange":      d.formatTimeRange(),
+			},
+			AttachedFiles: attachments,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to send email: %w", err)
+	}
+
+	message := fmt.Sprintf("sent to %d recipients", len(recipients))
+	if len(skipped) > 0 {
+		message += fmt.Sprintf(", failed to export panels: %s", strings.Join(skipped, ", "))
+	}
+	return message, nil
+}
+
+// prepare loads the owner and the dashboard of a report. The owner must still be a member of
+// the organization and be allowed to view the dashboard.
+func (s *ReportService) prepare(ctx context.Context, report *Report) (*delivery, error) {
+	signedInUser, err := s.userService.GetSignedInUserWithCacheCtx(ctx, &user.GetSignedInUserQuery{
+		UserID: report.UserID,
+		OrgID:  report.OrgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report owner: %w", err)
+	}
+	if signedInUser.OrgID != report.OrgID || signedInUser.IsDisabled {
+		return nil, fmt.Errorf("report owner is no longer an active member of the organization")
+	}
+	if signedInUser.Permissions == nil {
+		signedInUser.Permissions = make(map[int64]map[string][]string)
+	}
+	permissions, err := s.accessControlService.GetUserPermissions(ctx, signedInUser, accesscontrol.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions of report owner: %w", err)
+	}
+	signedInUser.Permissions[report.OrgID] = accesscontrol.GroupScopesByAction(permissions)
+
+	query := models.GetDashboardQuery{Uid: report.DashboardUID, OrgId: report.OrgID}
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		return nil, fmt.Errorf("failed to get dashboard: %w", err)
+	}
+	canView, err := guardian.New(ctx, query.Result.Id, report.OrgID, signedInUser).CanView()
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, fmt.Errorf("report owner cannot view the dashboard")
+	}
+
+	location, err := schedule.LoadLocation(report.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := report.TimeFrom, report.TimeTo
+	if from == "" {
+		from = query.Result.Data.Get("time").Get("from").MustString("now-6h")
+		to = query.Result.Data.Get("time").Get("to").MustString("now")
+	}
+
+	return &delivery{
+		report:    report,
+		user:      signedInUser,
+		dashboard: query.Result,
+		from:      from,
+		to:        to,
+		timeRange: legacydata.NewDataTimeRange(from, to),
+		location:  location,
+	}, nil
+}
+
+func (d *delivery) formatTimeRange() string {
+	return fmt.Sprintf("%s to %s",
+		d.timeRange.GetFromAsTimeUTC().In(d.location).Format(timeRangeFormat),
+		d.timeRange.GetToAsTimeUTC().In(d.location).Format(timeRangeFormat))
+}
+
+func (s *ReportService) dashboardURL(d *delivery) string {
+	return fmt.Sprintf("%sd/%s/%s?orgId=%d&from=%d&to=%d", s.cfg.AppURL, d.dashboard.Uid, d.dashboard.Slug,
+		d.report.OrgID, d.timeRange.GetFromAsMsEpoch(), d.timeRange.GetToAsMsEpoch())
+}
+
+// renderDashboard renders the dashboard of a report with the image renderer and lays it out as a PDF document.
+func (s *ReportService) renderDashboard(ctx context.Context, d *delivery) (*models.SendEmailAttachFile, error) {
+	path := fmt.Sprintf("d/%s/%s?orgId=%d&from=%s&to=%s&kiosk", d.dashboard.Uid, d.dashboard.Slug, d.report.OrgID,
+		url.QueryEscape(d.from), url.QueryEscape(d.to))
+	result, err := s.renderService.Render(ctx, rendering.Opts{
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout: s.settings.renderingTimeout,
+		},
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   d.report.OrgID,
+			UserID:  d.report.UserID,
+			OrgRole: d.user.OrgRole,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		Width:           s.settings.imageWidth,
+		Height:          dashboardHeight(d.dashboard.Data),
+		Path:            path,
+		Timezone:        d.report.Timezone,
+		ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+		Theme:           models.ThemeLight,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render dashboard: %w", err)
+	}
+
+	f, err := os.Open(result.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			s.log.Warn("Failed to close rendered image", "path", result.FilePath, "error", err)
+		}
+	}()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode rendered dashboard: %w", err)
+	}
+
+	contents, err := renderPDF(pdfLayout{
+		title:       d.dashboard.Title,
+		subtitle:    d.formatTimeRange(),
+		orientation: d.report.Orientation,
+	}, img)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SendEmailAttachFile{
+		Name:    d.report.Name + ".pdf",
+		Content: contents,
+	}, nil
+}
+
+// exportPanels queries the panels of the dashboard of a report and returns a CSV file for every
+// data frame. Panels which fail to query are skipped and returned by their title.
+func (s *ReportService) exportPanels(ctx context.Context, d *delivery) ([]*models.SendEmailAttachFile, []string) {
+	include := make(map[int64]bool, len(d.report.PanelIDs))
+	for _, id := range d.report.PanelIDs {
+		include[id] = true
+	}
+
+	attachments := make([]*models.SendEmailAttachFile, 0)
+	skipped := make([]string, 0)
+	for _, p := range panelexport.DashboardPanels(d.dashboard.Data) {
+		if len(include) > 0 && !include[p.ID] {
+			continue
+		}
+
+		frames, err := s.panelExportService.QueryPanel(ctx, d.user, p, panelexport.QueryOptions{
+			From:                d.from,
+			To:                  d.to,
+			SkipTransformations: true,
+		})
+		if err != nil {
+			s.log.Warn("Failed to export panel", "report", d.report.ID, "orgId", d.report.OrgID, "panel", p.ID, "error", err)
+			skipped = append(skipped, p.Title)
+			continue
+		}
+		for i, frame := range frames {
+			var contents bytes.Buffer
+			if err := panelexport.WriteCSV(&contents, frame, d.location); err != nil {
+				s.log.Warn("Failed to export panel", "report", d.report.ID, "orgId", d.report.OrgID, "panel", p.ID, "error", err)
+				skipped = append(skipped, p.Title)
+				break
+			}
+			attachments = append(attachments, &models.SendEmailAttachFile{
+				Name:    csvFileName(p, i, len(frames)),
+				Content: contents.Bytes(),
+			})
+		}
+	}
+	return attachments, skipped
+}
@@ -0,0 +1,230 @@
+package scheduledreports
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/infra/schedule"
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+const (
+	QuotaTargetSrv quota.TargetSrv = "scheduled_report"
+	QuotaTarget    quota.Target    = "scheduled_report"
+
+	maxNameLength    = 190
+	maxMessageLength = 255
+	maxRecipients    = 50
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrInvalidReport  = errors.New("invalid report")
+)
+
+// Format is the format of the files attached to the report emails.
+type Format string
+
+const (
+	// FormatPDF attaches the dashboard rendered by the image renderer as a PDF document.
+	FormatPDF Format = "pdf"
+	// FormatCSV attaches the data of every panel of the dashboard as a CSV file.
+	FormatCSV Format = "csv"
+)
+
+// Orientation is the page orientation of PDF reports.
+type Orientation string
+
+const (
+	OrientationPortrait  Orientation = "portrait"
+	OrientationLandscape Orientation = "landscape"
+)
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Report is a dashboard sent to a list of recipients on a schedule. The dashboard is rendered
+// and queried with the permissions of the user who created the report.
+// swagger:model ScheduledReport
+type Report struct {
+	ID           int64  `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID        int64  `json:"orgId" xorm:"org_id"`
+	UserID       int64  `json:"userId" xorm:"user_id"`
+	Name         string `json:"name" xorm:"name"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	Format       Format `json:"format" xorm:"format"`
+	// PanelIDs limits the panels exported to CSV. All panels are exported if it is empty.
+	PanelIDs    []int64     `json:"panelIds" xorm:"jsonb panel_ids"`
+	Orientation Orientation `json:"orientation" xorm:"orientation"`
+	// Recipients is a comma separated list of email addresses.
+	Recipients string `json:"recipients" xorm:"recipients"`
+	ReplyTo    string `json:"replyTo" xorm:"reply_to"`
+	Message    string `json:"message" xorm:"message"`
+	// Schedule is a cron expression evaluated in the time zone of the report.
+	Schedule string `json:"schedule" xorm:"schedule"`
+	Timezone string `json:"timezone" xorm:"timezone"`
+	// TimeFrom and TimeTo override the time range of the dashboard.
+	TimeFrom string     `json:"timeFrom" xorm:"time_from"`
+	TimeTo   string     `json:"timeTo" xorm:"time_to"`
+	Enabled  bool       `json:"enabled" xorm:"enabled"`
+	NextRun  *time.Time `json:"nextRun,omitempty" xorm:"next_run"`
+	Created  time.Time  `json:"created" xorm:"created"`
+	Updated  time.Time  `json:"updated" xorm:"updated"`
+}
+
+func (r Report) TableName() string {
+	return "scheduled_report"
+}
+
+// Run is a single delivery of a report.
+// swagger:model ScheduledReportRun
+type Run struct {
+	ID       int64      `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID    int64      `json:"orgId" xorm:"org_id"`
+	ReportID int64      `json:"reportId" xorm:"report_id"`
+	Status   Status     `json:"status" xorm:"status"`
+	Message  string     `json:"message,omitempty" xorm:"message"`
+	Started  time.Time  `json:"started" xorm:"started"`
+	Finished *time.Time `json:"finished,omitempty" xorm:"finished"`
+}
+
+func (r Run) TableName() string {
+	return "scheduled_report_run"
+}
+
+// ReportDTO is a report together with its last run.
+// swagger:model ScheduledReportDTO
+type ReportDTO struct {
+	*Report
+	LastRun *Run `json:"lastRun,omitempty"`
+}
+
+// CreateOrUpdateReportCommand is the body of the requests creating and updating reports.
+// swagger:model CreateOrUpdateScheduledReportCommand
+type CreateOrUpdateReportCommand struct {
+	// required:true
+	Name string `json:"name"`
+	// required:true
+	DashboardUID string `json:"dashboardUid"`
+	// pdf or csv, defaults to pdf
+	Format      Format      `json:"format"`
+	PanelIDs    []int64     `json:"panelIds"`
+	Orientation Orientation `json:"orientation"`
+	// Comma separated list of email addresses.
+	// required:true
+	Recipients string `json:"recipients"`
+	ReplyTo    string `json:"replyTo"`
+	Message    string `json:"message"`
+	// Cron expression with five fields, e.g. `0 8 * * 1` for every Monday at 08:00.
+	// required:true
+	Schedule string `json:"schedule"`
+	// IANA time zone the schedule is evaluated in, defaults to the time zone of the preferences
+	// of the user.
+	Timezone string `json:"timezone"`
+	TimeFrom string `json:"timeFrom"`
+	TimeTo   string `json:"timeTo"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// validate checks the command and applies its defaults.
+func (cmd *CreateOrUpdateReportCommand) validate() error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if cmd.Name == "" || utf8.RuneCountInString(cmd.Name) > maxNameLength {
+		return fmt.Errorf("%w: name is required and must be at most %d characters", ErrInvalidReport, maxNameLength)
+	}
+	if cmd.DashboardUID == "" {
+		return fmt.Errorf("%w: dashboardUid is required", ErrInvalidReport)
+	}
+
+	if cmd.Format == "" {
+		cmd.Format = FormatPDF
+	}
+	if cmd.Format != FormatPDF && cmd.Format != FormatCSV {
+		return fmt.Errorf("%w: format must be %s or %s", ErrInvalidReport, FormatPDF, FormatCSV)
+	}
+	if cmd.Orientation == "" {
+		cmd.Orientation = OrientationLandscape
+	}
+	if cmd.Orientation != OrientationPortrait && cmd.Orientation != OrientationLandscape {
+		return fmt.Errorf("%w: orientation must be %s or %s", ErrInvalidReport, OrientationPortrait, OrientationLandscape)
+	}
+
+	recipients, err := parseRecipients(cmd.Recipients)
+	if err != nil {
+		return err
+	}
+	cmd.Recipients = strings.Join(recipients, ",")
+	if cmd.ReplyTo != "" {
+		if _, err := mail.ParseAddress(cmd.ReplyTo); err != nil {
+			return fmt.Errorf("%w: invalid replyTo address", ErrInvalidReport)
+		}
+	}
+	if utf8.RuneCountInString(cmd.Message) > maxMessageLength {
+		return fmt.Errorf("%w: message must be at most %d characters", ErrInvalidReport, maxMessageLength)
+	}
+
+	if cmd.Timezone == "" {
+		cmd.Timezone = "UTC"
+	}
+	next, err := nextRun(cmd.Schedule, cmd.Timezone, time.Now())
+	if err != nil {
+		return err
+	}
+	// descriptors like @every can fire more often than the scheduler checks for due reports
+	if following, _ := nextRun(cmd.Schedule, cmd.Timezone, next); following.Sub(next) < scheduleInterval {
+		return fmt.Errorf("%w: schedule must not fire more often than every %s", ErrInvalidReport, scheduleInterval)
+	}
+	if (cmd.TimeFrom == "") != (cmd.TimeTo == "") {
+		return fmt.Errorf("%w: timeFrom and timeTo must be set together", ErrInvalidReport)
+	}
+	return nil
+}
+
+// parseRecipients splits a comma separated list of email addresses.
+func parseRecipients(recipients string) ([]string, error) {
+	result := make([]string, 0)
+	for _, r := range strings.Split(recipients, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid recipient %q", ErrInvalidReport, r)
+		}
+		result = append(result, addr.Address)
+	}
+	if len(result) == 0 || len(result) > maxRecipients {
+		return nil, fmt.Errorf("%w: between 1 and %d recipients are required", ErrInvalidReport, maxRecipients)
+	}
+	return result, nil
+}
+
+// nextRun returns the first time after the given time the schedule fires in the time zone.
+func nextRun(expr, timezone string, after time.Time) (time.Time, error) {
+	sched, err := schedule.Parse(expr, timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidReport, err)
+	}
+	next := sched.Next(after)
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: schedule never fires", ErrInvalidReport)
+	}
+	return next.UTC(), nil
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxMessageLength {
+		return s
+	}
+	return string([]rune(s)[:maxMessageLength-3]) + "..."
+}
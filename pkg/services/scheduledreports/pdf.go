@@ -0,0 +1,110 @@
+package scheduledreports
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	// the PDF dimensions are in millimeters
+	pdfMargin       = 10.0
+	pdfFooterHeight = 6.0
+	pdfFont         = "Helvetica"
+)
+
+// pdfLayout describes the page of a PDF report.
+type pdfLayout struct {
+	title       string
+	subtitle    string
+	orientation Orientation
+}
+
+// renderPDF lays out a rendered dashboard on A4 pages. The image is scaled to the width
+// of the page and split across as many pages as needed to keep it readable.
+func renderPDF(layout pdfLayout, img image.Image) ([]byte, error) {
+	orientation := "L"
+	if layout.orientation == OrientationPortrait {
+		orientation = "P"
+	}
+
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AliasNbPages("")
+	pdf.SetTitle(layout.title, true)
+	pdf.SetCreator("Grafana", true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin - pdfFooterHeight)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.CellFormat(0, pdfFooterHeight, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	// the core fonts only support the cp1252 code page
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.AddPage()
+	pdf.SetFont(pdfFont, "B", 16)
+	pdf.CellFormat(0, 8, tr(layout.title), "", 1, "L", false, 0, "")
+	if layout.subtitle != "" {
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.CellFormat(0, 6, tr(layout.subtitle), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(2)
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pdfMargin
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, fmt.Errorf("rendered image is empty")
+	}
+	// millimeters per pixel of the image scaled to the width of the page
+	scale := contentWidth / float64(bounds.Dx())
+
+	for part, y := 0, bounds.Min.Y; y < bounds.Max.Y; part++ {
+		if part > 0 {
+			pdf.AddPage()
+		}
+		top := pdf.GetY()
+		rows := int((pageHeight - pdfMargin - pdfFooterHeight - top) / scale)
+		if rows < 1 {
+			rows = 1
+		}
+		bottom := y + rows
+		if bottom > bounds.Max.Y {
+			bottom = bounds.Max.Y
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, cropImage(img, image.Rect(bounds.Min.X, y, bounds.Max.X, bottom))); err != nil {
+			return nil, fmt.Errorf("failed to encode page %d: %w", part+1, err)
+		}
+		name := fmt.Sprintf("dashboard-%d", part)
+		options := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(name, options, &buf)
+		pdf.ImageOptions(name, pdfMargin, top, contentWidth, float64(bottom-y)*scale, false, options, 0, "")
+		y = bottom
+	}
+
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		return nil, fmt.Errorf("failed to create PDF: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func cropImage(img image.Image, r image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), img, r.Min, draw.Src)
+	return cropped
+}
@@ -0,0 +1,483 @@
+package scheduledreports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/schedule"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/notificationcenter"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/panelexport"
+	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	scheduleInterval = time.Minute
+	cleanupInterval  = time.Hour
+	// runRetention is how long the history of report runs is kept.
+	runRetention = 90 * 24 * time.Hour
+	// runTimeout bounds the rendering, querying and sending of a single report.
+	runTimeout = 10 * time.Minute
+
+	schedulerLockName = "scheduled-reports"
+	scheduleFeature   = "scheduled-reports"
+
+	defaultRunsLimit = 50
+	maxRunsLimit     = 1000
+)
+
+type settings struct {
+	enabled          bool
+	renderingTimeout time.Duration
+	imageWidth       int
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("scheduled_reports")
+	return settings{
+		enabled:          section.Key("enabled").MustBool(true),
+		renderingTimeout: section.Key("rendering_timeout").MustDuration(time.Minute),
+		imageWidth:       section.Key("image_width").MustInt(1500),
+	}
+}
+
+// ReportService sends dashboards by email on a schedule, either rendered as a PDF document or
+// as CSV files with the data of the panels. A server lock makes sure every scheduled report
+// is sent once across Grafana instances.
+type ReportService struct {
+	settings             settings
+	cfg                  *setting.Cfg
+	store                db.DB
+	log                  log.Logger
+	renderService        rendering.Service
+	panelExportService   *panelexport.Service
+	dashboardService     dashboards.DashboardService
+	userService          user.Service
+	accessControlService accesscontrol.Service
+	emailSender          notifications.EmailSender
+	serverLock           *serverlock.ServerLockService
+	routeRegister        routing.RouteRegister
+	quotaService         quota.Service
+	notifications        notificationcenter.Service
+	preferenceService    pref.Service
+
+	now func() time.Time
+	wg  sync.WaitGroup
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, renderService rendering.Service, panelExportService *panelexport.Service,
+	dashboardService dashboards.DashboardService, userService user.Service, accessControlService accesscontrol.Service,
+	emailSender notifications.EmailSender, serverLock *serverlock.ServerLockService, routeRegister routing.RouteRegister,
+	quotaService quota.Service, notificationCenter notificationcenter.Service, preferenceService pref.Service,
+	scheduleRegistry *schedule.Registry) (*ReportService, error) {
+	s := &ReportService{
+		settings:             readSettings(cfg),
+		cfg:                  cfg,
+		store:                sqlStore,
+		log:                  log.New("scheduled-reports"),
+		renderService:        renderService,
+		panelExportService:   panelExportService,
+		dashboardService:     dashboardService,
+		userService:          userService,
+		accessControlService: accessControlService,
+		emailSender:          emailSender,
+		serverLock:           serverLock,
+		routeRegister:        routeRegister,
+		quotaService:         quotaService,
+		notifications:        notificationCenter,
+		preferenceService:    preferenceService,
+		now:                  time.Now,
+	}
+
+	defaultLimits, err := readQuotaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     QuotaTargetSrv,
+		DefaultLimits: defaultLimits,
+		Reporter:      s.count,
+	}); err != nil {
+		return nil, err
+	}
+
+	if s.settings.enabled {
+		s.registerAPIEndpoints()
+		scheduleRegistry.Register(scheduleFeature, s)
+	}
+
+	return s, nil
+}
+
+func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	limits := &quota.Map{}
+
+	globalQuotaTag, err := quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.GlobalScope)
+	if err != nil {
+		return limits, err
+	}
+	orgQuotaTag, err := quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+
+	limits.Set(globalQuotaTag, cfg.Quota.Global.ScheduledReport)
+	limits.Set(orgQuotaTag, cfg.Quota.Org.ScheduledReport)
+	return limits, nil
+}
+
+func (s *ReportService) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+func (s *ReportService) Run(ctx context.Context) error {
+	scheduleTicker := time.NewTicker(scheduleInterval)
+	defer scheduleTicker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return ctx.Err()
+		case <-scheduleTicker.C:
+			// the lock interval is shorter than the schedule interval so a stopped instance
+			// does not delay the reports for more than one tick
+			if err := s.serverLock.LockAndExecute(ctx, schedulerLockName, scheduleInterval/2, s.sendDueReports); err != nil {
+				s.log.Error("Failed to schedule reports", "error", err)
+			}
+		case <-cleanupTicker.C:
+			if err := s.deleteRunsBefore(ctx, s.now().Add(-runRetention)); err != nil {
+				s.log.Error("Failed to clean up report runs", "error", err)
+			}
+		}
+	}
+}
+
+// sendDueReports sends the reports whose next run has passed. The next run of every due report
+// is moved forward before any report is sent, so other instances do not pick them up again.
+func (s *ReportService) sendDueReports(ctx context.Context) {
+	now := s.now()
+	due, err := s.dueReports(ctx, now)
+	if err != nil {
+		s.log.Error("Failed to get due reports", "error", err)
+		return
+	}
+
+	claimed := make([]*Report, 0, len(due))
+	for _, report := range due {
+		var next *time.Time
+		if n, err := nextRun(report.Schedule, report.Timezone, now); err != nil {
+			s.log.Warn("Report has an invalid schedule and is not scheduled again", "report", report.ID, "orgId", report.OrgID, "error", err)
+		} else {
+			next = &n
+		}
+		if err := s.setNextRun(ctx, report.ID, next); err != nil {
+			s.log.Error("Failed to schedule next report run", "report", report.ID, "orgId", report.OrgID, "error", err)
+			continue
+		}
+		claimed = append(claimed, report)
+	}
+
+	for _, report := range claimed {
+		if ctx.Err() != nil {
+			return
+		}
+		run, err := s.startRun(ctx, report)
+		if err != nil {
+			s.log.Error("Failed to start report run", "report", report.ID, "orgId", report.OrgID, "error", err)
+			continue
+		}
+		s.send(ctx, report, run)
+	}
+}
+
+func (s *ReportService) startRun(ctx context.Context, report *Report) (*Run, error) {
+	run := &Run{
+		OrgID:    report.OrgID,
+		ReportID: report.ID,
+		Status:   StatusRunning,
+		Started:  s.now(),
+	}
+	return run, s.insertRun(ctx, run)
+}
+
+// send renders or exports the dashboard of a report, emails it to the recipients and records the result of the run.
+func (s *ReportService) send(ctx context.Context, report *Report, run *Run) {
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	logger := s.log.New("report", report.ID, "orgId", report.OrgID, "run", run.ID)
+	message, err := s.deliver(ctx, report)
+	if err != nil {
+		logger.Warn("Failed to send report", "error", err)
+		run.Status = StatusFailed
+		run.Message = truncate(err.Error())
+	} else {
+		logger.Info("Sent report", "message", message)
+		run.Status = StatusSucceeded
+		run.Message = truncate(message)
+	}
+
+	finished := s.now()
+	run.Finished = &finished
+	// the run is recorded even if the run timed out
+	if err := s.updateRun(context.Background(), run); err != nil {
+		logger.Error("Failed to record report run", "error", err)
+	}
+
+	s.notifyOwner(report, run)
+}
+
+// notifyOwner lets the owner of a report know how its run went.
+func (s *ReportService) notifyOwner(report *Report, run *Run) {
+	if s.notifications == nil {
+		return
+	}
+
+	cmd := notificationcenter.NotifyCommand{
+		OrgID:    report.OrgID,
+		UserIDs:  []int64{report.UserID},
+		Kind:     notificationcenter.KindReport,
+		Severity: notificationcenter.SeveritySuccess,
+		Title:    fmt.Sprintf("Report %q was sent", report.Name),
+		Body:     run.Message,
+		URL:      fmt.Sprintf("/d/%s", report.DashboardUID),
+	}
+	if run.Status == StatusFailed {
+		cmd.Severity = notificationcenter.SeverityError
+		cmd.Title = fmt.Sprintf("Report %q failed", report.Name)
+	}
+
+	if _, err := s.notifications.Notify(context.Background(), cmd); err != nil {
+		s.log.Warn("Failed to notify report owner", "report", report.ID, "error", err)
+	}
+}
+
+// ScheduledItems returns the enabled reports of the organization for the schedule registry.
+func (s *ReportService) ScheduledItems(ctx context.Context, orgID int64) ([]schedule.Item, error) {
+	reports, err := s.listReports(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]schedule.Item, 0, len(reports))
+	for _, report := range reports {
+		if !report.Enabled {
+			continue
+		}
+		items = append(items, schedule.Item{Name: report.Name, Schedule: report.Schedule, Timezone: report.Timezone})
+	}
+	return items, nil
+}
+
+// GetReports returns the reports of the organization together with their last run.
+func (s *ReportService) GetReports(ctx context.Context, orgID int64) ([]*ReportDTO, error) {
+	reports, err := s.listReports(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ReportDTO, 0, len(reports))
+	for _, report := range reports {
+		dto, err := s.toDTO(ctx, report)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+// GetReport returns a report of the organization together with its last run.
+func (s *ReportService) GetReport(ctx context.Context, orgID, id int64) (*ReportDTO, error) {
+	report, err := s.getReport(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDTO(ctx, report)
+}
+
+func (s *ReportService) toDTO(ctx context.Context, report *Report) (*ReportDTO, error) {
+	runs, err := s.runs(ctx, report.OrgID, report.ID, 1)
+	if err != nil {
+		return nil, err
+	}
+	dto := &ReportDTO{Report: report}
+	if len(runs) > 0 {
+		dto.LastRun = runs[0]
+	}
+	return dto, nil
+}
+
+// CreateReport creates a report owned by the signed in user.
+func (s *ReportService) CreateReport(ctx context.Context, signedInUser *user.SignedInUser, cmd *CreateOrUpdateReportCommand) (*Report, error) {
+	if err := s.defaultTimezone(ctx, signedInUser, cmd); err != nil {
+		return nil, err
+	}
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkDashboard(ctx, signedInUser, cmd.DashboardUID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	report := &Report{
+		OrgID:   signedInUser.OrgID,
+		UserID:  signedInUser.UserID,
+		Created: now,
+	}
+	if err := s.apply(report, cmd, now); err != nil {
+		return nil, err
+	}
+	if err := s.insertReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// UpdateReport updates a report. The owner of the report does not change.
+func (s *ReportService) UpdateReport(ctx context.Context, signedInUser *user.SignedInUser, id int64, cmd *CreateOrUpdateReportCommand) (*Report, error) {
+	if err := s.defaultTimezone(ctx, signedInUser, cmd); err != nil {
+		return nil, err
+	}
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	report, err := s.getReport(ctx, signedInUser.OrgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDashboard(ctx, signedInUser, cmd.DashboardUID); err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(report, cmd, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.updateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) apply(report *Report, cmd *CreateOrUpdateReportCommand, now time.Time) error {
+	report.Name = cmd.Name
+	report.DashboardUID = cmd.DashboardUID
+	report.Format = cmd.Format
+	report.PanelIDs = cmd.PanelIDs
+	report.Orientation = cmd.Orientation
+	report.Recipients = cmd.Recipients
+	report.ReplyTo = cmd.ReplyTo
+	report.Message = cmd.Message
+	report.Schedule = cmd.Schedule
+	report.Timezone = cmd.Timezone
+	report.TimeFrom = cmd.TimeFrom
+	report.TimeTo = cmd.TimeTo
+	report.Enabled = cmd.Enabled
+	report.Updated = now
+
+	report.NextRun = nil
+	if report.Enabled {
+		next, err := nextRun(report.Schedule, report.Timezone, now)
+		if err != nil {
+			return err
+		}
+		report.NextRun = &next
+	}
+	return nil
+}
+
+// defaultTimezone evaluates reports without a time zone in the time zone of the preferences of
+// the user, so that they are sent when the user expects.
+func (s *ReportService) defaultTimezone(ctx context.Context, signedInUser *user.SignedInUser, cmd *CreateOrUpdateReportCommand) error {
+	if cmd.Timezone != "" {
+		return nil
+	}
+	prefs, err := s.preferenceService.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{
+		Teams:  signedInUser.Teams,
+		OrgID:  signedInUser.OrgID,
+		UserID: signedInUser.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	cmd.Timezone = schedule.ResolveTimezone(prefs.Timezone)
+	return nil
+}
+
+// checkDashboard makes sure the dashboard of a report exists and the user can view it.
+func (s *ReportService) checkDashboard(ctx context.Context, signedInUser *user.SignedInUser, dashboardUID string) error {
+	query := models.GetDashboardQuery{Uid: dashboardUID, OrgId: signedInUser.OrgID}
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return fmt.Errorf("%w: dashboard not found", ErrInvalidReport)
+		}
+		return err
+	}
+
+	canView, err := guardian.New(ctx, query.Result.Id, signedInUser.OrgID, signedInUser).CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return fmt.Errorf("%w: dashboard not found", ErrInvalidReport)
+	}
+	return nil
+}
+
+// DeleteReport deletes a report and its run history.
+func (s *ReportService) DeleteReport(ctx context.Context, orgID, id int64) error {
+	return s.deleteReport(ctx, orgID, id)
+}
+
+// GetRuns returns the runs of a report, newest first.
+func (s *ReportService) GetRuns(ctx context.Context, orgID, id int64, limit int) ([]*Run, error) {
+	if _, err := s.getReport(ctx, orgID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	}
+	if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+	return s.runs(ctx, orgID, id, limit)
+}
+
+// SendReport sends a report right away, independent of its schedule. The report is sent in
+// the background and the returned run can be used to follow its progress.
+func (s *ReportService) SendReport(ctx context.Context, orgID, id int64) (*Run, error) {
+	report, err := s.getReport(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.startRun(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	result := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.send(context.Background(), report, run)
+	}()
+	return &result, nil
+}
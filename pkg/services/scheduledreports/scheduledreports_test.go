@@ -0,0 +1,119 @@
+package scheduledreports
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/panelexport"
+)
+
+func TestCreateOrUpdateReportCommand_validate(t *testing.T) {
+	valid := func() *CreateOrUpdateReportCommand {
+		return &CreateOrUpdateReportCommand{
+			Name:         " Weekly ",
+			DashboardUID: "abc",
+			Recipients:   "a@example.com, Someone <b@example.com>,",
+			Schedule:     "0 8 * * 1",
+		}
+	}
+
+	t.Run("applies defaults", func(t *testing.T) {
+		cmd := valid()
+		require.NoError(t, cmd.validate())
+		assert.Equal(t, "Weekly", cmd.Name)
+		assert.Equal(t, FormatPDF, cmd.Format)
+		assert.Equal(t, OrientationLandscape, cmd.Orientation)
+		assert.Equal(t, "UTC", cmd.Timezone)
+		assert.Equal(t, "a@example.com,b@example.com", cmd.Recipients)
+	})
+
+	testCases := []struct {
+		desc   string
+		modify func(cmd *CreateOrUpdateReportCommand)
+	}{
+		{desc: "missing name", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Name = " " }},
+		{desc: "missing dashboard", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.DashboardUID = "" }},
+		{desc: "unknown format", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Format = "xlsx" }},
+		{desc: "unknown orientation", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Orientation = "upside-down" }},
+		{desc: "no recipients", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Recipients = " , " }},
+		{desc: "invalid recipient", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Recipients = "a@example.com,nobody" }},
+		{desc: "invalid reply to", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.ReplyTo = "nobody" }},
+		{desc: "invalid schedule", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Schedule = "every monday" }},
+		{desc: "schedule with seconds", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Schedule = "0 0 8 * * 1" }},
+		{desc: "schedule too frequent", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Schedule = "@every 10s" }},
+		{desc: "unknown timezone", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.Timezone = "Mars/Olympus" }},
+		{desc: "half time range", modify: func(cmd *CreateOrUpdateReportCommand) { cmd.TimeFrom = "now-7d" }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd := valid()
+			tc.modify(cmd)
+			require.ErrorIs(t, cmd.validate(), ErrInvalidReport)
+		})
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	after := time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC)
+
+	next, err := nextRun("0 8 * * 1", "Europe/Berlin", after)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 11, 21, 7, 0, 0, 0, time.UTC), next)
+
+	next, err = nextRun("@daily", "UTC", after)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 11, 17, 0, 0, 0, 0, time.UTC), next)
+}
+
+func TestDashboardHeight(t *testing.T) {
+	dashboard := simplejson.MustJson([]byte(`{
+		"panels": [
+			{"id": 1, "gridPos": {"y": 0, "h": 8}},
+			{"id": 3, "type": "row", "collapsed": true, "gridPos": {"y": 12, "h": 1},
+				"panels": [{"id": 4, "gridPos": {"y": 13, "h": 6}}]}
+		]
+	}`))
+	assert.Equal(t, dashboardHeaderHeight+13*gridRowHeight, dashboardHeight(dashboard))
+}
+
+func TestCSVFileName(t *testing.T) {
+	p := panelexport.Panel{ID: 4, Title: "Errors / 5m"}
+	assert.Equal(t, "Errors _ 5m-4.csv", csvFileName(p, 0, 1))
+	assert.Equal(t, "Errors _ 5m-4-2.csv", csvFileName(p, 1, 2))
+	assert.Equal(t, "panel-7.csv", csvFileName(panelexport.Panel{ID: 7}, 0, 1))
+}
+
+func TestRenderPDF(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1000, 3000))
+	for y := 0; y < 3000; y++ {
+		for x := 0; x < 1000; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(y % 256), A: 255})
+		}
+	}
+
+	t.Run("splits tall dashboards across pages", func(t *testing.T) {
+		contents, err := renderPDF(pdfLayout{title: "Überblick", subtitle: "last 7 days", orientation: OrientationLandscape}, img)
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(contents, []byte("%PDF-")))
+		// 3000 pixels scaled to the 277mm wide content area are 831mm high, which takes five landscape pages
+		assert.Equal(t, 5, bytes.Count(contents, []byte("/Type /Page\n")))
+	})
+
+	t.Run("fits more on portrait pages", func(t *testing.T) {
+		contents, err := renderPDF(pdfLayout{title: "Overview", orientation: OrientationPortrait}, img)
+		require.NoError(t, err)
+		assert.Equal(t, 3, bytes.Count(contents, []byte("/Type /Page\n")))
+	})
+
+	t.Run("fails on empty images", func(t *testing.T) {
+		_, err := renderPDF(pdfLayout{title: "Overview"}, image.NewRGBA(image.Rectangle{}))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,143 @@
+package scheduledreports
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+func (s *ReportService) insertReport(ctx context.Context, report *Report) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(report)
+		return err
+	})
+}
+
+func (s *ReportService) updateReport(ctx context.Context, report *Report) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ?", report.OrgID).ID(report.ID).AllCols().Omit("created", "user_id").Update(report)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+func (s *ReportService) deleteReport(ctx context.Context, orgID, id int64) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ?", orgID).ID(id).Delete(&Report{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		_, err = sess.Exec("DELETE FROM scheduled_report_run WHERE org_id = ? AND report_id = ?", orgID, id)
+		return err
+	})
+}
+
+func (s *ReportService) getReport(ctx context.Context, orgID, id int64) (*Report, error) {
+	report := &Report{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND id = ?", orgID, id).Get(report)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (s *ReportService) listReports(ctx context.Context, orgID int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name", "id").Find(&reports)
+	})
+	return reports, err
+}
+
+// dueReports returns the enabled reports which should have run before the given time.
+func (s *ReportService) dueReports(ctx context.Context, now time.Time) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ? AND next_run <= ?", s.store.GetDialect().BooleanStr(true), now).
+			Asc("next_run").Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *ReportService) setNextRun(ctx context.Context, id int64, next *time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Cols("next_run").NoAutoTime().Update(&Report{NextRun: next})
+		return err
+	})
+}
+
+func (s *ReportService) insertRun(ctx context.Context, run *Run) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(run)
+		return err
+	})
+}
+
+func (s *ReportService) updateRun(ctx context.Context, run *Run) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(run.ID).Cols("status", "message", "finished").Update(run)
+		return err
+	})
+}
+
+func (s *ReportService) runs(ctx context.Context, orgID, reportID int64, limit int) ([]*Run, error) {
+	runs := make([]*Run, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND report_id = ?", orgID, reportID).
+			Desc("started", "id").Limit(limit).Find(&runs)
+	})
+	return runs, err
+}
+
+func (s *ReportService) deleteRunsBefore(ctx context.Context, before time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM scheduled_report_run WHERE started < ?", before)
+		return err
+	})
+}
+
+func (s *ReportService) count(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u := &quota.Map{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		total, err := sess.Count(&Report{})
+		if err != nil {
+			return err
+		}
+		tag, err := quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.GlobalScope)
+		if err != nil {
+			return err
+		}
+		u.Set(tag, total)
+
+		if scopeParams == nil || scopeParams.OrgID == 0 {
+			return nil
+		}
+		orgTotal, err := sess.Where("org_id = ?", scopeParams.OrgID).Count(&Report{})
+		if err != nil {
+			return err
+		}
+		tag, err = quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.OrgScope)
+		if err != nil {
+			return err
+		}
+		u.Set(tag, orgTotal)
+		return nil
+	})
+	return u, err
+}
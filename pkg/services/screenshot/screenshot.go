@@ -125,6 +125,10 @@ func (s *HeadlessScreenshotService) Take(ctx context.Context, opts ScreenshotOpt
 	result, err := s.rs.Render(ctx, renderOpts, nil)
 	if err != nil {
 		s.instrumentError(err)
+		if errors.Is(err, rendering.ErrRenderUnavailable) {
+			// no renderer is installed or healthy, so alerts are sent without screenshots
+			return nil, ErrScreenshotsUnavailable
+		}
 		return nil, fmt.Errorf("failed to take screenshot: %w", err)
 	}
 
@@ -138,6 +142,10 @@ func (s *HeadlessScreenshotService) instrumentError(err error) {
 		defer s.failures.With(prometheus.Labels{
 			"reason": "dashboard_not_found",
 		}).Inc()
+	} else if errors.Is(err, rendering.ErrRenderUnavailable) {
+		defer s.failures.With(prometheus.Labels{
+			"reason": "renderer_unavailable",
+		}).Inc()
 	} else if errors.Is(err, context.Canceled) {
 		defer s.failures.With(prometheus.Labels{
 			"reason": "context_canceled",
@@ -77,6 +77,14 @@ func TestHeadlessScreenshotService(t *testing.T) {
 	screenshot, err = s.Take(ctx, opts)
 	assert.EqualError(t, err, fmt.Sprintf("failed to take screenshot: %s", rendering.ErrTimeout))
 	assert.Nil(t, screenshot)
+
+	// no available renderer should return ErrScreenshotsUnavailable
+	r.EXPECT().
+		Render(ctx, renderOpts, nil).
+		Return(nil, rendering.ErrRenderUnavailable)
+	screenshot, err = s.Take(ctx, opts)
+	assert.ErrorIs(t, err, ErrScreenshotsUnavailable)
+	assert.Nil(t, screenshot)
 }
 
 func TestNoOpScreenshotService(t *testing.T) {
@@ -6,6 +6,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -41,6 +43,7 @@ type Query struct {
 	FolderIds     []int64
 	Permission    models.PermissionType
 	Sort          string
+	LabelSelector resourcelabel.Selector
 
 	Result models.HitList
 }
@@ -73,6 +76,10 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) error {
 		Permission:    query.Permission,
 	}
 
+	if len(query.LabelSelector) > 0 {
+		dashboardQuery.Filters = append(dashboardQuery.Filters, searchstore.LabelFilter{Selector: query.LabelSelector})
+	}
+
 	if sortOpt, exists := s.sortOptions[query.Sort]; exists {
 		dashboardQuery.Sort = sortOpt
 	}
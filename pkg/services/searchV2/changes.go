@@ -0,0 +1,163 @@
+package searchV2
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/services/store"
+)
+
+// maxPendingChanges bounds the changes waiting to be applied on the index. Changes are dropped
+// when the index does not keep up, and a full re-indexing is triggered instead.
+const maxPendingChanges = 1000
+
+// indexChange is a change of a dashboard or folder published on the bus.
+type indexChange struct {
+	orgID     int64
+	kind      store.EntityType
+	uid       string
+	eventType store.EntityEventType
+}
+
+// ReindexStatus is the state of the search index of this instance.
+type ReindexStatus struct {
+	InitialIndexingComplete bool    `json:"initialIndexingComplete"`
+	IndexedOrgs             []int64 `json:"indexedOrgs"`
+	// FullReindexInterval is 0s if periodic full re-indexing is disabled.
+	FullReindexInterval     string     `json:"fullReindexInterval"`
+	FullReindexInProgress   bool       `json:"fullReindexInProgress"`
+	LastFullReindexStarted  *time.Time `json:"lastFullReindexStarted,omitempty"`
+	LastFullReindexFinished *time.Time `json:"lastFullReindexFinished,omitempty"`
+	LastFullReindexDuration string     `json:"lastFullReindexDuration,omitempty"`
+	// LastEventID is the ID of the last entity event applied on the index.
+	LastEventID int64 `json:"lastEventId"`
+	// AppliedChanges counts the changes published on the bus and the entity events applied on
+	// the index since Grafana started.
+	AppliedChanges       int64      `json:"appliedChanges"`
+	FailedChanges        int64      `json:"failedChanges"`
+	DroppedChanges       int64      `json:"droppedChanges"`
+	PendingChanges       int        `json:"pendingChanges"`
+	LastIncrementalApply *time.Time `json:"lastIncrementalApply,omitempty"`
+}
+
+// registerChangeListeners applies the changes of dashboards and folders made on this instance
+// right away, including the changes of their tags, folders and permissions. The other instances
+// apply the changes from the entity events.
+func (s *StandardSearchService) registerChangeListeners(bus bus.Bus) {
+	bus.AddEventListener(func(_ context.Context, e *events.DashboardSaved) error {
+		s.enqueueChange(indexChange{orgID: e.OrgID, kind: store.EntityTypeDashboard, uid: e.UID, eventType: store.EntityEventTypeUpdate})
+		return nil
+	})
+	bus.AddEventListener(func(_ context.Context, e *events.DashboardDeleted) error {
+		s.enqueueChange(indexChange{orgID: e.OrgID, kind: store.EntityTypeDashboard, uid: e.UID, eventType: store.EntityEventTypeDelete})
+		return nil
+	})
+	bus.AddEventListener(func(_ context.Context, e *events.FolderSaved) error {
+		s.enqueueChange(indexChange{orgID: e.OrgID, kind: store.EntityTypeFolder, uid: e.UID, eventType: store.EntityEventTypeUpdate})
+		return nil
+	})
+	bus.AddEventListener(func(_ context.Context, e *events.FolderTitleUpdated) error {
+		s.enqueueChange(indexChange{orgID: e.OrgID, kind: store.EntityTypeFolder, uid: e.UID, eventType: store.EntityEventTypeUpdate})
+		return nil
+	})
+	bus.AddEventListener(func(_ context.Context, e *events.FolderDeleted) error {
+		s.enqueueChange(indexChange{orgID: e.OrgID, kind: store.EntityTypeFolder, uid: e.UID, eventType: store.EntityEventTypeDelete})
+		return nil
+	})
+	bus.AddEventListener(func(_ context.Context, e *events.ResourcePermissionsUpdated) error {
+		// Extenders may index documents depending on the permissions.
+		kind := store.EntityTypeDashboard
+		if e.Resource == "folders" {
+			kind = store.EntityTypeFolder
+		}
+		s.enqueueChange(indexChange{orgID: e.OrgID, kind: kind, uid: e.UID, eventType: store.EntityEventTypeUpdate})
+		return nil
+	})
+}
+
+func (s *StandardSearchService) enqueueChange(change indexChange) {
+	if !s.dashboardIndex.enqueueChange(change) {
+		s.TriggerReIndex()
+	}
+}
+
+// enqueueChange returns false if the change was dropped because too many changes are pending.
+func (i *searchIndex) enqueueChange(change indexChange) bool {
+	select {
+	case i.changes <- change:
+		return true
+	default:
+		i.statusMu.Lock()
+		i.status.DroppedChanges++
+		i.statusMu.Unlock()
+		i.logger.Warn("Too many pending search index changes, dropping change", "orgId", change.orgID, "kind", change.kind, "uid", change.uid)
+		return false
+	}
+}
+
+func (i *searchIndex) applyChange(ctx context.Context, change indexChange) {
+	err := i.applyEvent(ctx, change.orgID, change.kind, change.uid, change.eventType)
+
+	i.statusMu.Lock()
+	defer i.statusMu.Unlock()
+	if err != nil {
+		i.status.FailedChanges++
+		i.logger.Error("Can't apply change on search index", "orgId", change.orgID, "kind", change.kind, "uid", change.uid, "error", err)
+		return
+	}
+	now := time.Now()
+	i.status.AppliedChanges++
+	i.status.LastIncrementalApply = &now
+}
+
+func (i *searchIndex) eventsApplied(lastEventID int64, count int) {
+	now := time.Now()
+	i.statusMu.Lock()
+	defer i.statusMu.Unlock()
+	i.status.LastEventID = lastEventID
+	i.status.AppliedChanges += int64(count)
+	i.status.LastIncrementalApply = &now
+}
+
+func (i *searchIndex) fullReindexStarted(started time.Time) {
+	i.statusMu.Lock()
+	defer i.statusMu.Unlock()
+	i.status.FullReindexInProgress = true
+	i.status.LastFullReindexStarted = &started
+}
+
+func (i *searchIndex) fullReindexFinished(started time.Time) {
+	now := time.Now()
+	i.statusMu.Lock()
+	defer i.statusMu.Unlock()
+	i.status.FullReindexInProgress = false
+	i.status.LastFullReindexFinished = &now
+	i.status.LastFullReindexDuration = now.Sub(started).String()
+}
+
+func (i *searchIndex) reindexStatus() ReindexStatus {
+	i.statusMu.Lock()
+	status := i.status
+	i.statusMu.Unlock()
+
+	i.initializationMutex.RLock()
+	status.InitialIndexingComplete = i.initialIndexingComplete
+	i.initializationMutex.RUnlock()
+
+	i.mu.RLock()
+	status.IndexedOrgs = make([]int64, 0, len(i.perOrgIndex))
+	for orgID := range i.perOrgIndex {
+		status.IndexedOrgs = append(status.IndexedOrgs, orgID)
+	}
+	i.mu.RUnlock()
+	sort.Slice(status.IndexedOrgs, func(a, b int) bool {
+		return status.IndexedOrgs[a] < status.IndexedOrgs[b]
+	})
+
+	status.FullReindexInterval = i.settings.FullReindexInterval.String()
+	status.PendingChanges = len(i.changes)
+	return status
+}
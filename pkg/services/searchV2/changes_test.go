@@ -0,0 +1,51 @@
+package searchV2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/store"
+)
+
+func TestSearchIndexChanges(t *testing.T) {
+	t.Run("applies changes published on the bus", func(t *testing.T) {
+		index := initTestIndexFromDashes(t, testDashboards)
+		index.loader = &testDashboardLoader{dashboards: []dashboard{{
+			id:  2,
+			uid: "2",
+			summary: &models.ObjectSummary{
+				Name: "retagged",
+			},
+		}}}
+
+		require.True(t, index.enqueueChange(indexChange{orgID: testOrgID, kind: store.EntityTypeDashboard, uid: "2", eventType: store.EntityEventTypeUpdate}))
+		require.Equal(t, 1, index.reindexStatus().PendingChanges)
+		index.applyChange(context.Background(), <-index.changes)
+
+		orgIdx, ok := index.getOrgIndex(testOrgID)
+		require.True(t, ok)
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter,
+			DashboardQuery{Query: "retagged"}, &NoopQueryExtender{}, "")
+		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
+		require.True(t, ok)
+		require.Equal(t, uint64(1), custom.Count)
+
+		status := index.reindexStatus()
+		require.Equal(t, int64(1), status.AppliedChanges)
+		require.Equal(t, 0, status.PendingChanges)
+		require.Equal(t, []int64{testOrgID}, status.IndexedOrgs)
+		require.NotNil(t, status.LastIncrementalApply)
+	})
+
+	t.Run("drops changes if too many are pending", func(t *testing.T) {
+		index := initTestIndexFromDashes(t, testDashboards)
+		for i := 0; i < maxPendingChanges; i++ {
+			require.True(t, index.enqueueChange(indexChange{orgID: testOrgID, kind: store.EntityTypeDashboard, uid: "2"}))
+		}
+		require.False(t, index.enqueueChange(indexChange{orgID: testOrgID, kind: store.EntityTypeDashboard, uid: "2"}))
+		require.Equal(t, int64(1), index.reindexStatus().DroppedChanges)
+	})
+}
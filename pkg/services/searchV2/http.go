@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -22,14 +23,24 @@ type searchHTTPService struct {
 	search SearchService
 }
 
-func ProvideSearchHTTPService(search SearchService) SearchHTTPService {
-	return &searchHTTPService{search: search}
+func ProvideSearchHTTPService(search SearchService, routeRegister routing.RouteRegister, features featuremgmt.FeatureToggles) SearchHTTPService {
+	s := &searchHTTPService{search: search}
+	// the index is only kept up to date by the changes published on the bus with panel title search
+	if features.IsEnabled(featuremgmt.FlagPanelTitleSearch) {
+		routeRegister.Get("/api/admin/search/reindex-status", middleware.ReqGrafanaAdmin, routing.Wrap(s.reindexStatus))
+	}
+	return s
 }
 
 func (s *searchHTTPService) RegisterHTTPRoutes(storageRoute routing.RouteRegister) {
 	storageRoute.Post("/", middleware.ReqSignedIn, routing.Wrap(s.doQuery))
 }
 
+// reindexStatus returns the state of the search index of this instance.
+func (s *searchHTTPService) reindexStatus(c *models.ReqContext) response.Response {
+	return response.JSON(200, s.search.ReindexStatus())
+}
+
 func (s *searchHTTPService) doQuery(c *models.ReqContext) response.Response {
 	searchReadinessCheckResp := s.search.IsReady(c.Req.Context(), c.OrgID)
 	if !searchReadinessCheckResp.IsReady {
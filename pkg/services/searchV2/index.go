@@ -18,6 +18,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/semaphore"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -102,6 +103,13 @@ type searchIndex struct {
 	tracer                  tracing.Tracer
 	features                featuremgmt.FeatureToggles
 	settings                setting.SearchSettings
+	// semaphore limits the number of nodes re-indexing at the same time, nil for no limit.
+	semaphore semaphore.Service
+	// changes are published on the bus by this instance, and applied without waiting for the
+	// entity events.
+	changes  chan indexChange
+	statusMu sync.Mutex
+	status   ReindexStatus
 }
 
 func newSearchIndex(dashLoader dashboardLoader, evStore eventStore, extender DocumentExtender, folderIDs folderUIDLookup, tracer tracing.Tracer, features featuremgmt.FeatureToggles, settings setting.SearchSettings) *searchIndex {
@@ -115,6 +123,7 @@ func newSearchIndex(dashLoader dashboardLoader, evStore eventStore, extender Doc
 		extender:        extender,
 		folderIdLookup:  folderIDs,
 		syncCh:          make(chan chan struct{}),
+		changes:         make(chan indexChange, maxPendingChanges),
 		tracer:          tracer,
 		features:        features,
 		settings:        settings,
@@ -180,52 +189,63 @@ func (i *searchIndex) sync(ctx context.Context) error {
 	}
 }
 
-func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh chan struct{}) error {
+// init builds the indexes of the organizations and returns the id of the last entity event they
+// include.
+func (i *searchIndex) init(ctx context.Context, orgIDs []int64) (int64, error) {
 	i.logger.Info("Initializing SearchV2", "dashboardLoadingBatchSize", i.settings.DashboardLoadingBatchSize, "fullReindexInterval", i.settings.FullReindexInterval, "indexUpdateInterval", i.settings.IndexUpdateInterval)
 	initialSetupCtx, initialSetupSpan := i.tracer.Start(ctx, "searchV2 initialSetup")
-
-	reIndexInterval := i.settings.FullReindexInterval
-	fullReIndexTimer := time.NewTimer(reIndexInterval)
-	defer fullReIndexTimer.Stop()
-
-	partialUpdateInterval := i.settings.IndexUpdateInterval
-	partialUpdateTimer := time.NewTimer(partialUpdateInterval)
-	defer partialUpdateTimer.Stop()
+	defer initialSetupSpan.End()
 
 	var lastEventID int64
 	lastEvent, err := i.eventStore.GetLastEvent(initialSetupCtx)
 	if err != nil {
-		initialSetupSpan.End()
-		return err
+		return 0, err
 	}
 	if lastEvent != nil {
 		lastEventID = lastEvent.Id
 	}
 
-	err = i.buildInitialIndexes(initialSetupCtx, orgIDs)
-	if err != nil {
-		initialSetupSpan.End()
-		return err
+	if err := i.buildInitialIndexes(initialSetupCtx, orgIDs); err != nil {
+		return 0, err
 	}
 
+	i.initializationMutex.Lock()
+	i.initialIndexingComplete = true
+	i.initializationMutex.Unlock()
+	return lastEventID, nil
+}
+
+// run keeps the indexes built by init up to date, starting from the entity event lastEventID.
+func (i *searchIndex) run(ctx context.Context, lastEventID int64, reIndexSignalCh chan struct{}) error {
+	// Periodic full re-indexing is disabled if the interval is 0, the changes published on the bus
+	// and the entity events keep the index up to date then.
+	reIndexInterval := i.settings.FullReindexInterval
+	fullReIndexTimer := time.NewTimer(time.Hour)
+	fullReIndexTimer.Stop()
+	if reIndexInterval > 0 {
+		fullReIndexTimer.Reset(reIndexInterval)
+	}
+	defer fullReIndexTimer.Stop()
+
+	partialUpdateInterval := i.settings.IndexUpdateInterval
+	partialUpdateTimer := time.NewTimer(partialUpdateInterval)
+	defer partialUpdateTimer.Stop()
+
 	// This semaphore channel allows limiting concurrent async re-indexing routines to 1.
 	asyncReIndexSemaphore := make(chan struct{}, 1)
 
 	// Channel to handle signals about asynchronous full re-indexing completion.
 	reIndexDoneCh := make(chan int64, 1)
 
-	i.initializationMutex.Lock()
-	i.initialIndexingComplete = true
-	i.initializationMutex.Unlock()
-
-	initialSetupSpan.End()
-
 	for {
 		select {
 		case doneCh := <-i.syncCh:
 			// Executed on search read requests to make sure index is consistent.
 			lastEventID = i.applyIndexUpdates(ctx, lastEventID)
 			close(doneCh)
+		case change := <-i.changes:
+			// Apply the changes made on this instance right away.
+			i.applyChange(ctx, change)
 		case <-partialUpdateTimer.C:
 			// Periodically apply updates collected in entity events table.
 			partialIndexUpdateCtx, span := i.tracer.Start(ctx, "searchV2 partial update timer")
@@ -260,7 +280,7 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 				// We need semaphore here since asynchronous re-indexing may be in progress already.
 				asyncReIndexSemaphore <- struct{}{}
 				defer func() { <-asyncReIndexSemaphore }()
-				_, err = i.buildOrgIndex(buildSignalCtx, signal.orgID)
+				_, err := i.buildOrgIndex(buildSignalCtx, signal.orgID)
 				signal.done <- err
 				reIndexDoneCh <- lastIndexedEventID
 			}()
@@ -283,9 +303,11 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 				defer func() { <-asyncReIndexSemaphore }()
 
 				started := time.Now()
+				i.fullReindexStarted(started)
 				i.logger.Info("Start re-indexing", i.withCtxData(fullReindexCtx)...)
 				i.reIndexFromScratch(fullReindexCtx)
 				i.logger.Info("Full re-indexing finished", i.withCtxData(fullReindexCtx, "fullReIndexElapsed", time.Since(started))...)
+				i.fullReindexFinished(started)
 				reIndexDoneCh <- lastIndexedEventID
 			}()
 		case lastIndexedEventID := <-reIndexDoneCh:
@@ -298,7 +320,9 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 				// Apply events immediately.
 				partialUpdateTimer.Reset(0)
 			}
-			fullReIndexTimer.Reset(reIndexInterval)
+			if reIndexInterval > 0 {
+				fullReIndexTimer.Reset(reIndexInterval)
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -306,6 +330,9 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 }
 
 func (i *searchIndex) buildInitialIndexes(ctx context.Context, orgIDs []int64) error {
+	release := i.acquireReindexSlot(ctx)
+	defer release()
+
 	started := time.Now()
 	i.logger.Info("Start building in-memory indexes")
 	for _, orgID := range orgIDs {
@@ -567,7 +594,26 @@ func (i *searchIndex) getOrCreateOrgIndex(ctx context.Context, orgID int64) (*or
 	return index, nil
 }
 
+// acquireReindexSlot waits until this node may load all the dashboards of the database, so that
+// the nodes of a cluster don't all re-index at the same time, and returns a function releasing the
+// slot. Re-indexing is not interrupted if the slot is lost, and happens anyway if the slot can't be
+// acquired, since search doesn't work without an index.
+func (i *searchIndex) acquireReindexSlot(ctx context.Context) func() {
+	if i.semaphore == nil {
+		return func() {}
+	}
+	lease, err := i.semaphore.Acquire(ctx, semaphore.OperationSearchReindex)
+	if err != nil {
+		i.logger.Warn("Failed to acquire re-indexing slot, re-indexing anyway", "error", err)
+		return func() {}
+	}
+	return lease.Release
+}
+
 func (i *searchIndex) reIndexFromScratch(ctx context.Context) {
+	release := i.acquireReindexSlot(ctx)
+	defer release()
+
 	i.mu.RLock()
 	orgIDs := make([]int64, 0, len(i.perOrgIndex))
 	for orgID := range i.perOrgIndex {
@@ -617,6 +663,7 @@ func (i *searchIndex) applyIndexUpdates(ctx context.Context, lastEventID int64)
 		lastEventID = e.Id
 	}
 	i.logger.Info("Index updates applied", i.withCtxData(ctx, "indexEventsAppliedElapsed", time.Since(started), "numEvents", len(events))...)
+	i.eventsApplied(lastEventID, len(events))
 	return lastEventID
 }
 
@@ -793,6 +840,23 @@ func (i *searchIndex) updateDashboard(ctx context.Context, orgID int64, index *o
 		return err
 	}
 
+	// The panels of a dashboard moved to another folder are indexed at its previous location.
+	previousLocation, found, err := getDashboardLocation(index, dash.uid)
+	if err != nil {
+		return err
+	}
+	if found && previousLocation != folderUID {
+		previousPanelLocation := dash.uid
+		if previousLocation != "" {
+			previousPanelLocation = previousLocation + "/" + dash.uid
+		}
+		previousPanelIDs, err := getDashboardPanelIDs(index, previousPanelLocation)
+		if err != nil {
+			return err
+		}
+		indexedPanelIDs = append(indexedPanelIDs, previousPanelIDs...)
+	}
+
 	for _, panelID := range indexedPanelIDs {
 		if !stringInSlice(panelID, actualPanelIDs) {
 			batch.Delete(bluge.NewDocument(panelID).ID())
@@ -499,6 +499,27 @@ func TestDashboardIndex_Folders(t *testing.T) {
 		require.True(t, ok)
 		require.Equal(t, uint64(1), custom.Count) // 1 panel which does not belong to dashboards in removed folder.
 	})
+	t.Run("folders-panels-removed-on-dashboard-moved", func(t *testing.T) {
+		index := initTestIndexFromDashes(t, dashboardsWithFolders)
+		orgIdx, ok := index.getOrgIndex(testOrgID)
+		require.True(t, ok)
+		// Move the dashboard from the general folder and remove its panel.
+		err := index.updateDashboard(context.Background(), testOrgID, orgIdx, dashboard{
+			id:       4,
+			uid:      "4",
+			folderID: 1,
+			summary: &models.ObjectSummary{
+				Name: "One more dash",
+			},
+		})
+		require.NoError(t, err)
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter,
+			DashboardQuery{Query: "Panel", Kind: []string{string(entityKindPanel)}},
+			&NoopQueryExtender{}, "")
+		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
+		require.True(t, ok)
+		require.Equal(t, uint64(3), custom.Count) // the panel removed from the moved dashboard is gone.
+	})
 }
 
 var dashboardsWithPanels = []dashboard{
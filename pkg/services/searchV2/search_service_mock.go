@@ -66,6 +66,20 @@ func (_m *MockSearchService) RegisterDashboardIndexExtender(ext DashboardIndexEx
 	_m.Called(ext)
 }
 
+// ReindexStatus provides a mock function with given fields:
+func (_m *MockSearchService) ReindexStatus() ReindexStatus {
+	ret := _m.Called()
+
+	var r0 ReindexStatus
+	if rf, ok := ret.Get(0).(func() ReindexStatus); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(ReindexStatus)
+	}
+
+	return r0
+}
+
 // Run provides a mock function with given fields: ctx
 func (_m *MockSearchService) Run(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -12,8 +12,10 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/querylibrary"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/semaphore"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -75,8 +77,10 @@ type StandardSearchService struct {
 	dashboardIndex *searchIndex
 	extender       DashboardIndexExtender
 	reIndexCh      chan struct{}
-	queries        querylibrary.Service
-	features       featuremgmt.FeatureToggles
+	// lastEventID is the last entity event included in the indexes built by Init.
+	lastEventID int64
+	queries     querylibrary.Service
+	features    featuremgmt.FeatureToggles
 }
 
 func (s *StandardSearchService) IsReady(ctx context.Context, orgId int64) IsSearchReadyResponse {
@@ -85,7 +89,7 @@ func (s *StandardSearchService) IsReady(ctx context.Context, orgId int64) IsSear
 
 func ProvideService(cfg *setting.Cfg, sql db.DB, entityEventStore store.EntityEventsService,
 	ac accesscontrol.Service, tracer tracing.Tracer, features featuremgmt.FeatureToggles, orgService org.Service,
-	userService user.Service, queries querylibrary.Service) SearchService {
+	userService user.Service, queries querylibrary.Service, semaphore semaphore.Service, bus bus.Bus) SearchService {
 	extender := &NoopExtender{}
 	s := &StandardSearchService{
 		cfg: cfg,
@@ -112,6 +116,10 @@ func ProvideService(cfg *setting.Cfg, sql db.DB, entityEventStore store.EntityEv
 		queries:     queries,
 		features:    features,
 	}
+	s.dashboardIndex.semaphore = semaphore
+	if features.IsEnabled(featuremgmt.FlagPanelTitleSearch) {
+		s.registerChangeListeners(bus)
+	}
 	return s
 }
 
@@ -122,7 +130,8 @@ func (s *StandardSearchService) IsDisabled() bool {
 	return !s.cfg.IsFeatureToggleEnabled(featuremgmt.FlagPanelTitleSearch)
 }
 
-func (s *StandardSearchService) Run(ctx context.Context) error {
+// Init builds the indexes of all organizations.
+func (s *StandardSearchService) Init(ctx context.Context) error {
 	orgQuery := &org.SearchOrgsQuery{}
 	result, err := s.orgService.Search(ctx, orgQuery)
 	if err != nil {
@@ -132,7 +141,12 @@ func (s *StandardSearchService) Run(ctx context.Context) error {
 	for _, org := range result {
 		orgIDs = append(orgIDs, org.ID)
 	}
-	return s.dashboardIndex.run(ctx, orgIDs, s.reIndexCh)
+	s.lastEventID, err = s.dashboardIndex.init(ctx, orgIDs)
+	return err
+}
+
+func (s *StandardSearchService) Run(ctx context.Context) error {
+	return s.dashboardIndex.run(ctx, s.lastEventID, s.reIndexCh)
 }
 
 func (s *StandardSearchService) TriggerReIndex() {
@@ -143,6 +157,10 @@ func (s *StandardSearchService) TriggerReIndex() {
 	}
 }
 
+func (s *StandardSearchService) ReindexStatus() ReindexStatus {
+	return s.dashboardIndex.reindexStatus()
+}
+
 func (s *StandardSearchService) RegisterDashboardIndexExtender(ext DashboardIndexExtender) {
 	s.extender = ext
 	s.dashboardIndex.extender = ext.GetDocumentExtender()
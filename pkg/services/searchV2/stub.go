@@ -27,6 +27,10 @@ func (s *stubSearchService) TriggerReIndex() {
 	// noop.
 }
 
+func (s *stubSearchService) ReindexStatus() ReindexStatus {
+	return ReindexStatus{}
+}
+
 func NewStubSearchService() SearchService {
 	return &stubSearchService{}
 }
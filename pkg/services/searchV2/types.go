@@ -47,4 +47,5 @@ type SearchService interface {
 	IsReady(ctx context.Context, orgId int64) IsSearchReadyResponse
 	RegisterDashboardIndexExtender(ext DashboardIndexExtender)
 	TriggerReIndex()
+	ReindexStatus() ReindexStatus
 }
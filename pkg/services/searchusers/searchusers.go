@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/login"
@@ -42,9 +43,12 @@ func ProvideUsersService(searchUserFilter user.SearchUserFilter, userService use
 func (s *OSSService) SearchUsers(c *models.ReqContext) response.Response {
 	result, err := s.SearchUser(c)
 	if err != nil {
-		return response.Error(500, "Failed to fetch users", err)
+		return searchError(err)
 	}
 
+	if result.NextCursor != "" {
+		c.Resp.Header().Set(pagination.NextCursorHeader, result.NextCursor)
+	}
 	return response.JSON(http.StatusOK, result.Users)
 }
 
@@ -61,19 +65,33 @@ func (s *OSSService) SearchUsers(c *models.ReqContext) response.Response {
 func (s *OSSService) SearchUsersWithPaging(c *models.ReqContext) response.Response {
 	result, err := s.SearchUser(c)
 	if err != nil {
-		return response.Error(500, "Failed to fetch users", err)
+		return searchError(err)
 	}
 
 	return response.JSON(http.StatusOK, result)
 }
 
-func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResult, error) {
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+func searchError(err error) response.Response {
+	if pagination.IsInvalid(err) {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
 	}
-	page := c.QueryInt("page")
+	return response.Error(http.StatusInternalServerError, "Failed to fetch users", err)
+}
 
+// paginationConfig is the paging configuration of the user search.
+var paginationConfig = pagination.Config{
+	SortFields:   []string{"login", "email", "name"},
+	DefaultSort:  pagination.Sort{Field: "login"},
+	DefaultLimit: 1000,
+	MaxLimit:     5000,
+}
+
+func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResult, error) {
+	paging, err := paginationConfig.Parse(c.Req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	page := paging.Page
 	if page < 1 {
 		page = 1
 	}
@@ -93,7 +111,9 @@ func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResu
 		Query:        searchQuery,
 		Filters:      filters,
 		Page:         page,
-		Limit:        perPage,
+		Limit:        paging.Limit,
+		Sort:         paging.Sort,
+		After:        paging.After,
 	}
 	res, err := s.userService.Search(c.Req.Context(), query)
 	if err != nil {
@@ -111,7 +131,22 @@ func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResu
 	}
 
 	res.Page = page
-	res.PerPage = perPage
+	res.PerPage = paging.Limit
+	if n := len(res.Users); n > 0 {
+		last := res.Users[n-1]
+		res.NextCursor = paging.NextCursor(n, userSortValue(last, paging.Sort.Field), last.ID)
+	}
 
 	return res, nil
 }
+
+func userSortValue(u *user.UserSearchHitDTO, field string) interface{} {
+	switch field {
+	case "email":
+		return u.Email
+	case "name":
+		return u.Name
+	default:
+		return u.Login
+	}
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsettings"
+	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
+)
+
+type PluginSettingSecretMigrationService struct {
+	pluginSettingsService pluginsettings.Service
+	kvStore               *kvstore.NamespacedKVStore
+	features              featuremgmt.FeatureToggles
+}
+
+func ProvidePluginSettingMigrationService(
+	pluginSettingsService pluginsettings.Service,
+	kvStore kvstore.KVStore,
+	features featuremgmt.FeatureToggles,
+) *PluginSettingSecretMigrationService {
+	return &PluginSettingSecretMigrationService{
+		pluginSettingsService: pluginSettingsService,
+		kvStore:               kvstore.WithNamespace(kvStore, 0, secretskvs.PluginSettingSecretType),
+		features:              features,
+	}
+}
+
+func (s *PluginSettingSecretMigrationService) Migrate(ctx context.Context) error {
+	migrationStatus, _, err := s.kvStore.Get(ctx, secretMigrationStatusKey)
+	if err != nil {
+		return err
+	}
+	logger.Debug(fmt.Sprint("plugin setting secret migration status is ", migrationStatus))
+	// Same status handling as the data source secret migration
+	disableSecretsCompatibility := s.features.IsEnabled(featuremgmt.FlagDisableSecretsCompatibility)
+	needCompatibility := migrationStatus != compatibleSecretMigrationValue && !disableSecretsCompatibility
+	needMigration := migrationStatus != completeSecretMigrationValue && disableSecretsCompatibility
+
+	if needCompatibility || needMigration {
+		logger.Debug("performing plugin setting secret migration", "needs migration", needMigration, "needs compatibility", needCompatibility)
+		if err := s.pluginSettingsService.MigrateSecrets(ctx); err != nil {
+			return err
+		}
+
+		var newMigStatus string
+		if disableSecretsCompatibility {
+			newMigStatus = completeSecretMigrationValue
+		} else {
+			newMigStatus = compatibleSecretMigrationValue
+		}
+		err = s.kvStore.Set(ctx, secretMigrationStatusKey, newMigStatus)
+		if err != nil {
+			return err
+		}
+		logger.Debug(fmt.Sprint("set plugin setting secret migration status to ", newMigStatus))
+	}
+
+	return nil
+}
@@ -8,6 +8,11 @@ const (
 	QuitOnPluginStartupFailureKey = "quit_on_secrets_plugin_startup_failure"
 	PluginNamespace               = "secretsmanagerplugin"
 	DataSourceSecretType          = "datasource"
+	PluginSettingSecretType       = "plugin-setting"
+
+	// DataSourceUserCredentialsSecretType is followed by the id of the user, the namespace is the
+	// uid of the data source.
+	DataSourceUserCredentialsSecretType = "datasource-user-credentials"
 )
 
 // Item stored in k/v store.
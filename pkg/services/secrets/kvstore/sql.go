@@ -80,7 +80,7 @@ func (kv *SecretsKVStoreSQL) Get(ctx context.Context, orgId int64, namespace str
 
 // Set an item in the store
 func (kv *SecretsKVStoreSQL) Set(ctx context.Context, orgId int64, namespace string, typ string, value string) error {
-	encryptedValue, err := kv.secretsService.Encrypt(ctx, []byte(value), secrets.WithoutScope())
+	encryptedValue, err := kv.secretsService.Encrypt(ctx, []byte(value), secrets.WithOrgScope(orgId))
 	if err != nil {
 		kv.log.Error("error encrypting secret value", "orgId", orgId, "type", typ, "namespace", namespace, "err", err)
 		return err
@@ -255,6 +255,9 @@ func (kv *SecretsKVStoreSQL) getDecryptedValue(ctx context.Context, item Item) (
 		return decryptedValue, err
 	}
 
+	if item.OrgId != nil {
+		ctx = secrets.ContextWithOrgScope(ctx, *item.OrgId)
+	}
 	decryptedValue, err = kv.secretsService.Decrypt(ctx, decodedValue)
 	if err != nil {
 		return decryptedValue, err
@@ -15,6 +15,7 @@ var (
 type dataKeyCacheEntry struct {
 	id         string
 	label      string
+	scope      string
 	dataKey    []byte
 	active     bool
 	expiration time.Time
@@ -8,10 +8,12 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/startup/providers"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -43,6 +45,13 @@ type SecretsService struct {
 	kmsProvidersService kmsproviders.Service
 
 	currentProviderID secrets.ProviderID
+	// previousProviderID is the provider used to encrypt new data keys
+	// when the current one fails, e.g. while migrating to a new KMS.
+	previousProviderID secrets.ProviderID
+
+	// orgScopedDataKeys enables a distinct data key per organization for
+	// secrets encrypted with secrets.WithOrgScope.
+	orgScopedDataKeys bool
 
 	log log.Logger
 }
@@ -55,12 +64,18 @@ func ProvideSecretsService(
 	features featuremgmt.FeatureToggles,
 	usageStats usagestats.Service,
 ) (*SecretsService, error) {
+	defer providers.Track("secrets")()
 	ttl := settings.KeyValue("security.encryption", "data_keys_cache_ttl").MustDuration(15 * time.Minute)
 
 	currentProviderID := kmsproviders.NormalizeProviderID(secrets.ProviderID(
 		settings.KeyValue("security", "encryption_provider").MustString(kmsproviders.Default),
 	))
 
+	var previousProviderID secrets.ProviderID
+	if previous := settings.KeyValue("security", "previous_encryption_provider").MustString(""); previous != "" {
+		previousProviderID = kmsproviders.NormalizeProviderID(secrets.ProviderID(previous))
+	}
+
 	s := &SecretsService{
 		store:               store,
 		enc:                 enc,
@@ -69,6 +84,8 @@ func ProvideSecretsService(
 		kmsProvidersService: kmsProvidersService,
 		dataKeyCache:        newDataKeyCache(ttl),
 		currentProviderID:   currentProviderID,
+		previousProviderID:  previousProviderID,
+		orgScopedDataKeys:   settings.KeyValue("security.encryption", "org_scoped_data_keys").MustBool(false),
 		features:            features,
 		log:                 log.New("secrets"),
 	}
@@ -86,6 +103,10 @@ func ProvideSecretsService(
 		return nil, fmt.Errorf("missing configuration for current encryption provider %s", currentProviderID)
 	}
 
+	if _, ok := s.providers[previousProviderID]; enabled && previousProviderID != "" && !ok {
+		return nil, fmt.Errorf("missing configuration for previous encryption provider %s", previousProviderID)
+	}
+
 	if !enabled && currentProviderID != kmsproviders.Default {
 		s.log.Warn("Changing encryption provider requires enabling envelope encryption feature")
 	}
@@ -170,7 +191,7 @@ func (s *SecretsService) EncryptWithDBSession(ctx context.Context, payload []byt
 	}()
 
 	// If encryption featuremgmt.FlagEnvelopeEncryption toggle is on, use envelope encryption
-	scope := opt()
+	scope := s.dataKeyScope(opt())
 	label := secrets.KeyLabel(scope, s.currentProviderID)
 
 	var id string
@@ -200,6 +221,15 @@ func (s *SecretsService) EncryptWithDBSession(ctx context.Context, payload []byt
 	return blob, nil
 }
 
+// dataKeyScope returns the scope of the data key used to encrypt a secret. Organization scopes
+// share the root level data key unless org scoped data keys are enabled.
+func (s *SecretsService) dataKeyScope(scope string) string {
+	if !s.orgScopedDataKeys && strings.HasPrefix(scope, "org:") {
+		return "root"
+	}
+	return scope
+}
+
 // currentDataKey looks up for current data key in cache or database by name, and decrypts it.
 // If there's no current data key in cache nor in database it generates a new random data key,
 // and stores it into both the in-memory cache and database (encrypted by the encryption provider).
@@ -259,6 +289,7 @@ func (s *SecretsService) dataKeyByLabel(ctx context.Context, label string) (stri
 	s.dataKeyCache.add(&dataKeyCacheEntry{
 		id:      dataKey.Id,
 		label:   dataKey.Label,
+		scope:   dataKey.Scope,
 		dataKey: decrypted,
 		active:  dataKey.Active,
 	})
@@ -274,14 +305,18 @@ func (s *SecretsService) newDataKey(ctx context.Context, label string, scope str
 		return "", nil, err
 	}
 
-	// 2.1 Find the encryption provider.
-	provider, exists := s.providers[s.currentProviderID]
-	if !exists {
-		return "", nil, fmt.Errorf("could not find encryption provider '%s'", s.currentProviderID)
+	// 2. Encrypt the data key.
+	providerID := s.currentProviderID
+	encrypted, err := s.encryptDataKey(ctx, providerID, dataKey)
+	if err != nil && s.previousProviderID != "" && s.previousProviderID != s.currentProviderID {
+		// Fail over to the previous provider, so that secrets can still be stored
+		// while the current one is unavailable. The data key records the provider
+		// which encrypted it, so it can be decrypted later on.
+		s.log.Warn("Failed to encrypt data key with current encryption provider, falling back to previous provider",
+			"current", s.currentProviderID, "previous", s.previousProviderID, "error", err)
+		providerID = s.previousProviderID
+		encrypted, err = s.encryptDataKey(ctx, providerID, dataKey)
 	}
-
-	// 2.2 Encrypt the data key.
-	encrypted, err := provider.Encrypt(ctx, dataKey)
 	if err != nil {
 		return "", nil, err
 	}
@@ -291,7 +326,7 @@ func (s *SecretsService) newDataKey(ctx context.Context, label string, scope str
 	dbDataKey := secrets.DataKey{
 		Active:        true,
 		Id:            id,
-		Provider:      s.currentProviderID,
+		Provider:      providerID,
 		EncryptedData: encrypted,
 		Label:         label,
 		Scope:         scope,
@@ -311,6 +346,7 @@ func (s *SecretsService) newDataKey(ctx context.Context, label string, scope str
 	s.dataKeyCache.add(&dataKeyCacheEntry{
 		id:      id,
 		label:   label,
+		scope:   scope,
 		dataKey: dataKey,
 		active:  true,
 	})
@@ -318,6 +354,15 @@ func (s *SecretsService) newDataKey(ctx context.Context, label string, scope str
 	return id, dataKey, nil
 }
 
+func (s *SecretsService) encryptDataKey(ctx context.Context, providerID secrets.ProviderID, dataKey []byte) ([]byte, error) {
+	provider, exists := s.providers[providerID]
+	if !exists {
+		return nil, fmt.Errorf("could not find encryption provider '%s'", providerID)
+	}
+
+	return provider.Encrypt(ctx, dataKey)
+}
+
 func newRandomDataKey() ([]byte, error) {
 	rawDataKey := make([]byte, 16)
 	_, err := rand.Read(rawDataKey)
@@ -360,23 +405,22 @@ func (s *SecretsService) Decrypt(ctx context.Context, payload []byte) ([]byte, e
 		secretKey := s.settings.KeyValue("security", "secret_key").Value()
 		dataKey = []byte(secretKey)
 	} else {
-		payload = payload[1:]
-		endOfKey := bytes.Index(payload, []byte{keyIdDelimiter})
-		if endOfKey == -1 {
-			err = fmt.Errorf("could not find valid key id in encrypted payload")
+		var keyId string
+		keyId, payload, err = splitEnvelopePayload(payload)
+		if err != nil {
 			return nil, err
 		}
-		b64Key := payload[:endOfKey]
-		payload = payload[endOfKey+1:]
-		keyId := make([]byte, b64.DecodedLen(len(b64Key)))
-		_, err = b64.Decode(keyId, b64Key)
+
+		var scope string
+		dataKey, scope, err = s.dataKeyById(ctx, keyId)
 		if err != nil {
+			s.log.Error("Failed to lookup data key by id", "id", keyId, "error", err)
 			return nil, err
 		}
 
-		dataKey, err = s.dataKeyById(ctx, string(keyId))
-		if err != nil {
-			s.log.Error("Failed to lookup data key by id", "id", string(keyId), "error", err)
+		if orgID, ok := secrets.OrgScopeFromContext(ctx); ok && strings.HasPrefix(scope, "org:") && scope != secrets.OrgScope(orgID) {
+			s.log.Warn("Refusing to decrypt a secret of another organization", "id", keyId, "scope", scope, "orgId", orgID)
+			err = secrets.ErrDataKeyScopeMismatch
 			return nil, err
 		}
 	}
@@ -387,6 +431,35 @@ func (s *SecretsService) Decrypt(ctx context.Context, payload []byte) ([]byte, e
 	return decrypted, err
 }
 
+// splitEnvelopePayload splits a payload encrypted with envelope encryption
+// into the id of its data key and the encrypted secret.
+func splitEnvelopePayload(payload []byte) (string, []byte, error) {
+	payload = payload[1:]
+	endOfKey := bytes.Index(payload, []byte{keyIdDelimiter})
+	if endOfKey == -1 {
+		return "", nil, fmt.Errorf("could not find valid key id in encrypted payload")
+	}
+
+	b64Key := payload[:endOfKey]
+	keyId := make([]byte, b64.DecodedLen(len(b64Key)))
+	if _, err := b64.Decode(keyId, b64Key); err != nil {
+		return "", nil, err
+	}
+
+	return string(keyId), payload[endOfKey+1:], nil
+}
+
+// DataKeyID returns the id of the data key used to encrypt the payload,
+// or an empty string if it was encrypted with the legacy secret key.
+func DataKeyID(payload []byte) (string, error) {
+	if len(payload) == 0 || payload[0] != keyIdDelimiter {
+		return "", nil
+	}
+
+	id, _, err := splitEnvelopePayload(payload)
+	return id, err
+}
+
 func (s *SecretsService) EncryptJsonData(ctx context.Context, kv map[string]string, opt secrets.EncryptionOptions) (map[string][]byte, error) {
 	return s.EncryptJsonDataWithDBSession(ctx, kv, opt, nil)
 }
@@ -431,40 +504,41 @@ func (s *SecretsService) GetDecryptedValue(ctx context.Context, sjd map[string][
 }
 
 // dataKeyById looks up for data key in cache.
-// Otherwise, it fetches it from database and returns it decrypted.
-func (s *SecretsService) dataKeyById(ctx context.Context, id string) ([]byte, error) {
+// Otherwise, it fetches it from database and returns it decrypted, together with its scope.
+func (s *SecretsService) dataKeyById(ctx context.Context, id string) ([]byte, string, error) {
 	// 0. Get decrypted data key from in-memory cache.
 	if entry, exists := s.dataKeyCache.getById(id); exists {
-		return entry.dataKey, nil
+		return entry.dataKey, entry.scope, nil
 	}
 
 	// 1. Get encrypted data key from database.
 	dataKey, err := s.store.GetDataKey(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// 2.1. Find the encryption provider.
 	provider, exists := s.providers[kmsproviders.NormalizeProviderID(dataKey.Provider)]
 	if !exists {
-		return nil, fmt.Errorf("could not find encryption provider '%s'", dataKey.Provider)
+		return nil, "", fmt.Errorf("could not find encryption provider '%s'", dataKey.Provider)
 	}
 
 	// 2.2. Encrypt the data key.
 	decrypted, err := provider.Decrypt(ctx, dataKey.EncryptedData)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// 3. Store the decrypted data key into the in-memory cache.
 	s.dataKeyCache.add(&dataKeyCacheEntry{
 		id:      dataKey.Id,
 		label:   dataKey.Label,
+		scope:   dataKey.Scope,
 		dataKey: decrypted,
 		active:  dataKey.Active,
 	})
 
-	return decrypted, nil
+	return decrypted, dataKey.Scope, nil
 }
 
 func (s *SecretsService) GetProviders() map[secrets.ProviderID]secrets.Provider {
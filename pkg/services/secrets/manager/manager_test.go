@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -81,6 +82,66 @@ func TestSecretsService_EnvelopeEncryption(t *testing.T) {
 	})
 }
 
+func TestSecretsService_OrgScopedDataKeys(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("datasource password")
+
+	t.Run("org scopes should share the root DEK when disabled", func(t *testing.T) {
+		store := database.ProvideSecretsStore(db.InitTestDB(t))
+		svc := SetupTestService(t, store)
+
+		_, err := svc.Encrypt(ctx, plaintext, secrets.WithOrgScope(1))
+		require.NoError(t, err)
+		_, err = svc.Encrypt(ctx, plaintext, secrets.WithOrgScope(2))
+		require.NoError(t, err)
+
+		keys, err := store.GetAllDataKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "root", keys[0].Scope)
+	})
+
+	t.Run("org scopes should use a DEK per org when enabled", func(t *testing.T) {
+		store := database.ProvideSecretsStore(db.InitTestDB(t))
+		svc := SetupTestService(t, store)
+		svc.orgScopedDataKeys = true
+
+		encrypted, err := svc.Encrypt(ctx, plaintext, secrets.WithOrgScope(1))
+		require.NoError(t, err)
+		_, err = svc.Encrypt(ctx, plaintext, secrets.WithOrgScope(2))
+		require.NoError(t, err)
+
+		keys, err := store.GetAllDataKeys(ctx)
+		require.NoError(t, err)
+		assert.Len(t, keys, 2)
+
+		decrypted, err := svc.Decrypt(secrets.ContextWithOrgScope(ctx, 1), encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		_, err = svc.Decrypt(secrets.ContextWithOrgScope(ctx, 2), encrypted)
+		assert.ErrorIs(t, err, secrets.ErrDataKeyScopeMismatch)
+
+		// Without an org in the context, e.g. when re-encrypting all secrets.
+		decrypted, err = svc.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("root DEK secrets should be decrypted for any org", func(t *testing.T) {
+		store := database.ProvideSecretsStore(db.InitTestDB(t))
+		svc := SetupTestService(t, store)
+		svc.orgScopedDataKeys = true
+
+		encrypted, err := svc.Encrypt(ctx, plaintext, secrets.WithoutScope())
+		require.NoError(t, err)
+
+		decrypted, err := svc.Decrypt(secrets.ContextWithOrgScope(ctx, 2), encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	})
+}
+
 func TestSecretsService_DataKeys(t *testing.T) {
 	store := database.ProvideSecretsStore(db.InitTestDB(t))
 	ctx := context.Background()
@@ -220,15 +281,65 @@ func TestSecretsService_UseCurrentProvider(t *testing.T) {
 		_, _ = svcDecrypt.Decrypt(context.Background(), encrypted)
 		assert.True(t, kms.fake.decryptCalled, "fake provider's decrypt should be called")
 	})
+
+	t.Run("Should fail over to the previous encryption provider when the current one cannot encrypt", func(t *testing.T) {
+		rawCfg := `
+		[security]
+		secret_key = sdDkslslld
+		encryption_provider = fakeProvider.v1
+		previous_encryption_provider = secretKey.v1
+		available_encryption_providers = fakeProvider.v1
+		`
+
+		raw, err := ini.Load([]byte(rawCfg))
+		require.NoError(t, err)
+
+		settings := &setting.OSSImpl{Cfg: &setting.Cfg{Raw: raw}}
+
+		encryptionService, err := encryptionservice.ProvideEncryptionService(encryptionprovider.Provider{}, &usagestats.UsageStatsMock{}, settings)
+		require.NoError(t, err)
+
+		features := featuremgmt.WithFeatures()
+		kms := newFakeKMS(osskmsproviders.ProvideService(encryptionService, settings, features))
+		kms.fake.encryptErr = errors.New("kms unavailable")
+		store := database.ProvideSecretsStore(db.InitTestDB(t))
+
+		svc, err := ProvideSecretsService(store, &kms, encryptionService, settings, features, &usagestats.UsageStatsMock{T: t})
+		require.NoError(t, err)
+		assert.Equal(t, secrets.ProviderID("secretKey.v1"), svc.previousProviderID)
+
+		plaintext := []byte("very secret string")
+		encrypted, err := svc.Encrypt(context.Background(), plaintext, secrets.WithoutScope())
+		require.NoError(t, err)
+		assert.True(t, kms.fake.encryptCalled)
+
+		keys, err := store.GetAllDataKeys(context.Background())
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, secrets.ProviderID("secretKey.v1"), keys[0].Provider)
+
+		// A new service has no data key cached, so the data key is decrypted by the previous provider.
+		svcDecrypt, err := ProvideSecretsService(store, &kms, encryptionService, settings, features, &usagestats.UsageStatsMock{T: t})
+		require.NoError(t, err)
+
+		decrypted, err := svcDecrypt.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+		assert.False(t, kms.fake.decryptCalled)
+	})
 }
 
 type fakeProvider struct {
 	encryptCalled bool
 	decryptCalled bool
+	encryptErr    error
 }
 
 func (p *fakeProvider) Encrypt(_ context.Context, _ []byte) ([]byte, error) {
 	p.encryptCalled = true
+	if p.encryptErr != nil {
+		return nil, p.encryptErr
+	}
 	return []byte{}, nil
 }
 
@@ -401,3 +512,29 @@ func TestSecretsService_Decrypt(t *testing.T) {
 		assert.Equal(t, []byte("grafana"), decrypted)
 	})
 }
+
+func TestDataKeyID(t *testing.T) {
+	store := database.ProvideSecretsStore(db.InitTestDB(t))
+	svc := SetupTestService(t, store)
+	ctx := context.Background()
+
+	encrypted, err := svc.Encrypt(ctx, []byte("very secret string"), secrets.WithoutScope())
+	require.NoError(t, err)
+
+	keys, err := store.GetAllDataKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	id, err := DataKeyID(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, keys[0].Id, id)
+
+	legacy, err := svc.enc.Encrypt(ctx, []byte("very secret string"), "secret")
+	require.NoError(t, err)
+	id, err = DataKeyID(legacy)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+
+	_, err = DataKeyID([]byte("#invalid"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package migrator
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+// visitFunc is called for every secret stored in a target with the id of its row,
+// its field for columns holding several secrets and its encrypted value.
+type visitFunc func(id int, field string, payload []byte, err error)
+
+// GetInventory lists the secrets of every re-encryption target with the data key encrypting them.
+func (m *SecretsMigrator) GetInventory(ctx context.Context) (*secrets.Inventory, error) {
+	var dataKeys []*secrets.DataKey
+	if err := m.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("data_keys").Find(&dataKeys)
+	}); err != nil {
+		return nil, err
+	}
+
+	keysByID := make(map[string]*secrets.DataKey, len(dataKeys))
+	for _, k := range dataKeys {
+		keysByID[k.Id] = k
+	}
+
+	inventory := &secrets.Inventory{
+		Summary: secrets.InventorySummary{ByProvider: map[secrets.ProviderID]int{}},
+		Items:   []secrets.InventoryItem{},
+	}
+
+	for _, t := range reencryptTargets() {
+		table, column := t.target()
+
+		err := t.visit(ctx, m.sqlStore, m.batchSize(), func(id int, field string, payload []byte, err error) {
+			item := secrets.InventoryItem{Table: table, Column: column, ID: int64(id), Field: field}
+			if err == nil {
+				item = describeSecret(item, payload, keysByID)
+			} else {
+				item.Error = err.Error()
+			}
+
+			inventory.Items = append(inventory.Items, item)
+			inventory.Summary.Total++
+			switch {
+			case item.Error != "":
+				inventory.Summary.Errors++
+			case item.Provider == secrets.LegacyProvider:
+				inventory.Summary.Legacy++
+				inventory.Summary.ByProvider[item.Provider]++
+			default:
+				inventory.Summary.ByProvider[item.Provider]++
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets of %s.%s: %w", table, column, err)
+		}
+	}
+
+	return inventory, nil
+}
+
+func describeSecret(item secrets.InventoryItem, payload []byte, keysByID map[string]*secrets.DataKey) secrets.InventoryItem {
+	id, err := manager.DataKeyID(payload)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+
+	if id == "" {
+		item.Provider = secrets.LegacyProvider
+		return item
+	}
+
+	item.DataKeyID = id
+	dataKey, ok := keysByID[id]
+	if !ok {
+		item.Error = secrets.ErrDataKeyNotFound.Error()
+		return item
+	}
+
+	created := dataKey.Created
+	item.Provider = dataKey.Provider
+	item.DataKeyLabel = dataKey.Label
+	item.DataKeyActive = dataKey.Active
+	item.LastRotated = &created
+	return item
+}
+
+func (s simpleSecret) visit(ctx context.Context, sqlStore db.DB, batchSize int, fn visitFunc) error {
+	var lastID int
+
+	for {
+		var rows []struct {
+			Id     int
+			Secret []byte
+		}
+
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(s.tableName).Select(fmt.Sprintf("id, %s as secret", s.columnName)).
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&rows)
+		}); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if len(row.Secret) > 0 {
+				fn(row.Id, "", row.Secret, nil)
+			}
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].Id
+	}
+}
+
+func (s b64Secret) visit(ctx context.Context, sqlStore db.DB, batchSize int, fn visitFunc) error {
+	var lastID int
+
+	for {
+		var rows []struct {
+			Id     int
+			Secret string
+		}
+
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(s.tableName).Select(fmt.Sprintf("id, %s as secret", s.columnName)).
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&rows)
+		}); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if len(row.Secret) == 0 {
+				continue
+			}
+			decoded, err := s.encoding.DecodeString(row.Secret)
+			fn(row.Id, "", decoded, err)
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].Id
+	}
+}
+
+func (s jsonSecret) visit(ctx context.Context, sqlStore db.DB, batchSize int, fn visitFunc) error {
+	var lastID int
+
+	for {
+		var rows []struct {
+			Id             int
+			SecureJsonData map[string][]byte
+		}
+
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(s.tableName).Cols("id", "secure_json_data").
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&rows)
+		}); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			for field, payload := range row.SecureJsonData {
+				fn(row.Id, field, payload, nil)
+			}
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].Id
+	}
+}
+
+func (s alertingSecret) visit(ctx context.Context, sqlStore db.DB, batchSize int, fn visitFunc) error {
+	var lastID int
+
+	for {
+		var results []struct {
+			Id                        int
+			AlertmanagerConfiguration string
+		}
+
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table("alert_configuration").Cols("id", "alertmanager_configuration").
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&results)
+		}); err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			postableUserConfig, err := notifier.Load([]byte(result.AlertmanagerConfiguration))
+			if err != nil {
+				fn(result.Id, "", nil, err)
+				continue
+			}
+
+			for _, receiver := range postableUserConfig.AlertmanagerConfig.Receivers {
+				for _, gmr := range receiver.GrafanaManagedReceivers {
+					for k, v := range gmr.SecureSettings {
+						decoded, err := base64.StdEncoding.DecodeString(v)
+						fn(result.Id, gmr.UID+"."+k, decoded, err)
+					}
+				}
+			}
+		}
+
+		if len(results) < batchSize {
+			return nil
+		}
+		lastID = results[len(results)-1].Id
+	}
+}
@@ -3,12 +3,17 @@ package migrator
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/semaphore"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -19,6 +24,11 @@ type SecretsMigrator struct {
 	sqlStore      db.DB
 	settings      setting.Provider
 	features      featuremgmt.FeatureToggles
+	serverLock    *serverlock.ServerLockService
+	semaphore     semaphore.Service
+
+	rotationMtx sync.Mutex
+	rotation    secrets.RotationStatus
 }
 
 func ProvideSecretsMigrator(
@@ -27,6 +37,8 @@ func ProvideSecretsMigrator(
 	sqlStore db.DB,
 	settings setting.Provider,
 	features featuremgmt.FeatureToggles,
+	serverLock *serverlock.ServerLockService,
+	semaphore semaphore.Service,
 ) *SecretsMigrator {
 	return &SecretsMigrator{
 		encryptionSrv: encryptionSrv,
@@ -34,6 +46,9 @@ func ProvideSecretsMigrator(
 		sqlStore:      sqlStore,
 		settings:      settings,
 		features:      features,
+		serverLock:    serverLock,
+		semaphore:     semaphore,
+		rotation:      secrets.RotationStatus{State: secrets.RotationStateIdle, Targets: []secrets.RotationTargetProgress{}},
 	}
 }
 
@@ -43,23 +58,17 @@ func (m *SecretsMigrator) ReEncryptSecrets(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
-	toReencrypt := []interface {
-		reencrypt(context.Context, *manager.SecretsService, db.DB) bool
-	}{
-		simpleSecret{tableName: "dashboard_snapshot", columnName: "dashboard_encrypted"},
-		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_access_token"}, encoding: base64.StdEncoding},
-		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_refresh_token"}, encoding: base64.StdEncoding},
-		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_token_type"}, encoding: base64.StdEncoding},
-		b64Secret{simpleSecret: simpleSecret{tableName: "secrets", columnName: "value"}, hasUpdatedColumn: true, encoding: base64.RawStdEncoding},
-		jsonSecret{tableName: "data_source"},
-		jsonSecret{tableName: "plugin_setting"},
-		alertingSecret{},
+	lease, err := m.acquireReEncryptionSlot(ctx)
+	if err != nil {
+		return false, err
 	}
+	defer lease.Release()
+	ctx = lease.Context()
 
 	var anyFailure bool
 
-	for _, r := range toReencrypt {
-		if success := r.reencrypt(ctx, m.secretsSrv, m.sqlStore); !success {
+	for _, r := range reencryptTargets() {
+		if success := r.reencrypt(ctx, m.secretsSrv, m.sqlStore, m.batchSize(), func(int, int) {}); !success {
 			anyFailure = true
 		}
 	}
@@ -67,6 +76,26 @@ func (m *SecretsMigrator) ReEncryptSecrets(ctx context.Context) (bool, error) {
 	return !anyFailure, nil
 }
 
+func (m *SecretsMigrator) ReEncryptDataKeys(ctx context.Context) error {
+	lease, err := m.acquireReEncryptionSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+
+	return m.secretsSrv.ReEncryptDataKeys(lease.Context())
+}
+
+// acquireReEncryptionSlot limits the number of nodes re-encrypting secrets at the same time, since
+// re-encrypting reads and writes every stored secret.
+func (m *SecretsMigrator) acquireReEncryptionSlot(ctx context.Context) (*semaphore.Lease, error) {
+	lease, err := m.semaphore.TryAcquire(ctx, semaphore.OperationSecretsReEncryption)
+	if errors.Is(err, semaphore.ErrLimitReached) {
+		return nil, secrets.ErrReEncryptionLimitReached
+	}
+	return lease, err
+}
+
 func (m *SecretsMigrator) RollBackSecrets(ctx context.Context) (bool, error) {
 	err := m.initProvidersIfNeeded()
 	if err != nil {
@@ -80,7 +109,7 @@ func (m *SecretsMigrator) RollBackSecrets(ctx context.Context) (bool, error) {
 		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_access_token"}, encoding: base64.StdEncoding},
 		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_refresh_token"}, encoding: base64.StdEncoding},
 		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_token_type"}, encoding: base64.StdEncoding},
-		b64Secret{simpleSecret: simpleSecret{tableName: "secrets", columnName: "value"}, hasUpdatedColumn: true, encoding: base64.RawStdEncoding},
+		b64Secret{simpleSecret: simpleSecret{tableName: "secrets", columnName: "value"}, hasUpdatedColumn: true, hasOrgIdColumn: true, encoding: base64.RawStdEncoding},
 		jsonSecret{tableName: "data_source"},
 		jsonSecret{tableName: "plugin_setting"},
 		alertingSecret{},
@@ -128,6 +157,30 @@ func (m *SecretsMigrator) initProvidersIfNeeded() error {
 	return nil
 }
 
+// reencryptTarget is a table column holding secrets encrypted with the secrets service.
+type reencryptTarget interface {
+	target() (table string, column string)
+	reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB, batchSize int, progress progressFunc) bool
+	visit(ctx context.Context, sqlStore db.DB, batchSize int, fn visitFunc) error
+}
+
+// progressFunc is called after every batch of rows re-encrypted with the number of rows
+// processed in the batch and how many of them failed.
+type progressFunc func(processed int, failed int)
+
+func reencryptTargets() []reencryptTarget {
+	return []reencryptTarget{
+		simpleSecret{tableName: "dashboard_snapshot", columnName: "dashboard_encrypted"},
+		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_access_token"}, encoding: base64.StdEncoding},
+		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_refresh_token"}, encoding: base64.StdEncoding},
+		b64Secret{simpleSecret: simpleSecret{tableName: "user_auth", columnName: "o_auth_token_type"}, encoding: base64.StdEncoding},
+		b64Secret{simpleSecret: simpleSecret{tableName: "secrets", columnName: "value"}, hasUpdatedColumn: true, hasOrgIdColumn: true, encoding: base64.RawStdEncoding},
+		jsonSecret{tableName: "data_source"},
+		jsonSecret{tableName: "plugin_setting"},
+		alertingSecret{},
+	}
+}
+
 type simpleSecret struct {
 	tableName  string
 	columnName string
@@ -136,7 +189,9 @@ type simpleSecret struct {
 type b64Secret struct {
 	simpleSecret
 	hasUpdatedColumn bool
-	encoding         *base64.Encoding
+	// hasOrgIdColumn re-encrypts the secrets with the data keys of their organizations.
+	hasOrgIdColumn bool
+	encoding       *base64.Encoding
 }
 
 type jsonSecret struct {
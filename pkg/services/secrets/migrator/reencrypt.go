@@ -12,51 +12,70 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets/manager"
 )
 
-func (s simpleSecret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB) bool {
-	var rows []struct {
-		Id     int
-		Secret []byte
-	}
-
-	if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		return sess.Table(s.tableName).Select(fmt.Sprintf("id, %s as secret", s.columnName)).Find(&rows)
-	}); err != nil {
-		logger.Warn("Could not find any secret to re-encrypt", "table", s.tableName)
-		return false
-	}
+func (s simpleSecret) target() (string, string) {
+	return s.tableName, s.columnName
+}
 
+func (s simpleSecret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB, batchSize int, progress progressFunc) bool {
 	var anyFailure bool
+	var lastID int
 
-	for _, row := range rows {
-		if len(row.Secret) == 0 {
-			continue
+	for {
+		var rows []struct {
+			Id     int
+			Secret []byte
 		}
 
-		err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-			decrypted, err := secretsSrv.Decrypt(ctx, row.Secret)
-			if err != nil {
-				logger.Warn("Could not decrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
-				return err
-			}
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(s.tableName).Select(fmt.Sprintf("id, %s as secret", s.columnName)).
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&rows)
+		}); err != nil {
+			logger.Warn("Could not find any secret to re-encrypt", "table", s.tableName)
+			return false
+		}
 
-			encrypted, err := secretsSrv.EncryptWithDBSession(ctx, decrypted, secrets.WithoutScope(), sess.Session)
-			if err != nil {
-				logger.Warn("Could not encrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
-				return err
+		failed := 0
+		for _, row := range rows {
+			if len(row.Secret) == 0 {
+				continue
 			}
 
-			updateSQL := fmt.Sprintf("UPDATE %s SET %s = ?, updated = ? WHERE id = ?", s.tableName, s.columnName)
-			if _, err = sess.Exec(updateSQL, encrypted, nowInUTC(), row.Id); err != nil {
-				logger.Warn("Could not update secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
-				return err
-			}
+			err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				decrypted, err := secretsSrv.Decrypt(ctx, row.Secret)
+				if err != nil {
+					logger.Warn("Could not decrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
 
-			return nil
-		})
+				encrypted, err := secretsSrv.EncryptWithDBSession(ctx, decrypted, secrets.WithoutScope(), sess.Session)
+				if err != nil {
+					logger.Warn("Could not encrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
 
-		if err != nil {
+				updateSQL := fmt.Sprintf("UPDATE %s SET %s = ?, updated = ? WHERE id = ?", s.tableName, s.columnName)
+				if _, err = sess.Exec(updateSQL, encrypted, nowInUTC(), row.Id); err != nil {
+					logger.Warn("Could not update secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
+
+				return nil
+			})
+
+			if err != nil {
+				failed++
+			}
+		}
+
+		if failed > 0 {
 			anyFailure = true
 		}
+		progress(len(rows), failed)
+
+		if len(rows) < batchSize {
+			break
+		}
+		lastID = rows[len(rows)-1].Id
 	}
 
 	if anyFailure {
@@ -68,65 +87,91 @@ func (s simpleSecret) reencrypt(ctx context.Context, secretsSrv *manager.Secrets
 	return !anyFailure
 }
 
-func (s b64Secret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB) bool {
-	var rows []struct {
-		Id     int
-		Secret string
-	}
+func (s b64Secret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB, batchSize int, progress progressFunc) bool {
+	var anyFailure bool
+	var lastID int
 
-	if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		return sess.Table(s.tableName).Select(fmt.Sprintf("id, %s as secret", s.columnName)).Find(&rows)
-	}); err != nil {
-		logger.Warn("Could not find any secret to re-encrypt", "table", s.tableName)
-		return false
-	}
+	for {
+		var rows []struct {
+			Id     int
+			Secret string
+			OrgId  int64
+		}
 
-	var anyFailure bool
+		columns := fmt.Sprintf("id, %s as secret", s.columnName)
+		if s.hasOrgIdColumn {
+			columns += ", org_id"
+		}
 
-	for _, row := range rows {
-		if len(row.Secret) == 0 {
-			continue
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(s.tableName).Select(columns).
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&rows)
+		}); err != nil {
+			logger.Warn("Could not find any secret to re-encrypt", "table", s.tableName)
+			return false
 		}
 
-		err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-			decoded, err := s.encoding.DecodeString(row.Secret)
-			if err != nil {
-				logger.Warn("Could not decode base64-encoded secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
-				return err
+		failed := 0
+		for _, row := range rows {
+			if len(row.Secret) == 0 {
+				continue
 			}
 
-			decrypted, err := secretsSrv.Decrypt(ctx, decoded)
-			if err != nil {
-				logger.Warn("Could not decrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
-				return err
-			}
+			err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				decoded, err := s.encoding.DecodeString(row.Secret)
+				if err != nil {
+					logger.Warn("Could not decode base64-encoded secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
 
-			encrypted, err := secretsSrv.EncryptWithDBSession(ctx, decrypted, secrets.WithoutScope(), sess.Session)
-			if err != nil {
-				logger.Warn("Could not encrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
-				return err
-			}
+				decrypted, err := secretsSrv.Decrypt(ctx, decoded)
+				if err != nil {
+					logger.Warn("Could not decrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
 
-			encoded := s.encoding.EncodeToString(encrypted)
-			if s.hasUpdatedColumn {
-				updateSQL := fmt.Sprintf("UPDATE %s SET %s = ?, updated = ? WHERE id = ?", s.tableName, s.columnName)
-				_, err = sess.Exec(updateSQL, encoded, nowInUTC(), row.Id)
-			} else {
-				updateSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", s.tableName, s.columnName)
-				_, err = sess.Exec(updateSQL, encoded, row.Id)
-			}
+				opt := secrets.WithoutScope()
+				if s.hasOrgIdColumn {
+					opt = secrets.WithOrgScope(row.OrgId)
+				}
+
+				encrypted, err := secretsSrv.EncryptWithDBSession(ctx, decrypted, opt, sess.Session)
+				if err != nil {
+					logger.Warn("Could not encrypt secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
+
+				encoded := s.encoding.EncodeToString(encrypted)
+				if s.hasUpdatedColumn {
+					updateSQL := fmt.Sprintf("UPDATE %s SET %s = ?, updated = ? WHERE id = ?", s.tableName, s.columnName)
+					_, err = sess.Exec(updateSQL, encoded, nowInUTC(), row.Id)
+				} else {
+					updateSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", s.tableName, s.columnName)
+					_, err = sess.Exec(updateSQL, encoded, row.Id)
+				}
+
+				if err != nil {
+					logger.Warn("Could not update secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
+
+				return nil
+			})
 
 			if err != nil {
-				logger.Warn("Could not update secret while re-encrypting it", "table", s.tableName, "id", row.Id, "error", err)
-				return err
+				failed++
 			}
+		}
 
-			return nil
-		})
-
-		if err != nil {
+		if failed > 0 {
 			anyFailure = true
 		}
+		progress(len(rows), failed)
+
+		if len(rows) < batchSize {
+			break
+		}
+		lastID = rows[len(rows)-1].Id
 	}
 
 	if anyFailure {
@@ -138,55 +183,75 @@ func (s b64Secret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsSer
 	return !anyFailure
 }
 
-func (s jsonSecret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB) bool {
-	var rows []struct {
-		Id             int
-		SecureJsonData map[string][]byte
-	}
-
-	if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		return sess.Table(s.tableName).Cols("id", "secure_json_data").Find(&rows)
-	}); err != nil {
-		logger.Warn("Could not find any secret to re-encrypt", "table", s.tableName)
-		return false
-	}
+func (s jsonSecret) target() (string, string) {
+	return s.tableName, "secure_json_data"
+}
 
+func (s jsonSecret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB, batchSize int, progress progressFunc) bool {
 	var anyFailure bool
+	var lastID int
 
-	for _, row := range rows {
-		if len(row.SecureJsonData) == 0 {
-			continue
+	for {
+		var rows []struct {
+			Id             int
+			OrgId          int64
+			SecureJsonData map[string][]byte
 		}
 
-		err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-			decrypted, err := secretsSrv.DecryptJsonData(ctx, row.SecureJsonData)
-			if err != nil {
-				logger.Warn("Could not decrypt secrets while re-encrypting them", "table", s.tableName, "id", row.Id, "error", err)
-				return err
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(s.tableName).Cols("id", "org_id", "secure_json_data").
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&rows)
+		}); err != nil {
+			logger.Warn("Could not find any secret to re-encrypt", "table", s.tableName)
+			return false
+		}
+
+		failed := 0
+		for _, row := range rows {
+			if len(row.SecureJsonData) == 0 {
+				continue
 			}
 
-			toUpdate := struct {
-				SecureJsonData map[string][]byte
-				Updated        string
-			}{Updated: nowInUTC()}
+			err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				decrypted, err := secretsSrv.DecryptJsonData(ctx, row.SecureJsonData)
+				if err != nil {
+					logger.Warn("Could not decrypt secrets while re-encrypting them", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
 
-			toUpdate.SecureJsonData, err = secretsSrv.EncryptJsonDataWithDBSession(ctx, decrypted, secrets.WithoutScope(), sess.Session)
-			if err != nil {
-				logger.Warn("Could not re-encrypt secrets", "table", s.tableName, "id", row.Id, "error", err)
-				return err
-			}
+				toUpdate := struct {
+					SecureJsonData map[string][]byte
+					Updated        string
+				}{Updated: nowInUTC()}
 
-			if _, err := sess.Table(s.tableName).Where("id = ?", row.Id).Update(toUpdate); err != nil {
-				logger.Warn("Could not update secrets while re-encrypting them", "table", s.tableName, "id", row.Id, "error", err)
-				return err
-			}
+				toUpdate.SecureJsonData, err = secretsSrv.EncryptJsonDataWithDBSession(ctx, decrypted, secrets.WithOrgScope(row.OrgId), sess.Session)
+				if err != nil {
+					logger.Warn("Could not re-encrypt secrets", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
+
+				if _, err := sess.Table(s.tableName).Where("id = ?", row.Id).Update(toUpdate); err != nil {
+					logger.Warn("Could not update secrets while re-encrypting them", "table", s.tableName, "id", row.Id, "error", err)
+					return err
+				}
 
-			return nil
-		})
+				return nil
+			})
 
-		if err != nil {
+			if err != nil {
+				failed++
+			}
+		}
+
+		if failed > 0 {
 			anyFailure = true
 		}
+		progress(len(rows), failed)
+
+		if len(rows) < batchSize {
+			break
+		}
+		lastID = rows[len(rows)-1].Id
 	}
 
 	if anyFailure {
@@ -198,76 +263,94 @@ func (s jsonSecret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsSe
 	return !anyFailure
 }
 
-func (s alertingSecret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB) bool {
-	var results []struct {
-		Id                        int
-		AlertmanagerConfiguration string
-	}
-
-	selectSQL := "SELECT id, alertmanager_configuration FROM alert_configuration"
-	if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		return sess.SQL(selectSQL).Find(&results)
-	}); err != nil {
-		logger.Warn("Could not find any alert_configuration secret to re-encrypt")
-		return false
-	}
+func (s alertingSecret) target() (string, string) {
+	return "alert_configuration", "alertmanager_configuration"
+}
 
+func (s alertingSecret) reencrypt(ctx context.Context, secretsSrv *manager.SecretsService, sqlStore db.DB, batchSize int, progress progressFunc) bool {
 	var anyFailure bool
+	var lastID int
 
-	for _, result := range results {
-		result := result
+	for {
+		var results []struct {
+			Id                        int
+			AlertmanagerConfiguration string
+		}
 
-		err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-			postableUserConfig, err := notifier.Load([]byte(result.AlertmanagerConfiguration))
-			if err != nil {
-				logger.Warn("Could not load alert_configuration while re-encrypting it", "id", result.Id, "error", err)
-				return err
-			}
+		if err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table("alert_configuration").Cols("id", "alertmanager_configuration").
+				Where("id > ?", lastID).OrderBy("id").Limit(batchSize).Find(&results)
+		}); err != nil {
+			logger.Warn("Could not find any alert_configuration secret to re-encrypt")
+			return false
+		}
 
-			for _, receiver := range postableUserConfig.AlertmanagerConfig.Receivers {
-				for _, gmr := range receiver.GrafanaManagedReceivers {
-					for k, v := range gmr.SecureSettings {
-						decoded, err := base64.StdEncoding.DecodeString(v)
-						if err != nil {
-							logger.Warn("Could not decode base64-encoded alert_configuration secret", "id", result.Id, "key", k, "error", err)
-							return err
-						}
+		failed := 0
+		for _, result := range results {
+			result := result
 
-						decrypted, err := secretsSrv.Decrypt(ctx, decoded)
-						if err != nil {
-							logger.Warn("Could not decrypt alert_configuration secret", "id", result.Id, "key", k, "error", err)
-							return err
-						}
+			err := sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				postableUserConfig, err := notifier.Load([]byte(result.AlertmanagerConfiguration))
+				if err != nil {
+					logger.Warn("Could not load alert_configuration while re-encrypting it", "id", result.Id, "error", err)
+					return err
+				}
 
-						reencrypted, err := secretsSrv.EncryptWithDBSession(ctx, decrypted, secrets.WithoutScope(), sess.Session)
-						if err != nil {
-							logger.Warn("Could not re-encrypt alert_configuration secret", "id", result.Id, "key", k, "error", err)
-							return err
+				for _, receiver := range postableUserConfig.AlertmanagerConfig.Receivers {
+					for _, gmr := range receiver.GrafanaManagedReceivers {
+						for k, v := range gmr.SecureSettings {
+							decoded, err := base64.StdEncoding.DecodeString(v)
+							if err != nil {
+								logger.Warn("Could not decode base64-encoded alert_configuration secret", "id", result.Id, "key", k, "error", err)
+								return err
+							}
+
+							decrypted, err := secretsSrv.Decrypt(ctx, decoded)
+							if err != nil {
+								logger.Warn("Could not decrypt alert_configuration secret", "id", result.Id, "key", k, "error", err)
+								return err
+							}
+
+							reencrypted, err := secretsSrv.EncryptWithDBSession(ctx, decrypted, secrets.WithoutScope(), sess.Session)
+							if err != nil {
+								logger.Warn("Could not re-encrypt alert_configuration secret", "id", result.Id, "key", k, "error", err)
+								return err
+							}
+
+							gmr.SecureSettings[k] = base64.StdEncoding.EncodeToString(reencrypted)
 						}
-
-						gmr.SecureSettings[k] = base64.StdEncoding.EncodeToString(reencrypted)
 					}
 				}
-			}
 
-			marshalled, err := json.Marshal(postableUserConfig)
-			if err != nil {
-				logger.Warn("Could not marshal alert_configuration while re-encrypting it", "id", result.Id, "error", err)
-				return err
-			}
+				marshalled, err := json.Marshal(postableUserConfig)
+				if err != nil {
+					logger.Warn("Could not marshal alert_configuration while re-encrypting it", "id", result.Id, "error", err)
+					return err
+				}
 
-			result.AlertmanagerConfiguration = string(marshalled)
-			if _, err := sess.Table("alert_configuration").Where("id = ?", result.Id).Update(&result); err != nil {
-				logger.Warn("Could not update alert_configuration secret while re-encrypting it", "id", result.Id, "error", err)
-				return err
-			}
+				result.AlertmanagerConfiguration = string(marshalled)
+				if _, err := sess.Table("alert_configuration").Where("id = ?", result.Id).Update(&result); err != nil {
+					logger.Warn("Could not update alert_configuration secret while re-encrypting it", "id", result.Id, "error", err)
+					return err
+				}
 
-			return nil
-		})
+				return nil
+			})
 
-		if err != nil {
+			if err != nil {
+				failed++
+			}
+		}
+
+		if failed > 0 {
 			anyFailure = true
 		}
+		progress(len(results), failed)
+
+		if len(results) < batchSize {
+			break
+		}
+		lastID = results[len(results)-1].Id
 	}
 
 	if anyFailure {
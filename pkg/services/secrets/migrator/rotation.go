@@ -0,0 +1,207 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+const (
+	defaultReEncryptionBatchSize = 100
+
+	// rotationLockName is held while a rotation runs so that instances sharing the database
+	// never re-encrypt the secrets at the same time.
+	rotationLockName = "secrets-rotation"
+	// rotationLockTimeout is how long another instance waits before taking over the lock
+	// of a rotation that never released it, e.g. because the instance running it crashed.
+	rotationLockTimeout = 6 * time.Hour
+	// scheduledRotationLockName makes sure a scheduled rotation starts only once per
+	// interval across instances.
+	scheduledRotationLockName = "secrets-scheduled-rotation"
+	rotationScheduleTick      = 10 * time.Minute
+)
+
+func (m *SecretsMigrator) batchSize() int {
+	size, err := strconv.Atoi(m.settings.KeyValue("security.encryption", "reencryption_batch_size").MustString(""))
+	if err != nil || size <= 0 {
+		return defaultReEncryptionBatchSize
+	}
+	return size
+}
+
+func (m *SecretsMigrator) rotationInterval() time.Duration {
+	value := m.settings.KeyValue("security.encryption", "data_keys_rotation_interval").MustString("")
+	if value == "" {
+		return 0
+	}
+
+	interval, err := gtime.ParseDuration(value)
+	if err != nil {
+		logger.Error("Invalid data_keys_rotation_interval, scheduled secrets rotation is disabled", "value", value, "error", err)
+		return 0
+	}
+	return interval
+}
+
+// IsDisabled disables the scheduled rotation unless data_keys_rotation_interval is set.
+// Rotations can still be started through the admin API.
+func (m *SecretsMigrator) IsDisabled() bool {
+	return m.rotationInterval() <= 0
+}
+
+// Run starts a rotation every data_keys_rotation_interval.
+func (m *SecretsMigrator) Run(ctx context.Context) error {
+	interval := m.rotationInterval()
+	tick := rotationScheduleTick
+	if interval < tick {
+		tick = interval
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			err := m.serverLock.LockAndExecute(ctx, scheduledRotationLockName, interval, func(ctx context.Context) {
+				if _, err := m.RotateSecrets(ctx, secrets.RotationTriggerScheduled); err != nil {
+					logger.Warn("Could not start scheduled secrets rotation", "error", err)
+				}
+			})
+			if err != nil {
+				logger.Error("Failed to schedule secrets rotation", "error", err)
+			}
+		}
+	}
+}
+
+// RotateSecrets starts a rotation in the background and returns its initial status.
+func (m *SecretsMigrator) RotateSecrets(_ context.Context, trigger secrets.RotationTrigger) (secrets.RotationStatus, error) {
+	m.rotationMtx.Lock()
+	defer m.rotationMtx.Unlock()
+
+	if m.rotation.State == secrets.RotationStateRunning {
+		return m.copyRotationStatus(), secrets.ErrRotationInProgress
+	}
+
+	now := time.Now()
+	m.rotation = secrets.RotationStatus{
+		State:     secrets.RotationStateRunning,
+		Trigger:   trigger,
+		StartedAt: &now,
+		Targets:   []secrets.RotationTargetProgress{},
+	}
+
+	// The rotation outlives the request which started it.
+	go m.rotate(context.Background())
+
+	return m.copyRotationStatus(), nil
+}
+
+// GetRotationStatus returns the status of the last rotation started by this instance.
+func (m *SecretsMigrator) GetRotationStatus() secrets.RotationStatus {
+	m.rotationMtx.Lock()
+	defer m.rotationMtx.Unlock()
+
+	return m.copyRotationStatus()
+}
+
+func (m *SecretsMigrator) copyRotationStatus() secrets.RotationStatus {
+	status := m.rotation
+	status.Targets = append([]secrets.RotationTargetProgress{}, m.rotation.Targets...)
+	return status
+}
+
+func (m *SecretsMigrator) rotate(ctx context.Context) {
+	var err error
+	lockErr := m.serverLock.LockExecuteAndRelease(ctx, rotationLockName, rotationLockTimeout, func(ctx context.Context) {
+		lease, acquireErr := m.acquireReEncryptionSlot(ctx)
+		if acquireErr != nil {
+			err = acquireErr
+			return
+		}
+		defer lease.Release()
+		err = m.rotateLocked(lease.Context())
+	})
+	var lockExistsErr *serverlock.ServerLockExistsError
+	if errors.As(lockErr, &lockExistsErr) {
+		err = fmt.Errorf("another instance is rotating the secrets: %w", lockErr)
+	} else if lockErr != nil {
+		err = lockErr
+	}
+
+	m.rotationMtx.Lock()
+	defer m.rotationMtx.Unlock()
+
+	now := time.Now()
+	m.rotation.FinishedAt = &now
+	if err != nil {
+		logger.Error("Secrets rotation failed", "error", err)
+		m.rotation.State = secrets.RotationStateFailed
+		m.rotation.Error = err.Error()
+		return
+	}
+
+	logger.Info("Secrets rotation finished successfully")
+	m.rotation.State = secrets.RotationStateSucceeded
+}
+
+func (m *SecretsMigrator) rotateLocked(ctx context.Context) error {
+	logger.Info("Secrets rotation started")
+
+	if err := m.initProvidersIfNeeded(); err != nil {
+		return err
+	}
+
+	// Disabling the data keys makes the re-encryption below create and use new ones.
+	if err := m.secretsSrv.RotateDataKeys(ctx); err != nil {
+		return fmt.Errorf("failed to rotate data keys: %w", err)
+	}
+
+	var anyFailure bool
+	batchSize := m.batchSize()
+
+	for _, t := range reencryptTargets() {
+		table, column := t.target()
+
+		var total int64
+		if err := m.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			var err error
+			total, err = sess.Table(table).Count()
+			return err
+		}); err != nil {
+			logger.Warn("Could not count secrets to re-encrypt", "table", table, "error", err)
+		}
+
+		m.rotationMtx.Lock()
+		idx := len(m.rotation.Targets)
+		m.rotation.Targets = append(m.rotation.Targets, secrets.RotationTargetProgress{Table: table, Column: column, Total: total})
+		m.rotationMtx.Unlock()
+
+		success := t.reencrypt(ctx, m.secretsSrv, m.sqlStore, batchSize, func(processed, failed int) {
+			m.rotationMtx.Lock()
+			defer m.rotationMtx.Unlock()
+
+			m.rotation.Targets[idx].Processed += int64(processed)
+			m.rotation.Targets[idx].Failed += int64(failed)
+		})
+		if !success {
+			anyFailure = true
+		}
+	}
+
+	if anyFailure {
+		return errors.New("some secrets could not be re-encrypted, refer to the server logs for more details")
+	}
+	return nil
+}
@@ -75,11 +75,25 @@ type Migrator interface {
 	// ReEncryptSecrets decrypts and re-encrypts the secrets with most recent
 	// available data key. If a secret-specific decryption / re-encryption fails,
 	// it does not stop, but returns false as the first return (success or not)
-	// at the end of the process.
+	// at the end of the process. It returns ErrReEncryptionLimitReached if too
+	// many nodes are re-encrypting secrets.
 	ReEncryptSecrets(ctx context.Context) (bool, error)
+	// ReEncryptDataKeys re-encrypts the data keys with the current encryption
+	// provider. It returns ErrReEncryptionLimitReached if too many nodes are
+	// re-encrypting secrets.
+	ReEncryptDataKeys(ctx context.Context) error
 	// RollBackSecrets decrypts and re-encrypts the secrets using the legacy
 	// encryption. If a secret-specific decryption / re-encryption fails, it
 	// does not stop, but returns false as the first return (success or not)
 	// at the end of the process.
 	RollBackSecrets(ctx context.Context) (bool, error)
+	// RotateSecrets starts a rotation in the background: the data keys are
+	// disabled and every stored secret is re-encrypted in batches with a new
+	// data key. It returns ErrRotationInProgress if a rotation is already running.
+	RotateSecrets(ctx context.Context, trigger RotationTrigger) (RotationStatus, error)
+	// GetRotationStatus returns the progress of the last rotation.
+	GetRotationStatus() RotationStatus
+	// GetInventory lists every stored secret with the provider and data key
+	// encrypting it, without the secret values.
+	GetInventory(ctx context.Context) (*Inventory, error)
 }
@@ -1,11 +1,20 @@
 package secrets
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 )
 
-var ErrDataKeyNotFound = errors.New("data key not found")
+var (
+	ErrDataKeyNotFound    = errors.New("data key not found")
+	ErrRotationInProgress = errors.New("secrets rotation already in progress")
+	// ErrReEncryptionLimitReached is returned when too many nodes are re-encrypting secrets.
+	ErrReEncryptionLimitReached = errors.New("too many secrets re-encryptions in progress")
+	// ErrDataKeyScopeMismatch is returned when decrypting a secret of another organization.
+	ErrDataKeyScopeMismatch = errors.New("data key belongs to another organization")
+)
 
 type DataKey struct {
 	Active        bool
@@ -35,3 +44,104 @@ func WithScope(scope string) EncryptionOptions {
 		return scope
 	}
 }
+
+// WithOrgScope uses a data key for encryption bound to an organization when org scoped data keys
+// are enabled, so that secrets of an organization cannot be decrypted on behalf of another one.
+// Otherwise, it uses a root level data key.
+func WithOrgScope(orgID int64) EncryptionOptions {
+	return WithScope(OrgScope(orgID))
+}
+
+// OrgScope returns the data key scope of an organization.
+func OrgScope(orgID int64) string {
+	return fmt.Sprintf("org:%d", orgID)
+}
+
+type orgScopeContextKey struct{}
+
+// ContextWithOrgScope returns a context in which only secrets encrypted with root level data keys
+// or with data keys of the given organization can be decrypted.
+func ContextWithOrgScope(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, orgScopeContextKey{}, orgID)
+}
+
+// OrgScopeFromContext returns the organization set with ContextWithOrgScope.
+func OrgScopeFromContext(ctx context.Context) (int64, bool) {
+	orgID, ok := ctx.Value(orgScopeContextKey{}).(int64)
+	return orgID, ok
+}
+
+// RotationState is the state of a secrets rotation.
+type RotationState string
+
+const (
+	RotationStateIdle      RotationState = "idle"
+	RotationStateRunning   RotationState = "running"
+	RotationStateSucceeded RotationState = "succeeded"
+	RotationStateFailed    RotationState = "failed"
+)
+
+// RotationTrigger tells what started a secrets rotation.
+type RotationTrigger string
+
+const (
+	RotationTriggerManual    RotationTrigger = "manual"
+	RotationTriggerScheduled RotationTrigger = "scheduled"
+)
+
+// RotationTargetProgress is the progress of re-encrypting the secrets stored in a table column.
+type RotationTargetProgress struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	// Total is the number of rows in the table when the rotation reached it.
+	Total     int64 `json:"total"`
+	Processed int64 `json:"processed"`
+	Failed    int64 `json:"failed"`
+}
+
+// RotationStatus is the status of the last secrets rotation started by this instance.
+type RotationStatus struct {
+	State      RotationState            `json:"state"`
+	Trigger    RotationTrigger          `json:"trigger,omitempty"`
+	StartedAt  *time.Time               `json:"startedAt,omitempty"`
+	FinishedAt *time.Time               `json:"finishedAt,omitempty"`
+	Error      string                   `json:"error,omitempty"`
+	Targets    []RotationTargetProgress `json:"targets"`
+}
+
+// LegacyProvider is reported as the provider of secrets encrypted with
+// the secret key of the configuration instead of envelope encryption.
+const LegacyProvider ProviderID = "legacy"
+
+// InventoryItem describes a stored secret and how it is encrypted. It never holds the secret value.
+type InventoryItem struct {
+	// Table, Column and ID locate the resource owning the secret.
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	ID     int64  `json:"id"`
+	// Field is the key of the secret for columns holding several secrets.
+	Field    string     `json:"field,omitempty"`
+	Provider ProviderID `json:"provider"`
+	// DataKeyID is the version of the data key encrypting the secret.
+	DataKeyID     string `json:"dataKeyId,omitempty"`
+	DataKeyLabel  string `json:"dataKeyLabel,omitempty"`
+	DataKeyActive bool   `json:"dataKeyActive"`
+	// LastRotated is when the data key encrypting the secret was created.
+	LastRotated *time.Time `json:"lastRotated,omitempty"`
+	// Error is set when the encryption of the secret could not be determined.
+	Error string `json:"error,omitempty"`
+}
+
+// InventorySummary counts the secrets of an inventory.
+type InventorySummary struct {
+	Total      int                `json:"total"`
+	Legacy     int                `json:"legacy"`
+	Errors     int                `json:"errors"`
+	ByProvider map[ProviderID]int `json:"byProvider"`
+}
+
+// Inventory lists the secrets stored in the database.
+type Inventory struct {
+	Summary InventorySummary `json:"summary"`
+	Items   []InventoryItem  `json:"items"`
+}
@@ -0,0 +1,117 @@
+package securityheaders
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ErrInvalidSettings = errors.New("invalid security headers")
+
+// Settings are the security headers of the instance, they default to the [security] section of
+// the configuration.
+type Settings struct {
+	// AllowEmbedding omits the X-Frame-Options header, so that Grafana can be embedded by any site.
+	AllowEmbedding bool `json:"allowEmbedding"`
+	// ContentTypeProtection adds the X-Content-Type-Options header.
+	ContentTypeProtection bool `json:"contentTypeProtection"`
+	// XSSProtection adds the X-XSS-Protection header.
+	XSSProtection                     bool `json:"xssProtection"`
+	StrictTransportSecurity           bool `json:"strictTransportSecurity"`
+	StrictTransportSecurityMaxAge     int  `json:"strictTransportSecurityMaxAge"`
+	StrictTransportSecurityPreload    bool `json:"strictTransportSecurityPreload"`
+	StrictTransportSecuritySubDomains bool `json:"strictTransportSecuritySubDomains"`
+	CSPEnabled                        bool `json:"cspEnabled"`
+	// CSPTemplate is the Content-Security-Policy, $NONCE is replaced with the nonce of the request
+	// and $ROOT_PATH with the root path of the server.
+	CSPTemplate           string `json:"cspTemplate"`
+	CSPReportOnlyEnabled  bool   `json:"cspReportOnlyEnabled"`
+	CSPReportOnlyTemplate string `json:"cspReportOnlyTemplate"`
+}
+
+// FromConfig returns the security headers of the configuration.
+func FromConfig(cfg *setting.Cfg) Settings {
+	return Settings{
+		AllowEmbedding:                    cfg.AllowEmbedding,
+		ContentTypeProtection:             cfg.ContentTypeProtectionHeader,
+		XSSProtection:                     cfg.XSSProtectionHeader,
+		StrictTransportSecurity:           cfg.StrictTransportSecurity,
+		StrictTransportSecurityMaxAge:     cfg.StrictTransportSecurityMaxAge,
+		StrictTransportSecurityPreload:    cfg.StrictTransportSecurityPreload,
+		StrictTransportSecuritySubDomains: cfg.StrictTransportSecuritySubDomains,
+		CSPEnabled:                        cfg.CSPEnabled,
+		CSPTemplate:                       cfg.CSPTemplate,
+		CSPReportOnlyEnabled:              cfg.CSPReportOnlyEnabled,
+		CSPReportOnlyTemplate:             cfg.CSPReportOnlyTemplate,
+	}
+}
+
+func (s Settings) validate() error {
+	if s.StrictTransportSecurityMaxAge < 0 {
+		return fmt.Errorf("%w: strictTransportSecurityMaxAge cannot be negative", ErrInvalidSettings)
+	}
+	if s.CSPEnabled && strings.TrimSpace(s.CSPTemplate) == "" {
+		return fmt.Errorf("%w: cspEnabled requires a cspTemplate", ErrInvalidSettings)
+	}
+	if s.CSPReportOnlyEnabled && strings.TrimSpace(s.CSPReportOnlyTemplate) == "" {
+		return fmt.Errorf("%w: cspReportOnlyEnabled requires a cspReportOnlyTemplate", ErrInvalidSettings)
+	}
+	if strings.ContainsAny(s.CSPTemplate+s.CSPReportOnlyTemplate, "\r\n") {
+		return fmt.Errorf("%w: policy templates cannot contain line breaks", ErrInvalidSettings)
+	}
+	return nil
+}
+
+type Source string
+
+const (
+	SourceConfig Source = "config"
+	SourceAPI    Source = "api"
+)
+
+type SettingsResult struct {
+	Settings Settings `json:"settings"`
+	// Source is config if the settings are read from the configuration, and api if they were
+	// set through the API.
+	Source Source `json:"source"`
+}
+
+// OrgSettings are the security headers of the requests made in an organization.
+type OrgSettings struct {
+	OrgID int64 `json:"orgId"`
+	// FrameAncestors are the sources allowed to embed the organization, in the syntax of the
+	// frame-ancestors directive of Content Security Policy. The embedding settings of the instance
+	// are used when empty.
+	FrameAncestors []string `json:"frameAncestors"`
+}
+
+// Headers are the security headers of a request.
+type Headers struct {
+	Settings
+	// FrameAncestors replace the X-Frame-Options header with a frame-ancestors directive when set.
+	FrameAncestors []string
+}
+
+var (
+	schemeSourcePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:$`)
+	hostSourcePattern   = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*://)?(\*|(\*\.)?[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)*)(:(\d+|\*))?(/[^\s;,']*)?$`)
+)
+
+func validateFrameAncestors(sources []string) error {
+	for _, source := range sources {
+		switch {
+		case source == "'self'":
+		case source == "'none'":
+			if len(sources) > 1 {
+				return fmt.Errorf("%w: 'none' cannot be combined with other frame ancestors", ErrInvalidSettings)
+			}
+		case schemeSourcePattern.MatchString(source), hostSourcePattern.MatchString(source):
+		default:
+			return fmt.Errorf("%w: invalid frame ancestor %q", ErrInvalidSettings, source)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package securityheaders manages the security headers added to responses. The headers are read
+// from the configuration, and can be changed at runtime for the whole instance, and per
+// organization for embedding.
+package securityheaders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	kvNamespace       = "security-headers"
+	settingsKey       = "settings"
+	frameAncestorsKey = "frame-ancestors"
+
+	// cacheTTL is the delay after which changed settings are applied by the other instances of a
+	// cluster.
+	cacheTTL = time.Minute
+)
+
+type Service interface {
+	// GetSettings returns the security headers of the instance, and whether they come from the
+	// configuration or were set with SetSettings.
+	GetSettings(ctx context.Context) (*SettingsResult, error)
+	// SetSettings replaces the security headers of the configuration until ResetSettings is called.
+	SetSettings(ctx context.Context, settings Settings) error
+	ResetSettings(ctx context.Context) error
+	GetOrgSettings(ctx context.Context, orgID int64) (*OrgSettings, error)
+	// SetOrgSettings changes the settings of an organization, an empty list of frame ancestors
+	// removes them.
+	SetOrgSettings(ctx context.Context, settings OrgSettings) error
+	// GetHeaders returns the security headers of a request made in an organization, or outside
+	// of any organization if orgID is 0. The settings are cached, so that it can be called for
+	// every request.
+	GetHeaders(ctx context.Context, orgID int64) *Headers
+}
+
+type SecurityHeadersService struct {
+	cfg     *setting.Cfg
+	kvStore kvstore.KVStore
+	cache   *localcache.CacheService
+	log     log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, kvStore kvstore.KVStore) *SecurityHeadersService {
+	return &SecurityHeadersService{
+		cfg:     cfg,
+		kvStore: kvStore,
+		cache:   localcache.New(cacheTTL, 2*cacheTTL),
+		log:     log.New("security-headers"),
+	}
+}
+
+func (s *SecurityHeadersService) GetSettings(ctx context.Context) (*SettingsResult, error) {
+	stored, err := s.storedSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return &SettingsResult{Settings: FromConfig(s.cfg), Source: SourceConfig}, nil
+	}
+	return &SettingsResult{Settings: *stored, Source: SourceAPI}, nil
+}
+
+func (s *SecurityHeadersService) SetSettings(ctx context.Context, settings Settings) error {
+	if err := settings.validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	if err := kvstore.WithNamespace(s.kvStore, 0, kvNamespace).Set(ctx, settingsKey, string(value)); err != nil {
+		return err
+	}
+	s.cache.Delete(settingsKey)
+	s.log.Info("Security headers changed", "settings", string(value))
+	return nil
+}
+
+func (s *SecurityHeadersService) ResetSettings(ctx context.Context) error {
+	if err := kvstore.WithNamespace(s.kvStore, 0, kvNamespace).Del(ctx, settingsKey); err != nil {
+		return err
+	}
+	s.cache.Delete(settingsKey)
+	s.log.Info("Security headers reset to the configuration")
+	return nil
+}
+
+func (s *SecurityHeadersService) GetOrgSettings(ctx context.Context, orgID int64) (*OrgSettings, error) {
+	ancestors, err := s.frameAncestors(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &OrgSettings{OrgID: orgID, FrameAncestors: ancestors}, nil
+}
+
+func (s *SecurityHeadersService) SetOrgSettings(ctx context.Context, settings OrgSettings) error {
+	if err := validateFrameAncestors(settings.FrameAncestors); err != nil {
+		return err
+	}
+
+	kv := kvstore.WithNamespace(s.kvStore, settings.OrgID, kvNamespace)
+	if len(settings.FrameAncestors) == 0 {
+		if err := kv.Del(ctx, frameAncestorsKey); err != nil {
+			return err
+		}
+	} else {
+		value, err := json.Marshal(settings.FrameAncestors)
+		if err != nil {
+			return err
+		}
+		if err := kv.Set(ctx, frameAncestorsKey, string(value)); err != nil {
+			return err
+		}
+	}
+	s.cache.Delete(orgCacheKey(settings.OrgID))
+	s.log.Info("Organization embedding changed", "orgId", settings.OrgID, "frameAncestors", settings.FrameAncestors)
+	return nil
+}
+
+func (s *SecurityHeadersService) GetHeaders(ctx context.Context, orgID int64) *Headers {
+	headers := &Headers{Settings: FromConfig(s.cfg)}
+
+	stored, err := s.storedSettings(ctx)
+	if err != nil {
+		s.log.Error("Failed to get security headers, using the configuration", "error", err)
+	} else if stored != nil {
+		headers.Settings = *stored
+	}
+
+	if orgID > 0 {
+		ancestors, err := s.frameAncestors(ctx, orgID)
+		if err != nil {
+			s.log.Error("Failed to get frame ancestors of organization", "orgId", orgID, "error", err)
+		}
+		headers.FrameAncestors = ancestors
+	}
+	return headers
+}
+
+// storedSettings returns the settings set with SetSettings, or nil if the configuration is used.
+func (s *SecurityHeadersService) storedSettings(ctx context.Context) (*Settings, error) {
+	if cached, ok := s.cache.Get(settingsKey); ok {
+		return cached.(*Settings), nil
+	}
+
+	value, exists, err := kvstore.WithNamespace(s.kvStore, 0, kvNamespace).Get(ctx, settingsKey)
+	if err != nil {
+		return nil, err
+	}
+	var settings *Settings
+	if exists {
+		settings = &Settings{}
+		if err := json.Unmarshal([]byte(value), settings); err != nil {
+			return nil, err
+		}
+	}
+	s.cache.Set(settingsKey, settings, cacheTTL)
+	return settings, nil
+}
+
+func (s *SecurityHeadersService) frameAncestors(ctx context.Context, orgID int64) ([]string, error) {
+	if cached, ok := s.cache.Get(orgCacheKey(orgID)); ok {
+		return cached.([]string), nil
+	}
+
+	value, exists, err := kvstore.WithNamespace(s.kvStore, orgID, kvNamespace).Get(ctx, frameAncestorsKey)
+	if err != nil {
+		return nil, err
+	}
+	ancestors := []string{}
+	if exists {
+		if err := json.Unmarshal([]byte(value), &ancestors); err != nil {
+			return nil, err
+		}
+	}
+	s.cache.Set(orgCacheKey(orgID), ancestors, cacheTTL)
+	return ancestors, nil
+}
+
+func orgCacheKey(orgID int64) string {
+	return fmt.Sprintf("org-%d", orgID)
+}
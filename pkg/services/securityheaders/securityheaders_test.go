@@ -0,0 +1,80 @@
+package securityheaders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestValidateFrameAncestors(t *testing.T) {
+	for _, valid := range [][]string{
+		nil,
+		{"'self'"},
+		{"'none'"},
+		{"https:", "https://*.example.com", "http://localhost:8080", "example.com/embed/"},
+	} {
+		assert.NoError(t, validateFrameAncestors(valid), valid)
+	}
+
+	for _, invalid := range [][]string{
+		{"'none'", "'self'"},
+		{"'unsafe-inline'"},
+		{"https://example.com; script-src *"},
+		{"example.com example.org"},
+		{""},
+	} {
+		assert.ErrorIs(t, validateFrameAncestors(invalid), ErrInvalidSettings, invalid)
+	}
+}
+
+func TestIntegrationSecurityHeaders(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	cfg := setting.NewCfg()
+	cfg.XSSProtectionHeader = true
+	s := ProvideService(cfg, kvstore.ProvideService(db.InitTestDB(t)))
+
+	t.Run("Should use the configuration by default", func(t *testing.T) {
+		result, err := s.GetSettings(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, SourceConfig, result.Source)
+		assert.True(t, result.Settings.XSSProtection)
+		assert.True(t, s.GetHeaders(ctx, 1).XSSProtection)
+	})
+
+	t.Run("Should replace and reset the configuration", func(t *testing.T) {
+		err := s.SetSettings(ctx, Settings{CSPEnabled: true})
+		assert.ErrorIs(t, err, ErrInvalidSettings)
+
+		require.NoError(t, s.SetSettings(ctx, Settings{CSPEnabled: true, CSPTemplate: "default-src 'self' $NONCE;"}))
+		result, err := s.GetSettings(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, SourceAPI, result.Source)
+		headers := s.GetHeaders(ctx, 1)
+		assert.False(t, headers.XSSProtection)
+		assert.Equal(t, "default-src 'self' $NONCE;", headers.CSPTemplate)
+
+		require.NoError(t, s.ResetSettings(ctx))
+		assert.True(t, s.GetHeaders(ctx, 1).XSSProtection)
+	})
+
+	t.Run("Should add the frame ancestors of the organization", func(t *testing.T) {
+		require.NoError(t, s.SetOrgSettings(ctx, OrgSettings{OrgID: 2, FrameAncestors: []string{"https://portal.example.com"}}))
+		assert.Equal(t, []string{"https://portal.example.com"}, s.GetHeaders(ctx, 2).FrameAncestors)
+		assert.Empty(t, s.GetHeaders(ctx, 1).FrameAncestors)
+		assert.Empty(t, s.GetHeaders(ctx, 0).FrameAncestors)
+
+		require.NoError(t, s.SetOrgSettings(ctx, OrgSettings{OrgID: 2}))
+		settings, err := s.GetOrgSettings(ctx, 2)
+		require.NoError(t, err)
+		assert.Empty(t, settings.FrameAncestors)
+	})
+}
@@ -0,0 +1,40 @@
+package securityheaderstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/securityheaders"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// FakeService returns the security headers of the configuration, with the frame ancestors of
+// FrameAncestors.
+type FakeService struct {
+	Cfg            *setting.Cfg
+	FrameAncestors map[int64][]string
+	ExpectedError  error
+}
+
+func (s *FakeService) GetSettings(ctx context.Context) (*securityheaders.SettingsResult, error) {
+	return &securityheaders.SettingsResult{Settings: securityheaders.FromConfig(s.Cfg), Source: securityheaders.SourceConfig}, s.ExpectedError
+}
+
+func (s *FakeService) SetSettings(ctx context.Context, settings securityheaders.Settings) error {
+	return s.ExpectedError
+}
+
+func (s *FakeService) ResetSettings(ctx context.Context) error {
+	return s.ExpectedError
+}
+
+func (s *FakeService) GetOrgSettings(ctx context.Context, orgID int64) (*securityheaders.OrgSettings, error) {
+	return &securityheaders.OrgSettings{OrgID: orgID, FrameAncestors: s.FrameAncestors[orgID]}, s.ExpectedError
+}
+
+func (s *FakeService) SetOrgSettings(ctx context.Context, settings securityheaders.OrgSettings) error {
+	return s.ExpectedError
+}
+
+func (s *FakeService) GetHeaders(ctx context.Context, orgID int64) *securityheaders.Headers {
+	return &securityheaders.Headers{Settings: securityheaders.FromConfig(s.Cfg), FrameAncestors: s.FrameAncestors[orgID]}
+}
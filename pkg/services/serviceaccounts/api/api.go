@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -71,6 +72,12 @@ func (api *ServiceAccountsAPI) RegisterAPIEndpoints() {
 			accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.CreateToken))
 		serviceAccountsRoute.Delete("/:serviceAccountId/tokens/:tokenId", auth(middleware.ReqOrgAdmin,
 			accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.DeleteToken))
+		serviceAccountsRoute.Get("/:serviceAccountId/expiry", auth(middleware.ReqOrgAdmin,
+			accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(api.GetExpiry))
+		serviceAccountsRoute.Put("/:serviceAccountId/expiry", auth(middleware.ReqOrgAdmin,
+			accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.UpdateExpiry))
+		serviceAccountsRoute.Post("/:serviceAccountId/attest", auth(middleware.ReqOrgAdmin,
+			accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.Attest))
 		serviceAccountsRoute.Get("/migrationstatus", auth(middleware.ReqOrgAdmin,
 			accesscontrol.EvalPermission(serviceaccounts.ActionRead)), routing.Wrap(api.GetAPIKeysMigrationStatus))
 		serviceAccountsRoute.Post("/hideApiKeys", auth(middleware.ReqOrgAdmin,
@@ -280,6 +287,13 @@ func (api *ServiceAccountsAPI) DeleteServiceAccount(ctx *models.ReqContext) resp
 	return response.Success("Service account deleted")
 }
 
+var searchPagination = pagination.Config{
+	SortFields:   []string{"login", "name"},
+	DefaultSort:  pagination.Sort{Field: "login"},
+	DefaultLimit: 1000,
+	MaxLimit:     5000,
+}
+
 // swagger:route GET /serviceaccounts/search service_accounts searchOrgServiceAccountsWithPaging
 //
 // # Search service accounts with paging
@@ -294,13 +308,12 @@ func (api *ServiceAccountsAPI) DeleteServiceAccount(ctx *models.ReqContext) resp
 // 500: internalServerError
 func (api *ServiceAccountsAPI) SearchOrgServiceAccountsWithPaging(c *models.ReqContext) response.Response {
 	ctx := c.Req.Context()
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+	paging, err := searchPagination.Parse(c.Req.URL.Query())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
 	}
-	page := c.QueryInt("page")
-	if page < 1 {
-		page = 1
+	if paging.Page < 1 {
+		paging.Page = 1
 	}
 	// its okay that it fails, it is only filtering that might be weird, but to safe quard against any weird incoming query param
 	onlyWithExpiredTokens := c.QueryBool("expiredTokens")
@@ -312,7 +325,7 @@ func (api *ServiceAccountsAPI) SearchOrgServiceAccountsWithPaging(c *models.ReqC
 	if onlyDisabled {
 		filter = serviceaccounts.FilterOnlyDisabled
 	}
-	serviceAccountSearch, err := api.store.SearchOrgServiceAccounts(ctx, c.OrgID, c.Query("query"), filter, page, perPage, c.SignedInUser)
+	serviceAccountSearch, err := api.store.SearchOrgServiceAccounts(ctx, c.OrgID, c.Query("query"), filter, paging, c.SignedInUser)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to get service accounts for current organization", err)
 	}
@@ -335,6 +348,15 @@ func (api *ServiceAccountsAPI) SearchOrgServiceAccountsWithPaging(c *models.ReqC
 		sa.Tokens = int64(len(tokens))
 	}
 
+	if n := len(serviceAccountSearch.ServiceAccounts); n > 0 {
+		last := serviceAccountSearch.ServiceAccounts[n-1]
+		value := last.Login
+		if paging.Sort.Field == "name" {
+			value = last.Name
+		}
+		serviceAccountSearch.NextCursor = paging.NextCursor(n, value, last.Id)
+	}
+
 	return response.JSON(http.StatusOK, serviceAccountSearch)
 }
 
@@ -433,6 +455,14 @@ type SearchOrgServiceAccountsWithPagingParams struct {
 	// in:query
 	// required:false
 	Page int `json:"page"`
+	// Sort the service accounts by login or name, e.g. `name-desc`. The default value is login-asc.
+	// in:query
+	// required:false
+	Sort string `json:"sort"`
+	// Return the page after this cursor, the nextCursor field of the previous page.
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
 }
 
 // swagger:parameters createServiceAccount
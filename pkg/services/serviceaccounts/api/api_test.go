@@ -50,7 +50,7 @@ func TestServiceAccountsAPI_CreateServiceAccount(t *testing.T) {
 	apiKeyService, err := apikeyimpl.ProvideService(store, store.Cfg, quotaService)
 	require.NoError(t, err)
 	kvStore := kvstore.ProvideService(store)
-	orgService, err := orgimpl.ProvideService(store, setting.NewCfg(), quotaService)
+	orgService, err := orgimpl.ProvideService(store, setting.NewCfg(), quotaService, actest.FakeService{})
 	require.NoError(t, err)
 	saStore := database.ProvideServiceAccountsStore(store, apiKeyService, kvStore, orgService)
 	svcmock := tests.ServiceAccountMock{}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const defaultAttestationDays = 90
+
+// swagger:route GET /serviceaccounts/{serviceAccountId}/expiry service_accounts getServiceAccountExpiry
+//
+// # Get the expiry of a service account
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:read` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: getServiceAccountExpiryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) GetExpiry(c *models.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	expiry, err := api.store.GetServiceAccountExpiry(c.Req.Context(), c.OrgID, saID)
+	if err != nil {
+		if errors.Is(err, serviceaccounts.ErrServiceAccountExpiryNotFound) {
+			return response.Error(http.StatusNotFound, "Service account has no expiry", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get service account expiry", err)
+	}
+
+	return response.JSON(http.StatusOK, expiry)
+}
+
+// swagger:route PUT /serviceaccounts/{serviceAccountId}/expiry service_accounts updateServiceAccountExpiry
+//
+// # Set or remove the expiry of a service account
+//
+// Service accounts with an expiry are disabled when they are not attested before they expire.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) UpdateExpiry(c *models.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	cmd := serviceaccounts.UpdateServiceAccountExpiryForm{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+
+	if cmd.ExpiresAt != nil && !cmd.ExpiresAt.After(time.Now()) {
+		return response.Error(http.StatusBadRequest, serviceaccounts.ErrServiceAccountExpiryInPast.Error(), nil)
+	}
+
+	if err := api.store.UpdateServiceAccountExpiry(c.Req.Context(), c.OrgID, saID, cmd.ExpiresAt); err != nil {
+		if errors.Is(err, serviceaccounts.ErrServiceAccountNotFound) {
+			return response.Error(http.StatusNotFound, "Failed to retrieve service account", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update service account expiry", err)
+	}
+
+	if cmd.ExpiresAt == nil {
+		return response.Success("Service account expiry removed")
+	}
+	return response.Success("Service account expiry updated")
+}
+
+// swagger:route POST /serviceaccounts/{serviceAccountId}/attest service_accounts attestServiceAccount
+//
+// # Attest a service account
+//
+// Confirms that the service account is still needed and extends its expiry by the configured attestation period.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: getServiceAccountExpiryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) Attest(c *models.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	days := api.cfg.SAAttestationDays
+	if days <= 0 {
+		days = defaultAttestationDays
+	}
+	expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+
+	if err := api.store.AttestServiceAccount(c.Req.Context(), c.OrgID, saID, c.UserID, expiresAt); err != nil {
+		if errors.Is(err, serviceaccounts.ErrServiceAccountExpiryNotFound) {
+			return response.Error(http.StatusNotFound, "Service account has no expiry", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to attest service account", err)
+	}
+
+	expiry, err := api.store.GetServiceAccountExpiry(c.Req.Context(), c.OrgID, saID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get service account expiry", err)
+	}
+
+	return response.JSON(http.StatusOK, expiry)
+}
+
+// swagger:parameters getServiceAccountExpiry
+type GetServiceAccountExpiryParams struct {
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+}
+
+// swagger:parameters updateServiceAccountExpiry
+type UpdateServiceAccountExpiryParams struct {
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	Body serviceaccounts.UpdateServiceAccountExpiryForm
+}
+
+// swagger:parameters attestServiceAccount
+type AttestServiceAccountParams struct {
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+}
+
+// swagger:response getServiceAccountExpiryResponse
+type GetServiceAccountExpiryResponse struct {
+	// in:body
+	Body *serviceaccounts.ServiceAccountExpiryDTO
+}
@@ -39,6 +39,10 @@ type TokenDTO struct {
 	HasExpired bool `json:"hasExpired"`
 	// example: false
 	IsRevoked *bool `json:"isRevoked"`
+	// example: ["10.0.0.0/8"]
+	AllowedCIDRs []string `json:"allowedCidrs,omitempty"`
+	// example: ["10.0.1.0/24"]
+	DeniedCIDRs []string `json:"deniedCidrs,omitempty"`
 }
 
 func hasExpired(expiration *int64) bool {
@@ -106,6 +110,8 @@ func (api *ServiceAccountsAPI) ListTokens(ctx *models.ReqContext) response.Respo
 			HasExpired:             isExpired,
 			LastUsedAt:             token.LastUsedAt,
 			IsRevoked:              token.IsRevoked,
+			AllowedCIDRs:           token.AllowedCIDRList(),
+			DeniedCIDRs:            token.DeniedCIDRList(),
 		}
 	}
 
@@ -176,7 +182,7 @@ func (api *ServiceAccountsAPI) CreateToken(c *models.ReqContext) response.Respon
 	cmd.Key = newKeyInfo.HashedKey
 
 	if err := api.store.AddServiceAccountToken(c.Req.Context(), saID, &cmd); err != nil {
-		if errors.Is(err, database.ErrInvalidTokenExpiration) {
+		if errors.Is(err, database.ErrInvalidTokenExpiration) || errors.Is(err, database.ErrInvalidTokenAllowedCIDRs) {
 			return response.Error(http.StatusBadRequest, err.Error(), nil)
 		}
 		if errors.Is(err, database.ErrDuplicateToken) {
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -159,6 +160,7 @@ func (s *ServiceAccountsStoreImpl) UpdateServiceAccount(ctx context.Context,
 func ServiceAccountDeletions() []string {
 	deletes := []string{
 		"DELETE FROM api_key WHERE service_account_id = ?",
+		"DELETE FROM service_account_expiry WHERE service_account_id = ?",
 	}
 	deletes = append(deletes, sqlstore.UserDeletions()...)
 	return deletes
@@ -280,15 +282,20 @@ func (s *ServiceAccountsStoreImpl) RetrieveServiceAccountIdByName(ctx context.Co
 	return serviceAccount.Id, nil
 }
 
+var serviceAccountSortColumns = map[string]string{
+	"login": "login",
+	"name":  "name",
+}
+
 func (s *ServiceAccountsStoreImpl) SearchOrgServiceAccounts(
-	ctx context.Context, orgId int64, query string, filter serviceaccounts.ServiceAccountFilter, page int, limit int,
+	ctx context.Context, orgId int64, query string, filter serviceaccounts.ServiceAccountFilter, paging pagination.Params,
 	signedInUser *user.SignedInUser,
 ) (*serviceaccounts.SearchServiceAccountsResult, error) {
 	searchResult := &serviceaccounts.SearchServiceAccountsResult{
 		TotalCount:      0,
 		ServiceAccounts: make([]*serviceaccounts.ServiceAccountDTO, 0),
-		Page:            page,
-		PerPage:         limit,
+		Page:            paging.Page,
+		PerPage:         paging.Limit,
 	}
 
 	err := s.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
@@ -343,9 +350,29 @@ func (s *ServiceAccountsStoreImpl) SearchOrgServiceAccounts(
 		if len(whereConditions) > 0 {
 			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
 		}
-		if limit > 0 {
-			offset := limit * (page - 1)
-			sess.Limit(limit, offset)
+
+		if paging.Sort.Field == "" {
+			sess.Asc("user.email", "user.login")
+		} else {
+			column, ok := serviceAccountSortColumns[paging.Sort.Field]
+			if !ok {
+				return pagination.ErrInvalidSort
+			}
+			column = s.sqlStore.Dialect.Quote("user") + "." + column
+			idColumn := "org_user.user_id"
+			sess.OrderBy(pagination.OrderBy(paging.Sort, column, idColumn))
+			if paging.After != nil {
+				where, params := pagination.Where(paging.Sort, paging.After, column, idColumn)
+				sess.Where(where, params...)
+			}
+		}
+
+		if paging.Limit > 0 {
+			offset := paging.Limit * (paging.Page - 1)
+			if paging.After != nil {
+				offset = 0
+			}
+			sess.Limit(paging.Limit, offset)
 		}
 
 		sess.Cols(
@@ -358,7 +385,6 @@ func (s *ServiceAccountsStoreImpl) SearchOrgServiceAccounts(
 			"user.last_seen_at",
 			"user.is_disabled",
 		)
-		sess.Asc("user.email", "user.login")
 		if err := sess.Find(&searchResult.ServiceAccounts); err != nil {
 			return err
 		}
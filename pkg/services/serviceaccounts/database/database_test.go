@@ -8,9 +8,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/apikey/apikeyimpl"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
@@ -117,7 +119,7 @@ func setupTestDatabase(t *testing.T) (*sqlstore.SQLStore, *ServiceAccountsStoreI
 	apiKeyService, err := apikeyimpl.ProvideService(db, db.Cfg, quotaService)
 	require.NoError(t, err)
 	kvStore := kvstore.ProvideService(db)
-	orgService, err := orgimpl.ProvideService(db, setting.NewCfg(), quotaService)
+	orgService, err := orgimpl.ProvideService(db, setting.NewCfg(), quotaService, actest.FakeService{})
 	require.NoError(t, err)
 	return db, ProvideServiceAccountsStore(db, apiKeyService, kvStore, orgService)
 }
@@ -156,6 +158,35 @@ func TestStore_RetrieveServiceAccount(t *testing.T) {
 	}
 }
 
+func TestStore_SearchOrgServiceAccounts(t *testing.T) {
+	db, store := setupTestDatabase(t)
+	store.sqlStore.Cfg.AutoAssignOrg = true
+	store.sqlStore.Cfg.AutoAssignOrgId = 1
+	err := store.sqlStore.CreateOrg(context.Background(), &models.CreateOrgCommand{Name: "main"})
+	require.NoError(t, err)
+	for _, login := range []string{"sa-a", "sa-b", "sa-c"} {
+		tests.SetupUserServiceAccount(t, db, tests.TestUser{Login: login, Name: login, IsServiceAccount: true, OrgID: 1})
+	}
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: {"serviceaccounts:read": {"serviceaccounts:id:*"}},
+	}}
+	sort := pagination.Sort{Field: "login", Descending: true}
+
+	result, err := store.SearchOrgServiceAccounts(context.Background(), 1, "", serviceaccounts.FilterIncludeAll, pagination.Params{Limit: 2, Sort: sort}, signedInUser)
+	require.NoError(t, err)
+	require.Len(t, result.ServiceAccounts, 2)
+	require.Equal(t, "sa-c", result.ServiceAccounts[0].Login)
+	last := result.ServiceAccounts[1]
+	require.Equal(t, "sa-b", last.Login)
+
+	after := &pagination.Cursor{Value: last.Login, ID: last.Id}
+	result, err = store.SearchOrgServiceAccounts(context.Background(), 1, "", serviceaccounts.FilterIncludeAll, pagination.Params{Limit: 2, Sort: sort, After: after}, signedInUser)
+	require.NoError(t, err)
+	require.Len(t, result.ServiceAccounts, 1)
+	require.Equal(t, "sa-a", result.ServiceAccounts[0].Login)
+	require.Equal(t, int64(3), result.TotalCount)
+}
+
 func TestStore_MigrateApiKeys(t *testing.T) {
 	cases := []struct {
 		desc        string
@@ -184,7 +215,7 @@ func TestStore_MigrateApiKeys(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 
-				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), key.OrgId, "", "all", 1, 50, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
+				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), key.OrgId, "", "all", pagination.Params{Page: 1, Limit: 50}, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
 					key.OrgId: {
 						"serviceaccounts:read": {"serviceaccounts:id:*"},
 					},
@@ -265,7 +296,7 @@ func TestStore_MigrateAllApiKeys(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 
-				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), c.orgId, "", "all", 1, 50, &user.SignedInUser{UserID: 101, OrgID: c.orgId, Permissions: map[int64]map[string][]string{
+				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), c.orgId, "", "all", pagination.Params{Page: 1, Limit: 50}, &user.SignedInUser{UserID: 101, OrgID: c.orgId, Permissions: map[int64]map[string][]string{
 					c.orgId: {
 						"serviceaccounts:read": {"serviceaccounts:id:*"},
 					},
@@ -325,7 +356,7 @@ func TestStore_RevertApiKey(t *testing.T) {
 			if c.forceMismatchServiceAccount {
 				saId = rand.Int63()
 			} else {
-				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), key.OrgId, "", "all", 1, 50, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
+				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), key.OrgId, "", "all", pagination.Params{Page: 1, Limit: 50}, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
 					key.OrgId: {
 						"serviceaccounts:read": {"serviceaccounts:id:*"},
 					},
@@ -341,7 +372,7 @@ func TestStore_RevertApiKey(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 
-				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), key.OrgId, "", "all", 1, 50, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
+				serviceAccounts, err := store.SearchOrgServiceAccounts(context.Background(), key.OrgId, "", "all", pagination.Params{Page: 1, Limit: 50}, &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{
 					key.OrgId: {
 						"serviceaccounts:read": {"serviceaccounts:id:*"},
 					},
@@ -10,4 +10,5 @@ var (
 	ErrInvalidTokenExpiration         = errors.New("invalid SecondsToLive value")
 	ErrDuplicateToken                 = errors.New("service account token with given name already exists in the organization")
 	ErrServiceAccountAndTokenMismatch = errors.New("API token does not belong to the given service account")
+	ErrInvalidTokenAllowedCIDRs       = errors.New("invalid allowedCidrs or deniedCidrs value, expected a list of IP addresses or CIDR networks")
 )
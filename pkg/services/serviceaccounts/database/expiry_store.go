@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+type serviceAccountExpiry struct {
+	Id               int64      `xorm:"pk autoincr 'id'"`
+	OrgId            int64      `xorm:"org_id"`
+	ServiceAccountId int64      `xorm:"service_account_id"`
+	ExpiresAt        time.Time  `xorm:"expires_at"`
+	AttestedAt       *time.Time `xorm:"attested_at"`
+	AttestedBy       int64      `xorm:"attested_by"`
+	NotifiedAt       *time.Time `xorm:"notified_at"`
+}
+
+func (e serviceAccountExpiry) TableName() string { return "service_account_expiry" }
+
+func (s *ServiceAccountsStoreImpl) expirySelect() string {
+	return `SELECT
+		service_account_expiry.org_id,
+		service_account_expiry.service_account_id,
+		service_account_expiry.expires_at,
+		service_account_expiry.attested_at,
+		service_account_expiry.attested_by,
+		service_account_expiry.notified_at,
+		u.login,
+		u.name
+		FROM service_account_expiry
+		INNER JOIN ` + s.sqlStore.GetDialect().Quote("user") + ` AS u ON u.id = service_account_expiry.service_account_id`
+}
+
+// GetServiceAccountExpiry returns the expiry of a service account
+func (s *ServiceAccountsStoreImpl) GetServiceAccountExpiry(ctx context.Context, orgId, serviceAccountId int64) (*serviceaccounts.ServiceAccountExpiryDTO, error) {
+	expiry := &serviceaccounts.ServiceAccountExpiryDTO{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.SQL(s.expirySelect()+
+			` WHERE service_account_expiry.org_id = ? AND service_account_expiry.service_account_id = ?`,
+			orgId, serviceAccountId).Get(expiry)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return serviceaccounts.ErrServiceAccountExpiryNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expiry, nil
+}
+
+// UpdateServiceAccountExpiry sets the expiry of a service account. A nil expiry removes it.
+// Setting a new expiry resets any previous attestation and expiry notification.
+func (s *ServiceAccountsStoreImpl) UpdateServiceAccountExpiry(ctx context.Context, orgId, serviceAccountId int64, expiresAt *time.Time) error {
+	if _, err := s.RetrieveServiceAccount(ctx, orgId, serviceAccountId); err != nil {
+		return err
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM service_account_expiry WHERE org_id = ? AND service_account_id = ?",
+			orgId, serviceAccountId); err != nil {
+			return err
+		}
+
+		if expiresAt == nil {
+			return nil
+		}
+
+		_, err := sess.Insert(&serviceAccountExpiry{
+			OrgId:            orgId,
+			ServiceAccountId: serviceAccountId,
+			ExpiresAt:        *expiresAt,
+		})
+		return err
+	})
+}
+
+// AttestServiceAccount records that a user has confirmed the service account is still needed
+// and extends its expiry.
+func (s *ServiceAccountsStoreImpl) AttestServiceAccount(ctx context.Context, orgId, serviceAccountId, userId int64, expiresAt time.Time) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		now := time.Now()
+		result, err := sess.Exec(`UPDATE service_account_expiry
+			SET expires_at = ?, attested_at = ?, attested_by = ?, notified_at = NULL
+			WHERE org_id = ? AND service_account_id = ?`,
+			expiresAt, now, userId, orgId, serviceAccountId)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return serviceaccounts.ErrServiceAccountExpiryNotFound
+		}
+		return nil
+	})
+}
+
+// ListExpiringServiceAccounts returns the enabled service accounts across all organizations
+// that expire before the given time.
+func (s *ServiceAccountsStoreImpl) ListExpiringServiceAccounts(ctx context.Context, before time.Time) ([]*serviceaccounts.ServiceAccountExpiryDTO, error) {
+	result := make([]*serviceaccounts.ServiceAccountExpiryDTO, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(s.expirySelect()+
+			` WHERE service_account_expiry.expires_at <= ? AND u.is_disabled = ?
+			ORDER BY service_account_expiry.expires_at ASC`,
+			before, s.sqlStore.GetDialect().BooleanStr(false)).Find(&result)
+	})
+	return result, err
+}
+
+// MarkServiceAccountExpiryNotified records that the owners of a service account were notified
+// about its upcoming expiry.
+func (s *ServiceAccountsStoreImpl) MarkServiceAccountExpiryNotified(ctx context.Context, orgId, serviceAccountId int64, notifiedAt time.Time) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE service_account_expiry SET notified_at = ? WHERE org_id = ? AND service_account_id = ?",
+			notifiedAt, orgId, serviceAccountId)
+		return err
+	})
+}
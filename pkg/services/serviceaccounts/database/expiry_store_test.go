@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
+)
+
+func TestStore_ServiceAccountExpiry(t *testing.T) {
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, tests.TestUser{Login: "sa-expiry", Name: "expiry", IsServiceAccount: true})
+	ctx := context.Background()
+
+	t.Run("should not find expiry for service account without one", func(t *testing.T) {
+		_, err := store.GetServiceAccountExpiry(ctx, sa.OrgID, sa.ID)
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountExpiryNotFound)
+
+		err = store.AttestServiceAccount(ctx, sa.OrgID, sa.ID, 1, time.Now().Add(time.Hour))
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountExpiryNotFound)
+	})
+
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	t.Run("should set expiry and list expiring service accounts", func(t *testing.T) {
+		require.NoError(t, store.UpdateServiceAccountExpiry(ctx, sa.OrgID, sa.ID, &expiresAt))
+
+		expiry, err := store.GetServiceAccountExpiry(ctx, sa.OrgID, sa.ID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, expiresAt, expiry.ExpiresAt, time.Second)
+		assert.Equal(t, "expiry", expiry.Name)
+		assert.Nil(t, expiry.AttestedAt)
+
+		expiring, err := store.ListExpiringServiceAccounts(ctx, time.Now().Add(48*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, expiring, 1)
+		assert.Equal(t, sa.ID, expiring[0].ServiceAccountId)
+
+		expiring, err = store.ListExpiringServiceAccounts(ctx, time.Now())
+		require.NoError(t, err)
+		require.Len(t, expiring, 0)
+	})
+
+	t.Run("should reset notification when attested", func(t *testing.T) {
+		require.NoError(t, store.MarkServiceAccountExpiryNotified(ctx, sa.OrgID, sa.ID, time.Now()))
+		expiry, err := store.GetServiceAccountExpiry(ctx, sa.OrgID, sa.ID)
+		require.NoError(t, err)
+		require.NotNil(t, expiry.NotifiedAt)
+
+		extended := expiresAt.Add(90 * 24 * time.Hour)
+		require.NoError(t, store.AttestServiceAccount(ctx, sa.OrgID, sa.ID, 10, extended))
+
+		expiry, err = store.GetServiceAccountExpiry(ctx, sa.OrgID, sa.ID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, extended, expiry.ExpiresAt, time.Second)
+		assert.Equal(t, int64(10), expiry.AttestedBy)
+		assert.NotNil(t, expiry.AttestedAt)
+		assert.Nil(t, expiry.NotifiedAt)
+	})
+
+	t.Run("should remove expiry", func(t *testing.T) {
+		require.NoError(t, store.UpdateServiceAccountExpiry(ctx, sa.OrgID, sa.ID, nil))
+		_, err := store.GetServiceAccountExpiry(ctx, sa.OrgID, sa.ID)
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountExpiryNotFound)
+	})
+}
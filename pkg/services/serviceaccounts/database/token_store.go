@@ -51,6 +51,8 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 			Key:              cmd.Key,
 			SecondsToLive:    cmd.SecondsToLive,
 			ServiceAccountID: &serviceAccountId,
+			AllowedCIDRs:     cmd.AllowedCIDRs,
+			DeniedCIDRs:      cmd.DeniedCIDRs,
 		}
 
 		if err := s.apiKeyService.AddAPIKey(ctx, addKeyCmd); err != nil {
@@ -59,6 +61,8 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 				return ErrDuplicateToken
 			case errors.Is(err, apikey.ErrInvalidExpiration):
 				return ErrInvalidTokenExpiration
+			case errors.Is(err, apikey.ErrInvalidCIDR):
+				return ErrInvalidTokenAllowedCIDRs
 			}
 
 			return err
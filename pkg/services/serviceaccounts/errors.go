@@ -6,4 +6,6 @@ var (
 	ErrServiceAccountNotFound            = errors.New("service account not found")
 	ErrServiceAccountInvalidRole         = errors.New("invalid role specified")
 	ErrServiceAccountRolePrivilegeDenied = errors.New("can not assign a role higher than user's role")
+	ErrServiceAccountExpiryNotFound      = errors.New("service account has no expiry")
+	ErrServiceAccountExpiryInPast        = errors.New("service account expiry must be in the future")
 )
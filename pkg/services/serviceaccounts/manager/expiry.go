@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+const (
+	expiryCheckInterval     = time.Hour
+	defaultExpiryNoticeDays = 7
+	expiringEmailTemplate   = "service_account_expiring"
+	serviceAccountOwnerRole = "Admin"
+)
+
+// checkExpiry notifies the owners of service accounts that are about to expire
+// and disables service accounts that expired without being attested.
+func (sa *ServiceAccountsService) checkExpiry(ctx context.Context) error {
+	now := time.Now()
+	expiring, err := sa.store.ListExpiringServiceAccounts(ctx, now.Add(sa.expiryNoticePeriod))
+	if err != nil {
+		return err
+	}
+
+	for _, expiry := range expiring {
+		if !expiry.ExpiresAt.After(now) {
+			sa.disableExpired(ctx, expiry)
+			continue
+		}
+
+		if expiry.NotifiedAt != nil {
+			continue
+		}
+
+		if err := sa.notifyOwners(ctx, expiry); err != nil {
+			sa.backgroundLog.Warn("Failed to notify owners of expiring service account",
+				"serviceAccount", expiry.ServiceAccountId, "orgId", expiry.OrgId, "error", err)
+			continue
+		}
+
+		if err := sa.store.MarkServiceAccountExpiryNotified(ctx, expiry.OrgId, expiry.ServiceAccountId, now); err != nil {
+			sa.backgroundLog.Warn("Failed to mark service account expiry as notified",
+				"serviceAccount", expiry.ServiceAccountId, "orgId", expiry.OrgId, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (sa *ServiceAccountsService) disableExpired(ctx context.Context, expiry *serviceaccounts.ServiceAccountExpiryDTO) {
+	isDisabled := true
+	if _, err := sa.store.UpdateServiceAccount(ctx, expiry.OrgId, expiry.ServiceAccountId,
+		&serviceaccounts.UpdateServiceAccountForm{IsDisabled: &isDisabled}); err != nil {
+		sa.backgroundLog.Warn("Failed to disable expired service account",
+			"serviceAccount", expiry.ServiceAccountId, "orgId", expiry.OrgId, "error", err)
+		return
+	}
+
+	sa.backgroundLog.Info("Disabled expired service account",
+		"serviceAccount", expiry.ServiceAccountId, "orgId", expiry.OrgId, "expiredAt", expiry.ExpiresAt)
+}
+
+// notifyOwners sends an expiry notice to the users and teams with admin permission on the service account.
+func (sa *ServiceAccountsService) notifyOwners(ctx context.Context, expiry *serviceaccounts.ServiceAccountExpiryDTO) error {
+	if sa.permissionService == nil || sa.emailSender == nil {
+		return nil
+	}
+
+	backgroundUser := accesscontrol.BackgroundUser("serviceaccounts_expiry", expiry.OrgId, org.RoleAdmin,
+		[]accesscontrol.Permission{{Action: serviceaccounts.ActionPermissionsRead, Scope: serviceaccounts.ScopeAll}})
+
+	permissions, err := sa.permissionService.GetPermissions(ctx, backgroundUser, strconv.FormatInt(expiry.ServiceAccountId, 10))
+	if err != nil {
+		return err
+	}
+
+	recipients := make([]string, 0, len(permissions))
+	seen := make(map[string]bool)
+	for _, p := range permissions {
+		if sa.permissionService.MapActions(p) != serviceAccountOwnerRole {
+			continue
+		}
+
+		email := p.UserEmail
+		if p.TeamId > 0 {
+			email = p.TeamEmail
+		}
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		recipients = append(recipients, email)
+	}
+
+	if len(recipients) == 0 {
+		sa.backgroundLog.Debug("No owners to notify about expiring service account",
+			"serviceAccount", expiry.ServiceAccountId, "orgId", expiry.OrgId)
+		return nil
+	}
+
+	return sa.emailSender.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
+		To:       recipients,
+		Template: expiringEmailTemplate,
+		OrgID:    expiry.OrgId,
+		Data: map[string]interface{}{
+			"ServiceAccountName": expiry.Name,
+			"ExpiresAt":          expiry.ExpiresAt.UTC().Format(time.RFC1123),
+			"ServiceAccountUrl": fmt.Sprintf("%sorg/serviceaccounts/%d?orgId=%d",
+				sa.appURL, expiry.ServiceAccountId, expiry.OrgId),
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
+)
+
+func TestServiceAccountsService_CheckExpiry(t *testing.T) {
+	now := time.Now()
+	notifiedAt := now.Add(-time.Hour)
+
+	storeMock := &tests.ServiceAccountsStoreMock{
+		Calls: tests.Calls{},
+		Expiring: []*serviceaccounts.ServiceAccountExpiryDTO{
+			{OrgId: 1, ServiceAccountId: 1, ExpiresAt: now.Add(-time.Minute)},
+			{OrgId: 1, ServiceAccountId: 2, ExpiresAt: now.Add(24 * time.Hour)},
+			{OrgId: 1, ServiceAccountId: 3, ExpiresAt: now.Add(24 * time.Hour), NotifiedAt: &notifiedAt},
+		},
+	}
+	svc := ServiceAccountsService{
+		store:              storeMock,
+		backgroundLog:      log.New("test"),
+		expiryNoticePeriod: 7 * 24 * time.Hour,
+	}
+
+	require.NoError(t, svc.checkExpiry(context.Background()))
+
+	t.Run("should disable expired service accounts", func(t *testing.T) {
+		require.Len(t, storeMock.Calls.UpdateServiceAccount, 1)
+		args := storeMock.Calls.UpdateServiceAccount[0].([]interface{})
+		assert.Equal(t, int64(1), args[2])
+		form := args[3].(*serviceaccounts.UpdateServiceAccountForm)
+		require.NotNil(t, form.IsDisabled)
+		assert.True(t, *form.IsDisabled)
+	})
+
+	t.Run("should only notify owners once", func(t *testing.T) {
+		require.Len(t, storeMock.Calls.MarkServiceAccountExpiryNotified, 1)
+		args := storeMock.Calls.MarkServiceAccountExpiryNotified[0].([]interface{})
+		assert.Equal(t, int64(2), args[2])
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/api"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/secretscan"
@@ -28,6 +29,11 @@ type ServiceAccountsService struct {
 
 	secretScanEnabled  bool
 	secretScanInterval time.Duration
+
+	permissionService  accesscontrol.ServiceAccountPermissionsService
+	emailSender        notifications.EmailSender
+	appURL             string
+	expiryNoticePeriod time.Duration
 }
 
 func ProvideServiceAccountsService(
@@ -38,12 +44,22 @@ func ProvideServiceAccountsService(
 	serviceAccountsStore serviceaccounts.Store,
 	permissionService accesscontrol.ServiceAccountPermissionsService,
 	accesscontrolService accesscontrol.Service,
+	emailSender notifications.EmailSender,
 ) (*ServiceAccountsService, error) {
 	s := &ServiceAccountsService{
-		store:         serviceAccountsStore,
-		log:           log.New("serviceaccounts"),
-		backgroundLog: log.New("serviceaccounts.background"),
+		store:             serviceAccountsStore,
+		log:               log.New("serviceaccounts"),
+		backgroundLog:     log.New("serviceaccounts.background"),
+		permissionService: permissionService,
+		emailSender:       emailSender,
+		appURL:            cfg.AppURL,
+	}
+
+	expiryNoticeDays := cfg.SAExpiryNoticeDays
+	if expiryNoticeDays <= 0 {
+		expiryNoticeDays = defaultExpiryNoticeDays
 	}
+	s.expiryNoticePeriod = time.Duration(expiryNoticeDays) * 24 * time.Hour
 
 	if err := RegisterRoles(accesscontrolService); err != nil {
 		s.log.Error("Failed to register roles", "error", err)
@@ -75,6 +91,13 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 	updateStatsTicker := time.NewTicker(metricsCollectionInterval)
 	defer updateStatsTicker.Stop()
 
+	if err := sa.checkExpiry(ctx); err != nil {
+		sa.backgroundLog.Warn("Failed to check for expiring service accounts", "error", err.Error())
+	}
+
+	expiryCheckTicker := time.NewTicker(expiryCheckInterval)
+	defer expiryCheckTicker.Stop()
+
 	// Enforce a minimum interval of 1 minute.
 	if sa.secretScanInterval < time.Minute {
 		sa.backgroundLog.Warn("secret scan interval is too low, increasing to " +
@@ -118,6 +141,12 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if err := sa.secretScanService.CheckTokens(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to check for leaked tokens", "error", err.Error())
 			}
+		case <-expiryCheckTicker.C:
+			sa.backgroundLog.Debug("checking for expiring service accounts")
+
+			if err := sa.checkExpiry(ctx); err != nil {
+				sa.backgroundLog.Warn("Failed to check for expiring service accounts", "error", err.Error())
+			}
 		}
 	}
 }
@@ -70,11 +70,17 @@ type GetSATokensQuery struct {
 }
 
 type AddServiceAccountTokenCommand struct {
-	Name          string         `json:"name" binding:"Required"`
-	OrgId         int64          `json:"-"`
-	Key           string         `json:"-"`
-	SecondsToLive int64          `json:"secondsToLive"`
-	Result        *apikey.APIKey `json:"-"`
+	Name          string `json:"name" binding:"Required"`
+	OrgId         int64  `json:"-"`
+	Key           string `json:"-"`
+	SecondsToLive int64  `json:"secondsToLive"`
+	// List of IP addresses or CIDR networks the token can be used from. An empty list allows any address.
+	// example: ["10.0.0.0/8", "192.168.1.10"]
+	AllowedCIDRs []string `json:"allowedCidrs"`
+	// List of IP addresses or CIDR networks the token cannot be used from, even if they are allowed.
+	// example: ["10.0.1.0/24"]
+	DeniedCIDRs []string       `json:"deniedCidrs"`
+	Result      *apikey.APIKey `json:"-"`
 }
 
 // swagger: model
@@ -86,6 +92,7 @@ type SearchServiceAccountsResult struct {
 	ServiceAccounts []*ServiceAccountDTO `json:"serviceAccounts"`
 	Page            int                  `json:"page"`
 	PerPage         int                  `json:"perPage"`
+	NextCursor      string               `json:"nextCursor,omitempty"`
 }
 
 // swagger:model
@@ -114,6 +121,33 @@ type ServiceAccountProfileDTO struct {
 	AccessControl map[string]bool `json:"accessControl,omitempty" xorm:"-"`
 }
 
+// swagger:model
+type ServiceAccountExpiryDTO struct {
+	// example: 2
+	ServiceAccountId int64 `json:"serviceAccountId" xorm:"service_account_id"`
+	// example: 1
+	OrgId int64 `json:"orgId" xorm:"org_id"`
+	// example: 2022-06-21T14:35:33Z
+	ExpiresAt time.Time `json:"expiresAt" xorm:"expires_at"`
+	// example: 2022-03-21T14:35:33Z
+	AttestedAt *time.Time `json:"attestedAt,omitempty" xorm:"attested_at"`
+	// example: 1
+	AttestedBy int64 `json:"attestedBy,omitempty" xorm:"attested_by"`
+	// example: 2022-06-14T14:35:33Z
+	NotifiedAt *time.Time `json:"notifiedAt,omitempty" xorm:"notified_at"`
+	// example: sa-grafana
+	Login string `json:"login,omitempty" xorm:"login"`
+	// example: grafana
+	Name string `json:"name,omitempty" xorm:"name"`
+}
+
+// swagger:model
+type UpdateServiceAccountExpiryForm struct {
+	// Expiry date of the service account. Omitting it or setting it to null removes the expiry.
+	// example: 2022-06-21T14:35:33Z
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
 type ServiceAccountFilter string // used for filtering
 
 type APIKeysMigrationStatus struct {
@@ -2,7 +2,9 @@ package serviceaccounts
 
 import (
 	"context"
+	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -29,10 +31,15 @@ MigrateApiKey migrates a single API key to a service account.
 
 // only used for interal api calls
 RevertApiKey reverts a single service account to an API key.
+
+expiry and attestation:
+UpdateServiceAccountExpiry sets or removes the expiry of a service account.
+AttestServiceAccount confirms a service account is still needed and extends its expiry.
+ListExpiringServiceAccounts lists the enabled service accounts expiring before a given time.
 */
 type Store interface {
 	CreateServiceAccount(ctx context.Context, orgID int64, saForm *CreateServiceAccountForm) (*ServiceAccountDTO, error)
-	SearchOrgServiceAccounts(ctx context.Context, orgID int64, query string, filter ServiceAccountFilter, page int, limit int,
+	SearchOrgServiceAccounts(ctx context.Context, orgID int64, query string, filter ServiceAccountFilter, paging pagination.Params,
 		signedInUser *user.SignedInUser) (*SearchServiceAccountsResult, error)
 	UpdateServiceAccount(ctx context.Context, orgID, serviceAccountID int64,
 		saForm *UpdateServiceAccountForm) (*ServiceAccountProfileDTO, error)
@@ -49,4 +56,9 @@ type Store interface {
 	RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error
 	AddServiceAccountToken(ctx context.Context, serviceAccountID int64, cmd *AddServiceAccountTokenCommand) error
 	GetUsageMetrics(ctx context.Context) (*Stats, error)
+	GetServiceAccountExpiry(ctx context.Context, orgID, serviceAccountID int64) (*ServiceAccountExpiryDTO, error)
+	UpdateServiceAccountExpiry(ctx context.Context, orgID, serviceAccountID int64, expiresAt *time.Time) error
+	AttestServiceAccount(ctx context.Context, orgID, serviceAccountID, userID int64, expiresAt time.Time) error
+	ListExpiringServiceAccounts(ctx context.Context, before time.Time) ([]*ServiceAccountExpiryDTO, error)
+	MarkServiceAccountExpiryNotified(ctx context.Context, orgID, serviceAccountID int64, notifiedAt time.Time) error
 }
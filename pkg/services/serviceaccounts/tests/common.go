@@ -3,9 +3,11 @@ package tests
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
@@ -129,26 +131,29 @@ var _ serviceaccounts.Store = new(ServiceAccountsStoreMock)
 var _ serviceaccounts.Service = new(ServiceAccountMock)
 
 type Calls struct {
-	CreateServiceAccount            []interface{}
-	RetrieveServiceAccount          []interface{}
-	DeleteServiceAccount            []interface{}
-	GetAPIKeysMigrationStatus       []interface{}
-	HideApiKeysTab                  []interface{}
-	MigrateApiKeysToServiceAccounts []interface{}
-	MigrateApiKey                   []interface{}
-	RevertApiKey                    []interface{}
-	ListTokens                      []interface{}
-	DeleteServiceAccountToken       []interface{}
-	UpdateServiceAccount            []interface{}
-	AddServiceAccountToken          []interface{}
-	SearchOrgServiceAccounts        []interface{}
-	RetrieveServiceAccountIdByName  []interface{}
+	CreateServiceAccount             []interface{}
+	RetrieveServiceAccount           []interface{}
+	DeleteServiceAccount             []interface{}
+	GetAPIKeysMigrationStatus        []interface{}
+	HideApiKeysTab                   []interface{}
+	MigrateApiKeysToServiceAccounts  []interface{}
+	MigrateApiKey                    []interface{}
+	RevertApiKey                     []interface{}
+	ListTokens                       []interface{}
+	DeleteServiceAccountToken        []interface{}
+	UpdateServiceAccount             []interface{}
+	AddServiceAccountToken           []interface{}
+	SearchOrgServiceAccounts         []interface{}
+	RetrieveServiceAccountIdByName   []interface{}
+	ListExpiringServiceAccounts      []interface{}
+	MarkServiceAccountExpiryNotified []interface{}
 }
 
 type ServiceAccountsStoreMock struct {
 	serviceaccounts.Store
-	Stats *serviceaccounts.Stats
-	Calls Calls
+	Stats    *serviceaccounts.Stats
+	Expiring []*serviceaccounts.ServiceAccountExpiryDTO
+	Calls    Calls
 }
 
 func (s *ServiceAccountsStoreMock) RetrieveServiceAccountIdByName(ctx context.Context, orgID int64, name string) (int64, error) {
@@ -216,10 +221,9 @@ func (s *ServiceAccountsStoreMock) SearchOrgServiceAccounts(
 	orgID int64,
 	query string,
 	filter serviceaccounts.ServiceAccountFilter,
-	page int,
-	limit int,
+	paging pagination.Params,
 	user *user.SignedInUser) (*serviceaccounts.SearchServiceAccountsResult, error) {
-	s.Calls.SearchOrgServiceAccounts = append(s.Calls.SearchOrgServiceAccounts, []interface{}{ctx, orgID, query, page, limit, user})
+	s.Calls.SearchOrgServiceAccounts = append(s.Calls.SearchOrgServiceAccounts, []interface{}{ctx, orgID, query, paging, user})
 	return nil, nil
 }
 
@@ -240,3 +244,13 @@ func (s *ServiceAccountsStoreMock) GetUsageMetrics(ctx context.Context) (*servic
 
 	return s.Stats, nil
 }
+
+func (s *ServiceAccountsStoreMock) ListExpiringServiceAccounts(ctx context.Context, before time.Time) ([]*serviceaccounts.ServiceAccountExpiryDTO, error) {
+	s.Calls.ListExpiringServiceAccounts = append(s.Calls.ListExpiringServiceAccounts, []interface{}{ctx, before})
+	return s.Expiring, nil
+}
+
+func (s *ServiceAccountsStoreMock) MarkServiceAccountExpiryNotified(ctx context.Context, orgID, serviceAccountID int64, notifiedAt time.Time) error {
+	s.Calls.MarkServiceAccountExpiryNotified = append(s.Calls.MarkServiceAccountExpiryNotified, []interface{}{ctx, orgID, serviceAccountID, notifiedAt})
+	return nil
+}
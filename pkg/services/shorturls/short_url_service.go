@@ -3,6 +3,7 @@ package shorturls
 import (
 	"context"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 
@@ -10,10 +11,20 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
+	"xorm.io/xorm"
 )
 
 var getTime = time.Now
 
+// slugPattern matches the custom slugs of short URLs. Slugs are used as the UID of the short
+// URL, so they are limited to the characters of generated UIDs.
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{2,39}$`)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
 func ProvideService(sqlStore db.DB) *ShortURLService {
 	return &ShortURLService{
 		SQLStore: sqlStore,
@@ -22,7 +33,10 @@ func ProvideService(sqlStore db.DB) *ShortURLService {
 
 type Service interface {
 	GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*models.ShortUrl, error)
-	CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*models.ShortUrl, error)
+	CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *models.CreateShortURLCommand) (*models.ShortUrl, error)
+	UpdateShortURL(ctx context.Context, cmd *models.UpdateShortURLCommand) (*models.ShortUrl, error)
+	DeleteShortURL(ctx context.Context, orgID int64, uid string) error
+	SearchShortURLs(ctx context.Context, query *models.SearchShortURLsQuery) (*models.SearchShortURLsResult, error)
 	UpdateLastSeenAt(ctx context.Context, shortURL *models.ShortUrl) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error
 }
@@ -31,10 +45,24 @@ type ShortURLService struct {
 	SQLStore db.DB
 }
 
+// GetShortURLByUID returns the short URL of the user's organization. Expired short URLs are
+// reported with ErrShortURLExpired until they are deleted by the cleanup service.
 func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*models.ShortUrl, error) {
+	shortURL, err := s.getShortURL(ctx, user.OrgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if shortURL.IsExpired(getTime()) {
+		return nil, models.ErrShortURLExpired.Errorf("short URL expired")
+	}
+
+	return shortURL, nil
+}
+
+func (s ShortURLService) getShortURL(ctx context.Context, orgID int64, uid string) (*models.ShortUrl, error) {
 	var shortURL models.ShortUrl
 	err := s.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		exists, err := dbSession.Where("org_id=? AND uid=?", user.OrgID, uid).Get(&shortURL)
+		exists, err := dbSession.Where("org_id=? AND uid=?", orgID, uid).Get(&shortURL)
 		if err != nil {
 			return err
 		}
@@ -51,53 +79,190 @@ func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *user.Signed
 	return &shortURL, nil
 }
 
+// UpdateLastSeenAt records a visit of the short URL. The hits are incremented in the database
+// so that concurrent visits are all counted.
 func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *models.ShortUrl) error {
 	shortURL.LastSeenAt = getTime().Unix()
 	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
-		_, err := dbSession.ID(shortURL.Id).Update(shortURL)
+		_, err := dbSession.Exec("UPDATE short_url SET hits = hits + 1, last_seen_at = ? WHERE id = ?", shortURL.LastSeenAt, shortURL.Id)
 		if err != nil {
 			return err
 		}
+		shortURL.Hits++
 
 		return nil
 	})
 }
 
-func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, relPath string) (*models.ShortUrl, error) {
-	relPath = strings.TrimSpace(relPath)
-
-	if path.IsAbs(relPath) {
-		return nil, models.ErrShortURLAbsolutePath.Errorf("expected relative path: %s", relPath)
+func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *models.CreateShortURLCommand) (*models.ShortUrl, error) {
+	relPath, err := validatePath(cmd.Path)
+	if err != nil {
+		return nil, err
 	}
-	if strings.Contains(relPath, "../") {
-		return nil, models.ErrShortURLInvalidPath.Errorf("path cannot contain '../': %s", relPath)
+	if cmd.ExpiresIn < 0 {
+		return nil, models.ErrShortURLInvalidExpiry.Errorf("expiry must not be negative: %s", cmd.ExpiresIn)
 	}
 
-	now := time.Now().Unix()
+	now := getTime()
 	shortURL := models.ShortUrl{
 		OrgId:     user.OrgID,
 		Uid:       util.GenerateShortUID(),
 		Path:      relPath,
 		CreatedBy: user.UserID,
-		CreatedAt: now,
+		CreatedAt: now.Unix(),
+	}
+	if cmd.Slug != "" {
+		if !slugPattern.MatchString(cmd.Slug) {
+			return nil, models.ErrShortURLInvalidSlug.Errorf("invalid slug: %s", cmd.Slug)
+		}
+		shortURL.Uid = cmd.Slug
+		shortURL.CustomSlug = true
 	}
+	if cmd.ExpiresIn > 0 {
+		shortURL.ExpiresAt = now.Add(cmd.ExpiresIn).Unix()
+	}
+
+	err = s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		if shortURL.CustomSlug {
+			exists, err := session.Where("org_id=? AND uid=?", shortURL.OrgId, shortURL.Uid).Exist(&models.ShortUrl{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return models.ErrShortURLSlugTaken.Errorf("slug already in use: %s", shortURL.Uid)
+			}
+		}
 
-	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
 		_, err := session.Insert(&shortURL)
 		return err
 	})
 	if err != nil {
+		if models.ErrShortURLSlugTaken.Is(err) {
+			return nil, err
+		}
+		if s.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+			return nil, models.ErrShortURLSlugTaken.Errorf("slug already in use: %s", shortURL.Uid)
+		}
 		return nil, models.ErrShortURLInternal.Errorf("failed to insert shorturl: %w", err)
 	}
 
 	return &shortURL, nil
 }
 
+// UpdateShortURL changes the path or the expiry of a short URL. The UID is kept so that
+// links which were already shared keep working.
+func (s ShortURLService) UpdateShortURL(ctx context.Context, cmd *models.UpdateShortURLCommand) (*models.ShortUrl, error) {
+	shortURL, err := s.getShortURL(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Path != nil {
+		relPath, err := validatePath(*cmd.Path)
+		if err != nil {
+			return nil, err
+		}
+		shortURL.Path = relPath
+	}
+	if cmd.ExpiresIn != nil {
+		switch {
+		case *cmd.ExpiresIn < 0:
+			return nil, models.ErrShortURLInvalidExpiry.Errorf("expiry must not be negative: %s", *cmd.ExpiresIn)
+		case *cmd.ExpiresIn == 0:
+			shortURL.ExpiresAt = 0
+		default:
+			shortURL.ExpiresAt = getTime().Add(*cmd.ExpiresIn).Unix()
+		}
+	}
+
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		_, err := session.ID(shortURL.Id).Cols("path", "expires_at").Update(shortURL)
+		return err
+	})
+	if err != nil {
+		return nil, models.ErrShortURLInternal.Errorf("failed to update shorturl: %w", err)
+	}
+
+	return shortURL, nil
+}
+
+func (s ShortURLService) DeleteShortURL(ctx context.Context, orgID int64, uid string) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		deleted, err := session.Where("org_id=? AND uid=?", orgID, uid).Delete(&models.ShortUrl{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return models.ErrShortURLNotFound.Errorf("short URL not found")
+		}
+		return nil
+	})
+}
+
+// SearchShortURLs lists the short URLs of an organization with their usage.
+func (s ShortURLService) SearchShortURLs(ctx context.Context, query *models.SearchShortURLsQuery) (*models.SearchShortURLsResult, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	page := query.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	order := "created_at DESC"
+	switch query.Sort {
+	case "hits":
+		order = "hits DESC"
+	case "lastSeen":
+		order = "last_seen_at DESC"
+	}
+
+	result := &models.SearchShortURLsResult{
+		ShortURLs: make([]*models.ShortUrl, 0),
+		Page:      page,
+		PerPage:   limit,
+	}
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		filter := func() *xorm.Session {
+			sess := dbSession.Where("org_id=?", query.OrgID)
+			if query.Query != "" {
+				like := "%" + query.Query + "%"
+				sess = sess.And("(uid "+s.SQLStore.GetDialect().LikeStr()+" ? OR path "+s.SQLStore.GetDialect().LikeStr()+" ?)", like, like)
+			}
+			if query.CreatedBy > 0 {
+				sess = sess.And("created_by=?", query.CreatedBy)
+			}
+			return sess
+		}
+
+		count, err := filter().Count(&models.ShortUrl{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		return filter().OrderBy(order+", id DESC").Limit(limit, (page-1)*limit).Find(&result.ShortURLs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteStaleShortURLs deletes expired short URLs and short URLs which were never visited.
+// Short URLs with a custom slug or an expiry are kept until they expire.
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error {
 	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
-		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)"
+		var rawSql = "DELETE FROM short_url WHERE " +
+			"(expires_at > 0 AND expires_at <= ?) OR " +
+			"(created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0) AND (expires_at IS NULL OR expires_at = 0) AND custom_slug = " + s.SQLStore.GetDialect().BooleanStr(false) + ")"
 
-		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix()); err != nil {
+		if result, err := session.Exec(rawSql, getTime().Unix(), cmd.OlderThan.Unix()); err != nil {
 			return err
 		} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
 			return err
@@ -106,4 +271,16 @@ func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *models.D
 	})
 }
 
+func validatePath(relPath string) (string, error) {
+	relPath = strings.TrimSpace(relPath)
+
+	if path.IsAbs(relPath) {
+		return "", models.ErrShortURLAbsolutePath.Errorf("expected relative path: %s", relPath)
+	}
+	if strings.Contains(relPath, "../") {
+		return "", models.ErrShortURLInvalidPath.Errorf("path cannot contain '../': %s", relPath)
+	}
+	return relPath, nil
+}
+
 var _ Service = &ShortURLService{}
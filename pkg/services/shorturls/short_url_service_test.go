@@ -21,7 +21,7 @@ func TestShortURLService(t *testing.T) {
 
 		service := ShortURLService{SQLStore: sqlStore}
 
-		newShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+		newShortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortURLCommand{Path: refPath})
 		require.NoError(t, err)
 		require.NotNil(t, newShortURL)
 		require.NotEmpty(t, newShortURL.Uid)
@@ -48,10 +48,18 @@ func TestShortURLService(t *testing.T) {
 			updatedShortURL, err := service.GetShortURLByUID(context.Background(), user, existingShortURL.Uid)
 			require.NoError(t, err)
 			require.Equal(t, expectedTime.Unix(), updatedShortURL.LastSeenAt)
+			require.Equal(t, int64(1), updatedShortURL.Hits)
+
+			err = service.UpdateLastSeenAt(context.Background(), updatedShortURL)
+			require.NoError(t, err)
+
+			updatedShortURL, err = service.GetShortURLByUID(context.Background(), user, existingShortURL.Uid)
+			require.NoError(t, err)
+			require.Equal(t, int64(2), updatedShortURL.Hits)
 		})
 
 		t.Run("and stale short urls can be deleted", func(t *testing.T) {
-			staleShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+			staleShortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortURLCommand{Path: refPath})
 			require.NoError(t, err)
 			require.NotNil(t, staleShortURL)
 			require.NotEmpty(t, staleShortURL.Uid)
@@ -84,4 +92,111 @@ func TestShortURLService(t *testing.T) {
 		require.True(t, models.ErrShortURLNotFound.Is(err))
 		require.Nil(t, shortURL)
 	})
+
+	t.Run("User can create short URLs with a custom slug", func(t *testing.T) {
+		service := ShortURLService{SQLStore: sqlStore}
+
+		shortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortURLCommand{Path: "d/abc", Slug: "team-overview"})
+		require.NoError(t, err)
+		require.Equal(t, "team-overview", shortURL.Uid)
+		require.True(t, shortURL.CustomSlug)
+
+		t.Run("which must be unique in the organization", func(t *testing.T) {
+			_, err := service.CreateShortURL(context.Background(), user, &models.CreateShortURLCommand{Path: "d/def", Slug: "team-overview"})
+			require.True(t, models.ErrShortURLSlugTaken.Is(err))
+
+			otherOrgUser := *user
+			otherOrgUser.OrgID = 2
+			_, err = service.CreateShortURL(context.Background(), &otherOrgUser, &models.CreateShortURLCommand{Path: "d/def", Slug: "team-overview"})
+			require.NoError(t, err)
+		})
+
+		t.Run("which must be valid", func(t *testing.T) {
+			for _, slug := range []string{"ab", "Team", "-team", "team overview", "team/overview"} {
+				_, err := service.CreateShortURL(context.Background(), user, &models.CreateShortURLCommand{Path: "d/def", Slug: slug})
+				require.True(t, models.ErrShortURLInvalidSlug.Is(err), slug)
+			}
+		})
+
+		t.Run("which are not deleted for not being visited", func(t *testing.T) {
+			cmd := models.DeleteShortUrlCommand{OlderThan: time.Unix(shortURL.CreatedAt, 0)}
+			require.NoError(t, service.DeleteStaleShortURLs(context.Background(), &cmd))
+
+			_, err := service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Short URLs stop redirecting once expired", func(t *testing.T) {
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+		now := time.Date(2022, time.October, 1, 12, 0, 0, 0, time.UTC)
+		getTime = func() time.Time {
+			return now
+		}
+
+		service := ShortURLService{SQLStore: sqlStore}
+		shortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortURLCommand{Path: "d/abc", ExpiresIn: time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, now.Add(time.Hour).Unix(), shortURL.ExpiresAt)
+
+		_, err = service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Hour)
+		_, err = service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+		require.True(t, models.ErrShortURLExpired.Is(err))
+
+		t.Run("and are deleted by the cleanup", func(t *testing.T) {
+			cmd := models.DeleteShortUrlCommand{OlderThan: now.Add(-7 * 24 * time.Hour)}
+			require.NoError(t, service.DeleteStaleShortURLs(context.Background(), &cmd))
+			require.Equal(t, int64(1), cmd.NumDeleted)
+
+			_, err = service.GetShortURLByUID(context.Background(), user, shortURL.Uid)
+			require.True(t, models.ErrShortURLNotFound.Is(err))
+		})
+	})
+
+	t.Run("Org admins can manage the short URLs of their organization", func(t *testing.T) {
+		service := ShortURLService{SQLStore: sqlStore}
+		orgUser := *user
+		orgUser.UserID, orgUser.OrgID = 3, 3
+
+		shortURL, err := service.CreateShortURL(context.Background(), &orgUser, &models.CreateShortURLCommand{Path: "d/abc", Slug: "incident-board"})
+		require.NoError(t, err)
+		_, err = service.CreateShortURL(context.Background(), &orgUser, &models.CreateShortURLCommand{Path: "d/def"})
+		require.NoError(t, err)
+		require.NoError(t, service.UpdateLastSeenAt(context.Background(), shortURL))
+
+		result, err := service.SearchShortURLs(context.Background(), &models.SearchShortURLsQuery{OrgID: 3, Sort: "hits"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+		require.Equal(t, "incident-board", result.ShortURLs[0].Uid)
+		require.Equal(t, int64(1), result.ShortURLs[0].Hits)
+
+		result, err = service.SearchShortURLs(context.Background(), &models.SearchShortURLsQuery{OrgID: 3, Query: "incident"})
+		require.NoError(t, err)
+		require.Len(t, result.ShortURLs, 1)
+
+		newPath := "d/xyz"
+		expiresIn := 24 * time.Hour
+		updated, err := service.UpdateShortURL(context.Background(), &models.UpdateShortURLCommand{UID: "incident-board", OrgID: 3, Path: &newPath, ExpiresIn: &expiresIn})
+		require.NoError(t, err)
+		require.Equal(t, newPath, updated.Path)
+		require.NotZero(t, updated.ExpiresAt)
+
+		noExpiry := time.Duration(0)
+		updated, err = service.UpdateShortURL(context.Background(), &models.UpdateShortURLCommand{UID: "incident-board", OrgID: 3, ExpiresIn: &noExpiry})
+		require.NoError(t, err)
+		require.Zero(t, updated.ExpiresAt)
+
+		_, err = service.UpdateShortURL(context.Background(), &models.UpdateShortURLCommand{UID: "incident-board", OrgID: 1, Path: &newPath})
+		require.True(t, models.ErrShortURLNotFound.Is(err))
+
+		require.NoError(t, service.DeleteShortURL(context.Background(), 3, "incident-board"))
+		err = service.DeleteShortURL(context.Background(), 3, "incident-board")
+		require.True(t, models.ErrShortURLNotFound.Is(err))
+	})
 }
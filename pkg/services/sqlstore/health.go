@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 )
 
 // GetDBHealthQuery executes a query to check
@@ -14,3 +15,26 @@ func (ss *SQLStore) GetDBHealthQuery(ctx context.Context, query *models.GetDBHea
 		return err
 	})
 }
+
+// GetPendingMigrations returns the IDs of the migrations which were not
+// applied successfully to the database.
+func (ss *SQLStore) GetPendingMigrations(ctx context.Context, query *models.GetPendingMigrationsQuery) error {
+	query.Result = make([]string, 0)
+	if ss.dbCfg.SkipMigrations || ss.migrations == nil {
+		return nil
+	}
+
+	mg := migrator.NewMigrator(ss.engine, ss.Cfg)
+	ss.migrations.AddMigration(mg)
+	migrationLog, err := mg.GetMigrationLog()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range mg.GetMigrationIDs(true) {
+		if _, ok := migrationLog[id]; !ok {
+			query.Result = append(query.Result, id)
+		}
+	}
+	return nil
+}
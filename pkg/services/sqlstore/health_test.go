@@ -18,3 +18,15 @@ func TestIntegrationGetDBHealthQuery(t *testing.T) {
 	err := store.GetDBHealthQuery(context.Background(), &query)
 	require.NoError(t, err)
 }
+
+func TestIntegrationGetPendingMigrations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := InitTestDB(t)
+
+	query := models.GetPendingMigrationsQuery{}
+	err := store.GetPendingMigrations(context.Background(), &query)
+	require.NoError(t, err)
+	require.Empty(t, query.Result)
+}
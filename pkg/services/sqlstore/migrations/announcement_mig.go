@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAnnouncementMigrations(mg *Migrator) {
+	announcementV1 := Table{
+		Name: "announcement",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "title", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: true},
+			{Name: "severity", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "url", Type: DB_NVarchar, Length: 1024, Nullable: true},
+			{Name: "dismissible", Type: DB_Bool, Nullable: false},
+			{Name: "org_ids", Type: DB_Text, Nullable: true},
+			{Name: "roles", Type: DB_Text, Nullable: true},
+			{Name: "starts_at", Type: DB_DateTime, Nullable: true},
+			{Name: "ends_at", Type: DB_DateTime, Nullable: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"ends_at"}},
+		},
+	}
+
+	mg.AddMigration("create announcement table v1", NewAddTableMigration(announcementV1))
+	mg.AddMigration("add index announcement.ends_at", NewAddIndexMigration(announcementV1, announcementV1.Indices[0]))
+
+	dismissalV1 := Table{
+		Name: "announcement_dismissal",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "announcement_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"announcement_id", "user_id"}, Type: UniqueIndex},
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create announcement_dismissal table v1", NewAddTableMigration(dismissalV1))
+	mg.AddMigration("add unique index announcement_dismissal.announcement_id_user_id", NewAddIndexMigration(dismissalV1, dismissalV1.Indices[0]))
+	mg.AddMigration("add index announcement_dismissal.user_id", NewAddIndexMigration(dismissalV1, dismissalV1.Indices[1]))
+}
@@ -100,4 +100,12 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add is_revoked column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "is_revoked", Type: DB_Bool, Nullable: true, Default: "0",
 	}))
+
+	mg.AddMigration("Add allowed_cidrs column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "allowed_cidrs", Type: DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("Add denied_cidrs column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "denied_cidrs", Type: DB_Text, Nullable: true,
+	}))
 }
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addContentSyncMigrations(mg *Migrator) {
+	contentSyncChangeV1 := Table{
+		Name: "content_sync_change",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create content_sync_change table v1", NewAddTableMigration(contentSyncChangeV1))
+	mg.AddMigration("add index content_sync_change.created", NewAddIndexMigration(contentSyncChangeV1, contentSyncChangeV1.Indices[0]))
+}
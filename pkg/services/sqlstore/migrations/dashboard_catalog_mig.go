@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardCatalogMigrations(mg *Migrator) {
+	entryV1 := Table{
+		Name: "dashboard_catalog_entry",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "description", Type: DB_Text, Nullable: true},
+			{Name: "version", Type: DB_Int, Nullable: false},
+			{Name: "published_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_catalog_entry table v1", NewAddTableMigration(entryV1))
+	mg.AddMigration("add unique index dashboard_catalog_entry.uid", NewAddIndexMigration(entryV1, entryV1.Indices[0]))
+	mg.AddMigration("add unique index dashboard_catalog_entry.org_id_dashboard_uid", NewAddIndexMigration(entryV1, entryV1.Indices[1]))
+
+	subscriptionV1 := Table{
+		Name: "dashboard_catalog_subscription",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "entry_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "synced_version", Type: DB_Int, Nullable: false},
+			{Name: "last_synced", Type: DB_DateTime, Nullable: true},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"entry_uid", "org_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_catalog_subscription table v1", NewAddTableMigration(subscriptionV1))
+	mg.AddMigration("add unique index dashboard_catalog_subscription.entry_uid_org_id", NewAddIndexMigration(subscriptionV1, subscriptionV1.Indices[0]))
+	mg.AddMigration("add index dashboard_catalog_subscription.org_id", NewAddIndexMigration(subscriptionV1, subscriptionV1.Indices[1]))
+}
@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardReviewMigrations(mg *Migrator) {
+	policyV1 := Table{
+		Name: "dashboard_review_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "folder_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "reviewer_user_ids", Type: DB_Text, Nullable: false},
+			{Name: "reviewer_team_ids", Type: DB_Text, Nullable: false},
+			{Name: "required_approvals", Type: DB_Int, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "folder_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_review_policy table v1", NewAddTableMigration(policyV1))
+	addTableIndicesMigrations(mg, "v1", policyV1)
+
+	draftV1 := Table{
+		Name: "dashboard_draft",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "folder_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "data", Type: DB_MediumText, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "parent_version", Type: DB_Int, Nullable: false},
+			{Name: "applied_version", Type: DB_Int, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+			{Cols: []string{"org_id", "status"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_draft table v1", NewAddTableMigration(draftV1))
+	addTableIndicesMigrations(mg, "v1", draftV1)
+
+	reviewV1 := Table{
+		Name: "dashboard_draft_review",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "draft_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "decision", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "comment", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"draft_id", "user_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_draft_review table v1", NewAddTableMigration(reviewV1))
+	addTableIndicesMigrations(mg, "v1", reviewV1)
+}
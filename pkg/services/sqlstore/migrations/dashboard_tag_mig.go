@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/tag"
+)
+
+// addDashboardTagMigrations stores the key and the value of namespaced dashboard tags like
+// `env:prod` next to the term, so that they can be queried separately.
+func addDashboardTagMigrations(mg *migrator.Migrator) {
+	dashboardTag := migrator.Table{Name: "dashboard_tag"}
+
+	mg.AddMigration("add tag_key column to dashboard_tag", migrator.NewAddColumnMigration(dashboardTag, &migrator.Column{
+		Name: "tag_key", Type: migrator.DB_NVarchar, Length: 50, Nullable: true,
+	}))
+	mg.AddMigration("add tag_value column to dashboard_tag", migrator.NewAddColumnMigration(dashboardTag, &migrator.Column{
+		Name: "tag_value", Type: migrator.DB_NVarchar, Length: 50, Nullable: true,
+	}))
+	mg.AddMigration("add index dashboard_tag.tag_key_tag_value", migrator.NewAddIndexMigration(dashboardTag, &migrator.Index{
+		Cols: []string{"tag_key", "tag_value"},
+	}))
+	mg.AddMigration("split dashboard_tag terms into key and value", &splitDashboardTagTermsMigration{})
+}
+
+type splitDashboardTagTermsMigration struct {
+	migrator.MigrationBase
+}
+
+func (m *splitDashboardTagTermsMigration) SQL(dialect migrator.Dialect) string {
+	return "code migration"
+}
+
+func (m *splitDashboardTagTermsMigration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
+	var terms []string
+	if err := sess.SQL("SELECT DISTINCT term FROM dashboard_tag WHERE tag_key IS NULL").Find(&terms); err != nil {
+		return err
+	}
+
+	for _, term := range terms {
+		key, value := tag.SplitTerm(term)
+		if _, err := sess.Exec("UPDATE dashboard_tag SET tag_key = ?, tag_value = ? WHERE term = ?", key, value, term); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardTemplateMigrations(mg *Migrator) {
+	dashboardTemplateV1 := Table{
+		Name: "dashboard_template",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "description", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "parameters", Type: DB_Text, Nullable: false},
+			{Name: "dashboard", Type: DB_MediumText, Nullable: false},
+			{Name: "version", Type: DB_Int, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_template table v1", NewAddTableMigration(dashboardTemplateV1))
+	mg.AddMigration("add unique index dashboard_template.org_id_uid", NewAddIndexMigration(dashboardTemplateV1, dashboardTemplateV1.Indices[0]))
+
+	dashboardTemplateInstanceV1 := Table{
+		Name: "dashboard_template_instance",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "template_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "params", Type: DB_Text, Nullable: false},
+			{Name: "params_key", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "template_version", Type: DB_Int, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"template_id", "params_key"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_template_instance table v1", NewAddTableMigration(dashboardTemplateInstanceV1))
+	mg.AddMigration("add unique index dashboard_template_instance.template_id_params_key", NewAddIndexMigration(dashboardTemplateInstanceV1, dashboardTemplateInstanceV1.Indices[0]))
+	mg.AddMigration("add index dashboard_template_instance.org_id_dashboard_uid", NewAddIndexMigration(dashboardTemplateInstanceV1, dashboardTemplateInstanceV1.Indices[1]))
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardUIDReservationMigrations(mg *Migrator) {
+	reservationV1 := Table{
+		Name: "dashboard_uid_reservation",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "owner", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_uid_reservation table v1", NewAddTableMigration(reservationV1))
+	mg.AddMigration("add unique index dashboard_uid_reservation.org_id_uid", NewAddIndexMigration(reservationV1, reservationV1.Indices[0]))
+}
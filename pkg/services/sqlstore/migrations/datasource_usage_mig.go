@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDataSourceUsageMigrations(mg *Migrator) {
+	dataSourceUsageV1 := Table{
+		Name: "data_source_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "data_source_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "kind", "resource_uid", "data_source_uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "data_source_uid"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_usage table v1", NewAddTableMigration(dataSourceUsageV1))
+	addTableIndicesMigrations(mg, "v1", dataSourceUsageV1)
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addEditLockMigrations(mg *Migrator) {
+	editLockV1 := Table{
+		Name: "edit_lock",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "acquired", Type: DB_DateTime, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "kind", "resource_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create edit_lock table v1", NewAddTableMigration(editLockV1))
+	addTableIndicesMigrations(mg, "v1", editLockV1)
+}
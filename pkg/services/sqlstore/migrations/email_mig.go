@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addEmailMigrations(mg *Migrator) {
+	senderV1 := Table{
+		Name: "org_email_sender",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "from_address", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "from_name", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "reply_to", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org_email_sender table v1", NewAddTableMigration(senderV1))
+	mg.AddMigration("add unique index org_email_sender.org_id", NewAddIndexMigration(senderV1, senderV1.Indices[0]))
+
+	deliveryV1 := Table{
+		Name: "email_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "provider", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "provider_message_id", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "subject", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "template", Type: DB_NVarchar, Length: 100, Nullable: true},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "status"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create email_delivery table v1", NewAddTableMigration(deliveryV1))
+	mg.AddMigration("add index email_delivery.org_id_status", NewAddIndexMigration(deliveryV1, deliveryV1.Indices[0]))
+	mg.AddMigration("add index email_delivery.created", NewAddIndexMigration(deliveryV1, deliveryV1.Indices[1]))
+
+	templateV1 := Table{
+		Name: "email_template",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "subject", Type: DB_Text, Nullable: true},
+			{Name: "html_body", Type: DB_MediumText, Nullable: true},
+			{Name: "text_body", Type: DB_MediumText, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create email_template table v1", NewAddTableMigration(templateV1))
+	mg.AddMigration("add unique index email_template.org_id_name", NewAddIndexMigration(templateV1, templateV1.Indices[0]))
+}
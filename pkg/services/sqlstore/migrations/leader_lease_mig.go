@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addLeaderLeaseMigrations(mg *Migrator) {
+	leaderLeaseV1 := Table{
+		Name: "leader_lease",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "holder", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+			{Name: "acquired", Type: DB_BigInt, Nullable: false},
+			{Name: "renewed", Type: DB_BigInt, Nullable: false},
+			{Name: "expires", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create leader_lease table v1", NewAddTableMigration(leaderLeaseV1))
+	mg.AddMigration("add unique index leader_lease.name", NewAddIndexMigration(leaderLeaseV1, leaderLeaseV1.Indices[0]))
+}
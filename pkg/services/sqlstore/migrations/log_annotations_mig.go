@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addLogAnnotationMigrations(mg *Migrator) {
+	jobV1 := Table{
+		Name: "log_annotation_job",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "data_source_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "query", Type: DB_Text, Nullable: false},
+			{Name: "run_interval", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "mapping", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "materialized_until", Type: DB_DateTime, Nullable: true},
+			{Name: "backfill_from", Type: DB_DateTime, Nullable: true},
+			{Name: "backfill_until", Type: DB_DateTime, Nullable: true},
+			{Name: "last_error", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create log_annotation_job table v1", NewAddTableMigration(jobV1))
+	addTableIndicesMigrations(mg, "v1", jobV1)
+
+	lineV1 := Table{
+		Name: "log_annotation_line",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "job_id", Type: DB_BigInt, Nullable: false},
+			{Name: "line_hash", Type: DB_Char, Length: 64, Nullable: false},
+			{Name: "line_time", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"job_id", "line_hash"}, Type: UniqueIndex},
+			{Cols: []string{"job_id", "line_time"}},
+			{Cols: []string{"line_time"}},
+		},
+	}
+
+	mg.AddMigration("create log_annotation_line table v1", NewAddTableMigration(lineV1))
+	addTableIndicesMigrations(mg, "v1", lineV1)
+}
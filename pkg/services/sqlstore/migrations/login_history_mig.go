@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addLoginHistoryMigrations(mg *Migrator) {
+	loginHistoryV1 := Table{
+		Name: "login_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "auth_module", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "user_agent", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "success", Type: DB_Bool, Nullable: false},
+			{Name: "error", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create login_history table v1", NewAddTableMigration(loginHistoryV1))
+	mg.AddMigration("add index login_history.user_id_created", NewAddIndexMigration(loginHistoryV1, loginHistoryV1.Indices[0]))
+	mg.AddMigration("add index login_history.created", NewAddIndexMigration(loginHistoryV1, loginHistoryV1.Indices[1]))
+}
@@ -107,6 +107,38 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 
 	AddExternalAlertmanagerToDatasourceMigration(mg)
 
+	addServiceAccountExpiryMigrations(mg)
+
+	addLoginHistoryMigrations(mg)
+	addPluginJobMigrations(mg)
+	addScheduledReportMigrations(mg)
+	addEmailMigrations(mg)
+	addUserNotificationMigrations(mg)
+	addAnnouncementMigrations(mg)
+	addDashboardTagMigrations(mg)
+	addDashboardCatalogMigrations(mg)
+	addResourceLabelMigrations(mg)
+	addWebhookMigrations(mg)
+	addQueryCaptureMigrations(mg)
+	addLeaderLeaseMigrations(mg)
+	addNetworkPolicyMigrations(mg)
+	addEmbedTokenMigrations(mg)
+	addDashboardSubscriptionMigrations(mg)
+	addDashboardReviewMigrations(mg)
+	addEditLockMigrations(mg)
+	addOnCallMigrations(mg)
+	addSyntheticsMigrations(mg)
+	addDataSourceUsageMigrations(mg)
+	addTraceAnnotationMigrations(mg)
+	addLogAnnotationMigrations(mg)
+	addVariableConstraintMigrations(mg)
+	addSemaphoreMigrations(mg)
+	addOrgTokenMigrations(mg)
+	addContentSyncMigrations(mg)
+	addDashboardTemplateMigrations(mg)
+	addDashboardUIDReservationMigrations(mg)
+	addUserExportMigrations(mg)
+
 	// TODO: This migration will be enabled later in the nested folder feature
 	// implementation process. It is on hold so we can continue working on the
 	// store implementation without impacting any grafana instances built off
@@ -146,4 +178,22 @@ func addStarMigrations(mg *Migrator) {
 
 	mg.AddMigration("create star table", NewAddTableMigration(starV1))
 	mg.AddMigration("add unique index star.user_id_dashboard_id", NewAddIndexMigration(starV1, starV1.Indices[0]))
+
+	resourceStarV1 := Table{
+		Name: "resource_star",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "org_id", "kind", "resource_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create resource_star table", NewAddTableMigration(resourceStarV1))
+	mg.AddMigration("add unique index resource_star.user_id_org_id_kind_resource_uid", NewAddIndexMigration(resourceStarV1, resourceStarV1.Indices[0]))
 }
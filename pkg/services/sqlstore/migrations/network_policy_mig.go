@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addNetworkPolicyMigrations(mg *Migrator) {
+	orgNetworkPolicyV1 := Table{
+		Name: "org_network_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "allowed_cidrs", Type: DB_Text, Nullable: true},
+			{Name: "denied_cidrs", Type: DB_Text, Nullable: true},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org_network_policy table v1", NewAddTableMigration(orgNetworkPolicyV1))
+	mg.AddMigration("add unique index org_network_policy.org_id", NewAddIndexMigration(orgNetworkPolicyV1, orgNetworkPolicyV1.Indices[0]))
+
+	networkPolicyRejectionV1 := Table{
+		Name: "network_policy_rejection",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "api_key_id", Type: DB_BigInt, Nullable: false},
+			{Name: "policy", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "method", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "path", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create network_policy_rejection table v1", NewAddTableMigration(networkPolicyRejectionV1))
+	addTableIndicesMigrations(mg, "v1", networkPolicyRejectionV1)
+
+	mg.AddMigration("add count column to network_policy_rejection", NewAddColumnMigration(networkPolicyRejectionV1, &Column{
+		Name: "count", Type: DB_BigInt, Nullable: false, Default: "1",
+	}))
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addOrgTokenMigrations(mg *Migrator) {
+	orgTokenV1 := Table{
+		Name: "org_token",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "scopes", Type: DB_Text, Nullable: false},
+			{Name: "key_hash", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_key_hash", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_key_expires", Type: DB_DateTime, Nullable: true},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "rotated", Type: DB_DateTime, Nullable: true},
+			{Name: "expires", Type: DB_DateTime, Nullable: true},
+			{Name: "last_used_at", Type: DB_DateTime, Nullable: true},
+			{Name: "last_used_from", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''"},
+			{Name: "use_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "name"}, Type: UniqueIndex},
+			{Cols: []string{"key_hash"}, Type: UniqueIndex},
+			{Cols: []string{"previous_key_hash"}},
+		},
+	}
+
+	mg.AddMigration("create org_token table v1", NewAddTableMigration(orgTokenV1))
+	addTableIndicesMigrations(mg, "v1", orgTokenV1)
+}
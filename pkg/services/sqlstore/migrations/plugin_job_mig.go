@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addPluginJobMigrations(mg *Migrator) {
+	pluginJobRunV1 := Table{
+		Name: "plugin_job_run",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "plugin_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "job_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "started", Type: DB_DateTime, Nullable: false},
+			{Name: "finished", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "plugin_id", "job_id", "started"}},
+			{Cols: []string{"started"}},
+		},
+	}
+
+	mg.AddMigration("create plugin_job_run table v1", NewAddTableMigration(pluginJobRunV1))
+	mg.AddMigration("add index plugin_job_run.org_id_plugin_id_job_id_started", NewAddIndexMigration(pluginJobRunV1, pluginJobRunV1.Indices[0]))
+	mg.AddMigration("add index plugin_job_run.started", NewAddIndexMigration(pluginJobRunV1, pluginJobRunV1.Indices[1]))
+}
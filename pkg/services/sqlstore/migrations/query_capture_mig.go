@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addQueryCaptureMigrations(mg *Migrator) {
+	queryCaptureV1 := Table{
+		Name: "query_capture",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "duration", Type: DB_BigInt, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "request", Type: DB_MediumText, Nullable: false},
+			{Name: "response", Type: DB_MediumText, Nullable: true},
+			{Name: "summary", Type: DB_MediumText, Nullable: true},
+			{Name: "response_size", Type: DB_Int, Nullable: false},
+			{Name: "truncated", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "panel_id"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create query_capture table v1", NewAddTableMigration(queryCaptureV1))
+	mg.AddMigration("add index query_capture.org_id_dashboard_uid_panel_id", NewAddIndexMigration(queryCaptureV1, queryCaptureV1.Indices[0]))
+	mg.AddMigration("add index query_capture.created", NewAddIndexMigration(queryCaptureV1, queryCaptureV1.Indices[1]))
+}
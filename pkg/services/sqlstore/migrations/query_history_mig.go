@@ -29,4 +29,50 @@ func addQueryHistoryMigrations(mg *Migrator) {
 	mg.AddMigration("alter table query_history alter column created_by type to bigint", NewRawSQLMigration("").
 		Mysql("ALTER TABLE query_history MODIFY created_by BIGINT;").
 		Postgres("ALTER TABLE query_history ALTER COLUMN created_by TYPE BIGINT;"))
+
+	mg.AddMigration("add column updated_at to query_history", NewAddColumnMigration(queryHistoryV1, &Column{
+		Name: "updated_at", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("set updated_at of query_history", NewRawSQLMigration("UPDATE query_history SET updated_at = created_at"))
+
+	mg.AddMigration("add index query_history.org_id-created_by-updated_at", NewAddIndexMigration(queryHistoryV1, &Index{
+		Cols: []string{"org_id", "created_by", "updated_at"},
+	}))
+
+	queryHistoryShareV1 := Table{
+		Name: "query_history_share",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "query_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false},
+			{Name: "shared_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"query_uid", "team_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "team_id"}},
+		},
+	}
+
+	mg.AddMigration("create query_history_share table v1", NewAddTableMigration(queryHistoryShareV1))
+	addTableIndicesMigrations(mg, "v1", queryHistoryShareV1)
+
+	queryHistoryDeletedV1 := Table{
+		Name: "query_history_deleted",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "query_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "deleted_at", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "user_id", "deleted_at"}},
+		},
+	}
+
+	mg.AddMigration("create query_history_deleted table v1", NewAddTableMigration(queryHistoryDeletedV1))
+	addTableIndicesMigrations(mg, "v1", queryHistoryDeletedV1)
 }
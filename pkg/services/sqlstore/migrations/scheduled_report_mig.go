@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addScheduledReportMigrations(mg *Migrator) {
+	reportV1 := Table{
+		Name: "scheduled_report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "format", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "panel_ids", Type: DB_Text, Nullable: true},
+			{Name: "orientation", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "reply_to", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "message", Type: DB_Text, Nullable: true},
+			{Name: "schedule", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 100, Nullable: true},
+			{Name: "time_to", Type: DB_NVarchar, Length: 100, Nullable: true},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "next_run", Type: DB_DateTime, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+			{Cols: []string{"enabled", "next_run"}},
+		},
+	}
+
+	mg.AddMigration("create scheduled_report table v1", NewAddTableMigration(reportV1))
+	mg.AddMigration("add index scheduled_report.org_id", NewAddIndexMigration(reportV1, reportV1.Indices[0]))
+	mg.AddMigration("add index scheduled_report.enabled_next_run", NewAddIndexMigration(reportV1, reportV1.Indices[1]))
+
+	reportRunV1 := Table{
+		Name: "scheduled_report_run",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "report_id", Type: DB_BigInt, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "started", Type: DB_DateTime, Nullable: false},
+			{Name: "finished", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "report_id", "started"}},
+			{Cols: []string{"started"}},
+		},
+	}
+
+	mg.AddMigration("create scheduled_report_run table v1", NewAddTableMigration(reportRunV1))
+	mg.AddMigration("add index scheduled_report_run.org_id_report_id_started", NewAddIndexMigration(reportRunV1, reportRunV1.Indices[0]))
+	mg.AddMigration("add index scheduled_report_run.started", NewAddIndexMigration(reportRunV1, reportRunV1.Indices[1]))
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addSemaphoreMigrations(mg *Migrator) {
+	semaphoreLeaseV1 := Table{
+		Name: "semaphore_lease",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "slot", Type: DB_Int, Nullable: false},
+			{Name: "holder", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+			{Name: "acquired", Type: DB_BigInt, Nullable: false},
+			{Name: "expires", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"name", "slot"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create semaphore_lease table v1", NewAddTableMigration(semaphoreLeaseV1))
+	mg.AddMigration("add unique index semaphore_lease.name_slot", NewAddIndexMigration(semaphoreLeaseV1, semaphoreLeaseV1.Indices[0]))
+}
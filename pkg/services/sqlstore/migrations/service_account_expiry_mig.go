@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addServiceAccountExpiryMigrations(mg *Migrator) {
+	serviceAccountExpiryV1 := Table{
+		Name: "service_account_expiry",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "service_account_id", Type: DB_BigInt, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: false},
+			{Name: "attested_at", Type: DB_DateTime, Nullable: true},
+			{Name: "attested_by", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "notified_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "service_account_id"}, Type: UniqueIndex},
+			{Cols: []string{"expires_at"}},
+		},
+	}
+
+	mg.AddMigration("create service_account_expiry table v1", NewAddTableMigration(serviceAccountExpiryV1))
+	mg.AddMigration("add unique index service_account_expiry.org_id_service_account_id", NewAddIndexMigration(serviceAccountExpiryV1, serviceAccountExpiryV1.Indices[0]))
+	mg.AddMigration("add index service_account_expiry.expires_at", NewAddIndexMigration(serviceAccountExpiryV1, serviceAccountExpiryV1.Indices[1]))
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addSyntheticsMigrations(mg *Migrator) {
+	checkV1 := Table{
+		Name: "synthetic_check",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "type", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "target", Type: DB_Text, Nullable: false},
+			{Name: "frequency", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "timeout", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "probes", Type: DB_Text, Nullable: false},
+			{Name: "settings", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create synthetic_check table v1", NewAddTableMigration(checkV1))
+	addTableIndicesMigrations(mg, "v1", checkV1)
+
+	resultV1 := Table{
+		Name: "synthetic_check_result",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "check_id", Type: DB_BigInt, Nullable: false},
+			{Name: "probe", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "executed_at", Type: DB_DateTime, Nullable: false},
+			{Name: "success", Type: DB_Bool, Nullable: false},
+			{Name: "duration", Type: DB_Double, Nullable: false},
+			{Name: "status_code", Type: DB_Int, Nullable: false},
+			{Name: "error", Type: DB_NVarchar, Length: 255, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "check_id", "executed_at"}},
+			{Cols: []string{"executed_at"}},
+		},
+	}
+
+	mg.AddMigration("create synthetic_check_result table v1", NewAddTableMigration(resultV1))
+	addTableIndicesMigrations(mg, "v1", resultV1)
+}
@@ -166,6 +166,19 @@ func AlertInstanceMigration(mg *migrator.Migrator) {
 		migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
 			Name: "current_reason", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true,
 		}))
+
+	mg.AddMigration("add column acknowledged_by to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "acknowledged_by", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column acknowledged_at to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "acknowledged_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column snoozed_until to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "snoozed_until", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column assignee_id to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "assignee_id", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
 
 func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64) {
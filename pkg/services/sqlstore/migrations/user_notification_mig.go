@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addUserNotificationMigrations(mg *Migrator) {
+	notificationV1 := Table{
+		Name: "user_notification",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "severity", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "body", Type: DB_Text, Nullable: true},
+			{Name: "url", Type: DB_NVarchar, Length: 1024, Nullable: true},
+			{Name: "read_at", Type: DB_DateTime, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "org_id", "read_at"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create user_notification table v1", NewAddTableMigration(notificationV1))
+	mg.AddMigration("add index user_notification.user_id_org_id_read_at", NewAddIndexMigration(notificationV1, notificationV1.Indices[0]))
+	mg.AddMigration("add index user_notification.created", NewAddIndexMigration(notificationV1, notificationV1.Indices[1]))
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addVariableConstraintMigrations(mg *Migrator) {
+	constraintV1 := Table{
+		Name: "dashboard_variable_constraint",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "variable", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "rules", Type: DB_Text, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "updated_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "variable"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_variable_constraint table v1", NewAddTableMigration(constraintV1))
+	addTableIndicesMigrations(mg, "v1", constraintV1)
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addWebhookMigrations(mg *Migrator) {
+	webhookV1 := Table{
+		Name: "webhook",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "url", Type: DB_NVarchar, Length: 1024, Nullable: false},
+			{Name: "events", Type: DB_Text, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create webhook table v1", NewAddTableMigration(webhookV1))
+	mg.AddMigration("add unique index webhook.org_id_uid", NewAddIndexMigration(webhookV1, webhookV1.Indices[0]))
+
+	deliveryV1 := Table{
+		Name: "webhook_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "webhook_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "event", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false},
+			{Name: "next_attempt_at", Type: DB_DateTime, Nullable: false},
+			{Name: "response_status", Type: DB_Int, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"webhook_id", "id"}},
+			{Cols: []string{"state", "next_attempt_at"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create webhook_delivery table v1", NewAddTableMigration(deliveryV1))
+	mg.AddMigration("add index webhook_delivery.webhook_id_id", NewAddIndexMigration(deliveryV1, deliveryV1.Indices[0]))
+	mg.AddMigration("add index webhook_delivery.state_next_attempt_at", NewAddIndexMigration(deliveryV1, deliveryV1.Indices[1]))
+	mg.AddMigration("add index webhook_delivery.created", NewAddIndexMigration(deliveryV1, deliveryV1.Indices[2]))
+}
@@ -26,6 +26,10 @@ type SQLStoreMock struct {
 	ExpectedDataSourceStats        []*models.DataSourceStats
 	ExpectedDataSourcesAccessStats []*models.DataSourceAccessStats
 	ExpectedNotifierUsageStats     []*models.NotifierUsageStats
+	ExpectedOrgActiveUsersStats    []*models.OrgActiveUsersStats
+	ExpectedPendingMigrations      []string
+	ExpectedAdminOrgStats          *models.AdminOrgStatsResult
+	ExpectedAdminResourceAgeStats  *models.AdminResourceAgeStats
 	ExpectedSignedInUser           *user.SignedInUser
 
 	ExpectedError error
@@ -39,6 +43,16 @@ func (m *SQLStoreMock) GetAdminStats(ctx context.Context, query *models.GetAdmin
 	return m.ExpectedError
 }
 
+func (m *SQLStoreMock) GetAdminOrgStats(ctx context.Context, query *models.GetAdminOrgStatsQuery) error {
+	query.Result = m.ExpectedAdminOrgStats
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) GetAdminResourceAgeStats(ctx context.Context, query *models.GetAdminResourceAgeStatsQuery) error {
+	query.Result = m.ExpectedAdminResourceAgeStats
+	return m.ExpectedError
+}
+
 func (m *SQLStoreMock) GetAlertNotifiersUsageStats(ctx context.Context, query *models.GetAlertNotifierUsageStatsQuery) error {
 	query.Result = m.ExpectedNotifierUsageStats
 	return m.ExpectedError
@@ -59,6 +73,11 @@ func (m *SQLStoreMock) GetSystemStats(ctx context.Context, query *models.GetSyst
 	return m.ExpectedError
 }
 
+func (m *SQLStoreMock) GetOrgActiveUsersStats(ctx context.Context, query *models.GetOrgActiveUsersStatsQuery) error {
+	query.Result = m.ExpectedOrgActiveUsersStats
+	return m.ExpectedError
+}
+
 func (m *SQLStoreMock) GetDialect() migrator.Dialect {
 	return nil
 }
@@ -124,6 +143,11 @@ func (m *SQLStoreMock) GetDBHealthQuery(ctx context.Context, query *models.GetDB
 	return m.ExpectedError
 }
 
+func (m *SQLStoreMock) GetPendingMigrations(ctx context.Context, query *models.GetPendingMigrationsQuery) error {
+	query.Result = m.ExpectedPendingMigrations
+	return m.ExpectedError
+}
+
 func (m *SQLStoreMock) GetSqlxSession() *session.SessionDB {
 	return nil
 }
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/services/resourcelabel"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/tag"
 )
 
 // FilterWhere limits the set of dashboard IDs to the dashboards for
@@ -131,6 +133,26 @@ func (f TagsFilter) Where() (string, []interface{}) {
 	return `dashboard_tag.term IN (?` + strings.Repeat(",?", len(f.Tags)-1) + `)`, params
 }
 
+// TagFilter matches the dashboards with a tag matching a structured tag filter, see tag.Filter.
+type TagFilter struct {
+	Dialect migrator.Dialect
+	Filter  tag.Filter
+}
+
+func (f TagFilter) Where() (string, []interface{}) {
+	where, params := f.Filter.Where("dt.tag_key", "dt.tag_value", f.Dialect.LikeStr())
+	return `EXISTS (SELECT 1 FROM dashboard_tag dt WHERE dt.dashboard_id = dashboard.id AND ` + where + `)`, params
+}
+
+// LabelFilter matches the dashboards and folders with labels matching a label selector.
+type LabelFilter struct {
+	Selector resourcelabel.Selector
+}
+
+func (f LabelFilter) Where() (string, []interface{}) {
+	return f.Selector.Where("dashboard.org_id", "dashboard.uid", resourcelabel.KindDashboard, resourcelabel.KindFolder)
+}
+
 type TitleSorter struct {
 	Descending bool
 }
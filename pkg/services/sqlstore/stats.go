@@ -123,6 +123,20 @@ func (ss *SQLStore) GetSystemStats(ctx context.Context, query *models.GetSystemS
 	})
 }
 
+// GetOrgActiveUsersStats returns the number of users per org who were active in the last 30 days.
+func (ss *SQLStore) GetOrgActiveUsersStats(ctx context.Context, query *models.GetOrgActiveUsersStatsQuery) error {
+	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+		activeUserDeadlineDate := time.Now().Add(-activeUserTimeLimit)
+		var rawSQL = `SELECT org_user.org_id AS org_id, COUNT(DISTINCT u.id) AS count
+			FROM ` + dialect.Quote("org_user") + ` AS org_user
+			INNER JOIN ` + dialect.Quote("user") + ` AS u ON u.id = org_user.user_id
+			WHERE u.` + notServiceAccount(dialect) + ` AND u.last_seen_at > ?
+			GROUP BY org_user.org_id`
+		query.Result = make([]*models.OrgActiveUsersStats, 0)
+		return dbSession.SQL(rawSQL, activeUserDeadlineDate).Find(&query.Result)
+	})
+}
+
 func (ss *SQLStore) roleCounterSQL(ctx context.Context) string {
 	const roleCounterTimeout = 20 * time.Second
 	ctx, cancel := context.WithTimeout(ctx, roleCounterTimeout)
@@ -154,6 +168,25 @@ func viewersPermissionsCounterSQL(statName string, isFolder bool, permission mod
 	) AS ` + statName + `, `
 }
 
+// adminUserStats are the user counts of the admin stats, computed in a single pass over the user table.
+type adminUserStats struct {
+	Users              int64
+	ActiveUsers        int64
+	DailyActiveUsers   int64
+	MonthlyActiveUsers int64
+}
+
+// adminSessionStats are the session counts of the admin stats, computed in a single pass over the active sessions.
+type adminSessionStats struct {
+	ActiveSessions      int64
+	DailyActiveSessions int64
+}
+
+// countSince returns an SQL expression counting the rows with a column value after the first parameter.
+func countSince(column string) string {
+	return `COALESCE(SUM(CASE WHEN ` + column + ` > ? THEN 1 ELSE 0 END), 0)`
+}
+
 func (ss *SQLStore) GetAdminStats(ctx context.Context, query *models.GetAdminStatsQuery) error {
 	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
 		now := time.Now()
@@ -194,43 +227,137 @@ func (ss *SQLStore) GetAdminStats(ctx context.Context, query *models.GetAdminSta
 			SELECT COUNT(*)
 			FROM ` + dialect.Quote("alert") + `
 		) AS alerts,
-		(
-			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + `
-		) AS users,
-		(
-			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + ` AND last_seen_at > ?
-		) AS active_users,
-		(
-			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + ` AND last_seen_at > ?
-		) AS daily_active_users,
-		(
-			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + ` AND last_seen_at > ?
-		) AS monthly_active_users,
-		` + ss.roleCounterSQL(ctx) + `,
-		(
-			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user_auth_token") + ` WHERE rotated_at > ?
-		) AS active_sessions,
-		(
-			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user_auth_token") + ` WHERE rotated_at > ?
-		) AS daily_active_sessions`
+		` + ss.roleCounterSQL(ctx)
 
 		var stats models.AdminStats
-		_, err := dbSession.SQL(rawSQL, activeEndDate, dailyActiveEndDate, monthlyActiveEndDate, activeEndDate.Unix(), dailyActiveEndDate.Unix()).Get(&stats)
+		if _, err := dbSession.SQL(rawSQL).Get(&stats); err != nil {
+			return err
+		}
+
+		var userStats adminUserStats
+		userSQL := `SELECT
+			COUNT(*) AS users,
+			` + countSince("last_seen_at") + ` AS active_users,
+			` + countSince("last_seen_at") + ` AS daily_active_users,
+			` + countSince("last_seen_at") + ` AS monthly_active_users
+		FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect)
+		if _, err := dbSession.SQL(userSQL, activeEndDate, dailyActiveEndDate, monthlyActiveEndDate).Get(&userStats); err != nil {
+			return err
+		}
+		stats.Users = userStats.Users
+		stats.ActiveUsers = userStats.ActiveUsers
+		stats.DailyActiveUsers = userStats.DailyActiveUsers
+		stats.MonthlyActiveUsers = userStats.MonthlyActiveUsers
+
+		var sessionStats adminSessionStats
+		sessionSQL := `SELECT
+			COUNT(*) AS active_sessions,
+			` + countSince("rotated_at") + ` AS daily_active_sessions
+		FROM ` + dialect.Quote("user_auth_token") + ` WHERE rotated_at > ?`
+		if _, err := dbSession.SQL(sessionSQL, dailyActiveEndDate.Unix(), activeEndDate.Unix()).Get(&sessionStats); err != nil {
+			return err
+		}
+		stats.ActiveSessions = sessionStats.ActiveSessions
+		stats.DailyActiveSessions = sessionStats.DailyActiveSessions
+
+		query.Result = &stats
+		return nil
+	})
+}
+
+// GetAdminOrgStats returns a page of the resource counts per org, ordered by org ID.
+func (ss *SQLStore) GetAdminOrgStats(ctx context.Context, query *models.GetAdminOrgStatsQuery) error {
+	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+		total, err := dbSession.Table("org").Count()
 		if err != nil {
 			return err
 		}
 
+		var rawSQL = `SELECT
+			o.id AS org_id,
+			o.name AS name,
+			COALESCE(u.users, 0) AS users,
+			COALESCE(d.dashboards, 0) AS dashboards,
+			COALESCE(d.folders, 0) AS folders,
+			COALESCE(ds.datasources, 0) AS datasources
+		FROM ` + dialect.Quote("org") + ` AS o
+		LEFT JOIN (
+			SELECT org_id, COUNT(*) AS users
+			FROM ` + dialect.Quote("org_user") + `
+			GROUP BY org_id
+		) AS u ON u.org_id = o.id
+		LEFT JOIN (
+			SELECT org_id,
+				SUM(CASE WHEN is_folder = ` + dialect.BooleanStr(false) + ` THEN 1 ELSE 0 END) AS dashboards,
+				SUM(CASE WHEN is_folder = ` + dialect.BooleanStr(true) + ` THEN 1 ELSE 0 END) AS folders
+			FROM ` + dialect.Quote("dashboard") + `
+			GROUP BY org_id
+		) AS d ON d.org_id = o.id
+		LEFT JOIN (
+			SELECT org_id, COUNT(*) AS datasources
+			FROM ` + dialect.Quote("data_source") + `
+			GROUP BY org_id
+		) AS ds ON ds.org_id = o.id
+		ORDER BY o.id ` + dialect.LimitOffset(int64(query.PerPage), int64((query.Page-1)*query.PerPage))
+
+		orgs := make([]*models.AdminOrgStats, 0)
+		if err := dbSession.SQL(rawSQL).Find(&orgs); err != nil {
+			return err
+		}
+
+		query.Result = &models.AdminOrgStatsResult{
+			TotalCount: total,
+			Page:       query.Page,
+			PerPage:    query.PerPage,
+			Orgs:       orgs,
+		}
+		return nil
+	})
+}
+
+// GetAdminResourceAgeStats counts the dashboards and data sources by the time since their
+// last update, and the users by the time since they were last seen.
+func (ss *SQLStore) GetAdminResourceAgeStats(ctx context.Context, query *models.GetAdminResourceAgeStatsQuery) error {
+	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+		var stats models.AdminResourceAgeStats
+		now := time.Now()
+
+		if err := resourceAgeStats(dbSession, now, &stats.Dashboards, "dashboard", "updated", `is_folder = `+dialect.BooleanStr(false)); err != nil {
+			return err
+		}
+		if err := resourceAgeStats(dbSession, now, &stats.Datasources, "data_source", "updated", ""); err != nil {
+			return err
+		}
+		if err := resourceAgeStats(dbSession, now, &stats.Users, "user", "last_seen_at", notServiceAccount(dialect)); err != nil {
+			return err
+		}
+
 		query.Result = &stats
 		return nil
 	})
 }
 
+// resourceAgeStats counts the rows of a table by the age of a time column in a single pass.
+func resourceAgeStats(dbSession *DBSession, now time.Time, stats *models.ResourceAgeStats, table string, column string, filter string) error {
+	day := 24 * time.Hour
+	week, month, quarter, year := now.Add(-7*day), now.Add(-30*day), now.Add(-90*day), now.Add(-365*day)
+
+	rawSQL := `SELECT
+		COUNT(*) AS total,
+		COALESCE(SUM(CASE WHEN ` + column + ` > ? THEN 1 ELSE 0 END), 0) AS up_to_7_days,
+		COALESCE(SUM(CASE WHEN ` + column + ` <= ? AND ` + column + ` > ? THEN 1 ELSE 0 END), 0) AS up_to_30_days,
+		COALESCE(SUM(CASE WHEN ` + column + ` <= ? AND ` + column + ` > ? THEN 1 ELSE 0 END), 0) AS up_to_90_days,
+		COALESCE(SUM(CASE WHEN ` + column + ` <= ? AND ` + column + ` > ? THEN 1 ELSE 0 END), 0) AS up_to_365_days,
+		COALESCE(SUM(CASE WHEN ` + column + ` <= ? THEN 1 ELSE 0 END), 0) AS older
+	FROM ` + dialect.Quote(table)
+	if filter != "" {
+		rawSQL += ` WHERE ` + filter
+	}
+
+	_, err := dbSession.SQL(rawSQL, week, week, month, month, quarter, quarter, year, year).Get(stats)
+	return err
+}
+
 func (ss *SQLStore) GetSystemUserCountStats(ctx context.Context, query *models.GetSystemUserCountStatsQuery) error {
 	return ss.WithDbSession(ctx, func(sess *DBSession) error {
 		var rawSQL = `SELECT COUNT(id) AS Count FROM ` + dialect.Quote("user")
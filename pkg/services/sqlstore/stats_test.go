@@ -33,6 +33,14 @@ func TestIntegrationStatsDataAccess(t *testing.T) {
 		assert.Equal(t, int64(0), query.Result.APIKeys)
 	})
 
+	t.Run("Get org active users stats should not result in error", func(t *testing.T) {
+		query := models.GetOrgActiveUsersStatsQuery{}
+		err := sqlStore.GetOrgActiveUsersStats(context.Background(), &query)
+		require.NoError(t, err)
+		// the users were not seen since they were created
+		assert.Empty(t, query.Result)
+	})
+
 	t.Run("Get system user count stats should not results in error", func(t *testing.T) {
 		query := models.GetSystemUserCountStatsQuery{}
 		err := sqlStore.GetSystemUserCountStats(context.Background(), &query)
@@ -60,7 +68,37 @@ func TestIntegrationStatsDataAccess(t *testing.T) {
 	t.Run("Get admin stats should not result in error", func(t *testing.T) {
 		query := models.GetAdminStatsQuery{}
 		err := sqlStore.GetAdminStats(context.Background(), &query)
-		assert.NoError(t, err)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), query.Result.Orgs)
+		assert.Equal(t, int64(3), query.Result.Users)
+		assert.Equal(t, int64(0), query.Result.ActiveUsers)
+		assert.Equal(t, int64(0), query.Result.ActiveSessions)
+	})
+
+	t.Run("Get admin org stats should return a page of the orgs", func(t *testing.T) {
+		query := models.GetAdminOrgStatsQuery{Page: 1, PerPage: 2}
+		err := sqlStore.GetAdminOrgStats(context.Background(), &query)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), query.Result.TotalCount)
+		require.Len(t, query.Result.Orgs, 2)
+		// the first org has all three users
+		assert.Equal(t, int64(3), query.Result.Orgs[0].Users)
+		assert.Equal(t, int64(2), query.Result.Orgs[1].Users)
+
+		query = models.GetAdminOrgStatsQuery{Page: 2, PerPage: 2}
+		err = sqlStore.GetAdminOrgStats(context.Background(), &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result.Orgs, 1)
+		assert.Equal(t, int64(1), query.Result.Orgs[0].Users)
+	})
+
+	t.Run("Get admin resource age stats should count users by last seen", func(t *testing.T) {
+		query := models.GetAdminResourceAgeStatsQuery{}
+		err := sqlStore.GetAdminResourceAgeStats(context.Background(), &query)
+		require.NoError(t, err)
+		// the users were not seen since they were created
+		assert.Equal(t, models.ResourceAgeStats{Total: 3, Older: 3}, query.Result.Users)
+		assert.Equal(t, int64(0), query.Result.Dashboards.Total)
 	})
 }
 
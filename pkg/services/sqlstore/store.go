@@ -13,12 +13,15 @@ import (
 
 type Store interface {
 	GetAdminStats(ctx context.Context, query *models.GetAdminStatsQuery) error
+	GetAdminOrgStats(ctx context.Context, query *models.GetAdminOrgStatsQuery) error
+	GetAdminResourceAgeStats(ctx context.Context, query *models.GetAdminResourceAgeStatsQuery) error
 	GetAlertNotifiersUsageStats(ctx context.Context, query *models.GetAlertNotifierUsageStatsQuery) error
 	GetDataSourceStats(ctx context.Context, query *models.GetDataSourceStatsQuery) error
 	GetDataSourceAccessStats(ctx context.Context, query *models.GetDataSourceAccessStatsQuery) error
 	GetDialect() migrator.Dialect
 	GetDBType() core.DbType
 	GetSystemStats(ctx context.Context, query *models.GetSystemStatsQuery) error
+	GetOrgActiveUsersStats(ctx context.Context, query *models.GetOrgActiveUsersStatsQuery) error
 	CreateUser(ctx context.Context, cmd user.CreateUserCommand) (*user.User, error)
 	GetSignedInUser(ctx context.Context, query *models.GetSignedInUserQuery) error
 	WithDbSession(ctx context.Context, callback DBTransactionFunc) error
@@ -30,5 +33,6 @@ type Store interface {
 	Reset() error
 	Quote(value string) string
 	GetDBHealthQuery(ctx context.Context, query *models.GetDBHealthQuery) error
+	GetPendingMigrations(ctx context.Context, query *models.GetPendingMigrationsQuery) error
 	GetSqlxSession() *session.SessionDB
 }
@@ -469,6 +469,9 @@ func UserDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_notification WHERE user_id = ?",
+		"DELETE FROM dashboard_subscription WHERE user_id = ?",
+		"DELETE FROM announcement_dismissal WHERE user_id = ?",
 	}
 	return deletes
 }
@@ -1,6 +1,9 @@
 package star
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var ErrCommandValidationFailed = errors.New("command missing required fields")
 
@@ -52,3 +55,78 @@ type IsStarredByUserQuery struct {
 type GetUserStarsResult struct {
 	UserStars map[int64]bool
 }
+
+// Kind is the kind of resource a user starred.
+type Kind string
+
+const (
+	KindDashboard  Kind = "dashboard"
+	KindFolder     Kind = "folder"
+	KindDataSource Kind = "datasource"
+	KindAlertRule  Kind = "alert-rule"
+)
+
+var ErrInvalidKind = errors.New("invalid star kind")
+
+// IsValid returns whether resources of the kind can be starred. Dashboards are starred with
+// StarDashboardCommand.
+func (k Kind) IsValid() bool {
+	switch k {
+	case KindFolder, KindDataSource, KindAlertRule:
+		return true
+	}
+	return false
+}
+
+// ResourceStar is a star of a resource other than a dashboard. Resources are identified by
+// UID, which is only unique within an organization.
+type ResourceStar struct {
+	ID          int64     `xorm:"pk autoincr 'id'" db:"id"`
+	UserID      int64     `xorm:"user_id" db:"user_id"`
+	OrgID       int64     `xorm:"org_id" db:"org_id"`
+	Kind        Kind      `xorm:"kind" db:"kind"`
+	ResourceUID string    `xorm:"resource_uid" db:"resource_uid"`
+	Created     time.Time `xorm:"created" db:"created"`
+}
+
+type StarResourceCommand struct {
+	UserID      int64
+	OrgID       int64
+	Kind        Kind
+	ResourceUID string
+}
+
+func (cmd *StarResourceCommand) Validate() error {
+	if !cmd.Kind.IsValid() {
+		return ErrInvalidKind
+	}
+	if cmd.UserID == 0 || cmd.OrgID == 0 || cmd.ResourceUID == "" {
+		return ErrCommandValidationFailed
+	}
+	return nil
+}
+
+type UnstarResourceCommand struct {
+	UserID      int64
+	OrgID       int64
+	Kind        Kind
+	ResourceUID string
+}
+
+func (cmd *UnstarResourceCommand) Validate() error {
+	if !cmd.Kind.IsValid() {
+		return ErrInvalidKind
+	}
+	if cmd.UserID == 0 || cmd.OrgID == 0 || cmd.ResourceUID == "" {
+		return ErrCommandValidationFailed
+	}
+	return nil
+}
+
+// GetUserResourceStarsQuery lists the resources a user starred in an organization, optionally
+// limited to some kinds.
+type GetUserResourceStarsQuery struct {
+	UserID int64
+	OrgID  int64
+	Kinds  []Kind
+}
@@ -10,4 +10,7 @@ type Service interface {
 	DeleteByUser(context.Context, int64) error
 	IsStarredByUser(context.Context, *IsStarredByUserQuery) (bool, error)
 	GetByUser(context.Context, *GetUserStarsQuery) (*GetUserStarsResult, error)
+	AddResource(context.Context, *StarResourceCommand) error
+	DeleteResource(context.Context, *UnstarResourceCommand) error
+	GetResourcesByUser(context.Context, *GetUserResourceStarsQuery) ([]*ResourceStar, error)
 }
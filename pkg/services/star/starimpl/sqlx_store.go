@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/sqlstore/session"
 	"github.com/grafana/grafana/pkg/services/star"
@@ -43,7 +45,10 @@ func (s *sqlxStore) Delete(ctx context.Context, cmd *star.UnstarDashboardCommand
 }
 
 func (s *sqlxStore) DeleteByUser(ctx context.Context, userID int64) error {
-	_, err := s.sess.Exec(ctx, "DELETE FROM star WHERE user_id = ?", userID)
+	if _, err := s.sess.Exec(ctx, "DELETE FROM star WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	_, err := s.sess.Exec(ctx, "DELETE FROM resource_star WHERE user_id = ?", userID)
 	return err
 }
 
@@ -60,3 +65,50 @@ func (s *sqlxStore) List(ctx context.Context, query *star.GetUserStarsQuery) (*s
 
 	return &star.GetUserStarsResult{UserStars: userStars}, err
 }
+
+func (s *sqlxStore) InsertResource(ctx context.Context, cmd *star.StarResourceCommand) error {
+	var existing star.ResourceStar
+	err := s.sess.Get(ctx, &existing, "SELECT * FROM resource_star WHERE user_id=? AND org_id=? AND kind=? AND resource_uid=?",
+		cmd.UserID, cmd.OrgID, cmd.Kind, cmd.ResourceUID)
+	if err == nil {
+		// starring a resource twice is not an error
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	entity := star.ResourceStar{
+		UserID:      cmd.UserID,
+		OrgID:       cmd.OrgID,
+		Kind:        cmd.Kind,
+		ResourceUID: cmd.ResourceUID,
+		Created:     time.Now(),
+	}
+	_, err = s.sess.NamedExec(ctx, `INSERT INTO resource_star (user_id, org_id, kind, resource_uid, created) VALUES (:user_id, :org_id, :kind, :resource_uid, :created)`, entity)
+	return err
+}
+
+func (s *sqlxStore) DeleteResource(ctx context.Context, cmd *star.UnstarResourceCommand) error {
+	_, err := s.sess.Exec(ctx, "DELETE FROM resource_star WHERE user_id=? AND org_id=? AND kind=? AND resource_uid=?",
+		cmd.UserID, cmd.OrgID, cmd.Kind, cmd.ResourceUID)
+	return err
+}
+
+func (s *sqlxStore) ListResources(ctx context.Context, query *star.GetUserResourceStarsQuery) ([]*star.ResourceStar, error) {
+	rawSQL := "SELECT * FROM resource_star WHERE user_id=? AND org_id=?"
+	args := []interface{}{query.UserID, query.OrgID}
+	if len(query.Kinds) > 0 {
+		rawSQL += " AND kind IN (?" + strings.Repeat(",?", len(query.Kinds)-1) + ")"
+		for _, kind := range query.Kinds {
+			args = append(args, kind)
+		}
+	}
+	rawSQL += " ORDER BY created DESC, id DESC"
+
+	stars := make([]*star.ResourceStar, 0)
+	if err := s.sess.Select(ctx, &stars, rawSQL, args...); err != nil {
+		return nil, err
+	}
+	return stars, nil
+}
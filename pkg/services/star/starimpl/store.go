@@ -12,4 +12,7 @@ type store interface {
 	Delete(context.Context, *star.UnstarDashboardCommand) error
 	DeleteByUser(context.Context, int64) error
 	List(context.Context, *star.GetUserStarsQuery) (*star.GetUserStarsResult, error)
+	InsertResource(context.Context, *star.StarResourceCommand) error
+	DeleteResource(context.Context, *star.UnstarResourceCommand) error
+	ListResources(context.Context, *star.GetUserResourceStarsQuery) ([]*star.ResourceStar, error)
 }
@@ -87,5 +87,47 @@ func testIntegrationUserStarsDataAccess(t *testing.T, fn getStore) {
 			require.NoError(t, err)
 			require.Equal(t, 1, len(res.UserStars))
 		})
+
+		t.Run("Given starred resources", func(t *testing.T) {
+			for _, cmd := range []star.StarResourceCommand{
+				{UserID: 20, OrgID: 1, Kind: star.KindFolder, ResourceUID: "folder"},
+				{UserID: 20, OrgID: 1, Kind: star.KindDataSource, ResourceUID: "loki"},
+				{UserID: 20, OrgID: 1, Kind: star.KindDataSource, ResourceUID: "loki"},
+				{UserID: 20, OrgID: 2, Kind: star.KindAlertRule, ResourceUID: "rule"},
+				{UserID: 21, OrgID: 1, Kind: star.KindFolder, ResourceUID: "folder"},
+			} {
+				cmd := cmd
+				require.NoError(t, starStore.InsertResource(context.Background(), &cmd))
+			}
+
+			t.Run("ListResources should return the stars of the user in the organization", func(t *testing.T) {
+				stars, err := starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 20, OrgID: 1})
+				require.NoError(t, err)
+				require.Len(t, stars, 2)
+
+				stars, err = starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 20, OrgID: 1, Kinds: []star.Kind{star.KindDataSource}})
+				require.NoError(t, err)
+				require.Len(t, stars, 1)
+				require.Equal(t, "loki", stars[0].ResourceUID)
+			})
+
+			t.Run("DeleteResource should remove the star", func(t *testing.T) {
+				err := starStore.DeleteResource(context.Background(), &star.UnstarResourceCommand{UserID: 20, OrgID: 1, Kind: star.KindFolder, ResourceUID: "folder"})
+				require.NoError(t, err)
+				stars, err := starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 20, OrgID: 1, Kinds: []star.Kind{star.KindFolder}})
+				require.NoError(t, err)
+				require.Len(t, stars, 0)
+				stars, err = starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 21, OrgID: 1})
+				require.NoError(t, err)
+				require.Len(t, stars, 1)
+			})
+
+			t.Run("DeleteByUser should remove the resource stars of the user", func(t *testing.T) {
+				require.NoError(t, starStore.DeleteByUser(context.Background(), 20))
+				stars, err := starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 20, OrgID: 2})
+				require.NoError(t, err)
+				require.Len(t, stars, 0)
+			})
+		})
 	})
 }
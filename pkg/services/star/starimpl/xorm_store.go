@@ -2,6 +2,7 @@ package starimpl
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/star"
@@ -50,7 +51,10 @@ func (s *sqlStore) Delete(ctx context.Context, cmd *star.UnstarDashboardCommand)
 func (s *sqlStore) DeleteByUser(ctx context.Context, userID int64) error {
 	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var rawSQL = "DELETE FROM star WHERE user_id = ?"
-		_, err := sess.Exec(rawSQL, userID)
+		if _, err := sess.Exec(rawSQL, userID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM resource_star WHERE user_id = ?", userID)
 		return err
 	})
 }
@@ -67,3 +71,44 @@ func (s *sqlStore) List(ctx context.Context, query *star.GetUserStarsQuery) (*st
 	})
 	return &star.GetUserStarsResult{UserStars: userStars}, err
 }
+
+func (s *sqlStore) InsertResource(ctx context.Context, cmd *star.StarResourceCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		entity := star.ResourceStar{
+			UserID:      cmd.UserID,
+			OrgID:       cmd.OrgID,
+			Kind:        cmd.Kind,
+			ResourceUID: cmd.ResourceUID,
+		}
+
+		// starring a resource twice is not an error
+		exists, err := sess.Where("user_id=? AND org_id=? AND kind=? AND resource_uid=?", cmd.UserID, cmd.OrgID, cmd.Kind, cmd.ResourceUID).Exist(&star.ResourceStar{})
+		if err != nil || exists {
+			return err
+		}
+
+		entity.Created = time.Now()
+		_, err = sess.Insert(&entity)
+		return err
+	})
+}
+
+func (s *sqlStore) DeleteResource(ctx context.Context, cmd *star.UnstarResourceCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var rawSQL = "DELETE FROM resource_star WHERE user_id=? AND org_id=? AND kind=? AND resource_uid=?"
+		_, err := sess.Exec(rawSQL, cmd.UserID, cmd.OrgID, cmd.Kind, cmd.ResourceUID)
+		return err
+	})
+}
+
+func (s *sqlStore) ListResources(ctx context.Context, query *star.GetUserResourceStarsQuery) ([]*star.ResourceStar, error) {
+	stars := make([]*star.ResourceStar, 0)
+	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		sess := dbSession.Where("user_id=? AND org_id=?", query.UserID, query.OrgID)
+		if len(query.Kinds) > 0 {
+			sess = sess.In("kind", query.Kinds)
+		}
+		return sess.OrderBy("created DESC, id DESC").Find(&stars)
+	})
+	return stars, err
+}
@@ -277,24 +277,38 @@ func readDashboard(stream io.Reader, lookup DatasourceLookup) (*dashboardInfo, e
 }
 
 func panelRequiresDatasource(panel panelInfo) bool {
-	return panel.Type != "row"
+	// the data source of a library panel is part of the library panel's model
+	return panel.Type != "row" && panel.LibraryPanel == ""
 }
 
 func fillDefaultDatasources(dash *dashboardInfo, lookup DatasourceLookup) {
-	for i, panel := range dash.Panels {
+	fillPanelDefaultDatasources(dash.Panels, lookup)
+}
+
+func fillPanelDefaultDatasources(panels []panelInfo, lookup DatasourceLookup) {
+	for i, panel := range panels {
+		// panels of collapsed rows
+		fillPanelDefaultDatasources(panel.Collapsed, lookup)
+
 		if len(panel.Datasource) != 0 || !panelRequiresDatasource(panel) {
 			continue
 		}
 
 		defaultDs := lookup.ByRef(nil)
 		if defaultDs != nil {
-			dash.Panels[i].Datasource = []DataSourceRef{*defaultDs}
+			panels[i].Datasource = []DataSourceRef{*defaultDs}
 		}
 	}
 }
 
 func filterOutSpecialDatasources(dash *dashboardInfo) {
-	for i, panel := range dash.Panels {
+	filterOutPanelSpecialDatasources(dash.Panels)
+}
+
+func filterOutPanelSpecialDatasources(panels []panelInfo) {
+	for i, panel := range panels {
+		filterOutPanelSpecialDatasources(panel.Collapsed)
+
 		var dsRefs []DataSourceRef
 
 		// partition into actual datasource references and variables
@@ -311,12 +325,18 @@ func filterOutSpecialDatasources(dash *dashboardInfo) {
 			}
 		}
 
-		dash.Panels[i].Datasource = dsRefs
+		panels[i].Datasource = dsRefs
 	}
 }
 
 func replaceDatasourceVariables(dash *dashboardInfo, datasourceVariablesLookup *datasourceVariableLookup) {
-	for i, panel := range dash.Panels {
+	replacePanelDatasourceVariables(dash.Panels, datasourceVariablesLookup)
+}
+
+func replacePanelDatasourceVariables(panels []panelInfo, datasourceVariablesLookup *datasourceVariableLookup) {
+	for i, panel := range panels {
+		replacePanelDatasourceVariables(panel.Collapsed, datasourceVariablesLookup)
+
 		var dsVariableRefs []DataSourceRef
 		var dsRefs []DataSourceRef
 
@@ -331,7 +351,7 @@ func replaceDatasourceVariables(dash *dashboardInfo, datasourceVariablesLookup *
 		}
 
 		variables := findDatasourceRefsForVariables(dsVariableRefs, datasourceVariablesLookup)
-		dash.Panels[i].Datasource = append(dsRefs, variables...)
+		panels[i].Datasource = append(dsRefs, variables...)
 	}
 }
 
@@ -438,6 +458,15 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) panelInfo {
 		case "fieldConfig":
 			iter.Skip()
 
+		case "libraryPanel":
+			for sub := iter.ReadObject(); sub != ""; sub = iter.ReadObject() {
+				if sub == "uid" {
+					panel.LibraryPanel = iter.ReadString()
+				} else {
+					iter.Skip()
+				}
+			}
+
 		default:
 			v := iter.Read()
 			logf("[PANEL] support key: %s / %v\n", l1Field, v)
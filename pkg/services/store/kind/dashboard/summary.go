@@ -107,3 +107,23 @@ func NewStaticDashboardSummaryBuilder(lookup DatasourceLookup, sanitize bool) mo
 		return summary, body, err
 	}
 }
+
+// ReadDatasourceRefs returns the distinct data sources queried by the panels of a dashboard,
+// including the panels of collapsed rows. Panels without a data source reference the default.
+func ReadDatasourceRefs(body []byte, lookup DatasourceLookup) ([]DataSourceRef, error) {
+	dash, err := readDashboard(bytes.NewReader(body), lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []DataSourceRef
+	var add func(panels []panelInfo)
+	add = func(panels []panelInfo) {
+		for _, panel := range panels {
+			refs = append(refs, panel.Datasource...)
+			add(panel.Collapsed)
+		}
+	}
+	add(dash.Panels)
+	return unique(refs), nil
+}
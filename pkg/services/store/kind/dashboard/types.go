@@ -6,8 +6,9 @@ type panelInfo struct {
 	Description   string          `json:"description,omitempty"`
 	Type          string          `json:"type,omitempty"` // PluginID
 	PluginVersion string          `json:"pluginVersion,omitempty"`
-	Datasource    []DataSourceRef `json:"datasource,omitempty"`  // UIDs
-	Transformer   []string        `json:"transformer,omitempty"` // ids of the transformation steps
+	Datasource    []DataSourceRef `json:"datasource,omitempty"`   // UIDs
+	Transformer   []string        `json:"transformer,omitempty"`  // ids of the transformation steps
+	LibraryPanel  string          `json:"libraryPanel,omitempty"` // UID of the library panel
 
 	// Rows define panels as sub objects
 	Collapsed []panelInfo `json:"collapsed,omitempty"`
@@ -0,0 +1,290 @@
+package synthetics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *SyntheticsService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/synthetics", func(synthetics routing.RouteRegister) {
+		synthetics.Get("/checks", routing.Wrap(s.getChecksHandler))
+		synthetics.Post("/checks", middleware.ReqEditorRole, routing.Wrap(s.createCheckHandler))
+		synthetics.Get("/checks/:uid", routing.Wrap(s.getCheckHandler))
+		synthetics.Put("/checks/:uid", middleware.ReqEditorRole, routing.Wrap(s.updateCheckHandler))
+		synthetics.Delete("/checks/:uid", middleware.ReqEditorRole, routing.Wrap(s.deleteCheckHandler))
+		synthetics.Get("/checks/:uid/results", routing.Wrap(s.getResultsHandler))
+		synthetics.Get("/probes/:probe/checks", middleware.ReqEditorRole, routing.Wrap(s.getProbeChecksHandler))
+		synthetics.Post("/probes/:probe/results", middleware.ReqEditorRole, routing.Wrap(s.reportResultsHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /synthetics/checks synthetics getSyntheticChecks
+//
+// Get all synthetic checks of the current organization.
+//
+// Responses:
+// 200: getSyntheticChecksResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *SyntheticsService) getChecksHandler(c *models.ReqContext) response.Response {
+	checks, err := s.GetChecks(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, checks)
+}
+
+// swagger:route GET /synthetics/checks/{check_uid} synthetics getSyntheticCheck
+//
+// Get a synthetic check by uid.
+//
+// Responses:
+// 200: syntheticCheckResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *SyntheticsService) getCheckHandler(c *models.ReqContext) response.Response {
+	check, err := s.GetCheck(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, check)
+}
+
+// swagger:route POST /synthetics/checks synthetics createSyntheticCheck
+//
+// Create a synthetic check.
+//
+// Checks without probes are executed by the Grafana server. Requires the editor role.
+//
+// Responses:
+// 200: syntheticCheckResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *SyntheticsService) createCheckHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateCheckCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	check, err := s.CreateCheck(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, check)
+}
+
+// swagger:route PUT /synthetics/checks/{check_uid} synthetics updateSyntheticCheck
+//
+// Replace the settings of a synthetic check.
+//
+// Requires the editor role.
+//
+// Responses:
+// 200: syntheticCheckResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *SyntheticsService) updateCheckHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateCheckCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	check, err := s.UpdateCheck(c.Req.Context(), web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, check)
+}
+
+// swagger:route DELETE /synthetics/checks/{check_uid} synthetics deleteSyntheticCheck
+//
+// Delete a synthetic check and its results.
+//
+// Requires the editor role.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *SyntheticsService) deleteCheckHandler(c *models.ReqContext) response.Response {
+	if err := s.DeleteCheck(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("Synthetic check deleted")
+}
+
+// swagger:route GET /synthetics/checks/{check_uid}/results synthetics getSyntheticCheckResults
+//
+// Get the results of a synthetic check.
+//
+// Returns the latest results of the last hour, or of the period between the from and to parameters,
+// ordered by time.
+//
+// Responses:
+// 200: getSyntheticCheckResultsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *SyntheticsService) getResultsHandler(c *models.ReqContext) response.Response {
+	query := &ResultsQuery{
+		OrgID:    c.OrgID,
+		CheckUID: web.Params(c.Req)[":uid"],
+		Probe:    c.Query("probe"),
+		To:       s.now(),
+		Limit:    c.QueryInt("limit"),
+	}
+	query.From = query.To.Add(-time.Hour)
+	for param, t := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if raw := c.Query(param); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return response.Error(http.StatusBadRequest, param+" must be an RFC 3339 time", err)
+			}
+			*t = parsed
+		}
+	}
+	results, err := s.GetResults(c.Req.Context(), query)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, results)
+}
+
+// swagger:route GET /synthetics/probes/{probe}/checks synthetics getSyntheticProbeChecks
+//
+// Get the enabled synthetic checks a remote agent executes.
+//
+// Remote agents poll this endpoint with the token of a service account with the editor role.
+//
+// Responses:
+// 200: getSyntheticChecksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *SyntheticsService) getProbeChecksHandler(c *models.ReqContext) response.Response {
+	checks, err := s.GetProbeChecks(c.Req.Context(), c.OrgID, web.Params(c.Req)[":probe"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, checks)
+}
+
+// swagger:route POST /synthetics/probes/{probe}/results synthetics reportSyntheticProbeResults
+//
+// Report the results of synthetic checks executed by a remote agent.
+//
+// All checks must be enabled and executed by the probe. Requires the editor role.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *SyntheticsService) reportResultsHandler(c *models.ReqContext) response.Response {
+	cmd := ReportResultsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.Probe = web.Params(c.Req)[":probe"]
+	if err := s.ReportResults(c.Req.Context(), &cmd); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("Synthetic check results stored")
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrCheckNotFound):
+		return response.Error(http.StatusNotFound, "Synthetic check not found", err)
+	case errors.Is(err, ErrCheckExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidCheck), errors.Is(err, ErrInvalidResult):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process synthetic check", err)
+}
+
+// swagger:parameters getSyntheticCheck updateSyntheticCheck deleteSyntheticCheck getSyntheticCheckResults
+type CheckUIDParams struct {
+	// in:path
+	// required:true
+	CheckUID string `json:"check_uid"`
+}
+
+// swagger:parameters createSyntheticCheck updateSyntheticCheck
+type CreateOrUpdateCheckParams struct {
+	// in:body
+	// required:true
+	Body CreateOrUpdateCheckCommand `json:"body"`
+}
+
+// swagger:parameters getSyntheticCheckResults
+type GetResultsParams struct {
+	// RFC 3339 start of the period, defaults to an hour before to.
+	// in:query
+	// required:false
+	From string `json:"from"`
+	// RFC 3339 end of the period, defaults to now.
+	// in:query
+	// required:false
+	To string `json:"to"`
+	// Only return the results of this probe.
+	// in:query
+	// required:false
+	Probe string `json:"probe"`
+	// Maximum number of results, defaults to 1000 and at most 10000.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters getSyntheticProbeChecks reportSyntheticProbeResults
+type ProbeParams struct {
+	// in:path
+	// required:true
+	Probe string `json:"probe"`
+}
+
+// swagger:parameters reportSyntheticProbeResults
+type ReportResultsParams struct {
+	// in:body
+	// required:true
+	Body ReportResultsCommand `json:"body"`
+}
+
+// swagger:response getSyntheticChecksResponse
+type GetChecksResponse struct {
+	// in:body
+	Body []*Check `json:"body"`
+}
+
+// swagger:response syntheticCheckResponse
+type CheckResponse struct {
+	// in:body
+	Body *Check `json:"body"`
+}
+
+// swagger:response getSyntheticCheckResultsResponse
+type GetResultsResponse struct {
+	// in:body
+	Body []*Result `json:"body"`
+}
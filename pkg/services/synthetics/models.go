@@ -0,0 +1,294 @@
+package synthetics
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+const (
+	CheckTypeHTTP = "http"
+	CheckTypeTCP  = "tcp"
+	CheckTypeICMP = "icmp"
+
+	// LocalProbe is the probe of the Grafana server. Any other probe is a remote agent which fetches
+	// its checks and reports their results through the API.
+	LocalProbe = "local"
+
+	maxNameLength      = 190
+	maxProbes          = 10
+	maxReportedResults = 1000
+	maxErrorLength     = 255
+	minFrequency       = 10 * time.Second
+	maxFrequency       = time.Hour
+	defaultFrequency   = "1m"
+	defaultTimeout     = "10s"
+	defaultResultLimit = 1000
+	maxResultLimit     = 10000
+)
+
+var (
+	ErrCheckNotFound = errors.New("synthetic check not found")
+	ErrInvalidCheck  = errors.New("invalid synthetic check")
+	ErrInvalidResult = errors.New("invalid synthetic check result")
+	ErrCheckExists   = errors.New("a synthetic check with the same uid already exists")
+
+	probeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,40}$`)
+)
+
+// Check probes a target at a frequency from one or more probes.
+// swagger:model SyntheticCheck
+type Check struct {
+	ID    int64  `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	UID   string `json:"uid" xorm:"uid"`
+	Name  string `json:"name" xorm:"name"`
+	// One of `http`, `tcp` and `icmp`.
+	Type string `json:"type" xorm:"type"`
+	// URL of an http check, host:port of a tcp check or host of an icmp check.
+	Target string `json:"target" xorm:"target"`
+	// How often the check is executed, e.g. `30s` or `5m`.
+	Frequency string `json:"frequency" xorm:"frequency"`
+	// How long a probe waits for the target, at most the frequency.
+	Timeout string `json:"timeout" xorm:"timeout"`
+	// Probes executing the check, `local` for the Grafana server or the names of remote agents.
+	Probes   []string      `json:"probes" xorm:"jsonb probes"`
+	Settings CheckSettings `json:"settings" xorm:"jsonb settings"`
+	Enabled  bool          `json:"enabled" xorm:"enabled"`
+	Created  time.Time     `json:"created" xorm:"created"`
+	Updated  time.Time     `json:"updated" xorm:"updated"`
+}
+
+func (c Check) TableName() string {
+	return "synthetic_check"
+}
+
+// hasProbe returns true if the probe executes the check.
+func (c *Check) hasProbe(probe string) bool {
+	for _, p := range c.Probes {
+		if p == probe {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Check) frequency() time.Duration {
+	d, _ := gtime.ParseDuration(c.Frequency)
+	return d
+}
+
+func (c *Check) timeout() time.Duration {
+	d, _ := gtime.ParseDuration(c.Timeout)
+	return d
+}
+
+// CheckSettings configures http checks.
+type CheckSettings struct {
+	// HTTP method, defaults to GET.
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Status codes of a successful response, defaults to any 2xx status code.
+	ValidStatusCodes []int `json:"validStatusCodes,omitempty"`
+	// Regular expression the body of a successful response matches.
+	BodyMatch     string `json:"bodyMatch,omitempty"`
+	TLSSkipVerify bool   `json:"tlsSkipVerify,omitempty"`
+}
+
+// Result is the outcome of executing a check from a probe.
+// swagger:model SyntheticCheckResult
+type Result struct {
+	ID      int64     `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID   int64     `json:"-" xorm:"org_id"`
+	CheckID int64     `json:"-" xorm:"check_id"`
+	Probe   string    `json:"probe" xorm:"probe"`
+	Time    time.Time `json:"time" xorm:"executed_at"`
+	Success bool      `json:"success" xorm:"success"`
+	// Duration in milliseconds.
+	Duration float64 `json:"duration" xorm:"duration"`
+	// Status code of the response to an http check.
+	StatusCode int    `json:"statusCode,omitempty" xorm:"status_code"`
+	Error      string `json:"error,omitempty" xorm:"error"`
+}
+
+func (r Result) TableName() string {
+	return "synthetic_check_result"
+}
+
+// CreateOrUpdateCheckCommand creates a check, or replaces the settings of a check.
+// swagger:model
+type CreateOrUpdateCheckCommand struct {
+	OrgID int64 `json:"-"`
+	// UID of a new check, generated if empty.
+	UID       string        `json:"uid"`
+	Name      string        `json:"name"`
+	Type      string        `json:"type"`
+	Target    string        `json:"target"`
+	Frequency string        `json:"frequency"`
+	Timeout   string        `json:"timeout"`
+	Probes    []string      `json:"probes"`
+	Settings  CheckSettings `json:"settings"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+func (cmd *CreateOrUpdateCheckCommand) validate() error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if cmd.Name == "" || utf8.RuneCountInString(cmd.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be between 1 and %d characters", ErrInvalidCheck, maxNameLength)
+	}
+	if len(cmd.UID) > 40 {
+		return fmt.Errorf("%w: uid must be at most 40 characters", ErrInvalidCheck)
+	}
+	cmd.Target = strings.TrimSpace(cmd.Target)
+	if err := validateTarget(cmd.Type, cmd.Target); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidCheck, err)
+	}
+
+	if cmd.Frequency == "" {
+		cmd.Frequency = defaultFrequency
+	}
+	frequency, err := gtime.ParseDuration(cmd.Frequency)
+	if err != nil || frequency < minFrequency || frequency > maxFrequency {
+		return fmt.Errorf("%w: frequency must be between %s and %s", ErrInvalidCheck, minFrequency, maxFrequency)
+	}
+	if cmd.Timeout == "" {
+		cmd.Timeout = defaultTimeout
+	}
+	timeout, err := gtime.ParseDuration(cmd.Timeout)
+	if err != nil || timeout <= 0 || timeout > frequency {
+		return fmt.Errorf("%w: timeout must be positive and at most the frequency", ErrInvalidCheck)
+	}
+
+	if len(cmd.Probes) == 0 {
+		cmd.Probes = []string{LocalProbe}
+	}
+	if len(cmd.Probes) > maxProbes {
+		return fmt.Errorf("%w: a check has at most %d probes", ErrInvalidCheck, maxProbes)
+	}
+	seen := make(map[string]bool, len(cmd.Probes))
+	probes := make([]string, 0, len(cmd.Probes))
+	for _, p := range cmd.Probes {
+		if !probeNamePattern.MatchString(p) {
+			return fmt.Errorf("%w: invalid probe name %q", ErrInvalidCheck, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			probes = append(probes, p)
+		}
+	}
+	cmd.Probes = probes
+
+	if cmd.Type == CheckTypeHTTP {
+		return cmd.Settings.validate()
+	}
+	cmd.Settings = CheckSettings{}
+	return nil
+}
+
+func validateTarget(checkType, target string) error {
+	switch checkType {
+	case CheckTypeHTTP:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("the target of an http check must be an http or https URL")
+		}
+	case CheckTypeTCP:
+		host, port, err := net.SplitHostPort(target)
+		if err != nil || host == "" || port == "" {
+			return errors.New("the target of a tcp check must be host:port")
+		}
+	case CheckTypeICMP:
+		if target == "" || strings.ContainsAny(target, "/: ") && net.ParseIP(target) == nil {
+			return errors.New("the target of an icmp check must be a host name or an IP address")
+		}
+	default:
+		return fmt.Errorf("type must be one of %s, %s and %s", CheckTypeHTTP, CheckTypeTCP, CheckTypeICMP)
+	}
+	return nil
+}
+
+func (s *CheckSettings) validate() error {
+	if s.Method == "" {
+		s.Method = http.MethodGet
+	}
+	s.Method = strings.ToUpper(s.Method)
+	switch s.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodOptions:
+	default:
+		return fmt.Errorf("%w: method must be one of GET, HEAD, POST, PUT and OPTIONS", ErrInvalidCheck)
+	}
+	for _, code := range s.ValidStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("%w: invalid status code %d", ErrInvalidCheck, code)
+		}
+	}
+	if s.BodyMatch != "" {
+		if _, err := regexp.Compile(s.BodyMatch); err != nil {
+			return fmt.Errorf("%w: invalid body match: %s", ErrInvalidCheck, err)
+		}
+	}
+	return nil
+}
+
+// ReportResultsCommand stores the results of checks executed by a remote agent.
+type ReportResultsCommand struct {
+	OrgID   int64         `json:"-"`
+	Probe   string        `json:"-"`
+	Results []ProbeResult `json:"results"`
+}
+
+// ProbeResult is the outcome of executing a check, as reported by a remote agent.
+// swagger:model SyntheticProbeResult
+type ProbeResult struct {
+	CheckUID string    `json:"checkUid"`
+	Time     time.Time `json:"time"`
+	Success  bool      `json:"success"`
+	// Duration in milliseconds.
+	Duration   float64 `json:"duration"`
+	StatusCode int     `json:"statusCode"`
+	Error      string  `json:"error"`
+}
+
+func (cmd *ReportResultsCommand) validate(now time.Time) error {
+	if !probeNamePattern.MatchString(cmd.Probe) || cmd.Probe == LocalProbe {
+		return fmt.Errorf("%w: invalid probe name %q", ErrInvalidResult, cmd.Probe)
+	}
+	if len(cmd.Results) > maxReportedResults {
+		return fmt.Errorf("%w: at most %d results can be reported at once", ErrInvalidResult, maxReportedResults)
+	}
+	for _, r := range cmd.Results {
+		if r.CheckUID == "" || r.Time.IsZero() || r.Time.After(now.Add(time.Minute)) || r.Duration < 0 {
+			return fmt.Errorf("%w: results need the uid of the check, a time which is not in the future and a positive duration", ErrInvalidResult)
+		}
+	}
+	return nil
+}
+
+// ResultsQuery selects the results of a check in a period.
+type ResultsQuery struct {
+	OrgID    int64
+	CheckUID string
+	// Probe filters the results by probe if not empty.
+	Probe string
+	From  time.Time
+	To    time.Time
+	// Limit is the maximum number of results, the latest are returned.
+	Limit int
+}
+
+// truncateError shortens error messages to fit the column of results.
+func truncateError(msg string) string {
+	if utf8.RuneCountInString(msg) <= maxErrorLength {
+		return msg
+	}
+	return string([]rune(msg)[:maxErrorLength])
+}
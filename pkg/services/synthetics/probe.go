@@ -0,0 +1,251 @@
+package synthetics
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// maxBodySize limits how much of the body of a response is matched against the body match of an
+// http check.
+const maxBodySize = 1 << 20
+
+// echoSequence numbers the echo requests of icmp checks.
+var echoSequence uint32
+
+// prober executes checks from the Grafana server. Connections are not reused, so that the duration
+// of every probe includes connecting to the target.
+type prober struct {
+	client         *http.Client
+	insecureClient *http.Client
+}
+
+func newProber() *prober {
+	newClient := func(skipVerify bool) *http.Client {
+		return &http.Client{
+			Transport: &http.Transport{
+				Proxy:             http.ProxyFromEnvironment,
+				DisableKeepAlives: true,
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: skipVerify}, // #nosec G402 -- opt-in per check
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 10 {
+					return errors.New("stopped after 10 redirects")
+				}
+				return nil
+			},
+		}
+	}
+	return &prober{
+		client:         newClient(false),
+		insecureClient: newClient(true),
+	}
+}
+
+// probe executes a check and returns its result.
+func (p *prober) probe(ctx context.Context, check *Check, now time.Time) *Result {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout())
+	defer cancel()
+
+	var (
+		statusCode int
+		err        error
+	)
+	start := time.Now()
+	switch check.Type {
+	case CheckTypeHTTP:
+		statusCode, err = p.probeHTTP(ctx, check)
+	case CheckTypeTCP:
+		err = probeTCP(ctx, check.Target)
+	case CheckTypeICMP:
+		err = probeICMP(ctx, check.Target)
+	default:
+		err = fmt.Errorf("unknown check type %q", check.Type)
+	}
+	duration := time.Since(start)
+
+	result := &Result{
+		OrgID:      check.OrgID,
+		CheckID:    check.ID,
+		Probe:      LocalProbe,
+		Time:       now,
+		Success:    err == nil,
+		Duration:   float64(duration) / float64(time.Millisecond),
+		StatusCode: statusCode,
+	}
+	if err != nil {
+		result.Error = truncateError(err.Error())
+	}
+	return result
+}
+
+func (p *prober) probeHTTP(ctx context.Context, check *Check) (int, error) {
+	settings := check.Settings
+	req, err := http.NewRequestWithContext(ctx, settings.Method, check.Target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Grafana synthetic monitoring")
+	for k, v := range settings.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := p.client
+	if settings.TLSSkipVerify {
+		client = p.insecureClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if !validStatusCode(settings.ValidStatusCodes, resp.StatusCode) {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if settings.BodyMatch == "" {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize))
+		return resp.StatusCode, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	re, err := regexp.Compile(settings.BodyMatch)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if !re.Match(body) {
+		return resp.StatusCode, errors.New("the body does not match")
+	}
+	return resp.StatusCode, nil
+}
+
+func validStatusCode(valid []int, code int) bool {
+	if len(valid) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, c := range valid {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func probeTCP(ctx context.Context, target string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeICMP sends an echo request to the host and waits for the reply. It uses unprivileged ICMP
+// sockets if the system allows them, and raw sockets otherwise.
+func probeICMP(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no address found for %s", host)
+	}
+	addr := addrs[0]
+	for _, a := range addrs {
+		if a.IP.To4() != nil {
+			addr = a
+			break
+		}
+	}
+
+	var (
+		echoType  icmp.Type = ipv4.ICMPTypeEcho
+		replyType icmp.Type = ipv4.ICMPTypeEchoReply
+		protocol            = 1
+		networks            = [2]string{"udp4", "ip4:icmp"}
+		listen              = "0.0.0.0"
+	)
+	if addr.IP.To4() == nil {
+		echoType, replyType, protocol = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply, 58
+		networks, listen = [2]string{"udp6", "ip6:ipv6-icmp"}, "::"
+	}
+
+	privileged := false
+	conn, err := icmp.ListenPacket(networks[0], listen)
+	if err != nil {
+		privileged = true
+		if conn, err = icmp.ListenPacket(networks[1], listen); err != nil {
+			return fmt.Errorf("failed to open an ICMP socket: %w", err)
+		}
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	id := os.Getpid() & 0xffff
+	seq := int(atomic.AddUint32(&echoSequence, 1) & 0xffff)
+	msg := icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("grafana-synthetics")},
+	}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return err
+	}
+	var dst net.Addr = &net.UDPAddr{IP: addr.IP, Zone: addr.Zone}
+	if privileged {
+		dst = &net.IPAddr{IP: addr.IP, Zone: addr.Zone}
+	}
+	if _, err := conn.WriteTo(b, dst); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		reply, err := icmp.ParseMessage(protocol, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		// The kernel replaces the id of echo requests sent through unprivileged sockets.
+		if !ok || echo.Seq != seq || (privileged && echo.ID != id) || !sameIP(peer, addr.IP) {
+			continue
+		}
+		return nil
+	}
+}
+
+func sameIP(peer net.Addr, ip net.IP) bool {
+	switch a := peer.(type) {
+	case *net.UDPAddr:
+		return a.IP.Equal(ip)
+	case *net.IPAddr:
+		return a.IP.Equal(ip)
+	}
+	return false
+}
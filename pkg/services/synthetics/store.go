@@ -0,0 +1,121 @@
+package synthetics
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *SyntheticsService) insertCheck(ctx context.Context, check *Check) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", check.OrgID, check.UID).Exist(&Check{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrCheckExists
+		}
+		_, err = sess.Insert(check)
+		return err
+	})
+}
+
+func (s *SyntheticsService) updateCheck(ctx context.Context, check *Check) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ?", check.OrgID).ID(check.ID).
+			Cols("name", "type", "target", "frequency", "timeout", "probes", "settings", "enabled", "updated").
+			Update(check)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrCheckNotFound
+		}
+		return nil
+	})
+}
+
+func (s *SyntheticsService) deleteCheck(ctx context.Context, check *Check) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ?", check.OrgID).ID(check.ID).Delete(&Check{}); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM synthetic_check_result WHERE org_id = ? AND check_id = ?", check.OrgID, check.ID)
+		return err
+	})
+}
+
+func (s *SyntheticsService) getCheck(ctx context.Context, orgID int64, uid string) (*Check, error) {
+	check := &Check{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(check)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCheckNotFound
+		}
+		return nil
+	})
+	return check, err
+}
+
+func (s *SyntheticsService) listChecks(ctx context.Context, orgID int64) ([]*Check, error) {
+	checks := make([]*Check, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&checks)
+	})
+	return checks, err
+}
+
+// listEnabledChecks returns the enabled checks of all organizations.
+func (s *SyntheticsService) listEnabledChecks(ctx context.Context) ([]*Check, error) {
+	checks := make([]*Check, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ?", s.store.GetDialect().BooleanStr(true)).Find(&checks)
+	})
+	return checks, err
+}
+
+func (s *SyntheticsService) insertResults(ctx context.Context, results []*Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.InsertMulti(results)
+		return err
+	})
+}
+
+// listResults returns the latest results of a check in the period of the query, ordered by time.
+func (s *SyntheticsService) listResults(ctx context.Context, check *Check, query *ResultsQuery) ([]*Result, error) {
+	results := make([]*Result, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ? AND check_id = ?", check.OrgID, check.ID)
+		if !query.From.IsZero() {
+			sess.And("executed_at >= ?", query.From)
+		}
+		if !query.To.IsZero() {
+			sess.And("executed_at <= ?", query.To)
+		}
+		if query.Probe != "" {
+			sess.And("probe = ?", query.Probe)
+		}
+		return sess.Desc("executed_at").Limit(query.Limit).Find(&results)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results, nil
+}
+
+func (s *SyntheticsService) deleteResultsBefore(ctx context.Context, before time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM synthetic_check_result WHERE executed_at < ?", before)
+		return err
+	})
+}
@@ -0,0 +1,343 @@
+// Package synthetics executes simple uptime checks of HTTP endpoints, TCP ports and hosts
+// answering ICMP echo requests, without a separate synthetic monitoring stack.
+//
+// Checks are executed by probes: the Grafana server itself, named local, or remote agents which
+// fetch their checks and report the results through the API. The results are queried through the
+// built-in synthetic checks data source.
+package synthetics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	scheduleTick    = 5 * time.Second
+	cleanupInterval = time.Hour
+)
+
+type settings struct {
+	enabled bool
+	// maxConcurrentChecks limits the checks the local probe executes at the same time.
+	maxConcurrentChecks int
+	// retention is how long results are kept, 0 keeps them forever.
+	retention time.Duration
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("synthetic_monitoring")
+	s := settings{
+		enabled:             section.Key("enabled").MustBool(false),
+		maxConcurrentChecks: section.Key("max_concurrent_checks").MustInt(10),
+		retention:           7 * 24 * time.Hour,
+	}
+	if s.maxConcurrentChecks < 1 {
+		s.maxConcurrentChecks = 1
+	}
+	if value := section.Key("retention").MustString("7d"); value == "0" {
+		s.retention = 0
+	} else if d, err := gtime.ParseDuration(value); err == nil && d > 0 {
+		s.retention = d
+	}
+	return s
+}
+
+type Service interface {
+	GetChecks(ctx context.Context, orgID int64) ([]*Check, error)
+	GetCheck(ctx context.Context, orgID int64, uid string) (*Check, error)
+	CreateCheck(ctx context.Context, cmd *CreateOrUpdateCheckCommand) (*Check, error)
+	UpdateCheck(ctx context.Context, uid string, cmd *CreateOrUpdateCheckCommand) (*Check, error)
+	// DeleteCheck deletes a check and its results.
+	DeleteCheck(ctx context.Context, orgID int64, uid string) error
+	// GetProbeChecks returns the enabled checks a probe executes.
+	GetProbeChecks(ctx context.Context, orgID int64, probe string) ([]*Check, error)
+	// ReportResults stores the results of checks executed by a remote agent.
+	ReportResults(ctx context.Context, cmd *ReportResultsCommand) error
+	// GetResults returns the results of a check in a period, ordered by time.
+	GetResults(ctx context.Context, query *ResultsQuery) ([]*Result, error)
+}
+
+type SyntheticsService struct {
+	settings       settings
+	store          db.DB
+	leaderElection leaderelection.Service
+	routeRegister  routing.RouteRegister
+	log            log.Logger
+	now            func() time.Time
+
+	prober *prober
+
+	mu sync.Mutex
+	// lastRun is when the local probe last executed each check, by check id.
+	lastRun map[int64]time.Time
+	// running holds the ids of the checks the local probe is executing.
+	running map[int64]bool
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, leaderElection leaderelection.Service,
+	routeRegister routing.RouteRegister) *SyntheticsService {
+	s := &SyntheticsService{
+		settings:       readSettings(cfg),
+		store:          sqlStore,
+		leaderElection: leaderElection,
+		routeRegister:  routeRegister,
+		log:            log.New("synthetics"),
+		now:            time.Now,
+		prober:         newProber(),
+		lastRun:        map[int64]time.Time{},
+		running:        map[int64]bool{},
+	}
+	if s.settings.enabled {
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+func (s *SyntheticsService) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+// Run executes the checks of the local probe at their frequency on the node elected as leader, and
+// deletes the results past the retention.
+func (s *SyntheticsService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	sem := make(chan struct{}, s.settings.maxConcurrentChecks)
+	var lastCleanup time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !s.leaderElection.IsLeader(ctx, leaderelection.LeaseSyntheticMonitoring) {
+				continue
+			}
+			checks, err := s.dueChecks(ctx)
+			if err != nil {
+				s.log.Error("Failed to get the synthetic checks", "error", err)
+				continue
+			}
+			for _, check := range checks {
+				select {
+				case sem <- struct{}{}:
+				default:
+					s.log.Warn("Delaying synthetic check, too many checks are running", "org", check.OrgID, "uid", check.UID)
+					s.finish(check, false)
+					continue
+				}
+				wg.Add(1)
+				go func(check *Check) {
+					defer func() {
+						<-sem
+						s.finish(check, true)
+						wg.Done()
+					}()
+					s.execute(ctx, check)
+				}(check)
+			}
+
+			if now := s.now(); s.settings.retention > 0 && now.Sub(lastCleanup) >= cleanupInterval {
+				lastCleanup = now
+				if err := s.deleteResultsBefore(ctx, now.Add(-s.settings.retention)); err != nil {
+					s.log.Error("Failed to delete old synthetic check results", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// dueChecks returns the enabled checks of the local probe which were not executed within their
+// frequency, and marks them as running.
+func (s *SyntheticsService) dueChecks(ctx context.Context) ([]*Check, error) {
+	checks, err := s.listEnabledChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := make([]*Check, 0)
+	current := make(map[int64]bool, len(checks))
+	for _, check := range checks {
+		if !check.hasProbe(LocalProbe) {
+			continue
+		}
+		current[check.ID] = true
+		if s.running[check.ID] || now.Sub(s.lastRun[check.ID]) < check.frequency() {
+			continue
+		}
+		s.running[check.ID] = true
+		s.lastRun[check.ID] = now
+		due = append(due, check)
+	}
+	for id := range s.lastRun {
+		if !current[id] {
+			delete(s.lastRun, id)
+		}
+	}
+	return due, nil
+}
+
+// finish marks a check as not running. A check which was not executed is due again on the next
+// tick.
+func (s *SyntheticsService) finish(check *Check, executed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, check.ID)
+	if !executed {
+		delete(s.lastRun, check.ID)
+	}
+}
+
+func (s *SyntheticsService) execute(ctx context.Context, check *Check) {
+	result := s.prober.probe(ctx, check, s.now())
+	if ctx.Err() != nil {
+		return
+	}
+	if err := s.insertResults(ctx, []*Result{result}); err != nil {
+		s.log.Error("Failed to store the result of a synthetic check", "org", check.OrgID, "uid", check.UID, "error", err)
+	}
+}
+
+func (s *SyntheticsService) GetChecks(ctx context.Context, orgID int64) ([]*Check, error) {
+	return s.listChecks(ctx, orgID)
+}
+
+func (s *SyntheticsService) GetCheck(ctx context.Context, orgID int64, uid string) (*Check, error) {
+	return s.getCheck(ctx, orgID, uid)
+}
+
+func (s *SyntheticsService) CreateCheck(ctx context.Context, cmd *CreateOrUpdateCheckCommand) (*Check, error) {
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+
+	now := s.now()
+	check := &Check{
+		OrgID:   cmd.OrgID,
+		UID:     cmd.UID,
+		Created: now,
+	}
+	applyCommand(check, cmd, now)
+	if err := s.insertCheck(ctx, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func (s *SyntheticsService) UpdateCheck(ctx context.Context, uid string, cmd *CreateOrUpdateCheckCommand) (*Check, error) {
+	cmd.UID = ""
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	check, err := s.getCheck(ctx, cmd.OrgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	applyCommand(check, cmd, s.now())
+	if err := s.updateCheck(ctx, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func applyCommand(check *Check, cmd *CreateOrUpdateCheckCommand, now time.Time) {
+	check.Name = cmd.Name
+	check.Type = cmd.Type
+	check.Target = cmd.Target
+	check.Frequency = cmd.Frequency
+	check.Timeout = cmd.Timeout
+	check.Probes = cmd.Probes
+	check.Settings = cmd.Settings
+	check.Enabled = cmd.Enabled == nil || *cmd.Enabled
+	check.Updated = now
+}
+
+func (s *SyntheticsService) DeleteCheck(ctx context.Context, orgID int64, uid string) error {
+	check, err := s.getCheck(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	return s.deleteCheck(ctx, check)
+}
+
+func (s *SyntheticsService) GetProbeChecks(ctx context.Context, orgID int64, probe string) ([]*Check, error) {
+	checks, err := s.listChecks(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Check, 0, len(checks))
+	for _, check := range checks {
+		if check.Enabled && check.hasProbe(probe) {
+			result = append(result, check)
+		}
+	}
+	return result, nil
+}
+
+func (s *SyntheticsService) ReportResults(ctx context.Context, cmd *ReportResultsCommand) error {
+	if err := cmd.validate(s.now()); err != nil {
+		return err
+	}
+	checks, err := s.GetProbeChecks(ctx, cmd.OrgID, cmd.Probe)
+	if err != nil {
+		return err
+	}
+	byUID := make(map[string]*Check, len(checks))
+	for _, check := range checks {
+		byUID[check.UID] = check
+	}
+
+	results := make([]*Result, 0, len(cmd.Results))
+	for _, r := range cmd.Results {
+		check, ok := byUID[r.CheckUID]
+		if !ok {
+			return fmt.Errorf("%w: check %s does not exist, is disabled or is not executed by probe %s", ErrInvalidResult, r.CheckUID, cmd.Probe)
+		}
+		results = append(results, &Result{
+			OrgID:      cmd.OrgID,
+			CheckID:    check.ID,
+			Probe:      cmd.Probe,
+			Time:       r.Time,
+			Success:    r.Success,
+			Duration:   r.Duration,
+			StatusCode: r.StatusCode,
+			Error:      truncateError(r.Error),
+		})
+	}
+	return s.insertResults(ctx, results)
+}
+
+func (s *SyntheticsService) GetResults(ctx context.Context, query *ResultsQuery) ([]*Result, error) {
+	check, err := s.getCheck(ctx, query.OrgID, query.CheckUID)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultResultLimit
+	}
+	if query.Limit > maxResultLimit {
+		query.Limit = maxResultLimit
+	}
+	return s.listResults(ctx, check, query)
+}
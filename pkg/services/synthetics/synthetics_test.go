@@ -0,0 +1,249 @@
+package synthetics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCreateOrUpdateCheckCommandValidate(t *testing.T) {
+	valid := func() *CreateOrUpdateCheckCommand {
+		return &CreateOrUpdateCheckCommand{Name: " Home ", Type: CheckTypeHTTP, Target: "https://example.com"}
+	}
+
+	cmd := valid()
+	require.NoError(t, cmd.validate())
+	assert.Equal(t, "Home", cmd.Name)
+	assert.Equal(t, defaultFrequency, cmd.Frequency)
+	assert.Equal(t, defaultTimeout, cmd.Timeout)
+	assert.Equal(t, []string{LocalProbe}, cmd.Probes)
+	assert.Equal(t, http.MethodGet, cmd.Settings.Method)
+
+	cmd = &CreateOrUpdateCheckCommand{Name: "db", Type: CheckTypeTCP, Target: "db:5432", Probes: []string{"eu", "eu", "us"},
+		Settings: CheckSettings{Method: "POST"}}
+	require.NoError(t, cmd.validate())
+	assert.Equal(t, []string{"eu", "us"}, cmd.Probes)
+	assert.Equal(t, CheckSettings{}, cmd.Settings)
+
+	for name, modify := range map[string]func(*CreateOrUpdateCheckCommand){
+		"empty name":           func(c *CreateOrUpdateCheckCommand) { c.Name = " " },
+		"unknown type":         func(c *CreateOrUpdateCheckCommand) { c.Type = "dns" },
+		"ftp target":           func(c *CreateOrUpdateCheckCommand) { c.Target = "ftp://example.com" },
+		"tcp without port":     func(c *CreateOrUpdateCheckCommand) { c.Type, c.Target = CheckTypeTCP, "example.com" },
+		"icmp url":             func(c *CreateOrUpdateCheckCommand) { c.Type, c.Target = CheckTypeICMP, "http://example.com" },
+		"frequency too short":  func(c *CreateOrUpdateCheckCommand) { c.Frequency = "5s" },
+		"frequency too long":   func(c *CreateOrUpdateCheckCommand) { c.Frequency = "2h" },
+		"timeout > frequency":  func(c *CreateOrUpdateCheckCommand) { c.Frequency, c.Timeout = "30s", "1m" },
+		"invalid probe":        func(c *CreateOrUpdateCheckCommand) { c.Probes = []string{"eu west"} },
+		"unsupported method":   func(c *CreateOrUpdateCheckCommand) { c.Settings.Method = "DELETE" },
+		"invalid status code":  func(c *CreateOrUpdateCheckCommand) { c.Settings.ValidStatusCodes = []int{99} },
+		"invalid body match":   func(c *CreateOrUpdateCheckCommand) { c.Settings.BodyMatch = "(" },
+		"too many probes":      func(c *CreateOrUpdateCheckCommand) { c.Probes = make([]string, maxProbes+1) },
+		"uid longer than 40ch": func(c *CreateOrUpdateCheckCommand) { c.UID = string(make([]byte, 41)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := valid()
+			modify(cmd)
+			assert.ErrorIs(t, cmd.validate(), ErrInvalidCheck)
+		})
+	}
+}
+
+func TestProbeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("status: ok, method: " + r.Method + ", token: " + r.Header.Get("X-Token")))
+	}))
+	t.Cleanup(server.Close)
+	p := newProber()
+	now := time.Now()
+
+	t.Run("successful check", func(t *testing.T) {
+		check := &Check{Type: CheckTypeHTTP, Target: server.URL, Timeout: "5s", Settings: CheckSettings{
+			Method: http.MethodPost, Headers: map[string]string{"X-Token": "secret"}, BodyMatch: "method: POST, token: secret",
+		}}
+		result := p.probe(context.Background(), check, now)
+		assert.True(t, result.Success, result.Error)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, LocalProbe, result.Probe)
+		assert.Equal(t, now, result.Time)
+	})
+
+	t.Run("unexpected status code", func(t *testing.T) {
+		check := &Check{Type: CheckTypeHTTP, Target: server.URL + "/missing", Timeout: "5s"}
+		result := p.probe(context.Background(), check, now)
+		assert.False(t, result.Success)
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+		assert.NotEmpty(t, result.Error)
+
+		check.Settings.ValidStatusCodes = []int{http.StatusNotFound}
+		assert.True(t, p.probe(context.Background(), check, now).Success)
+	})
+
+	t.Run("body does not match", func(t *testing.T) {
+		check := &Check{Type: CheckTypeHTTP, Target: server.URL, Timeout: "5s", Settings: CheckSettings{BodyMatch: "status: down"}}
+		result := p.probe(context.Background(), check, now)
+		assert.False(t, result.Success)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+}
+
+func TestProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	addr := listener.Addr().String()
+
+	p := newProber()
+	result := p.probe(context.Background(), &Check{Type: CheckTypeTCP, Target: addr, Timeout: "5s"}, time.Now())
+	assert.True(t, result.Success, result.Error)
+
+	require.NoError(t, listener.Close())
+	result = p.probe(context.Background(), &Check{Type: CheckTypeTCP, Target: addr, Timeout: "5s"}, time.Now())
+	assert.False(t, result.Success)
+	assert.NotEmpty(t, result.Error)
+}
+
+func TestIntegrationSyntheticChecks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+	cfg := setting.NewCfg()
+	s := ProvideService(cfg, sqlStore, leaderelection.ProvideService(cfg, sqlStore, routing.NewRouteRegister()),
+		routing.NewRouteRegister())
+	now := time.Date(2022, 12, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	local, err := s.CreateCheck(ctx, &CreateOrUpdateCheckCommand{
+		OrgID: 1, UID: "home", Name: "Home", Type: CheckTypeHTTP, Target: "https://example.com",
+	})
+	require.NoError(t, err)
+	assert.True(t, local.Enabled)
+	remote, err := s.CreateCheck(ctx, &CreateOrUpdateCheckCommand{
+		OrgID: 1, Name: "Database", Type: CheckTypeTCP, Target: "db:5432", Probes: []string{LocalProbe, "eu"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, remote.UID)
+
+	t.Run("creating a check with the same uid fails", func(t *testing.T) {
+		_, err := s.CreateCheck(ctx, &CreateOrUpdateCheckCommand{
+			OrgID: 1, UID: "home", Name: "Other", Type: CheckTypeICMP, Target: "example.com",
+		})
+		assert.ErrorIs(t, err, ErrCheckExists)
+	})
+
+	t.Run("checks are scoped to the organization", func(t *testing.T) {
+		checks, err := s.GetChecks(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+		assert.Equal(t, "Database", checks[0].Name)
+		assert.Equal(t, []string{LocalProbe, "eu"}, checks[0].Probes)
+
+		_, err = s.GetCheck(ctx, 2, "home")
+		assert.ErrorIs(t, err, ErrCheckNotFound)
+	})
+
+	t.Run("the local probe executes due checks once", func(t *testing.T) {
+		due, err := s.dueChecks(ctx)
+		require.NoError(t, err)
+		assert.Len(t, due, 2)
+
+		due, err = s.dueChecks(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+
+		s.finish(local, true)
+		s.finish(remote, false)
+		due, err = s.dueChecks(ctx)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, remote.UID, due[0].UID)
+		s.finish(remote, true)
+	})
+
+	t.Run("remote probes report results", func(t *testing.T) {
+		checks, err := s.GetProbeChecks(ctx, 1, "eu")
+		require.NoError(t, err)
+		require.Len(t, checks, 1)
+		assert.Equal(t, remote.UID, checks[0].UID)
+
+		err = s.ReportResults(ctx, &ReportResultsCommand{OrgID: 1, Probe: "eu", Results: []ProbeResult{
+			{CheckUID: remote.UID, Time: now.Add(-2 * time.Minute), Success: true, Duration: 12},
+			{CheckUID: remote.UID, Time: now.Add(-time.Minute), Success: false, Duration: 5000, Error: "timeout"},
+		}})
+		require.NoError(t, err)
+		require.NoError(t, s.insertResults(ctx, []*Result{
+			{OrgID: 1, CheckID: remote.ID, Probe: LocalProbe, Time: now.Add(-90 * time.Second), Success: true, Duration: 3},
+		}))
+
+		err = s.ReportResults(ctx, &ReportResultsCommand{OrgID: 1, Probe: "eu", Results: []ProbeResult{
+			{CheckUID: local.UID, Time: now, Success: true},
+		}})
+		assert.ErrorIs(t, err, ErrInvalidResult)
+		err = s.ReportResults(ctx, &ReportResultsCommand{OrgID: 1, Probe: LocalProbe})
+		assert.ErrorIs(t, err, ErrInvalidResult)
+	})
+
+	t.Run("get results", func(t *testing.T) {
+		results, err := s.GetResults(ctx, &ResultsQuery{OrgID: 1, CheckUID: remote.UID, From: now.Add(-time.Hour), To: now})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, "eu", results[0].Probe)
+		assert.Equal(t, LocalProbe, results[1].Probe)
+		assert.Equal(t, "timeout", results[2].Error)
+
+		results, err = s.GetResults(ctx, &ResultsQuery{OrgID: 1, CheckUID: remote.UID, Probe: "eu", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].Success)
+	})
+
+	t.Run("old results are deleted", func(t *testing.T) {
+		require.NoError(t, s.deleteResultsBefore(ctx, now.Add(-time.Minute-time.Second)))
+		results, err := s.GetResults(ctx, &ResultsQuery{OrgID: 1, CheckUID: remote.UID})
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("disabled checks are not executed", func(t *testing.T) {
+		disabled := false
+		_, err := s.UpdateCheck(ctx, remote.UID, &CreateOrUpdateCheckCommand{
+			OrgID: 1, Name: "Database", Type: CheckTypeTCP, Target: "db:5432", Probes: []string{"eu"}, Enabled: &disabled,
+		})
+		require.NoError(t, err)
+		checks, err := s.GetProbeChecks(ctx, 1, "eu")
+		require.NoError(t, err)
+		assert.Empty(t, checks)
+	})
+
+	t.Run("delete check", func(t *testing.T) {
+		require.NoError(t, s.DeleteCheck(ctx, 1, remote.UID))
+		assert.ErrorIs(t, s.DeleteCheck(ctx, 1, remote.UID), ErrCheckNotFound)
+		_, err := s.GetResults(ctx, &ResultsQuery{OrgID: 1, CheckUID: remote.UID})
+		assert.ErrorIs(t, err, ErrCheckNotFound)
+	})
+}
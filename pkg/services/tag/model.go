@@ -36,6 +36,15 @@ func ParseTagPairs(tagPairs []string) (tags []*Tag) {
 	return tags
 }
 
+// SplitTerm returns the key and the value of a single tag, see ParseTagPairs.
+func SplitTerm(term string) (key, value string) {
+	tags := ParseTagPairs([]string{term})
+	if len(tags) == 0 {
+		return "", ""
+	}
+	return tags[0].Key, tags[0].Value
+}
+
 func ContainsTag(existingTags []*Tag, tag *Tag) bool {
 	for _, t := range existingTags {
 		if t.Key == tag.Key && t.Value == tag.Value {
@@ -58,3 +67,69 @@ func JoinTagPairs(tags []*Tag) []string {
 
 	return tagPairs
 }
+
+// Filter matches structured tags by key and value. Filters are written like tags, a trailing
+// * turns them into prefix queries: `team:pay*` matches the values of the key team starting
+// with pay, `team:*` matches any value of the key team, and `env*` matches the keys starting
+// with env.
+type Filter struct {
+	Key         string
+	Value       string
+	KeyPrefix   bool
+	ValuePrefix bool
+}
+
+// IsPrefix returns whether the filter matches by prefix rather than by exact key or value.
+func (f Filter) IsPrefix() bool {
+	return f.KeyPrefix || f.ValuePrefix
+}
+
+// Where returns the SQL condition matching the filter on the given key and value columns. A
+// filter without value matches the key with any value.
+func (f Filter) Where(keyColumn, valueColumn, like string) (string, []interface{}) {
+	if f.KeyPrefix {
+		return keyColumn + " " + like + " ?", []interface{}{f.Key + "%"}
+	}
+	if f.Value == "" {
+		return keyColumn + " = ?", []interface{}{f.Key}
+	}
+	if f.ValuePrefix {
+		return "(" + keyColumn + " = ? AND " + valueColumn + " " + like + " ?)", []interface{}{f.Key, f.Value + "%"}
+	}
+	return "(" + keyColumn + " = ? AND " + valueColumn + " = ?)", []interface{}{f.Key, f.Value}
+}
+
+// ParseFilters parses tag filters. Filters without key are ignored.
+func ParseFilters(terms []string) []Filter {
+	filters := make([]Filter, 0, len(terms))
+	for _, term := range terms {
+		var f Filter
+		parts := strings.Split(term, ":")
+		f.Key = strings.TrimSpace(parts[0])
+		if len(parts) > 1 {
+			f.Value = strings.TrimSpace(parts[1])
+			if strings.HasSuffix(f.Value, "*") {
+				f.Value = strings.TrimSuffix(f.Value, "*")
+				f.ValuePrefix = true
+			}
+		} else if strings.HasSuffix(f.Key, "*") {
+			f.Key = strings.TrimSuffix(f.Key, "*")
+			f.KeyPrefix = true
+		}
+
+		if f.Key == "" || containsFilter(filters, f) {
+			continue
+		}
+		filters = append(filters, f)
+	}
+	return filters
+}
+
+func containsFilter(filters []Filter, filter Filter) bool {
+	for _, f := range filters {
+		if f == filter {
+			return true
+		}
+	}
+	return false
+}
@@ -94,3 +94,32 @@ func TestJoinTagPairs(t *testing.T) {
 		assert.Equal(t, "key3", tags[2])
 	})
 }
+
+func TestParseFilters(t *testing.T) {
+	filters := ParseFilters([]string{"outage", " team : pay* ", "env:*", "svc*", "type:outage", "type:outage", "*", ":prod"})
+	assert.Equal(t, []Filter{
+		{Key: "outage"},
+		{Key: "team", Value: "pay", ValuePrefix: true},
+		{Key: "env", ValuePrefix: true},
+		{Key: "svc", KeyPrefix: true},
+		{Key: "type", Value: "outage"},
+	}, filters)
+
+	t.Run("builds SQL conditions", func(t *testing.T) {
+		for _, tc := range []struct {
+			filter Filter
+			where  string
+			args   []interface{}
+		}{
+			{filter: filters[0], where: "k = ?", args: []interface{}{"outage"}},
+			{filter: filters[1], where: "(k = ? AND v LIKE ?)", args: []interface{}{"team", "pay%"}},
+			{filter: filters[2], where: "k = ?", args: []interface{}{"env"}},
+			{filter: filters[3], where: "k LIKE ?", args: []interface{}{"svc%"}},
+			{filter: filters[4], where: "(k = ? AND v = ?)", args: []interface{}{"type", "outage"}},
+		} {
+			where, args := tc.filter.Where("k", "v", "LIKE")
+			assert.Equal(t, tc.where, where)
+			assert.Equal(t, tc.args, args)
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -187,6 +188,11 @@ func isTeamNameTaken(orgId int64, name string, existingId int64, sess *db.Sessio
 	return false, nil
 }
 
+var teamSortColumns = map[string]string{
+	"name":  "team.name",
+	"email": "team.email",
+}
+
 func (ss *xormStore) Search(ctx context.Context, query *models.SearchTeamsQuery) error {
 	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
 		query.Result = models.SearchTeamQueryResult{
@@ -235,10 +241,26 @@ func (ss *xormStore) Search(ctx context.Context, query *models.SearchTeamsQuery)
 			params = append(params, acFilter.Args...)
 		}
 
-		sql.WriteString(` order by team.name asc`)
+		if query.Sort.Field == "" {
+			sql.WriteString(` order by team.name asc`)
+		} else {
+			column, ok := teamSortColumns[query.Sort.Field]
+			if !ok {
+				return pagination.ErrInvalidSort
+			}
+			if query.After != nil {
+				where, args := pagination.Where(query.Sort, query.After, column, "team.id")
+				sql.WriteString(` and ` + where)
+				params = append(params, args...)
+			}
+			sql.WriteString(` order by ` + pagination.OrderBy(query.Sort, column, "team.id"))
+		}
 
 		if query.Limit != 0 {
 			offset := query.Limit * (query.Page - 1)
+			if query.After != nil {
+				offset = 0
+			}
 			sql.WriteString(ss.db.GetDialect().LimitOffset(int64(query.Limit), int64(offset)))
 		}
 
@@ -155,6 +155,27 @@ func TestIntegrationSqlStorage(t *testing.T) {
 		require.Equal(t, dash.Id, res[0].Id)
 	})
 
+	t.Run("Should find dashboards with thumbnails rendered before the given time", func(t *testing.T) {
+		setup()
+		dash := insertTestDashboard(t, sqlStore, "test dash 23", 1, savedFolder.Id, false, "prod", "webapp")
+		upsertTestDashboardThumbnail(t, store, dash.Uid, dash.OrgId, dash.Version)
+
+		cmd := thumbs.FindDashboardsWithStaleThumbnailsCommand{
+			Kind:          kind,
+			Theme:         theme,
+			UpdatedBefore: time.Now().Add(-time.Hour),
+		}
+		res, err := store.FindDashboardsWithStaleThumbnails(context.Background(), &cmd)
+		require.NoError(t, err)
+		require.Len(t, res, 0)
+
+		cmd.UpdatedBefore = time.Now().Add(time.Minute)
+		res, err = store.FindDashboardsWithStaleThumbnails(context.Background(), &cmd)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, dash.Id, res[0].Id)
+	})
+
 	t.Run("Should not return dashboards with locked thumbnails even if they are outdated", func(t *testing.T) {
 		setup()
 		dash := insertTestDashboard(t, sqlStore, "test dash 23", 1, savedFolder.Id, false, "prod", "webapp")
@@ -136,6 +136,11 @@ func (ss *xormStore) FindDashboardsWithStaleThumbnails(ctx context.Context, cmd
 			query += " OR dashboard_thumbnail.ds_uids = ? OR dashboard_thumbnail.ds_uids IS NULL"
 			args = append(args, "")
 		}
+
+		if !cmd.UpdatedBefore.IsZero() {
+			query += " OR dashboard_thumbnail.updated < ?"
+			args = append(args, cmd.UpdatedBefore)
+		}
 		sess.Where(query+")", args...)
 
 		if !cmd.IncludeManuallyUploadedThumbnails {
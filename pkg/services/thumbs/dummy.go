@@ -19,6 +19,10 @@ func (ds *dummyService) GetImage(c *models.ReqContext) {
 	c.JSON(400, map[string]string{"error": "invalid size"})
 }
 
+func (ds *dummyService) GetThumbnail(c *models.ReqContext) {
+	c.JSON(404, map[string]string{"error": "dashboard previews are not enabled"})
+}
+
 func (ds *dummyService) UpdateThumbnailState(c *models.ReqContext) {
 	c.JSON(400, map[string]string{"error": "invalid size"})
 }
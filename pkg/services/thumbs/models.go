@@ -185,6 +185,9 @@ type FindDashboardsWithStaleThumbnailsCommand struct {
 	Theme                             models.Theme
 	Kind                              ThumbnailKind
 	Result                            []*DashboardWithStaleThumbnail
+
+	// UpdatedBefore includes thumbnails last rendered before it, even if their dashboard did not change.
+	UpdatedBefore time.Time
 }
 
 type SaveDashboardThumbnailCommand struct {
@@ -0,0 +1,127 @@
+package thumbs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+// savedDashboardsQueueSize is the number of saved dashboards waiting to be rendered. Dashboards saved
+// while the queue is full are left to the next scheduled crawl.
+const savedDashboardsQueueSize = 100
+
+type savedDashboard struct {
+	orgID int64
+	uid   string
+}
+
+func (hs *thumbService) handleDashboardSaved(_ context.Context, e *events.DashboardSaved) error {
+	select {
+	case hs.savedDashboards <- savedDashboard{orgID: e.OrgID, uid: e.UID}:
+	default:
+		hs.log.Debug("Render queue is full, leaving the thumbnail to the next crawl", "dashboardUid", e.UID, "orgId", e.OrgID)
+	}
+	return nil
+}
+
+// renderSavedDashboard renders the thumbnails of a saved dashboard in all themes. Thumbnails which
+// are locked, manually uploaded or already match the saved version are kept.
+func (hs *thumbService) renderSavedDashboard(ctx context.Context, d savedDashboard) {
+	res, err := hs.renderingService.HasCapability(ctx, rendering.ScalingDownImages)
+	if err != nil || !res.IsSupported {
+		return
+	}
+
+	query := models.GetDashboardQuery{Uid: d.uid, OrgId: d.orgID}
+	if err := hs.dashboardService.GetDashboard(ctx, &query); err != nil {
+		hs.log.Debug("Saved dashboard not found", "dashboardUid", d.uid, "orgId", d.orgID, "err", err)
+		return
+	}
+	dash := query.Result
+
+	var dsUids []string
+	authOpts := rendering.AuthOpts{
+		OrgID:   dash.OrgId,
+		UserID:  hs.scheduleOptions.auth.GetUserId(dash.OrgId),
+		OrgRole: hs.scheduleOptions.auth.GetOrgRole(),
+	}
+
+	for _, theme := range hs.scheduleOptions.themes {
+		meta := DashboardThumbnailMeta{
+			DashboardUID: dash.Uid,
+			OrgId:        dash.OrgId,
+			Theme:        theme,
+			Kind:         ThumbnailKindDefault,
+		}
+
+		existing, err := hs.thumbnailRepo.getThumbnail(ctx, meta)
+		if err != nil && !errors.Is(err, dashboards.ErrDashboardThumbnailNotFound) {
+			hs.log.Error("Error when retrieving thumbnail", "dashboardUid", dash.Uid, "err", err)
+			continue
+		}
+		if existing != nil && (existing.State == ThumbnailStateLocked ||
+			existing.DashboardVersion == DashboardVersionForManualThumbnailUpload ||
+			(existing.DashboardVersion == dash.Version && existing.State != ThumbnailStateStale)) {
+			continue
+		}
+
+		if dsUids == nil {
+			if dsUids, err = hs.dsUidsLookup(ctx, dash.Uid, dash.OrgId); err != nil {
+				hs.log.Warn("Error getting datasource uids", "dashboardUid", dash.Uid, "err", err)
+				return
+			}
+		}
+
+		if err := hs.renderThumbnail(ctx, dash, meta, authOpts, dsUids); err != nil {
+			hs.log.Warn("Error rendering thumbnail of saved dashboard", "dashboardUid", dash.Uid, "theme", theme, "err", err)
+			continue
+		}
+		hs.log.Debug("Rendered thumbnail of saved dashboard", "dashboardUid", dash.Uid, "theme", theme, "version", dash.Version)
+	}
+}
+
+func (hs *thumbService) renderThumbnail(ctx context.Context, dash *models.Dashboard, meta DashboardThumbnailMeta, authOpts rendering.AuthOpts, dsUids []string) error {
+	url := models.GetKioskModeDashboardUrl(dash.Uid, dash.Slug, meta.Theme)
+	res, err := hs.renderingService.Render(ctx, rendering.Opts{
+		Width:    320,
+		Height:   240,
+		Path:     strings.TrimPrefix(url, "/"),
+		AuthOpts: authOpts,
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout:                  hs.settings.RenderingTimeout,
+			RequestTimeoutMultiplier: 3,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		Theme:             meta.Theme,
+		ConcurrentLimit:   hs.renderConcurrentLimit,
+		DeviceScaleFactor: -5, // negative numbers will render larger and then scale down.
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if res.FilePath == "" {
+		return errors.New("no image returned by the rendering service")
+	}
+	if strings.Contains(res.FilePath, "public/img") {
+		// rendering service returned a static error image - we should not remove that file
+		return errors.New("rendering service returned an error image")
+	}
+
+	defer func() {
+		if err := os.Remove(res.FilePath); err != nil {
+			hs.log.Error("Failed to remove thumbnail temp file", "dashboardUid", dash.Uid, "err", err)
+		}
+	}()
+
+	_, err = hs.thumbnailRepo.saveFromFile(ctx, res.FilePath, meta, dash.Version, dsUids)
+	return err
+}
@@ -6,25 +6,28 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 )
 
-func newThumbnailRepo(thumbsService DashboardThumbService, search searchV2.SearchService) thumbnailRepo {
+func newThumbnailRepo(thumbsService DashboardThumbService, search searchV2.SearchService, maxThumbnailAge time.Duration) thumbnailRepo {
 	repo := &sqlThumbnailRepository{
-		store:  thumbsService,
-		search: search,
-		log:    log.New("thumbnails_repo"),
+		store:           thumbsService,
+		search:          search,
+		maxThumbnailAge: maxThumbnailAge,
+		log:             log.New("thumbnails_repo"),
 	}
 	return repo
 }
 
 type sqlThumbnailRepository struct {
-	store  DashboardThumbService
-	search searchV2.SearchService
-	log    log.Logger
+	store           DashboardThumbService
+	search          searchV2.SearchService
+	maxThumbnailAge time.Duration
+	log             log.Logger
 }
 
 func (r *sqlThumbnailRepository) saveFromFile(ctx context.Context, filePath string, meta DashboardThumbnailMeta, dashboardVersion int, dsUids []string) (int64, error) {
@@ -88,12 +91,16 @@ func (r *sqlThumbnailRepository) getThumbnail(ctx context.Context, meta Dashboar
 }
 
 func (r *sqlThumbnailRepository) findDashboardsWithStaleThumbnails(ctx context.Context, theme models.Theme, kind ThumbnailKind) ([]*DashboardWithStaleThumbnail, error) {
-	return r.store.FindDashboardsWithStaleThumbnails(ctx, &FindDashboardsWithStaleThumbnailsCommand{
+	cmd := &FindDashboardsWithStaleThumbnailsCommand{
 		IncludeManuallyUploadedThumbnails: false,
 		IncludeThumbnailsWithEmptyDsUIDs:  !r.search.IsDisabled(),
 		Theme:                             theme,
 		Kind:                              kind,
-	})
+	}
+	if r.maxThumbnailAge > 0 {
+		cmd.UpdatedBefore = time.Now().Add(-r.maxThumbnailAge)
+	}
+	return r.store.FindDashboardsWithStaleThumbnails(ctx, cmd)
 }
 
 func (r *sqlThumbnailRepository) doThumbnailsExist(ctx context.Context) (bool, error) {
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/searchV2"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
@@ -33,6 +34,7 @@ type Service interface {
 	Run(ctx context.Context) error
 	Enabled() bool
 	GetImage(c *models.ReqContext)
+	GetThumbnail(c *models.ReqContext)
 	GetDashboardPreviewsSetupSettings(c *models.ReqContext) dashboardPreviewsSetupConfig
 
 	// from dashboard page
@@ -62,6 +64,9 @@ type thumbService struct {
 	dsPermissionsService       permissions.DatasourcePermissionsService
 	licensing                  models.Licensing
 	searchService              searchV2.SearchService
+	// savedDashboards queues the dashboards rendered on save. It is nil if rendering on save is disabled.
+	savedDashboards       chan savedDashboard
+	renderConcurrentLimit int
 }
 
 type crawlerScheduleOptions struct {
@@ -78,13 +83,13 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 	lockService *serverlock.ServerLockService, renderService rendering.Service,
 	gl *live.GrafanaLive, store db.DB, authSetupService CrawlerAuthSetupService,
 	dashboardService dashboards.DashboardService, dashboardThumbsService DashboardThumbService, searchService searchV2.SearchService,
-	dsPermissionsService permissions.DatasourcePermissionsService, licensing models.Licensing) Service {
+	dsPermissionsService permissions.DatasourcePermissionsService, licensing models.Licensing, bus bus.Bus) Service {
 	if !features.IsEnabled(featuremgmt.FlagDashboardPreviews) {
 		return &dummyService{}
 	}
 	logger := log.New("previews_service")
 
-	thumbnailRepo := newThumbnailRepo(dashboardThumbsService, searchService)
+	thumbnailRepo := newThumbnailRepo(dashboardThumbsService, searchService, cfg.DashboardPreviews.MaxThumbnailAge)
 
 	canRunCrawler := true
 
@@ -128,7 +133,13 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 			themes:           []models.Theme{models.ThemeDark, models.ThemeLight},
 			auth:             crawlerAuth,
 		},
-		dashboardService: dashboardService,
+		dashboardService:      dashboardService,
+		renderConcurrentLimit: cfg.RendererConcurrentRequestLimit,
+	}
+
+	if canRunCrawler && cfg.DashboardPreviews.RenderOnSave {
+		t.savedDashboards = make(chan savedDashboard, savedDashboardsQueueSize)
+		bus.AddEventListener(t.handleDashboardSaved)
 	}
 
 	return t
@@ -232,6 +243,37 @@ func (hs *thumbService) GetImage(c *models.ReqContext) {
 		return // already returned value
 	}
 
+	hs.serveThumbnail(c, req)
+}
+
+// GetThumbnail serves the default thumbnail of a dashboard for the search UI, in the theme of the
+// `theme` query parameter. The dark theme is used if it is not set.
+func (hs *thumbService) GetThumbnail(c *models.ReqContext) {
+	theme := models.ThemeDark
+	if c.Query("theme") != "" {
+		var err error
+		if theme, err = models.ParseTheme(c.Query("theme")); err != nil {
+			c.JSON(400, map[string]string{"error": "invalid theme"})
+			return
+		}
+	}
+
+	req := &previewRequest{
+		OrgID: c.OrgID,
+		UID:   web.Params(c.Req)[":uid"],
+		Theme: theme,
+		Kind:  ThumbnailKindDefault,
+	}
+
+	if status := hs.getStatus(c, req.UID, false); status != 200 {
+		c.JSON(status, map[string]string{"error": fmt.Sprintf("code: %d", status)})
+		return
+	}
+
+	hs.serveThumbnail(c, req)
+}
+
+func (hs *thumbService) serveThumbnail(c *models.ReqContext, req *previewRequest) {
 	res, err := hs.thumbnailRepo.getThumbnail(c.Req.Context(), DashboardThumbnailMeta{
 		DashboardUID: req.UID,
 		OrgId:        req.OrgID,
@@ -245,7 +287,7 @@ func (hs *thumbService) GetImage(c *models.ReqContext) {
 	}
 
 	if err != nil || res == nil {
-		hs.log.Error("Error when retrieving thumbnail", "dashboardUid", req.UID, "err", err.Error())
+		hs.log.Error("Error when retrieving thumbnail", "dashboardUid", req.UID, "err", err)
 		c.JSON(500, map[string]string{"dashboardUID": req.UID, "error": "unknown"})
 		return
 	}
@@ -543,6 +585,8 @@ func (hs *thumbService) Run(ctx context.Context) error {
 		select {
 		case <-gc.C:
 			go hs.runScheduledCrawl(ctx)
+		case d := <-hs.savedDashboards:
+			hs.renderSavedDashboard(ctx, d)
 		case <-ctx.Done():
 			hs.log.Debug("Grafana is shutting down - stopping dashboard crawler")
 			gc.Stop()
@@ -0,0 +1,172 @@
+package traceannotations
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *TraceAnnotationService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/trace-annotations", func(traceAnnotations routing.RouteRegister) {
+		traceAnnotations.Get("/jobs", routing.Wrap(s.getJobsHandler))
+		traceAnnotations.Post("/jobs", middleware.ReqEditorRole, routing.Wrap(s.createJobHandler))
+		traceAnnotations.Get("/jobs/:uid", routing.Wrap(s.getJobHandler))
+		traceAnnotations.Put("/jobs/:uid", middleware.ReqEditorRole, routing.Wrap(s.updateJobHandler))
+		traceAnnotations.Delete("/jobs/:uid", middleware.ReqEditorRole, routing.Wrap(s.deleteJobHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /trace-annotations/jobs trace_annotations getTraceAnnotationJobs
+//
+// Get all trace annotation jobs of the current organization.
+//
+// Responses:
+// 200: getTraceAnnotationJobsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *TraceAnnotationService) getJobsHandler(c *models.ReqContext) response.Response {
+	jobs, err := s.GetJobs(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, jobs)
+}
+
+// swagger:route GET /trace-annotations/jobs/{job_uid} trace_annotations getTraceAnnotationJob
+//
+// Get a trace annotation job by uid.
+//
+// Responses:
+// 200: traceAnnotationJobResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *TraceAnnotationService) getJobHandler(c *models.ReqContext) response.Response {
+	job, err := s.GetJob(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route POST /trace-annotations/jobs trace_annotations createTraceAnnotationJob
+//
+// Create a trace annotation job.
+//
+// Requires the editor role and the permission to query the data source of the job. The first run
+// converts the spans of the last interval.
+//
+// Responses:
+// 200: traceAnnotationJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *TraceAnnotationService) createJobHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateJobCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	if resp := annotationjobs.CheckDataSourceAccess(c, s.accessControl, cmd.DataSourceUID); resp != nil {
+		return resp
+	}
+	job, err := s.CreateJob(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route PUT /trace-annotations/jobs/{job_uid} trace_annotations updateTraceAnnotationJob
+//
+// Replace the settings of a trace annotation job.
+//
+// Requires the editor role and the permission to query the data source of the job.
+//
+// Responses:
+// 200: traceAnnotationJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *TraceAnnotationService) updateJobHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateJobCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	if resp := annotationjobs.CheckDataSourceAccess(c, s.accessControl, cmd.DataSourceUID); resp != nil {
+		return resp
+	}
+	job, err := s.UpdateJob(c.Req.Context(), web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route DELETE /trace-annotations/jobs/{job_uid} trace_annotations deleteTraceAnnotationJob
+//
+// Delete a trace annotation job.
+//
+// The annotations created by the job are kept. Requires the editor role.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *TraceAnnotationService) deleteJobHandler(c *models.ReqContext) response.Response {
+	if err := s.DeleteJob(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("Trace annotation job deleted")
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		return response.Error(http.StatusNotFound, "Trace annotation job not found", err)
+	case errors.Is(err, ErrJobExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidJob):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process trace annotation job", err)
+}
+
+// swagger:parameters getTraceAnnotationJob updateTraceAnnotationJob deleteTraceAnnotationJob
+type JobUIDParams struct {
+	// in:path
+	// required:true
+	JobUID string `json:"job_uid"`
+}
+
+// swagger:parameters createTraceAnnotationJob updateTraceAnnotationJob
+type CreateOrUpdateJobParams struct {
+	// in:body
+	// required:true
+	Body CreateOrUpdateJobCommand `json:"body"`
+}
+
+// swagger:response getTraceAnnotationJobsResponse
+type GetJobsResponse struct {
+	// in:body
+	Body []*Job `json:"body"`
+}
+
+// swagger:response traceAnnotationJobResponse
+type JobResponse struct {
+	// in:body
+	Body *Job `json:"body"`
+}
@@ -0,0 +1,187 @@
+package traceannotations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+const (
+	DataSourceTypeTempo  = "tempo"
+	DataSourceTypeJaeger = "jaeger"
+
+	// Tag is added to all annotations created from spans.
+	Tag = "trace"
+
+	maxNameLength    = 190
+	maxTags          = 10
+	maxAttributeTags = 5
+	// maxTagValueLength limits the values of attributes added as tags, so that the tags of an
+	// annotation fit the tags column.
+	maxTagValueLength = 50
+	minInterval       = 30 * time.Second
+	maxInterval       = 24 * time.Hour
+	defaultInterval   = "1m"
+	defaultText       = "{{.Service}}: {{.Name}}"
+	maxErrorLength    = 255
+	maxTextLength     = 2000
+)
+
+var (
+	ErrJobNotFound = errors.New("trace annotation job not found")
+	ErrInvalidJob  = errors.New("invalid trace annotation job")
+	ErrJobExists   = errors.New("a trace annotation job with the same uid already exists")
+)
+
+// Job periodically converts the spans selected by a query of a Tempo or Jaeger data source into
+// annotations.
+// swagger:model TraceAnnotationJob
+type Job struct {
+	ID            int64  `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID         int64  `json:"orgId" xorm:"org_id"`
+	UID           string `json:"uid" xorm:"uid"`
+	Name          string `json:"name" xorm:"name"`
+	DataSourceUID string `json:"dataSourceUid" xorm:"data_source_uid"`
+	// How often new spans are converted, e.g. `1m` or `1h`.
+	Interval string      `json:"interval" xorm:"run_interval"`
+	Query    SpanQuery   `json:"query" xorm:"jsonb query"`
+	Mapping  SpanMapping `json:"mapping" xorm:"jsonb mapping"`
+	Enabled  bool        `json:"enabled" xorm:"enabled"`
+	// ConvertedUntil is the end of the period up to which spans were converted.
+	ConvertedUntil time.Time `json:"convertedUntil" xorm:"converted_until"`
+	// LastError is the error of the last run, empty if it succeeded.
+	LastError string    `json:"lastError,omitempty" xorm:"last_error"`
+	Created   time.Time `json:"created" xorm:"created"`
+	Updated   time.Time `json:"updated" xorm:"updated"`
+}
+
+func (j Job) TableName() string {
+	return "trace_annotation_job"
+}
+
+func (j *Job) interval() time.Duration {
+	d, _ := gtime.ParseDuration(j.Interval)
+	return d
+}
+
+func (j *Job) schedule() (int64, time.Duration) {
+	return j.ID, j.interval()
+}
+
+// SpanQuery selects the spans converted into annotations.
+type SpanQuery struct {
+	// TraceQL query of a Tempo data source, e.g. `{ status = error }`. Only the spans matched by
+	// the query are converted.
+	TraceQL string `json:"traceql,omitempty"`
+	// Service of the traces of a Jaeger data source.
+	Service string `json:"service,omitempty"`
+	// Operation of the spans of a Jaeger data source, all operations if empty.
+	Operation string `json:"operation,omitempty"`
+	// Tags all spans of a Jaeger data source have, e.g. `{"error": "true"}`.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// SpanMapping configures the annotations created from spans.
+type SpanMapping struct {
+	// Text is a Go template of the text of annotations, executed with the span. Defaults to
+	// `{{.Service}}: {{.Name}}`.
+	Text string `json:"text"`
+	// Tags added to all annotations, besides `trace`.
+	Tags []string `json:"tags,omitempty"`
+	// TagAttributes are the span attributes added as `key:value` tags.
+	TagAttributes []string `json:"tagAttributes,omitempty"`
+	// DashboardUID of the dashboard the annotations are added to, organization annotations are
+	// created if empty.
+	DashboardUID string `json:"dashboardUid,omitempty"`
+	PanelID      int64  `json:"panelId,omitempty"`
+	// Region annotates the duration of spans rather than their start.
+	Region bool `json:"region,omitempty"`
+}
+
+// Span is a span selected by the query of a job, as passed to the text template of the mapping.
+type Span struct {
+	TraceID  string
+	SpanID   string
+	Name     string
+	Service  string
+	Start    time.Time
+	Duration time.Duration
+	// Attributes of the span. Tempo only returns the attributes selected by the query.
+	Attributes map[string]string
+}
+
+// CreateOrUpdateJobCommand creates a job, or replaces the settings of a job.
+// swagger:model
+type CreateOrUpdateJobCommand struct {
+	OrgID int64 `json:"-"`
+	// UID of a new job, generated if empty.
+	UID           string      `json:"uid"`
+	Name          string      `json:"name"`
+	DataSourceUID string      `json:"dataSourceUid"`
+	Interval      string      `json:"interval"`
+	Query         SpanQuery   `json:"query"`
+	Mapping       SpanMapping `json:"mapping"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+// validate checks the command for a data source of the given type.
+func (cmd *CreateOrUpdateJobCommand) validate(dataSourceType string) error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if cmd.Name == "" || utf8.RuneCountInString(cmd.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be between 1 and %d characters", ErrInvalidJob, maxNameLength)
+	}
+	if len(cmd.UID) > 40 {
+		return fmt.Errorf("%w: uid must be at most 40 characters", ErrInvalidJob)
+	}
+
+	if cmd.Interval == "" {
+		cmd.Interval = defaultInterval
+	}
+	interval, err := gtime.ParseDuration(cmd.Interval)
+	if err != nil || interval < minInterval || interval > maxInterval {
+		return fmt.Errorf("%w: interval must be between %s and %s", ErrInvalidJob, minInterval, maxInterval)
+	}
+
+	switch dataSourceType {
+	case DataSourceTypeTempo:
+		cmd.Query = SpanQuery{TraceQL: strings.TrimSpace(cmd.Query.TraceQL)}
+		if cmd.Query.TraceQL == "" {
+			return fmt.Errorf("%w: the query of a Tempo data source needs a TraceQL query", ErrInvalidJob)
+		}
+	case DataSourceTypeJaeger:
+		cmd.Query.TraceQL = ""
+		cmd.Query.Service = strings.TrimSpace(cmd.Query.Service)
+		if cmd.Query.Service == "" {
+			return fmt.Errorf("%w: the query of a Jaeger data source needs a service", ErrInvalidJob)
+		}
+	default:
+		return fmt.Errorf("%w: the data source must be a Tempo or Jaeger data source", ErrInvalidJob)
+	}
+
+	return cmd.Mapping.validate()
+}
+
+func (m *SpanMapping) validate() error {
+	if strings.TrimSpace(m.Text) == "" {
+		m.Text = defaultText
+	}
+	if _, err := template.New("text").Parse(m.Text); err != nil {
+		return fmt.Errorf("%w: invalid text template: %s", ErrInvalidJob, err)
+	}
+	if len(m.Tags) > maxTags {
+		return fmt.Errorf("%w: a mapping has at most %d tags", ErrInvalidJob, maxTags)
+	}
+	if len(m.TagAttributes) > maxAttributeTags {
+		return fmt.Errorf("%w: at most %d attributes are added as tags", ErrInvalidJob, maxAttributeTags)
+	}
+	if m.PanelID != 0 && m.DashboardUID == "" {
+		return fmt.Errorf("%w: the panel of annotations needs a dashboard", ErrInvalidJob)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package traceannotations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// spansPerSpanSet is the number of matched spans Tempo returns per trace.
+	spansPerSpanSet = 100
+)
+
+// fetchTempoSpans returns the spans matched by the TraceQL query of a job in the traces of a period.
+// At most limit traces are searched.
+func fetchTempoSpans(ctx context.Context, client *http.Client, baseURL string, query SpanQuery, from, to time.Time, limit int) ([]Span, error) {
+	params := url.Values{}
+	params.Set("q", query.TraceQL)
+	params.Set("start", strconv.FormatInt(from.Unix(), 10))
+	// the period is in seconds, so it's extended to include the last second
+	params.Set("end", strconv.FormatInt(to.Add(time.Second-1).Unix(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("spss", strconv.Itoa(spansPerSpanSet))
+
+	var resp struct {
+		Traces []struct {
+			TraceID         string         `json:"traceID"`
+			RootServiceName string         `json:"rootServiceName"`
+			SpanSet         *tempoSpanSet  `json:"spanSet"`
+			SpanSets        []tempoSpanSet `json:"spanSets"`
+		} `json:"traces"`
+	}
+	if err := annotationjobs.GetJSON(ctx, client, strings.TrimSuffix(baseURL, "/")+"/api/search?"+params.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	spans := make([]Span, 0)
+	for _, trace := range resp.Traces {
+		spanSets := trace.SpanSets
+		if trace.SpanSet != nil {
+			spanSets = append(spanSets, *trace.SpanSet)
+		}
+		seen := map[string]bool{}
+		for _, set := range spanSets {
+			for _, s := range set.Spans {
+				if seen[s.SpanID] {
+					continue
+				}
+				seen[s.SpanID] = true
+				span := Span{
+					TraceID:    trace.TraceID,
+					SpanID:     s.SpanID,
+					Name:       s.Name,
+					Service:    trace.RootServiceName,
+					Attributes: make(map[string]string, len(s.Attributes)),
+				}
+				if start, err := strconv.ParseInt(s.StartTimeUnixNano, 10, 64); err == nil {
+					span.Start = time.Unix(0, start)
+				}
+				if duration, err := strconv.ParseInt(s.DurationNanos, 10, 64); err == nil {
+					span.Duration = time.Duration(duration)
+				}
+				for _, attr := range s.Attributes {
+					span.Attributes[attr.Key] = attr.Value.String()
+				}
+				if service, ok := span.Attributes["service.name"]; ok {
+					span.Service = service
+				}
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans, nil
+}
+
+type tempoSpanSet struct {
+	Spans []struct {
+		SpanID            string           `json:"spanID"`
+		Name              string           `json:"name"`
+		StartTimeUnixNano string           `json:"startTimeUnixNano"`
+		DurationNanos     string           `json:"durationNanos"`
+		Attributes        []tempoAttribute `json:"attributes"`
+	} `json:"spans"`
+}
+
+type tempoAttribute struct {
+	Key   string        `json:"key"`
+	Value tempoAnyValue `json:"value"`
+}
+
+// tempoAnyValue is an OpenTelemetry attribute value, of which only one field is set.
+type tempoAnyValue struct {
+	StringValue *string  `json:"stringValue"`
+	IntValue    *string  `json:"intValue"`
+	BoolValue   *bool    `json:"boolValue"`
+	DoubleValue *float64 `json:"doubleValue"`
+}
+
+func (v tempoAnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		return *v.IntValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	}
+	return ""
+}
+
+// fetchJaegerSpans returns the spans of the service of a job in the traces of a period which have
+// the operation and the tags of the query. At most limit traces are searched.
+func fetchJaegerSpans(ctx context.Context, client *http.Client, baseURL string, query SpanQuery, from, to time.Time, limit int) ([]Span, error) {
+	params := url.Values{}
+	params.Set("service", query.Service)
+	if query.Operation != "" {
+		params.Set("operation", query.Operation)
+	}
+	if len(query.Tags) > 0 {
+		tags, err := json.Marshal(query.Tags)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("tags", string(tags))
+	}
+	params.Set("start", strconv.FormatInt(from.UnixMicro(), 10))
+	params.Set("end", strconv.FormatInt(to.UnixMicro(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+
+	var resp struct {
+		Data []struct {
+			TraceID string `json:"traceID"`
+			Spans   []struct {
+				SpanID        string `json:"spanID"`
+				OperationName string `json:"operationName"`
+				// StartTime and Duration are in microseconds.
+				StartTime int64 `json:"startTime"`
+				Duration  int64 `json:"duration"`
+				Tags      []struct {
+					Key   string      `json:"key"`
+					Value interface{} `json:"value"`
+				} `json:"tags"`
+				ProcessID string `json:"processID"`
+			} `json:"spans"`
+			Processes map[string]struct {
+				ServiceName string `json:"serviceName"`
+			} `json:"processes"`
+		} `json:"data"`
+	}
+	if err := annotationjobs.GetJSON(ctx, client, strings.TrimSuffix(baseURL, "/")+"/api/traces?"+params.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	spans := make([]Span, 0)
+	for _, trace := range resp.Data {
+	nextSpan:
+		for _, s := range trace.Spans {
+			// traces are returned with all their spans, including those of other services and
+			// operations or without the tags
+			service := trace.Processes[s.ProcessID].ServiceName
+			if service != query.Service || query.Operation != "" && s.OperationName != query.Operation {
+				continue
+			}
+			attributes := make(map[string]string, len(s.Tags))
+			for _, tag := range s.Tags {
+				attributes[tag.Key] = fmt.Sprint(tag.Value)
+			}
+			for key, value := range query.Tags {
+				if v, ok := attributes[key]; !ok || v != value {
+					continue nextSpan
+				}
+			}
+			spans = append(spans, Span{
+				TraceID:    trace.TraceID,
+				SpanID:     s.SpanID,
+				Name:       s.OperationName,
+				Service:    service,
+				Start:      time.UnixMicro(s.StartTime),
+				Duration:   time.Duration(s.Duration) * time.Microsecond,
+				Attributes: attributes,
+			})
+		}
+	}
+	return spans, nil
+}
@@ -0,0 +1,79 @@
+package traceannotations
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+)
+
+func (s *TraceAnnotationService) insertJob(ctx context.Context, job *Job) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", job.OrgID, job.UID).Exist(&Job{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrJobExists
+		}
+		_, err = sess.Insert(job)
+		return err
+	})
+}
+
+func (s *TraceAnnotationService) updateJob(ctx context.Context, job *Job) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ?", job.OrgID).ID(job.ID).
+			Cols("name", "data_source_uid", "run_interval", "query", "mapping", "enabled", "converted_until", "last_error", "updated").
+			Update(job)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrJobNotFound
+		}
+		return nil
+	})
+}
+
+// updateRun records the outcome of a run of a job.
+func (s *TraceAnnotationService) updateRun(ctx context.Context, job *Job, convertedUntil time.Time, lastError string) error {
+	return annotationjobs.UpdateRun(ctx, s.store, job.OrgID, job.ID, "converted_until", &Job{ConvertedUntil: convertedUntil, LastError: lastError})
+}
+
+func (s *TraceAnnotationService) deleteJob(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Job{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrJobNotFound
+		}
+		return nil
+	})
+}
+
+func (s *TraceAnnotationService) getJob(ctx context.Context, orgID int64, uid string) (*Job, error) {
+	job := &Job{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(job)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrJobNotFound
+		}
+		return nil
+	})
+	return job, err
+}
+
+func (s *TraceAnnotationService) listJobs(ctx context.Context, orgID int64) ([]*Job, error) {
+	jobs := make([]*Job, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&jobs)
+	})
+	return jobs, err
+}
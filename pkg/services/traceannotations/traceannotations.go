@@ -0,0 +1,373 @@
+// Package traceannotations converts spans of Tempo and Jaeger data sources into annotations, so
+// that errors and deployments recorded in traces appear on metric dashboards.
+//
+// Jobs query their data source at an interval for the spans started since the previous run, and
+// create an annotation for each span according to their mapping.
+package traceannotations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationjobs"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	scheduleTick = 10 * time.Second
+	// maxPeriod is the longest period of spans converted by a run. Spans of jobs which did not run
+	// for longer, e.g. because Grafana was stopped, are skipped.
+	maxPeriod = 24 * time.Hour
+)
+
+type settings struct {
+	enabled bool
+	// delay is how long spans are given to be ingested by the data source before they are
+	// converted.
+	delay time.Duration
+	// maxTraces limits the traces searched by a run.
+	maxTraces int
+}
+
+func readSettings(cfg *setting.Cfg) settings {
+	section := cfg.Raw.Section("trace_annotations")
+	s := settings{
+		enabled:   section.Key("enabled").MustBool(false),
+		delay:     time.Minute,
+		maxTraces: section.Key("max_traces_per_run").MustInt(1000),
+	}
+	if d, err := gtime.ParseDuration(section.Key("delay").MustString("1m")); err == nil && d >= 0 {
+		s.delay = d
+	}
+	if s.maxTraces < 1 {
+		s.maxTraces = 1
+	}
+	return s
+}
+
+type Service interface {
+	GetJobs(ctx context.Context, orgID int64) ([]*Job, error)
+	GetJob(ctx context.Context, orgID int64, uid string) (*Job, error)
+	CreateJob(ctx context.Context, cmd *CreateOrUpdateJobCommand) (*Job, error)
+	// UpdateJob replaces the settings of a job. Spans are converted from the end of the previous
+	// run, unless the data source of the job changes.
+	UpdateJob(ctx context.Context, uid string, cmd *CreateOrUpdateJobCommand) (*Job, error)
+	// DeleteJob deletes a job, the annotations it created are kept.
+	DeleteJob(ctx context.Context, orgID int64, uid string) error
+}
+
+type TraceAnnotationService struct {
+	settings           settings
+	store              db.DB
+	dataSourceService  datasources.DataSourceService
+	httpClientProvider httpclient.Provider
+	annotationsRepo    annotations.Repository
+	leaderElection     leaderelection.Service
+	routeRegister      routing.RouteRegister
+	accessControl      accesscontrol.AccessControl
+	log                log.Logger
+	now                func() time.Time
+	scheduler          *annotationjobs.Scheduler
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, dataSourceService datasources.DataSourceService,
+	httpClientProvider httpclient.Provider, annotationsRepo annotations.Repository, leaderElection leaderelection.Service,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *TraceAnnotationService {
+	s := &TraceAnnotationService{
+		settings:           readSettings(cfg),
+		store:              sqlStore,
+		dataSourceService:  dataSourceService,
+		httpClientProvider: httpClientProvider,
+		annotationsRepo:    annotationsRepo,
+		leaderElection:     leaderElection,
+		routeRegister:      routeRegister,
+		accessControl:      accessControl,
+		log:                log.New("trace-annotations"),
+		now:                time.Now,
+		scheduler:          annotationjobs.NewScheduler(),
+	}
+	if s.settings.enabled {
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+func (s *TraceAnnotationService) IsDisabled() bool {
+	return !s.settings.enabled
+}
+
+// Run executes the jobs at their interval on the node elected as leader.
+func (s *TraceAnnotationService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !s.leaderElection.IsLeader(ctx, leaderelection.LeaseTraceAnnotations) {
+				continue
+			}
+			jobs, err := s.dueJobs(ctx)
+			if err != nil {
+				s.log.Error("Failed to get the trace annotation jobs", "error", err)
+				continue
+			}
+			for _, job := range jobs {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				s.execute(ctx, job)
+			}
+		}
+	}
+}
+
+// dueJobs returns the enabled jobs which did not run within their interval, and marks them as run.
+func (s *TraceAnnotationService) dueJobs(ctx context.Context) ([]*Job, error) {
+	jobs, err := annotationjobs.ListEnabledJobs[Job](ctx, s.store)
+	if err != nil {
+		return nil, err
+	}
+	return annotationjobs.DueJobs(s.scheduler, s.now(), jobs, (*Job).schedule), nil
+}
+
+// execute converts the spans started since the previous run of a job, and records the outcome.
+func (s *TraceAnnotationService) execute(ctx context.Context, job *Job) {
+	to := s.now().Add(-s.settings.delay)
+	from := job.ConvertedUntil
+	if from.IsZero() {
+		from = to.Add(-job.interval())
+	}
+	if to.Sub(from) > maxPeriod {
+		s.log.Warn("Skipping spans of a trace annotation job which did not run for too long", "org", job.OrgID, "uid", job.UID, "from", from)
+		from = to.Add(-maxPeriod)
+	}
+	if !to.After(from) {
+		return
+	}
+
+	count, err := s.convert(ctx, job, from, to)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		s.log.Warn("Failed to convert spans into annotations", "org", job.OrgID, "uid", job.UID, "error", err)
+		err = s.updateRun(ctx, job, job.ConvertedUntil, annotationjobs.Truncate(err.Error(), maxErrorLength))
+	} else {
+		s.log.Debug("Converted spans into annotations", "org", job.OrgID, "uid", job.UID, "count", count)
+		err = s.updateRun(ctx, job, to, "")
+	}
+	if err != nil {
+		s.log.Error("Failed to update trace annotation job", "org", job.OrgID, "uid", job.UID, "error", err)
+	}
+}
+
+// convert creates the annotations of the spans of a job started in the period [from, to).
+func (s *TraceAnnotationService) convert(ctx context.Context, job *Job, from, to time.Time) (int, error) {
+	ds, err := s.getDataSource(ctx, job.OrgID, job.DataSourceUID)
+	if err != nil {
+		return 0, err
+	}
+	var dashboardID int64
+	if job.Mapping.DashboardUID != "" {
+		if dashboardID, err = annotationjobs.GetDashboardID(ctx, s.store, job.OrgID, job.Mapping.DashboardUID); err != nil {
+			return 0, err
+		}
+	}
+	spans, err := s.fetchSpans(ctx, ds, job.Query, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	text, err := template.New("text").Parse(job.Mapping.Text)
+	if err != nil {
+		return 0, err
+	}
+	items := make([]annotations.Item, 0, len(spans))
+	for _, span := range spans {
+		// traces overlapping the period are returned, their spans are converted by the run of the
+		// period they started in
+		if span.Start.Before(from) || !span.Start.Before(to) {
+			continue
+		}
+		items = append(items, newAnnotation(job, span, text, dashboardID))
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+	return len(items), s.annotationsRepo.SaveMany(ctx, items)
+}
+
+func (s *TraceAnnotationService) getDataSource(ctx context.Context, orgID int64, uid string) (*datasources.DataSource, error) {
+	query := &datasources.GetDataSourceQuery{OrgId: orgID, Uid: uid}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return nil, fmt.Errorf("data source %s not found", uid)
+		}
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (s *TraceAnnotationService) fetchSpans(ctx context.Context, ds *datasources.DataSource, query SpanQuery, from, to time.Time) ([]Span, error) {
+	transport, err := s.dataSourceService.GetHTTPTransport(ctx, ds, s.httpClientProvider)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: transport, Timeout: requestTimeout}
+	var spans []Span
+	switch ds.Type {
+	case DataSourceTypeTempo:
+		spans, err = fetchTempoSpans(ctx, client, ds.Url, query, from, to, s.settings.maxTraces)
+	case DataSourceTypeJaeger:
+		spans, err = fetchJaegerSpans(ctx, client, ds.Url, query, from, to, s.settings.maxTraces)
+	default:
+		return nil, fmt.Errorf("data source %s is not a Tempo or Jaeger data source", ds.Uid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+	return spans, nil
+}
+
+// newAnnotation returns the annotation of a span, linked to the span with its data.
+func newAnnotation(job *Job, span Span, text *template.Template, dashboardID int64) annotations.Item {
+	item := annotations.Item{
+		OrgId:       job.OrgID,
+		DashboardId: dashboardID,
+		PanelId:     job.Mapping.PanelID,
+		Epoch:       span.Start.UnixMilli(),
+		EpochEnd:    span.Start.UnixMilli(),
+		Text:        renderText(text, span),
+		Tags:        append([]string{Tag}, job.Mapping.Tags...),
+		Data: simplejson.NewFromAny(map[string]interface{}{
+			"traceId":       span.TraceID,
+			"spanId":        span.SpanID,
+			"datasourceUid": job.DataSourceUID,
+			"jobUid":        job.UID,
+		}),
+	}
+	if job.Mapping.Region {
+		item.EpochEnd = span.Start.Add(span.Duration).UnixMilli()
+	}
+	for _, key := range job.Mapping.TagAttributes {
+		if value, ok := span.Attributes[key]; ok && value != "" {
+			item.Tags = append(item.Tags, key+":"+annotationjobs.Truncate(value, maxTagValueLength))
+		}
+	}
+	return item
+}
+
+// renderText executes the text template of a mapping, falling back to the service and name of the
+// span if it fails.
+func renderText(text *template.Template, span Span) string {
+	var buf bytes.Buffer
+	if err := text.Execute(&buf, span); err != nil || buf.Len() == 0 {
+		return fmt.Sprintf("%s: %s", span.Service, span.Name)
+	}
+	return annotationjobs.Truncate(buf.String(), maxTextLength)
+}
+
+func (s *TraceAnnotationService) GetJobs(ctx context.Context, orgID int64) ([]*Job, error) {
+	return s.listJobs(ctx, orgID)
+}
+
+func (s *TraceAnnotationService) GetJob(ctx context.Context, orgID int64, uid string) (*Job, error) {
+	return s.getJob(ctx, orgID, uid)
+}
+
+// validate checks a command against the type of its data source and the dashboard of its mapping.
+func (s *TraceAnnotationService) validate(ctx context.Context, cmd *CreateOrUpdateJobCommand) error {
+	ds, err := s.getDataSource(ctx, cmd.OrgID, cmd.DataSourceUID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidJob, err)
+	}
+	if err := cmd.validate(ds.Type); err != nil {
+		return err
+	}
+	if cmd.Mapping.DashboardUID != "" {
+		if _, err := annotationjobs.GetDashboardID(ctx, s.store, cmd.OrgID, cmd.Mapping.DashboardUID); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidJob, err)
+		}
+	}
+	return nil
+}
+
+func (s *TraceAnnotationService) CreateJob(ctx context.Context, cmd *CreateOrUpdateJobCommand) (*Job, error) {
+	if err := s.validate(ctx, cmd); err != nil {
+		return nil, err
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+
+	now := s.now()
+	job := &Job{
+		OrgID:   cmd.OrgID,
+		UID:     cmd.UID,
+		Created: now,
+	}
+	applyCommand(job, cmd, now)
+	if err := s.insertJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *TraceAnnotationService) UpdateJob(ctx context.Context, uid string, cmd *CreateOrUpdateJobCommand) (*Job, error) {
+	cmd.UID = ""
+	if err := s.validate(ctx, cmd); err != nil {
+		return nil, err
+	}
+	job, err := s.getJob(ctx, cmd.OrgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.DataSourceUID != cmd.DataSourceUID {
+		job.ConvertedUntil = time.Time{}
+	}
+	applyCommand(job, cmd, s.now())
+	if err := s.updateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func applyCommand(job *Job, cmd *CreateOrUpdateJobCommand, now time.Time) {
+	job.Name = cmd.Name
+	job.DataSourceUID = cmd.DataSourceUID
+	job.Interval = cmd.Interval
+	job.Query = cmd.Query
+	job.Mapping = cmd.Mapping
+	job.Enabled = cmd.Enabled == nil || *cmd.Enabled
+	job.LastError = ""
+	job.Updated = now
+}
+
+func (s *TraceAnnotationService) DeleteJob(ctx context.Context, orgID int64, uid string) error {
+	return s.deleteJob(ctx, orgID, uid)
+}
@@ -0,0 +1,248 @@
+package traceannotations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"text/template"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCreateOrUpdateJobCommandValidate(t *testing.T) {
+	valid := func() *CreateOrUpdateJobCommand {
+		return &CreateOrUpdateJobCommand{Name: " Errors ", DataSourceUID: "tempo", Query: SpanQuery{TraceQL: "{ status = error }", Service: "api"}}
+	}
+
+	cmd := valid()
+	require.NoError(t, cmd.validate(DataSourceTypeTempo))
+	assert.Equal(t, "Errors", cmd.Name)
+	assert.Equal(t, defaultInterval, cmd.Interval)
+	assert.Equal(t, SpanQuery{TraceQL: "{ status = error }"}, cmd.Query)
+	assert.Equal(t, defaultText, cmd.Mapping.Text)
+
+	cmd = &CreateOrUpdateJobCommand{Name: "Deployments", Query: SpanQuery{TraceQL: "{}", Service: "api", Tags: map[string]string{"deployment": "true"}}}
+	require.NoError(t, cmd.validate(DataSourceTypeJaeger))
+	assert.Equal(t, SpanQuery{Service: "api", Tags: map[string]string{"deployment": "true"}}, cmd.Query)
+
+	for name, modify := range map[string]func(*CreateOrUpdateJobCommand){
+		"empty name":              func(c *CreateOrUpdateJobCommand) { c.Name = " " },
+		"uid longer than 40ch":    func(c *CreateOrUpdateJobCommand) { c.UID = string(make([]byte, 41)) },
+		"interval too short":      func(c *CreateOrUpdateJobCommand) { c.Interval = "10s" },
+		"interval too long":       func(c *CreateOrUpdateJobCommand) { c.Interval = "2d" },
+		"empty traceql":           func(c *CreateOrUpdateJobCommand) { c.Query.TraceQL = " " },
+		"invalid text template":   func(c *CreateOrUpdateJobCommand) { c.Mapping.Text = "{{.Name" },
+		"too many tags":           func(c *CreateOrUpdateJobCommand) { c.Mapping.Tags = make([]string, maxTags+1) },
+		"too many attributes":     func(c *CreateOrUpdateJobCommand) { c.Mapping.TagAttributes = make([]string, maxAttributeTags+1) },
+		"panel without dashboard": func(c *CreateOrUpdateJobCommand) { c.Mapping.PanelID = 2 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := valid()
+			modify(cmd)
+			assert.ErrorIs(t, cmd.validate(DataSourceTypeTempo), ErrInvalidJob)
+		})
+	}
+
+	assert.ErrorIs(t, valid().validate("prometheus"), ErrInvalidJob)
+	assert.ErrorIs(t, (&CreateOrUpdateJobCommand{Name: "Errors"}).validate(DataSourceTypeJaeger), ErrInvalidJob)
+}
+
+func TestFetchSpans(t *testing.T) {
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		switch r.URL.Path {
+		case "/api/search":
+			_, _ = w.Write([]byte(`{"traces": [{
+				"traceID": "t1",
+				"rootServiceName": "frontend",
+				"spanSets": [{"spans": [
+					{"spanID": "s1", "name": "GET /api", "startTimeUnixNano": "1667304000000000000", "durationNanos": "2000000",
+						"attributes": [{"key": "service.name", "value": {"stringValue": "api"}}, {"key": "http.status_code", "value": {"intValue": "500"}}]},
+					{"spanID": "s2", "name": "SELECT", "startTimeUnixNano": "1667304001000000000", "durationNanos": "1000000"}
+				]}],
+				"spanSet": {"spans": [{"spanID": "s2", "name": "SELECT", "startTimeUnixNano": "1667304001000000000", "durationNanos": "1000000"}]}
+			}]}`))
+		case "/api/traces":
+			_, _ = w.Write([]byte(`{"data": [{
+				"traceID": "t2",
+				"spans": [
+					{"spanID": "s1", "operationName": "deploy", "startTime": 1667304000000000, "duration": 5000000, "processID": "p1",
+						"tags": [{"key": "deployment", "type": "bool", "value": true}, {"key": "version", "type": "string", "value": "1.2.3"}]},
+					{"spanID": "s2", "operationName": "deploy", "startTime": 1667304001000000, "duration": 1000, "processID": "p1",
+						"tags": [{"key": "deployment", "type": "bool", "value": false}]},
+					{"spanID": "s3", "operationName": "deploy", "startTime": 1667304002000000, "duration": 1000, "processID": "p2",
+						"tags": [{"key": "deployment", "type": "bool", "value": true}]}
+				],
+				"processes": {"p1": {"serviceName": "api"}, "p2": {"serviceName": "worker"}}
+			}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("404 page not found"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	t.Run("tempo", func(t *testing.T) {
+		spans, err := fetchTempoSpans(ctx, server.Client(), server.URL, SpanQuery{TraceQL: "{ status = error }"}, start, start.Add(time.Minute), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"{ status = error }"}, query["q"])
+		assert.Equal(t, []string{"1667304000"}, query["start"])
+		assert.Equal(t, []string{"1667304060"}, query["end"])
+		require.Len(t, spans, 2)
+		assert.True(t, start.Equal(spans[0].Start))
+		spans[0].Start = start
+		assert.Equal(t, Span{TraceID: "t1", SpanID: "s1", Name: "GET /api", Service: "api", Start: start, Duration: 2 * time.Millisecond,
+			Attributes: map[string]string{"service.name": "api", "http.status_code": "500"}}, spans[0])
+		assert.Equal(t, "frontend", spans[1].Service)
+	})
+
+	t.Run("jaeger", func(t *testing.T) {
+		spans, err := fetchJaegerSpans(ctx, server.Client(), server.URL, SpanQuery{Service: "api", Operation: "deploy",
+			Tags: map[string]string{"deployment": "true"}}, start, start.Add(time.Minute), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"deployment":"true"}`}, query["tags"])
+		assert.Equal(t, []string{"1667304000000000"}, query["start"])
+		require.Len(t, spans, 1)
+		assert.True(t, start.Equal(spans[0].Start))
+		spans[0].Start = start
+		assert.Equal(t, Span{TraceID: "t2", SpanID: "s1", Name: "deploy", Service: "api", Start: start, Duration: 5 * time.Second,
+			Attributes: map[string]string{"deployment": "true", "version": "1.2.3"}}, spans[0])
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		_, err := fetchTempoSpans(ctx, server.Client(), server.URL+"/missing", SpanQuery{TraceQL: "{}"}, start, start.Add(time.Minute), 10)
+		assert.EqualError(t, err, "the data source responded with status 404: 404 page not found")
+	})
+}
+
+func TestNewAnnotation(t *testing.T) {
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	span := Span{TraceID: "t1", SpanID: "s1", Name: "deploy", Service: "api", Start: start, Duration: time.Minute,
+		Attributes: map[string]string{"version": "1.2.3", "empty": ""}}
+	job := &Job{OrgID: 1, UID: "deployments", DataSourceUID: "jaeger", Mapping: SpanMapping{
+		Text: `Deployed {{.Service}} {{index .Attributes "version"}}`, Tags: []string{"deployment"},
+		TagAttributes: []string{"version", "empty", "missing"}, Region: true,
+	}}
+
+	item := newAnnotation(job, span, template.Must(template.New("text").Parse(job.Mapping.Text)), 3)
+	assert.Equal(t, "Deployed api 1.2.3", item.Text)
+	assert.Equal(t, []string{Tag, "deployment", "version:1.2.3"}, item.Tags)
+	assert.Equal(t, int64(3), item.DashboardId)
+	assert.Equal(t, start.UnixMilli(), item.Epoch)
+	assert.Equal(t, start.Add(time.Minute).UnixMilli(), item.EpochEnd)
+	assert.Equal(t, "s1", item.Data.Get("spanId").MustString())
+
+	item = newAnnotation(job, span, template.Must(template.New("text").Parse("{{.Missing}}")), 0)
+	assert.Equal(t, "api: deploy", item.Text)
+}
+
+func TestIntegrationTraceAnnotations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the same spans are returned for all periods
+		spans := make([]map[string]interface{}, 0)
+		for i, offset := range []time.Duration{-90 * time.Second, -80 * time.Second, -30 * time.Second} {
+			spans = append(spans, map[string]interface{}{
+				"spanID":            string(rune('a' + i)),
+				"name":              "checkout",
+				"startTimeUnixNano": strconv.FormatInt(start.Add(offset).UnixNano(), 10),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"traces": []interface{}{map[string]interface{}{
+			"traceID": "t1", "rootServiceName": "shop", "spanSets": []interface{}{map[string]interface{}{"spans": spans}},
+		}}})
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	repo := annotationstest.NewFakeAnnotationsRepo()
+	dataSources := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{OrgId: 1, Uid: "tempo", Type: DataSourceTypeTempo, Url: server.URL},
+		{OrgId: 1, Uid: "prom", Type: "prometheus"},
+	}}
+	s := ProvideService(cfg, sqlStore, dataSources, httpclient.NewProvider(sdkhttpclient.ProviderOptions{}), repo,
+		leaderelection.ProvideService(cfg, sqlStore, routing.NewRouteRegister()), routing.NewRouteRegister(), actest.FakeAccessControl{})
+	s.now = func() time.Time { return now }
+
+	job, err := s.CreateJob(ctx, &CreateOrUpdateJobCommand{OrgID: 1, Name: "Errors", DataSourceUID: "tempo", Query: SpanQuery{TraceQL: "{ status = error }"}})
+	require.NoError(t, err)
+
+	_, err = s.CreateJob(ctx, &CreateOrUpdateJobCommand{OrgID: 1, UID: job.UID, Name: "Errors", DataSourceUID: "tempo", Query: SpanQuery{TraceQL: "{}"}})
+	assert.ErrorIs(t, err, ErrJobExists)
+	_, err = s.CreateJob(ctx, &CreateOrUpdateJobCommand{OrgID: 1, Name: "Errors", DataSourceUID: "prom", Query: SpanQuery{TraceQL: "{}"}})
+	assert.ErrorIs(t, err, ErrInvalidJob)
+	_, err = s.CreateJob(ctx, &CreateOrUpdateJobCommand{OrgID: 1, Name: "Errors", DataSourceUID: "tempo", Query: SpanQuery{TraceQL: "{}"},
+		Mapping: SpanMapping{DashboardUID: "missing"}})
+	assert.ErrorIs(t, err, ErrInvalidJob)
+
+	t.Run("first run converts the spans of the last interval", func(t *testing.T) {
+		jobs, err := s.dueJobs(ctx)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		s.execute(ctx, jobs[0])
+
+		// the period is [now - 2m, now - 1m) with the default delay of a minute
+		assert.Equal(t, 2, repo.Len())
+		job, err = s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		assert.True(t, now.Add(-time.Minute).Equal(job.ConvertedUntil), job.ConvertedUntil)
+		assert.Empty(t, job.LastError)
+
+		jobs, err = s.dueJobs(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+
+	t.Run("next run converts the spans since the previous run", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		s.execute(ctx, job)
+		assert.Equal(t, 3, repo.Len())
+	})
+
+	t.Run("failed run is recorded", func(t *testing.T) {
+		dataSources.DataSources[0].Url = server.URL + "/missing/%"
+		now = now.Add(time.Minute)
+		job, err = s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		s.execute(ctx, job)
+
+		failed, err := s.GetJob(ctx, 1, job.UID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, failed.LastError)
+		assert.True(t, job.ConvertedUntil.Equal(failed.ConvertedUntil))
+	})
+
+	t.Run("deleted job", func(t *testing.T) {
+		require.NoError(t, s.DeleteJob(ctx, 1, job.UID))
+		assert.ErrorIs(t, s.DeleteJob(ctx, 1, job.UID), ErrJobNotFound)
+		jobs, err := s.GetJobs(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/models/roletype"
 )
 
@@ -110,6 +111,10 @@ type SearchUsersQuery struct {
 	Limit        int
 	AuthModule   string
 	Filters      []Filter
+	// Sort is the order of the users by login, email or name, by login and email if not set.
+	Sort pagination.Sort
+	// After returns the users after the cursor instead of a page.
+	After *pagination.Cursor
 
 	IsDisabled *bool
 }
@@ -119,6 +124,7 @@ type SearchUserQueryResult struct {
 	Users      []*UserSearchHitDTO `json:"users"`
 	Page       int                 `json:"page"`
 	PerPage    int                 `json:"perPage"`
+	NextCursor string              `json:"nextCursor,omitempty"`
 }
 
 type UserSearchHitDTO struct {
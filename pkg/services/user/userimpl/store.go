@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -534,6 +535,12 @@ func (ss *sqlStore) Disable(ctx context.Context, cmd *user.DisableUserCommand) e
 	})
 }
 
+var userSortColumns = map[string]string{
+	"login": "u.login",
+	"email": "u.email",
+	"name":  "u.name",
+}
+
 func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*user.SearchUserQueryResult, error) {
 	result := user.SearchUserQueryResult{
 		Users: make([]*user.UserSearchHitDTO, 0),
@@ -601,13 +608,29 @@ func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*
 			}
 		}
 
+		if query.Sort.Field == "" {
+			sess.Asc("u.login", "u.email")
+		} else {
+			column, ok := userSortColumns[query.Sort.Field]
+			if !ok {
+				return pagination.ErrInvalidSort
+			}
+			sess.OrderBy(pagination.OrderBy(query.Sort, column, "u.id"))
+			if query.After != nil {
+				where, params := pagination.Where(query.Sort, query.After, column, "u.id")
+				sess.Where(where, params...)
+			}
+		}
+
 		if query.Limit > 0 {
 			offset := query.Limit * (query.Page - 1)
+			if query.After != nil {
+				offset = 0
+			}
 			sess.Limit(query.Limit, offset)
 		}
 
 		sess.Cols("u.id", "u.email", "u.name", "u.login", "u.is_admin", "u.is_disabled", "u.last_seen_at", "user_auth.auth_module")
-		sess.Asc("u.login", "u.email")
 		if err := sess.Find(&result.Users); err != nil {
 			return err
 		}
@@ -9,9 +9,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/pagination"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
 	"github.com/grafana/grafana/pkg/services/quota/quotaimpl"
@@ -27,8 +29,8 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 	}
 
 	ss := db.InitTestDB(t)
-	quotaService := quotaimpl.ProvideService(ss, ss.Cfg)
-	orgService, err := orgimpl.ProvideService(ss, ss.Cfg, quotaService)
+	quotaService := quotaimpl.ProvideService(ss, ss.Cfg, setting.ProvideProvider(ss.Cfg))
+	orgService, err := orgimpl.ProvideService(ss, ss.Cfg, quotaService, actest.FakeService{})
 	require.NoError(t, err)
 	userStore := ProvideStore(ss, setting.NewCfg())
 	usr := &user.SignedInUser{
@@ -716,6 +718,26 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		require.Nil(t, err)
 		require.Len(t, queryResult.Users, 1)
 		require.EqualValues(t, queryResult.TotalCount, 1)
+
+		// Return the users after a cursor
+		sort := pagination.Sort{Field: "email", Descending: true}
+		query = user.SearchUsersQuery{Query: "", Limit: 3, Sort: sort, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Len(t, queryResult.Users, 3)
+		require.Equal(t, "user4@test.com", queryResult.Users[0].Email)
+		last := queryResult.Users[2]
+		require.Equal(t, "user2@test.com", last.Email)
+
+		query = user.SearchUsersQuery{Query: "", Limit: 3, Sort: sort, After: &pagination.Cursor{Value: last.Email, ID: last.ID}, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Len(t, queryResult.Users, 2)
+		require.Equal(t, "user1@test.com", queryResult.Users[0].Email)
+		require.Equal(t, "user0@test.com", queryResult.Users[1].Email)
+		require.EqualValues(t, queryResult.TotalCount, 5)
 	})
 }
 
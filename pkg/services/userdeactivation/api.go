@@ -0,0 +1,123 @@
+package userdeactivation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.AccessControl)
+	userIDScope := ac.Scope("global.users", "id", ac.Parameter(":id"))
+
+	s.RouteRegister.Group("/api/admin/users/:id/deactivate", func(entities routing.RouteRegister) {
+		entities.Post("/preview", authorize(middleware.ReqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersDisable, userIDScope)), routing.Wrap(s.previewHandler))
+		entities.Post("/", authorize(middleware.ReqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersDisable, userIDScope)), routing.Wrap(s.deactivateHandler))
+	})
+}
+
+// swagger:route POST /admin/users/{user_id}/deactivate/preview admin_users adminPreviewDeactivateUser
+//
+// Preview the deactivation of a user.
+//
+// Lists the dashboards, library panels, managed permissions and email contact points that would be
+// reassigned to the target user or team, and the permissions that would be skipped, without changing anything.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:disable` and scope `global.users:1` (userIDScope).
+//
+// Responses:
+// 200: deactivateUserResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) previewHandler(c *models.ReqContext) response.Response {
+	cmd, errResp := parseCommand(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := s.Preview(c.Req.Context(), cmd)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /admin/users/{user_id}/deactivate admin_users adminDeactivateUser
+//
+// Deactivate a user.
+//
+// Disables the user, revokes their sessions and reassigns the dashboards, library panels, managed permissions
+// and email contact points they own to the target user or team in a single transaction.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:disable` and scope `global.users:1` (userIDScope).
+//
+// Responses:
+// 200: deactivateUserResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deactivateHandler(c *models.ReqContext) response.Response {
+	cmd, errResp := parseCommand(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := s.Deactivate(c.Req.Context(), cmd)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func parseCommand(c *models.ReqContext) (*DeactivateUserCommand, response.Response) {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return nil, response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	cmd := &DeactivateUserCommand{}
+	if err := web.Bind(c.Req, cmd); err != nil {
+		return nil, response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UserID = userID
+
+	return cmd, nil
+}
+
+func toErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrTargetNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrInvalidTarget), errors.Is(err, ErrTargetIsSameUser), errors.Is(err, ErrExternalUser):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to deactivate user", err)
+}
+
+// swagger:parameters adminPreviewDeactivateUser adminDeactivateUser
+type DeactivateUserParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+	// in:body
+	// required:true
+	Body DeactivateUserCommand `json:"body"`
+}
+
+// swagger:response deactivateUserResponse
+type DeactivateUserResponse struct {
+	// in:body
+	Body Result `json:"body"`
+}
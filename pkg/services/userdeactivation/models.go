@@ -0,0 +1,82 @@
+package userdeactivation
+
+import (
+	"errors"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrTargetNotFound   = errors.New("reassignment target not found")
+	ErrInvalidTarget    = errors.New("exactly one of targetUserId and targetTeamId must be set")
+	ErrTargetIsSameUser = errors.New("resources can not be reassigned to the deactivated user")
+	ErrExternalUser     = errors.New("external users can not be deactivated")
+)
+
+// DeactivateUserCommand disables a user and reassigns the resources they own
+// to either another user or a team.
+// swagger:model
+type DeactivateUserCommand struct {
+	UserID int64 `json:"-"`
+	// ID of the user the resources are reassigned to.
+	TargetUserID int64 `json:"targetUserId"`
+	// ID of the team the resources are reassigned to. Dashboards and library panels keep their creator
+	// when reassigning to a team since they can only be created by users.
+	TargetTeamID int64 `json:"targetTeamId"`
+}
+
+// ResourceRef is a resource that is reassigned on deactivation.
+type ResourceRef struct {
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	UID   string `json:"uid" xorm:"uid"`
+	Name  string `json:"name" xorm:"name"`
+}
+
+// PermissionRef is a managed permission of the deactivated user on a resource.
+type PermissionRef struct {
+	OrgID int64  `json:"orgId"`
+	Scope string `json:"scope"`
+	// Permission is the level of the permission, for example Edit. It is empty if the actions
+	// do not match a level of a resource with managed permissions.
+	Permission string   `json:"permission,omitempty"`
+	Actions    []string `json:"actions"`
+}
+
+// ContactPointRef is an email contact point that notifies the deactivated user.
+type ContactPointRef struct {
+	OrgID int64  `json:"orgId"`
+	Name  string `json:"name"`
+}
+
+// ReportRef is a scheduled report owned by the deactivated user.
+type ReportRef struct {
+	OrgID int64  `json:"orgId"`
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+}
+
+// Result lists the resources that are, or would be, reassigned on deactivation. API keys are
+// not reassigned since they belong to organizations and service accounts rather than users, and
+// neither are alert rules since they have no owner. Access to alert rules follows the permissions
+// on their folders, which are transferred.
+// swagger:model
+type Result struct {
+	UserID          int64             `json:"userId"`
+	TargetUserID    int64             `json:"targetUserId,omitempty"`
+	TargetTeamID    int64             `json:"targetTeamId,omitempty"`
+	Dashboards      []ResourceRef     `json:"dashboards"`
+	LibraryElements []ResourceRef     `json:"libraryElements"`
+	Permissions     []PermissionRef   `json:"permissions"`
+	ContactPoints   []ContactPointRef `json:"contactPoints"`
+	// ScheduledReports are the reports whose owner is changed to the target user.
+	ScheduledReports []ReportRef `json:"scheduledReports"`
+	// Permissions that are not transferred, because they are not on a dashboard, folder or service account,
+	// or the target is not part of the organization.
+	SkippedPermissions []PermissionRef `json:"skippedPermissions,omitempty"`
+	// Contact points that keep notifying the deactivated user, because the user is their only
+	// recipient and the target has no email address.
+	SkippedContactPoints []ContactPointRef `json:"skippedContactPoints,omitempty"`
+	// Scheduled reports that keep the deactivated user as owner, and are no longer delivered, because
+	// reports are rendered with the permissions of a user of their organization, which the target is not.
+	SkippedScheduledReports []ReportRef `json:"skippedScheduledReports,omitempty"`
+	Applied                 bool        `json:"applied"`
+}
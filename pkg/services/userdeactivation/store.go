@@ -0,0 +1,470 @@
+package userdeactivation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type userRow struct {
+	ID    int64  `xorm:"id"`
+	Login string `xorm:"login"`
+	Email string `xorm:"email"`
+}
+
+type teamRow struct {
+	ID    int64  `xorm:"id"`
+	OrgID int64  `xorm:"org_id"`
+	Email string `xorm:"email"`
+}
+
+type reportRow struct {
+	ID    int64  `xorm:"id"`
+	OrgID int64  `xorm:"org_id"`
+	Name  string `xorm:"name"`
+}
+
+type permissionRow struct {
+	OrgID  int64  `xorm:"org_id"`
+	Action string `xorm:"action"`
+	Scope  string `xorm:"scope"`
+}
+
+// permissionTransfer moves the permission of the deactivated user on a resource to the target.
+type permissionTransfer struct {
+	orgID      int64
+	resourceID string
+	permission string
+	service    accesscontrol.PermissionsService
+	// grant is false if the target already has the actions of the permission.
+	grant bool
+}
+
+// plan is the set of changes needed to deactivate a user.
+type plan struct {
+	result      *Result
+	user        userRow
+	targetEmail string
+	targetTeam  *teamRow
+	// targetOrgs are the organizations the target user is a member of, or the organization of the
+	// target team.
+	targetOrgs     map[int64]bool
+	teamMembers    []accesscontrol.TeamMember
+	reports        []int64
+	permissions    []permissionTransfer
+	permissionOrgs map[int64]bool
+	alertConfigs   map[int64]map[string]interface{}
+}
+
+func (s *Service) buildPlan(ctx context.Context, cmd *DeactivateUserCommand) (*plan, error) {
+	if (cmd.TargetUserID == 0) == (cmd.TargetTeamID == 0) {
+		return nil, ErrInvalidTarget
+	}
+	if cmd.TargetUserID == cmd.UserID {
+		return nil, ErrTargetIsSameUser
+	}
+
+	p := &plan{
+		result: &Result{
+			UserID:           cmd.UserID,
+			TargetUserID:     cmd.TargetUserID,
+			TargetTeamID:     cmd.TargetTeamID,
+			Dashboards:       []ResourceRef{},
+			LibraryElements:  []ResourceRef{},
+			Permissions:      []PermissionRef{},
+			ContactPoints:    []ContactPointRef{},
+			ScheduledReports: []ReportRef{},
+		},
+		permissionOrgs: make(map[int64]bool),
+		alertConfigs:   make(map[int64]map[string]interface{}),
+	}
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if err := s.planResources(sess, p, cmd); err != nil {
+			return err
+		}
+		if err := s.planTargetOrgs(sess, p); err != nil {
+			return err
+		}
+		if err := s.planPermissions(sess, p); err != nil {
+			return err
+		}
+		return s.planReports(sess, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.planContactPoints(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (s *Service) planResources(sess *db.Session, p *plan, cmd *DeactivateUserCommand) error {
+	has, err := sess.Table("user").Cols("id", "login", "email").
+		Where("id = ? AND is_service_account = ?", cmd.UserID, s.store.GetDialect().BooleanStr(false)).Get(&p.user)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return ErrUserNotFound
+	}
+
+	external, err := sess.Table("user_auth").Where("user_id = ?", cmd.UserID).Count()
+	if err != nil {
+		return err
+	}
+	if external > 0 {
+		return ErrExternalUser
+	}
+
+	if cmd.TargetUserID != 0 {
+		var target userRow
+		has, err := sess.Table("user").Cols("id", "login", "email").
+			Where("id = ? AND is_service_account = ?", cmd.TargetUserID, s.store.GetDialect().BooleanStr(false)).Get(&target)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrTargetNotFound
+		}
+		p.targetEmail = target.Email
+
+		if err := sess.SQL("SELECT org_id, uid, title AS name FROM dashboard WHERE created_by = ? ORDER BY org_id, title",
+			cmd.UserID).Find(&p.result.Dashboards); err != nil {
+			return err
+		}
+		return sess.SQL("SELECT org_id, uid, name FROM library_element WHERE created_by = ? ORDER BY org_id, name",
+			cmd.UserID).Find(&p.result.LibraryElements)
+	}
+
+	var team teamRow
+	has, err = sess.Table("team").Cols("id", "org_id", "email").Where("id = ?", cmd.TargetTeamID).Get(&team)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return ErrTargetNotFound
+	}
+	p.targetTeam = &team
+	p.targetEmail = team.Email
+
+	userTable := s.store.GetDialect().Quote("user")
+	p.teamMembers = make([]accesscontrol.TeamMember, 0)
+	return sess.Table("team_member").
+		Join("INNER", userTable, userTable+".id = team_member.user_id").
+		Where("team_member.team_id = ?", team.ID).
+		Distinct("team_member.user_id", userTable+".is_service_account").
+		Find(&p.teamMembers)
+}
+
+func (s *Service) planTargetOrgs(sess *db.Session, p *plan) error {
+	p.targetOrgs = make(map[int64]bool)
+	if p.targetTeam != nil {
+		p.targetOrgs[p.targetTeam.OrgID] = true
+		return nil
+	}
+
+	var orgIDs []int64
+	if err := sess.Table("org_user").Cols("org_id").Where("user_id = ?", p.result.TargetUserID).Find(&orgIDs); err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		p.targetOrgs[orgID] = true
+	}
+	return nil
+}
+
+// planPermissions groups the managed permissions of the user by resource. The permissions on
+// dashboards, folders and service accounts are transferred through their managed permissions
+// services, so that their validation and hooks apply, and the others are skipped.
+func (s *Service) planPermissions(sess *db.Session, p *plan) error {
+	var permissions []permissionRow
+	if err := sess.SQL(`SELECT role.org_id, permission.action, permission.scope
+		FROM permission INNER JOIN role ON role.id = permission.role_id
+		WHERE role.name = ? ORDER BY role.org_id, permission.scope, permission.action`,
+		accesscontrol.ManagedUserRoleName(p.user.ID)).Find(&permissions); err != nil {
+		return err
+	}
+
+	targetRole := accesscontrol.ManagedUserRoleName(p.result.TargetUserID)
+	if p.targetTeam != nil {
+		targetRole = accesscontrol.ManagedTeamRoleName(p.targetTeam.ID)
+	}
+
+	var targetPermissions []permissionRow
+	if err := sess.SQL(`SELECT role.org_id, permission.action, permission.scope
+		FROM permission INNER JOIN role ON role.id = permission.role_id
+		WHERE role.name = ?`, targetRole).Find(&targetPermissions); err != nil {
+		return err
+	}
+	targetActions := make(map[string][]string)
+	for _, perm := range targetPermissions {
+		key := fmt.Sprintf("%d|%s", perm.OrgID, perm.Scope)
+		targetActions[key] = append(targetActions[key], perm.Action)
+	}
+
+	for i := 0; i < len(permissions); {
+		ref := PermissionRef{OrgID: permissions[i].OrgID, Scope: permissions[i].Scope}
+		for ; i < len(permissions) && permissions[i].OrgID == ref.OrgID && permissions[i].Scope == ref.Scope; i++ {
+			ref.Actions = append(ref.Actions, permissions[i].Action)
+		}
+
+		resource := s.managedResourceFor(ref.Scope)
+		if resource != nil {
+			ref.Permission = resource.service.MapActions(accesscontrol.ResourcePermission{Actions: ref.Actions})
+		}
+		if ref.Permission == "" || !p.targetOrgs[ref.OrgID] {
+			p.result.SkippedPermissions = append(p.result.SkippedPermissions, ref)
+			continue
+		}
+
+		current := accesscontrol.ResourcePermission{Actions: targetActions[fmt.Sprintf("%d|%s", ref.OrgID, ref.Scope)]}
+		p.permissions = append(p.permissions, permissionTransfer{
+			orgID:      ref.OrgID,
+			resourceID: strings.TrimPrefix(ref.Scope, resource.scopePrefix),
+			permission: ref.Permission,
+			service:    resource.service,
+			grant:      !current.Contains(ref.Actions),
+		})
+		p.permissionOrgs[ref.OrgID] = true
+		p.result.Permissions = append(p.result.Permissions, ref)
+	}
+
+	return nil
+}
+
+// planReports finds the scheduled reports of the user. They are rendered with the permissions of
+// their owner, so they can only be reassigned to a user who is a member of their organization.
+func (s *Service) planReports(sess *db.Session, p *plan) error {
+	var reports []reportRow
+	if err := sess.SQL("SELECT id, org_id, name FROM scheduled_report WHERE user_id = ? ORDER BY org_id, name",
+		p.user.ID).Find(&reports); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		ref := ReportRef{OrgID: r.OrgID, ID: r.ID, Name: r.Name}
+		if p.targetTeam != nil || !p.targetOrgs[r.OrgID] {
+			p.result.SkippedScheduledReports = append(p.result.SkippedScheduledReports, ref)
+			continue
+		}
+		p.reports = append(p.reports, r.ID)
+		p.result.ScheduledReports = append(p.result.ScheduledReports, ref)
+	}
+	return nil
+}
+
+func (s *Service) managedResourceFor(scope string) *managedResource {
+	for i, r := range s.managedResources {
+		if strings.HasPrefix(scope, r.scopePrefix) && len(scope) > len(r.scopePrefix) {
+			return &s.managedResources[i]
+		}
+	}
+	return nil
+}
+
+// planContactPoints finds the email contact points in the latest alertmanager configuration
+// of every organization that notify the deactivated user.
+func (s *Service) planContactPoints(ctx context.Context, p *plan) error {
+	if p.user.Email == "" {
+		return nil
+	}
+
+	configs, err := s.alertmanagerConfigs.GetAllLatestAlertmanagerConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].OrgID < configs[j].OrgID
+	})
+
+	for _, cfg := range configs {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(cfg.AlertmanagerConfiguration), &parsed); err != nil {
+			s.log.Warn("Failed to parse alertmanager configuration", "orgId", cfg.OrgID, "error", err)
+			continue
+		}
+
+		changed := false
+		for _, receiver := range emailReceivers(parsed) {
+			found, replaced := replaceAddress(receiver.settings, p.user.Email, p.targetEmail)
+			if !found {
+				continue
+			}
+			ref := ContactPointRef{OrgID: cfg.OrgID, Name: receiver.name}
+			if !replaced {
+				p.result.SkippedContactPoints = append(p.result.SkippedContactPoints, ref)
+				continue
+			}
+			changed = true
+			p.result.ContactPoints = append(p.result.ContactPoints, ref)
+		}
+
+		if changed {
+			p.alertConfigs[cfg.OrgID] = parsed
+		}
+	}
+
+	return nil
+}
+
+type emailReceiver struct {
+	name     string
+	settings map[string]interface{}
+}
+
+func emailReceivers(config map[string]interface{}) []emailReceiver {
+	amConfig, _ := config["alertmanager_config"].(map[string]interface{})
+	receivers, _ := amConfig["receivers"].([]interface{})
+
+	var result []emailReceiver
+	for _, r := range receivers {
+		receiver, _ := r.(map[string]interface{})
+		integrations, _ := receiver["grafana_managed_receiver_configs"].([]interface{})
+		for _, i := range integrations {
+			integration, _ := i.(map[string]interface{})
+			if integration["type"] != "email" {
+				continue
+			}
+			settings, ok := integration["settings"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := receiver["name"].(string)
+			result = append(result, emailReceiver{name: name, settings: settings})
+		}
+	}
+	return result
+}
+
+// replaceAddress replaces the email address in the addresses setting of an email integration and
+// reports whether the address was found and replaced. When there is no replacement, the address is
+// removed, unless it is the only address of the integration, which is then left unchanged.
+func replaceAddress(settings map[string]interface{}, address, replacement string) (found bool, replaced bool) {
+	addresses, _ := settings["addresses"].(string)
+
+	updated := make([]string, 0)
+	for _, a := range util.SplitEmails(addresses) {
+		a = strings.TrimSpace(a)
+		if strings.EqualFold(a, address) {
+			found = true
+			a = replacement
+		}
+		if a == "" {
+			continue
+		}
+		duplicate := false
+		for _, u := range updated {
+			if strings.EqualFold(u, a) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			updated = append(updated, a)
+		}
+	}
+
+	if !found || len(updated) == 0 {
+		return found, false
+	}
+	settings["addresses"] = strings.Join(updated, ";")
+	return true, true
+}
+
+func (s *Service) applyPlan(ctx context.Context, p *plan) error {
+	now := time.Now()
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if p.targetTeam != nil {
+			return nil
+		}
+		if _, err := sess.Exec("UPDATE dashboard SET created_by = ? WHERE created_by = ?", p.result.TargetUserID, p.user.ID); err != nil {
+			return err
+		}
+		if _, err := sess.Exec("UPDATE library_element SET created_by = ? WHERE created_by = ?", p.result.TargetUserID, p.user.ID); err != nil {
+			return err
+		}
+		for _, id := range p.reports {
+			if _, err := sess.Exec("UPDATE scheduled_report SET user_id = ?, updated = ? WHERE id = ?", p.result.TargetUserID, now, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.transferPermissions(ctx, p); err != nil {
+		return err
+	}
+
+	orgIDs := make([]int64, 0, len(p.alertConfigs))
+	for orgID := range p.alertConfigs {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+	for _, orgID := range orgIDs {
+		raw, err := json.Marshal(p.alertConfigs[orgID])
+		if err != nil {
+			return err
+		}
+		if err := s.alertmanagerConfigs.SaveAlertmanagerConfiguration(ctx, &ngmodels.SaveAlertmanagerConfigurationCmd{
+			AlertmanagerConfiguration: string(raw),
+			ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
+			OrgID:                     orgID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	err = s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("UPDATE "+s.store.GetDialect().Quote("user")+" SET is_disabled = ?, updated = ? WHERE id = ?",
+			s.store.GetDialect().BooleanStr(true), now, p.user.ID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM user_auth_token WHERE user_id = ?", p.user.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	p.result.Applied = true
+	return nil
+}
+
+// transferPermissions grants the permissions of the user to the target and revokes them from the
+// user through the managed permissions services.
+func (s *Service) transferPermissions(ctx context.Context, p *plan) error {
+	for _, t := range p.permissions {
+		if t.grant {
+			var err error
+			if p.targetTeam != nil {
+				_, err = t.service.SetTeamPermission(ctx, t.orgID, p.targetTeam.ID, t.resourceID, t.permission)
+			} else {
+				_, err = t.service.SetUserPermission(ctx, t.orgID, accesscontrol.User{ID: p.result.TargetUserID}, t.resourceID, t.permission)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to grant permission on %s: %w", t.resourceID, err)
+			}
+		}
+
+		if _, err := t.service.SetUserPermission(ctx, t.orgID, accesscontrol.User{ID: p.user.ID}, t.resourceID, ""); err != nil {
+			return fmt.Errorf("failed to revoke permission on %s: %w", t.resourceID, err)
+		}
+	}
+	return nil
+}
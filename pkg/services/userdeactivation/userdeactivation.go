@@ -0,0 +1,123 @@
+package userdeactivation
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// managedResource is a kind of resource whose managed permissions are transferred on deactivation.
+// Team permissions are not transferred, since they make the target a member of the teams.
+type managedResource struct {
+	scopePrefix string
+	service     accesscontrol.PermissionsService
+}
+
+type alertmanagerConfigStore interface {
+	GetAllLatestAlertmanagerConfiguration(ctx context.Context) ([]*ngmodels.AlertConfiguration, error)
+	SaveAlertmanagerConfiguration(ctx context.Context, cmd *ngmodels.SaveAlertmanagerConfigurationCmd) error
+}
+
+type alertmanagerSyncer interface {
+	LoadAndSyncAlertmanagersForOrgs(ctx context.Context) error
+}
+
+func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	acService accesscontrol.Service, dashboardPermissions accesscontrol.DashboardPermissionsService,
+	folderPermissions accesscontrol.FolderPermissionsService,
+	serviceAccountPermissions accesscontrol.ServiceAccountPermissionsService, ng *ngalert.AlertNG) *Service {
+	logger := log.New("user-deactivation")
+	s := &Service{
+		store:         sqlStore,
+		RouteRegister: routeRegister,
+		AccessControl: ac,
+		acService:     acService,
+		managedResources: []managedResource{
+			{scopePrefix: dashboards.ScopeDashboardsPrefix, service: dashboardPermissions},
+			{scopePrefix: dashboards.ScopeFoldersPrefix, service: folderPermissions},
+			{scopePrefix: accesscontrol.Scope("serviceaccounts", "id", ""), service: serviceAccountPermissions},
+		},
+		alertmanagerConfigs: &ngstore.DBstore{SQLStore: sqlStore, Logger: logger},
+		log:                 logger,
+	}
+	if !ng.IsDisabled() && ng.MultiOrgAlertmanager != nil {
+		s.alertmanagers = ng.MultiOrgAlertmanager
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// Service disables users and reassigns the resources they own so that
+// nothing is left orphaned when someone is offboarded.
+type Service struct {
+	store               db.DB
+	RouteRegister       routing.RouteRegister
+	AccessControl       accesscontrol.AccessControl
+	acService           accesscontrol.Service
+	managedResources    []managedResource
+	alertmanagerConfigs alertmanagerConfigStore
+	// alertmanagers is nil if unified alerting is disabled.
+	alertmanagers alertmanagerSyncer
+	log           log.Logger
+}
+
+// Preview returns the resources that would be reassigned when deactivating the user without changing anything.
+func (s *Service) Preview(ctx context.Context, cmd *DeactivateUserCommand) (*Result, error) {
+	p, err := s.buildPlan(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return p.result, nil
+}
+
+// Deactivate disables the user, revokes their sessions and reassigns their resources in a single transaction.
+func (s *Service) Deactivate(ctx context.Context, cmd *DeactivateUserCommand) (*Result, error) {
+	var p *plan
+	err := s.store.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if p, err = s.buildPlan(ctx, cmd); err != nil {
+			return err
+		}
+		return s.applyPlan(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.clearPermissionCaches(p)
+	if len(p.alertConfigs) > 0 && s.alertmanagers != nil {
+		// the other instances load the configurations on their next poll
+		if err := s.alertmanagers.LoadAndSyncAlertmanagersForOrgs(ctx); err != nil {
+			s.log.Warn("Failed to apply alertmanager configurations", "error", err)
+		}
+	}
+
+	result := p.result
+	s.log.Info("Deactivated user", "userId", cmd.UserID, "targetUserId", cmd.TargetUserID, "targetTeamId", cmd.TargetTeamID,
+		"dashboards", len(result.Dashboards), "permissions", len(result.Permissions), "contactPoints", len(result.ContactPoints),
+		"scheduledReports", len(result.ScheduledReports))
+	return result, nil
+}
+
+// clearPermissionCaches clears the cached permissions of the users whose permissions were transferred.
+func (s *Service) clearPermissionCaches(p *plan) {
+	for orgID := range p.permissionOrgs {
+		s.acService.ClearUserPermissionCache(&user.SignedInUser{OrgID: orgID, UserID: p.user.ID})
+		if p.targetTeam == nil {
+			s.acService.ClearUserPermissionCache(&user.SignedInUser{OrgID: orgID, UserID: p.result.TargetUserID})
+		}
+	}
+	for _, m := range p.teamMembers {
+		s.acService.ClearUserPermissionCache(&user.SignedInUser{OrgID: p.targetTeam.OrgID, UserID: m.UserID, IsServiceAccount: m.IsServiceAccount})
+	}
+}
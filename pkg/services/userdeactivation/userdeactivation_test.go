@@ -0,0 +1,237 @@
+package userdeactivation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/ossaccesscontrol"
+	dashdb "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/scheduledreports"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/userimpl"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testAlertmanagerConfig = `{
+	"alertmanager_config": {
+		"route": {"receiver": "oncall"},
+		"receivers": [{
+			"name": "oncall",
+			"grafana_managed_receiver_configs": [{
+				"uid": "abc",
+				"name": "oncall",
+				"type": "email",
+				"settings": {"addresses": "leaver@example.com;team@example.com"}
+			}]
+		}, {
+			"name": "personal",
+			"grafana_managed_receiver_configs": [{
+				"uid": "def",
+				"name": "personal",
+				"type": "email",
+				"settings": {"addresses": "leaver@example.com"}
+			}]
+		}]
+	}
+}`
+
+func TestReplaceAddress(t *testing.T) {
+	settings := map[string]interface{}{"addresses": "Leaver@example.com, other@example.com\nnew@example.com"}
+	found, replaced := replaceAddress(settings, "leaver@example.com", "new@example.com")
+	require.True(t, found)
+	require.True(t, replaced)
+	assert.Equal(t, "new@example.com;other@example.com", settings["addresses"])
+
+	found, _ = replaceAddress(settings, "leaver@example.com", "new@example.com")
+	require.False(t, found)
+
+	settings = map[string]interface{}{"addresses": "leaver@example.com;other@example.com"}
+	found, replaced = replaceAddress(settings, "leaver@example.com", "")
+	require.True(t, found)
+	require.True(t, replaced)
+	assert.Equal(t, "other@example.com", settings["addresses"])
+
+	t.Run("should not remove the only address", func(t *testing.T) {
+		settings := map[string]interface{}{"addresses": "leaver@example.com"}
+		found, replaced := replaceAddress(settings, "leaver@example.com", "")
+		require.True(t, found)
+		require.False(t, replaced)
+		assert.Equal(t, "leaver@example.com", settings["addresses"])
+	})
+}
+
+func TestIntegrationDeactivateUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	leaver, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: "leaver", Email: "leaver@example.com"})
+	require.NoError(t, err)
+	successor, err := sqlStore.CreateUser(ctx, user.CreateUserCommand{Login: "successor", Email: "successor@example.com"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("INSERT INTO dashboard (version, slug, title, data, org_id, created, updated, created_by, uid) VALUES (1, 'dash', 'Dash', '{}', 1, ?, ?, ?, 'dash')",
+			now, now, leaver.ID); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (1, ?, 'Editor', ?, ?)",
+			successor.ID, now, now); err != nil {
+			return err
+		}
+
+		role := accesscontrol.Role{OrgID: 1, Name: accesscontrol.ManagedUserRoleName(leaver.ID), UID: "leaverrole", Created: now, Updated: now}
+		if _, err := sess.Insert(&role); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(&accesscontrol.UserRole{OrgID: 1, RoleID: role.ID, UserID: leaver.ID, Created: now}); err != nil {
+			return err
+		}
+		for _, perm := range []accesscontrol.Permission{
+			{Action: "dashboards:read", Scope: "dashboards:uid:dash"},
+			{Action: "dashboards:write", Scope: "dashboards:uid:dash"},
+			{Action: "dashboards:delete", Scope: "dashboards:uid:dash"},
+			{Action: "datasources:query", Scope: "datasources:uid:ds"},
+		} {
+			perm.RoleID, perm.Created, perm.Updated = role.ID, now, now
+			if _, err := sess.Insert(&perm); err != nil {
+				return err
+			}
+		}
+
+		for _, report := range []scheduledreports.Report{
+			{OrgID: 1, UserID: leaver.ID, Name: "weekly"},
+			{OrgID: 3, UserID: leaver.ID, Name: "other org"},
+		} {
+			report.DashboardUID, report.Format, report.Orientation = "dash", scheduledreports.FormatPDF, scheduledreports.OrientationLandscape
+			report.Recipients, report.Schedule, report.Timezone = "leaver@example.com", "0 8 * * 1", "UTC"
+			report.Created, report.Updated = now, now
+			if _, err := sess.Insert(&report); err != nil {
+				return err
+			}
+		}
+
+		_, err := sess.Insert(&ngmodels.AlertConfiguration{AlertmanagerConfiguration: testAlertmanagerConfig, ConfigurationVersion: "v1", OrgID: 1})
+		return err
+	})
+	require.NoError(t, err)
+
+	ac := accesscontrolmock.New()
+	dashStore, err := dashdb.ProvideDashboardStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, sqlStore.Cfg), quotatest.New(false, nil))
+	require.NoError(t, err)
+	teamSvc := teamimpl.ProvideService(sqlStore, sqlStore.Cfg)
+	userSvc, err := userimpl.ProvideService(sqlStore, nil, sqlStore.Cfg, teamSvc, nil, quotatest.New(false, nil))
+	require.NoError(t, err)
+	dashboardPermissions, err := ossaccesscontrol.ProvideDashboardPermissions(setting.NewCfg(), routing.NewRouteRegister(), sqlStore, ac,
+		licensingtest.NewFakeLicensing(), dashStore, ac, teamSvc, userSvc)
+	require.NoError(t, err)
+
+	s := ProvideService(sqlStore, routing.NewRouteRegister(), ac, ac, dashboardPermissions,
+		accesscontrolmock.NewMockedPermissionsService(), accesscontrolmock.NewMockedPermissionsService(), &ngalert.AlertNG{})
+	cmd := &DeactivateUserCommand{UserID: leaver.ID, TargetUserID: successor.ID}
+
+	team, err := teamSvc.CreateTeam("no email", "", 1)
+	require.NoError(t, err)
+
+	t.Run("should reject invalid targets", func(t *testing.T) {
+		_, err := s.Preview(ctx, &DeactivateUserCommand{UserID: leaver.ID})
+		require.ErrorIs(t, err, ErrInvalidTarget)
+
+		_, err = s.Preview(ctx, &DeactivateUserCommand{UserID: leaver.ID, TargetUserID: leaver.ID})
+		require.ErrorIs(t, err, ErrTargetIsSameUser)
+	})
+
+	t.Run("should preview without changes", func(t *testing.T) {
+		result, err := s.Preview(ctx, cmd)
+		require.NoError(t, err)
+		assert.False(t, result.Applied)
+		require.Len(t, result.Dashboards, 1)
+		assert.Equal(t, "dash", result.Dashboards[0].UID)
+		require.Len(t, result.Permissions, 1)
+		assert.Equal(t, "dashboards:uid:dash", result.Permissions[0].Scope)
+		assert.Equal(t, "Edit", result.Permissions[0].Permission)
+		require.Len(t, result.SkippedPermissions, 1)
+		assert.Equal(t, "datasources:uid:ds", result.SkippedPermissions[0].Scope)
+		require.Len(t, result.ContactPoints, 2)
+		assert.Equal(t, "oncall", result.ContactPoints[0].Name)
+		assert.Equal(t, "personal", result.ContactPoints[1].Name)
+		assert.Empty(t, result.SkippedContactPoints)
+		assert.Equal(t, []ReportRef{{OrgID: 1, ID: 1, Name: "weekly"}}, result.ScheduledReports)
+		assert.Equal(t, []ReportRef{{OrgID: 3, ID: 2, Name: "other org"}}, result.SkippedScheduledReports)
+
+		// previewing twice returns the same result
+		again, err := s.Preview(ctx, cmd)
+		require.NoError(t, err)
+		assert.Equal(t, result, again)
+	})
+
+	t.Run("should keep contact points and reports which cannot be reassigned to a team", func(t *testing.T) {
+		result, err := s.Preview(ctx, &DeactivateUserCommand{UserID: leaver.ID, TargetTeamID: team.Id})
+		require.NoError(t, err)
+		assert.Equal(t, []ContactPointRef{{OrgID: 1, Name: "oncall"}}, result.ContactPoints)
+		assert.Equal(t, []ContactPointRef{{OrgID: 1, Name: "personal"}}, result.SkippedContactPoints)
+		assert.Empty(t, result.ScheduledReports)
+		assert.Len(t, result.SkippedScheduledReports, 2)
+	})
+
+	t.Run("should reassign resources and disable the user", func(t *testing.T) {
+		result, err := s.Deactivate(ctx, cmd)
+		require.NoError(t, err)
+		assert.True(t, result.Applied)
+
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			var createdBy int64
+			_, err := sess.SQL("SELECT created_by FROM dashboard WHERE uid = 'dash'").Get(&createdBy)
+			require.NoError(t, err)
+			assert.Equal(t, successor.ID, createdBy)
+
+			var disabled bool
+			_, err = sess.Table("user").Cols("is_disabled").Where("id = ?", leaver.ID).Get(&disabled)
+			require.NoError(t, err)
+			assert.True(t, disabled)
+
+			count, err := sess.Table("permission").
+				Join("INNER", "role", "role.id = permission.role_id").
+				Where("role.name = ? AND permission.scope = ?", accesscontrol.ManagedUserRoleName(successor.ID), "dashboards:uid:dash").Count()
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), count)
+
+			count, err = sess.Table("permission").
+				Join("INNER", "role", "role.id = permission.role_id").
+				Where("role.name = ? AND permission.scope = ?", accesscontrol.ManagedUserRoleName(leaver.ID), "dashboards:uid:dash").Count()
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), count)
+
+			var config ngmodels.AlertConfiguration
+			_, err = sess.Desc("id").Where("org_id = 1").Limit(1).Get(&config)
+			require.NoError(t, err)
+			assert.Contains(t, config.AlertmanagerConfiguration, "successor@example.com;team@example.com")
+
+			var owners []int64
+			require.NoError(t, sess.SQL("SELECT user_id FROM scheduled_report ORDER BY id").Find(&owners))
+			assert.Equal(t, []int64{successor.ID, leaver.ID}, owners)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, ac.Calls.ClearUserPermissionCache)
+	})
+}
@@ -0,0 +1,103 @@
+package userexport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *UserExportService) registerAPIEndpoints() {
+	s.RouteRegister.Group("/api/user/export", func(entities routing.RouteRegister) {
+		entities.Get("/", middleware.ReqSignedInNoAnonymous, routing.Wrap(s.requestExportHandler))
+		entities.Get("/:uid/download", middleware.ReqSignedInNoAnonymous, routing.Wrap(s.downloadHandler))
+	})
+}
+
+// swagger:route GET /user/export signed_in_user exportUserData
+//
+// Export everything stored about the signed in user.
+//
+// Starts an export of the profile, preferences, stars, dashboards, annotations and
+// audit entries of the signed in user if none is running and returns its status.
+// Once the export is done, the archive can be downloaded from the returned `downloadUrl`
+// for 24 hours. Pass `refresh=true` to replace a finished export by a new one.
+//
+// Responses:
+// 200: userExportResponse
+// 202: userExportResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *UserExportService) requestExportHandler(c *models.ReqContext) response.Response {
+	if c.SignedInUser.IsServiceAccount {
+		return response.Error(http.StatusBadRequest, "Service accounts can not be exported", nil)
+	}
+
+	job, err := s.RequestExport(c.Req.Context(), c.UserID, c.QueryBool("refresh"))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to export user data", err)
+	}
+
+	if job.Status != StatusDone {
+		return response.JSON(http.StatusAccepted, job)
+	}
+
+	job.DownloadURL = fmt.Sprintf("%s/api/user/export/%s/download", s.Cfg.AppSubURL, job.UID)
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route GET /user/export/{export_uid}/download signed_in_user downloadUserExport
+//
+// Download the archive of a finished user data export.
+//
+// Produces:
+// - application/zip
+//
+// Responses:
+// 200: contentResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *UserExportService) downloadHandler(c *models.ReqContext) response.Response {
+	archive, err := s.GetArchive(c.Req.Context(), c.UserID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrExportNotFound):
+			return response.Error(http.StatusNotFound, "Export not found", err)
+		case errors.Is(err, ErrExportNotReady):
+			return response.Error(http.StatusConflict, "Export is not ready yet", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get export", err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/zip")
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="grafana-user-export-%s.zip"`, web.Params(c.Req)[":uid"]))
+	return response.CreateNormalResponse(header, archive, http.StatusOK)
+}
+
+// swagger:parameters exportUserData
+type ExportUserDataParams struct {
+	// in:query
+	// required:false
+	Refresh bool `json:"refresh"`
+}
+
+// swagger:parameters downloadUserExport
+type DownloadUserExportParams struct {
+	// in:path
+	// required:true
+	UID string `json:"export_uid"`
+}
+
+// swagger:response userExportResponse
+type UserExportResponse struct {
+	// in:body
+	Body Job `json:"body"`
+}
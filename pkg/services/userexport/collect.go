@@ -0,0 +1,229 @@
+package userexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// section is a part of the export written as a single JSON file in the archive.
+type section struct {
+	name    string
+	collect func(sess *db.Session, userID int64) (interface{}, error)
+}
+
+var sections = []section{
+	{name: "profile", collect: collectProfile},
+	{name: "organizations", collect: collectOrganizations},
+	{name: "preferences", collect: collectPreferences},
+	{name: "stars", collect: collectStars},
+	{name: "dashboards", collect: collectDashboards},
+	{name: "annotations", collect: collectAnnotations},
+	{name: "audit", collect: collectAudit},
+}
+
+type profile struct {
+	ID         int64     `xorm:"id" json:"id"`
+	Login      string    `xorm:"login" json:"login"`
+	Email      string    `xorm:"email" json:"email"`
+	Name       string    `xorm:"name" json:"name"`
+	OrgID      int64     `xorm:"org_id" json:"orgId"`
+	IsAdmin    bool      `xorm:"is_admin" json:"isGrafanaAdmin"`
+	IsDisabled bool      `xorm:"is_disabled" json:"isDisabled"`
+	Theme      string    `xorm:"theme" json:"theme"`
+	HelpFlags1 int64     `xorm:"help_flags1" json:"helpFlags1"`
+	Created    time.Time `xorm:"created" json:"created"`
+	Updated    time.Time `xorm:"updated" json:"updated"`
+	LastSeenAt time.Time `xorm:"last_seen_at" json:"lastSeenAt"`
+}
+
+func collectProfile(sess *db.Session, userID int64) (interface{}, error) {
+	var p profile
+	has, err := sess.Table("user").
+		Cols("id", "login", "email", "name", "org_id", "is_admin", "is_disabled", "theme", "help_flags1", "created", "updated", "last_seen_at").
+		Where("id = ?", userID).Get(&p)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, fmt.Errorf("user %d not found", userID)
+	}
+
+	var authModules []struct {
+		AuthModule string    `xorm:"auth_module" json:"authModule"`
+		AuthID     string    `xorm:"auth_id" json:"authId"`
+		Created    time.Time `xorm:"created" json:"created"`
+	}
+	if err := sess.Table("user_auth").Cols("auth_module", "auth_id", "created").
+		Where("user_id = ?", userID).Find(&authModules); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"user":        p,
+		"authModules": authModules,
+	}, nil
+}
+
+func collectOrganizations(sess *db.Session, userID int64) (interface{}, error) {
+	var orgs []struct {
+		OrgID   int64     `xorm:"org_id" json:"orgId"`
+		Name    string    `xorm:"name" json:"name"`
+		Role    string    `xorm:"role" json:"role"`
+		Created time.Time `xorm:"created" json:"created"`
+	}
+	err := sess.Table("org_user").
+		Join("INNER", "org", "org.id = org_user.org_id").
+		Cols("org_user.org_id", "org.name", "org_user.role", "org_user.created").
+		Where("org_user.user_id = ?", userID).
+		Find(&orgs)
+	return orgs, err
+}
+
+func collectPreferences(sess *db.Session, userID int64) (interface{}, error) {
+	var prefs []struct {
+		OrgID           int64     `xorm:"org_id" json:"orgId"`
+		HomeDashboardID int64     `xorm:"home_dashboard_id" json:"homeDashboardId"`
+		Timezone        string    `xorm:"timezone" json:"timezone"`
+		WeekStart       *string   `xorm:"week_start" json:"weekStart"`
+		Theme           string    `xorm:"theme" json:"theme"`
+		JSONData        *string   `xorm:"json_data" json:"jsonData"`
+		Updated         time.Time `xorm:"updated" json:"updated"`
+	}
+	err := sess.Table("preferences").
+		Cols("org_id", "home_dashboard_id", "timezone", "week_start", "theme", "json_data", "updated").
+		Where("user_id = ?", userID).
+		Find(&prefs)
+	return prefs, err
+}
+
+func collectStars(sess *db.Session, userID int64) (interface{}, error) {
+	var stars []struct {
+		OrgID int64  `xorm:"org_id" json:"orgId"`
+		UID   string `xorm:"uid" json:"dashboardUid"`
+		Title string `xorm:"title" json:"title"`
+	}
+	err := sess.Table("star").
+		Join("INNER", "dashboard", "dashboard.id = star.dashboard_id").
+		Cols("dashboard.org_id", "dashboard.uid", "dashboard.title").
+		Where("star.user_id = ?", userID).
+		Find(&stars)
+	return stars, err
+}
+
+func collectDashboards(sess *db.Session, userID int64) (interface{}, error) {
+	var dashboards []struct {
+		OrgID    int64     `xorm:"org_id" json:"orgId"`
+		UID      string    `xorm:"uid" json:"uid"`
+		Title    string    `xorm:"title" json:"title"`
+		IsFolder bool      `xorm:"is_folder" json:"isFolder"`
+		Created  time.Time `xorm:"created" json:"created"`
+		Updated  time.Time `xorm:"updated" json:"updated"`
+	}
+	err := sess.Table("dashboard").
+		Cols("org_id", "uid", "title", "is_folder", "created", "updated").
+		Where("created_by = ?", userID).
+		Find(&dashboards)
+	return dashboards, err
+}
+
+func collectAnnotations(sess *db.Session, userID int64) (interface{}, error) {
+	var annotations []struct {
+		ID          int64  `xorm:"id" json:"id"`
+		OrgID       int64  `xorm:"org_id" json:"orgId"`
+		DashboardID int64  `xorm:"dashboard_id" json:"dashboardId"`
+		PanelID     int64  `xorm:"panel_id" json:"panelId"`
+		Text        string `xorm:"text" json:"text"`
+		Epoch       int64  `xorm:"epoch" json:"time"`
+		EpochEnd    int64  `xorm:"epoch_end" json:"timeEnd"`
+		Created     int64  `xorm:"created" json:"created"`
+		Updated     int64  `xorm:"updated" json:"updated"`
+	}
+	err := sess.Table("annotation").
+		Cols("id", "org_id", "dashboard_id", "panel_id", "text", "epoch", "epoch_end", "created", "updated").
+		Where("user_id = ?", userID).
+		Find(&annotations)
+	return annotations, err
+}
+
+// collectAudit returns the changes made by the user that Grafana keeps a record of.
+func collectAudit(sess *db.Session, userID int64) (interface{}, error) {
+	var versions []struct {
+		DashboardUID string    `xorm:"uid" json:"dashboardUid"`
+		Version      int       `xorm:"version" json:"version"`
+		Message      string    `xorm:"message" json:"message"`
+		Created      time.Time `xorm:"created" json:"created"`
+	}
+	err := sess.Table("dashboard_version").
+		Join("INNER", "dashboard", "dashboard.id = dashboard_version.dashboard_id").
+		Cols("dashboard.uid", "dashboard_version.version", "dashboard_version.message", "dashboard_version.created").
+		Where("dashboard_version.created_by = ?", userID).
+		Find(&versions)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []struct {
+		UID           string `xorm:"uid" json:"uid"`
+		OrgID         int64  `xorm:"org_id" json:"orgId"`
+		DatasourceUID string `xorm:"datasource_uid" json:"datasourceUid"`
+		Queries       string `xorm:"queries" json:"queries"`
+		Comment       string `xorm:"comment" json:"comment"`
+		CreatedAt     int64  `xorm:"created_at" json:"createdAt"`
+	}
+	err = sess.Table("query_history").
+		Cols("uid", "org_id", "datasource_uid", "queries", "comment", "created_at").
+		Where("created_by = ?", userID).
+		Find(&queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"dashboardVersions": versions,
+		"queryHistory":      queries,
+	}, nil
+}
+
+// buildArchive collects all sections for the user and returns them as a zip archive.
+func (s *UserExportService) buildArchive(ctx context.Context, userID int64) ([]byte, error) {
+	data := make(map[string]interface{}, len(sections))
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, sec := range sections {
+			v, err := sec.collect(sess, userID)
+			if err != nil {
+				return fmt.Errorf("failed to collect %s: %w", sec.name, err)
+			}
+			data[sec.name] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, sec := range sections {
+		w, err := zw.Create(sec.name + ".json")
+		if err != nil {
+			return nil, err
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data[sec.name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
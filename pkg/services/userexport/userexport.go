@@ -0,0 +1,275 @@
+package userexport
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// exportRetention is how long a finished export can be downloaded before it is removed.
+	exportRetention = 24 * time.Hour
+	cleanupInterval = time.Hour
+	exportTimeout   = 10 * time.Minute
+
+	// storageRoot is the folder of the file storage the archives are stored in.
+	storageRoot     = "/user-exports/"
+	archiveMimeType = "application/zip"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+var (
+	ErrExportNotFound = errors.New("export not found")
+	ErrExportNotReady = errors.New("export is not ready")
+)
+
+// Job is an export of the data stored about a single user. Jobs and their archives are stored
+// in the database, so that any instance can report the status of an export and serve its archive.
+type Job struct {
+	ID          int64      `json:"-" xorm:"pk autoincr 'id'"`
+	UID         string     `json:"uid" xorm:"uid"`
+	UserID      int64      `json:"-" xorm:"user_id"`
+	Status      Status     `json:"status" xorm:"status"`
+	Created     time.Time  `json:"created" xorm:"created"`
+	Finished    *time.Time `json:"finished,omitempty" xorm:"finished"`
+	Error       string     `json:"error,omitempty" xorm:"error"`
+	DownloadURL string     `json:"downloadUrl,omitempty" xorm:"-"`
+}
+
+func (j Job) TableName() string {
+	return "user_export"
+}
+
+func (j *Job) expired(now time.Time) bool {
+	return j.Finished != nil && now.Sub(*j.Finished) > exportRetention
+}
+
+// running returns false for jobs that never finished, for example because the instance
+// running them was stopped.
+func (j *Job) running(now time.Time) bool {
+	return (j.Status == StatusPending || j.Status == StatusRunning) && now.Sub(j.Created) < exportTimeout
+}
+
+type Service interface {
+	// RequestExport returns the current export of the user, starting a new one when there is
+	// none, when the previous one never finished or when refresh is set and it has finished.
+	RequestExport(ctx context.Context, userID int64, refresh bool) (*Job, error)
+	// GetArchive returns the archive of a finished export.
+	GetArchive(ctx context.Context, userID int64, uid string) ([]byte, error)
+}
+
+type UserExportService struct {
+	store   db.DB
+	Cfg     *setting.Cfg
+	log     log.Logger
+	storage filestorage.FileStorage
+
+	RouteRegister routing.RouteRegister
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister) *UserExportService {
+	logger := log.New("user-export")
+	s := &UserExportService{
+		store:         sqlStore,
+		Cfg:           cfg,
+		RouteRegister: routeRegister,
+		log:           logger,
+		storage:       filestorage.NewDbStorage(logger, sqlStore, nil, storageRoot),
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *UserExportService) RequestExport(ctx context.Context, userID int64, refresh bool) (*Job, error) {
+	now := time.Now()
+	previous, err := s.getJob(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// exports which never finished are restarted, even if refresh isn't set
+	if previous != nil && !previous.expired(now) && (previous.running(now) || previous.Finished != nil && !refresh) {
+		return previous, nil
+	}
+
+	job := &Job{
+		UID:     util.GenerateShortUID(),
+		UserID:  userID,
+		Status:  StatusPending,
+		Created: now,
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if previous != nil {
+			if _, err := sess.ID(previous.ID).Delete(&Job{}); err != nil {
+				return err
+			}
+		}
+		_, err := sess.Insert(job)
+		return err
+	})
+	if err != nil {
+		// another instance may have started an export at the same time
+		current, getErr := s.getJob(ctx, userID)
+		if getErr == nil && current != nil && (previous == nil || current.UID != previous.UID) {
+			return current, nil
+		}
+		return nil, err
+	}
+
+	if previous != nil {
+		s.deleteArchive(ctx, previous)
+	}
+
+	go s.run(job)
+
+	copied := *job
+	return &copied, nil
+}
+
+func (s *UserExportService) GetArchive(ctx context.Context, userID int64, uid string) ([]byte, error) {
+	job, err := s.getJob(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.UID != uid || job.expired(time.Now()) {
+		return nil, ErrExportNotFound
+	}
+	if job.Status != StatusDone {
+		return nil, ErrExportNotReady
+	}
+
+	f, ok, err := s.storage.Get(ctx, archivePath(job), &filestorage.GetFileOptions{WithContents: true})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrExportNotFound
+	}
+	return f.Contents, nil
+}
+
+func (s *UserExportService) getJob(ctx context.Context, userID int64) (*Job, error) {
+	var job *Job
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		j := &Job{}
+		has, err := sess.Where("user_id = ?", userID).Get(j)
+		if has {
+			job = j
+		}
+		return err
+	})
+	return job, err
+}
+
+func archivePath(job *Job) string {
+	return filestorage.Delimiter + job.UID + ".zip"
+}
+
+func (s *UserExportService) deleteArchive(ctx context.Context, job *Job) {
+	if err := s.storage.Delete(ctx, archivePath(job)); err != nil {
+		s.log.Warn("Failed to remove user export", "uid", job.UID, "error", err)
+	}
+}
+
+func (s *UserExportService) setStatus(ctx context.Context, job *Job, status Status, err error) {
+	job.Status = status
+	if status == StatusDone || status == StatusFailed {
+		finished := time.Now()
+		job.Finished = &finished
+	}
+	if err != nil {
+		job.Error = err.Error()
+	}
+
+	updateErr := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(job.ID).Cols("status", "finished", "error").Update(job)
+		return err
+	})
+	if updateErr != nil {
+		s.log.Error("Failed to update user export", "uid", job.UID, "status", status, "error", updateErr)
+	}
+}
+
+func (s *UserExportService) run(job *Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	s.setStatus(ctx, job, StatusRunning, nil)
+
+	archive, err := s.buildArchive(ctx, job.UserID)
+	if err == nil {
+		err = s.storage.Upsert(ctx, &filestorage.UpsertFileCommand{
+			Path:     archivePath(job),
+			MimeType: archiveMimeType,
+			Contents: archive,
+		})
+	}
+	if err != nil {
+		s.log.Error("Failed to export user data", "userId", job.UserID, "uid", job.UID, "error", err)
+		s.setStatus(ctx, job, StatusFailed, errors.New("failed to export user data"))
+		return
+	}
+
+	s.log.Info("Exported user data", "userId", job.UserID, "uid", job.UID)
+	s.setStatus(ctx, job, StatusDone, nil)
+}
+
+// Run removes exports once their retention period has passed. Every instance removes them, which
+// is harmless since removing an export twice has no effect.
+func (s *UserExportService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.cleanup(ctx, time.Now()); err != nil {
+				s.log.Error("Failed to remove expired user exports", "error", err)
+			}
+		}
+	}
+}
+
+// cleanup removes the exports created before the retention period of the longest possible export.
+func (s *UserExportService) cleanup(ctx context.Context, now time.Time) error {
+	var jobs []*Job
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("created < ?", now.Add(-exportRetention-exportTimeout)).Find(&jobs)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := s.storage.Delete(ctx, archivePath(job)); err != nil {
+			s.log.Warn("Failed to remove user export", "uid", job.UID, "error", err)
+			continue
+		}
+		err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.ID(job.ID).Delete(&Job{})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
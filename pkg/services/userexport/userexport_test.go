@@ -0,0 +1,141 @@
+package userexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupTestService(t *testing.T) (*UserExportService, *user.User) {
+	t.Helper()
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+
+	usr, err := sqlStore.CreateUser(context.Background(), user.CreateUserCommand{
+		Login: "export-user",
+		Email: "export-user@example.com",
+		Name:  "Export User",
+	})
+	require.NoError(t, err)
+
+	return ProvideService(cfg, sqlStore, routing.NewRouteRegister()), usr
+}
+
+func TestIntegrationUserExport_BuildArchive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	s, usr := setupTestService(t)
+
+	contents, err := s.buildArchive(context.Background(), usr.ID)
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	require.NoError(t, err)
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	for _, sec := range sections {
+		require.Contains(t, files, sec.name+".json")
+	}
+
+	r, err := files["profile.json"].Open()
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	var p struct {
+		User struct {
+			Login string `json:"login"`
+			Email string `json:"email"`
+		} `json:"user"`
+	}
+	require.NoError(t, json.NewDecoder(r).Decode(&p))
+	assert.Equal(t, "export-user", p.User.Login)
+	assert.Equal(t, "export-user@example.com", p.User.Email)
+}
+
+func TestIntegrationUserExport_RequestExport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	s, usr := setupTestService(t)
+	ctx := context.Background()
+
+	job, err := s.RequestExport(ctx, usr.ID, false)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		current, err := s.RequestExport(ctx, usr.ID, false)
+		require.NoError(t, err)
+		require.Equal(t, job.UID, current.UID)
+		return current.Status == StatusDone
+	}, 10*time.Second, 50*time.Millisecond)
+
+	archive, err := s.GetArchive(ctx, usr.ID, job.UID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, archive)
+
+	_, err = s.GetArchive(ctx, usr.ID+1, job.UID)
+	require.ErrorIs(t, err, ErrExportNotFound)
+
+	t.Run("should serve the export from other instances", func(t *testing.T) {
+		other := ProvideService(s.Cfg, s.store, routing.NewRouteRegister())
+
+		current, err := other.RequestExport(ctx, usr.ID, false)
+		require.NoError(t, err)
+		assert.Equal(t, job.UID, current.UID)
+		assert.Equal(t, StatusDone, current.Status)
+
+		otherArchive, err := other.GetArchive(ctx, usr.ID, job.UID)
+		require.NoError(t, err)
+		assert.Equal(t, archive, otherArchive)
+	})
+
+	t.Run("should restart exports which never finished", func(t *testing.T) {
+		stale := &Job{UID: "stale", UserID: usr.ID + 1, Status: StatusRunning}
+		err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Insert(stale); err != nil {
+				return err
+			}
+			// the creation time is set on insert
+			_, err := sess.Exec("UPDATE user_export SET created = ? WHERE id = ?", time.Now().Add(-exportTimeout-time.Minute), stale.ID)
+			return err
+		})
+		require.NoError(t, err)
+
+		current, err := s.RequestExport(ctx, usr.ID+1, false)
+		require.NoError(t, err)
+		assert.NotEqual(t, stale.UID, current.UID)
+		assert.Equal(t, StatusPending, current.Status)
+
+		require.Eventually(t, func() bool {
+			current, err := s.RequestExport(ctx, usr.ID+1, false)
+			require.NoError(t, err)
+			return current.Status == StatusDone || current.Status == StatusFailed
+		}, 10*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("should remove expired exports", func(t *testing.T) {
+		require.NoError(t, s.cleanup(ctx, time.Now().Add(exportRetention+exportTimeout+time.Minute)))
+
+		_, err := s.GetArchive(ctx, usr.ID, job.UID)
+		require.ErrorIs(t, err, ErrExportNotFound)
+
+		_, ok, err := s.storage.Get(ctx, archivePath(job), nil)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,40 @@
+package variablecache
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *VariableCacheService) registerAPIEndpoints() {
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Delete("/api/datasources/uid/:uid/cache/variables", middleware.ReqSignedIn, authorize(middleware.ReqOrgAdmin, ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.invalidateHandler))
+}
+
+// swagger:route DELETE /datasources/uid/{uid}/cache/variables datasources invalidateVariableCache
+//
+// Invalidate the variable cache of a data source.
+//
+// Removes the cached responses of resource calls to the data source, such as the label values of template variables.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *VariableCacheService) invalidateHandler(c *models.ReqContext) response.Response {
+	s.Invalidate(c.OrgID, web.Params(c.Req)[":uid"])
+	return response.Success("Variable cache invalidated")
+}
+
+// swagger:parameters invalidateVariableCache
+type InvalidateVariableCacheParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
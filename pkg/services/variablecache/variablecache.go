@@ -0,0 +1,216 @@
+// Package variablecache caches the responses of data source resource calls, such as the label
+// values template variables query from Prometheus.
+//
+// Responses are keyed by the data source, the path and parameters of the request and its time
+// range rounded to a bucket, so that the variables of a dashboard loaded by many viewers only
+// query the data source once per bucket and TTL.
+package variablecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// HeaderCache is set on cached resource calls to HIT or MISS.
+const HeaderCache = "X-Grafana-Cache"
+
+// maxEntrySize is the size of a response body above which it is not cached.
+const maxEntrySize = 1 << 20
+
+// timeParams are the request parameters holding the time range of a request.
+var timeParams = []string{"start", "end", "from", "to"}
+
+type Service interface {
+	// Key returns the cache key of a resource call to a data source, or false if its response must
+	// not be cached.
+	Key(orgID int64, ds *datasources.DataSource, req *http.Request) (string, bool)
+	Get(key string) (*Entry, bool)
+	Set(key string, entry *Entry)
+	// Invalidate removes the cached responses of a data source.
+	Invalidate(orgID int64, dsUID string)
+}
+
+// Entry is a cached response.
+type Entry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type VariableCacheService struct {
+	cfg           setting.VariableCacheSettings
+	cache         *gocache.Cache
+	types         map[string]bool
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	log           log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, bus bus.Bus, accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *VariableCacheService {
+	s := &VariableCacheService{
+		cfg:           cfg.VariableCache,
+		cache:         gocache.New(cfg.VariableCache.TTL, 2*cfg.VariableCache.TTL),
+		types:         make(map[string]bool),
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+		log:           log.New("variablecache"),
+	}
+	for _, t := range cfg.VariableCache.DataSourceTypes {
+		s.types[t] = true
+	}
+
+	if !s.cfg.Enabled {
+		return s
+	}
+
+	s.registerAPIEndpoints()
+
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceUpdated) error {
+		s.Invalidate(e.OrgID, e.UID)
+		return nil
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceDeleted) error {
+		s.Invalidate(e.OrgID, e.UID)
+		return nil
+	})
+
+	return s
+}
+
+func (s *VariableCacheService) Key(orgID int64, ds *datasources.DataSource, req *http.Request) (string, bool) {
+	if !s.cfg.Enabled || req.Method != http.MethodGet || !s.types[ds.Type] {
+		return "", false
+	}
+
+	params := req.URL.Query()
+	for _, name := range timeParams {
+		if value := params.Get(name); value != "" {
+			if bucket, ok := bucketTime(value, s.cfg.TimeRangeBucket); ok {
+				params.Set(name, bucket)
+			}
+		}
+	}
+
+	// The version makes sure responses cached before the data source changed are not served by
+	// other instances which did not receive the invalidation.
+	sum := sha256.Sum256([]byte(req.URL.Path + "?" + params.Encode()))
+	return fmt.Sprintf("%s%d/%s", prefix(orgID, ds.Uid), ds.Version, hex.EncodeToString(sum[:])), true
+}
+
+func (s *VariableCacheService) Get(key string) (*Entry, bool) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return value.(*Entry), true
+}
+
+func (s *VariableCacheService) Set(key string, entry *Entry) {
+	if entry.Status != http.StatusOK || len(entry.Body) > maxEntrySize {
+		return
+	}
+	if s.cache.ItemCount() >= s.cfg.MaxEntries {
+		s.log.Debug("Not caching response, the cache is full", "maxEntries", s.cfg.MaxEntries)
+		return
+	}
+	s.cache.SetDefault(key, entry)
+}
+
+func (s *VariableCacheService) Invalidate(orgID int64, dsUID string) {
+	p := prefix(orgID, dsUID)
+	for key := range s.cache.Items() {
+		if strings.HasPrefix(key, p) {
+			s.cache.Delete(key)
+		}
+	}
+}
+
+func prefix(orgID int64, dsUID string) string {
+	return fmt.Sprintf("%d/%s/", orgID, dsUID)
+}
+
+// bucketTime rounds a time parameter down to the bucket. Numbers are read as Unix timestamps in
+// seconds, milliseconds, microseconds or nanoseconds depending on their magnitude, as data sources
+// use different precisions.
+func bucketTime(value string, bucket time.Duration) (string, bool) {
+	var t time.Time
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		switch {
+		case f > 1e17:
+			t = time.Unix(0, int64(f))
+		case f > 1e14:
+			t = time.UnixMicro(int64(f))
+		case f > 1e11:
+			t = time.UnixMilli(int64(f))
+		default:
+			t = time.Unix(int64(f), 0)
+		}
+	} else if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		t = parsed
+	} else {
+		return "", false
+	}
+	return strconv.FormatInt(t.Truncate(bucket).Unix(), 10), true
+}
+
+// Recorder passes a response on to the client and records it for caching.
+type Recorder struct {
+	http.ResponseWriter
+	entry *Entry
+}
+
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, entry: &Entry{Status: http.StatusOK}}
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	r.entry.Status = status
+	r.entry.Header = r.Header().Clone()
+	r.entry.Header.Del(HeaderCache)
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	if len(r.entry.Body) <= maxEntrySize {
+		r.entry.Body = append(r.entry.Body, b...)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *Recorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Entry returns the recorded response.
+func (r *Recorder) Entry() *Entry {
+	return r.entry
+}
+
+// Write writes a cached response to the client.
+func (e *Entry) Write(w http.ResponseWriter) error {
+	for k, values := range e.Header {
+		w.Header()[k] = values
+	}
+	w.Header().Set(HeaderCache, "HIT")
+	w.WriteHeader(e.Status)
+	_, err := w.Write(e.Body)
+	return err
+}
@@ -0,0 +1,117 @@
+package variablecache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupService(t *testing.T) *VariableCacheService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.VariableCache = setting.VariableCacheSettings{
+		Enabled:         true,
+		TTL:             time.Minute,
+		TimeRangeBucket: 5 * time.Minute,
+		DataSourceTypes: []string{"prometheus"},
+		MaxEntries:      2,
+	}
+	return ProvideService(cfg, bus.ProvideBus(tracing.InitializeTracerForTest()), acimpl.ProvideAccessControl(cfg), routing.NewRouteRegister())
+}
+
+func TestKey(t *testing.T) {
+	s := setupService(t)
+	prometheus := &datasources.DataSource{Uid: "prom", Type: "prometheus", Version: 1}
+
+	key := func(ds *datasources.DataSource, method, url string) (string, bool) {
+		return s.Key(1, ds, httptest.NewRequest(method, url, nil))
+	}
+
+	t.Run("Should share the key of requests in the same time range bucket", func(t *testing.T) {
+		first, ok := key(prometheus, http.MethodGet, "/api/v1/label/job/values?start=1664625600&end=1664629200")
+		require.True(t, ok)
+		second, ok := key(prometheus, http.MethodGet, "/api/v1/label/job/values?end=1664629230&start=1664625630")
+		require.True(t, ok)
+		assert.Equal(t, first, second)
+
+		other, _ := key(prometheus, http.MethodGet, "/api/v1/label/job/values?start=1664625600&end=1664629500")
+		assert.NotEqual(t, first, other)
+		other, _ = key(prometheus, http.MethodGet, "/api/v1/label/instance/values?start=1664625600&end=1664629200")
+		assert.NotEqual(t, first, other)
+		other, _ = key(&datasources.DataSource{Uid: "prom", Type: "prometheus", Version: 2}, http.MethodGet, "/api/v1/label/job/values?start=1664625600&end=1664629200")
+		assert.NotEqual(t, first, other)
+	})
+
+	t.Run("Should not cache other methods and data source types", func(t *testing.T) {
+		_, ok := key(prometheus, http.MethodPost, "/api/v1/series")
+		assert.False(t, ok)
+		_, ok = key(&datasources.DataSource{Uid: "influx", Type: "influxdb"}, http.MethodGet, "/api/v1/series")
+		assert.False(t, ok)
+	})
+}
+
+func TestBucketTime(t *testing.T) {
+	for _, value := range []string{"1664629230", "1664629230.5", "1664629230000", "1664629230000000", "1664629230000000000", "2022-10-01T13:00:30Z"} {
+		bucket, ok := bucketTime(value, 5*time.Minute)
+		require.True(t, ok, value)
+		assert.Equal(t, "1664629200", bucket, value)
+	}
+
+	_, ok := bucketTime("now-1h", 5*time.Minute)
+	assert.False(t, ok)
+}
+
+func TestCache(t *testing.T) {
+	s := setupService(t)
+
+	t.Run("Should cache successful responses until the cache is full", func(t *testing.T) {
+		s.Set("1/prom/1/a", &Entry{Status: http.StatusOK, Body: []byte("a")})
+		s.Set("1/prom/1/b", &Entry{Status: http.StatusInternalServerError})
+		s.Set("1/other/1/c", &Entry{Status: http.StatusOK})
+		s.Set("1/prom/1/d", &Entry{Status: http.StatusOK})
+
+		entry, ok := s.Get("1/prom/1/a")
+		require.True(t, ok)
+		assert.Equal(t, []byte("a"), entry.Body)
+		_, ok = s.Get("1/prom/1/b")
+		assert.False(t, ok)
+		_, ok = s.Get("1/prom/1/d")
+		assert.False(t, ok)
+	})
+
+	t.Run("Should invalidate the responses of a data source", func(t *testing.T) {
+		s.Invalidate(1, "prom")
+		_, ok := s.Get("1/prom/1/a")
+		assert.False(t, ok)
+		_, ok = s.Get("1/other/1/c")
+		assert.True(t, ok)
+	})
+
+	t.Run("Should record and replay responses", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		recorder := NewRecorder(rec)
+		recorder.Header().Set("Content-Type", "application/json")
+		recorder.Header().Set(HeaderCache, "MISS")
+		recorder.WriteHeader(http.StatusOK)
+		_, err := recorder.Write([]byte(`{"status":"success"}`))
+		require.NoError(t, err)
+
+		replayed := httptest.NewRecorder()
+		require.NoError(t, recorder.Entry().Write(replayed))
+		assert.Equal(t, http.StatusOK, replayed.Code)
+		assert.Equal(t, "application/json", replayed.Header().Get("Content-Type"))
+		assert.Equal(t, "HIT", replayed.Header().Get(HeaderCache))
+		assert.Equal(t, `{"status":"success"}`, replayed.Body.String())
+	})
+}
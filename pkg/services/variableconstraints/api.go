@@ -0,0 +1,129 @@
+package variableconstraints
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *ConstraintService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	dashboardScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.routeRegister.Group("/api/dashboards/uid/:uid/variable-constraints", func(constraintRoute routing.RouteRegister) {
+		constraintRoute.Get("/", authorize(middleware.ReqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsPermissionsRead, dashboardScope)), routing.Wrap(s.getConstraintsHandler))
+		constraintRoute.Put("/", authorize(middleware.ReqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsPermissionsWrite, dashboardScope)), routing.Wrap(s.setConstraintsHandler))
+		constraintRoute.Get("/allowed", authorize(middleware.ReqSignedIn, ac.EvalPermission(dashboards.ActionDashboardsRead, dashboardScope)), routing.Wrap(s.getAllowedValuesHandler))
+	})
+}
+
+// swagger:route GET /dashboards/uid/{uid}/variable-constraints dashboards getDashboardVariableConstraints
+//
+// Get the constraints of the template variables of a dashboard.
+//
+// Responses:
+// 200: getDashboardVariableConstraintsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ConstraintService) getConstraintsHandler(c *models.ReqContext) response.Response {
+	constraints, err := s.GetConstraints(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, constraints)
+}
+
+// swagger:route PUT /dashboards/uid/{uid}/variable-constraints dashboards setDashboardVariableConstraints
+//
+// Replace the constraints of the template variables of a dashboard.
+//
+// Constraints restrict the values of a variable users can select. Queries sent for the dashboard which use a value the user is not allowed to select are rejected. Organization administrators are not restricted.
+//
+// Responses:
+// 200: getDashboardVariableConstraintsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ConstraintService) setConstraintsHandler(c *models.ReqContext) response.Response {
+	cmd := SetConstraintsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.DashboardUID = web.Params(c.Req)[":uid"]
+	cmd.UserID = c.UserID
+
+	constraints, err := s.SetConstraints(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, constraints)
+}
+
+// swagger:route GET /dashboards/uid/{uid}/variable-constraints/allowed dashboards getDashboardVariableAllowedValues
+//
+// Get the values of the constrained template variables of a dashboard the signed in user can select.
+//
+// Responses:
+// 200: getDashboardVariableAllowedValuesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ConstraintService) getAllowedValuesHandler(c *models.ReqContext) response.Response {
+	allowed, err := s.GetAllowedValues(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, allowed)
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard not found", err)
+	case errors.Is(err, ErrInvalidConstraints):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process variable constraints", err)
+}
+
+// swagger:parameters getDashboardVariableConstraints getDashboardVariableAllowedValues
+type DashboardVariableConstraintsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters setDashboardVariableConstraints
+type SetDashboardVariableConstraintsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body SetConstraintsCommand `json:"body"`
+}
+
+// swagger:response getDashboardVariableConstraintsResponse
+type GetDashboardVariableConstraintsResponse struct {
+	// in:body
+	Body []*Constraint `json:"body"`
+}
+
+// swagger:response getDashboardVariableAllowedValuesResponse
+type GetDashboardVariableAllowedValuesResponse struct {
+	// in:body
+	Body []AllowedValues `json:"body"`
+}
@@ -0,0 +1,156 @@
+package variableconstraints
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// TeamPlaceholder is replaced by the names of the teams of the user in the values of rules.
+	TeamPlaceholder = "$__team"
+
+	maxConstraints = 50
+	maxRules       = 100
+	maxValues      = 100
+)
+
+var (
+	ErrInvalidConstraints = errors.New("invalid variable constraints")
+	ErrValueNotAllowed    = errors.New("variable value not allowed")
+)
+
+// Constraint restricts the values of a template variable of a dashboard users can select.
+type Constraint struct {
+	ID           int64     `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID        int64     `json:"-" xorm:"org_id"`
+	DashboardUID string    `json:"dashboardUid" xorm:"dashboard_uid"`
+	Variable     string    `json:"variable" xorm:"variable"`
+	Rules        []Rule    `json:"rules" xorm:"rules"`
+	Updated      time.Time `json:"updated" xorm:"updated"`
+	UpdatedBy    int64     `json:"updatedBy" xorm:"updated_by"`
+}
+
+func (c Constraint) TableName() string {
+	return "dashboard_variable_constraint"
+}
+
+// Rule allows the user, or the members of the team, to select values of a variable. A rule with
+// neither a user nor a team applies to all users, and is typically used with the $__team placeholder.
+// Users no rule applies to cannot select any value of a constrained variable.
+type Rule struct {
+	UserID int64 `json:"userId,omitempty"`
+	TeamID int64 `json:"teamId,omitempty"`
+	// Values are literal values or, enclosed in slashes, regular expressions matching the whole
+	// value, e.g. `/team-a-.*/`. $__team is replaced by the name of each team of the user.
+	Values []string `json:"values"`
+}
+
+func (r Rule) appliesTo(userID int64, teamIDs []int64) bool {
+	if r.UserID == 0 && r.TeamID == 0 {
+		return true
+	}
+	if r.UserID != 0 {
+		return r.UserID == userID
+	}
+	for _, id := range teamIDs {
+		if id == r.TeamID {
+			return true
+		}
+	}
+	return false
+}
+
+func isRegex(value string) bool {
+	return len(value) >= 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/")
+}
+
+// compileValue compiles a regex value anchored to match whole values, with $__team replaced by
+// the alternation of the team names.
+func compileValue(value string, teams []string) (*regexp.Regexp, error) {
+	pattern := value[1 : len(value)-1]
+	if strings.Contains(pattern, TeamPlaceholder) {
+		quoted := make([]string, 0, len(teams))
+		for _, team := range teams {
+			quoted = append(quoted, regexp.QuoteMeta(team))
+		}
+		// a pattern without teams matches nothing
+		alternation := "[^\\s\\S]"
+		if len(quoted) > 0 {
+			alternation = "(?:" + strings.Join(quoted, "|") + ")"
+		}
+		pattern = strings.ReplaceAll(pattern, TeamPlaceholder, alternation)
+	}
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// expandLiteral returns a literal value, or one value per team if it contains $__team.
+func expandLiteral(value string, teams []string) []string {
+	if !strings.Contains(value, TeamPlaceholder) {
+		return []string{value}
+	}
+	values := make([]string, 0, len(teams))
+	for _, team := range teams {
+		values = append(values, strings.ReplaceAll(value, TeamPlaceholder, team))
+	}
+	return values
+}
+
+func (c *Constraint) validate() error {
+	if c.Variable == "" {
+		return fmt.Errorf("%w: variable is required", ErrInvalidConstraints)
+	}
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("%w: variable %s has no rules", ErrInvalidConstraints, c.Variable)
+	}
+	if len(c.Rules) > maxRules {
+		return fmt.Errorf("%w: variable %s has more than %d rules", ErrInvalidConstraints, c.Variable, maxRules)
+	}
+	for _, rule := range c.Rules {
+		if rule.UserID != 0 && rule.TeamID != 0 {
+			return fmt.Errorf("%w: a rule of variable %s has both a user and a team", ErrInvalidConstraints, c.Variable)
+		}
+		if len(rule.Values) == 0 || len(rule.Values) > maxValues {
+			return fmt.Errorf("%w: rules of variable %s must have between 1 and %d values", ErrInvalidConstraints, c.Variable, maxValues)
+		}
+		for _, value := range rule.Values {
+			if value == "" {
+				return fmt.Errorf("%w: variable %s has an empty value", ErrInvalidConstraints, c.Variable)
+			}
+			if isRegex(value) {
+				if _, err := compileValue(value, []string{"team"}); err != nil {
+					return fmt.Errorf("%w: invalid regular expression %s of variable %s: %s", ErrInvalidConstraints, value, c.Variable, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SetConstraintsCommand replaces the constraints of the variables of a dashboard.
+// swagger:model
+type SetConstraintsCommand struct {
+	OrgID        int64  `json:"-"`
+	DashboardUID string `json:"-"`
+	UserID       int64  `json:"-"`
+	// Constraints of the variables, variables without a constraint are unrestricted.
+	Constraints []ConstraintDTO `json:"constraints"`
+}
+
+// ConstraintDTO is the constraint of a variable.
+type ConstraintDTO struct {
+	Variable string `json:"variable"`
+	Rules    []Rule `json:"rules"`
+}
+
+// AllowedValues are the values of a variable a user can select.
+// swagger:model
+type AllowedValues struct {
+	Variable string `json:"variable"`
+	// Values are literal values, with $__team replaced by the teams of the user.
+	Values []string `json:"values"`
+	// Patterns are regular expressions matching whole values.
+	Patterns []string `json:"patterns"`
+}
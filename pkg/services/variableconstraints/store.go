@@ -0,0 +1,76 @@
+package variableconstraints
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type team struct {
+	ID   int64  `xorm:"id"`
+	Name string `xorm:"name"`
+}
+
+func (s *ConstraintService) listConstraints(ctx context.Context, orgID int64, dashboardUID string) ([]*Constraint, error) {
+	constraints := make([]*Constraint, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Asc("variable").Find(&constraints)
+	})
+	return constraints, err
+}
+
+// replaceConstraints replaces all constraints of a dashboard.
+func (s *ConstraintService) replaceConstraints(ctx context.Context, orgID int64, dashboardUID string, constraints []*Constraint) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&Constraint{}); err != nil {
+			return err
+		}
+		for _, c := range constraints {
+			if _, err := sess.Insert(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getTeams returns the names of the teams of an organization by id.
+func (s *ConstraintService) getTeams(ctx context.Context, orgID int64) (map[int64]string, error) {
+	var teams []team
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("team").Where("org_id = ?", orgID).Cols("id", "name").Find(&teams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+	}
+	return names, nil
+}
+
+// checkOrgUsers returns an error if one of the users is not a member of the organization.
+func (s *ConstraintService) checkOrgUsers(ctx context.Context, orgID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	var members []int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("org_user").Where("org_id = ?", orgID).In("user_id", userIDs).Cols("user_id").Find(&members)
+	})
+	if err != nil {
+		return err
+	}
+	isMember := make(map[int64]bool, len(members))
+	for _, id := range members {
+		isMember[id] = true
+	}
+	for _, id := range userIDs {
+		if !isMember[id] {
+			return fmt.Errorf("%w: user %d is not a member of the organization", ErrInvalidConstraints, id)
+		}
+	}
+	return nil
+}
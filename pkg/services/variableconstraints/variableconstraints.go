@@ -0,0 +1,372 @@
+// Package variableconstraints restricts the values of dashboard template variables users and teams
+// can select, and rejects the queries of a dashboard using values the user is not allowed to select.
+package variableconstraints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// allValue is the value of the All option of multi-value variables.
+const allValue = "$__all"
+
+// queryKeys are the properties of queries which are not interpolated with variable values.
+var queryKeys = map[string]bool{"datasource": true, "datasourceId": true, "refId": true}
+
+type Service interface {
+	GetConstraints(ctx context.Context, orgID int64, dashboardUID string) ([]*Constraint, error)
+	// SetConstraints replaces the constraints of the variables of a dashboard.
+	SetConstraints(ctx context.Context, cmd SetConstraintsCommand) ([]*Constraint, error)
+	// GetAllowedValues returns the values of the constrained variables of a dashboard the user can
+	// select. It returns nothing for organization administrators, who are not restricted.
+	GetAllowedValues(ctx context.Context, u *user.SignedInUser, dashboardUID string) ([]AllowedValues, error)
+	// ValidateQueries returns ErrValueNotAllowed if the queries of a dashboard use a value of a
+	// variable the user is not allowed to select.
+	ValidateQueries(ctx context.Context, u *user.SignedInUser, dashboardUID string, queries []*simplejson.Json) error
+}
+
+type ConstraintService struct {
+	store            db.DB
+	log              log.Logger
+	dashboardService dashboards.DashboardService
+	routeRegister    routing.RouteRegister
+	accessControl    accesscontrol.AccessControl
+	now              func() time.Time
+}
+
+func ProvideService(sqlStore db.DB, bus bus.Bus, dashboardService dashboards.DashboardService,
+	routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *ConstraintService {
+	s := &ConstraintService{
+		store:            sqlStore,
+		log:              log.New("variable-constraints"),
+		dashboardService: dashboardService,
+		routeRegister:    routeRegister,
+		accessControl:    accessControl,
+		now:              time.Now,
+	}
+
+	bus.AddEventListener(s.handleDashboardDeleted)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *ConstraintService) GetConstraints(ctx context.Context, orgID int64, dashboardUID string) ([]*Constraint, error) {
+	if _, err := s.getDashboard(ctx, orgID, dashboardUID); err != nil {
+		return nil, err
+	}
+	return s.listConstraints(ctx, orgID, dashboardUID)
+}
+
+func (s *ConstraintService) SetConstraints(ctx context.Context, cmd SetConstraintsCommand) ([]*Constraint, error) {
+	if len(cmd.Constraints) > maxConstraints {
+		return nil, fmt.Errorf("%w: at most %d variables can be constrained", ErrInvalidConstraints, maxConstraints)
+	}
+	dash, err := s.getDashboard(ctx, cmd.OrgID, cmd.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	variables := templateVariables(dash.Data)
+
+	teams, err := s.getTeams(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []int64
+	constraints := make([]*Constraint, 0, len(cmd.Constraints))
+	seen := map[string]bool{}
+	for _, dto := range cmd.Constraints {
+		c := &Constraint{
+			OrgID:        cmd.OrgID,
+			DashboardUID: cmd.DashboardUID,
+			Variable:     dto.Variable,
+			Rules:        dto.Rules,
+			Updated:      s.now(),
+			UpdatedBy:    cmd.UserID,
+		}
+		if err := c.validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := variables[c.Variable]; !ok {
+			return nil, fmt.Errorf("%w: dashboard has no variable %s", ErrInvalidConstraints, c.Variable)
+		}
+		if seen[c.Variable] {
+			return nil, fmt.Errorf("%w: variable %s is constrained twice", ErrInvalidConstraints, c.Variable)
+		}
+		seen[c.Variable] = true
+		for _, rule := range c.Rules {
+			if rule.TeamID != 0 {
+				if _, ok := teams[rule.TeamID]; !ok {
+					return nil, fmt.Errorf("%w: team %d not found", ErrInvalidConstraints, rule.TeamID)
+				}
+			}
+			if rule.UserID != 0 {
+				userIDs = append(userIDs, rule.UserID)
+			}
+		}
+		constraints = append(constraints, c)
+	}
+	if err := s.checkOrgUsers(ctx, cmd.OrgID, userIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.replaceConstraints(ctx, cmd.OrgID, cmd.DashboardUID, constraints); err != nil {
+		return nil, err
+	}
+	return constraints, nil
+}
+
+func (s *ConstraintService) GetAllowedValues(ctx context.Context, u *user.SignedInUser, dashboardUID string) ([]AllowedValues, error) {
+	constraints, err := s.GetConstraints(ctx, u.OrgID, dashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]AllowedValues, 0, len(constraints))
+	if len(constraints) == 0 || u.HasRole(org.RoleAdmin) {
+		return result, nil
+	}
+	teams, err := s.getTeams(ctx, u.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	teamNames := userTeamNames(u, teams)
+	for _, c := range constraints {
+		allowed := AllowedValues{Variable: c.Variable, Values: []string{}, Patterns: []string{}}
+		for _, rule := range c.Rules {
+			if !rule.appliesTo(u.UserID, u.Teams) {
+				continue
+			}
+			for _, value := range rule.Values {
+				if isRegex(value) {
+					re, err := compileValue(value, teamNames)
+					if err != nil {
+						return nil, err
+					}
+					allowed.Patterns = append(allowed.Patterns, re.String())
+					continue
+				}
+				allowed.Values = append(allowed.Values, expandLiteral(value, teamNames)...)
+			}
+		}
+		result = append(result, allowed)
+	}
+	return result, nil
+}
+
+func (s *ConstraintService) ValidateQueries(ctx context.Context, u *user.SignedInUser, dashboardUID string, queries []*simplejson.Json) error {
+	if dashboardUID == "" || u.HasRole(org.RoleAdmin) {
+		return nil
+	}
+	constraints, err := s.listConstraints(ctx, u.OrgID, dashboardUID)
+	if err != nil || len(constraints) == 0 {
+		return err
+	}
+
+	denied, err := s.deniedValues(ctx, u, dashboardUID, constraints)
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		if query == nil {
+			continue
+		}
+		if value, ok := findDenied(query.Interface(), denied); ok {
+			return fmt.Errorf("%w: %s of variable %s", ErrValueNotAllowed, value, denied[value])
+		}
+	}
+	return nil
+}
+
+// deniedValues returns the known values of the constrained variables the user is not allowed to
+// select, mapped to their variable. Values are known from the options and current values saved
+// with the dashboard, and the literal values of the rules for all teams of the organization.
+func (s *ConstraintService) deniedValues(ctx context.Context, u *user.SignedInUser, dashboardUID string, constraints []*Constraint) (map[string]string, error) {
+	var variables map[string][]string
+	dash, err := s.getDashboard(ctx, u.OrgID, dashboardUID)
+	switch {
+	case err == nil:
+		variables = templateVariables(dash.Data)
+	case !errors.Is(err, dashboards.ErrDashboardNotFound):
+		return nil, err
+	}
+	teams, err := s.getTeams(ctx, u.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	allTeamNames := make([]string, 0, len(teams))
+	for _, name := range teams {
+		allTeamNames = append(allTeamNames, name)
+	}
+	teamNames := userTeamNames(u, teams)
+
+	denied := map[string]string{}
+	for _, c := range constraints {
+		candidates := variables[c.Variable]
+		literals := map[string]bool{}
+		var patterns []*regexp.Regexp
+		for _, rule := range c.Rules {
+			applies := rule.appliesTo(u.UserID, u.Teams)
+			for _, value := range rule.Values {
+				if isRegex(value) {
+					if !applies {
+						continue
+					}
+					re, err := compileValue(value, teamNames)
+					if err != nil {
+						return nil, err
+					}
+					patterns = append(patterns, re)
+					continue
+				}
+				candidates = append(candidates, expandLiteral(value, allTeamNames)...)
+				if applies {
+					for _, v := range expandLiteral(value, teamNames) {
+						literals[v] = true
+					}
+				}
+			}
+		}
+
+	candidates:
+		for _, value := range candidates {
+			if value == "" || value == allValue || literals[value] {
+				continue
+			}
+			for _, re := range patterns {
+				if re.MatchString(value) {
+					continue candidates
+				}
+			}
+			denied[value] = c.Variable
+		}
+	}
+	return denied, nil
+}
+
+func (s *ConstraintService) getDashboard(ctx context.Context, orgID int64, uid string) (*models.Dashboard, error) {
+	query := &models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if err := s.dashboardService.GetDashboard(ctx, query); err != nil {
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (s *ConstraintService) handleDashboardDeleted(ctx context.Context, e *events.DashboardDeleted) error {
+	if err := s.replaceConstraints(ctx, e.OrgID, e.UID, nil); err != nil {
+		s.log.Error("Failed to delete variable constraints of deleted dashboard", "orgId", e.OrgID, "dashboardUid", e.UID, "error", err)
+	}
+	return nil
+}
+
+// templateVariables returns the variables of a dashboard with the values of their options and their
+// current value.
+func templateVariables(dashboard *simplejson.Json) map[string][]string {
+	variables := map[string][]string{}
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+		var values []string
+		for _, option := range variable.Get("options").MustArray() {
+			values = append(values, optionValues(simplejson.NewFromAny(option).Get("value"))...)
+		}
+		values = append(values, optionValues(variable.GetPath("current", "value"))...)
+		variables[name] = values
+	}
+	return variables
+}
+
+// optionValues returns the value of an option, which is a list for multi-value variables.
+func optionValues(value *simplejson.Json) []string {
+	if s, err := value.String(); err == nil {
+		return []string{s}
+	}
+	var values []string
+	for _, v := range value.MustArray() {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+func userTeamNames(u *user.SignedInUser, teams map[int64]string) []string {
+	names := make([]string, 0, len(u.Teams))
+	for _, id := range u.Teams {
+		if name, ok := teams[id]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findDenied returns a denied value found in the strings of a query.
+func findDenied(value interface{}, denied map[string]string) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		for d := range denied {
+			if containsToken(v, d) || containsToken(v, regexp.QuoteMeta(d)) {
+				return d, true
+			}
+		}
+	case map[string]interface{}:
+		for key, nested := range v {
+			if queryKeys[key] {
+				continue
+			}
+			if d, ok := findDenied(nested, denied); ok {
+				return d, true
+			}
+		}
+	case []interface{}:
+		for _, nested := range v {
+			if d, ok := findDenied(nested, denied); ok {
+				return d, true
+			}
+		}
+	}
+	return "", false
+}
+
+// containsToken returns true if s contains the token, not as part of a longer word, so that the
+// value `prod` is not found in `prod-eu` or `team.prod`.
+func containsToken(s, token string) bool {
+	for offset := 0; offset < len(s); {
+		i := strings.Index(s[offset:], token)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(token)
+		if (start == 0 || !isWordByte(s[start-1])) && (end == len(s) || !isWordByte(s[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' ||
+		b == '_' || b == '-' || b == '.' || b >= 0x80
+}
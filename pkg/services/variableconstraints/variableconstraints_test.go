@@ -0,0 +1,186 @@
+package variableconstraints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestIntegrationVariableConstraints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	var teamA, teamB models.Team
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		teamA = models.Team{OrgId: 1, Name: "team-a", Created: time.Now(), Updated: time.Now()}
+		teamB = models.Team{OrgId: 1, Name: "team-b", Created: time.Now(), Updated: time.Now()}
+		if _, err := sess.Insert(&teamA, &teamB); err != nil {
+			return err
+		}
+		_, err := sess.Insert(&org.OrgUser{OrgID: 1, UserID: 10, Role: org.RoleViewer, Created: time.Now(), Updated: time.Now()})
+		return err
+	})
+	require.NoError(t, err)
+
+	dash := &models.Dashboard{Id: 1, OrgId: 1, Uid: "k8s", Title: "Kubernetes", Data: simplejson.NewFromAny(map[string]interface{}{
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{
+					"name":    "namespace",
+					"current": map[string]interface{}{"value": []interface{}{"team-a"}},
+					"options": []interface{}{
+						map[string]interface{}{"value": "$__all"},
+						map[string]interface{}{"value": "team-a"},
+						map[string]interface{}{"value": "team-b"},
+						map[string]interface{}{"value": "shared"},
+						map[string]interface{}{"value": "kube-system"},
+					},
+				},
+				map[string]interface{}{"name": "pod", "current": map[string]interface{}{"value": "web-1"}},
+			},
+		},
+	})}
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(func(_ context.Context, query *models.GetDashboardQuery) error {
+		if query.Uid != dash.Uid {
+			return dashboards.ErrDashboardNotFound
+		}
+		query.Result = dash
+		return nil
+	}).Maybe()
+
+	eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	s := ProvideService(sqlStore, eventBus, dashboardService, routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	set := func(constraints ...ConstraintDTO) error {
+		_, err := s.SetConstraints(ctx, SetConstraintsCommand{OrgID: 1, DashboardUID: "k8s", UserID: 1, Constraints: constraints})
+		return err
+	}
+
+	t.Run("invalid constraints are rejected", func(t *testing.T) {
+		for name, c := range map[string]ConstraintDTO{
+			"unknown variable":  {Variable: "cluster", Rules: []Rule{{Values: []string{"a"}}}},
+			"no rules":          {Variable: "namespace"},
+			"no values":         {Variable: "namespace", Rules: []Rule{{TeamID: teamA.Id}}},
+			"user and team":     {Variable: "namespace", Rules: []Rule{{TeamID: teamA.Id, UserID: 10, Values: []string{"a"}}}},
+			"invalid regex":     {Variable: "namespace", Rules: []Rule{{Values: []string{"/team-(/"}}}},
+			"unknown team":      {Variable: "namespace", Rules: []Rule{{TeamID: 999, Values: []string{"a"}}}},
+			"user not in org":   {Variable: "namespace", Rules: []Rule{{UserID: 999, Values: []string{"a"}}}},
+			"empty value":       {Variable: "namespace", Rules: []Rule{{Values: []string{""}}}},
+			"missing variable":  {Rules: []Rule{{Values: []string{"a"}}}},
+			"constrained twice": {Variable: "pod", Rules: []Rule{{Values: []string{"a"}}}},
+		} {
+			constraints := []ConstraintDTO{c}
+			if name == "constrained twice" {
+				constraints = append(constraints, c)
+			}
+			err := set(constraints...)
+			require.ErrorIs(t, err, ErrInvalidConstraints, name)
+		}
+
+		_, err := s.SetConstraints(ctx, SetConstraintsCommand{OrgID: 1, DashboardUID: "unknown"})
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+	})
+
+	require.NoError(t, set(ConstraintDTO{Variable: "namespace", Rules: []Rule{
+		{Values: []string{TeamPlaceholder}},
+		{UserID: 10, Values: []string{"/shar.*/"}},
+	}}))
+
+	constraints, err := s.GetConstraints(ctx, 1, "k8s")
+	require.NoError(t, err)
+	require.Len(t, constraints, 1)
+	assert.Equal(t, "namespace", constraints[0].Variable)
+	assert.Len(t, constraints[0].Rules, 2)
+
+	memberOfA := &user.SignedInUser{UserID: 11, OrgID: 1, OrgRole: org.RoleViewer, Teams: []int64{teamA.Id}}
+	user10 := &user.SignedInUser{UserID: 10, OrgID: 1, OrgRole: org.RoleViewer}
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin}
+
+	t.Run("allowed values are expanded for the user", func(t *testing.T) {
+		allowed, err := s.GetAllowedValues(ctx, memberOfA, "k8s")
+		require.NoError(t, err)
+		require.Equal(t, []AllowedValues{{Variable: "namespace", Values: []string{"team-a"}, Patterns: []string{}}}, allowed)
+
+		allowed, err = s.GetAllowedValues(ctx, user10, "k8s")
+		require.NoError(t, err)
+		require.Equal(t, []AllowedValues{{Variable: "namespace", Values: []string{}, Patterns: []string{"^(?:shar.*)$"}}}, allowed)
+
+		allowed, err = s.GetAllowedValues(ctx, admin, "k8s")
+		require.NoError(t, err)
+		require.Empty(t, allowed)
+	})
+
+	queries := func(expr string) []*simplejson.Json {
+		return []*simplejson.Json{simplejson.NewFromAny(map[string]interface{}{
+			"refId":      "A",
+			"datasource": map[string]interface{}{"uid": "team-b"},
+			"expr":       expr,
+		})}
+	}
+
+	t.Run("queries with values the user is not allowed to select are rejected", func(t *testing.T) {
+		for expr, allowed := range map[string]bool{
+			`up{namespace="team-a"}`:              true,
+			`up{namespace="team-b"}`:              false,
+			`up{namespace=~"(team-a|team-b)"}`:    false,
+			`up{namespace=~"team-a|kube-system"}`: false,
+			`up{namespace="team-b-canary"}`:       true,
+			`up{namespace="shared"}`:              false,
+			`up{pod="web-1"}`:                     true,
+		} {
+			err := s.ValidateQueries(ctx, memberOfA, "k8s", queries(expr))
+			if allowed {
+				require.NoError(t, err, expr)
+			} else {
+				require.ErrorIs(t, err, ErrValueNotAllowed, expr)
+			}
+		}
+
+		require.NoError(t, s.ValidateQueries(ctx, user10, "k8s", queries(`up{namespace="shared"}`)))
+		require.ErrorIs(t, s.ValidateQueries(ctx, user10, "k8s", queries(`up{namespace="team-a"}`)), ErrValueNotAllowed)
+	})
+
+	t.Run("administrators and queries of other dashboards are not restricted", func(t *testing.T) {
+		require.NoError(t, s.ValidateQueries(ctx, admin, "k8s", queries(`up{namespace="team-b"}`)))
+		require.NoError(t, s.ValidateQueries(ctx, memberOfA, "", queries(`up{namespace="team-b"}`)))
+		require.NoError(t, s.ValidateQueries(ctx, memberOfA, "other", queries(`up{namespace="team-b"}`)))
+	})
+
+	t.Run("constraints are deleted with their dashboard", func(t *testing.T) {
+		require.NoError(t, eventBus.Publish(ctx, &events.DashboardDeleted{OrgID: 1, UID: "k8s"}))
+		constraints, err := s.listConstraints(ctx, 1, "k8s")
+		require.NoError(t, err)
+		require.Empty(t, constraints)
+	})
+}
+
+func TestContainsToken(t *testing.T) {
+	assert.True(t, containsToken(`{namespace="prod"}`, "prod"))
+	assert.True(t, containsToken("prod", "prod"))
+	assert.True(t, containsToken("a|prod|b", "prod"))
+	assert.True(t, containsToken("prod-eu prod", "prod"))
+	assert.False(t, containsToken("prod-eu", "prod"))
+	assert.False(t, containsToken("team.prod", "prod"))
+	assert.False(t, containsToken("production", "prod"))
+	assert.False(t, containsToken("", "prod"))
+}
@@ -0,0 +1,314 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *WebhookService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	canRead := authorize(middleware.ReqOrgAdmin, ac.EvalPermission(ac.ActionWebhooksRead))
+	canWrite := authorize(middleware.ReqOrgAdmin, ac.EvalPermission(ac.ActionWebhooksWrite))
+
+	s.routeRegister.Group("/api/webhooks", func(webhooks routing.RouteRegister) {
+		webhooks.Get("/", canRead, routing.Wrap(s.getWebhooksHandler))
+		webhooks.Post("/", canWrite, routing.Wrap(s.createWebhookHandler))
+		webhooks.Get("/:uid", canRead, routing.Wrap(s.getWebhookHandler))
+		webhooks.Put("/:uid", canWrite, routing.Wrap(s.updateWebhookHandler))
+		webhooks.Delete("/:uid", canWrite, routing.Wrap(s.deleteWebhookHandler))
+		webhooks.Post("/:uid/ping", canWrite, routing.Wrap(s.pingWebhookHandler))
+		webhooks.Get("/:uid/deliveries", canRead, routing.Wrap(s.getDeliveriesHandler))
+		webhooks.Get("/:uid/deliveries/:deliveryUid", canRead, routing.Wrap(s.getDeliveryHandler))
+		webhooks.Post("/:uid/deliveries/:deliveryUid/redeliver", canWrite, routing.Wrap(s.redeliverHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /webhooks webhooks getWebhooks
+//
+// Get all webhooks of the current organization.
+//
+// Responses:
+// 200: getWebhooksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *WebhookService) getWebhooksHandler(c *models.ReqContext) response.Response {
+	webhooks, err := s.GetWebhooks(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, webhooks)
+}
+
+// swagger:route POST /webhooks webhooks createWebhook
+//
+// Create a webhook.
+//
+// The response contains the secret the deliveries are signed with. It is not returned again.
+//
+// Responses:
+// 200: createWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *WebhookService) createWebhookHandler(c *models.ReqContext) response.Response {
+	cmd := CreateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+
+	result, err := s.CreateWebhook(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /webhooks/{webhook_uid} webhooks getWebhook
+//
+// Get a webhook by UID.
+//
+// Responses:
+// 200: getWebhookResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhookService) getWebhookHandler(c *models.ReqContext) response.Response {
+	webhook, err := s.GetWebhook(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route PUT /webhooks/{webhook_uid} webhooks updateWebhook
+//
+// Update a webhook.
+//
+// The secret of the webhook is only changed if the request sets it.
+//
+// Responses:
+// 200: getWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhookService) updateWebhookHandler(c *models.ReqContext) response.Response {
+	cmd := UpdateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgID
+	cmd.UID = web.Params(c.Req)[":uid"]
+
+	webhook, err := s.UpdateWebhook(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route DELETE /webhooks/{webhook_uid} webhooks deleteWebhook
+//
+// Delete a webhook together with its delivery log.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhookService) deleteWebhookHandler(c *models.ReqContext) response.Response {
+	if err := s.DeleteWebhook(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err)
+	}
+	return response.Success("Webhook deleted")
+}
+
+// swagger:route POST /webhooks/{webhook_uid}/ping webhooks pingWebhook
+//
+// Send a ping event to a webhook.
+//
+// The ping event is sent regardless of the events the webhook subscribes to. The result of the delivery is shown in the delivery log.
+//
+// Responses:
+// 200: webhookDeliveryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhookService) pingWebhookHandler(c *models.ReqContext) response.Response {
+	delivery, err := s.Ping(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
+
+// swagger:route GET /webhooks/{webhook_uid}/deliveries webhooks getWebhookDeliveries
+//
+// Get the delivery log of a webhook, newest first.
+//
+// Responses:
+// 200: getWebhookDeliveriesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhookService) getDeliveriesHandler(c *models.ReqContext) response.Response {
+	deliveries, err := s.GetDeliveries(c.Req.Context(), DeliveriesQuery{
+		OrgID:      c.OrgID,
+		WebhookUID: web.Params(c.Req)[":uid"],
+		State:      DeliveryState(c.Query("state")),
+		Limit:      c.QueryInt("limit"),
+	})
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, deliveries)
+}
+
+// swagger:route GET /webhooks/{webhook_uid}/deliveries/{delivery_uid} webhooks getWebhookDelivery
+//
+// Get a delivery of a webhook together with its payload.
+//
+// Responses:
+// 200: getWebhookDeliveryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhookService) getDeliveryHandler(c *models.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	delivery, err := s.GetDelivery(c.Req.Context(), c.OrgID, params[":uid"], params[":deliveryUid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
+
+// swagger:route POST /webhooks/{webhook_uid}/deliveries/{delivery_uid}/redeliver webhooks redeliverWebhookDelivery
+//
+// Send the payload of a delivery again.
+//
+// The payload is sent as a new delivery, which is returned.
+//
+// Responses:
+// 200: webhookDeliveryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhookService) redeliverHandler(c *models.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	delivery, err := s.Redeliver(c.Req.Context(), c.OrgID, params[":uid"], params[":deliveryUid"])
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrWebhookNotFound):
+		return response.Error(http.StatusNotFound, "Webhook not found", err)
+	case errors.Is(err, ErrDeliveryNotFound):
+		return response.Error(http.StatusNotFound, "Delivery not found", err)
+	case errors.Is(err, ErrInvalidWebhook):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrWebhookUIDExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process webhook", err)
+}
+
+// swagger:parameters getWebhook updateWebhook deleteWebhook pingWebhook getWebhookDeliveries
+type WebhookUIDParams struct {
+	// in:path
+	// required:true
+	WebhookUID string `json:"webhook_uid"`
+}
+
+// swagger:parameters getWebhookDelivery redeliverWebhookDelivery
+type WebhookDeliveryParams struct {
+	// in:path
+	// required:true
+	WebhookUID string `json:"webhook_uid"`
+	// in:path
+	// required:true
+	DeliveryUID string `json:"delivery_uid"`
+}
+
+// swagger:parameters getWebhookDeliveries
+type GetWebhookDeliveriesParams struct {
+	// Only return deliveries in this state, pending, succeeded or failed.
+	// in:query
+	// required:false
+	State string `json:"state"`
+	// in:query
+	// required:false
+	// default:50
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters createWebhook
+type CreateWebhookParams struct {
+	// in:body
+	// required:true
+	Body CreateWebhookCommand `json:"body"`
+}
+
+// swagger:parameters updateWebhook
+type UpdateWebhookParams struct {
+	// in:body
+	// required:true
+	Body UpdateWebhookCommand `json:"body"`
+}
+
+// swagger:response getWebhooksResponse
+type GetWebhooksResponse struct {
+	// in:body
+	Body []*Webhook `json:"body"`
+}
+
+// swagger:response getWebhookResponse
+type GetWebhookResponse struct {
+	// in:body
+	Body *Webhook `json:"body"`
+}
+
+// swagger:response createWebhookResponse
+type CreateWebhookResponse struct {
+	// in:body
+	Body *CreateWebhookResult `json:"body"`
+}
+
+// swagger:response getWebhookDeliveriesResponse
+type GetWebhookDeliveriesResponse struct {
+	// in:body
+	Body []*Delivery `json:"body"`
+}
+
+// swagger:response getWebhookDeliveryResponse
+type GetWebhookDeliveryResponse struct {
+	// in:body
+	Body *DeliveryDTO `json:"body"`
+}
+
+// swagger:response webhookDeliveryResponse
+type WebhookDeliveryResponse struct {
+	// in:body
+	Body *Delivery `json:"body"`
+}
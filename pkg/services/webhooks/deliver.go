@@ -0,0 +1,179 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Grafana-Event"
+	DeliveryHeader  = "X-Grafana-Delivery"
+	TimestampHeader = "X-Grafana-Timestamp"
+	SignatureHeader = "X-Grafana-Signature"
+
+	// initialBackoff is the delay before the first retry of a delivery. It doubles with every
+	// failed attempt, up to maxBackoff.
+	initialBackoff = 30 * time.Second
+	maxBackoff     = time.Hour
+
+	maxErrorLength = 1024
+)
+
+var errWebhookDisabled = errors.New("webhook is disabled")
+
+// Sign returns the signature of a request body sent at the unix timestamp. It is the hex encoded
+// HMAC-SHA256 of the timestamp and the body joined by a dot, keyed with the webhook secret.
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the delay before the next attempt of a delivery that failed the given number
+// of times.
+func backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// sendDueDeliveries sends the pending deliveries whose next attempt is due, in batches until
+// none are left.
+func (s *WebhookService) sendDueDeliveries(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := s.dueDeliveries(ctx, s.now(), deliveryBatchSize)
+		if err != nil {
+			s.log.Error("Failed to get due webhook deliveries", "error", err)
+			return
+		}
+
+		sem := make(chan struct{}, maxConcurrentDeliveries)
+		var wg sync.WaitGroup
+		for _, d := range due {
+			claimed, err := s.claimDelivery(ctx, d, s.now().Add(s.cfg.Webhooks.Timeout+leaseMargin))
+			if err != nil {
+				s.log.Error("Failed to claim webhook delivery", "delivery", d.UID, "error", err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(d *Delivery) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				s.attempt(ctx, d)
+			}(d)
+		}
+		wg.Wait()
+
+		if len(due) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+// attempt sends a claimed delivery and stores the result. Failed deliveries are retried with
+// an exponential backoff until the maximum number of attempts is reached.
+func (s *WebhookService) attempt(ctx context.Context, d *Delivery) {
+	status, err := s.send(ctx, d)
+	if errors.Is(err, ErrWebhookNotFound) {
+		// the deliveries were deleted together with the webhook
+		return
+	}
+
+	d.ResponseStatus = status
+	d.Updated = s.now()
+	switch {
+	case err == nil:
+		d.State = DeliverySucceeded
+		d.Error = ""
+	case errors.Is(err, errWebhookDisabled) || d.Attempts >= s.cfg.Webhooks.MaxAttempts:
+		d.State = DeliveryFailed
+		d.Error = truncate(err.Error())
+	default:
+		d.State = DeliveryPending
+		d.Error = truncate(err.Error())
+		d.NextAttemptAt = d.Updated.Add(backoff(d.Attempts))
+	}
+
+	if err != nil {
+		s.log.Debug("Webhook delivery failed", "delivery", d.UID, "event", d.Event, "attempts", d.Attempts, "state", d.State, "error", err)
+	}
+	if err := s.finishAttempt(ctx, d); err != nil {
+		s.log.Error("Failed to store webhook delivery attempt", "delivery", d.UID, "error", err)
+	}
+}
+
+// send posts the payload of a delivery to the URL of its webhook and returns the response
+// status code.
+func (s *WebhookService) send(ctx context.Context, d *Delivery) (int, error) {
+	webhook, err := s.getWebhookByID(ctx, d.WebhookID)
+	if err != nil {
+		return 0, err
+	}
+	if !webhook.Enabled {
+		return 0, errWebhookDisabled
+	}
+	secret, err := s.decryptSecret(ctx, webhook)
+	if err != nil {
+		return 0, err
+	}
+
+	body := []byte(d.Payload)
+	timestamp := s.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.UID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+	return s
+}
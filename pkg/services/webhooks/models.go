@@ -0,0 +1,240 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	maxNameLength = 190
+	maxURLLength  = 1024
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrWebhookUIDExists = errors.New("a webhook with the same uid already exists")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+)
+
+// Events webhooks can subscribe to. Webhooks subscribe to all events of a resource with a
+// wildcard such as `dashboard.*`, or to all events with `*`.
+const (
+	EventDashboardSaved     = "dashboard.saved"
+	EventDashboardDeleted   = "dashboard.deleted"
+	EventDataSourceCreated  = "datasource.created"
+	EventDataSourceUpdated  = "datasource.updated"
+	EventDataSourceDeleted  = "datasource.deleted"
+	EventOrgUserAdded       = "org.user_added"
+	EventOrgUserRoleChanged = "org.user_role_changed"
+	EventOrgUserRemoved     = "org.user_removed"
+	EventAlertRuleCreated   = "alert_rule.created"
+	EventAlertRuleUpdated   = "alert_rule.updated"
+	EventAlertRuleDeleted   = "alert_rule.deleted"
+
+	// EventPing is sent to test a webhook, regardless of the events it subscribes to.
+	EventPing = "ping"
+)
+
+var eventTypes = []string{
+	EventDashboardSaved, EventDashboardDeleted,
+	EventDataSourceCreated, EventDataSourceUpdated, EventDataSourceDeleted,
+	EventOrgUserAdded, EventOrgUserRoleChanged, EventOrgUserRemoved,
+	EventAlertRuleCreated, EventAlertRuleUpdated, EventAlertRuleDeleted,
+}
+
+// isValidEventPattern returns whether an event a webhook subscribes to is a known event or
+// matches at least one known event.
+func isValidEventPattern(pattern string) bool {
+	for _, event := range eventTypes {
+		if matchEvent(pattern, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchEvent returns whether the event matches a pattern a webhook subscribes to.
+func matchEvent(pattern, event string) bool {
+	if pattern == "*" || pattern == event {
+		return true
+	}
+	if strings.HasSuffix(pattern, ".*") {
+		return strings.HasPrefix(event, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// Webhook is an HTTP endpoint notified of changes to the resources of an organization.
+// swagger:model
+type Webhook struct {
+	ID    int64  `json:"-" xorm:"pk autoincr 'id'"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	UID   string `json:"uid" xorm:"uid"`
+	Name  string `json:"name" xorm:"name"`
+	URL   string `json:"url" xorm:"url"`
+	// Events the webhook subscribes to.
+	Events []string `json:"events" xorm:"jsonb events"`
+	// Secret is the key deliveries are signed with, encrypted with the secrets service.
+	Secret  string    `json:"-" xorm:"secret"`
+	Enabled bool      `json:"enabled" xorm:"enabled"`
+	Created time.Time `json:"created" xorm:"created"`
+	Updated time.Time `json:"updated" xorm:"updated"`
+}
+
+func (w Webhook) TableName() string {
+	return "webhook"
+}
+
+// subscribes returns whether the webhook is notified of the event.
+func (w *Webhook) subscribes(event string) bool {
+	for _, pattern := range w.Events {
+		if matchEvent(pattern, event) {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryState string
+
+const (
+	// DeliveryPending deliveries are sent, or retried, once their next attempt is due.
+	DeliveryPending   DeliveryState = "pending"
+	DeliverySucceeded DeliveryState = "succeeded"
+	// DeliveryFailed deliveries were not accepted by the endpoint after the maximum number of
+	// attempts, or their webhook was disabled.
+	DeliveryFailed DeliveryState = "failed"
+)
+
+// Delivery is a single event sent to a webhook, together with the result of the last attempt.
+// swagger:model WebhookDelivery
+type Delivery struct {
+	ID        int64         `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID     int64         `json:"-" xorm:"org_id"`
+	WebhookID int64         `json:"-" xorm:"webhook_id"`
+	UID       string        `json:"uid" xorm:"uid"`
+	Event     string        `json:"event" xorm:"event"`
+	Payload   string        `json:"-" xorm:"payload"`
+	State     DeliveryState `json:"state" xorm:"state"`
+	Attempts  int           `json:"attempts" xorm:"attempts"`
+	// NextAttemptAt is when a pending delivery is sent next.
+	NextAttemptAt  time.Time `json:"nextAttemptAt" xorm:"next_attempt_at"`
+	ResponseStatus int       `json:"responseStatus,omitempty" xorm:"response_status"`
+	Error          string    `json:"error,omitempty" xorm:"error"`
+	Created        time.Time `json:"created" xorm:"created"`
+	Updated        time.Time `json:"updated" xorm:"updated"`
+}
+
+func (d Delivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// DeliveryDTO is a delivery together with the body of its requests.
+// swagger:model WebhookDeliveryDTO
+type DeliveryDTO struct {
+	*Delivery
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Payload is the body of the requests sent to webhooks.
+type Payload struct {
+	Event     string    `json:"event"`
+	OrgID     int64     `json:"orgId"`
+	Timestamp time.Time `json:"timestamp"`
+	// Data describes the changed resource. Its fields depend on the event.
+	Data interface{} `json:"data"`
+}
+
+// CreateWebhookCommand is the body of the request creating a webhook.
+// swagger:model
+type CreateWebhookCommand struct {
+	OrgID int64 `json:"-"`
+	// UID is generated if it is not set.
+	UID string `json:"uid"`
+	// required:true
+	Name string `json:"name"`
+	// required:true
+	URL string `json:"url"`
+	// Events the webhook subscribes to, e.g. `dashboard.saved`, `dashboard.*` or `*`.
+	// required:true
+	Events []string `json:"events"`
+	// Secret the deliveries are signed with. A random secret is generated if it is not set.
+	Secret string `json:"secret"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+// UpdateWebhookCommand is the body of the request updating a webhook.
+// swagger:model
+type UpdateWebhookCommand struct {
+	OrgID int64  `json:"-"`
+	UID   string `json:"-"`
+	// required:true
+	Name string `json:"name"`
+	// required:true
+	URL string `json:"url"`
+	// required:true
+	Events []string `json:"events"`
+	// Secret replaces the secret of the webhook if it is set.
+	Secret  string `json:"secret"`
+	Enabled bool   `json:"enabled"`
+}
+
+// CreateWebhookResult is a created webhook together with its secret. The secret is not
+// returned again.
+// swagger:model
+type CreateWebhookResult struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// DeliveriesQuery lists the deliveries of a webhook, newest first.
+type DeliveriesQuery struct {
+	OrgID      int64
+	WebhookUID string
+	State      DeliveryState
+	Limit      int
+}
+
+func validateWebhook(name, rawURL string, events []string) error {
+	if strings.TrimSpace(name) == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return fmt.Errorf("%w: name is required and must be at most %d characters", ErrInvalidWebhook, maxNameLength)
+	}
+	if len(rawURL) > maxURLLength {
+		return fmt.Errorf("%w: url must be at most %d characters", ErrInvalidWebhook, maxURLLength)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("%w: events are required", ErrInvalidWebhook)
+	}
+	for _, event := range events {
+		if !isValidEventPattern(event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+	}
+	return nil
+}
+
+func (cmd *CreateWebhookCommand) validate() error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if cmd.UID != "" && !util.IsValidShortUID(cmd.UID) {
+		return fmt.Errorf("%w: uid contains invalid characters or is too long", ErrInvalidWebhook)
+	}
+	return validateWebhook(cmd.Name, cmd.URL, cmd.Events)
+}
+
+func (cmd *UpdateWebhookCommand) validate() error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	return validateWebhook(cmd.Name, cmd.URL, cmd.Events)
+}
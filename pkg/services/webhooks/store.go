@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *WebhookService) insertWebhook(ctx context.Context, webhook *Webhook) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", webhook.OrgID, webhook.UID).Exist(&Webhook{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrWebhookUIDExists
+		}
+
+		if _, err := sess.Insert(webhook); err != nil {
+			if s.store.GetDialect().IsUniqueConstraintViolation(err) {
+				return ErrWebhookUIDExists
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *WebhookService) getWebhook(ctx context.Context, orgID int64, uid string) (*Webhook, error) {
+	webhook := &Webhook{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(webhook)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+	return webhook, err
+}
+
+func (s *WebhookService) getWebhookByID(ctx context.Context, id int64) (*Webhook, error) {
+	webhook := &Webhook{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.ID(id).Get(webhook)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+	return webhook, err
+}
+
+func (s *WebhookService) listWebhooks(ctx context.Context, orgID int64, enabledOnly bool) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", orgID)
+		if enabledOnly {
+			q = q.And("enabled = ?", s.store.GetDialect().BooleanStr(true))
+		}
+		return q.Asc("name", "id").Find(&webhooks)
+	})
+	return webhooks, err
+}
+
+func (s *WebhookService) updateWebhook(ctx context.Context, webhook *Webhook) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		updated, err := sess.ID(webhook.ID).UseBool("enabled").
+			Cols("name", "url", "events", "secret", "enabled", "updated").Update(webhook)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+}
+
+func (s *WebhookService) deleteWebhook(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		webhook := &Webhook{}
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(webhook)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrWebhookNotFound
+		}
+
+		if _, err := sess.Exec("DELETE FROM webhook_delivery WHERE webhook_id = ?", webhook.ID); err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM webhook WHERE id = ?", webhook.ID)
+		return err
+	})
+}
+
+func (s *WebhookService) insertDeliveries(ctx context.Context, deliveries []*Delivery) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, d := range deliveries {
+			if _, err := sess.Insert(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *WebhookService) getDelivery(ctx context.Context, webhookID int64, uid string) (*Delivery, error) {
+	delivery := &Delivery{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("webhook_id = ? AND uid = ?", webhookID, uid).Get(delivery)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrDeliveryNotFound
+		}
+		return nil
+	})
+	return delivery, err
+}
+
+func (s *WebhookService) listDeliveries(ctx context.Context, webhookID int64, state DeliveryState, limit int) ([]*Delivery, error) {
+	deliveries := make([]*Delivery, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("webhook_id = ?", webhookID)
+		if state != "" {
+			q = q.And("state = ?", state)
+		}
+		return q.Omit("payload").Desc("id").Limit(limit).Find(&deliveries)
+	})
+	return deliveries, err
+}
+
+// dueDeliveries returns the pending deliveries whose next attempt is due, oldest first.
+func (s *WebhookService) dueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error) {
+	deliveries := make([]*Delivery, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("state = ? AND next_attempt_at <= ?", DeliveryPending, now).
+			Asc("next_attempt_at", "id").Limit(limit).Find(&deliveries)
+	})
+	return deliveries, err
+}
+
+// claimDelivery counts an attempt of a due delivery and postpones its next attempt until
+// leaseUntil, so it is not sent again while the attempt is in progress. It returns false if
+// the delivery was claimed by another instance in the meantime.
+func (s *WebhookService) claimDelivery(ctx context.Context, d *Delivery, leaseUntil time.Time) (bool, error) {
+	var claimed bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE webhook_delivery SET attempts = attempts + 1, next_attempt_at = ? WHERE id = ? AND state = ? AND attempts = ?",
+			leaseUntil, d.ID, DeliveryPending, d.Attempts)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		claimed = affected == 1
+		return nil
+	})
+	if claimed {
+		d.Attempts++
+		d.NextAttemptAt = leaseUntil
+	}
+	return claimed, err
+}
+
+// finishAttempt stores the result of an attempt.
+func (s *WebhookService) finishAttempt(ctx context.Context, d *Delivery) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(d.ID).MustCols("response_status", "error").
+			Cols("state", "next_attempt_at", "response_status", "error", "updated").Update(d)
+		return err
+	})
+}
+
+func (s *WebhookService) deleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM webhook_delivery WHERE created < ? AND state <> ?", before, DeliveryPending)
+		if err != nil {
+			return err
+		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return removed, err
+}
@@ -0,0 +1,380 @@
+// Package webhooks notifies HTTP endpoints of changes to the resources of an organization.
+//
+// Organization admins subscribe webhooks to events such as saved dashboards or updated data
+// sources. Every event a webhook subscribes to is stored as a delivery and sent by a background
+// worker, which retries failed deliveries with an exponential backoff. The deliveries are signed
+// with the secret of the webhook and kept in a delivery log for inspection and redelivery.
+package webhooks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	pollInterval    = 10 * time.Second
+	cleanupInterval = time.Hour
+
+	// deliveryBatchSize is the number of due deliveries fetched at once.
+	deliveryBatchSize = 100
+	// maxConcurrentDeliveries is the number of deliveries sent in parallel by an instance.
+	maxConcurrentDeliveries = 10
+	// leaseMargin is added to the timeout of an attempt before another instance may retry it,
+	// in case the instance sending it stopped.
+	leaseMargin = time.Minute
+
+	defaultLimit = 50
+	maxLimit     = 500
+
+	secretLength = 32
+)
+
+type WebhookService struct {
+	cfg           *setting.Cfg
+	store         db.DB
+	log           log.Logger
+	secrets       secrets.Service
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	client        *http.Client
+
+	now  func() time.Time
+	wake chan struct{}
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, bus bus.Bus, secretsService secrets.Service,
+	accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *WebhookService {
+	s := &WebhookService{
+		cfg:           cfg,
+		store:         sqlStore,
+		log:           log.New("webhooks"),
+		secrets:       secretsService,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+		client:        &http.Client{Timeout: cfg.Webhooks.Timeout},
+		now:           time.Now,
+		wake:          make(chan struct{}, 1),
+	}
+
+	if cfg.Webhooks.Enabled {
+		s.registerAPIEndpoints()
+		s.registerEventListeners(bus)
+	}
+
+	return s
+}
+
+func (s *WebhookService) IsDisabled() bool {
+	return !s.cfg.Webhooks.Enabled
+}
+
+func (s *WebhookService) registerEventListeners(bus bus.Bus) {
+	bus.AddEventListener(func(ctx context.Context, e *events.DashboardSaved) error {
+		return s.handleEvent(ctx, e.OrgID, EventDashboardSaved, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DashboardDeleted) error {
+		return s.handleEvent(ctx, e.OrgID, EventDashboardDeleted, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceCreated) error {
+		return s.handleEvent(ctx, e.OrgID, EventDataSourceCreated, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceUpdated) error {
+		return s.handleEvent(ctx, e.OrgID, EventDataSourceUpdated, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.DataSourceDeleted) error {
+		return s.handleEvent(ctx, e.OrgID, EventDataSourceDeleted, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.OrgUserAdded) error {
+		return s.handleEvent(ctx, e.OrgID, EventOrgUserAdded, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.OrgUserRoleChanged) error {
+		return s.handleEvent(ctx, e.OrgID, EventOrgUserRoleChanged, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.OrgUserRemoved) error {
+		return s.handleEvent(ctx, e.OrgID, EventOrgUserRemoved, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.AlertRuleCreated) error {
+		return s.handleEvent(ctx, e.OrgID, EventAlertRuleCreated, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.AlertRuleUpdated) error {
+		return s.handleEvent(ctx, e.OrgID, EventAlertRuleUpdated, e.Timestamp, e)
+	})
+	bus.AddEventListener(func(ctx context.Context, e *events.AlertRuleDeleted) error {
+		return s.handleEvent(ctx, e.OrgID, EventAlertRuleDeleted, e.Timestamp, e)
+	})
+}
+
+// handleEvent queues a delivery of the event for every enabled webhook of the organization which
+// subscribes to it. Failures are only logged, so the listeners after it still receive the event.
+func (s *WebhookService) handleEvent(ctx context.Context, orgID int64, event string, timestamp time.Time, data interface{}) error {
+	if err := s.enqueue(ctx, orgID, event, timestamp, data); err != nil {
+		s.log.Error("Failed to queue webhook deliveries", "event", event, "orgId", orgID, "error", err)
+	}
+	return nil
+}
+
+func (s *WebhookService) enqueue(ctx context.Context, orgID int64, event string, timestamp time.Time, data interface{}) error {
+	webhooks, err := s.listWebhooks(ctx, orgID, true)
+	if err != nil {
+		return err
+	}
+
+	var subscribed []*Webhook
+	for _, webhook := range webhooks {
+		if webhook.subscribes(event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(Payload{Event: event, OrgID: orgID, Timestamp: timestamp, Data: data})
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]*Delivery, 0, len(subscribed))
+	for _, webhook := range subscribed {
+		deliveries = append(deliveries, s.newDelivery(webhook, event, string(payload)))
+	}
+	if err := s.insertDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+	s.wakeUp()
+	return nil
+}
+
+func (s *WebhookService) newDelivery(webhook *Webhook, event, payload string) *Delivery {
+	now := s.now()
+	return &Delivery{
+		OrgID:         webhook.OrgID,
+		WebhookID:     webhook.ID,
+		UID:           util.GenerateShortUID(),
+		Event:         event,
+		Payload:       payload,
+		State:         DeliveryPending,
+		NextAttemptAt: now,
+		Created:       now,
+		Updated:       now,
+	}
+}
+
+// wakeUp makes the worker send the due deliveries without waiting for the next poll.
+func (s *WebhookService) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, cmd CreateWebhookCommand) (*CreateWebhookResult, error) {
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	if cmd.Secret == "" {
+		secret, err := util.GetRandomString(secretLength)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Secret = secret
+	}
+	encryptedSecret, err := s.encryptSecret(ctx, cmd.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	webhook := &Webhook{
+		OrgID:   cmd.OrgID,
+		UID:     cmd.UID,
+		Name:    cmd.Name,
+		URL:     cmd.URL,
+		Events:  cmd.Events,
+		Secret:  encryptedSecret,
+		Enabled: cmd.Enabled == nil || *cmd.Enabled,
+		Created: now,
+		Updated: now,
+	}
+	if err := s.insertWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return &CreateWebhookResult{Webhook: webhook, Secret: cmd.Secret}, nil
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, orgID int64, uid string) (*Webhook, error) {
+	return s.getWebhook(ctx, orgID, uid)
+}
+
+func (s *WebhookService) GetWebhooks(ctx context.Context, orgID int64) ([]*Webhook, error) {
+	return s.listWebhooks(ctx, orgID, false)
+}
+
+func (s *WebhookService) UpdateWebhook(ctx context.Context, cmd UpdateWebhookCommand) (*Webhook, error) {
+	if err := cmd.validate(); err != nil {
+		return nil, err
+	}
+	webhook, err := s.getWebhook(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Secret != "" {
+		if webhook.Secret, err = s.encryptSecret(ctx, cmd.Secret); err != nil {
+			return nil, err
+		}
+	}
+	webhook.Name = cmd.Name
+	webhook.URL = cmd.URL
+	webhook.Events = cmd.Events
+	webhook.Enabled = cmd.Enabled
+	webhook.Updated = s.now()
+	if err := s.updateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook together with its deliveries.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, orgID int64, uid string) error {
+	return s.deleteWebhook(ctx, orgID, uid)
+}
+
+// Ping queues a ping event for a webhook, to test whether its endpoint accepts deliveries.
+func (s *WebhookService) Ping(ctx context.Context, orgID int64, uid string) (*Delivery, error) {
+	webhook, err := s.getWebhook(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(Payload{
+		Event:     EventPing,
+		OrgID:     orgID,
+		Timestamp: s.now(),
+		Data:      map[string]string{"uid": webhook.UID, "name": webhook.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := s.newDelivery(webhook, EventPing, string(payload))
+	if err := s.insertDeliveries(ctx, []*Delivery{delivery}); err != nil {
+		return nil, err
+	}
+	s.wakeUp()
+	return delivery, nil
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, query DeliveriesQuery) ([]*Delivery, error) {
+	webhook, err := s.getWebhook(ctx, query.OrgID, query.WebhookUID)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultLimit
+	}
+	if query.Limit > maxLimit {
+		query.Limit = maxLimit
+	}
+	return s.listDeliveries(ctx, webhook.ID, query.State, query.Limit)
+}
+
+func (s *WebhookService) GetDelivery(ctx context.Context, orgID int64, webhookUID, uid string) (*DeliveryDTO, error) {
+	webhook, err := s.getWebhook(ctx, orgID, webhookUID)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := s.getDelivery(ctx, webhook.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return &DeliveryDTO{Delivery: delivery, Payload: json.RawMessage(delivery.Payload)}, nil
+}
+
+// Redeliver queues a new delivery with the same payload as an earlier one.
+func (s *WebhookService) Redeliver(ctx context.Context, orgID int64, webhookUID, uid string) (*Delivery, error) {
+	webhook, err := s.getWebhook(ctx, orgID, webhookUID)
+	if err != nil {
+		return nil, err
+	}
+	original, err := s.getDelivery(ctx, webhook.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := s.newDelivery(webhook, original.Event, original.Payload)
+	if err := s.insertDeliveries(ctx, []*Delivery{delivery}); err != nil {
+		return nil, err
+	}
+	s.wakeUp()
+	return delivery, nil
+}
+
+func (s *WebhookService) encryptSecret(ctx context.Context, secret string) (string, error) {
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func (s *WebhookService) decryptSecret(ctx context.Context, webhook *Webhook) ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(webhook.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode webhook secret: %w", err)
+	}
+	secret, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Run sends the due deliveries and removes deliveries that are older than the configured
+// retention.
+func (s *WebhookService) Run(ctx context.Context) error {
+	pollTicker := time.NewTicker(pollInterval)
+	defer pollTicker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wake:
+			s.sendDueDeliveries(ctx)
+		case <-pollTicker.C:
+			s.sendDueDeliveries(ctx)
+		case <-cleanupTicker.C:
+			if s.cfg.Webhooks.DeliveryRetention <= 0 {
+				continue
+			}
+			removed, err := s.deleteDeliveriesBefore(ctx, s.now().Add(-s.cfg.Webhooks.DeliveryRetention))
+			if err != nil {
+				s.log.Error("Failed to clean up webhook deliveries", "error", err)
+				continue
+			}
+			if removed > 0 {
+				s.log.Debug("Cleaned up webhook deliveries", "removed", removed)
+			}
+		}
+	}
+}
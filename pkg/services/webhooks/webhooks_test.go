@@ -0,0 +1,241 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMatchEvent(t *testing.T) {
+	assert.True(t, matchEvent("*", EventDashboardSaved))
+	assert.True(t, matchEvent("dashboard.saved", EventDashboardSaved))
+	assert.True(t, matchEvent("dashboard.*", EventDashboardDeleted))
+	assert.False(t, matchEvent("dashboard.*", EventDataSourceCreated))
+	assert.False(t, matchEvent("dash*", EventDashboardSaved))
+
+	assert.True(t, isValidEventPattern("alert_rule.*"))
+	assert.False(t, isValidEventPattern("folder.*"))
+	assert.False(t, isValidEventPattern(EventPing))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1))
+	assert.Equal(t, time.Minute, backoff(2))
+	assert.Equal(t, 4*time.Minute, backoff(4))
+	assert.Equal(t, time.Hour, backoff(20))
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"event":"ping"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=4d39bd2442f073b6bc62e95d0297ce25475582a17389ab860abdc778fe1d9f77", Sign([]byte("secret"), 1700000000, []byte(`{"event":"ping"}`)))
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+func TestIntegrationWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	ctx := context.Background()
+
+	rcv := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(rcv)
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	cfg.Webhooks = setting.WebhooksSettings{Enabled: true, Timeout: 5 * time.Second, MaxAttempts: 2}
+	eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	s := ProvideService(cfg, sqlStore, eventBus, fakes.NewFakeSecretsService(), acimpl.ProvideAccessControl(cfg), routing.NewRouteRegister())
+	now := time.Now().Truncate(time.Second)
+	s.now = func() time.Time { return now }
+
+	deliveries := func(uid string, state DeliveryState) []*Delivery {
+		t.Helper()
+		result, err := s.GetDeliveries(ctx, DeliveriesQuery{OrgID: 1, WebhookUID: uid, State: state})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("validates webhooks", func(t *testing.T) {
+		for _, cmd := range []CreateWebhookCommand{
+			{Name: "", URL: server.URL, Events: []string{"*"}},
+			{Name: "no events", URL: server.URL},
+			{Name: "unknown event", URL: server.URL, Events: []string{"folder.saved"}},
+			{Name: "relative url", URL: "/hook", Events: []string{"*"}},
+			{Name: "other scheme", URL: "ftp://example.com", Events: []string{"*"}},
+		} {
+			_, err := s.CreateWebhook(ctx, cmd)
+			assert.ErrorIs(t, err, ErrInvalidWebhook, cmd.Name)
+		}
+	})
+
+	dashboards, err := s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 1, UID: "dashboards", Name: "Dashboards", URL: server.URL, Events: []string{"dashboard.*"}, Secret: "s3cret"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", dashboards.Secret)
+	assert.True(t, dashboards.Enabled)
+
+	disabled := false
+	_, err = s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 1, UID: "disabled", Name: "Disabled", URL: server.URL, Events: []string{"*"}, Enabled: &disabled})
+	require.NoError(t, err)
+
+	t.Run("rejects duplicate uids", func(t *testing.T) {
+		_, err := s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 1, UID: "dashboards", Name: "Other", URL: server.URL, Events: []string{"*"}})
+		assert.ErrorIs(t, err, ErrWebhookUIDExists)
+	})
+
+	t.Run("generates a secret", func(t *testing.T) {
+		result, err := s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 2, Name: "Generated", URL: server.URL, Events: []string{"*"}})
+		require.NoError(t, err)
+		assert.Len(t, result.Secret, secretLength)
+		assert.NotEmpty(t, result.UID)
+
+		webhook, err := s.GetWebhook(ctx, 2, result.UID)
+		require.NoError(t, err)
+		secret, err := s.decryptSecret(ctx, webhook)
+		require.NoError(t, err)
+		assert.Equal(t, result.Secret, string(secret))
+	})
+
+	t.Run("delivers subscribed events of the organization signed with the secret", func(t *testing.T) {
+		require.NoError(t, eventBus.Publish(ctx, &events.DashboardSaved{Timestamp: now, UID: "abc", OrgID: 1, Title: "Overview", Version: 2}))
+		require.NoError(t, eventBus.Publish(ctx, &events.DataSourceCreated{Timestamp: now, UID: "prom", OrgID: 1}))
+		require.NoError(t, eventBus.Publish(ctx, &events.DashboardSaved{Timestamp: now, UID: "abc", OrgID: 3}))
+		assert.Len(t, deliveries("dashboards", DeliveryPending), 1)
+		assert.Empty(t, deliveries("disabled", ""))
+
+		s.sendDueDeliveries(ctx)
+
+		require.Len(t, rcv.requests, 1)
+		req, body := rcv.requests[0], rcv.bodies[0]
+		assert.Equal(t, EventDashboardSaved, req.Header.Get(EventHeader))
+		assert.Equal(t, strconv.FormatInt(now.Unix(), 10), req.Header.Get(TimestampHeader))
+		assert.Equal(t, Sign([]byte("s3cret"), now.Unix(), body), req.Header.Get(SignatureHeader))
+
+		var payload struct {
+			Event string `json:"event"`
+			OrgID int64  `json:"orgId"`
+			Data  struct {
+				UID   string `json:"uid"`
+				Title string `json:"title"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, EventDashboardSaved, payload.Event)
+		assert.Equal(t, int64(1), payload.OrgID)
+		assert.Equal(t, "abc", payload.Data.UID)
+		assert.Equal(t, "Overview", payload.Data.Title)
+
+		succeeded := deliveries("dashboards", DeliverySucceeded)
+		require.Len(t, succeeded, 1)
+		assert.Equal(t, req.Header.Get(DeliveryHeader), succeeded[0].UID)
+		assert.Equal(t, 1, succeeded[0].Attempts)
+		assert.Equal(t, http.StatusOK, succeeded[0].ResponseStatus)
+
+		delivery, err := s.GetDelivery(ctx, 1, "dashboards", succeeded[0].UID)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(body), string(delivery.Payload))
+	})
+
+	t.Run("retries failed deliveries with backoff", func(t *testing.T) {
+		rcv.status = http.StatusInternalServerError
+		ping, err := s.Ping(ctx, 1, "dashboards")
+		require.NoError(t, err)
+
+		s.sendDueDeliveries(ctx)
+		delivery, err := s.GetDelivery(ctx, 1, "dashboards", ping.UID)
+		require.NoError(t, err)
+		assert.Equal(t, DeliveryPending, delivery.State)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+		assert.Equal(t, "endpoint responded with status 500", delivery.Error)
+		assert.True(t, now.Add(initialBackoff).Equal(delivery.NextAttemptAt))
+
+		// the next attempt is not due yet
+		s.sendDueDeliveries(ctx)
+		require.Len(t, rcv.requests, 2)
+
+		now = now.Add(initialBackoff)
+		s.sendDueDeliveries(ctx)
+		require.Len(t, rcv.requests, 3)
+		delivery, err = s.GetDelivery(ctx, 1, "dashboards", ping.UID)
+		require.NoError(t, err)
+		assert.Equal(t, DeliveryFailed, delivery.State)
+		assert.Equal(t, 2, delivery.Attempts)
+	})
+
+	t.Run("redelivers the payload of a delivery", func(t *testing.T) {
+		rcv.status = http.StatusNoContent
+		failed := deliveries("dashboards", DeliveryFailed)
+		require.Len(t, failed, 1)
+
+		redelivery, err := s.Redeliver(ctx, 1, "dashboards", failed[0].UID)
+		require.NoError(t, err)
+		assert.NotEqual(t, failed[0].UID, redelivery.UID)
+
+		s.sendDueDeliveries(ctx)
+		require.Len(t, rcv.requests, 4)
+		assert.Equal(t, rcv.bodies[2], rcv.bodies[3])
+		assert.Equal(t, redelivery.UID, rcv.requests[3].Header.Get(DeliveryHeader))
+	})
+
+	t.Run("fails deliveries of disabled webhooks", func(t *testing.T) {
+		ping, err := s.Ping(ctx, 1, "dashboards")
+		require.NoError(t, err)
+		_, err = s.UpdateWebhook(ctx, UpdateWebhookCommand{OrgID: 1, UID: "dashboards", Name: "Dashboards", URL: server.URL, Events: []string{"dashboard.*"}})
+		require.NoError(t, err)
+
+		s.sendDueDeliveries(ctx)
+		require.Len(t, rcv.requests, 4)
+		delivery, err := s.GetDelivery(ctx, 1, "dashboards", ping.UID)
+		require.NoError(t, err)
+		assert.Equal(t, DeliveryFailed, delivery.State)
+		assert.Equal(t, errWebhookDisabled.Error(), delivery.Error)
+	})
+
+	t.Run("deletes webhooks with their deliveries", func(t *testing.T) {
+		require.NoError(t, s.DeleteWebhook(ctx, 1, "dashboards"))
+		_, err := s.GetWebhook(ctx, 1, "dashboards")
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+		assert.ErrorIs(t, s.DeleteWebhook(ctx, 1, "dashboards"), ErrWebhookNotFound)
+
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			count, err := sess.Table("webhook_delivery").Count()
+			assert.Zero(t, count)
+			return err
+		})
+		require.NoError(t, err)
+	})
+}
@@ -32,14 +32,27 @@ var expanders = []registeredExpander{
 		priority: -5,
 		expander: fileExpander{},
 	},
+	{
+		name:     "vault",
+		priority: 0,
+		expander: &vaultExpander{},
+	},
 }
 
+// AddExpander registers an expander. An expander registered with the name of an existing one replaces it.
 func AddExpander(name string, priority int64, e Expander) {
-	expanders = append(expanders, registeredExpander{
+	registered := registeredExpander{
 		name:     name,
 		priority: priority,
 		expander: e,
-	})
+	}
+	for i, existing := range expanders {
+		if existing.name == name {
+			expanders[i] = registered
+			return
+		}
+	}
+	expanders = append(expanders, registered)
 }
 
 var regex = regexp.MustCompile(`\$(|__\w+){([^}]+)}`)
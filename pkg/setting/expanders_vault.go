@@ -0,0 +1,172 @@
+package setting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const vaultRequestTimeout = 10 * time.Second
+
+var errVaultNotConfigured = errors.New("vault is not configured, set auth_method in the [keystore.vault] section")
+
+// vaultExpander expands $__vault{engine:path:field} with a field of a secret read from HashiCorp Vault.
+// Secrets are cached until cache_ttl or their lease expires, whichever is first, so dynamic secrets are
+// not requested again for every expanded value and rotated secrets are read again once expired.
+type vaultExpander struct {
+	client    *http.Client
+	url       string
+	namespace string
+	token     string
+	cacheTTL  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]vaultSecret
+	now   func() time.Time
+}
+
+type vaultSecret struct {
+	data    map[string]interface{}
+	expires time.Time
+}
+
+type vaultResponse struct {
+	LeaseDuration int                    `json:"lease_duration"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+func (e *vaultExpander) SetupExpander(file *ini.File) error {
+	section := file.Section("keystore.vault")
+	authMethod := section.Key("auth_method").String()
+	if authMethod == "" {
+		return nil
+	}
+	if authMethod != "token" {
+		return fmt.Errorf("unsupported vault auth_method %q, only token is supported", authMethod)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.client = &http.Client{Timeout: vaultRequestTimeout}
+	e.url = strings.TrimSuffix(section.Key("url").String(), "/")
+	e.namespace = section.Key("namespace").String()
+	e.token = section.Key("token").String()
+	e.cacheTTL = section.Key("cache_ttl").MustDuration(5 * time.Minute)
+	e.cache = make(map[string]vaultSecret)
+	if e.url == "" {
+		return errors.New("vault url is required")
+	}
+	return nil
+}
+
+// Expand returns a field of a secret. The argument consists of the secrets engine, the path of
+// the secret and the field separated by colons, for example kv:secret/grafana/smtp:password.
+func (e *vaultExpander) Expand(s string) (string, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("invalid vault reference %q, expected engine:path:field", s)
+	}
+	engine, path, field := parts[0], parts[1], parts[2]
+
+	data, err := e.secret(engine, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %q has no field %q", path, field)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+func (e *vaultExpander) secret(engine, path string) (map[string]interface{}, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client == nil {
+		return nil, errVaultNotConfigured
+	}
+
+	key := engine + ":" + path
+	now := e.timeNow()
+	if cached, ok := e.cache[key]; ok && now.Before(cached.expires) {
+		return cached.data, nil
+	}
+
+	data, leaseDuration, err := e.read(engine, path)
+	if err != nil {
+		return nil, err
+	}
+	ttl := e.cacheTTL
+	if leaseDuration > 0 && leaseDuration < ttl {
+		ttl = leaseDuration
+	}
+	e.cache[key] = vaultSecret{data: data, expires: now.Add(ttl)}
+	return data, nil
+}
+
+func (e *vaultExpander) read(engine, path string) (map[string]interface{}, time.Duration, error) {
+	apiPath := path
+	if engine == "kv" {
+		// the data of K/V version 2 secrets is read from <mount>/data/<path>
+		mount, secretPath, found := strings.Cut(path, "/")
+		if !found {
+			return nil, 0, fmt.Errorf("invalid vault kv path %q, expected <mount>/<path>", path)
+		}
+		apiPath = mount + "/data/" + secretPath
+	}
+
+	u, err := url.Parse(e.url + "/v1/" + apiPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", e.token)
+	if e.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", e.namespace)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read vault secret %q: %w", path, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to read vault secret %q: unexpected status %d", path, resp.StatusCode)
+	}
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode vault secret %q: %w", path, err)
+	}
+	data := body.Data
+	if engine == "kv" {
+		nested, ok := data["data"].(map[string]interface{})
+		if !ok {
+			return nil, 0, fmt.Errorf("vault secret %q is not a K/V version 2 secret", path)
+		}
+		data = nested
+	}
+	return data, time.Duration(body.LeaseDuration) * time.Second, nil
+}
+
+func (e *vaultExpander) timeNow() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,89 @@
+package setting
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestVaultExpander(t *testing.T) {
+	reads := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("X-Vault-Token"))
+		reads[r.URL.Path]++
+		switch r.URL.Path {
+		case "/v1/secret/data/grafana/smtp":
+			_, _ = fmt.Fprint(w, `{"lease_duration":0,"data":{"data":{"password":"secret","port":587}}}`)
+		case "/v1/database/creds/grafana":
+			_, _ = fmt.Fprintf(w, `{"lease_duration":60,"data":{"username":"user-%d"}}`, reads[r.URL.Path])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	file := ini.Empty()
+	section, err := file.NewSection("keystore.vault")
+	require.NoError(t, err)
+	_, err = section.NewKey("url", server.URL)
+	require.NoError(t, err)
+	_, err = section.NewKey("auth_method", "token")
+	require.NoError(t, err)
+	_, err = section.NewKey("token", "test-token")
+	require.NoError(t, err)
+
+	now := time.Now()
+	e := &vaultExpander{now: func() time.Time { return now }}
+	require.NoError(t, e.SetupExpander(file))
+
+	t.Run("reads fields of K/V secrets", func(t *testing.T) {
+		got, err := e.Expand("kv:secret/grafana/smtp:password")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", got)
+
+		got, err = e.Expand("kv:secret/grafana/smtp:port")
+		require.NoError(t, err)
+		assert.Equal(t, "587", got)
+		assert.Equal(t, 1, reads["/v1/secret/data/grafana/smtp"])
+	})
+
+	t.Run("reads dynamic secrets again once their lease expired", func(t *testing.T) {
+		got, err := e.Expand("database:database/creds/grafana:username")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", got)
+
+		now = now.Add(30 * time.Second)
+		got, err = e.Expand("database:database/creds/grafana:username")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", got)
+
+		now = now.Add(time.Minute)
+		got, err = e.Expand("database:database/creds/grafana:username")
+		require.NoError(t, err)
+		assert.Equal(t, "user-2", got)
+	})
+
+	t.Run("returns an error for missing secrets and fields", func(t *testing.T) {
+		_, err := e.Expand("kv:secret/grafana/missing:password")
+		require.Error(t, err)
+
+		_, err = e.Expand("kv:secret/grafana/smtp:username")
+		require.Error(t, err)
+
+		_, err = e.Expand("secret/grafana/smtp")
+		require.Error(t, err)
+	})
+
+	t.Run("returns an error when vault is not configured", func(t *testing.T) {
+		unconfigured := &vaultExpander{}
+		require.NoError(t, unconfigured.SetupExpander(ini.Empty()))
+		_, err := unconfigured.Expand("kv:secret/grafana/smtp:password")
+		require.ErrorIs(t, err, errVaultNotConfigured)
+	})
+}
@@ -3,6 +3,7 @@ package setting
 import (
 	"errors"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/ini.v1"
@@ -49,6 +50,9 @@ type Provider interface {
 	// RegisterReloadHandler registers a handler for validation and reload
 	// of configuration updates tied to a specific section
 	RegisterReloadHandler(section string, handler ReloadHandler)
+	// Reload reads the configuration files again and applies the
+	// changed settings which can be applied without a restart.
+	Reload() (*ReloadResult, error)
 }
 
 // Section is a settings section copy
@@ -57,6 +61,9 @@ type Section interface {
 	// KeyValue returns a key-value
 	// abstraction for the given key.
 	KeyValue(key string) KeyValue
+	// ChangedKeys returns the keys which changed when
+	// the section is passed to a ReloadHandler.
+	ChangedKeys() []string
 }
 
 // KeyValue represents a settings key-value
@@ -92,6 +99,30 @@ type ReloadHandler interface {
 type SettingsBag map[string]map[string]string
 type SettingsRemovals map[string][]string
 
+// SettingsChanges are the changed keys per section.
+type SettingsChanges map[string][]string
+
+// ReloadResult lists the settings which changed since
+// the configuration was loaded or reloaded the last time.
+type ReloadResult struct {
+	// Applied are the changes which took effect.
+	Applied SettingsChanges `json:"applied"`
+	// RestartRequired are the changes which take
+	// effect after Grafana was restarted.
+	RestartRequired SettingsChanges `json:"restartRequired"`
+}
+
+// ReloadFunc is a ReloadHandler without validation.
+type ReloadFunc func(section Section) error
+
+func (f ReloadFunc) Reload(section Section) error {
+	return f(section)
+}
+
+func (f ReloadFunc) Validate(Section) error {
+	return nil
+}
+
 func ProvideProvider(cfg *Cfg) *OSSImpl {
 	return &OSSImpl{
 		Cfg: cfg,
@@ -100,9 +131,16 @@ func ProvideProvider(cfg *Cfg) *OSSImpl {
 
 type OSSImpl struct {
 	Cfg *Cfg
+
+	mu             sync.RWMutex
+	reloadMu       sync.Mutex
+	reloadHandlers map[string][]ReloadHandler
 }
 
-func (o OSSImpl) Current() SettingsBag {
+func (o *OSSImpl) Current() SettingsBag {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
 	settingsCopy := make(SettingsBag)
 
 	for _, section := range o.Cfg.Raw.Sections() {
@@ -115,7 +153,7 @@ func (o OSSImpl) Current() SettingsBag {
 	return settingsCopy
 }
 
-func (OSSImpl) Update(SettingsBag, SettingsRemovals) error {
+func (*OSSImpl) Update(SettingsBag, SettingsRemovals) error {
 	return errors.New("oss settings provider do not have support for settings updates")
 }
 
@@ -124,12 +162,21 @@ func (o *OSSImpl) KeyValue(section, key string) KeyValue {
 }
 
 func (o *OSSImpl) Section(section string) Section {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
 	return &sectionImpl{section: o.Cfg.Raw.Section(section)}
 }
 
-func (OSSImpl) RegisterReloadHandler(string, ReloadHandler) {}
+func (o *OSSImpl) RegisterReloadHandler(section string, handler ReloadHandler) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.reloadHandlers == nil {
+		o.reloadHandlers = make(map[string][]ReloadHandler)
+	}
+	o.reloadHandlers[section] = append(o.reloadHandlers[section], handler)
+}
 
-func (o OSSImpl) IsFeatureToggleEnabled(name string) bool {
+func (o *OSSImpl) IsFeatureToggleEnabled(name string) bool {
 	return o.Cfg.IsFeatureToggleEnabled(name)
 }
 
@@ -158,9 +205,14 @@ func (k *keyValImpl) MustDuration(defaultVal time.Duration) time.Duration {
 }
 
 type sectionImpl struct {
-	section *ini.Section
+	section     *ini.Section
+	changedKeys []string
 }
 
 func (s *sectionImpl) KeyValue(key string) KeyValue {
 	return &keyValImpl{s.section.Key(key)}
 }
+
+func (s *sectionImpl) ChangedKeys() []string {
+	return s.changedKeys
+}
@@ -0,0 +1,288 @@
+package setting
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// reloadableSettings are the settings which are applied without a restart of Grafana. Changes to
+// all other settings are reported as requiring a restart. Services reading a reloadable setting
+// once at startup register a ReloadHandler for its section to pick up the change.
+var reloadableSettings = []reloadableSetting{
+	{
+		name: "log",
+		matches: func(section, _ string) bool {
+			return section == "log" || strings.HasPrefix(section, "log.")
+		},
+		validate: func(file *ini.File) error {
+			for _, mode := range logModes(file) {
+				mode = strings.TrimSpace(mode)
+				if _, err := file.GetSection("log." + mode); err != nil {
+					return fmt.Errorf("unknown log mode %q", mode)
+				}
+			}
+			return nil
+		},
+		apply: func(cfg *Cfg) error {
+			return cfg.initLogging(cfg.Raw)
+		},
+	},
+	{
+		name: "smtp",
+		matches: func(section, key string) bool {
+			return section == "smtp" || section == "emails" && (key == "welcome_email_on_sign_up" || key == "content_types")
+		},
+		apply: func(cfg *Cfg) error {
+			cfg.readSmtpSettings()
+			return nil
+		},
+	},
+	{
+		name: "auth",
+		matches: func(section, key string) bool {
+			if section != "auth" {
+				return false
+			}
+			switch key {
+			case "login_maximum_inactive_lifetime_duration", "login_maximum_lifetime_duration",
+				"login_history_retention", "token_rotation_interval_minutes",
+				"login_max_concurrent_sessions", "login_concurrent_sessions_eviction":
+				return true
+			}
+			return false
+		},
+		validate: func(file *ini.File) error {
+			return (&Cfg{}).readAuthTimeouts(file.Section("auth"))
+		},
+		apply: func(cfg *Cfg) error {
+			return cfg.readAuthTimeouts(cfg.Raw.Section("auth"))
+		},
+	},
+	{
+		name: "security headers",
+		matches: func(section, key string) bool {
+			if section != "security" {
+				return false
+			}
+			switch key {
+			case "allow_embedding", "x_content_type_options", "x_xss_protection", "strict_transport_security",
+				"strict_transport_security_max_age_seconds", "strict_transport_security_preload",
+				"strict_transport_security_subdomains", "content_security_policy", "content_security_policy_template",
+				"content_security_policy_report_only", "content_security_policy_report_only_template":
+				return true
+			}
+			return false
+		},
+		validate: func(file *ini.File) error {
+			return (&Cfg{}).readSecurityHeaders(file.Section("security"))
+		},
+		apply: func(cfg *Cfg) error {
+			return cfg.readSecurityHeaders(cfg.Raw.Section("security"))
+		},
+	},
+	{
+		name: "quota",
+		matches: func(section, key string) bool {
+			// quotas are enforced by a different service when they are disabled
+			return section == "quota" && key != "enabled"
+		},
+		apply: func(cfg *Cfg) error {
+			enabled := cfg.Quota.Enabled
+			cfg.readQuotaSettings()
+			cfg.Quota.Enabled = enabled
+			return nil
+		},
+	},
+}
+
+type reloadableSetting struct {
+	name    string
+	matches func(section, key string) bool
+	// validate checks the settings before they are applied. Optional.
+	validate func(file *ini.File) error
+	// apply reads the settings again from cfg.Raw.
+	apply func(cfg *Cfg) error
+}
+
+// Reload reads the configuration files again. Reloadable settings are applied, and the
+// ReloadHandlers registered for the sections with changed settings are notified. Nothing
+// is applied if a setting is invalid or a ReloadHandler fails to validate its section.
+func (o *OSSImpl) Reload() (*ReloadResult, error) {
+	o.reloadMu.Lock()
+	defer o.reloadMu.Unlock()
+
+	// file becomes the new Raw, files is kept untouched for the next reload
+	file, err := o.Cfg.readConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+	files, err := o.Cfg.readConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.RLock()
+	loaded := o.Cfg.files
+	if loaded == nil {
+		loaded = o.Cfg.Raw
+	}
+	changes := diffSettings(loaded, files)
+	handlers := make(map[string][]ReloadHandler, len(o.reloadHandlers))
+	for section, sectionHandlers := range o.reloadHandlers {
+		handlers[section] = sectionHandlers
+	}
+	o.mu.RUnlock()
+
+	result := &ReloadResult{Applied: SettingsChanges{}, RestartRequired: SettingsChanges{}}
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	var settings []reloadableSetting
+	for section, keys := range changes {
+		for _, key := range keys {
+			s, ok := findReloadableSetting(section, key)
+			if !ok {
+				result.RestartRequired[section] = append(result.RestartRequired[section], key)
+				continue
+			}
+			result.Applied[section] = append(result.Applied[section], key)
+			if !containsReloadableSetting(settings, s.name) {
+				settings = append(settings, s)
+			}
+		}
+	}
+
+	var validationErrors []error
+	for _, s := range settings {
+		if s.validate == nil {
+			continue
+		}
+		if err := s.validate(file); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("invalid %s settings: %w", s.name, err))
+		}
+	}
+	for section := range changes {
+		for _, handler := range handlers[section] {
+			if err := handler.Validate(&sectionImpl{section: file.Section(section), changedKeys: changes[section]}); err != nil {
+				validationErrors = append(validationErrors, fmt.Errorf("invalid %s settings: %w", section, err))
+			}
+		}
+	}
+	if len(validationErrors) > 0 {
+		return nil, ValidationError{Errors: validationErrors}
+	}
+
+	o.mu.Lock()
+	o.Cfg.Raw = file
+	o.Cfg.files = files
+	Raw = file
+	o.mu.Unlock()
+
+	for _, s := range settings {
+		if err := s.apply(o.Cfg); err != nil {
+			return nil, fmt.Errorf("failed to apply %s settings: %w", s.name, err)
+		}
+	}
+	for section := range changes {
+		for _, handler := range handlers[section] {
+			if err := handler.Reload(&sectionImpl{section: file.Section(section), changedKeys: changes[section]}); err != nil {
+				return nil, fmt.Errorf("failed to reload %s settings: %w", section, err)
+			}
+		}
+	}
+
+	o.Cfg.Logger.Info("Settings reloaded", "applied", result.Applied, "restartRequired", result.RestartRequired)
+	return result, nil
+}
+
+func findReloadableSetting(section, key string) (reloadableSetting, bool) {
+	for _, s := range reloadableSettings {
+		if s.matches(section, key) {
+			return s, true
+		}
+	}
+	return reloadableSetting{}, false
+}
+
+func containsReloadableSetting(settings []reloadableSetting, name string) bool {
+	for _, s := range settings {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// diffSettings returns the keys which were added, removed or changed per section, sorted by name.
+func diffSettings(loaded, reloaded *ini.File) SettingsChanges {
+	changes := SettingsChanges{}
+	compare := func(a, b *ini.File, onlyMissing bool) {
+		for _, section := range a.Sections() {
+			other, _ := b.GetSection(section.Name())
+			for _, key := range section.Keys() {
+				if other == nil || !other.HasKey(key.Name()) {
+					changes[section.Name()] = append(changes[section.Name()], key.Name())
+					continue
+				}
+				if !onlyMissing && other.Key(key.Name()).Value() != key.Value() {
+					changes[section.Name()] = append(changes[section.Name()], key.Name())
+				}
+			}
+		}
+	}
+	compare(reloaded, loaded, false)
+	compare(loaded, reloaded, true)
+
+	for section := range changes {
+		sort.Strings(changes[section])
+	}
+	return changes
+}
+
+// ReadConfigFiles returns the configuration as read from the configuration files, the environment
+// and the command line. Unlike Raw, it does not contain the keys missing from the files which were
+// added with their default values while loading the settings.
+func (cfg *Cfg) ReadConfigFiles() (*ini.File, error) {
+	return cfg.readConfigFiles()
+}
+
+// readConfigFiles reads the configuration files with the command line arguments
+// the configuration was loaded with, without initializing the logging.
+func (cfg *Cfg) readConfigFiles() (*ini.File, error) {
+	// the files were already added to the loaded configuration files
+	loadedFiles := configFiles
+	defer func() {
+		configFiles = loadedFiles
+	}()
+
+	defaultConfigFile := path.Join(HomePath, "conf/defaults.ini")
+	if _, err := os.Stat(defaultConfigFile); err != nil {
+		return nil, fmt.Errorf("could not find config defaults: %w", err)
+	}
+	parsedFile, err := ini.Load(defaultConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse defaults.ini: %w", err)
+	}
+	parsedFile.BlockMode = false
+
+	commandLineProps := cfg.getCommandLineProperties(cfg.args.Args)
+	applyCommandLineDefaultProperties(commandLineProps, parsedFile)
+
+	if err := cfg.loadSpecifiedConfigFile(cfg.args.Config, parsedFile); err != nil {
+		return nil, err
+	}
+	if err := applyEnvVariableOverrides(parsedFile); err != nil {
+		return nil, err
+	}
+	applyCommandLineProperties(commandLineProps, parsedFile)
+	if err := expandConfig(parsedFile); err != nil {
+		return nil, err
+	}
+	return parsedFile, nil
+}
@@ -0,0 +1,170 @@
+package setting
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestDiffSettings(t *testing.T) {
+	loaded, err := ini.Load([]byte(`
+[server]
+http_port = 3000
+domain = localhost
+
+[smtp]
+host = localhost:25
+`))
+	require.NoError(t, err)
+	reloaded, err := ini.Load([]byte(`
+[server]
+http_port = 3001
+domain = localhost
+root_url = http://localhost:3001/
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, SettingsChanges{
+		"server": {"http_port", "root_url"},
+		"smtp":   {"host"},
+	}, diffSettings(loaded, reloaded))
+	assert.Empty(t, diffSettings(loaded, loaded))
+}
+
+type fakeReloadHandler struct {
+	validateErr error
+	reloaded    []string
+}
+
+func (h *fakeReloadHandler) Reload(section Section) error {
+	h.reloaded = section.ChangedKeys()
+	return nil
+}
+
+func (h *fakeReloadHandler) Validate(Section) error {
+	return h.validateErr
+}
+
+func TestOSSImpl_Reload(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "custom.ini")
+	writeConfig := func(t *testing.T, config string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(configFile, []byte(config), 0600))
+	}
+
+	writeConfig(t, `
+[auth]
+login_maximum_inactive_lifetime_duration = 1d
+
+[smtp]
+host = localhost:25
+
+[security]
+encryption_provider = secretKey.v1
+`)
+	cfg := NewCfg()
+	require.NoError(t, cfg.Load(CommandLineArgs{HomePath: "../../", Config: configFile}))
+	provider := ProvideProvider(cfg)
+	handler := &fakeReloadHandler{}
+	provider.RegisterReloadHandler("security", handler)
+
+	t.Run("applies reloadable settings and reports the other ones", func(t *testing.T) {
+		writeConfig(t, `
+[auth]
+login_maximum_inactive_lifetime_duration = 2d
+
+[smtp]
+host = smtp.example.com:587
+
+[server]
+http_port = 3001
+
+[security]
+encryption_provider = secretKey.v1
+`)
+		result, err := provider.Reload()
+		require.NoError(t, err)
+		assert.Equal(t, &ReloadResult{
+			Applied: SettingsChanges{
+				"auth": {"login_maximum_inactive_lifetime_duration"},
+				"smtp": {"host"},
+			},
+			RestartRequired: SettingsChanges{
+				"server": {"http_port"},
+			},
+		}, result)
+
+		assert.Equal(t, 48*time.Hour, cfg.LoginMaxInactiveLifetime)
+		assert.Equal(t, "smtp.example.com:587", cfg.Smtp.Host)
+		assert.Equal(t, "3001", provider.KeyValue("server", "http_port").Value())
+		assert.Empty(t, handler.reloaded)
+	})
+
+	t.Run("notifies the handlers of the changed keys", func(t *testing.T) {
+		writeConfig(t, `
+[auth]
+login_maximum_inactive_lifetime_duration = 2d
+
+[smtp]
+host = smtp.example.com:587
+
+[server]
+http_port = 3001
+
+[security]
+encryption_provider = secretKey.v2
+`)
+		result, err := provider.Reload()
+		require.NoError(t, err)
+		assert.Equal(t, SettingsChanges{"security": {"encryption_provider"}}, result.RestartRequired)
+		assert.Equal(t, []string{"encryption_provider"}, handler.reloaded)
+	})
+
+	t.Run("does not apply invalid settings", func(t *testing.T) {
+		writeConfig(t, `
+[auth]
+login_maximum_inactive_lifetime_duration = soon
+
+[smtp]
+host = localhost:25
+
+[server]
+http_port = 3001
+
+[security]
+encryption_provider = secretKey.v2
+`)
+		_, err := provider.Reload()
+		var validationErr ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, 48*time.Hour, cfg.LoginMaxInactiveLifetime)
+		assert.Equal(t, "smtp.example.com:587", cfg.Smtp.Host)
+	})
+
+	t.Run("does not apply settings rejected by a handler", func(t *testing.T) {
+		handler.validateErr = errors.New("invalid")
+		writeConfig(t, `
+[auth]
+login_maximum_inactive_lifetime_duration = 2d
+
+[smtp]
+host = localhost:25
+
+[server]
+http_port = 3001
+
+[security]
+encryption_provider = secretKey.v3
+`)
+		_, err := provider.Reload()
+		var validationErr ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, "smtp.example.com:587", cfg.Smtp.Host)
+	})
+}
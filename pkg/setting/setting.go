@@ -26,6 +26,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/gobwas/glob"
@@ -51,6 +52,14 @@ const (
 	ApplicationName  = "Grafana"
 )
 
+// Eviction strategies of the sessions over the concurrent session limit of a user.
+const (
+	// SessionEvictionOldest revokes the oldest sessions of the user when they log in.
+	SessionEvictionOldest = "oldest"
+	// SessionEvictionReject rejects the login until a session of the user ends.
+	SessionEvictionReject = "reject"
+)
+
 // zoneInfo names environment variable for setting the path to look for the timezone database in go
 const zoneInfo = "ZONEINFO"
 
@@ -235,6 +244,14 @@ type Cfg struct {
 	BundledPluginsPath    string
 	EnterpriseLicensePath string
 
+	// Provisioning
+	// ProvisioningPrune removes provisioned resources which are no longer declared in the provisioning files.
+	ProvisioningPrune bool
+	// ProvisioningPruneDryRun only reports the resources which would be pruned.
+	ProvisioningPruneDryRun bool
+	// ProvisioningSecretsRefreshInterval is how often the secrets referenced in the provisioning files are checked for rotation.
+	ProvisioningSecretsRefreshInterval time.Duration
+
 	// SMTP email settings
 	Smtp SmtpSettings
 
@@ -245,6 +262,8 @@ type Cfg struct {
 	RendererCallbackUrl            string
 	RendererAuthToken              string
 	RendererConcurrentRequestLimit int
+	RendererQueueTimeout           time.Duration
+	RendererHealthCheckInterval    time.Duration
 	RendererRenderKeyLifeTime      time.Duration
 
 	// Security
@@ -254,6 +273,8 @@ type Cfg struct {
 	CookieSameSiteDisabled            bool
 	CookieSameSiteMode                http.SameSite
 	AllowEmbedding                    bool
+	EmbedTokenMaxLifetime             time.Duration
+	TrustedProxies                    []string
 	XSSProtectionHeader               bool
 	ContentTypeProtectionHeader       bool
 	StrictTransportSecurity           bool
@@ -281,6 +302,7 @@ type Cfg struct {
 	PluginCatalogHiddenPlugins       []string
 	PluginAdminEnabled               bool
 	PluginAdminExternalManageEnabled bool
+	PluginSecretReferencesEnabled    bool
 
 	// Panels
 	DisableSanitizeHtml bool
@@ -291,6 +313,8 @@ type Cfg struct {
 	MetricsEndpointBasicAuthPassword string
 	MetricsEndpointDisableTotalStats bool
 	MetricsGrafanaEnvironmentInfo    map[string]string
+	MetricsOrgLabelsEnabled          bool
+	MetricsOrgLabelsMaxOrgs          int
 
 	// Dashboards
 	DefaultHomeDashboardPath string
@@ -300,6 +324,8 @@ type Cfg struct {
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
 	TokenRotationIntervalMinutes int
+	LoginHistoryRetention        time.Duration
+	LoginNewDeviceNotification   bool
 	SigV4AuthEnabled             bool
 	SigV4VerboseLogging          bool
 	AzureAuthEnabled             bool
@@ -310,6 +336,10 @@ type Cfg struct {
 	AdminEmail                   string
 	DisableSyncLock              bool
 
+	// Concurrent sessions of a user, LoginMaxConcurrentSessions 0 is unlimited.
+	LoginMaxConcurrentSessions      int
+	LoginConcurrentSessionsEviction string
+
 	// AWS Plugin Auth
 	AWSAllowedAuthProviders []string
 	AWSAssumeRoleEnabled    bool
@@ -381,12 +411,15 @@ type Cfg struct {
 	DateFormats DateFormats
 
 	// User
-	UserInviteMaxLifetime time.Duration
-	HiddenUsers           map[string]struct{}
-	CaseInsensitiveLogin  bool // Login and Email will be considered case insensitive
+	UserInviteMaxLifetime     time.Duration
+	HiddenUsers               map[string]struct{}
+	CaseInsensitiveLogin      bool // Login and Email will be considered case insensitive
+	UserNotificationRetention time.Duration
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	SAAttestationDays         int
+	SAExpiryNoticeDays        int
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -394,6 +427,7 @@ type Cfg struct {
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	KubernetesAnnotations              KubernetesAnnotationSettings
 
 	// Sentry config
 	Sentry Sentry
@@ -455,6 +489,18 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveHistoryChannels is a set of channel patterns (without the org prefix)
+	// for which Live keeps the history of published messages.
+	LiveHistoryChannels []string
+	// LiveHistorySize is the maximum number of messages kept per channel.
+	LiveHistorySize int
+	// LiveHistoryTTL is how long messages are kept in the channel history.
+	LiveHistoryTTL time.Duration
+	// LivePushRateLimit is the number of pushes per second a user can make
+	// to the Live push gateway. Zero means no limit.
+	LivePushRateLimit float64
+	// LivePushRateLimitBurst is the number of pushes a user can make at once.
+	LivePushRateLimitBurst int
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -468,6 +514,10 @@ type Cfg struct {
 
 	// Query history
 	QueryHistoryEnabled bool
+	// QueryHistoryRetention is how long queries which are not starred are kept.
+	QueryHistoryRetention      time.Duration
+	QueryHistoryMaxRows        int
+	QueryHistoryMaxStarredRows int
 
 	DashboardPreviews DashboardPreviewsSettings
 
@@ -475,6 +525,16 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	Webhooks WebhooksSettings
+
+	VariableCache VariableCacheSettings
+
+	OrgLogs OrgLogsSettings
+
+	ContentSync ContentSyncSettings
+
+	CDN CDNSettings
+
 	// Access Control
 	RBACEnabled         bool
 	RBACPermissionCache bool
@@ -484,6 +544,12 @@ type Cfg struct {
 	GRPCServerNetwork   string
 	GRPCServerAddress   string
 	GRPCServerTLSConfig *tls.Config
+
+	// args are the command line arguments the configuration was loaded with.
+	args CommandLineArgs
+	// files is the configuration as read from the files. Unlike Raw, it does not contain the
+	// default values added when reading the settings, so that reloads only report actual changes.
+	files *ini.File
 }
 
 type CommandLineArgs struct {
@@ -658,6 +724,21 @@ func (cfg *Cfg) readAnnotationSettings() error {
 	cfg.DashboardAnnotationCleanupSettings = newAnnotationCleanupSettings(dashboardAnnotation, "max_age")
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
 
+	kubernetesSection := cfg.Raw.Section("annotations.kubernetes")
+	cfg.KubernetesAnnotations = KubernetesAnnotationSettings{
+		Enabled:    kubernetesSection.Key("enabled").MustBool(false),
+		Namespaces: util.SplitString(kubernetesSection.Key("namespaces").MustString("")),
+		Kinds:      util.SplitString(kubernetesSection.Key("kinds").MustString("")),
+		ReasonTags: map[string][]string{},
+	}
+	for _, rule := range util.SplitString(kubernetesSection.Key("reason_tags").MustString("")) {
+		reason, tag, ok := strings.Cut(rule, ":")
+		if !ok || reason == "" || tag == "" {
+			return fmt.Errorf("invalid rule %q in [annotations.kubernetes.reason_tags], rules are written as reason:tag", rule)
+		}
+		cfg.KubernetesAnnotations.ReasonTags[reason] = append(cfg.KubernetesAnnotations.ReasonTags[reason], tag)
+	}
+
 	return nil
 }
 
@@ -671,6 +752,17 @@ type AnnotationCleanupSettings struct {
 	MaxCount int64
 }
 
+// KubernetesAnnotationSettings configures the annotations created from Kubernetes events.
+type KubernetesAnnotationSettings struct {
+	Enabled bool
+	// Namespaces and Kinds of the objects whose events are annotated, all if empty. Events of
+	// cluster scoped objects, like nodes, are annotated regardless of the namespaces.
+	Namespaces []string
+	Kinds      []string
+	// ReasonTags are the tags added to the annotations of events, by reason.
+	ReasonTags map[string][]string
+}
+
 func EnvKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
 	sN = strings.ReplaceAll(sN, "-", "_")
@@ -927,7 +1019,11 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		return err
 	}
 
+	cfg.args = args
 	cfg.Raw = iniFile
+	if cfg.files, err = cfg.readConfigFiles(); err != nil {
+		return err
+	}
 
 	// Temporarily keep global, to make refactor in steps
 	Raw = cfg.Raw
@@ -950,6 +1046,10 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.BundledPluginsPath = makeAbsolute("plugins-bundled", HomePath)
 	provisioning := valueAsString(iniFile.Section("paths"), "provisioning", "")
 	cfg.ProvisioningPath = makeAbsolute(provisioning, HomePath)
+	provisioningSection := iniFile.Section("provisioning")
+	cfg.ProvisioningPrune = provisioningSection.Key("prune").MustBool(false)
+	cfg.ProvisioningPruneDryRun = provisioningSection.Key("prune_dry_run").MustBool(false)
+	cfg.ProvisioningSecretsRefreshInterval = provisioningSection.Key("secrets_refresh_interval").MustDuration(5 * time.Minute)
 
 	if err := cfg.readServerSettings(iniFile); err != nil {
 		return err
@@ -997,6 +1097,8 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.MetricsEndpointBasicAuthUsername = valueAsString(iniFile.Section("metrics"), "basic_auth_username", "")
 	cfg.MetricsEndpointBasicAuthPassword = valueAsString(iniFile.Section("metrics"), "basic_auth_password", "")
 	cfg.MetricsEndpointDisableTotalStats = iniFile.Section("metrics").Key("disable_total_stats").MustBool(false)
+	cfg.MetricsOrgLabelsEnabled = iniFile.Section("metrics").Key("org_labels_enabled").MustBool(false)
+	cfg.MetricsOrgLabelsMaxOrgs = iniFile.Section("metrics").Key("org_labels_max_orgs").MustInt(100)
 
 	analytics := iniFile.Section("analytics")
 	cfg.CheckForGrafanaUpdates = analytics.Key("check_for_updates").MustBool(true)
@@ -1035,8 +1137,9 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	profile := iniFile.Section("profile")
 	ProfileEnabled = profile.Key("enabled").MustBool(true)
 
-	queryHistory := iniFile.Section("query_history")
-	cfg.QueryHistoryEnabled = queryHistory.Key("enabled").MustBool(true)
+	if err := cfg.readQueryHistorySettings(iniFile.Section("query_history")); err != nil {
+		return err
+	}
 
 	panelsSection := iniFile.Section("panels")
 	cfg.DisableSanitizeHtml = panelsSection.Key("disable_sanitize_html").MustBool(false)
@@ -1079,6 +1182,15 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.DashboardPreviews = readDashboardPreviewsSettings(iniFile)
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.Webhooks = readWebhooksSettings(iniFile)
+	cfg.VariableCache = readVariableCacheSettings(iniFile)
+	cfg.OrgLogs = readOrgLogsSettings(iniFile)
+	if cfg.ContentSync, err = readContentSyncSettings(iniFile); err != nil {
+		return err
+	}
+	if cfg.CDN, err = readCDNSettings(iniFile); err != nil {
+		return err
+	}
 
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		cfg.Logger.Warn("require_email_validation is enabled but smtp is disabled")
@@ -1189,16 +1301,20 @@ func (cfg *Cfg) readSessionConfig() {
 }
 
 func (cfg *Cfg) initLogging(file *ini.File) error {
+	logsPath := valueAsString(file.Section("paths"), "logs", "")
+	cfg.LogsPath = makeAbsolute(logsPath, HomePath)
+	return log.ReadLoggingConfig(logModes(file), cfg.LogsPath, file)
+}
+
+func logModes(file *ini.File) []string {
 	logModeStr := valueAsString(file.Section("log"), "mode", "console")
 	// split on comma
-	logModes := strings.Split(logModeStr, ",")
+	modes := strings.Split(logModeStr, ",")
 	// also try space
-	if len(logModes) == 1 {
-		logModes = strings.Split(logModeStr, " ")
+	if len(modes) == 1 {
+		modes = strings.Split(logModeStr, " ")
 	}
-	logsPath := valueAsString(file.Section("paths"), "logs", "")
-	cfg.LogsPath = makeAbsolute(logsPath, HomePath)
-	return log.ReadLoggingConfig(logModes, cfg.LogsPath, file)
+	return modes
 }
 
 func (cfg *Cfg) LogConfigSources() {
@@ -1287,27 +1403,22 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 			cfg.CookieSameSiteMode = CookieSameSiteMode
 		}
 	}
-	cfg.AllowEmbedding = security.Key("allow_embedding").MustBool(false)
-
-	cfg.ContentTypeProtectionHeader = security.Key("x_content_type_options").MustBool(true)
-	cfg.XSSProtectionHeader = security.Key("x_xss_protection").MustBool(true)
-	cfg.StrictTransportSecurity = security.Key("strict_transport_security").MustBool(false)
-	cfg.StrictTransportSecurityMaxAge = security.Key("strict_transport_security_max_age_seconds").MustInt(86400)
-	cfg.StrictTransportSecurityPreload = security.Key("strict_transport_security_preload").MustBool(false)
-	cfg.StrictTransportSecuritySubDomains = security.Key("strict_transport_security_subdomains").MustBool(false)
 	cfg.AngularSupportEnabled = security.Key("angular_support_enabled").MustBool(true)
-	cfg.CSPEnabled = security.Key("content_security_policy").MustBool(false)
-	cfg.CSPTemplate = security.Key("content_security_policy_template").MustString("")
-	cfg.CSPReportOnlyEnabled = security.Key("content_security_policy_report_only").MustBool(false)
-	cfg.CSPReportOnlyTemplate = security.Key("content_security_policy_report_only_template").MustString("")
+	if err := cfg.readSecurityHeaders(security); err != nil {
+		return err
+	}
 
-	if cfg.CSPEnabled && cfg.CSPTemplate == "" {
-		return fmt.Errorf("enabling content_security_policy requires a content_security_policy_template configuration")
+	embedTokenMaxLifetime, err := gtime.ParseDuration(valueAsString(security, "embed_token_max_lifetime", "30d"))
+	if err != nil {
+		return fmt.Errorf("invalid embed_token_max_lifetime: %w", err)
 	}
+	cfg.EmbedTokenMaxLifetime = embedTokenMaxLifetime
 
-	if cfg.CSPReportOnlyEnabled && cfg.CSPReportOnlyTemplate == "" {
-		return fmt.Errorf("enabling content_security_policy_report_only requires a content_security_policy_report_only_template configuration")
+	trustedProxies, err := network.NormalizeCIDRs(util.SplitString(valueAsString(security, "trusted_proxies", "")))
+	if err != nil {
+		return fmt.Errorf("invalid trusted_proxies: %w", err)
 	}
+	cfg.TrustedProxies = trustedProxies
 
 	// read data source proxy whitelist
 	DataProxyWhiteList = make(map[string]bool)
@@ -1326,11 +1437,45 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	return nil
 }
 
-func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
-	auth := iniFile.Section("auth")
+// readSecurityHeaders reads the settings of the security headers added to responses, they can be
+// changed at runtime through the security headers API.
+func (cfg *Cfg) readSecurityHeaders(security *ini.Section) error {
+	cfg.AllowEmbedding = security.Key("allow_embedding").MustBool(false)
+	cfg.ContentTypeProtectionHeader = security.Key("x_content_type_options").MustBool(true)
+	cfg.XSSProtectionHeader = security.Key("x_xss_protection").MustBool(true)
+	cfg.StrictTransportSecurity = security.Key("strict_transport_security").MustBool(false)
+	cfg.StrictTransportSecurityMaxAge = security.Key("strict_transport_security_max_age_seconds").MustInt(86400)
+	cfg.StrictTransportSecurityPreload = security.Key("strict_transport_security_preload").MustBool(false)
+	cfg.StrictTransportSecuritySubDomains = security.Key("strict_transport_security_subdomains").MustBool(false)
+	cfg.CSPEnabled = security.Key("content_security_policy").MustBool(false)
+	cfg.CSPTemplate = security.Key("content_security_policy_template").MustString("")
+	cfg.CSPReportOnlyEnabled = security.Key("content_security_policy_report_only").MustBool(false)
+	cfg.CSPReportOnlyTemplate = security.Key("content_security_policy_report_only_template").MustString("")
 
-	cfg.LoginCookieName = valueAsString(auth, "login_cookie_name", "grafana_session")
+	if cfg.CSPEnabled && cfg.CSPTemplate == "" {
+		return fmt.Errorf("enabling content_security_policy requires a content_security_policy_template configuration")
+	}
+
+	if cfg.CSPReportOnlyEnabled && cfg.CSPReportOnlyTemplate == "" {
+		return fmt.Errorf("enabling content_security_policy_report_only requires a content_security_policy_report_only_template configuration")
+	}
+	return nil
+}
+
+func (cfg *Cfg) readQueryHistorySettings(section *ini.Section) (err error) {
+	cfg.QueryHistoryEnabled = section.Key("enabled").MustBool(true)
+
+	cfg.QueryHistoryRetention, err = gtime.ParseDuration(valueAsString(section, "retention", "14d"))
+	if err != nil {
+		return fmt.Errorf("invalid query history retention: %w", err)
+	}
+	cfg.QueryHistoryMaxRows = section.Key("max_rows").MustInt(200000)
+	cfg.QueryHistoryMaxStarredRows = section.Key("max_starred_rows").MustInt(150000)
+	return nil
+}
 
+// readAuthTimeouts reads the lifetimes and limits of login sessions, which can be changed without a restart.
+func (cfg *Cfg) readAuthTimeouts(auth *ini.Section) (err error) {
 	const defaultMaxInactiveLifetime = "7d"
 	maxInactiveDurationVal := valueAsString(auth, "login_maximum_inactive_lifetime_duration", defaultMaxInactiveLifetime)
 	cfg.LoginMaxInactiveLifetime, err = gtime.ParseDuration(maxInactiveDurationVal)
@@ -1345,13 +1490,45 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		return err
 	}
 
-	cfg.ApiKeyMaxSecondsToLive = auth.Key("api_key_max_seconds_to_live").MustInt64(-1)
+	const defaultLoginHistoryRetention = "90d"
+	loginHistoryRetentionVal := valueAsString(auth, "login_history_retention", defaultLoginHistoryRetention)
+	cfg.LoginHistoryRetention, err = gtime.ParseDuration(loginHistoryRetentionVal)
+	if err != nil {
+		return err
+	}
 
 	cfg.TokenRotationIntervalMinutes = auth.Key("token_rotation_interval_minutes").MustInt(10)
 	if cfg.TokenRotationIntervalMinutes < 2 {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
 
+	cfg.LoginMaxConcurrentSessions = auth.Key("login_max_concurrent_sessions").MustInt(0)
+	if cfg.LoginMaxConcurrentSessions < 0 {
+		return fmt.Errorf("login_max_concurrent_sessions must not be negative")
+	}
+	cfg.LoginConcurrentSessionsEviction = valueAsString(auth, "login_concurrent_sessions_eviction", SessionEvictionOldest)
+	switch cfg.LoginConcurrentSessionsEviction {
+	case SessionEvictionOldest, SessionEvictionReject:
+	default:
+		return fmt.Errorf("invalid login_concurrent_sessions_eviction %q, must be %q or %q",
+			cfg.LoginConcurrentSessionsEviction, SessionEvictionOldest, SessionEvictionReject)
+	}
+	return nil
+}
+
+func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
+	auth := iniFile.Section("auth")
+
+	cfg.LoginCookieName = valueAsString(auth, "login_cookie_name", "grafana_session")
+
+	err = cfg.readAuthTimeouts(auth)
+	if err != nil {
+		return err
+	}
+	cfg.LoginNewDeviceNotification = auth.Key("login_new_device_notification").MustBool(true)
+
+	cfg.ApiKeyMaxSecondsToLive = auth.Key("api_key_max_seconds_to_live").MustInt64(-1)
+
 	// Debug setting unlocking frontend auth sync lock. Users will still be reset on their next login.
 	cfg.DisableSyncLock = auth.Key("disable_sync_lock").MustBool(false)
 
@@ -1484,12 +1661,20 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 		}
 	}
 
+	notificationRetentionVal := valueAsString(users, "notification_retention", "30d")
+	cfg.UserNotificationRetention, err = gtime.ParseDuration(notificationRetentionVal)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.SAAttestationDays = serviceAccount.Key("attestation_days").MustInt(90)
+	cfg.SAExpiryNoticeDays = serviceAccount.Key("expiry_notice_days").MustInt(7)
 	return nil
 }
 
@@ -1514,6 +1699,8 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	}
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+	cfg.RendererQueueTimeout = renderSec.Key("concurrent_render_queue_timeout").MustDuration(0)
+	cfg.RendererHealthCheckInterval = renderSec.Key("health_check_interval").MustDuration(10 * time.Second)
 	cfg.RendererRenderKeyLifeTime = renderSec.Key("render_key_lifetime").MustDuration(5 * time.Minute)
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
@@ -1725,7 +1912,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngine = section.Key("ha_engine").MustString("")
 	switch cfg.LiveHAEngine {
-	case "", "redis":
+	case "", "redis", "nats":
 	default:
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
@@ -1745,5 +1932,34 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	var historyPatterns []string
+	for _, historyPattern := range util.SplitString(section.Key("history_channels").MustString("")) {
+		if _, err := glob.Compile(historyPattern); err != nil {
+			return fmt.Errorf("error parsing [live] history_channels pattern %q: %v", historyPattern, err)
+		}
+		historyPatterns = append(historyPatterns, historyPattern)
+	}
+	cfg.LiveHistoryChannels = historyPatterns
+	if len(historyPatterns) > 0 && cfg.LiveHAEngine == "nats" {
+		return errors.New("[live] history_channels is not supported with the nats HA engine")
+	}
+	cfg.LiveHistorySize = section.Key("history_size").MustInt(100)
+	if cfg.LiveHistorySize < 1 {
+		return fmt.Errorf("unexpected value %d for [live] history_size", cfg.LiveHistorySize)
+	}
+	cfg.LiveHistoryTTL = section.Key("history_ttl").MustDuration(10 * time.Minute)
+	if cfg.LiveHistoryTTL < time.Second {
+		return fmt.Errorf("unexpected value %s for [live] history_ttl", cfg.LiveHistoryTTL)
+	}
+
+	cfg.LivePushRateLimit = section.Key("push_rate_limit").MustFloat64(0)
+	if cfg.LivePushRateLimit < 0 {
+		return fmt.Errorf("unexpected value %v for [live] push_rate_limit", cfg.LivePushRateLimit)
+	}
+	cfg.LivePushRateLimitBurst = section.Key("push_rate_limit_burst").MustInt(10)
+	if cfg.LivePushRateLimitBurst < 1 {
+		return fmt.Errorf("unexpected value %d for [live] push_rate_limit_burst", cfg.LivePushRateLimitBurst)
+	}
 	return nil
 }
@@ -0,0 +1,53 @@
+package setting
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+)
+
+type CDNSettings struct {
+	// PluginsURL is the root URL of a CDN pulling the plugin assets from this instance, e.g.
+	// https://cdn.example.com/grafana/. The plugin assets are served by this instance when it is nil.
+	PluginsURL *url.URL
+	// SigningKey signs the URLs of the plugin assets, which are then only served with a valid
+	// signature. The URLs are not signed when it is empty.
+	SigningKey string
+	// SignedURLTTL is how long the signed URLs are valid for at least. The URLs only change once per
+	// period, so that the CDN can cache them.
+	SignedURLTTL time.Duration
+	// ManifestRefreshInterval is how often the manifest of the plugin assets is refreshed, to pick up
+	// the plugins installed or updated since.
+	ManifestRefreshInterval time.Duration
+}
+
+func readCDNSettings(iniFile *ini.File) (CDNSettings, error) {
+	section := iniFile.Section("cdn")
+	s := CDNSettings{
+		SigningKey: section.Key("signing_key").MustString(""),
+	}
+
+	if pluginsURL := valueAsString(section, "plugins_url", ""); pluginsURL != "" {
+		u, err := url.Parse(pluginsURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return s, fmt.Errorf("cdn plugins_url must be an absolute URL, got %q", pluginsURL)
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		s.PluginsURL = u
+	}
+
+	var err error
+	if s.SignedURLTTL, err = gtime.ParseDuration(valueAsString(section, "signed_url_ttl", "24h")); err != nil || s.SignedURLTTL <= 0 {
+		return s, fmt.Errorf("invalid cdn signed_url_ttl %q", valueAsString(section, "signed_url_ttl", ""))
+	}
+	if s.ManifestRefreshInterval, err = gtime.ParseDuration(valueAsString(section, "manifest_refresh_interval", "5m")); err != nil || s.ManifestRefreshInterval <= 0 {
+		return s, fmt.Errorf("invalid cdn manifest_refresh_interval %q", valueAsString(section, "manifest_refresh_interval", ""))
+	}
+	return s, nil
+}
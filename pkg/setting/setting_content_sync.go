@@ -0,0 +1,75 @@
+package setting
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+)
+
+const (
+	// ContentSyncModePrimary records the changes of the content, and serves them to the replicas.
+	ContentSyncModePrimary = "primary"
+	// ContentSyncModeReplica replicates the content of a primary instance.
+	ContentSyncModeReplica = "replica"
+)
+
+type ContentSyncSettings struct {
+	// Mode is primary, replica, or empty if the content isn't replicated.
+	Mode string
+	// Token authenticates the replicas to the sync API of the primary.
+	Token string
+	// PrimaryURL is the root URL of the primary instance a replica replicates.
+	PrimaryURL string
+	// PollInterval is how often a replica gets the changes of the primary.
+	PollInterval time.Duration
+	// FullSyncInterval is how often a replica replicates the whole content of the primary, to repair
+	// the changes which could not be applied.
+	FullSyncInterval time.Duration
+	// ReadOnly rejects the changes of the replicated content made on a replica.
+	ReadOnly bool
+	// ChangeRetention is how long the primary keeps the changes. A replica which is behind by more
+	// than that replicates the whole content.
+	ChangeRetention time.Duration
+}
+
+func readContentSyncSettings(iniFile *ini.File) (ContentSyncSettings, error) {
+	section := iniFile.Section("content_sync")
+	s := ContentSyncSettings{
+		Mode:         section.Key("mode").MustString(""),
+		Token:        section.Key("token").MustString(""),
+		PrimaryURL:   section.Key("primary_url").MustString(""),
+		PollInterval: section.Key("poll_interval").MustDuration(30 * time.Second),
+		ReadOnly:     section.Key("read_only").MustBool(true),
+	}
+
+	var err error
+	if s.FullSyncInterval, err = gtime.ParseDuration(valueAsString(section, "full_sync_interval", "24h")); err != nil {
+		return s, fmt.Errorf("invalid content_sync full_sync_interval: %w", err)
+	}
+	if s.ChangeRetention, err = gtime.ParseDuration(valueAsString(section, "change_retention", "7d")); err != nil {
+		return s, fmt.Errorf("invalid content_sync change_retention: %w", err)
+	}
+
+	switch s.Mode {
+	case "":
+		return s, nil
+	case ContentSyncModePrimary, ContentSyncModeReplica:
+	default:
+		return s, fmt.Errorf("content_sync mode must be %q or %q, got %q", ContentSyncModePrimary, ContentSyncModeReplica, s.Mode)
+	}
+	if s.Token == "" {
+		return s, fmt.Errorf("content_sync token is required in %s mode", s.Mode)
+	}
+	if s.Mode == ContentSyncModeReplica {
+		if u, err := url.Parse(s.PrimaryURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return s, fmt.Errorf("content_sync primary_url must be the URL of the primary instance, got %q", s.PrimaryURL)
+		}
+		if s.PollInterval <= 0 {
+			return s, fmt.Errorf("content_sync poll_interval must be positive")
+		}
+	}
+	return s, nil
+}
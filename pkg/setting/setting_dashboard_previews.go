@@ -11,6 +11,11 @@ type DashboardPreviewsSettings struct {
 	MaxCrawlDuration  time.Duration
 	RenderingTimeout  time.Duration
 	CrawlThreadCount  uint32
+	// MaxThumbnailAge is how old a thumbnail can get before the scheduler renders it again, even if
+	// its dashboard did not change. Zero disables refreshing unchanged dashboards.
+	MaxThumbnailAge time.Duration
+	// RenderOnSave renders the thumbnails of a dashboard when it is saved.
+	RenderOnSave bool
 }
 
 func readDashboardPreviewsSettings(iniFile *ini.File) DashboardPreviewsSettings {
@@ -27,5 +32,7 @@ func readDashboardPreviewsSettings(iniFile *ini.File) DashboardPreviewsSettings
 	s.SchedulerInterval = previewsCrawlerSection.Key("scheduler_interval").MustDuration(12 * time.Hour)
 	s.MaxCrawlDuration = previewsCrawlerSection.Key("max_crawl_duration").MustDuration(1 * time.Hour)
 	s.RenderingTimeout = previewsCrawlerSection.Key("rendering_timeout").MustDuration(20 * time.Second)
+	s.MaxThumbnailAge = previewsCrawlerSection.Key("max_thumbnail_age").MustDuration(0)
+	s.RenderOnSave = previewsCrawlerSection.Key("render_on_save").MustBool(true)
 	return s
 }
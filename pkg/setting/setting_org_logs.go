@@ -0,0 +1,48 @@
+package setting
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// OrgIDHashPrefix prefixes the hashed organization IDs of logs and traces.
+const OrgIDHashPrefix = "h:"
+
+type OrgLogsSettings struct {
+	// HashOrgID replaces the organization IDs of the log lines and traces of requests with a keyed
+	// hash, so that they can be told apart without revealing which organization they belong to.
+	HashOrgID bool
+	// BufferSize is the number of recent log lines kept for each organization by the org logs API.
+	BufferSize int
+	// Level is the minimum level of the log lines kept for organizations.
+	Level string
+	// MaxStreamDuration is how long the org logs API streams log lines before closing the response.
+	MaxStreamDuration time.Duration
+}
+
+func readOrgLogsSettings(iniFile *ini.File) OrgLogsSettings {
+	s := OrgLogsSettings{}
+
+	logSection := iniFile.Section("log")
+	s.HashOrgID = logSection.Key("hash_org_id").MustBool(false)
+	s.BufferSize = logSection.Key("org_logs_buffer_size").MustInt(1000)
+	s.Level = logSection.Key("org_logs_level").MustString("info")
+	s.MaxStreamDuration = logSection.Key("org_logs_max_stream_duration").MustDuration(time.Hour)
+	return s
+}
+
+// OrgIDLogValue returns the value identifying an organization in log lines and traces, the ID of
+// the organization or its hash if hash_org_id is enabled.
+func (cfg *Cfg) OrgIDLogValue(orgID int64) interface{} {
+	if !cfg.OrgLogs.HashOrgID {
+		return orgID
+	}
+	mac := hmac.New(sha256.New, []byte(cfg.SecretKey))
+	mac.Write([]byte(strconv.FormatInt(orgID, 10)))
+	return OrgIDHashPrefix + hex.EncodeToString(mac.Sum(nil))[:16]
+}
@@ -1,11 +1,14 @@
 package setting
 
+import "time"
+
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	AlertRule  int64 `target:"alert_rule"`
+	User            int64 `target:"org_user"`
+	DataSource      int64 `target:"data_source"`
+	Dashboard       int64 `target:"dashboard"`
+	ApiKey          int64 `target:"api_key"`
+	AlertRule       int64 `target:"alert_rule"`
+	ScheduledReport int64 `target:"scheduled_report"`
 }
 
 type UserQuota struct {
@@ -13,14 +16,15 @@ type UserQuota struct {
 }
 
 type GlobalQuota struct {
-	Org        int64 `target:"org"`
-	User       int64 `target:"user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	Session    int64 `target:"-"`
-	AlertRule  int64 `target:"alert_rule"`
-	File       int64 `target:"file"`
+	Org             int64 `target:"org"`
+	User            int64 `target:"user"`
+	DataSource      int64 `target:"data_source"`
+	Dashboard       int64 `target:"dashboard"`
+	ApiKey          int64 `target:"api_key"`
+	Session         int64 `target:"session"`
+	AlertRule       int64 `target:"alert_rule"`
+	File            int64 `target:"file"`
+	ScheduledReport int64 `target:"scheduled_report"`
 }
 
 type QuotaSettings struct {
@@ -28,12 +32,21 @@ type QuotaSettings struct {
 	Org     OrgQuota
 	User    UserQuota
 	Global  GlobalQuota
+	// BurstPercent is the percentage by which the usage may exceed a limit before requests are rejected.
+	BurstPercent int64
+	// UsageCacheTTL is how long the usage counted for the quota checks of requests is reused.
+	UsageCacheTTL time.Duration
 }
 
 func (cfg *Cfg) readQuotaSettings() {
 	// set global defaults.
 	quota := cfg.Raw.Section("quota")
 	cfg.Quota.Enabled = quota.Key("enabled").MustBool(false)
+	cfg.Quota.BurstPercent = quota.Key("burst_percent").MustInt64(0)
+	if cfg.Quota.BurstPercent < 0 {
+		cfg.Quota.BurstPercent = 0
+	}
+	cfg.Quota.UsageCacheTTL = quota.Key("usage_cache_ttl").MustDuration(0)
 
 	var alertOrgQuota int64
 	var alertGlobalQuota int64
@@ -43,11 +56,12 @@ func (cfg *Cfg) readQuotaSettings() {
 	}
 	// per ORG Limits
 	cfg.Quota.Org = OrgQuota{
-		User:       quota.Key("org_user").MustInt64(10),
-		DataSource: quota.Key("org_data_source").MustInt64(10),
-		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
-		ApiKey:     quota.Key("org_api_key").MustInt64(10),
-		AlertRule:  alertOrgQuota,
+		User:            quota.Key("org_user").MustInt64(10),
+		DataSource:      quota.Key("org_data_source").MustInt64(10),
+		Dashboard:       quota.Key("org_dashboard").MustInt64(10),
+		ApiKey:          quota.Key("org_api_key").MustInt64(10),
+		AlertRule:       alertOrgQuota,
+		ScheduledReport: quota.Key("org_scheduled_report").MustInt64(10),
 	}
 
 	// per User limits
@@ -57,13 +71,14 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	// Global Limits
 	cfg.Quota.Global = GlobalQuota{
-		User:       quota.Key("global_user").MustInt64(-1),
-		Org:        quota.Key("global_org").MustInt64(-1),
-		DataSource: quota.Key("global_data_source").MustInt64(-1),
-		Dashboard:  quota.Key("global_dashboard").MustInt64(-1),
-		ApiKey:     quota.Key("global_api_key").MustInt64(-1),
-		Session:    quota.Key("global_session").MustInt64(-1),
-		File:       quota.Key("global_file").MustInt64(-1),
-		AlertRule:  alertGlobalQuota,
+		User:            quota.Key("global_user").MustInt64(-1),
+		Org:             quota.Key("global_org").MustInt64(-1),
+		DataSource:      quota.Key("global_data_source").MustInt64(-1),
+		Dashboard:       quota.Key("global_dashboard").MustInt64(-1),
+		ApiKey:          quota.Key("global_api_key").MustInt64(-1),
+		Session:         quota.Key("global_session").MustInt64(-1),
+		File:            quota.Key("global_file").MustInt64(-1),
+		AlertRule:       alertGlobalQuota,
+		ScheduledReport: quota.Key("global_scheduled_report").MustInt64(-1),
 	}
 }
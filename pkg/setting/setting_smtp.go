@@ -1,6 +1,13 @@
 package setting
 
-import "github.com/grafana/grafana/pkg/util"
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/util"
+)
 
 type SmtpSettings struct {
 	Enabled        bool
@@ -15,11 +22,25 @@ type SmtpSettings struct {
 	StartTLSPolicy string
 	SkipVerify     bool
 
+	// Provider selects how emails are delivered: smtp (default), ses, sendgrid or mailgun.
+	Provider      string
+	APIKey        string
+	APIURL        string
+	APIRegion     string
+	MailgunDomain string
+	// AllowedSenderDomains lists the domains organizations may use for their sender identities
+	// in addition to the domain of FromAddress.
+	AllowedSenderDomains []string
+
 	SendWelcomeEmailOnSignUp bool
 	TemplatesPatterns        []string
 	ContentTypes             []string
+	// DeliveryHistoryRetention is how long the delivery status of sent emails is kept.
+	DeliveryHistoryRetention time.Duration
 }
 
+const defaultEmailDeliveryHistoryRetention = 7 * 24 * time.Hour
+
 func (cfg *Cfg) readSmtpSettings() {
 	sec := cfg.Raw.Section("smtp")
 	cfg.Smtp.Enabled = sec.Key("enabled").MustBool(false)
@@ -33,9 +54,23 @@ func (cfg *Cfg) readSmtpSettings() {
 	cfg.Smtp.EhloIdentity = sec.Key("ehlo_identity").String()
 	cfg.Smtp.StartTLSPolicy = sec.Key("startTLS_policy").String()
 	cfg.Smtp.SkipVerify = sec.Key("skip_verify").MustBool(false)
+	cfg.Smtp.Provider = strings.ToLower(sec.Key("provider").MustString("smtp"))
+	cfg.Smtp.APIKey = sec.Key("api_key").String()
+	cfg.Smtp.APIURL = strings.TrimSuffix(sec.Key("api_url").String(), "/")
+	cfg.Smtp.APIRegion = sec.Key("api_region").String()
+	cfg.Smtp.MailgunDomain = sec.Key("mailgun_domain").String()
+	cfg.Smtp.AllowedSenderDomains = util.SplitString(strings.ToLower(sec.Key("allowed_sender_domains").String()))
 
 	emails := cfg.Raw.Section("emails")
 	cfg.Smtp.SendWelcomeEmailOnSignUp = emails.Key("welcome_email_on_sign_up").MustBool(false)
 	cfg.Smtp.TemplatesPatterns = util.SplitString(emails.Key("templates_pattern").MustString("emails/*.html, emails/*.txt"))
 	cfg.Smtp.ContentTypes = util.SplitString(emails.Key("content_types").MustString("text/html"))
+
+	retention := emails.Key("delivery_history_retention").MustString("7d")
+	cfg.Smtp.DeliveryHistoryRetention = defaultEmailDeliveryHistoryRetention
+	if d, err := gtime.ParseDuration(retention); err != nil {
+		cfg.Logger.Warn("Invalid emails delivery_history_retention, using the default", "value", retention, "error", err)
+	} else {
+		cfg.Smtp.DeliveryHistoryRetention = d
+	}
 }
@@ -52,6 +52,7 @@ const (
 	screenshotsDefaultCapture               = false
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
+	instanceLimitsDefaultAction             = "aggregate"
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -83,6 +84,7 @@ type UnifiedAlertingSettings struct {
 	DefaultRuleEvaluationInterval time.Duration
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
+	InstanceLimits                UnifiedAlertingInstanceLimitSettings
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -95,6 +97,13 @@ type UnifiedAlertingReservedLabelSettings struct {
 	DisabledLabels map[string]struct{}
 }
 
+type UnifiedAlertingInstanceLimitSettings struct {
+	// MaxInstancesPerRule is the maximum number of alert instances of a rule. Zero means no limit.
+	MaxInstancesPerRule int64
+	// Action is either "aggregate" or "drop".
+	Action string
+}
+
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
 // It hides the implementation details of the Enabled and simplifies its usage.
 func (u *UnifiedAlertingSettings) IsEnabled() bool {
@@ -294,6 +303,16 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.ReservedLabels = uaCfgReservedLabels
 
+	instanceLimits := iniFile.Section("unified_alerting.instance_limits")
+	uaCfgInstanceLimits := UnifiedAlertingInstanceLimitSettings{
+		MaxInstancesPerRule: instanceLimits.Key("max_instances_per_rule").MustInt64(0),
+		Action:              instanceLimits.Key("action").In(instanceLimitsDefaultAction, []string{"aggregate", "drop"}),
+	}
+	if uaCfgInstanceLimits.MaxInstancesPerRule < 0 {
+		return fmt.Errorf("value of setting 'max_instances_per_rule' should not be negative")
+	}
+	uaCfg.InstanceLimits = uaCfgInstanceLimits
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -0,0 +1,34 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type VariableCacheSettings struct {
+	Enabled bool
+	// TTL is how long a cached response is served.
+	TTL time.Duration
+	// TimeRangeBucket is the precision the time range of a request is rounded to before it is used
+	// in the cache key, so that requests of different viewers share the cached response.
+	TimeRangeBucket time.Duration
+	// DataSourceTypes are the data source plugins whose resource calls are cached.
+	DataSourceTypes []string
+	// MaxEntries is the number of cached responses above which new responses are not cached.
+	MaxEntries int
+}
+
+func readVariableCacheSettings(iniFile *ini.File) VariableCacheSettings {
+	s := VariableCacheSettings{}
+
+	variableCacheSection := iniFile.Section("variable_cache")
+	s.Enabled = variableCacheSection.Key("enabled").MustBool(false)
+	s.TTL = variableCacheSection.Key("ttl").MustDuration(time.Minute)
+	s.TimeRangeBucket = variableCacheSection.Key("time_range_bucket").MustDuration(5 * time.Minute)
+	s.DataSourceTypes = util.SplitString(variableCacheSection.Key("datasource_types").MustString("prometheus loki"))
+	s.MaxEntries = variableCacheSection.Key("max_entries").MustInt(10000)
+	return s
+}
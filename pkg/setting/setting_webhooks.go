@@ -0,0 +1,28 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type WebhooksSettings struct {
+	Enabled bool
+	// Timeout is the timeout of a single delivery attempt.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts after which a delivery is marked as failed.
+	MaxAttempts int
+	// DeliveryRetention is how long the delivery log is kept.
+	DeliveryRetention time.Duration
+}
+
+func readWebhooksSettings(iniFile *ini.File) WebhooksSettings {
+	s := WebhooksSettings{}
+
+	webhooksSection := iniFile.Section("webhooks")
+	s.Enabled = webhooksSection.Key("enabled").MustBool(true)
+	s.Timeout = webhooksSection.Key("timeout").MustDuration(10 * time.Second)
+	s.MaxAttempts = webhooksSection.Key("max_attempts").MustInt(5)
+	s.DeliveryRetention = webhooksSection.Key("delivery_retention").MustDuration(7 * 24 * time.Hour)
+	return s
+}
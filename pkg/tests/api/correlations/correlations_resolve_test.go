@@ -0,0 +1,136 @@
+package correlations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestIntegrationResolveCorrelations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := NewTestEnv(t)
+
+	viewerUser := User{
+		username: "viewer",
+		password: "viewer",
+	}
+	ctx.createUser(user.CreateUserCommand{
+		DefaultOrgRole: string(org.RoleViewer),
+		Password:       viewerUser.password,
+		Login:          viewerUser.username,
+	})
+
+	createDs := func(name, dsType string) *datasources.DataSource {
+		cmd := &datasources.AddDataSourceCommand{
+			Name:  name,
+			Type:  dsType,
+			OrgId: 1,
+		}
+		ctx.createDs(cmd)
+		return cmd.Result
+	}
+	logs := createDs("logs", "loki")
+	traces := createDs("traces", "tempo")
+	profiles := createDs("profiles", "phlare")
+
+	logsToTraces := ctx.createCorrelation(correlations.CreateCorrelationCommand{
+		SourceUID: logs.Uid,
+		TargetUID: &traces.Uid,
+		OrgId:     1,
+		Label:     "Logs to traces",
+		Config: correlations.CorrelationConfig{
+			Type:   correlations.ConfigTypeQuery,
+			Field:  "message",
+			Target: map[string]interface{}{"query": "${traceId}"},
+			Transformations: []correlations.Transformation{
+				{Type: correlations.TransformationRegex, Expression: `trace_id=(\w+)`, MapValue: "traceId"},
+				{Type: correlations.TransformationLabel, Field: "service_name", MapValue: "service"},
+			},
+		},
+	})
+	tracesToProfiles := ctx.createCorrelation(correlations.CreateCorrelationCommand{
+		SourceUID: traces.Uid,
+		TargetUID: &profiles.Uid,
+		OrgId:     1,
+		Label:     "Traces to profiles",
+		Config: correlations.CorrelationConfig{
+			Type:   correlations.ConfigTypeQuery,
+			Field:  "spanID",
+			Target: map[string]interface{}{"profileTypeId": "cpu", "labelSelector": `{service_name="$service"}`},
+		},
+	})
+	ctx.createCorrelation(correlations.CreateCorrelationCommand{
+		SourceUID: logs.Uid,
+		TargetUID: &profiles.Uid,
+		OrgId:     1,
+		Label:     "Unresolvable",
+		Config: correlations.CorrelationConfig{
+			Type:   correlations.ConfigTypeQuery,
+			Field:  "message",
+			Target: map[string]interface{}{"labelSelector": "${pod}"},
+		},
+	})
+
+	resolve := func(body string) []correlations.ResolvedCorrelation {
+		t.Helper()
+		res := ctx.Post(PostParams{
+			url:  fmt.Sprintf("/api/datasources/uid/%s/correlations/resolve", logs.Uid),
+			body: body,
+			user: viewerUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		var response []correlations.ResolvedCorrelation
+		require.NoError(t, json.Unmarshal(responseBody, &response))
+		return response
+	}
+	row := `"field": "message", "fields": {"message": "level=error trace_id=abc123"}, "labels": {"service_name": "checkout"}`
+
+	t.Run("resolves the correlations of a field with their transformations", func(t *testing.T) {
+		response := resolve(`{` + row + `}`)
+		require.Len(t, response, 1)
+		require.Equal(t, logsToTraces.UID, response[0].UID)
+		require.Equal(t, traces.Uid, response[0].TargetUID)
+		require.Equal(t, map[string]interface{}{"query": "abc123"}, response[0].Query)
+		require.Equal(t, "checkout", response[0].Variables["service"])
+		require.Equal(t, []string{logsToTraces.UID}, response[0].Path)
+	})
+
+	t.Run("follows the correlations of the target data sources", func(t *testing.T) {
+		response := resolve(`{` + row + `, "maxHops": 2}`)
+		require.Len(t, response, 2)
+		require.Equal(t, tracesToProfiles.UID, response[1].UID)
+		require.Equal(t, `{service_name="checkout"}`, response[1].Query["labelSelector"])
+		require.Equal(t, []string{logsToTraces.UID, tracesToProfiles.UID}, response[1].Path)
+	})
+
+	t.Run("skips correlations of other fields", func(t *testing.T) {
+		response := resolve(`{"field": "level", "fields": {"level": "error"}}`)
+		require.Len(t, response, 0)
+	})
+
+	t.Run("returns 404 for an unknown source data source", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  "/api/datasources/uid/nonexistent/correlations/resolve",
+			body: `{}`,
+			user: viewerUser,
+		})
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
@@ -0,0 +1,582 @@
+package sqleng
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrQueryNotAllowed is returned for queries rejected by the query policy of a data source.
+var ErrQueryNotAllowed = errors.New("query not allowed")
+
+type sqlDialect int
+
+const (
+	dialectPostgres sqlDialect = iota
+	dialectMySQL
+	dialectMSSQL
+)
+
+func dialectOf(driverName string) sqlDialect {
+	switch driverName {
+	case "mysql":
+		return dialectMySQL
+	case "mssql", "sqlserver":
+		return dialectMSSQL
+	}
+	return dialectPostgres
+}
+
+// forbiddenKeywords are rejected anywhere in a read-only query. They either start statements which
+// modify data or the schema, or write the result of a SELECT statement somewhere.
+var forbiddenKeywords = map[string]bool{
+	"ALTER": true, "CREATE": true, "DELETE": true, "DROP": true, "GRANT": true, "INSERT": true,
+	"INTO": true, "MERGE": true, "REVOKE": true, "TRUNCATE": true, "UPDATE": true,
+}
+
+// forbiddenMSSQLKeywords start statements in SQL Server, which runs statements in a batch without a
+// separating semicolon.
+var forbiddenMSSQLKeywords = map[string]bool{
+	"BACKUP": true, "BEGIN": true, "BULK": true, "CHECKPOINT": true, "CLOSE": true, "COMMIT": true,
+	"DBCC": true, "DEALLOCATE": true, "DECLARE": true, "DENY": true, "DISABLE": true, "ENABLE": true,
+	"EXEC": true, "EXECUTE": true, "GOTO": true, "IF": true, "KILL": true, "OPEN": true, "PRINT": true,
+	"RAISERROR": true, "READTEXT": true, "RECONFIGURE": true, "RESTORE": true, "RETURN": true,
+	"REVERT": true, "ROLLBACK": true, "SAVE": true, "SET": true, "SETUSER": true, "SHUTDOWN": true,
+	"THROW": true, "UPDATETEXT": true, "USE": true, "WAITFOR": true, "WHILE": true, "WRITETEXT": true,
+}
+
+// forbiddenFunctions run a query passed as a string, read other tables by name or reach other
+// servers and files, so the statements they run escape the policy.
+var forbiddenFunctions = map[sqlDialect]map[string]bool{
+	dialectPostgres: {
+		"query_to_xml": true, "query_to_xml_and_xmlschema": true, "query_to_xmlschema": true,
+		"cursor_to_xml": true, "table_to_xml": true, "table_to_xml_and_xmlschema": true,
+		"table_to_xmlschema": true, "schema_to_xml": true, "schema_to_xml_and_xmlschema": true,
+		"schema_to_xmlschema": true, "database_to_xml": true, "database_to_xml_and_xmlschema": true,
+		"database_to_xmlschema": true, "ts_stat": true, "dblink": true, "dblink_exec": true,
+		"dblink_open": true, "dblink_fetch": true, "dblink_send_query": true, "dblink_get_result": true,
+		"lo_import": true, "lo_export": true, "pg_read_file": true, "pg_read_binary_file": true,
+		"pg_ls_dir": true, "pg_stat_file": true,
+	},
+	dialectMySQL: {
+		"load_file": true,
+	},
+	dialectMSSQL: {
+		"openrowset": true, "openquery": true, "opendatasource": true,
+	},
+}
+
+// fromClauseEnd are the keywords ending the list of tables of a FROM clause.
+var fromClauseEnd = map[string]bool{
+	"EXCEPT": true, "FETCH": true, "FOR": true, "GROUP": true, "HAVING": true, "INTERSECT": true,
+	"INTO": true, "LIMIT": true, "MINUS": true, "OFFSET": true, "OPTION": true, "ORDER": true,
+	"RETURNING": true, "UNION": true, "WHERE": true, "WINDOW": true,
+}
+
+// queryPolicy restricts the queries of a data source. It is configured in the settings of the data
+// source, so Editors can't run DDL or DML statements through a panel query.
+type queryPolicy struct {
+	dialect sqlDialect
+	// readOnly rejects everything but a single SELECT statement.
+	readOnly bool
+	// allowedTables are the tables SELECT statements can read, as table, schema.table or schema.*.
+	// Setting allowed tables implies readOnly.
+	allowedTables []string
+	// rowLevelSecurity sets the identity of the user as session variables. It implies readOnly and
+	// rejects queries changing the session variables.
+	rowLevelSecurity bool
+}
+
+func newQueryPolicy(driverName string, jsonData JsonData) queryPolicy {
+	p := queryPolicy{
+		dialect:          dialectOf(driverName),
+		readOnly:         jsonData.ReadOnly,
+		rowLevelSecurity: jsonData.RowLevelSecurity,
+	}
+	for _, t := range jsonData.AllowedTables {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			p.allowedTables = append(p.allowedTables, t)
+		}
+	}
+	return p
+}
+
+func (p queryPolicy) enabled() bool {
+	return p.readOnly || len(p.allowedTables) > 0 || p.rowLevelSecurity
+}
+
+// check parses an interpolated query and returns an error wrapping ErrQueryNotAllowed if the
+// policy rejects it.
+func (p queryPolicy) check(sql string) error {
+	if !p.enabled() {
+		return nil
+	}
+	tokens, err := tokenizeSQL(sql, p.dialect)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrQueryNotAllowed, err)
+	}
+
+	var statements [][]sqlToken
+	start := 0
+	for i, t := range tokens {
+		if t.isSymbol(';') {
+			if i > start {
+				statements = append(statements, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		statements = append(statements, tokens[start:])
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+	if len(statements) > 1 {
+		return fmt.Errorf("%w: a query must be a single statement", ErrQueryNotAllowed)
+	}
+	stmt := statements[0]
+
+	first := 0
+	for first < len(stmt) && stmt[first].isSymbol('(') {
+		first++
+	}
+	if first == len(stmt) || !(stmt[first].isWord("SELECT") || stmt[first].isWord("WITH")) {
+		return fmt.Errorf("%w: only SELECT statements are allowed", ErrQueryNotAllowed)
+	}
+	for i, t := range stmt {
+		if p.rowLevelSecurity {
+			if err := checkSessionVariableChange(stmt, i, p.dialect); err != nil {
+				return err
+			}
+		}
+		if t.isName() && forbiddenFunctions[p.dialect][t.name()] {
+			return fmt.Errorf("%w: %s is not allowed in a SELECT statement", ErrQueryNotAllowed, t.text)
+		}
+		if t.kind != tokenWord {
+			continue
+		}
+		// MySQL has functions named like statements, such as INSERT(str, pos, len, newstr).
+		if p.dialect == dialectMySQL && i+1 < len(stmt) && stmt[i+1].isSymbol('(') {
+			continue
+		}
+		if forbiddenKeywords[t.upper] || (p.dialect == dialectMSSQL && forbiddenMSSQLKeywords[t.upper]) {
+			return fmt.Errorf("%w: %s is not allowed in a SELECT statement", ErrQueryNotAllowed, t.upper)
+		}
+	}
+
+	if len(p.allowedTables) == 0 {
+		return nil
+	}
+	ctes := cteNames(stmt)
+	for _, ref := range tableReferences(stmt) {
+		if ref.schema == "" && ctes[ref.name] {
+			continue
+		}
+		if p.dialect == dialectMySQL && ref.schema == "" && ref.name == "dual" {
+			continue
+		}
+		if !p.allowed(ref) {
+			return fmt.Errorf("%w: table %s is not in the allow list", ErrQueryNotAllowed, ref)
+		}
+	}
+	return nil
+}
+
+// checkSessionVariableChange rejects the token at i if it can change the session variables holding
+// the identity of the user.
+func checkSessionVariableChange(stmt []sqlToken, i int, dialect sqlDialect) error {
+	t := stmt[i]
+	if t.isName() && (t.name() == "set_config" || t.name() == "sp_set_session_context") {
+		return fmt.Errorf("%w: %s is not allowed with row-level security", ErrQueryNotAllowed, t.text)
+	}
+	// SELECT @var := value assigns a user variable in MySQL.
+	if dialect == dialectMySQL && t.isSymbol(':') && i+1 < len(stmt) && stmt[i+1].isSymbol('=') {
+		return fmt.Errorf("%w: assigning variables is not allowed with row-level security", ErrQueryNotAllowed)
+	}
+	return nil
+}
+
+// allowed returns true if a table matches an entry of the allow list. Tables referenced without a
+// schema only match entries without a schema.
+func (p queryPolicy) allowed(ref tableRef) bool {
+	for _, entry := range p.allowedTables {
+		schema, name, qualified := strings.Cut(entry, ".")
+		if !qualified {
+			if ref.schema == "" && entry == ref.name {
+				return true
+			}
+			continue
+		}
+		if ref.schema == schema && (name == "*" || name == ref.name) {
+			return true
+		}
+	}
+	return false
+}
+
+type tableRef struct {
+	schema string
+	name   string
+}
+
+func (r tableRef) String() string {
+	if r.schema == "" {
+		return r.name
+	}
+	return r.schema + "." + r.name
+}
+
+// tableReferences returns the tables and table functions of the FROM clauses and joins of a
+// statement, including those of subqueries.
+func tableReferences(stmt []sqlToken) []tableRef {
+	var refs []tableRef
+	// selects and fromLists track, for every level of parentheses, whether it holds a SELECT
+	// statement and whether the tokens are in the list of tables of a FROM clause.
+	selects := []bool{false}
+	fromLists := []bool{false}
+	depth := 0
+
+	// readRef reads the table reference starting at i and returns the index of its last token.
+	var readRef func(i int) int
+	readRef = func(i int) int {
+		for i < len(stmt) && (stmt[i].isWord("LATERAL") || stmt[i].isWord("ONLY")) {
+			i++
+		}
+		if i >= len(stmt) {
+			return i
+		}
+		if stmt[i].isSymbol('(') {
+			j := i
+			for j < len(stmt) && stmt[j].isSymbol('(') {
+				j++
+			}
+			if j < len(stmt) && (stmt[j].isWord("SELECT") || stmt[j].isWord("WITH") || stmt[j].isWord("VALUES")) {
+				return i - 1
+			}
+			// A parenthesized join, rather than a subquery, continues the list of tables.
+			for ; i < j; i++ {
+				depth++
+				selects = append(selects[:depth], true)
+				fromLists = append(fromLists[:depth], true)
+			}
+			return readRef(j)
+		}
+		if !stmt[i].isName() {
+			return i - 1
+		}
+		parts := []string{stmt[i].name()}
+		for i+1 < len(stmt) && stmt[i+1].isSymbol('.') {
+			i++
+			if i+1 < len(stmt) && stmt[i+1].isName() {
+				i++
+				parts = append(parts, stmt[i].name())
+			} else {
+				parts = append(parts, "")
+			}
+		}
+		ref := tableRef{name: parts[len(parts)-1]}
+		if len(parts) > 1 {
+			ref.schema = parts[len(parts)-2]
+		}
+		refs = append(refs, ref)
+		return i
+	}
+
+	for i := 0; i < len(stmt); i++ {
+		t := stmt[i]
+		switch {
+		case t.isSymbol('('):
+			depth++
+			selects = append(selects[:depth], false)
+			fromLists = append(fromLists[:depth], false)
+		case t.isSymbol(')'):
+			if depth > 0 {
+				depth--
+			}
+		case t.isSymbol(','):
+			if fromLists[depth] {
+				i = readRef(i + 1)
+			}
+		case t.kind != tokenWord:
+		case t.upper == "SELECT":
+			selects[depth] = true
+			fromLists[depth] = false
+		case t.upper == "TABLE":
+			// TABLE name is short for SELECT * FROM name in PostgreSQL.
+			i = readRef(i + 1)
+		case t.upper == "FROM":
+			// FROM is also part of expressions, like EXTRACT(EPOCH FROM time) and a IS DISTINCT FROM b.
+			if !selects[depth] || (i > 1 && stmt[i-1].isWord("DISTINCT") && (stmt[i-2].isWord("IS") || stmt[i-2].isWord("NOT"))) {
+				continue
+			}
+			fromLists[depth] = true
+			i = readRef(i + 1)
+		case t.upper == "JOIN" || t.upper == "STRAIGHT_JOIN" || t.upper == "APPLY":
+			if selects[depth] {
+				i = readRef(i + 1)
+			}
+		case fromClauseEnd[t.upper]:
+			fromLists[depth] = false
+		}
+	}
+	return refs
+}
+
+// cteNames returns the names of the common table expressions of a statement, which are written as
+// name AS (...) or name (columns) AS (...) after WITH.
+func cteNames(stmt []sqlToken) map[string]bool {
+	names := map[string]bool{}
+	with := false
+	for i, t := range stmt {
+		if t.isWord("WITH") {
+			with = true
+		}
+		if !with || !t.isName() {
+			continue
+		}
+		j := i + 1
+		if j < len(stmt) && stmt[j].isSymbol('(') {
+			for j < len(stmt) && !stmt[j].isSymbol(')') {
+				j++
+			}
+			j++
+		}
+		if j >= len(stmt) || !stmt[j].isWord("AS") {
+			continue
+		}
+		j++
+		for j < len(stmt) && (stmt[j].isWord("NOT") || stmt[j].isWord("MATERIALIZED")) {
+			j++
+		}
+		if j < len(stmt) && stmt[j].isSymbol('(') {
+			names[t.name()] = true
+		}
+	}
+	return names
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type sqlToken struct {
+	kind tokenKind
+	// text is the unquoted identifier of quoted identifiers, and the source of other tokens.
+	text string
+	// upper is the upper-cased text of words.
+	upper string
+}
+
+func (t sqlToken) isWord(keyword string) bool {
+	return t.kind == tokenWord && t.upper == keyword
+}
+
+func (t sqlToken) isSymbol(r rune) bool {
+	return t.kind == tokenSymbol && t.text == string(r)
+}
+
+func (t sqlToken) isName() bool {
+	return t.kind == tokenWord || t.kind == tokenQuotedIdentifier
+}
+
+func (t sqlToken) name() string {
+	return strings.ToLower(t.text)
+}
+
+// tokenizeSQL splits a query into tokens, skipping comments. Where the dialects differ, it errs on
+// the side of treating text as SQL rather than as a string or comment, so nothing the database
+// executes is hidden from the policy.
+func tokenizeSQL(sql string, dialect sqlDialect) ([]sqlToken, error) {
+	var tokens []sqlToken
+	// executableComment is set inside a MySQL /*! ... */ comment, whose content is executed.
+	executableComment := false
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		next := byte(0)
+		if i+1 < len(sql) {
+			next = sql[i+1]
+		}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+
+		case c == '-' && next == '-' && (dialect != dialectMySQL || i+2 == len(sql) || sql[i+2] <= ' '),
+			c == '#' && dialect == dialectMySQL:
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+
+		case c == '*' && next == '/' && executableComment:
+			executableComment = false
+			i += 2
+
+		case c == '/' && next == '*':
+			if dialect == dialectMySQL && i+2 < len(sql) && sql[i+2] == '!' {
+				executableComment = true
+				i += 3
+				for i < len(sql) && sql[i] >= '0' && sql[i] <= '9' {
+					i++
+				}
+				continue
+			}
+			end, err := skipBlockComment(sql, i, dialect != dialectMySQL)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '\'':
+			end, err := skipQuoted(sql, i, '\'', dialect == dialectMySQL)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: sql[i:end]})
+			i = end
+
+		case c == '"' && dialect == dialectMySQL:
+			end, err := skipQuoted(sql, i, '"', true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: sql[i:end]})
+			i = end
+
+		case c == '"' || (c == '`' && dialect == dialectMySQL):
+			end, err := skipQuoted(sql, i, c, false)
+			if err != nil {
+				return nil, err
+			}
+			ident := strings.ReplaceAll(sql[i+1:end-1], string([]byte{c, c}), string(c))
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdentifier, text: ident})
+			i = end
+
+		case c == '[' && dialect == dialectMSSQL:
+			end, err := skipQuoted(sql, i, ']', false)
+			if err != nil {
+				return nil, err
+			}
+			ident := strings.ReplaceAll(sql[i+1:end-1], "]]", "]")
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdentifier, text: ident})
+			i = end
+
+		case c == '$' && dialect == dialectPostgres && dollarTag(sql[i:]) != "":
+			tag := dollarTag(sql[i:])
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return nil, errors.New("unterminated dollar-quoted string")
+			}
+			end += i + 2*len(tag)
+			tokens = append(tokens, sqlToken{kind: tokenString, text: sql[i:end]})
+			i = end
+
+		case c >= '0' && c <= '9':
+			end := i + 1
+			for end < len(sql) && (isIdentifierByte(sql[end]) || sql[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: sql[i:end]})
+			i = end
+
+		case isIdentifierStart(sql[i:]):
+			end := i + 1
+			for end < len(sql) && (isIdentifierByte(sql[end]) || sql[end] >= utf8.RuneSelf) {
+				end++
+			}
+			word := sql[i:end]
+			// E'...' is a PostgreSQL string with backslash escapes.
+			if dialect == dialectPostgres && (word == "E" || word == "e") && end < len(sql) && sql[end] == '\'' {
+				stringEnd, err := skipQuoted(sql, end, '\'', true)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, sqlToken{kind: tokenString, text: sql[i:stringEnd]})
+				i = stringEnd
+				continue
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, text: word, upper: strings.ToUpper(word)})
+			i = end
+
+		default:
+			r, size := utf8.DecodeRuneInString(sql[i:])
+			tokens = append(tokens, sqlToken{kind: tokenSymbol, text: string(r)})
+			i += size
+		}
+	}
+	return tokens, nil
+}
+
+// skipQuoted returns the index after the quoted string or identifier starting at i. The closing
+// quote is escaped by doubling it, or with a backslash if backslashEscapes is set.
+func skipQuoted(sql string, i int, closing byte, backslashEscapes bool) (int, error) {
+	for j := i + 1; j < len(sql); j++ {
+		switch {
+		case backslashEscapes && sql[j] == '\\':
+			j++
+		case sql[j] == closing:
+			if j+1 < len(sql) && sql[j+1] == closing {
+				j++
+				continue
+			}
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted string or identifier starting with %c", sql[i])
+}
+
+// skipBlockComment returns the index after the block comment starting at i. PostgreSQL and SQL
+// Server support nested comments, MySQL ends a comment at the first */.
+func skipBlockComment(sql string, i int, nested bool) (int, error) {
+	depth := 0
+	for j := i; j+1 < len(sql); j++ {
+		switch {
+		case sql[j] == '/' && sql[j+1] == '*':
+			if depth == 0 || nested {
+				depth++
+			}
+			j++
+		case sql[j] == '*' && sql[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1, nil
+			}
+		}
+	}
+	return 0, errors.New("unterminated comment")
+}
+
+// dollarTag returns the tag, like $$ or $body$, if s starts with a PostgreSQL dollar-quoted string.
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		switch {
+		case s[j] == '$':
+			return s[:j+1]
+		case isIdentifierByte(s[j]) && !(j == 1 && s[j] >= '0' && s[j] <= '9'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentifierStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,133 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPolicyReadOnly(t *testing.T) {
+	allowed := map[sqlDialect][]string{
+		dialectPostgres: {
+			`SELECT 1`,
+			`select "time", value from metrics where "time" BETWEEN '2022-12-01T00:00:00Z' AND '2022-12-01T01:00:00Z';`,
+			`(SELECT 1) UNION (SELECT 2)`,
+			`WITH recent AS (SELECT * FROM metrics) SELECT * FROM recent`,
+			`SELECT 'DROP TABLE metrics; DELETE FROM metrics' AS text`,
+			`SELECT $body$ ; DROP TABLE metrics $body$`,
+			`SELECT E'it\'s; DROP TABLE metrics'`,
+			`SELECT "update" FROM metrics -- DELETE FROM metrics`,
+			`SELECT 1 /* outer /* nested */ DROP TABLE metrics */`,
+			`SELECT floor(extract(epoch from "time")/60)*60 AS "time" FROM metrics`,
+		},
+		dialectMySQL: {
+			`SELECT INSERT('Quadratic', 3, 4, 'What')`,
+			`SELECT 'it\'s; DROP TABLE metrics'`,
+			`SELECT "a;b" FROM metrics # DROP TABLE metrics`,
+			"SELECT `update` FROM metrics",
+		},
+		dialectMSSQL: {
+			`SELECT TOP 10 [time], value FROM dbo.metrics WITH (NOLOCK) ORDER BY [time] OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY`,
+			`SELECT [set] FROM metrics`,
+		},
+	}
+	for dialect, queries := range allowed {
+		p := queryPolicy{dialect: dialect, readOnly: true}
+		for _, q := range queries {
+			assert.NoError(t, p.check(q), q)
+		}
+	}
+
+	rejected := map[sqlDialect][]string{
+		dialectPostgres: {
+			`DELETE FROM metrics`,
+			`SELECT 1; DROP TABLE metrics`,
+			`WITH gone AS (DELETE FROM metrics RETURNING *) SELECT * FROM gone`,
+			`SELECT * INTO copy FROM metrics`,
+			`SELECT * FROM metrics FOR UPDATE`,
+			`SELECT 'unterminated`,
+			`SELECT 1 /* outer /* nested */ */ ; DROP TABLE metrics`,
+			`SELECT '\'; DROP TABLE metrics; --'`,
+			`COPY metrics TO '/tmp/metrics.csv'`,
+			`SELECT dblink_exec('dbname=grafana', 'DROP TABLE metrics')`,
+		},
+		dialectMySQL: {
+			`SELECT 'a\''; DROP TABLE metrics; SELECT '`,
+			`SELECT 1 /*!50000 ; DROP TABLE metrics */`,
+			`SELECT 1 --1; DROP TABLE metrics`,
+			`SELECT * FROM metrics INTO OUTFILE '/tmp/metrics.csv'`,
+			`REPLACE INTO metrics VALUES (1)`,
+		},
+		dialectMSSQL: {
+			`SELECT * FROM OPENROWSET('SQLNCLI', 'Server=other;', 'DELETE FROM dbo.metrics')`,
+			`SELECT 1 EXEC xp_cmdshell 'dir'`,
+			`SELECT 1 WAITFOR DELAY '00:00:10'`,
+			`SELECT 1 DROP TABLE metrics`,
+		},
+	}
+	for dialect, queries := range rejected {
+		p := queryPolicy{dialect: dialect, readOnly: true}
+		for _, q := range queries {
+			assert.ErrorIs(t, p.check(q), ErrQueryNotAllowed, q)
+		}
+	}
+}
+
+func TestQueryPolicyAllowedTables(t *testing.T) {
+	p := newQueryPolicy("postgres", JsonData{AllowedTables: []string{" Metrics ", "public.hosts", "reporting.*", ""}})
+	require.Equal(t, []string{"metrics", "public.hosts", "reporting.*"}, p.allowedTables)
+
+	for _, q := range []string{
+		`SELECT * FROM metrics`,
+		`SELECT * FROM "Metrics" m JOIN public.hosts h ON m.host = h.id`,
+		`SELECT * FROM reporting.daily, reporting.weekly WHERE 1 = 1`,
+		`SELECT * FROM (SELECT * FROM metrics) m, public.hosts`,
+		`WITH recent AS (SELECT * FROM metrics), hosts (id) AS (SELECT 1) SELECT * FROM recent JOIN hosts USING (id)`,
+		`SELECT extract(epoch from "time"), substring(name from 1 for 3) FROM metrics WHERE a IS DISTINCT FROM b`,
+		`SELECT * FROM metrics WHERE host IN (SELECT id FROM public.hosts)`,
+	} {
+		assert.NoError(t, p.check(q), q)
+	}
+
+	for _, q := range []string{
+		`SELECT * FROM secrets`,
+		`SELECT * FROM public.metrics`,
+		`SELECT * FROM other.hosts`,
+		`SELECT * FROM metrics, secrets`,
+		`SELECT * FROM metrics m LEFT JOIN secrets s ON m.id = s.id`,
+		`SELECT * FROM (SELECT * FROM metrics) m, secrets`,
+		`SELECT * FROM ((metrics JOIN secrets ON true))`,
+		`SELECT * FROM metrics WHERE id IN (SELECT id FROM secrets)`,
+		`SELECT (SELECT count(*) FROM secrets)`,
+		`SELECT * FROM metrics WHERE id IN (TABLE secrets)`,
+		`SELECT query_to_xml('SELECT * FROM secrets', true, false, '') FROM metrics`,
+		`SELECT xpath('/row/password/text()', query_to_xml('SELECT * FROM secrets', true, true, ''))`,
+		`SELECT pg_catalog."table_to_xml"('secrets', true, false, '')`,
+		`SELECT * FROM metrics, dblink('dbname=other', 'SELECT secret FROM secrets') AS t(secret text)`,
+		`SELECT dblink_exec('dbname=grafana', 'DROP TABLE metrics') FROM metrics`,
+		`SELECT pg_read_file('/etc/passwd') FROM metrics`,
+		`SELECT * FROM generate_series(1, 10)`,
+		`SELECT DISTINCT FROM secrets`,
+		`INSERT INTO metrics VALUES (1)`,
+	} {
+		assert.ErrorIs(t, p.check(q), ErrQueryNotAllowed, q)
+	}
+
+	mssql := newQueryPolicy("mssql", JsonData{AllowedTables: []string{"dbo.metrics"}})
+	assert.NoError(t, mssql.check(`SELECT * FROM [grafana].[dbo].[metrics]`))
+	assert.ErrorIs(t, mssql.check(`SELECT * FROM grafana.dbo.secrets CROSS APPLY dbo.metrics`), ErrQueryNotAllowed)
+	assert.ErrorIs(t, mssql.check(`SELECT * FROM dbo.metrics, OPENROWSET('SQLNCLI', 'Server=other;', 'SELECT * FROM dbo.secrets') AS s`), ErrQueryNotAllowed)
+	assert.ErrorIs(t, mssql.check(`SELECT * FROM dbo.metrics, OPENQUERY(linked, 'SELECT * FROM dbo.secrets') AS s`), ErrQueryNotAllowed)
+
+	mysql := newQueryPolicy("mysql", JsonData{AllowedTables: []string{"metrics"}})
+	assert.NoError(t, mysql.check("SELECT 1 FROM DUAL"))
+	assert.ErrorIs(t, mysql.check("SELECT * FROM `secrets`"), ErrQueryNotAllowed)
+	assert.ErrorIs(t, mysql.check("SELECT * FROM metrics /*!50000 , secrets */"), ErrQueryNotAllowed)
+	assert.ErrorIs(t, mysql.check("SELECT LOAD_FILE('/etc/passwd') FROM metrics"), ErrQueryNotAllowed)
+}
+
+func TestQueryPolicyDisabled(t *testing.T) {
+	p := newQueryPolicy("postgres", JsonData{})
+	assert.NoError(t, p.check(`DROP TABLE metrics`))
+}
@@ -0,0 +1,153 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+)
+
+// ErrNoUserIdentity is returned for queries which need the identity of the user when the request
+// doesn't have a user, like queries of alert rules.
+var ErrNoUserIdentity = errors.New("the query requires a signed-in user")
+
+var userMacroPattern = regexp.MustCompile(`\$__user(Id|Login|Email|Name|Role|Teams)\b`)
+
+// userIdentity is the identity of the user sending a query. It is taken from the request the
+// Grafana server sends to the plugin, never from the query model, so users can't impersonate others.
+type userIdentity struct {
+	id    int64
+	login string
+	email string
+	name  string
+	role  string
+	// teams are the ids of the teams of the user in the organization. Ids are used rather than names,
+	// since team administrators can rename their teams.
+	teams []int64
+}
+
+// identityFromRequest returns the identity of the user sending the query, or nil if the request
+// doesn't have a user with an id, so that queries are never run for a user matching every row.
+func identityFromRequest(req *backend.QueryDataRequest) *userIdentity {
+	u := req.PluginContext.User
+	if u == nil {
+		return nil
+	}
+	id, err := strconv.ParseInt(req.Headers[adapters.HeaderUserID], 10, 64)
+	if err != nil || id <= 0 {
+		return nil
+	}
+	identity := &userIdentity{
+		id:    id,
+		login: u.Login,
+		email: u.Email,
+		name:  u.Name,
+		role:  u.Role,
+	}
+	for _, s := range strings.Split(req.Headers[adapters.HeaderUserTeams], ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+			identity.teams = append(identity.teams, id)
+		}
+	}
+	return identity
+}
+
+func (u *userIdentity) teamList() string {
+	ids := make([]string, 0, len(u.teams))
+	for _, id := range u.teams {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(ids, ",")
+}
+
+// interpolateUser replaces the $__userId, $__userLogin, $__userEmail, $__userName, $__userRole and
+// $__userTeams macros. Strings are replaced with quoted literals and the teams with a list of ids, or
+// NULL if the user is not in a team, so team_id IN ($__userTeams) matches nothing. Unlike the
+// ${__user.login} variable, which the browser interpolates, the macros are interpolated on the server.
+func interpolateUser(sql string, identity *userIdentity, dialect sqlDialect) (string, error) {
+	if !userMacroPattern.MatchString(sql) {
+		return sql, nil
+	}
+	if identity == nil {
+		return sql, ErrNoUserIdentity
+	}
+	return userMacroPattern.ReplaceAllStringFunc(sql, func(macro string) string {
+		switch strings.TrimPrefix(macro, "$__user") {
+		case "Id":
+			return strconv.FormatInt(identity.id, 10)
+		case "Login":
+			return quoteString(identity.login, dialect)
+		case "Email":
+			return quoteString(identity.email, dialect)
+		case "Name":
+			return quoteString(identity.name, dialect)
+		case "Role":
+			return quoteString(identity.role, dialect)
+		}
+		if len(identity.teams) == 0 {
+			return "NULL"
+		}
+		return identity.teamList()
+	}), nil
+}
+
+// quoteString returns a string literal. MySQL treats backslashes in strings as escape characters.
+func quoteString(s string, dialect sqlDialect) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	if dialect == dialectMySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	s = "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	if dialect == dialectMSSQL {
+		return "N" + s
+	}
+	return s
+}
+
+// sessionVariables are the names of the session variables holding the identity of the user, in the
+// order of the values returned by sessionValues.
+var sessionVariables = []string{"user_id", "user_login", "user_email", "user_name", "user_role", "user_teams"}
+
+func (u *userIdentity) sessionValues() []interface{} {
+	return []interface{}{strconv.FormatInt(u.id, 10), u.login, u.email, u.name, u.role, u.teamList()}
+}
+
+// setSessionIdentity stores the identity of the user in session variables of the connection, which
+// row-level security policies, views and functions of the database read. The variables are set
+// before every query, so a pooled connection never keeps the identity of a previous user.
+//
+// PostgreSQL: current_setting('grafana.user_login')
+// MySQL: @grafana_user_login
+// SQL Server: SESSION_CONTEXT(N'grafana_user_login')
+func setSessionIdentity(ctx context.Context, conn *sql.Conn, dialect sqlDialect, identity *userIdentity) error {
+	statements := make([]string, 0, len(sessionVariables))
+	for i, name := range sessionVariables {
+		switch dialect {
+		case dialectPostgres:
+			statements = append(statements, fmt.Sprintf("set_config('grafana.%s', $%d, false)", name, i+1))
+		case dialectMySQL:
+			statements = append(statements, fmt.Sprintf("@grafana_%s = ?", name))
+		case dialectMSSQL:
+			statements = append(statements, fmt.Sprintf("EXEC sp_set_session_context N'grafana_%s', ?;", name))
+		}
+	}
+
+	var query string
+	switch dialect {
+	case dialectPostgres:
+		query = "SELECT " + strings.Join(statements, ", ")
+	case dialectMySQL:
+		query = "SET " + strings.Join(statements, ", ")
+	case dialectMSSQL:
+		query = strings.Join(statements, " ")
+	}
+	_, err := conn.ExecContext(ctx, query, identity.sessionValues()...)
+	return err
+}
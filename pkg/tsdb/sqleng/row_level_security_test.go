@@ -0,0 +1,78 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+)
+
+func TestIdentityFromRequest(t *testing.T) {
+	assert.Nil(t, identityFromRequest(&backend.QueryDataRequest{}))
+
+	user := &backend.User{Login: "jdoe", Email: "jdoe@example.com", Name: "J. Doe", Role: "Viewer"}
+	for _, headers := range []map[string]string{nil, {adapters.HeaderUserID: "0"}, {adapters.HeaderUserID: "-1"}, {adapters.HeaderUserID: "x"}} {
+		assert.Nil(t, identityFromRequest(&backend.QueryDataRequest{PluginContext: backend.PluginContext{User: user}, Headers: headers}))
+	}
+
+	identity := identityFromRequest(&backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{User: user},
+		Headers:       map[string]string{adapters.HeaderUserID: "7", adapters.HeaderUserTeams: "3, 12,x"},
+	})
+	require.NotNil(t, identity)
+	assert.Equal(t, &userIdentity{id: 7, login: "jdoe", email: "jdoe@example.com", name: "J. Doe", role: "Viewer", teams: []int64{3, 12}}, identity)
+}
+
+func TestInterpolateUser(t *testing.T) {
+	identity := &userIdentity{id: 7, login: `o'brien\`, email: "ob@example.com", name: "O'Brien", role: "Editor", teams: []int64{3, 12}}
+	query := `SELECT * FROM metrics WHERE owner = $__userLogin AND id = $__userId AND team_id IN ($__userTeams) AND $__userRole = 'Editor'`
+
+	for dialect, expected := range map[sqlDialect]string{
+		dialectPostgres: `SELECT * FROM metrics WHERE owner = 'o''brien\' AND id = 7 AND team_id IN (3,12) AND 'Editor' = 'Editor'`,
+		dialectMySQL:    `SELECT * FROM metrics WHERE owner = 'o''brien\\' AND id = 7 AND team_id IN (3,12) AND 'Editor' = 'Editor'`,
+		dialectMSSQL:    `SELECT * FROM metrics WHERE owner = N'o''brien\' AND id = 7 AND team_id IN (3,12) AND N'Editor' = 'Editor'`,
+	} {
+		sql, err := interpolateUser(query, identity, dialect)
+		require.NoError(t, err)
+		assert.Equal(t, expected, sql)
+	}
+
+	t.Run("user without teams", func(t *testing.T) {
+		sql, err := interpolateUser(`SELECT * FROM metrics WHERE team_id IN ($__userTeams)`, &userIdentity{login: "jdoe"}, dialectPostgres)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM metrics WHERE team_id IN (NULL)`, sql)
+	})
+
+	t.Run("other macros are left as they are", func(t *testing.T) {
+		query := `SELECT $__userLogins, $__user.login, $__timeFilter(time) FROM metrics`
+		sql, err := interpolateUser(query, nil, dialectPostgres)
+		require.NoError(t, err)
+		assert.Equal(t, query, sql)
+	})
+
+	t.Run("query without user", func(t *testing.T) {
+		_, err := interpolateUser(`SELECT $__userLogin`, nil, dialectPostgres)
+		assert.ErrorIs(t, err, ErrNoUserIdentity)
+	})
+}
+
+func TestQueryPolicyRowLevelSecurity(t *testing.T) {
+	pg := newQueryPolicy("postgres", JsonData{RowLevelSecurity: true})
+	assert.NoError(t, pg.check(`SELECT current_setting('grafana.user_login')`))
+	assert.ErrorIs(t, pg.check(`DELETE FROM metrics`), ErrQueryNotAllowed)
+	assert.ErrorIs(t, pg.check(`SELECT set_config('grafana.user_login', 'admin', false), * FROM metrics`), ErrQueryNotAllowed)
+	assert.ErrorIs(t, pg.check(`SELECT "set_config"('grafana.user_login', 'admin', false)`), ErrQueryNotAllowed)
+
+	mysql := newQueryPolicy("mysql", JsonData{RowLevelSecurity: true})
+	assert.NoError(t, mysql.check(`SELECT * FROM metrics WHERE owner = @grafana_user_login`))
+	assert.ErrorIs(t, mysql.check(`SELECT @grafana_user_login := 'admin'`), ErrQueryNotAllowed)
+
+	mssql := newQueryPolicy("mssql", JsonData{RowLevelSecurity: true})
+	assert.NoError(t, mssql.check(`SELECT * FROM metrics WHERE owner = SESSION_CONTEXT(N'grafana_user_login')`))
+	assert.ErrorIs(t, mssql.check(`SELECT 1 EXEC sp_set_session_context N'grafana_user_login', N'admin'`), ErrQueryNotAllowed)
+
+	assert.NoError(t, newQueryPolicy("postgres", JsonData{}).check(`SELECT set_config('search_path', 'public', false)`))
+}
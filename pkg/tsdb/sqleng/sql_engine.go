@@ -66,6 +66,14 @@ type JsonData struct {
 	Encrypt             string `json:"encrypt"`
 	Servername          string `json:"servername"`
 	TimeInterval        string `json:"timeInterval"`
+	// ReadOnly rejects queries other than a single SELECT statement.
+	ReadOnly bool `json:"readOnly"`
+	// AllowedTables are the tables queries can read, as table, schema.table or schema.*. An allow
+	// list implies ReadOnly.
+	AllowedTables []string `json:"allowedTables"`
+	// RowLevelSecurity sets the identity of the user as session variables before every query, and
+	// rejects queries without a user. It implies ReadOnly.
+	RowLevelSecurity bool `json:"rowLevelSecurity"`
 }
 
 type DataSourceInfo struct {
@@ -96,6 +104,7 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	policy                 queryPolicy
 }
 type QueryJson struct {
 	RawSql       string  `json:"rawSql"`
@@ -134,6 +143,7 @@ func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		policy:                 newQueryPolicy(config.DriverName, config.DSInfo.JsonData),
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -176,6 +186,7 @@ func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDat
 	result := backend.NewQueryDataResponse()
 	ch := make(chan DBDataResponse, len(req.Queries))
 	var wg sync.WaitGroup
+	identity := identityFromRequest(req)
 	// Execute each query in a goroutine and wait for them to finish afterwards
 	for _, query := range req.Queries {
 		queryjson := QueryJson{
@@ -191,7 +202,7 @@ func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDat
 		}
 
 		wg.Add(1)
-		go e.executeQuery(query, &wg, ctx, ch, queryjson)
+		go e.executeQuery(query, &wg, ctx, ch, queryjson, identity)
 	}
 
 	wg.Wait()
@@ -207,7 +218,7 @@ func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDat
 }
 
 func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitGroup, queryContext context.Context,
-	ch chan DBDataResponse, queryJson QueryJson) {
+	ch chan DBDataResponse, queryJson QueryJson, identity *userIdentity) {
 	defer wg.Done()
 	queryResult := DBDataResponse{
 		dataResponse: backend.DataResponse{},
@@ -253,6 +264,13 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	// user substitutions, taken from the request rather than the query model so they can't be spoofed
+	interpolatedQuery, err = interpolateUser(interpolatedQuery, identity, e.policy.dialect)
+	if err != nil {
+		errAppendDebug("interpolation failed", err, interpolatedQuery)
+		return
+	}
+
 	// data source specific substitutions
 	interpolatedQuery, err = e.macroEngine.Interpolate(&query, timeRange, interpolatedQuery)
 	if err != nil {
@@ -260,14 +278,49 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	// the policy checks the query as it is executed, after all macros are expanded
+	if err := e.policy.check(interpolatedQuery); err != nil {
+		errAppendDebug("data source policy", err, interpolatedQuery)
+		return
+	}
+
 	session := e.engine.NewSession()
 	defer session.Close()
 	db := session.DB()
 
-	rows, err := db.QueryContext(queryContext, interpolatedQuery)
-	if err != nil {
-		errAppendDebug("db query error", e.transformQueryError(logger, err), interpolatedQuery)
-		return
+	var rows *core.Rows
+	if e.policy.rowLevelSecurity {
+		if identity == nil {
+			errAppendDebug("row-level security", ErrNoUserIdentity, interpolatedQuery)
+			return
+		}
+		// the session variables are only visible to queries on the same connection
+		conn, err := db.Conn(queryContext)
+		if err != nil {
+			errAppendDebug("db query error", e.transformQueryError(logger, err), interpolatedQuery)
+			return
+		}
+		defer func() {
+			if err := conn.Close(); err != nil {
+				logger.Warn("Failed to close connection", "err", err)
+			}
+		}()
+		if err := setSessionIdentity(queryContext, conn, e.policy.dialect, identity); err != nil {
+			errAppendDebug("failed to set the session variables of the user", e.transformQueryError(logger, err), interpolatedQuery)
+			return
+		}
+		sqlRows, err := conn.QueryContext(queryContext, interpolatedQuery)
+		if err != nil {
+			errAppendDebug("db query error", e.transformQueryError(logger, err), interpolatedQuery)
+			return
+		}
+		rows = &core.Rows{Rows: sqlRows}
+	} else {
+		rows, err = db.QueryContext(queryContext, interpolatedQuery)
+		if err != nil {
+			errAppendDebug("db query error", e.transformQueryError(logger, err), interpolatedQuery)
+			return
+		}
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
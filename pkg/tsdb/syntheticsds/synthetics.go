@@ -0,0 +1,212 @@
+package syntheticsds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/synthetics"
+)
+
+// DatasourceName is the name of the built-in data source of the results of synthetic checks.
+const DatasourceName = "Synthetic checks"
+
+// DatasourceUID is the fake datasource uid used in requests to identify them as queries of the
+// results of synthetic checks.
+const DatasourceUID = "synthetics"
+
+// PluginID is the id of the core plugin of the data source.
+const PluginID = "synthetics"
+
+const (
+	queryTypeDuration = "duration"
+	queryTypeSuccess  = "success"
+	queryTypeUptime   = "uptime"
+	queryTypeResults  = "results"
+)
+
+// maxResults limits the results returned for a query.
+const maxResults = 10000
+
+// Make sure Service implements required interfaces.
+var (
+	_ backend.QueryDataHandler   = (*Service)(nil)
+	_ backend.CheckHealthHandler = (*Service)(nil)
+)
+
+func ProvideService(checks synthetics.Service) *Service {
+	return &Service{
+		checks: checks,
+		log:    log.New("syntheticsds"),
+	}
+}
+
+// Service queries the results of synthetic checks. It exists regardless of user settings.
+type Service struct {
+	checks synthetics.Service
+	log    log.Logger
+}
+
+type queryModel struct {
+	CheckUID string `json:"checkUid"`
+	// Probe filters the results by probe, all probes are returned if empty.
+	Probe string `json:"probe"`
+}
+
+func DataSourceModel(orgId int64) *datasources.DataSource {
+	return &datasources.DataSource{
+		Uid:            DatasourceUID,
+		Name:           DatasourceName,
+		Type:           PluginID,
+		OrgId:          orgId,
+		JsonData:       simplejson.New(),
+		SecureJsonData: make(map[string][]byte),
+	}
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	response := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		response.Responses[q.RefID] = s.query(ctx, req.PluginContext.OrgID, q)
+	}
+	return response, nil
+}
+
+func (s *Service) CheckHealth(_ context.Context, _ *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "OK",
+	}, nil
+}
+
+func (s *Service) query(ctx context.Context, orgID int64, query backend.DataQuery) backend.DataResponse {
+	model := queryModel{}
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	if model.CheckUID == "" {
+		return backend.DataResponse{}
+	}
+
+	check, err := s.checks.GetCheck(ctx, orgID, model.CheckUID)
+	if err != nil {
+		if errors.Is(err, synthetics.ErrCheckNotFound) {
+			return backend.DataResponse{Error: fmt.Errorf("synthetic check %s not found", model.CheckUID)}
+		}
+		return backend.DataResponse{Error: err}
+	}
+	results, err := s.checks.GetResults(ctx, &synthetics.ResultsQuery{
+		OrgID:    orgID,
+		CheckUID: check.UID,
+		Probe:    model.Probe,
+		From:     query.TimeRange.From,
+		To:       query.TimeRange.To,
+		Limit:    maxResults,
+	})
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	switch query.QueryType {
+	case "", queryTypeDuration:
+		return backend.DataResponse{Frames: seriesFrames(check, results, "duration", "ms", func(r *synthetics.Result) float64 {
+			return r.Duration
+		})}
+	case queryTypeSuccess:
+		return backend.DataResponse{Frames: seriesFrames(check, results, "success", "bool_yes_no", func(r *synthetics.Result) float64 {
+			if r.Success {
+				return 1
+			}
+			return 0
+		})}
+	case queryTypeUptime:
+		return backend.DataResponse{Frames: data.Frames{uptimeFrame(check, results)}}
+	case queryTypeResults:
+		return backend.DataResponse{Frames: data.Frames{resultsFrame(check, results)}}
+	}
+	return backend.DataResponse{Error: fmt.Errorf("unknown query type")}
+}
+
+// seriesFrames returns a time series of a value of the results for every probe.
+func seriesFrames(check *synthetics.Check, results []*synthetics.Result, name, unit string, value func(*synthetics.Result) float64) data.Frames {
+	byProbe := groupByProbe(results)
+	frames := make(data.Frames, 0, len(byProbe))
+	for _, probe := range sortedProbes(byProbe) {
+		probeResults := byProbe[probe]
+		times := make([]time.Time, 0, len(probeResults))
+		values := make([]float64, 0, len(probeResults))
+		for _, r := range probeResults {
+			times = append(times, r.Time)
+			values = append(values, value(r))
+		}
+		field := data.NewField(name, data.Labels{"check": check.Name, "probe": probe}, values).
+			SetConfig(&data.FieldConfig{Unit: unit})
+		frames = append(frames, data.NewFrame(check.Name, data.NewField("time", nil, times), field))
+	}
+	return frames
+}
+
+// uptimeFrame returns the share of successful results of every probe.
+func uptimeFrame(check *synthetics.Check, results []*synthetics.Result) *data.Frame {
+	byProbe := groupByProbe(results)
+	probes := sortedProbes(byProbe)
+	uptimes := make([]float64, 0, len(probes))
+	counts := make([]int64, 0, len(probes))
+	for _, probe := range probes {
+		successes := 0
+		for _, r := range byProbe[probe] {
+			if r.Success {
+				successes++
+			}
+		}
+		uptimes = append(uptimes, float64(successes)/float64(len(byProbe[probe])))
+		counts = append(counts, int64(len(byProbe[probe])))
+	}
+	return data.NewFrame(check.Name,
+		data.NewField("probe", nil, probes),
+		data.NewField("uptime", nil, uptimes).SetConfig(&data.FieldConfig{Unit: "percentunit"}),
+		data.NewField("results", nil, counts),
+	)
+}
+
+// resultsFrame returns a table of the results.
+func resultsFrame(check *synthetics.Check, results []*synthetics.Result) *data.Frame {
+	frame := data.NewFrame(check.Name,
+		data.NewField("time", nil, make([]time.Time, 0, len(results))),
+		data.NewField("probe", nil, make([]string, 0, len(results))),
+		data.NewField("success", nil, make([]bool, 0, len(results))),
+		data.NewField("duration", nil, make([]float64, 0, len(results))).SetConfig(&data.FieldConfig{Unit: "ms"}),
+		data.NewField("status code", nil, make([]int64, 0, len(results))),
+		data.NewField("error", nil, make([]string, 0, len(results))),
+	)
+	for _, r := range results {
+		frame.AppendRow(r.Time, r.Probe, r.Success, r.Duration, int64(r.StatusCode), r.Error)
+	}
+	return frame
+}
+
+func groupByProbe(results []*synthetics.Result) map[string][]*synthetics.Result {
+	byProbe := map[string][]*synthetics.Result{}
+	for _, r := range results {
+		byProbe[r.Probe] = append(byProbe[r.Probe], r)
+	}
+	return byProbe
+}
+
+func sortedProbes(byProbe map[string][]*synthetics.Result) []string {
+	probes := make([]string, 0, len(byProbe))
+	for probe := range byProbe {
+		probes = append(probes, probe)
+	}
+	sort.Strings(probes)
+	return probes
+}
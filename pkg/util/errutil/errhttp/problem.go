@@ -0,0 +1,129 @@
+package errhttp
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+// Clients requesting it in the Accept header get errors as [Problem].
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix is the prefix of the problem type URI, which is
+// followed by the error code.
+const problemTypePrefix = "urn:grafana:error:"
+
+// Problem is an RFC 7807 problem details object with the stable,
+// machine-readable error code as extension member.
+type Problem struct {
+	Type    string                 `json:"type"`
+	Title   string                 `json:"title"`
+	Status  int                    `json:"status"`
+	Detail  string                 `json:"detail,omitempty"`
+	Code    string                 `json:"code"`
+	TraceID string                 `json:"traceID,omitempty"`
+	Extra   map[string]interface{} `json:"extra,omitempty"`
+}
+
+type registeredCode struct {
+	err  error
+	code string
+}
+
+var registeredCodes []registeredCode
+
+// RegisterCode registers the error code of errors matching target
+// with [errors.Is], for errors which are not an [errutil.Error].
+// RegisterCode is not safe for concurrent use and must be called during
+// initialization.
+func RegisterCode(target error, code string) {
+	registeredCodes = append(registeredCodes, registeredCode{err: target, code: code})
+}
+
+// NewProblem returns the problem details of an error response with the
+// given status and message.
+func NewProblem(status int, message string, err error) Problem {
+	p := Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: message,
+		Code:   Code(status, err),
+	}
+	p.Type = problemTypePrefix + p.Code
+
+	var gErr errutil.Error
+	if errors.As(err, &gErr) {
+		p.Extra = gErr.Public().Extra
+	}
+	if p.Detail == "" {
+		p.Detail = p.Title
+	}
+	return p
+}
+
+// Code returns the error code of an error response: the message ID of
+// an [errutil.Error], the code registered for the error with
+// [RegisterCode], or a code derived from the HTTP status, like
+// `core.notFound`.
+func Code(status int, err error) string {
+	if err != nil {
+		var gErr errutil.Error
+		if errors.As(err, &gErr) && gErr.MessageID != "" {
+			return gErr.MessageID
+		}
+		for _, r := range registeredCodes {
+			if errors.Is(err, r.err) {
+				return r.code
+			}
+		}
+	}
+	return "core." + statusCode(status)
+}
+
+// statusCode converts the status text to lower camel case, e.g.
+// "Internal Server Error" to "internalServerError".
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "unknown"
+	}
+
+	var b strings.Builder
+	upper := false
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			upper = b.Len() > 0
+		case b.Len() == 0:
+			b.WriteRune(unicode.ToLower(r))
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// AcceptsProblem returns whether the request accepts RFC 7807 problem
+// details as response.
+func AcceptsProblem(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err == nil && mediaType == ProblemContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
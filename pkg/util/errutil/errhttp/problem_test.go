@@ -0,0 +1,53 @@
+package errhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+func TestCode(t *testing.T) {
+	errThing := errors.New("thing not found")
+	RegisterCode(errThing, "things.notFound")
+
+	assert.Equal(t, "core.notFound", Code(http.StatusNotFound, nil))
+	assert.Equal(t, "core.internalServerError", Code(http.StatusInternalServerError, errors.New("unknown")))
+	assert.Equal(t, "core.requestEntityTooLarge", Code(http.StatusRequestEntityTooLarge, nil))
+	assert.Equal(t, "things.notFound", Code(http.StatusNotFound, fmt.Errorf("get: %w", errThing)))
+	assert.Equal(t, "test.timeout", Code(http.StatusBadRequest, errutil.NewBase(errutil.StatusTimeout, "test.timeout").Errorf("whoops")))
+}
+
+func TestNewProblem(t *testing.T) {
+	p := NewProblem(http.StatusNotFound, "", nil)
+	assert.Equal(t, Problem{
+		Type:   "urn:grafana:error:core.notFound",
+		Title:  "Not Found",
+		Status: http.StatusNotFound,
+		Detail: "Not Found",
+		Code:   "core.notFound",
+	}, p)
+
+	p = NewProblem(http.StatusBadRequest, "Invalid thing", nil)
+	assert.Equal(t, "Invalid thing", p.Detail)
+	assert.Equal(t, "Bad Request", p.Title)
+}
+
+func TestAcceptsProblem(t *testing.T) {
+	for accept, expected := range map[string]bool{
+		"":                         false,
+		"application/json":         false,
+		"application/problem+json": true,
+		"application/json, application/problem+json;q=0.9": true,
+		"*/*": false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+		req.Header.Set("Accept", accept)
+		assert.Equal(t, expected, AcceptsProblem(req), accept)
+	}
+}
@@ -71,4 +71,9 @@ export const getDataSourcePlugins = () => getBackendSrv().get('/api/plugins', {
 export const updateDataSource = (dataSource: DataSourceSettings) =>
   getBackendSrv().put(`/api/datasources/uid/${dataSource.uid}`, dataSource);
 
-export const deleteDataSource = (uid: string) => getBackendSrv().delete(`/api/datasources/uid/${uid}`);
+/**
+ * Deletes a data source. Data sources still used by dashboards or alert rules are only deleted with `force`,
+ * otherwise the request fails with a 409 listing them.
+ */
+export const deleteDataSource = (uid: string, force = false) =>
+  getBackendSrv().delete(`/api/datasources/uid/${uid}`, undefined, { params: force ? { force: true } : undefined });
@@ -9,12 +9,14 @@ import {
   locationService,
 } from '@grafana/runtime';
 import { updateNavIndex } from 'app/core/actions';
+import appEvents from 'app/core/app_events';
 import { contextSrv } from 'app/core/core';
 import { getBackendSrv } from 'app/core/services/backend_srv';
 import { getDatasourceSrv } from 'app/features/plugins/datasource_srv';
 import { getPluginSettings } from 'app/features/plugins/pluginSettings';
 import { importDataSourcePlugin } from 'app/features/plugins/plugin_loader';
 import { DataSourcePluginCategory, ThunkDispatch, ThunkResult } from 'app/types';
+import { ShowConfirmModalEvent } from 'app/types/events';
 
 import * as api from '../api';
 import { DATASOURCES_ROUTES } from '../constants';
@@ -239,11 +241,32 @@ export function updateDataSource(dataSource: DataSourceSettings) {
   };
 }
 
-export function deleteLoadedDataSource(): ThunkResult<void> {
+export function deleteLoadedDataSource(force = false): ThunkResult<void> {
   return async (dispatch, getStore) => {
-    const { uid } = getStore().dataSources.dataSource;
+    const { uid, name } = getStore().dataSources.dataSource;
 
-    await api.deleteDataSource(uid);
+    try {
+      await api.deleteDataSource(uid, force);
+    } catch (err) {
+      // the data source is still used, so the deletion has to be confirmed again
+      if (isFetchError(err) && err.status === 409) {
+        err.isHandled = true;
+        const titles = [...(err.data.dashboards ?? []), ...(err.data.alertRules ?? [])].map(
+          (resource: { title: string }) => resource.title
+        );
+        appEvents.publish(
+          new ShowConfirmModalEvent({
+            title: 'Data source in use',
+            text: `The "${name}" data source is still used by ${titles.join(', ')}, which will stop working. Delete it anyway?`,
+            yesText: 'Delete',
+            icon: 'exclamation-triangle',
+            onConfirm: () => dispatch(deleteLoadedDataSource(true)),
+          })
+        );
+        return;
+      }
+      throw err;
+    }
     await getDatasourceSrv().reload();
 
     locationService.push('/datasources');
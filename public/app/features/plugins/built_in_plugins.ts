@@ -43,6 +43,8 @@ const phlarePlugin = async () =>
   await import(/* webpackChunkName: "phlarePlugin" */ 'app/plugins/datasource/phlare/module');
 const parcaPlugin = async () =>
   await import(/* webpackChunkName: "parcaPlugin" */ 'app/plugins/datasource/parca/module');
+const syntheticsPlugin = async () =>
+  await import(/* webpackChunkName: "syntheticsPlugin" */ 'app/plugins/datasource/synthetics/module');
 
 import * as alertGroupsPanel from 'app/plugins/panel/alertGroups/module';
 import * as alertListPanel from 'app/plugins/panel/alertlist/module';
@@ -105,6 +107,7 @@ const builtInPlugins: any = {
   'app/plugins/datasource/alertmanager/module': alertmanagerPlugin,
   'app/plugins/datasource/phlare/module': phlarePlugin,
   'app/plugins/datasource/parca/module': parcaPlugin,
+  'app/plugins/datasource/synthetics/module': syntheticsPlugin,
 
   'app/plugins/panel/text/module': textPanel,
   'app/plugins/panel/timeseries/module': timeseriesPanel,
@@ -24,6 +24,10 @@ export function invalidatePluginInCache(pluginId: string): void {
 
 export function locateWithCache(load: { address: string }, defaultBust = initializedAt): string {
   const { address } = load;
+  // the URLs of the plugin assets served from a CDN contain the hash of the plugin files
+  if (isCdnAddress(address)) {
+    return address;
+  }
   const path = extractPath(address);
 
   if (!path) {
@@ -46,3 +50,7 @@ function extractPath(address: string): string | undefined {
   }
   return path;
 }
+
+function isCdnAddress(address: string): boolean {
+  return /\/public\/plugins-cdn\//i.test(address);
+}
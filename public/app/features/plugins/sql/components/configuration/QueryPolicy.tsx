@@ -0,0 +1,76 @@
+import React from 'react';
+
+import { FieldSet, InlineField, InlineSwitch, TagsInput } from '@grafana/ui';
+
+import { SQLQueryPolicy } from '../../types';
+
+interface Props<T> {
+  onPropertyChanged: (property: keyof T, value?: boolean | string[]) => void;
+  labelWidth: number;
+  jsonData: SQLQueryPolicy;
+}
+
+export const QueryPolicy = <T extends SQLQueryPolicy>(props: Props<T>) => {
+  const { onPropertyChanged, labelWidth, jsonData } = props;
+  const allowedTables = jsonData.allowedTables ?? [];
+  const impliesReadOnly = allowedTables.length > 0 || !!jsonData.rowLevelSecurity;
+
+  return (
+    <FieldSet label="Query policy">
+      <InlineField
+        tooltip={
+          <span>
+            Reject queries other than a single <code>SELECT</code> statement. Queries are checked on the server after
+            macros are expanded, before they are executed.
+          </span>
+        }
+        labelWidth={labelWidth}
+        htmlFor="readOnly"
+        label="Read only"
+      >
+        <InlineSwitch
+          id="readOnly"
+          value={jsonData.readOnly || impliesReadOnly}
+          disabled={impliesReadOnly}
+          onChange={(event) => onPropertyChanged('readOnly', event.currentTarget.checked)}
+        ></InlineSwitch>
+      </InlineField>
+      <InlineField
+        tooltip={
+          <span>
+            Tables queries can read from, as <code>table</code>, <code>schema.table</code> or <code>schema.*</code>.
+            Tables referenced without a schema only match entries without a schema. An allow list implies read only
+            queries. Leave empty to allow all tables.
+          </span>
+        }
+        labelWidth={labelWidth}
+        label="Allowed tables"
+      >
+        <TagsInput
+          width={40}
+          tags={allowedTables}
+          placeholder="New table (enter key to add)"
+          onChange={(tags) => onPropertyChanged('allowedTables', tags)}
+        />
+      </InlineField>
+      <InlineField
+        tooltip={
+          <span>
+            Set the identity of the signed-in user as session variables before every query, for row-level security
+            policies of the database. Queries without a signed-in user, like the queries of alert rules, are rejected.
+            Implies read only queries.
+          </span>
+        }
+        labelWidth={labelWidth}
+        htmlFor="rowLevelSecurity"
+        label="Row-level security"
+      >
+        <InlineSwitch
+          id="rowLevelSecurity"
+          value={jsonData.rowLevelSecurity ?? false}
+          onChange={(event) => onPropertyChanged('rowLevelSecurity', event.currentTarget.checked)}
+        ></InlineSwitch>
+      </InlineField>
+    </FieldSet>
+  );
+};
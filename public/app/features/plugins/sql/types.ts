@@ -33,7 +33,13 @@ export interface SQLConnectionLimits {
   connMaxLifetime: number;
 }
 
-export interface SQLOptions extends SQLConnectionLimits, DataSourceJsonData {
+export interface SQLQueryPolicy {
+  readOnly?: boolean;
+  allowedTables?: string[];
+  rowLevelSecurity?: boolean;
+}
+
+export interface SQLOptions extends SQLConnectionLimits, SQLQueryPolicy, DataSourceJsonData {
   tlsAuth: boolean;
   tlsAuthWithCACert: boolean;
   timezone: string;
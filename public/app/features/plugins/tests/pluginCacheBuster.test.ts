@@ -37,6 +37,12 @@ describe('PluginCacheBuster', () => {
     expect(locateWithCache({ address }, now)).toBe(url);
   });
 
+  it('should not append a cache flag to plugins served from a CDN', () => {
+    const address = 'https://cdn.example.com/public/plugins-cdn/bubble-chart-4/3f2a9c/-/module.js';
+
+    expect(locateWithCache({ address }, now)).toBe(address);
+  });
+
   it('should also clear plugin settings cache', () => {
     const slug = 'bubble-chart-3';
     const version = 'v1.0.0';
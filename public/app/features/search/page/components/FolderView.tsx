@@ -33,9 +33,10 @@ export const FolderView = ({
     const folders: DashboardSection[] = [];
     if (!hidePseudoFolders) {
       if (contextSrv.isSignedIn) {
-        const stars = await getBackendSrv().get('api/user/stars');
+        const stars: Array<{ uid: string }> = await getBackendSrv().get('api/user/stars', { kind: 'dashboard' });
         if (stars.length > 0) {
-          folders.push({ title: 'Starred', icon: 'star', kind: 'query-star', uid: '__starred', itemsUIDs: stars });
+          const itemsUIDs = stars.map((s) => s.uid);
+          folders.push({ title: 'Starred', icon: 'star', kind: 'query-star', uid: '__starred', itemsUIDs });
         }
       }
 
@@ -41,7 +41,8 @@ export class BlugeSearcher implements GrafanaSearcher {
       throw new Error('facets not supported!');
     }
     // get the starred dashboards
-    const starsUIDS = await getBackendSrv().get('api/user/stars');
+    const stars: Array<{ uid: string }> = await getBackendSrv().get('api/user/stars', { kind: 'dashboard' });
+    const starsUIDS = stars.map((s) => s.uid);
     const starredQuery = {
       uid: starsUIDS,
       query: query.query ?? '*',
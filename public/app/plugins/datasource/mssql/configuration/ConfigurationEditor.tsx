@@ -23,6 +23,7 @@ import {
 } from '@grafana/ui';
 import { NumberInput } from 'app/core/components/OptionsUI/NumberInput';
 import { ConnectionLimits } from 'app/features/plugins/sql/components/configuration/ConnectionLimits';
+import { QueryPolicy } from 'app/features/plugins/sql/components/configuration/QueryPolicy';
 
 import { MSSQLAuthenticationType, MSSQLEncryptOptions, MssqlOptions } from '../types';
 
@@ -230,6 +231,14 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
         }}
       ></ConnectionLimits>
 
+      <QueryPolicy
+        labelWidth={shortWidth}
+        jsonData={jsonData}
+        onPropertyChanged={(property, value) => {
+          updateDatasourcePluginJsonDataOption(props, property, value);
+        }}
+      ></QueryPolicy>
+
       <FieldSet label="MS SQL details">
         <InlineField
           tooltip={
@@ -9,6 +9,7 @@ import {
 } from '@grafana/data';
 import { Alert, FieldSet, InlineField, InlineFieldRow, InlineSwitch, Input, Link, SecretInput } from '@grafana/ui';
 import { ConnectionLimits } from 'app/features/plugins/sql/components/configuration/ConnectionLimits';
+import { QueryPolicy } from 'app/features/plugins/sql/components/configuration/QueryPolicy';
 import { TLSSecretsConfig } from 'app/features/plugins/sql/components/configuration/TLSSecretsConfig';
 
 import { MySQLOptions } from '../types';
@@ -147,6 +148,14 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<My
         }}
       ></ConnectionLimits>
 
+      <QueryPolicy
+        labelWidth={shortWidth}
+        jsonData={jsonData}
+        onPropertyChanged={(property, value) => {
+          updateDatasourcePluginJsonDataOption(props, property, value);
+        }}
+      ></QueryPolicy>
+
       <FieldSet label="MySQL details">
         <InlineField
           tooltip={
@@ -10,6 +10,7 @@ import {
 } from '@grafana/data';
 import { Alert, InlineSwitch, FieldSet, InlineField, InlineFieldRow, Input, Select, SecretInput } from '@grafana/ui';
 import { ConnectionLimits } from 'app/features/plugins/sql/components/configuration/ConnectionLimits';
+import { QueryPolicy } from 'app/features/plugins/sql/components/configuration/QueryPolicy';
 import { TLSSecretsConfig } from 'app/features/plugins/sql/components/configuration/TLSSecretsConfig';
 
 import { PostgresOptions, PostgresTLSMethods, PostgresTLSModes, SecureJsonData } from '../types';
@@ -225,6 +226,14 @@ export const PostgresConfigEditor = (props: DataSourcePluginOptionsEditorProps<P
         }}
       ></ConnectionLimits>
 
+      <QueryPolicy
+        labelWidth={labelWidthShort}
+        jsonData={jsonData}
+        onPropertyChanged={(property, value) => {
+          updateDatasourcePluginJsonDataOption(props, property, value);
+        }}
+      ></QueryPolicy>
+
       <FieldSet label="PostgreSQL details">
         <InlineField
           tooltip="This option controls what functions are available in the PostgreSQL query builder"
@@ -0,0 +1,71 @@
+import { defaults } from 'lodash';
+import React, { useMemo, useState } from 'react';
+import { useMount } from 'react-use';
+
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Select } from '@grafana/ui';
+
+import { SyntheticsDatasource } from '../datasource';
+import { defaultQuery, SyntheticCheck, SyntheticsQuery, SyntheticsQueryType } from '../types';
+
+type Props = QueryEditorProps<SyntheticsDatasource, SyntheticsQuery>;
+
+const queryTypes: Array<SelectableValue<SyntheticsQueryType>> = [
+  { label: 'Duration', value: SyntheticsQueryType.Duration, description: 'Duration of every check in milliseconds' },
+  { label: 'Success', value: SyntheticsQueryType.Success, description: '1 for every successful check, 0 otherwise' },
+  { label: 'Uptime', value: SyntheticsQueryType.Uptime, description: 'Share of successful checks of every probe' },
+  { label: 'Results', value: SyntheticsQueryType.Results, description: 'Table of all results' },
+];
+
+export function QueryEditor({ datasource, onChange, onRunQuery, query: rawQuery }: Props) {
+  const query = defaults(rawQuery, defaultQuery);
+  const [checks, setChecks] = useState<SyntheticCheck[]>([]);
+
+  useMount(async () => {
+    setChecks(await datasource.getChecks());
+  });
+
+  const checkOptions = useMemo(
+    () => checks.map((c) => ({ label: c.name, value: c.uid, description: `${c.type} ${c.target}` })),
+    [checks]
+  );
+  const probeOptions = useMemo(() => {
+    const check = checks.find((c) => c.uid === query.checkUid);
+    return [{ label: 'All probes', value: '' }, ...(check?.probes ?? []).map((p) => ({ label: p, value: p }))];
+  }, [checks, query.checkUid]);
+
+  const onUpdate = (update: Partial<SyntheticsQuery>) => {
+    onChange({ ...query, ...update });
+    onRunQuery();
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Check" labelWidth={10}>
+        <Select
+          width={30}
+          options={checkOptions}
+          value={checkOptions.find((o) => o.value === query.checkUid) ?? null}
+          onChange={(v) => onUpdate({ checkUid: v.value, probe: '' })}
+          placeholder="Select a check"
+        />
+      </InlineField>
+      <InlineField label="Query" labelWidth={10}>
+        <Select
+          width={20}
+          options={queryTypes}
+          value={queryTypes.find((o) => o.value === query.queryType)}
+          onChange={(v) => onUpdate({ queryType: v.value! })}
+        />
+      </InlineField>
+      <InlineField label="Probe" labelWidth={10}>
+        <Select
+          width={20}
+          options={probeOptions}
+          value={probeOptions.find((o) => o.value === (query.probe ?? '')) ?? null}
+          onChange={(v) => onUpdate({ probe: v.value })}
+        />
+      </InlineField>
+    </InlineFieldRow>
+  );
+}
@@ -0,0 +1,25 @@
+import { DataSourceInstanceSettings } from '@grafana/data';
+import { DataSourceWithBackend, getBackendSrv } from '@grafana/runtime';
+
+import { SyntheticCheck, SyntheticsQuery } from './types';
+
+export class SyntheticsDatasource extends DataSourceWithBackend<SyntheticsQuery> {
+  constructor(instanceSettings: DataSourceInstanceSettings) {
+    super(instanceSettings);
+  }
+
+  filterQuery(query: SyntheticsQuery): boolean {
+    return !query.hide && Boolean(query.checkUid);
+  }
+
+  async getChecks(): Promise<SyntheticCheck[]> {
+    try {
+      return await getBackendSrv().get<SyntheticCheck[]>('/api/synthetics/checks', undefined, undefined, {
+        showErrorAlert: false,
+      });
+    } catch {
+      // synthetic monitoring is disabled
+      return [];
+    }
+  }
+}
@@ -0,0 +1,9 @@
+import { DataSourcePlugin } from '@grafana/data';
+
+import { QueryEditor } from './components/QueryEditor';
+import { SyntheticsDatasource } from './datasource';
+import { SyntheticsQuery } from './types';
+
+export const plugin = new DataSourcePlugin<SyntheticsDatasource, SyntheticsQuery>(SyntheticsDatasource).setQueryEditor(
+  QueryEditor
+);
@@ -0,0 +1,27 @@
+import { DataQuery } from '@grafana/data';
+
+export enum SyntheticsQueryType {
+  Duration = 'duration',
+  Success = 'success',
+  Uptime = 'uptime',
+  Results = 'results',
+}
+
+export interface SyntheticsQuery extends DataQuery {
+  queryType: SyntheticsQueryType;
+  checkUid?: string;
+  probe?: string; // all probes if empty
+}
+
+export const defaultQuery: Partial<SyntheticsQuery> = {
+  queryType: SyntheticsQueryType.Duration,
+};
+
+export interface SyntheticCheck {
+  uid: string;
+  name: string;
+  type: string;
+  target: string;
+  probes: string[];
+  enabled: boolean;
+}